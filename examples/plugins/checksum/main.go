@@ -0,0 +1,220 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+// ysm-checksum is a sample YSM plugin. It is deliberately self-contained:
+// plugins only speak the JSON protocol and never import YSM packages.
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// request mirrors the fields of YSM's plugin request this plugin uses
+type request struct {
+	Hook       string `json:"hook"`
+	Database   string `json:"database"`
+	View       string `json:"view"`
+	Format     string `json:"format"`
+	InputFile  string `json:"input_file"`
+	OutputFile string `json:"output_file"`
+	BackupDir  string `json:"backup_dir"`
+}
+
+// response mirrors YSM's plugin response
+type response struct {
+	Title   string     `json:"title,omitempty"`
+	Content string     `json:"content,omitempty"`
+	Columns []string   `json:"columns,omitempty"`
+	Rows    [][]string `json:"rows,omitempty"`
+	Message string     `json:"message,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+const sumsFile = "SHA256SUMS"
+
+func main() {
+	var req request
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		reply(response{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	var resp response
+	var err error
+	switch req.Hook {
+	case "post_backup":
+		resp, err = postBackup(req)
+	case "export":
+		resp, err = export(req)
+	case "view":
+		resp, err = view(req)
+	default:
+		err = fmt.Errorf("unsupported hook: %s", req.Hook)
+	}
+	if err != nil {
+		resp = response{Error: err.Error()}
+	}
+	reply(resp)
+}
+
+func reply(resp response) {
+	json.NewEncoder(os.Stdout).Encode(resp)
+}
+
+// postBackup writes a SHA256SUMS file listing every file in the backup
+func postBackup(req request) (response, error) {
+	entries, err := os.ReadDir(req.BackupDir)
+	if err != nil {
+		return response{}, err
+	}
+
+	var lines []string
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == sumsFile {
+			continue
+		}
+		sum, err := fileSum(filepath.Join(req.BackupDir, e.Name()))
+		if err != nil {
+			return response{}, err
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s", sum, e.Name()))
+	}
+
+	data := strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(req.BackupDir, sumsFile), []byte(data), 0644); err != nil {
+		return response{}, err
+	}
+	return response{Message: fmt.Sprintf("wrote %d checksum(s)", len(lines))}, nil
+}
+
+// export copies the SQL dump YSM produced and writes a .sha256 file beside it
+func export(req request) (response, error) {
+	in, err := os.Open(req.InputFile)
+	if err != nil {
+		return response{}, err
+	}
+	defer in.Close()
+
+	out, err := os.Create(req.OutputFile)
+	if err != nil {
+		return response{}, err
+	}
+	defer out.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, h), in); err != nil {
+		return response{}, err
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	line := fmt.Sprintf("%s  %s\n", sum, filepath.Base(req.OutputFile))
+	if err := os.WriteFile(req.OutputFile+".sha256", []byte(line), 0644); err != nil {
+		return response{}, err
+	}
+	return response{Message: "sha256 " + sum}, nil
+}
+
+// view verifies the SHA256SUMS file of every backup in the default backups directory
+func view(req request) (response, error) {
+	if req.View != "backups" {
+		return response{}, fmt.Errorf("unknown view: %s", req.View)
+	}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return response{}, err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	backupsDir := filepath.Join(dataHome, "ysm", "backups")
+
+	entries, err := os.ReadDir(backupsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return response{}, err
+	}
+
+	resp := response{
+		Title:   "Backup checksum verification",
+		Columns: []string{"BACKUP", "FILES", "STATUS"},
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		files, status := verify(filepath.Join(backupsDir, e.Name()))
+		resp.Rows = append(resp.Rows, []string{e.Name(), fmt.Sprintf("%d", files), status})
+	}
+	sort.Slice(resp.Rows, func(i, j int) bool { return resp.Rows[i][0] > resp.Rows[j][0] })
+
+	if len(resp.Rows) == 0 {
+		resp.Content = "No backups found in " + backupsDir
+	}
+	return resp, nil
+}
+
+// verify checks a backup directory against its SHA256SUMS file
+func verify(dir string) (int, string) {
+	f, err := os.Open(filepath.Join(dir, sumsFile))
+	if err != nil {
+		return 0, "no checksums"
+	}
+	defer f.Close()
+
+	files := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), "  ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		files++
+		sum, err := fileSum(filepath.Join(dir, parts[1]))
+		if err != nil {
+			return files, "missing " + parts[1]
+		}
+		if sum != parts[0] {
+			return files, "MISMATCH " + parts[1]
+		}
+	}
+	return files, "ok"
+}
+
+func fileSum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,387 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+	"github.com/blubskye/yandere_sql_manager/internal/webhook"
+)
+
+// DefaultInterval is how often a monitor polls when no interval is configured
+const DefaultInterval = 30 * time.Second
+
+// alertChecks are the health checks that raise alerts; the rest of
+// CheckHealth (disk, sizes) is for healthcheck runs
+var alertChecks = map[string]bool{
+	"connection":  true,
+	"replication": true,
+	"cluster":     true,
+	"nodes":       true,
+}
+
+// Config is the alerts section of the configuration file
+type Config struct {
+	Interval    string            `yaml:"interval,omitempty"`    // How often to poll, e.g. "30s"
+	Repeat      string            `yaml:"repeat,omitempty"`      // Resend unresolved alerts after e.g. "1h"; empty sends once
+	LagWarning  string            `yaml:"lag_warning,omitempty"` // Replication lag thresholds, e.g. "30s" and "5m"
+	LagCritical string            `yaml:"lag_critical,omitempty"`
+	ClusterSize int               `yaml:"cluster_size,omitempty"` // Expected nodes; default: the most seen since start
+	Webhooks    []webhook.Webhook `yaml:"webhooks,omitempty"`
+	Email       *Email            `yaml:"email,omitempty"`
+}
+
+// Email sends alerts through an SMTP server
+type Email struct {
+	SMTP     string   `yaml:"smtp"` // host:port
+	Username string   `yaml:"username,omitempty"`
+	Password string   `yaml:"password,omitempty"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+}
+
+// Validate checks that the config has somewhere to send alerts and that
+// its values parse
+func (c *Config) Validate() error {
+	if c == nil || (len(c.Webhooks) == 0 && c.Email == nil) {
+		return fmt.Errorf("no alert destinations: add webhooks or email under alerts in the config file")
+	}
+	for _, field := range []struct{ name, value string }{
+		{"interval", c.Interval}, {"repeat", c.Repeat},
+		{"lag_warning", c.LagWarning}, {"lag_critical", c.LagCritical},
+	} {
+		if _, err := parseDuration(field.name, field.value); err != nil {
+			return err
+		}
+	}
+	for _, hook := range c.Webhooks {
+		if err := hook.Validate(); err != nil {
+			return err
+		}
+	}
+	if e := c.Email; e != nil {
+		switch {
+		case e.SMTP == "" || !strings.Contains(e.SMTP, ":"):
+			return fmt.Errorf("email smtp must be host:port")
+		case e.From == "":
+			return fmt.Errorf("email requires from")
+		case len(e.To) == 0:
+			return fmt.Errorf("email requires at least one to address")
+		}
+	}
+	return nil
+}
+
+// parseDuration parses an optional positive duration setting
+func parseDuration(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid alerts %s %q: use a duration like 30s", name, value)
+	}
+	return d, nil
+}
+
+// Event is a change in a check's status worth telling someone about
+type Event struct {
+	Server   string
+	Check    string
+	Status   db.HealthStatus
+	Previous db.HealthStatus
+	Message  string
+	Time     time.Time
+	Repeat   bool // Re-sent because the problem is still unresolved
+}
+
+// Recovered reports whether the event clears an earlier alert
+func (e Event) Recovered() bool {
+	return e.Status == db.HealthOK
+}
+
+// Subject is the one-line summary used for email subjects and chat messages
+func (e Event) Subject() string {
+	label := e.Status.String()
+	switch {
+	case e.Recovered():
+		label = "RECOVERED"
+	case e.Repeat:
+		label = "STILL " + label
+	}
+	return fmt.Sprintf("[ysm] %s %s %s", label, e.Server, e.Check)
+}
+
+// Text is the full plain-text description of the event
+func (e Event) Text() string {
+	return fmt.Sprintf("%s\n\nServer: %s\nCheck: %s\nStatus: %s (was %s)\nTime: %s\n\n%s\n",
+		e.Subject(), e.Server, e.Check, e.Status, e.Previous, e.Time.Format(time.RFC3339), e.Message)
+}
+
+// checkState is what the monitor remembers about a check between polls
+type checkState struct {
+	status  db.HealthStatus
+	message string
+	since   time.Time
+	sentAt  time.Time
+}
+
+// Monitor polls a server's health and raises an event whenever a check
+// changes status. Unchanged problems stay quiet unless Repeat is set, and
+// a check returning to OK sends a recovery notice
+type Monitor struct {
+	cfg        *Config
+	server     string
+	interval   time.Duration
+	repeat     time.Duration
+	thresholds db.HealthThresholds
+
+	mu        sync.Mutex
+	states    map[string]*checkState
+	peakNodes int
+	lastPoll  time.Time
+	lastErr   error
+	running   bool
+	stop      chan struct{}
+}
+
+// NewMonitor creates a monitor for the server (a profile name or host)
+func NewMonitor(cfg *Config, server string) (*Monitor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	m := &Monitor{
+		cfg:      cfg,
+		server:   server,
+		interval: DefaultInterval,
+		states:   make(map[string]*checkState),
+	}
+	defaults := db.DefaultHealthThresholds()
+	m.thresholds.LagWarning, m.thresholds.LagCritical = defaults.LagWarning, defaults.LagCritical
+
+	if d, _ := parseDuration("interval", cfg.Interval); d > 0 {
+		m.interval = d
+	}
+	m.repeat, _ = parseDuration("repeat", cfg.Repeat)
+	if d, _ := parseDuration("lag_warning", cfg.LagWarning); d > 0 {
+		m.thresholds.LagWarning = d
+	}
+	if d, _ := parseDuration("lag_critical", cfg.LagCritical); d > 0 {
+		m.thresholds.LagCritical = d
+	}
+	return m, nil
+}
+
+// Interval returns the polling interval
+func (m *Monitor) Interval() time.Duration {
+	return m.interval
+}
+
+// Evaluate checks the server and returns the events its state changes
+// raise, without sending them
+func (m *Monitor) Evaluate(conn *db.Connection) []Event {
+	now := time.Now()
+	checks := m.collect(conn)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastPoll = now
+
+	var events []Event
+	for _, check := range checks {
+		state, seen := m.states[check.Name]
+		if !seen {
+			// The first poll only reports problems; there's nothing to recover from
+			state = &checkState{status: db.HealthOK, since: now}
+			m.states[check.Name] = state
+		}
+
+		event := Event{
+			Server:   m.server,
+			Check:    check.Name,
+			Status:   check.Status,
+			Previous: state.status,
+			Message:  check.Message,
+			Time:     now,
+		}
+		switch {
+		case check.Status != state.status:
+			state.status, state.since, state.sentAt = check.Status, now, now
+			events = append(events, event)
+		case check.Status != db.HealthOK && m.repeat > 0 && now.Sub(state.sentAt) >= m.repeat:
+			state.sentAt = now
+			event.Repeat = true
+			events = append(events, event)
+		}
+		state.message = check.Message
+	}
+	return events
+}
+
+// collect runs the alerting checks. When the server can't be reached only
+// the connection check counts, so an outage is one alert rather than one
+// per check
+func (m *Monitor) collect(conn *db.Connection) []db.HealthCheck {
+	report := conn.CheckHealth(m.thresholds)
+	var checks []db.HealthCheck
+	for _, check := range report.Checks {
+		if check.Name == "connection" && check.Status == db.HealthCritical {
+			return []db.HealthCheck{check}
+		}
+		if alertChecks[check.Name] && !check.Skipped {
+			checks = append(checks, check)
+		}
+	}
+	if nodes, ok := m.checkNodes(conn); ok {
+		checks = append(checks, nodes)
+	}
+	return checks
+}
+
+// checkNodes compares the cluster's node count to the configured size, or
+// to the most nodes seen so far when none is configured
+func (m *Monitor) checkNodes(conn *db.Connection) (db.HealthCheck, bool) {
+	status, err := conn.GetClusterStatus()
+	if err != nil || status.Type == db.ClusterTypeNone || status.NodeCount == 0 {
+		return db.HealthCheck{}, false
+	}
+
+	m.mu.Lock()
+	if status.NodeCount > m.peakNodes {
+		m.peakNodes = status.NodeCount
+	}
+	expected := m.peakNodes
+	m.mu.Unlock()
+	if m.cfg.ClusterSize > 0 {
+		expected = m.cfg.ClusterSize
+	}
+
+	check := db.HealthCheck{Name: "nodes"}
+	check.Message = fmt.Sprintf("%d of %d nodes", status.NodeCount, expected)
+	switch {
+	case status.NodeCount >= expected:
+	case status.NodeCount <= 1:
+		check.Status = db.HealthCritical
+	default:
+		check.Status = db.HealthWarning
+	}
+	return check, true
+}
+
+// Poll evaluates the server and sends the resulting events
+func (m *Monitor) Poll(conn *db.Connection) ([]Event, error) {
+	events := m.Evaluate(conn)
+	err := m.Send(events)
+	m.mu.Lock()
+	m.lastErr = err
+	m.mu.Unlock()
+	return events, err
+}
+
+// Send delivers events to every configured webhook and email address,
+// returning the first delivery error
+func (m *Monitor) Send(events []Event) error {
+	var firstErr error
+	for _, event := range events {
+		if err := deliver(m.cfg, event); err != nil {
+			logging.Warn("Alert delivery failed: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Start polls conn in the background; calling it on a running monitor is a no-op
+func (m *Monitor) Start(conn *db.Connection) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running {
+		return
+	}
+	m.running = true
+	m.stop = make(chan struct{})
+	go m.run(conn, m.stop)
+}
+
+// Stop ends background polling, keeping the alert state
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running {
+		return
+	}
+	m.running = false
+	close(m.stop)
+}
+
+func (m *Monitor) run(conn *db.Connection, stop chan struct{}) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.Poll(conn)
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Active returns the checks currently in a problem state, sorted by name,
+// as the events that raised them
+func (m *Monitor) Active() []Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var active []Event
+	for name, state := range m.states {
+		if state.status != db.HealthOK {
+			active = append(active, Event{
+				Server:  m.server,
+				Check:   name,
+				Status:  state.status,
+				Message: state.message,
+				Time:    state.since,
+			})
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].Check < active[j].Check })
+	return active
+}
+
+// LastPoll returns when the server was last checked, zero before the first poll
+func (m *Monitor) LastPoll() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastPoll
+}
+
+// Err returns the delivery error from the most recent poll, if any
+func (m *Monitor) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastErr
+}
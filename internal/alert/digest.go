@@ -0,0 +1,97 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package alert
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/webhook"
+)
+
+// SendDigest delivers a health digest to the alert webhooks and email
+// addresses, returning the first delivery error. The email goes to to when
+// given instead of the alert recipients, as HTML with a Markdown
+// alternative, or as Markdown only.
+func SendDigest(cfg *Config, digest *db.HealthDigest, format string, to []string) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if len(to) > 0 && cfg.Email == nil {
+		return fmt.Errorf("report recipients need an smtp server: add email under alerts in the config file")
+	}
+
+	var firstErr error
+	for _, hook := range cfg.Webhooks {
+		if err := sendDigestWebhook(hook, digest, format); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if cfg.Email != nil {
+		email := *cfg.Email
+		if len(to) > 0 {
+			email.To = to
+		}
+		if err := sendDigestEmail(&email, digest, format); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// sendDigestWebhook posts the digest as JSON; the text field carries the
+// Markdown for chat integrations
+func sendDigestWebhook(hook webhook.Webhook, digest *db.HealthDigest, format string) error {
+	payload := map[string]interface{}{
+		"text":     digest.Subject() + "\n\n" + digest.Markdown(),
+		"server":   digest.Server,
+		"status":   digest.Status().String(),
+		"subject":  digest.Subject(),
+		"markdown": digest.Markdown(),
+		"time":     digest.Time.Format(time.RFC3339),
+	}
+	if format == db.DigestFormatHTML {
+		payload["html"] = digest.HTML()
+	}
+	return webhook.PostJSON(hook, "report", payload)
+}
+
+// sendDigestEmail mails the digest as Markdown, or as HTML with a Markdown
+// alternative
+func sendDigestEmail(e *Email, digest *db.HealthDigest, format string) error {
+	if format != db.DigestFormatHTML {
+		return sendMail(e, digest.Subject(), digest.Time, "text/plain; charset=utf-8", digest.Markdown())
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", digest.Markdown()},
+		{"text/html; charset=utf-8", digest.HTML()},
+	} {
+		w, _ := parts.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		w.Write([]byte(crlf(part.content)))
+	}
+	parts.Close()
+	return sendMail(e, digest.Subject(), digest.Time, "multipart/alternative; boundary="+parts.Boundary(), body.String())
+}
@@ -0,0 +1,98 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package alert
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/webhook"
+)
+
+// deliver sends one event to every destination, returning the first error
+func deliver(cfg *Config, event Event) error {
+	var firstErr error
+	for _, hook := range cfg.Webhooks {
+		if err := sendWebhook(hook, event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if cfg.Email != nil {
+		if err := sendEmail(cfg.Email, event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// sendWebhook posts the event as JSON. The text field makes the payload
+// readable as-is by Slack and Mattermost incoming webhooks
+func sendWebhook(hook webhook.Webhook, event Event) error {
+	return webhook.PostJSON(hook, "alert", map[string]interface{}{
+		"text":      event.Subject() + ": " + event.Message,
+		"server":    event.Server,
+		"check":     event.Check,
+		"status":    event.Status.String(),
+		"previous":  event.Previous.String(),
+		"recovered": event.Recovered(),
+		"repeat":    event.Repeat,
+		"message":   event.Message,
+		"time":      event.Time.Format(time.RFC3339),
+	})
+}
+
+// sendEmail mails the event through the configured SMTP server
+func sendEmail(e *Email, event Event) error {
+	return sendMail(e, event.Subject(), event.Time, "text/plain; charset=utf-8", event.Text())
+}
+
+// sendMail mails a message through the configured SMTP server, logging in
+// when a username is set. Line breaks in a plain text body become CRLF.
+func sendMail(e *Email, subject string, date time.Time, contentType, body string) error {
+	var auth smtp.Auth
+	if e.Username != "" {
+		host, _, _ := net.SplitHostPort(e.SMTP)
+		auth = smtp.PlainAuth("", e.Username, e.Password, host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: %s\r\n\r\n", contentType)
+	if strings.HasPrefix(contentType, "text/") {
+		body = crlf(body)
+	}
+	msg.WriteString(body)
+
+	if err := smtp.SendMail(e.SMTP, auth, e.From, e.To, []byte(msg.String())); err != nil {
+		return fmt.Errorf("email failed: %w", err)
+	}
+	return nil
+}
+
+// crlf turns the line breaks of text into the CRLF mail wants
+func crlf(text string) string {
+	return strings.ReplaceAll(text, "\n", "\r\n")
+}
@@ -60,9 +60,10 @@ const (
 	CompressionZstd CompressionType = "zstd"
 )
 
-// DetectCompression detects compression type from filename
+// DetectCompression detects compression type from filename. A split dump's
+// manifest has the compression of the dump it was split from.
 func DetectCompression(filename string) CompressionType {
-	lower := strings.ToLower(filename)
+	lower := strings.ToLower(SplitDumpName(filename))
 	switch {
 	case strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".gzip"):
 		return CompressionGzip
@@ -77,13 +78,14 @@ func DetectCompression(filename string) CompressionType {
 
 // BufferedReader wraps an io.Reader with buffering and optional decompression
 type BufferedReader struct {
-	file       *os.File
+	file       io.ReadCloser
 	decompressor io.ReadCloser
 	reader     *bufio.Reader
 	bufferSize int
 }
 
-// NewBufferedReader creates a new buffered reader with optional decompression
+// NewBufferedReader creates a new buffered reader with optional decompression.
+// Given a split dump's manifest, it reads the parts in order.
 func NewBufferedReader(path string, bufferSize int) (*BufferedReader, error) {
 	if bufferSize <= 0 {
 		bufferSize = DefaultBufferSize
@@ -91,9 +93,9 @@ func NewBufferedReader(path string, bufferSize int) (*BufferedReader, error) {
 
 	logging.Debug("Opening file for reading: %s (buffer: %d bytes)", path, bufferSize)
 
-	file, err := os.Open(path)
+	file, _, err := OpenFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return nil, err
 	}
 
 	br := &BufferedReader{
@@ -581,8 +583,15 @@ func RecommendedBufferSize(fileSize int64) int {
 	}
 }
 
-// GetFileSize returns the size of a file
+// GetFileSize returns the size of a file, or of all the parts of a split dump
 func GetFileSize(path string) (int64, error) {
+	if IsSplitManifest(path) {
+		m, err := ReadSplitManifest(path)
+		if err != nil {
+			return 0, err
+		}
+		return m.Size, nil
+	}
 	info, err := os.Stat(path)
 	if err != nil {
 		return 0, err
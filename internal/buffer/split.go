@@ -0,0 +1,429 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package buffer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SplitManifestSuffix ends the name of a split dump's manifest, which is the
+// name the dump had before it was split with the suffix added
+const SplitManifestSuffix = ".parts.json"
+
+// SplitManifest lists the parts a dump was split into, in order
+type SplitManifest struct {
+	File     string      `json:"file"`      // Name of the dump before it was split
+	Size     int64       `json:"size"`      // Bytes in all parts together
+	PartSize int64       `json:"part_size"` // Most bytes in one part
+	SHA256   string      `json:"sha256"`    // Of the whole dump
+	Created  time.Time   `json:"created"`
+	Parts    []SplitPart `json:"parts"`
+
+	dir string // Where the manifest and its parts are
+}
+
+// SplitPart is one numbered part of a split dump
+type SplitPart struct {
+	Name   string `json:"name"` // File name, in the manifest's directory
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// IsSplitManifest reports whether a path names a split dump's manifest
+func IsSplitManifest(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), SplitManifestSuffix)
+}
+
+// SplitDumpName returns the name of the dump a split manifest stands for, or
+// the path unchanged when it isn't a manifest
+func SplitDumpName(path string) string {
+	if IsSplitManifest(path) {
+		return path[:len(path)-len(SplitManifestSuffix)]
+	}
+	return path
+}
+
+// SplitWriter writes a dump straight into numbered parts of at most the
+// part size (path.001, path.002, ...), starting the next part once one is
+// full, so the whole dump never needs to fit on the disk next to its parts.
+// Close writes the manifest to path.parts.json.
+type SplitWriter struct {
+	m     *SplitManifest
+	file  *os.File  // Part being written
+	part  SplitPart // Its name and size so far
+	hash  hash.Hash // Of the part being written
+	whole hash.Hash // Of everything written
+	done  bool
+}
+
+// CreateSplit starts a split dump at path with its first part
+func CreateSplit(path string, partSize int64) (*SplitWriter, error) {
+	if partSize <= 0 {
+		return nil, fmt.Errorf("part size must be positive")
+	}
+	w := &SplitWriter{
+		m: &SplitManifest{
+			File:     filepath.Base(path),
+			PartSize: partSize,
+			Created:  time.Now(),
+			dir:      filepath.Dir(path),
+		},
+		whole: sha256.New(),
+	}
+	if err := w.nextPart(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// nextPart creates the part after the ones written so far
+func (w *SplitWriter) nextPart() error {
+	name := fmt.Sprintf("%s.%03d", w.m.File, len(w.m.Parts)+1)
+	f, err := os.Create(filepath.Join(w.m.dir, name))
+	if err != nil {
+		return fmt.Errorf("failed to create part: %w", err)
+	}
+	w.file, w.part, w.hash = f, SplitPart{Name: name}, sha256.New()
+	return nil
+}
+
+// finishPart closes the part being written and adds it to the manifest
+func (w *SplitWriter) finishPart() error {
+	err := w.file.Close()
+	w.file = nil
+	w.part.SHA256 = hex.EncodeToString(w.hash.Sum(nil))
+	w.m.Parts = append(w.m.Parts, w.part)
+	w.m.Size += w.part.Size
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", w.part.Name, err)
+	}
+	return nil
+}
+
+func (w *SplitWriter) Write(p []byte) (int, error) {
+	if w.done {
+		return 0, fmt.Errorf("split dump %s is closed", w.m.File)
+	}
+	written := 0
+	for len(p) > 0 {
+		// A full part is only followed by another once there is more to write
+		if w.part.Size == w.m.PartSize {
+			if err := w.finishPart(); err != nil {
+				return written, err
+			}
+			if err := w.nextPart(); err != nil {
+				return written, err
+			}
+		}
+		chunk := p[:min(int64(len(p)), w.m.PartSize-w.part.Size)]
+		n, err := w.file.Write(chunk)
+		w.hash.Write(chunk[:n])
+		w.whole.Write(chunk[:n])
+		w.part.Size += int64(n)
+		written += n
+		p = p[n:]
+		if err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", w.part.Name, err)
+		}
+	}
+	return written, nil
+}
+
+// Close finishes the last part and writes the manifest
+func (w *SplitWriter) Close() error {
+	if w.done {
+		return nil
+	}
+	w.done = true
+	if err := w.finishPart(); err != nil {
+		return err
+	}
+	w.m.SHA256 = hex.EncodeToString(w.whole.Sum(nil))
+
+	data, err := json.MarshalIndent(w.m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(w.m.ManifestPath(), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Abort stops writing and removes the parts written so far
+func (w *SplitWriter) Abort() error {
+	if !w.done {
+		w.done = true
+		w.finishPart()
+	}
+	return w.m.Remove()
+}
+
+// Manifest returns the manifest of the parts, complete once closed
+func (w *SplitWriter) Manifest() *SplitManifest {
+	return w.m
+}
+
+// SplitFile cuts a file into numbered parts of at most partSize bytes next to
+// it (path.001, path.002, ...), writes their manifest to path.parts.json and
+// removes the file. Dumps YSM writes itself go straight into parts with
+// CreateSplit; this is for files other programs wrote.
+func SplitFile(path string, partSize int64) (*SplitManifest, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+
+	w, err := CreateSplit(path, partSize)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		w.Abort()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		w.Abort()
+		return nil, err
+	}
+	if err := src.Close(); err != nil {
+		return w.Manifest(), err
+	}
+	return w.Manifest(), os.Remove(path)
+}
+
+// Remove deletes the parts and the manifest
+func (m *SplitManifest) Remove() error {
+	var errs []error
+	for _, part := range m.Parts {
+		if err := os.Remove(m.PartPath(part)); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	if err := os.Remove(m.ManifestPath()); err != nil && !os.IsNotExist(err) {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// ReadSplitManifest reads a split dump's manifest
+func ReadSplitManifest(path string) (*SplitManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read split manifest: %w", err)
+	}
+	var m SplitManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid split manifest %s: %w", filepath.Base(path), err)
+	}
+	if len(m.Parts) == 0 {
+		return nil, fmt.Errorf("split manifest %s lists no parts", filepath.Base(path))
+	}
+
+	var total int64
+	for _, part := range m.Parts {
+		// Parts are always next to the manifest
+		if part.Name == "" || part.Name != filepath.Base(part.Name) || part.Name == ".." {
+			return nil, fmt.Errorf("split manifest %s has an invalid part name %q", filepath.Base(path), part.Name)
+		}
+		total += part.Size
+	}
+	if total != m.Size {
+		return nil, fmt.Errorf("split manifest %s: parts add up to %d bytes, not %d", filepath.Base(path), total, m.Size)
+	}
+	m.dir = filepath.Dir(path)
+	return &m, nil
+}
+
+// ManifestPath returns where the manifest is
+func (m *SplitManifest) ManifestPath() string {
+	return filepath.Join(m.dir, m.File+SplitManifestSuffix)
+}
+
+// PartPath returns where a part is
+func (m *SplitManifest) PartPath(part SplitPart) string {
+	return filepath.Join(m.dir, part.Name)
+}
+
+// Check makes sure every part is there with the size the manifest lists
+func (m *SplitManifest) Check() error {
+	for _, part := range m.Parts {
+		info, err := os.Stat(m.PartPath(part))
+		if err != nil {
+			return fmt.Errorf("part %s is missing: %w", part.Name, err)
+		}
+		if info.Size() != part.Size {
+			return fmt.Errorf("part %s is %d bytes, the manifest says %d", part.Name, info.Size(), part.Size)
+		}
+	}
+	return nil
+}
+
+// Open reads the parts in order as one stream. Each part's checksum is
+// checked once it has been read, and the whole dump's at the end, failing
+// the read on a mismatch.
+func (m *SplitManifest) Open() (io.ReadCloser, error) {
+	if err := m.Check(); err != nil {
+		return nil, err
+	}
+	return &splitReader{m: m, whole: sha256.New()}, nil
+}
+
+// Join writes the parts in order to dest, reassembling the dump
+func (m *SplitManifest) Join(dest string) error {
+	r, err := m.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dest)
+		return fmt.Errorf("failed to join parts: %w", err)
+	}
+	return nil
+}
+
+// splitReader reads a split dump's parts one after another
+type splitReader struct {
+	m    *SplitManifest
+	next int      // Index of the part to open after the current one
+	file *os.File // Part being read (nil between parts)
+	hash hash.Hash
+	read int64
+
+	whole hash.Hash // Of every part read so far
+}
+
+func (r *splitReader) Read(p []byte) (int, error) {
+	for {
+		if r.file == nil {
+			if r.next == len(r.m.Parts) {
+				if err := r.checkWhole(); err != nil {
+					return 0, err
+				}
+				return 0, io.EOF
+			}
+			f, err := os.Open(r.m.PartPath(r.m.Parts[r.next]))
+			if err != nil {
+				return 0, fmt.Errorf("failed to open part: %w", err)
+			}
+			r.file, r.hash, r.read = f, sha256.New(), 0
+			r.next++
+		}
+
+		n, err := r.file.Read(p)
+		r.hash.Write(p[:n])
+		r.whole.Write(p[:n])
+		r.read += int64(n)
+		if err == io.EOF {
+			if verr := r.finishPart(); verr != nil {
+				return n, verr
+			}
+			if n == 0 {
+				continue
+			}
+			return n, nil
+		}
+		return n, err
+	}
+}
+
+// finishPart closes the part just read and checks it against the manifest
+func (r *splitReader) finishPart() error {
+	part := r.m.Parts[r.next-1]
+	r.file.Close()
+	r.file = nil
+	if r.read != part.Size {
+		return fmt.Errorf("part %s is %d bytes, the manifest says %d", part.Name, r.read, part.Size)
+	}
+	if sum := hex.EncodeToString(r.hash.Sum(nil)); part.SHA256 != "" && sum != part.SHA256 {
+		return fmt.Errorf("part %s is damaged: its checksum doesn't match the manifest", part.Name)
+	}
+	return nil
+}
+
+// checkWhole compares the dump read with the manifest's checksum of it,
+// which catches parts that were swapped or came from another split
+func (r *splitReader) checkWhole() error {
+	if r.m.SHA256 == "" || r.whole == nil {
+		return nil
+	}
+	sum := hex.EncodeToString(r.whole.Sum(nil))
+	r.whole = nil // Checked once, however often EOF is read
+	if sum != r.m.SHA256 {
+		return fmt.Errorf("split dump %s is damaged: its checksum doesn't match the manifest", r.m.File)
+	}
+	return nil
+}
+
+func (r *splitReader) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// OpenFile opens a file for reading, or a split dump's parts in order when
+// given its manifest, and returns the number of bytes it holds
+func OpenFile(path string) (io.ReadCloser, int64, error) {
+	if IsSplitManifest(path) {
+		m, err := ReadSplitManifest(path)
+		if err != nil {
+			return nil, 0, err
+		}
+		r, err := m.Open()
+		if err != nil {
+			return nil, 0, err
+		}
+		return r, m.Size, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("failed to get file stats: %w", err)
+	}
+	return file, info.Size(), nil
+}
@@ -0,0 +1,141 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/alert"
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var alertsInterval time.Duration
+
+var alertsCmd = &cobra.Command{
+	Use:   "alerts",
+	Short: "Send webhook and email alerts on cluster health changes",
+	Long: `Send webhook and email alerts when the server's health changes.
+
+Alerts are configured in the alerts section of the config file. A check
+alerts when its status changes (replication stops, lag crosses a threshold,
+the cluster loses nodes or the server stops answering) and again when it
+recovers. Unchanged problems are only re-sent when repeat is set.
+
+The TUI also sends alerts while the cluster view or dashboard is open.
+
+Subcommands:
+  watch  - Poll the server and send alerts until stopped
+  test   - Send a test alert to every destination`,
+}
+
+var alertsWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Poll the server and send alerts until stopped",
+	Long: `Poll the server and send alerts until stopped.
+
+Examples:
+  ysm alerts watch --profile prod
+  ysm alerts watch --profile galera --interval 10s`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		monitor, err := alert.NewMonitor(cfg.Alerts, alertServerName())
+		if err != nil {
+			return err
+		}
+		interval := monitor.Interval()
+		if alertsInterval > 0 {
+			interval = alertsInterval
+		}
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		fmt.Printf("Watching %s every %s (Ctrl+C to stop)\n", alertServerName(), interval)
+		for {
+			events, err := monitor.Poll(conn)
+			for _, event := range events {
+				fmt.Printf("[%s] %s: %s\n", event.Time.Format("15:04:05"), event.Subject(), event.Message)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-sig:
+				return nil
+			}
+		}
+	},
+}
+
+var alertsTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test alert to every destination",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		monitor, err := alert.NewMonitor(cfg.Alerts, alertServerName())
+		if err != nil {
+			return err
+		}
+		event := alert.Event{
+			Server:   alertServerName(),
+			Check:    "test",
+			Status:   db.HealthWarning,
+			Previous: db.HealthOK,
+			Message:  "Test alert from ysm alerts test",
+			Time:     time.Now(),
+		}
+		if err := monitor.Send([]alert.Event{event}); err != nil {
+			return err
+		}
+		fmt.Println("Test alert sent.")
+		return nil
+	},
+}
+
+// alertServerName names the server in alerts: the profile (or default
+// profile) connected through, or the host
+func alertServerName() string {
+	if profile != "" {
+		return profile
+	}
+	if cfg != nil && cfg.DefaultProfile != "" {
+		return cfg.DefaultProfile
+	}
+	return host
+}
+
+func init() {
+	alertsWatchCmd.Flags().DurationVar(&alertsInterval, "interval", 0, "Polling interval (default: alerts interval from the config, or 30s)")
+	alertsCmd.AddCommand(alertsWatchCmd)
+	alertsCmd.AddCommand(alertsTestCmd)
+}
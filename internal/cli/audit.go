@@ -0,0 +1,120 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditSince      time.Duration
+	auditActions    []string
+	auditThisServer bool
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the log of destructive actions run through YSM",
+	Long: `Show the audit log of destructive actions performed through YSM, from the
+command line or the TUI: dropped databases and users, revoked privileges,
+restores and imports. Each entry records when it happened, the OS user and
+machine YSM ran on, the server and database user, what was acted on, and
+the error when it failed.
+
+The log is kept in audit.jsonl next to the backups directory, one JSON
+object per line. YSM only ever appends to it, so on a shared DBA machine it
+answers who dropped what and when.
+
+Examples:
+  ysm audit
+  ysm audit --since 168h --action drop_database,drop_user
+  ysm audit --profile prod --this-server --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, action := range auditActions {
+			if !slices.Contains(db.AuditActions, action) {
+				return fmt.Errorf("invalid --action %q: use %s", action, strings.Join(db.AuditActions, ", "))
+			}
+		}
+
+		var server string
+		if auditThisServer {
+			connCfg, err := getConnectionConfig()
+			if err != nil {
+				return err
+			}
+			server = connCfg.ServerKey()
+		}
+
+		all, err := db.LoadAuditLog()
+		if err != nil {
+			return err
+		}
+		entries := []db.AuditEntry{}
+		for _, e := range all {
+			if auditSince > 0 && e.Time.Before(time.Now().Add(-auditSince)) {
+				continue
+			}
+			if len(auditActions) > 0 && !slices.Contains(auditActions, e.Action) {
+				continue
+			}
+			if server != "" && e.Server != server {
+				continue
+			}
+			entries = append(entries, e)
+		}
+
+		if structuredOutput() {
+			return printStructured(entries)
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No destructive actions recorded.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tBY\tSERVER\tACTION\tTARGET")
+		fmt.Fprintln(w, "----\t--\t------\t------\t------")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s@%s\t%s (%s)\t%s\t%s\n", e.Time.Format("2006-01-02 15:04:05"), e.OSUser, e.Machine, e.Server, e.DBUser, e.Action, e.Target)
+			if e.Detail != "" {
+				fmt.Fprintf(w, "\t\t\t\t  %s\n", e.Detail)
+			}
+			if e.Failed() {
+				fmt.Fprintf(w, "\t\t\t\t  Failed: %s\n", e.Error)
+			}
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	auditCmd.Flags().DurationVar(&auditSince, "since", 0, "Only show actions this recent, e.g. 24h")
+	auditCmd.Flags().StringSliceVar(&auditActions, "action", nil, "Only show these actions: drop_database, drop_user, revoke, restore, import")
+	auditCmd.Flags().BoolVar(&auditThisServer, "this-server", false, "Only show actions on the server of the profile or connection flags")
+}
@@ -19,22 +19,46 @@
 package cli
 
 import (
+	"bufio"
+	"cmp"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/plugin"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/blubskye/yandere_sql_manager/internal/webhook"
 	"github.com/spf13/cobra"
 )
 
 var (
 	backupOutputDir   string
 	backupCompression string
+	backupLevel       int
+	benchRows         int
+	benchMinSpeed     float64
+	benchSave         bool
 	backupDescription string
 	backupParallel    int
 	restoreDropExist  bool
 	restoreRename     []string
+	restoreTarget     string
+	restoreForce      bool
+	restoreOwner      string
+	restoreReassign   []string
+	restoreGrantRole  string
+	restoreGrantSet   string
+	restoreAnalyze    bool
+	restoreBeforeSQL  []string
+	restoreAfterSQL   []string
+	pruneKeep         int
+	pruneOlderThan    string
+	pruneDryRun       bool
+	assumeYes         bool
 )
 
 var backupCmd = &cobra.Command{
@@ -47,7 +71,12 @@ Subcommands:
   list    - List all backups
   show    - Show backup details
   restore - Restore a backup
-  delete  - Delete a backup`,
+  check   - Check whether a backup can be restored
+  delete  - Delete a backup
+  prune   - Delete old backups by count or age
+  verify  - Verify backup checksums
+  bench   - Benchmark compression settings on sampled data
+  runbook - Write a disaster recovery runbook for a profile`,
 }
 
 var backupCreateCmd = &cobra.Command{
@@ -59,9 +88,11 @@ Examples:
   ysm backup create                           # Backup all databases
   ysm backup create mydb1 mydb2               # Backup specific databases
   ysm backup create --compress zstd           # Use zstd compression
+  ysm backup create --compress xz --level 9   # Use xz at level 9
   ysm backup create -o /path/to/backups       # Custom output directory
   ysm backup create --parallel 4              # Backup 4 databases in parallel
-  ysm backup create --parallel -1             # Auto-detect parallelism (CPU count)`,
+  ysm backup create --parallel -1             # Auto-detect parallelism (CPU count)
+  ysm backup create mydb --profile prod --json  # Print the backup's metadata as JSON`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		conn, err := connect()
 		if err != nil {
@@ -69,8 +100,19 @@ Examples:
 		}
 		defer conn.Close()
 
+		// The profile's compression (see backup bench --save) applies when none is given
+		compressionName, level := backupCompression, backupLevel
+		if compressionName == "" && profile != "" {
+			if p, err := cfg.GetProfile(profile); err == nil && p.Compression != "" {
+				compressionName = p.Compression
+				if level == 0 {
+					level = p.CompressionLevel
+				}
+			}
+		}
+
 		compression := db.CompressionNone
-		switch strings.ToLower(backupCompression) {
+		switch strings.ToLower(compressionName) {
 		case "gzip", "gz":
 			compression = db.CompressionGzip
 		case "xz":
@@ -79,32 +121,60 @@ Examples:
 			compression = db.CompressionZstd
 		}
 
+		bar := newProgressPrinter("Backing up", progress.Rows, 0)
 		opts := db.BackupOptions{
-			OutputDir:   backupOutputDir,
-			Databases:   args,
-			Compression: compression,
-			Description: backupDescription,
-			Profile:     profile,
-			Parallel:    backupParallel,
-			OnProgress: func(database string, dbNum, totalDBs int) {
-				fmt.Printf("Backing up %s (%d/%d)...\n", database, dbNum, totalDBs)
+			OutputDir:        backupOutputDir,
+			Databases:        args,
+			Compression:      compression,
+			CompressionLevel: level,
+			Description:      backupDescription,
+			Profile:          profile,
+			Parallel:         backupParallel,
+			OnProgress: func(database string, dbNum, totalDBs int, rows, bytes int64) {
+				bar.SetCurrent(database, dbNum, totalDBs)
+				bar.Set(rows)
+				bar.SetBytes(bytes)
+				bar.refresh()
 			},
 		}
+		// Estimated from table statistics, so the ETA is approximate
+		bar.SetTotal(conn.BackupRowEstimate(opts))
 
+		start := time.Now()
 		metadata, err := conn.CreateBackup(opts)
+		bar.finish()
+		event := webhook.BackupEvent(metadata, time.Since(start), err)
+		recordTimeline(event)
+		notifyWebhooks(event)
 		if err != nil {
 			return err
 		}
 
-		fmt.Println()
-		fmt.Printf("Backup created successfully!\n")
-		fmt.Printf("  ID:        %s\n", metadata.ID)
-		fmt.Printf("  Databases: %d\n", len(metadata.Databases))
-		fmt.Printf("  Size:      %s\n", db.FormatSize(metadata.TotalSize))
-		if metadata.Compression != "" {
-			fmt.Printf("  Compressed: %s\n", metadata.Compression)
+		if !structuredOutput() {
+			fmt.Println()
+			fmt.Printf("Backup created successfully!\n")
+			fmt.Printf("  ID:        %s\n", metadata.ID)
+			fmt.Printf("  Databases: %d\n", len(metadata.Databases))
+			fmt.Printf("  Size:      %s\n", db.FormatSize(metadata.TotalSize))
+			if metadata.Compression != "" {
+				fmt.Printf("  Compressed: %s\n", metadata.Compression)
+			}
 		}
 
+		// Hand the backup to any post-backup plugins
+		if reg, err := plugin.Discover(); err == nil {
+			messages, errs := reg.RunPostBackup(conn, metadata, backupOutputDir)
+			for _, m := range messages {
+				infof("  Plugin:    %s\n", m)
+			}
+			for _, e := range errs {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", e)
+			}
+		}
+
+		if structuredOutput() {
+			return printStructured(metadata)
+		}
 		return nil
 	},
 }
@@ -118,6 +188,13 @@ var backupListCmd = &cobra.Command{
 			return err
 		}
 
+		if structuredOutput() {
+			if backups == nil {
+				backups = []db.BackupMetadata{}
+			}
+			return printStructured(backups)
+		}
+
 		if len(backups) == 0 {
 			fmt.Println("No backups found.")
 			return nil
@@ -154,6 +231,10 @@ var backupShowCmd = &cobra.Command{
 			return err
 		}
 
+		if structuredOutput() {
+			return printStructured(metadata)
+		}
+
 		fmt.Printf("Backup: %s\n", metadata.ID)
 		fmt.Printf("  Timestamp:      %s\n", metadata.Timestamp.Format("2006-01-02 15:04:05"))
 		fmt.Printf("  Server Type:    %s\n", metadata.ServerType)
@@ -168,6 +249,9 @@ var backupShowCmd = &cobra.Command{
 		if metadata.Profile != "" {
 			fmt.Printf("  Profile:        %s\n", metadata.Profile)
 		}
+		if !metadata.VerifiedAt.IsZero() {
+			fmt.Printf("  Verified:       %s\n", metadata.VerifiedAt.Format("2006-01-02 15:04:05"))
+		}
 
 		fmt.Println()
 		fmt.Println("Databases:")
@@ -192,69 +276,224 @@ var backupRestoreCmd = &cobra.Command{
 	Short: "Restore a backup",
 	Long: `Restore a backup to the database server.
 
+The backup is checked against the server first (see 'ysm backup check') and
+the restore stops if anything blocks it, unless --force is given.
+
 Examples:
   ysm backup restore 20240101-120000              # Restore all databases
   ysm backup restore 20240101-120000 mydb         # Restore specific database
   ysm backup restore 20240101-120000 --drop       # Drop existing before restore
-  ysm backup restore 20240101-120000 --rename old:new  # Rename during restore`,
+  ysm backup restore 20240101-120000 --drop --safety-backup  # Back up what is dropped first
+  ysm backup restore 20240101-120000 --rename old:new  # Rename during restore
+  ysm backup restore 20240101-120000 --target-profile dr  # Restore to another server
+  ysm backup restore 20240101-120000 --owner app --grant-role app_ro  # Fix PostgreSQL ownership
+  ysm backup restore 20240101-120000 --after-sql rebuild-triggers.sql  # Run SQL on the target afterwards`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		conn, err := connect()
+		// Backups are read from disk, so only the target server is connected to
+		conn, err := connectProfile(restoreTarget)
 		if err != nil {
 			return err
 		}
 		defer conn.Close()
 
+		if restoreTarget != "" {
+			infof("Restoring to profile '%s' (%s)\n", restoreTarget, conn.Config.Host)
+		}
+
 		backupID := args[0]
 		databases := args[1:]
 
-		// Parse rename map
-		renameMap := make(map[string]string)
-		for _, r := range restoreRename {
-			parts := strings.SplitN(r, ":", 2)
-			if len(parts) == 2 {
-				renameMap[parts[0]] = parts[1]
-			}
+		opts, err := restoreOptions(backupID, databases)
+		if err != nil {
+			return err
 		}
+		opts.DisableForeignKeys = true
+		opts.Analyze = restoreAnalyze
+		var fromProfile db.OperationScripts
+		if p := connectedProfile(restoreTarget); p != nil {
+			fromProfile = p.Scripts.Restore
+		}
+		opts.Scripts = operationScripts(fromProfile, restoreBeforeSQL, restoreAfterSQL)
 
-		// Confirm if dropping existing
-		if restoreDropExist {
-			fmt.Printf("WARNING: This will DROP existing databases before restoring.\n")
-			fmt.Printf("Are you sure you want to continue? [y/N]: ")
-			var confirm string
-			fmt.Scanln(&confirm)
-			if strings.ToLower(confirm) != "y" && strings.ToLower(confirm) != "yes" {
-				fmt.Println("Aborted.")
+		report, err := conn.CheckRestore(opts)
+		if err != nil {
+			return fmt.Errorf("pre-restore check failed: %w", err)
+		}
+		if !report.Go() || report.Count(db.RestoreCheckWarning) > 0 {
+			printRestoreReport(report)
+			infof("\n")
+		}
+		if !report.Go() && !restoreForce {
+			return fmt.Errorf("restore blocked by %d problem(s), fix them or use --force", report.Count(db.RestoreCheckBlocker))
+		}
+
+		// Every database about to be dropped has to be named
+		if len(report.Dropped) > 0 && !assumeYes {
+			infof("WARNING: This will DROP %s on %s before restoring.\n", strings.Join(report.Dropped, ", "), conn.Config.Host)
+			if !confirmTyped("drop", report.Dropped) {
 				return nil
 			}
 		}
 
-		opts := db.RestoreOptions{
-			BackupID:           backupID,
-			Databases:          databases,
-			RenameMap:          renameMap,
-			DropExisting:       restoreDropExist,
-			CreateIfNotExists:  true,
-			DisableForeignKeys: true,
-			OnProgress: func(database string, dbNum, totalDBs int, percent float64) {
-				if percent > 0 {
-					fmt.Printf("\rRestoring %s (%d/%d): %.0f%%", database, dbNum, totalDBs, percent)
-				} else {
-					fmt.Printf("Restoring %s (%d/%d)...\n", database, dbNum, totalDBs)
-				}
-			},
+		enabled, expire, err := safetyBackupSettings(cmd)
+		if err != nil {
+			return err
+		}
+		if enabled {
+			err := takeSafetyBackup(conn, expire, db.SafetyBackupOptions{
+				Operation: "restore of " + backupID,
+				Databases: report.Dropped,
+				Tables:    report.Replaced,
+				Profile:   cmp.Or(restoreTarget, profile),
+			})
+			if err != nil {
+				return err
+			}
 		}
 
-		if err := conn.RestoreBackup(opts); err != nil {
+		bar := newProgressPrinter("Restoring", progress.Percent, 0)
+		opts.OnProgress = func(database string, dbNum, totalDBs int, percent float64, rows, bytes int64) {
+			// Overall progress in percentage points across all databases
+			bar.SetTotal(int64(totalDBs) * 100)
+			bar.Set(int64(dbNum-1)*100 + int64(percent))
+			bar.SetRows(rows)
+			bar.SetBytes(bytes)
+			bar.SetCurrent(database, dbNum, totalDBs)
+			bar.refresh()
+		}
+
+		start := time.Now()
+		stats, err := conn.RestoreBackupWithStats(opts)
+		bar.finish()
+		event := webhook.RestoreEvent(backupID, time.Since(start), err)
+		event.Server = restoreTarget
+		recordTimeline(event)
+		notifyWebhooks(event)
+		if err != nil {
+			if !structuredOutput() {
+				printScriptResults(stats.Scripts)
+			}
 			return err
 		}
 
+		if structuredOutput() {
+			var warnings []string
+			for _, check := range report.Checks {
+				if check.Level == db.RestoreCheckWarning {
+					warnings = append(warnings, check.Message)
+				}
+			}
+			return printStructured(restoreJSONResult{
+				BackupID:   backupID,
+				Databases:  databases,
+				Host:       conn.Config.Host,
+				Warnings:   warnings,
+				DurationMs: bar.Snapshot().Elapsed.Milliseconds(),
+				Scripts:    stats.Scripts,
+			})
+		}
+
 		fmt.Println()
 		fmt.Println("Restore completed successfully!")
+		printScriptResults(stats.Scripts)
+		return nil
+	},
+}
+
+var backupCheckCmd = &cobra.Command{
+	Use:   "check <backup-id> [databases...]",
+	Short: "Check whether a backup can be restored",
+	Long: `Analyze a backup against the target server without changing anything:
+tables that already exist, character sets, collations, storage engines and
+extensions the dumps need, the server version gap and the disk space
+required. Ends with a go/no-go verdict.
+
+Takes the same options as restore, so the check matches the restore you plan.
+
+Examples:
+  ysm backup check 20240101-120000
+  ysm backup check 20240101-120000 mydb --rename mydb:mydb_copy
+  ysm backup check 20240101-120000 --target-profile dr`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conn, err := connectProfile(restoreTarget)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		opts, err := restoreOptions(args[0], args[1:])
+		if err != nil {
+			return err
+		}
+		report, err := conn.CheckRestore(opts)
+		if err != nil {
+			return err
+		}
+
+		printRestoreReport(report)
+		if !report.Go() {
+			return fmt.Errorf("backup '%s' can't be restored as is", args[0])
+		}
 		return nil
 	},
 }
 
+// printRestoreReport prints the findings of a pre-restore check and the verdict
+func printRestoreReport(report *db.RestoreReport) {
+	infof("Pre-restore check for backup '%s'", report.BackupID)
+	if report.ServerVersion != "" {
+		infof(" on %s", report.ServerVersion)
+	}
+	infof("\n")
+	for _, check := range report.Checks {
+		infof("  [%-4s] %-9s %s\n", check.Level, check.Category, check.Message)
+	}
+
+	infof("\n")
+	warnings := report.Count(db.RestoreCheckWarning)
+	if report.Go() {
+		infof("GO: ready to restore (%d warning(s))\n", warnings)
+	} else {
+		infof("NO GO: %d blocker(s), %d warning(s)\n", report.Count(db.RestoreCheckBlocker), warnings)
+	}
+}
+
+// restoreOptions builds the options shared by restore and check from the flags
+func restoreOptions(backupID string, databases []string) (db.RestoreOptions, error) {
+	opts := db.RestoreOptions{
+		BackupID:          backupID,
+		Databases:         databases,
+		RenameMap:         parseRenameMap(restoreRename),
+		DropExisting:      restoreDropExist,
+		CreateIfNotExists: true,
+		Owner:             restoreOwner,
+		ReassignOwned:     restoreReassign,
+		GrantRole:         restoreGrantRole,
+	}
+	if restoreGrantSet != "" {
+		set, err := db.ParsePrivilegeSet(restoreGrantSet)
+		if err != nil {
+			return opts, err
+		}
+		opts.Privileges = set
+	}
+	return opts, nil
+}
+
+// parseRenameMap turns old:new pairs into a rename map
+func parseRenameMap(pairs []string) map[string]string {
+	renameMap := make(map[string]string)
+	for _, r := range pairs {
+		parts := strings.SplitN(r, ":", 2)
+		if len(parts) == 2 {
+			renameMap[parts[0]] = parts[1]
+		}
+	}
+	return renameMap
+}
+
 var backupDeleteCmd = &cobra.Command{
 	Use:   "delete <backup-id>",
 	Short: "Delete a backup",
@@ -263,11 +502,7 @@ var backupDeleteCmd = &cobra.Command{
 		backupID := args[0]
 
 		// Confirm deletion
-		fmt.Printf("Are you sure you want to delete backup '%s'? [y/N]: ", backupID)
-		var confirm string
-		fmt.Scanln(&confirm)
-		if strings.ToLower(confirm) != "y" && strings.ToLower(confirm) != "yes" {
-			fmt.Println("Aborted.")
+		if !assumeYes && !confirmPrompt(fmt.Sprintf("Are you sure you want to delete backup '%s'?", backupID)) {
 			return nil
 		}
 
@@ -275,25 +510,378 @@ var backupDeleteCmd = &cobra.Command{
 			return err
 		}
 
+		if structuredOutput() {
+			return printStructured(map[string]string{"deleted": backupID})
+		}
 		fmt.Printf("Backup '%s' deleted successfully.\n", backupID)
 		return nil
 	},
 }
 
+var backupPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old backups by count or age",
+	Long: `Delete backups beyond the newest --keep, optionally only those older than
+--older-than. Limit it to the backups of one database with -d or to those
+made with a profile with --profile. --dry-run lists what would be deleted.
+
+Examples:
+  ysm backup prune --keep 7
+  ysm backup prune --older-than 30d --dry-run
+  ysm backup prune --keep 3 --older-than 7d -d mydb --yes --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := db.PruneOptions{
+			Keep:     pruneKeep,
+			Database: database,
+			Profile:  profile,
+			DryRun:   true,
+		}
+		if pruneOlderThan != "" {
+			age, err := parseAge(pruneOlderThan)
+			if err != nil {
+				return err
+			}
+			opts.OlderThan = age
+		}
+
+		// Find what would go first, so the confirmation lists it
+		candidates, err := db.PruneBackups(opts)
+		if err != nil {
+			return err
+		}
+
+		pruned := candidates
+		if len(candidates) > 0 && !pruneDryRun {
+			for _, b := range candidates {
+				infof("  %s  %s  %s\n", b.ID, b.Timestamp.Format("2006-01-02 15:04"), strings.Join(b.Databases, ", "))
+			}
+			if !assumeYes && !confirmPrompt(fmt.Sprintf("Delete these %d backup(s)?", len(candidates))) {
+				return nil
+			}
+			opts.DryRun = false
+			if pruned, err = db.PruneBackups(opts); err != nil {
+				return err
+			}
+		}
+
+		if structuredOutput() {
+			ids := make([]string, 0, len(pruned))
+			for _, b := range pruned {
+				ids = append(ids, b.ID)
+			}
+			return printStructured(pruneJSONResult{DryRun: pruneDryRun, Backups: ids})
+		}
+
+		if len(pruned) == 0 {
+			fmt.Println("Nothing to prune.")
+			return nil
+		}
+		if pruneDryRun {
+			fmt.Printf("Would delete %d backup(s):\n", len(pruned))
+			for _, b := range pruned {
+				fmt.Printf("  %s  %s  %s\n", b.ID, b.Timestamp.Format("2006-01-02 15:04"), strings.Join(b.Databases, ", "))
+			}
+			return nil
+		}
+		fmt.Printf("Deleted %d backup(s).\n", len(pruned))
+		return nil
+	},
+}
+
+// restoreJSONResult is what backup restore prints with --json
+type restoreJSONResult struct {
+	BackupID   string            `json:"backup_id"`
+	Databases  []string          `json:"databases,omitempty"` // Empty = every database in the backup
+	Host       string            `json:"host"`
+	Warnings   []string          `json:"warnings,omitempty"`
+	DurationMs int64             `json:"duration_ms"`
+	Scripts    []db.ScriptResult `json:"scripts,omitempty"`
+}
+
+// pruneJSONResult is what backup prune prints with --json
+type pruneJSONResult struct {
+	DryRun  bool     `json:"dry_run"`
+	Backups []string `json:"backups"`
+}
+
+// parseAge parses a duration that may also be given in days, e.g. 30d
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q (use e.g. 30d or 12h)", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	age, err := time.ParseDuration(s)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 30d or 12h)", s)
+	}
+	return age, nil
+}
+
+// confirmPrompt asks a yes/no question and reports whether the answer was yes
+func confirmPrompt(question string) bool {
+	infof("%s [y/N]: ", question)
+	var confirm string
+	fmt.Scanln(&confirm)
+	if strings.ToLower(confirm) != "y" && strings.ToLower(confirm) != "yes" {
+		infof("Aborted.\n")
+		return false
+	}
+	return true
+}
+
+// confirmTyped asks for each name to be typed back exactly, like deleting a
+// repository on GitHub, and reports whether every one was
+func confirmTyped(action string, names []string) bool {
+	reader := bufio.NewReader(os.Stdin)
+	for _, name := range names {
+		infof("Type '%s' to %s it: ", name, action)
+		typed, _ := reader.ReadString('\n')
+		if strings.TrimRight(typed, "\r\n") != name {
+			infof("The name didn't match. Aborted.\n")
+			return false
+		}
+	}
+	return true
+}
+
+var backupVerifyCmd = &cobra.Command{
+	Use:   "verify <backup-id>",
+	Short: "Verify backup files against their recorded checksums",
+	Long: `Recompute the SHA-256 of every file in a backup and compare it with the
+checksum recorded when the backup was created. Use this after copying a
+backup to or from remote storage to make sure nothing was corrupted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bad, err := db.VerifyBackup(args[0])
+		if err != nil {
+			return err
+		}
+
+		if structuredOutput() {
+			if err := printStructured(map[string]interface{}{"backup_id": args[0], "ok": len(bad) == 0, "damaged": bad}); err != nil {
+				return err
+			}
+			if len(bad) > 0 {
+				return fmt.Errorf("backup '%s' failed verification: %d file(s) damaged", args[0], len(bad))
+			}
+			return nil
+		}
+
+		if len(bad) > 0 {
+			for _, b := range bad {
+				fmt.Printf("  %s\n", b)
+			}
+			return fmt.Errorf("backup '%s' failed verification: %d file(s) damaged", args[0], len(bad))
+		}
+
+		fmt.Printf("Backup '%s' verified successfully.\n", args[0])
+		return nil
+	},
+}
+
+var backupUploadCmd = &cobra.Command{
+	Use:   "upload <backup-id>",
+	Short: "Run the post-backup plugins for a backup again",
+	Long: `Hand an existing backup to the post-backup plugins again, e.g. after an
+upload failed every retry. Plugins get the resume file their earlier attempts
+kept their progress in, so an interrupted upload continues where it stopped.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		metadata, err := db.GetBackup(args[0])
+		if err != nil {
+			return err
+		}
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		reg, err := plugin.Discover()
+		if err != nil {
+			return err
+		}
+		messages, errs := reg.RunPostBackup(conn, metadata, "")
+		if structuredOutput() {
+			failed := make([]string, len(errs))
+			for i, e := range errs {
+				failed[i] = e.Error()
+			}
+			if err := printStructured(map[string]interface{}{"backup_id": args[0], "messages": messages, "errors": failed}); err != nil {
+				return err
+			}
+		} else {
+			for _, m := range messages {
+				fmt.Printf("  Plugin:    %s\n", m)
+			}
+		}
+		if len(errs) > 0 {
+			for _, e := range errs {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", e)
+			}
+			return fmt.Errorf("%d post-backup plugin(s) failed for backup '%s'", len(errs), args[0])
+		}
+		return nil
+	},
+}
+
+var backupBenchCmd = &cobra.Command{
+	Use:   "bench <database> [tables...]",
+	Short: "Benchmark compression settings on sampled data",
+	Long: `Sample rows from a database (or some of its tables) as the SQL a backup
+would write, then compress the sample with gzip, zstd and xz at several
+levels and report the ratio and speed of each.
+
+The recommended setting is the best ratio among those compressing at least
+--min-speed MB/s. With --save it becomes the profile's default for
+ysm backup create.
+
+Examples:
+  ysm backup bench mydb
+  ysm backup bench mydb orders events --rows 20000
+  ysm backup bench mydb --min-speed 50 --profile prod --save`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if benchSave && profile == "" {
+			return fmt.Errorf("--save needs --profile to know where to save the setting")
+		}
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		bench, err := conn.BenchmarkCompression(db.CompressionBenchOptions{
+			Database:      args[0],
+			Tables:        args[1:],
+			RowsPerTable:  benchRows,
+			MinThroughput: benchMinSpeed,
+			OnProgress: func(step string) {
+				fmt.Printf("\r%s...\033[K", step)
+			},
+		})
+		fmt.Print("\r\033[K")
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Sample: %s of SQL from %d rows in %d tables\n\n",
+			db.FormatSize(bench.SampleBytes), bench.SampledRows, bench.SampledTables)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		header := "COMPRESSION\tLEVEL\tRATIO\tSPEED\tSAMPLE SIZE"
+		if bench.DatabaseSize > 0 {
+			header += "\tEST. BACKUP\tEST. TIME"
+		}
+		fmt.Fprintln(w, header)
+		for i, r := range bench.Results {
+			if r.Err != nil {
+				fmt.Fprintf(w, "%s\t%d\t-\t-\t%v\n", r.Compression, r.Level, r.Err)
+				continue
+			}
+			marker := ""
+			if bench.Recommended == &bench.Results[i] {
+				marker = "  <- recommended"
+			}
+			line := fmt.Sprintf("%s\t%d\t%.2fx\t%.1f MB/s\t%s",
+				r.Compression, r.Level, r.Ratio, r.Throughput, db.FormatSize(r.Size))
+			if bench.DatabaseSize > 0 {
+				line += fmt.Sprintf("\t%s\t%s", db.FormatSize(bench.EstimatedSize(r)), bench.EstimatedDuration(r).Round(time.Second))
+			}
+			fmt.Fprintln(w, line+marker)
+		}
+		w.Flush()
+
+		best := bench.Recommended
+		if best == nil {
+			return fmt.Errorf("every compression setting failed")
+		}
+		fmt.Printf("\nRecommended: %s level %d (%s)\n", best.Compression, best.Level, bench.Recommendation)
+		if bench.DatabaseSize > 0 {
+			fmt.Println("Estimates assume the dump is about as large as the data on disk.")
+		}
+
+		if !benchSave {
+			fmt.Printf("Use it with: ysm backup create --compress %s --level %d\n", best.Compression, best.Level)
+			return nil
+		}
+		p, err := cfg.GetProfile(profile)
+		if err != nil {
+			return err
+		}
+		p.Compression = string(best.Compression)
+		p.CompressionLevel = best.Level
+		cfg.AddProfile(profile, *p)
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		fmt.Printf("Saved as the backup compression of profile '%s'\n", profile)
+		return nil
+	},
+}
+
 func init() {
 	// Create flags
 	backupCreateCmd.Flags().StringVarP(&backupOutputDir, "output", "o", "", "Output directory for backups")
-	backupCreateCmd.Flags().StringVarP(&backupCompression, "compress", "c", "", "Compression type (gzip, xz, zstd)")
+	backupCreateCmd.Flags().StringVarP(&backupCompression, "compress", "c", "", "Compression type (gzip, xz, zstd; default: the profile's)")
+	backupCreateCmd.Flags().IntVar(&backupLevel, "level", 0, "Compression level (default: gzip 6, xz 6, zstd 3)")
 	backupCreateCmd.Flags().StringVar(&backupDescription, "description", "", "Backup description")
 	backupCreateCmd.Flags().IntVar(&backupParallel, "parallel", 0, "Number of parallel workers (0=sequential, -1=auto)")
 
+	// Bench flags
+	backupBenchCmd.Flags().IntVar(&benchRows, "rows", 5000, "Rows sampled per table")
+	backupBenchCmd.Flags().Float64Var(&benchMinSpeed, "min-speed", 20, "Slowest acceptable compression speed in MB/s")
+	backupBenchCmd.Flags().BoolVar(&benchSave, "save", false, "Save the recommendation as the profile's backup compression")
+
 	// Restore flags
 	backupRestoreCmd.Flags().BoolVar(&restoreDropExist, "drop", false, "Drop existing databases before restore")
 	backupRestoreCmd.Flags().StringArrayVar(&restoreRename, "rename", []string{}, "Rename database during restore (format: old:new)")
+	backupRestoreCmd.Flags().StringVar(&restoreTarget, "target-profile", "", "Restore to the server of this profile instead of the current connection")
+	backupRestoreCmd.Flags().BoolVar(&restoreForce, "force", false, "Restore even if the pre-restore check finds blockers")
+	backupRestoreCmd.Flags().BoolVar(&restoreAnalyze, "analyze", false, "Refresh optimizer statistics (ANALYZE) of the restored tables afterwards")
+	backupRestoreCmd.Flags().StringArrayVar(&restoreBeforeSQL, "before-sql", nil, "SQL file to run on the target before restoring (repeatable)")
+	backupRestoreCmd.Flags().StringArrayVar(&restoreAfterSQL, "after-sql", nil, "SQL file to run on the target after restoring, even if it failed (repeatable)")
+
+	// Prune flags
+	backupPruneCmd.Flags().IntVar(&pruneKeep, "keep", 0, "Always keep this many of the newest backups")
+	backupPruneCmd.Flags().StringVar(&pruneOlderThan, "older-than", "", "Only delete backups older than this (e.g. 30d, 12h)")
+	backupPruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "List what would be deleted without deleting")
+
+	// Skip confirmation prompts for automation
+	for _, c := range []*cobra.Command{backupRestoreCmd, backupDeleteCmd, backupPruneCmd, backupUndoCmd} {
+		c.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Don't ask for confirmation")
+	}
+
+	// Check flags mirror restore's
+	backupCheckCmd.Flags().BoolVar(&restoreDropExist, "drop", false, "Check as if existing databases are dropped first")
+	backupCheckCmd.Flags().StringArrayVar(&restoreRename, "rename", []string{}, "Rename database during restore (format: old:new)")
+	backupCheckCmd.Flags().StringVar(&restoreTarget, "target-profile", "", "Check against the server of this profile instead of the current connection")
+
+	// PostgreSQL ownership and privileges
+	for _, c := range []*cobra.Command{backupRestoreCmd, backupCheckCmd} {
+		c.Flags().StringVar(&restoreOwner, "owner", "", "Role that owns the restored databases and their objects (PostgreSQL)")
+		c.Flags().StringArrayVar(&restoreReassign, "reassign-from", []string{}, "Only hand what this role owns in the restored databases to --owner (PostgreSQL)")
+		c.Flags().StringVar(&restoreGrantRole, "grant-role", "", "Role granted privileges on the restored objects, now and by default (PostgreSQL)")
+		c.Flags().StringVar(&restoreGrantSet, "grant-privileges", "read", "Privilege set for --grant-role: read, write or all")
+	}
 
 	backupCmd.AddCommand(backupCreateCmd)
 	backupCmd.AddCommand(backupListCmd)
 	backupCmd.AddCommand(backupShowCmd)
 	backupCmd.AddCommand(backupRestoreCmd)
+	backupCmd.AddCommand(backupCheckCmd)
 	backupCmd.AddCommand(backupDeleteCmd)
+	backupCmd.AddCommand(backupPruneCmd)
+	backupCmd.AddCommand(backupVerifyCmd)
+	backupCmd.AddCommand(backupUploadCmd)
+	backupCmd.AddCommand(backupBenchCmd)
+	backupCmd.AddCommand(backupRunbookCmd)
+	backupCmd.AddCommand(backupSafetyCmd)
+	backupCmd.AddCommand(backupUndoCmd)
 }
@@ -19,22 +19,40 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
 	"github.com/spf13/cobra"
 )
 
 var (
 	cloneNoData      bool
 	cloneDropTarget  bool
+	cloneChunkSize   int
 )
 
+// cloneJSONResult is what clone prints with --json
+type cloneJSONResult struct {
+	Source     string `json:"source"`
+	Target     string `json:"target"`
+	Data       bool   `json:"data"`
+	Rows       int64  `json:"rows"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
 var cloneCmd = &cobra.Command{
 	Use:   "clone <source-db> <target-db>",
 	Short: "Clone a database",
 	Long: `Create a copy of a database with all tables and optionally data.
 
+Rows are copied in primary key chunks, so progress is shown per row. Ctrl+C
+stops the clone and drops the partially created target.
+
 Examples:
   ysm clone mydb mydb_copy
   ysm clone mydb mydb_backup --no-data
@@ -50,23 +68,51 @@ Examples:
 		}
 		defer conn.Close()
 
-		fmt.Printf("Cloning database '%s' to '%s'...\n", sourceDB, targetDB)
+		infof("Cloning database '%s' to '%s'...\n", sourceDB, targetDB)
 
+		// Ctrl+C drops the partial target instead of leaving it behind
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		unit := progress.Rows
+		if cloneNoData {
+			unit = progress.Items
+		}
+		bar := newProgressPrinter("Cloning", unit, 0)
 		opts := db.CloneOptions{
 			SourceDB:     sourceDB,
 			TargetDB:     targetDB,
 			IncludeData:  !cloneNoData,
 			DropIfExists: cloneDropTarget,
+			ChunkSize:    cloneChunkSize,
 			OnProgress: func(table string, tableNum, totalTables int) {
-				fmt.Printf("\rCloning table %d/%d: %s", tableNum, totalTables, table)
+				bar.SetCurrent(table, tableNum, totalTables)
+				bar.refresh()
+			},
+			OnRows: func(p db.CopyProgress) {
+				bar.SetTotal(p.Total)
+				bar.Set(p.Done)
+				bar.refresh()
 			},
 		}
 
-		if err := conn.CloneDatabase(opts); err != nil {
+		err = conn.CloneDatabaseContext(ctx, opts)
+		bar.finish()
+		if err != nil {
 			return fmt.Errorf("clone failed: %w", err)
 		}
 
-		fmt.Println("\nClone completed successfully!")
+		if structuredOutput() {
+			return printStructured(cloneJSONResult{
+				Source:     sourceDB,
+				Target:     targetDB,
+				Data:       !cloneNoData,
+				Rows:       bar.Snapshot().Done,
+				DurationMs: bar.Snapshot().Elapsed.Milliseconds(),
+			})
+		}
+
+		fmt.Println("Clone completed successfully!")
 		return nil
 	},
 }
@@ -74,6 +120,7 @@ Examples:
 func init() {
 	cloneCmd.Flags().BoolVar(&cloneNoData, "no-data", false, "Clone structure only, no data")
 	cloneCmd.Flags().BoolVar(&cloneDropTarget, "drop-target", false, "Drop target database if it exists")
+	cloneCmd.Flags().IntVar(&cloneChunkSize, "chunk-size", db.DefaultCopyChunkSize, "Rows copied per statement")
 
 	rootCmd.AddCommand(cloneCmd)
 }
@@ -21,6 +21,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/blubskye/yandere_sql_manager/internal/db"
@@ -36,11 +37,13 @@ Supports:
   - MariaDB Galera Cluster
   - MariaDB Master/Slave Replication
   - PostgreSQL Streaming Replication
+  - PostgreSQL Logical Replication
 
 Subcommands:
   status  - Show cluster status
   nodes   - List cluster nodes
-  health  - Quick health check`,
+  health  - Quick health check
+  logical - List publications, subscriptions and their lag`,
 }
 
 var clusterStatusCmd = &cobra.Command{
@@ -58,6 +61,13 @@ var clusterStatusCmd = &cobra.Command{
 			return err
 		}
 
+		if structuredOutput() {
+			if status.Nodes == nil {
+				status.Nodes = []db.ClusterNode{}
+			}
+			return printStructured(status)
+		}
+
 		fmt.Println("Cluster Status")
 		fmt.Println("==============")
 		fmt.Println()
@@ -106,6 +116,17 @@ var clusterNodesCmd = &cobra.Command{
 			return err
 		}
 
+		if structuredOutput() {
+			nodes := status.Nodes
+			if len(nodes) == 0 {
+				nodes = []db.ClusterNode{}
+				if status.LocalNode != nil {
+					nodes = append(nodes, *status.LocalNode)
+				}
+			}
+			return printStructured(nodes)
+		}
+
 		if status.Type == db.ClusterTypeNone {
 			fmt.Println("Not running in cluster/replication mode.")
 			return nil
@@ -313,12 +334,91 @@ var clusterReplicationCmd = &cobra.Command{
 	},
 }
 
+var clusterLogicalCmd = &cobra.Command{
+	Use:   "logical",
+	Short: "Show logical replication details (PostgreSQL)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		status, err := conn.GetLogicalReplicationStatus()
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("PostgreSQL Logical Replication")
+		fmt.Println("==============================")
+		fmt.Println()
+
+		if !status.Configured() {
+			fmt.Println("No publications, subscriptions or logical slots.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if len(status.Publications) > 0 {
+			fmt.Fprintln(w, "PUBLICATION\tOWNER\tPUBLISHES\tTABLES")
+			fmt.Fprintln(w, "-----------\t-----\t---------\t------")
+			for _, pub := range status.Publications {
+				tables := "all tables"
+				if !pub.AllTables {
+					tables = strings.Join(pub.Tables, ", ")
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", pub.Name, pub.Owner, strings.Join(pub.Operations, ","), tables)
+			}
+			fmt.Fprintln(w)
+		}
+
+		if len(status.Subscriptions) > 0 {
+			fmt.Fprintln(w, "SUBSCRIPTION\tENABLED\tWORKER\tPUBLICATIONS\tRECEIVED\tLAG")
+			fmt.Fprintln(w, "------------\t-------\t------\t------------\t--------\t---")
+			for _, sub := range status.Subscriptions {
+				lag := "-"
+				if sub.LagSeconds != nil {
+					lag = fmt.Sprintf("%.1fs", *sub.LagSeconds)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+					sub.Name,
+					formatBool(sub.Enabled),
+					formatBool(sub.WorkerRunning),
+					strings.Join(sub.Publications, ","),
+					sub.ReceivedLSN,
+					lag,
+				)
+			}
+			fmt.Fprintln(w)
+		}
+
+		if len(status.Slots) > 0 {
+			fmt.Fprintln(w, "SLOT\tDATABASE\tACTIVE\tBEHIND")
+			fmt.Fprintln(w, "----\t--------\t------\t------")
+			for _, slot := range status.Slots {
+				behind := "-"
+				if slot.LagBytes != nil {
+					behind = db.FormatSize(*slot.LagBytes)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", slot.Name, slot.Database, formatBool(slot.Active), behind)
+			}
+		}
+		w.Flush()
+
+		if problem := status.Problem(); problem != "" {
+			fmt.Printf("\nWARNING: %s\n", problem)
+		}
+		return nil
+	},
+}
+
 func init() {
 	clusterCmd.AddCommand(clusterStatusCmd)
 	clusterCmd.AddCommand(clusterNodesCmd)
 	clusterCmd.AddCommand(clusterHealthCmd)
 	clusterCmd.AddCommand(clusterGaleraCmd)
 	clusterCmd.AddCommand(clusterReplicationCmd)
+	clusterCmd.AddCommand(clusterLogicalCmd)
 }
 
 // Helper functions
@@ -0,0 +1,204 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout bounds the connection made to complete database names,
+// so a slow or unreachable server doesn't hang the shell
+const completionTimeout = 3 * time.Second
+
+// completionFunc completes one argument or flag value
+type completionFunc = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// completePositional completes each positional argument with the function at
+// its index; the last function repeats for variadic arguments
+func completePositional(fns ...completionFunc) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(fns) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return fns[min(len(args), len(fns)-1)](cmd, args, toComplete)
+	}
+}
+
+// completeNothing stops completion, files included
+func completeNothing(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeValues returns a completion of fixed values
+func completeValues(values ...string) completionFunc {
+	return cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp)
+}
+
+// completeProfiles completes the profile names in the config file
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if cfg == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for name, p := range cfg.Profiles {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, fmt.Sprintf("%s\t%s@%s", name, p.User, p.Host))
+		}
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeBackupIDs completes backup IDs, newest first, described by their
+// time and databases
+func completeBackupIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	backups, err := db.ListBackups()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var ids []string
+	for _, b := range backups {
+		if strings.HasPrefix(b.ID, toComplete) {
+			ids = append(ids, fmt.Sprintf("%s\t%s %s", b.ID,
+				b.Timestamp.Format("2006-01-02 15:04"), strings.Join(b.Databases, ", ")))
+		}
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeBackupDatabases completes the databases in the backup named by
+// the first argument
+func completeBackupDatabases(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	backup, err := db.GetBackup(args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterCompletions(backup.Databases, args[1:], toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeDatabases completes database names by connecting briefly with the
+// connection flags or profile given so far. It never prompts for a password.
+func completeDatabases(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	connCfg, err := getConnectionConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	type result struct {
+		databases []db.Database
+		err       error
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := db.Connect(connCfg)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer conn.Close()
+		databases, err := conn.ListVisibleDatabases()
+		done <- result{databases: databases, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			cobra.CompDebugln(fmt.Sprintf("listing databases: %v", r.err), false)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		names := make([]string, len(r.databases))
+		for i, d := range r.databases {
+			names[i] = d.Name
+		}
+		return filterCompletions(names, args, toComplete), cobra.ShellCompDirectiveNoFileComp
+	case <-time.After(completionTimeout):
+		cobra.CompDebugln("listing databases: timed out", false)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// filterCompletions returns the candidates starting with toComplete that
+// aren't already among args
+func filterCompletions(candidates, args []string, toComplete string) []string {
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, toComplete) && !containsArg(args, c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func containsArg(args []string, s string) bool {
+	for _, a := range args {
+		if a == s {
+			return true
+		}
+	}
+	return false
+}
+
+// registerCompletions adds dynamic completion to the global flags and to
+// commands taking profiles, backups or databases. It runs once every
+// command and flag exists.
+func registerCompletions() {
+	rootCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	rootCmd.RegisterFlagCompletionFunc("database", completeDatabases)
+	rootCmd.RegisterFlagCompletionFunc("type", completeValues(string(db.DatabaseTypeMariaDB), string(db.DatabaseTypePostgres)))
+	rootCmd.RegisterFlagCompletionFunc("output", completeValues(outputTable, outputJSON, outputYAML))
+
+	// Backups
+	backupShowCmd.ValidArgsFunction = completePositional(completeBackupIDs, completeNothing)
+	backupRestoreCmd.ValidArgsFunction = completePositional(completeBackupIDs, completeBackupDatabases)
+	backupCheckCmd.ValidArgsFunction = completePositional(completeBackupIDs, completeBackupDatabases)
+	backupDeleteCmd.ValidArgsFunction = completePositional(completeBackupIDs, completeNothing)
+	backupVerifyCmd.ValidArgsFunction = completePositional(completeBackupIDs, completeNothing)
+	backupCreateCmd.ValidArgsFunction = completePositional(completeDatabases)
+	backupBenchCmd.ValidArgsFunction = completePositional(completeDatabases, completeNothing)
+
+	// Databases
+	for _, cmd := range []*cobra.Command{exportCmd, listTablesCmd, statsTablesCmd, statsBloatCmd, dbDropCmd} {
+		cmd.ValidArgsFunction = completePositional(completeDatabases, completeNothing)
+	}
+	for _, cmd := range []*cobra.Command{cloneCmd, diffCmd, dataDiffCmd} {
+		cmd.ValidArgsFunction = completePositional(completeDatabases, completeDatabases, completeNothing)
+	}
+	mergeCmd.ValidArgsFunction = completePositional(completeDatabases)
+	copyCmd.ValidArgsFunction = completePositional(completeNothing, completeDatabases, completeNothing)
+
+	// Profiles
+	for _, cmd := range []*cobra.Command{profileRemoveCmd, profileUseCmd, profileShowCmd, profileVarsCmd, profileSetVarCmd, profileUnsetVarCmd} {
+		cmd.ValidArgsFunction = completePositional(completeProfiles, completeNothing)
+	}
+	profileStartupCmd.ValidArgsFunction = completePositional(completeProfiles, completeNothing)
+	profileStartupCmd.RegisterFlagCompletionFunc("view", completeValues(config.StartupViews...))
+	userImportCmd.RegisterFlagCompletionFunc("from", completeProfiles)
+	userImportCmd.RegisterFlagCompletionFunc("on-conflict", completeValues("ask",
+		string(db.UserImportSkip), string(db.UserImportPassword), string(db.UserImportMerge)))
+}
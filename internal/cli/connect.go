@@ -45,7 +45,7 @@ var connectCmd = &cobra.Command{
 		}
 
 		// List databases
-		databases, err := conn.ListDatabases()
+		databases, err := conn.ListVisibleDatabases()
 		if err == nil {
 			fmt.Printf("Databases: %d\n", len(databases))
 		}
@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
 	"github.com/spf13/cobra"
 )
 
@@ -67,6 +68,7 @@ Examples:
 
 		fmt.Printf("Copying %s.%s to %s.%s...\n", sourceDB, sourceTable, targetDB, targetTable)
 
+		bar := newProgressPrinter("Copying", progress.Rows, 0)
 		opts := db.CopyTableOptions{
 			SourceDB:     sourceDB,
 			SourceTable:  sourceTable,
@@ -76,15 +78,18 @@ Examples:
 			DropIfExists: copyDropTarget,
 			WhereClause:  copyWhere,
 			OnProgress: func(rowsCopied int64) {
-				fmt.Printf("\rRows copied: %d", rowsCopied)
+				bar.Set(rowsCopied)
+				bar.refresh()
 			},
 		}
 
-		if err := conn.CopyTable(opts); err != nil {
+		err = conn.CopyTable(opts)
+		bar.finish()
+		if err != nil {
 			return fmt.Errorf("copy failed: %w", err)
 		}
 
-		fmt.Println("\nCopy completed successfully!")
+		fmt.Println("Copy completed successfully!")
 		return nil
 	},
 }
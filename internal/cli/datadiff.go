@@ -0,0 +1,176 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dataDiffTables        []string
+	dataDiffChunkSize     int
+	dataDiffMaxRows       int
+	dataDiffSQL           bool
+	dataDiffOutput        string
+	dataDiffTargetProfile string
+)
+
+var dataDiffCmd = &cobra.Command{
+	Use:   "datadiff <db1> <db2>",
+	Short: "Compare the data of two databases",
+	Long: `Compare the rows of the tables two databases share. Each table is split
+into primary key chunks and both sides checksum every chunk; only chunks whose
+checksums differ are compared row by row, so large, mostly identical tables are
+cheap to check. Tables without a primary key are skipped.
+
+Rows are reported as + (only in db1), - (only in db2) or ~ (changed). With
+--sql (or --output) YSM also writes the INSERT, UPDATE and DELETE statements
+that make db2's data match db1.
+
+Examples:
+  ysm datadiff production staging
+  ysm datadiff shop shop_restored --tables orders,customers
+  ysm datadiff production staging --target-profile staging -o sync.sql`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db1 := args[0]
+		db2 := args[1]
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		opts := db.DataDiffOptions{
+			Source:    db1,
+			Target:    db2,
+			Tables:    dataDiffTables,
+			ChunkSize: dataDiffChunkSize,
+			MaxRows:   dataDiffMaxRows,
+		}
+
+		if dataDiffTargetProfile != "" {
+			target, err := connectProfile(dataDiffTargetProfile)
+			if err != nil {
+				return err
+			}
+			defer target.Close()
+			opts.TargetConn = target
+		}
+
+		var script bytes.Buffer
+		var out *os.File
+		if dataDiffOutput != "" {
+			out, err = os.Create(dataDiffOutput)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", dataDiffOutput, err)
+			}
+			defer out.Close()
+			opts.SQL = out
+		} else if dataDiffSQL {
+			opts.SQL = &script
+		}
+		if opts.SQL != nil {
+			fmt.Fprintf(opts.SQL, "-- Statements that make %s match %s\n", db2, db1)
+		}
+
+		fmt.Printf("Comparing data: %s vs %s\n\n", db1, db2)
+
+		bar := newProgressPrinter("Comparing", progress.Items, 0)
+		opts.OnProgress = func(table string, tableNum, totalTables int) {
+			bar.SetCurrent(table, tableNum, totalTables)
+			bar.refresh()
+		}
+
+		result, err := conn.CompareData(opts)
+		bar.finish()
+		if err != nil {
+			return fmt.Errorf("comparison failed: %w", err)
+		}
+
+		for _, name := range result.OnlyInSource {
+			fmt.Printf("+ %s (only in %s)\n", name, db1)
+		}
+		for _, name := range result.OnlyInTarget {
+			fmt.Printf("- %s (only in %s)\n", name, db2)
+		}
+
+		var identical, different, skipped int
+		for _, t := range result.Tables {
+			switch {
+			case t.Skipped != "":
+				skipped++
+				fmt.Printf("? %s (skipped: %s)\n", t.Table, t.Skipped)
+			case t.Identical():
+				identical++
+			default:
+				different++
+				fmt.Printf("~ %s: %d missing, %d extra, %d changed (%d of %d chunks differ)\n",
+					t.Table, t.Missing, t.Extra, t.Changed, t.ChunksDiffer, t.Chunks)
+				printRowDiffs(t)
+			}
+		}
+
+		fmt.Println("\nSummary:")
+		fmt.Printf("  Different: %d\n", different)
+		fmt.Printf("  Identical: %d\n", identical)
+		fmt.Printf("  Skipped: %d\n", skipped)
+		fmt.Printf("  Only in %s: %d\n", db1, len(result.OnlyInSource))
+		fmt.Printf("  Only in %s: %d\n", db2, len(result.OnlyInTarget))
+
+		if out != nil {
+			fmt.Printf("\nReconcile script written to %s (%d statements)\n", dataDiffOutput, result.Statements)
+		} else if dataDiffSQL {
+			fmt.Println()
+			io.Copy(os.Stdout, &script)
+		}
+
+		return nil
+	},
+}
+
+// printRowDiffs lists a table's differing rows by primary key
+func printRowDiffs(t db.TableDataDiff) {
+	symbols := map[db.RowDiffKind]string{db.RowMissing: "+", db.RowExtra: "-", db.RowChanged: "~"}
+	for _, r := range t.Rows {
+		fmt.Printf("    %s %s\n", symbols[r.Kind], strings.Join(r.Key, ", "))
+	}
+	if total := t.Missing + t.Extra + t.Changed; total > int64(len(t.Rows)) {
+		fmt.Printf("    ... and %d more\n", total-int64(len(t.Rows)))
+	}
+}
+
+func init() {
+	dataDiffCmd.Flags().StringSliceVar(&dataDiffTables, "tables", nil, "Only compare these tables")
+	dataDiffCmd.Flags().IntVar(&dataDiffChunkSize, "chunk-size", 1000, "Rows per checksum chunk")
+	dataDiffCmd.Flags().IntVar(&dataDiffMaxRows, "max-rows", 100, "Differing rows listed per table")
+	dataDiffCmd.Flags().BoolVar(&dataDiffSQL, "sql", false, "Print the statements that make db2 match db1")
+	dataDiffCmd.Flags().StringVarP(&dataDiffOutput, "output", "o", "", "Write the reconcile statements to a file")
+	dataDiffCmd.Flags().StringVar(&dataDiffTargetProfile, "target-profile", "", "Profile of the server holding db2 (default: the same server)")
+	rootCmd.AddCommand(dataDiffCmd)
+}
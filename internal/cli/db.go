@@ -21,21 +21,25 @@ package cli
 import (
 	"fmt"
 	"os"
-	"strings"
 	"text/tabwriter"
 
+	"github.com/blubskye/yandere_sql_manager/internal/clipboard"
+	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
 
 var (
-	dbCharset    string
-	dbCollation  string
-	dbTemplate   string
-	dbUsername   string
-	dbPassword   string
-	dbHostFlag   string
+	dbCharset     string
+	dbCollation   string
+	dbTemplate    string
+	dbUsername    string
+	dbPassword    string
+	dbHostFlag    string
+	dbNoBootstrap bool
+	dbSnippet     string
+	dbCopy        bool
 )
 
 var dbCmd = &cobra.Command{
@@ -106,10 +110,13 @@ Examples:
 var dbDropCmd = &cobra.Command{
 	Use:   "drop <name>",
 	Short: "Drop a database",
-	Long: `Drop a database. This action cannot be undone.
+	Long: `Drop a database. The database name has to be typed back to confirm.
+Unless a safety backup is taken first (--safety-backup, or
+safety_backups.enabled in the config), this can't be undone.
 
 Examples:
-  ysm db drop mydb`,
+  ysm db drop mydb
+  ysm db drop mydb --safety-backup   # Back it up first, to undo with 'ysm backup undo'`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		conn, err := connect()
@@ -122,14 +129,29 @@ Examples:
 
 		// Confirm deletion
 		fmt.Printf("WARNING: This will permanently delete database '%s' and all its data.\n", name)
-		fmt.Printf("Are you sure you want to continue? [y/N]: ")
-		var confirm string
-		fmt.Scanln(&confirm)
-		if strings.ToLower(confirm) != "y" && strings.ToLower(confirm) != "yes" {
-			fmt.Println("Aborted.")
+		if !confirmTyped("drop", []string{name}) {
 			return nil
 		}
 
+		enabled, expire, err := safetyBackupSettings(cmd)
+		if err != nil {
+			return err
+		}
+		if enabled {
+			// Refuse protected databases before spending time backing them up
+			if err := conn.CheckDatabaseProtected(name, "drop"); err != nil {
+				return err
+			}
+			err := takeSafetyBackup(conn, expire, db.SafetyBackupOptions{
+				Operation: "drop database " + name,
+				Databases: []string{name},
+				Profile:   profile,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
 		if err := conn.DropDatabase(name); err != nil {
 			return err
 		}
@@ -143,10 +165,18 @@ var dbSetupCmd = &cobra.Command{
 	Use:   "setup <database-name>",
 	Short: "Setup database and user for an application",
 	Long: `Create a database and user pair optimized for a specific application.
+Custom templates from templates.yaml in the config dir are available too,
+and their bootstrap script runs in the new database unless --no-bootstrap.
+
+Afterwards it prints connection snippets for the application: a .env file
+(env), a DATABASE_URL (url), PHP PDO (php), Django settings (django) and
+Rails database.yml (rails). --snippet prints just one, and --copy puts it on
+the clipboard.
 
 Examples:
   ysm db setup myblog --template wordpress --user bloguser
-  ysm db setup myapp --template laravel --user appuser -p secretpass`,
+  ysm db setup myapp --template laravel --user appuser -p secretpass
+  ysm db setup myapp --template laravel --user appuser --snippet env --copy`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		conn, err := connect()
@@ -157,13 +187,17 @@ Examples:
 
 		dbName := args[0]
 
+		if dbCopy && dbSnippet == "" {
+			return fmt.Errorf("--copy needs --snippet to pick what to copy")
+		}
+
 		// Get template
 		templateName := dbTemplate
 		if templateName == "" {
 			templateName = "default"
 		}
 
-		template, err := db.GetTemplate(templateName)
+		template, err := config.GetAppTemplate(templateName)
 		if err != nil {
 			return err
 		}
@@ -214,6 +248,26 @@ Examples:
 		if dbCollation != "" {
 			template.Collation = dbCollation
 		}
+		if dbNoBootstrap {
+			template.Bootstrap = ""
+		}
+
+		snippets := db.AppSnippets(db.AppConnection{
+			Type:     conn.Config.Type,
+			Host:     conn.Config.Host,
+			Port:     conn.Config.Port,
+			Database: dbName,
+			User:     username,
+			Password: pwd,
+			Charset:  template.GetCharsetForDB(conn.Config.Type),
+		})
+		if dbSnippet != "" {
+			snippet, err := db.FindAppSnippet(snippets, dbSnippet)
+			if err != nil {
+				return err
+			}
+			snippets = []db.AppSnippet{*snippet}
+		}
 
 		fmt.Printf("Setting up database for %s...\n", template.Description)
 		fmt.Printf("  Database: %s\n", dbName)
@@ -222,6 +276,9 @@ Examples:
 		if template.Collation != "" {
 			fmt.Printf("  Collation: %s\n", template.Collation)
 		}
+		if template.Bootstrap != "" {
+			fmt.Printf("  Bootstrap: %s\n", template.Bootstrap)
+		}
 		fmt.Println()
 
 		if err := conn.SetupAppDatabase(template, dbName, username, pwd, host); err != nil {
@@ -236,6 +293,17 @@ Examples:
 		fmt.Printf("  Database: %s\n", dbName)
 		fmt.Printf("  User:     %s\n", username)
 
+		for _, snippet := range snippets {
+			fmt.Printf("\n# %s\n%s", snippet.Name, snippet.Text)
+		}
+		if dbCopy {
+			via, err := clipboard.Copy(snippets[0].Text, os.Stdout)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\nCopied %s to the clipboard (%s)\n", snippets[0].Name, via)
+		}
+
 		return nil
 	},
 }
@@ -243,23 +311,34 @@ Examples:
 var dbTemplatesCmd = &cobra.Command{
 	Use:   "templates",
 	Short: "List available application templates",
+	Long: `List the built-in application templates and the custom ones from
+templates.yaml in the config dir. A custom template named like a built-in
+one replaces it. Templates can be edited from the setup wizard (e).`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		templates := db.DefaultTemplates()
+		templates, err := config.AppTemplates()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: custom templates not loaded: %v\n", err)
+		}
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "NAME\tDESCRIPTION\tCHARSET\tCOLLATION")
-		fmt.Fprintln(w, "----\t-----------\t-------\t---------")
+		fmt.Fprintln(w, "NAME\tDESCRIPTION\tCHARSET\tCOLLATION\tSOURCE")
+		fmt.Fprintln(w, "----\t-----------\t-------\t---------\t------")
 
 		for _, t := range templates {
 			collation := t.Collation
 			if collation == "" {
 				collation = "(default)"
 			}
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			source := "built-in"
+			if t.Custom {
+				source = "custom"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 				t.Name,
 				t.Description,
 				t.Charset,
 				collation,
+				source,
 			)
 		}
 
@@ -342,6 +421,9 @@ func init() {
 	dbSetupCmd.Flags().StringVar(&dbHostFlag, "host", "localhost", "Host for the user (MariaDB only)")
 	dbSetupCmd.Flags().StringVar(&dbCharset, "charset", "", "Override template charset")
 	dbSetupCmd.Flags().StringVar(&dbCollation, "collation", "", "Override template collation")
+	dbSetupCmd.Flags().BoolVar(&dbNoBootstrap, "no-bootstrap", false, "Don't run the template's bootstrap script")
+	dbSetupCmd.Flags().StringVar(&dbSnippet, "snippet", "", "Print only this connection snippet: env, url, php, django, rails")
+	dbSetupCmd.Flags().BoolVar(&dbCopy, "copy", false, "Copy the --snippet to the clipboard")
 
 	dbCmd.AddCommand(dbCreateCmd)
 	dbCmd.AddCommand(dbDropCmd)
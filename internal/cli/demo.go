@@ -0,0 +1,91 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	demoName  string
+	demoReset bool
+	demoDrop  bool
+	demoNoTUI bool
+)
+
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Try YSM on sample data with a guided tour",
+	Long: `Seed a demo database on a sandbox server and start the TUI with a guide
+that walks through browsing, querying, exporting, backing up and restoring.
+
+Point it at a sandbox (a local container is perfect), never production.
+The demo database is marked when it is created; YSM refuses to seed or drop
+a database of the same name that it didn't create.
+
+Examples:
+  docker run -d -p 3306:3306 -e MARIADB_ROOT_PASSWORD=demo mariadb
+  ysm demo -u root -p demo
+
+  ysm demo -t postgres -u postgres      # PostgreSQL sandbox
+  ysm demo --reset                      # Start over with fresh sample data
+  ysm demo --drop                       # Remove the demo database`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if demoDrop {
+			if err := conn.DropDemo(demoName); err != nil {
+				return err
+			}
+			fmt.Printf("Dropped demo database '%s'.\n", demoName)
+			return nil
+		}
+
+		created, err := conn.SeedDemo(demoName, demoReset)
+		if err != nil {
+			return err
+		}
+		if created {
+			fmt.Printf("Created demo database '%s' with sample customers, products and orders.\n", demoName)
+		} else {
+			fmt.Printf("Using existing demo database '%s' (--reset to start over).\n", demoName)
+		}
+
+		if demoNoTUI {
+			return nil
+		}
+
+		return tui.RunDemo(conn, profile, demoName)
+	},
+}
+
+func init() {
+	demoCmd.Flags().StringVar(&demoName, "name", db.DemoDatabase, "Name of the demo database")
+	demoCmd.Flags().BoolVar(&demoReset, "reset", false, "Drop and re-seed the demo database")
+	demoCmd.Flags().BoolVar(&demoDrop, "drop", false, "Drop the demo database and exit")
+	demoCmd.Flags().BoolVar(&demoNoTUI, "no-tui", false, "Only seed the demo database")
+}
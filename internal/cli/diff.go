@@ -20,18 +20,31 @@ package cli
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/blubskye/yandere_sql_manager/internal/db"
 	"github.com/spf13/cobra"
 )
 
+var (
+	diffSQL    bool
+	diffOutput string
+)
+
 var diffCmd = &cobra.Command{
 	Use:   "diff <db1> <db2>",
 	Short: "Compare schemas between two databases",
-	Long: `Compare table structures between two databases and show differences.
+	Long: `Compare table structures between two databases and show differences
+column by column, including primary keys, indexes, foreign keys and checks.
+
+With --sql (or --output) YSM also generates the ALTER TABLE migration that
+makes db2 match db1. Tables only in db2 are dropped by the migration, so
+review it before running it.
 
 Examples:
   ysm diff production staging
-  ysm diff mydb mydb_backup`,
+  ysm diff mydb mydb_backup --sql
+  ysm diff production staging -o migrate_staging.sql`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		db1 := args[0]
@@ -45,35 +58,29 @@ Examples:
 
 		fmt.Printf("Comparing schemas: %s vs %s\n\n", db1, db2)
 
-		result, err := conn.CompareSchemas(db1, db2)
+		result, err := conn.DiffSchemas(db1, db2)
 		if err != nil {
 			return fmt.Errorf("comparison failed: %w", err)
 		}
 
-		// Tables only in first database
-		if len(result.OnlyInFirst) > 0 {
-			fmt.Printf("Tables only in %s:\n", db1)
-			for _, t := range result.OnlyInFirst {
-				fmt.Printf("  + %s\n", t)
-			}
-			fmt.Println()
-		}
-
-		// Tables only in second database
-		if len(result.OnlyInSecond) > 0 {
-			fmt.Printf("Tables only in %s:\n", db2)
-			for _, t := range result.OnlyInSecond {
-				fmt.Printf("  + %s\n", t)
+		var onlyInFirst, onlyInSecond, different int
+		for _, t := range result.Tables {
+			switch t.Kind {
+			case db.SchemaAdded:
+				onlyInFirst++
+				fmt.Printf("+ %s (only in %s)\n", t.Table, db1)
+			case db.SchemaRemoved:
+				onlyInSecond++
+				fmt.Printf("- %s (only in %s)\n", t.Table, db2)
+			default:
+				different++
+				fmt.Printf("~ %s\n", t.Table)
+				for _, c := range t.Changes {
+					fmt.Printf("    %s\n", c)
+				}
 			}
-			fmt.Println()
 		}
-
-		// Different tables
-		if len(result.Different) > 0 {
-			fmt.Println("Tables with different schemas:")
-			for _, d := range result.Different {
-				fmt.Printf("  ~ %s\n", d.TableName)
-			}
+		if len(result.Tables) > 0 {
 			fmt.Println()
 		}
 
@@ -84,15 +91,27 @@ Examples:
 
 		// Summary
 		fmt.Println("\nSummary:")
-		fmt.Printf("  Only in %s: %d\n", db1, len(result.OnlyInFirst))
-		fmt.Printf("  Only in %s: %d\n", db2, len(result.OnlyInSecond))
-		fmt.Printf("  Different: %d\n", len(result.Different))
+		fmt.Printf("  Only in %s: %d\n", db1, onlyInFirst)
+		fmt.Printf("  Only in %s: %d\n", db2, onlyInSecond)
+		fmt.Printf("  Different: %d\n", different)
 		fmt.Printf("  Identical: %d\n", len(result.Identical))
 
+		if diffOutput != "" {
+			if err := os.WriteFile(diffOutput, []byte(result.Script()), 0644); err != nil {
+				return fmt.Errorf("failed to write migration: %w", err)
+			}
+			fmt.Printf("\nMigration written to %s (%d statements)\n", diffOutput, len(result.Migration()))
+		} else if diffSQL {
+			fmt.Println()
+			fmt.Print(result.Script())
+		}
+
 		return nil
 	},
 }
 
 func init() {
+	diffCmd.Flags().BoolVar(&diffSQL, "sql", false, "Print the migration that makes db2 match db1")
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "", "Write the migration to a file")
 	rootCmd.AddCommand(diffCmd)
 }
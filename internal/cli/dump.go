@@ -0,0 +1,210 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dumpPreviewRows int
+	dumpExtractOut  string
+)
+
+var dumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Look inside SQL dumps without importing them",
+	Long: `Browse a SQL dump, compressed (.gz, .xz, .zst) or split, without a
+server: list the databases and tables in it, show a table's CREATE
+statement and first rows, and extract one table into a file of its own.
+
+Tables are named as they are in the dump; use database.table when the same
+name is in more than one of its databases. The TUI import view opens the
+same browser with Ctrl+B once a file is picked.
+
+Examples:
+  ysm dump tables backup.sql.zst
+  ysm dump show backup.sql.zst users --rows 20
+  ysm dump extract backup.sql.zst shop.users -o users.sql`,
+}
+
+var dumpTablesCmd = &cobra.Command{
+	Use:   "tables <file>",
+	Short: "List the databases and tables in a dump",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		index, err := indexDumpFile(args[0])
+		if err != nil {
+			return err
+		}
+		return printResult(index, func() error {
+			if len(index.Tables) == 0 {
+				fmt.Println("No tables found in the dump.")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "DATABASE\tTABLE\tCREATE\tINSERTS\tROWS")
+			for _, t := range index.Tables {
+				create := "-"
+				if t.CreateSQL != "" {
+					create = fmt.Sprintf("line %d", t.CreateLine)
+				}
+				database := t.Database
+				if database == "" {
+					database = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", database, t.Name, create, t.Inserts, t.Rows)
+			}
+			w.Flush()
+			fmt.Printf("\n%d tables in %d statements\n", len(index.Tables), index.Statements)
+			return nil
+		})
+	},
+}
+
+var dumpShowCmd = &cobra.Command{
+	Use:   "show <file> <table>",
+	Short: "Show a table's CREATE statement and first rows in a dump",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		index, err := indexDumpFile(args[0])
+		if err != nil {
+			return err
+		}
+		table, err := index.Find(args[1])
+		if err != nil {
+			return err
+		}
+		columns, rows := table.PreviewRows(dumpPreviewRows)
+
+		if structuredOutput() {
+			preview := make([]map[string]string, 0, len(rows))
+			for _, row := range rows {
+				preview = append(preview, dumpRowMap(columns, row))
+			}
+			return printStructured(struct {
+				*db.DumpTable
+				Create  string              `json:"create,omitempty"`
+				Columns []string            `json:"columns"`
+				Preview []map[string]string `json:"preview"`
+			}{table, table.CreateSQL, columns, preview})
+		}
+
+		fmt.Printf("-- %s: %d INSERT statements, %d rows\n", table.QualifiedName(), table.Inserts, table.Rows)
+		if table.CreateSQL != "" {
+			fmt.Printf("-- line %d\n%s;\n", table.CreateLine, table.CreateSQL)
+		} else {
+			fmt.Println("-- No CREATE TABLE in the dump")
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		fmt.Printf("\nFirst %d rows:\n", len(rows))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(columns, "\t"))
+		for _, row := range rows {
+			cells := make([]string, len(row))
+			for i, v := range row {
+				cells[i] = truncate(strings.ReplaceAll(v, "\n", `\n`), 40)
+			}
+			fmt.Fprintln(w, strings.Join(cells, "\t"))
+		}
+		return w.Flush()
+	},
+}
+
+var dumpExtractCmd = &cobra.Command{
+	Use:   "extract <file> <table>",
+	Short: "Copy one table's SQL out of a dump into a new file",
+	Long: `Copy one table's DROP, CREATE, INSERT, ALTER and CREATE INDEX statements
+out of a dump into a new file, after the session settings the dump starts
+with, ready for ysm import. The output is compressed by its extension.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		index, err := indexDumpFile(args[0])
+		if err != nil {
+			return err
+		}
+		table, err := index.Find(args[1])
+		if err != nil {
+			return err
+		}
+		output := dumpExtractOut
+		if output == "" {
+			output = table.Name + ".sql"
+		}
+		if _, err := os.Stat(output); err == nil {
+			return fmt.Errorf("%s already exists", output)
+		}
+
+		infof("Extracting %s to %s\n", table.QualifiedName(), output)
+		stats, err := db.ExtractDumpTable(args[0], table, output)
+		if err != nil {
+			return err
+		}
+		return printResult(stats, func() error {
+			fmt.Printf("Extracted %d statements (%d rows) to %s\n", stats.Statements, stats.Rows, output)
+			return nil
+		})
+	},
+}
+
+// indexDumpFile reads a dump through, showing how far it got
+func indexDumpFile(path string) (*db.DumpIndex, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+	infof("Reading %s...\n", filepath.Base(path))
+	index, err := db.IndexDump(path, func(statements int64) {
+		infof("\r  %d statements", statements)
+	})
+	infof("\n\n")
+	return index, err
+}
+
+// dumpRowMap pairs a preview row's values with the columns
+func dumpRowMap(columns, row []string) map[string]string {
+	m := make(map[string]string, len(row))
+	for i, v := range row {
+		name := fmt.Sprintf("column%d", i+1)
+		if i < len(columns) {
+			name = columns[i]
+		}
+		m[name] = v
+	}
+	return m
+}
+
+func init() {
+	dumpShowCmd.Flags().IntVar(&dumpPreviewRows, "rows", 10, "Rows of the first INSERT to show")
+	dumpExtractCmd.Flags().StringVarP(&dumpExtractOut, "output", "o", "", "File to write (default: <table>.sql)")
+
+	dumpCmd.AddCommand(dumpTablesCmd)
+	dumpCmd.AddCommand(dumpShowCmd)
+	dumpCmd.AddCommand(dumpExtractCmd)
+	rootCmd.AddCommand(dumpCmd)
+}
@@ -20,11 +20,17 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/blubskye/yandere_sql_manager/internal/buffer"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/plugin"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/blubskye/yandere_sql_manager/internal/webhook"
 	"github.com/spf13/cobra"
 )
 
@@ -35,10 +41,23 @@ var (
 	exportAddDrop     bool
 	exportTables      []string
 	exportCompress    string
+	exportLevel       int
 	exportBatchSize   int
 	exportIncludeVars bool
 	exportFormat      string
 	exportUseNative   bool
+	exportMask        string
+	exportMaskPreview bool
+	exportMaskSamples int
+	exportDialect     string
+	exportConvert     []string
+	exportSnapshot    string
+	exportSampleRows  int
+	exportBeforeSQL   []string
+	exportAfterSQL    []string
+	exportSplitSize   int
+	exportNoOwner     bool
+	exportNoACL       bool
 )
 
 var exportCmd = &cobra.Command{
@@ -58,6 +77,31 @@ Examples:
   ysm export mydb --no-data
   ysm export mydb --tables users,posts
   ysm export mydb --include-vars
+  ysm export mydb -o bug-report.sql --sample-rows 50
+  ysm export mydb --before-sql pause-events.sql --after-sql resume-events.sql
+  ysm export mydb -o backup.sql.zst --split-size 100
+  ysm export mydb -o portable.sql --no-owner --no-acl
+
+Split exports are written as numbered parts (backup.sql.zst.001, .002, ...)
+of at most --split-size MB, with a manifest (backup.sql.zst.parts.json) that
+ysm import takes in place of the dump.
+
+--no-owner and --no-acl (PostgreSQL) leave out what ties the dump to the
+role that made it, so it restores under another role without editing. The
+choice is remembered for the profile, and later exports and imports from it
+use it until --no-owner=false or --no-acl=false.
+
+Anonymized exports (see README "Data Masking"):
+  ysm export mydb -o anon.sql.zst --mask masking.yaml
+  ysm export mydb --mask masking.yaml --preview
+
+Deterministic CI seed data (see README "CI Snapshots"):
+  ysm export mydb -o testdata/seed.sql --snapshot snapshot.yaml
+
+Other engines (one-way handoff; incompatibilities are reported):
+  ysm export mydb -o for-mssql.sql --dialect sqlserver
+  ysm export mydb -o for-oracle.sql --dialect oracle
+  ysm export mydb -o for-mssql.sql --dialect sqlserver --convert datetime=zero-date-null --convert "bigint unsigned=signed"
 
 PostgreSQL native formats:
   ysm export mydb -o backup.dump --format=custom
@@ -68,12 +112,81 @@ PostgreSQL native formats:
 	RunE: func(cmd *cobra.Command, args []string) error {
 		dbName := args[0]
 
+		dialect, err := db.ParseOutputDialect(exportDialect)
+		if err != nil {
+			return err
+		}
+		converters, err := exportConverters(dialect)
+		if err != nil {
+			return err
+		}
+
+		var snapshot *db.SnapshotConfig
+		if exportSampleRows < 0 {
+			return fmt.Errorf("--sample-rows must not be negative")
+		}
+		if exportSampleRows > 0 && exportNoData {
+			return fmt.Errorf("--sample-rows can't be combined with --no-data")
+		}
+		if exportSplitSize < 0 {
+			return fmt.Errorf("--split-size must not be negative")
+		}
+
+		if structuredOutput() && (exportSnapshot != "" || exportMaskPreview) {
+			return fmt.Errorf("JSON and YAML output aren't supported with --snapshot or --preview")
+		}
+
+		if exportSnapshot != "" {
+			if exportSampleRows > 0 {
+				return fmt.Errorf("--snapshot can't be combined with --sample-rows; set rows per table in the snapshot config")
+			}
+			if dialect != db.DialectNative || exportFormat != "" || exportUseNative || exportCompress != "" || len(exportTables) > 0 || exportSplitSize > 0 {
+				return fmt.Errorf("--snapshot can't be combined with --dialect, --format, --native, --compress, --tables or --split-size")
+			}
+			if exportMaskPreview {
+				return fmt.Errorf("--preview isn't supported with --snapshot")
+			}
+			sc, err := db.LoadSnapshotConfig(exportSnapshot)
+			if err != nil {
+				return err
+			}
+			snapshot = sc
+		}
+
+		var masking *db.MaskingConfig
+		if exportMask != "" {
+			mc, err := db.LoadMaskingConfig(exportMask)
+			if err != nil {
+				return err
+			}
+			masking = mc
+		} else if exportMaskPreview {
+			return fmt.Errorf("--preview requires --mask")
+		}
+
 		conn, err := connect()
 		if err != nil {
 			return err
 		}
 		defer conn.Close()
 
+		noOwner, noACL := ownershipFlags(cmd, exportNoOwner, exportNoACL)
+		if err := checkOwnershipFlags(cmd, conn, &noOwner, &noACL); err != nil {
+			return err
+		}
+
+		if exportMaskPreview {
+			return printMaskPreview(conn, dbName, masking)
+		}
+
+		if snapshot != nil {
+			// --mask replaces the snapshot's own masking section
+			if masking != nil {
+				snapshot.Masking = masking
+			}
+			return exportSnapshotFile(conn, dbName, snapshot)
+		}
+
 		// Determine output file
 		output := exportOutput
 		if output == "" {
@@ -106,8 +219,9 @@ PostgreSQL native formats:
 			}
 		}
 
-		// Determine format (for PostgreSQL)
+		// Determine format (for PostgreSQL, or provided by a plugin)
 		var format db.DumpFormat
+		var pluginReg *plugin.Registry
 		if exportFormat != "" {
 			switch strings.ToLower(exportFormat) {
 			case "sql", "plain":
@@ -119,9 +233,22 @@ PostgreSQL native formats:
 			case "dir", "directory", "d":
 				format = db.DumpFormatDir
 			default:
-				return fmt.Errorf("unknown format: %s (use: sql, custom, tar, dir)", exportFormat)
+				reg, err := plugin.Discover()
+				if err != nil {
+					return err
+				}
+				if _, _, ok := reg.ExportFormat(exportFormat); !ok {
+					return fmt.Errorf("unknown format: %s (use: sql, custom, tar, dir, or a plugin format)", exportFormat)
+				}
+				pluginReg = reg
 			}
 		}
+		if pluginReg != nil && dialect != db.DialectNative {
+			return fmt.Errorf("--dialect can't be combined with a plugin format")
+		}
+		if pluginReg != nil && exportSampleRows > 0 {
+			return fmt.Errorf("--sample-rows can't be combined with a plugin format")
+		}
 
 		// Show compression info
 		compressionName := "none"
@@ -140,37 +267,116 @@ PostgreSQL native formats:
 			}
 		}
 
-		fmt.Printf("Exporting database '%s' to %s\n", dbName, output)
-		fmt.Printf("Compression: %s\n\n", compressionName)
+		infof("Exporting database '%s' to %s\n", dbName, output)
+		infof("Compression: %s\n", compressionName)
+		if exportSampleRows > 0 {
+			infof("Sample: first %d rows per table by primary key\n", exportSampleRows)
+		}
+		if noOwner || noACL {
+			infof("Ownership: %s\n", ownershipSummary(noOwner, noACL))
+		}
+		infof("\n")
 
+		bar := newProgressPrinter("Exporting", progress.Rows, 0)
 		opts := db.ExportOptions{
-			FilePath:      output,
-			Database:      dbName,
-			Tables:        exportTables,
-			NoData:        exportNoData,
-			NoCreate:      exportNoCreate,
-			AddDropTable:  exportAddDrop,
-			Compression:   compression,
-			BatchSize:     exportBatchSize,
-			IncludeVars:   exportIncludeVars,
-			Format:        format,
-			UseNativeTool: exportUseNative,
-			OnProgress: func(currentTable string, tableNum, totalTables int, rowsExported int64) {
-				fmt.Printf("\r[%d/%d] Exporting: %-40s (%d rows)", tableNum, totalTables, currentTable, rowsExported)
+			FilePath:         output,
+			Database:         dbName,
+			Tables:           exportTables,
+			NoData:           exportNoData,
+			NoCreate:         exportNoCreate,
+			AddDropTable:     exportAddDrop,
+			Compression:      compression,
+			CompressionLevel: exportLevel,
+			BatchSize:        exportBatchSize,
+			IncludeVars:      exportIncludeVars,
+			Format:           format,
+			UseNativeTool:    exportUseNative,
+			Masking:          masking,
+			Dialect:          dialect,
+			Converters:       converters,
+			SampleRows:       exportSampleRows,
+			Scripts:          exportScripts(),
+			SplitSize:        int64(exportSplitSize) * 1024 * 1024,
+			NoOwner:          noOwner,
+			NoACL:            noACL,
+			OnProgress: func(currentTable string, tableNum, totalTables int, rowsExported, bytesWritten int64) {
+				bar.SetCurrent(currentTable, tableNum, totalTables)
+				bar.Set(rowsExported)
+				bar.SetBytes(bytesWritten)
+				bar.refresh()
 			},
 		}
+		// Estimated from table statistics, so the ETA is approximate
+		bar.SetTotal(conn.ExportRowEstimate(opts))
 
-		stats, err := conn.ExportSQLWithStats(opts)
+		var stats *db.ExportStats
+		start := time.Now()
+		if pluginReg != nil {
+			infof("Format: %s (plugin)\n\n", exportFormat)
+			stats, err = pluginReg.Export(conn, exportFormat, opts)
+		} else {
+			stats, err = conn.ExportSQLWithStats(opts)
+		}
+		bar.finish()
+		if stats != nil && stats.Split != nil {
+			output = stats.OutputFile
+		}
+		event := webhook.ExportEvent(dbName, output, stats, time.Since(start), err)
+		recordTimeline(event)
+		notifyWebhooks(event)
+		if err != nil && stats != nil {
+			// Only an after script failed; the dump itself is complete
+			if !structuredOutput() {
+				printScriptResults(stats.Scripts)
+			}
+			return fmt.Errorf("export written to %s, but %w", output, err)
+		}
 		if err != nil {
 			return fmt.Errorf("export failed: %w", err)
 		}
+		if err := rememberOwnership(cmd, noOwner, noACL); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+
+		if structuredOutput() {
+			if masking != nil {
+				if err := verifyMaskedExport(output, masking); err != nil {
+					return err
+				}
+			}
+			return printStructured(newExportJSONResult(dbName, output, compressionName, stats))
+		}
 
-		fmt.Printf("\n\nExport completed successfully!\n")
+		fmt.Printf("\nExport completed successfully!\n")
 		fmt.Printf("  Tables exported: %d\n", stats.TablesExported)
 		fmt.Printf("  Rows exported: %d\n", stats.RowsExported)
+		if stats.LargeObjects > 0 {
+			fmt.Printf("  Large objects: %d\n", stats.LargeObjects)
+		}
 		fmt.Printf("  File size: %s\n", formatSize(stats.BytesWritten))
 		fmt.Printf("  Duration: %s\n", stats.Duration.Round(time.Millisecond))
 		fmt.Printf("  Output: %s\n", output)
+		if stats.Split != nil {
+			fmt.Printf("  Parts: %d of at most %s\n", len(stats.Split.Parts), formatSize(stats.Split.PartSize))
+		}
+
+		printScriptResults(stats.Scripts)
+
+		if len(stats.FilteredTables) > 0 {
+			fmt.Printf("\nWarning: row-level security may have hidden rows of %d table(s) from this role: %s\n",
+				len(stats.FilteredTables), strings.Join(stats.FilteredTables, ", "))
+			fmt.Printf("  Export as the table owner or a role with BYPASSRLS for a complete dump\n")
+		}
+
+		if dialect != db.DialectNative {
+			printDialectIssues(dialect, stats.DialectIssues)
+		}
+
+		if masking != nil {
+			if err := verifyMaskedExport(output, masking); err != nil {
+				return err
+			}
+		}
 
 		// Calculate compression ratio if we can
 		if stats.Compressed && stats.RowsExported > 0 {
@@ -182,6 +388,233 @@ PostgreSQL native formats:
 	},
 }
 
+// exportJSONResult is what export prints with --json
+type exportJSONResult struct {
+	Database       string            `json:"database"`
+	Output         string            `json:"output"`
+	Compression    string            `json:"compression"`
+	Tables         int               `json:"tables"`
+	Rows           int64             `json:"rows"`
+	LargeObjects   int               `json:"large_objects,omitempty"`
+	Bytes          int64             `json:"bytes"`
+	DurationMs     int64             `json:"duration_ms"`
+	SampleRows     int               `json:"sample_rows,omitempty"`
+	FilteredTables []string          `json:"filtered_tables,omitempty"`
+	DialectIssues  []string          `json:"dialect_issues,omitempty"`
+	Scripts        []db.ScriptResult `json:"scripts,omitempty"`
+	Parts          []string          `json:"parts,omitempty"`
+}
+
+func newExportJSONResult(dbName, output, compression string, stats *db.ExportStats) exportJSONResult {
+	result := exportJSONResult{
+		Database:       dbName,
+		Output:         output,
+		Compression:    compression,
+		Tables:         stats.TablesExported,
+		Rows:           stats.RowsExported,
+		LargeObjects:   stats.LargeObjects,
+		Bytes:          stats.BytesWritten,
+		DurationMs:     stats.Duration.Milliseconds(),
+		SampleRows:     exportSampleRows,
+		FilteredTables: stats.FilteredTables,
+		Scripts:        stats.Scripts,
+	}
+	for _, issue := range stats.DialectIssues {
+		result.DialectIssues = append(result.DialectIssues, issue.String())
+	}
+	if stats.Split != nil {
+		for _, part := range stats.Split.Parts {
+			result.Parts = append(result.Parts, stats.Split.PartPath(part))
+		}
+	}
+	return result
+}
+
+// exportScripts returns the scripts to run around the export: the
+// profile's, then --before-sql and --after-sql
+func exportScripts() db.OperationScripts {
+	var fromProfile db.OperationScripts
+	if p := connectedProfile(""); p != nil {
+		fromProfile = p.Scripts.Export
+	}
+	return operationScripts(fromProfile, exportBeforeSQL, exportAfterSQL)
+}
+
+// exportSnapshotFile writes a deterministic CI snapshot and verifies it
+// when masking rules are configured
+func exportSnapshotFile(conn *db.Connection, dbName string, snapshot *db.SnapshotConfig) error {
+	output := exportOutput
+	if output == "" {
+		output = dbName + "_snapshot.sql"
+	}
+	if ext := strings.ToLower(filepath.Ext(output)); ext == ".gz" || ext == ".xz" || ext == ".zst" || ext == ".zstd" {
+		return fmt.Errorf("snapshots are written as plain SQL so they diff cleanly in a repository")
+	}
+
+	fmt.Printf("Writing CI snapshot of '%s' to %s (seed %d)\n\n", dbName, output, snapshot.Seed)
+	stats, err := conn.ExportSnapshot(db.SnapshotOptions{
+		FilePath:  output,
+		Database:  dbName,
+		Config:    snapshot,
+		BatchSize: exportBatchSize,
+		OnProgress: func(currentTable string, tableNum, totalTables int) {
+			fmt.Printf("\r  Sampling [%d/%d] %-40s", tableNum, totalTables, truncate(currentTable, 40))
+		},
+	})
+	fmt.Println()
+	if err != nil {
+		return fmt.Errorf("snapshot failed: %w", err)
+	}
+	snapshotEvent := db.TimelineEvent{
+		Profile: alertServerName(),
+		Kind:    db.TimelineSnapshot,
+		Summary: fmt.Sprintf("CI snapshot of %s written to %s", dbName, output),
+		Detail:  fmt.Sprintf("%d rows in %d tables, seed %d", stats.RowsExported, len(stats.Tables), snapshot.Seed),
+	}
+	if err := db.RecordTimelineEvent(snapshotEvent); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tROWS\tDROPPED")
+	for _, t := range stats.Tables {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", t.Table, t.Rows, t.Dropped)
+	}
+	w.Flush()
+
+	fmt.Printf("\nSnapshot written: %d rows in %d tables, %s\n", stats.RowsExported, len(stats.Tables), formatSize(stats.BytesWritten))
+	fmt.Printf("  Load it with: ysm import %s -d <database> --create\n", output)
+	for _, warning := range stats.Warnings {
+		fmt.Printf("  Warning: %s\n", warning)
+	}
+
+	if snapshot.Masking != nil {
+		return verifyMaskedExport(output, snapshot.Masking)
+	}
+	return nil
+}
+
+// printDialectIssues lists what didn't translate to the output dialect
+// exportConverters returns the value converters of the config's converters
+// section, with those of --convert on top
+func exportConverters(dialect db.OutputDialect) (*db.ValueConverters, error) {
+	if dialect == db.DialectNative {
+		if len(exportConvert) > 0 {
+			return nil, fmt.Errorf("--convert needs --dialect")
+		}
+		return nil, nil
+	}
+	converters, err := cfg.ValueConverters()
+	if err != nil {
+		return nil, fmt.Errorf("invalid converters setting: %w", err)
+	}
+	for _, flag := range exportConvert {
+		sourceType, name, ok := strings.Cut(flag, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --convert %q: use TYPE=CONVERTER, e.g. datetime=zero-date-null", flag)
+		}
+		if err := converters.Use(sourceType, dialect, name); err != nil {
+			return nil, err
+		}
+	}
+	return converters, nil
+}
+
+func printDialectIssues(dialect db.OutputDialect, issues []db.DialectIssue) {
+	if len(issues) == 0 {
+		fmt.Printf("\nEverything translated cleanly to %s\n", dialect)
+		return
+	}
+	fmt.Printf("\n%d %s incompatibilities (also listed at the end of the file):\n", len(issues), dialect)
+	for _, issue := range issues {
+		fmt.Printf("  - %s\n", issue)
+	}
+}
+
+// printMaskPreview shows before/after samples for every masked column
+func printMaskPreview(conn *db.Connection, dbName string, masking *db.MaskingConfig) error {
+	if err := conn.UseDatabase(dbName); err != nil {
+		return err
+	}
+
+	previews, err := conn.PreviewMasking(masking, exportTables, exportMaskSamples)
+	if err != nil {
+		return err
+	}
+	if len(previews) == 0 {
+		fmt.Println("No columns match the masking rules.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COLUMN\tSTRATEGY\tBEFORE\tAFTER")
+	fmt.Fprintln(w, "------\t--------\t------\t-----")
+	for _, p := range previews {
+		column := p.Table + "." + p.Column
+		if len(p.Samples) == 0 {
+			fmt.Fprintf(w, "%s\t%s\t(no data)\t\n", column, p.Strategy)
+			continue
+		}
+		for i, sample := range p.Samples {
+			if i > 0 {
+				column = ""
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", column, p.Strategy, truncate(sample.Before, 40), truncate(sample.After, 40))
+		}
+	}
+	return w.Flush()
+}
+
+// verifyMaskedExport scans the dump for residual personal data and removes it on leakage
+func verifyMaskedExport(output string, masking *db.MaskingConfig) error {
+	infof("\nVerifying export for leaked data...\n")
+	report, err := db.VerifyDumpLeakage(output, masking)
+	if err != nil {
+		return fmt.Errorf("leak verification failed: %w", err)
+	}
+
+	if !report.Found() {
+		infof("  No leaks found (%d lines scanned)\n", report.LinesScanned)
+		return nil
+	}
+
+	infof("  Possible leaks detected:\n")
+	for pattern, count := range report.Counts {
+		infof("    %s: %d match(es)\n", pattern, count)
+	}
+	for _, leak := range report.Leaks {
+		infof("    line %d [%s]: %s\n", leak.Line, leak.Pattern, leak.Match)
+	}
+
+	if err := removeExport(output); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove leaky export: %v\n", err)
+	} else {
+		infof("  Removed %s\n", output)
+	}
+	return fmt.Errorf("export failed verification: residual personal data found, add masking rules or allow patterns")
+}
+
+// removeExport deletes an export, with all its parts when it was split
+func removeExport(output string) error {
+	if !buffer.IsSplitManifest(output) {
+		return os.RemoveAll(output)
+	}
+	m, err := buffer.ReadSplitManifest(output)
+	if err != nil {
+		return err
+	}
+	return m.Remove()
+}
+
+// truncate shortens s to at most n runes for table output
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-3]) + "..."
+}
+
 func formatSize(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
@@ -202,8 +635,21 @@ func init() {
 	exportCmd.Flags().BoolVar(&exportAddDrop, "add-drop", true, "Add DROP TABLE statements")
 	exportCmd.Flags().StringSliceVar(&exportTables, "tables", nil, "Export only specific tables (comma-separated)")
 	exportCmd.Flags().StringVar(&exportCompress, "compress", "", "Compression: gzip, xz, zstd, none (auto-detect from filename)")
+	exportCmd.Flags().IntVar(&exportLevel, "level", 0, "Compression level (default: gzip 6, xz 6, zstd 3)")
 	exportCmd.Flags().IntVar(&exportBatchSize, "batch", 1000, "Rows per INSERT batch")
 	exportCmd.Flags().BoolVar(&exportIncludeVars, "include-vars", false, "Include session variable SET statements in export")
-	exportCmd.Flags().StringVar(&exportFormat, "format", "", "PostgreSQL format: sql, custom, tar, dir (auto-detect from extension)")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "", "Format: sql, custom, tar, dir (PostgreSQL) or a plugin-provided format")
 	exportCmd.Flags().BoolVar(&exportUseNative, "native", false, "Use native tools (pg_dump for PostgreSQL, mysqldump for MariaDB)")
+	exportCmd.Flags().StringVar(&exportMask, "mask", "", "Masking config (YAML) to anonymize columns and verify the dump")
+	exportCmd.Flags().BoolVar(&exportMaskPreview, "preview", false, "Show before/after masking samples instead of exporting")
+	exportCmd.Flags().StringVar(&exportDialect, "dialect", "", "Write SQL for another engine: sqlserver, oracle")
+	exportCmd.Flags().StringArrayVar(&exportConvert, "convert", nil, "Convert values of a source column type for --dialect, e.g. datetime=zero-date-null (repeatable; "+strings.Join(db.ConverterNames(), ", ")+")")
+	exportCmd.Flags().StringVar(&exportSnapshot, "snapshot", "", "Snapshot config (YAML) for a small, deterministic, anonymized CI dataset")
+	exportCmd.Flags().IntVar(&exportSampleRows, "sample-rows", 0, "Export the full schema but only the first N rows per table, by primary key")
+	exportCmd.Flags().IntVar(&exportMaskSamples, "samples", 5, "Sample rows per masked column for --preview")
+	exportCmd.Flags().StringArrayVar(&exportBeforeSQL, "before-sql", nil, "SQL file to run on the connection before exporting (repeatable)")
+	exportCmd.Flags().IntVar(&exportSplitSize, "split-size", 0, "Split the dump into numbered parts of at most N MB, with a manifest to import")
+	exportCmd.Flags().BoolVar(&exportNoOwner, "no-owner", false, "Leave out owners, so the dump restores under any role (PostgreSQL; default: the profile's)")
+	exportCmd.Flags().BoolVar(&exportNoACL, "no-acl", false, "Leave out privileges (GRANT/REVOKE) (PostgreSQL; default: the profile's)")
+	exportCmd.Flags().StringArrayVar(&exportAfterSQL, "after-sql", nil, "SQL file to run on the connection after exporting, even if it failed (repeatable)")
 }
@@ -0,0 +1,125 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	healthLagWarning   time.Duration
+	healthLagCritical  time.Duration
+	healthConnWarning  float64
+	healthConnCritical float64
+	healthDiskWarning  float64
+	healthDiskCritical float64
+	healthSizeWarning  string
+	healthSizeCritical string
+	healthClusterSize  int
+)
+
+var healthcheckCmd = &cobra.Command{
+	Use:   "healthcheck",
+	Short: "Check server health with monitoring exit codes",
+	Long: `Check the connection, replication lag, Galera/cluster state, free disk
+space and database sizes against thresholds.
+
+The first line of output is a status summary with Nagios performance data.
+The exit code is 0 for OK, 1 for WARNING, 2 for CRITICAL and 3 for UNKNOWN,
+so the command works as a Nagios, Icinga or Zabbix check.
+
+Free disk space is only measured when the server runs on this machine, and
+database sizes are only checked when a size threshold is set.
+
+Examples:
+  ysm healthcheck --profile prod
+  ysm healthcheck --profile replica --lag-warning 10s --lag-critical 1m
+  ysm healthcheck --profile galera --cluster-size 3
+  ysm healthcheck --db-size-warning 50GB --db-size-critical 80GB`,
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true, // The report already says what's wrong
+	RunE: func(cmd *cobra.Command, args []string) error {
+		thresholds := db.HealthThresholds{
+			LagWarning:          healthLagWarning,
+			LagCritical:         healthLagCritical,
+			ConnectionsWarning:  healthConnWarning,
+			ConnectionsCritical: healthConnCritical,
+			DiskFreeWarning:     healthDiskWarning,
+			DiskFreeCritical:    healthDiskCritical,
+			ClusterSize:         healthClusterSize,
+		}
+		var err error
+		if thresholds.DatabaseSizeWarning, err = db.ParseSize(healthSizeWarning); err == nil {
+			thresholds.DatabaseSizeCritical, err = db.ParseSize(healthSizeCritical)
+		}
+		if err != nil {
+			fmt.Printf("%s - %v\n", db.HealthUnknown, err)
+			return &exitCodeError{code: int(db.HealthUnknown), err: err}
+		}
+
+		conn, err := connect()
+		if err != nil {
+			fmt.Printf("%s - connection failed: %v\n", db.HealthCritical, err)
+			return &exitCodeError{code: int(db.HealthCritical), err: err}
+		}
+		defer conn.Close()
+
+		report := conn.CheckHealth(thresholds)
+		if perf := report.PerfData(); perf != "" {
+			fmt.Printf("%s | %s\n", report.Summary(), perf)
+		} else {
+			fmt.Println(report.Summary())
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, check := range report.Checks {
+			status := check.Status.String()
+			if check.Skipped {
+				status = "SKIP"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", status, check.Name, check.Message)
+		}
+		w.Flush()
+
+		if status := report.Status(); status != db.HealthOK {
+			return &exitCodeError{code: int(status), err: fmt.Errorf("health check %s", status)}
+		}
+		return nil
+	},
+}
+
+func init() {
+	defaults := db.DefaultHealthThresholds()
+	healthcheckCmd.Flags().DurationVar(&healthLagWarning, "lag-warning", defaults.LagWarning, "Replication lag that warns (0 disables)")
+	healthcheckCmd.Flags().DurationVar(&healthLagCritical, "lag-critical", defaults.LagCritical, "Replication lag that is critical (0 disables)")
+	healthcheckCmd.Flags().Float64Var(&healthConnWarning, "connections-warning", defaults.ConnectionsWarning, "Percent of max connections in use that warns")
+	healthcheckCmd.Flags().Float64Var(&healthConnCritical, "connections-critical", defaults.ConnectionsCritical, "Percent of max connections in use that is critical")
+	healthcheckCmd.Flags().Float64Var(&healthDiskWarning, "disk-warning", defaults.DiskFreeWarning, "Percent of free disk space that warns")
+	healthcheckCmd.Flags().Float64Var(&healthDiskCritical, "disk-critical", defaults.DiskFreeCritical, "Percent of free disk space that is critical")
+	healthcheckCmd.Flags().StringVar(&healthSizeWarning, "db-size-warning", "", "Database size that warns, e.g. 50GB")
+	healthcheckCmd.Flags().StringVar(&healthSizeCritical, "db-size-critical", "", "Database size that is critical, e.g. 80GB")
+	healthcheckCmd.Flags().IntVar(&healthClusterSize, "cluster-size", 0, "Expected Galera cluster size; fewer nodes warns")
+}
@@ -25,7 +25,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/blubskye/yandere_sql_manager/internal/buffer"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/blubskye/yandere_sql_manager/internal/webhook"
 	"github.com/spf13/cobra"
 )
 
@@ -34,13 +37,38 @@ var (
 	importRename         string
 	importBatchSize      int
 	importContinue       bool
+	importOnError        string
 	importNoFKChecks     bool
 	importNoUniqueChecks bool
 	importUseNative      bool
 	importJobs           int
 	importParallel       int
+	importAnalyze        bool
+	importReport         string
+	importVerify         bool
+	importNoOwner        bool
+	importNoACL          bool
 )
 
+// importJSONResult is what import prints with --json
+type importJSONResult struct {
+	File           string                        `json:"file"`
+	Database       string                        `json:"database"`
+	Compression    string                        `json:"compression"`
+	BytesRead      int64                         `json:"bytes_read"`
+	Statements     int64                         `json:"statements"`
+	ByType         db.ImportStatementCounts      `json:"statements_by_type"`
+	TablesCreated  []string                      `json:"tables_created,omitempty"`
+	RowsInserted   int64                         `json:"rows_inserted"`
+	LargeObjects   int64                         `json:"large_objects,omitempty"`
+	Errors         int64                         `json:"errors"`
+	ErrorsByClass  map[db.ImportErrorClass]int64 `json:"errors_by_class,omitempty"`
+	Warnings       int64                         `json:"warnings"`
+	TablesAnalyzed int                           `json:"tables_analyzed,omitempty"`
+	Manifest       *db.ManifestCheck             `json:"manifest,omitempty"`
+	DurationMs     int64                         `json:"duration_ms"`
+}
+
 var importCmd = &cobra.Command{
 	Use:   "import <file>",
 	Short: "Import a SQL file into a database",
@@ -58,10 +86,34 @@ Examples:
   ysm import large_backup.sql -d mydb --batch=500
   ysm import backup.sql -d mydb --no-fk-checks
   ysm import large_backup.sql -d mydb --parallel=4
+  ysm import backup.sql -d mydb --report import-report.txt
+  ysm import backup.sql -d mydb --safety-backup   # Back up the tables it drops first
+  ysm import backup.sql.zst -d mydb --verify-manifest
+  ysm import backup.sql -d mydb --on-error duplicate_key=skip,unknown_collation=rewrite
+  ysm import backup.sql.zst.parts.json -d mydb   # Dump split with export --split-size
+
+--on-error picks what happens per class of failed statement: duplicate_key,
+unknown_collation, syntax, table_exists and other can each abort or skip,
+and unknown_collation can also rewrite the statement with a collation the
+server has. Classes left out abort, unless --continue skips them.
+
+Data exports written by YSM end with a manifest of each table's row count
+and checksum. --verify-manifest counts the rows of every table it lists
+after the import and fails unless they all match.
+
+A split dump is imported from its manifest (<dump>.parts.json). The parts
+are read in order from the manifest's directory, and each one's checksum is
+checked as it is read. pg_restore and psql get the parts joined into a
+temporary file first.
 
 PostgreSQL native formats:
   ysm import backup.dump -d mydb --create
   ysm import backup.dump -d mydb --jobs=4
+  ysm import backup.dump -d mydb --no-owner --no-acl   # Restore another role's dump
+
+--no-owner and --no-acl are passed to pg_restore, which otherwise gives the
+restored objects their dumped owners and privileges. They default to what
+the profile remembers from ysm export --no-owner.
   ysm import backup.sql -d mydb --native`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -72,12 +124,40 @@ PostgreSQL native formats:
 			return fmt.Errorf("file not found: %s", filePath)
 		}
 
+		// A split dump is imported from its manifest, and named after the
+		// file it was split from
+		dumpName := buffer.SplitDumpName(filePath)
+		var split *buffer.SplitManifest
+		if buffer.IsSplitManifest(filePath) {
+			m, err := buffer.ReadSplitManifest(filePath)
+			if err != nil {
+				return err
+			}
+			if err := m.Check(); err != nil {
+				return fmt.Errorf("split dump is incomplete: %w", err)
+			}
+			split = m
+		}
+
+		policy, err := db.ParseImportErrorPolicy(importOnError)
+		if err != nil {
+			return fmt.Errorf("invalid --on-error: %w", err)
+		}
+		if importOnError == "" {
+			policy = nil // Whole failed batches abort, or are skipped with --continue
+		}
+
 		conn, err := connect()
 		if err != nil {
 			return err
 		}
 		defer conn.Close()
 
+		noOwner, noACL := ownershipFlags(cmd, importNoOwner, importNoACL)
+		if err := checkOwnershipFlags(cmd, conn, &noOwner, &noACL); err != nil {
+			return err
+		}
+
 		// Determine target database
 		targetDB := database
 		if importRename != "" {
@@ -86,7 +166,7 @@ PostgreSQL native formats:
 
 		if targetDB == "" {
 			// Try to infer from filename (strip compression extensions)
-			base := filepath.Base(filePath)
+			base := filepath.Base(dumpName)
 			// Remove compression extensions
 			for _, ext := range []string{".gz", ".xz", ".zst", ".zstd", ".gzip"} {
 				base = strings.TrimSuffix(base, ext)
@@ -97,12 +177,12 @@ PostgreSQL native formats:
 			} else {
 				targetDB = base
 			}
-			fmt.Printf("No database specified, using: %s\n", targetDB)
+			infof("No database specified, using: %s\n", targetDB)
 		}
 
 		// Detect compression
 		compression := "none"
-		lowerPath := strings.ToLower(filePath)
+		lowerPath := strings.ToLower(dumpName)
 		if strings.HasSuffix(lowerPath, ".xz") {
 			compression = "xz"
 		} else if strings.HasSuffix(lowerPath, ".zst") || strings.HasSuffix(lowerPath, ".zstd") {
@@ -111,13 +191,37 @@ PostgreSQL native formats:
 			compression = "gzip"
 		}
 
-		fmt.Printf("Importing %s into database '%s'...\n", filePath, targetDB)
+		// Tables the file drops are backed up before they are replaced
+		enabled, expire, err := safetyBackupSettings(cmd)
+		if err != nil {
+			return err
+		}
+		if enabled {
+			replaced, err := conn.ImportReplacedTables(filePath, targetDB)
+			if err != nil {
+				return err
+			}
+			if len(replaced) > 0 {
+				err := takeSafetyBackup(conn, expire, db.SafetyBackupOptions{
+					Operation: "import of " + filepath.Base(filePath),
+					Tables:    map[string][]string{targetDB: replaced},
+					Profile:   profile,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		infof("Importing %s into database '%s'...\n", filePath, targetDB)
 		if compression != "none" {
-			fmt.Printf("Compression: %s\n", compression)
+			infof("Compression: %s\n", compression)
+		}
+		if split != nil {
+			infof("Split dump: %d parts, %s\n", len(split.Parts), formatSize(split.Size))
 		}
 
-		startTime := time.Now()
-		var lastProgress time.Time
+		bar := newProgressPrinter("Importing", progress.Bytes, 0)
 
 		opts := db.ImportOptions{
 			FilePath:            filePath,
@@ -129,47 +233,126 @@ PostgreSQL native formats:
 			DisableUniqueChecks: importNoUniqueChecks,
 			UseNativeTool:       importUseNative,
 			Jobs:                importJobs,
+			NoOwner:             noOwner,
+			NoACL:               noACL,
 			Parallel:            importParallel,
 			ContinueOnError:     importContinue,
-			OnProgress: func(bytesRead, totalBytes int64, stmts int64) {
-				now := time.Now()
-				if now.Sub(lastProgress) < 100*time.Millisecond {
-					return // Rate limit progress updates
-				}
-				lastProgress = now
-
-				if totalBytes > 0 {
-					pct := float64(bytesRead) / float64(totalBytes) * 100
-					elapsed := time.Since(startTime)
-					speed := float64(bytesRead) / elapsed.Seconds() / 1024 / 1024
-					fmt.Printf("\rProgress: %.1f%% | %d statements | %.1f MB/s", pct, stmts, speed)
-				} else {
-					// Compressed file - unknown total size
-					fmt.Printf("\rStatements: %d", stmts)
-				}
+			ErrorPolicy:         policy,
+			Analyze:             importAnalyze,
+			VerifyManifest:      importVerify,
+			OnAnalyze: func(table string, tableNum, totalTables int) {
+				bar.SetCurrent("analyzing "+table, tableNum, totalTables)
+				bar.refresh()
 			},
-			OnError: func(err error, stmt string) bool {
-				if importContinue {
-					fmt.Printf("\nWarning: %v\n", err)
-					return true // Continue on error
-				}
-				return false // Stop on error
+			OnProgress: func(bytesRead, totalBytes int64, stmts, rows int64) {
+				// Compressed files report compressed bytes against the file size
+				bar.SetTotal(totalBytes)
+				bar.Set(bytesRead)
+				bar.SetRows(rows)
+				bar.SetCurrent(fmt.Sprintf("%d statements", stmts), 0, 0)
+				bar.refresh()
 			},
 		}
 
+		start := time.Now()
 		stats, err := conn.ImportSQLWithStats(opts)
+		bar.finish()
+		event := webhook.ImportEvent(filePath, targetDB, stats, time.Since(start), err)
+		recordTimeline(event)
+		notifyWebhooks(event)
 		if err != nil {
 			return fmt.Errorf("import failed: %w", err)
 		}
 
-		fmt.Printf("\n\nImport completed successfully!\n")
+		if importReport != "" {
+			format := db.ImportReportText
+			if strings.EqualFold(filepath.Ext(importReport), ".json") {
+				format = db.ImportReportJSON
+			}
+			if err := db.NewImportReport(filePath, targetDB, stats).Export(importReport, format); err != nil {
+				return err
+			}
+			infof("Report written to %s\n", importReport)
+		}
+
+		// Checked after the report and output, which include the mismatches
+		var manifestErr error
+		if stats.Manifest != nil {
+			if bad := stats.Manifest.Mismatches(); len(bad) > 0 {
+				manifestErr = fmt.Errorf("manifest check failed: %d of %d tables don't hold the rows the dump wrote",
+					len(bad), len(stats.Manifest.Tables))
+			}
+		}
+
+		if structuredOutput() {
+			if err := printStructured(importJSONResult{
+				File:           filePath,
+				Database:       targetDB,
+				Compression:    compression,
+				BytesRead:      stats.BytesRead,
+				Statements:     stats.StatementsExecuted,
+				ByType:         stats.Statements,
+				TablesCreated:  stats.TablesCreated,
+				RowsInserted:   stats.RowsInserted,
+				LargeObjects:   stats.LargeObjects,
+				Errors:         stats.ErrorsEncountered,
+				ErrorsByClass:  stats.ErrorsByClass,
+				Warnings:       stats.WarningCount,
+				TablesAnalyzed: stats.TablesAnalyzed,
+				Manifest:       stats.Manifest,
+				DurationMs:     stats.Duration.Milliseconds(),
+			}); err != nil {
+				return err
+			}
+			return manifestErr
+		}
+
+		fmt.Printf("\nImport completed successfully!\n")
 		fmt.Printf("  Statements executed: %d\n", stats.StatementsExecuted)
-		fmt.Printf("  Duration: %s\n", stats.Duration.Round(time.Millisecond))
+		if !stats.NativeTool {
+			fmt.Printf("  By type: %d CREATE, %d INSERT, %d ALTER, %d other\n",
+				stats.Statements.Create, stats.Statements.Insert, stats.Statements.Alter, stats.Statements.Other)
+			fmt.Printf("  Tables created: %d\n", len(stats.TablesCreated))
+			fmt.Printf("  Rows inserted: ~%d\n", stats.RowsInserted)
+			if stats.LargeObjects > 0 {
+				fmt.Printf("  Large objects: %d\n", stats.LargeObjects)
+			}
+		}
+		fmt.Printf("  Duration: %s", stats.Duration.Round(time.Millisecond))
+		if len(stats.Phases) > 1 {
+			phases := make([]string, len(stats.Phases))
+			for i, p := range stats.Phases {
+				phases[i] = fmt.Sprintf("%s %s", p.Name, p.Duration.Round(time.Millisecond))
+			}
+			fmt.Printf(" (%s)", strings.Join(phases, ", "))
+		}
+		fmt.Println()
 		if stats.ErrorsEncountered > 0 {
 			fmt.Printf("  Errors (skipped): %d\n", stats.ErrorsEncountered)
 		}
+		if len(stats.ErrorsByClass) > 0 {
+			fmt.Printf("  Skipped or rewritten by class:\n")
+			for _, class := range db.ImportErrorClasses {
+				if n := stats.ErrorsByClass[class]; n > 0 {
+					fmt.Printf("    %s: %d\n", class, n)
+				}
+			}
+		}
+		if stats.WarningCount > 0 {
+			fmt.Printf("  Warnings: %d\n", stats.WarningCount)
+		}
+		if importAnalyze {
+			fmt.Printf("  Tables analyzed: %d\n", stats.TablesAnalyzed)
+		}
+		if stats.Manifest != nil {
+			bad := stats.Manifest.Mismatches()
+			fmt.Printf("  Manifest: %d of %d tables match\n", len(stats.Manifest.Tables)-len(bad), len(stats.Manifest.Tables))
+			for _, t := range bad {
+				fmt.Printf("    %s\n", t)
+			}
+		}
 
-		return nil
+		return manifestErr
 	},
 }
 
@@ -178,9 +361,15 @@ func init() {
 	importCmd.Flags().StringVar(&importRename, "rename", "", "Rename database during import")
 	importCmd.Flags().IntVar(&importBatchSize, "batch", 100, "Statements per transaction batch")
 	importCmd.Flags().BoolVar(&importContinue, "continue", false, "Continue on errors")
+	importCmd.Flags().StringVar(&importOnError, "on-error", "", "Action per error class, e.g. duplicate_key=skip,unknown_collation=rewrite")
 	importCmd.Flags().BoolVar(&importNoFKChecks, "no-fk-checks", false, "Disable foreign key checks during import")
 	importCmd.Flags().BoolVar(&importNoUniqueChecks, "no-unique-checks", false, "Disable unique checks during import")
 	importCmd.Flags().BoolVar(&importUseNative, "native", false, "Use native tools (pg_restore/psql for PostgreSQL)")
 	importCmd.Flags().IntVar(&importJobs, "jobs", 0, "Number of parallel jobs for pg_restore (PostgreSQL only)")
+	importCmd.Flags().BoolVar(&importNoOwner, "no-owner", false, "Let pg_restore leave the restored objects to this role (default: the profile's)")
+	importCmd.Flags().BoolVar(&importNoACL, "no-acl", false, "Let pg_restore skip the dumped privileges (default: the profile's)")
 	importCmd.Flags().IntVar(&importParallel, "parallel", 0, "Number of parallel workers for batch execution (0 = sequential)")
+	importCmd.Flags().StringVar(&importReport, "report", "", "Write a summary report to a file (.json for JSON, otherwise text)")
+	importCmd.Flags().BoolVar(&importAnalyze, "analyze", false, "Refresh optimizer statistics (ANALYZE) of the imported tables afterwards")
+	importCmd.Flags().BoolVar(&importVerify, "verify-manifest", false, "Check the loaded row counts against the dump's manifest afterwards")
 }
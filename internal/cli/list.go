@@ -23,6 +23,7 @@ import (
 	"os"
 	"text/tabwriter"
 
+	"github.com/blubskye/yandere_sql_manager/internal/db"
 	"github.com/spf13/cobra"
 )
 
@@ -37,6 +38,8 @@ Examples:
   ysm list tables mydb`,
 }
 
+var listAllDatabases bool
+
 var listDatabasesCmd = &cobra.Command{
 	Use:     "databases",
 	Aliases: []string{"dbs", "db"},
@@ -48,17 +51,26 @@ var listDatabasesCmd = &cobra.Command{
 		}
 		defer conn.Close()
 
-		databases, err := conn.ListDatabases()
+		listDatabases := conn.ListVisibleDatabases
+		if listAllDatabases {
+			listDatabases = conn.ListDatabases
+		}
+
+		databases, err := listDatabases()
 		if err != nil {
 			return fmt.Errorf("failed to list databases: %w", err)
 		}
 
-		fmt.Printf("Databases (%d):\n", len(databases))
-		for _, db := range databases {
-			fmt.Printf("  %s\n", db.Name)
+		if databases == nil {
+			databases = []db.Database{}
 		}
-
-		return nil
+		return printResult(databases, func() error {
+			fmt.Printf("Databases (%d):\n", len(databases))
+			for _, d := range databases {
+				fmt.Printf("  %s\n", d.Name)
+			}
+			return nil
+		})
 	},
 }
 
@@ -92,15 +104,18 @@ var listTablesCmd = &cobra.Command{
 			return fmt.Errorf("failed to list tables: %w", err)
 		}
 
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintf(w, "TABLE\tENGINE\tROWS\n")
-		fmt.Fprintf(w, "-----\t------\t----\n")
-		for _, t := range tables {
-			fmt.Fprintf(w, "%s\t%s\t%d\n", t.Name, t.Engine, t.Rows)
+		if tables == nil {
+			tables = []db.Table{}
 		}
-		w.Flush()
-
-		return nil
+		return printResult(tables, func() error {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "TABLE\tENGINE\tROWS\n")
+			fmt.Fprintf(w, "-----\t------\t----\n")
+			for _, t := range tables {
+				fmt.Fprintf(w, "%s\t%s\t%d\n", t.Name, t.Engine, t.Rows)
+			}
+			return w.Flush()
+		})
 	},
 }
 
@@ -128,24 +143,26 @@ var describeCmd = &cobra.Command{
 			return fmt.Errorf("failed to describe table: %w", err)
 		}
 
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintf(w, "FIELD\tTYPE\tNULL\tKEY\tDEFAULT\tEXTRA\n")
-		fmt.Fprintf(w, "-----\t----\t----\t---\t-------\t-----\n")
-		for _, col := range columns {
-			def := "NULL"
-			if col.Default != nil {
-				def = *col.Default
+		return printResult(columns, func() error {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "FIELD\tTYPE\tNULL\tKEY\tDEFAULT\tEXTRA\n")
+			fmt.Fprintf(w, "-----\t----\t----\t---\t-------\t-----\n")
+			for _, col := range columns {
+				def := "NULL"
+				if col.Default != nil {
+					def = *col.Default
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+					col.Field, col.Type, col.Null, col.Key, def, col.Extra)
 			}
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
-				col.Field, col.Type, col.Null, col.Key, def, col.Extra)
-		}
-		w.Flush()
-
-		return nil
+			return w.Flush()
+		})
 	},
 }
 
 func init() {
+	listDatabasesCmd.Flags().BoolVar(&listAllDatabases, "all", false, "Include system databases hidden by system_databases.show")
+
 	listCmd.AddCommand(listDatabasesCmd)
 	listCmd.AddCommand(listTablesCmd)
 	listCmd.AddCommand(describeCmd)
@@ -19,16 +19,23 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
 	"github.com/spf13/cobra"
 )
 
 var (
 	mergeConflict string
 	mergeCreate   bool
+	mergeYes      bool
+	mergeChunk    int
 )
 
 var mergeCmd = &cobra.Command{
@@ -42,9 +49,15 @@ Conflict handling options:
   append  - Append data to existing tables (requires matching schema)
   rename  - Rename conflicting tables (add source db name as suffix)
 
+Rows are copied in primary key chunks. Ctrl+C stops the merge: a target it
+created is dropped again, otherwise the table being copied is dropped if the
+merge created it. Tables finished before that, and rows appended to existing
+tables, stay.
+
 Examples:
   ysm merge combined db1 db2 db3
   ysm merge combined db1 db2 --conflict=append
+  ysm merge combined db1 db2 --conflict=replace   # Asks for 'combined' to be typed back
   ysm merge newdb db1 db2 --create`,
 	Args: cobra.MinimumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -72,28 +85,61 @@ Examples:
 			return fmt.Errorf("invalid conflict option: %s (use: skip, replace, append, rename)", mergeConflict)
 		}
 
+		// Replacing drops tables of the target, so it has to be named
+		if conflictAction == db.MergeReplace && !mergeYes {
+			exists, err := conn.DatabaseExists(targetDB)
+			if err != nil {
+				return err
+			}
+			if exists {
+				fmt.Printf("WARNING: Tables of '%s' that also exist in a source will be dropped and replaced.\n", targetDB)
+				if !confirmTyped("merge into", []string{targetDB}) {
+					return nil
+				}
+			}
+		}
+
 		fmt.Printf("Merging %d databases into '%s'...\n", len(sourceDBs), targetDB)
 		fmt.Printf("Sources: %s\n", strings.Join(sourceDBs, ", "))
 		fmt.Printf("Conflict handling: %s\n\n", mergeConflict)
 
+		// Ctrl+C cleans up the table being copied instead of leaving it half full
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		var bar *progressPrinter
 		opts := db.MergeOptions{
 			SourceDBs:    sourceDBs,
 			TargetDB:     targetDB,
 			CreateTarget: mergeCreate,
+			ChunkSize:    mergeChunk,
 			ConflictHandler: func(table, sourceDB string) db.MergeConflictAction {
 				fmt.Printf("  Conflict: table '%s' from '%s' - %s\n", table, sourceDB, mergeConflict)
 				return conflictAction
 			},
 			OnProgress: func(sourceDB, table string, sourceNum, totalSources int) {
-				fmt.Printf("\r[%d/%d] %s: %s", sourceNum, totalSources, sourceDB, table)
+				if bar == nil {
+					bar = newProgressPrinter("Merging", progress.Rows, 0)
+				}
+				bar.SetCurrent(sourceDB+"."+table, sourceNum, totalSources)
+				bar.refresh()
+			},
+			OnRows: func(p db.CopyProgress) {
+				bar.SetTotal(p.Total)
+				bar.Set(p.Done)
+				bar.refresh()
 			},
 		}
 
-		if err := conn.MergeDatabases(opts); err != nil {
+		err = conn.MergeDatabasesContext(ctx, opts)
+		if bar != nil {
+			bar.finish()
+		}
+		if err != nil {
 			return fmt.Errorf("merge failed: %w", err)
 		}
 
-		fmt.Println("\nMerge completed successfully!")
+		fmt.Println("Merge completed successfully!")
 		return nil
 	},
 }
@@ -101,6 +147,8 @@ Examples:
 func init() {
 	mergeCmd.Flags().StringVar(&mergeConflict, "conflict", "skip", "Conflict handling: skip, replace, append, rename")
 	mergeCmd.Flags().BoolVar(&mergeCreate, "create", false, "Create target database if it doesn't exist")
+	mergeCmd.Flags().BoolVarP(&mergeYes, "yes", "y", false, "Don't ask for the target name before replacing tables")
+	mergeCmd.Flags().IntVar(&mergeChunk, "chunk-size", db.DefaultCopyChunkSize, "Rows copied per statement")
 
 	rootCmd.AddCommand(mergeCmd)
 }
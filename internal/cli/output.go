@@ -0,0 +1,98 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats for --output
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// structuredOutput reports whether results are printed as JSON or YAML
+// instead of tables and messages
+func structuredOutput() bool {
+	return outputFormat != outputTable
+}
+
+// printResult prints v in the --output format, calling table for the default
+func printResult(v interface{}, table func() error) error {
+	if structuredOutput() {
+		return printStructured(v)
+	}
+	return table()
+}
+
+// printStructured prints v to stdout as JSON or YAML
+func printStructured(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return printStructuredJSON(data)
+}
+
+// printStructuredJSON prints encoded JSON as is, or converted to YAML. The
+// conversion goes through a node tree so keys keep the JSON field order.
+func printStructuredJSON(data []byte) error {
+	if outputFormat != outputYAML {
+		_, err := os.Stdout.Write(append(bytes.TrimRight(data, "\n"), '\n'))
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle drops the flow style JSON parses into, so YAML is written in
+// block style with strings quoted only where needed
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// infof prints a progress or status message. With JSON or YAML output it
+// goes to stderr so stdout holds nothing but the result.
+func infof(format string, args ...interface{}) {
+	if structuredOutput() {
+		fmt.Fprintf(os.Stderr, format, args...)
+		return
+	}
+	fmt.Printf(format, args...)
+}
@@ -0,0 +1,95 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/spf13/cobra"
+)
+
+// ownershipFlags returns the --no-owner and --no-acl choices, taking the
+// connected profile's when a flag isn't given
+func ownershipFlags(cmd *cobra.Command, noOwner, noACL bool) (bool, bool) {
+	p := connectedProfile("")
+	if p == nil {
+		return noOwner, noACL
+	}
+	if !cmd.Flags().Changed("no-owner") {
+		noOwner = p.NoOwner
+	}
+	if !cmd.Flags().Changed("no-acl") {
+		noACL = p.NoACL
+	}
+	return noOwner, noACL
+}
+
+// checkOwnershipFlags rejects --no-owner and --no-acl given for engines they
+// mean nothing to. A profile's choice is dropped there instead
+func checkOwnershipFlags(cmd *cobra.Command, conn *db.Connection, noOwner, noACL *bool) error {
+	if conn.Config.Type == db.DatabaseTypePostgres {
+		return nil
+	}
+	if (cmd.Flags().Changed("no-owner") && *noOwner) || (cmd.Flags().Changed("no-acl") && *noACL) {
+		return fmt.Errorf("--no-owner and --no-acl are for PostgreSQL; %s dumps don't carry owners", conn.Config.Type)
+	}
+	*noOwner, *noACL = false, false
+	return nil
+}
+
+// rememberOwnership saves --no-owner and --no-acl, when given, as the
+// connected profile's choice for later exports and imports
+func rememberOwnership(cmd *cobra.Command, noOwner, noACL bool) error {
+	if !cmd.Flags().Changed("no-owner") && !cmd.Flags().Changed("no-acl") {
+		return nil
+	}
+	name := connectedProfileName("")
+	if name == "" {
+		return nil
+	}
+	p, err := cfg.GetProfile(name)
+	if err != nil {
+		return err
+	}
+	if p.NoOwner == noOwner && p.NoACL == noACL {
+		return nil
+	}
+	p.NoOwner = noOwner
+	p.NoACL = noACL
+	cfg.AddProfile(name, *p)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	infof("Remembered --no-owner=%t --no-acl=%t for profile '%s'\n", noOwner, noACL, name)
+	return nil
+}
+
+// ownershipSummary describes what --no-owner and --no-acl leave out
+func ownershipSummary(noOwner, noACL bool) string {
+	switch {
+	case noOwner && noACL:
+		return "owners and privileges left out"
+	case noOwner:
+		return "owners left out"
+	case noACL:
+		return "privileges left out"
+	}
+	return "kept"
+}
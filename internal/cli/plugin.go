@@ -0,0 +1,188 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/blubskye/yandere_sql_manager/internal/plugin"
+	"github.com/spf13/cobra"
+)
+
+var pluginCmd = &cobra.Command{
+	Use:     "plugin",
+	Aliases: []string{"plugins"},
+	Short:   "Manage plugins",
+	Long: `List and run installed plugins.
+
+Plugins live in ~/.config/ysm/plugins/<name>/ and are described by a
+plugin.yaml manifest. They can provide TUI views, export formats
+(ysm export --format <name>) and post-backup processors.`,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List installed plugins",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := plugin.Discover()
+		if err != nil {
+			return err
+		}
+
+		if len(reg.Plugins) == 0 {
+			dir, _ := plugin.PluginsDir()
+			fmt.Println("No plugins installed.")
+			fmt.Printf("Install plugins into %s\n", dir)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tVERSION\tPROVIDES\tDESCRIPTION")
+		fmt.Fprintln(w, "----\t-------\t--------\t-----------")
+		for _, p := range reg.Plugins {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Manifest.Name, p.Manifest.Version,
+				strings.Join(pluginProvides(p), ","), p.Manifest.Description)
+		}
+		return w.Flush()
+	},
+}
+
+var pluginShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show plugin details",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := plugin.Discover()
+		if err != nil {
+			return err
+		}
+		p, err := reg.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		m := p.Manifest
+		fmt.Printf("Plugin: %s\n", m.Name)
+		if m.Version != "" {
+			fmt.Printf("  Version:     %s\n", m.Version)
+		}
+		if m.Description != "" {
+			fmt.Printf("  Description: %s\n", m.Description)
+		}
+		fmt.Printf("  Directory:   %s\n", p.Dir)
+		fmt.Printf("  Executable:  %s\n", p.ExecutablePath())
+		for _, v := range m.Views {
+			fmt.Printf("  View:        %s (%s)\n", v.Name, v.Title)
+		}
+		for _, f := range m.ExportFormats {
+			fmt.Printf("  Export:      %s %s\n", f.Name, f.Description)
+		}
+		if m.PostBackup {
+			fmt.Println("  Post-backup: yes")
+		}
+		return nil
+	},
+}
+
+var pluginRunCmd = &cobra.Command{
+	Use:   "run <plugin> <view>",
+	Short: "Render a plugin view in the terminal",
+	Long: `Render a plugin view without starting the TUI.
+
+Examples:
+  ysm plugin run checksum backups`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reg, err := plugin.Discover()
+		if err != nil {
+			return err
+		}
+		p, err := reg.Get(args[0])
+		if err != nil {
+			return err
+		}
+
+		var ref *plugin.ViewRef
+		for _, v := range reg.Views() {
+			if v.Plugin == p && v.View.Name == args[1] {
+				ref = &v
+				break
+			}
+		}
+		if ref == nil {
+			return fmt.Errorf("plugin '%s' has no view '%s'", args[0], args[1])
+		}
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		resp, err := reg.RenderView(conn, *ref, database)
+		if err != nil {
+			return err
+		}
+
+		if resp.Title != "" {
+			fmt.Println(resp.Title)
+			fmt.Println()
+		}
+		if resp.Content != "" {
+			fmt.Println(resp.Content)
+		}
+		if len(resp.Columns) > 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, strings.Join(resp.Columns, "\t"))
+			for _, row := range resp.Rows {
+				fmt.Fprintln(w, strings.Join(row, "\t"))
+			}
+			w.Flush()
+		}
+		if resp.Message != "" {
+			fmt.Printf("\n%s\n", resp.Message)
+		}
+		return nil
+	},
+}
+
+// pluginProvides summarizes the extension points a plugin implements
+func pluginProvides(p *plugin.Plugin) []string {
+	var provides []string
+	if len(p.Manifest.Views) > 0 {
+		provides = append(provides, "views")
+	}
+	if len(p.Manifest.ExportFormats) > 0 {
+		provides = append(provides, "export")
+	}
+	if p.Manifest.PostBackup {
+		provides = append(provides, "post-backup")
+	}
+	return provides
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginListCmd)
+	pluginCmd.AddCommand(pluginShowCmd)
+	pluginCmd.AddCommand(pluginRunCmd)
+}
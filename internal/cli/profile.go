@@ -21,12 +21,25 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/spf13/cobra"
 )
 
+var (
+	profileFolder string
+	profileTags   []string
+
+	profileOnConnectAdd    []string
+	profileOnConnectRemove int
+	profileOnConnectClear  bool
+
+	profileStartupView  string
+	profileStartupClear bool
+)
+
 var profileCmd = &cobra.Command{
 	Use:   "profile",
 	Short: "Manage connection profiles",
@@ -76,7 +89,8 @@ var profileAddCmd = &cobra.Command{
 Examples:
   ysm profile add local -H localhost -u root
   ysm profile add production -H db.example.com -u admin -P 3307
-  ysm profile add pglocal -t postgres -H localhost -u postgres`,
+  ysm profile add pglocal -t postgres -H localhost -u postgres
+  ysm profile add acme-prod -H db.acme.com -u admin --folder clients/acme --tags prod,eu`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := args[0]
@@ -95,6 +109,8 @@ Examples:
 			Password: password,
 			Socket:   socket,
 			Database: database,
+			Folder:   profileFolder,
+			Tags:     profileTags,
 		}
 
 		// Validate required fields
@@ -188,6 +204,12 @@ var profileShowCmd = &cobra.Command{
 		if p.Socket != "" {
 			fmt.Printf("  Socket:   %s\n", p.Socket)
 		}
+		if p.Folder != "" {
+			fmt.Printf("  Folder:   %s\n", p.Folder)
+		}
+		if len(p.Tags) > 0 {
+			fmt.Printf("  Tags:     %s\n", strings.Join(p.Tags, ", "))
+		}
 		if p.Password != "" {
 			fmt.Printf("  Password: ****\n")
 		}
@@ -197,6 +219,19 @@ var profileShowCmd = &cobra.Command{
 				fmt.Printf("    %s = %s\n", k, v)
 			}
 		}
+		if p.StartupDatabase != "" || p.StartupView != "" {
+			view, database, _ := p.Startup()
+			if database != "" {
+				view += " of " + database
+			}
+			fmt.Printf("  Startup:  %s\n", view)
+		}
+		if len(p.OnConnect) > 0 {
+			fmt.Println("  On connect:")
+			for _, stmt := range p.OnConnect {
+				fmt.Printf("    %s\n", stmt)
+			}
+		}
 		if name == cfg.DefaultProfile {
 			fmt.Println("  (default)")
 		}
@@ -311,6 +346,127 @@ Examples:
 	},
 }
 
+var profileOnConnectCmd = &cobra.Command{
+	Use:   "on-connect <profile>",
+	Short: "List or change the statements run on every session",
+	Long: `List, add or remove the statements run on every session YSM opens
+with a profile - the interactive one, the connections of background jobs
+and reconnects - so they all behave the way the application expects.
+Unlike set-var values, which are set once on connect, these run on each
+new session of the pool. A statement that fails fails the connection.
+
+Examples:
+  ysm profile on-connect app
+  ysm profile on-connect app --add "SET time_zone = '+00:00'"
+  ysm profile on-connect app --add "SET sql_mode = 'STRICT_TRANS_TABLES'"
+  ysm profile on-connect pg --add "SET search_path = app, public" --add "SET application_name = 'ysm'"
+  ysm profile on-connect app --remove 2
+  ysm profile on-connect app --clear`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profileName := args[0]
+
+		p, err := cfg.GetProfile(profileName)
+		if err != nil {
+			return err
+		}
+
+		changed := false
+		if profileOnConnectClear {
+			p.OnConnect = nil
+			changed = true
+		}
+		if profileOnConnectRemove > 0 {
+			if profileOnConnectRemove > len(p.OnConnect) {
+				return fmt.Errorf("profile '%s' has %d on connect statement(s)", profileName, len(p.OnConnect))
+			}
+			i := profileOnConnectRemove - 1
+			p.OnConnect = append(p.OnConnect[:i], p.OnConnect[i+1:]...)
+			changed = true
+		}
+		for _, stmt := range profileOnConnectAdd {
+			if stmt = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(stmt), ";")); stmt != "" {
+				p.OnConnect = append(p.OnConnect, stmt)
+				changed = true
+			}
+		}
+
+		if changed {
+			cfg.AddProfile(profileName, *p)
+			if err := cfg.Save(); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+		}
+
+		if len(p.OnConnect) == 0 {
+			fmt.Printf("No on connect statements for profile '%s'.\n", profileName)
+			return nil
+		}
+		fmt.Printf("On connect statements for profile '%s':\n\n", profileName)
+		for i, stmt := range p.OnConnect {
+			fmt.Printf("  %d. %s\n", i+1, stmt)
+		}
+		return nil
+	},
+}
+
+var profileStartupCmd = &cobra.Command{
+	Use:   "startup <profile>",
+	Short: "Show or change where the TUI opens after connecting",
+	Long: `Show or change where the TUI opens after connecting with a profile: the
+database to select and the view to start in, instead of the database list.
+A startup database, given with -d/--database, on its own opens its tables.
+
+Views: ` + strings.Join(config.StartupViews, ", ") + `
+
+Examples:
+  ysm profile startup monitoring --view dashboard
+  ysm profile startup dev --database app
+  ysm profile startup dev --database app --view query
+  ysm profile startup dev --clear`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profileName := args[0]
+
+		p, err := cfg.GetProfile(profileName)
+		if err != nil {
+			return err
+		}
+
+		changed := false
+		if profileStartupClear {
+			p.StartupDatabase, p.StartupView = "", ""
+			changed = true
+		}
+		if cmd.Flags().Changed("database") {
+			p.StartupDatabase = strings.TrimSpace(database)
+			changed = true
+		}
+		if cmd.Flags().Changed("view") {
+			p.StartupView = strings.TrimSpace(profileStartupView)
+			changed = true
+		}
+
+		view, database, err := p.Startup()
+		if err != nil {
+			return err
+		}
+		if changed {
+			cfg.AddProfile(profileName, *p)
+			if err := cfg.Save(); err != nil {
+				return fmt.Errorf("failed to save config: %w", err)
+			}
+		}
+
+		if database != "" {
+			fmt.Printf("Profile '%s' opens %s of %s on connect.\n", profileName, view, database)
+		} else {
+			fmt.Printf("Profile '%s' opens %s on connect.\n", profileName, view)
+		}
+		return nil
+	},
+}
+
 func init() {
 	profileCmd.AddCommand(profileListCmd)
 	profileCmd.AddCommand(profileAddCmd)
@@ -320,4 +476,16 @@ func init() {
 	profileCmd.AddCommand(profileSetVarCmd)
 	profileCmd.AddCommand(profileUnsetVarCmd)
 	profileCmd.AddCommand(profileVarsCmd)
+	profileCmd.AddCommand(profileOnConnectCmd)
+	profileCmd.AddCommand(profileStartupCmd)
+
+	profileAddCmd.Flags().StringVar(&profileFolder, "folder", "", "Folder to group the profile under in the TUI")
+	profileAddCmd.Flags().StringSliceVar(&profileTags, "tags", nil, "Comma-separated tags for finding the profile in the TUI")
+
+	profileOnConnectCmd.Flags().StringArrayVar(&profileOnConnectAdd, "add", nil, "Add a statement to run on every session (repeatable)")
+	profileOnConnectCmd.Flags().IntVar(&profileOnConnectRemove, "remove", 0, "Remove the statement with this number")
+	profileOnConnectCmd.Flags().BoolVar(&profileOnConnectClear, "clear", false, "Remove every statement")
+
+	profileStartupCmd.Flags().StringVar(&profileStartupView, "view", "", "View to open on connect (empty for the default)")
+	profileStartupCmd.Flags().BoolVar(&profileStartupClear, "clear", false, "Open the database list again")
 }
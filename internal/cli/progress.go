@@ -0,0 +1,61 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+)
+
+// progressPrinter redraws a tracker's status line in place. Progress
+// callbacks can come from parallel workers, so refresh is locked.
+type progressPrinter struct {
+	*progress.Tracker
+	mu    sync.Mutex
+	last  time.Time
+	quiet bool // JSON or YAML output: keep stdout machine-readable
+}
+
+func newProgressPrinter(label string, unit progress.Unit, total int64) *progressPrinter {
+	return &progressPrinter{Tracker: progress.New(label, unit, total), quiet: structuredOutput()}
+}
+
+// refresh redraws the line, at most every 100ms
+func (p *progressPrinter) refresh() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.quiet || time.Since(p.last) < 100*time.Millisecond {
+		return
+	}
+	p.last = time.Now()
+	fmt.Printf("\r%s\033[K", p.Snapshot().Line(24))
+}
+
+// finish draws the final state and ends the line
+func (p *progressPrinter) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.last.IsZero() {
+		return
+	}
+	fmt.Printf("\r%s\033[K\n", p.Snapshot().Line(24))
+}
@@ -24,21 +24,60 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/blubskye/yandere_sql_manager/internal/db"
 	"github.com/spf13/cobra"
 )
 
+var (
+	queryExecute   string
+	queryParams    []string
+	queryTranslate string
+)
+
+// queryJSONResult is what query prints with --json for a query returning rows
+type queryJSONResult struct {
+	Columns  []string   `json:"columns"`
+	Rows     [][]string `json:"rows"`
+	RowCount int        `json:"row_count"`
+}
+
+// execJSONResult is what query prints with --json for any other statement
+type execJSONResult struct {
+	RowsAffected int64 `json:"rows_affected"`
+}
+
 var queryCmd = &cobra.Command{
-	Use:   "query <sql>",
+	Use:   "query [<sql> | -e <sql>]",
 	Short: "Execute a SQL query",
 	Long: `Execute a SQL query and display results.
 
 Examples:
   ysm query "SELECT * FROM users LIMIT 10" -d mydb
   ysm query "SHOW DATABASES"
-  ysm query "INSERT INTO users (name) VALUES ('test')" -d mydb`,
-	Args: cobra.MinimumNArgs(1),
+  ysm query "INSERT INTO users (name) VALUES ('test')" -d mydb
+  ysm query -e "SELECT id, name FROM users" -d mydb --profile prod --json
+
+Prepared statements (values are bound, never interpolated; \N binds NULL):
+  ysm query "SELECT * FROM users WHERE id = ?" --param 42 -d mydb
+  ysm query "UPDATE users SET name = $1 WHERE id = $2" --param alice --param 7 -t postgres
+
+Print a query rewritten for another engine instead of running it:
+  ysm query --translate sqlserver "SELECT * FROM users ORDER BY id LIMIT 10"`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		sql := strings.Join(args, " ")
+		if queryExecute != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("give the SQL either as an argument or with -e, not both")
+			}
+			sql = queryExecute
+		}
+		if strings.TrimSpace(sql) == "" {
+			return fmt.Errorf("no SQL given (pass it as an argument or with -e)")
+		}
+
+		if queryTranslate != "" {
+			return printTranslatedQuery(sql, queryTranslate)
+		}
 
 		conn, err := connect()
 		if err != nil {
@@ -52,6 +91,12 @@ Examples:
 			}
 		}
 
+		// Check bind parameters against the query's placeholders
+		bindArgs := db.BindArgs(queryParams)
+		if n := db.CountPlaceholders(sql, conn.Config.Type); n != len(bindArgs) {
+			return fmt.Errorf("query expects %d parameter(s) but %d were given (use --param)", n, len(bindArgs))
+		}
+
 		// Determine if this is a SELECT/SHOW query
 		upperSQL := strings.ToUpper(strings.TrimSpace(sql))
 		isQuery := strings.HasPrefix(upperSQL, "SELECT") ||
@@ -60,11 +105,19 @@ Examples:
 			strings.HasPrefix(upperSQL, "EXPLAIN")
 
 		if isQuery {
-			result, err := conn.Query(sql)
+			result, err := conn.Query(sql, bindArgs...)
 			if err != nil {
 				return fmt.Errorf("query failed: %w", err)
 			}
 
+			if structuredOutput() {
+				rows := result.Rows
+				if rows == nil {
+					rows = [][]string{}
+				}
+				return printStructured(queryJSONResult{Columns: result.Columns, Rows: rows, RowCount: len(result.Rows)})
+			}
+
 			if len(result.Columns) == 0 {
 				fmt.Println("No results")
 				return nil
@@ -91,14 +144,39 @@ Examples:
 
 			fmt.Printf("\n%d row(s) returned\n", len(result.Rows))
 		} else {
-			affected, err := conn.Execute(sql)
+			affected, err := conn.Execute(sql, bindArgs...)
 			if err != nil {
 				return fmt.Errorf("execution failed: %w", err)
 			}
 
+			if structuredOutput() {
+				return printStructured(execJSONResult{RowsAffected: affected})
+			}
+
 			fmt.Printf("Query OK, %d row(s) affected\n", affected)
 		}
 
 		return nil
 	},
 }
+
+// printTranslatedQuery prints a query rewritten for another engine, with
+// what needs manual review on stderr
+func printTranslatedQuery(sql, dialectName string) error {
+	dialect, err := db.ParseOutputDialect(dialectName)
+	if err != nil {
+		return err
+	}
+	translated, issues := db.TranslateQuery(sql, dialect)
+	fmt.Println(translated)
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stderr, "Note: %s\n", issue)
+	}
+	return nil
+}
+
+func init() {
+	queryCmd.Flags().StringVarP(&queryExecute, "execute", "e", "", "SQL to run (instead of passing it as an argument)")
+	queryCmd.Flags().StringVar(&queryTranslate, "translate", "", "Print the query rewritten for sqlserver or oracle instead of running it")
+	queryCmd.Flags().StringArrayVar(&queryParams, "param", nil, "Bind a value to the next placeholder (? or $n); repeatable")
+}
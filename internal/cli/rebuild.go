@@ -0,0 +1,177 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rebuildEngine    string
+	rebuildCharset   string
+	rebuildCollation string
+	rebuildRowFormat string
+	rebuildChunkSize int
+	rebuildDropOld   bool
+	rebuildDryRun    bool
+	rebuildRevert    bool
+	rebuildCleanup   bool
+	rebuildYes       bool
+)
+
+var rebuildCmd = &cobra.Command{
+	Use:   "rebuild <database>.<table>",
+	Short: "Rebuild a MariaDB table online with a new engine, charset or row format",
+	Long: `Rebuild a MariaDB/MySQL table while it stays readable and writable, for
+changes that would otherwise lock it for the whole ALTER TABLE when gh-ost or
+pt-online-schema-change isn't available.
+
+A shadow table _<table>_new is created with the new settings and filled in
+primary key order, --chunk-size rows at a time, while triggers copy every
+concurrent insert, update and delete. An atomic RENAME TABLE then swaps it in
+and the original is kept as _<table>_old (unless --drop-old) so --revert can
+swap it back. Ctrl+C before the swap removes the triggers and shadow table;
+--cleanup does the same for a rebuild that was killed.
+
+The table needs a primary key, and can't have triggers of its own or be
+referenced by foreign keys.
+
+Examples:
+  ysm rebuild shop.orders --charset utf8mb4 --collation utf8mb4_unicode_ci
+  ysm rebuild shop.logs --engine InnoDB --row-format COMPRESSED --dry-run
+  ysm rebuild shop.orders --revert
+  ysm rebuild shop.orders --cleanup`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		database, table, ok := strings.Cut(args[0], ".")
+		if !ok || database == "" || table == "" {
+			return fmt.Errorf("table must be in format: database.table")
+		}
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if rebuildCleanup {
+			if err := conn.CleanupOnlineRebuild(database, table); err != nil {
+				return err
+			}
+			infof("Removed the rebuild triggers and shadow table of %s.%s\n", database, table)
+			return nil
+		}
+
+		if rebuildRevert {
+			if !rebuildYes && !confirmPrompt(fmt.Sprintf("Swap the original %s.%s back in? Rows written since the rebuild are only in the rebuilt table", database, table)) {
+				return nil
+			}
+			if err := conn.RevertOnlineRebuild(database, table); err != nil {
+				return err
+			}
+			infof("Reverted %s.%s; the rebuilt table is now _%s_new\n", database, table, table)
+			return nil
+		}
+
+		plan, err := conn.PlanOnlineRebuild(db.OnlineRebuildOptions{
+			Database:  database,
+			Table:     table,
+			Engine:    rebuildEngine,
+			Charset:   rebuildCharset,
+			Collation: rebuildCollation,
+			RowFormat: rebuildRowFormat,
+			ChunkSize: rebuildChunkSize,
+			DropOld:   rebuildDropOld,
+		})
+		if err != nil {
+			return err
+		}
+
+		infof("Rebuilding %s.%s (~%d rows)\n", database, table, plan.EstimatedRows)
+		infof("  Now:   %s\n  After: %s\n\n", plan.Current, plan.Target)
+		if rebuildDryRun {
+			for _, stmt := range plan.Statements() {
+				fmt.Printf("%s;\n", stmt)
+			}
+		}
+		for _, note := range plan.Notes {
+			infof("  - %s\n", note)
+		}
+		if rebuildDryRun {
+			return nil
+		}
+		if !rebuildYes && !confirmPrompt("\nStart the rebuild?") {
+			return nil
+		}
+
+		// Ctrl+C before the swap rolls back instead of leaving triggers behind
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		bar := newProgressPrinter("Copying", progress.Rows, plan.EstimatedRows)
+		result, err := conn.RunOnlineRebuild(ctx, plan, func(p db.OnlineRebuildProgress) {
+			if p.Phase != db.RebuildPhaseCopy {
+				bar.SetCurrent(p.Phase, 0, 0)
+			}
+			bar.Set(p.Copied)
+			bar.refresh()
+		})
+		bar.finish()
+		if err != nil {
+			return err
+		}
+
+		if structuredOutput() {
+			return printStructured(result)
+		}
+		fmt.Printf("Rebuilt %s.%s: %d rows in %d chunks, %s\n",
+			database, table, result.Copied, result.Chunks, progress.FormatDuration(result.Duration))
+		if result.Old != "" {
+			fmt.Printf("The original table is kept as %s.%s; revert with: ysm rebuild %s.%s --revert\n", database, result.Old, database, table)
+		}
+		for _, w := range result.Warnings {
+			fmt.Printf("Warning: %s\n", w)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rebuildCmd.Flags().StringVar(&rebuildEngine, "engine", "", "Storage engine of the rebuilt table (e.g. InnoDB, Aria)")
+	rebuildCmd.Flags().StringVar(&rebuildCharset, "charset", "", "Convert the table to this character set")
+	rebuildCmd.Flags().StringVar(&rebuildCollation, "collation", "", "Collation for --charset")
+	rebuildCmd.Flags().StringVar(&rebuildRowFormat, "row-format", "", "Row format (e.g. DYNAMIC, COMPRESSED)")
+	rebuildCmd.Flags().IntVar(&rebuildChunkSize, "chunk-size", db.DefaultRebuildChunkSize, "Rows copied per statement")
+	rebuildCmd.Flags().BoolVar(&rebuildDropOld, "drop-old", false, "Drop the original table after the swap instead of keeping it")
+	rebuildCmd.Flags().BoolVar(&rebuildDryRun, "dry-run", false, "Show the statements without running them")
+	rebuildCmd.Flags().BoolVar(&rebuildRevert, "revert", false, "Swap back the original table an earlier rebuild kept")
+	rebuildCmd.Flags().BoolVar(&rebuildCleanup, "cleanup", false, "Remove the triggers and shadow table of an interrupted rebuild")
+	rebuildCmd.Flags().BoolVarP(&rebuildYes, "yes", "y", false, "Don't ask for confirmation")
+
+	rootCmd.AddCommand(rebuildCmd)
+}
@@ -0,0 +1,348 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/alert"
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportFormat string
+	reportOut    string
+	reportTo     []string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Build and send health reports",
+	Long: `Build a health report of a server: the healthcheck results, grants
+worth a second look (as in ysm user audit) and how old each database's
+newest full backup is.
+
+Reports are scheduled per profile in the reports section of the config
+file, and sent by ysm scheduler run through the email and webhooks of the
+alerts section. A database never backed up is CRITICAL; one whose newest
+backup is older than backup_max_age (default 7d) warns. Only backups taken
+with the report's profile count.
+
+Subcommands:
+  show  - Print a report
+  send  - Send a report now
+  list  - List the scheduled reports
+
+Examples:
+  ysm report show --profile prod
+  ysm report show --profile prod --format html -o report.html
+  ysm report send --profile prod --to dba@example.com`,
+}
+
+var reportShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print a health report",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := connectedProfileName("")
+		settings := reportSettings(name)
+		format, maxAge, err := settings.Settings()
+		if err != nil {
+			return err
+		}
+		if !cmd.Flags().Changed("format") && reportOut == "" {
+			format = db.DigestFormatMarkdown // HTML is for files and email
+		}
+		if format, err = reportFormatFlag(cmd, format); err != nil {
+			return err
+		}
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		digest := conn.BuildHealthDigest(db.DigestOptions{
+			Profile:      name,
+			Thresholds:   db.DefaultHealthThresholds(),
+			BackupMaxAge: maxAge,
+		})
+		text := digest.Markdown()
+		if format == db.DigestFormatHTML {
+			text = digest.HTML()
+		}
+		if reportOut == "" {
+			fmt.Print(text)
+			return nil
+		}
+		if err := os.WriteFile(reportOut, []byte(text), 0644); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		fmt.Printf("Wrote the %s report for %s to %s\n", digest.Status(), digest.Server, reportOut)
+		return nil
+	},
+}
+
+var reportSendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Send a health report now",
+	Long: `Build a health report and send it now through the email and webhooks
+of the alerts section, to the report's recipients or those given with --to.
+The scheduled report counts as sent.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := connectedProfileName("")
+		settings := reportSettings(name)
+		if len(reportTo) > 0 {
+			settings.To = reportTo
+		}
+		if cmd.Flags().Changed("format") {
+			settings.Format = reportFormat
+		}
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		digest, err := sendReport(conn, settings)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Sent the %s health report for %s.\n", digest.Status(), digest.Server)
+		return nil
+	},
+}
+
+// reportEntry is a scheduled report as ysm report list shows it
+type reportEntry struct {
+	Profile  string    `json:"profile"`
+	Schedule string    `json:"schedule"`
+	Format   string    `json:"format"`
+	To       []string  `json:"to,omitempty"`
+	LastSent time.Time `json:"last_sent,omitempty"`
+	Next     time.Time `json:"next"`
+}
+
+var reportListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the scheduled health reports",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sent, err := db.LoadDigestsSent()
+		if err != nil {
+			return err
+		}
+
+		var reports []config.ReportConfig
+		if cfg != nil {
+			reports = cfg.Reports
+		}
+
+		now := time.Now()
+		entries := []reportEntry{}
+		for _, r := range reports {
+			schedule, err := r.Schedule()
+			if err != nil {
+				return err
+			}
+			format, _, err := r.Settings()
+			if err != nil {
+				return err
+			}
+			entry := reportEntry{
+				Profile:  r.Profile,
+				Schedule: schedule.String(),
+				Format:   format,
+				To:       r.To,
+				LastSent: sent.Sent[r.Profile],
+				Next:     schedule.NextSlot(now),
+			}
+			if schedule.Due(entry.LastSent, now) {
+				entry.Next = now
+			}
+			entries = append(entries, entry)
+		}
+
+		return printResult(entries, func() error {
+			if len(entries) == 0 {
+				fmt.Println("No scheduled reports. Add them under reports in the config file.")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PROFILE\tSCHEDULE\tFORMAT\tTO\tLAST SENT\tNEXT")
+			for _, e := range entries {
+				to, last, next := "alerts email", "never", e.Next.Format("2006-01-02 15:04")
+				if len(e.To) > 0 {
+					to = strings.Join(e.To, ", ")
+				}
+				if !e.LastSent.IsZero() {
+					last = e.LastSent.Format("2006-01-02 15:04")
+				}
+				if !e.Next.After(now) {
+					next = "due"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Profile, e.Schedule, e.Format, to, last, next)
+			}
+			return w.Flush()
+		})
+	},
+}
+
+// reportSettings returns the report settings of a profile
+func reportSettings(name string) config.ReportConfig {
+	if cfg == nil {
+		return config.ReportConfig{Profile: name}
+	}
+	return cfg.Report(name)
+}
+
+// reportFormatFlag returns --format when given, checked, or format
+func reportFormatFlag(cmd *cobra.Command, format string) (string, error) {
+	if !cmd.Flags().Changed("format") {
+		return format, nil
+	}
+	if !slices.Contains(db.DigestFormats, reportFormat) {
+		return "", fmt.Errorf("unknown report format %q (use %s)", reportFormat, strings.Join(db.DigestFormats, ", "))
+	}
+	return reportFormat, nil
+}
+
+// sendReport builds a profile's health report and sends it, recording it
+// as sent
+func sendReport(conn *db.Connection, settings config.ReportConfig) (*db.HealthDigest, error) {
+	var alerts *alert.Config
+	if cfg != nil {
+		alerts = cfg.Alerts
+	}
+	if err := alerts.Validate(); err != nil {
+		return nil, err
+	}
+	format, maxAge, err := settings.Settings()
+	if err != nil {
+		return nil, err
+	}
+
+	digest := conn.BuildHealthDigest(db.DigestOptions{
+		Profile:      settings.Profile,
+		Thresholds:   db.DefaultHealthThresholds(),
+		BackupMaxAge: maxAge,
+	})
+	if err := alert.SendDigest(alerts, digest, format, settings.To); err != nil {
+		return digest, err
+	}
+	if settings.Profile != "" {
+		if err := db.MarkDigestSent(settings.Profile, digest.Time); err != nil {
+			return digest, err
+		}
+	}
+	return digest, nil
+}
+
+// sendDueReports sends the scheduled reports that are due, connecting once
+// per profile. A server that can't be reached gets a report saying so.
+func sendDueReports(conns map[string]*db.Connection) error {
+	if cfg == nil || len(cfg.Reports) == 0 {
+		return nil
+	}
+	sent, err := db.LoadDigestsSent()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	failed := 0
+	for _, r := range cfg.Reports {
+		schedule, err := r.Schedule()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed++
+			continue
+		}
+		if !schedule.Due(sent.Sent[r.Profile], now) {
+			continue
+		}
+		if _, err := cfg.GetProfile(r.Profile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error in reports: %v\n", err)
+			failed++
+			continue
+		}
+
+		conn, ok := conns[r.Profile]
+		if !ok {
+			conn, err = connectProfile(r.Profile)
+			if err != nil {
+				if err := sendUnreachableReport(r, err); err != nil {
+					fmt.Fprintf(os.Stderr, "Error sending the report for '%s': %v\n", r.Profile, err)
+					failed++
+				}
+				continue
+			}
+			conns[r.Profile] = conn
+		}
+
+		digest, err := sendReport(conn, r)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error sending the report for '%s': %v\n", r.Profile, err)
+			failed++
+			continue
+		}
+		fmt.Printf("[%s] Sent the %s health report for %s\n", time.Now().Format("15:04:05"), digest.Status(), r.Profile)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d report(s) could not be sent", failed)
+	}
+	return nil
+}
+
+// sendUnreachableReport sends the report of a server YSM couldn't connect to
+func sendUnreachableReport(r config.ReportConfig, connErr error) error {
+	format, _, err := r.Settings()
+	if err != nil {
+		return err
+	}
+	digest := db.UnreachableHealthDigest(r.Profile, connErr)
+	if err := alert.SendDigest(cfg.Alerts, digest, format, r.To); err != nil {
+		return err
+	}
+	fmt.Printf("[%s] Sent the %s health report for %s: %v\n", time.Now().Format("15:04:05"), digest.Status(), r.Profile, connErr)
+	return db.MarkDigestSent(r.Profile, digest.Time)
+}
+
+func init() {
+	reportShowCmd.Flags().StringVar(&reportFormat, "format", "", "Report format: markdown or html (default markdown, or the report's format with -o)")
+	reportShowCmd.Flags().StringVarP(&reportOut, "output", "o", "", "File to write instead of printing")
+	reportSendCmd.Flags().StringVar(&reportFormat, "format", "", "Report format: html or markdown (default the report's format, or html)")
+	reportSendCmd.Flags().StringSliceVar(&reportTo, "to", nil, "Email recipients instead of the report's")
+
+	reportCmd.AddCommand(reportShowCmd)
+	reportCmd.AddCommand(reportSendCmd)
+	reportCmd.AddCommand(reportListCmd)
+	rootCmd.AddCommand(reportCmd)
+}
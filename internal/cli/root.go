@@ -19,6 +19,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -26,6 +27,7 @@ import (
 	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
 	"github.com/blubskye/yandere_sql_manager/internal/logging"
+	"github.com/blubskye/yandere_sql_manager/internal/scratch"
 	"github.com/blubskye/yandere_sql_manager/internal/tui"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
@@ -49,6 +51,16 @@ var (
 	logFile    string
 	stackTrace bool
 
+	// Protection flags
+	unlockProtected bool
+
+	// Scratch space flags
+	tempDir string
+
+	// Output flags
+	outputFormat string
+	jsonOutput   bool
+
 	// Flag changed tracking
 	typeChanged bool
 	hostChanged bool
@@ -65,9 +77,34 @@ var rootCmd = &cobra.Command{
 
 A TUI and CLI tool for managing MariaDB and PostgreSQL databases.
 Run without arguments to start the interactive TUI.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Initialize logging based on flags
 		initLogging()
+
+		policy := cfg.SystemDatabasePolicy()
+		if unlockProtected {
+			policy.Unlocked = true
+		}
+		db.SetSystemDatabasePolicy(policy)
+
+		scratchSettings, err := cfg.ScratchSettings()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if tempDir != "" {
+			scratchSettings.Dir = tempDir
+		}
+		scratch.Configure(scratchSettings)
+
+		// --json is short for --output json
+		if jsonOutput {
+			outputFormat = outputJSON
+		}
+		switch outputFormat {
+		case outputTable, outputJSON, outputYAML:
+			return nil
+		}
+		return fmt.Errorf("unknown output format: %s (use: table, json, yaml)", outputFormat)
 	},
 	PreRun: func(cmd *cobra.Command, args []string) {
 		typeChanged = cmd.Flag("type").Changed
@@ -99,6 +136,16 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Write logs to file (in addition to stderr)")
 	rootCmd.PersistentFlags().BoolVar(&stackTrace, "stack-trace", false, "Show stack traces on errors")
 
+	// Protection flags
+	rootCmd.PersistentFlags().BoolVar(&unlockProtected, "unlock-protected", false, "Allow dropping or truncating system and protected databases")
+
+	// Scratch space flags
+	rootCmd.PersistentFlags().StringVar(&tempDir, "temp-dir", "", "Directory for large temporary files (default: temp.dir in the config, then $TMPDIR)")
+
+	// Output flags
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", outputTable, "Output format: table, json, yaml (commands writing files take -o/--output as the path)")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Short for --output json")
+
 	// Add subcommands
 	rootCmd.AddCommand(connectCmd)
 	rootCmd.AddCommand(listCmd)
@@ -112,7 +159,19 @@ func init() {
 	rootCmd.AddCommand(dbCmd)
 	rootCmd.AddCommand(statsCmd)
 	rootCmd.AddCommand(clusterCmd)
+	rootCmd.AddCommand(snippetCmd)
+	rootCmd.AddCommand(pluginCmd)
+	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(schedulerCmd)
+	rootCmd.AddCommand(demoCmd)
+	rootCmd.AddCommand(healthcheckCmd)
+	rootCmd.AddCommand(alertsCmd)
+	rootCmd.AddCommand(webhooksCmd)
+	rootCmd.AddCommand(timelineCmd)
+	rootCmd.AddCommand(auditCmd)
 	rootCmd.AddCommand(versionCmd)
+
+	registerCompletions()
 }
 
 func initConfig() {
@@ -163,7 +222,36 @@ func initLogging() {
 
 // Execute runs the root command
 func Execute() error {
-	return rootCmd.Execute()
+	defer scratch.Cleanup()
+	err := rootCmd.Execute()
+	if hint := db.ExplainError(err); hint != nil {
+		fmt.Fprintf(os.Stderr, "\n%s: %s\nFix: %s\n", hint.Title, hint.Explanation, hint.Fix)
+	}
+	return err
+}
+
+// exitCodeError makes the process exit with a specific code, for commands
+// whose callers read it (monitoring checks)
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return 1
 }
 
 // getConnectionConfig returns the connection configuration from flags or profile
@@ -298,7 +386,7 @@ func startTUI() error {
 		}
 	}
 
-	return tui.Run(connCfg)
+	return tui.Run(connCfg, profile)
 }
 
 var versionCmd = &cobra.Command{
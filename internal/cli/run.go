@@ -0,0 +1,202 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/playbook"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runVars    []string
+	runDryRun  bool
+	runOnError string
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <playbook.yaml>",
+	Short: "Run a maintenance playbook",
+	Long: `Run a YAML playbook of maintenance steps.
+
+A playbook (or runbook) is a list of steps executed in order. Each step
+performs one action: connect, export, import, sql, verify, notify, backup,
+clone, anonymize or grant. Execution stops at the first failing step unless
+the step sets continue_on_error; notify steps with "when: failure" or
+"when: always" still run afterwards. With "on_error: continue" in the
+playbook (or --on-error continue) every step runs and the playbook still
+fails if any step did.
+
+Values can reference {{var}} placeholders from the playbook's vars section,
+--var flags, {{env.NAME}} environment variables and the built-ins {{date}},
+{{time}} and {{timestamp}}.
+
+Example runbook:
+  name: refresh-staging
+  profile: prod
+  steps:
+    - backup:
+        databases: [app]
+        compression: zstd
+    - clone:
+        source: app
+        target: app_staging
+        drop: true
+    - anonymize:
+        database: app_staging
+        mask: masking.yaml
+    - grant:
+        user: qa
+        host: "%"
+        database: app_staging
+        template: read-only
+
+Example playbook:
+  name: nightly-cleanup
+  profile: prod
+  steps:
+    - name: Backup before cleanup
+      export:
+        database: app
+        output: /backups/app-{{date}}.sql.zst
+    - name: Purge expired sessions
+      sql:
+        database: app
+        query: DELETE FROM sessions WHERE expires_at < NOW()
+    - name: Sessions table is sane
+      verify:
+        query: SELECT COUNT(*) FROM sessions
+        max: 100000
+    - notify:
+        when: failure
+        message: "nightly-cleanup failed: {{error}}"
+        webhook: https://hooks.example.com/ysm
+
+Examples:
+  ysm run nightly-cleanup.yaml
+  ysm run refresh-staging.yaml --var source=app --var target=app_staging
+  ysm run nightly-cleanup.yaml --dry-run
+  ysm run refresh-staging.yaml --on-error continue`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pb, err := playbook.Load(args[0])
+		if err != nil {
+			return err
+		}
+		if runOnError != "" {
+			pb.OnError = runOnError
+			if err := pb.Validate(); err != nil {
+				return err
+			}
+		}
+
+		vars := make(map[string]string)
+		for _, v := range runVars {
+			key, value, ok := strings.Cut(v, "=")
+			if !ok || key == "" {
+				return fmt.Errorf("invalid --var '%s', expected name=value", v)
+			}
+			vars[key] = value
+		}
+
+		name := pb.Name
+		if name == "" {
+			name = args[0]
+		}
+		fmt.Printf("Running playbook '%s' (%d steps)\n", name, len(pb.Steps))
+		if pb.Description != "" {
+			fmt.Println(pb.Description)
+		}
+		if runDryRun {
+			fmt.Println("Dry run: nothing will be executed")
+		}
+		fmt.Println()
+
+		runner := &playbook.Runner{
+			Connect: connectProfile,
+			Out:     os.Stdout,
+			Vars:    vars,
+			DryRun:  runDryRun,
+		}
+
+		start := time.Now()
+		results, err := runner.Run(pb)
+
+		ok, failed, skipped := 0, 0, 0
+		for _, r := range results {
+			switch {
+			case r.Skipped:
+				skipped++
+			case r.Err != nil:
+				failed++
+			default:
+				ok++
+			}
+		}
+		fmt.Printf("\n%d ok, %d failed, %d skipped in %s\n", ok, failed, skipped, time.Since(start).Round(time.Millisecond))
+		return err
+	},
+}
+
+// connectProfile connects using a named profile, or the global flags when empty
+func connectProfile(name string) (*db.Connection, error) {
+	if name == "" {
+		return connect()
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("profile '%s' not found", name)
+	}
+
+	p, err := cfg.GetProfile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	connCfg := p.ToConnectionConfig()
+	if connCfg.Password == "" {
+		fmt.Printf("Profile '%s': ", name)
+		pwd, err := promptPassword()
+		if err != nil {
+			return nil, err
+		}
+		connCfg.Password = pwd
+	}
+
+	conn, err := db.Connect(connCfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Variables) > 0 {
+		if err := conn.ApplyVariables(p.Variables); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to apply profile variables: %v\n", err)
+		}
+	}
+	return conn, nil
+}
+
+func init() {
+	runCmd.Flags().StringArrayVar(&runVars, "var", nil, "Set a playbook variable (name=value, repeatable)")
+	runCmd.Flags().BoolVar(&runDryRun, "dry-run", false, "Show the steps without executing them")
+	runCmd.Flags().StringVar(&runOnError, "on-error", "", "Override the playbook's on_error: stop or continue")
+}
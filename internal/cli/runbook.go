@@ -0,0 +1,280 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runbookOutput    string
+	runbookAll       bool
+	runbookNoConnect bool
+)
+
+var backupRunbookCmd = &cobra.Command{
+	Use:   "runbook",
+	Short: "Write a disaster recovery runbook for a profile",
+	Long: `Write a Markdown disaster recovery runbook for the on-call wiki. For each
+database of the profile it names the backup to restore from (the newest one
+verified with 'ysm backup verify' or already restored once, else the newest),
+where its files are, the commands that verify, check and restore it, and how
+long the restore should take going by earlier restores. The server is
+connected to for its replication topology unless --no-connect is given.
+
+Backups made without --profile count as the default profile's.
+
+Examples:
+  ysm --profile prod backup runbook
+  ysm --profile prod backup runbook -o prod-dr.md
+  ysm backup runbook --all -o wiki/dr/          # One <profile>-dr-runbook.md each`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cfg == nil || len(cfg.Profiles) == 0 {
+			return fmt.Errorf("runbooks are written per profile, and no profiles are saved")
+		}
+
+		names := []string{profile}
+		if runbookAll {
+			names = cfg.ListProfiles()
+			sort.Strings(names)
+		} else if profile == "" {
+			if cfg.DefaultProfile == "" {
+				return fmt.Errorf("use --profile, --all or set a default profile")
+			}
+			names = []string{cfg.DefaultProfile}
+		}
+
+		var runbooks []*db.DRRunbook
+		for _, name := range names {
+			runbook, err := buildRunbook(name)
+			if err != nil {
+				return err
+			}
+			runbooks = append(runbooks, runbook)
+		}
+
+		if structuredOutput() {
+			if runbookAll {
+				return printStructured(runbooks)
+			}
+			return printStructured(runbooks[0])
+		}
+
+		if !runbookAll {
+			if runbookOutput == "" {
+				fmt.Print(runbookMarkdown(runbooks[0]))
+				return nil
+			}
+			if err := os.WriteFile(runbookOutput, []byte(runbookMarkdown(runbooks[0])), 0644); err != nil {
+				return fmt.Errorf("failed to write runbook: %w", err)
+			}
+			fmt.Printf("Runbook for '%s' written to %s\n", runbooks[0].Profile, runbookOutput)
+			return nil
+		}
+
+		dir := runbookOutput
+		if dir == "" {
+			dir = "."
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		for _, runbook := range runbooks {
+			path := filepath.Join(dir, runbook.Profile+"-dr-runbook.md")
+			if err := os.WriteFile(path, []byte(runbookMarkdown(runbook)), 0644); err != nil {
+				return fmt.Errorf("failed to write runbook: %w", err)
+			}
+			fmt.Printf("Runbook for '%s' written to %s\n", runbook.Profile, path)
+		}
+		return nil
+	},
+}
+
+// buildRunbook gathers a profile's runbook, with its topology unless
+// --no-connect is given or the server can't be reached
+func buildRunbook(name string) (*db.DRRunbook, error) {
+	p, err := cfg.GetProfile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	runbook, err := db.BuildDRRunbook(name, p.ToConnectionConfig().ServerKey(), name == cfg.DefaultProfile)
+	if err != nil {
+		return nil, err
+	}
+	if runbookNoConnect {
+		return runbook, nil
+	}
+
+	conn, err := connectProfile(name)
+	if err != nil {
+		runbook.TopologyError = err.Error()
+		return runbook, nil
+	}
+	defer conn.Close()
+
+	status, err := conn.GetClusterStatus()
+	if err != nil {
+		runbook.TopologyError = err.Error()
+		return runbook, nil
+	}
+	runbook.Topology = status
+	return runbook, nil
+}
+
+// runbookMarkdown renders a runbook for a wiki
+func runbookMarkdown(r *db.DRRunbook) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Disaster recovery runbook: %s\n\n", r.Profile)
+	fmt.Fprintf(&b, "Server `%s`. Generated by ysm on %s; regenerate with `ysm --profile %s backup runbook`.\n\n",
+		r.Server, r.Generated.Format("2006-01-02 15:04 MST"), r.Profile)
+
+	b.WriteString("## Replication topology\n\n")
+	b.WriteString(runbookTopology(r))
+
+	b.WriteString("## Backups\n\n")
+	if len(r.Databases) == 0 {
+		fmt.Fprintf(&b, "**No backups of this profile were found in `%s`.** Take one now with `ysm --profile %s backup create`.\n\n",
+			r.BackupsDir, r.Profile)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Backups live in `%s`, one directory per backup ID holding the dump files and a `metadata.json` with their checksums. "+
+		"If this machine is gone too, copy a backup directory from wherever it is replicated to the same place on the new machine first.\n\n", r.BackupsDir)
+	b.WriteString("| Database | Restore from | Taken | Size | Verified | Last restored | Estimated restore |\n")
+	b.WriteString("|---|---|---|---|---|---|---|\n")
+	var unproven []string
+	for _, d := range r.Databases {
+		backup := d.Recommended()
+		if !backup.Proven() {
+			unproven = append(unproven, d.Database)
+		}
+		fmt.Fprintf(&b, "| %s | `%s` | %s | %s | %s | %s | %s |\n",
+			d.Database, backup.ID, backup.Timestamp.Format("2006-01-02 15:04"), db.FormatSize(backup.Size),
+			runbookDate(backup.VerifiedAt), runbookDate(backup.LastRestored), runbookEstimate(d.Estimate))
+	}
+	b.WriteString("\n")
+	if len(unproven) > 0 {
+		fmt.Fprintf(&b, "**Never verified or restored:** %s. Run `ysm backup verify <id>` on their latest backups, or rehearse a restore with `--target-profile`.\n\n",
+			strings.Join(unproven, ", "))
+	}
+
+	b.WriteString("## Restore\n\n")
+	b.WriteString("Run these in order for each database that needs restoring. `backup check` compares the backup with the server " +
+		"and stops on anything that blocks the restore. To restore to a replacement server, save a profile for it and add " +
+		"`--target-profile <profile>` to the check and restore commands.\n\n")
+	for _, d := range r.Databases {
+		backup := d.Recommended()
+		fmt.Fprintf(&b, "### %s\n\n", d.Database)
+		b.WriteString("```sh\n")
+		fmt.Fprintf(&b, "ysm --profile %s backup verify %s\n", r.Profile, backup.ID)
+		fmt.Fprintf(&b, "ysm --profile %s backup check %s %s --drop\n", r.Profile, backup.ID, d.Database)
+		fmt.Fprintf(&b, "ysm --profile %s backup restore %s %s --drop\n", r.Profile, backup.ID, d.Database)
+		b.WriteString("```\n\n")
+		fmt.Fprintf(&b, "- File: `%s`\n", backup.Path)
+		fmt.Fprintf(&b, "- Estimated restore time: %s\n", runbookEstimate(d.Estimate))
+		if !backup.Checksummed {
+			b.WriteString("- This backup has no recorded checksums, so `backup verify` can't check it\n")
+		}
+		if d.Latest.ID != backup.ID {
+			fmt.Fprintf(&b, "- A newer, unverified backup exists: `%s` from %s. Use it if losing the data since %s is worse than an untested backup\n",
+				d.Latest.ID, d.Latest.Timestamp.Format("2006-01-02 15:04"), backup.Timestamp.Format("2006-01-02 15:04"))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// runbookTopology describes the server's replication for the runbook
+func runbookTopology(r *db.DRRunbook) string {
+	switch {
+	case r.TopologyError != "":
+		return fmt.Sprintf("The server couldn't be reached when this was generated: %s\n\n", r.TopologyError)
+	case r.Topology == nil:
+		return "Not checked (generated with `--no-connect`).\n\n"
+	case r.Topology.Type == db.ClusterTypeNone:
+		return "Standalone server, no cluster or replication. Everything comes back from backups.\n\n"
+	}
+
+	s := r.Topology
+	var b strings.Builder
+	fmt.Fprintf(&b, "- Type: %s\n", formatClusterType(s.Type))
+	fmt.Fprintf(&b, "- This server: %s\n", formatRole(s.IsPrimary))
+	fmt.Fprintf(&b, "- Healthy: %s\n", formatBool(s.IsHealthy))
+	fmt.Fprintf(&b, "- Nodes: %d\n", s.NodeCount)
+	if s.ErrorMessage != "" {
+		fmt.Fprintf(&b, "- Warning: %s\n", s.ErrorMessage)
+	}
+	b.WriteString("\n")
+
+	if len(s.Nodes) > 0 {
+		b.WriteString("| Node | Role | State | Lag |\n|---|---|---|---|\n")
+		for _, n := range s.Nodes {
+			address := n.Address
+			if n.Port > 0 {
+				address = fmt.Sprintf("%s:%d", n.Address, n.Port)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %.0fs |\n", address, n.Role, n.State, n.LagSeconds)
+		}
+		b.WriteString("\n")
+	}
+	if s.IsPrimary {
+		b.WriteString("Before restoring, consider promoting a healthy replica instead (`ysm cluster`). " +
+			"Replicas of a restored primary have to be re-pointed or re-seeded.\n\n")
+	} else {
+		b.WriteString("This server is a replica: re-seeding it from the primary may be faster than restoring a backup.\n\n")
+	}
+	return b.String()
+}
+
+// runbookDate formats a runbook date, or "never"
+func runbookDate(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format("2006-01-02")
+}
+
+// runbookEstimate describes a restore estimate and what it's based on
+func runbookEstimate(e db.RestoreEstimate) string {
+	if !e.Known() {
+		return "unknown, no restores recorded yet"
+	}
+	where := "to this server"
+	if !e.SameServer {
+		where = "to other servers"
+	}
+	return fmt.Sprintf("~%s (from %d restore(s) %s)", progress.FormatDuration(e.Duration), e.Samples, where)
+}
+
+func init() {
+	backupRunbookCmd.Flags().StringVarP(&runbookOutput, "output", "o", "", "Write to this file (with --all, this directory) instead of stdout")
+	backupRunbookCmd.Flags().BoolVar(&runbookAll, "all", false, "Write a runbook for every profile")
+	backupRunbookCmd.Flags().BoolVar(&runbookNoConnect, "no-connect", false, "Don't connect to the server for its replication topology")
+}
@@ -0,0 +1,222 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/spf13/cobra"
+)
+
+var safetyBackup bool
+
+// safetyBackupSettings reports whether a command takes a safety backup, from
+// its --safety-backup flag or else the config, and how long they are kept
+func safetyBackupSettings(cmd *cobra.Command) (bool, time.Duration, error) {
+	enabled, expire := false, config.DefaultSafetyBackupExpire
+	if cfg != nil {
+		var err error
+		if enabled, expire, err = cfg.SafetyBackupSettings(); err != nil {
+			return false, expire, err
+		}
+	}
+	if cmd.Flags().Changed("safety-backup") {
+		enabled = safetyBackup
+	}
+	return enabled, expire, nil
+}
+
+// takeSafetyBackup backs up what an operation is about to replace, expiring
+// old safety backups first. A failed backup stops the operation.
+func takeSafetyBackup(conn *db.Connection, expire time.Duration, opts db.SafetyBackupOptions) error {
+	if opts.Empty() {
+		return nil
+	}
+	if expired, err := db.ExpireSafetyBackups(expire); err != nil {
+		infof("Warning: failed to expire old safety backups: %v\n", err)
+	} else if len(expired) > 0 {
+		infof("Expired %d safety backup(s) older than %s\n", len(expired), progress.FormatDuration(expire))
+	}
+
+	infof("Taking a safety backup before %s...\n", opts.Operation)
+	metadata, err := conn.CreateSafetyBackup(opts)
+	if err != nil {
+		return fmt.Errorf("%w (use --safety-backup=false to go ahead without one)", err)
+	}
+	if metadata == nil {
+		return nil
+	}
+	infof("Safety backup %s taken (%s). Undo with: ysm backup undo %s\n", metadata.ID, db.FormatSize(metadata.TotalSize), metadata.ID)
+	return nil
+}
+
+// safetyBackupContents describes what a safety backup holds, e.g.
+// "shop, blog (posts, tags)"
+func safetyBackupContents(b db.BackupMetadata) string {
+	parts := make([]string, len(b.Databases))
+	for i, name := range b.Databases {
+		parts[i] = name
+		if tables, ok := b.Tables[name]; ok {
+			parts[i] += " (" + strings.Join(tables, ", ") + ")"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+var backupSafetyCmd = &cobra.Command{
+	Use:   "safety",
+	Short: "List the safety backups taken before destructive operations",
+	Long: `List the safety backups taken before destructive operations.
+
+With --safety-backup, or safety_backups.enabled in the config, 'ysm db drop',
+'ysm import' and 'ysm backup restore' first back up what they are about to
+drop: whole databases, or only the tables an import or restore replaces.
+Safety backups are kept apart from regular backups and are deleted once
+older than safety_backups.expire (default 7d). Undo an operation with
+'ysm backup undo'.
+
+Examples:
+  ysm backup safety
+  ysm backup safety --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		backups, err := db.ListSafetyBackups()
+		if err != nil {
+			return err
+		}
+
+		if structuredOutput() {
+			if backups == nil {
+				backups = []db.BackupMetadata{}
+			}
+			return printStructured(backups)
+		}
+
+		if len(backups) == 0 {
+			fmt.Println("No safety backups found.")
+			return nil
+		}
+
+		_, expire, err := safetyBackupSettings(cmd)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tDATE\tEXPIRES\tSIZE\tTAKEN\tCONTENTS")
+		fmt.Fprintln(w, "--\t----\t-------\t----\t-----\t--------")
+		for _, b := range backups {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				b.ID,
+				b.Timestamp.Format("2006-01-02 15:04"),
+				b.Timestamp.Add(expire).Format("2006-01-02 15:04"),
+				db.FormatSize(b.TotalSize),
+				b.Description,
+				safetyBackupContents(b),
+			)
+		}
+		return w.Flush()
+	},
+}
+
+var backupUndoCmd = &cobra.Command{
+	Use:   "undo [safety-backup-id]",
+	Short: "Put back what a drop, import or restore replaced",
+	Long: `Restore a safety backup, undoing the operation it was taken before.
+Without an ID the newest safety backup is used.
+
+Databases backed up whole are dropped and restored, so anything written to
+them since is lost. Databases only partly backed up get their tables
+replaced and the rest left alone.
+
+Examples:
+  ysm backup undo
+  ysm backup undo 20240101-120000 -y`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var metadata *db.BackupMetadata
+		if len(args) == 1 {
+			var err error
+			if metadata, err = db.GetSafetyBackup(args[0]); err != nil {
+				return err
+			}
+		} else {
+			backups, err := db.ListSafetyBackups()
+			if err != nil {
+				return err
+			}
+			if len(backups) == 0 {
+				return fmt.Errorf("no safety backups to undo")
+			}
+			metadata = &backups[0]
+		}
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		infof("Safety backup %s, taken %s %s\n", metadata.ID, metadata.Description, metadata.Timestamp.Format("2006-01-02 15:04"))
+		var whole []string
+		for _, name := range metadata.Databases {
+			if _, ok := metadata.Tables[name]; !ok {
+				whole = append(whole, name)
+			}
+		}
+		if len(whole) > 0 {
+			infof("WARNING: %s will be dropped on %s and restored as it was.\n", strings.Join(whole, ", "), conn.Config.Host)
+		}
+		if !assumeYes && !confirmPrompt(fmt.Sprintf("Restore %s?", safetyBackupContents(*metadata))) {
+			return nil
+		}
+
+		bar := newProgressPrinter("Undoing", progress.Percent, 0)
+		err = conn.UndoSafetyBackup(metadata.ID, func(database string, dbNum, totalDBs int, percent float64, rows, bytes int64) {
+			bar.SetTotal(int64(totalDBs) * 100)
+			bar.Set(int64(dbNum-1)*100 + int64(percent))
+			bar.SetRows(rows)
+			bar.SetBytes(bytes)
+			bar.SetCurrent(database, dbNum, totalDBs)
+			bar.refresh()
+		})
+		bar.finish()
+		if err != nil {
+			return fmt.Errorf("undo failed: %w", err)
+		}
+
+		if structuredOutput() {
+			return printStructured(map[string]interface{}{"undone": metadata.ID, "databases": metadata.Databases})
+		}
+		fmt.Printf("\nRestored %s from safety backup %s.\n", safetyBackupContents(*metadata), metadata.ID)
+		return nil
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{dbDropCmd, importCmd, backupRestoreCmd} {
+		c.Flags().BoolVar(&safetyBackup, "safety-backup", false, "Back up what is about to be dropped first, to undo with 'ysm backup undo' (default: safety_backups.enabled)")
+	}
+}
@@ -0,0 +1,159 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var schedulerWatch time.Duration
+
+var schedulerCmd = &cobra.Command{
+	Use:   "scheduler",
+	Short: "Run scheduled maintenance tasks",
+	Long: `Run scheduled maintenance tasks.
+
+Subcommands:
+  run  - Revoke expired temporary grants and send due health reports`,
+}
+
+var schedulerRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Revoke expired temporary grants and send due health reports",
+	Long: `Revoke temporary grants (ysm user grant --expires) that have expired,
+and send the health reports (see ysm report) that are due.
+
+Each grant is revoked through the profile it was granted with, and only if
+that profile still points at the server the grant was made on. Failed
+revocations are kept and retried on the next run, as are reports that
+couldn't be sent.
+
+Run it from cron, or keep it running with --watch:
+  */5 * * * * ysm scheduler run
+  ysm scheduler run --watch 1m`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Connections stay open between watch runs so profiles without a
+		// saved password only prompt once
+		conns := make(map[string]*db.Connection)
+		defer closeConnections(conns)
+
+		if schedulerWatch <= 0 {
+			return runScheduledTasks(conns)
+		}
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		ticker := time.NewTicker(schedulerWatch)
+		defer ticker.Stop()
+
+		fmt.Printf("Watching for expired grants and due reports every %s (Ctrl+C to stop)\n", schedulerWatch)
+		for {
+			if err := runScheduledTasks(conns); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-sig:
+				return nil
+			}
+		}
+	},
+}
+
+// runScheduledTasks revokes expired grants and sends due reports
+func runScheduledTasks(conns map[string]*db.Connection) error {
+	return errors.Join(revokeDueGrants(conns), sendDueReports(conns))
+}
+
+// revokeDueGrants revokes all expired temporary grants, connecting once per profile
+func revokeDueGrants(conns map[string]*db.Connection) error {
+	due, err := db.GetDueTemporaryGrants()
+	if err != nil {
+		return err
+	}
+
+	// One pass per profile; RevokeExpiredGrants handles every due grant on that server
+	done := make(map[string]bool)
+	failed := 0
+	for _, g := range due {
+		key := g.Profile + "\x00" + g.Server
+		if done[key] {
+			continue
+		}
+		done[key] = true
+
+		conn, ok := conns[g.Profile]
+		if !ok {
+			conn, err = connectProfile(g.Profile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Cannot revoke grants made via profile '%s': %v\n", g.Profile, err)
+				failed++
+				continue
+			}
+			conns[g.Profile] = conn
+		}
+
+		if conn.ServerKey() != g.Server {
+			fmt.Fprintf(os.Stderr, "Skipping grant %s: profile '%s' now points at %s, not %s\n",
+				g.ID, g.Profile, conn.ServerKey(), g.Server)
+			failed++
+			continue
+		}
+
+		revoked, err := conn.RevokeExpiredGrants()
+		for _, r := range revoked {
+			fmt.Printf("[%s] Revoked %s on %s from '%s'@'%s' (%s, expired %s)\n",
+				time.Now().Format("15:04:05"), strings.Join(r.Privileges, ", "), r.Scope(),
+				r.Username, r.Host, r.ID, r.ExpiresAt.Format("2006-01-02 15:04:05"))
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error on %s: %v\n", g.Server, err)
+			failed++
+			// Reconnect next time in case the connection went away
+			conn.Close()
+			delete(conns, g.Profile)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d server(s) had grants that could not be revoked", failed)
+	}
+	return nil
+}
+
+func closeConnections(conns map[string]*db.Connection) {
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+func init() {
+	schedulerRunCmd.Flags().DurationVar(&schedulerWatch, "watch", 0, "Keep running and check at this interval (e.g. 1m)")
+
+	schedulerCmd.AddCommand(schedulerRunCmd)
+}
@@ -0,0 +1,86 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+)
+
+// connectedProfileName returns the name of the profile a connection made by
+// connectProfile(name) uses, or "" when it isn't from a profile
+func connectedProfileName(name string) string {
+	if cfg == nil {
+		return ""
+	}
+	switch {
+	case name != "":
+		return name
+	case profile != "":
+		return profile
+	case cfg.DefaultProfile != "" && user == "":
+		return cfg.DefaultProfile
+	}
+	return ""
+}
+
+// connectedProfile returns the profile a connection made by
+// connectProfile(name) uses, or nil when it isn't from a profile
+func connectedProfile(name string) *config.Profile {
+	name = connectedProfileName(name)
+	if name == "" {
+		return nil
+	}
+	p, err := cfg.GetProfile(name)
+	if err != nil {
+		return nil
+	}
+	return p
+}
+
+// operationScripts combines a profile's scripts with those given as
+// --before-sql and --after-sql files, the profile's running first
+func operationScripts(fromProfile db.OperationScripts, before, after []string) db.OperationScripts {
+	var flags db.OperationScripts
+	for _, file := range before {
+		flags.Before = append(flags.Before, db.Script{File: file})
+	}
+	for _, file := range after {
+		flags.After = append(flags.After, db.Script{File: file})
+	}
+	return fromProfile.Merge(flags)
+}
+
+// printScriptResults lists the before and after scripts that ran
+func printScriptResults(results []db.ScriptResult) {
+	if len(results) == 0 {
+		return
+	}
+	fmt.Printf("\nScripts:\n")
+	for _, r := range results {
+		status := fmt.Sprintf("%d statements in %s", r.Statements, r.Duration.Round(time.Millisecond))
+		if r.Error != "" {
+			status = "FAILED: " + r.Error
+		}
+		fmt.Printf("  %-6s %s: %s\n", r.Phase, r.Script, status)
+	}
+}
@@ -97,6 +97,9 @@ func listCommonVariables(conn *db.Connection) error {
 	if err != nil {
 		return err
 	}
+	if structuredOutput() {
+		return printVariables(variables)
+	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	fmt.Fprintln(w, "VARIABLE\tVALUE")
@@ -114,6 +117,9 @@ func showVariables(conn *db.Connection, pattern string) error {
 	if err != nil {
 		return err
 	}
+	if structuredOutput() {
+		return printVariables(variables)
+	}
 
 	if len(variables) == 0 {
 		fmt.Printf("No variables found matching '%s'\n", pattern)
@@ -131,6 +137,14 @@ func showVariables(conn *db.Connection, pattern string) error {
 	return w.Flush()
 }
 
+// printVariables prints variables as JSON or YAML
+func printVariables(variables []db.Variable) error {
+	if variables == nil {
+		variables = []db.Variable{}
+	}
+	return printStructured(variables)
+}
+
 func init() {
 	setCmd.Flags().BoolVarP(&setGlobal, "global", "g", false, "Set as global variable (requires SUPER privilege)")
 	setCmd.Flags().StringVarP(&setShow, "show", "s", "", "Show variables matching pattern")
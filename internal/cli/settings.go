@@ -0,0 +1,236 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	settingsOutput         string
+	settingsEncrypt        bool
+	settingsNoPasswords    bool
+	settingsPassphraseFile string
+	settingsOverwrite      bool
+	settingsDryRun         bool
+)
+
+var settingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Move profiles and settings between machines",
+	Long: `Export all connection profiles, settings, keybindings and snippets to one
+portable file, and import it on another machine.`,
+}
+
+var settingsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export profiles, settings, keybindings and snippets to a file",
+	Long: `Export config.yaml, keybindings.yaml and snippets.yaml to a single file.
+
+Profile passwords, webhook secrets and the alert SMTP password are included
+unless --no-passwords is given. With --encrypt the file is sealed with
+AES-256-GCM under a key derived from a passphrase, asked for twice or read
+from --passphrase-file.
+
+Examples:
+  ysm settings export -o ysm-settings.yaml
+  ysm settings export -o ysm-settings.enc --encrypt
+  ysm settings export -o team-profiles.yaml --no-passwords`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if settingsOutput == "" {
+			return fmt.Errorf("give the file to write with -o/--output")
+		}
+
+		bundle, err := config.NewSettingsBundle(cfg, !settingsNoPasswords)
+		if err != nil {
+			return err
+		}
+
+		passphrase := ""
+		if settingsEncrypt {
+			if passphrase, err = settingsPassphrase(true); err != nil {
+				return err
+			}
+		}
+		data, err := bundle.Marshal(passphrase)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(settingsOutput, data, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", settingsOutput, err)
+		}
+
+		fmt.Printf("Exported %d profile(s), settings, keybindings and snippets to %s\n", len(bundle.Config.Profiles), settingsOutput)
+		if passphrase == "" && bundle.HasSecrets() {
+			fmt.Fprintln(os.Stderr, "Warning: the file holds passwords in plain text; use --encrypt or --no-passwords to avoid that.")
+		}
+		return nil
+	},
+}
+
+var settingsImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import profiles, settings, keybindings and snippets from a file",
+	Long: `Import a file written by 'ysm settings export', asking for the passphrase
+when it is encrypted.
+
+Profiles, snippets and settings that are missing here are added, and
+keybindings customized in the file replace the defaults. Anything set both
+here and in the file is kept as it is unless --overwrite is given; a file
+exported without passwords never erases the passwords here.
+
+Examples:
+  ysm settings import ysm-settings.yaml --dry-run
+  ysm settings import ysm-settings.enc
+  ysm settings import ysm-settings.yaml --overwrite`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", args[0], err)
+		}
+
+		passphrase := ""
+		if config.IsEncryptedBundle(data) {
+			if passphrase, err = settingsPassphrase(false); err != nil {
+				return err
+			}
+		}
+		bundle, err := config.ParseSettingsBundle(data, passphrase)
+		if err != nil {
+			return err
+		}
+
+		// Merge into what is on disk, not the empty config a broken file leaves
+		current, err := config.Load()
+		if err != nil {
+			return err
+		}
+		kb, err := config.LoadKeyBindings()
+		if err != nil {
+			return err
+		}
+		lib, err := config.LoadSnippets()
+		if err != nil {
+			return err
+		}
+
+		result := bundle.Apply(current, kb, lib, settingsOverwrite)
+		printSettingsImport(result)
+
+		if settingsDryRun {
+			fmt.Println("\nDry run: nothing was saved.")
+			return nil
+		}
+		if err := current.Save(); err != nil {
+			return err
+		}
+		if err := kb.Save(); err != nil {
+			return err
+		}
+		if err := lib.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("\nImported settings from %s\n", args[0])
+		return nil
+	},
+}
+
+// printSettingsImport lists what an import changes
+func printSettingsImport(r *config.SettingsImport) {
+	line := func(label string, names []string) {
+		if len(names) > 0 {
+			fmt.Printf("%-20s %s\n", label+":", strings.Join(names, ", "))
+		}
+	}
+	line("Profiles added", r.ProfilesAdded)
+	line("Profiles replaced", r.ProfilesReplaced)
+	line("Profiles kept", r.ProfilesKept)
+	line("Settings taken", r.Settings)
+	line("Settings kept", r.SettingsKept)
+	fmt.Printf("%-20s %d taken, %d kept\n", "Keybindings:", r.KeyBindings, r.KeyBindingsKept)
+	fmt.Printf("%-20s %d added or replaced\n", "Snippets:", r.Snippets)
+
+	if !settingsOverwrite && (len(r.ProfilesKept) > 0 || len(r.SettingsKept) > 0 || r.KeyBindingsKept > 0) {
+		fmt.Println("\nEntries set differently here were kept; use --overwrite to take the file's.")
+	}
+}
+
+// settingsPassphrase reads the passphrase from --passphrase-file or the
+// terminal, asking twice when it is for a new file
+func settingsPassphrase(confirm bool) (string, error) {
+	if settingsPassphraseFile != "" {
+		data, err := os.ReadFile(settingsPassphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+		passphrase := strings.TrimRight(string(data), "\r\n")
+		if passphrase == "" {
+			return "", fmt.Errorf("passphrase file %s is empty", settingsPassphraseFile)
+		}
+		return passphrase, nil
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", errors.New("no terminal to ask for the passphrase; use --passphrase-file")
+	}
+	fmt.Print("Passphrase: ")
+	pass, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if len(pass) == 0 {
+		return "", fmt.Errorf("passphrase is required")
+	}
+	if confirm {
+		fmt.Print("Confirm passphrase: ")
+		again, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		if string(again) != string(pass) {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	return string(pass), nil
+}
+
+func init() {
+	settingsExportCmd.Flags().StringVarP(&settingsOutput, "output", "o", "", "File to write")
+	settingsExportCmd.Flags().BoolVar(&settingsEncrypt, "encrypt", false, "Encrypt the file with a passphrase")
+	settingsExportCmd.Flags().BoolVar(&settingsNoPasswords, "no-passwords", false, "Leave out passwords and webhook secrets")
+	settingsExportCmd.Flags().StringVar(&settingsPassphraseFile, "passphrase-file", "", "Read the passphrase from this file instead of asking")
+	settingsImportCmd.Flags().BoolVar(&settingsOverwrite, "overwrite", false, "Replace profiles, settings, keybindings and snippets set differently here")
+	settingsImportCmd.Flags().BoolVar(&settingsDryRun, "dry-run", false, "Show what would change without saving")
+	settingsImportCmd.Flags().StringVar(&settingsPassphraseFile, "passphrase-file", "", "Read the passphrase from this file instead of asking")
+
+	settingsCmd.AddCommand(settingsExportCmd)
+	settingsCmd.AddCommand(settingsImportCmd)
+	rootCmd.AddCommand(settingsCmd)
+}
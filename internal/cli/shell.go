@@ -0,0 +1,579 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Formats the shell prints results in
+const (
+	shellTable    = "table"
+	shellExpanded = "expanded"
+	shellCSV      = "csv"
+	shellJSON     = "json"
+)
+
+var shellFormats = []string{shellTable, shellExpanded, shellCSV, shellJSON}
+
+var (
+	shellFormat string
+	shellTiming bool
+)
+
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Interactive SQL shell",
+	Long: `Open an interactive SQL shell on the connection - lighter than the TUI
+over a slow link.
+
+Statements can span lines and run when they end with ; (or \g, or \G to
+print one record at a time). History is kept in ~/.config/ysm/shell_history;
+statements mentioning a password are left out of it. Meta commands such as
+\l, \c, \dt, \d, \du, \ps, \x and \format work as in psql; type \? for the list.
+
+Without a terminal, statements are read from stdin and the first error
+stops the shell.
+
+Examples:
+  ysm shell --profile prod -d shop
+  ysm shell -t postgres -u postgres --format expanded
+  ysm shell --profile prod -d shop < fixes.sql`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !slices.Contains(shellFormats, shellFormat) {
+			return fmt.Errorf("unknown format: %s (use: %s)", shellFormat, strings.Join(shellFormats, ", "))
+		}
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		// One connection, so USE, SET, transactions and temporary tables
+		// last the whole session
+		conn.DB.SetMaxOpenConns(1)
+
+		sh := &shell{conn: conn, format: shellFormat, timing: shellTiming}
+		fd := int(os.Stdin.Fd())
+		if !term.IsTerminal(fd) {
+			return sh.runScript(os.Stdin)
+		}
+		return sh.runInteractive(fd)
+	},
+}
+
+// shell runs statements and meta commands typed at its prompt
+type shell struct {
+	conn    *db.Connection
+	format  string
+	timing  bool
+	pending string // Unfinished statement typed so far
+	quit    bool
+}
+
+// runInteractive reads lines with editing and history until \q or Ctrl+D.
+// The terminal is raw only while a line is read, so output and Ctrl+C
+// behave as usual while a statement runs.
+func (sh *shell) runInteractive(fd int) error {
+	history := loadShellHistory()
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "")
+	t.History = history
+
+	version, err := sh.conn.GetServerVersion()
+	if err != nil {
+		version = string(sh.conn.Config.Type)
+	}
+	fmt.Printf("Connected to %s. Type \\? for help, \\q to quit.\n\n", version)
+
+	for !sh.quit {
+		if width, height, err := term.GetSize(fd); err == nil {
+			t.SetSize(width, height)
+		}
+		t.SetPrompt(sh.prompt())
+
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("failed to set up the terminal: %w", err)
+		}
+		line, err := t.ReadLine()
+		term.Restore(fd, state)
+
+		if err == io.EOF {
+			// Ctrl+C or Ctrl+D drops a half-typed statement, and quits at an empty prompt
+			if sh.pending != "" {
+				sh.pending = ""
+				fmt.Println("Statement discarded.")
+				continue
+			}
+			fmt.Println()
+			return nil
+		}
+		if err != nil && !errors.Is(err, term.ErrPasteIndicator) {
+			return err
+		}
+
+		for _, entry := range sh.feed(line, func(err error) {
+			fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		}) {
+			history.record(entry)
+		}
+	}
+	return nil
+}
+
+// runScript runs statements from a file or pipe, stopping at the first error
+func (sh *shell) runScript(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var failed error
+	for !sh.quit && failed == nil && scanner.Scan() {
+		sh.feed(scanner.Text(), func(err error) {
+			failed = err
+		})
+	}
+	if failed != nil {
+		return failed
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// A last statement without a terminator still runs
+	if sql := strings.TrimSpace(sh.pending); sql != "" && !sh.quit {
+		sh.pending = ""
+		return sh.run(shellStatement{sql: sql})
+	}
+	return nil
+}
+
+// prompt shows the database in use, and an arrow while a statement continues
+func (sh *shell) prompt() string {
+	name := sh.conn.Config.Database
+	if name == "" {
+		name = "(none)"
+	}
+	prompt := fmt.Sprintf("ysm:%s> ", name)
+	if sh.pending != "" {
+		return strings.Repeat(" ", len(prompt)-3) + "-> "
+	}
+	return prompt
+}
+
+// feed takes a line of input, running the meta command or the statements
+// it completes. It returns what was run, for the history, and reports
+// errors to fail, stopping at the first.
+func (sh *shell) feed(line string, fail func(error)) []string {
+	trimmed := strings.TrimSpace(line)
+
+	// Meta commands take the whole line, even in the middle of a statement
+	if strings.HasPrefix(trimmed, `\`) && trimmed != `\g` && trimmed != `\G` {
+		if err := sh.meta(trimmed); err != nil {
+			fail(err)
+		}
+		return []string{trimmed}
+	}
+	if sh.pending == "" && (trimmed == "" || strings.HasPrefix(trimmed, "--")) {
+		return nil
+	}
+
+	input := line
+	if sh.pending != "" {
+		input = sh.pending + "\n" + line
+	}
+	statements, rest := splitStatements(input, sh.conn.Config.Type)
+	sh.pending = rest
+
+	var ran []string
+	for _, stmt := range statements {
+		entry := stmt.sql + ";"
+		if stmt.expanded {
+			entry = stmt.sql + `\G`
+		}
+		ran = append(ran, entry)
+		if err := sh.run(stmt); err != nil {
+			fail(err)
+			sh.pending = ""
+			break
+		}
+	}
+	return ran
+}
+
+// run executes a statement and prints its result
+func (sh *shell) run(stmt shellStatement) error {
+	start := time.Now()
+	defer func() {
+		if sh.timing {
+			fmt.Printf("Time: %s\n", time.Since(start).Round(time.Microsecond))
+		}
+	}()
+
+	// USE goes through the driver, which reconnects for PostgreSQL
+	if fields := strings.Fields(stmt.sql); len(fields) == 2 && strings.EqualFold(fields[0], "USE") {
+		return sh.use(strings.Trim(fields[1], "`\""))
+	}
+
+	if returnsRows(stmt.sql) {
+		result, err := sh.conn.Query(stmt.sql)
+		if err != nil {
+			return err
+		}
+		format := sh.format
+		if stmt.expanded {
+			format = shellExpanded
+		}
+		return sh.print(result, format)
+	}
+
+	affected, err := sh.conn.Execute(stmt.sql)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Query OK, %d row(s) affected\n", affected)
+	return nil
+}
+
+// returnsRows tells queries from statements run for their effect
+func returnsRows(sql string) bool {
+	upper := strings.ToUpper(strings.TrimSpace(sql))
+	for _, prefix := range []string{"SELECT", "SHOW", "DESCRIBE", "DESC ", "EXPLAIN", "WITH", "VALUES", "TABLE ", "CHECK ", "ANALYZE TABLE"} {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	return strings.Contains(upper, " RETURNING ")
+}
+
+func (sh *shell) use(name string) error {
+	if err := sh.conn.UseDatabase(name); err != nil {
+		return err
+	}
+	fmt.Printf("Database changed to %s\n", name)
+	return nil
+}
+
+// print writes a result in a format
+func (sh *shell) print(result *db.QueryResult, format string) error {
+	if len(result.Columns) == 0 {
+		fmt.Println("No results")
+		return nil
+	}
+
+	switch format {
+	case shellJSON:
+		rows := result.Rows
+		if rows == nil {
+			rows = [][]string{}
+		}
+		data, err := json.MarshalIndent(queryJSONResult{Columns: result.Columns, Rows: rows, RowCount: len(rows)}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+
+	case shellCSV:
+		w := csv.NewWriter(os.Stdout)
+		w.Write(result.Columns)
+		w.WriteAll(result.Rows)
+		return w.Error()
+
+	case shellExpanded:
+		width := 0
+		for _, col := range result.Columns {
+			width = max(width, len(col))
+		}
+		for i, row := range result.Rows {
+			fmt.Printf("-[ RECORD %d ]%s\n", i+1, strings.Repeat("-", max(width-8, 4)))
+			for j, col := range result.Columns {
+				fmt.Printf("%-*s | %s\n", width, col, row[j])
+			}
+		}
+		fmt.Printf("(%d row(s))\n", len(result.Rows))
+
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(result.Columns, "\t"))
+		sep := make([]string, len(result.Columns))
+		for i, col := range result.Columns {
+			sep[i] = strings.Repeat("-", len(col))
+		}
+		fmt.Fprintln(w, strings.Join(sep, "\t"))
+		for _, row := range result.Rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		w.Flush()
+		fmt.Printf("(%d row(s))\n", len(result.Rows))
+	}
+	return nil
+}
+
+const shellHelp = `Meta commands:
+  \l                  List databases
+  \c <db>             Switch database (also USE <db>;)
+  \dt [pattern]       List tables, optionally matching a * / ? pattern
+  \d [table]          Describe a table (without one, list tables)
+  \di <table>         List a table's indexes
+  \du                 List users
+  \ps                 Show running queries
+  \conninfo           Show the connection
+  \x                  Toggle expanded output
+  \format [fmt]       Show or set the output format: table, expanded, csv, json
+  \timing             Toggle statement timing
+  \p                  Print the statement being typed
+  \r                  Reset the statement being typed
+  \q                  Quit (or Ctrl+D)
+
+End a statement with ; or \g to run it, or \G to print it one record at a time.
+Ctrl+C drops a half-typed statement.`
+
+// meta runs a backslash command
+func (sh *shell) meta(line string) error {
+	fields := strings.Fields(line)
+	name, args := fields[0], fields[1:]
+
+	arg := func() (string, error) {
+		if len(args) == 0 {
+			return "", fmt.Errorf("%s needs an argument (see \\?)", name)
+		}
+		return args[0], nil
+	}
+
+	switch name {
+	case `\q`, `\quit`:
+		sh.quit = true
+
+	case `\?`, `\h`, `\help`:
+		fmt.Println(shellHelp)
+
+	case `\l`:
+		databases, err := sh.conn.ListDatabases()
+		if err != nil {
+			return err
+		}
+		result := &db.QueryResult{Columns: []string{"Database"}}
+		for _, d := range databases {
+			result.Rows = append(result.Rows, []string{d.Name})
+		}
+		return sh.print(result, sh.format)
+
+	case `\c`, `\u`:
+		if len(args) == 0 {
+			fmt.Printf("Using database %s\n", cmp.Or(sh.conn.Config.Database, "(none)"))
+			return nil
+		}
+		return sh.use(args[0])
+
+	case `\d`:
+		if len(args) == 0 {
+			return sh.listTables("")
+		}
+		return sh.describe(args[0])
+
+	case `\dt`:
+		pattern := ""
+		if len(args) > 0 {
+			pattern = args[0]
+		}
+		return sh.listTables(pattern)
+
+	case `\di`:
+		table, err := arg()
+		if err != nil {
+			return err
+		}
+		return sh.listIndexes(table)
+
+	case `\du`:
+		users, err := sh.conn.ListUsers()
+		if err != nil {
+			return err
+		}
+		result := &db.QueryResult{Columns: []string{"User", "Host"}}
+		for _, u := range users {
+			result.Rows = append(result.Rows, []string{u.Username, u.Host})
+		}
+		return sh.print(result, sh.format)
+
+	case `\ps`:
+		sessions, err := sh.conn.RunningQueries()
+		if err != nil {
+			return err
+		}
+		result := &db.QueryResult{Columns: []string{"ID", "User", "Database", "Time", "State", "Query"}}
+		for _, s := range sessions {
+			result.Rows = append(result.Rows, []string{
+				strconv.FormatInt(s.ID, 10), s.User, s.Database,
+				progress.FormatDuration(s.Duration), s.State, strings.Join(strings.Fields(s.Query), " "),
+			})
+		}
+		return sh.print(result, sh.format)
+
+	case `\conninfo`:
+		c := sh.conn.Config
+		where := fmt.Sprintf("%s:%d", c.Host, c.Port)
+		if c.Socket != "" {
+			where = c.Socket
+		}
+		fmt.Printf("Connected to %s at %s as %s, database %s\n", c.Type, where, c.User, cmp.Or(c.Database, "(none)"))
+		if len(c.OnConnect) > 0 {
+			fmt.Println("Run on connect:")
+			for _, stmt := range c.OnConnect {
+				fmt.Printf("  %s\n", stmt)
+			}
+		}
+
+	case `\x`:
+		if sh.format == shellExpanded {
+			sh.format = shellTable
+		} else {
+			sh.format = shellExpanded
+		}
+		fmt.Printf("Output format is %s.\n", sh.format)
+
+	case `\format`:
+		if len(args) > 0 {
+			if !slices.Contains(shellFormats, args[0]) {
+				return fmt.Errorf("unknown format: %s (use: %s)", args[0], strings.Join(shellFormats, ", "))
+			}
+			sh.format = args[0]
+		}
+		fmt.Printf("Output format is %s.\n", sh.format)
+
+	case `\timing`:
+		sh.timing = !sh.timing
+		if sh.timing {
+			fmt.Println("Timing is on.")
+		} else {
+			fmt.Println("Timing is off.")
+		}
+
+	case `\p`:
+		if sh.pending == "" {
+			fmt.Println("Statement is empty.")
+		} else {
+			fmt.Println(sh.pending)
+		}
+
+	case `\r`:
+		sh.pending = ""
+		fmt.Println("Statement reset.")
+
+	default:
+		return fmt.Errorf("unknown command %s (see \\?)", name)
+	}
+	return nil
+}
+
+// listTables lists the tables of the database in use, those matching a
+// * / ? pattern when one is given
+func (sh *shell) listTables(pattern string) error {
+	if pattern != "" {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad pattern %q: %w", pattern, err)
+		}
+	}
+	tables, err := sh.conn.ListTables()
+	if err != nil {
+		return err
+	}
+	result := &db.QueryResult{Columns: []string{"Table", "Engine", "Rows"}}
+	for _, t := range tables {
+		if pattern != "" {
+			if ok, _ := path.Match(pattern, t.Name); !ok {
+				continue
+			}
+		}
+		result.Rows = append(result.Rows, []string{t.Name, t.Engine, strconv.FormatInt(t.Rows, 10)})
+	}
+	return sh.print(result, sh.format)
+}
+
+// describe prints a table's columns, then its indexes
+func (sh *shell) describe(table string) error {
+	columns, err := sh.conn.DescribeTable(table)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("table %s not found in %s", table, cmp.Or(sh.conn.Config.Database, "the current database"))
+	}
+	result := &db.QueryResult{Columns: []string{"Column", "Type", "Null", "Key", "Default", "Extra"}}
+	for _, c := range columns {
+		def := "NULL"
+		if c.Default != nil {
+			def = *c.Default
+		}
+		result.Rows = append(result.Rows, []string{c.Field, c.Type, c.Null, c.Key, def, c.Extra})
+	}
+	if err := sh.print(result, sh.format); err != nil {
+		return err
+	}
+	fmt.Println()
+	return sh.listIndexes(table)
+}
+
+func (sh *shell) listIndexes(table string) error {
+	indexes, err := sh.conn.ListIndexes(table)
+	if err != nil {
+		return err
+	}
+	result := &db.QueryResult{Columns: []string{"Index", "Columns", "Kind", "Type"}}
+	for _, idx := range indexes {
+		kind := ""
+		switch {
+		case idx.Primary:
+			kind = "PRIMARY"
+		case idx.Unique:
+			kind = "UNIQUE"
+		}
+		result.Rows = append(result.Rows, []string{idx.Name, strings.Join(idx.Columns, ", "), kind, idx.Type})
+	}
+	return sh.print(result, sh.format)
+}
+
+func init() {
+	shellCmd.Flags().StringVar(&shellFormat, "format", shellTable, "Output format: table, expanded, csv, json")
+	shellCmd.Flags().BoolVar(&shellTiming, "timing", false, "Print how long each statement took")
+
+	rootCmd.AddCommand(shellCmd)
+}
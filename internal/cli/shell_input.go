@@ -0,0 +1,196 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+)
+
+// shellStatement is one statement typed into the shell
+type shellStatement struct {
+	sql      string
+	expanded bool // Ended with \G, printed one record at a time
+}
+
+// dollarTag matches the opening of a PostgreSQL dollar-quoted string
+var dollarTag = regexp.MustCompile(`^\$[A-Za-z_][A-Za-z0-9_]*\$|^\$\$`)
+
+// splitStatements cuts the complete statements off the input, ended by ;,
+// \g or \G outside quotes and comments, and returns what is left of an
+// unfinished one. MariaDB escapes quotes with backslashes, PostgreSQL has
+// dollar quoting.
+func splitStatements(input string, dbType db.DatabaseType) ([]shellStatement, string) {
+	var statements []shellStatement
+	postgres := dbType == db.DatabaseTypePostgres
+
+	emit := func(sql string, expanded bool) {
+		if sql = strings.TrimSpace(sql); sql != "" {
+			statements = append(statements, shellStatement{sql: sql, expanded: expanded})
+		}
+	}
+
+	start := 0
+	var quote byte
+	var tag string
+	lineComment, blockComment := false, false
+	content := false // Something besides comments and spaces since the last statement
+
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		next := byte(0)
+		if i+1 < len(input) {
+			next = input[i+1]
+		}
+
+		switch {
+		case lineComment:
+			if c == '\n' {
+				lineComment = false
+			}
+		case blockComment:
+			if c == '*' && next == '/' {
+				blockComment = false
+				i++
+			}
+		case tag != "":
+			if strings.HasPrefix(input[i:], tag) {
+				i += len(tag) - 1
+				tag = ""
+			}
+		case quote != 0:
+			if c == '\\' && !postgres {
+				i++
+			} else if c == quote {
+				if next == quote {
+					i++ // Doubled quote
+				} else {
+					quote = 0
+				}
+			}
+		case c == '-' && next == '-', c == '#' && !postgres:
+			lineComment = true
+		case c == '/' && next == '*':
+			blockComment = true
+			i++
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			content = true
+		case c == '$' && postgres:
+			if m := dollarTag.FindString(input[i:]); m != "" {
+				tag = m
+				i += len(m) - 1
+			}
+			content = true
+		case c == ';':
+			emit(input[start:i], false)
+			start = i + 1
+			content = false
+		case c == '\\' && (next == 'g' || next == 'G'):
+			emit(input[start:i], next == 'G')
+			start = i + 2
+			content = false
+			i++
+		case c != ' ' && c != '\t' && c != '\n' && c != '\r':
+			content = true
+		}
+	}
+
+	// A comment after the last statement doesn't start another
+	if !content {
+		return statements, ""
+	}
+	return statements, input[start:]
+}
+
+// shellHistorySize is how many entries the history file keeps
+const shellHistorySize = 1000
+
+// shellHistory keeps what was typed into the shell across sessions, one
+// entry per line of the history file. The terminal adds every line it
+// reads; that is ignored so a statement typed over several lines comes
+// back whole.
+type shellHistory struct {
+	path    string
+	entries []string // Oldest first
+}
+
+// loadShellHistory reads the history file, starting empty without one
+func loadShellHistory() *shellHistory {
+	h := &shellHistory{}
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return h
+	}
+	h.path = filepath.Join(dir, "shell_history")
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		return h
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	return h
+}
+
+// Add is called by the terminal for each line it reads
+func (h *shellHistory) Add(string) {}
+
+// Len returns the number of entries
+func (h *shellHistory) Len() int {
+	return len(h.entries)
+}
+
+// At returns an entry, 0 being the newest
+func (h *shellHistory) At(i int) string {
+	return h.entries[len(h.entries)-1-i]
+}
+
+// record adds a statement or meta command and saves the history. Like the
+// mysql client, anything that may hold a password is left out.
+func (h *shellHistory) record(entry string) {
+	entry = strings.Join(strings.Split(strings.TrimSpace(entry), "\n"), " ")
+	upper := strings.ToUpper(entry)
+	if entry == "" || strings.Contains(upper, "IDENTIFIED") || strings.Contains(upper, "PASSWORD") {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == entry {
+		return
+	}
+
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > shellHistorySize {
+		h.entries = h.entries[len(h.entries)-shellHistorySize:]
+	}
+
+	if h.path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(h.path, []byte(strings.Join(h.entries, "\n")+"\n"), 0600)
+}
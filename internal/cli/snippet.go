@@ -0,0 +1,252 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snippetDescription string
+	snippetOverwrite   bool
+	snippetAllProfiles bool
+)
+
+var snippetCmd = &cobra.Command{
+	Use:     "snippet",
+	Aliases: []string{"snippets"},
+	Short:   "Manage saved queries",
+	Long: `Manage saved, parameterized queries (snippets).
+
+Snippets are stored per profile in ~/.config/ysm/snippets.yaml and can be
+picked from the TUI query editor with Ctrl+O. Use {{name}} placeholders in a
+query to be prompted for values when the snippet is used.`,
+}
+
+// snippetProfile returns the profile name snippets are grouped under
+func snippetProfile() string {
+	if profile != "" {
+		return profile
+	}
+	if cfg != nil {
+		return cfg.DefaultProfile
+	}
+	return ""
+}
+
+var snippetListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List saved queries for the current profile",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lib, err := config.LoadSnippets()
+		if err != nil {
+			return err
+		}
+
+		snippets := lib.ForProfile(snippetProfile())
+		if len(snippets) == 0 {
+			fmt.Println("No snippets saved for this profile.")
+			fmt.Println("Use 'ysm snippet add <name> <sql>' to create one.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tPARAMS\tDESCRIPTION")
+		fmt.Fprintln(w, "----\t------\t-----------")
+		for _, s := range snippets {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, strings.Join(s.Placeholders(), ","), s.Description)
+		}
+		return w.Flush()
+	},
+}
+
+var snippetAddCmd = &cobra.Command{
+	Use:   "add <name> <sql>",
+	Short: "Save a query as a snippet",
+	Long: `Save a query as a snippet for the current profile.
+
+Examples:
+  ysm snippet add recent-orders "SELECT * FROM orders ORDER BY created_at DESC LIMIT 20"
+  ysm snippet add user-by-email "SELECT * FROM users WHERE email = '{{email}}'" --profile prod`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lib, err := config.LoadSnippets()
+		if err != nil {
+			return err
+		}
+
+		snippet := config.Snippet{
+			Name:        args[0],
+			Description: snippetDescription,
+			Query:       strings.Join(args[1:], " "),
+		}
+		if err := lib.AddSnippet(snippetProfile(), snippet); err != nil {
+			return err
+		}
+		if err := lib.Save(); err != nil {
+			return err
+		}
+
+		fmt.Printf("Snippet '%s' saved.\n", snippet.Name)
+		if params := snippet.Placeholders(); len(params) > 0 {
+			fmt.Printf("Parameters: %s\n", strings.Join(params, ", "))
+		}
+		return nil
+	},
+}
+
+var snippetRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm", "delete"},
+	Short:   "Remove a snippet",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lib, err := config.LoadSnippets()
+		if err != nil {
+			return err
+		}
+		if err := lib.RemoveSnippet(snippetProfile(), args[0]); err != nil {
+			return err
+		}
+		if err := lib.Save(); err != nil {
+			return err
+		}
+
+		fmt.Printf("Snippet '%s' removed.\n", args[0])
+		return nil
+	},
+}
+
+var snippetShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a snippet's query",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lib, err := config.LoadSnippets()
+		if err != nil {
+			return err
+		}
+		s, err := lib.GetSnippet(snippetProfile(), args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Snippet: %s\n", s.Name)
+		if s.Description != "" {
+			fmt.Printf("  Description: %s\n", s.Description)
+		}
+		if params := s.Placeholders(); len(params) > 0 {
+			fmt.Printf("  Parameters:  %s\n", strings.Join(params, ", "))
+		}
+		fmt.Printf("\n%s\n", s.Query)
+		return nil
+	},
+}
+
+var snippetExportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Export snippets to a file",
+	Long: `Export saved snippets to a YAML file for sharing or backup.
+
+By default only the current profile's snippets are exported; use --all to
+export every profile.
+
+Examples:
+  ysm snippet export my-snippets.yaml
+  ysm snippet export team-snippets.yaml --all`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lib, err := config.LoadSnippets()
+		if err != nil {
+			return err
+		}
+
+		out := lib
+		if !snippetAllProfiles {
+			out = &config.SnippetLibrary{Profiles: make(map[string][]config.Snippet)}
+			for _, s := range lib.ForProfile(snippetProfile()) {
+				out.AddSnippet(snippetProfile(), s)
+			}
+		}
+
+		if err := out.WriteFile(args[0]); err != nil {
+			return err
+		}
+
+		count := 0
+		for _, snippets := range out.Profiles {
+			count += len(snippets)
+		}
+		fmt.Printf("Exported %d snippet(s) to %s\n", count, args[0])
+		return nil
+	},
+}
+
+var snippetImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import snippets from a file",
+	Long: `Import snippets from a YAML file created with 'ysm snippet export'.
+
+Snippets keep the profile they were exported from. Existing snippets with the
+same name are skipped unless --overwrite is given.
+
+Examples:
+  ysm snippet import team-snippets.yaml
+  ysm snippet import team-snippets.yaml --overwrite`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		other, err := config.ReadSnippetsFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		lib, err := config.LoadSnippets()
+		if err != nil {
+			return err
+		}
+
+		count := lib.Merge(other, snippetOverwrite)
+		if err := lib.Save(); err != nil {
+			return err
+		}
+
+		fmt.Printf("Imported %d snippet(s) from %s\n", count, args[0])
+		return nil
+	},
+}
+
+func init() {
+	snippetAddCmd.Flags().StringVar(&snippetDescription, "description", "", "Short description of the snippet")
+	snippetExportCmd.Flags().BoolVar(&snippetAllProfiles, "all", false, "Export snippets for all profiles")
+	snippetImportCmd.Flags().BoolVar(&snippetOverwrite, "overwrite", false, "Replace existing snippets with the same name")
+
+	snippetCmd.AddCommand(snippetListCmd)
+	snippetCmd.AddCommand(snippetAddCmd)
+	snippetCmd.AddCommand(snippetRemoveCmd)
+	snippetCmd.AddCommand(snippetShowCmd)
+	snippetCmd.AddCommand(snippetExportCmd)
+	snippetCmd.AddCommand(snippetImportCmd)
+}
@@ -19,9 +19,12 @@
 package cli
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/blubskye/yandere_sql_manager/internal/db"
 	"github.com/spf13/cobra"
@@ -37,9 +40,31 @@ Subcommands:
   databases   - Show database sizes
   tables      - Show table sizes
   connections - Show connection info
-  performance - Show performance metrics`,
+  performance - Show performance metrics
+  queries     - Show the top queries from the slow log or pg_stat_statements
+  bloat       - Show the most bloated tables and indexes
+  advise      - Recommend fixes for common schema and maintenance issues
+  tune        - Propose values for key variables for the machine and workload
+  sources     - Show who is connected, grouped by user, host and application`,
 }
 
+var (
+	statsQueriesFile     string
+	statsQueriesSort     string
+	statsQueriesLimit    int
+	statsQueriesOutput   string
+	statsBloatSort       string
+	statsBloatLimit      int
+	statsAdviseChecks    []string
+	statsSourcesDNS      bool
+	statsTuneMemory      string
+	statsTuneCPUs        int
+	statsTuneWorkload    string
+	statsTuneStorage     string
+	statsTuneConnections int
+	statsTuneOutput      string
+)
+
 var statsSummaryCmd = &cobra.Command{
 	Use:   "summary",
 	Short: "Show overall statistics",
@@ -244,6 +269,14 @@ var statsConnectionsCmd = &cobra.Command{
 			fmt.Printf("\n[%s] %.1f%%\n", bar, usage)
 		}
 
+		if len(conn.Config.OnConnect) > 0 {
+			fmt.Println()
+			fmt.Println("Run on every session YSM opens:")
+			for _, stmt := range conn.Config.OnConnect {
+				fmt.Printf("  %s\n", stmt)
+			}
+		}
+
 		return nil
 	},
 }
@@ -289,10 +322,401 @@ var statsPerformanceCmd = &cobra.Command{
 	},
 }
 
+var statsQueriesCmd = &cobra.Command{
+	Use:   "queries",
+	Short: "Show the top queries from the slow log or pg_stat_statements",
+	Long: `Aggregate slow queries by normalized fingerprint and show the top ones.
+
+MariaDB/MySQL queries come from the mysql.slow_log table, or from the slow
+query log file when the server runs on this machine. PostgreSQL queries come
+from pg_stat_statements. Use --file to analyze a copied slow log instead.
+
+Examples:
+  ysm stats queries
+  ysm stats queries --sort mean --limit 10
+  ysm stats queries --file /var/log/mysql/slow.log --json -o slow.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sortBy := map[string]db.QueryStatSort{
+			"total": db.SortByTotalTime,
+			"mean":  db.SortByMeanTime,
+			"calls": db.SortByCalls,
+		}
+		by, ok := sortBy[statsQueriesSort]
+		if !ok {
+			return fmt.Errorf("invalid sort %q (use total, mean or calls)", statsQueriesSort)
+		}
+
+		var report *db.SlowQueryReport
+		var err error
+		if statsQueriesFile != "" {
+			report, err = db.ReadSlowLog(statsQueriesFile)
+		} else {
+			conn, cerr := connect()
+			if cerr != nil {
+				return cerr
+			}
+			defer conn.Close()
+			report, err = conn.SlowQueryStats()
+		}
+		if err != nil {
+			return err
+		}
+
+		report.Sort(by)
+		if statsQueriesLimit > 0 && len(report.Queries) > statsQueriesLimit {
+			report.Queries = report.Queries[:statsQueriesLimit]
+		}
+
+		if statsQueriesOutput != "" {
+			if err := report.ExportJSON(statsQueriesOutput); err != nil {
+				return err
+			}
+			fmt.Printf("Exported %d queries to %s\n", len(report.Queries), statsQueriesOutput)
+			return nil
+		}
+		if structuredOutput() {
+			var buf bytes.Buffer
+			if err := report.WriteJSON(&buf); err != nil {
+				return err
+			}
+			return printStructuredJSON(buf.Bytes())
+		}
+
+		if len(report.Queries) == 0 {
+			fmt.Printf("No queries recorded in %s.\n", report.Source)
+			return nil
+		}
+
+		fmt.Printf("Top queries from %s by %s\n\n", report.Source, by)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TOTAL\tMEAN\tMAX\tCALLS\tROWS\tQUERY")
+		fmt.Fprintln(w, "-----\t----\t---\t-----\t----\t-----")
+		for _, q := range report.Queries {
+			fingerprint := q.Fingerprint
+			if len(fingerprint) > 80 {
+				fingerprint = fingerprint[:77] + "..."
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n",
+				q.TotalTime.Round(time.Millisecond),
+				q.MeanTime().Round(time.Microsecond),
+				q.MaxTime.Round(time.Millisecond),
+				q.Calls,
+				q.RowsSent,
+				fingerprint,
+			)
+		}
+		return w.Flush()
+	},
+}
+
+var statsBloatCmd = &cobra.Command{
+	Use:   "bloat [database]",
+	Short: "Show the most bloated tables and indexes",
+	Long: `Estimate the space tables and indexes waste and list the worst ones.
+
+PostgreSQL estimates table and btree index bloat from pg_stats, so run
+ANALYZE first for tables that have never been analyzed. MariaDB reports the
+free space inside each table's data file (data_free). Rebuild objects from
+the TUI bloat report (b in the tables list).
+
+Examples:
+  ysm stats bloat mydb
+  ysm stats bloat mydb --sort percent --limit 10`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if len(args) > 0 {
+			if err := conn.UseDatabase(args[0]); err != nil {
+				return err
+			}
+		}
+
+		estimates, err := conn.EstimateBloat()
+		if err != nil {
+			return err
+		}
+		if err := db.SortBloat(estimates, statsBloatSort); err != nil {
+			return err
+		}
+		if statsBloatLimit > 0 && len(estimates) > statsBloatLimit {
+			estimates = estimates[:statsBloatLimit]
+		}
+
+		if len(estimates) == 0 {
+			fmt.Println("No tables with statistics found.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "OBJECT	TYPE	SIZE	WASTED	BLOAT")
+		fmt.Fprintln(w, "------	----	----	------	-----")
+		for _, b := range estimates {
+			kind := "table"
+			if b.Index != "" {
+				kind = "index on " + b.Table
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.1f%%\n",
+				b.Name(), kind, db.FormatSize(b.Size), db.FormatSize(b.Wasted), b.Percent())
+		}
+		return w.Flush()
+	},
+}
+
+var statsAdviseCmd = &cobra.Command{
+	Use:   "advise [database]",
+	Short: "Recommend fixes for common schema and maintenance issues",
+	Long: `Scan a database for common issues and list them most urgent first, each
+with the SQL that fixes it:
+
+  no-primary-key    Tables without a primary key
+  duplicate-index   Indexes covered by another index of the same table
+  unused-index      Indexes no query used since the server started counting
+  charset           Tables and columns in a different or legacy charset
+                    (latin1, utf8mb3), or a PostgreSQL database not in UTF8
+  stale-statistics  Tables never analyzed, or with many rows changed since
+  unpartitioned     Huge tables without partitioning
+
+Checks the server or your privileges don't allow are skipped and listed at
+the end. Fixes marked as templates have <placeholders> to fill in. Review
+every fix before running it, or apply them from the TUI advisor (A in the
+tables list).
+
+Examples:
+  ysm stats advise mydb
+  ysm stats advise mydb --check no-primary-key,duplicate-index
+  ysm stats advise mydb --output json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if len(args) > 0 {
+			if err := conn.UseDatabase(args[0]); err != nil {
+				return err
+			}
+		}
+
+		report, err := conn.Advise(statsAdviseChecks...)
+		if err != nil {
+			return err
+		}
+		return printResult(report, func() error {
+			if len(report.Advice) == 0 {
+				fmt.Println("Nothing to fix~")
+			}
+			for _, a := range report.Advice {
+				fmt.Printf("[%s] %s: %s\n", a.Priority, a.Check, a.Subject())
+				fmt.Printf("  %s\n", a.Problem)
+				if a.Manual {
+					fmt.Println("  -- Template: fill in the <placeholders> first")
+				}
+				for _, line := range strings.Split(a.Fix, "\n") {
+					fmt.Printf("  %s\n", line)
+				}
+				fmt.Println()
+			}
+			if len(report.Advice) > 0 {
+				fmt.Printf("%d high, %d medium, %d low\n",
+					report.Count(db.AdviceHigh), report.Count(db.AdviceMedium), report.Count(db.AdviceLow))
+			}
+			for _, s := range report.Skipped {
+				fmt.Printf("Skipped %s\n", s)
+			}
+			return nil
+		})
+	},
+}
+
+var statsTuneCmd = &cobra.Command{
+	Use:   "tune",
+	Short: "Propose values for key variables for the machine and workload",
+	Long: `Propose values for the variables that matter most - innodb_buffer_pool_size,
+max_connections and the InnoDB log and IO settings on MariaDB, shared_buffers,
+work_mem, effective_cache_size, WAL and parallelism on PostgreSQL - from the
+machine's RAM and CPUs and the kind of workload, next to the current values.
+
+RAM and CPUs are read from this machine when the server runs on it; pass
+--memory and --cpus otherwise, or to tune for part of the machine. The
+values are a starting point to measure from. Write them as a my.cnf or
+postgresql.conf snippet with -o.
+
+Examples:
+  ysm stats tune --workload web
+  ysm stats tune --memory 64GB --cpus 16 --workload analytics --storage hdd
+  ysm stats tune --workload oltp -o tuning.cnf`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		in := db.TuningInput{
+			CPUs:        statsTuneCPUs,
+			Workload:    statsTuneWorkload,
+			Connections: statsTuneConnections,
+		}
+		switch statsTuneStorage {
+		case "ssd":
+			in.SSD = true
+		case "hdd":
+		default:
+			return fmt.Errorf("invalid storage %q (use ssd or hdd)", statsTuneStorage)
+		}
+		valid := false
+		for _, w := range db.TuningWorkloads() {
+			valid = valid || w[0] == in.Workload
+		}
+		if !valid {
+			return fmt.Errorf("invalid workload %q (use web, oltp, analytics or mixed)", in.Workload)
+		}
+		if in.Memory, err = db.ParseSize(statsTuneMemory); err != nil {
+			return err
+		}
+		if in.Memory == 0 || in.CPUs == 0 {
+			memory, cpus, ok := conn.DetectHostResources()
+			if !ok {
+				return fmt.Errorf("the server isn't on this machine: pass --memory and --cpus")
+			}
+			if in.Memory == 0 {
+				in.Memory = memory
+			}
+			if in.CPUs == 0 {
+				in.CPUs = cpus
+			}
+		}
+
+		plan, err := conn.ProposeTuning(in)
+		if err != nil {
+			return err
+		}
+
+		if statsTuneOutput != "" {
+			if err := os.WriteFile(statsTuneOutput, []byte(plan.Snippet()), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", statsTuneOutput, err)
+			}
+			infof("Wrote %s\n", statsTuneOutput)
+		}
+
+		return printResult(plan, func() error {
+			storage := "spinning disks"
+			if in.SSD {
+				storage = "SSD"
+			}
+			fmt.Printf("Tuning for a %s workload: %s RAM, %d CPUs, %s\n\n", in.Workload, db.FormatSize(in.Memory), in.CPUs, storage)
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "VARIABLE\tCURRENT\tPROPOSED\tNOTE\tWHY")
+			fmt.Fprintln(w, "--------\t-------\t--------\t----\t---")
+			for _, c := range plan.Changes {
+				note := ""
+				switch {
+				case !c.Changed:
+					note = "unchanged"
+				case c.Restart:
+					note = "restart"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.Name, c.Current, c.Proposed, note, c.Reason)
+			}
+			return w.Flush()
+		})
+	},
+}
+
+var statsSourcesCmd = &cobra.Command{
+	Use:   "sources",
+	Short: "Show who is connected, grouped by user, host and application",
+	Long: `Audit the server's connections: every user, client host and application
+connected right now, with the number of sessions, the databases they use and
+the age of the oldest one.
+
+Use it to find out what still connects to a server before retiring it.
+MariaDB doesn't record when connections opened, so ages are PostgreSQL only;
+application names on MariaDB need performance_schema.
+
+Examples:
+  ysm stats sources
+  ysm stats sources --resolve`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		sources, err := conn.ConnectionSources()
+		if err != nil {
+			return err
+		}
+		if len(sources) == 0 {
+			fmt.Println("No other connections.")
+			return nil
+		}
+		if statsSourcesDNS {
+			db.ResolveHostnames(sources, 5*time.Second)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "USER\tHOST\tAPPLICATION\tDATABASES\tCOUNT\tOLDEST")
+		fmt.Fprintln(w, "----\t----\t-----------\t---------\t-----\t------")
+		total := 0
+		for _, src := range sources {
+			host := src.Host
+			if src.Hostname != "" {
+				host += " (" + src.Hostname + ")"
+			}
+			oldest := "-"
+			if src.Oldest >= 0 {
+				oldest = db.FormatUptime(src.Oldest)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n",
+				src.User, host, src.Application, strings.Join(src.Databases, ","), src.Count, oldest)
+			total += src.Count
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\n%d connections from %d sources\n", total, len(sources))
+		return nil
+	},
+}
+
 func init() {
+	statsSourcesCmd.Flags().BoolVar(&statsSourcesDNS, "resolve", false, "Look up client hostnames with reverse DNS")
+
+	statsBloatCmd.Flags().StringVar(&statsBloatSort, "sort", db.BloatSortWasted, "Sort by wasted, percent, size or name")
+	statsBloatCmd.Flags().IntVar(&statsBloatLimit, "limit", 20, "Number of objects to show (0 for all)")
+
+	statsAdviseCmd.Flags().StringSliceVar(&statsAdviseChecks, "check", nil, "Checks to run (default all): "+strings.Join(db.AdviceChecks, ", "))
+
+	statsTuneCmd.Flags().StringVar(&statsTuneMemory, "memory", "", "RAM the server may use, like 16GB (default: this machine's)")
+	statsTuneCmd.Flags().IntVar(&statsTuneCPUs, "cpus", 0, "CPU cores (default: this machine's)")
+	statsTuneCmd.Flags().StringVar(&statsTuneWorkload, "workload", db.TuningMixed, "Workload: web, oltp, analytics or mixed")
+	statsTuneCmd.Flags().StringVar(&statsTuneStorage, "storage", "ssd", "Storage: ssd or hdd")
+	statsTuneCmd.Flags().IntVar(&statsTuneConnections, "connections", 0, "Expected concurrent connections (default: usual for the workload)")
+	statsTuneCmd.Flags().StringVarP(&statsTuneOutput, "output", "o", "", "Write a my.cnf or postgresql.conf snippet to this file")
+
+	statsQueriesCmd.Flags().StringVar(&statsQueriesFile, "file", "", "Analyze a slow query log file instead of the server")
+	statsQueriesCmd.Flags().StringVar(&statsQueriesSort, "sort", "total", "Sort by total, mean or calls")
+	statsQueriesCmd.Flags().IntVar(&statsQueriesLimit, "limit", 20, "Number of queries to show (0 for all)")
+	statsQueriesCmd.Flags().StringVarP(&statsQueriesOutput, "output", "o", "", "Write JSON to a file")
+
 	statsCmd.AddCommand(statsSummaryCmd)
 	statsCmd.AddCommand(statsDatabasesCmd)
 	statsCmd.AddCommand(statsTablesCmd)
 	statsCmd.AddCommand(statsConnectionsCmd)
 	statsCmd.AddCommand(statsPerformanceCmd)
+	statsCmd.AddCommand(statsQueriesCmd)
+	statsCmd.AddCommand(statsBloatCmd)
+	statsCmd.AddCommand(statsAdviseCmd)
+	statsCmd.AddCommand(statsTuneCmd)
+	statsCmd.AddCommand(statsSourcesCmd)
 }
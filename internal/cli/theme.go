@@ -0,0 +1,251 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/blubskye/yandere_sql_manager/internal/theme"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+var (
+	themeFrom  string
+	themeForce bool
+)
+
+// themeJSON is how theme list prints a theme with --json
+type themeJSON struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Active      bool   `json:"active"`
+	Path        string `json:"path,omitempty"` // Empty for built-in themes
+}
+
+var themeCmd = &cobra.Command{
+	Use:     "theme",
+	Aliases: []string{"themes"},
+	Short:   "Pick and write TUI color themes",
+	Long: `Pick and write the color themes of the TUI.
+
+Built-in themes: yandere (the default hot pink), midnight, colorblind (the
+Okabe-Ito palette), light (for light terminal backgrounds) and nocolor (bold,
+underline and reverse video only, picked when NO_COLOR is set and no theme is
+configured).
+
+User themes are YAML files in ~/.config/ysm/themes/. Colors are #RRGGBB or
+ANSI numbers 0-255; the ones a theme leaves out come from the built-in theme
+it extends:
+
+  name: dusk
+  extends: midnight
+  colors:
+    primary: "#B48EAD"
+    error: "196"
+
+Subcommands:
+  list  - List the built-in and user themes
+  show  - Show a theme's colors
+  use   - Make a theme the TUI's
+  new   - Write a user theme to start editing from`,
+}
+
+// themesDir returns the user themes directory
+func themesDir() string {
+	dir, err := config.ThemesDir()
+	if err != nil {
+		return ""
+	}
+	return dir
+}
+
+var themeListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the built-in and user themes",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		themes, err := theme.List(themesDir())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		active, _ := theme.Find(themesDir(), cfg.Theme)
+
+		result := make([]themeJSON, len(themes))
+		for i, t := range themes {
+			result[i] = themeJSON{Name: t.Name, Description: t.Description, Active: active != nil && t.Name == active.Name, Path: t.Path}
+		}
+		return printResult(result, func() error {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tSOURCE\tDESCRIPTION")
+			fmt.Fprintln(w, "----\t------\t-----------")
+			for _, t := range result {
+				name := t.Name
+				if t.Active {
+					name += " *"
+				}
+				source := "built-in"
+				if t.Path != "" {
+					source = t.Path
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", name, source, t.Description)
+			}
+			return w.Flush()
+		})
+	},
+}
+
+var themeShowCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "Show a theme's colors (default: the active theme)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := cfg.Theme
+		if len(args) > 0 {
+			name = args[0]
+		}
+		t, err := theme.Find(themesDir(), name)
+		if err != nil {
+			return err
+		}
+		if structuredOutput() {
+			return printStructured(t)
+		}
+
+		fmt.Printf("%s - %s\n", t.Name, t.Description)
+		if t.Path != "" {
+			fmt.Printf("File: %s\n", t.Path)
+		}
+		if t.NoColor {
+			fmt.Println("No colors: bold, underline and reverse video only")
+			return nil
+		}
+		fmt.Println()
+
+		theme.Use(t)
+		s := theme.Current()
+		swatches := []struct {
+			role  string
+			color lipgloss.TerminalColor
+		}{
+			{"primary", s.Primary},
+			{"secondary", s.Secondary},
+			{"accent", s.Accent},
+			{"text", s.Text},
+			{"selection", s.Selection},
+			{"muted", s.Muted},
+			{"error", s.Error},
+			{"success", s.Success},
+			{"warning", s.Warning},
+			{"highlight", s.Highlight},
+			{"subtle", s.Subtle},
+		}
+		for _, sw := range swatches {
+			value := ""
+			if c, ok := sw.color.(lipgloss.Color); ok {
+				value = string(c)
+			}
+			fmt.Printf("  %-10s %s %s\n", sw.role, lipgloss.NewStyle().Background(sw.color).Render("    "), value)
+		}
+		fmt.Println()
+		fmt.Println("  " + s.Title.Render("Title") + "  " + s.Header.Render("Header") + "  " +
+			s.Selected.Render(" Selected ") + "  " + s.MutedText.Render("Help") + "  " +
+			s.ErrorText.Render("Error") + "  " + s.SuccessText.Render("Success"))
+		return nil
+	},
+}
+
+var themeUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Make a theme the TUI's",
+	Long: `Make a theme the one the TUI starts with, saved as theme in the config file.
+
+Examples:
+  ysm theme use colorblind
+  ysm theme use nocolor`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := theme.Find(themesDir(), args[0])
+		if err != nil {
+			return err
+		}
+		cfg.Theme = t.Name
+		if err := cfg.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Theme set to %s.\n", t.Name)
+		return nil
+	},
+}
+
+var themeNewCmd = &cobra.Command{
+	Use:   "new <name>",
+	Short: "Write a user theme to start editing from",
+	Long: `Write a user theme with every color of another theme spelled out, to edit.
+
+Examples:
+  ysm theme new dusk --from midnight
+  ysm theme use dusk`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, err := theme.Find(themesDir(), themeFrom)
+		if err != nil {
+			return err
+		}
+		data, err := theme.Template(args[0], from)
+		if err != nil {
+			return err
+		}
+		check := theme.Theme{Name: args[0]}
+		if err := check.Validate(); err != nil {
+			return err
+		}
+
+		dir, err := config.ThemesDir()
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, args[0]+".yaml")
+		if _, err := os.Stat(path); err == nil && !themeForce {
+			return fmt.Errorf("%s exists (use --force to replace it)", path)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create themes directory: %w", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write theme: %w", err)
+		}
+
+		fmt.Printf("Theme written to %s\n", path)
+		fmt.Printf("Edit it, then run 'ysm theme use %s'.\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	themeNewCmd.Flags().StringVar(&themeFrom, "from", theme.DefaultName, "Theme to copy the colors from")
+	themeNewCmd.Flags().BoolVar(&themeForce, "force", false, "Replace an existing theme file")
+	themeCmd.AddCommand(themeListCmd, themeShowCmd, themeUseCmd, themeNewCmd)
+	rootCmd.AddCommand(themeCmd)
+}
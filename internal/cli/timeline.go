@@ -0,0 +1,142 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/webhook"
+	"github.com/spf13/cobra"
+)
+
+var (
+	timelineSince   time.Duration
+	timelineKinds   []string
+	timelineNoCheck bool
+)
+
+var timelineCmd = &cobra.Command{
+	Use:   "timeline",
+	Short: "Show notable events on the server in order",
+	Long: `Show a chronological timeline of notable events for the current profile:
+server restarts, replication errors appearing and clearing, backups taken,
+CI snapshots written, and the exports, imports, restores and other
+operations YSM ran from the command line or the TUI.
+
+Events are kept locally in timeline.json next to the backups directory, so
+incident retrospectives have one place to look even while the server is
+down. Each time the timeline is shown, and whenever the TUI connects, YSM
+checks the server and records what changed since the last look. Restarts
+are dated from the server's uptime, so they land in the right place even
+when nobody looked for days; several restarts between two checks show as
+the last one.
+
+Examples:
+  ysm timeline --profile prod
+  ysm timeline --since 48h --kind restart,replication
+  ysm timeline --no-check --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, kind := range timelineKinds {
+			if !slices.Contains(db.TimelineKinds, kind) {
+				return fmt.Errorf("invalid --kind %q: use %s", kind, strings.Join(db.TimelineKinds, ", "))
+			}
+		}
+
+		name := alertServerName()
+		if !timelineNoCheck {
+			if err := observeTimeline(name); err != nil {
+				infof("Warning: couldn't check the server, showing what was recorded: %v\n", err)
+			}
+		}
+
+		timeline, err := db.LoadTimeline()
+		if err != nil {
+			return err
+		}
+		var events []db.TimelineEvent
+		for _, e := range timeline.For(name) {
+			if timelineSince > 0 && e.Time.Before(time.Now().Add(-timelineSince)) {
+				continue
+			}
+			if len(timelineKinds) > 0 && !slices.Contains(timelineKinds, e.Kind) {
+				continue
+			}
+			events = append(events, e)
+		}
+
+		if structuredOutput() {
+			if events == nil {
+				events = []db.TimelineEvent{}
+			}
+			return printStructured(events)
+		}
+
+		if len(events) == 0 {
+			fmt.Printf("No events recorded for %s.\n", name)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tKIND\tEVENT")
+		fmt.Fprintln(w, "----\t----\t-----")
+		for _, e := range events {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", e.Time.Format("2006-01-02 15:04:05"), e.Kind, e.Summary)
+			if e.Detail != "" {
+				fmt.Fprintf(w, "\t\t  %s\n", e.Detail)
+			}
+		}
+		return w.Flush()
+	},
+}
+
+// observeTimeline connects to record restarts and replication changes since
+// the server was last checked
+func observeTimeline(name string) error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ObserveTimeline(name)
+	return err
+}
+
+// recordTimeline adds a finished operation to the profile's timeline.
+// Failing to record it is only a warning; the operation itself is done.
+func recordTimeline(event webhook.Event) {
+	name := cmp.Or(event.Server, alertServerName())
+	if err := db.RecordTimelineEvent(db.OperationEvent(name, event.Operation, event.Title(), event.Duration, event.Err)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+func init() {
+	timelineCmd.Flags().DurationVar(&timelineSince, "since", 0, "Only show events this recent, e.g. 24h")
+	timelineCmd.Flags().StringSliceVar(&timelineKinds, "kind", nil, "Only show these kinds: restart, replication, backup, snapshot, operation")
+	timelineCmd.Flags().BoolVar(&timelineNoCheck, "no-check", false, "Show what was recorded without connecting to the server")
+}
@@ -21,9 +21,12 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/blubskye/yandere_sql_manager/internal/db"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
@@ -34,6 +37,26 @@ var (
 	grantDatabase  string
 	grantTable     string
 	grantPrivileges []string
+	grantExpires   string
+	grantReason    string
+	grantColumns   []string
+	tempGrantsAll  bool
+	renameHost     string
+	expireNever    bool
+	roleLogin      bool
+	roleSuperuser  bool
+	roleCreateDB   bool
+	roleCreateRole bool
+	roleConnLimit  int
+	roleValidUntil string
+	memberRevoke   bool
+	cloneHost      string
+	auditFindings  bool
+	auditFormat    string
+	auditOutput    string
+	importFrom     string
+	importConflict string
+	importDryRun   bool
 )
 
 var userCmd = &cobra.Command{
@@ -42,12 +65,23 @@ var userCmd = &cobra.Command{
 	Long: `Manage database users and their privileges.
 
 Subcommands:
-  list    - List all users
-  create  - Create a new user
-  drop    - Drop a user
-  show    - Show user privileges
-  grant   - Grant privileges to a user
-  revoke  - Revoke privileges from a user`,
+  list     - List all users
+  create   - Create a new user
+  drop     - Drop a user
+  show     - Show user privileges
+  grant    - Grant privileges to a user (optionally with --expires)
+  revoke   - Revoke privileges from a user
+  passwd   - Change a user's password
+  rename   - Rename a user or move it to another host
+  lock     - Lock an account
+  unlock   - Unlock an account
+  expire   - Expire a password now, after N days, or never
+  attrs    - Show or change role attributes (PostgreSQL)
+  member   - Grant or revoke role membership (PostgreSQL)
+  clone    - Create a user with the grants of an existing one
+  template - Grant a permission template (read-only, read-write, admin)
+  audit    - Audit every user's privileges and flag dangerous grants
+  temp     - List or revoke temporary grants`,
 }
 
 var userListCmd = &cobra.Command{
@@ -65,22 +99,27 @@ var userListCmd = &cobra.Command{
 			return err
 		}
 
-		if len(users) == 0 {
-			fmt.Println("No users found.")
-			return nil
+		if users == nil {
+			users = []db.User{}
 		}
+		return printResult(users, func() error {
+			if len(users) == 0 {
+				fmt.Println("No users found.")
+				return nil
+			}
 
-		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		fmt.Fprintln(w, "USER\tHOST")
-		fmt.Fprintln(w, "----\t----")
-		for _, u := range users {
-			host := u.Host
-			if host == "" {
-				host = "(all)"
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "USER\tHOST")
+			fmt.Fprintln(w, "----\t----")
+			for _, u := range users {
+				host := u.Host
+				if host == "" {
+					host = "(all)"
+				}
+				fmt.Fprintf(w, "%s\t%s\n", u.Username, host)
 			}
-			fmt.Fprintf(w, "%s\t%s\n", u.Username, host)
-		}
-		return w.Flush()
+			return w.Flush()
+		})
 	},
 }
 
@@ -249,7 +288,13 @@ var userGrantCmd = &cobra.Command{
 Examples:
   ysm user grant myuser -d mydb
   ysm user grant myuser -d mydb --privileges SELECT,INSERT,UPDATE
-  ysm user grant myuser -d mydb -t mytable --privileges SELECT`,
+  ysm user grant myuser -d mydb -t mytable --privileges SELECT
+  ysm user grant myuser -d mydb -t mytable --columns id,name --privileges SELECT,UPDATE
+  ysm user grant oncall -d app --privileges ALL --expires 2h --reason "INC-1234"
+
+With --expires the grant is tracked by YSM and revoked automatically once it
+expires by 'ysm scheduler run' (run it from cron or with --watch) or by the
+TUI users view while it is open.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		username := args[0]
@@ -265,12 +310,8 @@ Examples:
 			host = "localhost"
 		}
 
-		privs := grantPrivileges
-		if len(privs) == 0 {
-			privs = []string{"ALL PRIVILEGES"}
-		}
-
-		if err := conn.GrantPrivileges(username, host, privs, grantDatabase, grantTable); err != nil {
+		privs, err := columnPrivileges(conn, grantPrivileges, grantColumns)
+		if err != nil {
 			return err
 		}
 
@@ -281,6 +322,25 @@ Examples:
 			target = fmt.Sprintf("%s.*", grantDatabase)
 		}
 
+		if grantExpires != "" {
+			ttl, err := parseExpiry(grantExpires)
+			if err != nil {
+				return err
+			}
+			grant, err := conn.GrantTemporary(username, host, privs, grantDatabase, grantTable, ttl, grantReason, profile)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Granted %s on %s to '%s'@'%s' until %s (id %s).\n",
+				strings.Join(grant.Privileges, ", "), target, username, host,
+				grant.ExpiresAt.Format("2006-01-02 15:04:05"), grant.ID)
+			return nil
+		}
+
+		if err := conn.GrantPrivileges(username, host, privs, grantDatabase, grantTable); err != nil {
+			return err
+		}
+
 		fmt.Printf("Granted %s on %s to '%s'@'%s'.\n",
 			strings.Join(privs, ", "), target, username, host)
 		return nil
@@ -295,7 +355,8 @@ var userRevokeCmd = &cobra.Command{
 Examples:
   ysm user revoke myuser -d mydb
   ysm user revoke myuser -d mydb --privileges SELECT,INSERT
-  ysm user revoke myuser -d mydb -t mytable --privileges ALL`,
+  ysm user revoke myuser -d mydb -t mytable --privileges ALL
+  ysm user revoke myuser -d mydb -t mytable --columns name --privileges UPDATE`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		username := args[0]
@@ -311,9 +372,9 @@ Examples:
 			host = "localhost"
 		}
 
-		privs := grantPrivileges
-		if len(privs) == 0 {
-			privs = []string{"ALL PRIVILEGES"}
+		privs, err := columnPrivileges(conn, grantPrivileges, grantColumns)
+		if err != nil {
+			return err
 		}
 
 		if err := conn.RevokePrivileges(username, host, privs, grantDatabase, grantTable); err != nil {
@@ -333,6 +394,774 @@ Examples:
 	},
 }
 
+var userPasswdCmd = &cobra.Command{
+	Use:   "passwd <username>",
+	Short: "Change a user's password",
+	Long: `Change a database user's password. Prompts when -p is not given.
+
+Examples:
+  ysm user passwd myuser
+  ysm user passwd myuser --host '%' -p newpassword`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		username := args[0]
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		pwd := userPassword
+		if pwd == "" {
+			fmt.Print("Enter new password: ")
+			pwdBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Println()
+			if err != nil {
+				return fmt.Errorf("failed to read password: %w", err)
+			}
+			pwd = string(pwdBytes)
+
+			fmt.Print("Confirm password: ")
+			confirmBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Println()
+			if err != nil {
+				return fmt.Errorf("failed to read password: %w", err)
+			}
+			if pwd != string(confirmBytes) {
+				return fmt.Errorf("passwords do not match")
+			}
+		}
+
+		if pwd == "" {
+			return fmt.Errorf("password is required")
+		}
+
+		if err := conn.ChangeUserPassword(username, userHost, pwd); err != nil {
+			return err
+		}
+
+		fmt.Printf("Password changed for '%s'@'%s'.\n", username, userHost)
+		return nil
+	},
+}
+
+var userRenameCmd = &cobra.Command{
+	Use:   "rename <username> <new-username>",
+	Short: "Rename a user",
+	Long: `Rename a database user, or move it to another host on MariaDB.
+Temporary grants for the user follow it to the new name.
+
+PostgreSQL clears MD5 passwords when a role is renamed, so set the
+password again with 'ysm user passwd' afterwards.
+
+Examples:
+  ysm user rename olduser newuser
+  ysm user rename appuser appuser --host localhost --new-host '%'`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		username, newName := args[0], args[1]
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		newHost := renameHost
+		if newHost == "" {
+			newHost = userHost
+		}
+
+		if err := conn.RenameUser(username, userHost, newName, newHost); err != nil {
+			return err
+		}
+
+		fmt.Printf("User '%s'@'%s' renamed to '%s'@'%s'.\n", username, userHost, newName, newHost)
+		return nil
+	},
+}
+
+// newUserLockCmd builds the lock and unlock commands
+func newUserLockCmd(lock bool) *cobra.Command {
+	use, short, done := "unlock", "Unlock an account", "unlocked"
+	if lock {
+		use, short, done = "lock", "Lock an account", "locked"
+	}
+
+	return &cobra.Command{
+		Use:   use + " <username>",
+		Short: short,
+		Long: short + `. MariaDB uses ACCOUNT LOCK/UNLOCK (10.4.2+);
+PostgreSQL removes or restores the role's LOGIN attribute.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			username := args[0]
+
+			conn, err := connect()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			if err := conn.SetUserLocked(username, userHost, lock); err != nil {
+				return err
+			}
+
+			fmt.Printf("User '%s'@'%s' %s.\n", username, userHost, done)
+			return nil
+		},
+	}
+}
+
+var userExpireCmd = &cobra.Command{
+	Use:   "expire <username> [days]",
+	Short: "Expire a user's password",
+	Long: `Expire a user's password now, after a number of days, or never.
+
+On MariaDB (10.4.3+) the days count from each password change. On
+PostgreSQL the password is valid until that many days from now.
+
+Examples:
+  ysm user expire myuser          # Must change password at next login
+  ysm user expire myuser 90
+  ysm user expire myuser --never`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		username := args[0]
+
+		days := 0
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid number of days: %s", args[1])
+			}
+			days = n
+		}
+		if expireNever {
+			if len(args) == 2 {
+				return fmt.Errorf("--never can't be combined with a number of days")
+			}
+			days = db.PasswordNeverExpires
+		}
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if err := conn.SetPasswordExpiry(username, userHost, days); err != nil {
+			return err
+		}
+
+		switch {
+		case days < 0:
+			fmt.Printf("Password for '%s'@'%s' never expires.\n", username, userHost)
+		case days == 0:
+			fmt.Printf("Password for '%s'@'%s' expired.\n", username, userHost)
+		default:
+			fmt.Printf("Password for '%s'@'%s' expires after %d days.\n", username, userHost, days)
+		}
+		return nil
+	},
+}
+
+var userAttrsCmd = &cobra.Command{
+	Use:   "attrs <role>",
+	Short: "Show or change role attributes (PostgreSQL)",
+	Long: `Show a PostgreSQL role's attributes and memberships, or change the
+attributes given as flags. Attributes without a flag are left alone.
+
+Examples:
+  ysm user attrs app
+  ysm user attrs app --createdb --connection-limit 20
+  ysm user attrs reporting --login=false
+  ysm user attrs contractor --valid-until 2026-12-31`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		role := args[0]
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		attrs, err := conn.GetRoleAttributes(role)
+		if err != nil {
+			return err
+		}
+
+		flags := cmd.Flags()
+		changed := false
+		for name, target := range map[string]*bool{
+			"login":      &attrs.Login,
+			"superuser":  &attrs.Superuser,
+			"createdb":   &attrs.CreateDB,
+			"createrole": &attrs.CreateRole,
+		} {
+			if flags.Changed(name) {
+				*target, _ = flags.GetBool(name)
+				changed = true
+			}
+		}
+		if flags.Changed("connection-limit") {
+			attrs.ConnectionLimit = roleConnLimit
+			changed = true
+		}
+		if flags.Changed("valid-until") {
+			attrs.ValidUntil = roleValidUntil
+			changed = true
+		}
+
+		if changed {
+			if err := conn.AlterRoleAttributes(*attrs); err != nil {
+				return err
+			}
+			if attrs, err = conn.GetRoleAttributes(role); err != nil {
+				return err
+			}
+			fmt.Printf("Role %s updated.\n\n", role)
+		}
+
+		yesNo := func(b bool) string {
+			if b {
+				return "yes"
+			}
+			return "no"
+		}
+		limit := "unlimited"
+		if attrs.ConnectionLimit >= 0 {
+			limit = strconv.Itoa(attrs.ConnectionLimit)
+		}
+		validUntil := attrs.ValidUntil
+		if validUntil == "" || validUntil == "infinity" {
+			validUntil = "never expires"
+		}
+		memberOf := "-"
+		if len(attrs.MemberOf) > 0 {
+			memberOf = strings.Join(attrs.MemberOf, ", ")
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "Role:\t%s\n", attrs.Name)
+		fmt.Fprintf(w, "Login:\t%s\n", yesNo(attrs.Login))
+		fmt.Fprintf(w, "Superuser:\t%s\n", yesNo(attrs.Superuser))
+		fmt.Fprintf(w, "Create DB:\t%s\n", yesNo(attrs.CreateDB))
+		fmt.Fprintf(w, "Create role:\t%s\n", yesNo(attrs.CreateRole))
+		fmt.Fprintf(w, "Connection limit:\t%s\n", limit)
+		fmt.Fprintf(w, "Password valid until:\t%s\n", validUntil)
+		fmt.Fprintf(w, "Member of:\t%s\n", memberOf)
+		return w.Flush()
+	},
+}
+
+var userMemberCmd = &cobra.Command{
+	Use:   "member <role> <member>",
+	Short: "Grant or revoke role membership (PostgreSQL)",
+	Long: `Make a role a member of another role (GRANT role TO member), or
+remove it with --revoke.
+
+Examples:
+  ysm user member readonly alice
+  ysm user member readonly alice --revoke`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		role, member := args[0], args[1]
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if memberRevoke {
+			if err := conn.RevokeRole(role, member); err != nil {
+				return err
+			}
+			fmt.Printf("%s is no longer a member of %s.\n", member, role)
+			return nil
+		}
+
+		if err := conn.GrantRole(role, member); err != nil {
+			return err
+		}
+		fmt.Printf("%s is now a member of %s.\n", member, role)
+		return nil
+	},
+}
+
+var userCloneCmd = &cobra.Command{
+	Use:   "clone <username> <new-username>",
+	Short: "Create a user with the grants of an existing one",
+	Long: `Create a new user with its own password and copy every grant of an
+existing user to it. Prompts for the password when -p is not given.
+
+On PostgreSQL the role attributes and memberships are copied too, but table
+grants only from the connected database.
+
+Examples:
+  ysm user clone alice bob
+  ysm user clone alice bob --host localhost --new-host '%' -p secret`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		username, newName := args[0], args[1]
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		pwd := userPassword
+		if pwd == "" {
+			fmt.Printf("Enter password for %s: ", newName)
+			pwdBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Println()
+			if err != nil {
+				return fmt.Errorf("failed to read password: %w", err)
+			}
+			pwd = string(pwdBytes)
+
+			fmt.Print("Confirm password: ")
+			confirmBytes, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Println()
+			if err != nil {
+				return fmt.Errorf("failed to read password: %w", err)
+			}
+			if pwd != string(confirmBytes) {
+				return fmt.Errorf("passwords do not match")
+			}
+		}
+
+		if pwd == "" {
+			return fmt.Errorf("password is required")
+		}
+
+		newHost := cloneHost
+		if newHost == "" {
+			newHost = userHost
+		}
+
+		if err := conn.CloneUser(username, userHost, newName, newHost, pwd); err != nil {
+			return err
+		}
+
+		fmt.Printf("User '%s'@'%s' created with the grants of '%s'@'%s'.\n", newName, newHost, username, userHost)
+		return nil
+	},
+}
+
+var userTemplateCmd = &cobra.Command{
+	Use:   "template [<username> <template>]",
+	Short: "Grant a permission template",
+	Long: `Grant a named set of privileges to a user in one step, on the database
+given with -d or on all databases (MariaDB only). Without arguments the
+templates and their privileges are listed.
+
+Templates:
+  read-only   - read tables and views
+  read-write  - read and change rows
+  admin       - all privileges
+
+Examples:
+  ysm user template
+  ysm user template newdev read-write -d app
+  ysm user template reporting read-only -d analytics --host '%'`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 && len(args) != 2 {
+			return fmt.Errorf("expected a username and a template, or no arguments to list the templates")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if len(args) == 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TEMPLATE\tDESCRIPTION\tPRIVILEGES")
+			for _, t := range conn.PermissionTemplates() {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", t.Name, t.Description, strings.Join(t.Privileges, ", "))
+			}
+			return w.Flush()
+		}
+
+		username, name := args[0], args[1]
+		if err := conn.ApplyPermissionTemplate(username, userHost, name, grantDatabase); err != nil {
+			return err
+		}
+
+		target := "*.*"
+		if grantDatabase != "" {
+			target = grantDatabase + ".*"
+		}
+		fmt.Printf("Granted %s on %s to '%s'@'%s'.\n", name, target, username, userHost)
+		return nil
+	},
+}
+
+var userAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit every user's privileges and flag dangerous grants",
+	Long: `List the effective privileges of every user per database and flag the
+dangerous ones: SUPER, FILE and other server administration privileges,
+superuser and server file access roles on PostgreSQL, GRANT OPTION, writes to
+the mysql grant tables, and anything PUBLIC may write (including CREATE on
+a schema). PostgreSQL table and schema grants come from the connected
+database.
+
+Examples:
+  ysm user audit
+  ysm user audit --findings
+  ysm user audit --format csv -o audit.csv
+  ysm user audit --format json > audit.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if auditOutput != "" && auditFormat == "" {
+			auditFormat = db.AuditFormatCSV
+			if strings.HasSuffix(strings.ToLower(auditOutput), ".json") {
+				auditFormat = db.AuditFormatJSON
+			}
+		}
+		if auditFormat != "" && auditFormat != db.AuditFormatCSV && auditFormat != db.AuditFormatJSON {
+			return fmt.Errorf("unsupported format %q: use csv or json", auditFormat)
+		}
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		audit, err := conn.AuditPrivileges()
+		if err != nil {
+			return err
+		}
+
+		if auditFormat != "" {
+			if auditOutput != "" {
+				if err := audit.Export(auditOutput, auditFormat); err != nil {
+					return err
+				}
+				fmt.Printf("Audit written to %s (%d grants, %d findings).\n", auditOutput, len(audit.Entries), len(audit.Findings))
+				return nil
+			}
+			if auditFormat == db.AuditFormatJSON {
+				return audit.WriteJSON(os.Stdout)
+			}
+			return audit.WriteCSV(os.Stdout)
+		}
+
+		if !auditFindings {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "USER\tDATABASE\tOBJECT\tPRIVILEGES")
+			fmt.Fprintln(w, "----\t--------\t------\t----------")
+			for _, e := range audit.Entries {
+				privs := strings.Join(e.Privileges, ", ")
+				if e.GrantOption {
+					privs += " (WITH GRANT OPTION)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", auditUser(e.User, e.Host), e.Database, e.Object, privs)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			fmt.Println()
+		}
+
+		if len(audit.Findings) == 0 {
+			fmt.Println("No dangerous grants found.")
+			return nil
+		}
+
+		fmt.Printf("Findings (%d):\n", len(audit.Findings))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, f := range audit.Findings {
+			target := f.Database
+			if f.Object != "*" {
+				target += " " + f.Object
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", auditUser(f.User, f.Host), target, f.Privilege, f.Reason)
+		}
+		return w.Flush()
+	},
+}
+
+// auditUser formats an audited user as user@host, or just the role name on
+// PostgreSQL
+func auditUser(user, host string) string {
+	if host == "" {
+		return user
+	}
+	return user + "@" + host
+}
+
+var userImportCmd = &cobra.Command{
+	Use:   "import [grants.sql]",
+	Short: "Import users from a grants file or another server",
+	Long: `Import accounts from a file of CREATE USER and GRANT statements (such as
+SHOW GRANTS or pt-show-grants output), or from the server of another profile
+with --from. New accounts are created with their password and grants.
+
+Accounts that already exist with a different password or fewer grants are
+conflicts. For each one choose skip, password (take the source's password) or
+merge (add the grants the account lacks); grants it has beyond the source's
+are never revoked. By default YSM asks per account, and skips conflicts when
+it can't ask. MariaDB only.
+
+Examples:
+  ysm user import grants.sql --dry-run
+  ysm user import grants.sql
+  ysm user import --from old-server --on-conflict merge`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if (len(args) == 0) == (importFrom == "") {
+			return fmt.Errorf("give either a grants file or --from <profile>")
+		}
+		conflictAction := db.UserImportAction(importConflict)
+		switch importConflict {
+		case "ask", string(db.UserImportSkip), string(db.UserImportPassword), string(db.UserImportMerge):
+		default:
+			return fmt.Errorf("invalid --on-conflict %q: use ask, skip, password or merge", importConflict)
+		}
+
+		var defs []db.UserDefinition
+		if len(args) == 1 {
+			var err error
+			if defs, err = db.ReadUserDefinitions(args[0]); err != nil {
+				return err
+			}
+		} else {
+			source, err := connectProfile(importFrom)
+			if err != nil {
+				return err
+			}
+			defs, err = source.UserDefinitions()
+			source.Close()
+			if err != nil {
+				return err
+			}
+		}
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		plans, err := conn.PlanUserImport(defs)
+		if err != nil {
+			return err
+		}
+
+		ask := importConflict == "ask" && !importDryRun && !structuredOutput() && term.IsTerminal(int(os.Stdin.Fd()))
+		for i := range plans {
+			plan := &plans[i]
+			if !plan.Conflict() {
+				continue
+			}
+			switch {
+			case ask:
+				plan.Action = askUserImportAction(plan)
+			case plan.Allows(conflictAction):
+				plan.Action = conflictAction
+			}
+		}
+
+		if importDryRun {
+			return printResult(plans, func() error {
+				return printUserImportPlans(plans)
+			})
+		}
+
+		result := conn.ApplyUserImport(plans)
+		err = printResult(result, func() error {
+			if err := printUserImportPlans(plans); err != nil {
+				return err
+			}
+			fmt.Printf("\n%d created, %d passwords changed, %d merged, %d skipped.\n",
+				result.Created, result.Passwords, result.Merged, result.Skipped)
+			for _, note := range result.Notes {
+				fmt.Printf("Note: %s\n", note)
+			}
+			for _, e := range result.Errors {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", e)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(result.Errors) > 0 {
+			return fmt.Errorf("%d account(s) failed to import", len(result.Errors))
+		}
+		return nil
+	},
+}
+
+// printUserImportPlans lists each account with how it compares and what
+// the import does with it
+func printUserImportPlans(plans []db.UserImportPlan) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACCOUNT\tSTATUS\tDIFFERENCES\tACTION")
+	fmt.Fprintln(w, "-------\t------\t-----------\t------")
+	for _, p := range plans {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Account(), p.Status(), userImportDifferences(p), p.Action)
+	}
+	return w.Flush()
+}
+
+// userImportDifferences summarizes how an existing account differs
+func userImportDifferences(p db.UserImportPlan) string {
+	var diffs []string
+	if p.PasswordDiffers {
+		diffs = append(diffs, "password")
+	}
+	if n := len(p.MissingGrants); n > 0 {
+		diffs = append(diffs, fmt.Sprintf("%d grant(s) missing", n))
+	}
+	if n := len(p.ExtraGrants); n > 0 {
+		diffs = append(diffs, fmt.Sprintf("%d extra grant(s)", n))
+	}
+	if len(diffs) == 0 {
+		return "-"
+	}
+	return strings.Join(diffs, ", ")
+}
+
+// askUserImportAction shows a conflicting account and asks what to do with it
+func askUserImportAction(p *db.UserImportPlan) db.UserImportAction {
+	fmt.Printf("\n%s exists with a different definition (%s).\n", p.Account(), userImportDifferences(*p))
+	for _, g := range p.MissingGrants {
+		fmt.Printf("  + %s\n", g)
+	}
+	for _, g := range p.ExtraGrants {
+		fmt.Printf("  = %s (kept)\n", g)
+	}
+
+	for {
+		fmt.Print("[s]kip, overwrite [p]assword or [m]erge grants? [s]: ")
+		var answer string
+		fmt.Scanln(&answer)
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "", "s", "skip":
+			return db.UserImportSkip
+		case "p", "password":
+			if p.Auth == "" {
+				fmt.Println("The source has no password for this account.")
+				continue
+			}
+			return db.UserImportPassword
+		case "m", "merge":
+			return db.UserImportMerge
+		}
+	}
+}
+
+var userTempCmd = &cobra.Command{
+	Use:   "temp",
+	Short: "List temporary grants",
+	Long: `List privileges granted with --expires and the time they have left.
+
+Examples:
+  ysm user temp
+  ysm user temp --all
+  ysm user temp revoke tg_1700000000000000000`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		grants, err := db.ListTemporaryGrants(tempGrantsAll)
+		if err != nil {
+			return err
+		}
+
+		if len(grants) == 0 {
+			fmt.Println("No temporary grants.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tUSER\tPRIVILEGES\tON\tSERVER\tREMAINING\tREASON")
+		fmt.Fprintln(w, "--\t----\t----------\t--\t------\t---------\t------")
+		for _, g := range grants {
+			fmt.Fprintf(w, "%s\t'%s'@'%s'\t%s\t%s\t%s\t%s\t%s\n",
+				g.ID, g.Username, g.Host, truncate(strings.Join(g.Privileges, ","), 30),
+				g.Scope(), g.Server, formatRemaining(g), truncate(g.Reason, 30))
+		}
+		return w.Flush()
+	},
+}
+
+var userTempRevokeCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revoke a temporary grant before it expires",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		if err := conn.RevokeTemporaryGrant(args[0]); err != nil {
+			return err
+		}
+
+		fmt.Printf("Revoked temporary grant %s.\n", args[0])
+		return nil
+	},
+}
+
+// parseExpiry parses a grant lifetime such as 30m, 2h or 1d
+func parseExpiry(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid expiry '%s'", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid expiry '%s' (use e.g. 30m, 2h or 1d)", s)
+	}
+	return d, nil
+}
+
+// formatRemaining describes how long a temporary grant has left
+func formatRemaining(g db.TemporaryGrant) string {
+	switch {
+	case g.Revoked():
+		return "revoked " + g.RevokedAt.Format("2006-01-02 15:04")
+	case g.Expired(time.Now()):
+		if g.LastError != "" {
+			return "expired (revoke failed)"
+		}
+		return "expired (pending revoke)"
+	default:
+		return g.Remaining().Round(time.Second).String()
+	}
+}
+
+// columnPrivileges limits privileges to --columns when given, defaulting
+// to ALL PRIVILEGES on the whole target otherwise
+func columnPrivileges(conn *db.Connection, privs, columns []string) ([]string, error) {
+	if len(columns) > 0 {
+		if grantTable == "" {
+			return nil, fmt.Errorf("--columns needs a table (-t)")
+		}
+		return conn.ColumnPrivileges(privs, columns)
+	}
+	if len(privs) == 0 {
+		return []string{"ALL PRIVILEGES"}, nil
+	}
+	return privs, nil
+}
+
 func init() {
 	// Common flags
 	userCreateCmd.Flags().StringVar(&userHost, "host", "localhost", "Host for the user (MariaDB only)")
@@ -346,11 +1175,15 @@ func init() {
 	userGrantCmd.Flags().StringVarP(&grantDatabase, "db", "d", "", "Database to grant access to")
 	userGrantCmd.Flags().StringVarP(&grantTable, "table", "t", "", "Table to grant access to")
 	userGrantCmd.Flags().StringSliceVar(&grantPrivileges, "privileges", []string{}, "Privileges to grant (comma-separated)")
+	userGrantCmd.Flags().StringVar(&grantExpires, "expires", "", "Revoke the grant automatically after this long (e.g. 30m, 2h, 1d)")
+	userGrantCmd.Flags().StringVar(&grantReason, "reason", "", "Reason recorded with a temporary grant")
+	userGrantCmd.Flags().StringSliceVar(&grantColumns, "columns", []string{}, "Grant only on these columns of the table (comma-separated)")
 
 	userRevokeCmd.Flags().StringVar(&userHost, "host", "localhost", "Host for the user (MariaDB only)")
 	userRevokeCmd.Flags().StringVarP(&grantDatabase, "db", "d", "", "Database to revoke access from")
 	userRevokeCmd.Flags().StringVarP(&grantTable, "table", "t", "", "Table to revoke access from")
 	userRevokeCmd.Flags().StringSliceVar(&grantPrivileges, "privileges", []string{}, "Privileges to revoke (comma-separated)")
+	userRevokeCmd.Flags().StringSliceVar(&grantColumns, "columns", []string{}, "Revoke only on these columns of the table (comma-separated)")
 
 	userCmd.AddCommand(userListCmd)
 	userCmd.AddCommand(userCreateCmd)
@@ -358,4 +1191,56 @@ func init() {
 	userCmd.AddCommand(userShowCmd)
 	userCmd.AddCommand(userGrantCmd)
 	userCmd.AddCommand(userRevokeCmd)
+
+	userLockCmd := newUserLockCmd(true)
+	userUnlockCmd := newUserLockCmd(false)
+
+	userPasswdCmd.Flags().StringVar(&userHost, "host", "localhost", "Host for the user (MariaDB only)")
+	userPasswdCmd.Flags().StringVarP(&userPassword, "password", "p", "", "New password")
+	userRenameCmd.Flags().StringVar(&userHost, "host", "localhost", "Host for the user (MariaDB only)")
+	userRenameCmd.Flags().StringVar(&renameHost, "new-host", "", "New host for the user (MariaDB only, default: unchanged)")
+	userLockCmd.Flags().StringVar(&userHost, "host", "localhost", "Host for the user (MariaDB only)")
+	userUnlockCmd.Flags().StringVar(&userHost, "host", "localhost", "Host for the user (MariaDB only)")
+	userExpireCmd.Flags().StringVar(&userHost, "host", "localhost", "Host for the user (MariaDB only)")
+	userExpireCmd.Flags().BoolVar(&expireNever, "never", false, "Turn password expiry off")
+
+	userCmd.AddCommand(userPasswdCmd)
+	userCmd.AddCommand(userRenameCmd)
+	userCmd.AddCommand(userLockCmd)
+	userCmd.AddCommand(userUnlockCmd)
+	userCmd.AddCommand(userExpireCmd)
+
+	userAttrsCmd.Flags().BoolVar(&roleLogin, "login", false, "Allow the role to log in")
+	userAttrsCmd.Flags().BoolVar(&roleSuperuser, "superuser", false, "Make the role a superuser")
+	userAttrsCmd.Flags().BoolVar(&roleCreateDB, "createdb", false, "Allow the role to create databases")
+	userAttrsCmd.Flags().BoolVar(&roleCreateRole, "createrole", false, "Allow the role to create roles")
+	userAttrsCmd.Flags().IntVar(&roleConnLimit, "connection-limit", -1, "Maximum concurrent connections (-1 = unlimited)")
+	userAttrsCmd.Flags().StringVar(&roleValidUntil, "valid-until", "", "Password expiry timestamp (empty = never)")
+	userMemberCmd.Flags().BoolVar(&memberRevoke, "revoke", false, "Remove the membership instead")
+
+	userCmd.AddCommand(userAttrsCmd)
+	userCmd.AddCommand(userMemberCmd)
+
+	userCloneCmd.Flags().StringVar(&userHost, "host", "localhost", "Host of the existing user (MariaDB only)")
+	userCloneCmd.Flags().StringVar(&cloneHost, "new-host", "", "Host for the new user (MariaDB only, default: same as --host)")
+	userCloneCmd.Flags().StringVarP(&userPassword, "password", "p", "", "Password for the new user")
+	userTemplateCmd.Flags().StringVar(&userHost, "host", "localhost", "Host for the user (MariaDB only)")
+	userTemplateCmd.Flags().StringVarP(&grantDatabase, "db", "d", "", "Database to grant the template on (default: all databases)")
+
+	userCmd.AddCommand(userCloneCmd)
+	userCmd.AddCommand(userTemplateCmd)
+
+	userAuditCmd.Flags().BoolVar(&auditFindings, "findings", false, "Show only the dangerous grants")
+	userAuditCmd.Flags().StringVar(&auditFormat, "format", "", "Print the full report as csv or json")
+	userAuditCmd.Flags().StringVarP(&auditOutput, "output", "o", "", "Write the report to a file (format from the extension unless --format is given)")
+	userCmd.AddCommand(userAuditCmd)
+
+	userImportCmd.Flags().StringVar(&importFrom, "from", "", "Import from the server of this profile instead of a file")
+	userImportCmd.Flags().StringVar(&importConflict, "on-conflict", "ask", "What to do with accounts that differ: ask, skip, password or merge")
+	userImportCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Show how each account compares without changing anything")
+	userCmd.AddCommand(userImportCmd)
+
+	userTempCmd.Flags().BoolVar(&tempGrantsAll, "all", false, "Include revoked grants")
+	userTempCmd.AddCommand(userTempRevokeCmd)
+	userCmd.AddCommand(userTempCmd)
 }
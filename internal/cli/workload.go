@@ -0,0 +1,354 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/spf13/cobra"
+)
+
+var (
+	workloadCaptureOutput   string
+	workloadCaptureDuration time.Duration
+	workloadCaptureInterval time.Duration
+	workloadCaptureFile     string
+	workloadReplaySpeed     float64
+	workloadReplayWorkers   int
+	workloadReplayReadOnly  bool
+	workloadReplayLimit     int
+	workloadReplayYes       bool
+)
+
+var workloadCmd = &cobra.Command{
+	Use:   "workload",
+	Short: "Capture a server's queries and replay them against another",
+	Long: `Record a window of the queries a server runs, then replay them against a
+test server at the original pace or faster and compare the latencies - to
+check a configuration change or an upgrade before production gets it.
+
+Subcommands:
+  capture - Record queries from the server or a general log file
+  replay  - Run a capture against the server and compare latencies`,
+}
+
+var workloadCaptureCmd = &cobra.Command{
+	Use:   "capture",
+	Short: "Record the queries the server runs",
+	Long: `Record the queries other sessions run for a while and save them to a file.
+
+MariaDB servers with general_log on and log_output including TABLE are read
+from mysql.general_log, which has every statement. Otherwise YSM samples the
+process list (MariaDB) or pg_stat_activity (PostgreSQL) every --interval,
+which misses statements that start and finish between two samples, and on
+PostgreSQL cuts queries at track_activity_query_size. Only pg_stat_activity
+tells how long queries took; replays of other captures report their own
+latencies without a comparison. Use --file to
+read a general log file instead of the server. -d keeps only the queries of
+one database. Ctrl+C ends the capture early and keeps what was recorded.
+
+Examples:
+  ysm workload capture -o peak.json --duration 5m
+  ysm workload capture -d shop -o shop.json --duration 30s --interval 50ms
+  ysm workload capture --file /var/log/mysql/general.log -o general.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := workloadCaptureOutput
+		if output == "" {
+			output = fmt.Sprintf("workload_%s.json", time.Now().Format("20060102_150405"))
+		}
+
+		var w *db.Workload
+		var err error
+		if workloadCaptureFile != "" {
+			w, err = db.ReadGeneralLog(workloadCaptureFile, database)
+		} else {
+			conn, cerr := connect()
+			if cerr != nil {
+				return cerr
+			}
+			defer conn.Close()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			infof("Capturing queries for %s (Ctrl+C to stop early)...\n", workloadCaptureDuration)
+			w, err = conn.CaptureWorkload(ctx, db.CaptureOptions{
+				Duration: workloadCaptureDuration,
+				Interval: workloadCaptureInterval,
+				Database: database,
+				OnProgress: func(captured int, elapsed time.Duration) {
+					if structuredOutput() {
+						return
+					}
+					if captured < 0 {
+						fmt.Printf("\r  %s elapsed\033[K", progress.FormatDuration(elapsed))
+					} else {
+						fmt.Printf("\r  %s elapsed, %d queries\033[K", progress.FormatDuration(elapsed), captured)
+					}
+				},
+			})
+			if !structuredOutput() {
+				fmt.Println()
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := w.Save(output); err != nil {
+			return err
+		}
+		result := struct {
+			Source     string  `json:"source"`
+			Queries    int     `json:"queries"`
+			DurationMs float64 `json:"duration_ms"`
+			Output     string  `json:"output"`
+		}{w.Source, len(w.Queries), w.DurationMs, output}
+		return printResult(result, func() error {
+			fmt.Printf("Captured %d queries over %s from %s\n", len(w.Queries),
+				progress.FormatDuration(time.Duration(w.DurationMs*float64(time.Millisecond))), w.Source)
+			fmt.Printf("Saved to %s\n", output)
+			return nil
+		})
+	},
+}
+
+var workloadReplayCmd = &cobra.Command{
+	Use:   "replay <file>",
+	Short: "Replay a captured workload and compare latencies",
+	Long: `Run a captured workload against the server and compare each query's
+latency with the capture.
+
+Queries keep their original spacing, divided by --speed (0 runs them back to
+back). Each captured session's queries run in order on one of --concurrency
+connections, so its transactions hold. On MariaDB every query runs in the
+database it was captured in, or -d's. A PostgreSQL connection stays in one
+database, so queries of other databases are skipped.
+
+Replay runs writes too: point it at a test server, or use --read-only to
+replay only SELECT, SHOW, EXPLAIN and similar statements.
+
+Examples:
+  ysm workload replay peak.json --profile staging
+  ysm workload replay peak.json --profile upgrade-test --speed 4 --read-only
+  ysm workload replay shop.json --profile staging -d shop_copy --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		w, err := db.LoadWorkload(args[0])
+		if err != nil {
+			return err
+		}
+
+		conn, err := connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		target := fmt.Sprintf("%s:%d", conn.Config.Host, conn.Config.Port)
+		infof("Replaying %d queries from %s against %s\n", len(w.Queries), args[0], target)
+		if !workloadReplayReadOnly && !workloadReplayYes && !confirmPrompt("The workload's writes run too. Replay it?") {
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		bar := newProgressPrinter("Replaying", progress.Items, int64(len(w.Queries)))
+		report, err := conn.ReplayWorkload(ctx, w, db.ReplayOptions{
+			Speed:       workloadReplaySpeed,
+			Concurrency: workloadReplayWorkers,
+			ReadOnly:    workloadReplayReadOnly,
+			Database:    database,
+			OnProgress: func(done, total int) {
+				bar.SetTotal(int64(total))
+				bar.Set(int64(done))
+				bar.refresh()
+			},
+		})
+		bar.finish()
+		if err != nil {
+			return err
+		}
+
+		if workloadReplayLimit > 0 && len(report.Queries) > workloadReplayLimit {
+			report.Queries = report.Queries[:workloadReplayLimit]
+		}
+		return printResult(newReplayJSON(report), func() error {
+			printReplayReport(report)
+			return nil
+		})
+	},
+}
+
+// printReplayReport prints the latency comparison of a replay
+func printReplayReport(r *db.ReplayReport) {
+	if r.Interrupted {
+		fmt.Println("Interrupted; the figures cover the queries replayed so far.")
+	}
+	fmt.Printf("\nReplayed %d queries in %s", r.Replayed, progress.FormatDuration(r.Duration))
+	if r.Skipped > 0 {
+		fmt.Printf(", %d skipped", r.Skipped)
+	}
+	if r.Errors > 0 {
+		fmt.Printf(", %d failed", r.Errors)
+	}
+	fmt.Println()
+	if r.MaxLag > time.Second {
+		fmt.Printf("Fell up to %s behind schedule; try a lower --speed or more --concurrency\n", progress.FormatDuration(r.MaxLag))
+	}
+	fmt.Println()
+
+	ms := func(d time.Duration) string {
+		return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LATENCY\tQUERIES\tMEAN\tP50\tP95\tP99\tMAX")
+	row := func(label string, s db.LatencySummary) {
+		if s.Count > 0 {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", label, s.Count, ms(s.Mean), ms(s.P50), ms(s.P95), ms(s.P99), ms(s.Max))
+		}
+	}
+	row("Replay (all)", r.Latency)
+	row("Captured", r.Original)
+	row("Replay (same)", r.Compared)
+	w.Flush()
+
+	if len(r.Queries) > 0 {
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CHANGE\tCAPTURED\tREPLAYED\tCALLS\tERRORS\tQUERY")
+		for _, q := range r.Queries {
+			change, captured := "-", "-"
+			if q.Original > 0 {
+				change = fmt.Sprintf("%.2fx", q.Change())
+				captured = ms(q.Original)
+			}
+			fingerprint := q.Fingerprint
+			if len(fingerprint) > 80 {
+				fingerprint = fingerprint[:77] + "..."
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n", change, captured, ms(q.Replayed), q.Calls, q.Errors, fingerprint)
+		}
+		w.Flush()
+	}
+
+	if len(r.ErrorSample) > 0 {
+		fmt.Println("\nErrors:")
+		for _, e := range r.ErrorSample {
+			fmt.Printf("  %s\n", e)
+		}
+	}
+}
+
+// replayJSON is the structured form of a replay report, with times in
+// milliseconds
+type replayJSON struct {
+	Source      string            `json:"source"`
+	Replayed    int               `json:"replayed"`
+	Skipped     int               `json:"skipped"`
+	Errors      int               `json:"errors"`
+	ErrorSample []string          `json:"error_sample,omitempty"`
+	DurationMs  float64           `json:"duration_ms"`
+	MaxLagMs    float64           `json:"max_lag_ms"`
+	Interrupted bool              `json:"interrupted,omitempty"`
+	Latency     latencyJSON       `json:"latency"`
+	Captured    latencyJSON       `json:"captured"`
+	Compared    latencyJSON       `json:"compared"`
+	Queries     []replayQueryJSON `json:"queries"`
+}
+
+type latencyJSON struct {
+	Count  int     `json:"count"`
+	MeanMs float64 `json:"mean_ms"`
+	P50Ms  float64 `json:"p50_ms"`
+	P95Ms  float64 `json:"p95_ms"`
+	P99Ms  float64 `json:"p99_ms"`
+	MaxMs  float64 `json:"max_ms"`
+}
+
+type replayQueryJSON struct {
+	Fingerprint string  `json:"fingerprint"`
+	Example     string  `json:"example"`
+	Calls       int     `json:"calls"`
+	Errors      int     `json:"errors"`
+	CapturedMs  float64 `json:"captured_ms,omitempty"`
+	ReplayedMs  float64 `json:"replayed_ms"`
+	Change      float64 `json:"change,omitempty"`
+}
+
+func newReplayJSON(r *db.ReplayReport) replayJSON {
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	latency := func(s db.LatencySummary) latencyJSON {
+		return latencyJSON{Count: s.Count, MeanMs: ms(s.Mean), P50Ms: ms(s.P50), P95Ms: ms(s.P95), P99Ms: ms(s.P99), MaxMs: ms(s.Max)}
+	}
+	out := replayJSON{
+		Source:      r.Source,
+		Replayed:    r.Replayed,
+		Skipped:     r.Skipped,
+		Errors:      r.Errors,
+		ErrorSample: r.ErrorSample,
+		DurationMs:  ms(r.Duration),
+		MaxLagMs:    ms(r.MaxLag),
+		Interrupted: r.Interrupted,
+		Latency:     latency(r.Latency),
+		Captured:    latency(r.Original),
+		Compared:    latency(r.Compared),
+		Queries:     make([]replayQueryJSON, len(r.Queries)),
+	}
+	for i, q := range r.Queries {
+		out.Queries[i] = replayQueryJSON{
+			Fingerprint: q.Fingerprint,
+			Example:     q.Example,
+			Calls:       q.Calls,
+			Errors:      q.Errors,
+			CapturedMs:  ms(q.Original),
+			ReplayedMs:  ms(q.Replayed),
+			Change:      q.Change(),
+		}
+	}
+	return out
+}
+
+func init() {
+	workloadCaptureCmd.Flags().StringVarP(&workloadCaptureOutput, "output", "o", "", "File to save the capture to (default: workload_<timestamp>.json)")
+	workloadCaptureCmd.Flags().DurationVar(&workloadCaptureDuration, "duration", time.Minute, "How long to capture")
+	workloadCaptureCmd.Flags().DurationVar(&workloadCaptureInterval, "interval", db.DefaultCaptureInterval, "How often to sample sessions when there's no general log table")
+	workloadCaptureCmd.Flags().StringVar(&workloadCaptureFile, "file", "", "Read a MariaDB/MySQL general log file instead of the server")
+
+	workloadReplayCmd.Flags().Float64Var(&workloadReplaySpeed, "speed", 1, "Pace relative to the capture (2 is twice as fast, 0 back to back)")
+	workloadReplayCmd.Flags().IntVar(&workloadReplayWorkers, "concurrency", db.DefaultReplayConcurrency, "Connections to replay on")
+	workloadReplayCmd.Flags().BoolVar(&workloadReplayReadOnly, "read-only", false, "Only replay statements that read")
+	workloadReplayCmd.Flags().IntVar(&workloadReplayLimit, "limit", 20, "Query fingerprints to list (0 for all)")
+	workloadReplayCmd.Flags().BoolVarP(&workloadReplayYes, "yes", "y", false, "Don't ask before replaying writes")
+
+	workloadCmd.AddCommand(workloadCaptureCmd)
+	workloadCmd.AddCommand(workloadReplayCmd)
+	rootCmd.AddCommand(workloadCmd)
+}
@@ -0,0 +1,123 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+// Package clipboard puts text on the system clipboard. It uses the first
+// clipboard tool that works and otherwise asks the terminal to, with an
+// OSC 52 escape sequence, which also reaches the local clipboard over SSH.
+// A TUI must write that sequence through its renderer's Output.
+// In an SSH session the terminal is asked first, since a tool there would
+// fill the remote machine's clipboard rather than the user's.
+package clipboard
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// tools are tried in order; a tool without its display server fails and
+// the next one is tried
+var tools = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// maxOSC52 is the most text sent with OSC 52; terminals drop longer
+// sequences (xterm, for one, stops at about 100KB of base64)
+const maxOSC52 = 74994
+
+// Output is a terminal whose writes are serialized. A TUI renders through
+// it, so an OSC 52 sequence written from a command lands between two frames
+// rather than inside one.
+type Output struct {
+	mu sync.Mutex
+	*os.File
+}
+
+// NewOutput wraps the terminal f
+func NewOutput(f *os.File) *Output {
+	return &Output{File: f}
+}
+
+func (o *Output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.File.Write(p)
+}
+
+func (o *Output) WriteString(s string) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.File.WriteString(s)
+}
+
+// Copy puts text on the clipboard, writing any OSC 52 sequence to terminal.
+// It reports the tool used, or "terminal" when it used OSC 52, which can't
+// tell whether the terminal took it.
+func Copy(text string, terminal io.Writer) (string, error) {
+	if overSSH() {
+		return copyTerminal(text, terminal)
+	}
+	for _, tool := range tools {
+		path, err := exec.LookPath(tool[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, tool[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err == nil {
+			return tool[0], nil
+		}
+	}
+	return copyTerminal(text, terminal)
+}
+
+// copyTerminal asks the terminal to put text on the clipboard
+func copyTerminal(text string, terminal io.Writer) (string, error) {
+	if len(text) > maxOSC52 {
+		return "", fmt.Errorf("failed to copy to the clipboard: %d bytes is more than the terminal accepts (%d)", len(text), maxOSC52)
+	}
+	if err := writeOSC52(terminal, text, os.Getenv("TMUX") != ""); err != nil {
+		return "", fmt.Errorf("failed to copy to the clipboard: %w", err)
+	}
+	return "terminal", nil
+}
+
+// overSSH reports whether ysm runs in an SSH session
+func overSSH() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+// writeOSC52 asks the terminal to put text on the clipboard. Inside tmux the
+// sequence is wrapped so tmux passes it on to the outer terminal (this needs
+// allow-passthrough, or set-clipboard, in the tmux config).
+func writeOSC52(w io.Writer, text string, tmux bool) error {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if tmux {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	_, err := io.WriteString(w, seq)
+	return err
+}
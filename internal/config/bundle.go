@@ -0,0 +1,381 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/alert"
+	"github.com/blubskye/yandere_sql_manager/internal/webhook"
+	"gopkg.in/yaml.v3"
+)
+
+// settingsBundleVersion is written to every bundle, for later format changes
+const settingsBundleVersion = 1
+
+// Encrypted bundles are this header line, then the base64 of the PBKDF2
+// salt, the AES-GCM nonce and the sealed YAML
+const (
+	encryptedBundleHeader = "YSM-ENCRYPTED-SETTINGS v1\n"
+	bundleKDFIterations   = 600000
+	bundleSaltSize        = 16
+)
+
+// ErrBundleEncrypted is returned when reading an encrypted bundle without a passphrase
+var ErrBundleEncrypted = errors.New("the settings file is encrypted; a passphrase is needed")
+
+// SettingsBundle is the configuration, keybindings and snippets in one
+// portable file, for moving to another machine
+type SettingsBundle struct {
+	Version     int             `yaml:"version"`
+	ExportedAt  time.Time       `yaml:"exported_at"`
+	Config      *Config         `yaml:"config"`
+	KeyBindings *KeyBindings    `yaml:"keybindings,omitempty"`
+	Snippets    *SnippetLibrary `yaml:"snippets,omitempty"`
+}
+
+// NewSettingsBundle gathers the configuration with the keybindings and
+// snippets saved next to it. Without passwords, profile, SMTP and webhook
+// secrets are left out.
+func NewSettingsBundle(cfg *Config, withPasswords bool) (*SettingsBundle, error) {
+	kb, err := LoadKeyBindings()
+	if err != nil {
+		return nil, err
+	}
+	lib, err := LoadSnippets()
+	if err != nil {
+		return nil, err
+	}
+
+	// Work on a copy so the secrets can go without touching cfg
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var copied Config
+	if err := yaml.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("failed to copy config: %w", err)
+	}
+	if !withPasswords {
+		copied.stripSecrets()
+	}
+
+	return &SettingsBundle{
+		Version:     settingsBundleVersion,
+		ExportedAt:  time.Now().UTC().Truncate(time.Second),
+		Config:      &copied,
+		KeyBindings: kb,
+		Snippets:    lib,
+	}, nil
+}
+
+// stripSecrets clears passwords and webhook secrets
+func (c *Config) stripSecrets() {
+	for name, p := range c.Profiles {
+		p.Password = ""
+		c.Profiles[name] = p
+	}
+	for i := range c.Webhooks {
+		c.Webhooks[i].Secret = ""
+	}
+	if c.Alerts != nil {
+		for i := range c.Alerts.Webhooks {
+			c.Alerts.Webhooks[i].Secret = ""
+		}
+		if c.Alerts.Email != nil {
+			c.Alerts.Email.Password = ""
+		}
+	}
+}
+
+// HasSecrets reports whether the bundle holds passwords or webhook secrets
+func (b *SettingsBundle) HasSecrets() bool {
+	for _, p := range b.Config.Profiles {
+		if p.Password != "" {
+			return true
+		}
+	}
+	for _, w := range b.Config.Webhooks {
+		if w.Secret != "" {
+			return true
+		}
+	}
+	if b.Config.Alerts == nil {
+		return false
+	}
+	for _, w := range b.Config.Alerts.Webhooks {
+		if w.Secret != "" {
+			return true
+		}
+	}
+	return b.Config.Alerts.Email != nil && b.Config.Alerts.Email.Password != ""
+}
+
+// Marshal encodes the bundle as YAML, encrypted with the passphrase when one is given
+func (b *SettingsBundle) Marshal(passphrase string) ([]byte, error) {
+	data, err := yaml.Marshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	if passphrase == "" {
+		return append([]byte("# YSM settings export - import with 'ysm settings import'\n"), data...), nil
+	}
+
+	salt := make([]byte, bundleSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := bundleCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := gcm.Seal(nil, nonce, data, []byte(encryptedBundleHeader))
+	payload := slices.Concat(salt, nonce, sealed)
+	encoded := base64.StdEncoding.EncodeToString(payload)
+	return []byte(encryptedBundleHeader + encoded + "\n"), nil
+}
+
+// IsEncryptedBundle reports whether a settings file is encrypted
+func IsEncryptedBundle(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(encryptedBundleHeader))
+}
+
+// ParseSettingsBundle reads a file written by Marshal, decrypting it with the
+// passphrase. An encrypted file without a passphrase gives ErrBundleEncrypted.
+func ParseSettingsBundle(data []byte, passphrase string) (*SettingsBundle, error) {
+	if IsEncryptedBundle(data) {
+		if passphrase == "" {
+			return nil, ErrBundleEncrypted
+		}
+		payload, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data[len(encryptedBundleHeader):])))
+		if err != nil {
+			return nil, fmt.Errorf("damaged settings file: %w", err)
+		}
+		if len(payload) < bundleSaltSize {
+			return nil, fmt.Errorf("damaged settings file: too short")
+		}
+		gcm, err := bundleCipher(passphrase, payload[:bundleSaltSize])
+		if err != nil {
+			return nil, err
+		}
+		payload = payload[bundleSaltSize:]
+		if len(payload) < gcm.NonceSize() {
+			return nil, fmt.Errorf("damaged settings file: too short")
+		}
+		data, err = gcm.Open(nil, payload[:gcm.NonceSize()], payload[gcm.NonceSize():], []byte(encryptedBundleHeader))
+		if err != nil {
+			return nil, fmt.Errorf("wrong passphrase or damaged settings file")
+		}
+	}
+
+	var b SettingsBundle
+	if err := yaml.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse settings file: %w", err)
+	}
+	if b.Config == nil {
+		return nil, fmt.Errorf("not a YSM settings file (no config section)")
+	}
+	if b.Version > settingsBundleVersion {
+		return nil, fmt.Errorf("settings file version %d is newer than this YSM understands (%d)", b.Version, settingsBundleVersion)
+	}
+	return &b, nil
+}
+
+// bundleCipher derives the AES-256-GCM key from a passphrase
+func bundleCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, bundleKDFIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SettingsImport lists what applying a bundle changed and what it left alone
+type SettingsImport struct {
+	ProfilesAdded    []string
+	ProfilesReplaced []string
+	ProfilesKept     []string // Existing profiles the bundle also has, left as they were
+	Settings         []string // Settings taken from the bundle
+	SettingsKept     []string // Settings set both here and in the bundle, left as they were
+	KeyBindings      int      // Customized bindings taken from the bundle
+	KeyBindingsKept  int      // Bindings customized both here and in the bundle, left as they were
+	Snippets         int      // Snippets added or replaced
+}
+
+// Apply merges the bundle into the configuration, keybindings and snippets.
+// What is missing here is added. Profiles, settings and keybindings set both
+// here and in the bundle are kept unless overwrite is set, and snippets
+// with the same name likewise.
+func (b *SettingsBundle) Apply(cfg *Config, kb *KeyBindings, lib *SnippetLibrary, overwrite bool) *SettingsImport {
+	r := &SettingsImport{}
+
+	names := make([]string, 0, len(b.Config.Profiles))
+	for name := range b.Config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		incoming := b.Config.Profiles[name]
+		existing, exists := cfg.Profiles[name]
+		switch {
+		case !exists:
+			cfg.AddProfile(name, incoming)
+			r.ProfilesAdded = append(r.ProfilesAdded, name)
+		case reflect.DeepEqual(existing, incoming):
+		case overwrite:
+			// A bundle exported without passwords doesn't erase the one here
+			if incoming.Password == "" {
+				incoming.Password = existing.Password
+			}
+			cfg.AddProfile(name, incoming)
+			r.ProfilesReplaced = append(r.ProfilesReplaced, name)
+		default:
+			r.ProfilesKept = append(r.ProfilesKept, name)
+		}
+	}
+	if _, ok := cfg.Profiles[b.Config.DefaultProfile]; ok {
+		mergeSetting(r, "default_profile", &cfg.DefaultProfile, b.Config.DefaultProfile, overwrite)
+	}
+
+	in := *b.Config
+	in.Webhooks, in.Alerts = keepSecrets(cfg, in.Webhooks, in.Alerts)
+	mergeSetting(r, "idle_timeout", &cfg.IdleTimeout, in.IdleTimeout, overwrite)
+	mergeSetting(r, "metrics_interval", &cfg.MetricsInterval, in.MetricsInterval, overwrite)
+	mergeSetting(r, "host_metrics", &cfg.HostMetrics, in.HostMetrics, overwrite)
+	mergeSetting(r, "alerts", &cfg.Alerts, in.Alerts, overwrite)
+	mergeSetting(r, "notify", &cfg.Notify, in.Notify, overwrite)
+	mergeSetting(r, "query_watch", &cfg.QueryWatch, in.QueryWatch, overwrite)
+	mergeSetting(r, "safety_backups", &cfg.SafetyBackups, in.SafetyBackups, overwrite)
+	mergeSetting(r, "safe_mode", &cfg.SafeMode, in.SafeMode, overwrite)
+	mergeSetting(r, "passwords", &cfg.Passwords, in.Passwords, overwrite)
+	mergeSetting(r, "reports", &cfg.Reports, in.Reports, overwrite)
+	mergeSetting(r, "converters", &cfg.Converters, in.Converters, overwrite)
+	mergeSetting(r, "webhooks", &cfg.Webhooks, in.Webhooks, overwrite)
+	mergeSetting(r, "system_databases", &cfg.SystemDatabases, in.SystemDatabases, overwrite)
+	mergeSetting(r, "theme", &cfg.Theme, in.Theme, overwrite)
+	mergeSetting(r, "status_bar", &cfg.StatusBar, in.StatusBar, overwrite)
+	mergeSetting(r, "layout", &cfg.Layout, in.Layout, overwrite)
+
+	if b.KeyBindings != nil && kb != nil {
+		defaults := DefaultKeyBindings()
+		for _, view := range ViewNames() {
+			for action, key := range b.KeyBindings.bindings(view) {
+				if key == defaults.bindings(view)[action] {
+					continue // Not customized in the bundle
+				}
+				current := kb.bindings(view)[action]
+				switch {
+				case current == key:
+				case current == defaults.bindings(view)[action] || overwrite:
+					if kb.SetKey(view, action, key) == nil {
+						r.KeyBindings++
+					}
+				default:
+					r.KeyBindingsKept++
+				}
+			}
+		}
+	}
+
+	if b.Snippets != nil && lib != nil {
+		r.Snippets = lib.Merge(b.Snippets, overwrite)
+	}
+
+	return r
+}
+
+// keepSecrets fills in the webhook secrets and SMTP password a bundle
+// exported without passwords left out, from the same webhooks and SMTP
+// account here, so overwriting doesn't erase them
+func keepSecrets(cfg *Config, webhooks []webhook.Webhook, alerts *alert.Config) ([]webhook.Webhook, *alert.Config) {
+	webhooks = keepWebhookSecrets(webhooks, cfg.Webhooks)
+	if alerts == nil || cfg.Alerts == nil {
+		return webhooks, alerts
+	}
+
+	copied := *alerts
+	copied.Webhooks = keepWebhookSecrets(alerts.Webhooks, cfg.Alerts.Webhooks)
+	if alerts.Email != nil && alerts.Email.Password == "" && cfg.Alerts.Email != nil &&
+		cfg.Alerts.Email.SMTP == alerts.Email.SMTP && cfg.Alerts.Email.Username == alerts.Email.Username {
+		email := *alerts.Email
+		email.Password = cfg.Alerts.Email.Password
+		copied.Email = &email
+	}
+	return webhooks, &copied
+}
+
+// keepWebhookSecrets fills in the secrets of webhooks that have none from
+// the webhooks with the same URL in existing
+func keepWebhookSecrets(webhooks, existing []webhook.Webhook) []webhook.Webhook {
+	webhooks = slices.Clone(webhooks)
+	for i, w := range webhooks {
+		if w.Secret != "" {
+			continue
+		}
+		for _, e := range existing {
+			if e.URL == w.URL {
+				webhooks[i].Secret = e.Secret
+			}
+		}
+	}
+	return webhooks
+}
+
+// mergeSetting takes a setting from the bundle when it is unset here, or set
+// differently and overwrite is given
+func mergeSetting[T any](r *SettingsImport, name string, current *T, incoming T, overwrite bool) {
+	if reflect.ValueOf(&incoming).Elem().IsZero() || reflect.DeepEqual(*current, incoming) {
+		return
+	}
+	if !reflect.ValueOf(current).Elem().IsZero() && !overwrite {
+		r.SettingsKept = append(r.SettingsKept, name)
+		return
+	}
+	*current = incoming
+	r.Settings = append(r.Settings, name)
+}
+
+// bindings returns the global or a view's keybindings
+func (kb *KeyBindings) bindings(view string) map[KeyAction]string {
+	if view == "global" {
+		return kb.Global
+	}
+	return kb.viewBindings(view)
+}
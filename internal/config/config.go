@@ -19,20 +19,182 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/blubskye/yandere_sql_manager/internal/alert"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/scratch"
+	"github.com/blubskye/yandere_sql_manager/internal/webhook"
 	"gopkg.in/yaml.v3"
 )
 
 // Config holds the application configuration
 type Config struct {
-	Profiles       map[string]Profile `yaml:"profiles"`
-	DefaultProfile string             `yaml:"default_profile"`
+	Profiles        map[string]Profile     `yaml:"profiles"`
+	DefaultProfile  string                 `yaml:"default_profile"`
+	IdleTimeout     string                 `yaml:"idle_timeout,omitempty"`     // e.g. "15m"; empty disables the TUI lock
+	MetricsInterval string                 `yaml:"metrics_interval,omitempty"` // Dashboard trend sampling interval, e.g. "5s"
+	HostMetrics     bool                   `yaml:"host_metrics,omitempty"`     // Sample this machine's CPU, memory and disk with the server
+	Alerts          *alert.Config          `yaml:"alerts,omitempty"`           // Webhook/email alerts on cluster health changes
+	Notify          *NotifyConfig          `yaml:"notify,omitempty"`           // Bell/desktop notice when a long job ends unwatched
+	QueryWatch      *QueryWatchConfig      `yaml:"query_watch,omitempty"`      // Status bar warning about long-running queries
+	SafetyBackups   *SafetyBackupsConfig   `yaml:"safety_backups,omitempty"`   // Back up what drops, imports and restores are about to replace
+	Webhooks        []webhook.Webhook      `yaml:"webhooks,omitempty"`         // POSTed to when exports, imports, backups and restores finish
+	SystemDatabases *SystemDatabasesConfig `yaml:"system_databases,omitempty"` // Visibility and protection of system databases
+	Theme           string                 `yaml:"theme,omitempty"`            // TUI color scheme, built in or from the themes directory
+	StatusBar       *StatusBarConfig       `yaml:"status_bar,omitempty"`       // What the TUI status bar shows, and in which order
+	Layout          string                 `yaml:"layout,omitempty"`           // TUI layout density: auto (default), compact or normal
+	Temp            *TempConfig            `yaml:"temp,omitempty"`             // Where large intermediate files go
+	SafeMode        *SafeModeConfig        `yaml:"safe_mode,omitempty"`        // Query editor warnings about risky statements
+	Passwords       *PasswordsConfig       `yaml:"passwords,omitempty"`        // What Ctrl+G generates in password fields
+	Reports         []ReportConfig         `yaml:"reports,omitempty"`          // Scheduled health reports, sent by ysm scheduler run
+	Converters      []ConverterConfig      `yaml:"converters,omitempty"`       // Value converters for exports in another dialect
 }
 
+// SystemDatabasesConfig controls whether system databases are listed and
+// which databases can't be dropped or truncated. The server's own databases
+// (mysql, sys, information_schema, performance_schema, postgres, template0/1)
+// are always protected.
+type SystemDatabasesConfig struct {
+	Show      bool     `yaml:"show,omitempty"`      // List system databases in the TUI and ysm list
+	Protected []string `yaml:"protected,omitempty"` // More databases to protect
+	Unlocked  bool     `yaml:"unlocked,omitempty"`  // Allow dropping and truncating protected databases
+}
+
+// NotifyConfig controls how the TUI tells you a long export, import, backup
+// or restore has ended while you were in another view or window
+type NotifyConfig struct {
+	Bell    bool   `yaml:"bell,omitempty"`    // Ring the terminal bell
+	Desktop bool   `yaml:"desktop,omitempty"` // notify-send on Linux, osascript on macOS
+	After   string `yaml:"after,omitempty"`   // Only for jobs running at least this long (default 10s)
+}
+
+// DefaultNotifyAfter is how long a job must run before its end is announced
+const DefaultNotifyAfter = 10 * time.Second
+
+// QueryWatchConfig turns on a background check of the server's process list
+// that warns in the TUI status bar when a query runs too long
+type QueryWatchConfig struct {
+	After    string `yaml:"after,omitempty"`    // Warn about queries running at least this long (default 1m)
+	Interval string `yaml:"interval,omitempty"` // How often to check (default 15s)
+}
+
+// Query watch defaults
+const (
+	DefaultQueryWatchAfter    = time.Minute
+	DefaultQueryWatchInterval = 15 * time.Second
+)
+
+// StatusBarConfig picks the items shown in the TUI status bar. Errors, status
+// messages and query watch warnings are always shown after them.
+type StatusBarConfig struct {
+	Items          []string `yaml:"items,omitempty"`           // In display order; leave one out to hide it
+	HealthInterval string   `yaml:"health_interval,omitempty"` // How often to ping the server (default 10s)
+}
+
+// Status bar items
+const (
+	StatusTabs        = "tabs"        // Open tabs, when there's more than one
+	StatusProfile     = "profile"     // Profile the tab connected with
+	StatusServer      = "server"      // user@host:port
+	StatusType        = "type"        // Server type and version
+	StatusDatabase    = "database"    // Current database
+	StatusHealth      = "health"      // Ping round trip, or down
+	StatusJob         = "job"         // Running export, import, backup or restore
+	StatusConnections = "connections" // Open connections
+)
+
+// StatusBarItems lists every status bar item in its default order
+var StatusBarItems = []string{
+	StatusTabs, StatusProfile, StatusServer, StatusType,
+	StatusDatabase, StatusHealth, StatusJob, StatusConnections,
+}
+
+// DefaultHealthInterval is how often the status bar pings the server
+const DefaultHealthInterval = 10 * time.Second
+
+// Layout densities
+const (
+	LayoutAuto    = "auto"    // Compact below CompactLayoutWidth columns
+	LayoutCompact = "compact" // Stack panels and shorten the status bar
+	LayoutNormal  = "normal"
+)
+
+// CompactLayoutWidth is the terminal width below which the auto layout is
+// compact
+const CompactLayoutWidth = 100
+
+// SafetyBackupsConfig controls the quick backups taken of what a drop, an
+// import or a restore is about to replace, so it can be undone
+type SafetyBackupsConfig struct {
+	Enabled bool   `yaml:"enabled,omitempty"` // Take them without --safety-backup
+	Expire  string `yaml:"expire,omitempty"`  // Delete them once this old, e.g. 3d or 12h (default 7d)
+}
+
+// DefaultSafetyBackupExpire is how long safety backups are kept
+const DefaultSafetyBackupExpire = 7 * 24 * time.Hour
+
+// TempConfig controls the scratch space of parallel export spill files,
+// joined split dumps and transfer and plugin dumps. It is not carried over
+// by settings exports, as paths differ between machines.
+type TempConfig struct {
+	Dir     string `yaml:"dir,omitempty"`      // Temp directory (default $TMPDIR or /tmp)
+	MinFree string `yaml:"min_free,omitempty"` // Space always left free there, e.g. 2GB (default 512MB)
+}
+
+// SafeModeConfig tunes the query editor's safe mode, which lints statements
+// before running them and asks before running one it warns about
+type SafeModeConfig struct {
+	Disabled       bool     `yaml:"disabled,omitempty"`         // Run statements without linting them
+	Ignore         []string `yaml:"ignore,omitempty"`           // Rules not checked, e.g. select-star
+	BusinessHours  string   `yaml:"business_hours,omitempty"`   // When DDL on large tables warns (default 09:00-18:00)
+	BusinessDays   []string `yaml:"business_days,omitempty"`    // Days with business hours (default mon to fri)
+	LargeTableRows int64    `yaml:"large_table_rows,omitempty"` // Rows from which a table is large (default 1000000)
+	HugeTableRows  int64    `yaml:"huge_table_rows,omitempty"`  // Rows from which SELECT * warns (default 10000000)
+}
+
+// PasswordsConfig shapes the passwords generated with Ctrl+G in the TUI's
+// password fields
+type PasswordsConfig struct {
+	Length  int    `yaml:"length,omitempty"`  // Characters per password (default 24)
+	Charset string `yaml:"charset,omitempty"` // full (default), alnum or hex
+	Exclude string `yaml:"exclude,omitempty"` // Characters never used, e.g. l1O0
+}
+
+// ReportConfig schedules a profile's health report: its health checks,
+// dangerous grants and backup freshness, sent through the email and
+// webhooks of the alerts section
+type ReportConfig struct {
+	Profile      string   `yaml:"profile"`
+	Every        string   `yaml:"every,omitempty"`          // daily or weekly (default weekly)
+	Day          string   `yaml:"day,omitempty"`            // Day of weekly reports (default mon)
+	At           string   `yaml:"at,omitempty"`             // Local time to send at (default 08:00)
+	Format       string   `yaml:"format,omitempty"`         // html (default) or markdown
+	To           []string `yaml:"to,omitempty"`             // Email recipients (default those of alerts)
+	BackupMaxAge string   `yaml:"backup_max_age,omitempty"` // Newest backup older than this warns (default 7d)
+}
+
+// ConverterConfig picks a value converter for the columns of a source type
+// in exports written in another dialect
+type ConverterConfig struct {
+	Type    string `yaml:"type"`              // Source column type, e.g. datetime or bigint unsigned
+	Dialect string `yaml:"dialect,omitempty"` // sqlserver or oracle (default both)
+	Use     string `yaml:"use"`               // Converter, e.g. zero-date-null
+}
+
+// Report defaults
+const (
+	DefaultReportAt  = 8 * time.Hour
+	DefaultReportDay = time.Monday
+)
+
 // Profile holds connection settings for a database
 type Profile struct {
 	Type      string            `yaml:"type,omitempty"` // "mariadb" or "postgres" (default: mariadb)
@@ -43,6 +205,61 @@ type Profile struct {
 	Socket    string            `yaml:"socket,omitempty"`
 	Database  string            `yaml:"database,omitempty"`
 	Variables map[string]string `yaml:"variables,omitempty"`
+
+	// Statements run on every session YSM opens with this profile, e.g.
+	// SET sql_mode = 'STRICT_TRANS_TABLES' or SET search_path = app, public
+	OnConnect []string `yaml:"on_connect,omitempty"`
+
+	// Backup compression used when none is given, e.g. from ysm backup bench --save
+	Compression      string `yaml:"compression,omitempty"`
+	CompressionLevel int    `yaml:"compression_level,omitempty"`
+
+	// Leave owners and privileges out of PostgreSQL exports and restores, so
+	// a dump from one role restores under another, e.g. from ysm export --no-owner
+	NoOwner bool `yaml:"no_owner,omitempty"`
+	NoACL   bool `yaml:"no_acl,omitempty"`
+
+	// SQL run on this server around exports from it and restores to it
+	Scripts ProfileScripts `yaml:"scripts,omitempty"`
+
+	// Grouping in the TUI profile manager
+	Folder string   `yaml:"folder,omitempty"`
+	Tags   []string `yaml:"tags,omitempty"`
+
+	// Where the TUI opens after connecting, e.g. the dashboard for a
+	// monitoring profile or the tables of the app database for a dev one
+	StartupDatabase string `yaml:"startup_database,omitempty"`
+	StartupView     string `yaml:"startup_view,omitempty"` // One of StartupViews (default databases)
+}
+
+// StartupViews lists the TUI views a profile can open on connect
+var StartupViews = []string{
+	"databases", "tables", "query", "dashboard", "processes",
+	"cluster", "users", "backup", "jobs", "timeline", "audit",
+}
+
+// Startup returns the view the TUI opens after connecting with the profile
+// and the database it opens it on. A startup database alone opens its
+// tables. An invalid setting falls back to the database list with an error.
+func (p *Profile) Startup() (view, database string, err error) {
+	view, database = p.StartupView, p.StartupDatabase
+	switch {
+	case view == "" && database != "":
+		return "tables", database, nil
+	case view == "":
+		return "databases", "", nil
+	case !slices.Contains(StartupViews, view):
+		return "databases", "", fmt.Errorf("unknown startup_view %q (use %s)", view, strings.Join(StartupViews, ", "))
+	case view == "tables" && database == "":
+		return "databases", "", fmt.Errorf("startup_view %s needs a startup_database", view)
+	}
+	return view, database, nil
+}
+
+// ProfileScripts are a profile's scripts per operation
+type ProfileScripts struct {
+	Export  db.OperationScripts `yaml:"export,omitempty"`
+	Restore db.OperationScripts `yaml:"restore,omitempty"`
 }
 
 // ToConnectionConfig converts a Profile to db.ConnectionConfig
@@ -56,13 +273,14 @@ func (p *Profile) ToConnectionConfig() db.ConnectionConfig {
 		port = db.DefaultPort(dbType)
 	}
 	return db.ConnectionConfig{
-		Type:     dbType,
-		Host:     p.Host,
-		Port:     port,
-		User:     p.User,
-		Password: p.Password,
-		Socket:   p.Socket,
-		Database: p.Database,
+		Type:      dbType,
+		Host:      p.Host,
+		Port:      port,
+		User:      p.User,
+		Password:  p.Password,
+		Socket:    p.Socket,
+		Database:  p.Database,
+		OnConnect: p.OnConnect,
 	}
 }
 
@@ -91,6 +309,15 @@ func ConfigPath() (string, error) {
 	return filepath.Join(dir, "config.yaml"), nil
 }
 
+// ThemesDir returns the directory user themes are read from
+func ThemesDir() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "themes"), nil
+}
+
 // Load loads the configuration from disk
 func Load() (*Config, error) {
 	path, err := ConfigPath()
@@ -150,6 +377,365 @@ func (c *Config) Save() error {
 	return nil
 }
 
+// IdleLockAfter returns how long the TUI may sit idle before it locks, or 0
+// when locking is disabled
+func (c *Config) IdleLockAfter() (time.Duration, error) {
+	if c.IdleTimeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.IdleTimeout)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid idle_timeout %q: use a duration like 15m", c.IdleTimeout)
+	}
+	return d, nil
+}
+
+// SystemDatabasePolicy returns the system database policy from the config
+func (c *Config) SystemDatabasePolicy() db.SystemDatabasePolicy {
+	if c.SystemDatabases == nil {
+		return db.SystemDatabasePolicy{}
+	}
+	return db.SystemDatabasePolicy{
+		Show:      c.SystemDatabases.Show,
+		Protected: c.SystemDatabases.Protected,
+		Unlocked:  c.SystemDatabases.Unlocked,
+	}
+}
+
+// NotifyAfter returns how long a job must run before the TUI announces its end
+func (c *Config) NotifyAfter() (time.Duration, error) {
+	if c.Notify == nil || c.Notify.After == "" {
+		return DefaultNotifyAfter, nil
+	}
+	d, err := time.ParseDuration(c.Notify.After)
+	if err != nil || d < 0 {
+		return DefaultNotifyAfter, fmt.Errorf("invalid notify after %q: use a duration like 30s", c.Notify.After)
+	}
+	return d, nil
+}
+
+// QueryWatchTimes returns how long a query may run before the TUI warns about
+// it and how often the process list is checked
+func (c *Config) QueryWatchTimes() (after, interval time.Duration, err error) {
+	after, interval = DefaultQueryWatchAfter, DefaultQueryWatchInterval
+	if c.QueryWatch == nil {
+		return after, interval, nil
+	}
+	if c.QueryWatch.After != "" {
+		d, err := time.ParseDuration(c.QueryWatch.After)
+		if err != nil || d <= 0 {
+			return after, interval, fmt.Errorf("invalid query_watch after %q: use a duration like 2m", c.QueryWatch.After)
+		}
+		after = d
+	}
+	if c.QueryWatch.Interval != "" {
+		d, err := time.ParseDuration(c.QueryWatch.Interval)
+		if err != nil || d < time.Second {
+			return after, interval, fmt.Errorf("invalid query_watch interval %q: use a duration of at least 1s", c.QueryWatch.Interval)
+		}
+		interval = d
+	}
+	return after, interval, nil
+}
+
+// StatusBarSettings returns the status bar items in order and how often the
+// server is pinged for the health item
+func (c *Config) StatusBarSettings() (items []string, health time.Duration, err error) {
+	items, health = StatusBarItems, DefaultHealthInterval
+	if c.StatusBar == nil {
+		return items, health, nil
+	}
+	if len(c.StatusBar.Items) > 0 {
+		for _, item := range c.StatusBar.Items {
+			if !slices.Contains(StatusBarItems, item) {
+				return StatusBarItems, health, fmt.Errorf("unknown status_bar item %q (use %s)", item, strings.Join(StatusBarItems, ", "))
+			}
+		}
+		items = c.StatusBar.Items
+	}
+	if c.StatusBar.HealthInterval != "" {
+		d, err := time.ParseDuration(c.StatusBar.HealthInterval)
+		if err != nil || d < time.Second {
+			return items, health, fmt.Errorf("invalid status_bar health_interval %q: use a duration of at least 1s", c.StatusBar.HealthInterval)
+		}
+		health = d
+	}
+	return items, health, nil
+}
+
+// CompactLayout reports whether the TUI should use the compact layout at the
+// given terminal width
+func (c *Config) CompactLayout(width int) (bool, error) {
+	switch c.Layout {
+	case "", LayoutAuto:
+		return width > 0 && width < CompactLayoutWidth, nil
+	case LayoutCompact:
+		return true, nil
+	case LayoutNormal:
+		return false, nil
+	}
+	return width > 0 && width < CompactLayoutWidth,
+		fmt.Errorf("invalid layout %q (use %s, %s or %s)", c.Layout, LayoutAuto, LayoutCompact, LayoutNormal)
+}
+
+// SafetyBackupSettings reports whether safety backups are taken by default
+// and how long they are kept
+func (c *Config) SafetyBackupSettings() (enabled bool, expire time.Duration, err error) {
+	if c.SafetyBackups == nil {
+		return false, DefaultSafetyBackupExpire, nil
+	}
+	enabled, expire = c.SafetyBackups.Enabled, DefaultSafetyBackupExpire
+	if value := c.SafetyBackups.Expire; value != "" {
+		d, err := parseDays(value)
+		if err != nil || d <= 0 {
+			return enabled, DefaultSafetyBackupExpire, fmt.Errorf("invalid safety_backups expire %q: use a duration like 3d or 12h", value)
+		}
+		expire = d
+	}
+	return enabled, expire, nil
+}
+
+// parseDays parses a duration that may also be given in days, like 3d
+func parseDays(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		return time.Duration(n) * 24 * time.Hour, err
+	}
+	return time.ParseDuration(value)
+}
+
+// ScratchSettings returns where temporary files go and how much space they
+// leave free
+func (c *Config) ScratchSettings() (scratch.Settings, error) {
+	s := scratch.Settings{MinFree: scratch.DefaultMinFree}
+	if c.Temp == nil {
+		return s, nil
+	}
+	if c.Temp.Dir != "" {
+		if info, err := os.Stat(c.Temp.Dir); err != nil || !info.IsDir() {
+			return s, fmt.Errorf("invalid temp dir %q: not an existing directory", c.Temp.Dir)
+		}
+		s.Dir = c.Temp.Dir
+	}
+	if c.Temp.MinFree != "" {
+		n, err := db.ParseSize(c.Temp.MinFree)
+		if err != nil {
+			return s, fmt.Errorf("invalid temp min_free: %w", err)
+		}
+		s.MinFree = n
+	}
+	return s, nil
+}
+
+// SafeModePolicy returns the lint policy of the query editor's safe mode.
+// An invalid setting is reported and left at its default.
+func (c *Config) SafeModePolicy() (db.LintPolicy, error) {
+	policy := db.DefaultLintPolicy()
+	s := c.SafeMode
+	if s == nil {
+		return policy, nil
+	}
+	policy.Disabled = s.Disabled
+
+	var errs []error
+	for _, rule := range s.Ignore {
+		if !slices.Contains(db.LintRules, rule) {
+			errs = append(errs, fmt.Errorf("unknown safe_mode rule %q (use %s)", rule, strings.Join(db.LintRules, ", ")))
+			continue
+		}
+		policy.Ignore = append(policy.Ignore, rule)
+	}
+	if s.BusinessHours != "" {
+		start, end, err := parseHours(s.BusinessHours)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid safe_mode business_hours %q: use a range like 08:30-17:00", s.BusinessHours))
+		} else {
+			policy.BusinessStart, policy.BusinessEnd = start, end
+		}
+	}
+	if len(s.BusinessDays) > 0 {
+		var days []time.Weekday
+		for _, name := range s.BusinessDays {
+			day, ok := parseWeekday(name)
+			if !ok {
+				errs = append(errs, fmt.Errorf("invalid safe_mode business day %q: use mon, tue, ...", name))
+				continue
+			}
+			days = append(days, day)
+		}
+		if len(days) > 0 {
+			policy.BusinessDays = days
+		}
+	}
+	if s.LargeTableRows > 0 {
+		policy.LargeTableRows = s.LargeTableRows
+	}
+	if s.HugeTableRows > 0 {
+		policy.HugeTableRows = s.HugeTableRows
+	}
+	return policy, errors.Join(errs...)
+}
+
+// PasswordPolicy returns the policy of generated passwords. An invalid
+// setting is reported and left at its default.
+func (c *Config) PasswordPolicy() (db.PasswordPolicy, error) {
+	policy := db.DefaultPasswordPolicy()
+	p := c.Passwords
+	if p == nil {
+		return policy, nil
+	}
+
+	var errs []error
+	if p.Length != 0 {
+		if p.Length < db.MinPasswordLength || p.Length > db.MaxPasswordLength {
+			errs = append(errs, fmt.Errorf("invalid passwords length %d: use %d to %d", p.Length, db.MinPasswordLength, db.MaxPasswordLength))
+		} else {
+			policy.Length = p.Length
+		}
+	}
+	if p.Charset != "" {
+		if !slices.Contains(db.PasswordCharsets, p.Charset) {
+			errs = append(errs, fmt.Errorf("unknown passwords charset %q (use %s)", p.Charset, strings.Join(db.PasswordCharsets, ", ")))
+		} else {
+			policy.Charset = p.Charset
+		}
+	}
+	policy.Exclude = p.Exclude
+	if _, err := db.GeneratePassword(policy); err != nil {
+		errs = append(errs, fmt.Errorf("invalid passwords exclude %q: %w", p.Exclude, err))
+		policy.Exclude = ""
+	}
+	return policy, errors.Join(errs...)
+}
+
+// Report returns the report settings of a profile, or the defaults when it
+// has none
+func (c *Config) Report(profile string) ReportConfig {
+	for _, r := range c.Reports {
+		if r.Profile == profile {
+			return r
+		}
+	}
+	return ReportConfig{Profile: profile}
+}
+
+// Schedule returns when the report goes out
+func (r ReportConfig) Schedule() (db.DigestSchedule, error) {
+	schedule := db.DigestSchedule{Weekly: true, Day: DefaultReportDay, At: DefaultReportAt}
+	switch r.Every {
+	case "", "weekly":
+	case "daily":
+		schedule.Weekly = false
+	default:
+		return schedule, fmt.Errorf("invalid report every %q for profile '%s': use daily or weekly", r.Every, r.Profile)
+	}
+	if r.Day != "" {
+		day, ok := parseWeekday(r.Day)
+		if !ok {
+			return schedule, fmt.Errorf("invalid report day %q for profile '%s': use mon, tue, ...", r.Day, r.Profile)
+		}
+		schedule.Day = day
+	}
+	if r.At != "" {
+		at, err := parseClock(r.At)
+		if err != nil || at >= 24*time.Hour {
+			return schedule, fmt.Errorf("invalid report at %q for profile '%s': use a time like 08:00", r.At, r.Profile)
+		}
+		schedule.At = at
+	}
+	return schedule, nil
+}
+
+// Settings returns the report's format and the backup age that warns
+func (r ReportConfig) Settings() (format string, backupMaxAge time.Duration, err error) {
+	format, backupMaxAge = db.DigestFormatHTML, db.DefaultBackupMaxAge
+	if r.Format != "" {
+		if !slices.Contains(db.DigestFormats, r.Format) {
+			return format, backupMaxAge, fmt.Errorf("unknown report format %q for profile '%s' (use %s)", r.Format, r.Profile, strings.Join(db.DigestFormats, ", "))
+		}
+		format = r.Format
+	}
+	if r.BackupMaxAge != "" {
+		d, err := parseDays(r.BackupMaxAge)
+		if err != nil || d <= 0 {
+			return format, backupMaxAge, fmt.Errorf("invalid report backup_max_age %q for profile '%s': use a duration like 7d or 36h", r.BackupMaxAge, r.Profile)
+		}
+		backupMaxAge = d
+	}
+	return format, backupMaxAge, nil
+}
+
+// ValueConverters returns the converters of the converters section. Invalid
+// entries are reported and left out.
+func (c *Config) ValueConverters() (*db.ValueConverters, error) {
+	converters := db.NewValueConverters()
+	var errs []error
+	for _, conv := range c.Converters {
+		dialect, err := db.ParseOutputDialect(conv.Dialect)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid converter dialect for %s: %w", conv.Type, err))
+			continue
+		}
+		if err := converters.Use(conv.Type, dialect, conv.Use); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return converters, errors.Join(errs...)
+}
+
+// parseHours parses a time range like 09:00-18:00 into offsets since
+// midnight. An end before the start runs past midnight.
+func parseHours(value string) (start, end time.Duration, err error) {
+	from, to, ok := strings.Cut(value, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("missing -")
+	}
+	if start, err = parseClock(from); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseClock(to); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// parseClock parses a time of day like 9:00 or 17:30, or 24:00 for the end
+// of the day
+func parseClock(value string) (time.Duration, error) {
+	h, m, ok := strings.Cut(strings.TrimSpace(value), ":")
+	hours, err1 := strconv.Atoi(h)
+	minutes, err2 := strconv.Atoi(m)
+	if !ok || err1 != nil || err2 != nil || hours < 0 || minutes < 0 || minutes > 59 || hours*60+minutes > 24*60 {
+		return 0, fmt.Errorf("invalid time %q", value)
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+}
+
+// parseWeekday parses a day name like mon or Monday
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || len(name) >= 3 && strings.HasPrefix(full, name) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// MetricsSampleInterval returns the dashboard trend sampling interval,
+// defaulting to db.DefaultMetricsInterval
+func (c *Config) MetricsSampleInterval() (time.Duration, error) {
+	if c.MetricsInterval == "" {
+		return db.DefaultMetricsInterval, nil
+	}
+	d, err := time.ParseDuration(c.MetricsInterval)
+	if err != nil || d < time.Second {
+		return db.DefaultMetricsInterval, fmt.Errorf("invalid metrics_interval %q: use a duration of at least 1s", c.MetricsInterval)
+	}
+	return d, nil
+}
+
 // GetProfile returns a profile by name
 func (c *Config) GetProfile(name string) (*Profile, error) {
 	if name == "" {
@@ -189,6 +775,27 @@ func (c *Config) RemoveProfile(name string) error {
 	return nil
 }
 
+// RenameProfile renames a profile, keeping it the default if it was
+func (c *Config) RenameProfile(oldName, newName string) error {
+	profile, ok := c.Profiles[oldName]
+	if !ok {
+		return fmt.Errorf("profile '%s' not found", oldName)
+	}
+	if oldName == newName {
+		return nil
+	}
+	if _, exists := c.Profiles[newName]; exists {
+		return fmt.Errorf("profile '%s' already exists", newName)
+	}
+
+	delete(c.Profiles, oldName)
+	c.Profiles[newName] = profile
+	if c.DefaultProfile == oldName {
+		c.DefaultProfile = newName
+	}
+	return nil
+}
+
 // SetDefault sets the default profile
 func (c *Config) SetDefault(name string) error {
 	if name != "" {
@@ -43,6 +43,7 @@ const (
 	ActionPageDown    KeyAction = "page_down"
 	ActionTop         KeyAction = "top"
 	ActionBottom      KeyAction = "bottom"
+	ActionHelp        KeyAction = "help"
 
 	// View switching actions
 	ActionNewDatabase KeyAction = "new_database"
@@ -55,6 +56,16 @@ const (
 	ActionQuery       KeyAction = "query"
 	ActionVariables   KeyAction = "variables"
 	ActionSettings    KeyAction = "settings"
+	ActionPlugins     KeyAction = "plugins"
+	ActionSchemaDiff  KeyAction = "schema_diff"
+	ActionSync        KeyAction = "sync"
+	ActionForeignLink KeyAction = "foreign_link"
+	ActionTransfer    KeyAction = "transfer"
+	ActionCloneMerge  KeyAction = "clone_merge"
+	ActionJobs        KeyAction = "jobs"
+	ActionTimeline    KeyAction = "timeline"
+	ActionAuditLog    KeyAction = "audit_log"
+	ActionProfiles    KeyAction = "profiles"
 
 	// Editing actions
 	ActionEdit        KeyAction = "edit"
@@ -62,6 +73,17 @@ const (
 	ActionCreate      KeyAction = "create"
 	ActionSave        KeyAction = "save"
 	ActionCancel      KeyAction = "cancel"
+	ActionSnippets    KeyAction = "snippets"
+	ActionChart       KeyAction = "chart"
+
+	// Data grid actions
+	ActionSort         KeyAction = "sort"
+	ActionNextColumn   KeyAction = "next_column"
+	ActionPrevColumn   KeyAction = "prev_column"
+	ActionExportResult KeyAction = "export_result"
+	ActionCopyCell     KeyAction = "copy_cell"
+	ActionCopyRow      KeyAction = "copy_row"
+	ActionViewCell     KeyAction = "view_cell"
 
 	// Toggle actions
 	ActionToggleGlobal KeyAction = "toggle_global"
@@ -107,6 +129,7 @@ func DefaultKeyBindings() *KeyBindings {
 		Global: map[KeyAction]string{
 			ActionQuit:     "q",
 			ActionBack:     "esc",
+			ActionHelp:     "?",
 			ActionSelect:   "enter",
 			ActionFilter:   "/",
 			ActionRefresh:  "r",
@@ -127,7 +150,17 @@ func DefaultKeyBindings() *KeyBindings {
 			ActionExport:      "e",
 			ActionQuery:       "s",
 			ActionVariables:   "v",
-			ActionSettings:    "?",
+			ActionSettings:    "K",
+			ActionPlugins:     "p",
+			ActionSchemaDiff:  "m",
+			ActionSync:        "y",
+			ActionForeignLink: "f",
+			ActionTransfer:    "t",
+			ActionCloneMerge:  "C",
+			ActionJobs:        "J",
+			ActionTimeline:    "T",
+			ActionAuditLog:    "a",
+			ActionProfiles:    "P",
 		},
 		Tables: map[KeyAction]string{
 			ActionQuery:  "s",
@@ -135,12 +168,24 @@ func DefaultKeyBindings() *KeyBindings {
 			ActionExport: "e",
 		},
 		Browser: map[KeyAction]string{
-			ActionEdit:   "e",
-			ActionDelete: "d",
+			ActionEdit:         "enter",
+			ActionCreate:       "n",
+			ActionDelete:       "d",
+			ActionSort:         "s",
+			ActionNextColumn:   "tab",
+			ActionPrevColumn:   "shift+tab",
+			ActionClearFilter:  "c",
+			ActionExportResult: "e",
+			ActionCopyCell:     "y",
+			ActionCopyRow:      "ctrl+y",
+			ActionViewCell:     "v",
 		},
 		Query: map[KeyAction]string{
-			ActionSave:   "ctrl+s",
-			ActionCancel: "esc",
+			ActionSave:     "ctrl+s",
+			ActionCancel:   "esc",
+			ActionSnippets: "ctrl+o",
+			ActionChart:    "ctrl+l",
+			ActionHelp:     "f1", // ? is typed into queries
 		},
 		Settings: map[KeyAction]string{
 			ActionToggleGlobal: "g",
@@ -246,37 +291,37 @@ func (kb *KeyBindings) Save() error {
 	return nil
 }
 
-// GetKey returns the key for an action in a specific view
-// Falls back to global keybindings if not found in view-specific bindings
-func (kb *KeyBindings) GetKey(view string, action KeyAction) string {
-	var viewBindings map[KeyAction]string
-
+// viewBindings returns a view's own keybindings, nil for unknown views
+func (kb *KeyBindings) viewBindings(view string) map[KeyAction]string {
 	switch view {
 	case "databases":
-		viewBindings = kb.Databases
+		return kb.Databases
 	case "tables":
-		viewBindings = kb.Tables
+		return kb.Tables
 	case "browser":
-		viewBindings = kb.Browser
+		return kb.Browser
 	case "query":
-		viewBindings = kb.Query
+		return kb.Query
 	case "settings":
-		viewBindings = kb.Settings
+		return kb.Settings
 	case "users":
-		viewBindings = kb.Users
+		return kb.Users
 	case "backup":
-		viewBindings = kb.Backup
+		return kb.Backup
 	case "dashboard":
-		viewBindings = kb.Dashboard
+		return kb.Dashboard
 	case "cluster":
-		viewBindings = kb.Cluster
+		return kb.Cluster
 	}
+	return nil
+}
 
+// GetKey returns the key for an action in a specific view
+// Falls back to global keybindings if not found in view-specific bindings
+func (kb *KeyBindings) GetKey(view string, action KeyAction) string {
 	// Check view-specific binding first
-	if viewBindings != nil {
-		if key, ok := viewBindings[action]; ok {
-			return key
-		}
+	if key, ok := kb.viewBindings(view)[action]; ok {
+		return key
 	}
 
 	// Fall back to global binding
@@ -287,6 +332,49 @@ func (kb *KeyBindings) GetKey(view string, action KeyAction) string {
 	return ""
 }
 
+// HelpSection is one category of keybindings in a view's help
+type HelpSection struct {
+	Category string
+	Bindings []KeyBinding
+}
+
+// helpCategories is the order categories appear in help
+var helpCategories = []string{"Navigation", "Views", "Editing", "Data", "Toggles", "Tabs"}
+
+// Help returns the keys that work in a view, its own and the global ones it
+// doesn't override, grouped by the categories of AllActions
+func (kb *KeyBindings) Help(view string) []HelpSection {
+	bindings := make(map[KeyAction]string)
+	for action, key := range kb.Global {
+		bindings[action] = key
+	}
+	for action, key := range kb.viewBindings(view) {
+		bindings[action] = key
+	}
+
+	all := AllActions()
+	var sections []HelpSection
+	for _, category := range helpCategories {
+		section := HelpSection{Category: category}
+		for _, action := range all[category] {
+			key, ok := bindings[action]
+			if !ok || key == "" {
+				continue
+			}
+			section.Bindings = append(section.Bindings, KeyBinding{
+				Key:         key,
+				Action:      action,
+				Description: GetActionDescription(action),
+			})
+			delete(bindings, action) // Actions listed in two categories show once
+		}
+		if len(section.Bindings) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
 // SetKey sets a keybinding for an action
 func (kb *KeyBindings) SetKey(view string, action KeyAction, key string) error {
 	key = strings.ToLower(strings.TrimSpace(key))
@@ -372,6 +460,7 @@ func GetActionDescription(action KeyAction) string {
 		ActionPageDown:          "Page down",
 		ActionTop:               "Go to top",
 		ActionBottom:            "Go to bottom",
+		ActionHelp:              "Show key help",
 		ActionNewDatabase:       "New database (wizard)",
 		ActionDashboard:         "Statistics dashboard",
 		ActionCluster:           "Cluster status",
@@ -382,11 +471,30 @@ func GetActionDescription(action KeyAction) string {
 		ActionQuery:             "SQL query editor",
 		ActionVariables:         "System variables",
 		ActionSettings:          "Settings & keybindings",
+		ActionPlugins:           "Plugin views",
+		ActionSchemaDiff:        "Compare schemas",
+		ActionSync:              "Sync databases",
+		ActionForeignLink:       "Link another server",
+		ActionTransfer:          "Copy to another tab's server",
+		ActionCloneMerge:        "Clone or merge databases",
+		ActionJobs:              "Background jobs",
+		ActionTimeline:          "Event timeline",
+		ActionAuditLog:          "Audit log of destructive actions",
+		ActionProfiles:          "Manage connection profiles",
 		ActionEdit:              "Edit item",
 		ActionDelete:            "Delete item",
 		ActionCreate:            "Create new",
 		ActionSave:              "Save changes",
 		ActionCancel:            "Cancel",
+		ActionSnippets:          "Saved queries",
+		ActionChart:             "Chart results (bars, line)",
+		ActionSort:              "Cycle column sort",
+		ActionNextColumn:        "Next column",
+		ActionPrevColumn:        "Previous column",
+		ActionExportResult:      "Export filtered result",
+		ActionCopyCell:          "Copy cell to clipboard",
+		ActionCopyRow:           "Copy row to clipboard",
+		ActionViewCell:          "View cell (text, JSON, hex dump)",
 		ActionToggleGlobal:      "Toggle global/session",
 		ActionToggleAutoRefresh: "Toggle auto-refresh",
 		ActionClearFilter:       "Clear filter",
@@ -419,6 +527,7 @@ func AllActions() map[string][]KeyAction {
 			ActionPageDown,
 			ActionTop,
 			ActionBottom,
+			ActionHelp,
 		},
 		"Views": {
 			ActionNewDatabase,
@@ -431,6 +540,16 @@ func AllActions() map[string][]KeyAction {
 			ActionQuery,
 			ActionVariables,
 			ActionSettings,
+			ActionPlugins,
+			ActionSchemaDiff,
+			ActionSync,
+			ActionForeignLink,
+			ActionTransfer,
+			ActionCloneMerge,
+			ActionJobs,
+			ActionTimeline,
+			ActionAuditLog,
+			ActionProfiles,
 		},
 		"Editing": {
 			ActionEdit,
@@ -438,6 +557,17 @@ func AllActions() map[string][]KeyAction {
 			ActionCreate,
 			ActionSave,
 			ActionCancel,
+			ActionSnippets,
+			ActionChart,
+		},
+		"Data": {
+			ActionSort,
+			ActionNextColumn,
+			ActionPrevColumn,
+			ActionExportResult,
+			ActionCopyCell,
+			ActionCopyRow,
+			ActionViewCell,
 		},
 		"Toggles": {
 			ActionToggleGlobal,
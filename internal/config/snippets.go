@@ -0,0 +1,235 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSnippetProfile is the snippet group used when no profile is active
+const DefaultSnippetProfile = "default"
+
+// snippetPlaceholderRe matches {{name}} placeholders in a snippet query
+var snippetPlaceholderRe = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// Snippet is a named, reusable SQL query with optional {{placeholders}}
+type Snippet struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Query       string `yaml:"query"`
+}
+
+// SnippetLibrary holds saved snippets grouped by profile name
+type SnippetLibrary struct {
+	Profiles map[string][]Snippet `yaml:"profiles"`
+}
+
+// Placeholders returns the unique placeholder names in order of first appearance
+func (s *Snippet) Placeholders() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range snippetPlaceholderRe.FindAllStringSubmatch(s.Query, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// Render substitutes placeholder values into the snippet query.
+// Placeholders without a value are left untouched.
+func (s *Snippet) Render(values map[string]string) string {
+	return snippetPlaceholderRe.ReplaceAllStringFunc(s.Query, func(match string) string {
+		name := snippetPlaceholderRe.FindStringSubmatch(match)[1]
+		if val, ok := values[name]; ok {
+			return val
+		}
+		return match
+	})
+}
+
+// SnippetsPath returns the snippets file path
+func SnippetsPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snippets.yaml"), nil
+}
+
+// LoadSnippets loads the snippet library from disk
+func LoadSnippets() (*SnippetLibrary, error) {
+	path, err := SnippetsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	lib, err := ReadSnippetsFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &SnippetLibrary{Profiles: make(map[string][]Snippet)}, nil
+		}
+		return nil, err
+	}
+	return lib, nil
+}
+
+// ReadSnippetsFile reads a snippet library from an arbitrary file
+func ReadSnippetsFile(path string) (*SnippetLibrary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read snippets file: %w", err)
+	}
+
+	var lib SnippetLibrary
+	if err := yaml.Unmarshal(data, &lib); err != nil {
+		return nil, fmt.Errorf("failed to parse snippets file: %w", err)
+	}
+	if lib.Profiles == nil {
+		lib.Profiles = make(map[string][]Snippet)
+	}
+	return &lib, nil
+}
+
+// Save saves the snippet library to disk
+func (l *SnippetLibrary) Save() error {
+	dir, err := ConfigDir()
+	if err != nil {
+		return err
+	}
+
+	// Create config directory if it doesn't exist
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	path, err := SnippetsPath()
+	if err != nil {
+		return err
+	}
+	return l.WriteFile(path)
+}
+
+// WriteFile writes the snippet library to an arbitrary file
+func (l *SnippetLibrary) WriteFile(path string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snippets: %w", err)
+	}
+
+	header := `# YSM Saved Queries
+# Use {{name}} placeholders to be prompted for values when running a snippet~
+
+`
+	if err := os.WriteFile(path, []byte(header+string(data)), 0644); err != nil {
+		return fmt.Errorf("failed to write snippets file: %w", err)
+	}
+	return nil
+}
+
+// snippetProfileKey maps an empty profile name to the default group
+func snippetProfileKey(profile string) string {
+	if profile == "" {
+		return DefaultSnippetProfile
+	}
+	return profile
+}
+
+// ForProfile returns the snippets for a profile, sorted by name
+func (l *SnippetLibrary) ForProfile(profile string) []Snippet {
+	snippets := append([]Snippet(nil), l.Profiles[snippetProfileKey(profile)]...)
+	sort.Slice(snippets, func(i, j int) bool {
+		return strings.ToLower(snippets[i].Name) < strings.ToLower(snippets[j].Name)
+	})
+	return snippets
+}
+
+// GetSnippet returns a snippet by name for a profile
+func (l *SnippetLibrary) GetSnippet(profile, name string) (*Snippet, error) {
+	for _, s := range l.Profiles[snippetProfileKey(profile)] {
+		if s.Name == name {
+			return &s, nil
+		}
+	}
+	return nil, fmt.Errorf("snippet '%s' not found", name)
+}
+
+// AddSnippet adds or replaces a snippet for a profile
+func (l *SnippetLibrary) AddSnippet(profile string, snippet Snippet) error {
+	snippet.Name = strings.TrimSpace(snippet.Name)
+	if snippet.Name == "" {
+		return fmt.Errorf("snippet name cannot be empty")
+	}
+	if strings.TrimSpace(snippet.Query) == "" {
+		return fmt.Errorf("snippet query cannot be empty")
+	}
+
+	key := snippetProfileKey(profile)
+	for i, s := range l.Profiles[key] {
+		if s.Name == snippet.Name {
+			l.Profiles[key][i] = snippet
+			return nil
+		}
+	}
+	l.Profiles[key] = append(l.Profiles[key], snippet)
+	return nil
+}
+
+// RemoveSnippet removes a snippet from a profile
+func (l *SnippetLibrary) RemoveSnippet(profile, name string) error {
+	key := snippetProfileKey(profile)
+	for i, s := range l.Profiles[key] {
+		if s.Name == name {
+			l.Profiles[key] = append(l.Profiles[key][:i], l.Profiles[key][i+1:]...)
+			if len(l.Profiles[key]) == 0 {
+				delete(l.Profiles, key)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("snippet '%s' not found", name)
+}
+
+// Merge adds all snippets from another library, returning how many were added or replaced.
+// Existing snippets with the same name are only replaced when overwrite is set.
+func (l *SnippetLibrary) Merge(other *SnippetLibrary, overwrite bool) int {
+	count := 0
+	for profile, snippets := range other.Profiles {
+		for _, s := range snippets {
+			if existing, err := l.GetSnippet(profile, s.Name); err == nil && (!overwrite || *existing == s) {
+				continue
+			}
+			if err := l.AddSnippet(profile, s); err == nil {
+				count++
+			}
+		}
+	}
+	return count
+}
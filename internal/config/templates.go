@@ -0,0 +1,212 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateLibrary holds the user's application templates, which are
+// merged with the built-in ones in the setup wizard and `ysm db create`
+type TemplateLibrary struct {
+	Templates []db.AppTemplate `yaml:"templates"`
+}
+
+// TemplatesPath returns the custom templates file path
+func TemplatesPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "templates.yaml"), nil
+}
+
+// LoadTemplateLibrary loads the custom templates, as written in the file.
+// A missing file is an empty library.
+func LoadTemplateLibrary() (*TemplateLibrary, error) {
+	path, err := TemplatesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &TemplateLibrary{}, nil
+		}
+		return nil, fmt.Errorf("failed to read templates file: %w", err)
+	}
+
+	var lib TemplateLibrary
+	if err := yaml.Unmarshal(data, &lib); err != nil {
+		return nil, fmt.Errorf("failed to parse templates file: %w", err)
+	}
+	return &lib, nil
+}
+
+// Save saves the custom templates to disk
+func (l *TemplateLibrary) Save() error {
+	dir, err := ConfigDir()
+	if err != nil {
+		return err
+	}
+
+	// Create config directory if it doesn't exist
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	path, err := TemplatesPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("failed to marshal templates: %w", err)
+	}
+
+	header := `# YSM Application Templates
+# Added to the setup wizard; one named like a built-in template replaces it.
+# bootstrap is a SQL script, relative to this directory, run in the new database~
+
+`
+	if err := os.WriteFile(path, []byte(header+string(data)), 0644); err != nil {
+		return fmt.Errorf("failed to write templates file: %w", err)
+	}
+	return nil
+}
+
+// Validate checks every template, as the setup wizard would use it
+func (l *TemplateLibrary) Validate() error {
+	seen := make(map[string]bool)
+	for _, t := range l.Templates {
+		if seen[t.Name] {
+			return fmt.Errorf("template %s is defined twice", t.Name)
+		}
+		seen[t.Name] = true
+		resolved := resolveTemplate(t)
+		if err := resolved.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns a custom template by name
+func (l *TemplateLibrary) Get(name string) (*db.AppTemplate, bool) {
+	for i := range l.Templates {
+		if l.Templates[i].Name == name {
+			return &l.Templates[i], true
+		}
+	}
+	return nil, false
+}
+
+// Put validates a template and adds it, or replaces the one named
+// replacing, which is empty for a new template
+func (l *TemplateLibrary) Put(replacing string, t db.AppTemplate) error {
+	t.Name = strings.TrimSpace(t.Name)
+	t.Custom = false
+	resolved := resolveTemplate(t)
+	if err := resolved.Validate(); err != nil {
+		return err
+	}
+	if _, ok := l.Get(t.Name); ok && t.Name != replacing {
+		return fmt.Errorf("a custom template named %s already exists", t.Name)
+	}
+
+	if replacing != "" {
+		for i := range l.Templates {
+			if l.Templates[i].Name == replacing {
+				l.Templates[i] = t
+				return nil
+			}
+		}
+	}
+	l.Templates = append(l.Templates, t)
+	return nil
+}
+
+// Remove removes a custom template by name
+func (l *TemplateLibrary) Remove(name string) bool {
+	for i := range l.Templates {
+		if l.Templates[i].Name == name {
+			l.Templates = append(l.Templates[:i], l.Templates[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// AppTemplates returns the built-in templates merged with the user's, with
+// bootstrap paths resolved. When the templates file can't be used the
+// built-in templates are returned along with the error.
+func AppTemplates() ([]db.AppTemplate, error) {
+	builtin := db.DefaultTemplates()
+	lib, err := LoadTemplateLibrary()
+	if err != nil {
+		return builtin, err
+	}
+	if err := lib.Validate(); err != nil {
+		return builtin, fmt.Errorf("templates.yaml: %w", err)
+	}
+
+	custom := make([]db.AppTemplate, len(lib.Templates))
+	for i, t := range lib.Templates {
+		custom[i] = resolveTemplate(t)
+	}
+	return db.MergeTemplates(builtin, custom), nil
+}
+
+// GetAppTemplate returns a built-in or custom template by name
+func GetAppTemplate(name string) (*db.AppTemplate, error) {
+	templates, err := AppTemplates()
+	if err != nil {
+		return nil, err
+	}
+	return db.FindTemplate(templates, name)
+}
+
+// resolveTemplate returns t with its bootstrap script path made absolute.
+// Relative paths are relative to the config dir and ~ is the home dir.
+func resolveTemplate(t db.AppTemplate) db.AppTemplate {
+	path := t.Bootstrap
+	if path == "" {
+		return t
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	if !filepath.IsAbs(path) {
+		if dir, err := ConfigDir(); err == nil {
+			path = filepath.Join(dir, path)
+		}
+	}
+	t.Bootstrap = path
+	return t
+}
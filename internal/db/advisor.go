@@ -0,0 +1,588 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+)
+
+// Advice priorities
+const (
+	AdviceHigh   = "high"
+	AdviceMedium = "medium"
+	AdviceLow    = "low"
+)
+
+// AdvicePriorities lists the priorities, most urgent first
+var AdvicePriorities = []string{AdviceHigh, AdviceMedium, AdviceLow}
+
+// Advisor checks
+const (
+	AdviceNoPrimaryKey    = "no-primary-key"
+	AdviceDuplicateIndex  = "duplicate-index"
+	AdviceUnusedIndex     = "unused-index"
+	AdviceCharset         = "charset"
+	AdviceStaleStatistics = "stale-statistics"
+	AdviceUnpartitioned   = "unpartitioned"
+)
+
+// AdviceChecks lists the advisor's checks in the order they run
+var AdviceChecks = []string{
+	AdviceNoPrimaryKey, AdviceDuplicateIndex, AdviceUnusedIndex,
+	AdviceCharset, AdviceStaleStatistics, AdviceUnpartitioned,
+}
+
+const (
+	advisorMinRows     = 1000               // Statistics of smaller tables hardly matter
+	advisorStaleShare  = 0.2                // Share of rows changed since the last analyze
+	advisorMinUsageAge = 7 * 24 * time.Hour // Index usage counted for less is no evidence
+	advisorHugeRows    = 100_000_000        // Tables worth partitioning, by rows...
+	advisorHugeBytes   = int64(50) << 30    // ...or by size
+)
+
+// legacyCharsets are MariaDB charsets that can't store all of Unicode
+var legacyCharsets = map[string]string{
+	"latin1":  "only stores Western European characters",
+	"utf8mb3": "can't store 4-byte characters such as emoji",
+}
+
+// Advice is one issue the advisor found and how to fix it
+type Advice struct {
+	Priority string `json:"priority"`
+	Check    string `json:"check"`
+	Table    string `json:"table,omitempty"`  // Empty for the database itself
+	Object   string `json:"object,omitempty"` // Index or columns within the table
+	Problem  string `json:"problem"`
+	Fix      string `json:"fix"`
+	Manual   bool   `json:"manual,omitempty"` // Fix is a template with <placeholders>, not to be run as is
+}
+
+// Subject names what the advice is about
+func (a Advice) Subject() string {
+	switch {
+	case a.Table == "":
+		return "database"
+	case a.Object != "":
+		return a.Table + " " + a.Object
+	}
+	return a.Table
+}
+
+// AdvisorReport is what the advisor found in a database
+type AdvisorReport struct {
+	Database string    `json:"database"`
+	Time     time.Time `json:"time"`
+	Advice   []Advice  `json:"advice"`
+	Skipped  []string  `json:"skipped,omitempty"` // Checks that couldn't run, and why
+}
+
+// skip notes a check that couldn't run
+func (r *AdvisorReport) skip(check string, err error) {
+	logging.Warn("Advisor check %s skipped: %v", check, err)
+	r.Skipped = append(r.Skipped, fmt.Sprintf("%s: %v", check, err))
+}
+
+// Count returns how much advice has a priority
+func (r *AdvisorReport) Count(priority string) int {
+	n := 0
+	for _, a := range r.Advice {
+		if a.Priority == priority {
+			n++
+		}
+	}
+	return n
+}
+
+// advisorTable is a table as the advisor sees it
+type advisorTable struct {
+	name        string
+	rows, bytes int64
+	primary     bool
+	partitioned bool
+}
+
+// Advise scans the current database for tables without a primary key,
+// duplicate and unused indexes, mismatched or legacy charsets, stale
+// statistics and huge unpartitioned tables, and returns what to fix, most
+// urgent first. Without checks every check runs. A check the server or the
+// user's privileges don't allow is skipped and noted in the report.
+func (c *Connection) Advise(checks ...string) (*AdvisorReport, error) {
+	for _, check := range checks {
+		if !slices.Contains(AdviceChecks, check) {
+			return nil, fmt.Errorf("unknown check '%s' (use %s)", check, strings.Join(AdviceChecks, ", "))
+		}
+	}
+	run := func(check string) bool {
+		return len(checks) == 0 || slices.Contains(checks, check)
+	}
+
+	report := &AdvisorReport{Database: c.Config.Database, Time: time.Now()}
+	tables, err := c.advisorTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", c.Config.Database, err)
+	}
+
+	if run(AdviceNoPrimaryKey) {
+		c.adviseNoPrimaryKey(report, tables)
+	}
+	if run(AdviceDuplicateIndex) || run(AdviceUnusedIndex) {
+		indexes, err := c.ListIndexes("")
+		if err != nil {
+			report.skip(AdviceDuplicateIndex, err)
+		} else {
+			duplicates := c.duplicateIndexes(indexes)
+			if run(AdviceDuplicateIndex) {
+				report.Advice = append(report.Advice, duplicates...)
+			}
+			if run(AdviceUnusedIndex) {
+				c.adviseUnusedIndexes(report, indexes, duplicates)
+			}
+		}
+	}
+	if run(AdviceCharset) {
+		c.adviseCharsets(report)
+	}
+	if run(AdviceStaleStatistics) {
+		c.adviseStaleStatistics(report)
+	}
+	if run(AdviceUnpartitioned) {
+		c.adviseUnpartitioned(report, tables)
+	}
+
+	sort.SliceStable(report.Advice, func(i, j int) bool {
+		a, b := report.Advice[i], report.Advice[j]
+		if a.Priority != b.Priority {
+			return slices.Index(AdvicePriorities, a.Priority) < slices.Index(AdvicePriorities, b.Priority)
+		}
+		if a.Check != b.Check {
+			return slices.Index(AdviceChecks, a.Check) < slices.Index(AdviceChecks, b.Check)
+		}
+		return a.Subject() < b.Subject()
+	})
+	return report, nil
+}
+
+// ApplyAdvice runs the fix of a piece of advice. Fixes that are templates
+// are refused.
+func (c *Connection) ApplyAdvice(a Advice) error {
+	if a.Manual || a.Fix == "" {
+		return fmt.Errorf("the fix for %s has to be adapted by hand", a.Subject())
+	}
+	logging.Info("Applying advisor fix for %s: %s", a.Subject(), a.Fix)
+
+	var err error
+	if a.Check == AdviceStaleStatistics {
+		// MariaDB's ANALYZE TABLE reports failures as rows
+		var rows *sql.Rows
+		if rows, err = c.DB.Query(a.Fix); err == nil {
+			_, err = c.tableCommandMessages(rows)
+		}
+	} else {
+		_, err = c.DB.Exec(a.Fix)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fix %s: %w", a.Subject(), err)
+	}
+	return nil
+}
+
+func (c *Connection) advisorTables() ([]advisorTable, error) {
+	rows, err := c.DB.Query(c.Driver.AdvisorTablesQuery())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []advisorTable
+	for rows.Next() {
+		var t advisorTable
+		if err := rows.Scan(&t.name, &t.rows, &t.bytes, &t.primary, &t.partitioned); err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+func (c *Connection) adviseNoPrimaryKey(report *AdvisorReport, tables []advisorTable) {
+	problem := "No primary key: replicas scan the whole table for every changed row, Galera can't " +
+		"reliably replicate its deletes, and its rows can't be edited in the browser"
+	if isPostgresType(c.Config.Type) {
+		problem = "No primary key: logical replication can't publish its updates and deletes, " +
+			"and its rows can't be edited in the browser"
+	}
+
+	for _, t := range tables {
+		if t.primary {
+			continue
+		}
+		report.Advice = append(report.Advice, Advice{
+			Priority: AdviceHigh,
+			Check:    AdviceNoPrimaryKey,
+			Table:    t.name,
+			Problem:  problem,
+			Fix:      c.Driver.AddPrimaryKeyQuery(t.name, c.surrogateKeyName(t.name)),
+		})
+	}
+}
+
+// surrogateKeyName picks a name for a new key column that the table
+// doesn't use yet
+func (c *Connection) surrogateKeyName(table string) string {
+	columns, err := c.DescribeTable(table)
+	if err != nil {
+		return "id"
+	}
+	taken := func(name string) bool {
+		return slices.ContainsFunc(columns, func(col Column) bool { return strings.EqualFold(col.Field, name) })
+	}
+	for _, name := range []string{"id", table + "_id", "row_id"} {
+		if !taken(name) {
+			return name
+		}
+	}
+	return "ysm_row_id"
+}
+
+// duplicateIndexes finds btree indexes whose columns lead another index
+// of the same table, which can serve every query they serve. Of identical
+// indexes the primary key, then a unique one, then the first is kept.
+func (c *Connection) duplicateIndexes(indexes []Index) []Advice {
+	var advice []Advice
+	for i, a := range indexes {
+		if a.Primary || !plainBtree(a) {
+			continue
+		}
+		for j, b := range indexes {
+			if i == j || a.Table != b.Table || !plainBtree(b) || !columnsLead(a.Columns, b.Columns) {
+				continue
+			}
+			if len(a.Columns) == len(b.Columns) {
+				keep := !b.Primary && (a.Unique && !b.Unique || a.Unique == b.Unique && i < j)
+				if keep {
+					continue
+				}
+			} else if a.Unique {
+				// It enforces uniqueness of fewer columns than b does
+				continue
+			}
+
+			advice = append(advice, Advice{
+				Priority: AdviceMedium,
+				Check:    AdviceDuplicateIndex,
+				Table:    a.Table,
+				Object:   a.Name,
+				Problem: fmt.Sprintf("Index %s (%s) is covered by %s (%s): it slows down writes and takes space without speeding up any query",
+					a.Name, strings.Join(a.Columns, ", "), b.Name, strings.Join(b.Columns, ", ")),
+				Fix: c.Driver.DropIndexQuery(a.Table, a.Name),
+			})
+			break
+		}
+	}
+	return advice
+}
+
+// plainBtree reports whether an index is a btree on columns only
+func plainBtree(idx Index) bool {
+	return strings.EqualFold(idx.Type, "btree") && !slices.Contains(idx.Columns, "(expression)")
+}
+
+// columnsLead reports whether lead are the first columns of columns
+func columnsLead(lead, columns []string) bool {
+	return len(lead) > 0 && len(lead) <= len(columns) && slices.Equal(lead, columns[:len(lead)])
+}
+
+// adviseUnusedIndexes flags indexes no query has used since the server
+// started counting, leaving out those that enforce a constraint and those
+// already reported as duplicates
+func (c *Connection) adviseUnusedIndexes(report *AdvisorReport, indexes []Index, duplicates []Advice) {
+	var since time.Time
+	if err := c.DB.QueryRow(c.Driver.IndexUsageSinceQuery()).Scan(&since); err != nil {
+		report.skip(AdviceUnusedIndex, err)
+		return
+	}
+	age := time.Since(since)
+	if age < advisorMinUsageAge {
+		report.skip(AdviceUnusedIndex, fmt.Errorf("index usage has only been counted for %s", FormatUptime(age)))
+		return
+	}
+
+	rows, err := c.DB.Query(c.Driver.IndexUsageQuery())
+	if err != nil {
+		report.skip(AdviceUnusedIndex, err)
+		return
+	}
+	defer rows.Close()
+	scans := make(map[string]int64)
+	for rows.Next() {
+		var table, index string
+		var n int64
+		if err := rows.Scan(&table, &index, &n); err != nil {
+			report.skip(AdviceUnusedIndex, err)
+			return
+		}
+		scans[table+"."+index] = n
+	}
+	if err := rows.Err(); err != nil {
+		report.skip(AdviceUnusedIndex, err)
+		return
+	}
+	if len(scans) == 0 && len(indexes) > 0 {
+		report.skip(AdviceUnusedIndex, fmt.Errorf("the server doesn't count index usage (is performance_schema on?)"))
+		return
+	}
+
+	duplicate := make(map[string]bool)
+	for _, a := range duplicates {
+		duplicate[a.Table+"."+a.Object] = true
+	}
+	foreignKeys := make(map[string][]ForeignKey)
+	for _, idx := range indexes {
+		key := idx.Table + "." + idx.Name
+		if n, ok := scans[key]; !ok || n > 0 || idx.Primary || idx.Unique || duplicate[key] {
+			continue
+		}
+
+		// MariaDB needs an index on the columns of every foreign key
+		if !isPostgresType(c.Config.Type) {
+			fks, ok := foreignKeys[idx.Table]
+			if !ok {
+				fks, _ = c.ListForeignKeys(idx.Table)
+				foreignKeys[idx.Table] = fks
+			}
+			if backsForeignKey(idx, indexes, fks) {
+				continue
+			}
+		}
+
+		report.Advice = append(report.Advice, Advice{
+			Priority: AdviceLow,
+			Check:    AdviceUnusedIndex,
+			Table:    idx.Table,
+			Object:   idx.Name,
+			Problem: fmt.Sprintf("Index %s (%s) wasn't used once in the last %s, but every write to the table still updates it",
+				idx.Name, strings.Join(idx.Columns, ", "), FormatUptime(age)),
+			Fix: c.Driver.DropIndexQuery(idx.Table, idx.Name),
+		})
+	}
+}
+
+// backsForeignKey reports whether idx is the only index a foreign key of
+// its table can use
+func backsForeignKey(idx Index, indexes []Index, fks []ForeignKey) bool {
+	for _, fk := range fks {
+		if !columnsLead(fk.Columns, idx.Columns) {
+			continue
+		}
+		covered := slices.ContainsFunc(indexes, func(other Index) bool {
+			return other.Table == idx.Table && other.Name != idx.Name && columnsLead(fk.Columns, other.Columns)
+		})
+		if !covered {
+			return true
+		}
+	}
+	return false
+}
+
+// tableCharsets is the charset of a table and of those columns that differ
+type tableCharsets struct {
+	name      string
+	charset   string
+	container string // The database's charset
+	columns   []string
+}
+
+// adviseCharsets flags a database or tables in a charset that differs from
+// the one they're in or that can't store all of Unicode, and columns in a
+// different charset than their table
+func (c *Connection) adviseCharsets(report *AdvisorReport) {
+	rows, err := c.DB.Query(c.Driver.CharsetsInUseQuery())
+	if err != nil {
+		report.skip(AdviceCharset, err)
+		return
+	}
+	defer rows.Close()
+
+	var tables []*tableCharsets
+	byName := make(map[string]*tableCharsets)
+	for rows.Next() {
+		var table, column, charset, container string
+		if err := rows.Scan(&table, &column, &charset, &container); err != nil {
+			report.skip(AdviceCharset, err)
+			return
+		}
+
+		switch {
+		case table == "":
+			c.adviseDatabaseCharset(report, charset, container)
+		case column == "":
+			t := &tableCharsets{name: table, charset: c.charsetName(charset), container: c.charsetName(container)}
+			tables = append(tables, t)
+			byName[table] = t
+		default:
+			// ascii is a common deliberate choice for codes and hashes
+			charset := c.charsetName(charset)
+			if t, ok := byName[table]; ok && charset != t.charset && charset != "ascii" {
+				t.columns = append(t.columns, column)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		report.skip(AdviceCharset, err)
+		return
+	}
+
+	for _, t := range tables {
+		var problems []string
+		priority := AdviceLow
+		if t.charset != t.container {
+			problems = append(problems, fmt.Sprintf("Table charset %s differs from the database's %s", t.charset, t.container))
+			priority = AdviceMedium
+		}
+		if len(t.columns) > 0 {
+			problems = append(problems, fmt.Sprintf("Columns %s aren't %s like their table, so comparing or joining them converts "+
+				"every value and can't use an index", strings.Join(t.columns, ", "), t.charset))
+			priority = AdviceMedium
+		}
+		if reason, ok := legacyCharsets[t.charset]; ok {
+			problems = append(problems, fmt.Sprintf("Charset %s %s", t.charset, reason))
+		}
+		if len(problems) == 0 {
+			continue
+		}
+
+		target := t.container
+		if _, ok := legacyCharsets[target]; ok {
+			target = "utf8mb4"
+		}
+		report.Advice = append(report.Advice, Advice{
+			Priority: priority,
+			Check:    AdviceCharset,
+			Table:    t.name,
+			Object:   strings.Join(t.columns, ", "),
+			Problem:  strings.Join(problems, "; "),
+			Fix:      c.Driver.CharsetFixQuery(c.Config.Database, t.name, target),
+		})
+	}
+}
+
+// adviseDatabaseCharset flags a database whose encoding isn't the expected
+// one (PostgreSQL) or whose default charset is a legacy one (MariaDB)
+func (c *Connection) adviseDatabaseCharset(report *AdvisorReport, charset, expected string) {
+	if !strings.EqualFold(charset, expected) {
+		report.Advice = append(report.Advice, Advice{
+			Priority: AdviceMedium,
+			Check:    AdviceCharset,
+			Problem: fmt.Sprintf("Database encoding is %s, not %s: text outside %s can't be stored, and the encoding "+
+				"can't be changed in place; restore a dump into a new database", charset, expected, charset),
+			Fix:    c.Driver.CharsetFixQuery(c.Config.Database, "", expected),
+			Manual: true,
+		})
+		return
+	}
+	if reason, ok := legacyCharsets[c.charsetName(charset)]; ok {
+		report.Advice = append(report.Advice, Advice{
+			Priority: AdviceLow,
+			Check:    AdviceCharset,
+			Problem:  fmt.Sprintf("Default charset %s %s, and new tables get it too", c.charsetName(charset), reason),
+			Fix:      c.Driver.CharsetFixQuery(c.Config.Database, "", "utf8mb4"),
+		})
+	}
+}
+
+// charsetName normalizes a charset name; MariaDB's utf8 is utf8mb3
+func (c *Connection) charsetName(charset string) string {
+	charset = strings.ToLower(charset)
+	if charset == "utf8" && !isPostgresType(c.Config.Type) {
+		return "utf8mb3"
+	}
+	return charset
+}
+
+// adviseStaleStatistics flags tables the planner has no statistics about
+// or whose statistics predate many of their rows
+func (c *Connection) adviseStaleStatistics(report *AdvisorReport) {
+	rows, err := c.DB.Query(c.Driver.StaleStatisticsQuery())
+	if err != nil {
+		report.skip(AdviceStaleStatistics, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table string
+		var count int64
+		var analyzed sql.NullTime
+		var changed sql.NullInt64
+		if err := rows.Scan(&table, &count, &analyzed, &changed); err != nil {
+			report.skip(AdviceStaleStatistics, err)
+			return
+		}
+		if count < advisorMinRows {
+			continue
+		}
+
+		a := Advice{
+			Check: AdviceStaleStatistics,
+			Table: table,
+			Fix:   c.Driver.MaintenanceQuery(MaintenanceAnalyze, false, table),
+		}
+		switch {
+		case !analyzed.Valid:
+			a.Priority = AdviceHigh
+			a.Problem = fmt.Sprintf("Never analyzed: the planner guesses at its ~%d rows and may pick full scans "+
+				"or bad join orders", count)
+		case changed.Valid && float64(changed.Int64) > advisorStaleShare*float64(count):
+			a.Priority = AdviceMedium
+			a.Problem = fmt.Sprintf("%d of its ~%d rows changed since it was last analyzed %s ago",
+				changed.Int64, count, FormatUptime(time.Since(analyzed.Time)))
+		default:
+			continue
+		}
+		report.Advice = append(report.Advice, a)
+	}
+	if err := rows.Err(); err != nil {
+		report.skip(AdviceStaleStatistics, err)
+	}
+}
+
+// adviseUnpartitioned flags huge tables that aren't partitioned
+func (c *Connection) adviseUnpartitioned(report *AdvisorReport, tables []advisorTable) {
+	for _, t := range tables {
+		if t.partitioned || t.rows < advisorHugeRows && t.bytes < advisorHugeBytes {
+			continue
+		}
+		report.Advice = append(report.Advice, Advice{
+			Priority: AdviceLow,
+			Check:    AdviceUnpartitioned,
+			Table:    t.name,
+			Problem: fmt.Sprintf("~%d rows in %s without partitioning: purging old rows, rebuilds and backups "+
+				"all have to work through the whole table", t.rows, FormatSize(t.bytes)),
+			Fix:    c.Driver.PartitionTemplate(t.name),
+			Manual: true,
+		})
+	}
+}
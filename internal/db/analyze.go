@@ -0,0 +1,101 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+)
+
+// affectedTableRe finds the table a data-loading statement writes to
+var affectedTableRe = regexp.MustCompile(`(?is)^(?:INSERT\s+(?:IGNORE\s+)?INTO|REPLACE\s+INTO|COPY|CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?)\s*([^\s(,;]+)`)
+
+// affectedTables collects the tables an import writes to, in first-seen order
+type affectedTables struct {
+	seen  map[string]bool
+	names [][]string // Identifier parts, e.g. schema and table
+}
+
+func newAffectedTables() *affectedTables {
+	return &affectedTables{seen: make(map[string]bool)}
+}
+
+// note records the table a statement writes to, if any
+func (a *affectedTables) note(stmt string) {
+	// The table name is near the start; INSERTs can be megabytes long
+	m := affectedTableRe.FindStringSubmatch(stmt[:min(len(stmt), 512)])
+	if m == nil {
+		return
+	}
+	parts := strings.Split(m[1], ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(part, "`\"")
+	}
+	key := strings.Join(parts, ".")
+	if key == largeObjectMapTable {
+		return
+	}
+	if !a.seen[key] {
+		a.seen[key] = true
+		a.names = append(a.names, parts)
+	}
+}
+
+// analyzeTables refreshes the optimizer statistics of tables. Failures are
+// logged and skipped, since the data itself is already in place; the number
+// of tables analyzed is returned.
+func (c *Connection) analyzeTables(tables [][]string, onProgress func(table string, tableNum, totalTables int)) int {
+	analyzed := 0
+	for i, parts := range tables {
+		name := strings.Join(parts, ".")
+		if onProgress != nil {
+			onProgress(name, i+1, len(tables))
+		}
+		if _, err := c.DB.Exec(c.Driver.AnalyzeTableQuery(parts...)); err != nil {
+			logging.Warn("Failed to analyze %s: %v", name, err)
+			continue
+		}
+		analyzed++
+	}
+	return analyzed
+}
+
+// analyzeDatabase refreshes the statistics of every table in a database
+func (c *Connection) analyzeDatabase(database string, onProgress func(table string, tableNum, totalTables int)) (int, error) {
+	conn := c
+	if database != "" {
+		var err error
+		if conn, err = c.openDatabase(database); err != nil {
+			return 0, err
+		}
+		defer conn.Close()
+	}
+
+	tables, err := conn.ListTables()
+	if err != nil {
+		return 0, err
+	}
+	names := make([][]string, len(tables))
+	for i, t := range tables {
+		names[i] = []string{t.Name}
+	}
+	return conn.analyzeTables(names, onProgress), nil
+}
@@ -0,0 +1,150 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// AppConnection is what an application needs to reach the database the
+// setup wizard made for it
+type AppConnection struct {
+	Type     DatabaseType
+	Host     string
+	Port     int
+	Database string
+	User     string
+	Password string
+	Charset  string // MariaDB only
+}
+
+// AppSnippet is connection configuration for one kind of application,
+// ready to paste
+type AppSnippet struct {
+	Key  string // Short name for the command line, e.g. "env"
+	Name string // e.g. ".env", "Django"
+	Text string
+}
+
+// AppSnippets returns connection snippets for common application stacks:
+// a .env file, a DATABASE_URL, PHP (PDO), Django and Rails
+func AppSnippets(a AppConnection) []AppSnippet {
+	if a.Host == "" {
+		a.Host = "localhost"
+	}
+	if a.Port == 0 {
+		a.Port = DefaultPort(a.Type)
+	}
+	postgres := a.Type == DatabaseTypePostgres
+	port := strconv.Itoa(a.Port)
+
+	scheme, envDriver, django, rails := "mysql", "mysql", "django.db.backends.mysql", "mysql2"
+	if postgres {
+		scheme, envDriver, django, rails = "postgres", "pgsql", "django.db.backends.postgresql", "postgresql"
+	}
+	databaseURL := (&url.URL{
+		Scheme: scheme,
+		User:   url.UserPassword(a.User, a.Password),
+		Host:   net.JoinHostPort(a.Host, port),
+		Path:   "/" + a.Database,
+	}).String()
+
+	var env strings.Builder
+	fmt.Fprintf(&env, "DB_CONNECTION=%s\n", envDriver)
+	fmt.Fprintf(&env, "DB_HOST=%s\n", envQuote(a.Host))
+	fmt.Fprintf(&env, "DB_PORT=%s\n", port)
+	fmt.Fprintf(&env, "DB_DATABASE=%s\n", envQuote(a.Database))
+	fmt.Fprintf(&env, "DB_USERNAME=%s\n", envQuote(a.User))
+	fmt.Fprintf(&env, "DB_PASSWORD=%s\n", envQuote(a.Password))
+	fmt.Fprintf(&env, "DATABASE_URL=%s\n", envQuote(databaseURL))
+
+	dsn := fmt.Sprintf("pgsql:host=%s;port=%s;dbname=%s", a.Host, port, a.Database)
+	if !postgres {
+		dsn = fmt.Sprintf("mysql:host=%s;port=%s;dbname=%s", a.Host, port, a.Database)
+		if a.Charset != "" {
+			dsn += ";charset=" + a.Charset
+		}
+	}
+	php := fmt.Sprintf("$pdo = new PDO(%s, %s, %s, [\n    PDO::ATTR_ERRMODE => PDO::ERRMODE_EXCEPTION,\n]);\n",
+		phpQuote(dsn), phpQuote(a.User), phpQuote(a.Password))
+
+	// Python and YAML double-quoted strings take Go's escapes
+	var py strings.Builder
+	py.WriteString("DATABASES = {\n    \"default\": {\n")
+	fmt.Fprintf(&py, "        \"ENGINE\": %q,\n", django)
+	fmt.Fprintf(&py, "        \"NAME\": %q,\n", a.Database)
+	fmt.Fprintf(&py, "        \"USER\": %q,\n", a.User)
+	fmt.Fprintf(&py, "        \"PASSWORD\": %q,\n", a.Password)
+	fmt.Fprintf(&py, "        \"HOST\": %q,\n", a.Host)
+	fmt.Fprintf(&py, "        \"PORT\": %q,\n", port)
+	py.WriteString("    }\n}\n")
+
+	var yml strings.Builder
+	yml.WriteString("production:\n")
+	fmt.Fprintf(&yml, "  adapter: %s\n", rails)
+	if postgres {
+		yml.WriteString("  encoding: unicode\n")
+	} else if a.Charset != "" {
+		fmt.Fprintf(&yml, "  encoding: %s\n", a.Charset)
+	}
+	fmt.Fprintf(&yml, "  host: %q\n", a.Host)
+	fmt.Fprintf(&yml, "  port: %s\n", port)
+	fmt.Fprintf(&yml, "  database: %q\n", a.Database)
+	fmt.Fprintf(&yml, "  username: %q\n", a.User)
+	fmt.Fprintf(&yml, "  password: %q\n", a.Password)
+
+	return []AppSnippet{
+		{Key: "env", Name: ".env", Text: env.String()},
+		{Key: "url", Name: "DATABASE_URL", Text: databaseURL + "\n"},
+		{Key: "php", Name: "PHP (PDO)", Text: php},
+		{Key: "django", Name: "Django (settings.py)", Text: py.String()},
+		{Key: "rails", Name: "Rails (config/database.yml)", Text: yml.String()},
+	}
+}
+
+// FindAppSnippet returns the snippet with the given key
+func FindAppSnippet(snippets []AppSnippet, key string) (*AppSnippet, error) {
+	var keys []string
+	for i := range snippets {
+		if strings.EqualFold(snippets[i].Key, key) {
+			return &snippets[i], nil
+		}
+		keys = append(keys, snippets[i].Key)
+	}
+	return nil, fmt.Errorf("unknown snippet: %s (use: %s)", key, strings.Join(keys, ", "))
+}
+
+// envQuote double-quotes a .env value when it has characters dotenv
+// parsers would otherwise cut or expand
+func envQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'#$\\=`\n") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`", "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// phpQuote writes s as a single-quoted PHP string
+func phpQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
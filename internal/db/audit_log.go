@@ -0,0 +1,186 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+)
+
+// Destructive actions recorded in the audit log
+const (
+	AuditDropDatabase = "drop_database"
+	AuditDropUser     = "drop_user"
+	AuditRevoke       = "revoke"
+	AuditRestore      = "restore"
+	AuditImport       = "import"
+)
+
+// AuditActions lists every action the audit log records
+var AuditActions = []string{AuditDropDatabase, AuditDropUser, AuditRevoke, AuditRestore, AuditImport}
+
+// AuditEntry is a destructive action YSM performed, or tried to
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	OSUser  string    `json:"os_user"` // Who ran YSM
+	Machine string    `json:"machine"` // Where YSM ran
+	Server  string    `json:"server"`  // See ServerKey
+	DBUser  string    `json:"db_user"` // Who YSM connected as
+	Action  string    `json:"action"`
+	Target  string    `json:"target"` // Database, user or file acted on
+	Detail  string    `json:"detail,omitempty"`
+	Error   string    `json:"error,omitempty"` // Set when the action failed
+}
+
+// Failed reports whether the action failed
+func (e AuditEntry) Failed() bool {
+	return e.Error != ""
+}
+
+// auditMu keeps concurrent appends from interleaving
+var auditMu sync.Mutex
+
+// GetAuditLogPath returns the path to the audit log, next to the backups
+// directory
+func GetAuditLogPath() (string, error) {
+	backupsDir, err := GetBackupsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(backupsDir), "audit.jsonl"), nil
+}
+
+// LoadAuditLog reads every entry of the audit log, oldest first. Lines that
+// don't parse, e.g. one cut short by a crash, are skipped.
+func LoadAuditLog() ([]AuditEntry, error) {
+	path, err := GetAuditLogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []AuditEntry{}, nil
+		}
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+
+	entries := []AuditEntry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// AppendAuditEntry adds an entry to the end of the audit log. The file is
+// only ever appended to, never rewritten.
+func AppendAuditEntry(entry AuditEntry) error {
+	path, err := GetAuditLogPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// audit records a destructive action on the connection's server. Failing
+// to record it is only a warning; the action itself already ran.
+func (c *Connection) audit(action, target, detail string, err error) {
+	entry := AuditEntry{
+		Time:   time.Now(),
+		OSUser: osUser(),
+		Server: c.ServerKey(),
+		DBUser: c.Config.User,
+		Action: action,
+		Target: target,
+		Detail: detail,
+	}
+	entry.Machine, _ = os.Hostname()
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if err := AppendAuditEntry(entry); err != nil {
+		logging.Warn("Failed to record %s of %s in the audit log: %v", action, target, err)
+	}
+}
+
+// osUser names the user YSM runs as
+func osUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return cmp.Or(os.Getenv("USER"), os.Getenv("USERNAME"), "unknown")
+}
+
+// auditAccount formats a user for the audit log the way MariaDB names them
+func auditAccount(username, host string) string {
+	return fmt.Sprintf("'%s'@'%s'", username, host)
+}
+
+// auditGrantTarget words what privileges were revoked on
+func auditGrantTarget(database, table string) string {
+	if database == "" || database == "*" {
+		return "*.*"
+	}
+	return database + "." + cmp.Or(table, "*")
+}
+
+// auditList joins names for an entry's detail
+func auditList(names []string) string {
+	if len(names) == 0 {
+		return "all"
+	}
+	return strings.Join(names, ", ")
+}
@@ -19,12 +19,17 @@
 package db
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -35,16 +40,18 @@ import (
 
 // BackupMetadata contains information about a backup
 type BackupMetadata struct {
-	ID            string          `json:"id"`
-	Timestamp     time.Time       `json:"timestamp"`
-	Databases     []string        `json:"databases"`
-	Files         []BackupFile    `json:"files"`
-	TotalSize     int64           `json:"total_size"`
-	Compression   CompressionType `json:"compression"`
-	ServerVersion string          `json:"server_version"`
-	ServerType    DatabaseType    `json:"server_type"`
-	Profile       string          `json:"profile,omitempty"`
-	Description   string          `json:"description,omitempty"`
+	ID            string              `json:"id"`
+	Timestamp     time.Time           `json:"timestamp"`
+	Databases     []string            `json:"databases"`
+	Files         []BackupFile        `json:"files"`
+	TotalSize     int64               `json:"total_size"`
+	Compression   CompressionType     `json:"compression"`
+	ServerVersion string              `json:"server_version"`
+	ServerType    DatabaseType        `json:"server_type"`
+	Profile       string              `json:"profile,omitempty"`
+	Description   string              `json:"description,omitempty"`
+	Tables        map[string][]string `json:"tables,omitempty"`      // Databases only partly backed up, and their tables
+	VerifiedAt    time.Time           `json:"verified_at,omitempty"` // Last time every checksum matched
 }
 
 // BackupFile represents a single backup file
@@ -54,17 +61,24 @@ type BackupFile struct {
 	Size     int64  `json:"size"`
 	Tables   int    `json:"tables"`
 	Rows     int64  `json:"rows"`
+	SHA256   string `json:"sha256,omitempty"`
 }
 
 // BackupOptions configures backup creation
 type BackupOptions struct {
-	OutputDir     string          // Directory to store backups
-	Databases     []string        // Databases to backup (empty = all)
-	Compression   CompressionType // Compression type
-	Description   string          // Optional description
-	Profile       string          // Optional profile name
-	Parallel      int             // Number of parallel workers (0 = sequential, -1 = auto)
-	OnProgress    func(database string, dbNum, totalDBs int)
+	OutputDir        string              // Directory to store backups
+	Databases        []string            // Databases to backup (empty = all)
+	Tables           map[string][]string // Only these tables of a database (a database not listed = all its tables)
+	Compression      CompressionType     // Compression type
+	CompressionLevel int                 // 0 = the type's default
+	Description      string              // Optional description
+	Profile          string              // Optional profile name
+	Parallel         int                 // Number of parallel workers (0 = sequential, -1 = auto)
+	Context          context.Context     // Cancels the backup, removing what it wrote (nil = never)
+
+	// OnProgress is told the database being backed up and the rows and SQL
+	// bytes exported so far, across databases
+	OnProgress func(database string, dbNum, totalDBs int, rows, bytes int64)
 }
 
 // RestoreOptions configures backup restoration
@@ -76,7 +90,19 @@ type RestoreOptions struct {
 	DropExisting       bool              // Drop existing databases before restore
 	CreateIfNotExists  bool              // Create databases if they don't exist
 	DisableForeignKeys bool              // Disable FK checks during restore
-	OnProgress         func(database string, dbNum, totalDBs int, percent float64)
+	Analyze            bool              // Refresh optimizer statistics after each database
+	Scripts            OperationScripts  // SQL run on the target before and after the restore
+	Context            context.Context   // Cancels the restore; databases already restored are kept (nil = never)
+
+	// OnProgress is told the database being restored, how far into it the
+	// restore is, and the rows and bytes read so far across databases
+	OnProgress func(database string, dbNum, totalDBs int, percent float64, rows, bytes int64)
+
+	// PostgreSQL ownership and privileges, applied to each restored database
+	Owner         string       // Role that owns the database and its objects
+	ReassignOwned []string     // Only hand what these roles own in the restored database to Owner, instead of every object
+	GrantRole     string       // Role given Privileges on the restored objects, now and by default
+	Privileges    PrivilegeSet // Privilege set for GrantRole (default read)
 }
 
 // GetBackupsDir returns the default backups directory
@@ -116,23 +142,9 @@ func (c *Connection) CreateBackup(opts BackupOptions) (*BackupMetadata, error) {
 	logging.Debug("Output directory: %s", outputDir)
 
 	// Get list of databases to backup
-	databases := opts.Databases
-	if len(databases) == 0 {
-		dbList, err := c.ListDatabases()
-		if err != nil {
-			return nil, fmt.Errorf("failed to list databases: %w", err)
-		}
-		for _, db := range dbList {
-			// Skip system databases
-			if isSystemDatabase(db.Name, c.Config.Type) {
-				continue
-			}
-			databases = append(databases, db.Name)
-		}
-	}
-
-	if len(databases) == 0 {
-		return nil, fmt.Errorf("no databases to backup")
+	databases, err := c.backupDatabases(opts)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get server version
@@ -158,6 +170,7 @@ func (c *Connection) CreateBackup(opts BackupOptions) (*BackupMetadata, error) {
 		ServerType:    c.Config.Type,
 		Profile:       opts.Profile,
 		Description:   opts.Description,
+		Tables:        opts.Tables,
 	}
 
 	// Determine file extension
@@ -179,6 +192,7 @@ func (c *Connection) CreateBackup(opts BackupOptions) (*BackupMetadata, error) {
 	parallelWorkers = min(parallelWorkers, len(databases))
 
 	var totalSize int64
+	var throughput backupThroughput
 
 	if parallelWorkers > 1 {
 		// Parallel backup
@@ -191,67 +205,102 @@ func (c *Connection) CreateBackup(opts BackupOptions) (*BackupMetadata, error) {
 			err      error
 		}
 
-		resultsChan := make(chan backupResult, len(databases))
+		resultsChan := make(chan backupResult, parallelWorkers)
 		var wg sync.WaitGroup
-		sem := make(chan struct{}, parallelWorkers) // Semaphore for limiting concurrency
 		var completed atomic.Int64
+		var failed atomic.Bool
 
-		// Launch backup goroutines
-		for i, dbName := range databases {
-			wg.Add(1)
-			go func(idx int, db string) {
-				defer wg.Done()
-				sem <- struct{}{}        // Acquire semaphore
-				defer func() { <-sem }() // Release semaphore
+		backupOne := func(idx int, db string) backupResult {
+			filename := fmt.Sprintf("%s%s", db, ext)
+			filePath := filepath.Join(backupDir, filename)
 
-				filename := fmt.Sprintf("%s%s", db, ext)
-				filePath := filepath.Join(backupDir, filename)
+			exportOpts := ExportOptions{
+				FilePath:         filePath,
+				Database:         db,
+				Tables:           opts.Tables[db],
+				AddDropTable:     true,
+				Compression:      opts.Compression,
+				CompressionLevel: opts.CompressionLevel,
+				Context:          opts.Context,
+			}
+			if opts.OnProgress != nil {
+				exportOpts.OnProgress = throughput.exportProgress(func(rows, bytes int64) {
+					opts.OnProgress(db, min(int(completed.Load())+1, len(databases)), len(databases), rows, bytes)
+				})
+			}
 
-				exportOpts := ExportOptions{
-					FilePath:     filePath,
-					Database:     db,
-					AddDropTable: true,
-					Compression:  opts.Compression,
+			stats, err := c.ExportSQLWithStats(exportOpts)
+			if err != nil {
+				return backupResult{
+					index:    idx,
+					database: db,
+					err:      fmt.Errorf("failed to backup database %s: %w", db, err),
 				}
+			}
 
-				stats, err := c.ExportSQLWithStats(exportOpts)
-				if err != nil {
-					resultsChan <- backupResult{
-						index:    idx,
-						database: db,
-						err:      fmt.Errorf("failed to backup database %s: %w", db, err),
-					}
-					return
+			// Get file size
+			fileInfo, err := os.Stat(filePath)
+			if err != nil {
+				return backupResult{
+					index:    idx,
+					database: db,
+					err:      fmt.Errorf("failed to get file info for %s: %w", filename, err),
 				}
+			}
 
-				// Get file size
-				fileInfo, err := os.Stat(filePath)
-				if err != nil {
-					resultsChan <- backupResult{
-						index:    idx,
-						database: db,
-						err:      fmt.Errorf("failed to get file info for %s: %w", filename, err),
-					}
-					return
+			sum, err := fileSHA256(filePath)
+			if err != nil {
+				return backupResult{
+					index:    idx,
+					database: db,
+					err:      fmt.Errorf("failed to checksum %s: %w", filename, err),
 				}
+			}
 
-				comp := completed.Add(1)
-				if opts.OnProgress != nil {
-					opts.OnProgress(db, int(comp), len(databases))
-				}
+			comp := completed.Add(1)
+			if opts.OnProgress != nil {
+				opts.OnProgress(db, int(comp), len(databases), throughput.rows.Load(), throughput.bytes.Load())
+			}
 
-				resultsChan <- backupResult{
-					index:    idx,
-					database: db,
-					file: BackupFile{
-						Database: db,
-						Filename: filename,
-						Size:     fileInfo.Size(),
-						Tables:   stats.TablesExported,
-						Rows:     stats.RowsExported,
-					},
+			return backupResult{
+				index:    idx,
+				database: db,
+				file: BackupFile{
+					Database: db,
+					Filename: filename,
+					Size:     fileInfo.Size(),
+					Tables:   stats.TablesExported,
+					Rows:     stats.RowsExported,
+					SHA256:   sum,
+				},
+			}
+		}
+
+		// A fixed pool of workers takes databases in turn, so thousands of
+		// databases don't start thousands of goroutines. None are handed
+		// out once one fails, since the backup is removed anyway.
+		jobs := make(chan int)
+		go func() {
+			defer close(jobs)
+			for i := range databases {
+				if failed.Load() {
+					return
+				}
+				jobs <- i
+			}
+		}()
+		for range parallelWorkers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for idx := range jobs {
+					result := backupOne(idx, databases[idx])
+					if result.err != nil {
+						failed.Store(true)
+					}
+					resultsChan <- result
 				}
-			}(i, dbName)
+			}()
 		}
 
 		// Wait for all goroutines and close results channel
@@ -273,7 +322,7 @@ func (c *Connection) CreateBackup(opts BackupOptions) (*BackupMetadata, error) {
 		// Check for errors
 		if firstError != nil {
 			os.RemoveAll(backupDir)
-			return nil, firstError
+			return nil, backupFailed(opts, firstError)
 		}
 
 		// Build metadata from ordered results
@@ -288,24 +337,32 @@ func (c *Connection) CreateBackup(opts BackupOptions) (*BackupMetadata, error) {
 		// Sequential backup (original logic)
 		for i, dbName := range databases {
 			if opts.OnProgress != nil {
-				opts.OnProgress(dbName, i+1, len(databases))
+				opts.OnProgress(dbName, i+1, len(databases), throughput.rows.Load(), throughput.bytes.Load())
 			}
 
 			filename := fmt.Sprintf("%s%s", dbName, ext)
 			filePath := filepath.Join(backupDir, filename)
 
 			exportOpts := ExportOptions{
-				FilePath:     filePath,
-				Database:     dbName,
-				AddDropTable: true,
-				Compression:  opts.Compression,
+				FilePath:         filePath,
+				Database:         dbName,
+				Tables:           opts.Tables[dbName],
+				AddDropTable:     true,
+				Compression:      opts.Compression,
+				CompressionLevel: opts.CompressionLevel,
+				Context:          opts.Context,
+			}
+			if opts.OnProgress != nil {
+				exportOpts.OnProgress = throughput.exportProgress(func(rows, bytes int64) {
+					opts.OnProgress(dbName, i+1, len(databases), rows, bytes)
+				})
 			}
 
 			stats, err := c.ExportSQLWithStats(exportOpts)
 			if err != nil {
 				// Clean up partial backup on error
 				os.RemoveAll(backupDir)
-				return nil, fmt.Errorf("failed to backup database %s: %w", dbName, err)
+				return nil, backupFailed(opts, fmt.Errorf("failed to backup database %s: %w", dbName, err))
 			}
 
 			// Get file size
@@ -315,12 +372,19 @@ func (c *Connection) CreateBackup(opts BackupOptions) (*BackupMetadata, error) {
 				return nil, fmt.Errorf("failed to get file info for %s: %w", filename, err)
 			}
 
+			sum, err := fileSHA256(filePath)
+			if err != nil {
+				os.RemoveAll(backupDir)
+				return nil, fmt.Errorf("failed to checksum %s: %w", filename, err)
+			}
+
 			metadata.Files = append(metadata.Files, BackupFile{
 				Database: dbName,
 				Filename: filename,
 				Size:     fileInfo.Size(),
 				Tables:   stats.TablesExported,
 				Rows:     stats.RowsExported,
+				SHA256:   sum,
 			})
 
 			totalSize += fileInfo.Size()
@@ -345,38 +409,159 @@ func (c *Connection) CreateBackup(opts BackupOptions) (*BackupMetadata, error) {
 	return metadata, nil
 }
 
+// RestoreStats reports on a finished restore
+type RestoreStats struct {
+	Databases []string // Databases restored, as named on the target
+	Bytes     int64    // Size of the backup files restored
+	Duration  time.Duration
+	Scripts   []ScriptResult // Before and after scripts that ran
+}
+
+// backupDatabases returns the databases a backup covers: those asked for,
+// or every database but the system ones, listed a page at a time so
+// servers with thousands of databases aren't read in one result
+func (c *Connection) backupDatabases(opts BackupOptions) ([]string, error) {
+	databases := opts.Databases
+	if len(databases) == 0 {
+		err := c.EachDatabase(func(page []Database) error {
+			for _, db := range page {
+				databases = append(databases, db.Name)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list databases: %w", err)
+		}
+	}
+
+	if len(databases) == 0 {
+		return nil, fmt.Errorf("no databases to backup")
+	}
+	return databases, nil
+}
+
+// BackupRowEstimate guesses the rows a backup exports from the server's
+// table statistics, so progress can show an ETA. It is 0 when unknown.
+func (c *Connection) BackupRowEstimate(opts BackupOptions) int64 {
+	databases, err := c.backupDatabases(opts)
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, db := range databases {
+		total += c.ExportRowEstimate(ExportOptions{Database: db, Tables: opts.Tables[db]})
+	}
+	return total
+}
+
+// backupThroughput adds up the rows and bytes of the databases a backup or
+// restore moves, several of which can be under way at once
+type backupThroughput struct {
+	rows  atomic.Int64
+	bytes atomic.Int64
+}
+
+// adder returns a function taking one database's rows and bytes so far,
+// which adds what is new to the totals and reports them
+func (t *backupThroughput) adder(report func(rows, bytes int64)) func(rows, bytes int64) {
+	var lastRows, lastBytes int64
+	return func(rows, bytes int64) {
+		totalRows, totalBytes := t.rows.Add(rows-lastRows), t.bytes.Add(bytes-lastBytes)
+		lastRows, lastBytes = rows, bytes
+		report(totalRows, totalBytes)
+	}
+}
+
+// exportProgress returns an export OnProgress adding one database's rows
+// and bytes to the totals
+func (t *backupThroughput) exportProgress(report func(rows, bytes int64)) func(string, int, int, int64, int64) {
+	add := t.adder(report)
+	return func(_ string, _, _ int, rows, bytes int64) {
+		add(rows, bytes)
+	}
+}
+
+// backupFailed words the error of a backup whose directory was removed,
+// which for a cancelled one is the cancellation
+func backupFailed(opts BackupOptions, err error) error {
+	if opts.Context != nil && opts.Context.Err() != nil {
+		return fmt.Errorf("backup cancelled; the partial backup was removed")
+	}
+	return err
+}
+
 // RestoreBackup restores a backup
 func (c *Connection) RestoreBackup(opts RestoreOptions) error {
-	logging.Debug("Starting backup restore")
-	logging.Debug("BackupID: %s, BackupPath: %s", opts.BackupID, opts.BackupPath)
-
-	// Find backup
-	var backupDir string
-	var metadata *BackupMetadata
+	_, err := c.RestoreBackupWithStats(opts)
+	return err
+}
 
-	if opts.BackupID != "" {
-		backupsDir, err := GetBackupsDir()
-		if err != nil {
-			return err
+// RestoreBackupWithStats restores a backup and reports what ran. The stats
+// are returned even when it fails, so the scripts that ran can be shown.
+// Successful restores are added to the restore history.
+func (c *Connection) RestoreBackupWithStats(opts RestoreOptions) (*RestoreStats, error) {
+	start := time.Now()
+	stats := &RestoreStats{}
+	var restoreTime time.Duration
+	results, err := c.withScripts(opts.Scripts, func() error {
+		restoreStart := time.Now()
+		defer func() { restoreTime = time.Since(restoreStart) }()
+		return c.restoreBackup(opts, stats)
+	})
+	stats.Scripts = results
+	stats.Duration = time.Since(start)
+
+	backupID := opts.BackupID
+	if backupID == "" {
+		backupID = filepath.Base(opts.BackupPath)
+	}
+	c.audit(AuditRestore, backupID, restoreAuditDetail(opts, stats), err)
+
+	if err == nil {
+		record := RestoreRecord{
+			BackupID:  backupID,
+			Server:    c.ServerKey(),
+			Databases: stats.Databases,
+			Bytes:     stats.Bytes,
+			Duration:  restoreTime,
+			Time:      time.Now(),
+		}
+		if err := recordRestore(record); err != nil {
+			logging.Warn("Failed to record the restore: %v", err)
 		}
-		backupDir = filepath.Join(backupsDir, opts.BackupID)
-	} else if opts.BackupPath != "" {
-		backupDir = opts.BackupPath
-	} else {
-		return fmt.Errorf("backup ID or path is required")
 	}
-	logging.Debug("Backup directory: %s", backupDir)
+	return stats, err
+}
 
-	// Load metadata
-	metadataPath := filepath.Join(backupDir, "metadata.json")
-	metadataData, err := os.ReadFile(metadataPath)
+// restoreAuditDetail words what a restore replaced for the audit log
+func restoreAuditDetail(opts RestoreOptions, stats *RestoreStats) string {
+	databases := stats.Databases
+	if len(databases) == 0 {
+		databases = opts.Databases
+	}
+	detail := "Databases: " + auditList(databases)
+	if opts.DropExisting {
+		detail += ", dropping the existing ones first"
+	}
+	return detail
+}
+
+func (c *Connection) restoreBackup(opts RestoreOptions, stats *RestoreStats) error {
+	logging.Debug("Starting backup restore")
+	logging.Debug("BackupID: %s, BackupPath: %s", opts.BackupID, opts.BackupPath)
+
+	backupDir, metadata, err := loadRestoreMetadata(opts)
 	if err != nil {
-		return fmt.Errorf("failed to read backup metadata: %w", err)
+		return err
 	}
 
-	metadata = &BackupMetadata{}
-	if err := json.Unmarshal(metadataData, metadata); err != nil {
-		return fmt.Errorf("failed to parse backup metadata: %w", err)
+	// Dumps are server-specific, which matters when restoring to another server
+	if metadata.ServerType != "" && isPostgresType(metadata.ServerType) != isPostgresType(c.Config.Type) {
+		return fmt.Errorf("backup was taken from a %s server and can't be restored to %s", metadata.ServerType, c.Config.Type)
+	}
+
+	if err := c.validateRestoreOwnership(opts); err != nil {
+		return err
 	}
 
 	// Determine which databases to restore
@@ -385,7 +570,21 @@ func (c *Connection) RestoreBackup(opts RestoreOptions) error {
 		databasesToRestore = metadata.Databases
 	}
 
+	// Refuse before anything is dropped rather than halfway through
+	if opts.DropExisting {
+		for _, dbName := range databasesToRestore {
+			targetDB := dbName
+			if rename, ok := opts.RenameMap[dbName]; ok {
+				targetDB = rename
+			}
+			if err := c.CheckDatabaseProtected(targetDB, "drop"); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Restore each database
+	var throughput backupThroughput
 	for i, dbName := range databasesToRestore {
 		// Find corresponding backup file
 		var backupFile *BackupFile
@@ -407,7 +606,7 @@ func (c *Connection) RestoreBackup(opts RestoreOptions) error {
 		}
 
 		if opts.OnProgress != nil {
-			opts.OnProgress(dbName, i+1, len(databasesToRestore), 0)
+			opts.OnProgress(dbName, i+1, len(databasesToRestore), 0, throughput.rows.Load(), throughput.bytes.Load())
 		}
 
 		// Drop existing if requested
@@ -423,34 +622,80 @@ func (c *Connection) RestoreBackup(opts RestoreOptions) error {
 
 		// Import the backup
 		filePath := filepath.Join(backupDir, backupFile.Filename)
+		var percent float64
+		add := throughput.adder(func(rows, bytes int64) {
+			opts.OnProgress(dbName, i+1, len(databasesToRestore), percent, rows, bytes)
+		})
 		importOpts := ImportOptions{
 			FilePath:           filePath,
 			Database:           targetDB,
 			CreateDB:           opts.CreateIfNotExists,
 			DisableForeignKeys: opts.DisableForeignKeys,
-			OnProgress: func(bytesRead, totalBytes int64, _ int64) {
+			Analyze:            opts.Analyze,
+			Context:            opts.Context,
+			OnProgress: func(bytesRead, totalBytes, _, rowsInserted int64) {
 				if opts.OnProgress != nil && totalBytes > 0 {
-					percent := float64(bytesRead) / float64(totalBytes) * 100
-					opts.OnProgress(dbName, i+1, len(databasesToRestore), percent)
+					percent = float64(bytesRead) / float64(totalBytes) * 100
+					add(rowsInserted, bytesRead)
 				}
 			},
 		}
 
-		if err := c.ImportSQL(importOpts); err != nil {
+		if _, err := c.importSQLWithStats(importOpts); err != nil {
 			return fmt.Errorf("failed to restore database %s: %w", dbName, err)
 		}
+
+		if opts.Owner != "" || opts.GrantRole != "" {
+			if err := c.applyRestoreOwnership(targetDB, opts); err != nil {
+				return fmt.Errorf("failed to set ownership of %s: %w", targetDB, err)
+			}
+		}
+		stats.Databases = append(stats.Databases, targetDB)
+		stats.Bytes += backupFile.Size
 	}
 
 	return nil
 }
 
+// loadRestoreMetadata finds the backup a restore refers to and reads its metadata
+func loadRestoreMetadata(opts RestoreOptions) (string, *BackupMetadata, error) {
+	var backupDir string
+	if opts.BackupID != "" {
+		backupsDir, err := GetBackupsDir()
+		if err != nil {
+			return "", nil, err
+		}
+		backupDir = filepath.Join(backupsDir, opts.BackupID)
+	} else if opts.BackupPath != "" {
+		backupDir = opts.BackupPath
+	} else {
+		return "", nil, fmt.Errorf("backup ID or path is required")
+	}
+	logging.Debug("Backup directory: %s", backupDir)
+
+	metadataData, err := os.ReadFile(filepath.Join(backupDir, "metadata.json"))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read backup metadata: %w", err)
+	}
+
+	metadata := &BackupMetadata{}
+	if err := json.Unmarshal(metadataData, metadata); err != nil {
+		return "", nil, fmt.Errorf("failed to parse backup metadata: %w", err)
+	}
+	return backupDir, metadata, nil
+}
+
 // ListBackups returns all available backups
 func ListBackups() ([]BackupMetadata, error) {
 	backupsDir, err := GetBackupsDir()
 	if err != nil {
 		return nil, err
 	}
+	return readBackups(backupsDir)
+}
 
+// readBackups returns the backups in a directory, newest first
+func readBackups(backupsDir string) ([]BackupMetadata, error) {
 	entries, err := os.ReadDir(backupsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -527,6 +772,103 @@ func DeleteBackup(id string) error {
 	return nil
 }
 
+// PruneOptions selects the backups PruneBackups deletes
+type PruneOptions struct {
+	Keep      int           // Always keep this many of the newest matching backups
+	OlderThan time.Duration // Only delete backups older than this (0 = any age)
+	Database  string        // Only consider backups containing this database
+	Profile   string        // Only consider backups made with this profile
+	DryRun    bool          // Report what would be deleted without deleting
+}
+
+// PruneBackups deletes the matching backups beyond the newest opts.Keep that
+// are older than opts.OlderThan, and returns them
+func PruneBackups(opts PruneOptions) ([]BackupMetadata, error) {
+	if opts.Keep <= 0 && opts.OlderThan <= 0 {
+		return nil, fmt.Errorf("set how many backups to keep or a minimum age, or every backup would be deleted")
+	}
+
+	backups, err := ListBackups()
+	if err != nil {
+		return nil, err
+	}
+
+	// Already sorted newest first
+	var matching []BackupMetadata
+	for _, b := range backups {
+		if opts.Profile != "" && b.Profile != opts.Profile {
+			continue
+		}
+		if opts.Database != "" && !containsString(b.Databases, opts.Database) {
+			continue
+		}
+		matching = append(matching, b)
+	}
+	if len(matching) <= opts.Keep {
+		return nil, nil
+	}
+
+	cutoff := time.Now().Add(-opts.OlderThan)
+	var pruned []BackupMetadata
+	for _, b := range matching[max(opts.Keep, 0):] {
+		if opts.OlderThan > 0 && b.Timestamp.After(cutoff) {
+			continue
+		}
+		if !opts.DryRun {
+			if err := DeleteBackup(b.ID); err != nil {
+				return pruned, fmt.Errorf("failed to delete old backup %s: %w", b.ID, err)
+			}
+		}
+		pruned = append(pruned, b)
+	}
+	return pruned, nil
+}
+
+// VerifyBackup recomputes the checksum of every file in a backup and returns
+// the files that are missing or no longer match their recorded SHA-256.
+// Files from backups made before checksums were recorded are skipped. A
+// clean result is recorded as the backup's VerifiedAt
+func VerifyBackup(id string) ([]string, error) {
+	metadata, err := GetBackup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	backupsDir, err := GetBackupsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var bad []string
+	checked := 0
+	for _, f := range metadata.Files {
+		if f.SHA256 == "" {
+			continue
+		}
+		checked++
+		sum, err := fileSHA256(filepath.Join(backupsDir, id, f.Filename))
+		if err != nil {
+			bad = append(bad, fmt.Sprintf("%s: %v", f.Filename, err))
+			continue
+		}
+		if sum != f.SHA256 {
+			bad = append(bad, fmt.Sprintf("%s: checksum mismatch", f.Filename))
+		}
+	}
+
+	if len(bad) == 0 && checked > 0 {
+		metadata.VerifiedAt = time.Now()
+		data, err := json.MarshalIndent(metadata, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(backupsDir, id, "metadata.json"), data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to record verification: %w", err)
+		}
+	}
+	return bad, nil
+}
+
 // GetServerVersion returns the database server version
 func (c *Connection) GetServerVersion() (string, error) {
 	var version string
@@ -539,32 +881,26 @@ func (c *Connection) GetServerVersion() (string, error) {
 
 // Helper functions
 
-func generateBackupID() string {
-	return time.Now().Format("20060102-150405")
-}
-
-func isSystemDatabase(name string, dbType DatabaseType) bool {
-	name = strings.ToLower(name)
-
-	if dbType == DatabaseTypePostgres {
-		return name == "postgres" || name == "template0" || name == "template1"
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
 	}
+	defer f.Close()
 
-	// MariaDB/MySQL system databases
-	systemDBs := []string{
-		"information_schema",
-		"mysql",
-		"performance_schema",
-		"sys",
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
 	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
 
-	for _, sysDB := range systemDBs {
-		if name == sysDB {
-			return true
-		}
-	}
+func isPostgresType(t DatabaseType) bool {
+	return t == DatabaseTypePostgres || t == "postgresql"
+}
 
-	return false
+func generateBackupID() string {
+	return time.Now().Format("20060102-150405")
 }
 
 // FormatSize formats bytes into human-readable size
@@ -586,3 +922,30 @@ func FormatSize(bytes int64) string {
 		return fmt.Sprintf("%d B", bytes)
 	}
 }
+
+// ParseSize parses sizes like 500MB, 10G or 1.5TB; an empty string is 0
+func ParseSize(s string) (int64, error) {
+	value := strings.TrimSpace(strings.ToUpper(s))
+	if value == "" {
+		return 0, nil
+	}
+	units := []struct {
+		suffix string
+		mult   float64
+	}{
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	}
+	mult := 1.0
+	for _, u := range units {
+		if strings.HasSuffix(value, u.suffix) {
+			value, mult = strings.TrimSpace(strings.TrimSuffix(value, u.suffix)), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q: use a value like 500MB or 10GB", s)
+	}
+	return int64(n * mult), nil
+}
@@ -0,0 +1,275 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+)
+
+// BloatEstimate is the space a table or index wastes on dead rows, page
+// splits or fragmentation
+type BloatEstimate struct {
+	Schema string // PostgreSQL schema, or the database on MariaDB
+	Table  string
+	Index  string // "" for the table itself
+	Size   int64  // Bytes on disk
+	Wasted int64  // Estimated bytes a rebuild would reclaim
+}
+
+// Name returns the object's qualified name
+func (b BloatEstimate) Name() string {
+	if b.Index != "" {
+		return b.Schema + "." + b.Index
+	}
+	return b.Schema + "." + b.Table
+}
+
+// Percent returns the wasted share of the object's size
+func (b BloatEstimate) Percent() float64 {
+	if b.Size <= 0 {
+		return 0
+	}
+	return float64(b.Wasted) / float64(b.Size) * 100
+}
+
+// kind names the object type for messages
+func (b BloatEstimate) kind() string {
+	if b.Index != "" {
+		return "index"
+	}
+	return "table"
+}
+
+// Bloat report sort orders
+const (
+	BloatSortWasted  = "wasted"
+	BloatSortPercent = "percent"
+	BloatSortSize    = "size"
+	BloatSortName    = "name"
+)
+
+// BloatSortOrders lists the sort orders in the order the TUI cycles them
+var BloatSortOrders = []string{BloatSortWasted, BloatSortPercent, BloatSortSize, BloatSortName}
+
+// SortBloat sorts estimates by the given order, largest first except by name
+func SortBloat(estimates []BloatEstimate, by string) error {
+	var less func(a, b BloatEstimate) bool
+	switch by {
+	case BloatSortWasted, "":
+		less = func(a, b BloatEstimate) bool { return a.Wasted > b.Wasted }
+	case BloatSortPercent:
+		less = func(a, b BloatEstimate) bool { return a.Percent() > b.Percent() }
+	case BloatSortSize:
+		less = func(a, b BloatEstimate) bool { return a.Size > b.Size }
+	case BloatSortName:
+		less = func(a, b BloatEstimate) bool { return a.Name() < b.Name() }
+	default:
+		return fmt.Errorf("unknown sort order '%s' (use %s)", by, strings.Join(BloatSortOrders, ", "))
+	}
+	sort.SliceStable(estimates, func(i, j int) bool {
+		if less(estimates[i], estimates[j]) != less(estimates[j], estimates[i]) {
+			return less(estimates[i], estimates[j])
+		}
+		return estimates[i].Name() < estimates[j].Name()
+	})
+	return nil
+}
+
+// EstimateBloat estimates the bloat of every table (and, on PostgreSQL,
+// every btree index) in the current database, most wasted space first
+func (c *Connection) EstimateBloat() ([]BloatEstimate, error) {
+	var estimates []BloatEstimate
+	for _, query := range c.Driver.BloatQueries() {
+		rows, err := c.DB.Query(query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate bloat: %w", err)
+		}
+		for rows.Next() {
+			var b BloatEstimate
+			if err := rows.Scan(&b.Schema, &b.Table, &b.Index, &b.Size, &b.Wasted); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to estimate bloat: %w", err)
+			}
+			if b.Size > 0 {
+				estimates = append(estimates, b)
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate bloat: %w", err)
+		}
+	}
+	SortBloat(estimates, BloatSortWasted)
+	return estimates, nil
+}
+
+// RebuildPlan is how an object would be rebuilt, for confirming first
+type RebuildPlan struct {
+	Target    BloatEstimate
+	Method    string   // pg_repack, VACUUM FULL, REINDEX or OPTIMIZE TABLE
+	Statement string   // SQL to run; empty when Command is set
+	Command   []string // External tool and arguments
+	Warning   string   // What the rebuild locks and what it needs
+}
+
+// Describe returns the statement or command line the plan runs
+func (p RebuildPlan) Describe() string {
+	if len(p.Command) > 0 {
+		return strings.Join(p.Command, " ")
+	}
+	return p.Statement
+}
+
+// PlanRebuild picks how to rebuild a bloated object: pg_repack when both
+// the command and the extension are installed, since it only locks briefly,
+// otherwise the server's own rebuild
+func (c *Connection) PlanRebuild(b BloatEstimate) RebuildPlan {
+	plan := RebuildPlan{Target: b}
+
+	if c.Config.Type == DatabaseTypeMariaDB {
+		plan.Method = "OPTIMIZE TABLE"
+		plan.Statement = c.Driver.RebuildTableQuery(b.Schema, b.Table)
+		plan.Warning = "InnoDB rebuilds the table online and only blocks writes briefly at the start and end; " +
+			"MyISAM and Aria tables are locked for the whole rebuild. Needs free disk space for a copy of the table."
+		return plan
+	}
+
+	if c.pgRepackAvailable() {
+		plan.Method = "pg_repack"
+		plan.Command = c.pgRepackCommand(b)
+		plan.Warning = "pg_repack works online and only takes an exclusive lock briefly at the start and end. " +
+			"Needs free disk space for a copy of the " + b.kind() + "."
+		return plan
+	}
+
+	if b.Index != "" {
+		concurrently := c.pgVersionNum() >= 120000
+		plan.Method = "REINDEX"
+		plan.Statement = c.Driver.RebuildIndexQuery(concurrently, b.Schema, b.Index)
+		if concurrently {
+			plan.Warning = "REINDEX CONCURRENTLY builds a new copy of the index without blocking writes, " +
+				"but takes longer and needs free disk space for the copy."
+		} else {
+			plan.Warning = "REINDEX blocks writes to " + b.Schema + "." + b.Table +
+				" and queries using the index until it finishes."
+		}
+		return plan
+	}
+
+	plan.Method = "VACUUM FULL"
+	plan.Statement = c.Driver.RebuildTableQuery(b.Schema, b.Table)
+	plan.Warning = "VACUUM FULL takes an ACCESS EXCLUSIVE lock: every read and write on the table waits until it " +
+		"finishes. Needs free disk space for a full copy of the table. Install pg_repack to rebuild online."
+	return plan
+}
+
+// Rebuild runs a rebuild plan
+func (c *Connection) Rebuild(plan RebuildPlan) error {
+	name := plan.Target.Name()
+	logging.Info("Rebuilding %s with %s", name, plan.Method)
+
+	if len(plan.Command) > 0 {
+		cmd := exec.Command(plan.Command[0], plan.Command[1:]...)
+		cmd.Env = append(os.Environ(), "PGPASSWORD="+c.Config.Password)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed for %s: %w\nOutput: %s", plan.Method, name, err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+
+	if c.Config.Type == DatabaseTypeMariaDB {
+		return c.optimizeTable(plan.Statement, name)
+	}
+	if _, err := c.DB.Exec(plan.Statement); err != nil {
+		return fmt.Errorf("%s failed for %s: %w", plan.Method, name, err)
+	}
+	return nil
+}
+
+// optimizeTable runs OPTIMIZE TABLE, which reports failures as result rows
+// rather than errors
+func (c *Connection) optimizeTable(statement, name string) error {
+	rows, err := c.DB.Query(statement)
+	if err != nil {
+		return fmt.Errorf("OPTIMIZE TABLE failed for %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var table, op, msgType, msgText string
+		if err := rows.Scan(&table, &op, &msgType, &msgText); err != nil {
+			return fmt.Errorf("OPTIMIZE TABLE failed for %s: %w", name, err)
+		}
+		if strings.EqualFold(msgType, "error") {
+			return fmt.Errorf("OPTIMIZE TABLE failed for %s: %s", name, msgText)
+		}
+	}
+	return rows.Err()
+}
+
+// pgRepackAvailable reports whether pg_repack can run: the command is on
+// PATH and the extension is installed in the database
+func (c *Connection) pgRepackAvailable() bool {
+	if _, err := exec.LookPath("pg_repack"); err != nil {
+		return false
+	}
+	return c.pgExtensionInstalled("pg_repack")
+}
+
+// pgExtensionInstalled reports whether a PostgreSQL extension is installed
+// in the database
+func (c *Connection) pgExtensionInstalled(name string) bool {
+	var installed bool
+	err := c.DB.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = $1)", name).Scan(&installed)
+	return err == nil && installed
+}
+
+func (c *Connection) pgRepackCommand(b BloatEstimate) []string {
+	args := []string{"pg_repack",
+		"-h", c.Config.Host,
+		"-p", strconv.Itoa(c.Config.Port),
+		"-U", c.Config.User,
+		"-d", c.Config.Database,
+	}
+	if c.Config.Socket != "" {
+		args[2] = c.Config.Socket
+	}
+	if b.Index != "" {
+		return append(args, "--index", b.Schema+"."+b.Index)
+	}
+	return append(args, "--table", b.Schema+"."+b.Table)
+}
+
+// pgVersionNum returns PostgreSQL's server_version_num, or 0 when unknown
+func (c *Connection) pgVersionNum() int {
+	var version string
+	if err := c.DB.QueryRow("SHOW server_version_num").Scan(&version); err != nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(version)
+	return n
+}
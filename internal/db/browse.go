@@ -0,0 +1,155 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TableDataOptions controls server-side paging, sorting and filtering of table data
+type TableDataOptions struct {
+	Limit    int
+	Offset   int
+	OrderBy  string            // Column to sort by (empty = server order)
+	Desc     bool              // Sort descending
+	TieBreak []string          // Extra sort columns (usually the primary key) for stable pages
+	Filters  map[string]string // Column -> filter expression, see ParseFilter
+}
+
+// ColumnFilter is a parsed filter expression
+type ColumnFilter struct {
+	Op    string // =, !=, >, >=, <, <=, LIKE, CONTAINS, IS NULL, IS NOT NULL
+	Value string
+}
+
+// ParseFilter parses a filter bar expression:
+//
+//	NULL / !NULL        IS NULL / IS NOT NULL
+//	=v !=v >v >=v <v <=v comparison
+//	text with %         LIKE pattern
+//	anything else       contains (case-insensitive)
+func ParseFilter(expr string) ColumnFilter {
+	expr = strings.TrimSpace(expr)
+	switch strings.ToUpper(expr) {
+	case "NULL":
+		return ColumnFilter{Op: "IS NULL"}
+	case "!NULL":
+		return ColumnFilter{Op: "IS NOT NULL"}
+	}
+	for _, op := range []string{">=", "<=", "!=", "=", ">", "<"} {
+		if strings.HasPrefix(expr, op) {
+			return ColumnFilter{Op: op, Value: strings.TrimSpace(expr[len(op):])}
+		}
+	}
+	if strings.Contains(expr, "%") {
+		return ColumnFilter{Op: "LIKE", Value: expr}
+	}
+	return ColumnFilter{Op: "CONTAINS", Value: expr}
+}
+
+// buildFilterClause builds a WHERE clause with bound values for the filters.
+// Columns are processed in name order so the clause is deterministic.
+func (c *Connection) buildFilterClause(filters map[string]string) (string, []interface{}) {
+	if len(filters) == 0 {
+		return "", nil
+	}
+
+	columns := make([]string, 0, len(filters))
+	for col := range filters {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+
+	var conds []string
+	var args []interface{}
+	for _, col := range columns {
+		f := ParseFilter(filters[col])
+		quoted := c.QuoteIdentifier(col)
+		marker := Placeholder(len(args), c.Config.Type)
+
+		switch f.Op {
+		case "IS NULL", "IS NOT NULL":
+			conds = append(conds, fmt.Sprintf("%s %s", quoted, f.Op))
+			continue
+		case "LIKE", "CONTAINS":
+			value := f.Value
+			if f.Op == "CONTAINS" {
+				value = "%" + value + "%"
+			}
+			// PostgreSQL needs a text cast to LIKE non-text columns, and is case-sensitive by default
+			if c.Config.Type == DatabaseTypePostgres {
+				conds = append(conds, fmt.Sprintf("CAST(%s AS TEXT) ILIKE %s", quoted, marker))
+			} else {
+				conds = append(conds, fmt.Sprintf("%s LIKE %s", quoted, marker))
+			}
+			args = append(args, value)
+		default:
+			conds = append(conds, fmt.Sprintf("%s %s %s", quoted, f.Op, marker))
+			args = append(args, f.Value)
+		}
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// tableDataQuery builds the filtered and sorted SELECT for table data, without paging
+func (c *Connection) tableDataQuery(tableName string, opts TableDataOptions) (string, []interface{}) {
+	where, args := c.buildFilterClause(opts.Filters)
+	query := "SELECT * FROM " + c.QuoteIdentifier(tableName) + where
+
+	if opts.OrderBy != "" {
+		dir := "ASC"
+		if opts.Desc {
+			dir = "DESC"
+		}
+		order := []string{c.QuoteIdentifier(opts.OrderBy) + " " + dir}
+		for _, col := range opts.TieBreak {
+			if col != opts.OrderBy {
+				order = append(order, c.QuoteIdentifier(col)+" "+dir)
+			}
+		}
+		query += " ORDER BY " + strings.Join(order, ", ")
+	}
+	return query, args
+}
+
+// GetTableDataWithOptions returns one page of table data, sorted and filtered on the server
+func (c *Connection) GetTableDataWithOptions(tableName string, opts TableDataOptions) (*QueryResult, error) {
+	query, args := c.tableDataQuery(tableName, opts)
+	if opts.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", opts.Limit, opts.Offset)
+	}
+	return c.Query(query, args...)
+}
+
+// CountTableRowsWithOptions counts the rows matching the filters
+func (c *Connection) CountTableRowsWithOptions(tableName string, opts TableDataOptions) (int64, error) {
+	if len(opts.Filters) == 0 {
+		return c.CountTableRows(tableName)
+	}
+
+	where, args := c.buildFilterClause(opts.Filters)
+	var count int64
+	err := c.DB.QueryRow("SELECT COUNT(*) FROM "+c.QuoteIdentifier(tableName)+where, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+	return count, nil
+}
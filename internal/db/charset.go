@@ -0,0 +1,209 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+)
+
+// tableCharsetRe finds the default character set in a CREATE TABLE's options
+var tableCharsetRe = regexp.MustCompile(`(?i)\b(?:CHARSET|CHARACTER\s+SET)\s*=?\s*([a-z0-9_]+)`)
+
+// useDatabaseRe finds the database a USE statement switches to
+var useDatabaseRe = regexp.MustCompile("(?i)^USE\\s+[`\"]?([^`\"\\s;]+)")
+
+// hasFourByteUTF8 reports whether s holds characters outside the Basic
+// Multilingual Plane, such as emoji, which take four bytes in UTF-8
+func hasFourByteUTF8(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0xF0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasNonASCII reports whether s holds anything beyond 7-bit ASCII
+func hasNonASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return true
+		}
+	}
+	return false
+}
+
+// charsetBase returns the character set a collation belongs to, e.g. utf8mb4
+// for utf8mb4_general_ci
+func charsetBase(collation string) string {
+	name, _, _ := strings.Cut(strings.ToLower(collation), "_")
+	return name
+}
+
+// charsetGuard watches an import for text the target can't store. MariaDB
+// tables in utf8/utf8mb3 or latin1 turn 4-byte characters such as emoji into
+// '?' or reject the row, and PostgreSQL databases in a single-byte encoding
+// fail on anything outside it. Only table defaults are looked at; a column
+// declared with its own character set isn't caught.
+type charsetGuard struct {
+	conn      *Connection
+	tally     *importTally
+	database  string
+	declared  map[string]string // Default charset of tables the dump creates; "" for the database's
+	checked   map[string]bool   // Tables already looked at
+	dbChecked bool              // The connection and database were looked at
+}
+
+func newCharsetGuard(conn *Connection, tally *importTally) *charsetGuard {
+	return &charsetGuard{
+		conn:     conn,
+		tally:    tally,
+		database: conn.Config.Database,
+		declared: make(map[string]string),
+		checked:  make(map[string]bool),
+	}
+}
+
+// note looks at a statement before it runs
+func (g *charsetGuard) note(stmt string) {
+	if isPostgresType(g.conn.Config.Type) {
+		g.notePostgres(stmt)
+		return
+	}
+
+	head := stmt[:min(len(stmt), 512)]
+	switch strings.ToUpper(firstKeyword(head)) {
+	case "USE":
+		if m := useDatabaseRe.FindStringSubmatch(head); m != nil {
+			g.database = m[1]
+		}
+	case "CREATE":
+		m := createdTableRe.FindStringSubmatch(head)
+		if m == nil {
+			return
+		}
+		// Table options follow the column list; column charsets are inside it
+		charset := ""
+		if end := strings.LastIndex(stmt, ")"); end >= 0 {
+			if cm := tableCharsetRe.FindStringSubmatch(stmt[end:]); cm != nil {
+				charset = strings.ToLower(cm[1])
+			}
+		}
+		g.declared[g.tableKey(m[1])] = charset
+	case "INSERT", "REPLACE":
+		m := affectedTableRe.FindStringSubmatch(head)
+		if m == nil {
+			return
+		}
+		key := g.tableKey(m[1])
+		if g.checked[key] || !hasFourByteUTF8(stmt) {
+			return
+		}
+		g.checked[key] = true
+		g.checkConnection()
+		g.checkTable(key)
+	}
+}
+
+// tableKey qualifies a table name with the database it lands in
+func (g *charsetGuard) tableKey(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(part, "`\"")
+	}
+	if len(parts) == 1 {
+		return g.database + "." + parts[0]
+	}
+	return strings.Join(parts, ".")
+}
+
+// checkConnection warns once when the session can't carry 4-byte characters
+func (g *charsetGuard) checkConnection() {
+	if g.dbChecked {
+		return
+	}
+	g.dbChecked = true
+
+	var charset string
+	if err := g.conn.DB.QueryRow("SELECT @@character_set_connection").Scan(&charset); err != nil {
+		logging.Debug("Failed to read the connection character set: %v", err)
+		return
+	}
+	if !strings.EqualFold(charset, "utf8mb4") {
+		g.tally.warn("The connection uses %s, so 4-byte characters such as emoji will be mangled", charset)
+	}
+}
+
+// checkTable warns when a table receiving 4-byte characters can't store them
+func (g *charsetGuard) checkTable(key string) {
+	database, table, _ := strings.Cut(key, ".")
+
+	charset, declared := g.declared[key]
+	if !declared {
+		var collation string
+		err := g.conn.DB.QueryRow(
+			"SELECT TABLE_COLLATION FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?",
+			database, table).Scan(&collation)
+		if err != nil {
+			logging.Debug("Failed to read the character set of %s: %v", key, err)
+			return
+		}
+		charset = charsetBase(collation)
+	}
+	if charset == "" {
+		err := g.conn.DB.QueryRow(
+			"SELECT DEFAULT_CHARACTER_SET_NAME FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = ?",
+			database).Scan(&charset)
+		if err != nil {
+			logging.Debug("Failed to read the character set of %s: %v", database, err)
+			return
+		}
+		charset = strings.ToLower(charset)
+	}
+
+	switch charset {
+	case "utf8mb4", "utf16", "utf16le", "utf32", "binary":
+	default:
+		g.tally.warn("%s stores %s text: 4-byte characters such as emoji in its rows will be replaced with '?' or rejected",
+			key, charset)
+	}
+}
+
+// notePostgres warns once when non-ASCII text meets a database whose
+// encoding may not hold it
+func (g *charsetGuard) notePostgres(stmt string) {
+	if g.dbChecked || !hasNonASCII(stmt) {
+		return
+	}
+	g.dbChecked = true
+
+	var encoding string
+	if err := g.conn.DB.QueryRow("SHOW server_encoding").Scan(&encoding); err != nil {
+		logging.Debug("Failed to read the server encoding: %v", err)
+		return
+	}
+	switch strings.ToUpper(encoding) {
+	case "UTF8", "SQL_ASCII":
+	default:
+		g.tally.warn("Database %s uses the %s encoding; characters outside it will fail to load", g.database, encoding)
+	}
+}
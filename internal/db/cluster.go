@@ -38,32 +38,32 @@ const (
 
 // ClusterStatus represents the overall cluster status
 type ClusterStatus struct {
-	Type         ClusterType
-	IsPrimary    bool
-	IsHealthy    bool
-	NodeCount    int
-	Nodes        []ClusterNode
-	LocalNode    *ClusterNode
-	LastChecked  time.Time
-	ErrorMessage string
+	Type         ClusterType   `json:"type"`
+	IsPrimary    bool          `json:"is_primary"`
+	IsHealthy    bool          `json:"is_healthy"`
+	NodeCount    int           `json:"node_count"`
+	Nodes        []ClusterNode `json:"nodes"`
+	LocalNode    *ClusterNode  `json:"local_node,omitempty"`
+	LastChecked  time.Time     `json:"last_checked"`
+	ErrorMessage string        `json:"error,omitempty"`
 }
 
 // ClusterNode represents a node in the cluster
 type ClusterNode struct {
-	Address          string
-	Port             int
-	Role             string // "primary", "replica", "standby", "donor", "synced", etc.
-	State            string
-	IsLocal          bool
-	LagBytes         int64
-	LagSeconds       float64
-	SyncState        string
-	LastSeen         time.Time
-	ReplicationSlot  string
-	SentLSN          string
-	WriteLSN         string
-	FlushLSN         string
-	ReplayLSN        string
+	Address         string    `json:"address"`
+	Port            int       `json:"port,omitempty"`
+	Role            string    `json:"role"` // "primary", "replica", "standby", "donor", "synced", etc.
+	State           string    `json:"state,omitempty"`
+	IsLocal         bool      `json:"is_local"`
+	LagBytes        int64     `json:"lag_bytes"`
+	LagSeconds      float64   `json:"lag_seconds"`
+	SyncState       string    `json:"sync_state,omitempty"`
+	LastSeen        time.Time `json:"last_seen"`
+	ReplicationSlot string    `json:"replication_slot,omitempty"`
+	SentLSN         string    `json:"sent_lsn,omitempty"`
+	WriteLSN        string    `json:"write_lsn,omitempty"`
+	FlushLSN        string    `json:"flush_lsn,omitempty"`
+	ReplayLSN       string    `json:"replay_lsn,omitempty"`
 }
 
 // GaleraStatus represents MariaDB Galera cluster status
@@ -171,6 +171,18 @@ func (c *Connection) GetClusterStatus() (*ClusterStatus, error) {
 				return status, nil
 			}
 		}
+
+		// Then logical replication, which any server can publish or subscribe to
+		logical, err := c.GetLogicalReplicationStatus()
+		if err == nil && logical.Configured() {
+			status.Type = ClusterTypePostgresLogical
+			status.Nodes = logical.Nodes()
+			status.NodeCount = len(status.Nodes) + 1
+			status.ErrorMessage = logical.Problem()
+			status.IsHealthy = status.ErrorMessage == ""
+			status.LocalNode = &ClusterNode{Role: logical.Role(), IsLocal: true}
+			return status, nil
+		}
 	}
 
 	return status, nil
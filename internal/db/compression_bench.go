@@ -0,0 +1,308 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default compression levels, matching what the tools pick on their own
+var defaultCompressionLevels = map[CompressionType]int{
+	CompressionGzip: 6,
+	CompressionXZ:   6,
+	CompressionZstd: 3,
+}
+
+// benchmarkLevels are the levels tried for each compression type
+var benchmarkLevels = map[CompressionType][]int{
+	CompressionGzip: {1, 6, 9},
+	CompressionZstd: {1, 3, 9, 19},
+	CompressionXZ:   {1, 6, 9},
+}
+
+// CompressionLevelOrDefault returns level, or the type's default when it is 0
+func CompressionLevelOrDefault(compression CompressionType, level int) int {
+	if level != 0 {
+		return level
+	}
+	return defaultCompressionLevels[compression]
+}
+
+// compressionLevelFlag returns the -N flag for the xz and zstd tools
+func compressionLevelFlag(compression CompressionType, level int) string {
+	return "-" + strconv.Itoa(CompressionLevelOrDefault(compression, level))
+}
+
+// CompressionBenchOptions configures a compression benchmark
+type CompressionBenchOptions struct {
+	Database       string
+	Tables         []string // Tables to sample; empty samples every table
+	RowsPerTable   int      // Rows sampled per table (default 5000)
+	MaxSampleBytes int      // Stop sampling after this much SQL (default 16MB)
+	MinThroughput  float64  // Slowest acceptable compression in MB/s for the recommendation (default 20)
+	OnProgress     func(step string)
+}
+
+// CompressionBenchResult is one compression setting's result on the sample
+type CompressionBenchResult struct {
+	Compression CompressionType
+	Level       int
+	Size        int64   // Compressed sample size
+	Ratio       float64 // Sample size / compressed size
+	Duration    time.Duration
+	Throughput  float64 // Input MB/s
+	Err         error   // Set when the tool isn't installed or failed
+}
+
+// CompressionBench is the outcome of a compression benchmark
+type CompressionBench struct {
+	SampleBytes    int64
+	SampledTables  int
+	SampledRows    int64
+	DatabaseSize   int64 // On-disk size, for estimating a full backup; 0 if unknown
+	Results        []CompressionBenchResult
+	Recommended    *CompressionBenchResult
+	Recommendation string // Why the recommended setting was picked
+}
+
+// EstimatedSize estimates a full dump's compressed size with a result,
+// assuming the dump is about as large as the data on disk
+func (b *CompressionBench) EstimatedSize(r CompressionBenchResult) int64 {
+	if b.DatabaseSize == 0 || r.Ratio == 0 {
+		return 0
+	}
+	return int64(float64(b.DatabaseSize) / r.Ratio)
+}
+
+// EstimatedDuration estimates how long compressing a full dump takes
+func (b *CompressionBench) EstimatedDuration(r CompressionBenchResult) time.Duration {
+	if b.DatabaseSize == 0 || r.Throughput == 0 {
+		return 0
+	}
+	return time.Duration(float64(b.DatabaseSize) / (r.Throughput * 1024 * 1024) * float64(time.Second))
+}
+
+// BenchmarkCompression samples rows as the SQL a backup would write and
+// compresses the sample with gzip, zstd and xz at several levels. The
+// recommendation is the best ratio among settings fast enough to keep up
+// with MinThroughput, or the fastest setting when none is.
+func (c *Connection) BenchmarkCompression(opts CompressionBenchOptions) (*CompressionBench, error) {
+	if opts.RowsPerTable <= 0 {
+		opts.RowsPerTable = 5000
+	}
+	if opts.MaxSampleBytes <= 0 {
+		opts.MaxSampleBytes = 16 * 1024 * 1024
+	}
+	if opts.MinThroughput <= 0 {
+		opts.MinThroughput = 20
+	}
+	if opts.Database != "" {
+		if err := c.UseDatabase(opts.Database); err != nil {
+			return nil, err
+		}
+	}
+
+	tables := opts.Tables
+	if len(tables) == 0 {
+		list, err := c.ListTables()
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range list {
+			tables = append(tables, t.Name)
+		}
+	}
+
+	bench := &CompressionBench{}
+	if opts.Database != "" {
+		if size, err := c.GetDatabaseSize(opts.Database); err == nil {
+			bench.DatabaseSize = size
+		}
+	}
+
+	var sample bytes.Buffer
+	for _, table := range tables {
+		if sample.Len() >= opts.MaxSampleBytes {
+			break
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress("Sampling " + table)
+		}
+		rows, err := c.sampleTableSQL(&sample, table, opts.RowsPerTable, opts.MaxSampleBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sample %s: %w", table, err)
+		}
+		bench.SampledTables++
+		bench.SampledRows += rows
+	}
+	bench.SampleBytes = int64(sample.Len())
+	if bench.SampleBytes == 0 {
+		return nil, fmt.Errorf("no rows to sample")
+	}
+
+	data := sample.Bytes()
+	for _, compression := range []CompressionType{CompressionGzip, CompressionZstd, CompressionXZ} {
+		for _, level := range benchmarkLevels[compression] {
+			if opts.OnProgress != nil {
+				opts.OnProgress(fmt.Sprintf("%s -%d", compression, level))
+			}
+			bench.Results = append(bench.Results, benchmarkCompressor(data, compression, level))
+		}
+	}
+
+	bench.recommend(opts.MinThroughput)
+	return bench, nil
+}
+
+// recommend picks the best ratio among results at least minThroughput fast
+func (b *CompressionBench) recommend(minThroughput float64) {
+	var best, fastest *CompressionBenchResult
+	for i := range b.Results {
+		r := &b.Results[i]
+		if r.Err != nil {
+			continue
+		}
+		if fastest == nil || r.Throughput > fastest.Throughput {
+			fastest = r
+		}
+		if r.Throughput >= minThroughput && (best == nil || r.Ratio > best.Ratio) {
+			best = r
+		}
+	}
+
+	switch {
+	case best != nil:
+		b.Recommended = best
+		b.Recommendation = fmt.Sprintf("best ratio at %.0f MB/s or faster", minThroughput)
+	case fastest != nil:
+		b.Recommended = fastest
+		b.Recommendation = fmt.Sprintf("nothing reached %.0f MB/s, so the fastest setting", minThroughput)
+	}
+}
+
+// sampleTableSQL writes up to limit rows of a table as batched INSERTs, the
+// way exports do, stopping once the buffer reaches maxBytes
+func (c *Connection) sampleTableSQL(buf *bytes.Buffer, table string, limit, maxBytes int) (int64, error) {
+	rows, err := c.DB.Query(fmt.Sprintf("SELECT * FROM %s LIMIT %d", c.QuoteIdentifier(table), limit))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	quotedColumns := make([]string, len(columns))
+	for i, col := range columns {
+		quotedColumns[i] = c.QuoteIdentifier(col)
+	}
+	valuePtrs := make([]interface{}, len(columns))
+	valueHolders := make([]interface{}, len(columns))
+	for i := range valuePtrs {
+		valuePtrs[i] = &valueHolders[i]
+	}
+
+	var count int64
+	var values []string
+	flush := func() {
+		if len(values) > 0 {
+			fmt.Fprintf(buf, "INSERT INTO %s (%s) VALUES\n%s;\n\n",
+				c.QuoteIdentifier(table), strings.Join(quotedColumns, ", "), strings.Join(values, ",\n"))
+			values = values[:0]
+		}
+	}
+
+	rowValues := make([]string, 0, len(columns))
+	for rows.Next() && buf.Len() < maxBytes {
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return count, err
+		}
+		rowValues = rowValues[:0]
+		for _, val := range valueHolders {
+			rowValues = append(rowValues, c.formatValueForExport(val))
+		}
+		values = append(values, "("+strings.Join(rowValues, ", ")+")")
+		count++
+		if len(values) >= 1000 {
+			flush()
+		}
+	}
+	flush()
+	return count, rows.Err()
+}
+
+// benchmarkCompressor compresses data once with a setting, in process for
+// gzip and through the xz and zstd tools like exports do
+func benchmarkCompressor(data []byte, compression CompressionType, level int) CompressionBenchResult {
+	result := CompressionBenchResult{Compression: compression, Level: level}
+	var out countingWriter
+
+	start := time.Now()
+	switch compression {
+	case CompressionGzip:
+		gz, err := gzip.NewWriterLevel(&out, level)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		gz.Write(data)
+		result.Err = gz.Close()
+	default:
+		tool := string(compression)
+		cmd := exec.Command(tool, "-c", compressionLevelFlag(compression, level))
+		cmd.Stdin = bytes.NewReader(data)
+		cmd.Stdout = &out
+		if err := cmd.Run(); err != nil {
+			if _, lookErr := exec.LookPath(tool); lookErr != nil {
+				err = fmt.Errorf("%s is not installed", tool)
+			}
+			result.Err = err
+		}
+	}
+	result.Duration = time.Since(start)
+	if result.Err != nil {
+		return result
+	}
+
+	result.Size = out.n
+	if out.n > 0 {
+		result.Ratio = float64(len(data)) / float64(out.n)
+	}
+	if secs := result.Duration.Seconds(); secs > 0 {
+		result.Throughput = float64(len(data)) / (1024 * 1024) / secs
+	}
+	return result
+}
+
+// countingWriter discards what it is given, counting the bytes
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
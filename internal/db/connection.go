@@ -21,6 +21,7 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"sync"
 
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
@@ -31,6 +32,20 @@ type Connection struct {
 	DB     *sql.DB
 	Config ConnectionConfig
 	Driver Driver
+
+	variables map[string]string // Session variables applied, replayed by Dedicated
+}
+
+var (
+	openConnsMu sync.Mutex
+	openConns   = make(map[*Connection]struct{})
+)
+
+// OpenConnections returns how many connections are open and not yet closed
+func OpenConnections() int {
+	openConnsMu.Lock()
+	defer openConnsMu.Unlock()
+	return len(openConns)
 }
 
 // ConnectionConfig holds the connection parameters
@@ -42,6 +57,9 @@ type ConnectionConfig struct {
 	Password string
 	Database string
 	Socket   string // Unix socket path (optional, MariaDB only)
+
+	// Statements run on every session opened, e.g. SET time_zone = '+00:00'
+	OnConnect []string
 }
 
 // Connect establishes a connection to the database server
@@ -63,7 +81,7 @@ func Connect(cfg ConnectionConfig) (*Connection, error) {
 	}
 
 	// Open connection using driver-specific DSN
-	db, err := sql.Open(driver.DriverName(), driver.DSN(cfg))
+	db, err := openDB(driver, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open connection: %w", err)
 	}
@@ -74,15 +92,38 @@ func Connect(cfg ConnectionConfig) (*Connection, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &Connection{
+	conn := &Connection{
 		DB:     db,
 		Config: cfg,
 		Driver: driver,
-	}, nil
+	}
+	openConnsMu.Lock()
+	openConns[conn] = struct{}{}
+	openConnsMu.Unlock()
+	return conn, nil
+}
+
+// Dedicated opens another connection with the same settings, database and
+// session variables, for long operations that would otherwise block or
+// interleave with the interactive session. The caller closes it.
+func (c *Connection) Dedicated() (*Connection, error) {
+	conn, err := Connect(c.Config)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.ApplyVariables(c.variables); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
 }
 
 // Close closes the database connection
 func (c *Connection) Close() error {
+	openConnsMu.Lock()
+	delete(openConns, c)
+	openConnsMu.Unlock()
+
 	if c.DB != nil {
 		return c.DB.Close()
 	}
@@ -118,7 +159,7 @@ func (c *Connection) reconnectToDatabase(name string) error {
 	newCfg := c.Config
 	newCfg.Database = name
 
-	db, err := sql.Open(c.Driver.DriverName(), c.Driver.DSN(newCfg))
+	db, err := openDB(c.Driver, newCfg)
 	if err != nil {
 		return fmt.Errorf("failed to reconnect to database %s: %w", name, err)
 	}
@@ -0,0 +1,167 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConnectionSource is every session sharing a user, client host and
+// application
+type ConnectionSource struct {
+	User        string
+	Host        string // Client address without the port; "local" for sockets on PostgreSQL
+	Hostname    string // Reverse DNS name of Host, once resolved
+	Application string
+	Databases   []string
+	Count       int
+	Oldest      time.Duration // Age of the oldest session; -1 when the server doesn't record it
+}
+
+// ConnectionSources lists who is connected to the server, grouped by user,
+// host and application, busiest first. The audit's own session is left out.
+func (c *Connection) ConnectionSources() ([]ConnectionSource, error) {
+	var lastErr error
+	for _, query := range c.Driver.ConnectionSourcesQueries() {
+		sources, err := c.queryConnectionSources(query)
+		if err == nil {
+			return sources, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("failed to list connections: %w", lastErr)
+}
+
+func (c *Connection) queryConnectionSources(query string) ([]ConnectionSource, error) {
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make(map[[3]string]*ConnectionSource)
+	var order []*ConnectionSource
+	for rows.Next() {
+		var user, host, app, database string
+		var age sql.NullInt64
+		if err := rows.Scan(&user, &host, &app, &database, &age); err != nil {
+			return nil, err
+		}
+		host = stripClientPort(host)
+
+		key := [3]string{user, host, app}
+		src, ok := groups[key]
+		if !ok {
+			src = &ConnectionSource{User: user, Host: host, Application: app, Oldest: -1}
+			groups[key] = src
+			order = append(order, src)
+		}
+		src.Count++
+		if database != "" && !containsString(src.Databases, database) {
+			src.Databases = append(src.Databases, database)
+		}
+		if age.Valid {
+			if d := time.Duration(age.Int64) * time.Second; d > src.Oldest {
+				src.Oldest = d
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sources := make([]ConnectionSource, len(order))
+	for i, src := range order {
+		sort.Strings(src.Databases)
+		sources[i] = *src
+	}
+	sort.SliceStable(sources, func(i, j int) bool {
+		if sources[i].Count != sources[j].Count {
+			return sources[i].Count > sources[j].Count
+		}
+		return sources[i].User+sources[i].Host < sources[j].User+sources[j].Host
+	})
+	return sources, nil
+}
+
+// stripClientPort removes the port from a MariaDB processlist host such as
+// 10.0.0.5:51234 or [::1]:51234
+func stripClientPort(host string) string {
+	if h, port, err := net.SplitHostPort(host); err == nil && port != "" {
+		return h
+	}
+	return host
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveHostnames fills in the reverse DNS names of the sources' client
+// addresses, looking each address up once and giving up after timeout.
+// Addresses without a PTR record keep an empty Hostname.
+func ResolveHostnames(sources []ConnectionSource, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	names := make(map[string]string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, src := range sources {
+		if net.ParseIP(src.Host) == nil {
+			continue
+		}
+		mu.Lock()
+		_, seen := names[src.Host]
+		names[src.Host] = ""
+		mu.Unlock()
+		if seen {
+			continue
+		}
+
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			found, err := net.DefaultResolver.LookupAddr(ctx, addr)
+			if err != nil || len(found) == 0 {
+				return
+			}
+			mu.Lock()
+			names[addr] = strings.TrimSuffix(found[0], ".")
+			mu.Unlock()
+		}(src.Host)
+	}
+	wg.Wait()
+
+	for i := range sources {
+		sources[i].Hostname = names[sources[i].Host]
+	}
+}
@@ -0,0 +1,158 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import "fmt"
+
+// ForeignKey describes a foreign key from Table to RefTable
+type ForeignKey struct {
+	Name       string
+	Table      string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnUpdate   string
+	OnDelete   string
+}
+
+// CheckConstraint describes a CHECK constraint
+type CheckConstraint struct {
+	Name   string
+	Clause string
+}
+
+// UniqueConstraint describes a UNIQUE constraint
+type UniqueConstraint struct {
+	Name    string
+	Columns []string
+}
+
+// TableConstraints holds a table's relationships and constraints
+type TableConstraints struct {
+	ForeignKeys  []ForeignKey // Keys on this table
+	ReferencedBy []ForeignKey // Keys on other tables pointing at this one
+	Uniques      []UniqueConstraint
+	Checks       []CheckConstraint
+}
+
+// ListForeignKeys lists the foreign keys defined on a table
+func (c *Connection) ListForeignKeys(table string) ([]ForeignKey, error) {
+	return c.listForeignKeys(c.Driver.ForeignKeysQuery(table, false))
+}
+
+// ListReferencingKeys lists the foreign keys on other tables that reference a table
+func (c *Connection) ListReferencingKeys(table string) ([]ForeignKey, error) {
+	return c.listForeignKeys(c.Driver.ForeignKeysQuery(table, true))
+}
+
+func (c *Connection) listForeignKeys(query string) ([]ForeignKey, error) {
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []ForeignKey
+	byName := make(map[string]int)
+	for rows.Next() {
+		var fk ForeignKey
+		var column, refColumn string
+		if err := rows.Scan(&fk.Name, &fk.Table, &column, &fk.RefTable, &refColumn, &fk.OnUpdate, &fk.OnDelete); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key: %w", err)
+		}
+
+		// One row per column pair
+		key := fk.Table + "." + fk.Name
+		i, ok := byName[key]
+		if !ok {
+			i = len(keys)
+			byName[key] = i
+			keys = append(keys, fk)
+		}
+		keys[i].Columns = append(keys[i].Columns, column)
+		keys[i].RefColumns = append(keys[i].RefColumns, refColumn)
+	}
+	return keys, rows.Err()
+}
+
+// ListUniqueConstraints lists the UNIQUE constraints of a table
+func (c *Connection) ListUniqueConstraints(table string) ([]UniqueConstraint, error) {
+	rows, err := c.DB.Query(c.Driver.UniqueConstraintsQuery(table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unique constraints: %w", err)
+	}
+	defer rows.Close()
+
+	var uniques []UniqueConstraint
+	byName := make(map[string]int)
+	for rows.Next() {
+		var name, column string
+		if err := rows.Scan(&name, &column); err != nil {
+			return nil, fmt.Errorf("failed to scan unique constraint: %w", err)
+		}
+		i, ok := byName[name]
+		if !ok {
+			i = len(uniques)
+			byName[name] = i
+			uniques = append(uniques, UniqueConstraint{Name: name})
+		}
+		uniques[i].Columns = append(uniques[i].Columns, column)
+	}
+	return uniques, rows.Err()
+}
+
+// ListCheckConstraints lists the CHECK constraints of a table
+func (c *Connection) ListCheckConstraints(table string) ([]CheckConstraint, error) {
+	rows, err := c.DB.Query(c.Driver.CheckConstraintsQuery(table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list check constraints: %w", err)
+	}
+	defer rows.Close()
+
+	var checks []CheckConstraint
+	for rows.Next() {
+		var cc CheckConstraint
+		if err := rows.Scan(&cc.Name, &cc.Clause); err != nil {
+			return nil, fmt.Errorf("failed to scan check constraint: %w", err)
+		}
+		checks = append(checks, cc)
+	}
+	return checks, rows.Err()
+}
+
+// GetTableConstraints collects a table's foreign keys, incoming references,
+// unique and check constraints. Servers without CHECK support (MariaDB
+// before 10.2) just report no checks.
+func (c *Connection) GetTableConstraints(table string) (*TableConstraints, error) {
+	var tc TableConstraints
+	var err error
+
+	if tc.ForeignKeys, err = c.ListForeignKeys(table); err != nil {
+		return nil, err
+	}
+	if tc.ReferencedBy, err = c.ListReferencingKeys(table); err != nil {
+		return nil, err
+	}
+	if tc.Uniques, err = c.ListUniqueConstraints(table); err != nil {
+		return nil, err
+	}
+	tc.Checks, _ = c.ListCheckConstraints(table)
+
+	return &tc, nil
+}
@@ -0,0 +1,130 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DefaultCopyChunkSize is how many rows each copy statement of a clone or
+// merge moves
+const DefaultCopyChunkSize = 5000
+
+// CopyProgress reports rows copied by a clone or merge. Total is the
+// server's row estimate for the whole run and may be off.
+type CopyProgress struct {
+	SourceDB string
+	Table    string // Table in the target, after any rename
+	Copied   int64  // Rows copied into this table
+	Done     int64  // Rows copied so far, all tables
+	Total    int64
+}
+
+// copyTableRows copies sourceDB.table into targetDB.targetTable with one
+// INSERT ... SELECT per primary key chunk, so a long copy reports progress
+// and can be cancelled between chunks. Tables without a primary key are
+// copied in a single statement. pk is the source table's primary key.
+func (c *Connection) copyTableRows(ctx context.Context, sourceDB, table, targetDB, targetTable string, pk []string, chunkSize int, progress func(int64)) (int64, error) {
+	source := c.QuoteIdentifier(sourceDB) + "." + c.QuoteIdentifier(table)
+	insert := fmt.Sprintf("INSERT INTO %s.%s SELECT * FROM %s",
+		c.QuoteIdentifier(targetDB), c.QuoteIdentifier(targetTable), source)
+	if chunkSize <= 0 {
+		chunkSize = DefaultCopyChunkSize
+	}
+
+	var copied int64
+	if len(pk) == 0 {
+		res, err := c.DB.ExecContext(ctx, insert)
+		if err != nil {
+			return 0, err
+		}
+		copied, _ = res.RowsAffected()
+		progress(copied)
+		return copied, nil
+	}
+
+	var lower []string // Key of the last row copied
+	for {
+		if err := ctx.Err(); err != nil {
+			return copied, err
+		}
+
+		upper, err := c.copyChunkBound(ctx, source, pk, lower, chunkSize)
+		if err != nil {
+			return copied, err
+		}
+		where, args := c.chunkRange(pk, lower, upper)
+		res, err := c.DB.ExecContext(ctx, insert+where, args...)
+		if err != nil {
+			return copied, err
+		}
+		n, _ := res.RowsAffected()
+		copied += n
+		progress(copied)
+
+		if upper == nil {
+			return copied, nil
+		}
+		lower = upper
+	}
+}
+
+// copyChunkBound returns the primary key closing the chunk after lower, or
+// nil when the rest of the table fits in one chunk
+func (c *Connection) copyChunkBound(ctx context.Context, source string, pk, lower []string, size int) ([]string, error) {
+	where, args := c.chunkRange(pk, lower, nil)
+	key := c.quoteIdentifiers(pk)
+	rows, err := c.DB.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT 1 OFFSET %d",
+		key, source, where, key, size-1), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find chunk boundary: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	upper := make([]string, len(pk))
+	dest := make([]interface{}, len(pk))
+	for i := range upper {
+		dest[i] = &upper[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, fmt.Errorf("failed to scan chunk boundary: %w", err)
+	}
+	return upper, nil
+}
+
+// copyAborted words the error of a cancelled or failed clone or merge,
+// naming what cleanup left behind
+func copyAborted(ctx context.Context, verb string, err error, copied int64, cleanup string) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("%s cancelled after %d rows; %s", verb, copied, cleanup)
+	}
+	return fmt.Errorf("%w; %s", err, cleanup)
+}
+
+// renamedCreateTable rewrites a CREATE TABLE statement to create newName
+func (c *Connection) renamedCreateTable(createStmt, table, newName string) string {
+	return strings.Replace(createStmt,
+		fmt.Sprintf("CREATE TABLE %s", c.QuoteIdentifier(table)),
+		fmt.Sprintf("CREATE TABLE %s", c.QuoteIdentifier(newName)), 1)
+}
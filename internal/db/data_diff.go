@@ -0,0 +1,523 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RowDiffKind says how a row differs between source and target
+type RowDiffKind int
+
+const (
+	RowMissing RowDiffKind = iota // Only in the source, inserted into the target
+	RowExtra                      // Only in the target, deleted from it
+	RowChanged                    // In both with different values
+)
+
+// RowDiff identifies a differing row by its primary key
+type RowDiff struct {
+	Kind RowDiffKind
+	Key  []string
+}
+
+// DataDiffOptions configures CompareData
+type DataDiffOptions struct {
+	Source     string      // Database with the expected data
+	Target     string      // Database to check
+	TargetConn *Connection // Server of the target (nil = the same server)
+	Tables     []string    // Empty = every table in both databases
+	ChunkSize  int         // Rows per checksum chunk (default 1000)
+	MaxRows    int         // Differing rows listed per table (default 100)
+	SQL        io.Writer   // When set, statements that reconcile the target are written here
+	OnProgress func(table string, tableNum, totalTables int)
+
+	// OnStatement is called with each reconciling statement, e.g. to apply it
+	OnStatement func(kind RowDiffKind, statement string) error
+}
+
+// TableDataDiff is the data comparison of one table
+type TableDataDiff struct {
+	Table        string
+	Chunks       int
+	ChunksDiffer int
+	Missing      int64
+	Extra        int64
+	Changed      int64
+	Rows         []RowDiff // The first MaxRows differing rows
+	Skipped      string    // Why the table wasn't compared
+}
+
+// Identical reports whether the table's data matches
+func (t TableDataDiff) Identical() bool {
+	return t.Skipped == "" && t.Missing == 0 && t.Extra == 0 && t.Changed == 0
+}
+
+// DataDiff is the result of CompareData
+type DataDiff struct {
+	Source       string
+	Target       string
+	Tables       []TableDataDiff
+	OnlyInSource []string
+	OnlyInTarget []string
+	Statements   int64 // Reconciling statements generated
+}
+
+// CompareData compares the rows of the tables two databases share, the way
+// pt-table-checksum does: both sides checksum the same primary key ranges and
+// only chunks whose checksums differ are compared row by row. Tables without
+// a primary key are skipped.
+func (c *Connection) CompareData(opts DataDiffOptions) (*DataDiff, error) {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = 1000
+	}
+	if opts.MaxRows <= 0 {
+		opts.MaxRows = 100
+	}
+
+	// Each side gets a connection of its own, so no USE or reconnect is
+	// needed while both are queried in turn
+	src, err := c.openDatabase(opts.Source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", opts.Source, err)
+	}
+	defer src.Close()
+
+	server := opts.TargetConn
+	if server == nil {
+		server = c
+	}
+	// OnStatement may apply the reconciling statements, deleting rows
+	if opts.OnStatement != nil {
+		if err := server.CheckDatabaseProtected(opts.Target, "reconcile data in"); err != nil {
+			return nil, err
+		}
+	}
+	dst, err := server.openDatabase(opts.Target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", opts.Target, err)
+	}
+	defer dst.Close()
+
+	if isPostgresType(src.Config.Type) != isPostgresType(dst.Config.Type) {
+		return nil, fmt.Errorf("can't compare data between %s and %s servers", src.Config.Type, dst.Config.Type)
+	}
+
+	srcTables, err := tableNameSet(src)
+	if err != nil {
+		return nil, err
+	}
+	dstTables, err := tableNameSet(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DataDiff{Source: opts.Source, Target: opts.Target}
+	tables := opts.Tables
+	if len(tables) == 0 {
+		tables = sortedKeys(srcTables)
+		for _, name := range sortedKeys(dstTables) {
+			if !srcTables[name] {
+				result.OnlyInTarget = append(result.OnlyInTarget, name)
+			}
+		}
+	}
+
+	var common []string
+	for _, name := range tables {
+		switch {
+		case !srcTables[name]:
+			result.OnlyInTarget = append(result.OnlyInTarget, name)
+		case !dstTables[name]:
+			result.OnlyInSource = append(result.OnlyInSource, name)
+		default:
+			common = append(common, name)
+		}
+	}
+
+	for i, table := range common {
+		if opts.OnProgress != nil {
+			opts.OnProgress(table, i+1, len(common))
+		}
+		td, written, err := compareTableData(src, dst, table, opts)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", table, err)
+		}
+		result.Tables = append(result.Tables, td)
+		result.Statements += written
+	}
+
+	return result, nil
+}
+
+// openDatabase opens a separate connection to a database on this server
+func (c *Connection) openDatabase(name string) (*Connection, error) {
+	cfg := c.Config
+	cfg.Database = name
+	return Connect(cfg)
+}
+
+func tableNameSet(c *Connection) (map[string]bool, error) {
+	tables, err := c.ListTables()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		names[t.Name] = true
+	}
+	return names, nil
+}
+
+// compareTableData checksums a table chunk by chunk and drills into the
+// chunks that differ. It returns the number of statements written.
+func compareTableData(src, dst *Connection, table string, opts DataDiffOptions) (TableDataDiff, int64, error) {
+	td := TableDataDiff{Table: table}
+
+	pk, err := src.PrimaryKey(table)
+	if err != nil {
+		return td, 0, err
+	}
+	if len(pk) == 0 {
+		td.Skipped = "no primary key"
+		return td, 0, nil
+	}
+	dstPK, err := dst.PrimaryKey(table)
+	if err != nil {
+		return td, 0, err
+	}
+	if strings.Join(pk, ",") != strings.Join(dstPK, ",") {
+		td.Skipped = "primary keys differ"
+		return td, 0, nil
+	}
+
+	// Only columns both sides have are compared
+	srcCols, err := src.DescribeTable(table)
+	if err != nil {
+		return td, 0, err
+	}
+	dstCols, err := dst.DescribeTable(table)
+	if err != nil {
+		return td, 0, err
+	}
+	inTarget := make(map[string]bool, len(dstCols))
+	for _, col := range dstCols {
+		inTarget[col.Field] = true
+	}
+	var columns []string
+	for _, col := range srcCols {
+		if inTarget[col.Field] {
+			columns = append(columns, col.Field)
+		}
+	}
+
+	var written int64
+	var lower []string
+	for {
+		upper, err := src.chunkUpperBound(table, pk, lower, opts.ChunkSize)
+		if err != nil {
+			return td, written, err
+		}
+		where, args := src.chunkRange(pk, lower, upper)
+		td.Chunks++
+
+		srcSum, err := src.chunkChecksum(table, columns, where, args)
+		if err != nil {
+			return td, written, err
+		}
+		dstSum, err := dst.chunkChecksum(table, columns, where, args)
+		if err != nil {
+			return td, written, err
+		}
+		if srcSum != dstSum {
+			td.ChunksDiffer++
+			n, err := compareChunkRows(src, dst, &td, pk, columns, where, args, opts)
+			written += n
+			if err != nil {
+				return td, written, err
+			}
+		}
+
+		if upper == nil {
+			break
+		}
+		lower = upper
+	}
+	return td, written, nil
+}
+
+// chunkUpperBound returns the primary key closing the chunk that starts after
+// lower (nil = from the start), or nil when the rest of the table fits in one chunk
+func (c *Connection) chunkUpperBound(table string, pk, lower []string, size int) ([]string, error) {
+	where, args := c.chunkRange(pk, lower, nil)
+	query := fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT 1 OFFSET %d",
+		c.quoteIdentifiers(pk), c.QuoteIdentifier(table), where, c.quoteIdentifiers(pk), size-1)
+
+	rows, err := c.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find chunk boundary: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	upper := make([]string, len(pk))
+	dest := make([]interface{}, len(pk))
+	for i := range upper {
+		dest[i] = &upper[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, fmt.Errorf("failed to scan chunk boundary: %w", err)
+	}
+	return upper, nil
+}
+
+// chunkRange builds the WHERE clause for lower < pk <= upper; nil bounds are open
+func (c *Connection) chunkRange(pk, lower, upper []string) (string, []interface{}) {
+	key := c.quoteIdentifiers(pk)
+	if len(pk) > 1 {
+		key = "(" + key + ")"
+	}
+	tuple := func(n int) string {
+		markers := make([]string, len(pk))
+		for i := range markers {
+			markers[i] = Placeholder(n+i, c.Config.Type)
+		}
+		if len(pk) > 1 {
+			return "(" + strings.Join(markers, ", ") + ")"
+		}
+		return markers[0]
+	}
+
+	var conds []string
+	var args []interface{}
+	if lower != nil {
+		conds = append(conds, key+" > "+tuple(len(args)))
+		args = append(args, BindArgs(lower)...)
+	}
+	if upper != nil {
+		conds = append(conds, key+" <= "+tuple(len(args)))
+		args = append(args, BindArgs(upper)...)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// chunkChecksum returns the row count and checksum of a chunk
+func (c *Connection) chunkChecksum(table string, columns []string, where string, args []interface{}) (string, error) {
+	query := fmt.Sprintf("SELECT COUNT(*), %s FROM %s%s",
+		c.Driver.ChunkChecksumExpr(columns), c.QuoteIdentifier(table), where)
+	var count int64
+	var sum string
+	if err := c.DB.QueryRow(query, args...).Scan(&count, &sum); err != nil {
+		return "", fmt.Errorf("failed to checksum chunk: %w", err)
+	}
+	return fmt.Sprintf("%d/%s", count, sum), nil
+}
+
+// rowHash is a row's primary key and the hash of its compared columns
+type rowHash struct {
+	key  []string
+	hash string
+}
+
+// chunkRowHashes returns the hash of every row in a chunk, by key
+func (c *Connection) chunkRowHashes(table string, pk, columns []string, where string, args []interface{}) (map[string]rowHash, []string, error) {
+	query := fmt.Sprintf("SELECT %s, %s FROM %s%s ORDER BY %s",
+		c.quoteIdentifiers(pk), c.Driver.RowHashExpr(columns), c.QuoteIdentifier(table), where, c.quoteIdentifiers(pk))
+	rows, err := c.DB.Query(query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to hash rows: %w", err)
+	}
+	defer rows.Close()
+
+	hashes := make(map[string]rowHash)
+	var order []string
+	for rows.Next() {
+		r := rowHash{key: make([]string, len(pk))}
+		dest := make([]interface{}, len(pk)+1)
+		for i := range r.key {
+			dest[i] = &r.key[i]
+		}
+		dest[len(pk)] = &r.hash
+		if err := rows.Scan(dest...); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan row hash: %w", err)
+		}
+		k := strings.Join(r.key, "\x00")
+		hashes[k] = r
+		order = append(order, k)
+	}
+	return hashes, order, rows.Err()
+}
+
+// compareChunkRows finds the rows that differ in a chunk and writes the
+// statements that reconcile them
+func compareChunkRows(src, dst *Connection, td *TableDataDiff, pk, columns []string, where string, args []interface{}, opts DataDiffOptions) (int64, error) {
+	srcRows, srcOrder, err := src.chunkRowHashes(td.Table, pk, columns, where, args)
+	if err != nil {
+		return 0, err
+	}
+	dstRows, dstOrder, err := dst.chunkRowHashes(td.Table, pk, columns, where, args)
+	if err != nil {
+		return 0, err
+	}
+
+	record := func(kind RowDiffKind, key []string) {
+		switch kind {
+		case RowMissing:
+			td.Missing++
+		case RowExtra:
+			td.Extra++
+		case RowChanged:
+			td.Changed++
+		}
+		if len(td.Rows) < opts.MaxRows {
+			td.Rows = append(td.Rows, RowDiff{Kind: kind, Key: key})
+		}
+	}
+
+	var upserts []RowDiff
+	for _, k := range srcOrder {
+		s := srcRows[k]
+		d, ok := dstRows[k]
+		switch {
+		case !ok:
+			record(RowMissing, s.key)
+			upserts = append(upserts, RowDiff{Kind: RowMissing, Key: s.key})
+		case d.hash != s.hash:
+			record(RowChanged, s.key)
+			upserts = append(upserts, RowDiff{Kind: RowChanged, Key: s.key})
+		}
+	}
+	var deletes [][]string
+	for _, k := range dstOrder {
+		if _, ok := srcRows[k]; !ok {
+			record(RowExtra, dstRows[k].key)
+			deletes = append(deletes, dstRows[k].key)
+		}
+	}
+
+	if opts.SQL == nil && opts.OnStatement == nil {
+		return 0, nil
+	}
+
+	var written int64
+	write := func(kind RowDiffKind, stmt string) error {
+		written++
+		if opts.SQL != nil {
+			if _, err := io.WriteString(opts.SQL, stmt+";\n"); err != nil {
+				return err
+			}
+		}
+		if opts.OnStatement != nil {
+			return opts.OnStatement(kind, stmt)
+		}
+		return nil
+	}
+	for _, key := range deletes {
+		if err := write(RowExtra, "DELETE FROM "+dst.QuoteIdentifier(td.Table)+dst.literalWhereKey(pk, key)); err != nil {
+			return written, err
+		}
+	}
+	if len(upserts) == 0 {
+		return written, nil
+	}
+
+	values, err := src.chunkRowValues(td.Table, pk, columns, where, args)
+	if err != nil {
+		return written, err
+	}
+	isKey := make(map[string]bool, len(pk))
+	for _, col := range pk {
+		isKey[col] = true
+	}
+	for _, r := range upserts {
+		row, ok := values[strings.Join(r.Key, "\x00")]
+		if !ok {
+			continue // Deleted from the source since it was hashed
+		}
+		var stmt string
+		if r.Kind == RowMissing {
+			literals := make([]string, len(row))
+			for i, v := range row {
+				literals[i] = dst.formatValueForInsert(v)
+			}
+			stmt = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+				dst.QuoteIdentifier(td.Table), dst.quoteIdentifiers(columns), strings.Join(literals, ", "))
+		} else {
+			var sets []string
+			for i, col := range columns {
+				if !isKey[col] {
+					sets = append(sets, dst.QuoteIdentifier(col)+" = "+dst.formatValueForInsert(row[i]))
+				}
+			}
+			if len(sets) == 0 {
+				continue
+			}
+			stmt = fmt.Sprintf("UPDATE %s SET %s%s", dst.QuoteIdentifier(td.Table), strings.Join(sets, ", "), dst.literalWhereKey(pk, r.Key))
+		}
+		if err := write(r.Kind, stmt); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// chunkRowValues returns the compared columns of every row in a chunk, by key
+func (c *Connection) chunkRowValues(table string, pk, columns []string, where string, args []interface{}) (map[string][]interface{}, error) {
+	query := fmt.Sprintf("SELECT %s, %s FROM %s%s",
+		c.quoteIdentifiers(pk), c.quoteIdentifiers(columns), c.QuoteIdentifier(table), where)
+	rows, err := c.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string][]interface{})
+	for rows.Next() {
+		key := make([]string, len(pk))
+		row := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(pk)+len(columns))
+		for i := range key {
+			dest[i] = &key[i]
+		}
+		for i := range row {
+			dest[len(pk)+i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		values[strings.Join(key, "\x00")] = row
+	}
+	return values, rows.Err()
+}
+
+// literalWhereKey builds "WHERE pk1 = 'v1' AND ..." for a generated script
+func (c *Connection) literalWhereKey(pk, key []string) string {
+	conds := make([]string, len(pk))
+	for i, col := range pk {
+		conds[i] = c.QuoteIdentifier(col) + " = " + c.formatValueForInsert(key[i])
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
@@ -0,0 +1,149 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultDatabasePageSize is how many databases one listing page holds
+const DefaultDatabasePageSize = 500
+
+// DatabasePage is one page of a filtered database listing
+type DatabasePage struct {
+	Databases []Database
+	Offset    int // Position of the first database among all that match
+	Total     int // Databases matching the filter across every page
+}
+
+// More reports whether databases past this page match the filter
+func (p *DatabasePage) More() bool {
+	return p.Offset+len(p.Databases) < p.Total
+}
+
+// ListDatabasesPage lists up to limit databases, in name order, whose
+// names contain filter case-insensitively, starting at offset. Filtering
+// and paging happen on the server, so a server with thousands of databases
+// only sends the ones shown. System databases are left out unless the
+// policy shows them.
+func (c *Connection) ListDatabasesPage(filter string, limit, offset int) (*DatabasePage, error) {
+	var hidden []string
+	if !GetSystemDatabasePolicy().Show {
+		hidden = DefaultSystemDatabases(c.Config.Type)
+	}
+	return c.listDatabasesPage(filter, hidden, limit, offset)
+}
+
+// EachDatabase calls fn with every database but the system ones, a page of
+// DefaultDatabasePageSize at a time, stopping at the first error
+func (c *Connection) EachDatabase(fn func(page []Database) error) error {
+	hidden := DefaultSystemDatabases(c.Config.Type)
+	for offset := 0; ; {
+		page, err := c.listDatabasesPage("", hidden, DefaultDatabasePageSize, offset)
+		if err != nil {
+			return err
+		}
+		if len(page.Databases) > 0 {
+			if err := fn(page.Databases); err != nil {
+				return err
+			}
+		}
+		if !page.More() || len(page.Databases) == 0 {
+			return nil
+		}
+		offset += len(page.Databases)
+	}
+}
+
+func (c *Connection) listDatabasesPage(filter string, hidden []string, limit, offset int) (*DatabasePage, error) {
+	if limit <= 0 {
+		limit = DefaultDatabasePageSize
+	}
+	offset = max(offset, 0)
+
+	from, column, args := c.databaseListing(filter, hidden)
+	query := fmt.Sprintf("SELECT %s %s ORDER BY %s LIMIT %d OFFSET %d", column, from, column, limit, offset)
+	rows, err := c.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+	defer rows.Close()
+
+	page := &DatabasePage{Offset: offset}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan database: %w", err)
+		}
+		page.Databases = append(page.Databases, Database{Name: name})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list databases: %w", err)
+	}
+
+	// A short page is the last one, so only a full one needs counting
+	page.Total = offset + len(page.Databases)
+	if len(page.Databases) == limit {
+		if err := c.DB.QueryRow("SELECT COUNT(*) "+from, args...).Scan(&page.Total); err != nil {
+			return nil, fmt.Errorf("failed to count databases: %w", err)
+		}
+	}
+	return page, nil
+}
+
+// databaseListing builds the FROM and WHERE clauses selecting databases
+// whose names contain filter and are not in hidden, with the name column
+// and bound values
+func (c *Connection) databaseListing(filter string, hidden []string) (string, string, []interface{}) {
+	table, column := "information_schema.SCHEMATA", "SCHEMA_NAME"
+	var conds []string
+	like := "LOWER(%s) LIKE LOWER(%s)"
+	dbType := DatabaseTypeMariaDB
+	if isPostgresType(c.Config.Type) {
+		table, column = "pg_database", "datname"
+		conds = []string{"datistemplate = false"}
+		like = "%s ILIKE %s"
+		dbType = DatabaseTypePostgres
+	}
+
+	var args []interface{}
+	if filter != "" {
+		conds = append(conds, fmt.Sprintf(like, column, Placeholder(len(args), dbType)))
+		args = append(args, "%"+escapeLike(filter)+"%")
+	}
+	if len(hidden) > 0 {
+		markers := make([]string, len(hidden))
+		for i, name := range hidden {
+			markers[i] = Placeholder(len(args), dbType)
+			args = append(args, strings.ToLower(name))
+		}
+		conds = append(conds, fmt.Sprintf("LOWER(%s) NOT IN (%s)", column, strings.Join(markers, ", ")))
+	}
+	from := "FROM " + table
+	if len(conds) > 0 {
+		from += " WHERE " + strings.Join(conds, " AND ")
+	}
+	return from, column, args
+}
+
+// escapeLike escapes LIKE wildcards so s matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
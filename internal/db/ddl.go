@@ -0,0 +1,327 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ColumnDef describes a column for CREATE/ALTER TABLE
+type ColumnDef struct {
+	Name          string
+	Type          string // Driver-specific type, e.g. VARCHAR(255)
+	Nullable      bool
+	Default       string // SQL expression such as 'text', 0 or CURRENT_TIMESTAMP (empty = none)
+	PrimaryKey    bool
+	Unique        bool
+	AutoIncrement bool
+
+	Original    string // Name in the existing table (empty = new column)
+	UniqueIndex string // Existing single-column unique index/constraint
+}
+
+// TableDef describes a table for CREATE/ALTER TABLE
+type TableDef struct {
+	Name           string
+	Columns        []ColumnDef
+	PrimaryKeyName string // Existing primary key constraint (ALTER only)
+}
+
+// PrimaryKey returns the names of the primary key columns in column order
+func (t TableDef) PrimaryKey() []string {
+	var pk []string
+	for _, col := range t.Columns {
+		if col.PrimaryKey {
+			pk = append(pk, col.Name)
+		}
+	}
+	return pk
+}
+
+// Validate checks a table definition before building DDL from it
+func (t TableDef) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("table name is required")
+	}
+	if len(t.Columns) == 0 {
+		return fmt.Errorf("a table needs at least one column")
+	}
+
+	seen := make(map[string]bool)
+	for i, col := range t.Columns {
+		name := strings.TrimSpace(col.Name)
+		if name == "" {
+			return fmt.Errorf("column %d has no name", i+1)
+		}
+		if seen[strings.ToLower(name)] {
+			return fmt.Errorf("duplicate column name: %s", name)
+		}
+		seen[strings.ToLower(name)] = true
+
+		if strings.TrimSpace(col.Type) == "" {
+			return fmt.Errorf("column %s has no type", name)
+		}
+		if col.AutoIncrement && !col.PrimaryKey && !col.Unique {
+			return fmt.Errorf("auto-increment column %s must be a primary key or unique", name)
+		}
+		if col.AutoIncrement && col.Default != "" {
+			return fmt.Errorf("auto-increment column %s can't have a default", name)
+		}
+	}
+	return nil
+}
+
+// columnChange is an existing column whose definition changed
+type columnChange struct {
+	From ColumnDef
+	To   ColumnDef
+}
+
+// tableDiff is the difference between an existing table and its new definition
+type tableDiff struct {
+	Renamed       bool
+	Dropped       []ColumnDef
+	Added         []ColumnDef
+	Modified      []columnChange
+	Reordered     bool     // Existing columns changed order
+	AddedInside   bool     // A new column was placed before an existing one
+	PrimaryKey    []string // New primary key columns
+	PKChanged     bool
+	UniqueAdded   []string    // Existing columns that became unique
+	UniqueDropped []ColumnDef // Columns that are no longer unique
+}
+
+// Empty reports whether the diff contains no changes
+func (d tableDiff) Empty() bool {
+	return !d.Renamed && len(d.Dropped) == 0 && len(d.Added) == 0 && len(d.Modified) == 0 &&
+		!d.Reordered && !d.PKChanged && len(d.UniqueAdded) == 0 && len(d.UniqueDropped) == 0
+}
+
+// definitionChanged reports whether anything but the column's position changed
+func definitionChanged(from, to ColumnDef) bool {
+	return from.Name != to.Name ||
+		!strings.EqualFold(strings.TrimSpace(from.Type), strings.TrimSpace(to.Type)) ||
+		from.Nullable != to.Nullable ||
+		from.Default != to.Default ||
+		from.AutoIncrement != to.AutoIncrement
+}
+
+// diffTables compares an existing table with its new definition
+func diffTables(from, to TableDef) tableDiff {
+	d := tableDiff{Renamed: from.Name != to.Name}
+
+	existing := make(map[string]ColumnDef)
+	for _, col := range from.Columns {
+		existing[col.Name] = col
+	}
+
+	kept := make(map[string]bool)
+	var keptOrder []string
+	lastExisting := -1
+	for i, col := range to.Columns {
+		old, ok := existing[col.Original]
+		if col.Original == "" || !ok {
+			// New columns declare UNIQUE inline
+			d.Added = append(d.Added, col)
+			continue
+		}
+		lastExisting = i
+		kept[col.Original] = true
+		keptOrder = append(keptOrder, col.Original)
+
+		if definitionChanged(old, col) {
+			d.Modified = append(d.Modified, columnChange{From: old, To: col})
+		}
+		if col.Unique && !old.Unique {
+			d.UniqueAdded = append(d.UniqueAdded, col.Name)
+		}
+		if !col.Unique && old.Unique {
+			d.UniqueDropped = append(d.UniqueDropped, old)
+		}
+	}
+
+	// New columns placed before an existing column
+	for i, col := range to.Columns {
+		if _, ok := existing[col.Original]; (col.Original == "" || !ok) && i < lastExisting {
+			d.AddedInside = true
+		}
+	}
+
+	var fromOrder []string
+	for _, col := range from.Columns {
+		if kept[col.Name] {
+			fromOrder = append(fromOrder, col.Name)
+		} else {
+			d.Dropped = append(d.Dropped, col)
+		}
+	}
+	for i := range keptOrder {
+		if keptOrder[i] != fromOrder[i] {
+			d.Reordered = true
+			break
+		}
+	}
+
+	oldPK := from.PrimaryKey()
+	d.PrimaryKey = to.PrimaryKey()
+	// Renaming a key column doesn't change the key itself
+	oldPKByOriginal := make([]string, 0, len(d.PrimaryKey))
+	for _, col := range to.Columns {
+		if col.PrimaryKey {
+			oldPKByOriginal = append(oldPKByOriginal, col.Original)
+		}
+	}
+	d.PKChanged = strings.Join(oldPK, "\x00") != strings.Join(oldPKByOriginal, "\x00")
+
+	return d
+}
+
+// ColumnTypes returns the common column types offered by the table designer
+func (c *Connection) ColumnTypes() []string {
+	return c.Driver.ColumnTypes()
+}
+
+// LoadTableDef reads the definition of an existing table
+func (c *Connection) LoadTableDef(tableName string) (TableDef, error) {
+	def := TableDef{Name: tableName}
+
+	rows, err := c.DB.Query(c.Driver.ColumnDefinitionsQuery(tableName))
+	if err != nil {
+		return def, fmt.Errorf("failed to read columns: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var col ColumnDef
+		var nullable, extra string
+		var dflt sql.NullString
+		if err := rows.Scan(&col.Name, &col.Type, &nullable, &dflt, &extra, &col.UniqueIndex); err != nil {
+			return def, fmt.Errorf("failed to scan column: %w", err)
+		}
+		col.Nullable = nullable == "YES"
+		col.AutoIncrement = strings.Contains(strings.ToLower(extra), "auto_increment")
+		col.Unique = col.UniqueIndex != ""
+		if dflt.Valid && !col.AutoIncrement {
+			col.Default = dflt.String
+			// MariaDB reports a missing default on nullable columns as NULL
+			if col.Nullable && strings.EqualFold(col.Default, "NULL") {
+				col.Default = ""
+			}
+		}
+		col.Original = col.Name
+		def.Columns = append(def.Columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return def, err
+	}
+	if len(def.Columns) == 0 {
+		return def, fmt.Errorf("table %s not found", tableName)
+	}
+
+	pk, err := c.PrimaryKey(tableName)
+	if err != nil {
+		return def, err
+	}
+	for _, name := range pk {
+		for i := range def.Columns {
+			if def.Columns[i].Name == name {
+				def.Columns[i].PrimaryKey = true
+			}
+		}
+	}
+
+	if len(pk) > 0 {
+		if err := c.DB.QueryRow(c.Driver.PrimaryKeyNameQuery(tableName)).Scan(&def.PrimaryKeyName); err != nil {
+			return def, fmt.Errorf("failed to get primary key name: %w", err)
+		}
+	}
+
+	return def, nil
+}
+
+// BuildCreateTable builds the CREATE TABLE statement for a definition
+func (c *Connection) BuildCreateTable(def TableDef) (string, error) {
+	if err := def.Validate(); err != nil {
+		return "", err
+	}
+	return c.Driver.CreateTableQuery(def), nil
+}
+
+// GetCreateTable returns the CREATE TABLE statement of an existing table in
+// the current database, as the server (or, on PostgreSQL, the catalog) has it
+func (c *Connection) GetCreateTable(table string) (string, error) {
+	create, err := c.getCreateTable(table)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the CREATE TABLE statement of %s: %w", table, err)
+	}
+	return create, nil
+}
+
+// BuildAlterTable builds the statements that turn table from into to.
+// No statements and no error means there is nothing to change.
+func (c *Connection) BuildAlterTable(from, to TableDef) ([]string, error) {
+	if err := to.Validate(); err != nil {
+		return nil, err
+	}
+	diff := diffTables(from, to)
+	if diff.Empty() {
+		return nil, nil
+	}
+	return c.Driver.AlterTableQueries(from, to, diff)
+}
+
+// ApplyDDL runs DDL statements in order. PostgreSQL runs them in one
+// transaction; MariaDB commits each DDL statement implicitly.
+func (c *Connection) ApplyDDL(statements []string) error {
+	if c.Config.Type != DatabaseTypePostgres {
+		for _, stmt := range statements {
+			if _, err := c.DB.Exec(stmt); err != nil {
+				return fmt.Errorf("failed to apply %q: %w", truncateSQL(stmt), err)
+			}
+		}
+		return nil
+	}
+
+	tx, err := c.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply %q: %w", truncateSQL(stmt), err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// hasColumn reports whether a table definition has a column
+func hasColumn(def TableDef, name string) bool {
+	for _, col := range def.Columns {
+		if col.Name == name {
+			return true
+		}
+	}
+	return false
+}
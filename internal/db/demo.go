@@ -0,0 +1,244 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DemoDatabase is the default name of the demo database
+const DemoDatabase = "ysm_demo"
+
+// demoMarkerTable marks a database as created by SeedDemo, so the demo never
+// touches a database it didn't create
+const demoMarkerTable = "ysm_demo_info"
+
+var demoFirstNames = []string{"Yuno", "Kotonoha", "Ayano", "Shion", "Kaede", "Rena", "Satoko", "Mion",
+	"Lucy", "Tohru", "Akane", "Himiko", "Chika", "Megumi", "Nozomi", "Saya"}
+
+var demoLastNames = []string{"Gasai", "Katsura", "Aishi", "Sonozaki", "Ryuuguu", "Houjou", "Furude", "Kirishima"}
+
+var demoCountries = []string{"JP", "DE", "US", "FR", "BR", "KR", "GB", "SE"}
+
+var demoProducts = []struct {
+	name     string
+	category string
+	price    float64
+}{
+	{"Heart-shaped Locket", "jewelry", 24.99},
+	{"Diary (Future Edition)", "stationery", 12.50},
+	{"Pink Ribbon", "accessories", 3.99},
+	{"Bento Box", "kitchen", 18.00},
+	{"Love Letter Set", "stationery", 7.25},
+	{"Plush Bunny", "toys", 15.00},
+	{"Mechanical Keyboard", "electronics", 89.90},
+	{"Strawberry Cake", "food", 21.40},
+	{"Umbrella for Two", "accessories", 29.00},
+	{"Friendship Bracelet", "jewelry", 9.99},
+	{"Cat Ear Headphones", "electronics", 49.95},
+	{"Matcha Tin", "food", 11.80},
+}
+
+var demoStatuses = []string{"pending", "paid", "shipped", "delivered", "cancelled"}
+
+// IsDemoDatabase reports whether a database exists and was created by SeedDemo
+func (c *Connection) IsDemoDatabase(name string) (bool, error) {
+	exists, err := c.DatabaseExists(name)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	// PostgreSQL only lists the current database's tables, so look from inside it
+	original := c.Config.Database
+	if err := c.UseDatabase(name); err != nil {
+		return false, err
+	}
+	defer c.restoreDatabase(original)
+
+	tables, err := c.ListTables()
+	if err != nil {
+		return false, err
+	}
+	for _, t := range tables {
+		if t.Name == demoMarkerTable {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// restoreDatabase switches back to a previous database. MariaDB can't
+// leave a database once selected, so an empty name is a no-op there.
+func (c *Connection) restoreDatabase(name string) error {
+	if name == "" && c.Config.Type != DatabaseTypePostgres {
+		return nil
+	}
+	return c.UseDatabase(name)
+}
+
+// SeedDemo creates a demo database with sample customers, products and
+// orders. An existing demo database is kept unless reset is set; any other
+// database with the same name is never touched.
+func (c *Connection) SeedDemo(name string, reset bool) (created bool, err error) {
+	exists, err := c.DatabaseExists(name)
+	if err != nil {
+		return false, err
+	}
+
+	if exists {
+		isDemo, err := c.IsDemoDatabase(name)
+		if err != nil {
+			return false, err
+		}
+		if !isDemo {
+			return false, fmt.Errorf("database %s already exists and was not created by ysm demo", name)
+		}
+		if !reset {
+			return false, nil
+		}
+		if err := c.DropDemo(name); err != nil {
+			return false, err
+		}
+	}
+
+	if err := c.CreateDatabase(name); err != nil {
+		return false, err
+	}
+
+	original := c.Config.Database
+	if err := c.UseDatabase(name); err != nil {
+		return false, err
+	}
+	defer c.restoreDatabase(original)
+
+	for _, stmt := range c.demoSchema() {
+		if _, err := c.DB.Exec(stmt); err != nil {
+			return false, fmt.Errorf("failed to create demo schema: %w", err)
+		}
+	}
+	if err := c.seedDemoRows(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DropDemo drops a demo database, refusing databases SeedDemo didn't create
+func (c *Connection) DropDemo(name string) error {
+	isDemo, err := c.IsDemoDatabase(name)
+	if err != nil {
+		return err
+	}
+	if !isDemo {
+		return fmt.Errorf("database %s was not created by ysm demo", name)
+	}
+
+	// PostgreSQL can't drop the database it is connected to
+	if c.Config.Type == DatabaseTypePostgres && c.Config.Database == name {
+		if err := c.UseDatabase("postgres"); err != nil {
+			return err
+		}
+	}
+	return c.DropDatabase(name)
+}
+
+// demoSchema returns the CREATE TABLE statements for the demo database
+func (c *Connection) demoSchema() []string {
+	id := "INT AUTO_INCREMENT PRIMARY KEY"
+	timestamp := "DATETIME"
+	if c.Config.Type == DatabaseTypePostgres {
+		id = "SERIAL PRIMARY KEY"
+		timestamp = "TIMESTAMP"
+	}
+	q := c.QuoteIdentifier
+
+	return []string{
+		fmt.Sprintf("CREATE TABLE %s (%s VARCHAR(64) PRIMARY KEY, %s VARCHAR(255))",
+			q(demoMarkerTable), q("key"), q("value")),
+		fmt.Sprintf("CREATE TABLE %s (%s %s, %s VARCHAR(100) NOT NULL, %s VARCHAR(255) NOT NULL UNIQUE, %s VARCHAR(32), %s CHAR(2), %s %s NOT NULL)",
+			q("customers"), q("id"), id, q("name"), q("email"), q("phone"), q("country"), q("created_at"), timestamp),
+		fmt.Sprintf("CREATE TABLE %s (%s %s, %s VARCHAR(100) NOT NULL, %s VARCHAR(50), %s DECIMAL(10,2) NOT NULL, %s INT NOT NULL)",
+			q("products"), q("id"), id, q("name"), q("category"), q("price"), q("stock")),
+		fmt.Sprintf("CREATE TABLE %s (%s %s, %s INT NOT NULL, %s INT NOT NULL, %s INT NOT NULL, %s DECIMAL(10,2) NOT NULL, %s VARCHAR(20) NOT NULL, %s %s NOT NULL, "+
+			"FOREIGN KEY (%s) REFERENCES %s (%s), FOREIGN KEY (%s) REFERENCES %s (%s))",
+			q("orders"), q("id"), id, q("customer_id"), q("product_id"), q("quantity"), q("total"), q("status"), q("ordered_at"), timestamp,
+			q("customer_id"), q("customers"), q("id"), q("product_id"), q("products"), q("id")),
+	}
+}
+
+// seedDemoRows inserts deterministic sample data into the current database
+func (c *Connection) seedDemoRows() error {
+	now := time.Now().Truncate(time.Second)
+	base := now.AddDate(0, -3, 0)
+
+	rows := [][]string{
+		{"created_by", "ysm demo"},
+		{"created_at", now.Format("2006-01-02 15:04:05")},
+	}
+	for _, r := range rows {
+		if err := c.InsertRow(demoMarkerTable, []string{"key", "value"}, r); err != nil {
+			return err
+		}
+	}
+
+	customers := 24
+	for i := 0; i < customers; i++ {
+		first := demoFirstNames[i%len(demoFirstNames)]
+		last := demoLastNames[(i*3)%len(demoLastNames)]
+		// Reserved example domains and 555 numbers are never real people
+		email := fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), i+1)
+		phone := fmt.Sprintf("+1-555-%04d", 100+i*37)
+		if i%7 == 6 {
+			phone = NullParam
+		}
+		created := base.Add(time.Duration(i) * 26 * time.Hour).Format("2006-01-02 15:04:05")
+		err := c.InsertRow("customers",
+			[]string{"name", "email", "phone", "country", "created_at"},
+			[]string{first + " " + last, email, phone, demoCountries[i%len(demoCountries)], created})
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, p := range demoProducts {
+		err := c.InsertRow("products",
+			[]string{"name", "category", "price", "stock"},
+			[]string{p.name, p.category, fmt.Sprintf("%.2f", p.price), fmt.Sprint((i*17 + 5) % 60)})
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < 80; i++ {
+		product := (i*5)%len(demoProducts) + 1
+		quantity := i%3 + 1
+		price := demoProducts[product-1].price
+		ordered := base.Add(time.Duration(i) * 27 * time.Hour).Format("2006-01-02 15:04:05")
+		err := c.InsertRow("orders",
+			[]string{"customer_id", "product_id", "quantity", "total", "status", "ordered_at"},
+			[]string{fmt.Sprint((i*7)%customers + 1), fmt.Sprint(product), fmt.Sprint(quantity),
+				fmt.Sprintf("%.2f", price*float64(quantity)), demoStatuses[(i*3)%len(demoStatuses)], ordered})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
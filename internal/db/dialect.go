@@ -0,0 +1,922 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OutputDialect is the SQL dialect an export is written in, for one-way
+// handoffs to teams on other engines
+type OutputDialect string
+
+const (
+	DialectNative    OutputDialect = ""          // The source server's own dialect
+	DialectSQLServer OutputDialect = "sqlserver" // Microsoft SQL Server 2016+
+	DialectOracle    OutputDialect = "oracle"    // Oracle Database 12c+
+)
+
+// ParseOutputDialect parses a dialect name such as sqlserver, mssql or oracle
+func ParseOutputDialect(name string) (OutputDialect, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "native":
+		return DialectNative, nil
+	case "sqlserver", "mssql", "tsql":
+		return DialectSQLServer, nil
+	case "oracle":
+		return DialectOracle, nil
+	}
+	return "", fmt.Errorf("unknown dialect: %s (use: sqlserver, oracle)", name)
+}
+
+// String returns the dialect's display name
+func (d OutputDialect) String() string {
+	switch d {
+	case DialectSQLServer:
+		return "SQL Server"
+	case DialectOracle:
+		return "Oracle"
+	}
+	return "native"
+}
+
+// DialectIssue is something the target engine can't take as-is
+type DialectIssue struct {
+	Table   string // Empty for issues that aren't about one table
+	Column  string
+	Message string
+}
+
+// String formats the issue with its location
+func (i DialectIssue) String() string {
+	switch {
+	case i.Column != "":
+		return fmt.Sprintf("%s.%s: %s", i.Table, i.Column, i.Message)
+	case i.Table != "":
+		return fmt.Sprintf("%s: %s", i.Table, i.Message)
+	}
+	return i.Message
+}
+
+// dialectWriter renders DDL and DML for a foreign dialect, collecting what
+// doesn't translate into an incompatibility report
+type dialectWriter struct {
+	dialect    OutputDialect
+	converters *ValueConverters // Chosen by the user for what YSM doesn't convert (nil = none)
+	issues     []DialectIssue
+	seen       map[string]bool // Issues already reported
+	names      map[string]bool // Schema-wide object names used so far (Oracle)
+}
+
+func newDialectWriter(dialect OutputDialect) *dialectWriter {
+	return &dialectWriter{
+		dialect: dialect,
+		seen:    make(map[string]bool),
+		names:   make(map[string]bool),
+	}
+}
+
+func (w *dialectWriter) note(table, column, format string, args ...interface{}) {
+	issue := DialectIssue{Table: table, Column: column, Message: fmt.Sprintf(format, args...)}
+	if key := issue.String(); !w.seen[key] {
+		w.seen[key] = true
+		w.issues = append(w.issues, issue)
+	}
+}
+
+// quote quotes an identifier. Oracle names are upper-cased so they can be
+// used unquoted on the other side.
+func (w *dialectWriter) quote(name string) string {
+	if w.dialect == DialectSQLServer {
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	}
+	return `"` + strings.ReplaceAll(strings.ToUpper(name), `"`, `""`) + `"`
+}
+
+func (w *dialectWriter) quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = w.quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// objectName returns a name for an index or constraint. Oracle scopes these
+// names to the schema rather than the table, so clashes get the table prefixed.
+func (w *dialectWriter) objectName(table, name string) string {
+	if w.dialect != DialectOracle {
+		return name
+	}
+	key := strings.ToUpper(name)
+	if w.names[key] {
+		name = table + "_" + name
+		key = strings.ToUpper(name)
+	}
+	if len(name) > 128 {
+		w.note(table, "", "name %s is longer than Oracle's 128 characters and was shortened", name)
+		name = name[:128]
+		key = strings.ToUpper(name)
+	}
+	w.names[key] = true
+	return name
+}
+
+func (w *dialectWriter) header() string {
+	if w.dialect == DialectSQLServer {
+		return "SET NOCOUNT ON;\nSET XACT_ABORT ON;\n"
+	}
+	// SQL*Plus would treat & in string values as a substitution variable
+	return "SET DEFINE OFF;\n" +
+		"ALTER SESSION SET NLS_DATE_FORMAT = 'YYYY-MM-DD HH24:MI:SS';\n" +
+		"ALTER SESSION SET NLS_TIMESTAMP_FORMAT = 'YYYY-MM-DD HH24:MI:SS.FF';\n" +
+		"ALTER SESSION SET NLS_TIMESTAMP_TZ_FORMAT = 'YYYY-MM-DD HH24:MI:SS.FFTZH:TZM';\n"
+}
+
+func (w *dialectWriter) dropTable(table string) string {
+	if w.dialect == DialectSQLServer {
+		return fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", w.quote(table))
+	}
+	// ORA-00942 (table does not exist) is fine; Oracle has no IF EXISTS before 23c
+	return fmt.Sprintf("BEGIN\n  EXECUTE IMMEDIATE 'DROP TABLE %s CASCADE CONSTRAINTS';\n"+
+		"EXCEPTION WHEN OTHERS THEN\n  IF SQLCODE != -942 THEN RAISE; END IF;\nEND;\n/\n",
+		strings.ReplaceAll(w.quote(table), "'", "''"))
+}
+
+// columnType describes a source column type
+type columnType struct {
+	base     string // Lower-case type name without arguments, e.g. varchar or timestamp with time zone
+	args     []string
+	unsigned bool
+	array    bool
+}
+
+var columnTypeRe = regexp.MustCompile(`^([a-z ]+?)\s*(?:\((.*)\))?((?:\s+[a-z ]+)*)$`)
+
+func parseColumnType(sqlType string) columnType {
+	t := strings.ToLower(strings.TrimSpace(sqlType))
+	ct := columnType{base: t}
+	if strings.HasSuffix(t, "[]") {
+		ct.array = true
+		t = strings.TrimSuffix(t, "[]")
+	}
+	m := columnTypeRe.FindStringSubmatch(t)
+	if m == nil {
+		return ct
+	}
+	ct.base = strings.TrimSpace(m[1])
+	if m[2] != "" {
+		if strings.HasPrefix(ct.base, "enum") || strings.HasPrefix(ct.base, "set") {
+			ct.args = []string{m[2]}
+		} else {
+			for _, arg := range strings.Split(m[2], ",") {
+				ct.args = append(ct.args, strings.TrimSpace(arg))
+			}
+		}
+	}
+	suffix := strings.TrimSpace(m[3])
+	ct.unsigned = strings.Contains(suffix, "unsigned")
+	// PostgreSQL puts the time zone after the precision: timestamp(3) with time zone
+	if strings.Contains(suffix, "with time zone") {
+		ct.base += " with time zone"
+	}
+	return ct
+}
+
+func (ct columnType) arg(i int) int {
+	if i >= len(ct.args) {
+		return 0
+	}
+	n, _ := strconv.Atoi(ct.args[i])
+	return n
+}
+
+// mapType translates a column type, returning the target type and any
+// column CHECK it needs (for enums)
+func (w *dialectWriter) mapType(table string, col ColumnDef) (string, string) {
+	ct := parseColumnType(col.Type)
+	mssql := w.dialect == DialectSQLServer
+	pick := func(sqlServer, oracle string) string {
+		if mssql {
+			return sqlServer
+		}
+		return oracle
+	}
+	text := func(n int) string {
+		switch {
+		case n <= 0:
+			return pick("NVARCHAR(MAX)", "CLOB")
+		case mssql && n <= 4000:
+			return fmt.Sprintf("NVARCHAR(%d)", n)
+		case !mssql && n <= 4000:
+			return fmt.Sprintf("VARCHAR2(%d CHAR)", n)
+		}
+		return pick("NVARCHAR(MAX)", "CLOB")
+	}
+	unsupported := func(target string) string {
+		w.note(table, col.Name, "%s has no %s equivalent, stored as %s", col.Type, w.dialect, target)
+		return target
+	}
+
+	if ct.array {
+		return unsupported(pick("NVARCHAR(MAX)", "CLOB")), ""
+	}
+
+	switch ct.base {
+	case "bool", "boolean", "bit":
+		if ct.base == "bit" && ct.arg(0) > 1 {
+			return pick("BINARY", "RAW") + fmt.Sprintf("(%d)", (ct.arg(0)+7)/8), ""
+		}
+		return pick("BIT", "NUMBER(1)"), ""
+	case "tinyint":
+		if ct.arg(0) == 1 {
+			return pick("BIT", "NUMBER(1)"), ""
+		}
+		if ct.unsigned {
+			return pick("TINYINT", "NUMBER(3)"), ""
+		}
+		return pick("SMALLINT", "NUMBER(3)"), ""
+	case "smallint", "int2":
+		if ct.unsigned {
+			return pick("INT", "NUMBER(5)"), ""
+		}
+		return pick("SMALLINT", "NUMBER(5)"), ""
+	case "mediumint":
+		return pick("INT", "NUMBER(8)"), ""
+	case "int", "integer", "int4":
+		if ct.unsigned {
+			return pick("BIGINT", "NUMBER(10)"), ""
+		}
+		return pick("INT", "NUMBER(10)"), ""
+	case "bigint", "int8":
+		if ct.unsigned {
+			return pick("DECIMAL(20, 0)", "NUMBER(20)"), ""
+		}
+		return pick("BIGINT", "NUMBER(19)"), ""
+	case "decimal", "numeric", "dec", "fixed":
+		p, s := ct.arg(0), ct.arg(1)
+		if p == 0 {
+			return pick("DECIMAL(38, 10)", "NUMBER"), ""
+		}
+		if p > 38 {
+			w.note(table, col.Name, "precision %d exceeds the maximum of 38 and was reduced", p)
+			p = 38
+		}
+		return fmt.Sprintf("%s(%d, %d)", pick("DECIMAL", "NUMBER"), p, s), ""
+	case "float", "real", "float4":
+		return pick("REAL", "BINARY_FLOAT"), ""
+	case "double", "double precision", "float8":
+		return pick("FLOAT", "BINARY_DOUBLE"), ""
+	case "money":
+		return pick("MONEY", "NUMBER(19, 4)"), ""
+	case "char", "character", "nchar", "bpchar":
+		n := max(ct.arg(0), 1)
+		if n > 2000 {
+			return text(n), ""
+		}
+		return fmt.Sprintf(pick("NCHAR(%d)", "CHAR(%d CHAR)"), n), ""
+	case "varchar", "character varying", "nvarchar", "varchar2":
+		return text(ct.arg(0)), ""
+	case "text", "tinytext", "mediumtext", "longtext", "citext", "clob":
+		if ct.base == "tinytext" {
+			return text(255), ""
+		}
+		return text(0), ""
+	case "json", "jsonb":
+		w.note(table, col.Name, "%s stored as text; add a %s check to validate it", col.Type, pick("ISJSON()", "IS JSON"))
+		return text(0), ""
+	case "xml":
+		return pick("XML", "XMLTYPE"), ""
+	case "binary":
+		n := max(ct.arg(0), 1)
+		return fmt.Sprintf(pick("BINARY(%d)", "RAW(%d)"), n), ""
+	case "varbinary":
+		if n := ct.arg(0); n > 0 && n <= 2000 {
+			return fmt.Sprintf(pick("VARBINARY(%d)", "RAW(%d)"), n), ""
+		}
+		return pick("VARBINARY(MAX)", "BLOB"), ""
+	case "blob", "tinyblob", "mediumblob", "longblob", "bytea":
+		return pick("VARBINARY(MAX)", "BLOB"), ""
+	case "date":
+		return "DATE", ""
+	case "datetime", "timestamp", "timestamp without time zone", "smalldatetime":
+		return pick("DATETIME2", "TIMESTAMP"), ""
+	case "timestamptz", "timestamp with time zone":
+		return pick("DATETIMEOFFSET", "TIMESTAMP WITH TIME ZONE"), ""
+	case "time", "time without time zone":
+		if mssql {
+			return "TIME", ""
+		}
+		return unsupported("VARCHAR2(16 CHAR)"), ""
+	case "year":
+		return pick("SMALLINT", "NUMBER(4)"), ""
+	case "uuid":
+		if mssql {
+			return "UNIQUEIDENTIFIER", ""
+		}
+		return "CHAR(36 CHAR)", ""
+	case "enum":
+		labels := splitEnumLabels(ct.args)
+		longest := 1
+		for _, label := range labels {
+			longest = max(longest, len([]rune(label)))
+		}
+		quoted := make([]string, len(labels))
+		for i, label := range labels {
+			quoted[i] = "'" + strings.ReplaceAll(label, "'", "''") + "'"
+		}
+		check := fmt.Sprintf("CHECK (%s IN (%s))", w.quote(col.Name), strings.Join(quoted, ", "))
+		return text(longest), check
+	case "set":
+		return unsupported(text(0)), ""
+	}
+	return unsupported(text(0)), ""
+}
+
+// columnType maps a column's type, or lets the converter registered for its
+// source type choose one
+func (w *dialectWriter) columnType(table string, col ColumnDef) (string, string) {
+	conv := w.converters.lookup(col.Type, w.dialect)
+	if conv == nil || conv.Type == nil {
+		return w.mapType(table, col)
+	}
+	mapType := func(sourceType string) string {
+		sqlType, _ := w.mapType(table, ColumnDef{Name: col.Name, Type: sourceType})
+		return sqlType
+	}
+	return conv.Type(ConverterColumn{Table: table, Column: col.Name, Type: col.Type, Dialect: w.dialect}, mapType), ""
+}
+
+// splitEnumLabels splits the quoted labels of an ENUM('a','b') type
+func splitEnumLabels(args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	var labels []string
+	var cur strings.Builder
+	in := false
+	s := args[0]
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '\'' && in && i+1 < len(s) && s[i+1] == '\'':
+			cur.WriteByte('\'')
+			i++
+		case ch == '\'':
+			if in {
+				labels = append(labels, cur.String())
+				cur.Reset()
+			}
+			in = !in
+		case in:
+			cur.WriteByte(ch)
+		}
+	}
+	return labels
+}
+
+var (
+	pgCastRe        = regexp.MustCompile(`::[a-z_ ]+(?:\([0-9, ]*\))?(?:\[\])?`)
+	nowDefaultRe    = regexp.MustCompile(`(?i)^(?:current_timestamp|now|localtimestamp|sysdate)(?:\(\s*\d*\s*\))?$`)
+	numberDefaultRe = regexp.MustCompile(`^-?\d+(?:\.\d+)?$`)
+	stringDefaultRe = regexp.MustCompile(`^'(?:[^']|'')*'$`)
+	uuidDefaultRe   = regexp.MustCompile(`(?i)^(?:gen_random_uuid|uuid_generate_v4|uuid)\(\)$`)
+)
+
+// translateDefault rewrites a column default, or drops it with a note
+func (w *dialectWriter) translateDefault(table string, col ColumnDef, targetType string) string {
+	expr := strings.TrimSpace(col.Default)
+	if expr == "" || strings.EqualFold(expr, "NULL") {
+		return ""
+	}
+	expr = strings.TrimSpace(pgCastRe.ReplaceAllString(expr, ""))
+	if strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+
+	switch {
+	case stringDefaultRe.MatchString(expr), numberDefaultRe.MatchString(expr):
+		if w.dialect == DialectOracle && expr == "''" {
+			w.note(table, col.Name, "default '' is NULL in Oracle")
+		}
+		return expr
+	case strings.EqualFold(expr, "true"), strings.EqualFold(expr, "b'1'"):
+		return "1"
+	case strings.EqualFold(expr, "false"), strings.EqualFold(expr, "b'0'"):
+		return "0"
+	case strings.EqualFold(expr, "current_date"), strings.EqualFold(expr, "curdate()"):
+		if w.dialect == DialectSQLServer {
+			return "CAST(GETDATE() AS DATE)"
+		}
+		return "TRUNC(SYSDATE)"
+	case nowDefaultRe.MatchString(expr):
+		if w.dialect == DialectSQLServer {
+			if strings.HasPrefix(targetType, "DATETIMEOFFSET") {
+				return "SYSDATETIMEOFFSET()"
+			}
+			return "SYSDATETIME()"
+		}
+		return "SYSTIMESTAMP"
+	case uuidDefaultRe.MatchString(expr) && w.dialect == DialectSQLServer:
+		return "NEWID()"
+	}
+
+	w.note(table, col.Name, "default %s has no %s translation and was dropped", col.Default, w.dialect)
+	return ""
+}
+
+// createTable renders CREATE TABLE for a loaded table definition
+func (w *dialectWriter) createTable(def TableDef) string {
+	var lines []string
+	for _, col := range def.Columns {
+		sqlType, check := w.columnType(def.Name, col)
+		line := "  " + w.quote(col.Name) + " " + sqlType
+		if col.AutoIncrement {
+			if w.dialect == DialectSQLServer {
+				line += " IDENTITY(1, 1)"
+			} else {
+				line += " GENERATED BY DEFAULT AS IDENTITY"
+			}
+		} else if dflt := w.translateDefault(def.Name, col, sqlType); dflt != "" {
+			line += " DEFAULT " + dflt
+		}
+		if !col.Nullable {
+			line += " NOT NULL"
+		} else if w.dialect == DialectSQLServer {
+			line += " NULL"
+		}
+		if check != "" {
+			line += " " + check
+		}
+		lines = append(lines, line)
+	}
+	if pk := def.PrimaryKey(); len(pk) > 0 {
+		name := w.objectName(def.Name, "pk_"+def.Name)
+		lines = append(lines, fmt.Sprintf("  CONSTRAINT %s PRIMARY KEY (%s)", w.quote(name), w.quoteList(pk)))
+	}
+	return fmt.Sprintf("CREATE TABLE %s (\n%s\n);\n", w.quote(def.Name), strings.Join(lines, ",\n"))
+}
+
+// createIndex renders a secondary index, or "" when it can't be translated
+func (w *dialectWriter) createIndex(idx Index) string {
+	if idx.Primary {
+		return ""
+	}
+	switch strings.ToLower(idx.Type) {
+	case "", "btree", "hash":
+	default:
+		w.note(idx.Table, "", "%s index %s was skipped", idx.Type, idx.Name)
+		return ""
+	}
+	for _, col := range idx.Columns {
+		if strings.ContainsAny(col, "( ") {
+			w.note(idx.Table, "", "expression index %s was skipped", idx.Name)
+			return ""
+		}
+	}
+	unique := ""
+	if idx.Unique {
+		unique = "UNIQUE "
+	}
+	name := w.objectName(idx.Table, idx.Name)
+	return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s);\n", unique, w.quote(name), w.quote(idx.Table), w.quoteList(idx.Columns))
+}
+
+// foreignKey renders a foreign key as ALTER TABLE, written after all data
+func (w *dialectWriter) foreignKey(fk ForeignKey) string {
+	stmt := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		w.quote(fk.Table), w.quote(w.objectName(fk.Table, fk.Name)), w.quoteList(fk.Columns),
+		w.quote(fk.RefTable), w.quoteList(fk.RefColumns))
+
+	switch strings.ToUpper(fk.OnDelete) {
+	case "CASCADE", "SET NULL":
+		stmt += " ON DELETE " + strings.ToUpper(fk.OnDelete)
+	case "SET DEFAULT":
+		if w.dialect == DialectSQLServer {
+			stmt += " ON DELETE SET DEFAULT"
+		} else {
+			w.note(fk.Table, "", "foreign key %s: ON DELETE SET DEFAULT is not supported", fk.Name)
+		}
+	}
+	switch onUpdate := strings.ToUpper(fk.OnUpdate); onUpdate {
+	case "", "NO ACTION", "RESTRICT":
+	default:
+		if w.dialect == DialectSQLServer {
+			stmt += " ON UPDATE " + onUpdate
+		} else {
+			w.note(fk.Table, "", "foreign key %s: ON UPDATE %s is not supported", fk.Name, fk.OnUpdate)
+		}
+	}
+	return stmt + ";\n"
+}
+
+// oracleStringChunk is the longest string literal Oracle accepts in SQL
+const oracleStringChunk = 4000
+
+// formatValue renders a value as a literal in the target dialect
+func (w *dialectWriter) formatValue(table, column string, val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		if containsBinaryData(v) {
+			if w.dialect == DialectSQLServer {
+				return fmt.Sprintf("0x%X", v)
+			}
+			if len(v) > oracleStringChunk/2 {
+				w.note(table, column, "binary values over %d bytes can't be written as SQL literals and were exported as NULL", oracleStringChunk/2)
+				return "NULL"
+			}
+			return fmt.Sprintf("HEXTORAW('%X')", v)
+		}
+		return w.formatString(table, column, string(v))
+	case string:
+		return w.formatString(table, column, v)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case time.Time:
+		if v.IsZero() {
+			w.note(table, column, "zero dates (0000-00-00) were written as 0001-01-01; the zero-date-null converter writes NULL instead")
+		}
+		if w.dialect == DialectSQLServer {
+			return "'" + v.Format("2006-01-02T15:04:05.999999") + "'"
+		}
+		return "TIMESTAMP '" + v.Format("2006-01-02 15:04:05.999999") + "'"
+	}
+	return w.formatString(table, column, fmt.Sprintf("%v", val))
+}
+
+func (w *dialectWriter) formatString(table, column, s string) string {
+	if w.dialect == DialectSQLServer {
+		return "N'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	if s == "" {
+		w.note(table, column, "empty strings become NULL in Oracle")
+	}
+	if len(s) <= oracleStringChunk {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	// Longer values are built from CLOB pieces, split on rune boundaries
+	var parts []string
+	for len(s) > 0 {
+		n := min(len(s), oracleStringChunk/4)
+		for n < len(s) && n > 0 && !isRuneStart(s[n]) {
+			n--
+		}
+		parts = append(parts, "TO_CLOB('"+strings.ReplaceAll(s[:n], "'", "''")+"')")
+		s = s[n:]
+	}
+	return strings.Join(parts, " || ")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// maxInsertRows caps multi-row inserts; SQL Server allows 1000 rows per VALUES
+const maxInsertRows = 1000
+
+// insertRows renders rows as INSERTs. Oracle gets one statement per row,
+// since multi-row VALUES only arrived in 23c.
+func (w *dialectWriter) insertRows(table string, columns []string, rows []string) string {
+	var b strings.Builder
+	if w.dialect == DialectSQLServer {
+		for start := 0; start < len(rows); start += maxInsertRows {
+			end := min(start+maxInsertRows, len(rows))
+			fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES\n%s;\n\n", w.quote(table), w.quoteList(columns), strings.Join(rows[start:end], ",\n"))
+		}
+		return b.String()
+	}
+	for _, row := range rows {
+		fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES %s;\n", w.quote(table), w.quoteList(columns), row)
+	}
+	b.WriteString("COMMIT;\n\n")
+	return b.String()
+}
+
+// identityLoad returns the statements around a data load into a table with
+// an identity column: SQL Server needs IDENTITY_INSERT on, and Oracle
+// needs the identity moved past the loaded values afterwards
+func (w *dialectWriter) identityLoad(table, column string) (before, after string) {
+	if w.dialect == DialectSQLServer {
+		return fmt.Sprintf("SET IDENTITY_INSERT %s ON;\n", w.quote(table)),
+			fmt.Sprintf("SET IDENTITY_INSERT %s OFF;\n\n", w.quote(table))
+	}
+	return "", fmt.Sprintf("ALTER TABLE %s MODIFY %s GENERATED BY DEFAULT AS IDENTITY (START WITH LIMIT VALUE);\n\n",
+		w.quote(table), w.quote(column))
+}
+
+// report renders the incompatibility report as SQL comments
+func (w *dialectWriter) report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "-- --------------------------------------------------------\n")
+	fmt.Fprintf(&b, "-- %s incompatibility report\n", w.dialect)
+	fmt.Fprintf(&b, "-- --------------------------------------------------------\n")
+	if len(w.issues) == 0 {
+		b.WriteString("-- Everything translated cleanly\n")
+	}
+	for _, issue := range w.issues {
+		fmt.Fprintf(&b, "-- %s\n", issue)
+	}
+	return b.String()
+}
+
+// exportTableDialect writes one table's structure and data in the output
+// dialect, returning the number of rows written
+func (c *Connection) exportTableDialect(writer *bufio.Writer, tableName string, opts ExportOptions, w *dialectWriter) (int64, error) {
+	def, err := c.LoadTableDef(tableName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read structure of %s: %w", tableName, err)
+	}
+
+	if !opts.NoCreate {
+		if opts.AddDropTable {
+			writer.WriteString(w.dropTable(tableName))
+		}
+		writer.WriteString(w.createTable(def))
+
+		indexes, err := c.ListIndexes(tableName)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list indexes of %s: %w", tableName, err)
+		}
+		for _, idx := range indexes {
+			writer.WriteString(w.createIndex(idx))
+		}
+		writer.WriteString("\n")
+
+		// CHECK clauses are in the source engine's expression syntax
+		if checks, err := c.ListCheckConstraints(tableName); err == nil {
+			for _, check := range checks {
+				w.note(tableName, "", "check constraint %s (%s) was not exported", check.Name, check.Clause)
+			}
+		}
+	}
+
+	if opts.NoData {
+		return 0, nil
+	}
+
+	var before, after string
+	for _, col := range def.Columns {
+		if col.AutoIncrement {
+			before, after = w.identityLoad(tableName, col.Name)
+		}
+	}
+
+	query, err := c.exportSelectQuery(tableName, opts.SampleRows)
+	if err != nil {
+		return 0, fmt.Errorf("failed to export data for %s: %w", tableName, err)
+	}
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to export data for %s: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	valuePtrs := make([]interface{}, len(columns))
+	valueHolders := make([]interface{}, len(columns))
+	for i := range valuePtrs {
+		valuePtrs[i] = &valueHolders[i]
+	}
+	masks := opts.Masking.columnMasks(tableName, columns)
+	convs, convCols := w.converters.rowConverters(def, w.dialect, columns)
+
+	fmt.Fprintf(writer, "-- Dumping data for table %s\n\n", w.quote(tableName))
+	writer.WriteString(before)
+
+	var rowCount int64
+	batch := make([]string, 0, opts.BatchSize)
+	rowValues := make([]string, len(columns))
+	for rows.Next() {
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return rowCount, err
+		}
+		for i, val := range valueHolders {
+			if masks != nil && masks[i] != nil {
+				val = masks[i].Apply(val)
+			}
+			if convs != nil && convs[i] != nil {
+				if val, err = convs[i].Convert(convCols[i], val); err != nil {
+					return rowCount, fmt.Errorf("failed to convert %s.%s (%s) for %s: %w", tableName, columns[i], convCols[i].Type, w.dialect, err)
+				}
+			}
+			rowValues[i] = w.formatValue(tableName, columns[i], val)
+		}
+		batch = append(batch, "("+strings.Join(rowValues, ", ")+")")
+		rowCount++
+
+		if len(batch) >= opts.BatchSize {
+			writer.WriteString(w.insertRows(tableName, columns, batch))
+			batch = batch[:0]
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return rowCount, fmt.Errorf("failed to export data for %s: %w", tableName, err)
+	}
+	if len(batch) > 0 {
+		writer.WriteString(w.insertRows(tableName, columns, batch))
+	}
+	writer.WriteString(after)
+
+	return rowCount, nil
+}
+
+// writeDialectForeignKeys adds the foreign keys once every table and its
+// rows are in place, so load order doesn't matter
+func (c *Connection) writeDialectForeignKeys(writer *bufio.Writer, tables []string, w *dialectWriter) error {
+	exported := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		exported[table] = true
+	}
+
+	var statements []string
+	for _, table := range tables {
+		fks, err := c.ListForeignKeys(table)
+		if err != nil {
+			return fmt.Errorf("failed to list foreign keys of %s: %w", table, err)
+		}
+		for _, fk := range fks {
+			if !exported[fk.RefTable] {
+				w.note(table, "", "foreign key %s references %s, which is not in this export, and was skipped", fk.Name, fk.RefTable)
+				continue
+			}
+			statements = append(statements, w.foreignKey(fk))
+		}
+	}
+	if len(statements) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(writer, "-- --------------------------------------------------------\n")
+	fmt.Fprintf(writer, "-- Foreign keys\n")
+	fmt.Fprintf(writer, "-- --------------------------------------------------------\n\n")
+	for _, stmt := range statements {
+		writer.WriteString(stmt)
+	}
+	return nil
+}
+
+// Query translation
+var (
+	limitRe       = regexp.MustCompile(`(?is)\s+LIMIT\s+(\d+)(?:\s*,\s*(\d+)|\s+OFFSET\s+(\d+))?\s*;?\s*$`)
+	selectStartRe = regexp.MustCompile(`(?i)^(\s*SELECT\s+(?:DISTINCT\s+)?)`)
+	orderByRe     = regexp.MustCompile(`(?i)\bORDER\s+BY\b`)
+	wordRe        = regexp.MustCompile(`(?i)\b(?:TRUE|FALSE|IFNULL|ILIKE|RETURNING)\b|\b(?:NOW|CURRENT_TIMESTAMP)\(\)`)
+	castRe        = regexp.MustCompile(`::`)
+	upsertRe      = regexp.MustCompile(`(?i)\bON\s+(?:DUPLICATE\s+KEY|CONFLICT)\b`)
+)
+
+// TranslateQuery rewrites a statement for another engine: identifier
+// quoting, LIMIT/OFFSET, booleans and common functions. Constructs it can't
+// rewrite are returned as issues for manual review.
+func TranslateQuery(query string, dialect OutputDialect) (string, []DialectIssue) {
+	if dialect == DialectNative {
+		return query, nil
+	}
+	w := newDialectWriter(dialect)
+
+	// Rewrite outside string literals only
+	var out strings.Builder
+	for i := 0; i < len(query); {
+		ch := query[i]
+		switch ch {
+		case '\'':
+			end := i + 1
+			for end < len(query) {
+				if query[end] == '\'' {
+					if end+1 < len(query) && query[end+1] == '\'' {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			end = min(end+1, len(query))
+			lit := query[i:end]
+			if dialect == DialectSQLServer {
+				out.WriteByte('N')
+			}
+			out.WriteString(lit)
+			i = end
+		case '`', '"':
+			end := strings.IndexByte(query[i+1:], ch)
+			if end < 0 {
+				out.WriteString(query[i:])
+				i = len(query)
+				continue
+			}
+			out.WriteString(w.quote(query[i+1 : i+1+end]))
+			i += end + 2
+		default:
+			next := len(query)
+			if j := strings.IndexAny(query[i:], "'`\""); j >= 0 {
+				next = i + j
+			}
+			out.WriteString(w.translateWords(query[i:next]))
+			i = next
+		}
+	}
+	translated := out.String()
+
+	if m := limitRe.FindStringSubmatchIndex(translated); m != nil {
+		count := translated[m[2]:m[3]]
+		offset := ""
+		switch {
+		case m[4] >= 0: // LIMIT offset, count
+			offset, count = count, translated[m[4]:m[5]]
+		case m[6] >= 0:
+			offset = translated[m[6]:m[7]]
+		}
+		head := translated[:m[0]]
+		switch {
+		case offset == "" && dialect == DialectOracle:
+			translated = head + " FETCH FIRST " + count + " ROWS ONLY"
+		case offset == "" && selectStartRe.MatchString(head):
+			translated = selectStartRe.ReplaceAllString(head, "${1}TOP "+count+" ")
+		default:
+			if offset == "" {
+				offset = "0"
+			}
+			if dialect == DialectSQLServer && !orderByRe.MatchString(head) {
+				head += " ORDER BY (SELECT NULL)"
+				w.note("", "", "OFFSET needs ORDER BY in SQL Server; added ORDER BY (SELECT NULL), so row order is arbitrary")
+			}
+			translated = head + " OFFSET " + offset + " ROWS FETCH NEXT " + count + " ROWS ONLY"
+		}
+		if strings.HasSuffix(strings.TrimSpace(query), ";") {
+			translated += ";"
+		}
+	}
+
+	if castRe.MatchString(translated) {
+		w.note("", "", "PostgreSQL :: casts need rewriting as CAST(x AS type)")
+	}
+	if upsertRe.MatchString(query) {
+		w.note("", "", "upserts (ON DUPLICATE KEY / ON CONFLICT) need rewriting as MERGE")
+	}
+	return translated, w.issues
+}
+
+// translateWords rewrites keywords and functions in unquoted SQL text
+func (w *dialectWriter) translateWords(sql string) string {
+	return wordRe.ReplaceAllStringFunc(sql, func(word string) string {
+		switch strings.ToUpper(word) {
+		case "TRUE":
+			return "1"
+		case "FALSE":
+			return "0"
+		case "NOW()", "CURRENT_TIMESTAMP()":
+			if w.dialect == DialectSQLServer {
+				return "SYSDATETIME()"
+			}
+			return "SYSTIMESTAMP"
+		case "IFNULL":
+			if w.dialect == DialectSQLServer {
+				return "ISNULL"
+			}
+			return "NVL"
+		case "ILIKE":
+			w.note("", "", "ILIKE was rewritten as LIKE; case sensitivity follows the target collation")
+			return "LIKE"
+		case "RETURNING":
+			w.note("", "", "RETURNING needs rewriting (OUTPUT in SQL Server, RETURNING INTO in PL/SQL)")
+		}
+		return word
+	})
+}
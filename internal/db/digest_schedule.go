@@ -0,0 +1,142 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DigestSchedule is when a health digest goes out: every day, or once a
+// week, at a local time of day
+type DigestSchedule struct {
+	Weekly bool
+	Day    time.Weekday  // Day of weekly digests
+	At     time.Duration // Since midnight
+}
+
+// LastSlot returns the most recent time the schedule came round, at or
+// before now
+func (s DigestSchedule) LastSlot(now time.Time) time.Time {
+	hour, minute := int(s.At/time.Hour), int(s.At%time.Hour/time.Minute)
+	slot := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	for s.Weekly && slot.Weekday() != s.Day {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	return slot
+}
+
+// NextSlot returns the next time the schedule comes round after now
+func (s DigestSchedule) NextSlot(now time.Time) time.Time {
+	if s.Weekly {
+		return s.LastSlot(now).AddDate(0, 0, 7)
+	}
+	return s.LastSlot(now).AddDate(0, 0, 1)
+}
+
+// Due reports whether a digest last sent at last is due again; one never
+// sent is due right away
+func (s DigestSchedule) Due(last, now time.Time) bool {
+	return last.Before(s.LastSlot(now))
+}
+
+// String describes the schedule, e.g. "weekly on Monday at 08:00"
+func (s DigestSchedule) String() string {
+	at := fmt.Sprintf("%02d:%02d", int(s.At/time.Hour), int(s.At%time.Hour/time.Minute))
+	if s.Weekly {
+		return fmt.Sprintf("weekly on %s at %s", s.Day, at)
+	}
+	return "daily at " + at
+}
+
+// DigestsSent records when each profile's health digest was last sent
+type DigestsSent struct {
+	Sent map[string]time.Time `json:"sent"`
+}
+
+// GetDigestsSentPath returns the path to the file of sent health digests
+func GetDigestsSentPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+
+	configDir := filepath.Join(configHome, "ysm")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, "digests.json"), nil
+}
+
+// LoadDigestsSent loads when each health digest was last sent
+func LoadDigestsSent() (*DigestsSent, error) {
+	path, err := GetDigestsSentPath()
+	if err != nil {
+		return nil, err
+	}
+
+	sent := &DigestsSent{Sent: make(map[string]time.Time)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sent, nil
+		}
+		return nil, fmt.Errorf("failed to read sent digests: %w", err)
+	}
+	if err := json.Unmarshal(data, sent); err != nil {
+		return nil, fmt.Errorf("failed to parse sent digests: %w", err)
+	}
+	if sent.Sent == nil {
+		sent.Sent = make(map[string]time.Time)
+	}
+	return sent, nil
+}
+
+// MarkDigestSent records that a profile's health digest was sent at t
+func MarkDigestSent(profile string, t time.Time) error {
+	sent, err := LoadDigestsSent()
+	if err != nil {
+		return err
+	}
+	sent.Sent[profile] = t
+
+	path, err := GetDigestsSentPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(sent, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sent digests: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write sent digests: %w", err)
+	}
+	return nil
+}
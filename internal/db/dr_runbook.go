@@ -0,0 +1,140 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DRRunbook is what an on-call engineer needs to bring one server's
+// databases back: the backups to restore from, where they are, and how long
+// restoring them should take
+type DRRunbook struct {
+	Profile    string            `json:"profile"`
+	Server     string            `json:"server"` // See ServerKey
+	BackupsDir string            `json:"backups_dir"`
+	Generated  time.Time         `json:"generated"`
+	Databases  []RunbookDatabase `json:"databases"`
+
+	// Replication topology, filled in by the caller when the server is reachable
+	Topology      *ClusterStatus `json:"topology,omitempty"`
+	TopologyError string         `json:"topology_error,omitempty"`
+}
+
+// RunbookDatabase is one database's recovery options
+type RunbookDatabase struct {
+	Database string          `json:"database"`
+	Latest   RunbookBackup   `json:"latest"`             // Newest backup holding it
+	Verified *RunbookBackup  `json:"verified,omitempty"` // Newest verified or restored one, if any
+	Estimate RestoreEstimate `json:"estimate"`           // Restoring it from Recommended
+}
+
+// Recommended is the backup to restore from: the newest verified one, or
+// the newest when none was verified
+func (d RunbookDatabase) Recommended() RunbookBackup {
+	if d.Verified != nil {
+		return *d.Verified
+	}
+	return d.Latest
+}
+
+// RunbookBackup is a backup as far as one of its databases is concerned
+type RunbookBackup struct {
+	ID           string    `json:"id"`
+	Path         string    `json:"path"`
+	Timestamp    time.Time `json:"timestamp"`
+	Size         int64     `json:"size"` // Of this database's file
+	Checksummed  bool      `json:"checksummed"`
+	VerifiedAt   time.Time `json:"verified_at,omitempty"`
+	LastRestored time.Time `json:"last_restored,omitempty"`
+}
+
+// Proven reports whether the backup has been verified or restored
+func (b RunbookBackup) Proven() bool {
+	return !b.VerifiedAt.IsZero() || !b.LastRestored.IsZero()
+}
+
+// BuildDRRunbook gathers the backups of a profile and estimates restoring
+// each database to server. Backups made without naming a profile count as
+// the profile's when includeUnnamed is set, as for the default profile
+func BuildDRRunbook(profile, server string, includeUnnamed bool) (*DRRunbook, error) {
+	backupsDir, err := GetBackupsDir()
+	if err != nil {
+		return nil, err
+	}
+	backups, err := ListBackups()
+	if err != nil {
+		return nil, err
+	}
+	history, err := LoadRestoreHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	runbook := &DRRunbook{
+		Profile:    profile,
+		Server:     server,
+		BackupsDir: backupsDir,
+		Generated:  time.Now(),
+	}
+
+	// Backups are sorted newest first, so the first seen of each database is the latest
+	byDatabase := make(map[string]*RunbookDatabase)
+	for _, b := range backups {
+		if b.Profile != profile && !(b.Profile == "" && includeUnnamed) {
+			continue
+		}
+		for _, f := range b.Files {
+			entry := RunbookBackup{
+				ID:          b.ID,
+				Path:        filepath.Join(backupsDir, b.ID, f.Filename),
+				Timestamp:   b.Timestamp,
+				Size:        f.Size,
+				Checksummed: f.SHA256 != "",
+			}
+			// Files without a checksum weren't checked by the verification
+			if entry.Checksummed {
+				entry.VerifiedAt = b.VerifiedAt
+			}
+			if r := history.LastRestore(b.ID); r != nil && containsString(r.Databases, f.Database) {
+				entry.LastRestored = r.Time
+			}
+
+			d, ok := byDatabase[f.Database]
+			if !ok {
+				d = &RunbookDatabase{Database: f.Database, Latest: entry}
+				byDatabase[f.Database] = d
+			}
+			if d.Verified == nil && entry.Proven() {
+				d.Verified = &entry
+			}
+		}
+	}
+
+	for _, d := range byDatabase {
+		d.Estimate = history.EstimateRestore(d.Recommended().Size, server)
+		runbook.Databases = append(runbook.Databases, *d)
+	}
+	sort.Slice(runbook.Databases, func(i, j int) bool {
+		return runbook.Databases[i].Database < runbook.Databases[j].Database
+	})
+	return runbook, nil
+}
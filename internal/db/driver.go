@@ -44,6 +44,7 @@ type Driver interface {
 	DescribeTableQuery(table string) string
 	GetCreateTableQuery(table string) string
 	TableRowCountQuery(table string) string
+	PrimaryKeyQuery(table string) string
 
 	// Database operations
 	CreateDatabaseQuery(name string) string
@@ -80,6 +81,10 @@ type Driver interface {
 	ShowUserGrantsQuery(username, host string) string
 	GrantPrivilegesQuery(privs []string, database, table, username, host string) string
 	RevokePrivilegesQuery(privs []string, database, table, username, host string) string
+	ChangePasswordQuery(username, host, password string) string
+	RenameUserQuery(username, host, newName, newHost string) string
+	LockUserQuery(username, host string, lock bool) string
+	PasswordExpiryQuery(username, host string, days int) string
 	FlushPrivilegesQuery() string
 
 	// Enhanced database creation
@@ -102,6 +107,82 @@ type Driver interface {
 	ClusterNodesQuery() string
 	ReplicationStatusQuery() string
 	IsPrimaryQuery() string
+
+	// Constraints
+	ForeignKeysQuery(table string, referencing bool) string
+	UniqueConstraintsQuery(table string) string
+	CheckConstraintsQuery(table string) string
+	DropForeignKeyQuery(table, name string) string
+
+	// Indexes
+	ListIndexesQuery(table string) string
+	DropIndexQuery(table, name string) string
+
+	// Table designer
+	ColumnTypes() []string
+	ColumnDefinitionsQuery(table string) string
+	PrimaryKeyNameQuery(table string) string
+	CreateTableQuery(def TableDef) string
+	AlterTableQueries(from, to TableDef, diff tableDiff) ([]string, error)
+
+	// Data diff
+	RowHashExpr(columns []string) string
+	ChunkChecksumExpr(columns []string) string
+
+	// Restore checks
+	CharsetsQuery() string // "" when the server doesn't list them
+	CollationsQuery() string
+	ExtensionsQuery() string // Storage engines on MariaDB
+	DataDirectoryQuery() string
+
+	// Maintenance
+	AnalyzeTableQuery(name ...string) string // name is the table, optionally schema-qualified
+	BloatQueries() []string                  // Each returns schema, table, index ("" for tables), size and wasted bytes
+	RebuildTableQuery(name ...string) string
+	RebuildIndexQuery(concurrently bool, name ...string) string // "" when indexes are rebuilt with their table
+
+	// Table statistics and maintenance
+	TableStatsQueries(table string) []string                            // Alternatives tried in order until one succeeds
+	MaintenanceQuery(op string, concurrently bool, table string) string // "" when the server has no such operation
+	MaintenanceProgressQuery(session int64) string                      // Phase and percent done of a session's statement
+	SessionIDQuery() string
+
+	// Maintenance advisor
+	AdvisorTablesQuery() string                             // Table, estimated rows, bytes, has a primary key, is partitioned
+	IndexUsageQuery() string                                // Table, index and how often it was scanned
+	IndexUsageSinceQuery() string                           // When index usage counting started
+	CharsetsInUseQuery() string                             // Table ("" for the database), column ("" for the table), charset, charset of its container
+	StaleStatisticsQuery() string                           // Table, estimated rows, last analyze and rows changed since (NULL when unknown)
+	AddPrimaryKeyQuery(table, column string) string         // Adds a surrogate key column
+	CharsetFixQuery(database, table, charset string) string // Converts a table, or the database when table is ""
+	PartitionTemplate(table string) string                  // PARTITION BY skeleton with <placeholders> to fill in
+
+	// Large objects
+	LargeObjectColumnsQuery() string           // Table and column of each column holding large object OIDs; "" without large objects
+	LargeObjectsQuery(sources []string) string // Existing large objects among the OIDs the source queries select
+	LargeObjectReadQuery() string              // Chunk of a large object by OID, offset and length
+
+	// Workload capture
+	WorkloadSampleQuery() string // Statements of other sessions: session, database, query, start (epoch ms), elapsed ms, running
+
+	// Locks
+	LockWaitsQueries() []string // Alternatives tried in order until one succeeds
+	KillSessionQuery(id int64, queryOnly bool) string
+	RunningQueriesQuery() string // Sessions running a statement, longest first
+
+	// Connection audit
+	ConnectionSourcesQueries() []string // Alternatives tried in order until one succeeds
+
+	// Slow queries
+	SlowQueryStatsQueries() []string // Alternatives tried in order until one succeeds
+	SlowQueryLogFileQuery() string   // "" when the server doesn't log to a file
+
+	// Metrics
+	MetricsCountersQuery() string // Cumulative statements, cache lookups and cache misses
+
+	// Row-level security ("" when unsupported)
+	RowSecurityQuery(table string) string // table "" = every table with row security enabled
+	PoliciesQuery(table string) string
 }
 
 // GetDriver returns the appropriate driver for the given database type
@@ -20,6 +20,7 @@ package db
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
@@ -30,7 +31,7 @@ type MariaDBDriver struct{}
 func (d *MariaDBDriver) DSN(cfg ConnectionConfig) string {
 	// Use socket if provided
 	if cfg.Socket != "" {
-		dsn := fmt.Sprintf("%s:%s@unix(%s)/%s?parseTime=true&multiStatements=true",
+		dsn := fmt.Sprintf("%s:%s@unix(%s)/%s?parseTime=true&multiStatements=true&charset=utf8mb4",
 			cfg.User, cfg.Password, cfg.Socket, cfg.Database)
 		return dsn
 	}
@@ -45,7 +46,7 @@ func (d *MariaDBDriver) DSN(cfg ConnectionConfig) string {
 		port = 3306
 	}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&multiStatements=true",
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&multiStatements=true&charset=utf8mb4",
 		cfg.User, cfg.Password, host, port, cfg.Database)
 	return dsn
 }
@@ -90,6 +91,13 @@ func (d *MariaDBDriver) TableRowCountQuery(table string) string {
 	return fmt.Sprintf("SELECT COUNT(*) FROM %s", d.QuoteIdentifier(table))
 }
 
+// PrimaryKeyQuery returns the query to list a table's primary key columns in order
+func (d *MariaDBDriver) PrimaryKeyQuery(table string) string {
+	return fmt.Sprintf(`SELECT COLUMN_NAME FROM information_schema.KEY_COLUMN_USAGE
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = '%s' AND CONSTRAINT_NAME = 'PRIMARY'
+	ORDER BY ORDINAL_POSITION`, d.EscapeString(table))
+}
+
 // CreateDatabaseQuery returns the query to create a database
 func (d *MariaDBDriver) CreateDatabaseQuery(name string) string {
 	return fmt.Sprintf("CREATE DATABASE %s", d.QuoteIdentifier(name))
@@ -126,9 +134,16 @@ func (d *MariaDBDriver) SetVariableQuery(name, value string, global bool) string
 	if global {
 		scope = "GLOBAL"
 	}
-	return fmt.Sprintf("SET %s %s = ?", scope, name)
+	// Numeric variables reject quoted values, and DEFAULT is a keyword
+	if numericValue.MatchString(value) || strings.EqualFold(value, "DEFAULT") {
+		return fmt.Sprintf("SET %s %s = %s", scope, name, value)
+	}
+	return fmt.Sprintf("SET %s %s = '%s'", scope, name, d.EscapeString(value))
 }
 
+// numericValue matches a plain decimal number
+var numericValue = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
 // CommonVariables returns the list of common MariaDB variables
 func (d *MariaDBDriver) CommonVariables() []string {
 	return []string{
@@ -151,7 +166,8 @@ func (d *MariaDBDriver) CommonVariables() []string {
 
 // ExportHeader returns the SQL header for exports
 func (d *MariaDBDriver) ExportHeader() string {
-	return `SET FOREIGN_KEY_CHECKS=0;
+	return `SET NAMES utf8mb4;
+SET FOREIGN_KEY_CHECKS=0;
 SET SQL_MODE = "NO_AUTO_VALUE_ON_ZERO";
 SET AUTOCOMMIT = 0;
 START TRANSACTION;
@@ -284,6 +300,42 @@ func (d *MariaDBDriver) RevokePrivilegesQuery(privs []string, database, table, u
 		d.EscapeString(username), d.EscapeString(host))
 }
 
+// ChangePasswordQuery returns the query to set a user's password
+func (d *MariaDBDriver) ChangePasswordQuery(username, host, password string) string {
+	return fmt.Sprintf("ALTER USER '%s'@'%s' IDENTIFIED BY '%s'",
+		d.EscapeString(username), d.EscapeString(host), d.EscapeString(password))
+}
+
+// RenameUserQuery returns the query to rename a user or move it to another host
+func (d *MariaDBDriver) RenameUserQuery(username, host, newName, newHost string) string {
+	return fmt.Sprintf("RENAME USER '%s'@'%s' TO '%s'@'%s'",
+		d.EscapeString(username), d.EscapeString(host), d.EscapeString(newName), d.EscapeString(newHost))
+}
+
+// LockUserQuery returns the query to lock or unlock an account (MariaDB 10.4.2+)
+func (d *MariaDBDriver) LockUserQuery(username, host string, lock bool) string {
+	action := "UNLOCK"
+	if lock {
+		action = "LOCK"
+	}
+	return fmt.Sprintf("ALTER USER '%s'@'%s' ACCOUNT %s",
+		d.EscapeString(username), d.EscapeString(host), action)
+}
+
+// PasswordExpiryQuery returns the query to expire a password now (days 0),
+// never (days < 0) or every days days after it is set (MariaDB 10.4.3+)
+func (d *MariaDBDriver) PasswordExpiryQuery(username, host string, days int) string {
+	expire := "PASSWORD EXPIRE"
+	switch {
+	case days < 0:
+		expire = "PASSWORD EXPIRE NEVER"
+	case days > 0:
+		expire = fmt.Sprintf("PASSWORD EXPIRE INTERVAL %d DAY", days)
+	}
+	return fmt.Sprintf("ALTER USER '%s'@'%s' %s",
+		d.EscapeString(username), d.EscapeString(host), expire)
+}
+
 // FlushPrivilegesQuery returns the query to flush privileges
 func (d *MariaDBDriver) FlushPrivilegesQuery() string {
 	return "FLUSH PRIVILEGES"
@@ -401,3 +453,536 @@ func (d *MariaDBDriver) ReplicationStatusQuery() string {
 func (d *MariaDBDriver) IsPrimaryQuery() string {
 	return "SHOW MASTER STATUS"
 }
+
+// Constraints
+
+// ForeignKeysQuery returns name, table, column, referenced table, referenced
+// column, update and delete rule, one row per column. With referencing set it
+// lists the keys on other tables that point at table.
+func (d *MariaDBDriver) ForeignKeysQuery(table string, referencing bool) string {
+	side := "k.TABLE_NAME"
+	if referencing {
+		side = "k.REFERENCED_TABLE_NAME"
+	}
+	return fmt.Sprintf(`SELECT k.CONSTRAINT_NAME, k.TABLE_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME,
+		r.UPDATE_RULE, r.DELETE_RULE
+	FROM information_schema.KEY_COLUMN_USAGE k
+	JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+		ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME AND r.TABLE_NAME = k.TABLE_NAME
+	WHERE k.TABLE_SCHEMA = DATABASE() AND k.REFERENCED_TABLE_NAME IS NOT NULL AND %s = '%s'
+	ORDER BY k.TABLE_NAME, k.CONSTRAINT_NAME, k.ORDINAL_POSITION`, side, d.EscapeString(table))
+}
+
+// UniqueConstraintsQuery returns name and column of each unique constraint column
+func (d *MariaDBDriver) UniqueConstraintsQuery(table string) string {
+	return fmt.Sprintf(`SELECT tc.CONSTRAINT_NAME, k.COLUMN_NAME
+	FROM information_schema.TABLE_CONSTRAINTS tc
+	JOIN information_schema.KEY_COLUMN_USAGE k
+		ON k.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA AND k.CONSTRAINT_NAME = tc.CONSTRAINT_NAME AND k.TABLE_NAME = tc.TABLE_NAME
+	WHERE tc.TABLE_SCHEMA = DATABASE() AND tc.TABLE_NAME = '%s' AND tc.CONSTRAINT_TYPE = 'UNIQUE'
+	ORDER BY tc.CONSTRAINT_NAME, k.ORDINAL_POSITION`, d.EscapeString(table))
+}
+
+// CheckConstraintsQuery returns name and clause of each check constraint.
+// MariaDB names checks per table, so CHECK_CONSTRAINTS is matched on the table too.
+func (d *MariaDBDriver) CheckConstraintsQuery(table string) string {
+	return fmt.Sprintf(`SELECT CONSTRAINT_NAME, CHECK_CLAUSE
+	FROM information_schema.CHECK_CONSTRAINTS
+	WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = '%s'
+	ORDER BY CONSTRAINT_NAME`, d.EscapeString(table))
+}
+
+// DropForeignKeyQuery returns the query to drop a foreign key
+func (d *MariaDBDriver) DropForeignKeyQuery(table, name string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", d.QuoteIdentifier(table), d.QuoteIdentifier(name))
+}
+
+// Indexes
+
+// ListIndexesQuery returns table, index, column, unique, primary and index
+// type, one row per indexed column. An empty table lists all tables.
+func (d *MariaDBDriver) ListIndexesQuery(table string) string {
+	filter := ""
+	if table != "" {
+		filter = fmt.Sprintf(" AND TABLE_NAME = '%s'", d.EscapeString(table))
+	}
+	return `SELECT TABLE_NAME, INDEX_NAME, COLUMN_NAME, NON_UNIQUE = 0, INDEX_NAME = 'PRIMARY', INDEX_TYPE
+	FROM information_schema.STATISTICS
+	WHERE TABLE_SCHEMA = DATABASE()` + filter + `
+	ORDER BY TABLE_NAME, INDEX_NAME = 'PRIMARY' DESC, INDEX_NAME, SEQ_IN_INDEX`
+}
+
+// DropIndexQuery returns the query to drop an index
+func (d *MariaDBDriver) DropIndexQuery(table, name string) string {
+	return fmt.Sprintf("DROP INDEX %s ON %s", d.QuoteIdentifier(name), d.QuoteIdentifier(table))
+}
+
+// Table designer
+
+// ColumnTypes returns common column types for the table designer
+func (d *MariaDBDriver) ColumnTypes() []string {
+	return []string{
+		"INT", "BIGINT", "SMALLINT", "TINYINT(1)", "DECIMAL(10,2)", "DOUBLE",
+		"VARCHAR(255)", "CHAR(36)", "TEXT", "LONGTEXT",
+		"DATE", "DATETIME", "TIMESTAMP", "TIME",
+		"BOOLEAN", "JSON", "BLOB", "ENUM('a','b')",
+	}
+}
+
+// ColumnDefinitionsQuery returns name, type, nullable, default, extra and the
+// single-column unique index of each column
+func (d *MariaDBDriver) ColumnDefinitionsQuery(table string) string {
+	return fmt.Sprintf(`SELECT c.COLUMN_NAME, c.COLUMN_TYPE, c.IS_NULLABLE, c.COLUMN_DEFAULT, c.EXTRA,
+		COALESCE((SELECT s.INDEX_NAME FROM information_schema.STATISTICS s
+			WHERE s.TABLE_SCHEMA = c.TABLE_SCHEMA AND s.TABLE_NAME = c.TABLE_NAME
+			AND s.COLUMN_NAME = c.COLUMN_NAME AND s.NON_UNIQUE = 0 AND s.INDEX_NAME <> 'PRIMARY'
+			AND (SELECT COUNT(*) FROM information_schema.STATISTICS s2
+				WHERE s2.TABLE_SCHEMA = s.TABLE_SCHEMA AND s2.TABLE_NAME = s.TABLE_NAME
+				AND s2.INDEX_NAME = s.INDEX_NAME) = 1
+			LIMIT 1), '')
+	FROM information_schema.COLUMNS c
+	WHERE c.TABLE_SCHEMA = DATABASE() AND c.TABLE_NAME = '%s'
+	ORDER BY c.ORDINAL_POSITION`, d.EscapeString(table))
+}
+
+// PrimaryKeyNameQuery returns the query for the name of a table's primary key
+func (d *MariaDBDriver) PrimaryKeyNameQuery(table string) string {
+	return "SELECT 'PRIMARY'"
+}
+
+// columnDefinition renders a column for CREATE/ALTER TABLE
+func (d *MariaDBDriver) columnDefinition(col ColumnDef) string {
+	def := d.QuoteIdentifier(col.Name) + " " + col.Type
+	if col.Nullable {
+		def += " NULL"
+	} else {
+		def += " NOT NULL"
+	}
+	if col.Default != "" {
+		def += " DEFAULT " + col.Default
+	}
+	if col.AutoIncrement {
+		def += " AUTO_INCREMENT"
+	}
+	return def
+}
+
+// CreateTableQuery builds a CREATE TABLE statement
+func (d *MariaDBDriver) CreateTableQuery(def TableDef) string {
+	var lines []string
+	for _, col := range def.Columns {
+		line := d.columnDefinition(col)
+		if col.Unique && !col.PrimaryKey {
+			line += " UNIQUE"
+		}
+		lines = append(lines, line)
+	}
+	if pk := def.PrimaryKey(); len(pk) > 0 {
+		lines = append(lines, "PRIMARY KEY ("+d.quoteList(pk)+")")
+	}
+	return fmt.Sprintf("CREATE TABLE %s (\n  %s\n)", d.QuoteIdentifier(def.Name), strings.Join(lines, ",\n  "))
+}
+
+// AlterTableQueries builds a single ALTER TABLE (plus RENAME TABLE) for a diff.
+// Column positions are kept with FIRST/AFTER when columns move or are inserted.
+func (d *MariaDBDriver) AlterTableQueries(from, to TableDef, diff tableDiff) ([]string, error) {
+	var clauses []string
+
+	if diff.PKChanged && len(from.PrimaryKey()) > 0 {
+		clauses = append(clauses, "DROP PRIMARY KEY")
+	}
+	for _, col := range diff.UniqueDropped {
+		clauses = append(clauses, "DROP INDEX "+d.QuoteIdentifier(col.UniqueIndex))
+	}
+	for _, col := range diff.Dropped {
+		clauses = append(clauses, "DROP COLUMN "+d.QuoteIdentifier(col.Name))
+	}
+
+	modified := make(map[string]bool)
+	for _, m := range diff.Modified {
+		modified[m.From.Name] = true
+	}
+	reposition := diff.Reordered || diff.AddedInside
+
+	prev := ""
+	for _, col := range to.Columns {
+		pos := " FIRST"
+		if prev != "" {
+			pos = " AFTER " + d.QuoteIdentifier(prev)
+		}
+		prev = col.Name
+
+		if col.Original == "" || !hasColumn(from, col.Original) {
+			clause := "ADD COLUMN " + d.columnDefinition(col)
+			if col.Unique && !col.PrimaryKey {
+				clause += " UNIQUE"
+			}
+			if reposition {
+				clause += pos
+			}
+			clauses = append(clauses, clause)
+			continue
+		}
+		if modified[col.Original] || reposition {
+			clause := "CHANGE COLUMN " + d.QuoteIdentifier(col.Original) + " " + d.columnDefinition(col)
+			if reposition {
+				clause += pos
+			}
+			clauses = append(clauses, clause)
+		}
+	}
+
+	for _, name := range diff.UniqueAdded {
+		clauses = append(clauses, "ADD UNIQUE ("+d.QuoteIdentifier(name)+")")
+	}
+	if diff.PKChanged && len(diff.PrimaryKey) > 0 {
+		clauses = append(clauses, "ADD PRIMARY KEY ("+d.quoteList(diff.PrimaryKey)+")")
+	}
+
+	var statements []string
+	if len(clauses) > 0 {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s\n  %s",
+			d.QuoteIdentifier(from.Name), strings.Join(clauses, ",\n  ")))
+	}
+	if diff.Renamed {
+		statements = append(statements, fmt.Sprintf("RENAME TABLE %s TO %s",
+			d.QuoteIdentifier(from.Name), d.QuoteIdentifier(to.Name)))
+	}
+	return statements, nil
+}
+
+// Data diff
+
+// RowHashExpr returns an MD5 of a row's columns. CONCAT_WS skips NULLs, so the
+// NULL flags are appended to tell NULL apart from an empty string.
+func (d *MariaDBDriver) RowHashExpr(columns []string) string {
+	values := make([]string, len(columns))
+	nulls := make([]string, len(columns))
+	for i, col := range columns {
+		values[i] = d.QuoteIdentifier(col)
+		nulls[i] = "ISNULL(" + d.QuoteIdentifier(col) + ")"
+	}
+	return fmt.Sprintf("MD5(CONCAT_WS('#', %s, CONCAT(%s)))", strings.Join(values, ", "), strings.Join(nulls, ", "))
+}
+
+// ChunkChecksumExpr returns an order-independent checksum of the row hashes
+func (d *MariaDBDriver) ChunkChecksumExpr(columns []string) string {
+	return fmt.Sprintf("COALESCE(BIT_XOR(CAST(CONV(SUBSTRING(%s, 1, 16), 16, 10) AS UNSIGNED)), 0)", d.RowHashExpr(columns))
+}
+
+// CharsetsQuery returns the query listing the server's character sets
+func (d *MariaDBDriver) CharsetsQuery() string {
+	return "SELECT CHARACTER_SET_NAME FROM information_schema.CHARACTER_SETS"
+}
+
+// CollationsQuery returns the query listing the server's collations
+func (d *MariaDBDriver) CollationsQuery() string {
+	return "SELECT COLLATION_NAME FROM information_schema.COLLATIONS"
+}
+
+// ExtensionsQuery returns the query listing the storage engines that can be used
+func (d *MariaDBDriver) ExtensionsQuery() string {
+	return "SELECT ENGINE FROM information_schema.ENGINES WHERE SUPPORT IN ('YES', 'DEFAULT')"
+}
+
+// DataDirectoryQuery returns the query for the server's data directory
+func (d *MariaDBDriver) DataDirectoryQuery() string {
+	return "SELECT @@datadir"
+}
+
+// AnalyzeTableQuery returns the query refreshing a table's index statistics
+func (d *MariaDBDriver) AnalyzeTableQuery(name ...string) string {
+	quoted := make([]string, len(name))
+	for i, part := range name {
+		quoted[i] = d.QuoteIdentifier(part)
+	}
+	return "ANALYZE TABLE " + strings.Join(quoted, ".")
+}
+
+// BloatQueries returns the query reporting fragmentation: the free space
+// InnoDB and Aria keep inside each table's data file
+func (d *MariaDBDriver) BloatQueries() []string {
+	return []string{
+		`SELECT table_schema, table_name, '',
+			COALESCE(data_length + index_length + data_free, 0),
+			COALESCE(data_free, 0)
+		FROM information_schema.tables
+		WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE'`,
+	}
+}
+
+// RebuildTableQuery returns the query defragmenting a table
+func (d *MariaDBDriver) RebuildTableQuery(name ...string) string {
+	quoted := make([]string, len(name))
+	for i, part := range name {
+		quoted[i] = d.QuoteIdentifier(part)
+	}
+	return "OPTIMIZE TABLE " + strings.Join(quoted, ".")
+}
+
+// RebuildIndexQuery returns ""; OPTIMIZE TABLE rebuilds the indexes too
+func (d *MariaDBDriver) RebuildIndexQuery(concurrently bool, name ...string) string {
+	return ""
+}
+
+// TableStatsQueries returns the queries for a table's estimated rows, data
+// and index size, dead rows (NULL), last analyze and last vacuum (NULL). The
+// last analyze comes from InnoDB's persistent statistics, which need access
+// to the mysql database; the second query does without it.
+func (d *MariaDBDriver) TableStatsQueries(table string) []string {
+	filter := fmt.Sprintf("t.TABLE_SCHEMA = DATABASE() AND t.TABLE_NAME = '%s'", d.EscapeString(table))
+	return []string{
+		`SELECT COALESCE(t.TABLE_ROWS, 0), COALESCE(t.DATA_LENGTH, 0), COALESCE(t.INDEX_LENGTH, 0), NULL, s.last_update, NULL
+		FROM information_schema.TABLES t
+		LEFT JOIN mysql.innodb_table_stats s ON s.database_name = t.TABLE_SCHEMA AND s.table_name = t.TABLE_NAME
+		WHERE ` + filter,
+		`SELECT COALESCE(t.TABLE_ROWS, 0), COALESCE(t.DATA_LENGTH, 0), COALESCE(t.INDEX_LENGTH, 0), NULL, NULL, NULL
+		FROM information_schema.TABLES t
+		WHERE ` + filter,
+	}
+}
+
+// MaintenanceQuery returns ANALYZE, OPTIMIZE or CHECK TABLE. VACUUM FULL and
+// REINDEX have no MariaDB equivalent; OPTIMIZE TABLE rebuilds the indexes too.
+func (d *MariaDBDriver) MaintenanceQuery(op string, concurrently bool, table string) string {
+	switch op {
+	case MaintenanceAnalyze:
+		return "ANALYZE TABLE " + d.QuoteIdentifier(table)
+	case MaintenanceOptimize:
+		return "OPTIMIZE TABLE " + d.QuoteIdentifier(table)
+	case MaintenanceCheck:
+		return "CHECK TABLE " + d.QuoteIdentifier(table)
+	}
+	return ""
+}
+
+// MaintenanceProgressQuery returns a session's state and, for statements
+// that report their progress, the percentage done
+func (d *MariaDBDriver) MaintenanceProgressQuery(session int64) string {
+	return fmt.Sprintf(`SELECT COALESCE(STATE, ''), CASE WHEN MAX_STAGE > 0 THEN PROGRESS END
+		FROM information_schema.PROCESSLIST WHERE ID = %d`, session)
+}
+
+// SessionIDQuery returns the query for the current connection's ID
+func (d *MariaDBDriver) SessionIDQuery() string {
+	return "SELECT CONNECTION_ID()"
+}
+
+// Maintenance advisor
+
+// AdvisorTablesQuery returns each base table's estimated rows and bytes,
+// whether it has a primary key and whether it is partitioned
+func (d *MariaDBDriver) AdvisorTablesQuery() string {
+	return `SELECT t.TABLE_NAME, COALESCE(t.TABLE_ROWS, 0), COALESCE(t.DATA_LENGTH + t.INDEX_LENGTH, 0),
+		EXISTS (SELECT 1 FROM information_schema.TABLE_CONSTRAINTS c
+			WHERE c.TABLE_SCHEMA = t.TABLE_SCHEMA AND c.TABLE_NAME = t.TABLE_NAME AND c.CONSTRAINT_TYPE = 'PRIMARY KEY'),
+		COALESCE(t.CREATE_OPTIONS, '') LIKE '%partitioned%'
+	FROM information_schema.TABLES t
+	WHERE t.TABLE_SCHEMA = DATABASE() AND t.TABLE_TYPE = 'BASE TABLE'
+	ORDER BY t.TABLE_NAME`
+}
+
+// IndexUsageQuery returns how often each index was read, as counted by the
+// performance schema. It's empty when performance_schema is off.
+func (d *MariaDBDriver) IndexUsageQuery() string {
+	return `SELECT OBJECT_NAME, INDEX_NAME, COUNT_STAR
+	FROM performance_schema.table_io_waits_summary_by_index_usage
+	WHERE OBJECT_SCHEMA = DATABASE() AND INDEX_NAME IS NOT NULL`
+}
+
+// IndexUsageSinceQuery returns when the server started, which is when the
+// performance schema started counting
+func (d *MariaDBDriver) IndexUsageSinceQuery() string {
+	return `SELECT UTC_TIMESTAMP() - INTERVAL VARIABLE_VALUE SECOND
+	FROM information_schema.GLOBAL_STATUS WHERE VARIABLE_NAME = 'UPTIME'`
+}
+
+// CharsetsInUseQuery returns the charset of the database, of each table
+// and of each text column, next to the charset it would inherit
+func (d *MariaDBDriver) CharsetsInUseQuery() string {
+	return `SELECT '', '', DEFAULT_CHARACTER_SET_NAME, DEFAULT_CHARACTER_SET_NAME
+	FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = DATABASE()
+	UNION ALL
+	SELECT t.TABLE_NAME, '', SUBSTRING_INDEX(t.TABLE_COLLATION, '_', 1), s.DEFAULT_CHARACTER_SET_NAME
+	FROM information_schema.TABLES t
+	JOIN information_schema.SCHEMATA s ON s.SCHEMA_NAME = t.TABLE_SCHEMA
+	WHERE t.TABLE_SCHEMA = DATABASE() AND t.TABLE_TYPE = 'BASE TABLE'
+	UNION ALL
+	SELECT c.TABLE_NAME, c.COLUMN_NAME, c.CHARACTER_SET_NAME, SUBSTRING_INDEX(t.TABLE_COLLATION, '_', 1)
+	FROM information_schema.COLUMNS c
+	JOIN information_schema.TABLES t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME
+	WHERE c.TABLE_SCHEMA = DATABASE() AND t.TABLE_TYPE = 'BASE TABLE' AND c.CHARACTER_SET_NAME IS NOT NULL
+	ORDER BY 1, 2`
+}
+
+// StaleStatisticsQuery returns when InnoDB last saved each table's
+// persistent statistics. MariaDB doesn't count changes since then.
+func (d *MariaDBDriver) StaleStatisticsQuery() string {
+	return `SELECT t.TABLE_NAME, COALESCE(t.TABLE_ROWS, 0), s.last_update, NULL
+	FROM information_schema.TABLES t
+	LEFT JOIN mysql.innodb_table_stats s ON s.database_name = t.TABLE_SCHEMA AND s.table_name = t.TABLE_NAME
+	WHERE t.TABLE_SCHEMA = DATABASE() AND t.TABLE_TYPE = 'BASE TABLE' AND t.ENGINE = 'InnoDB'
+		AND COALESCE(t.CREATE_OPTIONS, '') NOT LIKE '%partitioned%'
+	ORDER BY t.TABLE_NAME`
+}
+
+// AddPrimaryKeyQuery returns the statement adding an auto-increment
+// primary key column in front of a table's columns
+func (d *MariaDBDriver) AddPrimaryKeyQuery(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY FIRST",
+		d.QuoteIdentifier(table), d.QuoteIdentifier(column))
+}
+
+// CharsetFixQuery converts a table and its text columns to charset. For the
+// database it only changes the default of tables created from now on.
+func (d *MariaDBDriver) CharsetFixQuery(database, table, charset string) string {
+	if table == "" {
+		return fmt.Sprintf("ALTER DATABASE %s CHARACTER SET %s", d.QuoteIdentifier(database), charset)
+	}
+	return fmt.Sprintf("ALTER TABLE %s CONVERT TO CHARACTER SET %s", d.QuoteIdentifier(table), charset)
+}
+
+// LargeObjectColumnsQuery returns "": MariaDB keeps BLOBs in their rows
+func (d *MariaDBDriver) LargeObjectColumnsQuery() string {
+	return ""
+}
+
+// LargeObjectsQuery returns "": MariaDB has no large objects
+func (d *MariaDBDriver) LargeObjectsQuery(sources []string) string {
+	return ""
+}
+
+// LargeObjectReadQuery returns "": MariaDB has no large objects
+func (d *MariaDBDriver) LargeObjectReadQuery() string {
+	return ""
+}
+
+// WorkloadSampleQuery returns the statements other client connections are
+// running, from the process list
+func (d *MariaDBDriver) WorkloadSampleQuery() string {
+	return `SELECT ID, COALESCE(DB, ''), INFO,
+		UNIX_TIMESTAMP(NOW(6)) * 1000 - TIME_MS, TIME_MS, 1
+	FROM information_schema.PROCESSLIST
+	WHERE COMMAND IN ('Query', 'Execute') AND INFO IS NOT NULL
+		AND ID <> CONNECTION_ID() AND USER <> 'system user'`
+}
+
+// PartitionTemplate returns a range partitioning of a table to fill in.
+// The partition column has to be part of every unique key.
+func (d *MariaDBDriver) PartitionTemplate(table string) string {
+	return fmt.Sprintf(`ALTER TABLE %s PARTITION BY RANGE COLUMNS(<column>) (
+	PARTITION p0 VALUES LESS THAN (<value>),
+	PARTITION pmax VALUES LESS THAN (MAXVALUE)
+)`, d.QuoteIdentifier(table))
+}
+
+// ConnectionSourcesQueries returns the queries listing other sessions'
+// user, client host, program and database. MariaDB doesn't record when a
+// connection opened, so the age column is NULL. The program name comes from
+// performance_schema's connection attributes when it is enabled.
+func (d *MariaDBDriver) ConnectionSourcesQueries() []string {
+	return []string{
+		`SELECT p.USER, p.HOST, COALESCE(a.ATTR_VALUE, ''), COALESCE(p.DB, ''), NULL
+		FROM information_schema.PROCESSLIST p
+		LEFT JOIN performance_schema.session_connect_attrs a
+			ON a.PROCESSLIST_ID = p.ID AND a.ATTR_NAME = 'program_name'
+		WHERE p.ID <> CONNECTION_ID() AND p.COMMAND <> 'Daemon' AND p.USER <> 'system user'`,
+		`SELECT USER, HOST, '', COALESCE(DB, ''), NULL
+		FROM information_schema.PROCESSLIST
+		WHERE ID <> CONNECTION_ID() AND COMMAND <> 'Daemon' AND USER <> 'system user'`,
+	}
+}
+
+// LockWaitsQueries returns the queries listing blocked sessions and their
+// blockers. InnoDB's information_schema lock tables are gone in MySQL 8,
+// where performance_schema takes over.
+func (d *MariaDBDriver) LockWaitsQueries() []string {
+	return []string{
+		`SELECT r.trx_mysql_thread_id, IFNULL(rp.USER, ''), IFNULL(rp.DB, ''), IFNULL(rp.STATE, ''), IFNULL(r.trx_query, ''),
+		        TIMESTAMPDIFF(SECOND, r.trx_wait_started, NOW()),
+		        b.trx_mysql_thread_id, IFNULL(bp.USER, ''), IFNULL(bp.DB, ''), IFNULL(bp.STATE, ''), IFNULL(b.trx_query, ''),
+		        IFNULL(bp.TIME, 0),
+		        CONCAT(IFNULL(l.lock_mode, ''), ' ', IFNULL(l.lock_type, ''), ' on ', IFNULL(l.lock_table, ''))
+		 FROM information_schema.INNODB_LOCK_WAITS w
+		 JOIN information_schema.INNODB_TRX r ON r.trx_id = w.requesting_trx_id
+		 JOIN information_schema.INNODB_TRX b ON b.trx_id = w.blocking_trx_id
+		 LEFT JOIN information_schema.PROCESSLIST rp ON rp.ID = r.trx_mysql_thread_id
+		 LEFT JOIN information_schema.PROCESSLIST bp ON bp.ID = b.trx_mysql_thread_id
+		 LEFT JOIN information_schema.INNODB_LOCKS l ON l.lock_id = w.requested_lock_id`,
+		`SELECT r.trx_mysql_thread_id, IFNULL(rp.USER, ''), IFNULL(rp.DB, ''), IFNULL(rp.STATE, ''), IFNULL(r.trx_query, ''),
+		        TIMESTAMPDIFF(SECOND, r.trx_wait_started, NOW()),
+		        b.trx_mysql_thread_id, IFNULL(bp.USER, ''), IFNULL(bp.DB, ''), IFNULL(bp.STATE, ''), IFNULL(b.trx_query, ''),
+		        IFNULL(bp.TIME, 0),
+		        CONCAT(IFNULL(l.LOCK_MODE, ''), ' ', IFNULL(l.LOCK_TYPE, ''), ' on ', IFNULL(l.OBJECT_SCHEMA, ''), '.', IFNULL(l.OBJECT_NAME, ''))
+		 FROM performance_schema.data_lock_waits w
+		 JOIN information_schema.INNODB_TRX r ON r.trx_id = w.REQUESTING_ENGINE_TRANSACTION_ID
+		 JOIN information_schema.INNODB_TRX b ON b.trx_id = w.BLOCKING_ENGINE_TRANSACTION_ID
+		 LEFT JOIN information_schema.PROCESSLIST rp ON rp.ID = r.trx_mysql_thread_id
+		 LEFT JOIN information_schema.PROCESSLIST bp ON bp.ID = b.trx_mysql_thread_id
+		 LEFT JOIN performance_schema.data_locks l ON l.ENGINE_LOCK_ID = w.REQUESTING_ENGINE_LOCK_ID`,
+	}
+}
+
+// KillSessionQuery returns the statement killing a connection or just its
+// running query
+func (d *MariaDBDriver) KillSessionQuery(id int64, queryOnly bool) string {
+	if queryOnly {
+		return fmt.Sprintf("KILL QUERY %d", id)
+	}
+	return fmt.Sprintf("KILL %d", id)
+}
+
+// RunningQueriesQuery returns the query listing connections busy with a
+// statement, leaving out replication and server threads
+func (d *MariaDBDriver) RunningQueriesQuery() string {
+	return `SELECT ID, IFNULL(USER, ''), IFNULL(DB, ''), IFNULL(STATE, ''), IFNULL(INFO, ''), TIME
+		FROM information_schema.PROCESSLIST
+		WHERE ID <> CONNECTION_ID() AND INFO IS NOT NULL AND USER <> 'system user'
+		  AND COMMAND NOT IN ('Sleep', 'Daemon', 'Binlog Dump', 'Binlog Dump GTID', 'Slave_IO', 'Slave_SQL', 'Slave_worker')
+		ORDER BY TIME DESC`
+}
+
+// SlowQueryStatsQueries returns the query summarizing mysql.slow_log, used
+// when log_output includes TABLE
+func (d *MariaDBDriver) SlowQueryStatsQueries() []string {
+	return []string{
+		`SELECT CONVERT(sql_text USING utf8mb4) AS q, COUNT(*),
+		        SUM(TIME_TO_SEC(query_time) + MICROSECOND(query_time) / 1000000) * 1000,
+		        MAX(TIME_TO_SEC(query_time) + MICROSECOND(query_time) / 1000000) * 1000,
+		        SUM(rows_sent), SUM(rows_examined)
+		 FROM mysql.slow_log GROUP BY q`,
+	}
+}
+
+// SlowQueryLogFileQuery returns the query for the slow query log file
+func (d *MariaDBDriver) SlowQueryLogFileQuery() string {
+	return "SELECT @@slow_query_log_file"
+}
+
+// MetricsCountersQuery returns the cumulative statement count and InnoDB
+// buffer pool read requests and disk reads
+func (d *MariaDBDriver) MetricsCountersQuery() string {
+	return `SELECT
+		(SELECT Variable_value FROM information_schema.global_status WHERE Variable_name = 'Questions'),
+		(SELECT Variable_value FROM information_schema.global_status WHERE Variable_name = 'Innodb_buffer_pool_read_requests'),
+		(SELECT Variable_value FROM information_schema.global_status WHERE Variable_name = 'Innodb_buffer_pool_reads')`
+}
+
+// RowSecurityQuery returns "" since MariaDB has no row-level security
+func (d *MariaDBDriver) RowSecurityQuery(table string) string {
+	return ""
+}
+
+// PoliciesQuery returns "" since MariaDB has no row-level security
+func (d *MariaDBDriver) PoliciesQuery(table string) string {
+	return ""
+}
+
+// quoteList quotes and joins identifiers
+func (d *MariaDBDriver) quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = d.QuoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // PostgresDriver implements the Driver interface for PostgreSQL
@@ -131,6 +132,19 @@ func (d *PostgresDriver) TableRowCountQuery(table string) string {
 	return fmt.Sprintf("SELECT COUNT(*) FROM %s", d.QuoteIdentifier(table))
 }
 
+// PrimaryKeyQuery returns the query to list a table's primary key columns in order
+func (d *PostgresDriver) PrimaryKeyQuery(table string) string {
+	return fmt.Sprintf(`SELECT kcu.column_name
+	FROM information_schema.table_constraints tc
+	JOIN information_schema.key_column_usage kcu
+		ON tc.constraint_name = kcu.constraint_name
+		AND tc.table_schema = kcu.table_schema
+		AND tc.table_name = kcu.table_name
+	WHERE tc.constraint_type = 'PRIMARY KEY'
+	AND tc.table_schema = 'public' AND tc.table_name = '%s'
+	ORDER BY kcu.ordinal_position`, d.EscapeString(table))
+}
+
 // CreateDatabaseQuery returns the query to create a database
 func (d *PostgresDriver) CreateDatabaseQuery(name string) string {
 	return fmt.Sprintf("CREATE DATABASE %s", d.QuoteIdentifier(name))
@@ -168,9 +182,9 @@ func (d *PostgresDriver) SetVariableQuery(name, value string, global bool) strin
 	// PostgreSQL uses SET for session variables
 	// Global variables require ALTER SYSTEM (and reload) - not supported in session
 	if global {
-		return fmt.Sprintf("ALTER SYSTEM SET %s = '%s'", name, value)
+		return fmt.Sprintf("ALTER SYSTEM SET %s = '%s'", name, d.EscapeString(value))
 	}
-	return fmt.Sprintf("SET %s = '%s'", name, value)
+	return fmt.Sprintf("SET %s = '%s'", name, d.EscapeString(value))
 }
 
 // CommonVariables returns the list of common PostgreSQL variables
@@ -255,9 +269,10 @@ func (d *PostgresDriver) EscapeString(s string) string {
 
 // User Management
 
-// ListUsersQuery returns the query to list all users (roles)
+// ListUsersQuery returns the query to list all users (roles). Roles without
+// LOGIN are included so locked users and group roles stay manageable.
 func (d *PostgresDriver) ListUsersQuery() string {
-	return `SELECT usename AS "User", '' AS "Host" FROM pg_user ORDER BY usename`
+	return `SELECT rolname AS "User", '' AS "Host" FROM pg_roles WHERE rolname !~ '^pg_' ORDER BY rolname`
 }
 
 // CreateUserQuery returns the query to create a user
@@ -297,14 +312,13 @@ func (d *PostgresDriver) GrantPrivilegesQuery(privs []string, database, table, u
 	pgPrivs := d.mapPrivileges(privs)
 
 	if database != "" && table != "" {
-		return fmt.Sprintf("GRANT %s ON TABLE %s.%s TO %s",
-			strings.Join(pgPrivs, ", "),
-			d.QuoteIdentifier(database), d.QuoteIdentifier(table),
+		return fmt.Sprintf("GRANT %s ON TABLE %s TO %s",
+			strings.Join(pgPrivs, ", "), d.quoteTable(table),
 			d.QuoteIdentifier(username))
 	} else if database != "" {
 		// Grant on all tables in schema + connect privilege
-		return fmt.Sprintf("GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA public TO %s; GRANT CONNECT ON DATABASE %s TO %s",
-			d.QuoteIdentifier(username),
+		return fmt.Sprintf("GRANT %s ON ALL TABLES IN SCHEMA public TO %s; GRANT CONNECT ON DATABASE %s TO %s",
+			strings.Join(d.tablePrivileges(pgPrivs), ", "), d.QuoteIdentifier(username),
 			d.QuoteIdentifier(database), d.QuoteIdentifier(username))
 	}
 	return fmt.Sprintf("GRANT %s TO %s",
@@ -316,29 +330,79 @@ func (d *PostgresDriver) RevokePrivilegesQuery(privs []string, database, table,
 	pgPrivs := d.mapPrivileges(privs)
 
 	if database != "" && table != "" {
-		return fmt.Sprintf("REVOKE %s ON TABLE %s.%s FROM %s",
-			strings.Join(pgPrivs, ", "),
-			d.QuoteIdentifier(database), d.QuoteIdentifier(table),
+		return fmt.Sprintf("REVOKE %s ON TABLE %s FROM %s",
+			strings.Join(pgPrivs, ", "), d.quoteTable(table),
 			d.QuoteIdentifier(username))
 	} else if database != "" {
-		return fmt.Sprintf("REVOKE ALL PRIVILEGES ON ALL TABLES IN SCHEMA public FROM %s; REVOKE CONNECT ON DATABASE %s FROM %s",
-			d.QuoteIdentifier(username),
+		return fmt.Sprintf("REVOKE %s ON ALL TABLES IN SCHEMA public FROM %s; REVOKE CONNECT ON DATABASE %s FROM %s",
+			strings.Join(d.tablePrivileges(pgPrivs), ", "), d.QuoteIdentifier(username),
 			d.QuoteIdentifier(database), d.QuoteIdentifier(username))
 	}
 	return fmt.Sprintf("REVOKE %s FROM %s",
 		strings.Join(pgPrivs, ", "), d.QuoteIdentifier(username))
 }
 
+// ChangePasswordQuery returns the query to set a role's password
+func (d *PostgresDriver) ChangePasswordQuery(username, host, password string) string {
+	return fmt.Sprintf("ALTER ROLE %s WITH PASSWORD '%s'",
+		d.QuoteIdentifier(username), d.EscapeString(password))
+}
+
+// RenameUserQuery returns the query to rename a role. PostgreSQL clears MD5
+// passwords on rename since the role name is part of the hash.
+func (d *PostgresDriver) RenameUserQuery(username, host, newName, newHost string) string {
+	return fmt.Sprintf("ALTER ROLE %s RENAME TO %s",
+		d.QuoteIdentifier(username), d.QuoteIdentifier(newName))
+}
+
+// LockUserQuery returns the query to lock a role by taking away LOGIN, or
+// to unlock it by giving LOGIN back
+func (d *PostgresDriver) LockUserQuery(username, host string, lock bool) string {
+	login := "LOGIN"
+	if lock {
+		login = "NOLOGIN"
+	}
+	return fmt.Sprintf("ALTER ROLE %s WITH %s", d.QuoteIdentifier(username), login)
+}
+
+// PasswordExpiryQuery returns the query to expire a role's password now
+// (days 0), never (days < 0) or days days from now. VALID UNTIL is a fixed
+// time, so unlike MariaDB the expiry doesn't move when the password changes.
+func (d *PostgresDriver) PasswordExpiryQuery(username, host string, days int) string {
+	until := "now"
+	switch {
+	case days < 0:
+		until = "infinity"
+	case days > 0:
+		until = time.Now().UTC().AddDate(0, 0, days).Format("2006-01-02 15:04:05+00")
+	}
+	return fmt.Sprintf("ALTER ROLE %s VALID UNTIL '%s'", d.QuoteIdentifier(username), until)
+}
+
 // FlushPrivilegesQuery returns empty string as PostgreSQL doesn't need this
 func (d *PostgresDriver) FlushPrivilegesQuery() string {
 	return "" // PostgreSQL applies privilege changes immediately
 }
 
+// quoteTable quotes a table name that may be prefixed with its schema. Table
+// grants resolve in the connected database, so the database isn't part of it.
+func (d *PostgresDriver) quoteTable(table string) string {
+	if schema, name, ok := strings.Cut(table, "."); ok {
+		return d.QuoteIdentifier(schema) + "." + d.QuoteIdentifier(name)
+	}
+	return d.QuoteIdentifier(table)
+}
+
 // mapPrivileges maps MySQL-style privileges to PostgreSQL equivalents
 func (d *PostgresDriver) mapPrivileges(privs []string) []string {
 	result := make([]string, 0, len(privs))
 	for _, p := range privs {
 		upper := strings.ToUpper(strings.TrimSpace(p))
+		if strings.Contains(upper, "(") {
+			// Column privilege, keep the quoted column names as they are
+			result = append(result, strings.TrimSpace(p))
+			continue
+		}
 		switch upper {
 		case "ALL", "ALL PRIVILEGES":
 			result = append(result, "ALL PRIVILEGES")
@@ -359,6 +423,24 @@ func (d *PostgresDriver) mapPrivileges(privs []string) []string {
 	return result
 }
 
+// tablePrivileges keeps the privileges that apply to tables, falling back
+// to ALL PRIVILEGES when none do
+func (d *PostgresDriver) tablePrivileges(privs []string) []string {
+	var result []string
+	for _, p := range privs {
+		switch p {
+		case "ALL PRIVILEGES":
+			return []string{p}
+		case "SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER":
+			result = append(result, p)
+		}
+	}
+	if len(result) == 0 {
+		return []string{"ALL PRIVILEGES"}
+	}
+	return result
+}
+
 // Enhanced Database Creation
 
 // CreateDatabaseWithOptionsQuery returns the query to create a database with options
@@ -494,3 +576,668 @@ func (d *PostgresDriver) ReplicationStatusQuery() string {
 func (d *PostgresDriver) IsPrimaryQuery() string {
 	return "SELECT NOT pg_is_in_recovery() AS is_primary"
 }
+
+// Constraints
+
+// ForeignKeysQuery returns name, table, column, referenced table, referenced
+// column, update and delete rule, one row per column. With referencing set it
+// lists the keys on other tables that point at table.
+func (d *PostgresDriver) ForeignKeysQuery(table string, referencing bool) string {
+	side := "k.table_name"
+	if referencing {
+		side = "ref.table_name"
+	}
+	return fmt.Sprintf(`SELECT k.constraint_name, k.table_name, k.column_name, ref.table_name, ref.column_name,
+		r.update_rule, r.delete_rule
+	FROM information_schema.key_column_usage k
+	JOIN information_schema.referential_constraints r
+		ON r.constraint_schema = k.constraint_schema AND r.constraint_name = k.constraint_name
+	JOIN information_schema.key_column_usage ref
+		ON ref.constraint_schema = r.unique_constraint_schema AND ref.constraint_name = r.unique_constraint_name
+		AND ref.ordinal_position = k.position_in_unique_constraint
+	WHERE k.table_schema = 'public' AND %s = '%s'
+	ORDER BY k.table_name, k.constraint_name, k.ordinal_position`, side, d.EscapeString(table))
+}
+
+// UniqueConstraintsQuery returns name and column of each unique constraint column
+func (d *PostgresDriver) UniqueConstraintsQuery(table string) string {
+	return fmt.Sprintf(`SELECT tc.constraint_name, k.column_name
+	FROM information_schema.table_constraints tc
+	JOIN information_schema.key_column_usage k
+		ON k.constraint_schema = tc.constraint_schema AND k.constraint_name = tc.constraint_name AND k.table_name = tc.table_name
+	WHERE tc.table_schema = 'public' AND tc.table_name = '%s' AND tc.constraint_type = 'UNIQUE'
+	ORDER BY tc.constraint_name, k.ordinal_position`, d.EscapeString(table))
+}
+
+// CheckConstraintsQuery returns name and clause of each check constraint.
+// NOT NULL shows up as a generated check here, so it's left out.
+func (d *PostgresDriver) CheckConstraintsQuery(table string) string {
+	return fmt.Sprintf(`SELECT tc.constraint_name, cc.check_clause
+	FROM information_schema.table_constraints tc
+	JOIN information_schema.check_constraints cc
+		ON cc.constraint_schema = tc.constraint_schema AND cc.constraint_name = tc.constraint_name
+	WHERE tc.table_schema = 'public' AND tc.table_name = '%s' AND tc.constraint_type = 'CHECK'
+		AND tc.constraint_name NOT LIKE '%%_not_null'
+	ORDER BY tc.constraint_name`, d.EscapeString(table))
+}
+
+// DropForeignKeyQuery returns the query to drop a foreign key
+func (d *PostgresDriver) DropForeignKeyQuery(table, name string) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", d.QuoteIdentifier(table), d.QuoteIdentifier(name))
+}
+
+// Indexes
+
+// ListIndexesQuery returns table, index, column, unique, primary and index
+// type, one row per key column. An empty table lists all tables.
+func (d *PostgresDriver) ListIndexesQuery(table string) string {
+	filter := ""
+	if table != "" {
+		filter = fmt.Sprintf(" AND t.relname = '%s'", d.EscapeString(table))
+	}
+	return `SELECT t.relname, i.relname, pg_get_indexdef(idx.indexrelid, k.n, true),
+		idx.indisunique, idx.indisprimary, am.amname
+	FROM pg_index idx
+	JOIN pg_class i ON i.oid = idx.indexrelid
+	JOIN pg_class t ON t.oid = idx.indrelid
+	JOIN pg_namespace n ON n.oid = t.relnamespace
+	JOIN pg_am am ON am.oid = i.relam
+	CROSS JOIN LATERAL generate_series(1, idx.indnkeyatts) AS k(n)
+	WHERE n.nspname = 'public'` + filter + `
+	ORDER BY t.relname, idx.indisprimary DESC, i.relname, k.n`
+}
+
+// DropIndexQuery returns the query to drop an index. Indexes that back a
+// primary key or unique constraint are dropped through their constraint.
+func (d *PostgresDriver) DropIndexQuery(table, name string) string {
+	return fmt.Sprintf(`DO $$
+BEGIN
+	IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname = '%s' AND conrelid = '%s'::regclass) THEN
+		ALTER TABLE %s DROP CONSTRAINT %s;
+	ELSE
+		DROP INDEX %s;
+	END IF;
+END $$`, d.EscapeString(name), d.EscapeString(d.QuoteIdentifier(table)),
+		d.QuoteIdentifier(table), d.QuoteIdentifier(name), d.QuoteIdentifier(name))
+}
+
+// Table designer
+
+// ColumnTypes returns common column types for the table designer
+func (d *PostgresDriver) ColumnTypes() []string {
+	return []string{
+		"INTEGER", "BIGINT", "SMALLINT", "NUMERIC(10,2)", "REAL", "DOUBLE PRECISION",
+		"VARCHAR(255)", "CHAR(36)", "TEXT",
+		"DATE", "TIMESTAMP", "TIMESTAMPTZ", "TIME",
+		"BOOLEAN", "JSONB", "UUID", "BYTEA",
+	}
+}
+
+// ColumnDefinitionsQuery returns name, type, nullable, default, extra and the
+// single-column unique constraint of each column
+func (d *PostgresDriver) ColumnDefinitionsQuery(table string) string {
+	return fmt.Sprintf(`SELECT a.attname,
+		format_type(a.atttypid, a.atttypmod),
+		CASE WHEN a.attnotnull THEN 'NO' ELSE 'YES' END,
+		pg_get_expr(ad.adbin, ad.adrelid),
+		CASE WHEN a.attidentity <> '' OR pg_get_expr(ad.adbin, ad.adrelid) LIKE 'nextval(%%' THEN 'auto_increment' ELSE '' END,
+		COALESCE((SELECT con.conname FROM pg_constraint con
+			WHERE con.conrelid = c.oid AND con.contype = 'u' AND con.conkey = ARRAY[a.attnum]
+			LIMIT 1), '')
+	FROM pg_attribute a
+	JOIN pg_class c ON c.oid = a.attrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_attrdef ad ON ad.adrelid = a.attrelid AND ad.adnum = a.attnum
+	WHERE n.nspname = 'public' AND c.relname = '%s' AND a.attnum > 0 AND NOT a.attisdropped
+	ORDER BY a.attnum`, d.EscapeString(table))
+}
+
+// PrimaryKeyNameQuery returns the query for the name of a table's primary key
+func (d *PostgresDriver) PrimaryKeyNameQuery(table string) string {
+	return fmt.Sprintf(`SELECT con.conname FROM pg_constraint con
+	JOIN pg_class c ON c.oid = con.conrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = 'public' AND c.relname = '%s' AND con.contype = 'p'`, d.EscapeString(table))
+}
+
+// columnDefinition renders a column for CREATE TABLE / ADD COLUMN
+func (d *PostgresDriver) columnDefinition(col ColumnDef) string {
+	def := d.QuoteIdentifier(col.Name) + " " + col.Type
+	if col.AutoIncrement {
+		def += " GENERATED BY DEFAULT AS IDENTITY"
+	}
+	if !col.Nullable {
+		def += " NOT NULL"
+	}
+	if col.Default != "" {
+		def += " DEFAULT " + col.Default
+	}
+	if col.Unique && !col.PrimaryKey {
+		def += " UNIQUE"
+	}
+	return def
+}
+
+// CreateTableQuery builds a CREATE TABLE statement
+func (d *PostgresDriver) CreateTableQuery(def TableDef) string {
+	var lines []string
+	for _, col := range def.Columns {
+		lines = append(lines, d.columnDefinition(col))
+	}
+	if pk := def.PrimaryKey(); len(pk) > 0 {
+		lines = append(lines, "PRIMARY KEY ("+d.quoteList(pk)+")")
+	}
+	return fmt.Sprintf("CREATE TABLE %s (\n  %s\n)", d.QuoteIdentifier(def.Name), strings.Join(lines, ",\n  "))
+}
+
+// AlterTableQueries builds one statement per change. PostgreSQL can't move
+// columns, so reordering is rejected.
+func (d *PostgresDriver) AlterTableQueries(from, to TableDef, diff tableDiff) ([]string, error) {
+	if diff.Reordered || diff.AddedInside {
+		return nil, fmt.Errorf("PostgreSQL can't reorder columns; new columns must go at the end")
+	}
+
+	table := d.QuoteIdentifier(from.Name)
+	var statements []string
+	alter := func(format string, args ...interface{}) {
+		statements = append(statements, "ALTER TABLE "+table+" "+fmt.Sprintf(format, args...))
+	}
+
+	if diff.PKChanged && from.PrimaryKeyName != "" {
+		alter("DROP CONSTRAINT %s", d.QuoteIdentifier(from.PrimaryKeyName))
+	}
+	for _, col := range diff.UniqueDropped {
+		alter("DROP CONSTRAINT %s", d.QuoteIdentifier(col.UniqueIndex))
+	}
+	for _, col := range diff.Dropped {
+		alter("DROP COLUMN %s", d.QuoteIdentifier(col.Name))
+	}
+
+	for _, m := range diff.Modified {
+		name := d.QuoteIdentifier(m.To.Name)
+		if m.From.Name != m.To.Name {
+			alter("RENAME COLUMN %s TO %s", d.QuoteIdentifier(m.From.Name), name)
+		}
+		if !strings.EqualFold(strings.TrimSpace(m.From.Type), strings.TrimSpace(m.To.Type)) {
+			alter("ALTER COLUMN %s TYPE %s USING %s::%s", name, m.To.Type, name, m.To.Type)
+		}
+		if m.From.Nullable != m.To.Nullable {
+			if m.To.Nullable {
+				alter("ALTER COLUMN %s DROP NOT NULL", name)
+			} else {
+				alter("ALTER COLUMN %s SET NOT NULL", name)
+			}
+		}
+		if m.From.AutoIncrement != m.To.AutoIncrement {
+			if m.To.AutoIncrement {
+				alter("ALTER COLUMN %s ADD GENERATED BY DEFAULT AS IDENTITY", name)
+			} else {
+				// Covers both identity columns and serial defaults
+				alter("ALTER COLUMN %s DROP IDENTITY IF EXISTS", name)
+				alter("ALTER COLUMN %s DROP DEFAULT", name)
+			}
+		}
+		if m.From.Default != m.To.Default {
+			if m.To.Default != "" {
+				alter("ALTER COLUMN %s SET DEFAULT %s", name, m.To.Default)
+			} else if !m.From.AutoIncrement {
+				alter("ALTER COLUMN %s DROP DEFAULT", name)
+			}
+		}
+	}
+
+	for _, col := range diff.Added {
+		alter("ADD COLUMN %s", d.columnDefinition(col))
+	}
+	for _, name := range diff.UniqueAdded {
+		alter("ADD UNIQUE (%s)", d.QuoteIdentifier(name))
+	}
+	if diff.PKChanged && len(diff.PrimaryKey) > 0 {
+		alter("ADD PRIMARY KEY (%s)", d.quoteList(diff.PrimaryKey))
+	}
+	if diff.Renamed {
+		alter("RENAME TO %s", d.QuoteIdentifier(to.Name))
+	}
+	return statements, nil
+}
+
+// Data diff
+
+// RowHashExpr returns an MD5 of a row's text form
+func (d *PostgresDriver) RowHashExpr(columns []string) string {
+	return fmt.Sprintf("md5(ROW(%s)::text)", d.quoteList(columns))
+}
+
+// ChunkChecksumExpr returns an order-independent checksum of the row hashes
+func (d *PostgresDriver) ChunkChecksumExpr(columns []string) string {
+	return fmt.Sprintf("COALESCE(SUM(('x' || substr(%s, 1, 16))::bit(64)::bigint), 0)", d.RowHashExpr(columns))
+}
+
+// CharsetsQuery returns "" as every server supports the standard encodings
+func (d *PostgresDriver) CharsetsQuery() string {
+	return ""
+}
+
+// CollationsQuery returns the query listing the server's collations
+func (d *PostgresDriver) CollationsQuery() string {
+	return "SELECT collname FROM pg_collation"
+}
+
+// ExtensionsQuery returns the query listing the extensions that can be installed
+func (d *PostgresDriver) ExtensionsQuery() string {
+	return "SELECT name FROM pg_available_extensions"
+}
+
+// DataDirectoryQuery returns the query for the server's data directory
+func (d *PostgresDriver) DataDirectoryQuery() string {
+	return "SHOW data_directory"
+}
+
+// AnalyzeTableQuery returns the query refreshing a table's planner statistics
+func (d *PostgresDriver) AnalyzeTableQuery(name ...string) string {
+	quoted := make([]string, len(name))
+	for i, part := range name {
+		quoted[i] = d.QuoteIdentifier(part)
+	}
+	return "ANALYZE " + strings.Join(quoted, ".")
+}
+
+// BloatQueries returns the table and btree index bloat estimates. They
+// compare each relation's pages with the pages its rows would need according
+// to pg_stats (after the ioguix/pgsql-bloat-estimation queries), so tables
+// without statistics are left out
+func (d *PostgresDriver) BloatQueries() []string {
+	return []string{
+		`SELECT schemaname, tblname, '',
+			(bs * tblpages)::bigint,
+			GREATEST((tblpages - est_tblpages) * bs, 0)::bigint
+		FROM (
+			SELECT ceil(reltuples / ((bs - page_hdr) * fillfactor / (tpl_size * 100))) + ceil(toasttuples / 4) AS est_tblpages,
+				tblpages, bs, schemaname, tblname, is_na
+			FROM (
+				SELECT (4 + tpl_hdr_size + tpl_data_size + (2 * ma)
+						- CASE WHEN tpl_hdr_size % ma = 0 THEN ma ELSE tpl_hdr_size % ma END
+						- CASE WHEN ceil(tpl_data_size)::int % ma = 0 THEN ma ELSE ceil(tpl_data_size)::int % ma END
+					) AS tpl_size,
+					heappages + toastpages AS tblpages, reltuples, toasttuples, bs, page_hdr, schemaname, tblname, fillfactor, is_na
+				FROM (
+					SELECT ns.nspname AS schemaname, tbl.relname AS tblname, tbl.reltuples,
+						tbl.relpages AS heappages, COALESCE(toast.relpages, 0) AS toastpages,
+						COALESCE(toast.reltuples, 0) AS toasttuples,
+						COALESCE(substring(array_to_string(tbl.reloptions, ' ') FROM 'fillfactor=([0-9]+)')::smallint, 100) AS fillfactor,
+						current_setting('block_size')::numeric AS bs,
+						CASE WHEN version() ~ 'mingw32|64-bit|x86_64|ppc64|ia64|amd64' THEN 8 ELSE 4 END AS ma,
+						24 AS page_hdr,
+						23 + CASE WHEN max(COALESCE(s.null_frac, 0)) > 0 THEN (7 + count(s.attname)) / 8 ELSE 0 END AS tpl_hdr_size,
+						sum((1 - COALESCE(s.null_frac, 0)) * COALESCE(s.avg_width, 0)) AS tpl_data_size,
+						bool_or(att.atttypid = 'pg_catalog.name'::regtype) OR count(*) <> count(s.attname) AS is_na
+					FROM pg_attribute att
+					JOIN pg_class tbl ON tbl.oid = att.attrelid
+					JOIN pg_namespace ns ON ns.oid = tbl.relnamespace
+					LEFT JOIN pg_stats s ON s.schemaname = ns.nspname AND s.tablename = tbl.relname
+						AND s.inherited = false AND s.attname = att.attname
+					LEFT JOIN pg_class toast ON toast.oid = tbl.reltoastrelid
+					WHERE att.attnum > 0 AND NOT att.attisdropped
+						AND tbl.relkind IN ('r', 'm') AND tbl.reltuples >= 0
+						AND ns.nspname NOT IN ('pg_catalog', 'information_schema') AND ns.nspname !~ '^pg_toast'
+					GROUP BY 1, 2, 3, 4, 5, 6, 7, 8, 9, 10
+				) AS s
+			) AS s2
+		) AS s3
+		WHERE NOT is_na AND tblpages > 0`,
+		`SELECT nspname, tblname, idxname,
+			(bs * relpages)::bigint,
+			GREATEST((relpages - est_pages) * bs, 0)::bigint
+		FROM (
+			SELECT COALESCE(1 + ceil(reltuples / floor((bs - pageopqdata - pagehdr) * fillfactor / (100 * (4 + nulldatahdrwidth)::float))), 0) AS est_pages,
+				bs, nspname, tblname, idxname, relpages, is_na
+			FROM (
+				SELECT bs, nspname, tblname, idxname, reltuples, relpages, fillfactor, pagehdr, pageopqdata, is_na,
+					(index_tuple_hdr_bm + maxalign
+						- CASE WHEN index_tuple_hdr_bm % maxalign = 0 THEN maxalign ELSE index_tuple_hdr_bm % maxalign END
+						+ nulldatawidth + maxalign
+						- CASE WHEN nulldatawidth = 0 THEN 0
+							WHEN nulldatawidth::integer % maxalign = 0 THEN maxalign
+							ELSE nulldatawidth::integer % maxalign END
+					)::numeric AS nulldatahdrwidth
+				FROM (
+					SELECT n.nspname, i.tblname, i.idxname, i.reltuples, i.relpages, i.idxoid, i.fillfactor,
+						current_setting('block_size')::numeric AS bs,
+						CASE WHEN version() ~ 'mingw32|64-bit|x86_64|ppc64|ia64|amd64' THEN 8 ELSE 4 END AS maxalign,
+						24 AS pagehdr, 16 AS pageopqdata,
+						CASE WHEN max(COALESCE(s.null_frac, 0)) = 0 THEN 8 ELSE 8 + ((32 + 8 - 1) / 8) END AS index_tuple_hdr_bm,
+						sum((1 - COALESCE(s.null_frac, 0)) * COALESCE(s.avg_width, 1024)) AS nulldatawidth,
+						max(CASE WHEN i.atttypid = 'pg_catalog.name'::regtype THEN 1 ELSE 0 END) > 0 AS is_na
+					FROM (
+						SELECT ct.relname AS tblname, ct.relnamespace, ic.idxname, ic.reltuples, ic.relpages, ic.idxoid, ic.fillfactor,
+							COALESCE(a1.attname, a2.attname) AS attname, COALESCE(a1.atttypid, a2.atttypid) AS atttypid,
+							CASE WHEN a1.attnum IS NULL THEN ic.idxname ELSE ct.relname END AS attrelname
+						FROM (
+							SELECT idxname, reltuples, relpages, tbloid, idxoid, fillfactor, indkey,
+								generate_series(1, indnatts) AS attpos
+							FROM (
+								SELECT ci.relname AS idxname, ci.reltuples, ci.relpages, i.indrelid AS tbloid, i.indexrelid AS idxoid,
+									COALESCE(substring(array_to_string(ci.reloptions, ' ') FROM 'fillfactor=([0-9]+)')::smallint, 90) AS fillfactor,
+									i.indnatts, string_to_array(textin(int2vectorout(i.indkey)), ' ')::int[] AS indkey
+								FROM pg_index i
+								JOIN pg_class ci ON ci.oid = i.indexrelid
+								WHERE ci.relam = (SELECT oid FROM pg_am WHERE amname = 'btree') AND ci.relpages > 0
+							) AS idx_data
+						) AS ic
+						JOIN pg_class ct ON ct.oid = ic.tbloid
+						LEFT JOIN pg_attribute a1 ON ic.indkey[ic.attpos] <> 0 AND a1.attrelid = ic.tbloid AND a1.attnum = ic.indkey[ic.attpos]
+						LEFT JOIN pg_attribute a2 ON ic.indkey[ic.attpos] = 0 AND a2.attrelid = ic.idxoid AND a2.attnum = ic.attpos
+					) i
+					JOIN pg_namespace n ON n.oid = i.relnamespace
+					JOIN pg_stats s ON s.schemaname = n.nspname AND s.tablename = i.attrelname AND s.attname = i.attname
+					WHERE n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname !~ '^pg_toast'
+					GROUP BY 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11
+				) AS rows_data_stats
+			) AS rows_hdr_pdg_stats
+		) AS relation_stats
+		WHERE NOT is_na`,
+	}
+}
+
+// RebuildTableQuery returns the query rewriting a table without its dead space
+func (d *PostgresDriver) RebuildTableQuery(name ...string) string {
+	quoted := make([]string, len(name))
+	for i, part := range name {
+		quoted[i] = d.QuoteIdentifier(part)
+	}
+	return "VACUUM FULL " + strings.Join(quoted, ".")
+}
+
+// RebuildIndexQuery returns the query rebuilding an index; CONCURRENTLY
+// needs PostgreSQL 12
+func (d *PostgresDriver) RebuildIndexQuery(concurrently bool, name ...string) string {
+	quoted := make([]string, len(name))
+	for i, part := range name {
+		quoted[i] = d.QuoteIdentifier(part)
+	}
+	if concurrently {
+		return "REINDEX INDEX CONCURRENTLY " + strings.Join(quoted, ".")
+	}
+	return "REINDEX INDEX " + strings.Join(quoted, ".")
+}
+
+// TableStatsQueries returns the query for a table's estimated rows, table
+// and index size, dead rows and last manual or automatic analyze and vacuum
+func (d *PostgresDriver) TableStatsQueries(table string) []string {
+	return []string{fmt.Sprintf(`SELECT GREATEST(c.reltuples, 0)::bigint, pg_table_size(c.oid), pg_indexes_size(c.oid),
+		s.n_dead_tup, GREATEST(s.last_analyze, s.last_autoanalyze), GREATEST(s.last_vacuum, s.last_autovacuum)
+	FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_stat_user_tables s ON s.relid = c.oid
+	WHERE n.nspname = 'public' AND c.relname = '%s'`, d.EscapeString(table))}
+}
+
+// MaintenanceQuery returns ANALYZE, VACUUM, VACUUM FULL, REINDEX TABLE, or
+// an amcheck verification of the table's btree indexes. CONCURRENTLY needs
+// PostgreSQL 12.
+func (d *PostgresDriver) MaintenanceQuery(op string, concurrently bool, table string) string {
+	name := d.QuoteIdentifier(table)
+	switch op {
+	case MaintenanceAnalyze:
+		return "ANALYZE " + name
+	case MaintenanceOptimize:
+		return "VACUUM " + name
+	case MaintenanceVacuumFull:
+		return "VACUUM FULL " + name
+	case MaintenanceReindex:
+		if concurrently {
+			return "REINDEX TABLE CONCURRENTLY " + name
+		}
+		return "REINDEX TABLE " + name
+	case MaintenanceCheck:
+		return fmt.Sprintf(`SELECT bt_index_check(i.indexrelid)
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		JOIN pg_am am ON am.oid = c.relam
+		WHERE i.indrelid = '%s'::regclass AND am.amname = 'btree'`, d.EscapeString(name))
+	}
+	return ""
+}
+
+// MaintenanceProgressQuery returns the phase and percentage done of a
+// backend's VACUUM, ANALYZE, VACUUM FULL or REINDEX from the progress views
+// (ANALYZE's needs PostgreSQL 13)
+func (d *PostgresDriver) MaintenanceProgressQuery(session int64) string {
+	return fmt.Sprintf(`SELECT phase, CASE WHEN heap_blks_total > 0 THEN 100.0 * heap_blks_scanned / heap_blks_total END
+		FROM pg_stat_progress_vacuum WHERE pid = %[1]d
+	UNION ALL
+	SELECT phase, CASE WHEN sample_blks_total > 0 THEN 100.0 * sample_blks_scanned / sample_blks_total END
+		FROM pg_stat_progress_analyze WHERE pid = %[1]d
+	UNION ALL
+	SELECT phase, CASE WHEN heap_blks_total > 0 THEN 100.0 * heap_blks_scanned / heap_blks_total END
+		FROM pg_stat_progress_cluster WHERE pid = %[1]d
+	UNION ALL
+	SELECT phase, CASE WHEN blocks_total > 0 THEN 100.0 * blocks_done / blocks_total END
+		FROM pg_stat_progress_create_index WHERE pid = %[1]d`, session)
+}
+
+// SessionIDQuery returns the query for the current backend's process ID
+func (d *PostgresDriver) SessionIDQuery() string {
+	return "SELECT pg_backend_pid()"
+}
+
+// Maintenance advisor
+
+// AdvisorTablesQuery returns each table's estimated rows and bytes, whether
+// it has a primary key and whether it is partitioned or a partition
+func (d *PostgresDriver) AdvisorTablesQuery() string {
+	return `SELECT c.relname, GREATEST(c.reltuples, 0)::bigint, pg_total_relation_size(c.oid),
+		EXISTS (SELECT 1 FROM pg_index i WHERE i.indrelid = c.oid AND i.indisprimary),
+		c.relkind = 'p' OR c.relispartition
+	FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p')
+	ORDER BY c.relname`
+}
+
+// IndexUsageQuery returns how many scans used each index
+func (d *PostgresDriver) IndexUsageQuery() string {
+	return `SELECT relname, indexrelname, idx_scan
+	FROM pg_stat_user_indexes
+	WHERE schemaname = 'public'`
+}
+
+// IndexUsageSinceQuery returns when the database's statistics were last
+// reset, or when the server started if that's later
+func (d *PostgresDriver) IndexUsageSinceQuery() string {
+	return `SELECT GREATEST(stats_reset, pg_postmaster_start_time())
+	FROM pg_stat_database WHERE datname = current_database()`
+}
+
+// CharsetsInUseQuery returns the database's encoding next to UTF8.
+// PostgreSQL has no per table or column encodings.
+func (d *PostgresDriver) CharsetsInUseQuery() string {
+	return `SELECT '', '', pg_encoding_to_char(encoding), 'UTF8'
+	FROM pg_database WHERE datname = current_database()`
+}
+
+// StaleStatisticsQuery returns when each table was last analyzed and how
+// many rows changed since
+func (d *PostgresDriver) StaleStatisticsQuery() string {
+	return `SELECT relname, n_live_tup, GREATEST(last_analyze, last_autoanalyze), n_mod_since_analyze
+	FROM pg_stat_user_tables
+	WHERE schemaname = 'public'
+	ORDER BY relname`
+}
+
+// AddPrimaryKeyQuery returns the statement adding an identity primary key
+// column to a table
+func (d *PostgresDriver) AddPrimaryKeyQuery(table, column string) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY",
+		d.QuoteIdentifier(table), d.QuoteIdentifier(column))
+}
+
+// CharsetFixQuery returns a new database in charset to restore a dump of
+// the database into, since a database's encoding can't be changed
+func (d *PostgresDriver) CharsetFixQuery(database, table, charset string) string {
+	if table != "" {
+		return ""
+	}
+	return fmt.Sprintf("CREATE DATABASE %s ENCODING '%s' TEMPLATE template0",
+		d.QuoteIdentifier(database+"_"+strings.ToLower(charset)), d.EscapeString(charset))
+}
+
+// LargeObjectColumnsQuery returns the oid columns of the public schema's
+// tables. The lo extension's type is a domain over oid, so it's included.
+func (d *PostgresDriver) LargeObjectColumnsQuery() string {
+	return `SELECT table_name, column_name
+	FROM information_schema.columns
+	WHERE table_schema = 'public' AND data_type = 'oid'
+	ORDER BY table_name, ordinal_position`
+}
+
+// LargeObjectsQuery returns the OIDs the source queries select that are
+// large objects, leaving out dangling references
+func (d *PostgresDriver) LargeObjectsQuery(sources []string) string {
+	return `SELECT oid FROM pg_largeobject_metadata
+	WHERE oid IN (` + strings.Join(sources, " UNION ") + `)
+	ORDER BY oid`
+}
+
+// LargeObjectReadQuery returns the query reading $3 bytes of large object
+// $1 from offset $2
+func (d *PostgresDriver) LargeObjectReadQuery() string {
+	return "SELECT lo_get($1::oid, $2, $3)"
+}
+
+// WorkloadSampleQuery returns the current or last statement of every other
+// client backend. Finished statements report how long they ran.
+func (d *PostgresDriver) WorkloadSampleQuery() string {
+	return `SELECT pid, COALESCE(datname, ''), query,
+		EXTRACT(EPOCH FROM query_start) * 1000,
+		EXTRACT(EPOCH FROM COALESCE(CASE WHEN state <> 'active' THEN state_change END, clock_timestamp()) - query_start) * 1000,
+		COALESCE(state, '') = 'active'
+	FROM pg_stat_activity
+	WHERE backend_type = 'client backend' AND pid <> pg_backend_pid()
+		AND query_start IS NOT NULL AND query <> ''`
+}
+
+// PartitionTemplate returns a range partitioned copy of a table to fill in
+// and move the rows into. The partition column has to be part of the
+// primary key.
+func (d *PostgresDriver) PartitionTemplate(table string) string {
+	return fmt.Sprintf(`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS) PARTITION BY RANGE (<column>);
+CREATE TABLE %s PARTITION OF %s FOR VALUES FROM (<from>) TO (<to>);
+CREATE TABLE %s PARTITION OF %s DEFAULT`,
+		d.QuoteIdentifier(table+"_partitioned"), d.QuoteIdentifier(table),
+		d.QuoteIdentifier(table+"_p0"), d.QuoteIdentifier(table+"_partitioned"),
+		d.QuoteIdentifier(table+"_default"), d.QuoteIdentifier(table+"_partitioned"))
+}
+
+// ConnectionSourcesQueries returns the queries listing other client
+// backends' user, client address, application, database and age in seconds.
+// backend_type needs PostgreSQL 10.
+func (d *PostgresDriver) ConnectionSourcesQueries() []string {
+	return []string{
+		`SELECT COALESCE(usename, ''), COALESCE(host(client_addr), 'local'), COALESCE(application_name, ''),
+			COALESCE(datname, ''), EXTRACT(EPOCH FROM now() - backend_start)::bigint
+		FROM pg_stat_activity
+		WHERE pid <> pg_backend_pid() AND backend_type = 'client backend'`,
+		`SELECT COALESCE(usename, ''), COALESCE(host(client_addr), 'local'), COALESCE(application_name, ''),
+			COALESCE(datname, ''), EXTRACT(EPOCH FROM now() - backend_start)::bigint
+		FROM pg_stat_activity
+		WHERE pid <> pg_backend_pid() AND usename IS NOT NULL`,
+	}
+}
+
+// LockWaitsQueries returns the query listing blocked backends and their
+// blockers
+func (d *PostgresDriver) LockWaitsQueries() []string {
+	return []string{
+		`SELECT a.pid, COALESCE(a.usename, ''), COALESCE(a.datname, ''), COALESCE(a.state, ''), COALESCE(a.query, ''),
+		        COALESCE(EXTRACT(EPOCH FROM now() - a.state_change), 0)::bigint,
+		        b.pid, COALESCE(b.usename, ''), COALESCE(b.datname, ''), COALESCE(b.state, ''), COALESCE(b.query, ''),
+		        COALESCE(EXTRACT(EPOCH FROM now() - COALESCE(b.xact_start, b.state_change)), 0)::bigint,
+		        COALESCE(l.mode || ' ' || l.locktype || COALESCE(' on ' || l.relation::regclass::text, ''), '')
+		 FROM pg_stat_activity a
+		 CROSS JOIN LATERAL unnest(pg_blocking_pids(a.pid)) AS blocker(pid)
+		 JOIN pg_stat_activity b ON b.pid = blocker.pid
+		 LEFT JOIN LATERAL (
+		     SELECT mode, locktype, relation FROM pg_locks
+		     WHERE pid = a.pid AND NOT granted LIMIT 1
+		 ) l ON true`,
+	}
+}
+
+// KillSessionQuery returns the statement terminating a backend or cancelling
+// its running query
+func (d *PostgresDriver) KillSessionQuery(id int64, queryOnly bool) string {
+	if queryOnly {
+		return fmt.Sprintf("SELECT pg_cancel_backend(%d)", id)
+	}
+	return fmt.Sprintf("SELECT pg_terminate_backend(%d)", id)
+}
+
+// RunningQueriesQuery returns the query listing client backends with an
+// active statement
+func (d *PostgresDriver) RunningQueriesQuery() string {
+	return `SELECT pid, COALESCE(usename, ''), COALESCE(datname, ''), COALESCE(wait_event_type || ': ' || wait_event, state, ''),
+		       COALESCE(query, ''), COALESCE(EXTRACT(EPOCH FROM now() - query_start), 0)::bigint
+		FROM pg_stat_activity
+		WHERE pid <> pg_backend_pid() AND state = 'active' AND backend_type = 'client backend'
+		ORDER BY query_start`
+}
+
+// SlowQueryStatsQueries returns the pg_stat_statements queries, with the
+// column names of PostgreSQL 13+ first
+func (d *PostgresDriver) SlowQueryStatsQueries() []string {
+	return []string{
+		"SELECT query, calls, total_exec_time, max_exec_time, rows, 0 FROM pg_stat_statements",
+		"SELECT query, calls, total_time, max_time, rows, 0 FROM pg_stat_statements",
+	}
+}
+
+// SlowQueryLogFileQuery returns "" since statement statistics come from
+// pg_stat_statements
+func (d *PostgresDriver) SlowQueryLogFileQuery() string {
+	return ""
+}
+
+// MetricsCountersQuery returns the cumulative transaction count and shared
+// buffer lookups and disk reads across all databases
+func (d *PostgresDriver) MetricsCountersQuery() string {
+	return `SELECT COALESCE(SUM(xact_commit + xact_rollback), 0),
+		COALESCE(SUM(blks_hit + blks_read), 0),
+		COALESCE(SUM(blks_read), 0)
+		FROM pg_stat_database`
+}
+
+// RowSecurityQuery returns whether row security is enabled and forced on
+// tables, and whether the current user bypasses it as owner or through
+// superuser/BYPASSRLS
+func (d *PostgresDriver) RowSecurityQuery(table string) string {
+	filter := "c.relrowsecurity"
+	if table != "" {
+		filter = fmt.Sprintf("c.relname = '%s'", d.EscapeString(table))
+	}
+	return fmt.Sprintf(`SELECT c.relname, c.relrowsecurity, c.relforcerowsecurity,
+		pg_has_role(current_user, c.relowner, 'USAGE'), r.rolsuper OR r.rolbypassrls
+	FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_roles r ON r.rolname = current_user
+	WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p') AND %s
+	ORDER BY c.relname`, filter)
+}
+
+// PoliciesQuery returns the row-level security policies of a table
+func (d *PostgresDriver) PoliciesQuery(table string) string {
+	return fmt.Sprintf(`SELECT policyname, permissive, array_to_string(roles, ','), cmd,
+		COALESCE(qual, ''), COALESCE(with_check, '')
+	FROM pg_policies
+	WHERE schemaname = 'public' AND tablename = '%s'
+	ORDER BY policyname`, d.EscapeString(table))
+}
+
+// quoteList quotes and joins identifiers
+func (d *PostgresDriver) quoteList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = d.QuoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,436 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/buffer"
+)
+
+// dumpPreviewBytes caps the first INSERT kept per table for the row preview
+const dumpPreviewBytes = 256 * 1024
+
+// DumpTable is a table found in a SQL dump
+type DumpTable struct {
+	Database    string `json:"database,omitempty"` // "" when the dump doesn't say
+	Name        string `json:"name"`
+	CreateSQL   string `json:"-"` // The CREATE TABLE statement, "" for a data-only dump
+	CreateLine  int    `json:"create_line,omitempty"`
+	Inserts     int64  `json:"inserts"` // INSERT and REPLACE statements
+	Rows        int64  `json:"rows"`    // Row tuples in them
+	FirstInsert string `json:"-"`       // For the row preview, cut to dumpPreviewBytes
+}
+
+// QualifiedName returns database.table, or just the table when the dump
+// doesn't name its database
+func (t *DumpTable) QualifiedName() string {
+	if t.Database == "" {
+		return t.Name
+	}
+	return t.Database + "." + t.Name
+}
+
+// DumpIndex is what a SQL dump holds, found by reading it through once
+// without importing anything
+type DumpIndex struct {
+	Path       string       `json:"path"`
+	Databases  []string     `json:"databases,omitempty"` // In the order the dump switches to them
+	Tables     []*DumpTable `json:"tables"`              // In the order the dump has them
+	Statements int64        `json:"statements"`
+}
+
+// dumpStatementKind is what a statement of a dump does
+type dumpStatementKind int
+
+const (
+	dumpStatementOther dumpStatementKind = iota
+	dumpStatementUse
+	dumpStatementCreate
+	dumpStatementDrop
+	dumpStatementInsert
+	dumpStatementAlter // ALTER TABLE and CREATE INDEX
+)
+
+var (
+	dumpAlterTableRe  = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:ONLY\s+)?(?:IF\s+EXISTS\s+)?([^\s(,;]+)`)
+	dumpCreateIndexRe = regexp.MustCompile(`(?is)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+.*?\bON\s+(?:ONLY\s+)?([^\s(,;]+)`)
+
+	// The database named in the comments at the top of a YSM export
+	// ("-- Database: shop") or mysqldump ("-- Host: db1    Database: shop")
+	dumpHeaderDatabaseRe = regexp.MustCompile(`(?i)^--.*\bDatabase:\s*(\S+)`)
+)
+
+// classifyDumpStatement returns what a statement does and the table, or
+// for USE the database, it does it to
+func classifyDumpStatement(stmt string) (dumpStatementKind, string) {
+	head := stmt[:min(len(stmt), 512)]
+	var m []string
+	kind := dumpStatementOther
+	switch strings.ToUpper(firstKeyword(head)) {
+	case "USE":
+		if m = useDatabaseRe.FindStringSubmatch(head); m != nil {
+			return dumpStatementUse, m[1]
+		}
+	case "CREATE":
+		if m = createdTableRe.FindStringSubmatch(head); m != nil {
+			kind = dumpStatementCreate
+		} else if m = dumpCreateIndexRe.FindStringSubmatch(head); m != nil {
+			kind = dumpStatementAlter
+		}
+	case "DROP":
+		if m = dumpDropTableRe.FindStringSubmatch(head); m != nil {
+			kind = dumpStatementDrop
+		}
+	case "INSERT", "REPLACE":
+		if m = affectedTableRe.FindStringSubmatch(head); m != nil {
+			kind = dumpStatementInsert
+		}
+	case "ALTER":
+		if m = dumpAlterTableRe.FindStringSubmatch(head); m != nil {
+			kind = dumpStatementAlter
+		}
+	}
+	if m == nil {
+		return dumpStatementOther, ""
+	}
+	return kind, dumpIdentifier(m[1])
+}
+
+// dumpHeaderDatabase returns the database the comments at the top of a dump
+// name, or "" when they don't
+func dumpHeaderDatabase(path string) string {
+	reader, err := buffer.NewBufferedReader(path, 0)
+	if err != nil {
+		return ""
+	}
+	defer reader.Close()
+	for range 20 {
+		line, err := reader.ReadLine()
+		if m := dumpHeaderDatabaseRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			return strings.Trim(m[1], "`\"")
+		}
+		if err != nil {
+			break
+		}
+	}
+	return ""
+}
+
+// IndexDump reads a SQL dump, compressed or split, and lists the databases
+// and tables in it, with each table's CREATE statement and first INSERT.
+// onProgress, when set, is told the statements read so far.
+func IndexDump(path string, onProgress func(statements int64)) (*DumpIndex, error) {
+	reader, err := buffer.NewSQLStatementReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	index := &DumpIndex{Path: path}
+	database := dumpHeaderDatabase(path)
+	if database != "" {
+		index.Databases = append(index.Databases, database)
+	}
+	tables := make(map[string]*DumpTable)
+	for {
+		stmt, line, err := reader.ReadStatement()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		index.Statements++
+		if onProgress != nil && index.Statements%1000 == 0 {
+			onProgress(index.Statements)
+		}
+
+		kind, name := classifyDumpStatement(stmt)
+		if kind == dumpStatementUse {
+			database = name
+			if !containsFold(index.Databases, name) {
+				index.Databases = append(index.Databases, name)
+			}
+			continue
+		}
+		if kind != dumpStatementCreate && kind != dumpStatementInsert {
+			continue
+		}
+
+		key := database + "." + name
+		t := tables[key]
+		if t == nil {
+			t = &DumpTable{Database: database, Name: name}
+			tables[key] = t
+			index.Tables = append(index.Tables, t)
+		}
+		if kind == dumpStatementCreate {
+			if t.CreateSQL == "" {
+				t.CreateSQL = stmt
+				t.CreateLine = line
+			}
+			continue
+		}
+		t.Inserts++
+		t.Rows += countValueTuples(stmt)
+		if t.FirstInsert == "" {
+			t.FirstInsert = stmt[:min(len(stmt), dumpPreviewBytes)]
+		}
+	}
+	if onProgress != nil {
+		onProgress(index.Statements)
+	}
+	return index, nil
+}
+
+// Find returns a table of the dump, named as table or database.table
+func (i *DumpIndex) Find(name string) (*DumpTable, error) {
+	var found []*DumpTable
+	for _, t := range i.Tables {
+		if strings.EqualFold(t.QualifiedName(), name) || strings.EqualFold(t.Name, name) {
+			found = append(found, t)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("table %s isn't in %s", name, i.Path)
+	case 1:
+		return found[0], nil
+	}
+	return nil, fmt.Errorf("%s is in more than one database of the dump; name it as database.table", name)
+}
+
+// Columns returns the column names of the table's CREATE statement
+func (t *DumpTable) Columns() []string {
+	start := strings.Index(t.CreateSQL, "(")
+	end := strings.LastIndex(t.CreateSQL, ")")
+	if start < 0 || end <= start {
+		return nil
+	}
+	var columns []string
+	for _, def := range splitTopLevel(t.CreateSQL[start+1 : end]) {
+		word, _, _ := strings.Cut(strings.TrimSpace(def), " ")
+		switch strings.ToUpper(word) {
+		case "", "PRIMARY", "KEY", "UNIQUE", "INDEX", "CONSTRAINT", "FOREIGN", "CHECK", "FULLTEXT", "SPATIAL", "EXCLUDE", "LIKE", "PERIOD":
+			continue
+		}
+		columns = append(columns, strings.Trim(word, "`\"[]"))
+	}
+	return columns
+}
+
+// dumpInsertColumnsRe finds the column list of an INSERT, if it has one
+var dumpInsertColumnsRe = regexp.MustCompile(`(?is)^(?:INSERT|REPLACE)\b[^(]*?\s*\(([^)]*)\)\s*VALUES?\s*\(`)
+
+// PreviewRows returns the columns and up to limit rows of the table's first
+// INSERT, with strings unquoted and NULL as "NULL"
+func (t *DumpTable) PreviewRows(limit int) ([]string, [][]string) {
+	stmt := t.FirstInsert
+	loc := valuesRe.FindStringIndex(stmt[:min(len(stmt), 4096)])
+	if loc == nil {
+		return t.Columns(), nil
+	}
+
+	columns := t.Columns()
+	if m := dumpInsertColumnsRe.FindStringSubmatch(stmt[:loc[1]]); m != nil {
+		columns = nil
+		for _, c := range splitTopLevel(m[1]) {
+			columns = append(columns, strings.Trim(strings.TrimSpace(c), "`\"[]"))
+		}
+	}
+
+	var rows [][]string
+	for _, tuple := range splitTopLevel(stmt[loc[1]-1:]) {
+		if len(rows) >= limit {
+			break
+		}
+		tuple = strings.TrimSpace(tuple)
+		if !strings.HasPrefix(tuple, "(") || !strings.HasSuffix(tuple, ")") {
+			break // Cut short by dumpPreviewBytes, or an ON DUPLICATE KEY clause
+		}
+		var row []string
+		for _, value := range splitTopLevel(tuple[1 : len(tuple)-1]) {
+			row = append(row, unquoteDumpValue(strings.TrimSpace(value)))
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows
+}
+
+// splitTopLevel splits s at the commas outside quotes and parentheses
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if quote != 0 {
+			switch ch {
+			case '\\':
+				i++ // Skip the escaped character
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '\'', '"', '`':
+			quote = ch
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unquoteDumpValue turns a quoted SQL string literal back into its text;
+// anything else is returned as it is
+func unquoteDumpValue(v string) string {
+	if strings.HasPrefix(v, "E'") {
+		v = v[1:] // PostgreSQL escape string
+	}
+	if len(v) < 2 || v[0] != '\'' || v[len(v)-1] != '\'' {
+		return v
+	}
+	v = v[1 : len(v)-1]
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		ch := v[i]
+		switch {
+		case ch == '\'' && i+1 < len(v) && v[i+1] == '\'':
+			i++
+		case ch == '\\' && i+1 < len(v):
+			i++
+			switch v[i] {
+			case 'n':
+				ch = '\n'
+			case 'r':
+				ch = '\r'
+			case 't':
+				ch = '\t'
+			case '0':
+				ch = 0
+			default:
+				ch = v[i]
+			}
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
+
+// DumpExtractStats is what ExtractDumpTable wrote
+type DumpExtractStats struct {
+	Statements int64  `json:"statements"`
+	Rows       int64  `json:"rows"`
+	Output     string `json:"output"`
+}
+
+// ExtractDumpTable copies one table's statements out of a SQL dump into a
+// new file, compressed by its extension: its DROP, CREATE, INSERT, ALTER
+// and CREATE INDEX statements, after the session settings the dump starts
+// with. table is a name from the dump's index.
+func ExtractDumpTable(path string, table *DumpTable, output string) (*DumpExtractStats, error) {
+	reader, err := buffer.NewSQLStatementReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	writer, err := buffer.NewBufferedWriter(output, buffer.DetectCompression(output), 0)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(writer)
+
+	fmt.Fprintf(w, "-- YSM (Yandere SQL Manager) Table Extract\n")
+	fmt.Fprintf(w, "-- From: %s\n", path)
+	fmt.Fprintf(w, "-- Table: %s\n\n", table.QualifiedName())
+
+	stats := &DumpExtractStats{Output: output}
+	database := dumpHeaderDatabase(path)
+	preamble := true
+	for {
+		stmt, _, err := reader.ReadStatement()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writer.Close()
+			return nil, err
+		}
+
+		kind, name := classifyDumpStatement(stmt)
+		switch kind {
+		case dumpStatementUse:
+			database = name
+			continue
+		case dumpStatementOther:
+			// Session settings at the top of the dump come along, except
+			// autocommit, whose COMMIT is at the end
+			if preamble && isDumpSessionSetting(stmt) {
+				fmt.Fprintf(w, "%s;\n", stmt)
+			}
+			continue
+		}
+		preamble = false
+		if !strings.EqualFold(name, table.Name) || !strings.EqualFold(database, table.Database) {
+			continue
+		}
+		if kind == dumpStatementInsert {
+			stats.Rows += countValueTuples(stmt)
+		}
+		fmt.Fprintf(w, "%s;\n", stmt)
+		stats.Statements++
+	}
+
+	if err := w.Flush(); err != nil {
+		writer.Close()
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	if stats.Statements == 0 {
+		return stats, fmt.Errorf("no statements for %s in %s", table.QualifiedName(), path)
+	}
+	return stats, nil
+}
+
+// isDumpSessionSetting reports whether a statement only sets up the
+// session, like SET NAMES or mysqldump's /*!40101 SET ... */
+func isDumpSessionSetting(stmt string) bool {
+	upper := strings.ToUpper(stmt[:min(len(stmt), 64)])
+	if strings.Contains(upper, "AUTOCOMMIT") {
+		return false
+	}
+	return strings.HasPrefix(upper, "SET ") || strings.HasPrefix(upper, "/*!") && strings.Contains(upper, " SET ")
+}
@@ -0,0 +1,210 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// ErrorHint explains a server error and suggests a fix
+type ErrorHint struct {
+	Title       string // Short name, e.g. "Access denied"
+	Explanation string // What the error means
+	Fix         string // What to try next
+}
+
+var (
+	hintAccessDenied = &ErrorHint{
+		Title:       "Access denied",
+		Explanation: "The server rejected the user name or password.",
+		Fix:         "Check the password, and that the user exists for the host you connect from (MariaDB matches 'user'@'host').",
+	}
+	hintNoPrivilege = &ErrorHint{
+		Title:       "Insufficient privileges",
+		Explanation: "The user is logged in but isn't allowed to do this.",
+		Fix:         "Grant the missing privilege (ysm user grant), or connect as a user that has it.",
+	}
+	hintTooManyConnections = &ErrorHint{
+		Title:       "Too many connections",
+		Explanation: "The server has reached its connection limit.",
+		Fix:         "Close idle clients or raise max_connections; check for applications leaking connections.",
+	}
+	hintUserConnections = &ErrorHint{
+		Title:       "User connection limit reached",
+		Explanation: "This user has used up its own connection limit.",
+		Fix:         "Close other sessions of this user, or raise its MAX_USER_CONNECTIONS / CONNECTION LIMIT.",
+	}
+	hintUnknownCollation = &ErrorHint{
+		Title:       "Unknown collation or character set",
+		Explanation: "The server doesn't support a collation or character set used by the statement, often from a dump made on a newer server (e.g. utf8mb4_0900_ai_ci from MySQL 8).",
+		Fix:         "Replace it with one the server supports, e.g. utf8mb4_unicode_ci, or check SHOW COLLATION.",
+	}
+	hintDiskFull = &ErrorHint{
+		Title:       "Disk or table full",
+		Explanation: "The server ran out of space while writing data.",
+		Fix:         "Free disk space on the server (old binlogs, WAL, backups) or grow the volume, then retry.",
+	}
+	hintPacketTooLarge = &ErrorHint{
+		Title:       "Packet too large",
+		Explanation: "A single statement or row is bigger than max_allowed_packet.",
+		Fix:         "Raise max_allowed_packet on the server (e.g. ysm set --global max_allowed_packet 1073741824), or import with smaller batches.",
+	}
+	hintUnknownDatabase = &ErrorHint{
+		Title:       "Unknown database",
+		Explanation: "The database doesn't exist or the user can't see it.",
+		Fix:         "Check the name with ysm list, create it with ysm db create, or grant access to it.",
+	}
+	hintUnknownTable = &ErrorHint{
+		Title:       "Unknown table",
+		Explanation: "The table doesn't exist in the current database.",
+		Fix:         "Check the name and the selected database; names can be case-sensitive.",
+	}
+	hintDuplicateKey = &ErrorHint{
+		Title:       "Duplicate key",
+		Explanation: "A row with the same unique or primary key value already exists.",
+		Fix:         "Change the conflicting value, or update the existing row instead of inserting.",
+	}
+	hintForeignKey = &ErrorHint{
+		Title:       "Foreign key violation",
+		Explanation: "The change would leave a row pointing at a parent that doesn't exist.",
+		Fix:         "Insert or keep the referenced parent row first, or delete dependent rows before the parent.",
+	}
+	hintDeadlock = &ErrorHint{
+		Title:       "Lock conflict",
+		Explanation: "The statement waited too long for a lock or was chosen as a deadlock victim.",
+		Fix:         "Retry the statement; keep transactions short and touch tables in a consistent order.",
+	}
+	hintConnectionRefused = &ErrorHint{
+		Title:       "Connection refused",
+		Explanation: "Nothing is listening on that host and port.",
+		Fix:         "Check the server is running and the host, port and socket are right; firewalls can also cause this.",
+	}
+	hintUnknownHost = &ErrorHint{
+		Title:       "Unknown host",
+		Explanation: "The host name couldn't be resolved.",
+		Fix:         "Check the spelling of the host, or use an IP address.",
+	}
+	hintTimeout = &ErrorHint{
+		Title:       "Connection timed out",
+		Explanation: "The server didn't answer in time.",
+		Fix:         "Check the host is reachable from here (VPN, firewall, security groups) and not overloaded.",
+	}
+	hintProtectedDatabase = &ErrorHint{
+		Title:       "Protected database",
+		Explanation: "YSM won't drop or truncate system databases or databases listed under system_databases.protected.",
+		Fix:         "Run the command with --unlock-protected, or set system_databases.unlocked in the config if you really mean it.",
+	}
+	hintConnectionLost = &ErrorHint{
+		Title:       "Connection lost",
+		Explanation: "The server closed the connection, usually after wait_timeout, a restart or a packet that was too large.",
+		Fix:         "Reconnect and retry; for large imports raise max_allowed_packet and wait_timeout.",
+	}
+)
+
+// mariadbHints maps MariaDB/MySQL error numbers to hints
+var mariadbHints = map[uint16]*ErrorHint{
+	1044: hintNoPrivilege,        // ER_DBACCESS_DENIED_ERROR
+	1045: hintAccessDenied,       // ER_ACCESS_DENIED_ERROR
+	1142: hintNoPrivilege,        // ER_TABLEACCESS_DENIED_ERROR
+	1143: hintNoPrivilege,        // ER_COLUMNACCESS_DENIED_ERROR
+	1227: hintNoPrivilege,        // ER_SPECIFIC_ACCESS_DENIED_ERROR
+	1040: hintTooManyConnections, // ER_CON_COUNT_ERROR
+	1203: hintUserConnections,    // ER_TOO_MANY_USER_CONNECTIONS
+	1226: hintUserConnections,    // ER_USER_LIMIT_REACHED
+	1273: hintUnknownCollation,   // ER_UNKNOWN_COLLATION
+	1115: hintUnknownCollation,   // ER_UNKNOWN_CHARACTER_SET
+	1021: hintDiskFull,           // ER_DISK_FULL
+	1114: hintDiskFull,           // ER_RECORD_FILE_FULL
+	1153: hintPacketTooLarge,     // ER_NET_PACKET_TOO_LARGE
+	1049: hintUnknownDatabase,    // ER_BAD_DB_ERROR
+	1146: hintUnknownTable,       // ER_NO_SUCH_TABLE
+	1062: hintDuplicateKey,       // ER_DUP_ENTRY
+	1451: hintForeignKey,         // ER_ROW_IS_REFERENCED_2
+	1452: hintForeignKey,         // ER_NO_REFERENCED_ROW_2
+	1205: hintDeadlock,           // ER_LOCK_WAIT_TIMEOUT
+	1213: hintDeadlock,           // ER_LOCK_DEADLOCK
+}
+
+// postgresHints maps PostgreSQL SQLSTATE codes to hints
+var postgresHints = map[pq.ErrorCode]*ErrorHint{
+	"28P01": hintAccessDenied,       // invalid_password
+	"28000": hintAccessDenied,       // invalid_authorization_specification
+	"42501": hintNoPrivilege,        // insufficient_privilege
+	"53300": hintTooManyConnections, // too_many_connections
+	"53100": hintDiskFull,           // disk_full
+	"3D000": hintUnknownDatabase,    // invalid_catalog_name
+	"42P01": hintUnknownTable,       // undefined_table
+	"23505": hintDuplicateKey,       // unique_violation
+	"23503": hintForeignKey,         // foreign_key_violation
+	"40P01": hintDeadlock,           // deadlock_detected
+	"55P03": hintDeadlock,           // lock_not_available
+	"57P01": hintConnectionLost,     // admin_shutdown
+}
+
+// ExplainError returns a hint for a known server error, or nil
+func ExplainError(err error) *ErrorHint {
+	if err == nil {
+		return nil
+	}
+
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return mariadbHints[myErr.Number]
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// undefined_object covers more than collations, so check the message
+		if pqErr.Code == "42704" && strings.Contains(pqErr.Message, "collation") {
+			return hintUnknownCollation
+		}
+		return postgresHints[pqErr.Code]
+	}
+
+	if errors.Is(err, ErrProtectedDatabase) {
+		return hintProtectedDatabase
+	}
+	if errors.Is(err, mysql.ErrPktTooLarge) {
+		return hintPacketTooLarge
+	}
+	if errors.Is(err, mysql.ErrInvalidConn) {
+		return hintConnectionLost
+	}
+
+	// Network errors come from the OS, match them by text
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "connection refused"):
+		return hintConnectionRefused
+	case strings.Contains(msg, "no such host"):
+		return hintUnknownHost
+	case strings.Contains(msg, "i/o timeout"):
+		return hintTimeout
+	case strings.Contains(msg, "broken pipe"), strings.Contains(msg, "connection reset"):
+		return hintConnectionLost
+	case strings.Contains(msg, "no space left on device"):
+		return hintDiskFull
+	}
+
+	return nil
+}
@@ -22,12 +22,15 @@ import (
 	"bufio"
 	"bytes"
 	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -36,6 +39,7 @@ import (
 
 	"github.com/blubskye/yandere_sql_manager/internal/buffer"
 	"github.com/blubskye/yandere_sql_manager/internal/logging"
+	"github.com/blubskye/yandere_sql_manager/internal/scratch"
 )
 
 // CompressionType represents supported compression formats
@@ -60,21 +64,34 @@ const (
 
 // ExportOptions configures the export behavior
 type ExportOptions struct {
-	FilePath        string
-	Database        string
-	Tables          []string        // Empty = all tables
-	NoData          bool            // Export structure only
-	NoCreate        bool            // Export data only
-	AddDropTable    bool            // Add DROP TABLE statements
-	Compression     CompressionType // Compression type (auto-detected from extension if empty)
-	BufferSize      int             // Write buffer size (0 = default 64KB)
-	BatchSize       int             // Rows per INSERT batch (0 = default 1000)
-	IncludeVars     bool            // Include SET statements for session variables
-	IncludeVarsList []string        // Specific variables to include (empty = common variables)
-	Format          DumpFormat      // Dump format (PostgreSQL: sql, custom, tar, dir)
-	UseNativeTool   bool            // Use pg_dump/mysqldump instead of built-in export
-	Parallel        int             // Number of parallel workers for export (0 = sequential)
-	OnProgress      func(currentTable string, tableNum, totalTables int, rowsExported int64)
+	FilePath         string
+	Database         string
+	Tables           []string         // Empty = all tables
+	NoData           bool             // Export structure only
+	NoCreate         bool             // Export data only
+	AddDropTable     bool             // Add DROP TABLE statements
+	Compression      CompressionType  // Compression type (auto-detected from extension if empty)
+	CompressionLevel int              // 0 = the type's default (gzip 6, xz 6, zstd 3)
+	BufferSize       int              // Write buffer size (0 = default 64KB)
+	BatchSize        int              // Rows per INSERT batch (0 = default 1000)
+	IncludeVars      bool             // Include SET statements for session variables
+	IncludeVarsList  []string         // Specific variables to include (empty = common variables)
+	Format           DumpFormat       // Dump format (PostgreSQL: sql, custom, tar, dir)
+	UseNativeTool    bool             // Use pg_dump/mysqldump instead of built-in export
+	Parallel         int              // Number of parallel workers for export (0 = sequential)
+	Masking          *MaskingConfig   // Anonymize matching columns (built-in SQL export only)
+	Dialect          OutputDialect    // Write SQL Server or Oracle syntax (built-in SQL export only)
+	Converters       *ValueConverters // Convert values of chosen source types for the Dialect (nil = none)
+	SampleRows       int              // Rows per table, first by primary key (0 = all rows, built-in SQL export only)
+	Scripts          OperationScripts // SQL run on the connection before and after the export
+	SplitSize        int64            // Split the dump into numbered parts of at most this many bytes, with a manifest (0 = one file)
+	Context          context.Context  // Cancels the built-in export between tables and batches of rows (nil = never)
+	NoOwner          bool             // Leave out what only the exporting role may run, so the dump restores under any role (PostgreSQL)
+	NoACL            bool             // Leave out privileges (GRANT/REVOKE) (PostgreSQL)
+
+	// OnProgress is called at each table and between batches of its rows,
+	// with the SQL bytes written so far before compression
+	OnProgress func(currentTable string, tableNum, totalTables int, rowsExported, bytesWritten int64)
 }
 
 // ExportStats contains statistics about the export
@@ -85,6 +102,11 @@ type ExportStats struct {
 	Duration       time.Duration
 	Compressed     bool
 	OutputFile     string
+	DialectIssues  []DialectIssue        // What didn't translate to the output dialect
+	FilteredTables []string              // Tables whose rows row-level security may have hidden
+	LargeObjects   int                   // PostgreSQL large objects referenced by the rows, dumped with them
+	Scripts        []ScriptResult        // Before and after scripts that ran
+	Split          *buffer.SplitManifest // Parts the dump was split into (nil when not split)
 }
 
 // ExportSQL exports a database to a SQL file with improved buffering
@@ -94,8 +116,88 @@ func (c *Connection) ExportSQL(opts ExportOptions) error {
 	return err
 }
 
-// ExportSQLWithStats exports a database and returns detailed statistics
+// ExportSQLWithStats exports a database and returns detailed statistics.
+// When only an after script fails the dump is still written, and its stats
+// are returned along with the error. With a SplitSize the dump is written
+// straight into parts, and OutputFile is their manifest. A cancelled export
+// removes its partial file.
 func (c *Connection) ExportSQLWithStats(opts ExportOptions) (*ExportStats, error) {
+	if opts.SplitSize > 0 && opts.Format == DumpFormatDir {
+		return nil, fmt.Errorf("directory format dumps can't be split")
+	}
+	stats, err := c.exportSQLWithScripts(opts)
+	if errors.Is(err, context.Canceled) {
+		os.Remove(opts.FilePath)
+		removeSplitExport(opts)
+		return nil, fmt.Errorf("export cancelled; the partial file was removed")
+	}
+	if opts.SplitSize <= 0 {
+		return stats, err
+	}
+	if stats == nil {
+		// A failed dump's manifest would pass for a whole one
+		removeSplitExport(opts)
+		return nil, err
+	}
+
+	split, splitErr := buffer.ReadSplitManifest(opts.FilePath + buffer.SplitManifestSuffix)
+	if splitErr != nil {
+		return nil, fmt.Errorf("failed to split the dump: %w", splitErr)
+	}
+	stats.Split = split
+	stats.OutputFile = split.ManifestPath()
+	stats.BytesWritten = split.Size
+	return stats, err
+}
+
+// removeSplitExport deletes the parts and manifest of a split export
+func removeSplitExport(opts ExportOptions) {
+	if opts.SplitSize <= 0 {
+		return
+	}
+	if split, err := buffer.ReadSplitManifest(opts.FilePath + buffer.SplitManifestSuffix); err == nil {
+		split.Remove()
+	}
+}
+
+// createExportFile creates the file a dump is written to, or with a
+// SplitSize the first of its parts; the manifest is written on Close
+func createExportFile(opts ExportOptions) (io.WriteCloser, error) {
+	if opts.SplitSize > 0 {
+		return buffer.CreateSplit(opts.FilePath, opts.SplitSize)
+	}
+	file, err := os.Create(opts.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	return file, nil
+}
+
+// exportSQLWithScripts exports a database, running its scripts around it
+func (c *Connection) exportSQLWithScripts(opts ExportOptions) (*ExportStats, error) {
+	if opts.Scripts.Empty() {
+		return c.exportSQL(opts)
+	}
+
+	// Scripts run against the database being exported
+	if opts.Database != "" {
+		if err := c.UseDatabase(opts.Database); err != nil {
+			return nil, err
+		}
+	}
+	var stats *ExportStats
+	results, err := c.withScripts(opts.Scripts, func() error {
+		var exportErr error
+		stats, exportErr = c.exportSQL(opts)
+		return exportErr
+	})
+	if stats != nil {
+		stats.Scripts = results
+	}
+	return stats, err
+}
+
+func (c *Connection) exportSQL(opts ExportOptions) (*ExportStats, error) {
 	startTime := time.Now()
 	stats := &ExportStats{}
 
@@ -115,6 +217,20 @@ func (c *Connection) ExportSQLWithStats(opts ExportOptions) (*ExportStats, error
 		}
 	}
 
+	// Native tools write rows themselves, so masking can't be applied
+	if opts.Masking != nil && (opts.UseNativeTool || opts.Format != DumpFormatSQL) {
+		return nil, fmt.Errorf("masking is only supported for plain SQL exports without --native")
+	}
+	if opts.Dialect != DialectNative && (opts.UseNativeTool || opts.Format != DumpFormatSQL) {
+		return nil, fmt.Errorf("output dialects are only supported for plain SQL exports without --native")
+	}
+	if opts.SampleRows < 0 {
+		return nil, fmt.Errorf("sample rows must not be negative")
+	}
+	if opts.SampleRows > 0 && (opts.UseNativeTool || opts.Format != DumpFormatSQL) {
+		return nil, fmt.Errorf("sample rows are only supported for plain SQL exports without --native")
+	}
+
 	// Use native tool for PostgreSQL non-SQL formats or if explicitly requested
 	if c.Config.Type == DatabaseTypePostgres && (opts.Format != DumpFormatSQL || opts.UseNativeTool) {
 		return c.exportWithPgDump(opts)
@@ -156,9 +272,9 @@ func (c *Connection) ExportSQLWithStats(opts ExportOptions) (*ExportStats, error
 	}
 
 	// Create output file
-	file, err := os.Create(opts.FilePath)
+	file, err := createExportFile(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create file: %w", err)
+		return nil, err
 	}
 	defer file.Close()
 
@@ -169,7 +285,7 @@ func (c *Connection) ExportSQLWithStats(opts ExportOptions) (*ExportStats, error
 	switch compression {
 	case CompressionXZ:
 		stats.Compressed = true
-		compressCmd = exec.Command("xz", "-c", "-6") // Level 6 is good balance
+		compressCmd = exec.Command("xz", "-c", compressionLevelFlag(CompressionXZ, opts.CompressionLevel))
 		compressCmd.Stdout = file
 		stdin, err := compressCmd.StdinPipe()
 		if err != nil {
@@ -186,7 +302,7 @@ func (c *Connection) ExportSQLWithStats(opts ExportOptions) (*ExportStats, error
 
 	case CompressionZstd:
 		stats.Compressed = true
-		compressCmd = exec.Command("zstd", "-c", "-3") // Level 3 is fast with good compression
+		compressCmd = exec.Command("zstd", "-c", compressionLevelFlag(CompressionZstd, opts.CompressionLevel))
 		compressCmd.Stdout = file
 		stdin, err := compressCmd.StdinPipe()
 		if err != nil {
@@ -203,7 +319,10 @@ func (c *Connection) ExportSQLWithStats(opts ExportOptions) (*ExportStats, error
 
 	case CompressionGzip:
 		stats.Compressed = true
-		gzWriter := gzip.NewWriter(file)
+		gzWriter, err := gzip.NewWriterLevel(file, CompressionLevelOrDefault(CompressionGzip, opts.CompressionLevel))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip level: %w", err)
+		}
 		defer gzWriter.Close()
 		writer = gzWriter
 
@@ -211,19 +330,40 @@ func (c *Connection) ExportSQLWithStats(opts ExportOptions) (*ExportStats, error
 		writer = file
 	}
 
-	// Wrap in buffered writer
-	bufWriter := bufio.NewWriterSize(writer, opts.BufferSize)
+	// Wrap in buffered writer, counting the SQL written for progress
+	metered := &meteredWriter{w: writer}
+	bufWriter := bufio.NewWriterSize(metered, opts.BufferSize)
 	defer bufWriter.Flush()
+	written := func() int64 {
+		return metered.n.Load() + int64(bufWriter.Buffered())
+	}
 
 	// Write header
 	fmt.Fprintf(bufWriter, "-- YSM (Yandere SQL Manager) Database Export\n")
 	fmt.Fprintf(bufWriter, "-- Database: %s\n", opts.Database)
 	fmt.Fprintf(bufWriter, "-- Type: %s\n", c.Config.Type)
 	fmt.Fprintf(bufWriter, "-- Generated: %s\n", time.Now().Format(time.RFC3339))
+	var dialect *dialectWriter
+	if opts.Dialect != DialectNative {
+		dialect = newDialectWriter(opts.Dialect)
+		dialect.converters = opts.Converters
+		fmt.Fprintf(bufWriter, "-- Dialect: %s\n", opts.Dialect)
+	}
+	if opts.SampleRows > 0 && !opts.NoData {
+		fmt.Fprintf(bufWriter, "-- Sample: first %d rows per table by primary key\n", opts.SampleRows)
+	}
+	if opts.NoOwner {
+		fmt.Fprintf(bufWriter, "-- Ownership: not included, restores under any role\n")
+	}
+	if opts.NoACL {
+		fmt.Fprintf(bufWriter, "-- Privileges: not included\n")
+	}
 	fmt.Fprintf(bufWriter, "-- \"I'll never let your databases go~\"\n\n")
 
-	// Include session variables if requested
-	if opts.IncludeVars {
+	// Include session variables if requested (they only mean something to the source engine)
+	if opts.IncludeVars && dialect != nil {
+		dialect.note("", "", "session variables are specific to %s and were not included", c.Config.Type)
+	} else if opts.IncludeVars {
 		fmt.Fprintf(bufWriter, "-- Session Variables\n")
 		varList := opts.IncludeVarsList
 		if len(varList) == 0 {
@@ -244,7 +384,11 @@ func (c *Connection) ExportSQLWithStats(opts ExportOptions) (*ExportStats, error
 	}
 
 	// Write database-specific header
-	fmt.Fprintf(bufWriter, "%s\n", c.Driver.ExportHeader())
+	if dialect != nil {
+		fmt.Fprintf(bufWriter, "%s\n", dialect.header())
+	} else {
+		fmt.Fprintf(bufWriter, "%s\n", exportSessionSQL(c.Driver.ExportHeader(), opts))
+	}
 
 	// Get tables to export
 	tables := opts.Tables
@@ -258,6 +402,39 @@ func (c *Connection) ExportSQLWithStats(opts ExportOptions) (*ExportStats, error
 		}
 	}
 
+	// Row-level security filters SELECT * without an error, so the dump can
+	// silently miss rows
+	if !opts.NoData {
+		stats.FilteredTables, _ = c.RowSecurityFilteredTables(tables)
+	}
+
+	// Large objects go ahead of the tables, so their rows can look up the
+	// OIDs the objects get on restore
+	var objects *largeObjects
+	if !opts.NoData {
+		objects, err = c.findLargeObjects(tables)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if objects != nil && dialect != nil {
+		for _, table := range tables {
+			for _, column := range objects.columns[table] {
+				dialect.note(table, column, "large objects aren't exported, only their OIDs")
+			}
+		}
+		objects = nil
+	}
+	if objects != nil {
+		if opts.OnProgress != nil {
+			opts.OnProgress("large objects", 0, len(tables), 0, written())
+		}
+		if err := c.writeLargeObjects(orBackground(opts.Context), bufWriter, objects); err != nil {
+			return nil, err
+		}
+		stats.LargeObjects = objects.count()
+	}
+
 	// Determine parallelism
 	parallelWorkers := opts.Parallel
 	if parallelWorkers <= 0 {
@@ -267,10 +444,11 @@ func (c *Connection) ExportSQLWithStats(opts ExportOptions) (*ExportStats, error
 
 	// Export tables - parallel or sequential
 	var totalRows int64
-	if parallelWorkers > 1 && len(tables) > 1 {
+	manifest := &DumpManifest{}
+	if parallelWorkers > 1 && len(tables) > 1 && dialect == nil {
 		// Parallel export
 		logging.Debug("Exporting %d tables with %d parallel workers", len(tables), parallelWorkers)
-		rowCount, err := c.exportTablesParallel(bufWriter, tables, opts, parallelWorkers)
+		rowCount, err := c.exportTablesParallel(bufWriter, tables, opts, parallelWorkers, manifest, objects)
 		if err != nil {
 			return nil, err
 		}
@@ -278,15 +456,29 @@ func (c *Connection) ExportSQLWithStats(opts ExportOptions) (*ExportStats, error
 		stats.TablesExported = len(tables)
 	} else {
 		// Sequential export
+		ctx := orBackground(opts.Context)
 		for i, tableName := range tables {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
 			if opts.OnProgress != nil {
-				opts.OnProgress(tableName, i+1, len(tables), totalRows)
+				opts.OnProgress(tableName, i+1, len(tables), totalRows, written())
 			}
 
 			fmt.Fprintf(bufWriter, "-- --------------------------------------------------------\n")
 			fmt.Fprintf(bufWriter, "-- Table structure for table %s\n", c.QuoteIdentifier(tableName))
 			fmt.Fprintf(bufWriter, "-- --------------------------------------------------------\n\n")
 
+			if dialect != nil {
+				rowCount, err := c.exportTableDialect(bufWriter, tableName, opts, dialect)
+				if err != nil {
+					return nil, err
+				}
+				totalRows += rowCount
+				stats.TablesExported++
+				continue
+			}
+
 			// Export table structure
 			if !opts.NoCreate {
 				if opts.AddDropTable {
@@ -302,11 +494,18 @@ func (c *Connection) ExportSQLWithStats(opts ExportOptions) (*ExportStats, error
 
 			// Export table data
 			if !opts.NoData {
-				rowCount, err := c.exportTableDataBuffered(bufWriter, tableName, opts.BatchSize)
+				var onBatch func(rows int64)
+				if opts.OnProgress != nil {
+					onBatch = func(rows int64) {
+						opts.OnProgress(tableName, i+1, len(tables), totalRows+rows, written())
+					}
+				}
+				sum, err := c.exportTableDataBuffered(ctx, bufWriter, tableName, opts.BatchSize, opts.SampleRows, opts.Masking, objects, onBatch)
 				if err != nil {
 					return nil, fmt.Errorf("failed to export data for %s: %w", tableName, err)
 				}
-				totalRows += rowCount
+				totalRows += sum.rows
+				manifest.add(tableName, sum)
 			}
 
 			stats.TablesExported++
@@ -314,7 +513,24 @@ func (c *Connection) ExportSQLWithStats(opts ExportOptions) (*ExportStats, error
 	}
 
 	// Write database-specific footer
-	fmt.Fprintf(bufWriter, "\n%s", c.Driver.ExportFooter())
+	if dialect != nil {
+		if !opts.NoCreate {
+			if err := c.writeDialectForeignKeys(bufWriter, tables, dialect); err != nil {
+				return nil, err
+			}
+		}
+		fmt.Fprintf(bufWriter, "\n%s", dialect.report())
+		stats.DialectIssues = dialect.issues
+	} else {
+		if objects != nil {
+			writeLargeObjectsEnd(bufWriter)
+		}
+		fmt.Fprintf(bufWriter, "\n%s", exportSessionSQL(c.Driver.ExportFooter(), opts))
+		// Structure-only dumps have no rows to account for
+		if !opts.NoData {
+			manifest.write(bufWriter)
+		}
+	}
 
 	// Ensure everything is flushed
 	bufWriter.Flush()
@@ -323,14 +539,58 @@ func (c *Connection) ExportSQLWithStats(opts ExportOptions) (*ExportStats, error
 	stats.Duration = time.Since(startTime)
 	stats.OutputFile = opts.FilePath
 
-	// Get file size
-	if info, err := file.Stat(); err == nil {
+	// Get file size; a split dump's is known once its manifest is written
+	if info, err := os.Stat(opts.FilePath); err == nil {
 		stats.BytesWritten = info.Size()
 	}
 
 	return stats, nil
 }
 
+// ExportSizeEstimate guesses the disk space of an uncompressed dump of the
+// whole database from its size on the server, for free space checks. It is
+// 0 when unknown, without data or for some of the tables only.
+func (c *Connection) ExportSizeEstimate(opts ExportOptions) int64 {
+	if opts.NoData || len(opts.Tables) > 0 {
+		return 0
+	}
+	size, err := c.GetDatabaseSize(opts.Database)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// ExportRowEstimate guesses the rows an export writes from the server's
+// table statistics, so progress can show an ETA. It is 0 when unknown or
+// without data. Like the export, it switches to the export's database.
+func (c *Connection) ExportRowEstimate(opts ExportOptions) int64 {
+	if opts.NoData {
+		return 0
+	}
+	if opts.Database != "" {
+		if err := c.UseDatabase(opts.Database); err != nil {
+			return 0
+		}
+	}
+	tables, err := c.ListTables()
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, t := range tables {
+		if len(opts.Tables) > 0 && !slices.Contains(opts.Tables, t.Name) {
+			continue
+		}
+		rows := max(t.Rows, 0)
+		if opts.SampleRows > 0 {
+			rows = min(rows, int64(opts.SampleRows))
+		}
+		total += rows
+	}
+	return total
+}
+
 func (c *Connection) getCreateTable(tableName string) (string, error) {
 	if c.Config.Type == DatabaseTypePostgres {
 		// PostgreSQL: Build CREATE TABLE from information_schema
@@ -427,24 +687,51 @@ func (c *Connection) buildCreateTablePostgres(tableName string) (string, error)
 	return createStmt, nil
 }
 
-// exportTableDataBuffered exports table data with batched INSERTs
-func (c *Connection) exportTableDataBuffered(writer *bufio.Writer, tableName string, batchSize int) (int64, error) {
-	rows, err := c.DB.Query(fmt.Sprintf("SELECT * FROM %s", c.QuoteIdentifier(tableName)))
+// exportSelectQuery returns the SELECT that reads a table's rows for export,
+// limited to the first sampleRows rows by primary key when sampleRows > 0.
+// Tables without a primary key are sampled in the server's scan order.
+func (c *Connection) exportSelectQuery(tableName string, sampleRows int) (string, error) {
+	query := fmt.Sprintf("SELECT * FROM %s", c.QuoteIdentifier(tableName))
+	if sampleRows <= 0 {
+		return query, nil
+	}
+
+	pk, err := c.PrimaryKey(tableName)
+	if err != nil {
+		return "", err
+	}
+	if len(pk) > 0 {
+		query += " ORDER BY " + c.quoteIdentifiers(pk)
+	}
+	return fmt.Sprintf("%s LIMIT %d", query, sampleRows), nil
+}
+
+// exportTableDataBuffered exports table data with batched INSERTs and
+// returns the row count and checksum for the dump's manifest, calling
+// onBatch (when set) with the rows so far after each batch. Large object
+// OIDs among the values are looked up in the dump's mapping table.
+// Cancelling ctx stops it between rows.
+func (c *Connection) exportTableDataBuffered(ctx context.Context, writer *bufio.Writer, tableName string, batchSize, sampleRows int, masking *MaskingConfig, objects *largeObjects, onBatch func(rows int64)) (manifestChecksum, error) {
+	var sum manifestChecksum
+	query, err := c.exportSelectQuery(tableName, sampleRows)
 	if err != nil {
-		return 0, err
+		return sum, err
+	}
+	rows, err := c.DB.QueryContext(ctx, query)
+	if err != nil {
+		return sum, err
 	}
 	defer rows.Close()
 
 	columns, err := rows.Columns()
 	if err != nil {
-		return 0, err
+		return sum, err
 	}
 
 	if len(columns) == 0 {
-		return 0, nil
+		return sum, nil
 	}
 
-	var rowCount int64
 	values := make([]string, 0, batchSize)
 
 	// Quote column names for the INSERT statement
@@ -460,23 +747,35 @@ func (c *Connection) exportTableDataBuffered(writer *bufio.Writer, tableName str
 		valuePtrs[i] = &valueHolders[i]
 	}
 	rowValues := make([]string, 0, len(columns))
+	masks := masking.columnMasks(tableName, columns)
+	remap := objects.remapped(tableName, columns)
 
 	// Write table comment
 	fmt.Fprintf(writer, "-- Dumping data for table %s\n\n", c.QuoteIdentifier(tableName))
 
 	for rows.Next() {
 		if err := rows.Scan(valuePtrs...); err != nil {
-			return rowCount, err
+			return sum, err
 		}
 
 		// Format values - reuse slice
 		rowValues = rowValues[:0]
-		for _, val := range valueHolders {
+		for i, val := range valueHolders {
+			if masks != nil && masks[i] != nil {
+				val = masks[i].Apply(val)
+			}
+			if remap != nil && remap[i] {
+				if lookup, ok := objects.lookup(val); ok {
+					rowValues = append(rowValues, lookup)
+					continue
+				}
+			}
 			rowValues = append(rowValues, c.formatValueForExport(val))
 		}
 
-		values = append(values, fmt.Sprintf("(%s)", strings.Join(rowValues, ", ")))
-		rowCount++
+		tuple := fmt.Sprintf("(%s)", strings.Join(rowValues, ", "))
+		values = append(values, tuple)
+		sum.add(tuple)
 
 		// Write batch
 		if len(values) >= batchSize {
@@ -484,7 +783,10 @@ func (c *Connection) exportTableDataBuffered(writer *bufio.Writer, tableName str
 				c.QuoteIdentifier(tableName),
 				strings.Join(quotedColumns, ", "),
 				strings.Join(values, ",\n"))
-			clear(values)
+			values = values[:0]
+			if onBatch != nil {
+				onBatch(sum.rows)
+			}
 		}
 	}
 
@@ -496,20 +798,53 @@ func (c *Connection) exportTableDataBuffered(writer *bufio.Writer, tableName str
 			strings.Join(values, ",\n"))
 	}
 
-	return rowCount, rows.Err()
+	return sum, rows.Err()
+}
+
+// exportSessionSQL returns a driver's export header or footer. With NoOwner
+// it leaves out session_replication_role, which takes a superuser to set and
+// would stop the dump restoring under an ordinary role
+func exportSessionSQL(sql string, opts ExportOptions) string {
+	if !opts.NoOwner {
+		return sql
+	}
+	var kept strings.Builder
+	for _, line := range strings.SplitAfter(sql, "\n") {
+		if !strings.HasPrefix(line, "SET session_replication_role") {
+			kept.WriteString(line)
+		}
+	}
+	return kept.String()
+}
+
+// meteredWriter counts the bytes written through it. The count can be read
+// while another goroutine writes.
+type meteredWriter struct {
+	w io.Writer
+	n atomic.Int64
+}
+
+func (m *meteredWriter) Write(p []byte) (int, error) {
+	n, err := m.w.Write(p)
+	m.n.Add(int64(n))
+	return n, err
 }
 
 // tableExportResult holds the result of exporting a single table
 type tableExportResult struct {
 	Index     int
 	TableName string
-	Data      []byte
-	RowCount  int64
+	Spill     *scratch.File // The table's part of the dump, in the temp directory
+	Checksum  manifestChecksum
+	Bytes     int64 // SQL written to the spill file
 	Error     error
 }
 
-// exportTablesParallel exports multiple tables in parallel
-func (c *Connection) exportTablesParallel(writer *bufio.Writer, tables []string, opts ExportOptions, workers int) (int64, error) {
+// exportTablesParallel exports multiple tables in parallel, adding them to
+// the manifest in table order. Each worker spills its table to a scratch
+// file rather than memory, and the files are appended to the dump in table
+// order as soon as they are done.
+func (c *Connection) exportTablesParallel(writer *bufio.Writer, tables []string, opts ExportOptions, workers int, manifest *DumpManifest, objects *largeObjects) (int64, error) {
 	if workers <= 0 {
 		workers = runtime.NumCPU()
 	}
@@ -524,17 +859,15 @@ func (c *Connection) exportTablesParallel(writer *bufio.Writer, tables []string,
 
 	tasks := make(chan exportTask, len(tables))
 	results := make(chan tableExportResult, len(tables))
+	done := make(chan struct{}) // Closed when the collector gives up
+	var stopOnce sync.Once
+	stop := func() { stopOnce.Do(func() { close(done) }) }
+	defer stop()
 
 	// Track progress
 	var completed atomic.Int64
 	var totalRows atomic.Int64
-
-	// Buffer pool for parallel workers - reduces allocations
-	bufPool := sync.Pool{
-		New: func() interface{} {
-			return new(bytes.Buffer)
-		},
-	}
+	var spilled atomic.Int64 // Bytes of finished tables; the dump is only appended to later
 
 	// Start workers
 	var wg sync.WaitGroup
@@ -544,71 +877,26 @@ func (c *Connection) exportTablesParallel(writer *bufio.Writer, tables []string,
 			defer wg.Done()
 
 			for task := range tasks {
-				logging.Debug("Worker %d exporting table: %s", workerID, task.tableName)
-
-				buf := bufPool.Get().(*bytes.Buffer)
-				buf.Reset()
-				bufWriter := bufio.NewWriterSize(buf, opts.BufferSize)
-
-				// Write table header
-				fmt.Fprintf(bufWriter, "-- --------------------------------------------------------\n")
-				fmt.Fprintf(bufWriter, "-- Table structure for table %s\n", c.QuoteIdentifier(task.tableName))
-				fmt.Fprintf(bufWriter, "-- --------------------------------------------------------\n\n")
-
-				// Export table structure
-				if !opts.NoCreate {
-					if opts.AddDropTable {
-						fmt.Fprintf(bufWriter, "DROP TABLE IF EXISTS %s;\n", c.QuoteIdentifier(task.tableName))
-					}
-
-					createStmt, err := c.getCreateTable(task.tableName)
-					if err != nil {
-						bufPool.Put(buf)
-						results <- tableExportResult{
-							Index:     task.index,
-							TableName: task.tableName,
-							Error:     fmt.Errorf("failed to get CREATE TABLE for %s: %w", task.tableName, err),
-						}
-						continue
-					}
-					fmt.Fprintf(bufWriter, "%s;\n\n", createStmt)
-				}
-
-				// Export table data
-				var rowCount int64
-				if !opts.NoData {
-					var err error
-					rowCount, err = c.exportTableDataBuffered(bufWriter, task.tableName, opts.BatchSize)
-					if err != nil {
-						bufPool.Put(buf)
-						results <- tableExportResult{
-							Index:     task.index,
-							TableName: task.tableName,
-							Error:     fmt.Errorf("failed to export data for %s: %w", task.tableName, err),
-						}
-						continue
-					}
+				select {
+				case <-done:
+					return
+				default:
 				}
+				logging.Debug("Worker %d exporting table: %s", workerID, task.tableName)
 
-				bufWriter.Flush()
-
-				// Copy data before returning buffer to pool
-				data := make([]byte, buf.Len())
-				copy(data, buf.Bytes())
-				bufPool.Put(buf)
-
-				results <- tableExportResult{
-					Index:     task.index,
-					TableName: task.tableName,
-					Data:      data,
-					RowCount:  rowCount,
+				result := c.exportTableSpill(task.tableName, opts, objects)
+				result.Index = task.index
+				results <- result
+				if result.Error != nil {
+					continue
 				}
 
 				completed.Add(1)
-				totalRows.Add(rowCount)
+				totalRows.Add(result.Checksum.rows)
+				spilled.Add(result.Bytes)
 
 				if opts.OnProgress != nil {
-					opts.OnProgress(task.tableName, int(completed.Load()), len(tables), totalRows.Load())
+					opts.OnProgress(task.tableName, int(completed.Load()), len(tables), totalRows.Load(), spilled.Load())
 				}
 			}
 		}(w)
@@ -626,17 +914,38 @@ func (c *Connection) exportTablesParallel(writer *bufio.Writer, tables []string,
 		close(results)
 	}()
 
-	// Collect results in order
-	tableResults := make([]tableExportResult, len(tables))
+	// Append results in table order as they become available; on the first
+	// error, the rest are only drained and removed
+	pending := make(map[int]tableExportResult)
+	next := 0
 	var firstError error
-	resultCount := 0
-
 	for result := range results {
-		tableResults[result.Index] = result
-		if result.Error != nil && firstError == nil {
-			firstError = result.Error
+		if firstError != nil || result.Error != nil {
+			if firstError == nil {
+				firstError = result.Error
+				stop()
+			}
+			removeSpill(result)
+			continue
+		}
+		pending[result.Index] = result
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			if err := appendSpill(writer, r); err != nil {
+				firstError = err
+				stop()
+				break
+			}
+			manifest.add(r.TableName, r.Checksum)
 		}
-		resultCount++
+	}
+	for _, r := range pending {
+		removeSpill(r)
 	}
 
 	// Check for errors
@@ -644,16 +953,79 @@ func (c *Connection) exportTablesParallel(writer *bufio.Writer, tables []string,
 		return 0, firstError
 	}
 
-	// Write results in order to maintain table order in output
-	for _, result := range tableResults {
-		if len(result.Data) > 0 {
-			writer.Write(result.Data)
+	logging.Info("Parallel export completed: %d tables, %d total rows", len(tables), totalRows.Load())
+
+	return totalRows.Load(), nil
+}
+
+// exportTableSpill writes one table's structure and data to a scratch file
+func (c *Connection) exportTableSpill(tableName string, opts ExportOptions, objects *largeObjects) tableExportResult {
+	result := tableExportResult{TableName: tableName}
+	spill, err := scratch.CreateTemp("export-*.sql", 0)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	fail := func(err error) tableExportResult {
+		spill.Remove()
+		result.Error = err
+		return result
+	}
+	metered := &meteredWriter{w: spill}
+	bufWriter := bufio.NewWriterSize(metered, opts.BufferSize)
+
+	// Write table header
+	fmt.Fprintf(bufWriter, "-- --------------------------------------------------------\n")
+	fmt.Fprintf(bufWriter, "-- Table structure for table %s\n", c.QuoteIdentifier(tableName))
+	fmt.Fprintf(bufWriter, "-- --------------------------------------------------------\n\n")
+
+	// Export table structure
+	if !opts.NoCreate {
+		if opts.AddDropTable {
+			fmt.Fprintf(bufWriter, "DROP TABLE IF EXISTS %s;\n", c.QuoteIdentifier(tableName))
 		}
+
+		createStmt, err := c.getCreateTable(tableName)
+		if err != nil {
+			return fail(fmt.Errorf("failed to get CREATE TABLE for %s: %w", tableName, err))
+		}
+		fmt.Fprintf(bufWriter, "%s;\n\n", createStmt)
 	}
 
-	logging.Info("Parallel export completed: %d tables, %d total rows", len(tables), totalRows.Load())
+	// Export table data
+	if !opts.NoData {
+		sum, err := c.exportTableDataBuffered(orBackground(opts.Context), bufWriter, tableName, opts.BatchSize, opts.SampleRows, opts.Masking, objects, nil)
+		if err != nil {
+			return fail(fmt.Errorf("failed to export data for %s: %w", tableName, err))
+		}
+		result.Checksum = sum
+	}
 
-	return totalRows.Load(), nil
+	if err := bufWriter.Flush(); err != nil {
+		return fail(fmt.Errorf("failed to spill %s to the temp directory: %w", tableName, err))
+	}
+	result.Spill = spill
+	result.Bytes = metered.n.Load()
+	return result
+}
+
+// appendSpill copies a table's scratch file into the dump and removes it
+func appendSpill(writer *bufio.Writer, r tableExportResult) error {
+	defer removeSpill(r)
+	if _, err := r.Spill.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read back %s: %w", r.TableName, err)
+	}
+	if _, err := io.Copy(writer, r.Spill.File); err != nil {
+		return fmt.Errorf("failed to write %s: %w", r.TableName, err)
+	}
+	return nil
+}
+
+// removeSpill deletes the scratch file of a result, if any
+func removeSpill(r tableExportResult) {
+	if r.Spill != nil {
+		r.Spill.Remove()
+	}
 }
 
 // formatValueForExport formats a value for use in an export SQL file
@@ -724,7 +1096,7 @@ func (c *Connection) ExportSQLWithCallback(filePath, database string, progress f
 		FilePath:     filePath,
 		Database:     database,
 		AddDropTable: true,
-		OnProgress: func(currentTable string, tableNum, totalTables int, _ int64) {
+		OnProgress: func(currentTable string, tableNum, totalTables int, _, _ int64) {
 			if progress != nil && totalTables > 0 {
 				progress(currentTable, float64(tableNum)/float64(totalTables)*100)
 			}
@@ -768,14 +1140,47 @@ func (c *Connection) exportWithPgDump(opts ExportOptions) (*ExportStats, error)
 	if opts.AddDropTable {
 		args = append(args, "--clean")
 	}
+	if opts.NoOwner {
+		args = append(args, "--no-owner")
+	}
+	if opts.NoACL {
+		args = append(args, "--no-acl")
+	}
 
 	// Add specific tables
 	for _, table := range opts.Tables {
 		args = append(args, "-t", table)
 	}
 
-	// Output file
-	args = append(args, "-f", opts.FilePath)
+	// pg_dump only includes large objects when dumping the whole database
+	if len(opts.Tables) > 0 && !opts.NoData {
+		if opts.Database != "" {
+			if err := c.UseDatabase(opts.Database); err != nil {
+				return nil, err
+			}
+		}
+		objects, err := c.findLargeObjects(opts.Tables)
+		if err != nil {
+			return nil, err
+		}
+		if objects != nil {
+			args = append(args, "--blobs")
+			stats.LargeObjects = objects.count()
+		}
+	}
+
+	// Output file; a split dump comes from stdout straight into its parts
+	var splitOut io.WriteCloser
+	if opts.SplitSize > 0 {
+		out, err := createExportFile(opts)
+		if err != nil {
+			return nil, err
+		}
+		splitOut = out
+		defer splitOut.Close()
+	} else {
+		args = append(args, "-f", opts.FilePath)
+	}
 
 	// Database name
 	dbName := opts.Database
@@ -791,9 +1196,13 @@ func (c *Connection) exportWithPgDump(opts ExportOptions) (*ExportStats, error)
 	logging.Debug("Running: pg_dump %v", args)
 
 	// Run the command
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("pg_dump failed: %w\nOutput: %s", err, string(output))
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+	if splitOut != nil {
+		cmd.Stdout = splitOut
+	}
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("pg_dump failed: %w\nOutput: %s", err, output.String())
 	}
 
 	// Get file stats
@@ -824,6 +1233,7 @@ func (c *Connection) exportWithMysqldump(opts ExportOptions) (*ExportStats, erro
 		"-u", c.Config.User,
 		"-p" + c.Config.Password,
 		"--single-transaction",
+		"--default-character-set=utf8mb4",
 		"--routines",
 		"--triggers",
 	}
@@ -852,9 +1262,9 @@ func (c *Connection) exportWithMysqldump(opts ExportOptions) (*ExportStats, erro
 	logging.Debug("Running: mysqldump (arguments hidden for security)")
 
 	// Create output file
-	outFile, err := os.Create(opts.FilePath)
+	outFile, err := createExportFile(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create output file: %w", err)
+		return nil, err
 	}
 	defer outFile.Close()
 
@@ -864,12 +1274,15 @@ func (c *Connection) exportWithMysqldump(opts ExportOptions) (*ExportStats, erro
 
 	switch opts.Compression {
 	case CompressionGzip:
-		gzWriter := gzip.NewWriter(outFile)
+		gzWriter, err := gzip.NewWriterLevel(outFile, CompressionLevelOrDefault(CompressionGzip, opts.CompressionLevel))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip level: %w", err)
+		}
 		defer gzWriter.Close()
 		writer = gzWriter
 		stats.Compressed = true
 	case CompressionXZ:
-		compressCmd = exec.Command("xz", "-c", "-6")
+		compressCmd = exec.Command("xz", "-c", compressionLevelFlag(CompressionXZ, opts.CompressionLevel))
 		compressCmd.Stdout = outFile
 		stdin, err := compressCmd.StdinPipe()
 		if err != nil {
@@ -885,7 +1298,7 @@ func (c *Connection) exportWithMysqldump(opts ExportOptions) (*ExportStats, erro
 			compressCmd.Wait()
 		}()
 	case CompressionZstd:
-		compressCmd = exec.Command("zstd", "-c", "-3")
+		compressCmd = exec.Command("zstd", "-c", compressionLevelFlag(CompressionZstd, opts.CompressionLevel))
 		compressCmd.Stdout = outFile
 		stdin, err := compressCmd.StdinPipe()
 		if err != nil {
@@ -0,0 +1,241 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ForeignLinkOptions describes tables on another server to make queryable
+// from this one
+type ForeignLinkOptions struct {
+	Name         string           // Foreign server name
+	Remote       ConnectionConfig // The other server; Remote.Database is the database to link
+	RemoteSchema string           // PostgreSQL schema on the other server (default public)
+	Tables       []string         // Remote tables to link; empty links all of them
+	LocalSchema  string           // PostgreSQL schema or MariaDB database the tables go in (default: Name)
+}
+
+// ForeignLinkPlan is the statements that set up a foreign link, for
+// previewing before they run
+type ForeignLinkPlan struct {
+	Method     string   // postgres_fdw, mysql_fdw, FEDERATED or CONNECT
+	Statements []string // With the remote password
+	Redacted   []string // The same statements with the password masked, for display
+	Notes      []string // Prerequisites and caveats
+}
+
+// redactedPassword replaces the remote password in previews
+const redactedPassword = "********"
+
+// PlanForeignLink builds the statements linking the remote server's tables
+// into this one: postgres_fdw or mysql_fdw on PostgreSQL, FederatedX or
+// CONNECT (for PostgreSQL remotes) on MariaDB. On MariaDB, where every
+// table is created separately, an empty table list is filled in by asking
+// the remote server.
+func (c *Connection) PlanForeignLink(opts ForeignLinkOptions) (*ForeignLinkPlan, error) {
+	if opts.Name == "" {
+		return nil, fmt.Errorf("a link name is required")
+	}
+	if opts.Remote.Database == "" {
+		return nil, fmt.Errorf("a remote database is required")
+	}
+	if opts.LocalSchema == "" {
+		opts.LocalSchema = opts.Name
+	}
+	if opts.RemoteSchema == "" && isPostgresType(opts.Remote.Type) {
+		opts.RemoteSchema = "public"
+	}
+	if opts.Remote.Port == 0 {
+		opts.Remote.Port = DefaultPort(opts.Remote.Type)
+	}
+	if opts.Remote.Host == "" {
+		opts.Remote.Host = "localhost"
+	}
+
+	plan := &ForeignLinkPlan{}
+	if opts.Remote.Host == "localhost" || opts.Remote.Host == "127.0.0.1" || opts.Remote.Host == "::1" {
+		plan.Notes = append(plan.Notes, fmt.Sprintf(
+			"The remote host is %s, which this server resolves to itself; use an address it can reach if the other server runs elsewhere.",
+			opts.Remote.Host))
+	}
+
+	if c.Config.Type == DatabaseTypePostgres {
+		c.planPostgresLink(plan, opts)
+	} else {
+		if len(opts.Tables) == 0 {
+			tables, err := listRemoteTables(opts)
+			if err != nil {
+				return nil, err
+			}
+			if len(tables) == 0 {
+				return nil, fmt.Errorf("no tables found in %s on the remote server", opts.Remote.Database)
+			}
+			opts.Tables = tables
+		}
+		c.planMariaDBLink(plan, opts)
+	}
+	return plan, nil
+}
+
+// planPostgresLink links a server through a foreign data wrapper, importing
+// the remote tables with IMPORT FOREIGN SCHEMA
+func (c *Connection) planPostgresLink(plan *ForeignLinkPlan, opts ForeignLinkOptions) {
+	server := c.QuoteIdentifier(opts.Name)
+	remoteSchema := opts.RemoteSchema
+	serverOptions := fmt.Sprintf("host %s, port %s, dbname %s",
+		c.literal(opts.Remote.Host), c.literal(strconv.Itoa(opts.Remote.Port)), c.literal(opts.Remote.Database))
+	userOption := "user"
+
+	plan.Method = "postgres_fdw"
+	if !isPostgresType(opts.Remote.Type) {
+		plan.Method = "mysql_fdw"
+		serverOptions = fmt.Sprintf("host %s, port %s", c.literal(opts.Remote.Host), c.literal(strconv.Itoa(opts.Remote.Port)))
+		userOption = "username"
+		remoteSchema = opts.Remote.Database // mysql_fdw imports a database as the schema
+		plan.Notes = append(plan.Notes,
+			"mysql_fdw is not part of PostgreSQL: install it on the server first (e.g. the postgresql-<version>-mysql-fdw package).")
+	}
+	plan.Notes = append(plan.Notes,
+		"Creating the extension and the server needs superuser rights (or a trusted extension).",
+		"The remote password is stored in this server's user mapping, visible to superusers.")
+
+	limit := ""
+	if len(opts.Tables) > 0 {
+		limit = " LIMIT TO (" + c.quoteIdentifiers(opts.Tables) + ")"
+	}
+
+	build := func(password string) []string {
+		return []string{
+			"CREATE EXTENSION IF NOT EXISTS " + plan.Method,
+			fmt.Sprintf("CREATE SERVER IF NOT EXISTS %s FOREIGN DATA WRAPPER %s OPTIONS (%s)", server, plan.Method, serverOptions),
+			fmt.Sprintf("CREATE USER MAPPING IF NOT EXISTS FOR CURRENT_USER SERVER %s OPTIONS (%s %s, password %s)",
+				server, userOption, c.literal(opts.Remote.User), c.literal(password)),
+			"CREATE SCHEMA IF NOT EXISTS " + c.QuoteIdentifier(opts.LocalSchema),
+			fmt.Sprintf("IMPORT FOREIGN SCHEMA %s%s FROM SERVER %s INTO %s",
+				c.QuoteIdentifier(remoteSchema), limit, server, c.QuoteIdentifier(opts.LocalSchema)),
+		}
+	}
+	plan.Statements = build(opts.Remote.Password)
+	plan.Redacted = build(redactedPassword)
+}
+
+// planMariaDBLink stores the remote server with CREATE SERVER and creates
+// one discovered table per remote table, with FederatedX for MariaDB and
+// MySQL remotes and CONNECT's JDBC tables for PostgreSQL
+func (c *Connection) planMariaDBLink(plan *ForeignLinkPlan, opts ForeignLinkOptions) {
+	server := c.QuoteIdentifier(opts.Name)
+	engine, soname, wrapper, host := "FEDERATED", "ha_federatedx", "mysql", opts.Remote.Host
+	if isPostgresType(opts.Remote.Type) {
+		engine, soname, wrapper = "CONNECT", "ha_connect", "postgresql"
+		host = fmt.Sprintf("jdbc:postgresql://%s:%d/%s", opts.Remote.Host, opts.Remote.Port, opts.Remote.Database)
+		plan.Notes = append(plan.Notes,
+			"CONNECT's JDBC tables need a Java runtime on the server and the PostgreSQL JDBC driver in connect_class_path.")
+	}
+	plan.Method = engine
+	plan.Notes = append(plan.Notes,
+		"CREATE SERVER needs the FEDERATED ADMIN or SUPER privilege.",
+		"The remote password is stored in mysql.servers, readable by anyone with access to the mysql database.")
+
+	var preamble []string
+	if !c.engineAvailable(engine) {
+		preamble = append(preamble, fmt.Sprintf("INSTALL SONAME '%s'", soname))
+	}
+	preamble = append(preamble, "CREATE DATABASE IF NOT EXISTS "+c.QuoteIdentifier(opts.LocalSchema))
+
+	build := func(password string) []string {
+		statements := append([]string(nil), preamble...)
+		statements = append(statements, fmt.Sprintf(
+			"CREATE OR REPLACE SERVER %s FOREIGN DATA WRAPPER %s OPTIONS (HOST %s, DATABASE %s, USER %s, PASSWORD %s, PORT %d)",
+			server, wrapper, c.literal(host), c.literal(opts.Remote.Database),
+			c.literal(opts.Remote.User), c.literal(password), opts.Remote.Port))
+
+		for _, table := range opts.Tables {
+			local := c.QuoteIdentifier(opts.LocalSchema) + "." + c.QuoteIdentifier(table)
+			if isPostgresType(opts.Remote.Type) {
+				statements = append(statements, fmt.Sprintf(
+					"CREATE TABLE %s ENGINE=CONNECT TABLE_TYPE=JDBC CONNECTION=%s TABNAME=%s",
+					local, c.literal(opts.Name), c.literal(opts.RemoteSchema+"."+table)))
+			} else {
+				statements = append(statements, fmt.Sprintf(
+					"CREATE TABLE %s ENGINE=FEDERATED CONNECTION=%s",
+					local, c.literal(opts.Name+"/"+table)))
+			}
+		}
+		return statements
+	}
+	plan.Statements = build(opts.Remote.Password)
+	plan.Redacted = build(redactedPassword)
+}
+
+// CreateForeignLink runs a plan's statements in order, stopping at the
+// first failure
+func (c *Connection) CreateForeignLink(plan *ForeignLinkPlan) error {
+	for i, stmt := range plan.Statements {
+		if _, err := c.DB.Exec(stmt); err != nil {
+			return fmt.Errorf("%s failed: %w", plan.Redacted[i], err)
+		}
+	}
+	return nil
+}
+
+// literal quotes a string literal for the connection's dialect
+func (c *Connection) literal(s string) string {
+	return "'" + c.EscapeString(s) + "'"
+}
+
+// engineAvailable reports whether a MariaDB storage engine is loaded
+func (c *Connection) engineAvailable(engine string) bool {
+	var support string
+	err := c.DB.QueryRow("SELECT SUPPORT FROM information_schema.ENGINES WHERE ENGINE = ?", engine).Scan(&support)
+	return err == nil && (support == "YES" || support == "DEFAULT")
+}
+
+// listRemoteTables connects to the remote server and lists the base tables
+// of the linked database (or schema on PostgreSQL)
+func listRemoteTables(opts ForeignLinkOptions) ([]string, error) {
+	remote, err := Connect(opts.Remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the remote server: %w", err)
+	}
+	defer remote.Close()
+
+	schema := opts.Remote.Database
+	if isPostgresType(opts.Remote.Type) {
+		schema = opts.RemoteSchema
+	}
+	rows, err := remote.DB.Query(
+		"SELECT table_name FROM information_schema.tables WHERE table_schema = "+
+			Placeholder(0, remote.Config.Type)+" AND table_type = 'BASE TABLE' ORDER BY table_name", schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
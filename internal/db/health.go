@@ -0,0 +1,430 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/scratch"
+)
+
+// HealthStatus grades a health check; the values are the exit codes
+// Nagios-style monitoring expects
+type HealthStatus int
+
+const (
+	HealthOK       HealthStatus = 0
+	HealthWarning  HealthStatus = 1
+	HealthCritical HealthStatus = 2
+	HealthUnknown  HealthStatus = 3
+)
+
+// String returns the monitoring label of the status
+func (s HealthStatus) String() string {
+	switch s {
+	case HealthWarning:
+		return "WARNING"
+	case HealthCritical:
+		return "CRITICAL"
+	case HealthUnknown:
+		return "UNKNOWN"
+	}
+	return "OK"
+}
+
+// severity orders statuses for picking the worst; UNKNOWN ranks between OK
+// and WARNING like in Nagios
+func (s HealthStatus) severity() int {
+	switch s {
+	case HealthUnknown:
+		return 1
+	case HealthWarning:
+		return 2
+	case HealthCritical:
+		return 3
+	}
+	return 0
+}
+
+// HealthThresholds configures when a check warns or goes critical; zero
+// values disable a threshold
+type HealthThresholds struct {
+	LagWarning           time.Duration
+	LagCritical          time.Duration
+	ConnectionsWarning   float64 // Percent of max_connections in use
+	ConnectionsCritical  float64
+	DiskFreeWarning      float64 // Percent of the data directory's filesystem free
+	DiskFreeCritical     float64
+	DatabaseSizeWarning  int64 // Bytes, per database
+	DatabaseSizeCritical int64
+	ClusterSize          int // Expected Galera nodes
+}
+
+// DefaultHealthThresholds returns the thresholds used when none are given
+func DefaultHealthThresholds() HealthThresholds {
+	return HealthThresholds{
+		LagWarning:          30 * time.Second,
+		LagCritical:         5 * time.Minute,
+		ConnectionsWarning:  80,
+		ConnectionsCritical: 95,
+		DiskFreeWarning:     20,
+		DiskFreeCritical:    10,
+	}
+}
+
+// HealthCheck is the result of one check
+type HealthCheck struct {
+	Name     string
+	Status   HealthStatus
+	Skipped  bool // Not applicable or not measurable; doesn't count towards the status
+	Message  string
+	PerfData []string // Nagios performance data, e.g. lag=42s;30;300
+}
+
+// HealthReport is the outcome of CheckHealth
+type HealthReport struct {
+	Checks []HealthCheck
+}
+
+func (r *HealthReport) add(check HealthCheck) {
+	r.Checks = append(r.Checks, check)
+}
+
+// Status returns the worst status of the checks that ran
+func (r *HealthReport) Status() HealthStatus {
+	worst := HealthOK
+	for _, check := range r.Checks {
+		if !check.Skipped && check.Status.severity() > worst.severity() {
+			worst = check.Status
+		}
+	}
+	return worst
+}
+
+// Summary is the one-line status monitoring systems show: the worst
+// problems, or how many checks passed
+func (r *HealthReport) Summary() string {
+	status := r.Status()
+	var problems []string
+	passed := 0
+	for _, check := range r.Checks {
+		switch {
+		case check.Skipped:
+		case check.Status == status && status != HealthOK:
+			problems = append(problems, check.Name+": "+check.Message)
+		case check.Status == HealthOK:
+			passed++
+		}
+	}
+	if status == HealthOK {
+		return fmt.Sprintf("%s - %d checks passed", status, passed)
+	}
+	return fmt.Sprintf("%s - %s", status, strings.Join(problems, "; "))
+}
+
+// PerfData joins the performance data of every check
+func (r *HealthReport) PerfData() string {
+	var all []string
+	for _, check := range r.Checks {
+		all = append(all, check.PerfData...)
+	}
+	return strings.Join(all, " ")
+}
+
+// gradeAbove grades a value that is bad when high
+func gradeAbove(value, warning, critical float64) HealthStatus {
+	switch {
+	case critical > 0 && value >= critical:
+		return HealthCritical
+	case warning > 0 && value >= warning:
+		return HealthWarning
+	}
+	return HealthOK
+}
+
+// gradeBelow grades a value that is bad when low
+func gradeBelow(value, warning, critical float64) HealthStatus {
+	switch {
+	case critical > 0 && value <= critical:
+		return HealthCritical
+	case warning > 0 && value <= warning:
+		return HealthWarning
+	}
+	return HealthOK
+}
+
+// perfValue formats one Nagios performance data value, leaving unset
+// thresholds empty
+func perfValue(label, value, uom string, warning, critical float64) string {
+	threshold := func(v float64) string {
+		if v <= 0 {
+			return ""
+		}
+		return fmt.Sprintf("%g", v)
+	}
+	return fmt.Sprintf("%s=%s%s;%s;%s", label, value, uom, threshold(warning), threshold(critical))
+}
+
+// CheckHealth checks the connection, replication, cluster state, disk space
+// and database sizes against the thresholds
+func (c *Connection) CheckHealth(t HealthThresholds) *HealthReport {
+	report := &HealthReport{}
+	report.add(c.checkConnection(t))
+	report.add(c.checkReplication(t))
+	report.add(c.checkCluster(t))
+	report.add(c.checkDiskFree(t))
+	report.add(c.checkDatabaseSizes(t))
+	return report
+}
+
+func (c *Connection) checkConnection(t HealthThresholds) HealthCheck {
+	check := HealthCheck{Name: "connection"}
+
+	start := time.Now()
+	if err := c.HealthCheck(); err != nil {
+		check.Status = HealthCritical
+		check.Message = fmt.Sprintf("ping failed: %v", err)
+		return check
+	}
+	latency := time.Since(start)
+	check.PerfData = append(check.PerfData, fmt.Sprintf("time=%.3fs", latency.Seconds()))
+
+	stats, err := c.GetConnectionStats()
+	if err != nil || stats.Max <= 0 {
+		check.Message = fmt.Sprintf("responded in %s", latency.Round(time.Millisecond))
+		return check
+	}
+	usage := float64(stats.Active) / float64(stats.Max) * 100
+	check.Status = gradeAbove(usage, t.ConnectionsWarning, t.ConnectionsCritical)
+	check.Message = fmt.Sprintf("responded in %s, %d/%d connections (%.1f%%)",
+		latency.Round(time.Millisecond), stats.Active, stats.Max, usage)
+	check.PerfData = append(check.PerfData,
+		fmt.Sprintf("connections=%d;%s;%s;0;%d", stats.Active,
+			connectionLimit(stats.Max, t.ConnectionsWarning), connectionLimit(stats.Max, t.ConnectionsCritical), stats.Max))
+	return check
+}
+
+// connectionLimit converts a percent threshold to a connection count
+func connectionLimit(max int, percent float64) string {
+	if percent <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", int(float64(max)*percent/100))
+}
+
+func (c *Connection) checkReplication(t HealthThresholds) HealthCheck {
+	check := HealthCheck{Name: "replication"}
+
+	var lag float64
+	if c.Config.Type == DatabaseTypePostgres {
+		stats, err := c.GetReplicationStats()
+		if err != nil {
+			check.Status = HealthUnknown
+			check.Message = fmt.Sprintf("can't read replication state: %v", err)
+			return check
+		}
+		if !stats.IsReplica {
+			check.Skipped = true
+			check.Message = "not a replica"
+			return check
+		}
+		lag = stats.LagSeconds
+	} else {
+		status, err := c.GetMariaDBReplicationStatus()
+		if err != nil {
+			check.Status = HealthUnknown
+			check.Message = fmt.Sprintf("can't read replication state: %v", err)
+			return check
+		}
+		if !status.IsReplica {
+			check.Skipped = true
+			check.Message = "not a replica"
+			return check
+		}
+		if !status.ReplicaIORunning || !status.ReplicaSQLRunning {
+			check.Status = HealthCritical
+			check.Message = "replication stopped"
+			if lastErr := firstNonEmpty(status.LastIOError, status.LastSQLError, status.LastError); lastErr != "" {
+				check.Message += ": " + lastErr
+			}
+			return check
+		}
+		if status.SecondsBehind == nil {
+			check.Status = HealthCritical
+			check.Message = "lag unknown, the replica isn't applying events"
+			return check
+		}
+		lag = float64(*status.SecondsBehind)
+	}
+
+	check.Status = gradeAbove(lag, t.LagWarning.Seconds(), t.LagCritical.Seconds())
+	check.Message = fmt.Sprintf("%.0fs behind the primary", lag)
+	check.PerfData = append(check.PerfData, perfValue("lag", fmt.Sprintf("%.0f", lag), "s", t.LagWarning.Seconds(), t.LagCritical.Seconds()))
+	return check
+}
+
+func (c *Connection) checkCluster(t HealthThresholds) HealthCheck {
+	check := HealthCheck{Name: "cluster"}
+
+	if c.Config.Type == DatabaseTypeMariaDB {
+		if galera, err := c.GetGaleraStatus(); err == nil {
+			return checkGalera(galera, t)
+		}
+	}
+
+	status, err := c.GetClusterStatus()
+	if err != nil {
+		check.Status = HealthUnknown
+		check.Message = fmt.Sprintf("can't read cluster state: %v", err)
+		return check
+	}
+	switch {
+	case status.Type == ClusterTypeNone:
+		check.Skipped = true
+		check.Message = "standalone server"
+	case status.IsHealthy:
+		check.Message = fmt.Sprintf("%s healthy", status.Type)
+	default:
+		check.Status = HealthCritical
+		check.Message = fmt.Sprintf("%s unhealthy", status.Type)
+		if status.ErrorMessage != "" {
+			check.Message += ": " + status.ErrorMessage
+		}
+	}
+	return check
+}
+
+// checkGalera grades a Galera node: a non-primary component or a node that
+// isn't ready is critical, a donor/desynced node or missing nodes a warning
+func checkGalera(g *GaleraStatus, t HealthThresholds) HealthCheck {
+	check := HealthCheck{Name: "cluster"}
+	check.Message = fmt.Sprintf("galera %s, node %s, %d nodes", g.ClusterStatus, g.LocalState, g.ClusterSize)
+	check.PerfData = append(check.PerfData, fmt.Sprintf("cluster_size=%d;%s;", g.ClusterSize, clusterSizeThreshold(t.ClusterSize)))
+
+	switch {
+	case g.ClusterStatus != "Primary" || !g.Ready || !g.Connected:
+		check.Status = HealthCritical
+	case g.LocalState != "Synced":
+		check.Status = HealthWarning
+	case t.ClusterSize > 0 && g.ClusterSize < t.ClusterSize:
+		check.Status = HealthWarning
+		check.Message += fmt.Sprintf(" (expected %d)", t.ClusterSize)
+	}
+	return check
+}
+
+func clusterSizeThreshold(size int) string {
+	if size <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d:", size)
+}
+
+func (c *Connection) checkDiskFree(t HealthThresholds) HealthCheck {
+	check := HealthCheck{Name: "disk"}
+
+	if !c.isLocal() {
+		check.Skipped = true
+		check.Message = "not measured, the server isn't on this machine"
+		return check
+	}
+	var dataDir string
+	if err := c.DB.QueryRow(c.Driver.DataDirectoryQuery()).Scan(&dataDir); err != nil || dataDir == "" {
+		check.Status = HealthUnknown
+		check.Message = "can't find the data directory"
+		return check
+	}
+	free, err := scratch.DiskFree(dataDir)
+	if err != nil {
+		check.Status = HealthUnknown
+		check.Message = fmt.Sprintf("can't measure %s: %v", dataDir, err)
+		return check
+	}
+	total, err := scratch.DiskSize(dataDir)
+	if err != nil || total <= 0 {
+		check.Status = HealthUnknown
+		check.Message = fmt.Sprintf("can't measure %s: %v", dataDir, err)
+		return check
+	}
+
+	pct := float64(free) / float64(total) * 100
+	check.Status = gradeBelow(pct, t.DiskFreeWarning, t.DiskFreeCritical)
+	check.Message = fmt.Sprintf("%s free of %s (%.1f%%) in %s", FormatSize(free), FormatSize(total), pct, dataDir)
+	check.PerfData = append(check.PerfData, perfValue("disk_free", fmt.Sprintf("%.1f", pct), "%", t.DiskFreeWarning, t.DiskFreeCritical))
+	return check
+}
+
+func (c *Connection) checkDatabaseSizes(t HealthThresholds) HealthCheck {
+	check := HealthCheck{Name: "database size"}
+
+	if t.DatabaseSizeWarning <= 0 && t.DatabaseSizeCritical <= 0 {
+		check.Skipped = true
+		check.Message = "no threshold set"
+		return check
+	}
+	databases, err := c.ListDatabases()
+	if err != nil {
+		check.Status = HealthUnknown
+		check.Message = fmt.Sprintf("can't list databases: %v", err)
+		return check
+	}
+
+	var largest string
+	var largestSize int64
+	var over []string
+	for _, d := range databases {
+		size, err := c.GetDatabaseSize(d.Name)
+		if err != nil {
+			continue
+		}
+		if size > largestSize || largest == "" {
+			largest, largestSize = d.Name, size
+		}
+		status := gradeAbove(float64(size), float64(t.DatabaseSizeWarning), float64(t.DatabaseSizeCritical))
+		if status != HealthOK {
+			over = append(over, fmt.Sprintf("%s %s", d.Name, FormatSize(size)))
+		}
+		if status.severity() > check.Status.severity() {
+			check.Status = status
+		}
+	}
+
+	if len(over) > 0 {
+		check.Message = "over the limit: " + strings.Join(over, ", ")
+	} else if largest != "" {
+		check.Message = fmt.Sprintf("largest is %s at %s", largest, FormatSize(largestSize))
+	} else {
+		check.Message = "no databases"
+	}
+	check.PerfData = append(check.PerfData, perfValue("largest_db", fmt.Sprintf("%d", largestSize), "B",
+		float64(t.DatabaseSizeWarning), float64(t.DatabaseSizeCritical)))
+	return check
+}
+
+// firstNonEmpty returns the first of values that isn't empty
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,380 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultBackupMaxAge is how old a database's newest backup may get before
+// a health digest warns about it
+const DefaultBackupMaxAge = 7 * 24 * time.Hour
+
+// Health digest formats
+const (
+	DigestFormatHTML     = "html"
+	DigestFormatMarkdown = "markdown"
+)
+
+// DigestFormats lists every health digest format
+var DigestFormats = []string{DigestFormatHTML, DigestFormatMarkdown}
+
+// digestFindingLimit caps the privilege findings a digest lists; the rest
+// are counted
+const digestFindingLimit = 20
+
+// DigestOptions configures BuildHealthDigest
+type DigestOptions struct {
+	Profile      string // Names the server, and picks the backups made through it
+	Thresholds   HealthThresholds
+	BackupMaxAge time.Duration // 0 = DefaultBackupMaxAge
+}
+
+// BackupFreshness is how recently a database was backed up in full
+type BackupFreshness struct {
+	Database string
+	BackupID string    // Newest backup holding the whole database
+	Last     time.Time // Zero when it was never backed up
+	Status   HealthStatus
+}
+
+// HealthDigest combines the health checks, the privilege audit and the age
+// of each database's newest backup into one report
+type HealthDigest struct {
+	Server      string
+	Time        time.Time
+	Health      *HealthReport
+	Findings    []PrivilegeFinding
+	SecurityErr string // Why the privilege audit didn't run
+	Backups     []BackupFreshness
+	BackupErr   string // Why backup freshness wasn't checked
+}
+
+// BuildHealthDigest checks the server's health, audits privileges and
+// compares each database's newest full backup (ysm backup) against the
+// maximum age. Parts that fail are reported in the digest as UNKNOWN.
+func (c *Connection) BuildHealthDigest(opts DigestOptions) *HealthDigest {
+	d := &HealthDigest{
+		Server: opts.Profile,
+		Time:   time.Now(),
+		Health: c.CheckHealth(opts.Thresholds),
+	}
+	if d.Server == "" {
+		d.Server = c.ServerKey()
+	}
+
+	if audit, err := c.AuditPrivileges(); err != nil {
+		d.SecurityErr = err.Error()
+	} else {
+		d.Findings = audit.Findings
+	}
+
+	maxAge := opts.BackupMaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultBackupMaxAge
+	}
+	databases, err := c.ListVisibleDatabases()
+	if err != nil {
+		d.BackupErr = err.Error()
+		return d
+	}
+	backups, err := ListBackups()
+	if err != nil {
+		d.BackupErr = err.Error()
+		return d
+	}
+	for _, database := range databases {
+		fresh := BackupFreshness{Database: database.Name, Status: HealthCritical}
+		for _, b := range backups {
+			if b.Profile != opts.Profile || !containsString(b.Databases, database.Name) {
+				continue
+			}
+			if _, partial := b.Tables[database.Name]; partial {
+				continue
+			}
+			if b.Timestamp.After(fresh.Last) {
+				fresh.Last, fresh.BackupID = b.Timestamp, b.ID
+			}
+		}
+		switch {
+		case fresh.Last.IsZero():
+		case d.Time.Sub(fresh.Last) > maxAge:
+			fresh.Status = HealthWarning
+		default:
+			fresh.Status = HealthOK
+		}
+		d.Backups = append(d.Backups, fresh)
+	}
+	sort.SliceStable(d.Backups, func(i, j int) bool {
+		return d.Backups[i].Status.severity() > d.Backups[j].Status.severity()
+	})
+	return d
+}
+
+// UnreachableHealthDigest is the digest of a server YSM couldn't connect to
+func UnreachableHealthDigest(server string, err error) *HealthDigest {
+	return &HealthDigest{
+		Server: server,
+		Time:   time.Now(),
+		Health: &HealthReport{Checks: []HealthCheck{{
+			Name:    "connection",
+			Status:  HealthCritical,
+			Message: fmt.Sprintf("connection failed: %v", err),
+		}}},
+		SecurityErr: "the server is unreachable",
+		BackupErr:   "the server is unreachable",
+	}
+}
+
+// SecurityStatus grades the privilege audit: WARNING when it found
+// anything, UNKNOWN when it couldn't run
+func (d *HealthDigest) SecurityStatus() HealthStatus {
+	switch {
+	case d.SecurityErr != "":
+		return HealthUnknown
+	case len(d.Findings) > 0:
+		return HealthWarning
+	}
+	return HealthOK
+}
+
+// BackupStatus grades backup freshness: CRITICAL when a database was never
+// backed up, WARNING when its newest backup is too old
+func (d *HealthDigest) BackupStatus() HealthStatus {
+	if d.BackupErr != "" {
+		return HealthUnknown
+	}
+	worst := HealthOK
+	for _, b := range d.Backups {
+		if b.Status.severity() > worst.severity() {
+			worst = b.Status
+		}
+	}
+	return worst
+}
+
+// Status returns the worst status of the digest's sections
+func (d *HealthDigest) Status() HealthStatus {
+	worst := d.Health.Status()
+	for _, s := range []HealthStatus{d.SecurityStatus(), d.BackupStatus()} {
+		if s.severity() > worst.severity() {
+			worst = s
+		}
+	}
+	return worst
+}
+
+// Subject is the one-line summary used for email subjects and chat messages
+func (d *HealthDigest) Subject() string {
+	return fmt.Sprintf("[ysm] Health report for %s: %s", d.Server, d.Status())
+}
+
+// digestBackupRow is a backup freshness line as the reports show it
+type digestBackupRow struct {
+	Status, Database, Last, Age, BackupID string
+}
+
+func (d *HealthDigest) backupRows() []digestBackupRow {
+	var rows []digestBackupRow
+	for _, b := range d.Backups {
+		row := digestBackupRow{Status: b.Status.String(), Database: b.Database, Last: "never", Age: "-", BackupID: b.BackupID}
+		if !b.Last.IsZero() {
+			row.Last = b.Last.Format("2006-01-02 15:04")
+			row.Age = FormatUptime(d.Time.Sub(b.Last))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// shownFindings returns the findings listed, and how many more there are
+func (d *HealthDigest) shownFindings() ([]PrivilegeFinding, int) {
+	if len(d.Findings) <= digestFindingLimit {
+		return d.Findings, 0
+	}
+	return d.Findings[:digestFindingLimit], len(d.Findings) - digestFindingLimit
+}
+
+// findingGrantee names the user a finding is about, with its host on MariaDB
+func findingGrantee(f PrivilegeFinding) string {
+	if f.Host != "" {
+		return fmt.Sprintf("'%s'@'%s'", f.User, f.Host)
+	}
+	return f.User
+}
+
+// findingObject names what a finding's privilege applies to
+func findingObject(f PrivilegeFinding) string {
+	if f.Database == "*" {
+		return "server"
+	}
+	if f.Object == "*" {
+		return f.Database
+	}
+	return f.Database + "." + f.Object
+}
+
+// markdownCell keeps a value from breaking a Markdown table row
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
+
+// Markdown renders the digest as Markdown, readable as plain text too
+func (d *HealthDigest) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Health report for %s\n\n", d.Server)
+	fmt.Fprintf(&b, "Overall: **%s**, generated %s\n\n", d.Status(), d.Time.Format("Mon 2006-01-02 15:04 MST"))
+
+	fmt.Fprintf(&b, "## Health: %s\n\n", d.Health.Status())
+	b.WriteString("| Status | Check | Details |\n|---|---|---|\n")
+	for _, check := range d.Health.Checks {
+		status := check.Status.String()
+		if check.Skipped {
+			status = "SKIP"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", status, check.Name, markdownCell(check.Message))
+	}
+
+	fmt.Fprintf(&b, "\n## Security: %s\n\n", d.SecurityStatus())
+	findings, more := d.shownFindings()
+	switch {
+	case d.SecurityErr != "":
+		fmt.Fprintf(&b, "Privileges not audited: %s\n", d.SecurityErr)
+	case len(findings) == 0:
+		b.WriteString("No dangerous grants found.\n")
+	default:
+		fmt.Fprintf(&b, "%d grant(s) worth a second look:\n\n", len(d.Findings))
+		b.WriteString("| User | On | Privilege | Why |\n|---|---|---|---|\n")
+		for _, f := range findings {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", markdownCell(findingGrantee(f)), markdownCell(findingObject(f)), f.Privilege, f.Reason)
+		}
+		if more > 0 {
+			fmt.Fprintf(&b, "\n...and %d more; see ysm user audit.\n", more)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n## Backups: %s\n\n", d.BackupStatus())
+	rows := d.backupRows()
+	switch {
+	case d.BackupErr != "":
+		fmt.Fprintf(&b, "Backups not checked: %s\n", d.BackupErr)
+	case len(rows) == 0:
+		b.WriteString("No databases to back up.\n")
+	default:
+		b.WriteString("| Status | Database | Last backup | Age |\n|---|---|---|---|\n")
+		for _, row := range rows {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", row.Status, markdownCell(row.Database), row.Last, row.Age)
+		}
+	}
+	return b.String()
+}
+
+// digestStatusColors are the HTML colors of each status
+var digestStatusColors = map[string]string{
+	"OK":       "#2e7d32",
+	"WARNING":  "#ef6c00",
+	"CRITICAL": "#c62828",
+	"UNKNOWN":  "#6a1b9a",
+	"SKIP":     "#757575",
+}
+
+var digestHTML = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"color": func(status string) string { return digestStatusColors[status] },
+}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Subject}}</title></head>
+<body style="font-family: sans-serif; color: #212121;">
+<h1>Health report for {{.Server}}</h1>
+<p>Overall: <b style="color: {{color .Status}}">{{.Status}}</b>, generated {{.Time}}</p>
+{{define "status"}}<td style="color: {{color .}}; font-weight: bold;">{{.}}</td>{{end}}
+<h2>Health: <span style="color: {{color .HealthStatus}}">{{.HealthStatus}}</span></h2>
+<table cellpadding="4" border="1" style="border-collapse: collapse;">
+<tr><th>Status</th><th>Check</th><th>Details</th></tr>
+{{range .Checks}}<tr>{{template "status" .Status}}<td>{{.Name}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>
+<h2>Security: <span style="color: {{color .SecurityStatus}}">{{.SecurityStatus}}</span></h2>
+{{if .SecurityErr}}<p>Privileges not audited: {{.SecurityErr}}</p>
+{{else if not .Findings}}<p>No dangerous grants found.</p>
+{{else}}<p>{{.FindingCount}} grant(s) worth a second look:</p>
+<table cellpadding="4" border="1" style="border-collapse: collapse;">
+<tr><th>User</th><th>On</th><th>Privilege</th><th>Why</th></tr>
+{{range .Findings}}<tr><td>{{.User}}</td><td>{{.On}}</td><td>{{.Privilege}}</td><td>{{.Reason}}</td></tr>
+{{end}}</table>
+{{if .MoreFindings}}<p>...and {{.MoreFindings}} more; see ysm user audit.</p>{{end}}
+{{end}}
+<h2>Backups: <span style="color: {{color .BackupStatus}}">{{.BackupStatus}}</span></h2>
+{{if .BackupErr}}<p>Backups not checked: {{.BackupErr}}</p>
+{{else if not .Backups}}<p>No databases to back up.</p>
+{{else}}<table cellpadding="4" border="1" style="border-collapse: collapse;">
+<tr><th>Status</th><th>Database</th><th>Last backup</th><th>Age</th></tr>
+{{range .Backups}}<tr>{{template "status" .Status}}<td>{{.Database}}</td><td>{{.Last}}</td><td>{{.Age}}</td></tr>
+{{end}}</table>
+{{end}}
+</body></html>
+`))
+
+// HTML renders the digest as an HTML page, for email
+func (d *HealthDigest) HTML() string {
+	type check struct{ Status, Name, Message string }
+	type finding struct{ User, On, Privilege, Reason string }
+	data := struct {
+		Subject, Server, Status, Time        string
+		HealthStatus, SecurityStatus         string
+		BackupStatus, SecurityErr, BackupErr string
+		Checks                               []check
+		Findings                             []finding
+		FindingCount, MoreFindings           int
+		Backups                              []digestBackupRow
+	}{
+		Subject:        d.Subject(),
+		Server:         d.Server,
+		Status:         d.Status().String(),
+		Time:           d.Time.Format("Mon 2006-01-02 15:04 MST"),
+		HealthStatus:   d.Health.Status().String(),
+		SecurityStatus: d.SecurityStatus().String(),
+		BackupStatus:   d.BackupStatus().String(),
+		SecurityErr:    d.SecurityErr,
+		BackupErr:      d.BackupErr,
+		FindingCount:   len(d.Findings),
+		Backups:        d.backupRows(),
+	}
+	for _, c := range d.Health.Checks {
+		status := c.Status.String()
+		if c.Skipped {
+			status = "SKIP"
+		}
+		data.Checks = append(data.Checks, check{status, c.Name, c.Message})
+	}
+	findings, more := d.shownFindings()
+	for _, f := range findings {
+		data.Findings = append(data.Findings, finding{findingGrantee(f), findingObject(f), f.Privilege, f.Reason})
+	}
+	data.MoreFindings = more
+
+	var b bytes.Buffer
+	if err := digestHTML.Execute(&b, data); err != nil {
+		// The template is fixed; failing means a bug, so show it
+		return fmt.Sprintf("<pre>%s</pre>", template.HTMLEscapeString(err.Error()))
+	}
+	return b.String()
+}
@@ -0,0 +1,138 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/scratch"
+)
+
+// HostMetrics is one reading of the machine the server runs on. Percentages
+// come from counter deltas since the previous reading; a negative value means
+// the metric was unavailable
+type HostMetrics struct {
+	Time        time.Time
+	CPU         float64 // Percent of CPU time spent busy, all cores
+	IOWait      float64 // Percent of CPU time spent idle waiting on IO
+	MemoryTotal int64
+	MemoryUsed  int64 // Total less what is available without swapping
+	DataDir     string
+	DiskTotal   int64   // Size of the data directory's filesystem
+	DiskUsed    int64   // Space used on it
+	DiskBusy    float64 // Percent of time the data directory's device had IO in flight
+}
+
+// MemoryPercent returns the share of memory in use, or -1 when unknown
+func (h *HostMetrics) MemoryPercent() float64 {
+	if h.MemoryTotal <= 0 {
+		return -1
+	}
+	return 100 * float64(h.MemoryUsed) / float64(h.MemoryTotal)
+}
+
+// DiskPercent returns the share of the data directory's filesystem in use,
+// or -1 when unknown
+func (h *HostMetrics) DiskPercent() float64 {
+	if h.DiskTotal <= 0 {
+		return -1
+	}
+	return 100 * float64(h.DiskUsed) / float64(h.DiskTotal)
+}
+
+// hostCounters holds the cumulative counters a reading's percentages derive from
+type hostCounters struct {
+	at      time.Time
+	cpuAll  uint64 // Jiffies in every CPU state
+	cpuIdle uint64 // Jiffies idle, IO wait included
+	iowait  uint64
+	ioTicks int64 // Milliseconds the device had IO in flight; -1 when unknown
+}
+
+// hostSampler reads the host's metrics for a server running on this machine
+type hostSampler struct {
+	dataDir string
+	device  string // Block device holding dataDir; "" when it can't be found
+	prev    *hostCounters
+}
+
+// newHostSampler prepares host readings, which are only meaningful when the
+// server shares this machine
+func (c *Connection) newHostSampler() (*hostSampler, error) {
+	if !c.isLocal() {
+		return nil, fmt.Errorf("host metrics need the server on this machine")
+	}
+	var dataDir string
+	if err := c.DB.QueryRow(c.Driver.DataDirectoryQuery()).Scan(&dataDir); err != nil || dataDir == "" {
+		return nil, fmt.Errorf("can't find the data directory: %v", err)
+	}
+
+	s := &hostSampler{dataDir: dataDir}
+	s.device, _ = blockDevice(dataDir)
+	// Fail now rather than on every sample where /proc isn't there
+	if _, err := readHostCounters(s.device); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// sample takes one reading; CPU and device percentages need a previous
+// reading and are -1 on the first
+func (s *hostSampler) sample() (*HostMetrics, error) {
+	counters, err := readHostCounters(s.device)
+	if err != nil {
+		s.prev = nil
+		return nil, err
+	}
+
+	h := &HostMetrics{Time: counters.at, CPU: -1, IOWait: -1, DiskBusy: -1, DataDir: s.dataDir}
+	if total, available, err := readMemory(); err == nil {
+		h.MemoryTotal = total
+		h.MemoryUsed = total - available
+	}
+	if total, err := scratch.DiskSize(s.dataDir); err == nil {
+		if free, err := scratch.DiskFree(s.dataDir); err == nil {
+			h.DiskTotal = total
+			h.DiskUsed = total - free
+		}
+	}
+
+	prev := s.prev
+	s.prev = counters
+	if prev == nil {
+		return h, nil
+	}
+
+	// The kernel's IO wait counter can step backwards, so every delta is
+	// checked before use
+	if counters.cpuAll > prev.cpuAll && counters.cpuIdle >= prev.cpuIdle {
+		all := counters.cpuAll - prev.cpuAll
+		idle := min(counters.cpuIdle-prev.cpuIdle, all)
+		h.CPU = 100 * float64(all-idle) / float64(all)
+		if counters.iowait >= prev.iowait {
+			h.IOWait = 100 * float64(min(counters.iowait-prev.iowait, all)) / float64(all)
+		}
+	}
+	elapsed := counters.at.Sub(prev.at).Milliseconds()
+	if elapsed > 0 && prev.ioTicks >= 0 && counters.ioTicks >= prev.ioTicks {
+		h.DiskBusy = min(100, 100*float64(counters.ioTicks-prev.ioTicks)/float64(elapsed))
+	}
+	return h, nil
+}
@@ -0,0 +1,140 @@
+//go:build linux
+
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// readHostCounters reads the CPU counters from /proc/stat and, when device
+// is set, its IO time from /proc/diskstats
+func readHostCounters(device string) (*hostCounters, error) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return nil, fmt.Errorf("can't read host CPU usage: %w", err)
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 6 || fields[0] != "cpu" {
+		return nil, fmt.Errorf("can't read host CPU usage: unexpected /proc/stat format")
+	}
+
+	counters := &hostCounters{at: time.Now(), ioTicks: -1}
+	// user nice system idle iowait irq softirq steal; guest time is already
+	// counted in user and nice
+	for i, f := range fields[1:min(len(fields), 9)] {
+		n, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("can't read host CPU usage: %w", err)
+		}
+		counters.cpuAll += n
+		switch i {
+		case 3:
+			counters.cpuIdle += n
+		case 4:
+			counters.cpuIdle += n
+			counters.iowait = n
+		}
+	}
+
+	if device != "" {
+		counters.ioTicks = readIOTicks(device)
+	}
+	return counters, nil
+}
+
+// readIOTicks returns the milliseconds device spent with IO in flight, or -1
+// when it isn't listed in /proc/diskstats
+func readIOTicks(device string) int64 {
+	f, err := os.Open("/proc/diskstats")
+	if err != nil {
+		return -1
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// major minor name reads ... io_in_progress io_ticks ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 13 || fields[2] != device {
+			continue
+		}
+		ticks, err := strconv.ParseInt(fields[12], 10, 64)
+		if err != nil {
+			return -1
+		}
+		return ticks
+	}
+	return -1
+}
+
+// readMemory returns the total and available memory from /proc/meminfo
+func readMemory() (total, available int64, err error) {
+	data, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0, 0, fmt.Errorf("can't read host memory: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		kb, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "MemTotal":
+			total = kb * 1024
+		case "MemAvailable":
+			available = kb * 1024
+		}
+	}
+	if total == 0 {
+		return 0, 0, fmt.Errorf("can't read host memory: no MemTotal in /proc/meminfo")
+	}
+	return total, available, nil
+}
+
+// blockDevice returns the /proc/diskstats name of the device holding path
+func blockDevice(path string) (string, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return "", err
+	}
+	dev := uint64(st.Dev)
+	major := (dev>>8)&0xfff | (dev>>32)&^0xfff
+	minor := dev&0xff | (dev>>12)&^0xff
+
+	// /sys/dev/block/MAJOR:MINOR links to the device's sysfs directory,
+	// which is named after it
+	target, err := os.Readlink(fmt.Sprintf("/sys/dev/block/%d:%d", major, minor))
+	if err != nil {
+		return "", err
+	}
+	return target[strings.LastIndex(target, "/")+1:], nil
+}
@@ -0,0 +1,37 @@
+//go:build !linux
+
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import "errors"
+
+var errNoHostMetrics = errors.New("host metrics are only available on Linux")
+
+func readHostCounters(device string) (*hostCounters, error) {
+	return nil, errNoHostMetrics
+}
+
+func readMemory() (total, available int64, err error) {
+	return 0, 0, errNoHostMetrics
+}
+
+func blockDevice(path string) (string, error) {
+	return "", errNoHostMetrics
+}
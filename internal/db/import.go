@@ -20,8 +20,10 @@ package db
 
 import (
 	"bufio"
+	"cmp"
 	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -36,6 +38,7 @@ import (
 
 	"github.com/blubskye/yandere_sql_manager/internal/buffer"
 	"github.com/blubskye/yandere_sql_manager/internal/logging"
+	"github.com/blubskye/yandere_sql_manager/internal/scratch"
 )
 
 // ImportOptions configures the import behavior
@@ -46,8 +49,7 @@ type ImportOptions struct {
 	RenameDB           string            // Rename database during import (empty = use original)
 	BatchSize          int               // Number of statements per transaction batch (0 = auto)
 	BufferSize         int               // Read buffer size in bytes (0 = default 64KB)
-	OnProgress         func(bytesRead, totalBytes int64, statementsExecuted int64)
-	OnError            func(err error, statement string) bool // Return true to continue, false to abort
+	OnProgress         func(bytesRead, totalBytes, statementsExecuted, rowsInserted int64) // Compressed files report compressed bytes; rows are estimated like RowsInserted
 	MaxMemory          int64             // Maximum memory for statement buffer (0 = 64MB)
 	ResumeFromByte     int64             // Resume from this byte position (for interrupted imports)
 	DisableForeignKeys bool              // Disable foreign key checks during import
@@ -55,18 +57,36 @@ type ImportOptions struct {
 	SetVariables       map[string]string // Additional variables to set before import
 	UseNativeTool      bool              // Use pg_restore/mysql instead of built-in import
 	Jobs               int               // Number of parallel jobs for pg_restore (0 = default)
+	NoOwner            bool              // pg_restore: don't give objects their dumped owners, the restoring role keeps them
+	NoACL              bool              // pg_restore: skip the dumped privileges (GRANT/REVOKE)
 	Parallel           int               // Number of parallel workers for batch execution (0 = sequential)
-	ContinueOnError    bool              // Continue processing even if errors occur
+	ContinueOnError    bool              // Skip failed batches, or with ErrorPolicy the statements it doesn't name
+	ErrorPolicy        ImportErrorPolicy // Skip, rewrite or abort per class of failed statement (nil = whole batches)
+	Analyze            bool              // Refresh optimizer statistics of the imported tables afterwards
+	OnAnalyze          func(table string, tableNum, totalTables int)
+	VerifyManifest     bool              // Count the loaded rows against the dump's manifest afterwards
+	Context            context.Context   // Cancels the built-in import between reads of the file (nil = never)
 }
 
 // ImportStats contains statistics about the import
 type ImportStats struct {
-	BytesRead          int64
-	StatementsExecuted int64
-	ErrorsEncountered  int64
-	Duration           time.Duration
-	Compressed         bool
-	CompressionType    string
+	BytesRead          int64                 `json:"bytes_read"`
+	StatementsExecuted int64                 `json:"statements_executed"`
+	ErrorsEncountered  int64                 `json:"errors"`
+	Duration           time.Duration         `json:"duration_ns"`
+	Compressed         bool                  `json:"compressed"`
+	CompressionType    string                `json:"compression,omitempty"`
+	TablesAnalyzed     int                   `json:"tables_analyzed"`
+	NativeTool         bool                  `json:"native_tool"`              // Loaded by pg_restore or psql, so the details below are empty
+	Statements         ImportStatementCounts `json:"statements"`               // Statements read, by type
+	TablesCreated      []string              `json:"tables_created,omitempty"` // By CREATE TABLE, in file order
+	RowsInserted       int64                 `json:"rows_inserted"`            // Estimated from the VALUES tuples of INSERT and REPLACE
+	LargeObjects       int64                 `json:"large_objects,omitempty"`  // PostgreSQL large objects loaded under new OIDs
+	WarningCount       int64                 `json:"warning_count"`            // Errors continued past and skipped statements
+	ErrorsByClass      map[ImportErrorClass]int64 `json:"errors_by_class,omitempty"` // Statements skipped or rewritten by the error policy
+	Warnings           []string              `json:"warnings,omitempty"`       // The first of them
+	Phases             []ImportPhase         `json:"phases"`                   // Elapsed time per step
+	Manifest           *ManifestCheck        `json:"manifest,omitempty"`       // Loaded rows against the dump's manifest
 }
 
 // ImportSQL imports a SQL file into the database with improved buffering
@@ -76,16 +96,66 @@ func (c *Connection) ImportSQL(opts ImportOptions) error {
 	return err
 }
 
-// ImportSQLWithStats imports a SQL file and returns detailed statistics
+// ImportSQLWithStats imports a SQL file and returns detailed statistics.
+// With VerifyManifest the dump must have a manifest; tables that don't hold
+// the rows it lists are reported in the stats, not as an error. A cancelled
+// import keeps the batches it already committed. Imports are recorded in
+// the audit log.
 func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error) {
+	stats, err := c.importSQLWithStats(opts)
+	detail := "Into " + cmp.Or(opts.RenameDB, opts.Database, "the databases named in the file")
+	if stats != nil {
+		detail += fmt.Sprintf(", %d statements", stats.StatementsExecuted)
+	}
+	c.audit(AuditImport, opts.FilePath, detail, err)
+	return stats, err
+}
+
+// importSQLWithStats is ImportSQLWithStats without the audit entry, for
+// restores, which record one entry for the whole backup
+func (c *Connection) importSQLWithStats(opts ImportOptions) (*ImportStats, error) {
+	var manifest *DumpManifest
+	if opts.VerifyManifest {
+		var err error
+		if manifest, err = ReadDumpManifest(opts.FilePath); err != nil {
+			return nil, fmt.Errorf("failed to read the manifest: %w", err)
+		}
+		if manifest == nil {
+			return nil, fmt.Errorf("%s has no manifest; only data exports written by YSM have one", filepath.Base(opts.FilePath))
+		}
+	}
+
+	stats, err := c.importSQL(opts)
+	if errors.Is(err, context.Canceled) {
+		return stats, fmt.Errorf("import cancelled after %d statements; the batches before it were kept", stats.StatementsExecuted)
+	}
+	if err != nil || manifest == nil {
+		return stats, err
+	}
+
+	targetDB := opts.Database
+	if opts.RenameDB != "" {
+		targetDB = opts.RenameDB
+	}
+	verifyStart := time.Now()
+	stats.Manifest, err = c.VerifyManifest(targetDB, manifest)
+	stats.Phases = append(stats.Phases, ImportPhase{Name: "verify", Duration: time.Since(verifyStart)})
+	stats.Duration += time.Since(verifyStart)
+	return stats, err
+}
+
+func (c *Connection) importSQL(opts ImportOptions) (*ImportStats, error) {
 	startTime := time.Now()
 	stats := &ImportStats{}
+	ctx := orBackground(opts.Context)
 
 	logging.Debug("Starting SQL import from: %s", opts.FilePath)
 
-	// Detect if this is a PostgreSQL dump file
-	ext := strings.ToLower(filepath.Ext(opts.FilePath))
-	baseName := strings.ToLower(filepath.Base(opts.FilePath))
+	// Detect if this is a PostgreSQL dump file. A split dump is named after
+	// the file it was split from.
+	dumpName := buffer.SplitDumpName(opts.FilePath)
+	ext := strings.ToLower(filepath.Ext(dumpName))
+	baseName := strings.ToLower(filepath.Base(dumpName))
 
 	isPgDump := ext == ".dump" || ext == ".pgdump" ||
 		strings.HasSuffix(baseName, ".dump.gz") ||
@@ -94,7 +164,33 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 
 	// Use pg_restore for PostgreSQL dump files
 	if c.Config.Type == DatabaseTypePostgres && (isPgDump || opts.UseNativeTool) {
-		return c.importWithPgRestore(opts)
+		// The native tools read a whole file, so split dumps are joined first
+		if buffer.IsSplitManifest(opts.FilePath) {
+			joined, cleanup, err := joinSplitDump(opts.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			defer cleanup()
+			opts.FilePath = joined
+		}
+		stats, err := c.importWithPgRestore(opts)
+		if err != nil {
+			return stats, err
+		}
+		stats.NativeTool = true
+		stats.Phases = append(stats.Phases, ImportPhase{Name: "restore", Duration: stats.Duration})
+		if opts.Analyze {
+			// The native tools don't say what they loaded, so analyze everything
+			targetDB := opts.Database
+			if opts.RenameDB != "" {
+				targetDB = opts.RenameDB
+			}
+			analyzeStart := time.Now()
+			stats.TablesAnalyzed, err = c.analyzeDatabase(targetDB, opts.OnAnalyze)
+			stats.Phases = append(stats.Phases, ImportPhase{Name: "analyze", Duration: time.Since(analyzeStart)})
+			stats.Duration = time.Since(startTime)
+		}
+		return stats, err
 	}
 
 	// Get file size to determine optimal buffer size
@@ -119,26 +215,23 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 		logging.Debug("Using batch size: %d statements", opts.BatchSize)
 	}
 
-	// Open file and detect compression
-	file, err := os.Open(opts.FilePath)
+	// Open file (or a split dump's parts, in order) and detect compression
+	file, totalBytes, err := buffer.OpenFile(opts.FilePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+		return nil, err
 	}
 	defer file.Close()
 
-	// Get file size for progress
-	stat, err := file.Stat()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get file stats: %w", err)
-	}
-	totalBytes := stat.Size()
+	// The uncompressed size of a compressed file isn't known up front, so
+	// progress for those counts the compressed bytes taken from the file
+	compressed := buffer.NewProgressReader(file, totalBytes, nil)
 
 	// Create reader based on file extension (handle compression)
 	var reader io.Reader
-	ext = strings.ToLower(filepath.Ext(opts.FilePath))
+	ext = strings.ToLower(filepath.Ext(dumpName))
 
 	// Handle double extensions like .sql.xz
-	baseName = filepath.Base(opts.FilePath)
+	baseName = filepath.Base(dumpName)
 	if strings.HasSuffix(strings.ToLower(baseName), ".sql.xz") {
 		ext = ".xz"
 	} else if strings.HasSuffix(strings.ToLower(baseName), ".sql.gz") {
@@ -152,8 +245,8 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 		stats.Compressed = true
 		stats.CompressionType = "xz"
 		// Use external xz command for decompression (more efficient)
-		cmd := exec.Command("xz", "-dc")
-		cmd.Stdin = file
+		cmd := exec.CommandContext(ctx, "xz", "-dc")
+		cmd.Stdin = compressed
 		stdout, err := cmd.StdoutPipe()
 		if err != nil {
 			return nil, fmt.Errorf("failed to create xz pipe: %w", err)
@@ -163,14 +256,13 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 		}
 		defer cmd.Wait()
 		reader = stdout
-		totalBytes = -1 // Unknown uncompressed size
 
 	case ".zst", ".zstd":
 		stats.Compressed = true
 		stats.CompressionType = "zstd"
 		// Use external zstd command for decompression
-		cmd := exec.Command("zstd", "-dc")
-		cmd.Stdin = file
+		cmd := exec.CommandContext(ctx, "zstd", "-dc")
+		cmd.Stdin = compressed
 		stdout, err := cmd.StdoutPipe()
 		if err != nil {
 			return nil, fmt.Errorf("failed to create zstd pipe: %w", err)
@@ -180,24 +272,26 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 		}
 		defer cmd.Wait()
 		reader = stdout
-		totalBytes = -1 // Unknown uncompressed size
 
 	case ".gz", ".gzip":
 		stats.Compressed = true
 		stats.CompressionType = "gzip"
-		gzReader, err := gzip.NewReader(file)
+		gzReader, err := gzip.NewReader(compressed)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
 		}
 		defer gzReader.Close()
 		reader = gzReader
-		totalBytes = -1 // Unknown uncompressed size
 
 	default:
 		reader = file
 		// Resume support for uncompressed files
 		if opts.ResumeFromByte > 0 {
-			if _, err := file.Seek(opts.ResumeFromByte, 0); err != nil {
+			seeker, ok := file.(io.Seeker)
+			if !ok {
+				return nil, fmt.Errorf("resuming isn't supported for split dumps")
+			}
+			if _, err := seeker.Seek(opts.ResumeFromByte, 0); err != nil {
 				return nil, fmt.Errorf("failed to seek to resume position: %w", err)
 			}
 			stats.BytesRead = opts.ResumeFromByte
@@ -205,7 +299,7 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 	}
 
 	// Wrap in buffered reader
-	bufReader := bufio.NewReaderSize(reader, opts.BufferSize)
+	bufReader := bufio.NewReaderSize(contextReader{ctx: ctx, r: reader}, opts.BufferSize)
 
 	// Determine target database
 	targetDB := opts.Database
@@ -268,10 +362,25 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 	var bytesRead atomic.Int64
 	bytesRead.Store(stats.BytesRead)
 
+	// progressBytes is the position reported to OnProgress
+	progressBytes := bytesRead.Load
+	if stats.Compressed {
+		progressBytes = func() int64 {
+			read, _ := compressed.Progress()
+			return read
+		}
+	}
+
+	loadStart := time.Now()
+	stats.Phases = append(stats.Phases, ImportPhase{Name: "prepare", Duration: loadStart.Sub(startTime)})
+
 	parser := newSQLParser(bufReader, opts.MaxMemory)
+	affected := newAffectedTables()
+	tally := newImportTally(stats)
+	charsets := newCharsetGuard(c, tally)
 	var batch []string
 	var statementsExecuted atomic.Int64
-	var errorsEncountered atomic.Int64
+	failed := newImportErrorHandler(c, opts.ErrorPolicy, opts.ContinueOnError)
 
 	if useParallel {
 		// Parallel batch execution
@@ -279,10 +388,12 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 
 		executor := newParallelBatchExecutor(c, opts.Parallel)
 		executor.Start()
+		objects := &largeObjectLoader{conn: c}
 
 		var batchIndex int
 		var firstError error
 		var resultWg sync.WaitGroup
+		var rowsSubmitted atomic.Int64 // The tally is the parser's; the collector reads this
 
 		// Start result collector
 		resultWg.Add(1)
@@ -290,14 +401,11 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 			defer resultWg.Done()
 			for result := range executor.Results() {
 				if result.err != nil {
-					errorsEncountered.Add(1)
-					if opts.OnError != nil {
-						if !opts.OnError(result.err, result.failStmt) && firstError == nil {
-							firstError = result.err
-							// Don't stop - let other batches complete
-						}
-					} else if firstError == nil && !opts.ContinueOnError {
-						firstError = result.err
+					// Don't stop on an abort - let other batches complete
+					executed, err := failed.handle(result.statements, result.err)
+					statementsExecuted.Add(executed)
+					if err != nil && firstError == nil {
+						firstError = err
 					}
 				} else {
 					statementsExecuted.Add(int64(result.count))
@@ -305,7 +413,7 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 
 				// Report progress
 				if opts.OnProgress != nil {
-					opts.OnProgress(bytesRead.Load(), totalBytes, statementsExecuted.Load())
+					opts.OnProgress(progressBytes(), totalBytes, statementsExecuted.Load(), rowsSubmitted.Load())
 				}
 			}
 		}()
@@ -321,6 +429,7 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 			if err != nil {
 				executor.Stop()
 				resultWg.Wait()
+				stats.StatementsExecuted = statementsExecuted.Load()
 				return stats, fmt.Errorf("failed to parse SQL: %w", err)
 			}
 
@@ -334,36 +443,60 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 				upperStmt := strings.ToUpper(stmt)
 				if strings.Contains(upperStmt, "CREATE DATABASE") ||
 					strings.HasPrefix(upperStmt, "USE ") {
+					tally.warn("Skipped for the rename: %s", truncateSQL(stmt))
 					continue
 				}
 			}
 
+			// Large objects load before the rows that look up their OIDs
+			if taken, err := objects.take(ctx, stmt); taken {
+				tally.note(stmt)
+				if err != nil {
+					executor.Stop()
+					resultWg.Wait()
+					stats.StatementsExecuted = statementsExecuted.Load()
+					return stats, err
+				}
+				statementsExecuted.Add(1)
+				continue
+			}
+
+			if opts.Analyze {
+				affected.note(stmt)
+			}
+			tally.note(stmt)
+			charsets.note(stmt)
 			batch = append(batch, stmt)
 
 			// Submit batch
 			if len(batch) >= opts.BatchSize {
+				rowsSubmitted.Store(stats.RowsInserted)
 				executor.Submit(batchIndex, batch)
 				batchIndex++
-				clear(batch)
+				batch = batch[:0]
 			}
 		}
 
 		// Submit remaining batch
 		if len(batch) > 0 {
+			rowsSubmitted.Store(stats.RowsInserted)
 			executor.Submit(batchIndex, batch)
 		}
 
 		// Wait for all batches to complete
 		executor.Wait()
 		resultWg.Wait()
-
-		if firstError != nil && !opts.ContinueOnError {
-			return stats, firstError
+		if err := objects.finish(); err != nil && firstError == nil {
+			firstError = err
 		}
 
-		stats.ErrorsEncountered = errorsEncountered.Load()
+		failed.flush(stats, tally)
 		stats.StatementsExecuted = statementsExecuted.Load()
 
+		if firstError != nil {
+			return stats, firstError
+		}
+
 	} else {
 		// Sequential batch execution (original logic)
 		var seqStatementsExecuted int64
@@ -376,6 +509,7 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 				break
 			}
 			if err != nil {
+				stats.StatementsExecuted = seqStatementsExecuted
 				return stats, fmt.Errorf("failed to parse SQL: %w", err)
 			}
 
@@ -389,33 +523,35 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 				upperStmt := strings.ToUpper(stmt)
 				if strings.Contains(upperStmt, "CREATE DATABASE") ||
 					strings.HasPrefix(upperStmt, "USE ") {
+					tally.warn("Skipped for the rename: %s", truncateSQL(stmt))
 					continue
 				}
 			}
 
+			if opts.Analyze {
+				affected.note(stmt)
+			}
+			tally.note(stmt)
+			charsets.note(stmt)
 			batch = append(batch, stmt)
 
 			// Execute batch
 			if len(batch) >= opts.BatchSize {
 				if err := c.executeBatch(batch); err != nil {
-					if opts.OnError != nil && opts.OnError(err, batch[len(batch)-1]) {
-						stats.ErrorsEncountered++
-						clear(batch)
-						continue
-					}
-					if opts.ContinueOnError {
-						stats.ErrorsEncountered++
-						clear(batch)
-						continue
+					executed, err := failed.handle(batch, err)
+					seqStatementsExecuted += executed
+					failed.flush(stats, tally)
+					if err != nil {
+						return stats, err
 					}
-					return stats, err
+				} else {
+					seqStatementsExecuted += int64(len(batch))
 				}
-				seqStatementsExecuted += int64(len(batch))
-				clear(batch)
+				batch = batch[:0]
 
 				// Report progress
 				if opts.OnProgress != nil {
-					opts.OnProgress(bytesRead.Load(), totalBytes, seqStatementsExecuted)
+					opts.OnProgress(progressBytes(), totalBytes, seqStatementsExecuted, stats.RowsInserted)
 				}
 			}
 		}
@@ -423,13 +559,12 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 		// Execute remaining batch
 		if len(batch) > 0 {
 			if err := c.executeBatch(batch); err != nil {
-				if opts.OnError == nil || !opts.OnError(err, batch[len(batch)-1]) {
-					if !opts.ContinueOnError {
-						return stats, err
-					}
-					stats.ErrorsEncountered++
-				} else {
-					stats.ErrorsEncountered++
+				executed, err := failed.handle(batch, err)
+				seqStatementsExecuted += executed
+				failed.flush(stats, tally)
+				if err != nil {
+					stats.StatementsExecuted = seqStatementsExecuted
+					return stats, err
 				}
 			} else {
 				seqStatementsExecuted += int64(len(batch))
@@ -439,6 +574,14 @@ func (c *Connection) ImportSQLWithStats(opts ImportOptions) (*ImportStats, error
 		stats.StatementsExecuted = seqStatementsExecuted
 	}
 
+	stats.Phases = append(stats.Phases, ImportPhase{Name: "load", Duration: time.Since(loadStart)})
+
+	if opts.Analyze {
+		analyzeStart := time.Now()
+		stats.TablesAnalyzed = c.analyzeTables(affected.names, opts.OnAnalyze)
+		stats.Phases = append(stats.Phases, ImportPhase{Name: "analyze", Duration: time.Since(analyzeStart)})
+	}
+
 	stats.BytesRead = bytesRead.Load()
 	stats.Duration = time.Since(startTime)
 
@@ -479,10 +622,10 @@ type batchTask struct {
 
 // batchResult represents the result of executing a batch
 type batchResult struct {
-	index    int
-	count    int
-	err      error
-	failStmt string
+	index      int
+	count      int
+	err        error
+	statements []string // The rolled back batch, for the error policy
 }
 
 // parallelBatchExecutor manages concurrent batch execution
@@ -549,9 +692,7 @@ func (pe *parallelBatchExecutor) worker(id int) {
 
 			if err != nil {
 				result.err = err
-				if len(task.statements) > 0 {
-					result.failStmt = task.statements[len(task.statements)-1]
-				}
+				result.statements = task.statements
 				pe.errors.Add(1)
 			}
 
@@ -744,7 +885,7 @@ func (c *Connection) ImportSQLWithCallback(filePath, database string, progress f
 		FilePath: filePath,
 		Database: database,
 		CreateDB: true,
-		OnProgress: func(bytesRead, totalBytes int64, _ int64) {
+		OnProgress: func(bytesRead, totalBytes, _, _ int64) {
 			if totalBytes > 0 && progress != nil {
 				progress(float64(bytesRead) / float64(totalBytes) * 100)
 			}
@@ -752,6 +893,25 @@ func (c *Connection) ImportSQLWithCallback(filePath, database string, progress f
 	})
 }
 
+// joinSplitDump reassembles a split dump in a temporary directory, under the
+// name it had before it was split so its format is still recognized
+func joinSplitDump(manifestPath string) (string, func(), error) {
+	m, err := buffer.ReadSplitManifest(manifestPath)
+	if err != nil {
+		return "", nil, err
+	}
+	dir, err := scratch.MkdirTemp("split-*", m.Size)
+	if err != nil {
+		return "", nil, err
+	}
+	joined := filepath.Join(dir, m.File)
+	if err := m.Join(joined); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+	return joined, func() { os.RemoveAll(dir) }, nil
+}
+
 // importWithPgRestore imports a PostgreSQL dump using pg_restore
 func (c *Connection) importWithPgRestore(opts ImportOptions) (*ImportStats, error) {
 	startTime := time.Now()
@@ -840,6 +1000,15 @@ func (c *Connection) runPgRestore(opts ImportOptions, targetDB string, startTime
 		args = append(args, "--clean", "--if-exists")
 	}
 
+	// pg_dump ignores --no-owner for archive formats, so restoring one
+	// under another role leaves the owners out here
+	if opts.NoOwner {
+		args = append(args, "--no-owner")
+	}
+	if opts.NoACL {
+		args = append(args, "--no-acl")
+	}
+
 	// Add the file to restore
 	args = append(args, opts.FilePath)
 
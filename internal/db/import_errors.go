@@ -0,0 +1,336 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// ImportErrorClass groups the statement failures an import can treat alike
+type ImportErrorClass string
+
+// Import error classes
+const (
+	ImportErrorDuplicateKey     ImportErrorClass = "duplicate_key"     // A row with the same unique key exists
+	ImportErrorUnknownCollation ImportErrorClass = "unknown_collation" // The server lacks a collation, e.g. MySQL 8's utf8mb4_0900_ai_ci
+	ImportErrorSyntax           ImportErrorClass = "syntax"            // The server can't parse the statement
+	ImportErrorTableExists      ImportErrorClass = "table_exists"      // CREATE TABLE for a table that is there
+	ImportErrorOther            ImportErrorClass = "other"             // Anything else
+)
+
+// ImportErrorClasses lists the classes in the order forms show them
+var ImportErrorClasses = []ImportErrorClass{
+	ImportErrorDuplicateKey,
+	ImportErrorUnknownCollation,
+	ImportErrorSyntax,
+	ImportErrorTableExists,
+	ImportErrorOther,
+}
+
+// ImportErrorAction is what an import does about a failed statement
+type ImportErrorAction string
+
+// Import error actions
+const (
+	ImportErrorAbort   ImportErrorAction = "abort"   // Stop the import
+	ImportErrorSkip    ImportErrorAction = "skip"    // Leave the statement out and carry on
+	ImportErrorRewrite ImportErrorAction = "rewrite" // Swap the unknown collation for one the server has, and retry
+)
+
+// Actions returns the actions a class can take, abort first
+func (class ImportErrorClass) Actions() []ImportErrorAction {
+	if class == ImportErrorUnknownCollation {
+		return []ImportErrorAction{ImportErrorAbort, ImportErrorSkip, ImportErrorRewrite}
+	}
+	return []ImportErrorAction{ImportErrorAbort, ImportErrorSkip}
+}
+
+// ImportErrorPolicy says what to do per class of failed statement. Classes
+// left out abort, or follow the "other" entry when there is one.
+type ImportErrorPolicy map[ImportErrorClass]ImportErrorAction
+
+// ParseImportErrorPolicy reads a policy like "duplicate_key=skip,syntax=abort"
+func ParseImportErrorPolicy(spec string) (ImportErrorPolicy, error) {
+	policy := make(ImportErrorPolicy)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected class=action, got '%s'", part)
+		}
+		class := ImportErrorClass(strings.TrimSpace(name))
+		action := ImportErrorAction(strings.TrimSpace(value))
+		if !containsClass(ImportErrorClasses, class) {
+			return nil, fmt.Errorf("unknown error class '%s' (use: %s)", class, joinClasses(ImportErrorClasses))
+		}
+		if !containsAction(class.Actions(), action) {
+			return nil, fmt.Errorf("%s can't %s (use: %s)", class, action, joinActions(class.Actions()))
+		}
+		policy[class] = action
+	}
+	return policy, nil
+}
+
+// String formats the policy the way ParseImportErrorPolicy reads it
+func (p ImportErrorPolicy) String() string {
+	var parts []string
+	for _, class := range ImportErrorClasses {
+		if action, ok := p[class]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%s", class, action))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// action returns what to do about a class
+func (p ImportErrorPolicy) action(class ImportErrorClass) ImportErrorAction {
+	if action, ok := p[class]; ok {
+		return action
+	}
+	if action, ok := p[ImportErrorOther]; ok && action != ImportErrorRewrite {
+		return action
+	}
+	return ImportErrorAbort
+}
+
+func containsClass(classes []ImportErrorClass, class ImportErrorClass) bool {
+	for _, c := range classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAction(actions []ImportErrorAction, action ImportErrorAction) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+func joinClasses(classes []ImportErrorClass) string {
+	names := make([]string, len(classes))
+	for i, c := range classes {
+		names[i] = string(c)
+	}
+	return strings.Join(names, ", ")
+}
+
+func joinActions(actions []ImportErrorAction) string {
+	names := make([]string, len(actions))
+	for i, a := range actions {
+		names[i] = string(a)
+	}
+	return strings.Join(names, ", ")
+}
+
+// mariadbErrorClasses maps MariaDB/MySQL error numbers to classes
+var mariadbErrorClasses = map[uint16]ImportErrorClass{
+	1062: ImportErrorDuplicateKey,     // ER_DUP_ENTRY
+	1586: ImportErrorDuplicateKey,     // ER_DUP_ENTRY_WITH_KEY_NAME
+	1273: ImportErrorUnknownCollation, // ER_UNKNOWN_COLLATION
+	1064: ImportErrorSyntax,           // ER_PARSE_ERROR
+	1149: ImportErrorSyntax,           // ER_SYNTAX_ERROR
+	1050: ImportErrorTableExists,      // ER_TABLE_EXISTS_ERROR
+}
+
+// postgresErrorClasses maps PostgreSQL SQLSTATE codes to classes
+var postgresErrorClasses = map[pq.ErrorCode]ImportErrorClass{
+	"23505": ImportErrorDuplicateKey, // unique_violation
+	"42601": ImportErrorSyntax,       // syntax_error
+	"42P07": ImportErrorTableExists,  // duplicate_table
+}
+
+// ClassifyImportError returns the class of a failed statement's error
+func ClassifyImportError(err error) ImportErrorClass {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		if class, ok := mariadbErrorClasses[myErr.Number]; ok {
+			return class
+		}
+		return ImportErrorOther
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// undefined_object covers more than collations, so check the message
+		if pqErr.Code == "42704" && strings.Contains(pqErr.Message, "collation") {
+			return ImportErrorUnknownCollation
+		}
+		if class, ok := postgresErrorClasses[pqErr.Code]; ok {
+			return class
+		}
+	}
+	return ImportErrorOther
+}
+
+// unknownCollationRe finds the collation name in the server's error
+var unknownCollationRe = regexp.MustCompile(`(?i)collation:? ['"]([^'"]+)['"]`)
+
+// importErrorHandler applies the policy to failed batches. Its warnings are
+// kept until flush, as the parallel import handles failures beside the
+// goroutine reading the file.
+type importErrorHandler struct {
+	conn      *Connection
+	policy    ImportErrorPolicy // nil: a failed batch aborts, or is skipped whole with skipBatch
+	skipBatch bool
+
+	collations map[string]string // Unknown collation -> what replaces it; "" drops the COLLATE clause
+	skipped    int64
+	byClass    map[ImportErrorClass]int64
+	warnings   []string
+}
+
+func newImportErrorHandler(conn *Connection, policy ImportErrorPolicy, skipBatch bool) *importErrorHandler {
+	if skipBatch && policy != nil {
+		// --continue skips whatever the policy doesn't name
+		if _, ok := policy[ImportErrorOther]; !ok {
+			withOther := ImportErrorPolicy{ImportErrorOther: ImportErrorSkip}
+			for class, action := range policy {
+				withOther[class] = action
+			}
+			policy = withOther
+		}
+	}
+	return &importErrorHandler{
+		conn:       conn,
+		policy:     policy,
+		skipBatch:  skipBatch,
+		collations: make(map[string]string),
+		byClass:    make(map[ImportErrorClass]int64),
+	}
+}
+
+// handle deals with a batch whose transaction failed and was rolled back.
+// With a policy the statements run again one at a time, so only those that
+// fail are skipped or rewritten. It returns the statements that ran and the
+// error that aborts the import.
+func (h *importErrorHandler) handle(batch []string, err error) (int64, error) {
+	if h.policy == nil {
+		if !h.skipBatch {
+			return 0, err
+		}
+		h.skipped++
+		h.warn("%v", err)
+		return 0, nil
+	}
+
+	var executed int64
+	for _, stmt := range batch {
+		_, err := h.conn.DB.Exec(stmt)
+		if err == nil {
+			executed++
+			continue
+		}
+
+		class := ClassifyImportError(err)
+		switch h.policy.action(class) {
+		case ImportErrorSkip:
+			h.skipped++
+			h.byClass[class]++
+			h.warn("Skipped (%s): %v - %s", class, err, truncateSQL(stmt))
+
+		case ImportErrorRewrite:
+			rewritten, what, ok := h.rewriteCollation(stmt, err)
+			if !ok {
+				return executed, fmt.Errorf("failed to execute statement: %w\nSQL: %s", err, truncateSQL(stmt))
+			}
+			if _, err := h.conn.DB.Exec(rewritten); err != nil {
+				return executed, fmt.Errorf("failed to execute statement with %s: %w\nSQL: %s", what, err, truncateSQL(rewritten))
+			}
+			executed++
+			h.byClass[class]++
+			h.warn("Rewrote (%s): %s - %s", class, what, truncateSQL(stmt))
+
+		default:
+			return executed, fmt.Errorf("failed to execute statement (%s): %w\nSQL: %s", class, err, truncateSQL(stmt))
+		}
+	}
+	return executed, nil
+}
+
+// rewriteCollation swaps the collation the server rejected for the default
+// of its character set on MariaDB, or drops the COLLATE clause on
+// PostgreSQL so the column default applies
+func (h *importErrorHandler) rewriteCollation(stmt string, err error) (string, string, bool) {
+	m := unknownCollationRe.FindStringSubmatch(err.Error())
+	if m == nil {
+		return "", "", false
+	}
+	name := m[1]
+
+	replacement, known := h.collations[name]
+	if !known {
+		if !isPostgresType(h.conn.Config.Type) {
+			err := h.conn.DB.QueryRow(
+				"SELECT DEFAULT_COLLATE_NAME FROM information_schema.CHARACTER_SETS WHERE CHARACTER_SET_NAME = ?",
+				charsetBase(name)).Scan(&replacement)
+			if err != nil {
+				return "", "", false
+			}
+		}
+		h.collations[name] = replacement
+	}
+
+	quoted := "[`'\"]?\\b" + regexp.QuoteMeta(name) + "\\b[`'\"]?"
+	if replacement == "" {
+		re := regexp.MustCompile(`(?i)\s*COLLATE\s+(?:[a-z_]+\.)?` + quoted)
+		rewritten := re.ReplaceAllString(stmt, "")
+		return rewritten, "COLLATE " + name + " dropped", rewritten != stmt
+	}
+	re := regexp.MustCompile(`(?i)` + quoted)
+	rewritten := re.ReplaceAllString(stmt, replacement)
+	return rewritten, name + " replaced with " + replacement, rewritten != stmt
+}
+
+func (h *importErrorHandler) warn(format string, args ...interface{}) {
+	h.warnings = append(h.warnings, fmt.Sprintf(format, args...))
+}
+
+// flush moves the counts and warnings into the stats
+func (h *importErrorHandler) flush(stats *ImportStats, tally *importTally) {
+	stats.ErrorsEncountered += h.skipped
+	for _, w := range h.warnings {
+		tally.warn("%s", w)
+	}
+	h.warnings = nil
+	h.skipped = 0
+	if len(h.byClass) == 0 {
+		return
+	}
+	if stats.ErrorsByClass == nil {
+		stats.ErrorsByClass = make(map[ImportErrorClass]int64)
+	}
+	for class, n := range h.byClass {
+		stats.ErrorsByClass[class] += n
+	}
+	clear(h.byClass)
+}
@@ -0,0 +1,278 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Import report formats
+const (
+	ImportReportText = "txt"
+	ImportReportJSON = "json"
+)
+
+// maxImportWarnings caps the warnings an import keeps; the count goes on
+const maxImportWarnings = 100
+
+// ImportStatementCounts counts the statements an import read, by type
+type ImportStatementCounts struct {
+	Create int64 `json:"create"`
+	Insert int64 `json:"insert"` // INSERT and REPLACE
+	Alter  int64 `json:"alter"`
+	Other  int64 `json:"other"`
+}
+
+// Total returns the number of statements of every type
+func (s ImportStatementCounts) Total() int64 {
+	return s.Create + s.Insert + s.Alter + s.Other
+}
+
+// ImportPhase is how long one step of an import took
+type ImportPhase struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// ImportReport is the summary of a finished import, for display and export
+type ImportReport struct {
+	File     string       `json:"file"`
+	Database string       `json:"database"`
+	Time     time.Time    `json:"time"`
+	Stats    *ImportStats `json:"stats"`
+}
+
+// NewImportReport summarizes a finished import
+func NewImportReport(file, database string, stats *ImportStats) *ImportReport {
+	return &ImportReport{File: file, Database: database, Time: time.Now(), Stats: stats}
+}
+
+// WriteJSON writes the report as JSON
+func (r *ImportReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the report as plain text
+func (r *ImportReport) WriteText(w io.Writer) error {
+	s := r.Stats
+	fmt.Fprintf(w, "Import of %s into %s\n", r.File, r.Database)
+	fmt.Fprintf(w, "Finished: %s\n\n", r.Time.Format(time.RFC3339))
+
+	if s.NativeTool {
+		fmt.Fprintf(w, "Loaded with the native client tools; statement details aren't available.\n\n")
+	} else {
+		fmt.Fprintf(w, "Statements by type:\n")
+		fmt.Fprintf(w, "  CREATE  %d\n", s.Statements.Create)
+		fmt.Fprintf(w, "  INSERT  %d\n", s.Statements.Insert)
+		fmt.Fprintf(w, "  ALTER   %d\n", s.Statements.Alter)
+		fmt.Fprintf(w, "  Other   %d\n", s.Statements.Other)
+		fmt.Fprintf(w, "  Total   %d (%d executed)\n\n", s.Statements.Total(), s.StatementsExecuted)
+
+		fmt.Fprintf(w, "Rows inserted (estimated): %d\n", s.RowsInserted)
+		fmt.Fprintf(w, "Tables created: %d\n", len(s.TablesCreated))
+		for _, t := range s.TablesCreated {
+			fmt.Fprintf(w, "  %s\n", t)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "Read: %s", FormatSize(s.BytesRead))
+	if s.Compressed {
+		fmt.Fprintf(w, " (%s compressed)", s.CompressionType)
+	}
+	fmt.Fprintln(w)
+	if s.TablesAnalyzed > 0 {
+		fmt.Fprintf(w, "Tables analyzed: %d\n", s.TablesAnalyzed)
+	}
+
+	fmt.Fprintf(w, "\nElapsed: %s\n", s.Duration.Round(time.Millisecond))
+	for _, p := range s.Phases {
+		fmt.Fprintf(w, "  %-8s %s\n", p.Name, p.Duration.Round(time.Millisecond))
+	}
+
+	if s.Manifest != nil {
+		bad := s.Manifest.Mismatches()
+		fmt.Fprintf(w, "\nManifest: %d of %d tables match\n", len(s.Manifest.Tables)-len(bad), len(s.Manifest.Tables))
+		for _, t := range bad {
+			fmt.Fprintf(w, "  %s\n", t)
+		}
+	}
+
+	if len(s.ErrorsByClass) > 0 {
+		fmt.Fprintf(w, "\nSkipped or rewritten by class:\n")
+		for _, class := range ImportErrorClasses {
+			if n := s.ErrorsByClass[class]; n > 0 {
+				fmt.Fprintf(w, "  %-18s %d\n", class, n)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\nWarnings: %d\n", s.WarningCount)
+	for _, warning := range s.Warnings {
+		fmt.Fprintf(w, "  %s\n", warning)
+	}
+	if extra := s.WarningCount - int64(len(s.Warnings)); extra > 0 {
+		fmt.Fprintf(w, "  ... and %d more\n", extra)
+	}
+	return nil
+}
+
+// Export writes the report to a file in the given format
+func (r *ImportReport) Export(path, format string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+
+	switch format {
+	case ImportReportJSON:
+		err = r.WriteJSON(f)
+	case ImportReportText:
+		err = r.WriteText(f)
+	default:
+		err = fmt.Errorf("unknown report format %q (use %s or %s)", format, ImportReportText, ImportReportJSON)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// createdTableRe finds the table a CREATE TABLE statement creates
+var createdTableRe = regexp.MustCompile(`(?is)^CREATE\s+(?:OR\s+REPLACE\s+)?(?:(?:GLOBAL\s+|LOCAL\s+)?(?:TEMPORARY|TEMP|UNLOGGED)\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([^\s(,;]+)`)
+
+// valuesRe finds the start of an INSERT's row tuples
+var valuesRe = regexp.MustCompile(`(?i)\bVALUES?\s*\(`)
+
+// importTally counts an import's statements as they're read
+type importTally struct {
+	stats *ImportStats
+	seen  map[string]bool
+}
+
+func newImportTally(stats *ImportStats) *importTally {
+	return &importTally{stats: stats, seen: make(map[string]bool)}
+}
+
+// note counts a statement and, for INSERTs, the rows it carries
+func (t *importTally) note(stmt string) {
+	// Large objects load through the dump's mapping table, not as rows
+	if largeObjectStatement(stmt) {
+		if strings.HasPrefix(stmt, "INSERT") {
+			t.stats.LargeObjects++
+		}
+		return
+	}
+
+	// Only the start matters for the type; INSERTs can be megabytes long
+	head := stmt[:min(len(stmt), 512)]
+	keyword := strings.ToUpper(firstKeyword(head))
+
+	switch keyword {
+	case "CREATE":
+		t.stats.Statements.Create++
+		if m := createdTableRe.FindStringSubmatch(head); m != nil {
+			parts := strings.Split(m[1], ".")
+			for i, part := range parts {
+				parts[i] = strings.Trim(part, "`\"")
+			}
+			name := strings.Join(parts, ".")
+			if !t.seen[name] {
+				t.seen[name] = true
+				t.stats.TablesCreated = append(t.stats.TablesCreated, name)
+			}
+		}
+	case "INSERT", "REPLACE":
+		t.stats.Statements.Insert++
+		t.stats.RowsInserted += countValueTuples(stmt)
+	case "ALTER":
+		t.stats.Statements.Alter++
+	default:
+		t.stats.Statements.Other++
+	}
+}
+
+// warn records a warning, keeping the first maxImportWarnings
+func (t *importTally) warn(format string, args ...interface{}) {
+	t.stats.WarningCount++
+	if len(t.stats.Warnings) < maxImportWarnings {
+		t.stats.Warnings = append(t.stats.Warnings, fmt.Sprintf(format, args...))
+	}
+}
+
+// firstKeyword returns the first word of a statement
+func firstKeyword(stmt string) string {
+	stmt = strings.TrimLeft(stmt, " \t\r\n(")
+	end := strings.IndexFunc(stmt, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	})
+	if end < 0 {
+		return stmt
+	}
+	return stmt[:end]
+}
+
+// countValueTuples counts the row tuples after VALUES in an INSERT. An
+// INSERT ... SELECT counts as no rows, since they aren't known up front.
+func countValueTuples(stmt string) int64 {
+	loc := valuesRe.FindStringIndex(stmt[:min(len(stmt), 4096)])
+	if loc == nil {
+		return 0
+	}
+	start := loc[1] - 1 // The first tuple's opening parenthesis
+
+	var tuples int64
+	depth := 0
+	var quote byte
+	for i := start; i < len(stmt); i++ {
+		ch := stmt[i]
+		if quote != 0 {
+			switch ch {
+			case '\\':
+				i++ // Skip the escaped character
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '\'', '"', '`':
+			quote = ch
+		case '(':
+			if depth == 0 {
+				tuples++
+			}
+			depth++
+		case ')':
+			depth--
+		}
+	}
+	return tuples
+}
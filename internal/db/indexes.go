@@ -0,0 +1,149 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Index describes an index on a table
+type Index struct {
+	Table   string
+	Name    string
+	Columns []string // Key columns (or expressions) in index order
+	Unique  bool
+	Primary bool
+	Type    string // BTREE, HASH, FULLTEXT, gin, ...
+	Size    int64  // Bytes, 0 when unknown
+}
+
+// ListIndexes lists the indexes of a table in the current database.
+// An empty table lists the indexes of every table.
+func (c *Connection) ListIndexes(table string) ([]Index, error) {
+	rows, err := c.DB.Query(c.Driver.ListIndexesQuery(table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
+	}
+	defer rows.Close()
+
+	var indexes []Index
+	byName := make(map[string]int)
+	for rows.Next() {
+		var tableName, name, indexType string
+		var column sql.NullString
+		var unique, primary bool
+		if err := rows.Scan(&tableName, &name, &column, &unique, &primary, &indexType); err != nil {
+			return nil, fmt.Errorf("failed to scan index: %w", err)
+		}
+
+		// One row per indexed column
+		key := tableName + "." + name
+		i, ok := byName[key]
+		if !ok {
+			i = len(indexes)
+			byName[key] = i
+			indexes = append(indexes, Index{Table: tableName, Name: name, Unique: unique, Primary: primary, Type: indexType})
+		}
+		if column.Valid {
+			indexes[i].Columns = append(indexes[i].Columns, column.String)
+		} else {
+			indexes[i].Columns = append(indexes[i].Columns, "(expression)")
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Sizes need extra privileges on MariaDB (mysql.innodb_index_stats), so
+	// they're best effort
+	if sizes, err := c.DB.Query(c.Driver.IndexSizesQuery()); err == nil {
+		defer sizes.Close()
+		for sizes.Next() {
+			var tableName, name string
+			var size sql.NullInt64
+			if err := sizes.Scan(&tableName, &name, &size); err != nil {
+				continue
+			}
+			if i, ok := byName[tableName+"."+name]; ok {
+				indexes[i].Size = size.Int64
+			}
+		}
+	}
+
+	return indexes, nil
+}
+
+// CreateIndex creates an index on the given columns. An empty name
+// generates one from the table and columns.
+func (c *Connection) CreateIndex(table, name string, columns []string, unique bool) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("an index needs at least one column")
+	}
+	if name == "" {
+		name = DefaultIndexName(table, columns, unique)
+	}
+
+	if _, err := c.DB.Exec(c.createIndexQuery(table, name, columns, unique)); err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+	return nil
+}
+
+// createIndexQuery builds a CREATE INDEX statement; both servers share the syntax
+func (c *Connection) createIndexQuery(table, name string, columns []string, unique bool) string {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = c.QuoteIdentifier(col)
+	}
+	kind := "INDEX"
+	if unique {
+		kind = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf("CREATE %s %s ON %s (%s)", kind, c.QuoteIdentifier(name), c.QuoteIdentifier(table), strings.Join(quoted, ", "))
+}
+
+// DropIndex drops an index. Primary keys are left to the table designer.
+func (c *Connection) DropIndex(table, name string) error {
+	if c.Config.Type != DatabaseTypePostgres && name == "PRIMARY" {
+		return fmt.Errorf("the primary key can't be dropped as an index, change it in the table designer")
+	}
+	if _, err := c.DB.Exec(c.Driver.DropIndexQuery(table, name)); err != nil {
+		return fmt.Errorf("failed to drop index: %w", err)
+	}
+	return nil
+}
+
+var indexNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// DefaultIndexName builds an index name like idx_orders_customer_id,
+// shortened to the 63 characters both servers accept
+func DefaultIndexName(table string, columns []string, unique bool) string {
+	prefix := "idx"
+	if unique {
+		prefix = "ux"
+	}
+	name := indexNameUnsafe.ReplaceAllString(prefix+"_"+table+"_"+strings.Join(columns, "_"), "_")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.ToLower(name)
+}
@@ -0,0 +1,230 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PostgreSQL large objects live outside the rows that reference them by
+// OID, and a restore can't count on getting the same OIDs back. Built-in
+// dumps load each one into a new large object first, record the new OID in
+// a mapping table, and have the rows' INSERTs look their OIDs up in it.
+
+const (
+	largeObjectMapTable = "_ysm_lo_map"
+	largeObjectChunk    = 1 << 20 // Bytes per statement; hex doubles it
+)
+
+// largeObjects are the large objects referenced by the tables of a dump
+type largeObjects struct {
+	columns map[string][]string // oid and lo columns, by table
+	oids    []int64             // Existing large objects referenced, ascending
+	bytes   int64               // Written so far
+}
+
+// findLargeObjects looks for oid and lo columns among tables and the large
+// objects they reference. It returns nil when there are none.
+func (c *Connection) findLargeObjects(tables []string) (*largeObjects, error) {
+	query := c.Driver.LargeObjectColumnsQuery()
+	if query == "" {
+		return nil, nil
+	}
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look for large objects: %w", err)
+	}
+	defer rows.Close()
+
+	lo := &largeObjects{columns: make(map[string][]string)}
+	var sources []string
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, fmt.Errorf("failed to look for large objects: %w", err)
+		}
+		if slices.Contains(tables, table) {
+			lo.columns[table] = append(lo.columns[table], column)
+			sources = append(sources, fmt.Sprintf("SELECT %s FROM %s", c.QuoteIdentifier(column), c.QuoteIdentifier(table)))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to look for large objects: %w", err)
+	}
+	if len(sources) == 0 {
+		return nil, nil
+	}
+
+	oids, err := c.DB.Query(c.Driver.LargeObjectsQuery(sources))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list large objects: %w", err)
+	}
+	defer oids.Close()
+	for oids.Next() {
+		var oid int64
+		if err := oids.Scan(&oid); err != nil {
+			return nil, fmt.Errorf("failed to list large objects: %w", err)
+		}
+		lo.oids = append(lo.oids, oid)
+	}
+	if err := oids.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list large objects: %w", err)
+	}
+	if len(lo.oids) == 0 {
+		return nil, nil
+	}
+	return lo, nil
+}
+
+// count returns how many large objects are dumped
+func (lo *largeObjects) count() int {
+	if lo == nil {
+		return 0
+	}
+	return len(lo.oids)
+}
+
+// remapped reports which of a table's columns hold large object OIDs, or
+// nil when none do
+func (lo *largeObjects) remapped(table string, columns []string) []bool {
+	if lo == nil || len(lo.columns[table]) == 0 {
+		return nil
+	}
+	remap := make([]bool, len(columns))
+	for i, col := range columns {
+		remap[i] = slices.Contains(lo.columns[table], col)
+	}
+	return remap
+}
+
+// lookup returns the expression a row's value becomes: the large object's
+// new OID from the mapping table. Dangling OIDs and NULLs are kept as is.
+func (lo *largeObjects) lookup(val interface{}) (string, bool) {
+	var oid int64
+	var err error
+	switch v := val.(type) {
+	case []byte:
+		oid, err = strconv.ParseInt(string(v), 10, 64)
+	case string:
+		oid, err = strconv.ParseInt(v, 10, 64)
+	case int64:
+		oid = v
+	default:
+		return "", false
+	}
+	if err != nil {
+		return "", false
+	}
+	if _, ok := slices.BinarySearch(lo.oids, oid); !ok {
+		return "", false
+	}
+	return fmt.Sprintf(`(SELECT new_oid FROM "%s" WHERE old_oid = %d)`, largeObjectMapTable, oid), true
+}
+
+// writeLargeObjects dumps the large objects ahead of the tables, each into a new large
+// object whose OID goes into the mapping table
+func (c *Connection) writeLargeObjects(ctx context.Context, w *bufio.Writer, lo *largeObjects) error {
+	fmt.Fprintf(w, "-- --------------------------------------------------------\n")
+	fmt.Fprintf(w, "-- Large objects (%d), restored under new OIDs\n", len(lo.oids))
+	fmt.Fprintf(w, "-- --------------------------------------------------------\n\n")
+	fmt.Fprintf(w, "DROP TABLE IF EXISTS \"%s\";\n", largeObjectMapTable)
+	fmt.Fprintf(w, "CREATE TABLE \"%s\" (old_oid oid PRIMARY KEY, new_oid oid NOT NULL);\n\n", largeObjectMapTable)
+
+	for _, oid := range lo.oids {
+		for offset := int64(0); ; offset += largeObjectChunk {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			var chunk []byte
+			if err := c.DB.QueryRowContext(ctx, c.Driver.LargeObjectReadQuery(), oid, offset, largeObjectChunk).Scan(&chunk); err != nil {
+				return fmt.Errorf("failed to read large object %d: %w", oid, err)
+			}
+			lo.bytes += int64(len(chunk))
+
+			if offset == 0 {
+				fmt.Fprintf(w, "INSERT INTO \"%s\" VALUES (%d, lo_from_bytea(0, '\\x%x'));\n", largeObjectMapTable, oid, chunk)
+			} else if len(chunk) > 0 {
+				fmt.Fprintf(w, "SELECT lo_put(new_oid, %d, '\\x%x') FROM \"%s\" WHERE old_oid = %d;\n", offset, chunk, largeObjectMapTable, oid)
+			}
+			if len(chunk) < largeObjectChunk {
+				break
+			}
+		}
+	}
+	fmt.Fprintf(w, "\n")
+	return nil
+}
+
+// writeLargeObjectsEnd drops the mapping table once the rows are in
+func writeLargeObjectsEnd(w *bufio.Writer) {
+	fmt.Fprintf(w, "\nDROP TABLE IF EXISTS \"%s\";\n", largeObjectMapTable)
+}
+
+// largeObjectStatement reports whether a dump statement loads large
+// objects rather than rows
+func largeObjectStatement(stmt string) bool {
+	table := `"` + largeObjectMapTable + `"`
+	return strings.HasPrefix(stmt, "DROP TABLE IF EXISTS "+table) ||
+		strings.HasPrefix(stmt, "CREATE TABLE "+table) ||
+		strings.HasPrefix(stmt, "INSERT INTO "+table) ||
+		strings.HasPrefix(stmt, "SELECT lo_put(")
+}
+
+// largeObjectLoader runs a dump's large object statements in file order on
+// the importing connection, so that parallel batches of rows find their new
+// OIDs, and holds back dropping the mapping table until the rows are in
+type largeObjectLoader struct {
+	conn    *Connection
+	loaded  int64
+	cleanup string
+}
+
+// take runs stmt if it loads large objects, and reports whether it did
+func (l *largeObjectLoader) take(ctx context.Context, stmt string) (bool, error) {
+	if !largeObjectStatement(stmt) {
+		return false, nil
+	}
+	if strings.HasPrefix(stmt, "DROP") && l.loaded > 0 {
+		l.cleanup = stmt
+		return true, nil
+	}
+	if strings.HasPrefix(stmt, "INSERT") {
+		l.loaded++
+	}
+	if _, err := l.conn.DB.ExecContext(ctx, stmt); err != nil {
+		return true, fmt.Errorf("failed to load large objects: %w", err)
+	}
+	return true, nil
+}
+
+// finish drops the mapping table after the rows were loaded
+func (l *largeObjectLoader) finish() error {
+	if l.cleanup == "" {
+		return nil
+	}
+	if _, err := l.conn.DB.Exec(l.cleanup); err != nil {
+		return fmt.Errorf("failed to drop %s: %w", largeObjectMapTable, err)
+	}
+	return nil
+}
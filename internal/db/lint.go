@@ -0,0 +1,470 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Lint rules, named so they can be switched off in the config
+const (
+	LintNoWhere    = "no-where"    // UPDATE or DELETE without a WHERE clause
+	LintLargeDDL   = "large-ddl"   // DDL on a large table during business hours
+	LintCrossJoin  = "cross-join"  // Tables joined without a join condition
+	LintSelectStar = "select-star" // SELECT * without LIMIT on a huge table
+)
+
+// LintRules lists every lint rule
+var LintRules = []string{LintNoWhere, LintLargeDDL, LintCrossJoin, LintSelectStar}
+
+// Lint defaults
+const (
+	DefaultLargeTableRows = 1000000
+	DefaultHugeTableRows  = 10000000
+	DefaultBusinessStart  = 9 * time.Hour
+	DefaultBusinessEnd    = 18 * time.Hour
+)
+
+// LintPolicy decides which statements the query editor's safe mode warns
+// about before running them
+type LintPolicy struct {
+	Disabled       bool
+	Ignore         []string       // Rules not checked
+	BusinessStart  time.Duration  // Since midnight, local time
+	BusinessEnd    time.Duration  // Since midnight; before the start means overnight
+	BusinessDays   []time.Weekday // Days with business hours
+	LargeTableRows int64          // DDL on tables this big warns in business hours
+	HugeTableRows  int64          // SELECT * on tables this big warns
+}
+
+// DefaultLintPolicy returns the policy used without a safe_mode config:
+// business hours are 09:00-18:00, Monday to Friday
+func DefaultLintPolicy() LintPolicy {
+	return LintPolicy{
+		BusinessStart:  DefaultBusinessStart,
+		BusinessEnd:    DefaultBusinessEnd,
+		BusinessDays:   []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		LargeTableRows: DefaultLargeTableRows,
+		HugeTableRows:  DefaultHugeTableRows,
+	}
+}
+
+// InBusinessHours reports whether t falls in the policy's business hours
+func (p LintPolicy) InBusinessHours(t time.Time) bool {
+	day := t.Weekday()
+	since := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if p.BusinessEnd <= p.BusinessStart && since < p.BusinessEnd {
+		// Early morning of hours that started the day before
+		day = (day + 6) % 7
+		since += 24 * time.Hour
+	}
+	if !slices.Contains(p.BusinessDays, day) {
+		return false
+	}
+	end := p.BusinessEnd
+	if end <= p.BusinessStart {
+		end += 24 * time.Hour
+	}
+	return since >= p.BusinessStart && since < end
+}
+
+// checks reports whether rule is checked
+func (p LintPolicy) checks(rule string) bool {
+	return !p.Disabled && !slices.Contains(p.Ignore, rule)
+}
+
+// LintWarning is a likely mistake found in a statement
+type LintWarning struct {
+	Rule    string
+	Message string
+}
+
+// LintSQL looks for the classic mistakes in sql before it runs: UPDATE or
+// DELETE without WHERE, DDL on large tables during business hours, joins
+// without a condition and SELECT * on huge tables. Table sizes come from
+// the connected database's statistics and are only looked up when a rule
+// needs them; unknown tables don't warn.
+func (c *Connection) LintSQL(sql string, policy LintPolicy, now time.Time) []LintWarning {
+	if policy.Disabled {
+		return nil
+	}
+
+	var rows map[string]int64
+	tableRows := func(name string) int64 {
+		if rows == nil {
+			rows = make(map[string]int64)
+			if tables, err := c.ListTables(); err == nil {
+				for _, t := range tables {
+					rows[strings.ToLower(t.Name)] = t.Rows
+				}
+			}
+		}
+		return rows[strings.ToLower(name)]
+	}
+
+	var warnings []LintWarning
+	for _, stmt := range splitLintStatements(lexSQL(sql, c.Config.Type)) {
+		warnings = append(warnings, lintStatement(stmt, policy, now, tableRows)...)
+	}
+	return warnings
+}
+
+// lintStatement checks one statement's tokens
+func lintStatement(stmt []sqlToken, policy LintPolicy, now time.Time, tableRows func(string) int64) []LintWarning {
+	var warnings []LintWarning
+	first := stmt[0].upper()
+
+	if (first == "UPDATE" || first == "DELETE") && policy.checks(LintNoWhere) && !hasKeyword(stmt, "WHERE") {
+		warnings = append(warnings, LintWarning{
+			Rule:    LintNoWhere,
+			Message: fmt.Sprintf("%s without WHERE changes every row of the table", first),
+		})
+	}
+
+	if policy.checks(LintLargeDDL) && policy.InBusinessHours(now) {
+		if verb, table := ddlTarget(stmt); table != "" {
+			if n := tableRows(table); n >= policy.LargeTableRows {
+				warnings = append(warnings, LintWarning{
+					Rule:    LintLargeDDL,
+					Message: fmt.Sprintf("%s on %s (~%d rows) during business hours can lock it for a long time", verb, table, n),
+				})
+			}
+		}
+	}
+
+	if policy.checks(LintCrossJoin) {
+		if joined := crossJoined(stmt); joined != "" {
+			warnings = append(warnings, LintWarning{
+				Rule:    LintCrossJoin,
+				Message: fmt.Sprintf("%s is joined without a condition, giving every combination of rows", joined),
+			})
+		}
+	}
+
+	if first == "SELECT" && policy.checks(LintSelectStar) && selectsStar(stmt) && !hasKeyword(stmt, "LIMIT") && !hasKeyword(stmt, "FETCH") {
+		for _, table := range fromTables(stmt) {
+			if n := tableRows(table); n >= policy.HugeTableRows {
+				warnings = append(warnings, LintWarning{
+					Rule:    LintSelectStar,
+					Message: fmt.Sprintf("SELECT * without LIMIT reads all of %s (~%d rows)", table, n),
+				})
+			}
+		}
+	}
+	return warnings
+}
+
+// hasKeyword reports whether the statement's top level has the keyword
+func hasKeyword(stmt []sqlToken, keyword string) bool {
+	for _, t := range stmt {
+		if t.depth == 0 && t.upper() == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// ddlTarget returns the DDL statement and the table it changes, or an
+// empty table when stmt isn't DDL on a table
+func ddlTarget(stmt []sqlToken) (verb, table string) {
+	words := make([]string, 0, 6)
+	for _, t := range stmt[:min(len(stmt), 6)] {
+		words = append(words, t.upper())
+	}
+	at := func(i int) string {
+		if i < len(words) {
+			return words[i]
+		}
+		return ""
+	}
+	// Position of the table name, past IF [NOT] EXISTS and ONLY
+	name := func(i int) string {
+		for i < len(stmt) && slices.Contains([]string{"IF", "NOT", "EXISTS", "ONLY"}, stmt[i].upper()) {
+			i++
+		}
+		return tableName(stmt, i)
+	}
+
+	switch {
+	case at(0) == "ALTER" && at(1) == "TABLE":
+		return "ALTER TABLE", name(2)
+	case at(0) == "DROP" && at(1) == "TABLE":
+		return "DROP TABLE", name(2)
+	case at(0) == "TRUNCATE":
+		if at(1) == "TABLE" {
+			return "TRUNCATE", name(2)
+		}
+		return "TRUNCATE", name(1)
+	case at(0) == "OPTIMIZE" && at(1) == "TABLE":
+		return "OPTIMIZE TABLE", name(2)
+	case (at(0) == "CREATE" || at(0) == "DROP") && (at(1) == "INDEX" || at(2) == "INDEX"):
+		// CREATE [UNIQUE] INDEX name ON table; MariaDB's DROP INDEX name ON table
+		for i, t := range stmt {
+			if t.depth == 0 && t.upper() == "ON" {
+				return at(0) + " INDEX", name(i + 1)
+			}
+		}
+	}
+	return "", ""
+}
+
+// clauseKeywords end a FROM clause
+var clauseKeywords = []string{"WHERE", "GROUP", "ORDER", "HAVING", "LIMIT", "UNION", "SET", "RETURNING", "WINDOW"}
+
+// crossJoined returns the first table joined without a condition: listed
+// after a comma in a FROM without WHERE, or a JOIN without ON or USING.
+// CROSS and NATURAL joins are meant and not reported.
+func crossJoined(stmt []sqlToken) string {
+	inFrom := false
+	for i, t := range stmt {
+		if t.depth != 0 || t.quoted {
+			continue
+		}
+		switch word := t.upper(); {
+		case word == "FROM":
+			inFrom = true
+		case slices.Contains(clauseKeywords, word):
+			inFrom = false
+		case inFrom && t.text == "," && !hasKeyword(stmt, "WHERE"):
+			if name := tableName(stmt, i+1); name != "" {
+				return name
+			}
+		case word == "JOIN":
+			if i > 0 && stmt[i-1].upper() == "CROSS" || naturalJoin(stmt, i) || joinCondition(stmt, i+1) {
+				continue
+			}
+			if name := tableName(stmt, i+1); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// naturalJoin reports whether the JOIN at i is a NATURAL [LEFT|RIGHT|...] JOIN
+func naturalJoin(stmt []sqlToken, i int) bool {
+	for j := i - 1; j >= 0 && j >= i-3; j-- {
+		switch stmt[j].upper() {
+		case "NATURAL":
+			return true
+		case "LEFT", "RIGHT", "FULL", "INNER", "OUTER":
+			continue
+		}
+		return false
+	}
+	return false
+}
+
+// joinCondition reports whether the join starting at i has ON or USING
+// before the next join or clause
+func joinCondition(stmt []sqlToken, i int) bool {
+	depth := stmt[i-1].depth
+	for ; i < len(stmt); i++ {
+		t := stmt[i]
+		if t.depth != depth || t.quoted {
+			continue
+		}
+		switch t.upper() {
+		case "ON", "USING":
+			return true
+		case "JOIN", ",", "WHERE", "GROUP", "ORDER", "HAVING", "LIMIT", "UNION":
+			return false
+		}
+	}
+	return false
+}
+
+// selectsStar reports whether the top-level select list has * or table.*,
+// as opposed to a multiplication
+func selectsStar(stmt []sqlToken) bool {
+	for i := 1; i < len(stmt); i++ {
+		t := stmt[i]
+		if t.depth != 0 || t.quoted {
+			continue
+		}
+		if t.text == "*" {
+			switch stmt[i-1].upper() {
+			case "SELECT", "DISTINCT", "ALL", ",", ".":
+				return true
+			}
+		}
+		if t.upper() == "FROM" {
+			return false
+		}
+	}
+	return false
+}
+
+// fromTables returns the tables named in the top-level FROM clause
+func fromTables(stmt []sqlToken) []string {
+	var tables []string
+	inFrom := false
+	for i, t := range stmt {
+		if t.depth != 0 || t.quoted {
+			continue
+		}
+		switch word := t.upper(); {
+		case word == "FROM", word == "JOIN", inFrom && word == ",":
+			inFrom = true
+			if name := tableName(stmt, i+1); name != "" {
+				tables = append(tables, name)
+			}
+		case slices.Contains(clauseKeywords, word):
+			inFrom = false
+		}
+	}
+	return tables
+}
+
+// tableName returns the table named at i, without its database or schema,
+// or "" when i isn't a name
+func tableName(stmt []sqlToken, i int) string {
+	if i >= len(stmt) || !stmt[i].word {
+		return ""
+	}
+	name := stmt[i].text
+	for i+2 < len(stmt) && stmt[i+1].text == "." && !stmt[i+1].quoted && stmt[i+2].word {
+		i += 2
+		name = stmt[i].text
+	}
+	return name
+}
+
+// sqlToken is a word, quoted identifier or punctuation of a statement,
+// with its parenthesis depth. String literals and comments are dropped.
+type sqlToken struct {
+	text   string
+	word   bool // Keyword or identifier
+	quoted bool // Quoted identifier
+	depth  int
+}
+
+func (t sqlToken) upper() string {
+	if t.quoted {
+		return ""
+	}
+	return strings.ToUpper(t.text)
+}
+
+// lexSQL splits sql into tokens, honoring the quoting and comment rules of
+// the server type
+func lexSQL(sql string, dbType DatabaseType) []sqlToken {
+	postgres := isPostgresType(dbType)
+	var tokens []sqlToken
+	depth := 0
+
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+		case ch == '-' && i+1 < len(sql) && sql[i+1] == '-', ch == '#' && !postgres:
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case ch == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 3
+		case ch == '\'' || ch == '"' || ch == '`':
+			start := i + 1
+			for i++; i < len(sql); i++ {
+				if sql[i] == '\\' && ch == '\'' && !postgres {
+					i++
+					continue
+				}
+				if sql[i] == ch {
+					if i+1 < len(sql) && sql[i+1] == ch {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			if ch != '\'' {
+				// MariaDB's "..." is a string unless ANSI_QUOTES is on, but
+				// reading it as a name only matters for table lookups
+				tokens = append(tokens, sqlToken{text: sql[start:min(i, len(sql))], word: true, quoted: true, depth: depth})
+			}
+		case ch == '$' && postgres && dollarTag(sql[i:]) != "":
+			tag := dollarTag(sql[i:])
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				return tokens
+			}
+			i += len(tag) + end + len(tag) - 1
+		case ch == '(':
+			depth++
+			tokens = append(tokens, sqlToken{text: "(", depth: depth})
+		case ch == ')':
+			tokens = append(tokens, sqlToken{text: ")", depth: depth})
+			depth = max(depth-1, 0)
+		case isWordByte(ch):
+			start := i
+			for i+1 < len(sql) && isWordByte(sql[i+1]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{text: sql[start : i+1], word: true, depth: depth})
+		default:
+			tokens = append(tokens, sqlToken{text: string(ch), depth: depth})
+		}
+	}
+	return tokens
+}
+
+// dollarTag returns the $tag$ opening a PostgreSQL dollar-quoted string at
+// the start of s, or ""
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '$' {
+			return s[:i+1]
+		}
+		if !isWordByte(s[i]) || (s[i] >= '0' && s[i] <= '9' && i == 1) {
+			return ""
+		}
+	}
+	return ""
+}
+
+func isWordByte(ch byte) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch >= 0x80
+}
+
+// splitLintStatements splits tokens on top-level semicolons, dropping
+// empty statements
+func splitLintStatements(tokens []sqlToken) [][]sqlToken {
+	var stmts [][]sqlToken
+	start := 0
+	for i, t := range tokens {
+		if t.text == ";" && !t.quoted && t.depth == 0 {
+			if i > start {
+				stmts = append(stmts, tokens[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		stmts = append(stmts, tokens[start:])
+	}
+	return stmts
+}
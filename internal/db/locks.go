@@ -0,0 +1,156 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Session is a server connection taking part in a lock wait, or running a query
+type Session struct {
+	ID       int64
+	User     string
+	Database string
+	State    string
+	Query    string
+	Duration time.Duration // How long it has been waiting, in its transaction for blockers, or running its query
+}
+
+// LockWait is a session waiting on a lock held by another
+type LockWait struct {
+	Blocked Session
+	Blocker Session
+	Lock    string // Lock mode and object, when the server reports them
+}
+
+// BlockingNode is a session in a blocking tree, with the sessions waiting on it
+type BlockingNode struct {
+	Session
+	Lock    string // The lock this session is waiting for; empty for roots
+	Waiting []*BlockingNode
+}
+
+// Count returns the number of sessions waiting on this node, directly or not
+func (n *BlockingNode) Count() int {
+	count := 0
+	for _, child := range n.Waiting {
+		count += 1 + child.Count()
+	}
+	return count
+}
+
+// LockWaits returns the current lock waits on the server
+func (c *Connection) LockWaits() ([]LockWait, error) {
+	var lastErr error
+	for _, query := range c.Driver.LockWaitsQueries() {
+		waits, err := c.queryLockWaits(query)
+		if err == nil {
+			return waits, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("failed to read lock waits: %w", lastErr)
+}
+
+func (c *Connection) queryLockWaits(query string) ([]LockWait, error) {
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var waits []LockWait
+	for rows.Next() {
+		var w LockWait
+		var blockedSecs, blockerSecs int64
+		if err := rows.Scan(
+			&w.Blocked.ID, &w.Blocked.User, &w.Blocked.Database, &w.Blocked.State, &w.Blocked.Query, &blockedSecs,
+			&w.Blocker.ID, &w.Blocker.User, &w.Blocker.Database, &w.Blocker.State, &w.Blocker.Query, &blockerSecs,
+			&w.Lock,
+		); err != nil {
+			return nil, err
+		}
+		w.Blocked.Duration = time.Duration(blockedSecs) * time.Second
+		w.Blocker.Duration = time.Duration(blockerSecs) * time.Second
+		waits = append(waits, w)
+	}
+	return waits, rows.Err()
+}
+
+// BlockingTrees arranges lock waits into trees rooted at the sessions that
+// block others without waiting themselves. Sessions in a wait cycle (a
+// deadlock the server hasn't resolved yet) are rooted at the lowest ID.
+func BlockingTrees(waits []LockWait) []*BlockingNode {
+	sessions := make(map[int64]Session)
+	waitingOn := make(map[int64][]LockWait) // Blocker ID -> waits on it
+	blocked := make(map[int64]bool)
+	for _, w := range waits {
+		if _, ok := sessions[w.Blocker.ID]; !ok {
+			sessions[w.Blocker.ID] = w.Blocker
+		}
+		sessions[w.Blocked.ID] = w.Blocked
+		waitingOn[w.Blocker.ID] = append(waitingOn[w.Blocker.ID], w)
+		blocked[w.Blocked.ID] = true
+	}
+
+	ids := make([]int64, 0, len(sessions))
+	for id := range sessions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	placed := make(map[int64]bool)
+	var build func(s Session, lock string, ancestors map[int64]bool) *BlockingNode
+	build = func(s Session, lock string, ancestors map[int64]bool) *BlockingNode {
+		node := &BlockingNode{Session: s, Lock: lock}
+		placed[s.ID] = true
+		ancestors[s.ID] = true
+		for _, w := range waitingOn[s.ID] {
+			if ancestors[w.Blocked.ID] {
+				continue
+			}
+			node.Waiting = append(node.Waiting, build(w.Blocked, w.Lock, ancestors))
+		}
+		delete(ancestors, s.ID)
+		return node
+	}
+
+	var roots []*BlockingNode
+	for _, id := range ids {
+		if !blocked[id] {
+			roots = append(roots, build(sessions[id], "", make(map[int64]bool)))
+		}
+	}
+	for _, id := range ids {
+		if !placed[id] {
+			roots = append(roots, build(sessions[id], "", make(map[int64]bool)))
+		}
+	}
+	return roots
+}
+
+// KillSession ends a server session, or only cancels its running query
+func (c *Connection) KillSession(id int64, queryOnly bool) error {
+	if _, err := c.DB.Exec(c.Driver.KillSessionQuery(id, queryOnly)); err != nil {
+		return fmt.Errorf("failed to kill session %d: %w", id, err)
+	}
+	return nil
+}
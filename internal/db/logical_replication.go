@@ -0,0 +1,473 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// publicationOperations are the changes a publication can send
+var publicationOperations = []string{"insert", "update", "delete", "truncate"}
+
+// Publication is a PostgreSQL publication in the current database
+type Publication struct {
+	Name       string
+	Owner      string
+	AllTables  bool
+	Operations []string // Published changes: insert, update, delete, truncate
+	Tables     []string // schema.table; empty when AllTables is set
+}
+
+// Subscription is a PostgreSQL subscription in the current database
+type Subscription struct {
+	Name          string
+	Owner         string
+	Enabled       bool
+	Publications  []string
+	SlotName      string
+	WorkerRunning bool     // The apply worker is connected to the publisher
+	ReceivedLSN   string   // Last WAL position received
+	LatestEndLSN  string   // Last WAL position reported back to the publisher
+	LagSeconds    *float64 // Time since the publisher last confirmed a position; nil if unknown
+}
+
+// LogicalSlot is a logical replication slot on a publisher, one per
+// subscription reading from it
+type LogicalSlot struct {
+	Name     string
+	Plugin   string
+	Database string
+	Active   bool
+	LagBytes *int64 // WAL the subscriber hasn't confirmed; nil on a standby
+}
+
+// LogicalReplicationStatus lists the logical replication set up on a server
+type LogicalReplicationStatus struct {
+	Publications  []Publication
+	Subscriptions []Subscription
+	Slots         []LogicalSlot
+}
+
+// Configured reports whether the server publishes or subscribes to anything
+func (s *LogicalReplicationStatus) Configured() bool {
+	return len(s.Publications) > 0 || len(s.Subscriptions) > 0 || len(s.Slots) > 0
+}
+
+// Problem describes the first unhealthy subscription or slot, or returns ""
+func (s *LogicalReplicationStatus) Problem() string {
+	for _, sub := range s.Subscriptions {
+		switch {
+		case !sub.Enabled:
+			return fmt.Sprintf("subscription %s is disabled", sub.Name)
+		case !sub.WorkerRunning:
+			return fmt.Sprintf("subscription %s has no running apply worker", sub.Name)
+		case sub.LagSeconds != nil && *sub.LagSeconds > 60:
+			return fmt.Sprintf("subscription %s is %.0fs behind", sub.Name, *sub.LagSeconds)
+		}
+	}
+	for _, slot := range s.Slots {
+		if !slot.Active {
+			return fmt.Sprintf("slot %s has no subscriber connected and is holding WAL", slot.Name)
+		}
+	}
+	return ""
+}
+
+// Role describes the server's part in logical replication
+func (s *LogicalReplicationStatus) Role() string {
+	publishes := len(s.Publications) > 0 || len(s.Slots) > 0
+	subscribes := len(s.Subscriptions) > 0
+	switch {
+	case publishes && subscribes:
+		return "publisher+subscriber"
+	case subscribes:
+		return "subscriber"
+	default:
+		return "publisher"
+	}
+}
+
+// Nodes lists the servers on the other end: a subscriber per slot and a
+// publisher per subscription
+func (s *LogicalReplicationStatus) Nodes() []ClusterNode {
+	var nodes []ClusterNode
+	for _, slot := range s.Slots {
+		node := ClusterNode{
+			Address:         slot.Name,
+			Role:            "subscriber",
+			State:           "inactive",
+			ReplicationSlot: slot.Name,
+		}
+		if slot.Active {
+			node.State = "streaming"
+		}
+		if slot.LagBytes != nil {
+			node.LagBytes = *slot.LagBytes
+		}
+		nodes = append(nodes, node)
+	}
+	for _, sub := range s.Subscriptions {
+		node := ClusterNode{
+			Address:         sub.Name,
+			Role:            "publisher",
+			State:           "stopped",
+			ReplicationSlot: sub.SlotName,
+			WriteLSN:        sub.ReceivedLSN,
+			FlushLSN:        sub.LatestEndLSN,
+		}
+		switch {
+		case !sub.Enabled:
+			node.State = "disabled"
+		case sub.WorkerRunning:
+			node.State = "streaming"
+		}
+		if sub.LagSeconds != nil {
+			node.LagSeconds = *sub.LagSeconds
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// CreatePublicationOptions configures CREATE PUBLICATION
+type CreatePublicationOptions struct {
+	Name       string
+	Tables     []string // Tables to publish, optionally schema-qualified; empty publishes all tables
+	Operations []string // Changes to publish; empty publishes all of them
+}
+
+// CreateSubscriptionOptions configures CREATE SUBSCRIPTION
+type CreateSubscriptionOptions struct {
+	Name         string
+	Host         string
+	Port         int // Default 5432
+	User         string
+	Password     string
+	Database     string // Database on the publisher
+	Publications []string
+	CopyData     bool // Copy the existing rows before streaming changes
+}
+
+// GetLogicalReplicationStatus lists publications, subscriptions and
+// logical slots on a PostgreSQL server
+func (c *Connection) GetLogicalReplicationStatus() (*LogicalReplicationStatus, error) {
+	if err := c.requirePostgresLogical(); err != nil {
+		return nil, err
+	}
+	status := &LogicalReplicationStatus{}
+	var err error
+	if status.Publications, err = c.ListPublications(); err != nil {
+		return nil, err
+	}
+	if status.Subscriptions, err = c.ListSubscriptions(); err != nil {
+		return nil, err
+	}
+	if status.Slots, err = c.ListLogicalSlots(); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// ListPublications lists the publications in the current database
+func (c *Connection) ListPublications() ([]Publication, error) {
+	if err := c.requirePostgresLogical(); err != nil {
+		return nil, err
+	}
+	rows, err := c.DB.Query(`
+		SELECT p.pubname, pg_get_userbyid(p.pubowner), p.puballtables,
+			p.pubinsert, p.pubupdate, p.pubdelete, p.pubtruncate
+		FROM pg_publication p
+		ORDER BY p.pubname`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list publications: %w", err)
+	}
+	defer rows.Close()
+
+	var pubs []Publication
+	index := make(map[string]int)
+	for rows.Next() {
+		var pub Publication
+		var ops [4]bool
+		if err := rows.Scan(&pub.Name, &pub.Owner, &pub.AllTables, &ops[0], &ops[1], &ops[2], &ops[3]); err != nil {
+			return nil, err
+		}
+		for i, enabled := range ops {
+			if enabled {
+				pub.Operations = append(pub.Operations, publicationOperations[i])
+			}
+		}
+		index[pub.Name] = len(pubs)
+		pubs = append(pubs, pub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tableRows, err := c.DB.Query(`
+		SELECT pt.pubname, pt.schemaname, pt.tablename
+		FROM pg_publication_tables pt
+		JOIN pg_publication p ON p.pubname = pt.pubname
+		WHERE NOT p.puballtables
+		ORDER BY pt.pubname, pt.schemaname, pt.tablename`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list published tables: %w", err)
+	}
+	defer tableRows.Close()
+
+	for tableRows.Next() {
+		var pubName, schema, table string
+		if err := tableRows.Scan(&pubName, &schema, &table); err != nil {
+			return nil, err
+		}
+		if i, ok := index[pubName]; ok {
+			pubs[i].Tables = append(pubs[i].Tables, schema+"."+table)
+		}
+	}
+	return pubs, tableRows.Err()
+}
+
+// ListSubscriptions lists the subscriptions in the current database with
+// their apply worker's progress
+func (c *Connection) ListSubscriptions() ([]Subscription, error) {
+	if err := c.requirePostgresLogical(); err != nil {
+		return nil, err
+	}
+	// subconninfo is left out: only superusers may read it
+	rows, err := c.DB.Query(`
+		SELECT s.subname, pg_get_userbyid(s.subowner), s.subenabled,
+			COALESCE(s.subslotname, ''), array_to_string(s.subpublications, ','),
+			st.pid IS NOT NULL,
+			COALESCE(st.received_lsn::text, ''), COALESCE(st.latest_end_lsn::text, ''),
+			EXTRACT(EPOCH FROM (now() - st.latest_end_time))::float8
+		FROM pg_subscription s
+		LEFT JOIN pg_stat_subscription st ON st.subid = s.oid AND st.relid IS NULL
+		WHERE s.subdbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+		ORDER BY s.subname`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []Subscription
+	for rows.Next() {
+		var sub Subscription
+		var publications string
+		var lag sql.NullFloat64
+		if err := rows.Scan(&sub.Name, &sub.Owner, &sub.Enabled, &sub.SlotName, &publications,
+			&sub.WorkerRunning, &sub.ReceivedLSN, &sub.LatestEndLSN, &lag); err != nil {
+			return nil, err
+		}
+		if publications != "" {
+			sub.Publications = strings.Split(publications, ",")
+		}
+		if lag.Valid {
+			sub.LagSeconds = &lag.Float64
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// ListLogicalSlots lists logical replication slots with how much WAL each
+// subscriber has yet to confirm
+func (c *Connection) ListLogicalSlots() ([]LogicalSlot, error) {
+	if err := c.requirePostgresLogical(); err != nil {
+		return nil, err
+	}
+	// pg_current_wal_lsn() fails during recovery, so standbys get no lag
+	rows, err := c.DB.Query(`
+		SELECT slot_name, COALESCE(plugin, ''), COALESCE(database, ''), active,
+			CASE WHEN pg_is_in_recovery() THEN NULL
+				ELSE pg_wal_lsn_diff(pg_current_wal_lsn(), confirmed_flush_lsn)::bigint END
+		FROM pg_replication_slots
+		WHERE slot_type = 'logical'
+		ORDER BY slot_name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replication slots: %w", err)
+	}
+	defer rows.Close()
+
+	var slots []LogicalSlot
+	for rows.Next() {
+		var slot LogicalSlot
+		var lag sql.NullInt64
+		if err := rows.Scan(&slot.Name, &slot.Plugin, &slot.Database, &slot.Active, &lag); err != nil {
+			return nil, err
+		}
+		if lag.Valid {
+			slot.LagBytes = &lag.Int64
+		}
+		slots = append(slots, slot)
+	}
+	return slots, rows.Err()
+}
+
+// CreatePublicationStatement builds the CREATE PUBLICATION statement for opts
+func (c *Connection) CreatePublicationStatement(opts CreatePublicationOptions) (string, error) {
+	if opts.Name == "" {
+		return "", fmt.Errorf("a publication name is required")
+	}
+
+	stmt := "CREATE PUBLICATION " + c.QuoteIdentifier(opts.Name)
+	if len(opts.Tables) == 0 {
+		stmt += " FOR ALL TABLES"
+	} else {
+		tables := make([]string, len(opts.Tables))
+		for i, table := range opts.Tables {
+			tables[i] = c.quoteQualified(table)
+		}
+		stmt += " FOR TABLE " + strings.Join(tables, ", ")
+	}
+
+	if len(opts.Operations) > 0 {
+		for _, op := range opts.Operations {
+			if !containsString(publicationOperations, op) {
+				return "", fmt.Errorf("unknown operation %q (use %s)", op, strings.Join(publicationOperations, ", "))
+			}
+		}
+		stmt += " WITH (publish = " + c.literal(strings.Join(opts.Operations, ", ")) + ")"
+	}
+	return stmt, nil
+}
+
+// CreatePublication creates a publication. Publishing all tables needs
+// superuser; listed tables need ownership of each.
+func (c *Connection) CreatePublication(opts CreatePublicationOptions) error {
+	if err := c.requirePostgresLogical(); err != nil {
+		return err
+	}
+	stmt, err := c.CreatePublicationStatement(opts)
+	if err != nil {
+		return err
+	}
+	if _, err := c.DB.Exec(stmt); err != nil {
+		return fmt.Errorf("failed to create publication: %w", err)
+	}
+	return nil
+}
+
+// DropPublication drops a publication. Subscribers to it stop receiving
+// changes and report errors until they drop their subscription.
+func (c *Connection) DropPublication(name string) error {
+	if err := c.requirePostgresLogical(); err != nil {
+		return err
+	}
+	if _, err := c.DB.Exec("DROP PUBLICATION " + c.QuoteIdentifier(name)); err != nil {
+		return fmt.Errorf("failed to drop publication: %w", err)
+	}
+	return nil
+}
+
+// CreateSubscriptionStatement builds the CREATE SUBSCRIPTION statement for
+// opts, with the password masked when redact is set
+func (c *Connection) CreateSubscriptionStatement(opts CreateSubscriptionOptions, redact bool) (string, error) {
+	switch {
+	case opts.Name == "":
+		return "", fmt.Errorf("a subscription name is required")
+	case opts.Host == "":
+		return "", fmt.Errorf("a publisher host is required")
+	case opts.Database == "":
+		return "", fmt.Errorf("the publisher's database is required")
+	case len(opts.Publications) == 0:
+		return "", fmt.Errorf("at least one publication is required")
+	}
+	if opts.Port == 0 {
+		opts.Port = 5432
+	}
+
+	conninfo := []string{
+		"host=" + conninfoValue(opts.Host),
+		"port=" + strconv.Itoa(opts.Port),
+		"dbname=" + conninfoValue(opts.Database),
+	}
+	if opts.User != "" {
+		conninfo = append(conninfo, "user="+conninfoValue(opts.User))
+	}
+	if opts.Password != "" {
+		password := opts.Password
+		if redact {
+			password = redactedPassword
+		}
+		conninfo = append(conninfo, "password="+conninfoValue(password))
+	}
+
+	return fmt.Sprintf("CREATE SUBSCRIPTION %s CONNECTION %s PUBLICATION %s WITH (copy_data = %t)",
+		c.QuoteIdentifier(opts.Name),
+		c.literal(strings.Join(conninfo, " ")),
+		c.quoteIdentifiers(opts.Publications),
+		opts.CopyData), nil
+}
+
+// CreateSubscription subscribes to publications on another server. This
+// creates a replication slot on the publisher, so it must be reachable.
+func (c *Connection) CreateSubscription(opts CreateSubscriptionOptions) error {
+	if err := c.requirePostgresLogical(); err != nil {
+		return err
+	}
+	stmt, err := c.CreateSubscriptionStatement(opts, false)
+	if err != nil {
+		return err
+	}
+	if _, err := c.DB.Exec(stmt); err != nil {
+		// The error may quote the connection string, password included
+		msg := err.Error()
+		if opts.Password != "" {
+			msg = strings.ReplaceAll(msg, opts.Password, redactedPassword)
+		}
+		return fmt.Errorf("failed to create subscription %s: %s", opts.Name, msg)
+	}
+	return nil
+}
+
+// DropSubscription drops a subscription along with its slot on the publisher
+func (c *Connection) DropSubscription(name string) error {
+	if err := c.requirePostgresLogical(); err != nil {
+		return err
+	}
+	if _, err := c.DB.Exec("DROP SUBSCRIPTION " + c.QuoteIdentifier(name)); err != nil {
+		return fmt.Errorf("failed to drop subscription: %w", err)
+	}
+	return nil
+}
+
+// requirePostgresLogical checks the server supports logical replication
+func (c *Connection) requirePostgresLogical() error {
+	if !isPostgresType(c.Config.Type) {
+		return fmt.Errorf("logical replication is only supported on PostgreSQL")
+	}
+	return nil
+}
+
+// quoteQualified quotes a table name that may be prefixed with its schema
+func (c *Connection) quoteQualified(name string) string {
+	if schema, table, ok := strings.Cut(name, "."); ok {
+		return c.QuoteIdentifier(schema) + "." + c.QuoteIdentifier(table)
+	}
+	return c.QuoteIdentifier(name)
+}
+
+// conninfoValue quotes a value for a libpq connection string
+func conninfoValue(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
@@ -0,0 +1,292 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+)
+
+// Table maintenance operations
+const (
+	MaintenanceAnalyze    = "analyze"     // Refresh the optimizer statistics
+	MaintenanceOptimize   = "optimize"    // OPTIMIZE TABLE, or VACUUM on PostgreSQL
+	MaintenanceVacuumFull = "vacuum-full" // PostgreSQL only
+	MaintenanceReindex    = "reindex"     // PostgreSQL only; OPTIMIZE TABLE rebuilds MariaDB's indexes
+	MaintenanceCheck      = "check"       // CHECK TABLE, or amcheck on PostgreSQL
+)
+
+// MaintenanceOps lists the maintenance operations in menu order
+var MaintenanceOps = []string{MaintenanceAnalyze, MaintenanceOptimize, MaintenanceVacuumFull, MaintenanceReindex, MaintenanceCheck}
+
+// maintenancePollInterval is how often a running operation's progress is read
+const maintenancePollInterval = time.Second
+
+// TableHealth is a table's size, its statistics' age and its bloat
+type TableHealth struct {
+	Table       string
+	Rows        int64 // Estimated
+	DataSize    int64
+	IndexSize   int64
+	DeadRows    int64     // -1 when the server doesn't count them
+	LastAnalyze time.Time // Zero when never or unknown
+	LastVacuum  time.Time // Zero when never or unknown; PostgreSQL only
+	Bloat       []BloatEstimate
+}
+
+// Wasted returns the bytes a rebuild of the table and its indexes would
+// reclaim, as estimated
+func (s *TableHealth) Wasted() int64 {
+	var wasted int64
+	for _, b := range s.Bloat {
+		wasted += b.Wasted
+	}
+	return wasted
+}
+
+// GetTableHealth returns the size, statistics age and bloat of a table in
+// the current database. Bloat estimates that fail are logged and left out.
+func (c *Connection) GetTableHealth(table string) (*TableHealth, error) {
+	stats := &TableHealth{Table: table}
+	var dead sql.NullInt64
+	var analyzed, vacuumed sql.NullTime
+
+	var err error
+	for _, query := range c.Driver.TableStatsQueries(table) {
+		err = c.DB.QueryRow(query).Scan(&stats.Rows, &stats.DataSize, &stats.IndexSize, &dead, &analyzed, &vacuumed)
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("table %s not found", table)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table health: %w", err)
+	}
+
+	stats.DeadRows = -1
+	if dead.Valid {
+		stats.DeadRows = dead.Int64
+	}
+	if analyzed.Valid {
+		stats.LastAnalyze = analyzed.Time
+	}
+	if vacuumed.Valid {
+		stats.LastVacuum = vacuumed.Time
+	}
+
+	bloat, err := c.EstimateBloat()
+	if err != nil {
+		logging.Warn("No bloat estimate for %s: %v", table, err)
+	}
+	for _, b := range bloat {
+		if b.Table == table {
+			stats.Bloat = append(stats.Bloat, b)
+		}
+	}
+	return stats, nil
+}
+
+// MaintenanceAction is a maintenance operation the server can run on a
+// table, for confirming first
+type MaintenanceAction struct {
+	Op        string
+	Table     string
+	Method    string // e.g. OPTIMIZE TABLE or VACUUM FULL
+	Statement string
+	Warning   string // What it locks and what it needs
+}
+
+// MaintenanceActions returns the maintenance operations the server can run
+// on a table, in menu order. PostgreSQL's check needs the amcheck extension.
+func (c *Connection) MaintenanceActions(table string) []MaintenanceAction {
+	postgres := isPostgresType(c.Config.Type)
+	concurrently := postgres && c.pgVersionNum() >= 120000
+
+	var actions []MaintenanceAction
+	for _, op := range MaintenanceOps {
+		statement := c.Driver.MaintenanceQuery(op, concurrently, table)
+		if statement == "" || (op == MaintenanceCheck && postgres && !c.pgExtensionInstalled("amcheck")) {
+			continue
+		}
+		action := MaintenanceAction{Op: op, Table: table, Statement: statement}
+		action.Method, action.Warning = maintenanceInfo(op, postgres, concurrently)
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// maintenanceInfo returns the name of an operation and what it locks
+func maintenanceInfo(op string, postgres, concurrently bool) (method, warning string) {
+	if !postgres {
+		switch op {
+		case MaintenanceAnalyze:
+			return "ANALYZE TABLE", "Samples the table's index statistics; InnoDB tables stay readable and writable."
+		case MaintenanceOptimize:
+			return "OPTIMIZE TABLE", "InnoDB rebuilds the table online and only blocks writes briefly at the start and end; " +
+				"MyISAM and Aria tables are locked for the whole rebuild. Needs free disk space for a copy of the table."
+		case MaintenanceCheck:
+			return "CHECK TABLE", "Reads the whole table and its indexes; MyISAM and Aria tables are read-only until it finishes."
+		}
+		return op, ""
+	}
+
+	switch op {
+	case MaintenanceAnalyze:
+		return "ANALYZE", "Samples the table's rows; reads and writes carry on."
+	case MaintenanceOptimize:
+		return "VACUUM", "Makes dead rows' space reusable without blocking reads or writes; the files don't shrink."
+	case MaintenanceVacuumFull:
+		return "VACUUM FULL", "VACUUM FULL takes an ACCESS EXCLUSIVE lock: every read and write on the table waits until it " +
+			"finishes. Needs free disk space for a full copy of the table."
+	case MaintenanceReindex:
+		if concurrently {
+			return "REINDEX CONCURRENTLY", "Builds new copies of the table's indexes without blocking writes, " +
+				"but takes longer and needs free disk space for the copies."
+		}
+		return "REINDEX", "Blocks writes to the table and queries using its indexes until it finishes."
+	case MaintenanceCheck:
+		return "amcheck", "Verifies the table's btree indexes with bt_index_check; reads and writes carry on."
+	}
+	return op, ""
+}
+
+// MaintenanceProgress is reported while a maintenance operation runs
+type MaintenanceProgress struct {
+	Phase   string  // What the server says it is doing, when it says
+	Percent float64 // -1 when the server doesn't report how far along it is
+}
+
+// MaintenanceResult is how a maintenance operation went
+type MaintenanceResult struct {
+	Action   MaintenanceAction
+	Messages []string // MariaDB's notes, e.g. "status: OK"
+	Elapsed  time.Duration
+}
+
+// RunMaintenance runs a maintenance operation, reporting the progress the
+// server publishes about it every second. Cancelling ctx stops the
+// statement on the server.
+func (c *Connection) RunMaintenance(ctx context.Context, action MaintenanceAction, onProgress func(MaintenanceProgress)) (*MaintenanceResult, error) {
+	ctx = orBackground(ctx)
+	result := &MaintenanceResult{Action: action}
+	start := time.Now()
+	logging.Info("Running %s on %s", action.Method, action.Table)
+
+	// One session for the statement, so its progress can be looked up by ID
+	conn, err := c.DB.Conn(ctx)
+	if err != nil {
+		return result, fmt.Errorf("%s failed for %s: %w", action.Method, action.Table, err)
+	}
+	defer conn.Close()
+	var session int64
+	if err := conn.QueryRowContext(ctx, c.Driver.SessionIDQuery()).Scan(&session); err != nil {
+		return result, fmt.Errorf("%s failed for %s: %w", action.Method, action.Table, err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	if onProgress != nil {
+		go c.watchMaintenance(session, done, onProgress)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		if _, err := c.DB.Exec(c.Driver.KillSessionQuery(session, true)); err != nil {
+			logging.Warn("Failed to stop %s on %s: %v", action.Method, action.Table, err)
+		}
+	})
+	defer stop()
+
+	if isPostgresType(c.Config.Type) && action.Op != MaintenanceCheck {
+		_, err = conn.ExecContext(ctx, action.Statement)
+	} else {
+		var rows *sql.Rows
+		if rows, err = conn.QueryContext(ctx, action.Statement); err == nil {
+			result.Messages, err = c.tableCommandMessages(rows)
+		}
+	}
+	result.Elapsed = time.Since(start)
+	if err != nil {
+		return result, fmt.Errorf("%s failed for %s: %w", action.Method, action.Table, err)
+	}
+	return result, nil
+}
+
+// watchMaintenance reports a session's progress until done is closed. It
+// stops early when the server can't tell, e.g. a PostgreSQL older than 13.
+func (c *Connection) watchMaintenance(session int64, done <-chan struct{}, onProgress func(MaintenanceProgress)) {
+	ticker := time.NewTicker(maintenancePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		var phase string
+		var percent sql.NullFloat64
+		err := c.DB.QueryRow(c.Driver.MaintenanceProgressQuery(session)).Scan(&phase, &percent)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			logging.Debug("Maintenance progress unavailable: %v", err)
+			return
+		}
+		p := MaintenanceProgress{Phase: phase, Percent: -1}
+		if percent.Valid {
+			p.Percent = percent.Float64
+		}
+		onProgress(p)
+	}
+}
+
+// tableCommandMessages reads the result of MariaDB's ANALYZE, OPTIMIZE and
+// CHECK TABLE, which report failures as rows rather than errors. Any other
+// result, like PostgreSQL's amcheck, is only drained for its errors.
+func (c *Connection) tableCommandMessages(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+	if c.Config.Type != DatabaseTypeMariaDB {
+		for rows.Next() {
+		}
+		return nil, rows.Err()
+	}
+
+	var messages, failures []string
+	for rows.Next() {
+		var table, op, msgType, msgText string
+		if err := rows.Scan(&table, &op, &msgType, &msgText); err != nil {
+			return messages, err
+		}
+		messages = append(messages, msgType+": "+msgText)
+		if strings.EqualFold(msgType, "error") {
+			failures = append(failures, msgText)
+		}
+	}
+	if len(failures) > 0 {
+		return messages, errors.New(strings.Join(failures, "; "))
+	}
+	return messages, rows.Err()
+}
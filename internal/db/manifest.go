@@ -0,0 +1,194 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/buffer"
+)
+
+// The comment lines framing the manifest at the end of a built-in dump
+const (
+	manifestStart = "-- YSM Manifest"
+	manifestEnd   = "-- End of YSM Manifest"
+)
+
+// DumpManifest is the footer of a built-in SQL dump: the rows written for
+// each table and a checksum of them, so a restore can be proven complete
+type DumpManifest struct {
+	Tables []ManifestTable `json:"tables"`
+}
+
+// ManifestTable is one table's entry in a dump manifest
+type ManifestTable struct {
+	Table    string `json:"table"`
+	Rows     int64  `json:"rows"`
+	Checksum uint32 `json:"crc32"` // CRC-32 of the row tuples as written, in order
+}
+
+// manifestChecksum is the rolling checksum of the rows written for a table
+type manifestChecksum struct {
+	rows int64
+	crc  uint32
+}
+
+// add folds one formatted row tuple into the checksum
+func (m *manifestChecksum) add(tuple string) {
+	m.rows++
+	m.crc = crc32.Update(m.crc, crc32.IEEETable, []byte(tuple))
+	m.crc = crc32.Update(m.crc, crc32.IEEETable, []byte{'\n'})
+}
+
+func (m *DumpManifest) add(table string, sum manifestChecksum) {
+	m.Tables = append(m.Tables, ManifestTable{Table: table, Rows: sum.rows, Checksum: sum.crc})
+}
+
+// write appends the manifest as a comment block, which importers skip
+func (m *DumpManifest) write(w io.Writer) {
+	fmt.Fprintf(w, "\n%s\n", manifestStart)
+	for _, t := range m.Tables {
+		fmt.Fprintf(w, "-- table %s rows=%d crc32=%08x\n", strconv.Quote(t.Table), t.Rows, t.Checksum)
+	}
+	fmt.Fprintf(w, "%s\n", manifestEnd)
+}
+
+// parseManifestLine reads one table line of a manifest
+func parseManifestLine(line string) (ManifestTable, error) {
+	var t ManifestTable
+	rest, ok := strings.CutPrefix(line, "-- table ")
+	if !ok {
+		return t, fmt.Errorf("unexpected manifest line %q", line)
+	}
+	quoted, err := strconv.QuotedPrefix(rest)
+	if err != nil {
+		return t, fmt.Errorf("bad table name in manifest line %q", line)
+	}
+	if t.Table, err = strconv.Unquote(quoted); err != nil {
+		return t, err
+	}
+	if _, err := fmt.Sscanf(rest[len(quoted):], " rows=%d crc32=%x", &t.Rows, &t.Checksum); err != nil {
+		return t, fmt.Errorf("bad manifest line %q: %w", line, err)
+	}
+	return t, nil
+}
+
+// ReadDumpManifest reads the manifest at the end of a dump, compressed or
+// not. It returns nil when the dump has none, as with dumps written by
+// other tools, structure-only exports or pg_dump's own formats.
+func ReadDumpManifest(path string) (*DumpManifest, error) {
+	reader, err := buffer.NewBufferedReader(path, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var manifest, current *DumpManifest
+	for {
+		line, err := reader.ReadLine()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		switch {
+		case line == manifestStart:
+			current = &DumpManifest{}
+		case current != nil && line == manifestEnd:
+			manifest, current = current, nil
+		case current != nil:
+			t, parseErr := parseManifestLine(line)
+			if parseErr != nil {
+				return nil, parseErr
+			}
+			current.Tables = append(current.Tables, t)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	return manifest, nil
+}
+
+// ManifestCheck compares the rows of the tables a dump was loaded into with
+// its manifest
+type ManifestCheck struct {
+	Tables []ManifestTableCheck `json:"tables"`
+}
+
+// ManifestTableCheck is one table of a manifest check
+type ManifestTableCheck struct {
+	Table    string `json:"table"`
+	Expected int64  `json:"expected"`
+	Loaded   int64  `json:"loaded"` // -1 when the table is missing
+	Error    string `json:"error,omitempty"`
+}
+
+// OK reports whether the table holds exactly the rows the dump wrote
+func (t ManifestTableCheck) OK() bool {
+	return t.Error == "" && t.Loaded == t.Expected
+}
+
+// String says how the table compares with the manifest
+func (t ManifestTableCheck) String() string {
+	if t.Error != "" {
+		return fmt.Sprintf("%s: %s", t.Table, t.Error)
+	}
+	return fmt.Sprintf("%s: %d rows, the dump wrote %d", t.Table, t.Loaded, t.Expected)
+}
+
+// Mismatches returns the tables that don't match the manifest
+func (c *ManifestCheck) Mismatches() []ManifestTableCheck {
+	var bad []ManifestTableCheck
+	for _, t := range c.Tables {
+		if !t.OK() {
+			bad = append(bad, t)
+		}
+	}
+	return bad
+}
+
+// VerifyManifest counts the rows of each table in the manifest in the
+// database the dump was loaded into. Tables that already held rows before
+// the load show up as mismatches too.
+func (c *Connection) VerifyManifest(database string, manifest *DumpManifest) (*ManifestCheck, error) {
+	conn := c
+	if database != "" {
+		opened, err := c.openDatabase(database)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", database, err)
+		}
+		defer opened.Close()
+		conn = opened
+	}
+
+	check := &ManifestCheck{}
+	for _, t := range manifest.Tables {
+		result := ManifestTableCheck{Table: t.Table, Expected: t.Rows, Loaded: -1}
+		err := conn.DB.QueryRow("SELECT COUNT(*) FROM " + conn.QuoteIdentifier(t.Table)).Scan(&result.Loaded)
+		if err != nil {
+			result.Loaded = -1
+			result.Error = err.Error()
+		}
+		check.Tables = append(check.Tables, result)
+	}
+	return check, nil
+}
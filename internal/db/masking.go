@@ -0,0 +1,549 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/buffer"
+	"gopkg.in/yaml.v3"
+)
+
+// MaskStrategy determines how a column value is anonymized
+type MaskStrategy string
+
+const (
+	MaskRedact  MaskStrategy = "redact"  // Replace with a fixed value (default "REDACTED")
+	MaskNull    MaskStrategy = "null"    // Replace with NULL
+	MaskHash    MaskStrategy = "hash"    // Deterministic hash, keeps joins consistent
+	MaskEmail   MaskStrategy = "email"   // Deterministic address on a reserved domain
+	MaskPhone   MaskStrategy = "phone"   // Deterministic fictional 555 number
+	MaskPartial MaskStrategy = "partial" // Keep the last 4 characters
+)
+
+// Built-in leak detectors
+const (
+	DetectEmail = "email"
+	DetectPhone = "phone"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`(\+\d{1,3}[\s-]?)?\(?\b\d{3}\)?[-.\s]\d{3}[-.\s]\d{4}\b`)
+	// Reserved domains (RFC 2606) are what masked emails use, so they never count as leaks
+	reservedEmailPattern = regexp.MustCompile(`(?i)@(example\.(com|org|net)|[A-Za-z0-9.-]+\.(invalid|test|example))$`)
+)
+
+// MaskRule masks one column; Table "*" matches every table
+type MaskRule struct {
+	Table    string       `yaml:"table"`
+	Column   string       `yaml:"column"`
+	Strategy MaskStrategy `yaml:"strategy"`
+	Value    string       `yaml:"value,omitempty"` // Replacement for redact
+}
+
+// LeakCheck configures the post-export verifier
+type LeakCheck struct {
+	Detect   []string `yaml:"detect,omitempty"`   // Built-in detectors (default: email, phone)
+	Patterns []string `yaml:"patterns,omitempty"` // Extra regexes that must not appear
+	Allow    []string `yaml:"allow,omitempty"`    // Regexes for matches that are fine
+}
+
+// MaskingConfig is a set of masking rules plus leak checks, usually loaded from YAML
+type MaskingConfig struct {
+	Rules  []MaskRule `yaml:"rules"`
+	Verify LeakCheck  `yaml:"verify,omitempty"`
+}
+
+// LoadMaskingConfig reads a masking config file
+func LoadMaskingConfig(path string) (*MaskingConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read masking config: %w", err)
+	}
+
+	var mc MaskingConfig
+	if err := yaml.Unmarshal(data, &mc); err != nil {
+		return nil, fmt.Errorf("failed to parse masking config: %w", err)
+	}
+	if err := mc.validate(); err != nil {
+		return nil, err
+	}
+	return &mc, nil
+}
+
+// validate checks the rules and leak checks, filling in the default strategy
+func (mc *MaskingConfig) validate() error {
+	for i, r := range mc.Rules {
+		if r.Table == "" || r.Column == "" {
+			return fmt.Errorf("masking rule %d: table and column are required", i+1)
+		}
+		switch r.Strategy {
+		case "":
+			mc.Rules[i].Strategy = MaskRedact
+		case MaskRedact, MaskNull, MaskHash, MaskEmail, MaskPhone, MaskPartial:
+		default:
+			return fmt.Errorf("masking rule %d: unknown strategy '%s'", i+1, r.Strategy)
+		}
+	}
+	_, _, err := mc.compileChecks()
+	return err
+}
+
+// RuleFor returns the rule masking a column, if any
+func (mc *MaskingConfig) RuleFor(table, column string) *MaskRule {
+	if mc == nil {
+		return nil
+	}
+	var wildcard *MaskRule
+	for i, r := range mc.Rules {
+		if !strings.EqualFold(r.Column, column) {
+			continue
+		}
+		if strings.EqualFold(r.Table, table) {
+			return &mc.Rules[i]
+		}
+		if r.Table == "*" && wildcard == nil {
+			wildcard = &mc.Rules[i]
+		}
+	}
+	return wildcard
+}
+
+// Apply masks a scanned value. NULLs stay NULL so nullability is preserved.
+func (r *MaskRule) Apply(val interface{}) interface{} {
+	if val == nil {
+		return nil
+	}
+
+	var s string
+	switch v := val.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		s = fmt.Sprintf("%v", v)
+	}
+
+	sum := sha256.Sum256([]byte(s))
+	digest := hex.EncodeToString(sum[:])
+
+	switch r.Strategy {
+	case MaskNull:
+		return nil
+	case MaskHash:
+		return digest[:16]
+	case MaskEmail:
+		return "user_" + digest[:10] + "@example.invalid"
+	case MaskPhone:
+		return fmt.Sprintf("555-01%02d", int(sum[0])%100)
+	case MaskPartial:
+		runes := []rune(s)
+		if len(runes) <= 4 {
+			return strings.Repeat("*", len(runes))
+		}
+		return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+	default:
+		if r.Value != "" {
+			return r.Value
+		}
+		return "REDACTED"
+	}
+}
+
+// columnMasks resolves the rule for each result column of a table
+func (mc *MaskingConfig) columnMasks(table string, columns []string) []*MaskRule {
+	if mc == nil || len(mc.Rules) == 0 {
+		return nil
+	}
+	masks := make([]*MaskRule, len(columns))
+	found := false
+	for i, col := range columns {
+		if masks[i] = mc.RuleFor(table, col); masks[i] != nil {
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+	return masks
+}
+
+// MaskSample is one before/after pair in a masking preview
+type MaskSample struct {
+	Before string
+	After  string
+}
+
+// MaskPreview shows how a masked column will look in the export
+type MaskPreview struct {
+	Table    string
+	Column   string
+	Strategy MaskStrategy
+	Samples  []MaskSample
+}
+
+// PreviewMasking samples each masked column and shows the masked result
+func (c *Connection) PreviewMasking(mc *MaskingConfig, tables []string, samples int) ([]MaskPreview, error) {
+	if samples <= 0 {
+		samples = 5
+	}
+	if len(tables) == 0 {
+		tableList, err := c.ListTables()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+		for _, t := range tableList {
+			tables = append(tables, t.Name)
+		}
+	}
+
+	var previews []MaskPreview
+	for _, table := range tables {
+		columns, err := c.DescribeTable(table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe %s: %w", table, err)
+		}
+
+		for _, col := range columns {
+			rule := mc.RuleFor(table, col.Field)
+			if rule == nil {
+				continue
+			}
+
+			query := fmt.Sprintf("SELECT %s FROM %s WHERE %s IS NOT NULL LIMIT %d",
+				c.QuoteIdentifier(col.Field), c.QuoteIdentifier(table), c.QuoteIdentifier(col.Field), samples)
+			rows, err := c.DB.Query(query)
+			if err != nil {
+				return nil, fmt.Errorf("failed to sample %s.%s: %w", table, col.Field, err)
+			}
+
+			preview := MaskPreview{Table: table, Column: col.Field, Strategy: rule.Strategy}
+			for rows.Next() {
+				var val interface{}
+				if err := rows.Scan(&val); err != nil {
+					rows.Close()
+					return nil, err
+				}
+				preview.Samples = append(preview.Samples, MaskSample{
+					Before: previewString(val),
+					After:  previewString(rule.Apply(val)),
+				})
+			}
+			rows.Close()
+			previews = append(previews, preview)
+		}
+	}
+	return previews, nil
+}
+
+// MaskTablesStats counts what MaskTables changed
+type MaskTablesStats struct {
+	Tables int   // Tables with at least one masked column
+	Rows   int64 // Rows rewritten
+}
+
+// MaskTables masks the configured columns in place in every table of the
+// current database, one transaction per table. Rows are addressed by primary
+// key, so a masked table without one is an error. Meant for clones and
+// staging copies; it refuses protected databases.
+func (c *Connection) MaskTables(mc *MaskingConfig, onProgress func(table string, tableNum, totalTables int)) (*MaskTablesStats, error) {
+	if err := c.CheckDatabaseProtected(c.Config.Database, "mask"); err != nil {
+		return nil, err
+	}
+	tables, err := c.ListTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	stats := &MaskTablesStats{}
+	for i, table := range tables {
+		if onProgress != nil {
+			onProgress(table.Name, i+1, len(tables))
+		}
+
+		columns, err := c.DescribeTable(table.Name)
+		if err != nil {
+			return stats, fmt.Errorf("failed to describe %s: %w", table.Name, err)
+		}
+		var masked []string
+		var rules []*MaskRule
+		for _, col := range columns {
+			if rule := mc.RuleFor(table.Name, col.Field); rule != nil {
+				masked = append(masked, col.Field)
+				rules = append(rules, rule)
+			}
+		}
+		if len(masked) == 0 {
+			continue
+		}
+
+		pk, err := c.PrimaryKey(table.Name)
+		if err != nil {
+			return stats, err
+		}
+		if len(pk) == 0 {
+			return stats, fmt.Errorf("can't mask %s: it has no primary key", table.Name)
+		}
+
+		rows, err := c.maskTable(table.Name, pk, masked, rules)
+		if err != nil {
+			return stats, fmt.Errorf("failed to mask %s: %w", table.Name, err)
+		}
+		stats.Tables++
+		stats.Rows += rows
+	}
+	return stats, nil
+}
+
+// maskTable rewrites the masked columns of every row in one transaction
+func (c *Connection) maskTable(table string, pk, masked []string, rules []*MaskRule) (int64, error) {
+	query := fmt.Sprintf("SELECT %s, %s FROM %s", c.quoteIdentifiers(pk), c.quoteIdentifiers(masked), c.QuoteIdentifier(table))
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return 0, err
+	}
+
+	// Read the keys and masked values first so the updates don't race the scan
+	var updates [][]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(pk)+len(masked))
+		ptrs := make([]interface{}, len(values))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		args := make([]interface{}, 0, len(values))
+		for i, rule := range rules {
+			args = append(args, rule.Apply(values[len(pk)+i]))
+		}
+		// Keys go back as text; drivers would bind raw bytes as binary
+		for _, key := range values[:len(pk)] {
+			if b, ok := key.([]byte); ok {
+				key = string(b)
+			}
+			args = append(args, key)
+		}
+		updates = append(updates, args)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	sets := make([]string, len(masked))
+	for i, col := range masked {
+		sets[i] = fmt.Sprintf("%s = %s", c.QuoteIdentifier(col), Placeholder(i, c.Config.Type))
+	}
+	conds := make([]string, len(pk))
+	for i, col := range pk {
+		conds[i] = fmt.Sprintf("%s = %s", c.QuoteIdentifier(col), Placeholder(len(masked)+i, c.Config.Type))
+	}
+	update := fmt.Sprintf("UPDATE %s SET %s WHERE %s", c.QuoteIdentifier(table),
+		strings.Join(sets, ", "), strings.Join(conds, " AND "))
+
+	tx, err := c.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	stmt, err := tx.Prepare(update)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	defer stmt.Close()
+	for _, args := range updates {
+		if _, err := stmt.Exec(args...); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int64(len(updates)), nil
+}
+
+func previewString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Leak is a suspicious match found in an exported dump
+type Leak struct {
+	Line    int
+	Pattern string
+	Match   string
+}
+
+// LeakReport summarizes a dump scan
+type LeakReport struct {
+	LinesScanned int
+	Counts       map[string]int // Matches per pattern
+	Leaks        []Leak         // First matches, capped for display
+}
+
+// Found reports whether any leak was detected
+func (r *LeakReport) Found() bool {
+	return len(r.Leaks) > 0
+}
+
+type leakDetector struct {
+	name string
+	re   *regexp.Regexp
+}
+
+func (mc *MaskingConfig) compileChecks() ([]leakDetector, []*regexp.Regexp, error) {
+	detect := mc.Verify.Detect
+	if detect == nil {
+		detect = []string{DetectEmail, DetectPhone}
+	}
+
+	var detectors []leakDetector
+	for _, d := range detect {
+		switch d {
+		case DetectEmail:
+			detectors = append(detectors, leakDetector{name: DetectEmail, re: emailPattern})
+		case DetectPhone:
+			detectors = append(detectors, leakDetector{name: DetectPhone, re: phonePattern})
+		default:
+			return nil, nil, fmt.Errorf("unknown leak detector '%s'", d)
+		}
+	}
+	for _, p := range mc.Verify.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid leak pattern '%s': %w", p, err)
+		}
+		detectors = append(detectors, leakDetector{name: p, re: re})
+	}
+
+	var allow []*regexp.Regexp
+	for _, p := range mc.Verify.Allow {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid allow pattern '%s': %w", p, err)
+		}
+		allow = append(allow, re)
+	}
+	return detectors, allow, nil
+}
+
+// maxReportedLeaks caps how many individual matches a report keeps
+const maxReportedLeaks = 20
+
+// VerifyDumpLeakage scans an exported dump (compressed or not) for residual
+// personal data. Only data lines are scanned; comments and DDL are skipped.
+func VerifyDumpLeakage(path string, mc *MaskingConfig) (*LeakReport, error) {
+	if mc == nil {
+		mc = &MaskingConfig{}
+	}
+	detectors, allow, err := mc.compileChecks()
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := buffer.NewBufferedReader(path, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	report := &LeakReport{Counts: make(map[string]int)}
+	inData := false
+	for {
+		line, err := reader.ReadLine()
+		if line != "" || err == nil {
+			report.LinesScanned++
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "INSERT INTO") || strings.HasPrefix(trimmed, "COPY ") {
+				inData = true
+			}
+			if inData && !strings.HasPrefix(trimmed, "--") {
+				scanLeaks(report, line, detectors, allow)
+			}
+			if strings.HasSuffix(trimmed, ";") || trimmed == `\.` {
+				inData = false
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dump: %w", err)
+		}
+	}
+	return report, nil
+}
+
+func scanLeaks(report *LeakReport, line string, detectors []leakDetector, allow []*regexp.Regexp) {
+	for _, d := range detectors {
+		for _, match := range d.re.FindAllString(line, -1) {
+			if d.name == DetectEmail && reservedEmailPattern.MatchString(match) {
+				continue
+			}
+			if allowed(match, allow) {
+				continue
+			}
+			report.Counts[d.name]++
+			if len(report.Leaks) < maxReportedLeaks {
+				report.Leaks = append(report.Leaks, Leak{
+					Line:    report.LinesScanned,
+					Pattern: d.name,
+					Match:   redactMatch(match),
+				})
+			}
+		}
+	}
+}
+
+func allowed(match string, allow []*regexp.Regexp) bool {
+	for _, re := range allow {
+		if re.MatchString(match) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactMatch hides most of a leaked value so reports don't leak it again
+func redactMatch(s string) string {
+	runes := []rune(s)
+	if len(runes) <= 4 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:2]) + strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-2:])
+}
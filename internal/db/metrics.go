@@ -0,0 +1,298 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// DefaultMetricsInterval is the sampling interval used when none is configured
+const DefaultMetricsInterval = 5 * time.Second
+
+// MetricsRetention is how much history a MetricsCollector keeps
+const MetricsRetention = time.Hour
+
+// MetricSample is one point of the server's time series. Rates are computed
+// from counter deltas since the previous sample; a negative value means the
+// metric was unavailable at that point
+type MetricSample struct {
+	Time           time.Time
+	QPS            float64 // Statements (MariaDB) or transactions (PostgreSQL) per second
+	Connections    float64
+	CacheHitRate   float64 // Percent of buffer lookups served from memory
+	ReplicationLag float64 // Seconds behind the primary
+
+	// Host metrics, -1 unless enabled with EnableHostMetrics
+	HostCPU    float64 // Percent of CPU time busy
+	HostIOWait float64 // Percent of CPU time waiting on IO
+	HostMemory float64 // Percent of memory in use
+	DiskBusy   float64 // Percent of time the data directory's device was busy
+}
+
+// metricCounters holds the cumulative counters a sample's rates derive from
+type metricCounters struct {
+	at      time.Time
+	queries int64
+	lookups int64
+	misses  int64
+}
+
+// MetricsCollector samples server metrics in the background into a ring
+// buffer covering the last MetricsRetention
+type MetricsCollector struct {
+	conn     *Connection
+	interval time.Duration
+
+	mu      sync.Mutex
+	samples []MetricSample
+	next    int
+	full    bool
+	prev    *metricCounters
+	lastErr error
+	running bool
+	stop    chan struct{}
+
+	hostOn   bool         // Host metrics requested
+	host     *hostSampler // nil when host metrics are off or unavailable
+	lastHost *HostMetrics
+	hostErr  error
+}
+
+// NewMetricsCollector creates a collector sampling conn every interval
+func NewMetricsCollector(conn *Connection, interval time.Duration) *MetricsCollector {
+	if interval <= 0 {
+		interval = DefaultMetricsInterval
+	}
+	size := int(MetricsRetention / interval)
+	if size < 2 {
+		size = 2
+	}
+	return &MetricsCollector{
+		conn:     conn,
+		interval: interval,
+		samples:  make([]MetricSample, size),
+	}
+}
+
+// Interval returns the sampling interval
+func (m *MetricsCollector) Interval() time.Duration {
+	return m.interval
+}
+
+// RateLabel names the QPS series for the collector's database type
+func (m *MetricsCollector) RateLabel() string {
+	if m.conn.Config.Type == DatabaseTypePostgres {
+		return "TPS"
+	}
+	return "QPS"
+}
+
+// EnableHostMetrics adds CPU, memory, disk and IO wait readings of this
+// machine to every sample. It fails when the server isn't local or the
+// host can't be read; HostMetrics reports the same error afterwards.
+func (m *MetricsCollector) EnableHostMetrics() error {
+	sampler, err := m.conn.newHostSampler()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hostOn = true
+	m.host = sampler
+	m.hostErr = err
+	return err
+}
+
+// HostMetricsEnabled reports whether host metrics were requested
+func (m *MetricsCollector) HostMetricsEnabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.hostOn
+}
+
+// HostMetrics returns the latest host reading and the error from taking it;
+// both are nil before the first sample
+func (m *MetricsCollector) HostMetrics() (*HostMetrics, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastHost, m.hostErr
+}
+
+// Start begins background sampling; calling it on a running collector is a no-op
+func (m *MetricsCollector) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running {
+		return
+	}
+	m.running = true
+	m.stop = make(chan struct{})
+	go m.run(m.stop)
+}
+
+// Stop ends background sampling, keeping the collected history
+func (m *MetricsCollector) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running {
+		return
+	}
+	m.running = false
+	close(m.stop)
+}
+
+// Running reports whether the collector is sampling
+func (m *MetricsCollector) Running() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.running
+}
+
+// Samples returns the retained samples, oldest first
+func (m *MetricsCollector) Samples() []MetricSample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.full {
+		return append([]MetricSample(nil), m.samples[:m.next]...)
+	}
+	out := make([]MetricSample, 0, len(m.samples))
+	out = append(out, m.samples[m.next:]...)
+	return append(out, m.samples[:m.next]...)
+}
+
+// Err returns the error from the most recent sample, if any
+func (m *MetricsCollector) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastErr
+}
+
+func (m *MetricsCollector) run(stop chan struct{}) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.collect()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			m.collect()
+		}
+	}
+}
+
+// collect takes one sample and appends it once a previous counter reading
+// exists to compute rates against
+func (m *MetricsCollector) collect() {
+	sample := MetricSample{Time: time.Now(), QPS: -1, Connections: -1, CacheHitRate: -1, ReplicationLag: -1,
+		HostCPU: -1, HostIOWait: -1, HostMemory: -1, DiskBusy: -1}
+
+	counters, err := m.readCounters()
+	if err == nil {
+		sample.Time = counters.at
+	}
+
+	if stats, cerr := m.conn.GetConnectionStats(); cerr == nil {
+		sample.Connections = float64(stats.Active)
+	}
+	sample.ReplicationLag = m.replicationLag()
+
+	m.mu.Lock()
+	host := m.host
+	m.mu.Unlock()
+	var hostMetrics *HostMetrics
+	var hostErr error
+	if host != nil {
+		// Only this goroutine samples, so the sampler needs no lock
+		hostMetrics, hostErr = host.sample()
+		if hostErr == nil {
+			sample.HostCPU = hostMetrics.CPU
+			sample.HostIOWait = hostMetrics.IOWait
+			sample.HostMemory = hostMetrics.MemoryPercent()
+			sample.DiskBusy = hostMetrics.DiskBusy
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastErr = err
+	if host != nil {
+		m.lastHost = hostMetrics
+		m.hostErr = hostErr
+	}
+	if err != nil {
+		m.prev = nil
+		return
+	}
+	prev := m.prev
+	m.prev = counters
+	if prev == nil {
+		return
+	}
+
+	elapsed := counters.at.Sub(prev.at).Seconds()
+	// Counters going backwards mean the server restarted
+	if elapsed > 0 && counters.queries >= prev.queries {
+		sample.QPS = float64(counters.queries-prev.queries) / elapsed
+	}
+	lookups := counters.lookups - prev.lookups
+	misses := counters.misses - prev.misses
+	if lookups > 0 && misses >= 0 && misses <= lookups {
+		sample.CacheHitRate = 100 * float64(lookups-misses) / float64(lookups)
+	}
+
+	m.samples[m.next] = sample
+	m.next++
+	if m.next == len(m.samples) {
+		m.next = 0
+		m.full = true
+	}
+}
+
+func (m *MetricsCollector) readCounters() (*metricCounters, error) {
+	var queries, lookups, misses sql.NullInt64
+	err := m.conn.DB.QueryRow(m.conn.Driver.MetricsCountersQuery()).Scan(&queries, &lookups, &misses)
+	if err != nil {
+		return nil, err
+	}
+	return &metricCounters{
+		at:      time.Now(),
+		queries: queries.Int64,
+		lookups: lookups.Int64,
+		misses:  misses.Int64,
+	}, nil
+}
+
+// replicationLag returns the replica's lag in seconds, or -1 when the server
+// isn't a replica or the lag is unknown
+func (m *MetricsCollector) replicationLag() float64 {
+	if m.conn.Config.Type == DatabaseTypePostgres {
+		stats, err := m.conn.GetReplicationStats()
+		if err != nil || !stats.IsReplica {
+			return -1
+		}
+		return stats.LagSeconds
+	}
+	status, err := m.conn.GetMariaDBReplicationStatus()
+	if err != nil || !status.IsReplica || status.SecondsBehind == nil {
+		return -1
+	}
+	return float64(*status.SecondsBehind)
+}
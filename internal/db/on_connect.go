@@ -0,0 +1,136 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// openDB opens the pool for cfg. With OnConnect statements, every session
+// the pool opens runs them first, so none of YSM's connections - the
+// interactive one, jobs on connections of their own, reconnects - misses
+// them.
+func openDB(d Driver, cfg ConnectionConfig) (*sql.DB, error) {
+	dsn := d.DSN(cfg)
+	if len(cfg.OnConnect) == 0 {
+		return sql.Open(d.DriverName(), dsn)
+	}
+
+	// sql.Open doesn't connect, it only finds the registered driver
+	probe, err := sql.Open(d.DriverName(), dsn)
+	if err != nil {
+		return nil, err
+	}
+	registered := probe.Driver()
+	probe.Close()
+
+	var connector driver.Connector = dsnConnector{dsn: dsn, driver: registered}
+	if opener, ok := registered.(driver.DriverContext); ok {
+		if connector, err = opener.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+	return sql.OpenDB(&onConnectConnector{Connector: connector, statements: cfg.OnConnect}), nil
+}
+
+// dsnConnector opens sessions with Open for drivers without connectors of
+// their own, such as lib/pq's
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// onConnectConnector runs statements on each new session before the pool
+// hands it out
+type onConnectConnector struct {
+	driver.Connector
+	statements []string
+}
+
+// Connect opens a session and runs the statements, failing the session
+// when one fails
+func (c *onConnectConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range c.statements {
+		if strings.TrimSpace(stmt) == "" {
+			continue
+		}
+		if err := execDriverConn(ctx, conn, stmt); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("on connect statement %q failed: %w", stmt, err)
+		}
+	}
+	return conn, nil
+}
+
+// execDriverConn runs a statement without arguments on a driver session
+func execDriverConn(ctx context.Context, conn driver.Conn, stmt string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, stmt, nil)
+		if err != driver.ErrSkip {
+			return err
+		}
+	}
+
+	prepared, err := conn.Prepare(stmt)
+	if err != nil {
+		return err
+	}
+	defer prepared.Close()
+	if execer, ok := prepared.(driver.StmtExecContext); ok {
+		_, err = execer.ExecContext(ctx, nil)
+		return err
+	}
+	_, err = prepared.Exec(nil)
+	return err
+}
+
+// SplitOnConnect splits statements typed on one line with the import's
+// parser, so semicolons in quotes, after backslash escapes or in comments
+// don't end a statement. Empty statements are dropped.
+func SplitOnConnect(s string) []string {
+	var stmts []string
+	parser := newSQLParser(bufio.NewReader(strings.NewReader(s)), int64(len(s))+1)
+	for {
+		stmt, _, err := parser.NextStatement()
+		if err != nil {
+			return stmts
+		}
+		stmt = strings.TrimSpace(strings.TrimSuffix(stmt, ";"))
+		if stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+}
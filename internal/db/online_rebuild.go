@@ -0,0 +1,554 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultRebuildChunkSize is how many rows each copy statement of an online
+// rebuild moves
+const DefaultRebuildChunkSize = 1000
+
+// Online rebuild phases, as reported to the progress callback
+const (
+	RebuildPhaseCopy    = "copy"
+	RebuildPhaseSwap    = "swap"
+	RebuildPhaseCleanup = "cleanup"
+)
+
+// OnlineRebuildOptions describes a MariaDB table rebuild that keeps the table
+// usable: a shadow copy with the new settings is filled in chunks while
+// triggers carry over concurrent writes, then takes the table's place in one
+// atomic RENAME
+type OnlineRebuildOptions struct {
+	Database  string
+	Table     string
+	Engine    string // "" keeps the current engine
+	Charset   string // Converts the text columns; "" keeps them
+	Collation string // Needs Charset
+	RowFormat string // "" keeps the current row format
+	ChunkSize int    // Rows per copy statement (default DefaultRebuildChunkSize)
+	DropOld   bool   // Drop the original after the swap instead of keeping it for a revert
+}
+
+// OnlineRebuildPlan is what an online rebuild will run, for review first
+type OnlineRebuildPlan struct {
+	OnlineRebuildOptions
+	Shadow        string   // Table being filled, _<table>_new
+	Old           string   // Name the original gets at the swap, _<table>_old
+	Key           []string // Primary key the copy walks
+	Columns       []string // Columns copied; generated ones are left to the server
+	Current       string   // Engine, collation and row format now
+	Target        string   // The same after the rebuild
+	EstimatedRows int64
+	Setup         []string // Shadow table and triggers
+	Copy          string   // One chunk, with the key bounds as placeholders
+	Swap          string
+	Cleanup       []string // Run after the swap
+	Rollback      []string // Run when the rebuild stops before the swap
+	Notes         []string
+}
+
+// Statements lists everything the rebuild runs, in order, for previews
+func (p *OnlineRebuildPlan) Statements() []string {
+	statements := append([]string(nil), p.Setup...)
+	statements = append(statements, fmt.Sprintf("-- repeated in chunks of %d rows:\n%s", p.ChunkSize, p.Copy), p.Swap)
+	return append(statements, p.Cleanup...)
+}
+
+// OnlineRebuildProgress is reported after each chunk and at each phase
+type OnlineRebuildProgress struct {
+	Phase  string
+	Copied int64
+	Total  int64 // Estimated rows
+}
+
+// OnlineRebuildResult summarizes a finished online rebuild
+type OnlineRebuildResult struct {
+	Copied   int64
+	Chunks   int
+	Duration time.Duration
+	Old      string   // The original table, kept for a revert; "" when dropped
+	Warnings []string // Cleanup statements that failed after the swap
+}
+
+// rebuildOptionPattern keeps engine, charset and row format names from
+// carrying anything but a name into the ALTER
+var rebuildOptionPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// foreignKeyConstraintPattern finds the foreign key names of a CREATE TABLE
+var foreignKeyConstraintPattern = regexp.MustCompile("CONSTRAINT `((?:[^`]|``)+)` FOREIGN KEY")
+
+// rebuildTableNames returns the shadow and old table names for a table
+func rebuildTableNames(table string) (shadow, old string) {
+	return "_" + table + "_new", "_" + table + "_old"
+}
+
+// rebuildTriggerName names one of the triggers feeding the shadow table,
+// keeping within MariaDB's 64 character limit
+func rebuildTriggerName(table, event string) string {
+	name := []rune(table)
+	if len(name) > 52 {
+		name = name[:52]
+	}
+	return "ysm_" + string(name) + "_" + event
+}
+
+// PlanOnlineRebuild checks that a table can be rebuilt online and builds the
+// statements doing it. The table needs a primary key to copy in chunks, and
+// can't have triggers of its own or be referenced by foreign keys, since
+// both would stay with the original table at the swap.
+func (c *Connection) PlanOnlineRebuild(opts OnlineRebuildOptions) (*OnlineRebuildPlan, error) {
+	if c.Config.Type != DatabaseTypeMariaDB {
+		return nil, fmt.Errorf("online rebuilds are only supported on MariaDB/MySQL; use pg_repack on PostgreSQL")
+	}
+	if opts.Database == "" || opts.Table == "" {
+		return nil, fmt.Errorf("a database and table are required")
+	}
+	if err := c.CheckDatabaseProtected(opts.Database, "rebuild tables in"); err != nil {
+		return nil, err
+	}
+	for _, option := range []struct{ name, value string }{
+		{"engine", opts.Engine}, {"charset", opts.Charset}, {"collation", opts.Collation}, {"row format", opts.RowFormat},
+	} {
+		if option.value != "" && !rebuildOptionPattern.MatchString(option.value) {
+			return nil, fmt.Errorf("invalid %s %q", option.name, option.value)
+		}
+	}
+	if opts.Collation != "" && opts.Charset == "" {
+		return nil, fmt.Errorf("a collation needs its charset")
+	}
+	if opts.Engine != "" && !c.engineAvailable(opts.Engine) {
+		return nil, fmt.Errorf("storage engine %s isn't available on this server", opts.Engine)
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultRebuildChunkSize
+	}
+
+	plan := &OnlineRebuildPlan{OnlineRebuildOptions: opts}
+	plan.Shadow, plan.Old = rebuildTableNames(opts.Table)
+	if len([]rune(plan.Shadow)) > 64 {
+		return nil, fmt.Errorf("table name %s is too long to add a shadow table next to it", opts.Table)
+	}
+
+	var engine, collation, rowFormat, tableType string
+	err := c.DB.QueryRow(`SELECT TABLE_TYPE, IFNULL(ENGINE, ''), IFNULL(TABLE_COLLATION, ''), IFNULL(ROW_FORMAT, ''), IFNULL(TABLE_ROWS, 0)
+		FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`, opts.Database, opts.Table).
+		Scan(&tableType, &engine, &collation, &rowFormat, &plan.EstimatedRows)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("table %s.%s not found", opts.Database, opts.Table)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table status: %w", err)
+	}
+	if tableType != "BASE TABLE" {
+		return nil, fmt.Errorf("%s.%s is a %s, not a table", opts.Database, opts.Table, strings.ToLower(tableType))
+	}
+	plan.Current = fmt.Sprintf("%s, %s, %s", engine, collation, rowFormat)
+	target := func(value, current string) string {
+		if value == "" {
+			return current
+		}
+		return value
+	}
+	targetCollation := collation
+	if opts.Charset != "" {
+		targetCollation = target(opts.Collation, opts.Charset+" default collation")
+	}
+	plan.Target = fmt.Sprintf("%s, %s, %s", target(opts.Engine, engine), targetCollation, target(opts.RowFormat, rowFormat))
+
+	for _, name := range []string{plan.Shadow, plan.Old} {
+		var exists int
+		if err := c.DB.QueryRow("SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?",
+			opts.Database, name).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to check for %s: %w", name, err)
+		}
+		if exists > 0 && name == plan.Shadow {
+			return nil, fmt.Errorf("%s.%s already exists, left by an interrupted rebuild; clean it up with ysm rebuild %s.%s --cleanup",
+				opts.Database, name, opts.Database, opts.Table)
+		}
+		if exists > 0 {
+			return nil, fmt.Errorf("%s.%s already exists from an earlier rebuild; drop it or revert first", opts.Database, name)
+		}
+	}
+
+	var triggers, leftover, references int
+	if err := c.DB.QueryRow(`SELECT COUNT(*), COALESCE(SUM(TRIGGER_NAME IN (?, ?, ?)), 0) FROM information_schema.TRIGGERS
+		WHERE EVENT_OBJECT_SCHEMA = ? AND EVENT_OBJECT_TABLE = ?`,
+		rebuildTriggerName(opts.Table, "ins"), rebuildTriggerName(opts.Table, "upd"), rebuildTriggerName(opts.Table, "del"),
+		opts.Database, opts.Table).Scan(&triggers, &leftover); err != nil {
+		return nil, fmt.Errorf("failed to check triggers: %w", err)
+	}
+	if leftover > 0 {
+		return nil, fmt.Errorf("an interrupted rebuild left its triggers on %s; clean it up with ysm rebuild %s.%s --cleanup",
+			opts.Table, opts.Database, opts.Table)
+	}
+	if triggers > 0 {
+		return nil, fmt.Errorf("%s has %d triggers of its own, which would stay with the original table at the swap", opts.Table, triggers)
+	}
+	if err := c.DB.QueryRow(`SELECT COUNT(*) FROM information_schema.REFERENTIAL_CONSTRAINTS
+		WHERE UNIQUE_CONSTRAINT_SCHEMA = ? AND REFERENCED_TABLE_NAME = ?`, opts.Database, opts.Table).Scan(&references); err != nil {
+		return nil, fmt.Errorf("failed to check foreign keys: %w", err)
+	}
+	if references > 0 {
+		return nil, fmt.Errorf("%d foreign keys reference %s; the swap would leave them pointing at the original table", references, opts.Table)
+	}
+
+	if err := c.UseDatabase(opts.Database); err != nil {
+		return nil, err
+	}
+	plan.Key, err = c.PrimaryKey(opts.Table)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Key) == 0 {
+		return nil, fmt.Errorf("%s has no primary key to copy it in chunks by", opts.Table)
+	}
+	columns, err := c.DescribeTable(opts.Table)
+	if err != nil {
+		return nil, err
+	}
+	for _, col := range columns {
+		if !strings.Contains(strings.ToUpper(col.Extra), "GENERATED") {
+			plan.Columns = append(plan.Columns, col.Field)
+		}
+	}
+
+	create, err := c.getCreateTable(opts.Table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the table definition: %w", err)
+	}
+	create, renamed := c.shadowCreateTable(create, opts.Database, opts.Table, plan.Shadow)
+	if renamed > 0 {
+		plan.Notes = append(plan.Notes, fmt.Sprintf(
+			"Foreign key names must be unique, so the %d foreign keys of the rebuilt table gain or lose a leading underscore", renamed))
+	}
+	plan.buildStatements(c, create)
+
+	if opts.DropOld {
+		plan.Notes = append(plan.Notes, "The original table is dropped after the swap; there is no revert")
+	} else {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("The original table is kept as %s for a revert; drop it once you're happy", plan.Old))
+	}
+	plan.Notes = append(plan.Notes,
+		"The table stays readable and writable while rows are copied; the swap briefly waits for a metadata lock",
+		"The shadow table needs about as much disk space as the table itself")
+	return plan, nil
+}
+
+// shadowCreateTable turns a table's CREATE TABLE into the shadow table's,
+// toggling a leading underscore on foreign key names so they don't clash
+func (c *Connection) shadowCreateTable(create, database, table, shadow string) (string, int) {
+	create = strings.Replace(create,
+		"CREATE TABLE "+c.QuoteIdentifier(table),
+		"CREATE TABLE "+c.QuoteIdentifier(database)+"."+c.QuoteIdentifier(shadow), 1)
+
+	renamed := 0
+	create = foreignKeyConstraintPattern.ReplaceAllStringFunc(create, func(match string) string {
+		name := foreignKeyConstraintPattern.FindStringSubmatch(match)[1]
+		renamed++
+		if strings.HasPrefix(name, "_") {
+			return "CONSTRAINT `" + name[1:] + "` FOREIGN KEY"
+		}
+		return "CONSTRAINT `_" + name + "` FOREIGN KEY"
+	})
+	return create, renamed
+}
+
+// buildStatements fills in the plan's SQL from the shadow CREATE TABLE
+func (p *OnlineRebuildPlan) buildStatements(c *Connection, create string) {
+	qualified := func(name string) string {
+		return c.QuoteIdentifier(p.Database) + "." + c.QuoteIdentifier(name)
+	}
+	table, shadow, old := qualified(p.Table), qualified(p.Shadow), qualified(p.Old)
+
+	quoted := make([]string, len(p.Columns))
+	newValues := make([]string, len(p.Columns))
+	for i, col := range p.Columns {
+		quoted[i] = c.QuoteIdentifier(col)
+		newValues[i] = "NEW." + quoted[i]
+	}
+	columns := strings.Join(quoted, ", ")
+
+	// Matches the shadow row of the trigger's OLD row
+	oldRow := make([]string, len(p.Key))
+	keyChanged := make([]string, len(p.Key))
+	for i, col := range p.Key {
+		q := c.QuoteIdentifier(col)
+		oldRow[i] = fmt.Sprintf("%s.%s <=> OLD.%s", shadow, q, q)
+		keyChanged[i] = fmt.Sprintf("NOT (OLD.%s <=> NEW.%s)", q, q)
+	}
+	replace := fmt.Sprintf("REPLACE INTO %s (%s) VALUES (%s)", shadow, columns, strings.Join(newValues, ", "))
+
+	p.Setup = []string{create}
+	var alter []string
+	if p.Engine != "" {
+		alter = append(alter, "ENGINE = "+p.Engine)
+	}
+	if p.Charset != "" {
+		convert := "CONVERT TO CHARACTER SET " + p.Charset
+		if p.Collation != "" {
+			convert += " COLLATE " + p.Collation
+		}
+		alter = append(alter, convert)
+	}
+	if p.RowFormat != "" {
+		alter = append(alter, "ROW_FORMAT = "+p.RowFormat)
+	}
+	if len(alter) > 0 {
+		p.Setup = append(p.Setup, fmt.Sprintf("ALTER TABLE %s %s", shadow, strings.Join(alter, ", ")))
+	}
+
+	triggers := map[string]string{
+		"ins": fmt.Sprintf("AFTER INSERT ON %s FOR EACH ROW %s", table, replace),
+		"upd": fmt.Sprintf("AFTER UPDATE ON %s FOR EACH ROW BEGIN DELETE IGNORE FROM %s WHERE (%s) AND %s; %s; END",
+			table, shadow, strings.Join(keyChanged, " OR "), strings.Join(oldRow, " AND "), replace),
+		"del": fmt.Sprintf("AFTER DELETE ON %s FOR EACH ROW DELETE IGNORE FROM %s WHERE %s", table, shadow, strings.Join(oldRow, " AND ")),
+	}
+	var dropTriggers []string
+	for _, event := range []string{"ins", "upd", "del"} {
+		name := qualified(rebuildTriggerName(p.Table, event))
+		p.Setup = append(p.Setup, fmt.Sprintf("CREATE TRIGGER %s %s", name, triggers[event]))
+		dropTriggers = append(dropTriggers, "DROP TRIGGER "+name)
+	}
+
+	key := c.keyList(p.Key)
+	p.Copy = fmt.Sprintf("INSERT LOW_PRIORITY IGNORE INTO %s (%s) SELECT %s FROM %s FORCE INDEX (PRIMARY) WHERE (%s) > (%s) AND (%s) <= (%s) LOCK IN SHARE MODE",
+		shadow, columns, columns, table, key, placeholders(len(p.Key)), key, placeholders(len(p.Key)))
+	p.Swap = fmt.Sprintf("RENAME TABLE %s TO %s, %s TO %s", table, old, shadow, table)
+	p.Cleanup = dropTriggers
+	if p.DropOld {
+		p.Cleanup = append(p.Cleanup, "DROP TABLE "+old)
+	}
+	p.Rollback = c.onlineRebuildRollback(p.Database, p.Table)
+}
+
+// onlineRebuildRollback returns the statements removing a rebuild's
+// triggers and shadow table
+func (c *Connection) onlineRebuildRollback(database, table string) []string {
+	qualified := func(name string) string {
+		return c.QuoteIdentifier(database) + "." + c.QuoteIdentifier(name)
+	}
+	var statements []string
+	for _, event := range []string{"ins", "upd", "del"} {
+		statements = append(statements, "DROP TRIGGER IF EXISTS "+qualified(rebuildTriggerName(table, event)))
+	}
+	shadow, _ := rebuildTableNames(table)
+	return append(statements, "DROP TABLE IF EXISTS "+qualified(shadow))
+}
+
+// keyList quotes and joins key columns
+func (c *Connection) keyList(key []string) string {
+	quoted := make([]string, len(key))
+	for i, col := range key {
+		quoted[i] = c.QuoteIdentifier(col)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// placeholders returns n comma-separated ? placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// RunOnlineRebuild runs a planned online rebuild. Until the swap, a failure
+// or a cancelled ctx removes the triggers and shadow table, leaving the
+// table as it was.
+func (c *Connection) RunOnlineRebuild(ctx context.Context, plan *OnlineRebuildPlan, onProgress func(OnlineRebuildProgress)) (*OnlineRebuildResult, error) {
+	start := time.Now()
+	report := func(phase string, copied int64) {
+		if onProgress != nil {
+			onProgress(OnlineRebuildProgress{Phase: phase, Copied: copied, Total: plan.EstimatedRows})
+		}
+	}
+	rollback := func(err error, copied int64) error {
+		if rbErr := c.rollbackOnlineRebuild(plan); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed too, run ysm rebuild %s.%s --cleanup: %v)", err, plan.Database, plan.Table, rbErr)
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("rebuild aborted after %d rows; %s is unchanged", copied, plan.Table)
+		}
+		return fmt.Errorf("%w; rolled back, %s is unchanged", err, plan.Table)
+	}
+
+	for _, stmt := range plan.Setup {
+		if _, err := c.DB.Exec(stmt); err != nil {
+			return nil, rollback(fmt.Errorf("failed to prepare the rebuild: %w", err), 0)
+		}
+	}
+
+	report(RebuildPhaseCopy, 0)
+	copied, chunks, err := c.copyRebuildChunks(ctx, plan, func(copied int64) {
+		report(RebuildPhaseCopy, copied)
+	})
+	if err != nil {
+		return nil, rollback(fmt.Errorf("copy failed: %w", err), copied)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, rollback(err, copied)
+	}
+
+	report(RebuildPhaseSwap, copied)
+	if _, err := c.DB.Exec(plan.Swap); err != nil {
+		return nil, rollback(fmt.Errorf("swap failed: %w", err), copied)
+	}
+
+	report(RebuildPhaseCleanup, copied)
+	result := &OnlineRebuildResult{Copied: copied, Chunks: chunks, Old: plan.Old}
+	for _, stmt := range plan.Cleanup {
+		if _, err := c.DB.Exec(stmt); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", stmt, err))
+		} else if strings.HasPrefix(stmt, "DROP TABLE") {
+			result.Old = ""
+		}
+	}
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// copyRebuildChunks copies the table into the shadow table in primary key
+// order, one chunk per statement. Rows the triggers already wrote are
+// skipped by INSERT IGNORE.
+func (c *Connection) copyRebuildChunks(ctx context.Context, plan *OnlineRebuildPlan, progress func(int64)) (int64, int, error) {
+	qualified := func(name string) string {
+		return c.QuoteIdentifier(plan.Database) + "." + c.QuoteIdentifier(name)
+	}
+	table, shadow := qualified(plan.Table), qualified(plan.Shadow)
+	key := c.keyList(plan.Key)
+	quoted := make([]string, len(plan.Columns))
+	for i, col := range plan.Columns {
+		quoted[i] = c.QuoteIdentifier(col)
+	}
+	columns := strings.Join(quoted, ", ")
+	bound := placeholders(len(plan.Key))
+
+	var copied int64
+	var chunks int
+	var lower []interface{} // Key of the last row copied
+	for {
+		if err := ctx.Err(); err != nil {
+			return copied, chunks, err
+		}
+
+		var conds []string
+		args := append([]interface{}(nil), lower...)
+		if lower != nil {
+			conds = append(conds, fmt.Sprintf("(%s) > (%s)", key, bound))
+		}
+
+		// The chunk ends at the key ChunkSize rows on; past the end, the rest is copied
+		where := ""
+		if len(conds) > 0 {
+			where = " WHERE " + strings.Join(conds, " AND ")
+		}
+		upper := make([]interface{}, len(plan.Key))
+		ptrs := make([]interface{}, len(upper))
+		for i := range upper {
+			ptrs[i] = &upper[i]
+		}
+		err := c.DB.QueryRowContext(ctx, fmt.Sprintf("SELECT %s FROM %s FORCE INDEX (PRIMARY)%s ORDER BY %s LIMIT 1 OFFSET %d",
+			key, table, where, key, plan.ChunkSize-1), args...).Scan(ptrs...)
+		last := err == sql.ErrNoRows
+		if err != nil && !last {
+			return copied, chunks, err
+		}
+		if !last {
+			conds = append(conds, fmt.Sprintf("(%s) <= (%s)", key, bound))
+			args = append(args, upper...)
+			where = " WHERE " + strings.Join(conds, " AND ")
+		}
+
+		res, err := c.DB.ExecContext(ctx, fmt.Sprintf("INSERT LOW_PRIORITY IGNORE INTO %s (%s) SELECT %s FROM %s FORCE INDEX (PRIMARY)%s LOCK IN SHARE MODE",
+			shadow, columns, columns, table, where), args...)
+		if err != nil {
+			return copied, chunks, err
+		}
+		n, _ := res.RowsAffected()
+		copied += n
+		chunks++
+		progress(copied)
+
+		if last {
+			return copied, chunks, nil
+		}
+		lower = upper
+	}
+}
+
+// rollbackOnlineRebuild removes the triggers and shadow table of a rebuild
+// that didn't reach the swap
+func (c *Connection) rollbackOnlineRebuild(plan *OnlineRebuildPlan) error {
+	return c.runRebuildRollback(plan.Rollback)
+}
+
+// CleanupOnlineRebuild removes the triggers and shadow table an interrupted
+// rebuild of a table left behind, such as when YSM was killed mid-copy.
+// The original table is untouched.
+func (c *Connection) CleanupOnlineRebuild(database, table string) error {
+	if c.Config.Type != DatabaseTypeMariaDB {
+		return fmt.Errorf("online rebuilds are only supported on MariaDB/MySQL")
+	}
+	if err := c.runRebuildRollback(c.onlineRebuildRollback(database, table)); err != nil {
+		return fmt.Errorf("failed to clean up the rebuild of %s: %w", table, err)
+	}
+	return nil
+}
+
+func (c *Connection) runRebuildRollback(statements []string) error {
+	var firstErr error
+	for _, stmt := range statements {
+		if _, err := c.DB.Exec(stmt); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// RevertOnlineRebuild swaps back the original table an online rebuild kept,
+// leaving the rebuilt one as _<table>_new. Rows written since the rebuild
+// are only in the rebuilt table.
+func (c *Connection) RevertOnlineRebuild(database, table string) error {
+	if c.Config.Type != DatabaseTypeMariaDB {
+		return fmt.Errorf("online rebuilds are only supported on MariaDB/MySQL")
+	}
+	shadow, old := rebuildTableNames(table)
+	var exists int
+	if err := c.DB.QueryRow("SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?",
+		database, old).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check for %s: %w", old, err)
+	}
+	if exists == 0 {
+		return fmt.Errorf("%s.%s not found; only rebuilds that kept the original table can be reverted", database, old)
+	}
+
+	qualified := func(name string) string {
+		return c.QuoteIdentifier(database) + "." + c.QuoteIdentifier(name)
+	}
+	if _, err := c.DB.Exec(fmt.Sprintf("RENAME TABLE %s TO %s, %s TO %s",
+		qualified(table), qualified(shadow), qualified(old), qualified(table))); err != nil {
+		return fmt.Errorf("failed to revert %s: %w", table, err)
+	}
+	return nil
+}
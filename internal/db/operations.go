@@ -93,80 +93,158 @@ type CloneOptions struct {
 	TargetDB     string
 	IncludeData  bool // If false, only clone structure
 	DropIfExists bool // Drop target database if it exists
+	ChunkSize    int  // Rows per copy statement (default DefaultCopyChunkSize)
 	OnProgress   func(table string, tableNum, totalTables int)
+	OnRows       func(CopyProgress) // Called after each copied chunk
 }
 
-// CloneDatabase creates a copy of a database
-func (c *Connection) CloneDatabase(opts CloneOptions) error {
-	// Check if target exists
-	if opts.DropIfExists {
-		c.DB.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", c.QuoteIdentifier(opts.TargetDB)))
+// CopyPlan is what a clone or merge will do, for review first
+type CopyPlan struct {
+	TargetDB     string
+	TargetExists bool // The target is there already
+	DropTarget   bool // Clone: the existing target is dropped first
+	CreateTarget bool // The target is created, and dropped again on failure
+	IncludeData  bool // Rows are copied, not only structure
+	Tables       []CopyPlanTable
+	Rows         int64 // Estimated rows to copy
+}
+
+// CopyPlanTable is one table of a CopyPlan
+type CopyPlanTable struct {
+	SourceDB string
+	Table    string
+	Target   string              // Name in the target; differs for MergeRename
+	Action   MergeConflictAction // What a merge does with it
+	Conflict bool                // A merge found the table in the target already
+	Rows     int64               // Server's estimate
+}
+
+// Copied returns the tables the plan copies, leaving out skipped ones
+func (p *CopyPlan) Copied() []CopyPlanTable {
+	var tables []CopyPlanTable
+	for _, t := range p.Tables {
+		if t.Action != MergeSkip {
+			tables = append(tables, t)
+		}
 	}
+	return tables
+}
 
-	// Create target database
-	_, err := c.DB.Exec(c.Driver.CreateDatabaseQuery(opts.TargetDB))
+// PlanClone lists what cloning opts.SourceDB would copy
+func (c *Connection) PlanClone(opts CloneOptions) (*CopyPlan, error) {
+	exists, err := c.DatabaseExists(opts.TargetDB)
 	if err != nil {
-		return fmt.Errorf("failed to create target database: %w", err)
+		return nil, err
+	}
+	if exists && !opts.DropIfExists {
+		return nil, fmt.Errorf("target database %s already exists", opts.TargetDB)
+	}
+	if exists {
+		if err := c.CheckDatabaseProtected(opts.TargetDB, "drop"); err != nil {
+			return nil, err
+		}
 	}
 
-	// Switch to source database
 	if err := c.UseDatabase(opts.SourceDB); err != nil {
-		return err
+		return nil, err
 	}
-
-	// Get all tables
 	tables, err := c.ListTables()
 	if err != nil {
-		return fmt.Errorf("failed to list tables: %w", err)
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	plan := &CopyPlan{
+		TargetDB:     opts.TargetDB,
+		TargetExists: exists,
+		DropTarget:   exists,
+		CreateTarget: true,
+		IncludeData:  opts.IncludeData,
+	}
+	for _, t := range tables {
+		plan.Tables = append(plan.Tables, CopyPlanTable{
+			SourceDB: opts.SourceDB,
+			Table:    t.Name,
+			Target:   t.Name,
+			Action:   MergeReplace,
+			Rows:     t.Rows,
+		})
+		if opts.IncludeData {
+			plan.Rows += t.Rows
+		}
 	}
+	return plan, nil
+}
 
-	// Clone each table
-	for i, table := range tables {
-		if opts.OnProgress != nil {
-			opts.OnProgress(table.Name, i+1, len(tables))
-		}
+// CloneDatabase creates a copy of a database
+func (c *Connection) CloneDatabase(opts CloneOptions) error {
+	return c.CloneDatabaseContext(context.Background(), opts)
+}
 
-		// Get CREATE TABLE statement
-		createStmt, err := c.getCreateTable(table.Name)
-		if err != nil {
-			return fmt.Errorf("failed to get CREATE TABLE for %s: %w", table.Name, err)
-		}
+// CloneDatabaseContext creates a copy of a database, copying rows in chunks.
+// If it fails or ctx is cancelled after the target was created, the partial
+// target is dropped again.
+func (c *Connection) CloneDatabaseContext(ctx context.Context, opts CloneOptions) error {
+	plan, err := c.PlanClone(opts)
+	if err != nil {
+		return err
+	}
 
-		// Create table in target database
-		if err := c.UseDatabase(opts.TargetDB); err != nil {
-			return fmt.Errorf("failed to switch to target database: %w", err)
+	// Check if target exists
+	if plan.DropTarget {
+		if _, err := c.DB.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", c.QuoteIdentifier(opts.TargetDB))); err != nil {
+			return fmt.Errorf("failed to drop target database: %w", err)
 		}
+	}
 
-		if _, err := c.DB.Exec(createStmt); err != nil {
-			return fmt.Errorf("failed to create table %s: %w", table.Name, err)
-		}
+	// Create target database
+	_, err = c.DB.Exec(c.Driver.CreateDatabaseQuery(opts.TargetDB))
+	if err != nil {
+		return fmt.Errorf("failed to create target database: %w", err)
+	}
 
-		// Copy data if requested
-		if opts.IncludeData {
-			_, err := c.DB.Exec(fmt.Sprintf(
-				"INSERT INTO %s.%s SELECT * FROM %s.%s",
-				c.QuoteIdentifier(opts.TargetDB), c.QuoteIdentifier(table.Name),
-				c.QuoteIdentifier(opts.SourceDB), c.QuoteIdentifier(table.Name),
-			))
-			if err != nil {
-				return fmt.Errorf("failed to copy data for %s: %w", table.Name, err)
-			}
-		}
+	run := &copyRun{conn: c, ctx: ctx, plan: plan, chunkSize: opts.ChunkSize, onRows: opts.OnRows}
+	fail := func(err error) error {
+		return copyAborted(ctx, "clone", err, run.done, c.dropCopyTarget(opts.SourceDB, opts.TargetDB))
+	}
 
-		// Switch back to source for next iteration
-		c.UseDatabase(opts.SourceDB)
+	// Clone each table
+	for i, table := range plan.Tables {
+		if err := ctx.Err(); err != nil {
+			return fail(err)
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(table.Table, i+1, len(plan.Tables))
+		}
+		if err := run.copyTable(table); err != nil {
+			return fail(err)
+		}
 	}
 
+	// Leave the source current, as before the clone
+	c.UseDatabase(opts.SourceDB)
 	return nil
 }
 
+// dropCopyTarget removes the target database of a failed clone or merge and
+// says what happened, for the error message
+func (c *Connection) dropCopyTarget(sourceDB, targetDB string) string {
+	// PostgreSQL can't drop the database it is connected to
+	c.UseDatabase(sourceDB)
+	if _, err := c.DB.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", c.QuoteIdentifier(targetDB))); err != nil {
+		return fmt.Sprintf("dropping the partial %s failed too: %v", targetDB, err)
+	}
+	return fmt.Sprintf("the partial %s was dropped", targetDB)
+}
+
 // MergeOptions configures database merging
 type MergeOptions struct {
 	SourceDBs       []string // Databases to merge from
 	TargetDB        string   // Database to merge into
 	CreateTarget    bool     // Create target if it doesn't exist
+	ChunkSize       int      // Rows per copy statement (default DefaultCopyChunkSize)
 	ConflictHandler func(table string, sourceDB string) MergeConflictAction
 	OnProgress      func(sourceDB, table string, sourceNum, totalSources int)
+	OnRows          func(CopyProgress) // Called after each copied chunk
 }
 
 // MergeConflictAction defines how to handle merge conflicts
@@ -179,135 +257,225 @@ const (
 	MergeRename                              // Rename source table (add suffix)
 )
 
-// MergeDatabases merges multiple databases into one
-func (c *Connection) MergeDatabases(opts MergeOptions) error {
-	// Create target if needed
-	if opts.CreateTarget {
-		c.DB.Exec(c.Driver.CreateDatabaseQuery(opts.TargetDB))
-	}
+// String returns the action as the merge command's --conflict names it
+func (a MergeConflictAction) String() string {
+	switch a {
+	case MergeSkip:
+		return "skip"
+	case MergeReplace:
+		return "replace"
+	case MergeAppend:
+		return "append"
+	case MergeRename:
+		return "rename"
+	}
+	return strconv.Itoa(int(a))
+}
 
-	// Get existing tables in target
-	if err := c.UseDatabase(opts.TargetDB); err != nil {
-		return fmt.Errorf("failed to switch to target database: %w", err)
+// PlanMerge lists what merging opts.SourceDBs would do to each table,
+// asking opts.ConflictHandler about tables the target already has
+func (c *Connection) PlanMerge(opts MergeOptions) (*CopyPlan, error) {
+	// Merging replaces tables in the target
+	if err := c.CheckDatabaseProtected(opts.TargetDB, "merge into"); err != nil {
+		return nil, err
 	}
-	existingTables, err := c.ListTables()
+
+	exists, err := c.DatabaseExists(opts.TargetDB)
 	if err != nil {
-		return fmt.Errorf("failed to list target tables: %w", err)
+		return nil, err
+	}
+	if !exists && !opts.CreateTarget {
+		return nil, fmt.Errorf("target database %s doesn't exist", opts.TargetDB)
 	}
+	plan := &CopyPlan{TargetDB: opts.TargetDB, TargetExists: exists, CreateTarget: !exists, IncludeData: true}
+
+	// Get existing tables in target
 	existingTableMap := make(map[string]bool)
-	for _, t := range existingTables {
-		existingTableMap[t.Name] = true
+	if exists {
+		if err := c.UseDatabase(opts.TargetDB); err != nil {
+			return nil, fmt.Errorf("failed to switch to target database: %w", err)
+		}
+		existingTables, err := c.ListTables()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list target tables: %w", err)
+		}
+		for _, t := range existingTables {
+			existingTableMap[t.Name] = true
+		}
 	}
 
-	// Process each source database
-	for sourceNum, sourceDB := range opts.SourceDBs {
+	for _, sourceDB := range opts.SourceDBs {
 		if err := c.UseDatabase(sourceDB); err != nil {
-			return fmt.Errorf("failed to switch to source database %s: %w", sourceDB, err)
+			return nil, fmt.Errorf("failed to switch to source database %s: %w", sourceDB, err)
 		}
-
 		tables, err := c.ListTables()
 		if err != nil {
-			return fmt.Errorf("failed to list tables in %s: %w", sourceDB, err)
+			return nil, fmt.Errorf("failed to list tables in %s: %w", sourceDB, err)
 		}
 
 		for _, table := range tables {
-			if opts.OnProgress != nil {
-				opts.OnProgress(sourceDB, table.Name, sourceNum+1, len(opts.SourceDBs))
-			}
-
-			tableName := table.Name
-			action := MergeAppend // Default action
+			entry := CopyPlanTable{SourceDB: sourceDB, Table: table.Name, Target: table.Name, Rows: table.Rows}
 
 			// Check for conflicts
-			if existingTableMap[tableName] {
+			entry.Action = MergeReplace // No conflict, just copy
+			if existingTableMap[table.Name] {
+				entry.Conflict = true
+				entry.Action = MergeAppend // Default action
 				if opts.ConflictHandler != nil {
-					action = opts.ConflictHandler(tableName, sourceDB)
+					entry.Action = opts.ConflictHandler(table.Name, sourceDB)
 				}
-			} else {
-				action = MergeReplace // No conflict, just copy
+			}
+			if entry.Action == MergeRename {
+				entry.Target = fmt.Sprintf("%s_%s", table.Name, sourceDB)
 			}
 
-			switch action {
-			case MergeSkip:
-				continue
+			if entry.Action != MergeSkip {
+				existingTableMap[entry.Target] = true
+				plan.Rows += table.Rows
+			}
+			plan.Tables = append(plan.Tables, entry)
+		}
+	}
+	return plan, nil
+}
 
-			case MergeReplace:
-				// Drop existing and copy
-				c.DB.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s.%s",
-					c.QuoteIdentifier(opts.TargetDB), c.QuoteIdentifier(tableName)))
+// MergeDatabases merges multiple databases into one
+func (c *Connection) MergeDatabases(opts MergeOptions) error {
+	return c.MergeDatabasesContext(context.Background(), opts)
+}
 
-				createStmt, err := c.getCreateTable(tableName)
-				if err != nil {
-					return fmt.Errorf("failed to get CREATE TABLE for %s: %w", tableName, err)
-				}
+// MergeDatabasesContext merges multiple databases into one, copying rows in
+// chunks. If it fails or ctx is cancelled, a target the merge created is
+// dropped again; in an existing target, a table the merge was creating is.
+// Rows already appended to existing tables stay.
+func (c *Connection) MergeDatabasesContext(ctx context.Context, opts MergeOptions) error {
+	plan, err := c.PlanMerge(opts)
+	if err != nil {
+		return err
+	}
 
-				if err := c.UseDatabase(opts.TargetDB); err != nil {
-					return err
-				}
-				if _, err := c.DB.Exec(createStmt); err != nil {
-					return fmt.Errorf("failed to create table %s: %w", tableName, err)
-				}
+	// Create target if needed
+	if plan.CreateTarget {
+		if _, err := c.DB.Exec(c.Driver.CreateDatabaseQuery(opts.TargetDB)); err != nil {
+			return fmt.Errorf("failed to create target database: %w", err)
+		}
+	}
 
-				_, err = c.DB.Exec(fmt.Sprintf(
-					"INSERT INTO %s.%s SELECT * FROM %s.%s",
-					c.QuoteIdentifier(opts.TargetDB), c.QuoteIdentifier(tableName),
-					c.QuoteIdentifier(sourceDB), c.QuoteIdentifier(tableName),
-				))
-				if err != nil {
-					return fmt.Errorf("failed to copy data for %s: %w", tableName, err)
-				}
+	run := &copyRun{conn: c, ctx: ctx, plan: plan, chunkSize: opts.ChunkSize, onRows: opts.OnRows}
+	sourceNum := make(map[string]int, len(opts.SourceDBs))
+	for i, sourceDB := range opts.SourceDBs {
+		sourceNum[sourceDB] = i + 1
+	}
 
-				existingTableMap[tableName] = true
-
-			case MergeAppend:
-				// Just append data (assumes compatible schema)
-				_, err := c.DB.Exec(fmt.Sprintf(
-					"INSERT INTO %s.%s SELECT * FROM %s.%s",
-					c.QuoteIdentifier(opts.TargetDB), c.QuoteIdentifier(tableName),
-					c.QuoteIdentifier(sourceDB), c.QuoteIdentifier(tableName),
-				))
-				if err != nil {
-					return fmt.Errorf("failed to append data for %s: %w", tableName, err)
-				}
+	for _, table := range plan.Tables {
+		if err := ctx.Err(); err != nil {
+			return c.abortMerge(ctx, run, err, table)
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(table.SourceDB, table.Table, sourceNum[table.SourceDB], len(opts.SourceDBs))
+		}
+		if table.Action == MergeSkip {
+			continue
+		}
+		if err := run.copyTable(table); err != nil {
+			return c.abortMerge(ctx, run, err, table)
+		}
+	}
 
-			case MergeRename:
-				// Copy with new name
-				newName := fmt.Sprintf("%s_%s", tableName, sourceDB)
+	// Switch back to source
+	if len(opts.SourceDBs) > 0 {
+		c.UseDatabase(opts.SourceDBs[len(opts.SourceDBs)-1])
+	}
+	return nil
+}
 
-				createStmt, err := c.getCreateTable(tableName)
-				if err != nil {
-					return fmt.Errorf("failed to get CREATE TABLE for %s: %w", tableName, err)
-				}
+// abortMerge cleans up after a merge failed or was cancelled while on table
+func (c *Connection) abortMerge(ctx context.Context, run *copyRun, err error, table CopyPlanTable) error {
+	if run.plan.CreateTarget {
+		return copyAborted(ctx, "merge", err, run.done, c.dropCopyTarget(table.SourceDB, run.plan.TargetDB))
+	}
+	if !run.creating {
+		return copyAborted(ctx, "merge", err, run.done, fmt.Sprintf("tables merged before %s are kept", table.Target))
+	}
+	if _, dropErr := c.DB.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s.%s",
+		c.QuoteIdentifier(run.plan.TargetDB), c.QuoteIdentifier(table.Target))); dropErr != nil {
+		return copyAborted(ctx, "merge", err, run.done, fmt.Sprintf("dropping the partial %s failed too: %v", table.Target, dropErr))
+	}
+	return copyAborted(ctx, "merge", err, run.done, fmt.Sprintf("the partial %s was dropped; tables merged before it are kept", table.Target))
+}
 
-				// Replace table name in CREATE statement
-				createStmt = strings.Replace(createStmt,
-					fmt.Sprintf("CREATE TABLE %s", c.QuoteIdentifier(tableName)),
-					fmt.Sprintf("CREATE TABLE %s", c.QuoteIdentifier(newName)), 1)
+// copyRun carries the state of a clone or merge across its tables
+type copyRun struct {
+	conn      *Connection
+	ctx       context.Context
+	plan      *CopyPlan
+	chunkSize int
+	onRows    func(CopyProgress)
+	done      int64 // Rows copied so far, all tables
+	creating  bool  // The current table was created by this run
+}
 
-				if err := c.UseDatabase(opts.TargetDB); err != nil {
-					return err
-				}
-				if _, err := c.DB.Exec(createStmt); err != nil {
-					return fmt.Errorf("failed to create renamed table %s: %w", newName, err)
-				}
+// copyTable creates (unless appending) and fills one table of the plan
+func (r *copyRun) copyTable(table CopyPlanTable) error {
+	c := r.conn
+	r.creating = false
 
-				_, err = c.DB.Exec(fmt.Sprintf(
-					"INSERT INTO %s.%s SELECT * FROM %s.%s",
-					c.QuoteIdentifier(opts.TargetDB), c.QuoteIdentifier(newName),
-					c.QuoteIdentifier(sourceDB), c.QuoteIdentifier(tableName),
-				))
-				if err != nil {
-					return fmt.Errorf("failed to copy data for %s: %w", newName, err)
-				}
+	if err := c.UseDatabase(table.SourceDB); err != nil {
+		return fmt.Errorf("failed to switch to source database %s: %w", table.SourceDB, err)
+	}
+	var pk []string
+	if r.plan.IncludeData {
+		var err error
+		if pk, err = c.PrimaryKey(table.Table); err != nil {
+			return err
+		}
+	}
 
-				existingTableMap[newName] = true
-			}
+	if table.Action != MergeAppend {
+		// Get CREATE TABLE statement
+		createStmt, err := c.getCreateTable(table.Table)
+		if err != nil {
+			return fmt.Errorf("failed to get CREATE TABLE for %s: %w", table.Table, err)
+		}
+		if table.Target != table.Table {
+			createStmt = c.renamedCreateTable(createStmt, table.Table, table.Target)
+		}
 
-			// Switch back to source
-			c.UseDatabase(sourceDB)
+		// Create table in target database
+		if err := c.UseDatabase(r.plan.TargetDB); err != nil {
+			return fmt.Errorf("failed to switch to target database: %w", err)
+		}
+		if table.Action == MergeReplace && table.Conflict {
+			// Drop existing and copy
+			c.DB.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s.%s",
+				c.QuoteIdentifier(r.plan.TargetDB), c.QuoteIdentifier(table.Target)))
+		}
+		r.creating = true
+		if _, err := c.DB.Exec(createStmt); err != nil {
+			return fmt.Errorf("failed to create table %s: %w", table.Target, err)
 		}
 	}
 
+	if !r.plan.IncludeData {
+		return nil
+	}
+	start := r.done
+	report := func(copied int64) {
+		r.done = start + copied
+		if r.onRows != nil {
+			r.onRows(CopyProgress{
+				SourceDB: table.SourceDB,
+				Table:    table.Target,
+				Copied:   copied,
+				Done:     r.done,
+				Total:    r.plan.Rows,
+			})
+		}
+	}
+	report(0)
+	if _, err := c.copyTableRows(r.ctx, table.SourceDB, table.Table, r.plan.TargetDB, table.Target, pk, r.chunkSize, report); err != nil {
+		return fmt.Errorf("failed to copy data for %s: %w", table.Target, err)
+	}
 	return nil
 }
 
@@ -352,6 +520,9 @@ func (c *Connection) CopyTable(opts CopyTableOptions) error {
 
 	// Create target table
 	if opts.DropIfExists {
+		if err := c.CheckDatabaseProtected(opts.TargetDB, "drop tables in"); err != nil {
+			return err
+		}
 		c.DB.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s.%s",
 			c.QuoteIdentifier(opts.TargetDB), c.QuoteIdentifier(opts.TargetTable)))
 	}
@@ -510,6 +681,17 @@ const (
 	SyncFull                          // Sync both structure and data
 )
 
+// String returns a label for the mode
+func (m SyncMode) String() string {
+	switch m {
+	case SyncStructureOnly:
+		return "Structure only"
+	case SyncDataOnly:
+		return "Data only"
+	}
+	return "Structure and data"
+}
+
 // SyncResult contains synchronization results
 type SyncResult struct {
 	TablesCreated  []string
@@ -0,0 +1,137 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NullParam is the value typed in a parameter form to bind SQL NULL
+const NullParam = `\N`
+
+// CountPlaceholders returns how many bind parameters a query expects.
+// MariaDB uses positional ? markers; PostgreSQL uses numbered $1..$n markers,
+// in which case the highest number is returned. Markers inside string
+// literals, quoted identifiers and comments are ignored.
+func CountPlaceholders(query string, dbType DatabaseType) int {
+	count := 0
+	maxNum := 0
+
+	for i := 0; i < len(query); i++ {
+		ch := query[i]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			// Skip quoted section, honoring doubled quotes and backslash escapes
+			for i++; i < len(query); i++ {
+				if query[i] == '\\' && ch == '\'' && dbType != DatabaseTypePostgres {
+					i++
+					continue
+				}
+				if query[i] == ch {
+					if i+1 < len(query) && query[i+1] == ch {
+						i++
+						continue
+					}
+					break
+				}
+			}
+		case ch == '-' && i+1 < len(query) && query[i+1] == '-',
+			ch == '#' && dbType != DatabaseTypePostgres:
+			// Line comment
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case ch == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return placeholderResult(dbType, count, maxNum)
+			}
+			i += end + 3
+		case ch == '?' && dbType != DatabaseTypePostgres:
+			count++
+		case ch == '$' && dbType == DatabaseTypePostgres:
+			j := i + 1
+			num := 0
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				num = num*10 + int(query[j]-'0')
+				j++
+			}
+			if j > i+1 {
+				if num > maxNum {
+					maxNum = num
+				}
+				i = j - 1
+				continue
+			}
+			// Dollar-quoted string ($$...$$ or $tag$...$tag$)
+			k := strings.IndexByte(query[i+1:], '$')
+			if k < 0 {
+				continue
+			}
+			tag := query[i : i+k+2]
+			if strings.ContainsAny(tag[1:len(tag)-1], " \t\n;()") {
+				continue
+			}
+			end := strings.Index(query[i+len(tag):], tag)
+			if end < 0 {
+				return placeholderResult(dbType, count, maxNum)
+			}
+			i += len(tag) + end + len(tag) - 1
+		}
+	}
+
+	return placeholderResult(dbType, count, maxNum)
+}
+
+func placeholderResult(dbType DatabaseType, count, maxNum int) int {
+	if dbType == DatabaseTypePostgres {
+		return maxNum
+	}
+	return count
+}
+
+// ParamLabel returns the display label for the n-th (0-based) parameter
+func ParamLabel(n int, dbType DatabaseType) string {
+	if dbType == DatabaseTypePostgres {
+		return "$" + strconv.Itoa(n+1)
+	}
+	return "?" + strconv.Itoa(n+1)
+}
+
+// Placeholder returns the bind marker for the n-th (0-based) parameter
+func Placeholder(n int, dbType DatabaseType) string {
+	if dbType == DatabaseTypePostgres {
+		return "$" + strconv.Itoa(n+1)
+	}
+	return "?"
+}
+
+// BindArgs converts form values into query args, mapping NullParam to NULL
+func BindArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		if v == NullParam {
+			args[i] = nil
+		} else {
+			args[i] = v
+		}
+	}
+	return args
+}
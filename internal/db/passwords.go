@@ -0,0 +1,313 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// Password charsets, named so they can be picked in the config
+const (
+	PasswordCharsetFull  = "full"  // Letters, digits and symbols
+	PasswordCharsetAlnum = "alnum" // Letters and digits
+	PasswordCharsetHex   = "hex"   // Lowercase hex digits
+)
+
+// PasswordCharsets lists every password charset
+var PasswordCharsets = []string{PasswordCharsetFull, PasswordCharsetAlnum, PasswordCharsetHex}
+
+// Password generator defaults and limits
+const (
+	DefaultPasswordLength = 24
+	MinPasswordLength     = 8
+	MaxPasswordLength     = 128
+)
+
+// Character classes of generated passwords. The symbols leave out quotes,
+// backslashes, backticks, $ and spaces, which need escaping in shells,
+// connection strings and config files.
+const (
+	passwordLower   = "abcdefghijklmnopqrstuvwxyz"
+	passwordUpper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	passwordDigits  = "0123456789"
+	passwordSymbols = "!#%*+-.:=?@^_~"
+	passwordHex     = "0123456789abcdef"
+)
+
+// PasswordPolicy decides what generated passwords look like
+type PasswordPolicy struct {
+	Length  int
+	Charset string // One of PasswordCharsets
+	Exclude string // Characters never used, e.g. easily confused ones like l1O0
+}
+
+// DefaultPasswordPolicy returns the policy used without a passwords config:
+// 24 letters, digits and symbols
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{Length: DefaultPasswordLength, Charset: PasswordCharsetFull}
+}
+
+// classes returns the character classes of the policy's charset, with the
+// excluded characters taken out
+func (p PasswordPolicy) classes() ([]string, error) {
+	var classes []string
+	switch p.Charset {
+	case PasswordCharsetFull, "":
+		classes = []string{passwordLower, passwordUpper, passwordDigits, passwordSymbols}
+	case PasswordCharsetAlnum:
+		classes = []string{passwordLower, passwordUpper, passwordDigits}
+	case PasswordCharsetHex:
+		classes = []string{passwordHex}
+	default:
+		return nil, fmt.Errorf("unknown password charset %q (use %s)", p.Charset, strings.Join(PasswordCharsets, ", "))
+	}
+
+	var kept []string
+	for _, class := range classes {
+		class = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(p.Exclude, r) {
+				return -1
+			}
+			return r
+		}, class)
+		if class != "" {
+			kept = append(kept, class)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("no characters left to generate passwords from")
+	}
+	return kept, nil
+}
+
+// GeneratePassword returns a random password following the policy, with at
+// least one character of each of the charset's classes
+func GeneratePassword(policy PasswordPolicy) (string, error) {
+	length := policy.Length
+	if length == 0 {
+		length = DefaultPasswordLength
+	}
+	if length < MinPasswordLength || length > MaxPasswordLength {
+		return "", fmt.Errorf("password length must be between %d and %d", MinPasswordLength, MaxPasswordLength)
+	}
+	classes, err := policy.classes()
+	if err != nil {
+		return "", err
+	}
+
+	// One character from each class, the rest from all of them, shuffled
+	// so the guaranteed ones don't always come first
+	all := strings.Join(classes, "")
+	password := make([]byte, length)
+	for i := range password {
+		from := all
+		if i < len(classes) {
+			from = classes[i]
+		}
+		n, err := randomIndex(len(from))
+		if err != nil {
+			return "", err
+		}
+		password[i] = from[n]
+	}
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomIndex(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+// randomIndex returns a uniformly random number in [0, n)
+func randomIndex(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate password: %w", err)
+	}
+	return int(v.Int64()), nil
+}
+
+// Password strength scores
+const (
+	PasswordVeryWeak = iota
+	PasswordWeak
+	PasswordFair
+	PasswordStrong
+	PasswordVeryStrong
+)
+
+// passwordScoreLabels are the names of the strength scores
+var passwordScoreLabels = []string{"very weak", "weak", "fair", "strong", "very strong"}
+
+// commonPasswords are words that make up many leaked passwords. A password
+// built around one of them is cracked from a word list, not by brute force.
+var commonPasswords = []string{
+	"password", "passw0rd", "qwerty", "azerty", "letmein", "welcome", "admin",
+	"root", "secret", "changeme", "default", "master", "dragon", "monkey",
+	"iloveyou", "trustno1", "sunshine", "football", "baseball", "login",
+	"abc123", "123456", "111111", "000000", "mysql", "mariadb", "postgres",
+}
+
+// PasswordStrength is an estimate of how hard a password is to guess
+type PasswordStrength struct {
+	Score    int      // PasswordVeryWeak to PasswordVeryStrong
+	Bits     float64  // Estimated entropy
+	Warnings []string // What makes it weaker, e.g. being short
+}
+
+// Label names the strength score, e.g. "fair"
+func (s PasswordStrength) Label() string {
+	return passwordScoreLabels[s.Score]
+}
+
+// Weak reports whether the password should be confirmed before it is used
+func (s PasswordStrength) Weak() bool {
+	return s.Score < PasswordFair
+}
+
+// CheckPasswordStrength estimates the strength of a password for a user.
+// The estimate starts from the length and the kinds of characters used, and
+// drops for the user name, common words, repeats and keyboard runs.
+func CheckPasswordStrength(password, username string) PasswordStrength {
+	var s PasswordStrength
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return s
+	}
+
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r > unicode.MaxASCII:
+			other = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	pool, kinds := 0, 0
+	for _, c := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if c.used {
+			pool += c.size
+			kinds++
+		}
+	}
+	s.Bits = float64(len(runes)) * math.Log2(float64(pool))
+
+	if len(runes) < 12 {
+		s.Warnings = append(s.Warnings, "shorter than 12 characters")
+	}
+	if kinds < 3 && len(runes) < 20 {
+		s.Warnings = append(s.Warnings, "mix upper and lower case letters, digits and symbols")
+	}
+
+	lowered := strings.ToLower(password)
+	if username != "" && len(username) >= 3 && strings.Contains(lowered, strings.ToLower(username)) {
+		s.Warnings = append(s.Warnings, "contains the user name")
+		s.Bits /= 2
+	}
+	for _, word := range commonPasswords {
+		if strings.Contains(lowered, word) {
+			s.Warnings = append(s.Warnings, fmt.Sprintf("contains the common password %q", word))
+			s.Bits = min(s.Bits, 20+float64(len(runes)-len(word))*math.Log2(float64(pool)))
+			break
+		}
+	}
+
+	unique := map[rune]bool{}
+	for _, r := range runes {
+		unique[r] = true
+	}
+	if len(unique)*3 < len(runes) {
+		s.Warnings = append(s.Warnings, "repeats the same characters")
+		s.Bits *= float64(len(unique)*3) / float64(len(runes))
+	}
+	if run := longestSequence(lowered); run >= 4 {
+		s.Warnings = append(s.Warnings, "contains a run like abcd or 1234")
+		s.Bits -= float64(run-1) * math.Log2(float64(pool))
+	}
+	s.Bits = max(s.Bits, 0)
+
+	switch {
+	case s.Bits < 28:
+		s.Score = PasswordVeryWeak
+	case s.Bits < 40:
+		s.Score = PasswordWeak
+	case s.Bits < 64:
+		s.Score = PasswordFair
+	case s.Bits < 96:
+		s.Score = PasswordStrong
+	default:
+		s.Score = PasswordVeryStrong
+	}
+	if len(runes) < 10 {
+		// Too short to outlast an offline attack, however mixed
+		s.Score = min(s.Score, PasswordWeak)
+	}
+	return s
+}
+
+// keyboardRows are typed in sequence as often as the alphabet is
+var keyboardRows = []string{"qwertyuiop", "asdfghjkl", "zxcvbnm", "1234567890"}
+
+// longestSequence returns the length of the longest run of characters that
+// follow each other in the alphabet, in digits or on a keyboard row, either way
+func longestSequence(s string) int {
+	longest, run := 1, 1
+	for i := 1; i < len(s); i++ {
+		if follows(s[i-1], s[i]) {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 1
+		}
+	}
+	return longest
+}
+
+// follows reports whether b comes right before or after a
+func follows(a, b byte) bool {
+	if a+1 == b || b+1 == a {
+		letters := unicode.IsLetter(rune(a)) && unicode.IsLetter(rune(b))
+		digits := unicode.IsDigit(rune(a)) && unicode.IsDigit(rune(b))
+		return letters || digits
+	}
+	for _, row := range keyboardRows {
+		i := strings.IndexByte(row, a)
+		if i >= 0 && (i+1 < len(row) && row[i+1] == b || i > 0 && row[i-1] == b) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,374 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Privilege audit export formats
+const (
+	AuditFormatCSV  = "csv"
+	AuditFormatJSON = "json"
+)
+
+// PrivilegeEntry is what one user may do on one object. Database and Object
+// are "*" for server-wide and database-wide privileges.
+type PrivilegeEntry struct {
+	User        string   `json:"user"`
+	Host        string   `json:"host,omitempty"` // Empty for PostgreSQL
+	Database    string   `json:"database"`
+	Object      string   `json:"object"` // Table, table.column or "schema <name>"
+	Privileges  []string `json:"privileges"`
+	GrantOption bool     `json:"grant_option"`
+}
+
+// PrivilegeFinding is a grant worth a second look
+type PrivilegeFinding struct {
+	User      string `json:"user"`
+	Host      string `json:"host,omitempty"`
+	Database  string `json:"database"`
+	Object    string `json:"object"`
+	Privilege string `json:"privilege"`
+	Reason    string `json:"reason"`
+}
+
+// PrivilegeAudit is every user's effective privileges and the dangerous ones
+// among them
+type PrivilegeAudit struct {
+	Server   string             `json:"server"`
+	Time     time.Time          `json:"time"`
+	Entries  []PrivilegeEntry   `json:"entries"`
+	Findings []PrivilegeFinding `json:"findings"`
+}
+
+// auditGrant is one privilege as read from the server
+type auditGrant struct {
+	user, host, database, object, privilege string
+	grantable                               bool
+}
+
+// AuditPrivileges lists the privileges of every user per database and flags
+// dangerous ones: server administration (SUPER, FILE, superuser...), GRANT
+// OPTION, writes to the grant tables and anything PUBLIC may write. Table
+// privileges on PostgreSQL are read from the connected database only.
+func (c *Connection) AuditPrivileges() (*PrivilegeAudit, error) {
+	var grants []auditGrant
+	var err error
+	if isPostgresType(c.Config.Type) {
+		grants, err = c.postgresAuditGrants()
+	} else {
+		grants, err = c.mariadbAuditGrants()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to audit privileges: %w", err)
+	}
+
+	audit := &PrivilegeAudit{
+		Server: fmt.Sprintf("%s:%d", c.Config.Host, c.Config.Port),
+		Time:   time.Now(),
+	}
+	if c.Config.Socket != "" {
+		audit.Server = c.Config.Socket
+	}
+
+	byKey := make(map[string]*PrivilegeEntry)
+	var keys []string
+	for _, g := range grants {
+		key := strings.Join([]string{g.user, g.host, g.database, g.object}, "\x00")
+		entry, ok := byKey[key]
+		if !ok {
+			entry = &PrivilegeEntry{User: g.user, Host: g.host, Database: g.database, Object: g.object}
+			byKey[key] = entry
+			keys = append(keys, key)
+		}
+		if !containsString(entry.Privileges, g.privilege) {
+			entry.Privileges = append(entry.Privileges, g.privilege)
+		}
+		entry.GrantOption = entry.GrantOption || g.grantable
+
+		if reason := c.dangerousGrant(g); reason != "" {
+			audit.Findings = append(audit.Findings, PrivilegeFinding{
+				User: g.user, Host: g.host, Database: g.database, Object: g.object,
+				Privilege: g.privilege, Reason: reason,
+			})
+		}
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		entry := byKey[key]
+		sort.Strings(entry.Privileges)
+		if entry.GrantOption {
+			audit.Findings = append(audit.Findings, PrivilegeFinding{
+				User: entry.User, Host: entry.Host, Database: entry.Database, Object: entry.Object,
+				Privilege: "GRANT OPTION", Reason: "can pass its privileges on to other users",
+			})
+		}
+		audit.Entries = append(audit.Entries, *entry)
+	}
+
+	sort.SliceStable(audit.Findings, func(i, j int) bool {
+		a, b := audit.Findings[i], audit.Findings[j]
+		if a.User != b.User {
+			return a.User < b.User
+		}
+		return a.Host < b.Host
+	})
+
+	return audit, nil
+}
+
+// mariadbAuditGrants reads the privileges shown in information_schema,
+// where ALL PRIVILEGES is already expanded
+func (c *Connection) mariadbAuditGrants() ([]auditGrant, error) {
+	rows, err := c.DB.Query(`SELECT GRANTEE, '*', '*', PRIVILEGE_TYPE, IS_GRANTABLE
+		FROM information_schema.USER_PRIVILEGES
+	UNION ALL
+	SELECT GRANTEE, TABLE_SCHEMA, '*', PRIVILEGE_TYPE, IS_GRANTABLE
+		FROM information_schema.SCHEMA_PRIVILEGES
+	UNION ALL
+	SELECT GRANTEE, TABLE_SCHEMA, TABLE_NAME, PRIVILEGE_TYPE, IS_GRANTABLE
+		FROM information_schema.TABLE_PRIVILEGES
+	UNION ALL
+	SELECT GRANTEE, TABLE_SCHEMA, CONCAT(TABLE_NAME, '.', COLUMN_NAME), PRIVILEGE_TYPE, IS_GRANTABLE
+		FROM information_schema.COLUMN_PRIVILEGES`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []auditGrant
+	for rows.Next() {
+		var grantee, grantable string
+		var g auditGrant
+		if err := rows.Scan(&grantee, &g.database, &g.object, &g.privilege, &grantable); err != nil {
+			return nil, err
+		}
+		if g.privilege == "USAGE" {
+			continue
+		}
+		g.user, g.host = splitGrantee(grantee)
+		g.grantable = grantable == "YES"
+		grants = append(grants, g)
+	}
+	return grants, rows.Err()
+}
+
+// splitGrantee splits a MariaDB grantee such as 'app'@'%' into user and host
+func splitGrantee(grantee string) (string, string) {
+	user, host, ok := strings.Cut(grantee, "'@'")
+	if !ok {
+		return strings.Trim(grantee, "'"), ""
+	}
+	return strings.TrimPrefix(user, "'"), strings.TrimSuffix(host, "'")
+}
+
+// postgresAuditGrants reads role attributes, server role memberships,
+// database privileges on every database, and schema and table privileges in
+// the connected database. PUBLIC grants appear under the user PUBLIC.
+func (c *Connection) postgresAuditGrants() ([]auditGrant, error) {
+	queries := []string{
+		// Role attributes
+		`SELECT rolname, '*', '*', attr, false
+		FROM pg_roles
+		CROSS JOIN LATERAL (VALUES
+			('SUPERUSER', rolsuper), ('CREATEROLE', rolcreaterole),
+			('CREATEDB', rolcreatedb), ('REPLICATION', rolreplication),
+			('BYPASSRLS', rolbypassrls)) a(attr, granted)
+		WHERE granted AND rolname !~ '^pg_'`,
+		// Predefined roles that reach the server itself
+		`SELECT u.rolname, '*', '*', r.rolname, m.admin_option
+		FROM pg_auth_members m
+		JOIN pg_roles r ON r.oid = m.roleid
+		JOIN pg_roles u ON u.oid = m.member
+		WHERE r.rolname ~ '^pg_' AND u.rolname !~ '^pg_'`,
+		// Database privileges, including those every role gets from PUBLIC
+		`SELECT r.rolname, d.datname, '*', p.priv, false
+		FROM pg_roles r
+		CROSS JOIN pg_database d
+		CROSS JOIN (VALUES ('CREATE'), ('CONNECT'), ('TEMPORARY')) p(priv)
+		WHERE r.rolname !~ '^pg_' AND NOT d.datistemplate
+		AND has_database_privilege(r.oid, d.oid, p.priv)`,
+		// Schema privileges other than the owner's
+		`SELECT COALESCE(r.rolname, 'PUBLIC'), current_database(), 'schema ' || n.nspname,
+			a.privilege_type, a.is_grantable
+		FROM pg_namespace n
+		CROSS JOIN LATERAL aclexplode(n.nspacl) a
+		LEFT JOIN pg_roles r ON r.oid = a.grantee
+		WHERE a.grantee <> n.nspowner
+		AND n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname !~ '^pg_'`,
+		// Table privileges other than the owner's
+		`SELECT COALESCE(r.rolname, 'PUBLIC'), current_database(), n.nspname || '.' || c.relname,
+			a.privilege_type, a.is_grantable
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		CROSS JOIN LATERAL aclexplode(c.relacl) a
+		LEFT JOIN pg_roles r ON r.oid = a.grantee
+		WHERE c.relkind IN ('r', 'v', 'm', 'p', 'f') AND a.grantee <> c.relowner
+		AND n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname !~ '^pg_'`,
+	}
+
+	var grants []auditGrant
+	for _, query := range queries {
+		rows, err := c.DB.Query(query)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var g auditGrant
+			if err := rows.Scan(&g.user, &g.database, &g.object, &g.privilege, &g.grantable); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			grants = append(grants, g)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return grants, nil
+}
+
+// Server-wide privileges that reach beyond the data
+var (
+	mariadbDangerousPrivileges = map[string]string{
+		"SUPER":       "can change global settings, kill sessions and write while read_only",
+		"FILE":        "can read and write files on the database server",
+		"SHUTDOWN":    "can stop the server",
+		"CREATE USER": "can create, rename and drop any account",
+		"PROCESS":     "can see every session's queries",
+	}
+	postgresDangerousPrivileges = map[string]string{
+		"SUPERUSER":                 "bypasses every permission check",
+		"CREATEROLE":                "can create roles and manage the roles it has admin on",
+		"BYPASSRLS":                 "ignores row level security policies",
+		"pg_read_server_files":      "can read files on the database server",
+		"pg_write_server_files":     "can write files on the database server",
+		"pg_execute_server_program": "can run programs on the database server",
+	}
+)
+
+// dangerousGrant returns why a single grant is risky, or "" if it isn't
+func (c *Connection) dangerousGrant(g auditGrant) string {
+	if isPostgresType(c.Config.Type) {
+		if g.database == "*" {
+			return postgresDangerousPrivileges[g.privilege]
+		}
+		if g.user == "PUBLIC" {
+			if strings.HasPrefix(g.object, "schema ") && g.privilege == "CREATE" {
+				return "every role can create objects in this schema"
+			}
+			switch g.privilege {
+			case "INSERT", "UPDATE", "DELETE", "TRUNCATE":
+				if !strings.HasPrefix(g.object, "schema ") {
+					return "every role can change this table"
+				}
+			}
+		}
+		return ""
+	}
+
+	if g.database == "*" {
+		return mariadbDangerousPrivileges[g.privilege]
+	}
+	if strings.EqualFold(g.database, "mysql") {
+		switch g.privilege {
+		case "INSERT", "UPDATE", "DELETE", "DROP", "ALTER", "CREATE":
+			return "can edit the grant tables and so give itself any privilege"
+		}
+	}
+	if strings.EqualFold(g.user, "PUBLIC") {
+		switch g.privilege {
+		case "INSERT", "UPDATE", "DELETE", "DROP", "ALTER", "CREATE":
+			return "every user can write here"
+		}
+	}
+	return ""
+}
+
+// FindingsFor returns the findings about an entry
+func (a *PrivilegeAudit) FindingsFor(entry PrivilegeEntry) []PrivilegeFinding {
+	var findings []PrivilegeFinding
+	for _, f := range a.Findings {
+		if f.User == entry.User && f.Host == entry.Host && f.Database == entry.Database && f.Object == entry.Object {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// WriteCSV writes one line per entry, with the findings about it joined in
+// the last column
+func (a *PrivilegeAudit) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"user", "host", "database", "object", "privileges", "grant_option", "findings"})
+	for _, e := range a.Entries {
+		var findings []string
+		for _, f := range a.FindingsFor(e) {
+			findings = append(findings, f.Privilege+": "+f.Reason)
+		}
+		cw.Write([]string{
+			e.User, e.Host, e.Database, e.Object,
+			strings.Join(e.Privileges, ", "),
+			fmt.Sprintf("%t", e.GrantOption),
+			strings.Join(findings, "; "),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the audit as JSON
+func (a *PrivilegeAudit) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(a)
+}
+
+// Export writes the audit to a CSV or JSON file
+func (a *PrivilegeAudit) Export(path, format string) error {
+	write := a.WriteCSV
+	switch format {
+	case AuditFormatCSV:
+	case AuditFormatJSON:
+		write = a.WriteJSON
+	default:
+		return fmt.Errorf("unsupported audit format: %s", format)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
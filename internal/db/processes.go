@@ -0,0 +1,57 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// RunningQueries returns the sessions busy with a statement, longest running
+// first. Idle connections and this connection are left out.
+func (c *Connection) RunningQueries() ([]Session, error) {
+	rows, err := c.DB.Query(c.Driver.RunningQueriesQuery())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read running queries: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []Session
+	for rows.Next() {
+		var s Session
+		var secs int64
+		if err := rows.Scan(&s.ID, &s.User, &s.Database, &s.State, &s.Query, &secs); err != nil {
+			return nil, fmt.Errorf("failed to read running queries: %w", err)
+		}
+		s.Duration = time.Duration(secs) * time.Second
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// LongRunning returns the sessions that have run their query for at least threshold
+func LongRunning(sessions []Session, threshold time.Duration) []Session {
+	var long []Session
+	for _, s := range sessions {
+		if s.Duration >= threshold {
+			long = append(long, s)
+		}
+	}
+	return long
+}
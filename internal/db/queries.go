@@ -19,29 +19,30 @@
 package db
 
 import (
+	gosql "database/sql"
 	"fmt"
 )
 
 // Database represents a database with its metadata
 type Database struct {
-	Name string
+	Name string `json:"name"`
 }
 
 // Table represents a table with its metadata
 type Table struct {
-	Name   string
-	Engine string
-	Rows   int64
+	Name   string `json:"name"`
+	Engine string `json:"engine,omitempty"`
+	Rows   int64  `json:"rows"`
 }
 
 // Column represents a table column
 type Column struct {
-	Field   string
-	Type    string
-	Null    string
-	Key     string
-	Default *string
-	Extra   string
+	Field   string  `json:"field"`
+	Type    string  `json:"type"`
+	Null    string  `json:"null"`
+	Key     string  `json:"key"`
+	Default *string `json:"default"`
+	Extra   string  `json:"extra"`
 }
 
 // QueryResult holds the result of a query
@@ -170,9 +171,26 @@ func (c *Connection) DescribeTable(tableName string) ([]Column, error) {
 	return columns, rows.Err()
 }
 
-// Query executes a SQL query and returns the results
-func (c *Connection) Query(sql string) (*QueryResult, error) {
-	rows, err := c.DB.Query(sql)
+// Query executes a SQL query and returns the results.
+// When args are given the query is run as a prepared statement with the
+// args bound to its placeholders (? for MariaDB, $1..$n for PostgreSQL).
+func (c *Connection) Query(sql string, args ...interface{}) (*QueryResult, error) {
+	if err := c.CheckStatementProtected(sql); err != nil {
+		return nil, err
+	}
+
+	var rows *gosql.Rows
+	var err error
+	if len(args) > 0 {
+		stmt, perr := c.DB.Prepare(sql)
+		if perr != nil {
+			return nil, fmt.Errorf("failed to prepare query: %w", perr)
+		}
+		defer stmt.Close()
+		rows, err = stmt.Query(args...)
+	} else {
+		rows, err = c.DB.Query(sql)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
@@ -217,9 +235,25 @@ func (c *Connection) Query(sql string) (*QueryResult, error) {
 	return result, rows.Err()
 }
 
-// Execute runs a SQL statement that doesn't return rows
-func (c *Connection) Execute(sql string) (int64, error) {
-	result, err := c.DB.Exec(sql)
+// Execute runs a SQL statement that doesn't return rows.
+// When args are given the statement is prepared and the args are bound to its placeholders.
+func (c *Connection) Execute(sql string, args ...interface{}) (int64, error) {
+	if err := c.CheckStatementProtected(sql); err != nil {
+		return 0, err
+	}
+
+	var result gosql.Result
+	var err error
+	if len(args) > 0 {
+		stmt, perr := c.DB.Prepare(sql)
+		if perr != nil {
+			return 0, fmt.Errorf("failed to prepare statement: %w", perr)
+		}
+		defer stmt.Close()
+		result, err = stmt.Exec(args...)
+	} else {
+		result, err = c.DB.Exec(sql)
+	}
 	if err != nil {
 		return 0, fmt.Errorf("execution failed: %w", err)
 	}
@@ -259,7 +293,11 @@ func (c *Connection) CreateDatabase(name string) error {
 
 // DropDatabase deletes a database
 func (c *Connection) DropDatabase(name string) error {
+	if err := c.CheckDatabaseProtected(name, "drop"); err != nil {
+		return err
+	}
 	_, err := c.DB.Exec(c.Driver.DropDatabaseQuery(name))
+	c.audit(AuditDropDatabase, name, "", err)
 	if err != nil {
 		return fmt.Errorf("failed to drop database: %w", err)
 	}
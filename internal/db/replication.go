@@ -0,0 +1,175 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChangePrimaryOptions points a MariaDB replica at a new primary
+type ChangePrimaryOptions struct {
+	Host     string
+	Port     int    // Default 3306
+	User     string // Replication user; empty keeps the current one
+	Password string // Empty keeps the current one
+	UseGTID  bool   // Continue from the replica's GTID position (MASTER_USE_GTID = slave_pos)
+	LogFile  string // Binlog coordinates, used when UseGTID is off
+	LogPos   int64
+}
+
+// StartReplica starts the replica I/O and SQL threads
+func (c *Connection) StartReplica() error {
+	if err := c.requireMariaDBReplication(); err != nil {
+		return err
+	}
+	if _, err := c.DB.Exec("START SLAVE"); err != nil {
+		return fmt.Errorf("failed to start replica: %w", err)
+	}
+	return nil
+}
+
+// StopReplica stops the replica I/O and SQL threads
+func (c *Connection) StopReplica() error {
+	if err := c.requireMariaDBReplication(); err != nil {
+		return err
+	}
+	if _, err := c.DB.Exec("STOP SLAVE"); err != nil {
+		return fmt.Errorf("failed to stop replica: %w", err)
+	}
+	return nil
+}
+
+// SkipReplicationError skips the event the SQL thread stopped on and
+// restarts replication
+func (c *Connection) SkipReplicationError() error {
+	if err := c.requireMariaDBReplication(); err != nil {
+		return err
+	}
+	statements := []string{
+		"STOP SLAVE",
+		"SET GLOBAL sql_slave_skip_counter = 1",
+		"START SLAVE",
+	}
+	for _, stmt := range statements {
+		if _, err := c.DB.Exec(stmt); err != nil {
+			return fmt.Errorf("%s failed: %w", stmt, err)
+		}
+	}
+	return nil
+}
+
+// ChangePrimaryStatement builds the CHANGE MASTER TO statement for opts,
+// with the password masked when redact is set
+func (c *Connection) ChangePrimaryStatement(opts ChangePrimaryOptions, redact bool) (string, error) {
+	if opts.Host == "" {
+		return "", fmt.Errorf("a primary host is required")
+	}
+	if opts.Port == 0 {
+		opts.Port = 3306
+	}
+
+	settings := []string{
+		"MASTER_HOST = " + c.literal(opts.Host),
+		fmt.Sprintf("MASTER_PORT = %d", opts.Port),
+	}
+	if opts.User != "" {
+		settings = append(settings, "MASTER_USER = "+c.literal(opts.User))
+	}
+	if opts.Password != "" {
+		password := opts.Password
+		if redact {
+			password = redactedPassword
+		}
+		settings = append(settings, "MASTER_PASSWORD = "+c.literal(password))
+	}
+	if opts.UseGTID {
+		settings = append(settings, "MASTER_USE_GTID = slave_pos")
+	} else if opts.LogFile != "" {
+		settings = append(settings,
+			"MASTER_LOG_FILE = "+c.literal(opts.LogFile),
+			fmt.Sprintf("MASTER_LOG_POS = %d", opts.LogPos))
+	} else {
+		return "", fmt.Errorf("either GTID or a binlog file and position is required")
+	}
+	return "CHANGE MASTER TO " + strings.Join(settings, ", "), nil
+}
+
+// ChangePrimary stops replication, points the replica at a new primary and
+// starts it again. The threads are left stopped if CHANGE MASTER TO fails.
+func (c *Connection) ChangePrimary(opts ChangePrimaryOptions) error {
+	if c.Config.Type != DatabaseTypeMariaDB {
+		return fmt.Errorf("changing the primary is only supported on MariaDB")
+	}
+	stmt, err := c.ChangePrimaryStatement(opts, false)
+	if err != nil {
+		return err
+	}
+	redacted, _ := c.ChangePrimaryStatement(opts, true)
+
+	// A server that isn't a replica yet has nothing to stop
+	if status, err := c.GetMariaDBReplicationStatus(); err == nil && status.IsReplica {
+		if _, err := c.DB.Exec("STOP SLAVE"); err != nil {
+			return fmt.Errorf("failed to stop replica: %w", err)
+		}
+	}
+	if _, err := c.DB.Exec(stmt); err != nil {
+		return fmt.Errorf("%s failed: %w", redacted, err)
+	}
+	if _, err := c.DB.Exec("START SLAVE"); err != nil {
+		return fmt.Errorf("failed to start replica: %w", err)
+	}
+	return nil
+}
+
+// PromoteStandby promotes a PostgreSQL standby to primary with pg_promote,
+// waiting up to a minute for the promotion to finish
+func (c *Connection) PromoteStandby() error {
+	if c.Config.Type != DatabaseTypePostgres {
+		return fmt.Errorf("promoting a standby is only supported on PostgreSQL")
+	}
+	var inRecovery bool
+	if err := c.DB.QueryRow("SELECT pg_is_in_recovery()").Scan(&inRecovery); err != nil {
+		return fmt.Errorf("failed to check recovery state: %w", err)
+	}
+	if !inRecovery {
+		return fmt.Errorf("this server is not a standby")
+	}
+
+	var promoted bool
+	if err := c.DB.QueryRow("SELECT pg_promote(true, 60)").Scan(&promoted); err != nil {
+		return fmt.Errorf("failed to promote standby: %w", err)
+	}
+	if !promoted {
+		return fmt.Errorf("promotion did not finish within 60 seconds; check the server log")
+	}
+	return nil
+}
+
+// requireMariaDBReplication checks the server is a MariaDB replica
+func (c *Connection) requireMariaDBReplication() error {
+	if c.Config.Type != DatabaseTypeMariaDB {
+		return fmt.Errorf("replica thread control is only supported on MariaDB")
+	}
+	status, err := c.GetMariaDBReplicationStatus()
+	if err != nil || !status.IsReplica {
+		return fmt.Errorf("this server is not a replica")
+	}
+	return nil
+}
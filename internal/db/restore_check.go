@@ -0,0 +1,442 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/buffer"
+	"github.com/blubskye/yandere_sql_manager/internal/scratch"
+)
+
+// RestoreCheckLevel grades a finding of the pre-restore check
+type RestoreCheckLevel int
+
+const (
+	RestoreCheckInfo    RestoreCheckLevel = iota // Nothing to worry about
+	RestoreCheckWarning                          // Restorable, but look first
+	RestoreCheckBlocker                          // The restore would fail or destroy data
+)
+
+// String returns a label for the level
+func (l RestoreCheckLevel) String() string {
+	switch l {
+	case RestoreCheckWarning:
+		return "WARN"
+	case RestoreCheckBlocker:
+		return "FAIL"
+	}
+	return "OK"
+}
+
+// RestoreCheck is one finding of the pre-restore check
+type RestoreCheck struct {
+	Level    RestoreCheckLevel
+	Category string // backup, server, role, database, table, charset, extension or disk
+	Message  string
+}
+
+// RestoreReport is the go/no-go analysis of a backup against a target server
+type RestoreReport struct {
+	BackupID      string
+	ServerVersion string // Of the target server
+	Checks        []RestoreCheck
+	RequiredBytes int64               // Uncompressed size of the dumps, a rough estimate of the space needed
+	FreeBytes     int64               // Free space in the server's data directory, -1 when unknown
+	Dropped       []string            // Existing databases the restore drops first
+	Replaced      map[string][]string // Existing tables, per database, the restore drops and recreates
+}
+
+func (r *RestoreReport) add(level RestoreCheckLevel, category, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, RestoreCheck{Level: level, Category: category, Message: fmt.Sprintf(format, args...)})
+}
+
+// Go reports whether nothing blocks the restore
+func (r *RestoreReport) Go() bool {
+	return r.Count(RestoreCheckBlocker) == 0
+}
+
+// Count returns the number of findings at a level
+func (r *RestoreReport) Count(level RestoreCheckLevel) int {
+	n := 0
+	for _, check := range r.Checks {
+		if check.Level == level {
+			n++
+		}
+	}
+	return n
+}
+
+// dumpContents is what a dump creates and requires, from scanning it
+type dumpContents struct {
+	tables     []string
+	replaced   map[string]bool // Dropped before being created
+	kept       map[string]bool // CREATE TABLE IF NOT EXISTS
+	charsets   map[string]bool
+	collations map[string]bool
+	extensions map[string]bool // Storage engines on MariaDB
+	bytes      int64
+}
+
+var (
+	dumpCreateTableRe = regexp.MustCompile(`(?is)^CREATE\s+(?:TEMPORARY\s+|UNLOGGED\s+)?TABLE\s+(IF\s+NOT\s+EXISTS\s+)?([^\s(]+)`)
+	dumpDropTableRe   = regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?([^\s;,]+)`)
+	dumpCharsetRe     = regexp.MustCompile("(?i)(?:CHARSET|CHARACTER\\s+SET)\\s*=?\\s*['\"`]?(\\w+)")
+	dumpCollateRe     = regexp.MustCompile("(?i)COLLATE\\s*=?\\s*(?:\\w+\\.)?['\"`]?([\\w.-]+)")
+	dumpEngineRe      = regexp.MustCompile(`(?i)ENGINE\s*=\s*(\w+)`)
+	dumpExtensionRe   = regexp.MustCompile(`(?is)^CREATE\s+EXTENSION\s+(?:IF\s+NOT\s+EXISTS\s+)?"?([\w-]+)`)
+	versionRe         = regexp.MustCompile(`(\d+)\.(\d+)`)
+)
+
+// scanDump reads a dump statement by statement, looking only at DDL
+func scanDump(path string) (*dumpContents, error) {
+	reader, err := buffer.NewSQLStatementReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	dump := &dumpContents{
+		replaced:   make(map[string]bool),
+		kept:       make(map[string]bool),
+		charsets:   make(map[string]bool),
+		collations: make(map[string]bool),
+		extensions: make(map[string]bool),
+	}
+	for {
+		stmt, _, err := reader.ReadStatement()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		dump.bytes += int64(len(stmt)) + 2
+
+		head := strings.ToUpper(stmt[:min(len(stmt), 8)])
+		if !strings.HasPrefix(head, "CREATE") && !strings.HasPrefix(head, "DROP") && !strings.HasPrefix(head, "ALTER") {
+			continue
+		}
+
+		if m := dumpDropTableRe.FindStringSubmatch(stmt); m != nil {
+			dump.replaced[dumpIdentifier(m[1])] = true
+			continue
+		}
+		if m := dumpCreateTableRe.FindStringSubmatch(stmt); m != nil {
+			name := dumpIdentifier(m[2])
+			dump.tables = append(dump.tables, name)
+			if m[1] != "" {
+				dump.kept[name] = true
+			}
+		}
+		if m := dumpExtensionRe.FindStringSubmatch(stmt); m != nil {
+			dump.extensions[strings.ToLower(m[1])] = true
+		}
+		for _, m := range dumpCharsetRe.FindAllStringSubmatch(stmt, -1) {
+			dump.charsets[strings.ToLower(m[1])] = true
+		}
+		for _, m := range dumpCollateRe.FindAllStringSubmatch(stmt, -1) {
+			dump.collations[strings.ToLower(m[1])] = true
+		}
+		for _, m := range dumpEngineRe.FindAllStringSubmatch(stmt, -1) {
+			dump.extensions[strings.ToLower(m[1])] = true
+		}
+	}
+	return dump, nil
+}
+
+// dumpIdentifier strips quotes and the schema from a table name in a dump
+func dumpIdentifier(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.Trim(name, "`\"")
+}
+
+// CheckRestore analyzes a backup against this server before restoring it:
+// objects that already exist, character sets, collations, engines and
+// extensions the dumps need, the version gap and the disk space required.
+// Nothing is changed.
+func (c *Connection) CheckRestore(opts RestoreOptions) (*RestoreReport, error) {
+	backupDir, metadata, err := loadRestoreMetadata(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RestoreReport{BackupID: metadata.ID, FreeBytes: -1}
+
+	// Server type and version
+	if metadata.ServerType != "" && isPostgresType(metadata.ServerType) != isPostgresType(c.Config.Type) {
+		report.add(RestoreCheckBlocker, "server", "backup was taken from a %s server and can't be restored to %s", metadata.ServerType, c.Config.Type)
+		return report, nil
+	}
+	report.ServerVersion, _ = c.GetServerVersion()
+	c.checkVersionGap(report, metadata.ServerVersion)
+	c.checkRestoreRoles(report, opts)
+
+	// Databases and their dumps
+	databases := opts.Databases
+	if len(databases) == 0 {
+		databases = metadata.Databases
+	}
+
+	charsets := make(map[string]bool)
+	collations := make(map[string]bool)
+	extensions := make(map[string]bool)
+
+	for _, dbName := range databases {
+		var file *BackupFile
+		for i := range metadata.Files {
+			if metadata.Files[i].Database == dbName {
+				file = &metadata.Files[i]
+				break
+			}
+		}
+		if file == nil {
+			report.add(RestoreCheckBlocker, "backup", "database %s is not in the backup", dbName)
+			continue
+		}
+
+		path := filepath.Join(backupDir, file.Filename)
+		if file.SHA256 != "" {
+			sum, err := fileSHA256(path)
+			if err != nil {
+				report.add(RestoreCheckBlocker, "backup", "%s: %v", file.Filename, err)
+				continue
+			}
+			if sum != file.SHA256 {
+				report.add(RestoreCheckBlocker, "backup", "%s: checksum mismatch, the file is damaged", file.Filename)
+				continue
+			}
+		}
+
+		dump, err := scanDump(path)
+		if err != nil {
+			report.add(RestoreCheckBlocker, "backup", "%s: %v", file.Filename, err)
+			continue
+		}
+		report.RequiredBytes += dump.bytes
+		for name := range dump.charsets {
+			charsets[name] = true
+		}
+		for name := range dump.collations {
+			collations[name] = true
+		}
+		for name := range dump.extensions {
+			extensions[name] = true
+		}
+
+		targetDB := dbName
+		if rename, ok := opts.RenameMap[dbName]; ok {
+			targetDB = rename
+		}
+		if err := c.checkRestoreTarget(report, targetDB, dump, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	// What the dumps need from the server
+	c.checkAvailable(report, "charset", "character set", c.Driver.CharsetsQuery(), charsets)
+	c.checkAvailable(report, "charset", "collation", c.Driver.CollationsQuery(), collations)
+	kind := "extension"
+	if !isPostgresType(c.Config.Type) {
+		kind = "storage engine"
+	}
+	c.checkAvailable(report, "extension", kind, c.Driver.ExtensionsQuery(), extensions)
+
+	c.checkDiskSpace(report)
+	return report, nil
+}
+
+// checkVersionGap warns when the backup comes from a newer server
+func (c *Connection) checkVersionGap(report *RestoreReport, backupVersion string) {
+	from := parseVersion(backupVersion)
+	to := parseVersion(report.ServerVersion)
+	if from == nil || to == nil {
+		report.add(RestoreCheckWarning, "server", "can't compare server versions (backup %s, target %s)", orNone(backupVersion), orNone(report.ServerVersion))
+		return
+	}
+
+	// PostgreSQL majors are a single number since 10
+	major := func(v []int) string {
+		if isPostgresType(c.Config.Type) && v[0] >= 10 {
+			return strconv.Itoa(v[0])
+		}
+		return fmt.Sprintf("%d.%d", v[0], v[1])
+	}
+	switch {
+	case major(from) == major(to):
+		report.add(RestoreCheckInfo, "server", "same major version (%s)", major(to))
+	case from[0] > to[0] || (from[0] == to[0] && from[1] > to[1]):
+		report.add(RestoreCheckWarning, "server", "backup is from a newer server (%s to %s), newer syntax may fail", major(from), major(to))
+	default:
+		report.add(RestoreCheckInfo, "server", "upgrading from %s to %s", major(from), major(to))
+	}
+}
+
+// parseVersion returns the major and minor numbers of a version string
+func parseVersion(version string) []int {
+	m := versionRe.FindStringSubmatch(version)
+	if m == nil {
+		return nil
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return []int{major, minor}
+}
+
+// checkRestoreTarget looks for collisions between a dump and the database it goes into
+func (c *Connection) checkRestoreTarget(report *RestoreReport, targetDB string, dump *dumpContents, opts RestoreOptions) error {
+	exists, err := c.DatabaseExists(targetDB)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !exists && opts.CreateIfNotExists:
+		report.add(RestoreCheckInfo, "database", "%s will be created (%d tables)", targetDB, len(dump.tables))
+		return nil
+	case !exists:
+		report.add(RestoreCheckBlocker, "database", "%s doesn't exist", targetDB)
+		return nil
+	case opts.DropExisting:
+		report.add(RestoreCheckWarning, "database", "%s exists and will be dropped first", targetDB)
+		report.Dropped = append(report.Dropped, targetDB)
+		return nil
+	}
+
+	conn, err := c.openDatabase(targetDB)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	tables, err := tableNameSet(conn)
+	if err != nil {
+		return err
+	}
+
+	var replaced, kept, collide []string
+	for _, name := range dump.tables {
+		if !tables[name] {
+			continue
+		}
+		switch {
+		case dump.replaced[name]:
+			replaced = append(replaced, name)
+		case dump.kept[name]:
+			kept = append(kept, name)
+		default:
+			collide = append(collide, name)
+		}
+	}
+
+	if len(collide) > 0 {
+		report.add(RestoreCheckBlocker, "table", "%s: %d tables already exist: %s", targetDB, len(collide), strings.Join(collide, ", "))
+	}
+	if len(replaced) > 0 {
+		report.add(RestoreCheckWarning, "table", "%s: %d existing tables will be replaced: %s", targetDB, len(replaced), strings.Join(replaced, ", "))
+		if report.Replaced == nil {
+			report.Replaced = make(map[string][]string)
+		}
+		report.Replaced[targetDB] = replaced
+	}
+	if len(kept) > 0 {
+		report.add(RestoreCheckWarning, "table", "%s: %d existing tables are kept and get the backup's rows added: %s", targetDB, len(kept), strings.Join(kept, ", "))
+	}
+	if len(collide) == 0 && len(replaced) == 0 && len(kept) == 0 {
+		report.add(RestoreCheckInfo, "database", "%s exists, none of its tables collide with the backup", targetDB)
+	}
+	return nil
+}
+
+// checkAvailable reports the names a dump needs that the server lacks
+func (c *Connection) checkAvailable(report *RestoreReport, category, what, query string, needed map[string]bool) {
+	if len(needed) == 0 || query == "" {
+		return
+	}
+
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		report.add(RestoreCheckWarning, category, "can't list the server's %ss: %v", what, err)
+		return
+	}
+	defer rows.Close()
+	available := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err == nil {
+			available[strings.ToLower(name)] = true
+		}
+	}
+
+	var missing []string
+	for name := range needed {
+		if !available[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		report.add(RestoreCheckBlocker, category, "missing %s: %s", what, strings.Join(missing, ", "))
+		return
+	}
+	report.add(RestoreCheckInfo, category, "all %d %ss used are available", len(needed), what)
+}
+
+// isLocal reports whether the server runs on this machine, so its files can
+// be read directly
+func (c *Connection) isLocal() bool {
+	switch c.Config.Host {
+	case "", "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return c.Config.Socket != ""
+}
+
+// checkDiskSpace compares the dump size with the free space in the data
+// directory, which can only be measured when the server runs on this machine
+func (c *Connection) checkDiskSpace(report *RestoreReport) {
+	required := FormatSize(report.RequiredBytes)
+
+	var dataDir string
+	if c.isLocal() {
+		c.DB.QueryRow(c.Driver.DataDirectoryQuery()).Scan(&dataDir)
+	}
+	if dataDir != "" {
+		if free, err := scratch.DiskFree(dataDir); err == nil {
+			report.FreeBytes = free
+		}
+	}
+
+	switch {
+	case report.FreeBytes < 0:
+		report.add(RestoreCheckInfo, "disk", "about %s needed, free space on the server is unknown", required)
+	case report.RequiredBytes > report.FreeBytes:
+		report.add(RestoreCheckBlocker, "disk", "about %s needed but only %s free", required, FormatSize(report.FreeBytes))
+	case report.RequiredBytes*2 > report.FreeBytes:
+		report.add(RestoreCheckWarning, "disk", "about %s needed, %s free leaves little room for indexes and logs", required, FormatSize(report.FreeBytes))
+	default:
+		report.add(RestoreCheckInfo, "disk", "about %s needed, %s free", required, FormatSize(report.FreeBytes))
+	}
+}
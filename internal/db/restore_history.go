@@ -0,0 +1,168 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxRestoreHistory is how many restores the history keeps
+const maxRestoreHistory = 200
+
+// RestoreRecord is a finished restore, kept to estimate how long the next
+// one will take
+type RestoreRecord struct {
+	BackupID  string        `json:"backup_id"`
+	Server    string        `json:"server"` // Server restored to, see ServerKey
+	Databases []string      `json:"databases"`
+	Bytes     int64         `json:"bytes"` // Size of the backup files restored
+	Duration  time.Duration `json:"duration_ns"`
+	Time      time.Time     `json:"time"`
+}
+
+// RestoreHistory holds the most recent successful restores, oldest first
+type RestoreHistory struct {
+	Restores []RestoreRecord `json:"restores"`
+}
+
+// GetRestoreHistoryPath returns the path to the restore history file, next
+// to the backups directory
+func GetRestoreHistoryPath() (string, error) {
+	backupsDir, err := GetBackupsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(backupsDir), "restore_history.json"), nil
+}
+
+// LoadRestoreHistory loads the restore history
+func LoadRestoreHistory() (*RestoreHistory, error) {
+	path, err := GetRestoreHistoryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &RestoreHistory{Restores: []RestoreRecord{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read restore history: %w", err)
+	}
+
+	var history RestoreHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to parse restore history: %w", err)
+	}
+
+	return &history, nil
+}
+
+// SaveRestoreHistory saves the restore history
+func SaveRestoreHistory(history *RestoreHistory) error {
+	path, err := GetRestoreHistoryPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal restore history: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write restore history: %w", err)
+	}
+
+	return nil
+}
+
+// recordRestore adds a restore to the history, dropping the oldest beyond
+// maxRestoreHistory
+func recordRestore(record RestoreRecord) error {
+	history, err := LoadRestoreHistory()
+	if err != nil {
+		return err
+	}
+	history.Restores = append(history.Restores, record)
+	if n := len(history.Restores); n > maxRestoreHistory {
+		history.Restores = history.Restores[n-maxRestoreHistory:]
+	}
+	return SaveRestoreHistory(history)
+}
+
+// RestoreEstimate is how long restoring some amount of backup is expected
+// to take, going by earlier restores
+type RestoreEstimate struct {
+	Duration   time.Duration `json:"duration_ns"`
+	Samples    int           `json:"samples"`     // Restores the estimate is based on
+	SameServer bool          `json:"same_server"` // They went to the server estimated for
+}
+
+// Known reports whether there was any history to estimate from
+func (e RestoreEstimate) Known() bool {
+	return e.Samples > 0
+}
+
+// EstimateRestore estimates restoring bytes of backup files to server from
+// the throughput of earlier restores to it, or of every restore when none
+// went there
+func (h *RestoreHistory) EstimateRestore(bytes int64, server string) RestoreEstimate {
+	var sameBytes, allBytes int64
+	var sameTime, allTime time.Duration
+	sameCount, allCount := 0, 0
+	for _, r := range h.Restores {
+		if r.Bytes <= 0 || r.Duration <= 0 {
+			continue
+		}
+		allBytes += r.Bytes
+		allTime += r.Duration
+		allCount++
+		if r.Server == server {
+			sameBytes += r.Bytes
+			sameTime += r.Duration
+			sameCount++
+		}
+	}
+
+	estimate := func(b int64, d time.Duration) time.Duration {
+		return time.Duration(float64(bytes) / float64(b) * float64(d))
+	}
+	switch {
+	case sameCount > 0:
+		return RestoreEstimate{Duration: estimate(sameBytes, sameTime), Samples: sameCount, SameServer: true}
+	case allCount > 0:
+		return RestoreEstimate{Duration: estimate(allBytes, allTime), Samples: allCount}
+	}
+	return RestoreEstimate{}
+}
+
+// LastRestore returns the most recent restore of a backup, or nil
+func (h *RestoreHistory) LastRestore(backupID string) *RestoreRecord {
+	for i := len(h.Restores) - 1; i >= 0; i-- {
+		if h.Restores[i].BackupID == backupID {
+			return &h.Restores[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PrivilegeSet is a named set of privileges granted on restored objects
+type PrivilegeSet string
+
+const (
+	PrivilegesRead  PrivilegeSet = "read"  // Read tables and sequences
+	PrivilegesWrite PrivilegeSet = "write" // Also change rows, use sequences and run functions
+	PrivilegesAll   PrivilegeSet = "all"   // Everything, including creating objects in the schemas
+)
+
+// privilegeGrants are the privileges a set grants per kind of object; "" grants nothing
+type privilegeGrants struct {
+	schemas   string
+	tables    string
+	sequences string
+	functions string
+}
+
+var privilegeSets = map[PrivilegeSet]privilegeGrants{
+	PrivilegesRead:  {schemas: "USAGE", tables: "SELECT", sequences: "SELECT"},
+	PrivilegesWrite: {schemas: "USAGE", tables: "SELECT, INSERT, UPDATE, DELETE", sequences: "USAGE, SELECT, UPDATE", functions: "EXECUTE"},
+	PrivilegesAll:   {schemas: "ALL", tables: "ALL", sequences: "ALL", functions: "ALL"},
+}
+
+// ParsePrivilegeSet validates a privilege set name
+func ParsePrivilegeSet(name string) (PrivilegeSet, error) {
+	set := PrivilegeSet(strings.ToLower(name))
+	if _, ok := privilegeSets[set]; !ok {
+		return "", fmt.Errorf("unknown privilege set '%s' (use read, write or all)", name)
+	}
+	return set, nil
+}
+
+// userSchemasFilter excludes the system schemas, for a namespace aliased n
+const userSchemasFilter = `n.nspname NOT LIKE 'pg\_%' AND n.nspname <> 'information_schema'`
+
+// ownedObjectsQuery lists the objects of a database that can change owner,
+// and their owners, leaving out extension members and sequences that follow
+// their table
+const ownedObjectsQuery = `
+SELECT 'SCHEMA', quote_ident(n.nspname), pg_get_userbyid(n.nspowner)
+FROM pg_namespace n
+WHERE ` + userSchemasFilter + ` AND n.nspname <> 'public'
+  AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = 'pg_namespace'::regclass AND d.objid = n.oid AND d.deptype = 'e')
+UNION ALL
+SELECT CASE c.relkind WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'MATERIALIZED VIEW' WHEN 'S' THEN 'SEQUENCE' WHEN 'f' THEN 'FOREIGN TABLE' ELSE 'TABLE' END,
+       quote_ident(n.nspname) || '.' || quote_ident(c.relname), pg_get_userbyid(c.relowner)
+FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('r', 'p', 'v', 'm', 'S', 'f') AND ` + userSchemasFilter + `
+  AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = 'pg_class'::regclass AND d.objid = c.oid AND d.deptype IN ('e', 'a', 'i'))
+UNION ALL
+SELECT CASE p.prokind WHEN 'p' THEN 'PROCEDURE' WHEN 'a' THEN 'AGGREGATE' ELSE 'FUNCTION' END,
+       quote_ident(n.nspname) || '.' || quote_ident(p.proname) || '(' || pg_get_function_identity_arguments(p.oid) || ')', pg_get_userbyid(p.proowner)
+FROM pg_proc p JOIN pg_namespace n ON n.oid = p.pronamespace
+WHERE ` + userSchemasFilter + `
+  AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = 'pg_proc'::regclass AND d.objid = p.oid AND d.deptype = 'e')
+UNION ALL
+SELECT CASE t.typtype WHEN 'd' THEN 'DOMAIN' ELSE 'TYPE' END,
+       quote_ident(n.nspname) || '.' || quote_ident(t.typname), pg_get_userbyid(t.typowner)
+FROM pg_type t JOIN pg_namespace n ON n.oid = t.typnamespace
+WHERE t.typtype IN ('c', 'd', 'e', 'r') AND ` + userSchemasFilter + `
+  AND (t.typtype <> 'c' OR (SELECT c.relkind FROM pg_class c WHERE c.oid = t.typrelid) = 'c')
+  AND NOT EXISTS (SELECT 1 FROM pg_depend d WHERE d.classid = 'pg_type'::regclass AND d.objid = t.oid AND d.deptype = 'e')`
+
+// validateRestoreOwnership rejects ownership options the server can't honor
+func (c *Connection) validateRestoreOwnership(opts RestoreOptions) error {
+	if opts.Owner == "" && opts.GrantRole == "" && len(opts.ReassignOwned) == 0 {
+		return nil
+	}
+	if !isPostgresType(c.Config.Type) {
+		return fmt.Errorf("ownership and privilege options are only supported on PostgreSQL")
+	}
+	if len(opts.ReassignOwned) > 0 && opts.Owner == "" {
+		return fmt.Errorf("reassigning ownership needs an owner")
+	}
+	if opts.Privileges != "" {
+		if _, err := ParsePrivilegeSet(string(opts.Privileges)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyRestoreOwnership hands a restored database to its owner and grants
+// the privilege set to the grant role, including default privileges so
+// objects created later are covered too
+func (c *Connection) applyRestoreOwnership(database string, opts RestoreOptions) error {
+	conn, err := c.openDatabase(database)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var statements []string
+	if opts.Owner != "" {
+		owner := conn.QuoteIdentifier(opts.Owner)
+		statements = append(statements, fmt.Sprintf("ALTER DATABASE %s OWNER TO %s", conn.QuoteIdentifier(database), owner))
+
+		// Objects are altered one by one rather than with REASSIGN OWNED,
+		// which would also move what the roles own in other databases
+		reassign := make(map[string]bool, len(opts.ReassignOwned))
+		for _, role := range opts.ReassignOwned {
+			reassign[role] = true
+		}
+		rows, err := conn.DB.Query(ownedObjectsQuery)
+		if err != nil {
+			return fmt.Errorf("failed to list objects: %w", err)
+		}
+		for rows.Next() {
+			var kind, name, current string
+			if err := rows.Scan(&kind, &name, &current); err != nil {
+				rows.Close()
+				return err
+			}
+			if len(reassign) > 0 && !reassign[current] {
+				continue
+			}
+			statements = append(statements, fmt.Sprintf("ALTER %s %s OWNER TO %s", kind, name, owner))
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	if opts.GrantRole != "" {
+		set := opts.Privileges
+		if set == "" {
+			set = PrivilegesRead
+		}
+		grants := privilegeSets[set]
+		role := conn.QuoteIdentifier(opts.GrantRole)
+
+		// Default privileges apply to objects the owner creates later
+		creator := opts.Owner
+		if creator == "" {
+			creator = conn.Config.User
+		}
+		creator = conn.QuoteIdentifier(creator)
+
+		rows, err := conn.DB.Query("SELECT quote_ident(n.nspname) FROM pg_namespace n WHERE " + userSchemasFilter)
+		if err != nil {
+			return fmt.Errorf("failed to list schemas: %w", err)
+		}
+		var schemas []string
+		for rows.Next() {
+			var schema string
+			if err := rows.Scan(&schema); err != nil {
+				rows.Close()
+				return err
+			}
+			schemas = append(schemas, schema)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		statements = append(statements, fmt.Sprintf("GRANT CONNECT ON DATABASE %s TO %s", conn.QuoteIdentifier(database), role))
+		for _, schema := range schemas {
+			statements = append(statements, fmt.Sprintf("GRANT %s ON SCHEMA %s TO %s", grants.schemas, schema, role))
+			for _, g := range []struct{ privileges, objects string }{
+				{grants.tables, "TABLES"},
+				{grants.sequences, "SEQUENCES"},
+				{grants.functions, "FUNCTIONS"},
+			} {
+				if g.privileges == "" {
+					continue
+				}
+				statements = append(statements,
+					fmt.Sprintf("GRANT %s ON ALL %s IN SCHEMA %s TO %s", g.privileges, g.objects, schema, role),
+					fmt.Sprintf("ALTER DEFAULT PRIVILEGES FOR ROLE %s IN SCHEMA %s GRANT %s ON %s TO %s", creator, schema, g.privileges, g.objects, role))
+			}
+		}
+	}
+
+	for _, stmt := range statements {
+		if _, err := conn.DB.Exec(stmt); err != nil {
+			return fmt.Errorf("%w\n%s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// checkRestoreRoles reports ownership options that would fail the restore
+func (c *Connection) checkRestoreRoles(report *RestoreReport, opts RestoreOptions) {
+	if err := c.validateRestoreOwnership(opts); err != nil {
+		report.add(RestoreCheckBlocker, "role", "%v", err)
+		return
+	}
+
+	roles := append([]string{opts.Owner, opts.GrantRole}, opts.ReassignOwned...)
+	for _, role := range roles {
+		if role == "" {
+			continue
+		}
+		var exists int
+		err := c.DB.QueryRow("SELECT 1 FROM pg_roles WHERE rolname = $1", role).Scan(&exists)
+		if errors.Is(err, sql.ErrNoRows) {
+			report.add(RestoreCheckBlocker, "role", "role %s doesn't exist", role)
+			continue
+		}
+		if err != nil {
+			report.add(RestoreCheckWarning, "role", "can't look up role %s: %v", role, err)
+			continue
+		}
+		report.add(RestoreCheckInfo, "role", "role %s exists", role)
+	}
+}
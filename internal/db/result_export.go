@@ -0,0 +1,182 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Result export formats
+const (
+	ResultFormatCSV = "csv"
+	ResultFormatSQL = "sql"
+)
+
+// resultExportBatch is the number of rows per INSERT in SQL result exports
+const resultExportBatch = 1000
+
+// ExportTableResult writes every row matching the browser's filters, in its
+// sort order, to a CSV or SQL file. Limit and Offset are ignored. The rows are
+// streamed from a single statement, so the file is one consistent snapshot
+// even while other sessions keep writing to the table.
+func (c *Connection) ExportTableResult(tableName string, opts TableDataOptions, format, path string) (int64, error) {
+	if format != ResultFormatCSV && format != ResultFormatSQL {
+		return 0, fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	query, args := c.tableDataQuery(tableName, opts)
+	rows, err := c.DB.Query(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	writer := bufio.NewWriter(file)
+
+	var count int64
+	if format == ResultFormatCSV {
+		count, err = c.writeResultCSV(writer, rows, columns)
+	} else {
+		count, err = c.writeResultSQL(writer, tableName, rows, columns)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		// Don't leave a partial export behind
+		os.Remove(path)
+		return count, fmt.Errorf("export failed: %w", err)
+	}
+	return count, nil
+}
+
+// writeResultCSV writes a header and one line per row. NULL becomes an empty field.
+func (c *Connection) writeResultCSV(w *bufio.Writer, rows *sql.Rows, columns []string) (int64, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return 0, err
+	}
+
+	values := make([]interface{}, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+
+	var count int64
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return count, err
+		}
+		for i, val := range values {
+			record[i] = csvValue(val)
+		}
+		if err := cw.Write(record); err != nil {
+			return count, err
+		}
+		count++
+	}
+	cw.Flush()
+	return count, cw.Error()
+}
+
+// csvValue formats a scanned value for CSV
+func csvValue(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// writeResultSQL writes batched INSERT statements into the source table
+func (c *Connection) writeResultSQL(w *bufio.Writer, tableName string, rows *sql.Rows, columns []string) (int64, error) {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = c.QuoteIdentifier(col)
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES\n", c.QuoteIdentifier(tableName), strings.Join(quoted, ", "))
+
+	fmt.Fprintf(w, "-- Filtered rows of %s exported by YSM on %s\n\n",
+		c.QuoteIdentifier(tableName), time.Now().Format("2006-01-02 15:04:05"))
+
+	values := make([]interface{}, len(columns))
+	ptrs := make([]interface{}, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	formatted := make([]string, len(columns))
+
+	var count int64
+	batch := make([]string, 0, resultExportBatch)
+	flush := func() {
+		if len(batch) > 0 {
+			fmt.Fprintf(w, "%s%s;\n\n", insert, strings.Join(batch, ",\n"))
+			batch = batch[:0]
+		}
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return count, err
+		}
+		for i, val := range values {
+			formatted[i] = c.formatValueForExport(val)
+		}
+		batch = append(batch, "("+strings.Join(formatted, ", ")+")")
+		count++
+		if len(batch) >= resultExportBatch {
+			flush()
+		}
+	}
+	flush()
+	return count, nil
+}
+
+// DefaultResultExportPath returns a file name like orders-20250102-150405.csv
+func DefaultResultExportPath(tableName, format string) string {
+	return fmt.Sprintf("%s-%s.%s", tableName, time.Now().Format("20060102-150405"), format)
+}
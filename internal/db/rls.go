@@ -0,0 +1,143 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RowSecurityPolicy is a PostgreSQL row-level security policy
+type RowSecurityPolicy struct {
+	Name       string
+	Permissive bool     // false for RESTRICTIVE policies
+	Roles      []string // "public" applies to everyone
+	Command    string   // ALL, SELECT, INSERT, UPDATE or DELETE
+	Using      string   // Filters the rows that are visible
+	WithCheck  string   // Filters the rows that may be written
+}
+
+// RowSecurity describes row-level security on a table as it applies to the
+// connected role
+type RowSecurity struct {
+	Table    string
+	Enabled  bool
+	Forced   bool // Applies to the table owner too
+	Owner    bool // The connected role owns the table
+	Bypass   bool // The connected role is a superuser or has BYPASSRLS
+	Policies []RowSecurityPolicy
+}
+
+// Filtered reports whether policies can hide rows from the connected role
+func (r *RowSecurity) Filtered() bool {
+	return r != nil && r.Enabled && !r.Bypass && (!r.Owner || r.Forced)
+}
+
+// Warning explains why results may be incomplete, or "" when they aren't
+func (r *RowSecurity) Warning() string {
+	if !r.Filtered() {
+		return ""
+	}
+	if len(r.Policies) == 0 {
+		return fmt.Sprintf("row-level security is enabled on %s with no policies, so no rows are visible to this role", r.Table)
+	}
+	return fmt.Sprintf("row-level security is enabled on %s, so rows may be hidden by its %d policies for this role", r.Table, len(r.Policies))
+}
+
+// GetRowSecurity returns the row-level security state and policies of a
+// table, or nil when the server doesn't support row-level security
+func (c *Connection) GetRowSecurity(table string) (*RowSecurity, error) {
+	query := c.Driver.RowSecurityQuery(table)
+	if query == "" {
+		return nil, nil
+	}
+
+	securities, err := c.queryRowSecurity(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(securities) == 0 {
+		return nil, nil
+	}
+	rs := &securities[0]
+
+	rows, err := c.DB.Query(c.Driver.PoliciesQuery(table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p RowSecurityPolicy
+		var permissive, roles string
+		if err := rows.Scan(&p.Name, &permissive, &roles, &p.Command, &p.Using, &p.WithCheck); err != nil {
+			return nil, fmt.Errorf("failed to scan policy: %w", err)
+		}
+		p.Permissive = strings.EqualFold(permissive, "PERMISSIVE")
+		if roles != "" {
+			p.Roles = strings.Split(roles, ",")
+		}
+		rs.Policies = append(rs.Policies, p)
+	}
+	return rs, rows.Err()
+}
+
+// RowSecurityFilteredTables returns the tables among tables (all when empty)
+// whose rows row-level security may hide from the connected role
+func (c *Connection) RowSecurityFilteredTables(tables []string) ([]string, error) {
+	query := c.Driver.RowSecurityQuery("")
+	if query == "" {
+		return nil, nil
+	}
+
+	securities, err := c.queryRowSecurity(query)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		wanted[t] = true
+	}
+	var filtered []string
+	for _, rs := range securities {
+		if rs.Filtered() && (len(tables) == 0 || wanted[rs.Table]) {
+			filtered = append(filtered, rs.Table)
+		}
+	}
+	return filtered, nil
+}
+
+func (c *Connection) queryRowSecurity(query string) ([]RowSecurity, error) {
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read row-level security: %w", err)
+	}
+	defer rows.Close()
+
+	var securities []RowSecurity
+	for rows.Next() {
+		var rs RowSecurity
+		if err := rows.Scan(&rs.Table, &rs.Enabled, &rs.Forced, &rs.Owner, &rs.Bypass); err != nil {
+			return nil, fmt.Errorf("failed to scan row-level security: %w", err)
+		}
+		securities = append(securities, rs)
+	}
+	return securities, rows.Err()
+}
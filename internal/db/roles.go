@@ -0,0 +1,202 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RoleAttributes are the attributes of a PostgreSQL role
+type RoleAttributes struct {
+	Name            string
+	Login           bool
+	Superuser       bool
+	CreateDB        bool
+	CreateRole      bool
+	ConnectionLimit int      // -1 = unlimited
+	ValidUntil      string   // Password expiry as text; empty or "infinity" = never
+	MemberOf        []string // Roles this role is a member of
+}
+
+// GetRoleAttributes returns a PostgreSQL role's attributes and memberships
+func (c *Connection) GetRoleAttributes(name string) (*RoleAttributes, error) {
+	if err := c.requirePostgresRoles(); err != nil {
+		return nil, err
+	}
+
+	attrs := &RoleAttributes{Name: name}
+	err := c.DB.QueryRow(`SELECT rolcanlogin, rolsuper, rolcreatedb, rolcreaterole,
+		rolconnlimit, COALESCE(rolvaliduntil::text, '')
+	FROM pg_roles WHERE rolname = $1`, name).Scan(
+		&attrs.Login, &attrs.Superuser, &attrs.CreateDB, &attrs.CreateRole,
+		&attrs.ConnectionLimit, &attrs.ValidUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attributes of role %s: %w", name, err)
+	}
+
+	rows, err := c.DB.Query(`SELECT r.rolname
+	FROM pg_auth_members m
+	JOIN pg_roles r ON r.oid = m.roleid
+	JOIN pg_roles u ON u.oid = m.member
+	WHERE u.rolname = $1
+	ORDER BY r.rolname`, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memberships of role %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, err
+		}
+		attrs.MemberOf = append(attrs.MemberOf, role)
+	}
+
+	return attrs, rows.Err()
+}
+
+// ListRoles returns every PostgreSQL role that can be granted, login or not,
+// leaving out the built-in pg_ roles
+func (c *Connection) ListRoles() ([]string, error) {
+	if err := c.requirePostgresRoles(); err != nil {
+		return nil, err
+	}
+
+	rows, err := c.DB.Query(`SELECT rolname FROM pg_roles WHERE rolname !~ '^pg_' ORDER BY rolname`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []string
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+// AlterRoleStatement returns the ALTER ROLE statement that sets attrs
+func (c *Connection) AlterRoleStatement(attrs RoleAttributes) string {
+	flag := func(on bool, name string) string {
+		if on {
+			return name
+		}
+		return "NO" + name
+	}
+
+	validUntil := attrs.ValidUntil
+	if validUntil == "" {
+		validUntil = "infinity"
+	}
+
+	return fmt.Sprintf("ALTER ROLE %s WITH %s %s %s %s CONNECTION LIMIT %d VALID UNTIL %s",
+		c.QuoteIdentifier(attrs.Name),
+		flag(attrs.Login, "LOGIN"),
+		flag(attrs.Superuser, "SUPERUSER"),
+		flag(attrs.CreateDB, "CREATEDB"),
+		flag(attrs.CreateRole, "CREATEROLE"),
+		attrs.ConnectionLimit,
+		c.literal(validUntil))
+}
+
+// AlterRoleAttributes sets a PostgreSQL role's attributes. MemberOf is left
+// alone; use SetRoleMemberships for that.
+func (c *Connection) AlterRoleAttributes(attrs RoleAttributes) error {
+	if err := c.requirePostgresRoles(); err != nil {
+		return err
+	}
+	if attrs.ConnectionLimit < -1 {
+		return fmt.Errorf("connection limit must be -1 (unlimited) or more")
+	}
+
+	if _, err := c.DB.Exec(c.AlterRoleStatement(attrs)); err != nil {
+		return fmt.Errorf("failed to alter role %s: %w", attrs.Name, err)
+	}
+	return nil
+}
+
+// GrantRole makes member a member of role
+func (c *Connection) GrantRole(role, member string) error {
+	if err := c.requirePostgresRoles(); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("GRANT %s TO %s", c.QuoteIdentifier(role), c.QuoteIdentifier(member))
+	if _, err := c.DB.Exec(query); err != nil {
+		return fmt.Errorf("failed to grant role %s to %s: %w", role, member, err)
+	}
+	return nil
+}
+
+// RevokeRole removes member from role
+func (c *Connection) RevokeRole(role, member string) error {
+	if err := c.requirePostgresRoles(); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("REVOKE %s FROM %s", c.QuoteIdentifier(role), c.QuoteIdentifier(member))
+	if _, err := c.DB.Exec(query); err != nil {
+		return fmt.Errorf("failed to revoke role %s from %s: %w", role, member, err)
+	}
+	return nil
+}
+
+// SetRoleMemberships grants and revokes roles so that member belongs to
+// exactly the roles given
+func (c *Connection) SetRoleMemberships(member string, roles []string) error {
+	current, err := c.GetRoleAttributes(member)
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, role := range roles {
+		if !containsString(current.MemberOf, role) {
+			if err := c.GrantRole(role, member); err != nil {
+				failed = append(failed, err.Error())
+			}
+		}
+	}
+	for _, role := range current.MemberOf {
+		if !containsString(roles, role) {
+			if err := c.RevokeRole(role, member); err != nil {
+				failed = append(failed, err.Error())
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func (c *Connection) requirePostgresRoles() error {
+	if !isPostgresType(c.Config.Type) {
+		return fmt.Errorf("role attributes and membership are only supported on PostgreSQL")
+	}
+	return nil
+}
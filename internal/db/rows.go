@@ -0,0 +1,166 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RowKey identifies a single row by its primary key values
+type RowKey struct {
+	Columns []string
+	Values  []string
+}
+
+// PrimaryKey returns the primary key columns of a table, in key order.
+// An empty result means the table has no primary key.
+func (c *Connection) PrimaryKey(tableName string) ([]string, error) {
+	rows, err := c.DB.Query(c.Driver.PrimaryKeyQuery(tableName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get primary key: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var col string
+		if err := rows.Scan(&col); err != nil {
+			return nil, fmt.Errorf("failed to scan primary key: %w", err)
+		}
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+// RowKeyFor builds the key of a result row from its column names
+func RowKeyFor(pk, columns, row []string) (RowKey, error) {
+	key := RowKey{Columns: pk, Values: make([]string, len(pk))}
+	for i, keyCol := range pk {
+		found := false
+		for j, col := range columns {
+			if col == keyCol && j < len(row) {
+				key.Values[i] = row[j]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return RowKey{}, fmt.Errorf("primary key column %s is not in the result", keyCol)
+		}
+	}
+	return key, nil
+}
+
+// whereKey builds "WHERE pk1 = ? AND pk2 = ?" starting at parameter n
+func (c *Connection) whereKey(key RowKey, n int) (string, []interface{}) {
+	conds := make([]string, len(key.Columns))
+	for i, col := range key.Columns {
+		conds[i] = fmt.Sprintf("%s = %s", c.QuoteIdentifier(col), Placeholder(n+i, c.Config.Type))
+	}
+	return " WHERE " + strings.Join(conds, " AND "), BindArgs(key.Values)
+}
+
+// BuildUpdateRow builds an UPDATE for one row. Values are bound as parameters;
+// NullParam sets a column to NULL.
+func (c *Connection) BuildUpdateRow(tableName string, key RowKey, columns, values []string) (string, []interface{}) {
+	sets := make([]string, len(columns))
+	for i, col := range columns {
+		sets[i] = fmt.Sprintf("%s = %s", c.QuoteIdentifier(col), Placeholder(i, c.Config.Type))
+	}
+	where, keyArgs := c.whereKey(key, len(columns))
+
+	query := fmt.Sprintf("UPDATE %s SET %s%s", c.QuoteIdentifier(tableName), strings.Join(sets, ", "), where)
+	return query, append(BindArgs(values), keyArgs...)
+}
+
+// BuildInsertRow builds an INSERT for one row
+func (c *Connection) BuildInsertRow(tableName string, columns, values []string) (string, []interface{}) {
+	quoted := make([]string, len(columns))
+	markers := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = c.QuoteIdentifier(col)
+		markers[i] = Placeholder(i, c.Config.Type)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		c.QuoteIdentifier(tableName), strings.Join(quoted, ", "), strings.Join(markers, ", "))
+	return query, BindArgs(values)
+}
+
+// BuildDeleteRow builds a DELETE for one row
+func (c *Connection) BuildDeleteRow(tableName string, key RowKey) (string, []interface{}) {
+	where, args := c.whereKey(key, 0)
+	return "DELETE FROM " + c.QuoteIdentifier(tableName) + where, args
+}
+
+// UpdateRow updates the row identified by key
+func (c *Connection) UpdateRow(tableName string, key RowKey, columns, values []string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	query, args := c.BuildUpdateRow(tableName, key, columns, values)
+	// MariaDB reports 0 affected rows when values don't change, so a miss isn't an error here
+	return c.execSingleRow(query, args, false)
+}
+
+// InsertRow inserts a row; columns left out take their defaults
+func (c *Connection) InsertRow(tableName string, columns, values []string) error {
+	query, args := c.BuildInsertRow(tableName, columns, values)
+	if _, err := c.DB.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to insert row: %w", err)
+	}
+	return nil
+}
+
+// DeleteRow deletes the row identified by key
+func (c *Connection) DeleteRow(tableName string, key RowKey) error {
+	query, args := c.BuildDeleteRow(tableName, key)
+	return c.execSingleRow(query, args, true)
+}
+
+// execSingleRow runs a keyed statement in a transaction and rolls it back
+// if it affected more than one row (or none, when mustMatch is set)
+func (c *Connection) execSingleRow(query string, args []interface{}, mustMatch bool) error {
+	tx, err := c.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("execution failed: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err == nil && affected > 1 {
+		tx.Rollback()
+		return fmt.Errorf("statement would affect %d rows, rolled back", affected)
+	}
+	if err == nil && affected == 0 && mustMatch {
+		tx.Rollback()
+		return fmt.Errorf("row not found (was it changed or deleted?)")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
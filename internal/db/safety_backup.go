@@ -0,0 +1,272 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/buffer"
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+)
+
+// Safety backups are quick backups of what a drop, an import or a restore is
+// about to replace, taken just before it runs. They are kept apart from
+// regular backups, so they don't count towards pruning or show up in lists,
+// and expire on their own.
+
+// SafetyBackupOptions describes what an operation is about to replace
+type SafetyBackupOptions struct {
+	Operation string              // What is about to happen, e.g. "drop database shop"
+	Databases []string            // Databases dropped whole
+	Tables    map[string][]string // Tables dropped from databases that are kept
+	Profile   string
+}
+
+// Empty reports whether the operation replaces nothing that exists
+func (o SafetyBackupOptions) Empty() bool {
+	return len(o.Databases) == 0 && len(o.Tables) == 0
+}
+
+// GetSafetyBackupsDir returns the directory safety backups are kept in, next
+// to the backups directory
+func GetSafetyBackupsDir() (string, error) {
+	backupsDir, err := GetBackupsDir()
+	if err != nil {
+		return "", err
+	}
+	safetyDir := filepath.Join(filepath.Dir(backupsDir), "safety")
+	if err := os.MkdirAll(safetyDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create safety backups directory: %w", err)
+	}
+	return safetyDir, nil
+}
+
+// CreateSafetyBackup backs up the databases and tables an operation is about
+// to replace. Databases and tables that don't exist are left out, and nothing
+// is written when none do, in which case the metadata is nil.
+func (c *Connection) CreateSafetyBackup(opts SafetyBackupOptions) (*BackupMetadata, error) {
+	var databases []string
+	tables := make(map[string][]string)
+	for _, name := range opts.Databases {
+		exists, err := c.DatabaseExists(name)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			databases = append(databases, name)
+		}
+	}
+	for name, wanted := range opts.Tables {
+		if containsString(databases, name) {
+			continue // Already backed up whole
+		}
+		existing, err := c.existingTables(name, wanted)
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) > 0 {
+			databases = append(databases, name)
+			tables[name] = existing
+		}
+	}
+	if len(databases) == 0 {
+		return nil, nil
+	}
+	if len(tables) == 0 {
+		tables = nil
+	}
+
+	safetyDir, err := GetSafetyBackupsDir()
+	if err != nil {
+		return nil, err
+	}
+	logging.Info("Taking a safety backup of %s before: %s", strings.Join(databases, ", "), opts.Operation)
+	metadata, err := c.CreateBackup(BackupOptions{
+		OutputDir:   safetyDir,
+		Databases:   databases,
+		Tables:      tables,
+		Compression: CompressionZstd,
+		Description: "before " + opts.Operation,
+		Profile:     opts.Profile,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("safety backup failed: %w", err)
+	}
+	return metadata, nil
+}
+
+// existingTables returns the tables of a database, among those wanted, that
+// exist
+func (c *Connection) existingTables(database string, wanted []string) ([]string, error) {
+	exists, err := c.DatabaseExists(database)
+	if err != nil || !exists {
+		return nil, err
+	}
+	conn, err := c.openDatabase(database)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	names, err := tableNameSet(conn)
+	if err != nil {
+		return nil, err
+	}
+	var existing []string
+	for _, name := range wanted {
+		if names[name] && !containsString(existing, name) {
+			existing = append(existing, name)
+		}
+	}
+	sort.Strings(existing)
+	return existing, nil
+}
+
+// ImportReplacedTables scans a SQL file for DROP TABLE statements and returns
+// the tables of the target database they would drop. Dumps in pg_dump's
+// custom format can't be scanned and report none.
+func (c *Connection) ImportReplacedTables(path, database string) ([]string, error) {
+	base := strings.ToLower(filepath.Base(buffer.SplitDumpName(path)))
+	for _, ext := range []string{".gz", ".xz", ".zst"} {
+		base = strings.TrimSuffix(base, ext)
+	}
+	if strings.HasSuffix(base, ".dump") || strings.HasSuffix(base, ".pgdump") {
+		return nil, nil
+	}
+
+	dump, err := scanDump(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", path, err)
+	}
+	dropped := make([]string, 0, len(dump.replaced))
+	for name := range dump.replaced {
+		dropped = append(dropped, name)
+	}
+	return c.existingTables(database, dropped)
+}
+
+// ListSafetyBackups returns the safety backups, newest first
+func ListSafetyBackups() ([]BackupMetadata, error) {
+	safetyDir, err := GetSafetyBackupsDir()
+	if err != nil {
+		return nil, err
+	}
+	return readBackups(safetyDir)
+}
+
+// GetSafetyBackup returns the metadata of a safety backup
+func GetSafetyBackup(id string) (*BackupMetadata, error) {
+	safetyDir, err := GetSafetyBackupsDir()
+	if err != nil {
+		return nil, err
+	}
+	metadataData, err := os.ReadFile(filepath.Join(safetyDir, id, "metadata.json"))
+	if err != nil {
+		return nil, fmt.Errorf("safety backup not found: %w", err)
+	}
+	var metadata BackupMetadata
+	if err := json.Unmarshal(metadataData, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse safety backup metadata: %w", err)
+	}
+	return &metadata, nil
+}
+
+// DeleteSafetyBackup removes a safety backup
+func DeleteSafetyBackup(id string) error {
+	safetyDir, err := GetSafetyBackupsDir()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(safetyDir, id)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return fmt.Errorf("safety backup not found: %s", id)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to delete safety backup: %w", err)
+	}
+	return nil
+}
+
+// ExpireSafetyBackups deletes the safety backups older than maxAge and
+// returns them
+func ExpireSafetyBackups(maxAge time.Duration) ([]BackupMetadata, error) {
+	backups, err := ListSafetyBackups()
+	if err != nil {
+		return nil, err
+	}
+	cutoff := time.Now().Add(-maxAge)
+	var expired []BackupMetadata
+	for _, b := range backups {
+		if b.Timestamp.After(cutoff) {
+			continue
+		}
+		if err := DeleteSafetyBackup(b.ID); err != nil {
+			return expired, err
+		}
+		expired = append(expired, b)
+	}
+	return expired, nil
+}
+
+// UndoSafetyBackup puts back what a safety backup holds. Databases backed up
+// whole replace whatever has their name now; tables backed up from a kept
+// database replace those tables only, leaving the rest of it alone.
+func (c *Connection) UndoSafetyBackup(id string, onProgress func(database string, dbNum, totalDBs int, percent float64, rows, bytes int64)) error {
+	metadata, err := GetSafetyBackup(id)
+	if err != nil {
+		return err
+	}
+	safetyDir, err := GetSafetyBackupsDir()
+	if err != nil {
+		return err
+	}
+
+	var whole, partial []string
+	for _, name := range metadata.Databases {
+		if _, ok := metadata.Tables[name]; ok {
+			partial = append(partial, name)
+		} else {
+			whole = append(whole, name)
+		}
+	}
+	for _, restore := range []struct {
+		databases []string
+		drop      bool
+	}{{whole, true}, {partial, false}} {
+		if len(restore.databases) == 0 {
+			continue
+		}
+		err := c.RestoreBackup(RestoreOptions{
+			BackupPath:        filepath.Join(safetyDir, id),
+			Databases:         restore.databases,
+			DropExisting:      restore.drop,
+			CreateIfNotExists: true,
+			OnProgress:        onProgress,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
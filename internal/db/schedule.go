@@ -284,30 +284,6 @@ func CleanupOldBackups(database string, retainCount int) error {
 		return nil // Keep all
 	}
 
-	backups, err := ListBackups()
-	if err != nil {
-		return err
-	}
-
-	// Filter backups for this database
-	var dbBackups []BackupMetadata
-	for _, b := range backups {
-		for _, db := range b.Databases {
-			if db == database {
-				dbBackups = append(dbBackups, b)
-				break
-			}
-		}
-	}
-
-	// Delete old backups (they're already sorted newest first)
-	if len(dbBackups) > retainCount {
-		for _, b := range dbBackups[retainCount:] {
-			if err := DeleteBackup(b.ID); err != nil {
-				return fmt.Errorf("failed to delete old backup %s: %w", b.ID, err)
-			}
-		}
-	}
-
-	return nil
+	_, err := PruneBackups(PruneOptions{Keep: retainCount, Database: database})
+	return err
 }
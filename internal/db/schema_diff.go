@@ -0,0 +1,568 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaChangeKind says how an object differs between two schemas
+type SchemaChangeKind int
+
+const (
+	SchemaAdded    SchemaChangeKind = iota // Only in the source, created in the target
+	SchemaRemoved                          // Only in the target, dropped from it
+	SchemaModified                         // In both, but defined differently
+)
+
+// Symbol returns the diff marker for a change kind
+func (k SchemaChangeKind) Symbol() string {
+	switch k {
+	case SchemaAdded:
+		return "+"
+	case SchemaRemoved:
+		return "-"
+	}
+	return "~"
+}
+
+// SchemaChange is a single object-level difference
+type SchemaChange struct {
+	Kind   SchemaChangeKind
+	Object string // column, primary key, unique, index, foreign key, check
+	Name   string
+	Detail string
+}
+
+// String renders the change like "~ column email: VARCHAR(100) -> VARCHAR(255)"
+func (c SchemaChange) String() string {
+	s := c.Kind.Symbol() + " " + c.Object
+	if c.Name != "" {
+		s += " " + c.Name
+	}
+	if c.Detail != "" {
+		s += ": " + c.Detail
+	}
+	return s
+}
+
+// Migration phases, so that foreign keys are dropped before the columns and
+// indexes they use and added after the tables they reference exist
+const (
+	phaseDropForeignKeys = iota
+	phaseDropIndexes
+	phaseCreateTables
+	phaseAlterTables
+	phaseCreateIndexes
+	phaseAddConstraints
+	phaseDropTables
+	migrationPhases
+)
+
+// TableSchemaDiff holds the differences for one table
+type TableSchemaDiff struct {
+	Table   string
+	Kind    SchemaChangeKind // Added/Removed for whole tables
+	Changes []SchemaChange
+	phases  [migrationPhases][]string
+}
+
+// Statements returns the migration statements for this table alone
+func (t TableSchemaDiff) Statements() []string {
+	var statements []string
+	for _, phase := range t.phases {
+		statements = append(statements, phase...)
+	}
+	return statements
+}
+
+func (t *TableSchemaDiff) add(phase int, statements ...string) {
+	t.phases[phase] = append(t.phases[phase], statements...)
+}
+
+// SchemaDiff is a structural comparison of two databases. The migration
+// makes Target match Source.
+type SchemaDiff struct {
+	Source    string
+	Target    string
+	Tables    []TableSchemaDiff // Tables that differ, by name
+	Identical []string
+}
+
+// Migration returns the statements that make the target match the source,
+// ordered across tables so that dependencies are respected
+func (d *SchemaDiff) Migration() []string {
+	var statements []string
+	for phase := 0; phase < migrationPhases; phase++ {
+		for _, t := range d.Tables {
+			statements = append(statements, t.phases[phase]...)
+		}
+	}
+	return statements
+}
+
+// Script renders the migration as a SQL script
+func (d *SchemaDiff) Script() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "-- Migration from %s to %s\n", d.Target, d.Source)
+	fmt.Fprintf(&b, "-- Makes %s match %s\n\n", d.Target, d.Source)
+	for _, stmt := range d.Migration() {
+		b.WriteString(stmt)
+		b.WriteString(";\n\n")
+	}
+	return b.String()
+}
+
+// tableSchema is everything the differ compares for a table
+type tableSchema struct {
+	def     TableDef
+	indexes []Index
+	fks     []ForeignKey
+	checks  []CheckConstraint
+}
+
+// DiffSchemas compares the structure of two databases column by column,
+// including keys, indexes, foreign keys and checks. The connection is left
+// on the target database.
+func (c *Connection) DiffSchemas(source, target string) (*SchemaDiff, error) {
+	src, err := c.loadSchema(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	dst, err := c.loadSchema(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", target, err)
+	}
+
+	diff := &SchemaDiff{Source: source, Target: target}
+
+	for _, name := range sortedKeys(src) {
+		s := src[name]
+		d, ok := dst[name]
+		if !ok {
+			diff.Tables = append(diff.Tables, c.createTableDiff(s))
+			continue
+		}
+		td, err := c.diffTableSchemas(s, d)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", name, err)
+		}
+		if len(td.Changes) == 0 {
+			diff.Identical = append(diff.Identical, name)
+			continue
+		}
+		diff.Tables = append(diff.Tables, td)
+	}
+
+	for _, name := range sortedKeys(dst) {
+		if _, ok := src[name]; ok {
+			continue
+		}
+		td := TableSchemaDiff{Table: name, Kind: SchemaRemoved}
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaRemoved, Object: "table", Name: name})
+		// Keys pointing out of a dropped table go first so drop order doesn't matter
+		for _, fk := range dst[name].fks {
+			td.add(phaseDropForeignKeys, c.Driver.DropForeignKeyQuery(name, fk.Name))
+		}
+		td.add(phaseDropTables, "DROP TABLE "+c.QuoteIdentifier(name))
+		diff.Tables = append(diff.Tables, td)
+	}
+
+	sort.Slice(diff.Tables, func(i, j int) bool {
+		return diff.Tables[i].Table < diff.Tables[j].Table
+	})
+	return diff, nil
+}
+
+// loadSchema reads the structure of every table in a database
+func (c *Connection) loadSchema(database string) (map[string]*tableSchema, error) {
+	if err := c.UseDatabase(database); err != nil {
+		return nil, err
+	}
+	tables, err := c.ListTables()
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := c.ListIndexes("")
+	if err != nil {
+		return nil, err
+	}
+
+	schema := make(map[string]*tableSchema, len(tables))
+	for _, t := range tables {
+		def, err := c.LoadTableDef(t.Name)
+		if err != nil {
+			return nil, err
+		}
+		fks, err := c.ListForeignKeys(t.Name)
+		if err != nil {
+			return nil, err
+		}
+		// Checks need MariaDB 10.2+, so they're best effort like in the inspector
+		checks, _ := c.ListCheckConstraints(t.Name)
+		schema[t.Name] = &tableSchema{def: def, fks: fks, checks: checks}
+	}
+	for _, idx := range indexes {
+		if s, ok := schema[idx.Table]; ok {
+			s.indexes = append(s.indexes, idx)
+		}
+	}
+	return schema, nil
+}
+
+// createTableDiff describes a table that only exists in the source
+func (c *Connection) createTableDiff(s *tableSchema) TableSchemaDiff {
+	name := s.def.Name
+	td := TableSchemaDiff{Table: name, Kind: SchemaAdded}
+	td.Changes = append(td.Changes, SchemaChange{
+		Kind:   SchemaAdded,
+		Object: "table",
+		Name:   name,
+		Detail: fmt.Sprintf("%d columns", len(s.def.Columns)),
+	})
+
+	create := s.def
+	for i := range create.Columns {
+		create.Columns[i].Original = ""
+	}
+	td.add(phaseCreateTables, c.Driver.CreateTableQuery(create))
+
+	skip := columnUniqueIndexes(s.def)
+	for _, idx := range s.indexes {
+		if idx.Primary || skip[idx.Name] {
+			continue
+		}
+		c.diffIndex(&td, &idx, nil)
+	}
+	for i := range s.fks {
+		c.diffForeignKey(&td, &s.fks[i], nil)
+	}
+	for i := range s.checks {
+		c.diffCheck(&td, &s.checks[i], nil)
+	}
+	return td
+}
+
+// diffTableSchemas compares a table that exists in both databases
+func (c *Connection) diffTableSchemas(src, dst *tableSchema) (TableSchemaDiff, error) {
+	td := TableSchemaDiff{Table: src.def.Name, Kind: SchemaModified}
+
+	// The desired definition is the source's, matched to the target's
+	// columns by name
+	to := TableDef{Name: dst.def.Name}
+	for _, col := range src.def.Columns {
+		col.Original = ""
+		if hasColumn(dst.def, col.Name) {
+			col.Original = col.Name
+		}
+		to.Columns = append(to.Columns, col)
+	}
+	reordered := false
+	if c.Config.Type == DatabaseTypePostgres {
+		// PostgreSQL can't move columns, so only report the difference
+		var kept []ColumnDef
+		kept, reordered = keepColumnOrder(dst.def, to.Columns)
+		to.Columns = kept
+	}
+
+	diff := diffTables(dst.def, to)
+	for _, col := range diff.Added {
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaAdded, Object: "column", Name: col.Name, Detail: columnSummary(col)})
+	}
+	for _, col := range diff.Dropped {
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaRemoved, Object: "column", Name: col.Name, Detail: columnSummary(col)})
+	}
+	for _, m := range diff.Modified {
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaModified, Object: "column", Name: m.To.Name, Detail: columnChanges(m.From, m.To)})
+	}
+	if diff.Reordered || diff.AddedInside {
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaModified, Object: "column order"})
+	}
+	if reordered {
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaModified, Object: "column order", Detail: "PostgreSQL can't reorder columns, not migrated"})
+	}
+	if diff.PKChanged {
+		td.Changes = append(td.Changes, SchemaChange{
+			Kind:   SchemaModified,
+			Object: "primary key",
+			Detail: fmt.Sprintf("(%s) -> (%s)", strings.Join(dst.def.PrimaryKey(), ", "), strings.Join(diff.PrimaryKey, ", ")),
+		})
+	}
+	for _, name := range diff.UniqueAdded {
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaAdded, Object: "unique", Name: name})
+	}
+	for _, col := range diff.UniqueDropped {
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaRemoved, Object: "unique", Name: col.Name})
+	}
+	if !diff.Empty() {
+		statements, err := c.Driver.AlterTableQueries(dst.def, to, diff)
+		if err != nil {
+			return td, err
+		}
+		td.add(phaseAlterTables, statements...)
+	}
+
+	// Single-column unique indexes are covered by the columns above
+	srcSkip := columnUniqueIndexes(src.def)
+	dstSkip := columnUniqueIndexes(dst.def)
+	dstIndexes := make(map[string]*Index)
+	for i, idx := range dst.indexes {
+		if !idx.Primary && !dstSkip[idx.Name] {
+			dstIndexes[idx.Name] = &dst.indexes[i]
+		}
+	}
+	for i, idx := range src.indexes {
+		if idx.Primary || srcSkip[idx.Name] {
+			continue
+		}
+		c.diffIndex(&td, &src.indexes[i], dstIndexes[idx.Name])
+		delete(dstIndexes, idx.Name)
+	}
+	for _, name := range sortedKeys(dstIndexes) {
+		c.diffIndex(&td, nil, dstIndexes[name])
+	}
+
+	dstKeys := make(map[string]*ForeignKey)
+	for i, fk := range dst.fks {
+		dstKeys[fk.Name] = &dst.fks[i]
+	}
+	for i, fk := range src.fks {
+		c.diffForeignKey(&td, &src.fks[i], dstKeys[fk.Name])
+		delete(dstKeys, fk.Name)
+	}
+	for _, name := range sortedKeys(dstKeys) {
+		c.diffForeignKey(&td, nil, dstKeys[name])
+	}
+
+	dstChecks := make(map[string]*CheckConstraint)
+	for i, cc := range dst.checks {
+		dstChecks[cc.Name] = &dst.checks[i]
+	}
+	for i, cc := range src.checks {
+		c.diffCheck(&td, &src.checks[i], dstChecks[cc.Name])
+		delete(dstChecks, cc.Name)
+	}
+	for _, name := range sortedKeys(dstChecks) {
+		c.diffCheck(&td, nil, dstChecks[name])
+	}
+
+	return td, nil
+}
+
+// diffIndex compares an index in the source (want) with the target (have);
+// either may be nil
+func (c *Connection) diffIndex(td *TableSchemaDiff, want, have *Index) {
+	create := func() {
+		for _, col := range want.Columns {
+			if col == "(expression)" {
+				td.Changes = append(td.Changes, SchemaChange{Kind: SchemaModified, Object: "index", Name: want.Name, Detail: "expression index, create it by hand"})
+				return
+			}
+		}
+		td.add(phaseCreateIndexes, c.createIndexQuery(td.Table, want.Name, want.Columns, want.Unique))
+	}
+
+	switch {
+	case have == nil:
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaAdded, Object: "index", Name: want.Name, Detail: indexSummary(*want)})
+		create()
+	case want == nil:
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaRemoved, Object: "index", Name: have.Name, Detail: indexSummary(*have)})
+		td.add(phaseDropIndexes, c.Driver.DropIndexQuery(td.Table, have.Name))
+	case indexSummary(*want) != indexSummary(*have):
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaModified, Object: "index", Name: want.Name, Detail: indexSummary(*have) + " -> " + indexSummary(*want)})
+		td.add(phaseDropIndexes, c.Driver.DropIndexQuery(td.Table, have.Name))
+		create()
+	}
+}
+
+// diffForeignKey compares a foreign key in the source (want) with the target (have)
+func (c *Connection) diffForeignKey(td *TableSchemaDiff, want, have *ForeignKey) {
+	switch {
+	case have == nil:
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaAdded, Object: "foreign key", Name: want.Name, Detail: foreignKeySummary(*want)})
+		td.add(phaseAddConstraints, c.addForeignKeyQuery(td.Table, *want))
+	case want == nil:
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaRemoved, Object: "foreign key", Name: have.Name, Detail: foreignKeySummary(*have)})
+		td.add(phaseDropForeignKeys, c.Driver.DropForeignKeyQuery(td.Table, have.Name))
+	case foreignKeySummary(*want) != foreignKeySummary(*have):
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaModified, Object: "foreign key", Name: want.Name, Detail: foreignKeySummary(*have) + " -> " + foreignKeySummary(*want)})
+		td.add(phaseDropForeignKeys, c.Driver.DropForeignKeyQuery(td.Table, have.Name))
+		td.add(phaseAddConstraints, c.addForeignKeyQuery(td.Table, *want))
+	}
+}
+
+// diffCheck compares a check constraint in the source (want) with the target (have)
+func (c *Connection) diffCheck(td *TableSchemaDiff, want, have *CheckConstraint) {
+	table := c.QuoteIdentifier(td.Table)
+	switch {
+	case have == nil:
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaAdded, Object: "check", Name: want.Name, Detail: want.Clause})
+		td.add(phaseAddConstraints, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s)", table, c.QuoteIdentifier(want.Name), want.Clause))
+	case want == nil:
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaRemoved, Object: "check", Name: have.Name, Detail: have.Clause})
+		td.add(phaseAlterTables, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, c.QuoteIdentifier(have.Name)))
+	case want.Clause != have.Clause:
+		td.Changes = append(td.Changes, SchemaChange{Kind: SchemaModified, Object: "check", Name: want.Name, Detail: have.Clause + " -> " + want.Clause})
+		td.add(phaseAlterTables, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, c.QuoteIdentifier(have.Name)))
+		td.add(phaseAddConstraints, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s)", table, c.QuoteIdentifier(want.Name), want.Clause))
+	}
+}
+
+// addForeignKeyQuery builds an ADD CONSTRAINT ... FOREIGN KEY statement
+func (c *Connection) addForeignKeyQuery(table string, fk ForeignKey) string {
+	query := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		c.QuoteIdentifier(table), c.QuoteIdentifier(fk.Name), c.quoteIdentifiers(fk.Columns),
+		c.QuoteIdentifier(fk.RefTable), c.quoteIdentifiers(fk.RefColumns))
+	if fk.OnUpdate != "" {
+		query += " ON UPDATE " + fk.OnUpdate
+	}
+	if fk.OnDelete != "" {
+		query += " ON DELETE " + fk.OnDelete
+	}
+	return query
+}
+
+// quoteIdentifiers quotes and joins identifiers
+func (c *Connection) quoteIdentifiers(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = c.QuoteIdentifier(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// keepColumnOrder puts existing columns in the target's order followed by
+// new columns, and reports whether that differs from the wanted order
+func keepColumnOrder(from TableDef, cols []ColumnDef) ([]ColumnDef, bool) {
+	byOriginal := make(map[string]ColumnDef)
+	var added []ColumnDef
+	for _, col := range cols {
+		if col.Original == "" {
+			added = append(added, col)
+		} else {
+			byOriginal[col.Original] = col
+		}
+	}
+
+	var kept []ColumnDef
+	for _, col := range from.Columns {
+		if want, ok := byOriginal[col.Name]; ok {
+			kept = append(kept, want)
+		}
+	}
+	kept = append(kept, added...)
+
+	for i := range kept {
+		if kept[i].Name != cols[i].Name {
+			return kept, true
+		}
+	}
+	return kept, false
+}
+
+// columnUniqueIndexes returns the names of single-column unique indexes,
+// which the column comparison already covers
+func columnUniqueIndexes(def TableDef) map[string]bool {
+	names := make(map[string]bool)
+	for _, col := range def.Columns {
+		if col.UniqueIndex != "" {
+			names[col.UniqueIndex] = true
+		}
+	}
+	return names
+}
+
+// columnSummary renders a column like "INT(11) NOT NULL DEFAULT 0"
+func columnSummary(col ColumnDef) string {
+	s := col.Type
+	if !col.Nullable {
+		s += " NOT NULL"
+	}
+	if col.Default != "" {
+		s += " DEFAULT " + col.Default
+	}
+	if col.AutoIncrement {
+		s += " AUTO_INCREMENT"
+	}
+	return s
+}
+
+// columnChanges describes what changed in a column definition
+func columnChanges(from, to ColumnDef) string {
+	var parts []string
+	if !strings.EqualFold(strings.TrimSpace(from.Type), strings.TrimSpace(to.Type)) {
+		parts = append(parts, fmt.Sprintf("type %s -> %s", from.Type, to.Type))
+	}
+	if from.Nullable != to.Nullable {
+		if to.Nullable {
+			parts = append(parts, "NOT NULL -> NULL")
+		} else {
+			parts = append(parts, "NULL -> NOT NULL")
+		}
+	}
+	if from.Default != to.Default {
+		parts = append(parts, fmt.Sprintf("default %s -> %s", orNone(from.Default), orNone(to.Default)))
+	}
+	if from.AutoIncrement != to.AutoIncrement {
+		if to.AutoIncrement {
+			parts = append(parts, "auto-increment added")
+		} else {
+			parts = append(parts, "auto-increment removed")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func indexSummary(idx Index) string {
+	s := "(" + strings.Join(idx.Columns, ", ") + ")"
+	if idx.Unique {
+		s = "UNIQUE " + s
+	}
+	return s
+}
+
+func foreignKeySummary(fk ForeignKey) string {
+	s := fmt.Sprintf("(%s) -> %s (%s)", strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "))
+	if fk.OnUpdate != "" {
+		s += " ON UPDATE " + fk.OnUpdate
+	}
+	if fk.OnDelete != "" {
+		s += " ON DELETE " + fk.OnDelete
+	}
+	return s
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,151 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+)
+
+// Script is SQL run before or after an export or restore, given inline or
+// as a file
+type Script struct {
+	SQL  string `yaml:"sql,omitempty"`
+	File string `yaml:"file,omitempty"`
+}
+
+// Name identifies the script in reports: its file, or the start of its SQL
+func (s Script) Name() string {
+	if s.File != "" {
+		return s.File
+	}
+	return truncateSQL(strings.Join(strings.Fields(s.SQL), " "))
+}
+
+// OperationScripts are the scripts run around one kind of operation
+type OperationScripts struct {
+	Before []Script `yaml:"before,omitempty"`
+	After  []Script `yaml:"after,omitempty"`
+}
+
+// Empty reports whether there is nothing to run
+func (o OperationScripts) Empty() bool {
+	return len(o.Before) == 0 && len(o.After) == 0
+}
+
+// Merge returns the scripts of o followed by those of other
+func (o OperationScripts) Merge(other OperationScripts) OperationScripts {
+	return OperationScripts{
+		Before: append(append([]Script(nil), o.Before...), other.Before...),
+		After:  append(append([]Script(nil), o.After...), other.After...),
+	}
+}
+
+// Script phases
+const (
+	ScriptBefore = "before"
+	ScriptAfter  = "after"
+)
+
+// ScriptResult records one script run
+type ScriptResult struct {
+	Script     string        `json:"script"`
+	Phase      string        `json:"phase"`
+	Statements int           `json:"statements"` // Statements that ran successfully
+	Duration   time.Duration `json:"duration_ns"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// runScripts runs scripts in order, stopping at the first failing statement.
+// Every script attempted is recorded, the failed one included.
+func (c *Connection) runScripts(phase string, scripts []Script) ([]ScriptResult, error) {
+	var results []ScriptResult
+	for _, script := range scripts {
+		start := time.Now()
+		result := ScriptResult{Script: script.Name(), Phase: phase}
+
+		count, err := c.runScript(script)
+		result.Statements = count
+		result.Duration = time.Since(start)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			return results, fmt.Errorf("%s script %s failed: %w", phase, script.Name(), err)
+		}
+		logging.Debug("Ran %s script %s: %d statements", phase, script.Name(), count)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// runScript executes a script's statements, returning how many succeeded
+func (c *Connection) runScript(script Script) (int, error) {
+	sql := script.SQL
+	if script.File != "" {
+		data, err := os.ReadFile(script.File)
+		if err != nil {
+			return 0, err
+		}
+		sql = string(data)
+	}
+
+	parser := newSQLParser(bufio.NewReader(strings.NewReader(sql)), 64*1024*1024)
+	count := 0
+	for {
+		stmt, _, err := parser.NextStatement()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		stmt = strings.TrimSpace(stmt)
+		if stmt == "" || stmt == ";" {
+			continue
+		}
+		if _, err := c.DB.Exec(stmt); err != nil {
+			return count, fmt.Errorf("%s: %w", truncateSQL(stmt), err)
+		}
+		count++
+	}
+}
+
+// withScripts runs the before scripts, the operation, then the after scripts.
+// A failing before script stops the operation from running. After scripts
+// run even when the operation failed, so they can undo what the before
+// scripts changed; the operation's error wins over theirs.
+func (c *Connection) withScripts(scripts OperationScripts, operation func() error) ([]ScriptResult, error) {
+	results, err := c.runScripts(ScriptBefore, scripts.Before)
+	if err != nil {
+		return results, err
+	}
+	opErr := operation()
+	after, err := c.runScripts(ScriptAfter, scripts.After)
+	results = append(results, after...)
+	if opErr != nil {
+		return results, opErr
+	}
+	return results, err
+}
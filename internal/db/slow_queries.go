@@ -0,0 +1,362 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryStat aggregates the executions of one query fingerprint
+type QueryStat struct {
+	Fingerprint  string
+	Example      string // One of the original statements
+	Calls        int64
+	TotalTime    time.Duration
+	MaxTime      time.Duration
+	RowsSent     int64
+	RowsExamined int64 // Only reported by MariaDB/MySQL
+}
+
+// MeanTime returns the average execution time
+func (q *QueryStat) MeanTime() time.Duration {
+	if q.Calls == 0 {
+		return 0
+	}
+	return q.TotalTime / time.Duration(q.Calls)
+}
+
+// QueryStatSort orders query statistics
+type QueryStatSort int
+
+const (
+	SortByTotalTime QueryStatSort = iota
+	SortByMeanTime
+	SortByCalls
+)
+
+// String returns the sort's display name
+func (s QueryStatSort) String() string {
+	switch s {
+	case SortByMeanTime:
+		return "mean time"
+	case SortByCalls:
+		return "calls"
+	default:
+		return "total time"
+	}
+}
+
+// SlowQueryReport is the aggregated slow query workload
+type SlowQueryReport struct {
+	Source  string // mysql.slow_log, pg_stat_statements or the log file path
+	Queries []QueryStat
+}
+
+// Sort orders the queries, highest first
+func (r *SlowQueryReport) Sort(by QueryStatSort) {
+	sort.SliceStable(r.Queries, func(i, j int) bool {
+		a, b := &r.Queries[i], &r.Queries[j]
+		switch by {
+		case SortByMeanTime:
+			return a.MeanTime() > b.MeanTime()
+		case SortByCalls:
+			return a.Calls > b.Calls
+		default:
+			return a.TotalTime > b.TotalTime
+		}
+	})
+}
+
+// Fingerprint normalization, in the spirit of pt-fingerprint
+var (
+	fpCommentRe     = regexp.MustCompile(`(?s)/\*.*?\*/|--[^\n]*`)
+	fpStringRe      = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'`)
+	fpNumberRe      = regexp.MustCompile(`\b(?:0x[0-9a-fA-F]+|\d+(?:\.\d+)?(?:[eE][+-]?\d+)?)\b`)
+	fpPlaceholderRe = regexp.MustCompile(`\$\d+`)
+	fpListRe        = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+	fpRowsRe        = regexp.MustCompile(`\(\?\+\)(?:\s*,\s*\(\?\+\))+`)
+	fpSpaceRe       = regexp.MustCompile(`\s+`)
+)
+
+// FingerprintQuery normalizes a statement so executions that differ only in
+// their literals group together: literals become ?, IN lists and multi-row
+// VALUES collapse to (?+), and case and whitespace are normalized.
+func FingerprintQuery(query string) string {
+	fp := fpCommentRe.ReplaceAllString(query, " ")
+	fp = fpStringRe.ReplaceAllString(fp, "?")
+	fp = fpPlaceholderRe.ReplaceAllString(fp, "?")
+	fp = fpNumberRe.ReplaceAllString(fp, "?")
+	fp = fpListRe.ReplaceAllString(fp, "(?+)")
+	fp = fpRowsRe.ReplaceAllString(fp, "(?+)")
+	fp = fpSpaceRe.ReplaceAllString(fp, " ")
+	return strings.ToLower(strings.TrimRight(strings.TrimSpace(fp), "; "))
+}
+
+// queryAggregator groups statements by fingerprint
+type queryAggregator struct {
+	byFingerprint map[string]*QueryStat
+	order         []string
+}
+
+func newQueryAggregator() *queryAggregator {
+	return &queryAggregator{byFingerprint: make(map[string]*QueryStat)}
+}
+
+func (a *queryAggregator) add(query string, calls int64, total, maxTime time.Duration, rowsSent, rowsExamined int64) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return
+	}
+	fp := FingerprintQuery(query)
+	stat, ok := a.byFingerprint[fp]
+	if !ok {
+		stat = &QueryStat{Fingerprint: fp, Example: query}
+		a.byFingerprint[fp] = stat
+		a.order = append(a.order, fp)
+	}
+	stat.Calls += calls
+	stat.TotalTime += total
+	stat.MaxTime = max(stat.MaxTime, maxTime)
+	stat.RowsSent += rowsSent
+	stat.RowsExamined += rowsExamined
+}
+
+func (a *queryAggregator) report(source string) *SlowQueryReport {
+	report := &SlowQueryReport{Source: source, Queries: make([]QueryStat, 0, len(a.order))}
+	for _, fp := range a.order {
+		report.Queries = append(report.Queries, *a.byFingerprint[fp])
+	}
+	report.Sort(SortByTotalTime)
+	return report
+}
+
+// Slow log parsing
+var (
+	slowLogFieldRe    = regexp.MustCompile(`(\w+): (\S+)`)
+	slowLogPreambleRe = regexp.MustCompile(`^(?:\S.*, Version: |Tcp port: |Time\s+Id\s+Command)`)
+	slowLogSkipRe     = regexp.MustCompile(`(?i)^(?:SET timestamp=\d+;|use \S+;)$`)
+)
+
+// ParseSlowLog aggregates a MariaDB/MySQL slow query log
+func ParseSlowLog(r io.Reader, source string) (*SlowQueryReport, error) {
+	agg := newQueryAggregator()
+
+	var query strings.Builder
+	var queryTime time.Duration
+	var rowsSent, rowsExamined int64
+	inEntry := false
+	flush := func() {
+		if query.Len() > 0 {
+			agg.add(query.String(), 1, queryTime, queryTime, rowsSent, rowsExamined)
+		}
+		query.Reset()
+		queryTime, rowsSent, rowsExamined = 0, 0, 0
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "#"):
+			if query.Len() > 0 {
+				flush()
+			}
+			inEntry = true
+			for _, m := range slowLogFieldRe.FindAllStringSubmatch(line, -1) {
+				switch m[1] {
+				case "Query_time":
+					if secs, err := strconv.ParseFloat(m[2], 64); err == nil {
+						queryTime = time.Duration(secs * float64(time.Second))
+					}
+				case "Rows_sent":
+					rowsSent, _ = strconv.ParseInt(m[2], 10, 64)
+				case "Rows_examined":
+					rowsExamined, _ = strconv.ParseInt(m[2], 10, 64)
+				}
+			}
+		case !inEntry, slowLogPreambleRe.MatchString(line):
+			// Server banner, written at startup and on log rotation
+		case query.Len() == 0 && slowLogSkipRe.MatchString(strings.TrimSpace(line)):
+		default:
+			query.WriteString(line)
+			query.WriteByte('\n')
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read slow query log: %w", err)
+	}
+	flush()
+
+	return agg.report(source), nil
+}
+
+// ReadSlowLog aggregates a slow query log file
+func ReadSlowLog(path string) (*SlowQueryReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open slow query log: %w", err)
+	}
+	defer f.Close()
+	return ParseSlowLog(f, path)
+}
+
+// SlowQueryStats aggregates the server's slow query statistics: the
+// mysql.slow_log table or pg_stat_statements, falling back to the slow log
+// file of a MariaDB server running on this machine
+func (c *Connection) SlowQueryStats() (*SlowQueryReport, error) {
+	source := "mysql.slow_log"
+	if c.Config.Type == DatabaseTypePostgres {
+		source = "pg_stat_statements"
+	}
+
+	var report *SlowQueryReport
+	var lastErr error
+	for _, query := range c.Driver.SlowQueryStatsQueries() {
+		if report, lastErr = c.querySlowQueryStats(query, source); lastErr == nil {
+			break
+		}
+	}
+	if report != nil && len(report.Queries) > 0 {
+		return report, nil
+	}
+
+	if path := c.slowQueryLogFile(); path != "" {
+		if fileReport, err := ReadSlowLog(path); err == nil {
+			return fileReport, nil
+		} else if report == nil {
+			lastErr = err
+		}
+	}
+
+	if report == nil {
+		if c.Config.Type == DatabaseTypePostgres {
+			return nil, fmt.Errorf("pg_stat_statements is unavailable (add it to shared_preload_libraries and run CREATE EXTENSION pg_stat_statements): %w", lastErr)
+		}
+		return nil, fmt.Errorf("failed to read slow queries: %w", lastErr)
+	}
+	return report, nil
+}
+
+func (c *Connection) querySlowQueryStats(query, source string) (*SlowQueryReport, error) {
+	rows, err := c.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	agg := newQueryAggregator()
+	for rows.Next() {
+		var text string
+		var calls, rowsSent, rowsExamined int64
+		var totalMs, maxMs float64
+		if err := rows.Scan(&text, &calls, &totalMs, &maxMs, &rowsSent, &rowsExamined); err != nil {
+			return nil, err
+		}
+		agg.add(text, calls, time.Duration(totalMs*float64(time.Millisecond)),
+			time.Duration(maxMs*float64(time.Millisecond)), rowsSent, rowsExamined)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return agg.report(source), nil
+}
+
+// slowQueryLogFile returns the path of the server's slow query log when it
+// can be read from here
+func (c *Connection) slowQueryLogFile() string {
+	query := c.Driver.SlowQueryLogFileQuery()
+	if query == "" || !c.isLocal() {
+		return ""
+	}
+	var path string
+	if err := c.DB.QueryRow(query).Scan(&path); err != nil || path == "" {
+		return ""
+	}
+	if !filepath.IsAbs(path) {
+		var dataDir string
+		if err := c.DB.QueryRow(c.Driver.DataDirectoryQuery()).Scan(&dataDir); err != nil {
+			return ""
+		}
+		path = filepath.Join(dataDir, path)
+	}
+	return path
+}
+
+// slowQueryJSON is the exported form of a QueryStat, with times in milliseconds
+type slowQueryJSON struct {
+	Fingerprint  string  `json:"fingerprint"`
+	Example      string  `json:"example"`
+	Calls        int64   `json:"calls"`
+	TotalTimeMs  float64 `json:"total_time_ms"`
+	MeanTimeMs   float64 `json:"mean_time_ms"`
+	MaxTimeMs    float64 `json:"max_time_ms"`
+	RowsSent     int64   `json:"rows_sent"`
+	RowsExamined int64   `json:"rows_examined"`
+}
+
+// WriteJSON writes the report as JSON
+func (r *SlowQueryReport) WriteJSON(w io.Writer) error {
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	out := struct {
+		Source  string          `json:"source"`
+		Queries []slowQueryJSON `json:"queries"`
+	}{Source: r.Source, Queries: make([]slowQueryJSON, len(r.Queries))}
+	for i, q := range r.Queries {
+		out.Queries[i] = slowQueryJSON{
+			Fingerprint:  q.Fingerprint,
+			Example:      q.Example,
+			Calls:        q.Calls,
+			TotalTimeMs:  ms(q.TotalTime),
+			MeanTimeMs:   ms(q.MeanTime()),
+			MaxTimeMs:    ms(q.MaxTime),
+			RowsSent:     q.RowsSent,
+			RowsExamined: q.RowsExamined,
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// ExportJSON writes the report to a JSON file
+func (r *SlowQueryReport) ExportJSON(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := r.WriteJSON(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,521 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSnapshotRows is the per-table row cap when a snapshot config sets none
+const DefaultSnapshotRows = 100
+
+// autoIncrementPattern matches the table option MariaDB bumps on every insert
+var autoIncrementPattern = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+
+// SnapshotTable overrides the snapshot settings of one table
+type SnapshotTable struct {
+	Rows *int   `yaml:"rows,omitempty"` // Row cap; 0 keeps the structure only
+	Seed *int64 `yaml:"seed,omitempty"` // Sampling seed
+}
+
+// SnapshotConfig describes a deterministic, anonymized CI snapshot, usually
+// loaded from YAML
+type SnapshotConfig struct {
+	Seed        int64                    `yaml:"seed"`
+	DefaultRows *int                     `yaml:"default_rows,omitempty"`
+	Tables      map[string]SnapshotTable `yaml:"tables,omitempty"`
+	Masking     *MaskingConfig           `yaml:"masking,omitempty"`
+}
+
+// LoadSnapshotConfig reads a snapshot config file
+func LoadSnapshotConfig(path string) (*SnapshotConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot config: %w", err)
+	}
+
+	var sc SnapshotConfig
+	if err := yaml.Unmarshal(data, &sc); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot config: %w", err)
+	}
+
+	if sc.DefaultRows != nil && *sc.DefaultRows < 0 {
+		return nil, fmt.Errorf("snapshot default_rows can't be negative")
+	}
+	for name, t := range sc.Tables {
+		if t.Rows != nil && *t.Rows < 0 {
+			return nil, fmt.Errorf("snapshot table %s: rows can't be negative", name)
+		}
+	}
+	if sc.Masking != nil {
+		if err := sc.Masking.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &sc, nil
+}
+
+// rowsFor returns the row cap of a table
+func (sc *SnapshotConfig) rowsFor(table string) int {
+	if t, ok := sc.Tables[table]; ok && t.Rows != nil {
+		return *t.Rows
+	}
+	if sc.DefaultRows != nil {
+		return *sc.DefaultRows
+	}
+	return DefaultSnapshotRows
+}
+
+// seedFor returns the sampling seed of a table
+func (sc *SnapshotConfig) seedFor(table string) int64 {
+	if t, ok := sc.Tables[table]; ok && t.Seed != nil {
+		return *t.Seed
+	}
+	return sc.Seed
+}
+
+// SnapshotOptions configures ExportSnapshot
+type SnapshotOptions struct {
+	FilePath   string
+	Database   string
+	Config     *SnapshotConfig
+	BatchSize  int // Rows per INSERT batch (0 = default 1000)
+	OnProgress func(currentTable string, tableNum, totalTables int)
+}
+
+// SnapshotTableStats is the outcome for one table of a snapshot
+type SnapshotTableStats struct {
+	Table   string
+	Rows    int
+	Dropped int // Sampled rows removed because a referenced row wasn't sampled
+}
+
+// SnapshotStats summarizes a snapshot export
+type SnapshotStats struct {
+	Tables       []SnapshotTableStats // In the order they were written
+	RowsExported int64
+	BytesWritten int64
+	OutputFile   string
+	Warnings     []string
+}
+
+// snapshotTable is a table being sampled
+type snapshotTable struct {
+	name    string
+	columns []string
+	index   map[string]int
+	pk      []string
+	fks     []ForeignKey
+	rows    [][]interface{}
+	dropped int
+}
+
+// ExportSnapshot writes a small sample of a database that is the same on
+// every run against the same data: rows are picked by a seeded hash of their
+// key, written in key order, and rows whose foreign keys point at rows that
+// weren't picked are left out. Nothing time-dependent goes in the file, so
+// it can be committed and loaded by CI with ysm import.
+func (c *Connection) ExportSnapshot(opts SnapshotOptions) (*SnapshotStats, error) {
+	sc := opts.Config
+	if sc == nil {
+		sc = &SnapshotConfig{}
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+
+	if opts.Database != "" {
+		if err := c.UseDatabase(opts.Database); err != nil {
+			return nil, err
+		}
+	}
+
+	tables, err := c.loadSnapshotTables()
+	if err != nil {
+		return nil, err
+	}
+	for name := range sc.Tables {
+		if tables[name] == nil {
+			return nil, fmt.Errorf("snapshot config names table %s, which doesn't exist", name)
+		}
+	}
+	order := snapshotOrder(tables)
+
+	stats := &SnapshotStats{OutputFile: opts.FilePath}
+	stats.Warnings = snapshotMaskWarnings(tables, order, sc.Masking)
+
+	// Parents come first, so children only sample rows whose parents made it
+	for i, name := range order {
+		if opts.OnProgress != nil {
+			opts.OnProgress(name, i+1, len(order))
+		}
+		if err := c.sampleSnapshotTable(tables[name], tables, sc); err != nil {
+			return nil, err
+		}
+	}
+
+	// Self-references and cycles can't be filtered while sampling
+	pruneSnapshotRows(tables, order)
+
+	file, err := os.Create(opts.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	fmt.Fprintf(w, "-- YSM (Yandere SQL Manager) CI Snapshot\n")
+	fmt.Fprintf(w, "-- Database: %s\n", opts.Database)
+	fmt.Fprintf(w, "-- Type: %s\n", c.Config.Type)
+	fmt.Fprintf(w, "-- Seed: %d\n", sc.Seed)
+	fmt.Fprintf(w, "-- Deterministic: the same data and config always produce this file\n")
+	fmt.Fprintf(w, "-- \"I'll never let your databases go~\"\n\n")
+	fmt.Fprintf(w, "%s\n", c.Driver.ExportHeader())
+
+	for _, name := range order {
+		t := tables[name]
+		fmt.Fprintf(w, "-- --------------------------------------------------------\n")
+		fmt.Fprintf(w, "-- Table structure for table %s\n", c.QuoteIdentifier(name))
+		fmt.Fprintf(w, "-- --------------------------------------------------------\n\n")
+
+		createStmt, err := c.getCreateTable(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get CREATE TABLE for %s: %w", name, err)
+		}
+		fmt.Fprintf(w, "DROP TABLE IF EXISTS %s;\n", c.QuoteIdentifier(name))
+		fmt.Fprintf(w, "%s;\n\n", autoIncrementPattern.ReplaceAllString(createStmt, ""))
+
+		c.writeSnapshotRows(w, t, sc.Masking, opts.BatchSize)
+		stats.Tables = append(stats.Tables, SnapshotTableStats{Table: name, Rows: len(t.rows), Dropped: t.dropped})
+		stats.RowsExported += int64(len(t.rows))
+	}
+
+	fmt.Fprintf(w, "\n%s", c.Driver.ExportFooter())
+	if err := w.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if info, err := file.Stat(); err == nil {
+		stats.BytesWritten = info.Size()
+	}
+	return stats, nil
+}
+
+// loadSnapshotTables reads the columns, primary key and foreign keys of
+// every table in the current database
+func (c *Connection) loadSnapshotTables() (map[string]*snapshotTable, error) {
+	list, err := c.ListTables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	tables := make(map[string]*snapshotTable, len(list))
+	for _, tbl := range list {
+		def, err := c.LoadTableDef(tbl.Name)
+		if err != nil {
+			return nil, err
+		}
+		t := &snapshotTable{name: tbl.Name, index: make(map[string]int), pk: def.PrimaryKey()}
+		for i, col := range def.Columns {
+			t.columns = append(t.columns, col.Name)
+			t.index[col.Name] = i
+		}
+		if t.fks, err = c.ListForeignKeys(tbl.Name); err != nil {
+			return nil, err
+		}
+		tables[tbl.Name] = t
+	}
+
+	// Keys to tables outside the database can't be checked
+	for _, t := range tables {
+		kept := t.fks[:0]
+		for _, fk := range t.fks {
+			if tables[fk.RefTable] != nil {
+				kept = append(kept, fk)
+			}
+		}
+		t.fks = kept
+	}
+	return tables, nil
+}
+
+// snapshotOrder sorts tables so referenced tables come first, breaking ties
+// and cycles by name
+func snapshotOrder(tables map[string]*snapshotTable) []string {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	done := make(map[string]bool, len(names))
+	order := make([]string, 0, len(names))
+	for len(order) < len(names) {
+		next := ""
+		for _, name := range names {
+			if done[name] {
+				continue
+			}
+			if next == "" {
+				next = name // Fallback when every remaining table is in a cycle
+			}
+			ready := true
+			for _, fk := range tables[name].fks {
+				if fk.RefTable != name && !done[fk.RefTable] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				next = name
+				break
+			}
+		}
+		done[next] = true
+		order = append(order, next)
+	}
+	return order
+}
+
+// sampleSnapshotTable picks a table's rows by seeded hash, limited to rows
+// whose foreign keys reference rows already picked from their parents
+func (c *Connection) sampleSnapshotTable(t *snapshotTable, tables map[string]*snapshotTable, sc *SnapshotConfig) error {
+	limit := sc.rowsFor(t.name)
+	if limit == 0 {
+		return nil
+	}
+
+	var args []interface{}
+	var conds []string
+	for _, fk := range t.fks {
+		parent := tables[fk.RefTable]
+		if fk.RefTable == t.name || (parent.rows == nil && sc.rowsFor(parent.name) != 0) {
+			continue // Not sampled yet, pruned afterwards
+		}
+		cond, condArgs := c.snapshotKeyFilter(fk.Columns, parent.keyValues(fk.RefColumns), len(args))
+		conds = append(conds, cond)
+		args = append(args, condArgs...)
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	// Without a key, rows that tie on the hash are identical, so hash order
+	// is already stable
+	var query string
+	if len(t.pk) == 0 {
+		query = fmt.Sprintf("SELECT * FROM %s%s ORDER BY MD5(CONCAT_WS('|', %d, %s)) LIMIT %d",
+			c.QuoteIdentifier(t.name), where, sc.seedFor(t.name), c.quoteIdentifiers(t.columns), limit)
+	} else {
+		pk := c.quoteIdentifiers(t.pk)
+		query = fmt.Sprintf("SELECT * FROM (SELECT * FROM %s%s ORDER BY MD5(CONCAT_WS('|', %d, %s)), %s LIMIT %d) AS snapshot ORDER BY %s",
+			c.QuoteIdentifier(t.name), where, sc.seedFor(t.name), pk, pk, limit, pk)
+	}
+
+	rows, err := c.DB.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to sample %s: %w", t.name, err)
+	}
+	defer rows.Close()
+
+	if t.columns, err = rows.Columns(); err != nil {
+		return err
+	}
+	for i, col := range t.columns {
+		t.index[col] = i
+	}
+
+	t.rows = [][]interface{}{}
+	for rows.Next() {
+		row := make([]interface{}, len(t.columns))
+		ptrs := make([]interface{}, len(row))
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return fmt.Errorf("failed to scan %s: %w", t.name, err)
+		}
+		t.rows = append(t.rows, row)
+	}
+	return rows.Err()
+}
+
+// snapshotKeyFilter matches rows whose key columns are NULL or one of keys,
+// numbering placeholders from n
+func (c *Connection) snapshotKeyFilter(columns []string, keys [][]interface{}, n int) (string, []interface{}) {
+	var alts []string
+	for _, col := range columns {
+		alts = append(alts, c.QuoteIdentifier(col)+" IS NULL")
+	}
+	if len(keys) == 0 {
+		return "(" + strings.Join(alts, " OR ") + ")", nil
+	}
+
+	var args []interface{}
+	tuples := make([]string, len(keys))
+	for i, key := range keys {
+		markers := make([]string, len(key))
+		for j, val := range key {
+			markers[j] = Placeholder(n+len(args), c.Config.Type)
+			args = append(args, val)
+		}
+		tuples[i] = strings.Join(markers, ", ")
+		if len(key) > 1 {
+			tuples[i] = "(" + tuples[i] + ")"
+		}
+	}
+	target := c.quoteIdentifiers(columns)
+	if len(columns) > 1 {
+		target = "(" + target + ")"
+	}
+	alts = append(alts, fmt.Sprintf("%s IN (%s)", target, strings.Join(tuples, ", ")))
+	return "(" + strings.Join(alts, " OR ") + ")", args
+}
+
+// keyValues returns the distinct non-NULL values of columns among the
+// sampled rows
+func (t *snapshotTable) keyValues(columns []string) [][]interface{} {
+	seen := make(map[string]bool)
+	var keys [][]interface{}
+	for _, row := range t.rows {
+		key, ok := t.key(row, columns)
+		if !ok {
+			continue
+		}
+		id := snapshotKeyString(key)
+		if !seen[id] {
+			seen[id] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// key extracts columns from a row, reporting false when any is NULL
+func (t *snapshotTable) key(row []interface{}, columns []string) ([]interface{}, bool) {
+	key := make([]interface{}, len(columns))
+	for i, col := range columns {
+		key[i] = row[t.index[col]]
+		if key[i] == nil {
+			return nil, false
+		}
+	}
+	return key, true
+}
+
+// snapshotKeyString turns a key into a map key; drivers may return the
+// same value as []byte on one side and string on the other
+func snapshotKeyString(key []interface{}) string {
+	parts := make([]string, len(key))
+	for i, val := range key {
+		if b, ok := val.([]byte); ok {
+			parts[i] = string(b)
+		} else {
+			parts[i] = fmt.Sprint(val)
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// pruneSnapshotRows drops rows referencing rows that weren't sampled until
+// every foreign key in the snapshot resolves
+func pruneSnapshotRows(tables map[string]*snapshotTable, order []string) {
+	for changed := true; changed; {
+		changed = false
+		for _, name := range order {
+			t := tables[name]
+			for _, fk := range t.fks {
+				parent := tables[fk.RefTable]
+				present := make(map[string]bool)
+				for _, key := range parent.keyValues(fk.RefColumns) {
+					present[snapshotKeyString(key)] = true
+				}
+
+				kept := t.rows[:0]
+				for _, row := range t.rows {
+					key, ok := t.key(row, fk.Columns)
+					if ok && !present[snapshotKeyString(key)] {
+						t.dropped++
+						changed = true
+						continue
+					}
+					kept = append(kept, row)
+				}
+				t.rows = kept
+			}
+		}
+	}
+}
+
+// snapshotMaskWarnings lists foreign keys whose two sides are masked
+// differently, which breaks the join in the snapshot
+func snapshotMaskWarnings(tables map[string]*snapshotTable, order []string, masking *MaskingConfig) []string {
+	var warnings []string
+	for _, name := range order {
+		for _, fk := range tables[name].fks {
+			for i, col := range fk.Columns {
+				child := masking.RuleFor(name, col)
+				parent := masking.RuleFor(fk.RefTable, fk.RefColumns[i])
+				if child == nil && parent == nil {
+					continue
+				}
+				if child != nil && parent != nil && child.Strategy == MaskHash && parent.Strategy == MaskHash {
+					continue
+				}
+				warnings = append(warnings, fmt.Sprintf("%s.%s references %s.%s but they aren't both masked with hash, so the join won't match",
+					name, col, fk.RefTable, fk.RefColumns[i]))
+			}
+		}
+	}
+	return warnings
+}
+
+// writeSnapshotRows writes a table's sampled rows as batched INSERTs
+func (c *Connection) writeSnapshotRows(w *bufio.Writer, t *snapshotTable, masking *MaskingConfig, batchSize int) {
+	if len(t.rows) == 0 {
+		return
+	}
+
+	masks := masking.columnMasks(t.name, t.columns)
+	fmt.Fprintf(w, "-- Dumping data for table %s\n\n", c.QuoteIdentifier(t.name))
+	for start := 0; start < len(t.rows); start += batchSize {
+		end := min(start+batchSize, len(t.rows))
+		values := make([]string, 0, end-start)
+		for _, row := range t.rows[start:end] {
+			formatted := make([]string, len(row))
+			for i, val := range row {
+				if masks != nil && masks[i] != nil {
+					val = masks[i].Apply(val)
+				}
+				formatted[i] = c.formatValueForExport(val)
+			}
+			values = append(values, "("+strings.Join(formatted, ", ")+")")
+		}
+		fmt.Fprintf(w, "INSERT INTO %s (%s) VALUES\n%s;\n\n",
+			c.QuoteIdentifier(t.name), c.quoteIdentifiers(t.columns), strings.Join(values, ",\n"))
+	}
+}
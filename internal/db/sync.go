@@ -0,0 +1,182 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import "fmt"
+
+// SyncDatabases makes the target database match the source. Structure sync
+// creates missing tables and applies schema changes; data sync inserts and
+// updates the rows that differ and deletes the rows the source doesn't have.
+// Tables only in the target are left alone. With DryRun nothing is changed
+// and the result reports what would be.
+func (c *Connection) SyncDatabases(opts SyncOptions) (*SyncResult, error) {
+	result := &SyncResult{}
+	progress := func(table, action string) {
+		if opts.OnProgress != nil {
+			opts.OnProgress(table, action)
+		}
+	}
+
+	selected := make(map[string]bool, len(opts.Tables))
+	for _, t := range opts.Tables {
+		selected[t] = true
+	}
+	wanted := func(table string) bool {
+		return len(selected) == 0 || selected[table]
+	}
+
+	// Schema sync can drop columns and data sync deletes rows
+	if err := c.CheckDatabaseProtected(opts.TargetDB, "sync into"); err != nil {
+		return nil, err
+	}
+
+	target, err := c.openDatabase(opts.TargetDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", opts.TargetDB, err)
+	}
+	defer target.Close()
+	// One session, so the foreign key setting below covers every statement
+	target.DB.SetMaxOpenConns(1)
+
+	exec := func(stmt string) error {
+		if opts.DryRun {
+			return nil
+		}
+		if _, err := target.DB.Exec(stmt); err != nil {
+			return fmt.Errorf("%w\n%s", err, stmt)
+		}
+		return nil
+	}
+
+	created := make(map[string]bool)
+	modified := make(map[string]bool)
+
+	if opts.SyncMode != SyncDataOnly {
+		diff, err := c.DiffSchemas(opts.SourceDB, opts.TargetDB)
+		if err != nil {
+			return nil, err
+		}
+
+		var plan []TableSchemaDiff
+		for _, t := range diff.Tables {
+			if !wanted(t.Table) {
+				continue
+			}
+			switch t.Kind {
+			case SchemaRemoved:
+				result.TablesSkipped = append(result.TablesSkipped, t.Table)
+				continue
+			case SchemaAdded:
+				created[t.Table] = true
+				result.TablesCreated = append(result.TablesCreated, t.Table)
+			default:
+				modified[t.Table] = true
+				result.TablesModified = append(result.TablesModified, t.Table)
+			}
+			plan = append(plan, t)
+		}
+
+		// Same phase order as SchemaDiff.Migration
+		for phase := 0; phase < migrationPhases; phase++ {
+			for _, t := range plan {
+				for _, stmt := range t.phases[phase] {
+					if created[t.Table] {
+						progress(t.Table, "create")
+					} else {
+						progress(t.Table, "alter")
+					}
+					if err := exec(stmt); err != nil {
+						return result, fmt.Errorf("table %s: %w", t.Table, err)
+					}
+				}
+			}
+		}
+	}
+
+	if opts.SyncMode == SyncStructureOnly {
+		return result, nil
+	}
+
+	if !opts.DryRun {
+		target.DB.Exec(target.Driver.DisableForeignKeysSQL())
+		defer target.DB.Exec(target.Driver.EnableForeignKeysSQL())
+	}
+
+	dataOpts := DataDiffOptions{
+		Source: opts.SourceDB,
+		Target: opts.TargetDB,
+		Tables: opts.Tables,
+		OnProgress: func(table string, tableNum, totalTables int) {
+			progress(table, "compare")
+		},
+		OnStatement: func(kind RowDiffKind, stmt string) error {
+			switch kind {
+			case RowMissing:
+				result.RowsInserted++
+			case RowChanged:
+				result.RowsUpdated++
+			case RowExtra:
+				result.RowsDeleted++
+			}
+			return exec(stmt)
+		},
+	}
+	data, err := c.CompareData(dataOpts)
+	if err != nil {
+		return result, err
+	}
+
+	for _, t := range data.Tables {
+		switch {
+		case t.Skipped != "":
+			result.TablesSkipped = append(result.TablesSkipped, t.Table)
+		case !t.Identical() && !created[t.Table] && !modified[t.Table]:
+			modified[t.Table] = true
+			result.TablesModified = append(result.TablesModified, t.Table)
+		}
+	}
+
+	for _, table := range data.OnlyInSource {
+		// A dry run didn't create the table, so all its rows would be inserted
+		if created[table] {
+			count, err := c.countSourceRows(opts.SourceDB, table)
+			if err != nil {
+				return result, err
+			}
+			result.RowsInserted += count
+			continue
+		}
+		result.TablesSkipped = append(result.TablesSkipped, table)
+	}
+
+	return result, nil
+}
+
+// countSourceRows counts the rows of a table in another database
+func (c *Connection) countSourceRows(database, table string) (int64, error) {
+	src, err := c.openDatabase(database)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	var count int64
+	err = src.DB.QueryRow("SELECT COUNT(*) FROM " + src.QuoteIdentifier(table)).Scan(&count)
+	return count, err
+}
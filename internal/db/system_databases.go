@@ -0,0 +1,165 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrProtectedDatabase is returned when a drop or truncate would touch a
+// protected database while protection is locked
+var ErrProtectedDatabase = errors.New("protected database")
+
+// SystemDatabasePolicy decides which databases views list and which ones
+// destructive operations refuse to touch
+type SystemDatabasePolicy struct {
+	Show      bool     // List system databases in views and pickers
+	Protected []string // Protected in addition to the system databases
+	Unlocked  bool     // Allow dropping and truncating protected databases
+}
+
+var (
+	systemPolicyMu sync.RWMutex
+	systemPolicy   SystemDatabasePolicy
+)
+
+// SetSystemDatabasePolicy sets the policy used by every connection
+func SetSystemDatabasePolicy(policy SystemDatabasePolicy) {
+	systemPolicyMu.Lock()
+	defer systemPolicyMu.Unlock()
+	systemPolicy = policy
+}
+
+// GetSystemDatabasePolicy returns the policy used by every connection
+func GetSystemDatabasePolicy() SystemDatabasePolicy {
+	systemPolicyMu.RLock()
+	defer systemPolicyMu.RUnlock()
+	return systemPolicy
+}
+
+// DefaultSystemDatabases returns the server's own databases for a type
+func DefaultSystemDatabases(dbType DatabaseType) []string {
+	if isPostgresType(dbType) {
+		return []string{"postgres", "template0", "template1"}
+	}
+	return []string{"information_schema", "mysql", "performance_schema", "sys"}
+}
+
+// IsSystemDatabase reports whether name is one of the server's own
+// databases. These are hidden unless the policy shows them and are left out
+// of full backups.
+func (c *Connection) IsSystemDatabase(name string) bool {
+	return isSystemDatabase(name, c.Config.Type)
+}
+
+// IsProtectedDatabase reports whether name is a system database or listed
+// as protected in the policy
+func (c *Connection) IsProtectedDatabase(name string) bool {
+	if c.IsSystemDatabase(name) {
+		return true
+	}
+	for _, protected := range GetSystemDatabasePolicy().Protected {
+		if strings.EqualFold(name, protected) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckDatabaseProtected returns an ErrProtectedDatabase error when action
+// (e.g. "drop") would touch a protected database and protection is locked
+func (c *Connection) CheckDatabaseProtected(name, action string) error {
+	if name == "" || GetSystemDatabasePolicy().Unlocked || !c.IsProtectedDatabase(name) {
+		return nil
+	}
+	return fmt.Errorf("%w: refusing to %s %s", ErrProtectedDatabase, action, name)
+}
+
+// ListVisibleDatabases returns the databases views should list: all of them
+// when the policy shows system databases, otherwise all but those
+func (c *Connection) ListVisibleDatabases() ([]Database, error) {
+	databases, err := c.ListDatabases()
+	if err != nil || GetSystemDatabasePolicy().Show {
+		return databases, err
+	}
+
+	visible := databases[:0]
+	for _, d := range databases {
+		if !c.IsSystemDatabase(d.Name) {
+			visible = append(visible, d)
+		}
+	}
+	return visible, nil
+}
+
+var (
+	dropDatabaseRe   = regexp.MustCompile("(?is)^DROP\\s+(DATABASE|SCHEMA)\\s+(?:IF\\s+EXISTS\\s+)?[`\"]?([^`\"\\s;]+)")
+	tableStatementRe = regexp.MustCompile("(?is)^(?:TRUNCATE(?:\\s+TABLE)?|DROP\\s+TABLE(?:\\s+IF\\s+EXISTS)?)\\s+(?:ONLY\\s+)?[`\"]?([^`\"\\s.;,]+)[`\"]?(?:\\.[`\"]?([^`\"\\s.;,]+))?")
+)
+
+// CheckStatementProtected refuses SQL that drops a protected database or
+// drops or truncates a table in one. Unqualified tables are resolved
+// against the connected database.
+func (c *Connection) CheckStatementProtected(sql string) error {
+	if GetSystemDatabasePolicy().Unlocked {
+		return nil
+	}
+
+	for _, stmt := range strings.Split(sql, ";") {
+		stmt = strings.TrimSpace(stmt)
+
+		if m := dropDatabaseRe.FindStringSubmatch(stmt); m != nil {
+			// A PostgreSQL schema isn't a database
+			if strings.EqualFold(m[1], "SCHEMA") && isPostgresType(c.Config.Type) {
+				continue
+			}
+			if err := c.CheckDatabaseProtected(m[2], "drop"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if m := tableStatementRe.FindStringSubmatch(stmt); m != nil {
+			// MariaDB tables may be qualified with their database; on
+			// PostgreSQL the qualifier is a schema in the connected database
+			database := c.Config.Database
+			if m[2] != "" && !isPostgresType(c.Config.Type) {
+				database = m[1]
+			}
+			if err := c.CheckDatabaseProtected(database, "drop or truncate tables in"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isSystemDatabase reports whether name is a default system database
+func isSystemDatabase(name string, dbType DatabaseType) bool {
+	for _, system := range DefaultSystemDatabases(dbType) {
+		if strings.EqualFold(name, system) {
+			return true
+		}
+	}
+	return false
+}
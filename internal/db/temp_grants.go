@@ -0,0 +1,553 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TemporaryGrant is a privilege grant that YSM revokes once it expires
+type TemporaryGrant struct {
+	ID         string    `json:"id"`
+	Profile    string    `json:"profile,omitempty"` // Connection profile used by the scheduler to revoke
+	Server     string    `json:"server"`            // Server the grant was made on, see ServerKey
+	Username   string    `json:"username"`
+	Host       string    `json:"host,omitempty"`
+	Privileges []string  `json:"privileges"` // Only those the account didn't hold before
+	Database   string    `json:"database,omitempty"`
+	Table      string    `json:"table,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	GrantedAt  time.Time `json:"granted_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	RevokedAt  time.Time `json:"revoked_at,omitempty"`
+	LastError  string    `json:"last_error,omitempty"` // Last failed revocation attempt
+
+	// KeepConnect is set when a PostgreSQL account could already connect to
+	// Database, so revoking the grant leaves CONNECT alone
+	KeepConnect bool `json:"keep_connect,omitempty"`
+}
+
+// TemporaryGrantConfig holds all tracked temporary grants
+type TemporaryGrantConfig struct {
+	Grants []TemporaryGrant `json:"grants"`
+}
+
+// Revoked reports whether the grant has already been revoked
+func (g *TemporaryGrant) Revoked() bool {
+	return !g.RevokedAt.IsZero()
+}
+
+// Expired reports whether the grant is past its expiry but not yet revoked
+func (g *TemporaryGrant) Expired(now time.Time) bool {
+	return !g.Revoked() && !now.Before(g.ExpiresAt)
+}
+
+// Remaining returns the time left before the grant expires
+func (g *TemporaryGrant) Remaining() time.Duration {
+	if g.Revoked() {
+		return 0
+	}
+	if d := time.Until(g.ExpiresAt); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// Scope returns the object the grant applies to, e.g. "app.*"
+func (g *TemporaryGrant) Scope() string {
+	database, table := g.Database, g.Table
+	if database == "" {
+		database = "*"
+	}
+	if table == "" {
+		table = "*"
+	}
+	return database + "." + table
+}
+
+// ServerKey identifies the server a connection points at, so grants are
+// only ever revoked on the server they were made on
+func (c *Connection) ServerKey() string {
+	return c.Config.ServerKey()
+}
+
+// ServerKey identifies the server the config points at without connecting
+func (cfg ConnectionConfig) ServerKey() string {
+	if cfg.Socket != "" {
+		return fmt.Sprintf("%s://%s", cfg.Type, cfg.Socket)
+	}
+	return fmt.Sprintf("%s://%s:%d", cfg.Type, cfg.Host, cfg.Port)
+}
+
+// GetTemporaryGrantsPath returns the path to the temporary grants file
+func GetTemporaryGrantsPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+
+	configDir := filepath.Join(configHome, "ysm")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, "temp_grants.json"), nil
+}
+
+// LoadTemporaryGrants loads all tracked temporary grants
+func LoadTemporaryGrants() (*TemporaryGrantConfig, error) {
+	path, err := GetTemporaryGrantsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &TemporaryGrantConfig{Grants: []TemporaryGrant{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read temporary grants: %w", err)
+	}
+
+	var config TemporaryGrantConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse temporary grants: %w", err)
+	}
+
+	return &config, nil
+}
+
+// SaveTemporaryGrants saves all tracked temporary grants
+func SaveTemporaryGrants(config *TemporaryGrantConfig) error {
+	path, err := GetTemporaryGrantsPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal temporary grants: %w", err)
+	}
+
+	// Grants can name users and reasons, keep the file private
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write temporary grants: %w", err)
+	}
+
+	return nil
+}
+
+// GrantTemporary grants privileges and records them for automatic revocation
+// after ttl. profile is the connection profile the scheduler should use to
+// revoke the grant (empty = default connection flags). Privileges the account
+// already holds are neither granted nor recorded, so the revocation leaves
+// them; when revoking would take some of them away regardless, e.g. with
+// ALL PRIVILEGES, the grant is refused.
+func (c *Connection) GrantTemporary(username, host string, privileges []string, database, table string, ttl time.Duration, reason, profile string) (*TemporaryGrant, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("expiry must be in the future")
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	if len(privileges) == 0 {
+		privileges = []string{"ALL PRIVILEGES"}
+	}
+
+	// Load first so a broken file doesn't leave an untracked grant behind
+	config, err := LoadTemporaryGrants()
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := c.heldPrivileges(username, host, privileges, database, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the privileges '%s'@'%s' holds: %w", username, host, err)
+	}
+	scope := (&TemporaryGrant{Database: database, Table: table}).Scope()
+	if len(existing.overlap) > 0 {
+		return nil, fmt.Errorf("'%s'@'%s' already holds %s on %s, which revoking the temporary grant would take away; grant only the privileges it lacks",
+			username, host, strings.Join(existing.overlap, ", "), scope)
+	}
+	var added []string
+	for _, p := range privileges {
+		if !existing.held[strings.ToUpper(strings.TrimSpace(p))] {
+			added = append(added, p)
+		}
+	}
+	if len(added) == 0 {
+		return nil, fmt.Errorf("'%s'@'%s' already holds %s on %s", username, host, strings.Join(privileges, ", "), scope)
+	}
+	privileges = added
+
+	if err := c.GrantPrivileges(username, host, privileges, database, table); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	grant := TemporaryGrant{
+		ID:         fmt.Sprintf("tg_%d", now.UnixNano()),
+		Profile:    profile,
+		Server:     c.ServerKey(),
+		Username:   username,
+		Host:       host,
+		Privileges: privileges,
+		Database:   database,
+		Table:      table,
+		Reason:     reason,
+		GrantedAt:  now,
+		ExpiresAt:  now.Add(ttl),
+
+		KeepConnect: existing.connect,
+	}
+	config.Grants = append(config.Grants, grant)
+
+	if err := SaveTemporaryGrants(config); err != nil {
+		// Don't leave access behind that nothing will ever revoke
+		c.revokeTracked(&grant)
+		return nil, err
+	}
+
+	return &grant, nil
+}
+
+// ListTemporaryGrants returns tracked grants sorted by expiry. Revoked grants
+// are only included when all is set.
+func ListTemporaryGrants(all bool) ([]TemporaryGrant, error) {
+	config, err := LoadTemporaryGrants()
+	if err != nil {
+		return nil, err
+	}
+
+	var grants []TemporaryGrant
+	for _, g := range config.Grants {
+		if all || !g.Revoked() {
+			grants = append(grants, g)
+		}
+	}
+
+	sort.Slice(grants, func(i, j int) bool {
+		return grants[i].ExpiresAt.Before(grants[j].ExpiresAt)
+	})
+	return grants, nil
+}
+
+// GetDueTemporaryGrants returns expired grants that still need revoking
+func GetDueTemporaryGrants() ([]TemporaryGrant, error) {
+	config, err := LoadTemporaryGrants()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var due []TemporaryGrant
+	for _, g := range config.Grants {
+		if g.Expired(now) {
+			due = append(due, g)
+		}
+	}
+	return due, nil
+}
+
+// RevokeTemporaryGrant revokes a tracked grant now, before it expires
+func (c *Connection) RevokeTemporaryGrant(id string) error {
+	config, err := LoadTemporaryGrants()
+	if err != nil {
+		return err
+	}
+
+	for i := range config.Grants {
+		g := &config.Grants[i]
+		if g.ID != id {
+			continue
+		}
+		if g.Revoked() {
+			return fmt.Errorf("grant %s was already revoked", id)
+		}
+		if g.Server != c.ServerKey() {
+			return fmt.Errorf("grant %s was made on %s, not %s", id, g.Server, c.ServerKey())
+		}
+		if err := c.revokeTracked(g); err != nil {
+			SaveTemporaryGrants(config)
+			return err
+		}
+		return SaveTemporaryGrants(config)
+	}
+
+	return fmt.Errorf("no temporary grant found with id: %s", id)
+}
+
+// RevokeExpiredGrants revokes every expired grant made on this connection's
+// server. Failed revocations are recorded on the grant and retried next time.
+func (c *Connection) RevokeExpiredGrants() ([]TemporaryGrant, error) {
+	config, err := LoadTemporaryGrants()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	server := c.ServerKey()
+	var revoked []TemporaryGrant
+	var failed int
+
+	for i := range config.Grants {
+		g := &config.Grants[i]
+		if g.Server != server || !g.Expired(now) {
+			continue
+		}
+		if err := c.revokeTracked(g); err != nil {
+			failed++
+			continue
+		}
+		revoked = append(revoked, *g)
+	}
+
+	if len(revoked) == 0 && failed == 0 {
+		return nil, nil
+	}
+	if err := SaveTemporaryGrants(config); err != nil {
+		return revoked, err
+	}
+	if failed > 0 {
+		return revoked, fmt.Errorf("failed to revoke %d expired grant(s)", failed)
+	}
+	return revoked, nil
+}
+
+// renameTemporaryGrants points this server's unrevoked grants for a user at
+// the user's new name and host
+func (c *Connection) renameTemporaryGrants(username, host, newName, newHost string) error {
+	config, err := LoadTemporaryGrants()
+	if err != nil {
+		return err
+	}
+
+	server := c.ServerKey()
+	renamed := false
+	for i := range config.Grants {
+		g := &config.Grants[i]
+		if g.Server != server || g.Revoked() || g.Username != username || g.Host != host {
+			continue
+		}
+		g.Username, g.Host = newName, newHost
+		renamed = true
+	}
+
+	if !renamed {
+		return nil
+	}
+	return SaveTemporaryGrants(config)
+}
+
+// revokeTracked revokes a grant and updates its record in place
+func (c *Connection) revokeTracked(g *TemporaryGrant) error {
+	revoke := c.RevokePrivileges
+	if g.KeepConnect {
+		revoke = c.revokeTablePrivileges
+	}
+	if err := revoke(g.Username, g.Host, g.Privileges, g.Database, g.Table); err != nil {
+		g.LastError = err.Error()
+		return err
+	}
+	g.RevokedAt = time.Now()
+	g.LastError = ""
+	return nil
+}
+
+// heldGrants is what an account already holds where a temporary grant goes
+type heldGrants struct {
+	held    map[string]bool // Requested privileges held in full, in upper case
+	overlap []string        // Held privileges a revoke would take away but can't leave out
+	connect bool            // PostgreSQL: the account may already connect to the database
+}
+
+// heldPrivileges looks up which of privileges the account already holds on
+// database and table. Only privileges granted to the account itself count,
+// as those are what a revoke takes away; ones it has through roles or PUBLIC
+// stay either way.
+func (c *Connection) heldPrivileges(username, host string, privileges []string, database, table string) (*heldGrants, error) {
+	var have map[string]int // Privilege to the number of objects holding it
+	total := 1
+	result := &heldGrants{held: make(map[string]bool)}
+	serverName := func(privilege string) string { return privilege }
+
+	if pg, ok := c.Driver.(*PostgresDriver); ok {
+		serverName = func(privilege string) string { return pg.mapPrivileges([]string{privilege})[0] }
+		var err error
+		if have, total, err = c.postgresHeldPrivileges(pg, username, database, table); err != nil {
+			return nil, err
+		}
+		if table == "" && database != "" {
+			if have["CONNECT"] > 0 {
+				result.connect = true
+			}
+			delete(have, "CONNECT")
+		}
+	} else {
+		grants, err := c.GetUserGrants(username, host)
+		if err != nil {
+			return nil, err
+		}
+		have = mariadbHeldPrivileges(grants, database, table)
+	}
+
+	all := have["ALL PRIVILEGES"] == total
+	for _, p := range privileges {
+		key := strings.ToUpper(strings.TrimSpace(p))
+		name := serverName(key)
+		switch {
+		case name == "ALL" || name == "ALL PRIVILEGES":
+			// Revoking everything would also take what the account held
+			for held := range have {
+				result.overlap = append(result.overlap, held)
+			}
+		case all || have[name] == total:
+			result.held[key] = true
+		case have[name] > 0:
+			// Held on some of the database's tables only
+			result.overlap = append(result.overlap, name)
+		}
+	}
+	sort.Strings(result.overlap)
+	return result, nil
+}
+
+// mariadbGrantRe splits a SHOW GRANTS line into its privileges and target
+var mariadbGrantRe = regexp.MustCompile("(?i)^GRANT (.+?) ON (\\S+) TO ")
+
+// mariadbHeldPrivileges lists the privileges SHOW GRANTS output gives on
+// exactly the grant's target, which is all a revoke there takes away
+func mariadbHeldPrivileges(grants []Grant, database, table string) map[string]int {
+	target := "*.*"
+	if database != "" && table != "" {
+		target = database + "." + table
+	} else if database != "" {
+		target = database + ".*"
+	}
+
+	have := make(map[string]int)
+	for _, g := range grants {
+		m := mariadbGrantRe.FindStringSubmatch(g.GrantText)
+		if m == nil || strings.ReplaceAll(m[2], "`", "") != target {
+			continue
+		}
+		for _, p := range splitTopLevel(m[1]) {
+			p = strings.ToUpper(strings.TrimSpace(p))
+			if p == "USAGE" || strings.Contains(p, "(") {
+				continue
+			}
+			if p == "ALL" {
+				p = "ALL PRIVILEGES"
+			}
+			have[p] = 1
+		}
+	}
+	return have
+}
+
+// postgresHeldPrivileges counts the objects the grant would cover on which
+// the role holds each privilege itself, owners' implicit ones included.
+// Database grants cover the tables of the public schema, plus CONNECT.
+func (c *Connection) postgresHeldPrivileges(pg *PostgresDriver, username, database, table string) (map[string]int, int, error) {
+	const role = `(SELECT oid FROM pg_roles WHERE rolname = $1)`
+	have := make(map[string]int)
+	count := func(query string, args ...interface{}) error {
+		rows, err := c.DB.Query(query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var privilege string
+			var n int
+			if err := rows.Scan(&privilege, &n); err != nil {
+				return err
+			}
+			have[privilege] += n
+		}
+		return rows.Err()
+	}
+
+	switch {
+	case table != "":
+		err := count(`SELECT a.privilege_type, 1
+FROM pg_class c, aclexplode(COALESCE(c.relacl, acldefault('r', c.relowner))) a
+WHERE c.oid = $2::regclass AND a.grantee = `+role, username, pg.quoteTable(table))
+		return have, 1, err
+
+	case database != "":
+		const publicTables = `FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p', 'v', 'm', 'f')`
+		var total int
+		if err := c.DB.QueryRow(`SELECT count(*) ` + publicTables).Scan(&total); err != nil {
+			return nil, 0, err
+		}
+		if err := count(`SELECT a.privilege_type, count(*)
+FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace,
+     aclexplode(COALESCE(c.relacl, acldefault('r', c.relowner))) a
+WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p', 'v', 'm', 'f') AND a.grantee = `+role+`
+GROUP BY a.privilege_type`, username); err != nil {
+			return nil, 0, err
+		}
+		if err := count(`SELECT a.privilege_type, 1
+FROM pg_database d, aclexplode(COALESCE(d.datacl, acldefault('d', d.datdba))) a
+WHERE d.datname = $2 AND a.privilege_type = 'CONNECT' AND a.grantee = `+role, username, database); err != nil {
+			return nil, 0, err
+		}
+		// With no tables, a table privilege counted 0 times isn't held
+		return have, max(total, 1), nil
+
+	default:
+		// Privileges granted to the whole role are role memberships
+		err := count(`SELECT upper(r.rolname), 1
+FROM pg_auth_members m JOIN pg_roles r ON r.oid = m.roleid
+WHERE m.member = `+role, username)
+		return have, 1, err
+	}
+}
+
+// revokeTablePrivileges revokes a PostgreSQL database grant from the tables
+// of the public schema only, leaving the CONNECT the account held before
+func (c *Connection) revokeTablePrivileges(username, host string, privileges []string, database, table string) error {
+	pg, ok := c.Driver.(*PostgresDriver)
+	if !ok || table != "" || database == "" {
+		return c.RevokePrivileges(username, host, privileges, database, table)
+	}
+
+	stmt := fmt.Sprintf("REVOKE %s ON ALL TABLES IN SCHEMA public FROM %s",
+		strings.Join(pg.tablePrivileges(pg.mapPrivileges(privileges)), ", "), pg.QuoteIdentifier(username))
+	detail := fmt.Sprintf("%s ON %s", strings.Join(privileges, ", "), auditGrantTarget(database, table))
+	_, err := c.DB.Exec(stmt)
+	c.audit(AuditRevoke, auditAccount(username, host), detail, err)
+	if err != nil {
+		return fmt.Errorf("failed to revoke privileges: %w", err)
+	}
+	return nil
+}
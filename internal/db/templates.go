@@ -18,15 +18,106 @@
 
 package db
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
 
-// AppTemplate defines a preset for common applications
+// AppTemplate defines a preset for common applications. Users can add
+// their own, or replace built-in ones, in templates.yaml in the config dir.
 type AppTemplate struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Charset     string   `json:"charset"`
-	Collation   string   `json:"collation"`
-	Privileges  []string `json:"privileges"`
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description" yaml:"description,omitempty"`
+	Charset     string   `json:"charset" yaml:"charset"`
+	Collation   string   `json:"collation" yaml:"collation,omitempty"`
+	Privileges  []string `json:"privileges" yaml:"privileges"`
+	Bootstrap   string   `json:"bootstrap,omitempty" yaml:"bootstrap,omitempty"` // SQL script run in the new database after it is set up
+	Custom      bool     `json:"custom,omitempty" yaml:"-"`                      // Defined by the user rather than built in
+}
+
+var (
+	templateNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+	charsetNameRe  = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+)
+
+// Validate checks a template before it is saved or used. Privileges are
+// written into GRANT statements as they are, so only known ones pass.
+func (t *AppTemplate) Validate() error {
+	if !templateNameRe.MatchString(t.Name) {
+		return fmt.Errorf("template name %q must be letters, digits, '_', '.' or '-'", t.Name)
+	}
+	if !charsetNameRe.MatchString(t.Charset) {
+		return fmt.Errorf("template %s: invalid charset %q", t.Name, t.Charset)
+	}
+	if t.Collation != "" {
+		if !charsetNameRe.MatchString(t.Collation) {
+			return fmt.Errorf("template %s: invalid collation %q", t.Name, t.Collation)
+		}
+		if t.Charset != "binary" && !strings.HasPrefix(strings.ToLower(t.Collation), strings.ToLower(t.Charset)+"_") {
+			return fmt.Errorf("template %s: collation %s is not one of charset %s", t.Name, t.Collation, t.Charset)
+		}
+	}
+	if len(t.Privileges) == 0 {
+		return fmt.Errorf("template %s: at least one privilege is required", t.Name)
+	}
+	for _, priv := range t.Privileges {
+		if !isCommonPrivilege(priv) {
+			return fmt.Errorf("template %s: unknown privilege %q (known: %s)", t.Name, priv, strings.Join(CommonPrivileges(), ", "))
+		}
+	}
+	if t.Bootstrap != "" {
+		info, err := os.Stat(t.Bootstrap)
+		if err != nil {
+			return fmt.Errorf("template %s: bootstrap script: %w", t.Name, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("template %s: bootstrap script %s is a directory", t.Name, t.Bootstrap)
+		}
+	}
+	return nil
+}
+
+// isCommonPrivilege reports whether priv is one of CommonPrivileges
+func isCommonPrivilege(priv string) bool {
+	for _, known := range CommonPrivileges() {
+		if strings.EqualFold(strings.Join(strings.Fields(priv), " "), known) {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeTemplates returns the built-in templates with custom ones added.
+// A custom template named like a built-in one takes its place.
+func MergeTemplates(builtin, custom []AppTemplate) []AppTemplate {
+	merged := append([]AppTemplate(nil), builtin...)
+	for _, t := range custom {
+		t.Custom = true
+		replaced := false
+		for i := range merged {
+			if merged[i].Name == t.Name {
+				merged[i] = t
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
+
+// FindTemplate returns the template named name from templates
+func FindTemplate(templates []AppTemplate, name string) (*AppTemplate, error) {
+	for _, t := range templates {
+		if t.Name == name {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("template not found: %s", name)
 }
 
 // GetCharsetForDB returns the appropriate charset for the database type
@@ -142,15 +233,9 @@ func DefaultTemplates() []AppTemplate {
 	}
 }
 
-// GetTemplate returns a template by name
+// GetTemplate returns a built-in template by name
 func GetTemplate(name string) (*AppTemplate, error) {
-	templates := DefaultTemplates()
-	for _, t := range templates {
-		if t.Name == name {
-			return &t, nil
-		}
-	}
-	return nil, fmt.Errorf("template not found: %s", name)
+	return FindTemplate(DefaultTemplates(), name)
 }
 
 // GetCharsets returns available character sets for MariaDB
@@ -251,9 +336,29 @@ func (c *Connection) SetupAppDatabase(template *AppTemplate, dbName, username, p
 		return fmt.Errorf("failed to grant privileges: %w", err)
 	}
 
+	// Run the bootstrap script in the new database, on a connection of its
+	// own so the session's database doesn't change
+	if template.Bootstrap != "" {
+		if err := c.runBootstrap(template.Bootstrap, dbName); err != nil {
+			c.DropUser(username, host)
+			c.DB.Exec(c.Driver.DropDatabaseQuery(dbName))
+			return fmt.Errorf("failed to run bootstrap script: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// runBootstrap imports a template's bootstrap script into database
+func (c *Connection) runBootstrap(script, database string) error {
+	conn, err := c.Dedicated()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.ImportSQL(ImportOptions{FilePath: script, Database: database})
+}
+
 // CommonCharsets returns commonly used character sets
 func CommonCharsets() []string {
 	return []string{
@@ -0,0 +1,277 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+)
+
+// Kinds of timeline event
+const (
+	TimelineRestart     = "restart"
+	TimelineReplication = "replication"
+	TimelineBackup      = "backup"
+	TimelineSnapshot    = "snapshot"
+	TimelineOperation   = "operation"
+)
+
+// TimelineKinds lists every kind of timeline event
+var TimelineKinds = []string{TimelineRestart, TimelineReplication, TimelineBackup, TimelineSnapshot, TimelineOperation}
+
+// maxTimelineEvents is how many events the timeline keeps per profile
+const maxTimelineEvents = 1000
+
+// restartSlack is how much later a server's start time has to move before
+// it counts as a restart; uptime is only reported in whole seconds and the
+// clocks drift
+const restartSlack = time.Minute
+
+// TimelineEvent is something notable that happened to a server
+type TimelineEvent struct {
+	Time    time.Time `json:"time"`
+	Profile string    `json:"profile"` // Profile name, or host when connected without one
+	Kind    string    `json:"kind"`
+	Summary string    `json:"summary"`
+	Detail  string    `json:"detail,omitempty"`
+	Failed  bool      `json:"failed,omitempty"`
+}
+
+// TimelineServer is what was last seen of a profile's server, to notice
+// restarts and replication problems between checks
+type TimelineServer struct {
+	StartedAt   time.Time `json:"started_at"`
+	Replication string    `json:"replication,omitempty"` // Problem last seen, "" when healthy
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// Timeline holds the recorded events of every profile, oldest first
+type Timeline struct {
+	Events  []TimelineEvent           `json:"events"`
+	Servers map[string]TimelineServer `json:"servers"`
+}
+
+// timelineMu serializes updates from the TUI's background jobs
+var timelineMu sync.Mutex
+
+// GetTimelinePath returns the path to the timeline file, next to the
+// backups directory
+func GetTimelinePath() (string, error) {
+	backupsDir, err := GetBackupsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(backupsDir), "timeline.json"), nil
+}
+
+// LoadTimeline loads the timeline
+func LoadTimeline() (*Timeline, error) {
+	path, err := GetTimelinePath()
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := &Timeline{Events: []TimelineEvent{}, Servers: map[string]TimelineServer{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return timeline, nil
+		}
+		return nil, fmt.Errorf("failed to read timeline: %w", err)
+	}
+
+	if err := json.Unmarshal(data, timeline); err != nil {
+		return nil, fmt.Errorf("failed to parse timeline: %w", err)
+	}
+	if timeline.Servers == nil {
+		timeline.Servers = map[string]TimelineServer{}
+	}
+
+	return timeline, nil
+}
+
+// SaveTimeline saves the timeline
+func SaveTimeline(timeline *Timeline) error {
+	path, err := GetTimelinePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(timeline, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal timeline: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write timeline: %w", err)
+	}
+
+	return nil
+}
+
+// updateTimeline loads the timeline, applies change and saves it
+func updateTimeline(change func(t *Timeline)) error {
+	timelineMu.Lock()
+	defer timelineMu.Unlock()
+
+	timeline, err := LoadTimeline()
+	if err != nil {
+		return err
+	}
+	change(timeline)
+	return SaveTimeline(timeline)
+}
+
+// add inserts an event in time order, dropping the profile's oldest beyond
+// maxTimelineEvents
+func (t *Timeline) add(event TimelineEvent) {
+	i := slices.IndexFunc(t.Events, func(e TimelineEvent) bool {
+		return e.Time.After(event.Time)
+	})
+	if i < 0 {
+		i = len(t.Events)
+	}
+	t.Events = slices.Insert(t.Events, i, event)
+
+	excess := -maxTimelineEvents
+	for _, e := range t.Events {
+		if e.Profile == event.Profile {
+			excess++
+		}
+	}
+	if excess > 0 {
+		t.Events = slices.DeleteFunc(t.Events, func(e TimelineEvent) bool {
+			if e.Profile != event.Profile || excess == 0 {
+				return false
+			}
+			excess--
+			return true
+		})
+	}
+}
+
+// For returns a profile's events, oldest first
+func (t *Timeline) For(profile string) []TimelineEvent {
+	var events []TimelineEvent
+	for _, e := range t.Events {
+		if e.Profile == profile {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// RecordTimelineEvent adds an event to the timeline
+func RecordTimelineEvent(event TimelineEvent) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	return updateTimeline(func(t *Timeline) {
+		t.add(event)
+	})
+}
+
+// OperationEvent is a finished YSM operation as a timeline event, e.g.
+// "Export of shop finished in 2m10s". Backups get their own kind.
+func OperationEvent(profile, operation, title string, elapsed time.Duration, err error) TimelineEvent {
+	event := TimelineEvent{
+		Time:    time.Now(),
+		Profile: profile,
+		Kind:    TimelineOperation,
+		Summary: fmt.Sprintf("%s finished in %s", title, progress.FormatDuration(elapsed)),
+	}
+	if operation == TimelineBackup {
+		event.Kind = TimelineBackup
+	}
+	if err != nil {
+		event.Summary = title + " failed"
+		event.Detail = err.Error()
+		event.Failed = true
+	}
+	return event
+}
+
+// ObserveTimeline checks the server for restarts since the last check,
+// going by its uptime, and for replication errors appearing or clearing,
+// and records what changed on the profile's timeline
+func (c *Connection) ObserveTimeline(profile string) ([]TimelineEvent, error) {
+	uptime, err := c.GetUptime()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get uptime: %w", err)
+	}
+	now := time.Now()
+	startedAt := now.Add(-uptime).Truncate(time.Second)
+	problem := c.replicationProblem()
+
+	var events []TimelineEvent
+	err = updateTimeline(func(t *Timeline) {
+		last, seen := t.Servers[profile]
+		if seen && startedAt.Sub(last.StartedAt) > restartSlack {
+			events = append(events, TimelineEvent{
+				Time:    startedAt,
+				Profile: profile,
+				Kind:    TimelineRestart,
+				Summary: "Server restarted",
+				Detail:  fmt.Sprintf("Up %s when checked; it had been running since %s", FormatUptime(uptime), last.StartedAt.Format("2006-01-02 15:04:05")),
+			})
+		}
+		if problem != last.Replication {
+			switch {
+			case problem != "":
+				events = append(events, TimelineEvent{Time: now, Profile: profile, Kind: TimelineReplication, Summary: "Replication error", Detail: problem, Failed: true})
+			default:
+				events = append(events, TimelineEvent{Time: now, Profile: profile, Kind: TimelineReplication, Summary: "Replication recovered", Detail: "Was: " + last.Replication})
+			}
+		}
+		for _, e := range events {
+			t.add(e)
+		}
+
+		if !seen || startedAt.After(last.StartedAt) {
+			last.StartedAt = startedAt
+		}
+		last.Replication = problem
+		last.CheckedAt = now
+		t.Servers[profile] = last
+	})
+	return events, err
+}
+
+// replicationProblem describes what is wrong with the server's replication,
+// or returns "" when it is healthy or not set up
+func (c *Connection) replicationProblem() string {
+	if c.Config.Type == DatabaseTypeMariaDB {
+		if repl, err := c.GetMariaDBReplicationStatus(); err == nil && repl.IsReplica && repl.LastError != "" {
+			return repl.LastError
+		}
+	}
+	status, err := c.GetClusterStatus()
+	if err != nil || status.Type == ClusterTypeNone || status.IsHealthy {
+		return ""
+	}
+	return cmp.Or(status.ErrorMessage, "Replication is unhealthy")
+}
@@ -0,0 +1,152 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/scratch"
+)
+
+// TransferOptions configures copying a database, or some of its tables, to
+// another server
+type TransferOptions struct {
+	SourceDB   string
+	Tables     []string // Empty = all tables
+	TargetDB   string   // Empty = the source's name
+	NoData     bool     // Copy the structure only
+	Replace    bool     // Drop tables the target already has instead of failing
+	OnProgress func(phase string, percent float64)
+}
+
+// TransferStats contains statistics about a transfer
+type TransferStats struct {
+	TablesCopied int
+	RowsCopied   int64
+	Duration     time.Duration
+}
+
+// TransferTo copies tables from this connection's server to the target's by
+// exporting them to a temporary file and importing that on the target. Both
+// servers must be of the same type, since the dump is written in the
+// source's dialect. Without Replace the transfer fails before anything is
+// written when the target database already has one of the tables.
+func (c *Connection) TransferTo(target *Connection, opts TransferOptions) (*TransferStats, error) {
+	startTime := time.Now()
+	if opts.TargetDB == "" {
+		opts.TargetDB = opts.SourceDB
+	}
+	if target.Config.Type != c.Config.Type {
+		return nil, fmt.Errorf("can't transfer from %s to %s: both servers must be of the same type", c.Config.Type, target.Config.Type)
+	}
+	if target == c && opts.TargetDB == opts.SourceDB {
+		return nil, fmt.Errorf("source and target are the same database")
+	}
+	if err := target.CheckDatabaseProtected(opts.TargetDB, "transfer into"); err != nil {
+		return nil, err
+	}
+
+	progress := func(phase string, percent float64) {
+		if opts.OnProgress != nil {
+			opts.OnProgress(phase, percent)
+		}
+	}
+
+	tables := opts.Tables
+	if len(tables) == 0 {
+		source, err := c.openDatabase(opts.SourceDB)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", opts.SourceDB, err)
+		}
+		names, err := tableNameSet(source)
+		source.Close()
+		if err != nil {
+			return nil, err
+		}
+		for name := range names {
+			tables = append(tables, name)
+		}
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("database %s has no tables", opts.SourceDB)
+	}
+
+	if !opts.Replace {
+		existing, err := target.existingTables(opts.TargetDB, tables)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", opts.TargetDB, err)
+		}
+		if len(existing) > 0 {
+			return nil, fmt.Errorf("%s on the target already has %s", opts.TargetDB, strings.Join(existing, ", "))
+		}
+	}
+
+	exportOpts := ExportOptions{
+		Database:     opts.SourceDB,
+		Tables:       opts.Tables,
+		NoData:       opts.NoData,
+		AddDropTable: opts.Replace,
+		Compression:  CompressionNone,
+		Format:       DumpFormatSQL,
+		OnProgress: func(currentTable string, tableNum, totalTables int, _, _ int64) {
+			if totalTables > 0 {
+				progress("export", float64(tableNum)/float64(totalTables)*100)
+			}
+		},
+	}
+	tmp, err := scratch.CreateTemp("transfer-*.sql", c.ExportSizeEstimate(exportOpts))
+	if err != nil {
+		return nil, err
+	}
+	tmp.Close()
+	path := tmp.Name()
+	defer os.Remove(path)
+	exportOpts.FilePath = path
+
+	progress("export", 0)
+	exported, err := c.ExportSQLWithStats(exportOpts)
+	if err != nil {
+		return nil, fmt.Errorf("export failed: %w", err)
+	}
+
+	progress("import", 0)
+	_, err = target.ImportSQLWithStats(ImportOptions{
+		FilePath:           path,
+		Database:           opts.TargetDB,
+		CreateDB:           true,
+		DisableForeignKeys: true,
+		OnProgress: func(bytesRead, totalBytes, _, _ int64) {
+			if totalBytes > 0 {
+				progress("import", float64(bytesRead)/float64(totalBytes)*100)
+			}
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("import failed: %w", err)
+	}
+
+	return &TransferStats{
+		TablesCopied: exported.TablesExported,
+		RowsCopied:   exported.RowsExported,
+		Duration:     time.Since(startTime),
+	}, nil
+}
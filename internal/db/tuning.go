@@ -0,0 +1,333 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Workload types to tune for
+const (
+	TuningWeb       = "web"       // Many short queries from many connections
+	TuningOLTP      = "oltp"      // Transactions with frequent writes and point lookups
+	TuningAnalytics = "analytics" // Few connections running large scans, sorts and joins
+	TuningMixed     = "mixed"     // A bit of everything, or a machine shared with other services
+)
+
+// TuningWorkloads returns the workload types in display order with a
+// description of each
+func TuningWorkloads() [][2]string {
+	return [][2]string{
+		{TuningWeb, "Web application: many short queries from many connections"},
+		{TuningOLTP, "OLTP: transactions with frequent writes and point lookups"},
+		{TuningAnalytics, "Analytics: few connections running large scans, sorts and joins"},
+		{TuningMixed, "Mixed: a bit of everything, or a machine shared with other services"},
+	}
+}
+
+// TuningInput describes the machine and workload to tune a server for
+type TuningInput struct {
+	Memory      int64 // Bytes of RAM the server may use
+	CPUs        int
+	Workload    string // One of the Tuning workload types
+	SSD         bool   // Data on solid state storage
+	Connections int    // Expected concurrent connections; 0 picks one for the workload
+}
+
+// TuningChange is a proposed value for one variable
+type TuningChange struct {
+	Name     string `json:"name"`
+	Current  string `json:"current"`  // As the server shows it
+	Proposed string `json:"proposed"` // In configuration file syntax
+	Reason   string `json:"reason"`
+	Changed  bool   `json:"changed"` // Proposed differs from Current
+	Restart  bool   `json:"restart"` // Takes effect only after a restart
+}
+
+// TuningPlan is the set of proposed values for a server
+type TuningPlan struct {
+	Server  DatabaseType   `json:"server"`
+	Input   TuningInput    `json:"input"`
+	Changes []TuningChange `json:"changes"`
+}
+
+// DetectHostResources returns the RAM and CPU count of this machine when
+// the server runs on it; ok is false when they have to be asked for
+func (c *Connection) DetectHostResources() (memory int64, cpus int, ok bool) {
+	if !c.isLocal() {
+		return 0, 0, false
+	}
+	total, _, err := readMemory()
+	if err != nil {
+		return 0, 0, false
+	}
+	return total, runtime.NumCPU(), true
+}
+
+// ProposeTuning proposes values for the variables that matter most for a
+// machine and workload, next to the server's current ones. The rules
+// follow common sizing advice (a large share of RAM for the buffer pool on
+// MariaDB, a quarter for shared_buffers on PostgreSQL) and are a starting
+// point to measure from, not a final answer.
+func (c *Connection) ProposeTuning(in TuningInput) (*TuningPlan, error) {
+	if in.Memory < 256<<20 {
+		return nil, fmt.Errorf("at least 256MB of RAM is needed to propose values")
+	}
+	if in.CPUs < 1 {
+		in.CPUs = 1
+	}
+	if in.Workload == "" {
+		in.Workload = TuningMixed
+	}
+
+	postgres := isPostgresType(c.Config.Type)
+	var proposals []TuningChange
+	if postgres {
+		proposals = postgresTuning(&in)
+	} else {
+		proposals = mariadbTuning(&in)
+	}
+
+	plan := &TuningPlan{Server: c.Config.Type, Input: in}
+	for _, p := range proposals {
+		current, err := c.tuningCurrent(p.Name)
+		if err != nil {
+			// Not a variable of this server version
+			continue
+		}
+		p.Current = current
+		p.Changed = !sameSetting(current, p.Proposed)
+		if meta, err := c.GetVariableMeta(p.Name); err == nil {
+			p.Restart = meta.Apply == VariableApplyRestart
+		}
+		plan.Changes = append(plan.Changes, p)
+	}
+	return plan, nil
+}
+
+// tuningCurrent returns a variable's value with the server's own units
+func (c *Connection) tuningCurrent(name string) (string, error) {
+	if isPostgresType(c.Config.Type) {
+		var value string
+		err := c.DB.QueryRow("SHOW " + c.Driver.QuoteIdentifier(name)).Scan(&value)
+		return value, err
+	}
+	return c.GetVariable(name)
+}
+
+// tuningConnections returns the expected connections for a workload
+func tuningConnections(in *TuningInput, web, oltp, analytics, mixed int) int {
+	if in.Connections > 0 {
+		return in.Connections
+	}
+	switch in.Workload {
+	case TuningWeb:
+		return web
+	case TuningOLTP:
+		return oltp
+	case TuningAnalytics:
+		return analytics
+	}
+	return mixed
+}
+
+func mariadbTuning(in *TuningInput) []TuningChange {
+	const chunk = 128 << 20 // innodb_buffer_pool_chunk_size default
+
+	share := map[string]int64{TuningWeb: 60, TuningOLTP: 70, TuningAnalytics: 75, TuningMixed: 50}[in.Workload]
+	pool := max(in.Memory*share/100/chunk*chunk, chunk)
+	logSize := min(max(pool/4, 128<<20), 4<<30) >> 20 << 20
+	connections := tuningConnections(in, 500, 300, 50, 150)
+
+	tmpTable := int64(64 << 20)
+	if in.Workload == TuningAnalytics {
+		tmpTable = 256 << 20
+	}
+	ioCapacity, ioCapacityMax := 200, 2000
+	storage := "spinning disks"
+	if in.SSD {
+		ioCapacity, ioCapacityMax = 2000, 4000
+		storage = "SSDs"
+	}
+
+	return []TuningChange{
+		{Name: "innodb_buffer_pool_size", Proposed: configSize(pool, false),
+			Reason: fmt.Sprintf("%d%% of RAM caches data and indexes; the rest is left for connections and the OS", share)},
+		{Name: "innodb_log_file_size", Proposed: configSize(logSize, false),
+			Reason: "a quarter of the buffer pool, so checkpoints don't hold up writes"},
+		{Name: "max_connections", Proposed: strconv.Itoa(connections),
+			Reason: fmt.Sprintf("expected concurrent connections of a %s workload", in.Workload)},
+		{Name: "tmp_table_size", Proposed: configSize(tmpTable, false),
+			Reason: "in-memory temporary tables up to this size before going to disk"},
+		{Name: "max_heap_table_size", Proposed: configSize(tmpTable, false),
+			Reason: "caps tmp_table_size too, so it's raised with it"},
+		{Name: "innodb_io_capacity", Proposed: strconv.Itoa(ioCapacity),
+			Reason: "background flushing rate for " + storage},
+		{Name: "innodb_io_capacity_max", Proposed: strconv.Itoa(ioCapacityMax),
+			Reason: "flushing rate when InnoDB falls behind, for " + storage},
+	}
+}
+
+func postgresTuning(in *TuningInput) []TuningChange {
+	const mb = 1 << 20
+
+	sharedBuffers := in.Memory / 4 / mb * mb
+	cacheShare := int64(75)
+	if in.Workload == TuningMixed {
+		sharedBuffers = in.Memory / 8 / mb * mb
+		cacheShare = 50
+	}
+	cache := in.Memory * cacheShare / 100 / mb * mb
+	maintenance := in.Memory / 16
+	if in.Workload == TuningAnalytics {
+		maintenance = in.Memory / 8
+	}
+	maintenance = min(maintenance, 2<<30) / mb * mb
+	connections := tuningConnections(in, 200, 300, 40, 100)
+
+	gather := max((in.CPUs+1)/2, 1)
+	if in.Workload != TuningAnalytics {
+		gather = min(gather, 4)
+	}
+	// Each connection may run a few sorts or hashes at once, each with its own work_mem
+	workMem := max((in.Memory-sharedBuffers)/int64(connections*3)/int64(gather), 64<<10) >> 10 << 10
+	if workMem >= 4*mb {
+		workMem = workMem / mb * mb
+	}
+
+	walMin, walMax := int64(1<<30), int64(4<<30)
+	switch in.Workload {
+	case TuningOLTP:
+		walMin, walMax = 2<<30, 8<<30
+	case TuningAnalytics:
+		walMin, walMax = 4<<30, 16<<30
+	}
+
+	pageCost, ioConcurrency := "4", "2"
+	storage := "spinning disks"
+	if in.SSD {
+		pageCost, ioConcurrency = "1.1", "200"
+		storage = "SSDs"
+	}
+
+	changes := []TuningChange{
+		{Name: "shared_buffers", Proposed: configSize(sharedBuffers, true),
+			Reason: "PostgreSQL's own cache; the OS page cache holds the rest of the hot data"},
+		{Name: "effective_cache_size", Proposed: configSize(cache, true),
+			Reason: fmt.Sprintf("tells the planner %d%% of RAM caches data, which favors index scans", cacheShare)},
+		{Name: "work_mem", Proposed: configSize(workMem, true),
+			Reason: fmt.Sprintf("per sort or hash: RAM after shared_buffers over %d connections, 3 operations each and %d parallel workers", connections, gather)},
+		{Name: "maintenance_work_mem", Proposed: configSize(maintenance, true),
+			Reason: "VACUUM, CREATE INDEX and ALTER TABLE, which run one at a time"},
+		{Name: "max_connections", Proposed: strconv.Itoa(connections),
+			Reason: fmt.Sprintf("expected concurrent connections of a %s workload; use a pooler beyond that", in.Workload)},
+		{Name: "min_wal_size", Proposed: configSize(walMin, true),
+			Reason: "WAL kept for reuse between checkpoints"},
+		{Name: "max_wal_size", Proposed: configSize(walMax, true),
+			Reason: "WAL written before a checkpoint is forced, spreading checkpoints out"},
+		{Name: "random_page_cost", Proposed: pageCost,
+			Reason: "cost of random reads on " + storage},
+		{Name: "effective_io_concurrency", Proposed: ioConcurrency,
+			Reason: "concurrent reads " + storage + " handle"},
+	}
+	if in.CPUs >= 4 {
+		cpus := strconv.Itoa(in.CPUs)
+		changes = append(changes,
+			TuningChange{Name: "max_worker_processes", Proposed: cpus,
+				Reason: "one background worker per CPU"},
+			TuningChange{Name: "max_parallel_workers", Proposed: cpus,
+				Reason: "parallel query workers across all queries, one per CPU"},
+			TuningChange{Name: "max_parallel_workers_per_gather", Proposed: strconv.Itoa(gather),
+				Reason: "parallel workers one query step may use"},
+			TuningChange{Name: "max_parallel_maintenance_workers", Proposed: strconv.Itoa(min(gather, 4)),
+				Reason: "parallel workers for CREATE INDEX and VACUUM"},
+		)
+	}
+	return changes
+}
+
+// configSize writes bytes with the largest unit that divides them: 4G and
+// 512M in my.cnf, 4GB and 64kB in postgresql.conf
+func configSize(bytes int64, postgres bool) string {
+	units := []struct {
+		size    int64
+		mariadb string
+		pg      string
+	}{{1 << 30, "G", "GB"}, {1 << 20, "M", "MB"}, {1 << 10, "K", "kB"}}
+	for _, u := range units {
+		if bytes >= u.size && bytes%u.size == 0 {
+			if postgres {
+				return strconv.FormatInt(bytes/u.size, 10) + u.pg
+			}
+			return strconv.FormatInt(bytes/u.size, 10) + u.mariadb
+		}
+	}
+	return strconv.FormatInt(bytes, 10)
+}
+
+// sameSetting compares a server's value with a proposed one, as sizes or
+// numbers when both are
+func sameSetting(current, proposed string) bool {
+	if a, err := strconv.ParseFloat(current, 64); err == nil {
+		if b, err := strconv.ParseFloat(proposed, 64); err == nil {
+			return a == b
+		}
+	}
+	a, errA := ParseSize(current)
+	b, errB := ParseSize(proposed)
+	if errA == nil && errB == nil {
+		return a == b
+	}
+	return strings.EqualFold(current, proposed)
+}
+
+// Snippet returns the proposed values as a my.cnf or postgresql.conf
+// fragment, each with its reason
+func (p *TuningPlan) Snippet() string {
+	var b strings.Builder
+	storage := "spinning disks"
+	if p.Input.SSD {
+		storage = "SSD"
+	}
+	fmt.Fprintf(&b, "# Tuned by YSM for a %s workload: %s RAM, %d CPUs, %s\n",
+		p.Input.Workload, FormatSize(p.Input.Memory), p.Input.CPUs, storage)
+
+	postgres := isPostgresType(p.Server)
+	if postgres {
+		b.WriteString("# Append to postgresql.conf, or apply each with ALTER SYSTEM SET\n")
+	} else {
+		b.WriteString("# Add to the server's option file, such as /etc/mysql/my.cnf\n[mysqld]\n")
+	}
+	for _, change := range p.Changes {
+		restart := ""
+		if change.Restart {
+			restart = " (restart)"
+		}
+		fmt.Fprintf(&b, "\n# %s%s\n", change.Reason, restart)
+		if postgres && strings.ContainsAny(change.Proposed, "kMGB") {
+			fmt.Fprintf(&b, "%s = '%s'\n", change.Name, change.Proposed)
+		} else {
+			fmt.Fprintf(&b, "%s = %s\n", change.Name, change.Proposed)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,421 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// UserImportAction is what an import does with one account
+type UserImportAction string
+
+const (
+	UserImportCreate   UserImportAction = "create"   // New account: create it with its password and grants
+	UserImportSkip     UserImportAction = "skip"     // Leave the account alone
+	UserImportPassword UserImportAction = "password" // Existing account: take the source's password
+	UserImportMerge    UserImportAction = "merge"    // Existing account: add the grants it lacks
+)
+
+// UserDefinition is an account as a grants file or another server describes it
+type UserDefinition struct {
+	Username string   `json:"username"`
+	Host     string   `json:"host"`
+	Auth     string   `json:"-"` // IDENTIFIED ... clause; "" when the source has none
+	Grants   []string `json:"grants"`
+}
+
+// Account returns the definition's 'user'@'host' name
+func (d UserDefinition) Account() string {
+	return fmt.Sprintf("'%s'@'%s'", d.Username, d.Host)
+}
+
+// UserImportPlan compares one source account with the connected server
+type UserImportPlan struct {
+	UserDefinition
+	Exists          bool             `json:"exists"`
+	PasswordDiffers bool             `json:"password_differs"`
+	MissingGrants   []string         `json:"missing_grants,omitempty"` // Source grants the server lacks
+	ExtraGrants     []string         `json:"extra_grants,omitempty"`   // Server grants the source lacks, left alone
+	Action          UserImportAction `json:"action"`
+}
+
+// Conflict reports whether the account exists with a different definition
+func (p *UserImportPlan) Conflict() bool {
+	return p.Exists && (p.PasswordDiffers || len(p.MissingGrants) > 0)
+}
+
+// Status describes the comparison in a word
+func (p *UserImportPlan) Status() string {
+	switch {
+	case !p.Exists:
+		return "new"
+	case p.Conflict():
+		return "differs"
+	default:
+		return "same"
+	}
+}
+
+// Actions returns the choices for the account, the default first
+func (p *UserImportPlan) Actions() []UserImportAction {
+	switch {
+	case !p.Exists:
+		return []UserImportAction{UserImportCreate, UserImportSkip}
+	case p.Conflict():
+		return []UserImportAction{UserImportSkip, UserImportPassword, UserImportMerge}
+	default:
+		return []UserImportAction{UserImportSkip}
+	}
+}
+
+// Allows reports whether action is one of the account's choices
+func (p *UserImportPlan) Allows(action UserImportAction) bool {
+	for _, a := range p.Actions() {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// UserImportResult counts what an import did
+type UserImportResult struct {
+	Created   int      `json:"created"`
+	Passwords int      `json:"passwords"`
+	Merged    int      `json:"merged"`
+	Skipped   int      `json:"skipped"`
+	Notes     []string `json:"notes,omitempty"`  // Accounts created without a password, which are locked
+	Errors    []string `json:"errors,omitempty"` // One per account that failed
+}
+
+// accountPart matches a user or host, quoted or bare
+const accountPart = "(?:`([^`]*)`|'([^']*)'|\"([^\"]*)\"|([^\\s@'`\"]+))"
+
+// grantAccountRe finds the account a GRANT gives privileges to
+var grantAccountRe = regexp.MustCompile(`(?is)\bTO\s+` + accountPart + `\s*@\s*` + accountPart)
+
+// createUserRe finds the account a CREATE USER or ALTER USER names
+var createUserRe = regexp.MustCompile(`(?is)^(?:CREATE|ALTER)\s+(?:OR\s+REPLACE\s+)?USER\s+(?:IF\s+(?:NOT\s+)?EXISTS\s+)?` +
+	accountPart + `\s*@\s*` + accountPart)
+
+// authClauseRe finds the authentication clause of a CREATE USER or GRANT
+var authClauseRe = regexp.MustCompile(`(?is)\bIDENTIFIED\s+(?:BY|VIA|WITH)\s.*?(?:\s+(?:WITH|REQUIRE|PASSWORD\s+EXPIRE|ACCOUNT)\s|;?\s*$)`)
+
+// trailingOptionRe finds the option keyword authClauseRe stops at
+var trailingOptionRe = regexp.MustCompile(`(?is)\s+(?:WITH|REQUIRE|PASSWORD\s+EXPIRE|ACCOUNT)\s$`)
+
+// systemAccounts are created by the server itself and never imported
+var systemAccounts = map[string]bool{
+	"mariadb.sys": true, "mysql.sys": true, "mysql.session": true, "mysql.infoschema": true,
+}
+
+// accountFrom returns the user and host captured by an accountPart pair
+func accountFrom(m []string) (string, string) {
+	pick := func(groups []string) string {
+		for _, g := range groups {
+			if g != "" {
+				return g
+			}
+		}
+		return ""
+	}
+	return pick(m[1:5]), pick(m[5:9])
+}
+
+// authClause returns the IDENTIFIED ... clause of a statement
+func authClause(stmt string) string {
+	clause := authClauseRe.FindString(stmt)
+	clause = trailingOptionRe.ReplaceAllString(clause, "")
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(clause), ";"))
+}
+
+// parseUserDefinitions groups CREATE USER, ALTER USER and GRANT statements by
+// account, in the order accounts first appear. Other statements are ignored.
+func parseUserDefinitions(statements []string) []UserDefinition {
+	var defs []UserDefinition
+	index := make(map[string]int)
+
+	add := func(user, host string) *UserDefinition {
+		key := user + "@" + host
+		i, ok := index[key]
+		if !ok {
+			i = len(defs)
+			index[key] = i
+			defs = append(defs, UserDefinition{Username: user, Host: host})
+		}
+		return &defs[i]
+	}
+
+	for _, stmt := range statements {
+		stmt = strings.TrimSuffix(strings.TrimSpace(stmt), ";")
+		switch strings.ToUpper(firstKeyword(stmt)) {
+		case "CREATE", "ALTER":
+			m := createUserRe.FindStringSubmatch(stmt)
+			if m == nil {
+				continue
+			}
+			def := add(accountFrom(m))
+			if auth := authClause(stmt); auth != "" {
+				def.Auth = auth
+			}
+		case "GRANT":
+			m := grantAccountRe.FindStringSubmatch(stmt)
+			if m == nil {
+				continue
+			}
+			def := add(accountFrom(m))
+			if auth := authClause(stmt); auth != "" {
+				def.Auth = auth
+			}
+			def.Grants = append(def.Grants, strings.TrimSpace(grantIdentifiedRe.ReplaceAllString(stmt, "$1")))
+		}
+	}
+
+	kept := defs[:0]
+	for _, d := range defs {
+		if !systemAccounts[d.Username] {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// ReadUserDefinitions reads the accounts in a grants file, such as the
+// output of SHOW GRANTS or pt-show-grants
+func ReadUserDefinitions(path string) ([]UserDefinition, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open grants file: %w", err)
+	}
+	defer f.Close()
+
+	parser := newSQLParser(bufio.NewReader(f), 16*1024*1024)
+	var statements []string
+	for {
+		stmt, _, err := parser.NextStatement()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		statements = append(statements, stmt)
+	}
+
+	defs := parseUserDefinitions(statements)
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("no CREATE USER or GRANT statements in %s", path)
+	}
+	return defs, nil
+}
+
+// UserDefinitions reads every account on the server, for importing elsewhere
+func (c *Connection) UserDefinitions() ([]UserDefinition, error) {
+	if isPostgresType(c.Config.Type) {
+		return nil, fmt.Errorf("importing users is only supported on MariaDB")
+	}
+
+	users, err := c.ListUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []string
+	for _, u := range users {
+		if systemAccounts[u.Username] {
+			continue
+		}
+		grants, err := c.GetUserGrants(u.Username, u.Host)
+		if err != nil {
+			return nil, err
+		}
+		if auth := c.accountAuth(u.Username, u.Host); auth != "" {
+			statements = append(statements, fmt.Sprintf("CREATE USER %s@%s %s", c.literal(u.Username), c.literal(u.Host), auth))
+		}
+		for _, g := range grants {
+			statements = append(statements, g.GrantText)
+		}
+	}
+	return parseUserDefinitions(statements), nil
+}
+
+// accountAuth returns the authentication clause the server has for an
+// account, or "" when it can't be read
+func (c *Connection) accountAuth(username, host string) string {
+	var create string
+	err := c.DB.QueryRow(fmt.Sprintf("SHOW CREATE USER %s@%s", c.literal(username), c.literal(host))).Scan(&create)
+	if err == nil {
+		return authClause(create)
+	}
+
+	// Servers without SHOW CREATE USER show the password on the USAGE grant
+	grants, err := c.GetUserGrants(username, host)
+	if err != nil {
+		return ""
+	}
+	for _, g := range grants {
+		if auth := authClause(g.GrantText); auth != "" {
+			return auth
+		}
+	}
+	return ""
+}
+
+// normalizeGrant reduces a GRANT statement to a form two servers' SHOW GRANTS
+// output can be compared in, whatever quoting each used
+func normalizeGrant(grant string) string {
+	grant = grantIdentifiedRe.ReplaceAllString(grant, "$1")
+	grant = strings.NewReplacer("`", "", "'", "", `"`, "").Replace(grant)
+	return strings.ToUpper(strings.Join(strings.Fields(grant), " "))
+}
+
+// isUsageGrant reports whether a grant gives nothing but the right to log in
+func isUsageGrant(normalized string) bool {
+	return strings.HasPrefix(normalized, "GRANT USAGE ON *.* TO ")
+}
+
+// PlanUserImport compares accounts with the ones on the server. New accounts
+// default to being created and everything else to being skipped.
+func (c *Connection) PlanUserImport(defs []UserDefinition) ([]UserImportPlan, error) {
+	if isPostgresType(c.Config.Type) {
+		return nil, fmt.Errorf("importing users is only supported on MariaDB")
+	}
+
+	existing, err := c.ListUsers()
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool)
+	for _, u := range existing {
+		exists[u.Username+"@"+u.Host] = true
+	}
+
+	plans := make([]UserImportPlan, 0, len(defs))
+	for _, def := range defs {
+		plan := UserImportPlan{UserDefinition: def, Exists: exists[def.Username+"@"+def.Host]}
+		if plan.Exists {
+			if err := c.compareAccount(&plan); err != nil {
+				return nil, err
+			}
+		}
+		plan.Action = plan.Actions()[0]
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// compareAccount fills in how an existing account differs from the source
+func (c *Connection) compareAccount(plan *UserImportPlan) error {
+	grants, err := c.GetUserGrants(plan.Username, plan.Host)
+	if err != nil {
+		return err
+	}
+
+	have := make(map[string]bool)
+	for _, g := range grants {
+		have[normalizeGrant(g.GrantText)] = true
+	}
+	want := make(map[string]bool)
+	for _, g := range plan.Grants {
+		n := normalizeGrant(g)
+		want[n] = true
+		if !have[n] && !isUsageGrant(n) {
+			plan.MissingGrants = append(plan.MissingGrants, g)
+		}
+	}
+	for _, g := range grants {
+		if n := normalizeGrant(g.GrantText); !want[n] && !isUsageGrant(n) {
+			plan.ExtraGrants = append(plan.ExtraGrants, grantIdentifiedRe.ReplaceAllString(g.GrantText, "$1"))
+		}
+	}
+
+	if plan.Auth != "" {
+		current := c.accountAuth(plan.Username, plan.Host)
+		plan.PasswordDiffers = !strings.EqualFold(strings.Join(strings.Fields(current), " "), strings.Join(strings.Fields(plan.Auth), " "))
+	}
+	return nil
+}
+
+// ApplyUserImport carries out each plan's action. A failing account is
+// recorded and the rest still imported.
+func (c *Connection) ApplyUserImport(plans []UserImportPlan) *UserImportResult {
+	result := &UserImportResult{}
+
+	for _, plan := range plans {
+		account := c.literal(plan.Username) + "@" + c.literal(plan.Host)
+
+		var statements []string
+		switch plan.Action {
+		case UserImportCreate:
+			statements = append(statements, strings.TrimSpace("CREATE USER "+account+" "+plan.Auth))
+			statements = append(statements, plan.Grants...)
+		case UserImportPassword:
+			if plan.Auth == "" {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: the source has no password", plan.Account()))
+				continue
+			}
+			statements = append(statements, "ALTER USER "+account+" "+plan.Auth)
+		case UserImportMerge:
+			statements = append(statements, plan.MissingGrants...)
+		default:
+			result.Skipped++
+			continue
+		}
+
+		var failed error
+		for _, stmt := range statements {
+			if _, err := c.DB.Exec(stmt); err != nil {
+				failed = err
+				break
+			}
+		}
+		if failed != nil {
+			if plan.Action == UserImportCreate {
+				c.DropUser(plan.Username, plan.Host)
+			}
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", plan.Account(), failed))
+			continue
+		}
+
+		switch plan.Action {
+		case UserImportCreate:
+			result.Created++
+			if plan.Auth == "" {
+				// An account without a password would let anyone in
+				if err := c.SetUserLocked(plan.Username, plan.Host, true); err != nil {
+					result.Notes = append(result.Notes, fmt.Sprintf("%s has no password and could not be locked: %v", plan.Account(), err))
+				} else {
+					result.Notes = append(result.Notes, fmt.Sprintf("%s has no password in the source and was locked", plan.Account()))
+				}
+			}
+		case UserImportPassword:
+			result.Passwords++
+		case UserImportMerge:
+			result.Merged++
+		}
+	}
+
+	c.flushPrivileges()
+	return result
+}
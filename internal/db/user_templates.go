@@ -0,0 +1,76 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PermissionTemplate is a named set of privileges granted in one step
+type PermissionTemplate struct {
+	Name        string
+	Description string
+	Privileges  []string
+}
+
+// PermissionTemplates returns the built-in templates for the connected server
+func (c *Connection) PermissionTemplates() []PermissionTemplate {
+	if isPostgresType(c.Config.Type) {
+		return []PermissionTemplate{
+			{"read-only", "Read tables", []string{"SELECT"}},
+			{"read-write", "Read and change rows", []string{"SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE"}},
+			{"admin", "Everything on the tables", []string{"ALL PRIVILEGES"}},
+		}
+	}
+	return []PermissionTemplate{
+		{"read-only", "Read tables and views", []string{"SELECT", "SHOW VIEW"}},
+		{"read-write", "Read and change rows, run routines", []string{
+			"SELECT", "INSERT", "UPDATE", "DELETE", "CREATE TEMPORARY TABLES",
+			"LOCK TABLES", "EXECUTE", "SHOW VIEW",
+		}},
+		{"admin", "Everything, including schema changes", []string{"ALL PRIVILEGES"}},
+	}
+}
+
+// GetPermissionTemplate returns the template with the given name
+func (c *Connection) GetPermissionTemplate(name string) (PermissionTemplate, error) {
+	var names []string
+	for _, t := range c.PermissionTemplates() {
+		if strings.EqualFold(t.Name, name) {
+			return t, nil
+		}
+		names = append(names, t.Name)
+	}
+	return PermissionTemplate{}, fmt.Errorf("unknown permission template %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// ApplyPermissionTemplate grants a template's privileges to a user on a
+// database, or on all databases when database is empty (MariaDB only)
+func (c *Connection) ApplyPermissionTemplate(username, host, name, database string) error {
+	template, err := c.GetPermissionTemplate(name)
+	if err != nil {
+		return err
+	}
+	if database == "" && isPostgresType(c.Config.Type) {
+		return fmt.Errorf("a database is required to apply a permission template on PostgreSQL")
+	}
+
+	return c.GrantPrivileges(username, host, template.Privileges, database, "")
+}
@@ -19,14 +19,16 @@
 package db
 
 import (
+	"database/sql"
 	"fmt"
+	"regexp"
 	"strings"
 )
 
 // User represents a database user
 type User struct {
-	Username string
-	Host     string // Empty for PostgreSQL
+	Username string `json:"username"`
+	Host     string `json:"host,omitempty"` // Empty for PostgreSQL
 }
 
 // Grant represents a user privilege
@@ -37,6 +39,20 @@ type Grant struct {
 	GrantText string // Raw grant statement (MariaDB)
 }
 
+// GrantStatement returns the GRANT statement that gives a user grant g, to
+// copy or replay elsewhere. MariaDB reports the statement itself; on
+// PostgreSQL it is built from the privilege and the object.
+func (c *Connection) GrantStatement(g Grant, username, host string) string {
+	if g.GrantText != "" {
+		return g.GrantText
+	}
+	if g.Table == "*" {
+		return fmt.Sprintf("GRANT %s ON DATABASE %s TO %s", g.Privilege,
+			c.QuoteIdentifier(g.Database), c.QuoteIdentifier(username))
+	}
+	return c.Driver.GrantPrivilegesQuery([]string{g.Privilege}, g.Database, g.Table, username, host)
+}
+
 // ListUsers returns all database users
 func (c *Connection) ListUsers() ([]User, error) {
 	query := c.Driver.ListUsersQuery()
@@ -87,6 +103,7 @@ func (c *Connection) DropUser(username, host string) error {
 
 	query := c.Driver.DropUserQuery(username, host)
 	_, err := c.DB.Exec(query)
+	c.audit(AuditDropUser, auditAccount(username, host), "", err)
 	if err != nil {
 		return fmt.Errorf("failed to drop user '%s'@'%s': %w", username, host, err)
 	}
@@ -100,6 +117,88 @@ func (c *Connection) DropUser(username, host string) error {
 	return nil
 }
 
+// PasswordNeverExpires turns password expiry off in SetPasswordExpiry
+const PasswordNeverExpires = -1
+
+// ChangeUserPassword sets a user's password
+func (c *Connection) ChangeUserPassword(username, host, password string) error {
+	if host == "" {
+		host = "localhost"
+	}
+
+	query := c.Driver.ChangePasswordQuery(username, host, password)
+	if _, err := c.DB.Exec(query); err != nil {
+		return fmt.Errorf("failed to change password for '%s'@'%s': %w", username, host, err)
+	}
+
+	c.flushPrivileges()
+	return nil
+}
+
+// RenameUser renames a user, or on MariaDB moves it to another host (an
+// empty newHost keeps the current one). Temporary grants tracked for the
+// user follow it so they are still revoked on time.
+func (c *Connection) RenameUser(username, host, newName, newHost string) error {
+	if host == "" {
+		host = "localhost"
+	}
+	if newHost == "" {
+		newHost = host
+	}
+
+	query := c.Driver.RenameUserQuery(username, host, newName, newHost)
+	if _, err := c.DB.Exec(query); err != nil {
+		return fmt.Errorf("failed to rename user '%s'@'%s': %w", username, host, err)
+	}
+
+	c.flushPrivileges()
+	return c.renameTemporaryGrants(username, host, newName, newHost)
+}
+
+// SetUserLocked locks or unlocks an account. MariaDB uses ACCOUNT LOCK;
+// PostgreSQL has no lock, so the role loses or regains LOGIN instead.
+func (c *Connection) SetUserLocked(username, host string, locked bool) error {
+	if host == "" {
+		host = "localhost"
+	}
+
+	action := "unlock"
+	if locked {
+		action = "lock"
+	}
+
+	query := c.Driver.LockUserQuery(username, host, locked)
+	if _, err := c.DB.Exec(query); err != nil {
+		return fmt.Errorf("failed to %s user '%s'@'%s': %w", action, username, host, err)
+	}
+
+	c.flushPrivileges()
+	return nil
+}
+
+// SetPasswordExpiry expires a user's password now (days 0), never
+// (PasswordNeverExpires) or after days days
+func (c *Connection) SetPasswordExpiry(username, host string, days int) error {
+	if host == "" {
+		host = "localhost"
+	}
+
+	query := c.Driver.PasswordExpiryQuery(username, host, days)
+	if _, err := c.DB.Exec(query); err != nil {
+		return fmt.Errorf("failed to set password expiry for '%s'@'%s': %w", username, host, err)
+	}
+
+	c.flushPrivileges()
+	return nil
+}
+
+// flushPrivileges reloads the grant tables on MariaDB
+func (c *Connection) flushPrivileges() {
+	if flushQuery := c.Driver.FlushPrivilegesQuery(); flushQuery != "" {
+		c.DB.Exec(flushQuery)
+	}
+}
+
 // GetUserGrants returns the grants for a user
 func (c *Connection) GetUserGrants(username, host string) ([]Grant, error) {
 	if host == "" {
@@ -154,6 +253,10 @@ func (c *Connection) GrantPrivileges(username, host string, privileges []string,
 		privileges = []string{"ALL PRIVILEGES"}
 	}
 
+	if err := c.checkTableGrantDatabase(database, table); err != nil {
+		return err
+	}
+
 	query := c.Driver.GrantPrivilegesQuery(privileges, database, table, username, host)
 
 	// Handle multiple statements (PostgreSQL may return semicolon-separated)
@@ -188,7 +291,12 @@ func (c *Connection) RevokePrivileges(username, host string, privileges []string
 		privileges = []string{"ALL PRIVILEGES"}
 	}
 
+	if err := c.checkTableGrantDatabase(database, table); err != nil {
+		return err
+	}
+
 	query := c.Driver.RevokePrivilegesQuery(privileges, database, table, username, host)
+	detail := fmt.Sprintf("%s ON %s", strings.Join(privileges, ", "), auditGrantTarget(database, table))
 
 	// Handle multiple statements (PostgreSQL may return semicolon-separated)
 	statements := strings.Split(query, ";")
@@ -199,9 +307,11 @@ func (c *Connection) RevokePrivileges(username, host string, privileges []string
 		}
 		_, err := c.DB.Exec(stmt)
 		if err != nil {
+			c.audit(AuditRevoke, auditAccount(username, host), detail, err)
 			return fmt.Errorf("failed to revoke privileges: %w", err)
 		}
 	}
+	c.audit(AuditRevoke, auditAccount(username, host), detail, nil)
 
 	// Flush privileges for MariaDB
 	flushQuery := c.Driver.FlushPrivilegesQuery()
@@ -234,6 +344,94 @@ func (c *Connection) CreateUserWithDBAccess(username, host, password, database s
 	return nil
 }
 
+// CloneUser creates newName@newHost with password and copies every grant
+// of username@host to it (an empty newHost keeps the source's host). On
+// PostgreSQL the role attributes and memberships are copied as well, but
+// table grants only from the connected database since PostgreSQL keeps them
+// per database. The new user is dropped again if a grant can't be copied.
+func (c *Connection) CloneUser(username, host, newName, newHost, password string) error {
+	if host == "" {
+		host = "localhost"
+	}
+	if newHost == "" {
+		newHost = host
+	}
+
+	grants, err := c.GetUserGrants(username, host)
+	if err != nil {
+		return err
+	}
+
+	var statements []string
+	if isPostgresType(c.Config.Type) {
+		statements, err = c.postgresCloneStatements(username, newName, grants)
+		if err != nil {
+			return err
+		}
+	} else {
+		statements = mariadbCloneStatements(grants, username, host, c.literal(newName)+"@"+c.literal(newHost))
+	}
+
+	if err := c.CreateUser(newName, newHost, password); err != nil {
+		return err
+	}
+
+	for _, stmt := range statements {
+		if _, err := c.DB.Exec(stmt); err != nil {
+			c.DropUser(newName, newHost)
+			return fmt.Errorf("failed to copy grants of '%s'@'%s' to '%s'@'%s': %w", username, host, newName, newHost, err)
+		}
+	}
+
+	c.flushPrivileges()
+	return nil
+}
+
+// grantIdentifiedRe matches the authentication clause MariaDB shows on the
+// USAGE grant, up to any WITH or REQUIRE options that follow it
+var grantIdentifiedRe = regexp.MustCompile(`(?i)\s+IDENTIFIED\s+(?:BY|VIA|WITH)\s.*?(\s+(?:WITH|REQUIRE)\s|$)`)
+
+// mariadbCloneStatements rewrites SHOW GRANTS output for another account,
+// leaving out the source's password since the clone has its own
+func mariadbCloneStatements(grants []Grant, username, host, account string) []string {
+	source := regexp.MustCompile("[`']" + regexp.QuoteMeta(username) + "[`']@[`']" + regexp.QuoteMeta(host) + "[`']")
+
+	var statements []string
+	for _, g := range grants {
+		stmt := grantIdentifiedRe.ReplaceAllString(g.GrantText, "$1")
+		statements = append(statements, source.ReplaceAllLiteralString(stmt, account))
+	}
+	return statements
+}
+
+// postgresCloneStatements returns the statements that give newName the
+// attributes, memberships and grants of username
+func (c *Connection) postgresCloneStatements(username, newName string, grants []Grant) ([]string, error) {
+	attrs, err := c.GetRoleAttributes(username)
+	if err != nil {
+		return nil, err
+	}
+
+	role := c.QuoteIdentifier(newName)
+	attrs.Name = newName
+	statements := []string{c.AlterRoleStatement(*attrs)}
+
+	for _, member := range attrs.MemberOf {
+		statements = append(statements, fmt.Sprintf("GRANT %s TO %s", c.QuoteIdentifier(member), role))
+	}
+
+	for _, g := range grants {
+		if g.Table == "*" {
+			statements = append(statements, fmt.Sprintf("GRANT %s ON DATABASE %s TO %s",
+				g.Privilege, c.QuoteIdentifier(g.Database), role))
+		} else {
+			statements = append(statements, fmt.Sprintf("GRANT %s ON TABLE %s TO %s",
+				g.Privilege, c.quoteQualified(g.Table), role))
+		}
+	}
+	return statements, nil
+}
+
 // UserExists checks if a user exists
 func (c *Connection) UserExists(username, host string) (bool, error) {
 	users, err := c.ListUsers()
@@ -257,6 +455,107 @@ func (c *Connection) UserExists(username, host string) (bool, error) {
 	return false, nil
 }
 
+// checkTableGrantDatabase makes sure a PostgreSQL table grant goes to the
+// intended database: table names resolve in the connected database only
+func (c *Connection) checkTableGrantDatabase(database, table string) error {
+	if table == "" || database == "" || !isPostgresType(c.Config.Type) || database == c.Config.Database {
+		return nil
+	}
+	return fmt.Errorf("table grants apply to the connected database %s, connect to %s to grant on its tables",
+		c.Config.Database, database)
+}
+
+// ColumnGrantPrivileges are the privileges that can be granted on columns
+var ColumnGrantPrivileges = []string{"SELECT", "INSERT", "UPDATE", "REFERENCES"}
+
+// ColumnPrivileges limits privileges to columns, e.g. SELECT becomes
+// SELECT (`a`, `b`). The result can be passed to GrantPrivileges,
+// RevokePrivileges and GrantTemporary along with the table.
+func (c *Connection) ColumnPrivileges(privileges, columns []string) ([]string, error) {
+	if len(columns) == 0 {
+		return privileges, nil
+	}
+	if len(privileges) == 0 {
+		return nil, fmt.Errorf("choose the column privileges to grant: %s", strings.Join(ColumnGrantPrivileges, ", "))
+	}
+
+	list := "(" + c.quoteIdentifiers(columns) + ")"
+	result := make([]string, len(privileges))
+	for i, priv := range privileges {
+		priv = strings.ToUpper(strings.TrimSpace(priv))
+		if !containsString(ColumnGrantPrivileges, priv) {
+			return nil, fmt.Errorf("%s can't be granted on columns, only %s", priv, strings.Join(ColumnGrantPrivileges, ", "))
+		}
+		result[i] = priv + " " + list
+	}
+	return result, nil
+}
+
+// ListGrantTables returns the tables of a database that privileges can be
+// granted on. PostgreSQL tables are schema-qualified and, since they can
+// only be reached from their own database, listed for the connected one only.
+func (c *Connection) ListGrantTables(database string) ([]string, error) {
+	var rows *sql.Rows
+	var err error
+	if isPostgresType(c.Config.Type) {
+		if database != c.Config.Database {
+			return nil, fmt.Errorf("connect to %s to grant on its tables", database)
+		}
+		rows, err = c.DB.Query(`SELECT table_schema || '.' || table_name
+		FROM information_schema.tables
+		WHERE table_schema NOT IN ('pg_catalog', 'information_schema')
+		ORDER BY table_schema, table_name`)
+	} else {
+		rows, err = c.DB.Query(`SELECT TABLE_NAME FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = ? ORDER BY TABLE_NAME`, database)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables of %s: %w", database, err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// ListGrantColumns returns the columns of a table from ListGrantTables
+func (c *Connection) ListGrantColumns(database, table string) ([]string, error) {
+	var rows *sql.Rows
+	var err error
+	if isPostgresType(c.Config.Type) {
+		schema, name, ok := strings.Cut(table, ".")
+		if !ok {
+			schema, name = "public", table
+		}
+		rows, err = c.DB.Query(`SELECT column_name FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = $2 ORDER BY ordinal_position`, schema, name)
+	} else {
+		rows, err = c.DB.Query(`SELECT COLUMN_NAME FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION`, database, table)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
 // CommonPrivileges returns a list of common privilege options
 func CommonPrivileges() []string {
 	return []string{
@@ -0,0 +1,252 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConverterColumn is the column a value converter works on
+type ConverterColumn struct {
+	Table   string
+	Column  string
+	Type    string // Source column type, e.g. bigint(20) unsigned
+	Dialect OutputDialect
+}
+
+// ValueConverter resolves what an export in another dialect can't carry
+// over as-is, for the columns of one source type
+type ValueConverter struct {
+	// Type, when set, returns the target column type in place of the one
+	// YSM maps; mapType maps any source type the built-in way. A converted
+	// type drops the CHECK an ENUM would get.
+	Type func(col ConverterColumn, mapType func(sourceType string) string) string
+
+	// Convert, when set, rewrites each value before it's written: the
+	// driver's value in, nil for NULL out. An error stops the export.
+	Convert func(col ConverterColumn, val interface{}) (interface{}, error)
+}
+
+// converterKey names a source type and dialect; DialectNative matches every
+// output dialect
+type converterKey struct {
+	sourceType string
+	dialect    OutputDialect
+}
+
+// ValueConverters picks a converter for each column of a dialect export by
+// its source type. A converter for a dialect wins over one for every
+// dialect, and one for an unsigned type over one for the type itself.
+type ValueConverters struct {
+	converters map[converterKey]ValueConverter
+}
+
+// NewValueConverters creates an empty set of converters
+func NewValueConverters() *ValueConverters {
+	return &ValueConverters{converters: make(map[converterKey]ValueConverter)}
+}
+
+// Register uses conv for columns of sourceType (e.g. datetime or bigint
+// unsigned; lengths don't matter) exported to dialect, or to every dialect
+// when dialect is DialectNative
+func (vc *ValueConverters) Register(sourceType string, dialect OutputDialect, conv ValueConverter) {
+	vc.converters[converterKey{converterTypeKey(sourceType), dialect}] = conv
+}
+
+// Use registers the named converter (see ConverterNames) for sourceType
+func (vc *ValueConverters) Use(sourceType string, dialect OutputDialect, name string) error {
+	conv, ok := namedConverters[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return fmt.Errorf("unknown converter: %s (use: %s)", name, strings.Join(ConverterNames(), ", "))
+	}
+	if strings.TrimSpace(sourceType) == "" {
+		return fmt.Errorf("converter %s needs a source column type", name)
+	}
+	vc.Register(sourceType, dialect, conv)
+	return nil
+}
+
+// lookup returns the converter for a column of sqlType, or nil
+func (vc *ValueConverters) lookup(sqlType string, dialect OutputDialect) *ValueConverter {
+	if vc == nil || len(vc.converters) == 0 {
+		return nil
+	}
+	key := converterTypeKey(sqlType)
+	keys := []string{key}
+	if base := strings.TrimSuffix(key, " unsigned"); base != key {
+		keys = append(keys, base)
+	}
+	for _, k := range keys {
+		for _, d := range []OutputDialect{dialect, DialectNative} {
+			if conv, ok := vc.converters[converterKey{k, d}]; ok {
+				return &conv
+			}
+		}
+	}
+	return nil
+}
+
+// converterTypeKey reduces a column type to what converters are chosen by:
+// its name, without lengths, and whether it's unsigned or an array
+func converterTypeKey(sqlType string) string {
+	ct := parseColumnType(sqlType)
+	key := ct.base
+	if ct.unsigned {
+		key += " unsigned"
+	}
+	if ct.array {
+		key += "[]"
+	}
+	return key
+}
+
+// namedConverters are the converters config files and flags can pick
+var namedConverters = map[string]ValueConverter{
+	// MariaDB's 0000-00-00 reaches YSM as the zero time, which other
+	// engines would store as 0001-01-01
+	"zero-date-null": {Convert: func(col ConverterColumn, val interface{}) (interface{}, error) {
+		if isZeroDate(val) {
+			return nil, nil
+		}
+		return val, nil
+	}},
+	"zero-date-epoch": {Convert: func(col ConverterColumn, val interface{}) (interface{}, error) {
+		if isZeroDate(val) {
+			return time.Unix(0, 0).UTC(), nil
+		}
+		return val, nil
+	}},
+	// Oracle reads '' as NULL anyway; elsewhere it keeps NOT NULL honest
+	"empty-null": {Convert: func(col ConverterColumn, val interface{}) (interface{}, error) {
+		if s, ok := converterString(val); ok && s == "" {
+			return nil, nil
+		}
+		return val, nil
+	}},
+	// Non-strict MariaDB stores invalid ENUM values as '', which the CHECK
+	// an ENUM becomes would reject
+	"enum-invalid-null": {Convert: func(col ConverterColumn, val interface{}) (interface{}, error) {
+		s, ok := converterString(val)
+		if !ok {
+			return val, nil
+		}
+		for _, label := range splitEnumLabels(parseColumnType(col.Type).args) {
+			if s == label {
+				return val, nil
+			}
+		}
+		return nil, nil
+	}},
+	// Keeps unsigned integers in the target's signed type of the same
+	// width, failing on values that don't fit rather than wrapping them
+	"signed": {
+		Type: func(col ConverterColumn, mapType func(string) string) string {
+			return mapType(strings.Replace(strings.ToLower(col.Type), "unsigned", "", 1))
+		},
+		Convert: func(col ConverterColumn, val interface{}) (interface{}, error) {
+			if n, ok := val.(uint64); ok {
+				if n > math.MaxInt64 {
+					return nil, fmt.Errorf("%d doesn't fit a signed integer", n)
+				}
+				return int64(n), nil
+			}
+			return val, nil
+		},
+	},
+	// Keeps the source's text, for types the target would reject or mangle
+	"text": {
+		Type: func(col ConverterColumn, mapType func(string) string) string {
+			return mapType("text")
+		},
+		Convert: func(col ConverterColumn, val interface{}) (interface{}, error) {
+			switch v := val.(type) {
+			case nil, []byte, string:
+				return val, nil
+			case time.Time:
+				return v.Format("2006-01-02 15:04:05.999999"), nil
+			}
+			return fmt.Sprintf("%v", val), nil
+		},
+	},
+}
+
+// RegisterValueConverter adds a named converter, which config files and
+// --convert can then pick like the built-in ones
+func RegisterValueConverter(name string, conv ValueConverter) {
+	namedConverters[strings.ToLower(name)] = conv
+}
+
+// ConverterNames lists the converters that can be picked by name
+func ConverterNames() []string {
+	names := make([]string, 0, len(namedConverters))
+	for name := range namedConverters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isZeroDate(val interface{}) bool {
+	if t, ok := val.(time.Time); ok {
+		return t.IsZero()
+	}
+	s, ok := converterString(val)
+	return ok && strings.HasPrefix(s, "0000-00-00")
+}
+
+// converterString returns a text value the driver returned as bytes or a string
+func converterString(val interface{}) (string, bool) {
+	switch v := val.(type) {
+	case []byte:
+		return string(v), true
+	case string:
+		return v, true
+	}
+	return "", false
+}
+
+// rowConverters resolves the converter of each result column of a table,
+// or returns nil when none has one
+func (vc *ValueConverters) rowConverters(def TableDef, dialect OutputDialect, columns []string) ([]*ValueConverter, []ConverterColumn) {
+	if vc == nil || len(vc.converters) == 0 {
+		return nil, nil
+	}
+	types := make(map[string]string, len(def.Columns))
+	for _, col := range def.Columns {
+		types[col.Name] = col.Type
+	}
+	convs := make([]*ValueConverter, len(columns))
+	cols := make([]ConverterColumn, len(columns))
+	found := false
+	for i, name := range columns {
+		cols[i] = ConverterColumn{Table: def.Name, Column: name, Type: types[name], Dialect: dialect}
+		if conv := vc.lookup(types[name], dialect); conv != nil && conv.Convert != nil {
+			convs[i] = conv
+			found = true
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	return convs, cols
+}
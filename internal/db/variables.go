@@ -19,15 +19,22 @@
 package db
 
 import (
+	"database/sql"
 	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 // Variable represents a database system variable
 type Variable struct {
-	Name  string
-	Value string
-	Scope string // GLOBAL, SESSION, or BOTH
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Scope   string `json:"scope,omitempty"`   // GLOBAL, SESSION, or BOTH
+	Default string `json:"default,omitempty"` // Compiled-in default, set by AnnotateDefaults; "" when unknown
+	Changed bool   `json:"changed,omitempty"` // Differs from the default, set by AnnotateDefaults
 }
 
 // GetVariable retrieves a single system variable value
@@ -152,7 +159,12 @@ func (c *Connection) ApplyVariables(vars map[string]string) error {
 	for name, value := range vars {
 		if err := c.SetVariable(name, value, false); err != nil {
 			errors = append(errors, fmt.Sprintf("%s: %v", name, err))
+			continue
 		}
+		if c.variables == nil {
+			c.variables = make(map[string]string)
+		}
+		c.variables[name] = value
 	}
 
 	if len(errors) > 0 {
@@ -208,3 +220,434 @@ func (c *Connection) GetVariableInfo(name string) (*Variable, error) {
 
 	return v, nil
 }
+
+// AnnotateDefaults fills in each variable's compiled-in default and whether
+// it was changed from it. MariaDB reads information_schema.SYSTEM_VARIABLES
+// (10.1+) and compares values; PostgreSQL reads pg_settings, where anything
+// not coming from the default counts as changed.
+func (c *Connection) AnnotateDefaults(variables []Variable) error {
+	type setting struct {
+		value   string
+		changed bool
+	}
+	defaults := make(map[string]setting)
+
+	if isPostgresType(c.Config.Type) {
+		rows, err := c.DB.Query(`SELECT name, COALESCE(boot_val, ''), COALESCE(unit, ''),
+			source NOT IN ('default', 'override')
+		FROM pg_settings`)
+		if err != nil {
+			return fmt.Errorf("failed to get variable defaults: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var name, value, unit string
+			var changed bool
+			if err := rows.Scan(&name, &value, &unit, &changed); err != nil {
+				return err
+			}
+			if unit != "" && value != "" {
+				// Units like 8kB are a multiplier: 16384 x 8kB
+				if unit[0] >= '0' && unit[0] <= '9' {
+					value += " × " + unit
+				} else {
+					value += " " + unit
+				}
+			}
+			defaults[name] = setting{value: value, changed: changed}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	} else {
+		rows, err := c.DB.Query(`SELECT LOWER(VARIABLE_NAME), DEFAULT_VALUE
+		FROM information_schema.SYSTEM_VARIABLES WHERE DEFAULT_VALUE IS NOT NULL`)
+		if err != nil {
+			return fmt.Errorf("failed to get variable defaults: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var name, value string
+			if err := rows.Scan(&name, &value); err != nil {
+				return err
+			}
+			defaults[name] = setting{value: value}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	for i := range variables {
+		d, ok := defaults[strings.ToLower(variables[i].Name)]
+		if !ok {
+			continue
+		}
+		variables[i].Default = d.value
+		if isPostgresType(c.Config.Type) {
+			variables[i].Changed = d.changed
+		} else {
+			variables[i].Changed = !strings.EqualFold(variables[i].Value, d.value)
+		}
+	}
+	return nil
+}
+
+// How a changed variable takes effect
+const (
+	VariableApplyDynamic     = "dynamic"      // At once
+	VariableApplyNewSessions = "new sessions" // For connections opened after the change
+	VariableApplyReload      = "reload"       // Once the configuration is reloaded
+	VariableApplyRestart     = "restart"      // Only after a server restart
+	VariableApplyReadOnly    = "read-only"    // Can't be changed at runtime
+)
+
+// Ways to apply a variable change
+const (
+	VariableSetSession = "session" // SET, for the current connection
+	VariableSetGlobal  = "global"  // SET GLOBAL, until the server restarts (MariaDB)
+	VariableSetSystem  = "system"  // ALTER SYSTEM and pg_reload_conf() (PostgreSQL)
+)
+
+// VariableMeta describes the values a variable accepts and how changes to
+// it take effect
+type VariableMeta struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`              // bool, integer, real, enum, set or string; "" when unknown
+	Min     string   `json:"min,omitempty"`     // Lowest number allowed, in Unit
+	Max     string   `json:"max,omitempty"`     // Highest number allowed, in Unit
+	Values  []string `json:"values,omitempty"`  // Allowed values of an enum or set
+	Unit    string   `json:"unit,omitempty"`    // PostgreSQL base unit, such as kB, 8kB or ms
+	Apply   string   `json:"apply"`             // One of the VariableApply constants
+	Methods []string `json:"methods,omitempty"` // VariableSet constants that can change it, most local first
+	Comment string   `json:"comment,omitempty"` // Server's description
+}
+
+// GetVariableMeta reads the type, range and change behavior of a variable
+// from pg_settings, or on MariaDB from information_schema.SYSTEM_VARIABLES
+// (10.1+). A server without that metadata gives a meta with an empty Type,
+// whose values aren't validated.
+func (c *Connection) GetVariableMeta(name string) (*VariableMeta, error) {
+	m := &VariableMeta{Name: name}
+
+	if isPostgresType(c.Config.Type) {
+		var context, values string
+		err := c.DB.QueryRow(`SELECT vartype, COALESCE(min_val, ''), COALESCE(max_val, ''),
+			COALESCE(array_to_string(enumvals, ','), ''), COALESCE(unit, ''), context, short_desc
+		FROM pg_settings WHERE name = $1`, name).Scan(&m.Type, &m.Min, &m.Max, &values, &m.Unit, &context, &m.Comment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get variable '%s': %w", name, err)
+		}
+		if values != "" {
+			m.Values = strings.Split(values, ",")
+		}
+
+		switch context {
+		case "internal":
+			m.Apply = VariableApplyReadOnly
+		case "postmaster":
+			m.Apply = VariableApplyRestart
+			m.Methods = []string{VariableSetSystem}
+		case "sighup":
+			m.Apply = VariableApplyReload
+			m.Methods = []string{VariableSetSystem}
+		case "backend", "superuser-backend":
+			m.Apply = VariableApplyNewSessions
+			m.Methods = []string{VariableSetSystem}
+		default: // user, superuser
+			m.Apply = VariableApplyDynamic
+			m.Methods = []string{VariableSetSession, VariableSetSystem}
+		}
+		return m, nil
+	}
+
+	var varType, scope, readOnly string
+	var min, max, values sql.NullString
+	err := c.DB.QueryRow(`SELECT VARIABLE_TYPE, VARIABLE_SCOPE, READ_ONLY, NUMERIC_MIN_VALUE, NUMERIC_MAX_VALUE,
+			ENUM_VALUE_LIST, COALESCE(VARIABLE_COMMENT, '')
+		FROM information_schema.SYSTEM_VARIABLES WHERE VARIABLE_NAME = ?`, name).
+		Scan(&varType, &scope, &readOnly, &min, &max, &values, &m.Comment)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("variable '%s' not found", name)
+	}
+	if err != nil {
+		// MySQL and MariaDB before 10.1 have no SYSTEM_VARIABLES
+		m.Apply = VariableApplyDynamic
+		m.Methods = []string{VariableSetSession, VariableSetGlobal}
+		return m, nil
+	}
+
+	switch {
+	case varType == "BOOLEAN":
+		m.Type = "bool"
+	case strings.Contains(varType, "INT"):
+		m.Type = "integer"
+	case varType == "DOUBLE":
+		m.Type = "real"
+	case varType == "ENUMERATION":
+		m.Type = "enum"
+	case varType == "SET":
+		m.Type = "set"
+	default: // VARCHAR, FLAGSET
+		m.Type = "string"
+	}
+	if m.Type == "integer" || m.Type == "real" {
+		m.Min, m.Max = min.String, max.String
+	}
+	if (m.Type == "enum" || m.Type == "set") && values.String != "" {
+		m.Values = strings.Split(values.String, ",")
+	}
+
+	if readOnly == "YES" {
+		m.Apply = VariableApplyRestart
+		return m, nil
+	}
+	m.Apply = VariableApplyDynamic
+	switch scope {
+	case "GLOBAL":
+		m.Methods = []string{VariableSetGlobal}
+	case "SESSION ONLY":
+		m.Methods = []string{VariableSetSession}
+	default:
+		m.Methods = []string{VariableSetSession, VariableSetGlobal}
+	}
+	return m, nil
+}
+
+// Validate checks a new value against the variable's type, range and
+// allowed values. Numbers with a unit, such as 64MB or 5min, are left to
+// the server when the variable has a unit.
+func (m *VariableMeta) Validate(value string) error {
+	if strings.EqualFold(value, "DEFAULT") {
+		return nil
+	}
+
+	switch m.Type {
+	case "bool":
+		switch strings.ToLower(value) {
+		case "on", "off", "true", "false", "yes", "no", "1", "0":
+			return nil
+		}
+		return fmt.Errorf("%s is a boolean: use on or off", m.Name)
+
+	case "integer", "real":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			if m.Unit != "" && unitValue.MatchString(value) {
+				return nil
+			}
+			return fmt.Errorf("%s takes a number", m.Name)
+		}
+		if m.Type == "integer" && number != math.Trunc(number) {
+			return fmt.Errorf("%s takes a whole number", m.Name)
+		}
+		if lower, err := strconv.ParseFloat(m.Min, 64); err == nil && number < lower {
+			return fmt.Errorf("%s must be at least %s%s", m.Name, m.Min, m.unitSuffix())
+		}
+		if upper, err := strconv.ParseFloat(m.Max, 64); err == nil && number > upper {
+			return fmt.Errorf("%s must be at most %s%s", m.Name, m.Max, m.unitSuffix())
+		}
+
+	case "enum":
+		if len(m.Values) > 0 && !containsFold(m.Values, value) {
+			return fmt.Errorf("%s must be one of %s", m.Name, strings.Join(m.Values, ", "))
+		}
+
+	case "set":
+		if value == "" || len(m.Values) == 0 {
+			return nil
+		}
+		for _, item := range strings.Split(value, ",") {
+			if !containsFold(m.Values, strings.TrimSpace(item)) {
+				return fmt.Errorf("%s: %q isn't one of %s", m.Name, strings.TrimSpace(item), strings.Join(m.Values, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// unitValue matches a number with a unit, as PostgreSQL accepts for
+// memory and time settings
+var unitValue = regexp.MustCompile(`^\s*-?[0-9]+(\.[0-9]+)?\s*(B|kB|MB|GB|TB|us|ms|s|min|h|d)\s*$`)
+
+func (m *VariableMeta) unitSuffix() string {
+	if m.Unit == "" {
+		return ""
+	}
+	return " " + m.Unit
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyHint explains when a change made with method takes effect
+func (m *VariableMeta) ApplyHint(method string) string {
+	switch method {
+	case VariableSetSession:
+		return "applies to this session only"
+	case VariableSetGlobal:
+		return "takes effect for new sessions and is lost at restart; add it to the option file (my.cnf) to keep it"
+	case VariableSetSystem:
+		switch m.Apply {
+		case VariableApplyRestart:
+			return "written to postgresql.auto.conf; takes effect after a server restart"
+		case VariableApplyNewSessions:
+			return "written to postgresql.auto.conf and reloaded; applies to new connections"
+		default:
+			return "written to postgresql.auto.conf and reloaded; takes effect at once"
+		}
+	}
+	switch m.Apply {
+	case VariableApplyRestart:
+		return "read-only at runtime; set it in the server's configuration and restart"
+	case VariableApplyReadOnly:
+		return "fixed when the server was built or initialized"
+	}
+	return ""
+}
+
+// ApplyVariableChange sets a variable with one of the VariableSet methods.
+// VariableSetSystem writes it with ALTER SYSTEM and reloads the
+// configuration.
+func (c *Connection) ApplyVariableChange(name, value, method string) error {
+	switch method {
+	case VariableSetSession:
+		return c.SetVariable(name, value, false)
+	case VariableSetGlobal:
+		if isPostgresType(c.Config.Type) {
+			return fmt.Errorf("PostgreSQL has no SET GLOBAL; use ALTER SYSTEM")
+		}
+		return c.SetVariable(name, value, true)
+	case VariableSetSystem:
+		if !isPostgresType(c.Config.Type) {
+			return fmt.Errorf("ALTER SYSTEM is PostgreSQL only")
+		}
+		if err := c.SetVariable(name, value, true); err != nil {
+			return err
+		}
+		return c.ReloadConfig()
+	}
+	return fmt.Errorf("unknown way to set a variable: %s", method)
+}
+
+// ReloadConfig has PostgreSQL reread its configuration files
+func (c *Connection) ReloadConfig() error {
+	if _, err := c.DB.Exec("SELECT pg_reload_conf()"); err != nil {
+		return fmt.Errorf("failed to reload the configuration: %w", err)
+	}
+	return nil
+}
+
+// Variable categories, in display order. The first category whose
+// patterns match a variable's name wins.
+var variableCategories = []struct {
+	name     string
+	patterns []string
+}{
+	{"InnoDB", []string{"innodb_"}},
+	{"WAL", []string{"wal_", "checkpoint", "archive_", "full_page_writes", "synchronous_commit", "commit_", "fsync"}},
+	{"Replication", []string{"repl", "slave", "replica", "master", "gtid", "binlog", "log_bin", "relay", "wsrep_", "hot_standby", "primary_", "max_wal_senders", "standby"}},
+	{"Logging", []string{"log"}},
+	{"Memory", []string{"buffer", "cache", "mem", "_size", "sort_", "join_"}},
+	{"Connections", []string{"connect", "thread", "timeout", "listen", "port", "ssl", "socket"}},
+}
+
+// VariableCategoryOther holds variables no other category matches
+const VariableCategoryOther = "Other"
+
+// VariableCategory returns the category a variable belongs to, such as
+// Memory, Logging, Replication, InnoDB or WAL
+func VariableCategory(name string) string {
+	lower := strings.ToLower(name)
+	for _, cat := range variableCategories {
+		for _, p := range cat.patterns {
+			if strings.Contains(lower, p) {
+				return cat.name
+			}
+		}
+	}
+	return VariableCategoryOther
+}
+
+// SortVariablesByCategory orders variables by category, then by name
+func SortVariablesByCategory(variables []Variable) {
+	rank := make(map[string]int, len(variableCategories)+1)
+	for i, cat := range variableCategories {
+		rank[cat.name] = i
+	}
+	rank[VariableCategoryOther] = len(variableCategories)
+
+	sort.SliceStable(variables, func(i, j int) bool {
+		ci, cj := rank[VariableCategory(variables[i].Name)], rank[VariableCategory(variables[j].Name)]
+		if ci != cj {
+			return ci < cj
+		}
+		return variables[i].Name < variables[j].Name
+	})
+}
+
+// VariableDiff is a variable whose value differs between two servers. A
+// value is empty when the variable doesn't exist on that server.
+type VariableDiff struct {
+	Name  string
+	Local string
+	Other string
+}
+
+// volatileVariables differ between any two servers and are left out of diffs
+var volatileVariables = map[string]bool{
+	"hostname": true, "server_id": true, "server_uuid": true, "pid_file": true,
+	"gtid_binlog_pos": true, "gtid_binlog_state": true, "gtid_current_pos": true,
+	"gtid_slave_pos": true, "timestamp": true, "rand_seed1": true, "rand_seed2": true,
+	"pseudo_thread_id": true, "last_insert_id": true, "insert_id": true, "identity": true,
+	"warning_count": true, "error_count": true, "wsrep_node_name": true,
+	"wsrep_node_address": true, "wsrep_node_incoming_address": true,
+}
+
+// DiffVariables compares the global variables of this server and another,
+// returning the ones that differ by name. Per-server identity values such
+// as hostname and server_uuid are left out.
+func (c *Connection) DiffVariables(other *Connection) ([]VariableDiff, error) {
+	if isPostgresType(c.Config.Type) != isPostgresType(other.Config.Type) {
+		return nil, fmt.Errorf("can't compare the variables of a %s and a %s server", c.Config.Type, other.Config.Type)
+	}
+
+	local, err := c.GetGlobalVariables("%")
+	if err != nil {
+		return nil, err
+	}
+	remote, err := other.GetGlobalVariables("%")
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]*VariableDiff)
+	for _, v := range local {
+		values[strings.ToLower(v.Name)] = &VariableDiff{Name: v.Name, Local: v.Value}
+	}
+	for _, v := range remote {
+		key := strings.ToLower(v.Name)
+		if d, ok := values[key]; ok {
+			d.Other = v.Value
+		} else {
+			values[key] = &VariableDiff{Name: v.Name, Other: v.Value}
+		}
+	}
+
+	var diffs []VariableDiff
+	for key, d := range values {
+		if d.Local != d.Other && !volatileVariables[key] {
+			diffs = append(diffs, *d)
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+	return diffs, nil
+}
@@ -0,0 +1,730 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package db
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Workload is a window of queries captured from a server, to be replayed
+// against another one
+type Workload struct {
+	Server     DatabaseType    `json:"server"`
+	Source     string          `json:"source"` // mysql.general_log, information_schema.PROCESSLIST, pg_stat_activity or a log file
+	Started    time.Time       `json:"started"`
+	DurationMs float64         `json:"duration_ms"`
+	Queries    []CapturedQuery `json:"queries"` // In the order they started
+}
+
+// CapturedQuery is one statement of a workload
+type CapturedQuery struct {
+	OffsetMs   float64 `json:"offset_ms"` // Since the first query started
+	Session    int64   `json:"session"`   // Connection or backend it ran on
+	Database   string  `json:"database,omitempty"`
+	Query      string  `json:"query"`
+	DurationMs float64 `json:"duration_ms,omitempty"` // How long it originally took, when the source tells
+}
+
+// CaptureOptions configures a workload capture
+type CaptureOptions struct {
+	Duration   time.Duration // How long to record
+	Interval   time.Duration // Between samples of the process list or pg_stat_activity
+	Database   string        // Only queries run in this database
+	OnProgress func(captured int, elapsed time.Duration)
+}
+
+// DefaultCaptureInterval is how often sessions are sampled by default
+const DefaultCaptureInterval = 100 * time.Millisecond
+
+// CaptureWorkload records the queries other sessions run for a while. A
+// MariaDB server with general_log on and log_output including TABLE is read
+// from mysql.general_log, which has every statement; otherwise the process
+// list or pg_stat_activity is sampled, which misses statements that start
+// and finish between two samples. Cancelling ctx ends the capture early.
+func (c *Connection) CaptureWorkload(ctx context.Context, opts CaptureOptions) (*Workload, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultCaptureInterval
+	}
+	w := &Workload{Server: c.Config.Type, Started: time.Now()}
+
+	if !isPostgresType(c.Config.Type) {
+		var generalLog, logOutput string
+		err := c.DB.QueryRowContext(ctx, "SELECT @@general_log, @@log_output").Scan(&generalLog, &logOutput)
+		if err == nil && generalLog == "1" && strings.Contains(strings.ToUpper(logOutput), "TABLE") {
+			if err := c.captureGeneralLog(ctx, opts, w); err != nil {
+				return nil, err
+			}
+			return w, nil
+		}
+	}
+	if err := c.sampleWorkload(ctx, opts, w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// waitCapture waits for the end of a capture, reporting progress
+func waitCapture(ctx context.Context, opts CaptureOptions, began time.Time, captured func() int) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	deadline := time.NewTimer(opts.Duration)
+	defer deadline.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline.C:
+			return
+		case <-ticker.C:
+			if opts.OnProgress != nil {
+				opts.OnProgress(captured(), time.Since(began))
+			}
+		}
+	}
+}
+
+// captureGeneralLog reads the statements the general log table gets during
+// the capture
+func (c *Connection) captureGeneralLog(ctx context.Context, opts CaptureOptions, w *Workload) error {
+	w.Source = "mysql.general_log"
+
+	// One connection, so CONNECTION_ID() leaves out all of the capture's own statements
+	conn, err := c.DB.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var from, to string
+	if err := conn.QueryRowContext(ctx, "SELECT CAST(NOW(6) AS CHAR)").Scan(&from); err != nil {
+		return fmt.Errorf("failed to start the capture: %w", err)
+	}
+	waitCapture(ctx, opts, w.Started, func() int { return -1 })
+	if err := conn.QueryRowContext(context.Background(), "SELECT CAST(NOW(6) AS CHAR)").Scan(&to); err != nil {
+		return fmt.Errorf("failed to end the capture: %w", err)
+	}
+
+	rows, err := conn.QueryContext(context.Background(), `SELECT UNIX_TIMESTAMP(event_time) * 1000, thread_id,
+			command_type, CONVERT(argument USING utf8mb4)
+		FROM mysql.general_log
+		WHERE event_time >= ? AND event_time < ? AND thread_id <> CONNECTION_ID()
+		ORDER BY event_time`, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to read mysql.general_log: %w", err)
+	}
+	defer rows.Close()
+
+	g := newGeneralLogReader(w, opts.Database)
+	for rows.Next() {
+		var atMs float64
+		var thread int64
+		var command, argument string
+		if err := rows.Scan(&atMs, &thread, &command, &argument); err != nil {
+			return err
+		}
+		g.event(atMs, thread, command, argument)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	g.finish()
+	return nil
+}
+
+// sampleWorkload polls the statements other sessions are running. A
+// statement is new when its session shows a different query or start time
+// than in the last sample.
+func (c *Connection) sampleWorkload(ctx context.Context, opts CaptureOptions, w *Workload) error {
+	w.Source = "information_schema.PROCESSLIST"
+	if isPostgresType(c.Config.Type) {
+		w.Source = "pg_stat_activity"
+	}
+
+	type execution struct {
+		query   string
+		started float64
+		index   int // In w.Queries, -1 when it wasn't captured
+		running bool
+	}
+	sessions := make(map[int64]*execution)
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	deadline := w.Started.Add(opts.Duration)
+	lastProgress := w.Started
+
+	for first := true; ; first = false {
+		rows, err := c.DB.QueryContext(ctx, c.Driver.WorkloadSampleQuery())
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return fmt.Errorf("failed to sample %s: %w", w.Source, err)
+		}
+		for rows.Next() {
+			var session int64
+			var database, query string
+			var startedMs, elapsedMs float64
+			var running bool
+			if err := rows.Scan(&session, &database, &query, &startedMs, &elapsedMs, &running); err != nil {
+				rows.Close()
+				return err
+			}
+			if opts.Database != "" && database != opts.Database {
+				continue
+			}
+
+			if e := sessions[session]; e != nil && e.query == query && math.Abs(e.started-startedMs) < 5 {
+				// Still the same execution; PostgreSQL tells how long it took once it ends
+				if e.running && !running && e.index >= 0 {
+					w.Queries[e.index].DurationMs = elapsedMs
+				}
+				e.running = running
+				continue
+			}
+
+			e := &execution{query: query, started: startedMs, index: -1, running: running}
+			sessions[session] = e
+			if first && !running {
+				// The last statement of an idle session ran before the capture
+				continue
+			}
+			e.index = len(w.Queries)
+			q := CapturedQuery{OffsetMs: startedMs, Session: session, Database: database, Query: query}
+			if !running {
+				q.DurationMs = elapsedMs
+			}
+			w.Queries = append(w.Queries, q)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil && ctx.Err() == nil {
+			return err
+		}
+
+		if opts.OnProgress != nil && time.Since(lastProgress) >= time.Second {
+			lastProgress = time.Now()
+			opts.OnProgress(len(w.Queries), time.Since(w.Started))
+		}
+		if !time.Now().Before(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	// Offsets so far are start times on the server's clock
+	sort.SliceStable(w.Queries, func(i, j int) bool { return w.Queries[i].OffsetMs < w.Queries[j].OffsetMs })
+	if len(w.Queries) > 0 {
+		first := w.Queries[0].OffsetMs
+		for i := range w.Queries {
+			w.Queries[i].OffsetMs -= first
+		}
+	}
+	w.DurationMs = float64(time.Since(w.Started)) / float64(time.Millisecond)
+	return nil
+}
+
+// generalLogReader turns general log events into a workload, following
+// the database each connection uses
+type generalLogReader struct {
+	w         *Workload
+	database  string // Only queries run in this database
+	databases map[int64]string
+	first     float64
+	last      float64
+}
+
+func newGeneralLogReader(w *Workload, database string) *generalLogReader {
+	return &generalLogReader{w: w, database: database, databases: make(map[int64]string), first: -1}
+}
+
+var generalLogUseRe = regexp.MustCompile("(?i)^use\\s+`?([^`;\\s]+)`?\\s*;?\\s*$")
+
+// event handles one entry: atMs is its time in milliseconds since any
+// fixed point
+func (g *generalLogReader) event(atMs float64, thread int64, command, argument string) {
+	switch command {
+	case "Connect":
+		// user@host on database using TCP/IP
+		if _, rest, ok := strings.Cut(argument, " on "); ok {
+			database, _, _ := strings.Cut(rest, " using ")
+			g.databases[thread] = strings.TrimSpace(database)
+		}
+		return
+	case "Init DB":
+		g.databases[thread] = strings.TrimSpace(argument)
+		return
+	case "Quit":
+		delete(g.databases, thread)
+		return
+	case "Query", "Execute":
+	default:
+		return
+	}
+
+	query := strings.TrimSpace(argument)
+	if m := generalLogUseRe.FindStringSubmatch(query); m != nil {
+		g.databases[thread] = m[1]
+		return
+	}
+	if query == "" || (g.database != "" && g.databases[thread] != g.database) {
+		return
+	}
+
+	if g.first < 0 {
+		g.first = atMs
+		g.w.Started = time.UnixMilli(int64(atMs))
+	}
+	g.last = atMs
+	g.w.Queries = append(g.w.Queries, CapturedQuery{
+		OffsetMs: atMs - g.first,
+		Session:  thread,
+		Database: g.databases[thread],
+		Query:    query,
+	})
+}
+
+func (g *generalLogReader) finish() {
+	if g.first >= 0 {
+		g.w.DurationMs = g.last - g.first
+	}
+}
+
+// General log file lines: an optional time (MariaDB's 241016 9:05:01 or
+// MySQL's ISO 8601), the connection id, the command and its argument.
+// Lines that don't match continue the previous statement.
+var generalLogLineRe = regexp.MustCompile(`^(\d{6} +\d{1,2}:\d\d:\d\d|\d{4}-\d\d-\d\dT[0-9:.]+(?:Z|[+-]\d\d:\d\d)?)?\s+(\d+) ([A-Z][a-z]+(?: [A-Za-z]+)?)\t?(.*)$`)
+
+// ParseGeneralLog reads a workload from a MariaDB or MySQL general query
+// log file. Statements logged in the same second share its time.
+func ParseGeneralLog(r io.Reader, source, database string) (*Workload, error) {
+	w := &Workload{Server: DatabaseTypeMariaDB, Source: source}
+	g := newGeneralLogReader(w, database)
+
+	var atMs float64
+	var thread int64
+	var command string
+	var argument strings.Builder
+	pending := false
+	flush := func() {
+		if pending {
+			g.event(atMs, thread, command, argument.String())
+		}
+		pending = false
+		argument.Reset()
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		m := generalLogLineRe.FindStringSubmatch(line)
+		if m == nil {
+			if pending {
+				argument.WriteByte('\n')
+				argument.WriteString(line)
+			}
+			continue
+		}
+		flush()
+
+		if m[1] != "" {
+			at, err := parseGeneralLogTime(m[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the time of %q: %w", line, err)
+			}
+			atMs = float64(at.UnixNano()) / float64(time.Millisecond)
+		}
+		thread, _ = strconv.ParseInt(m[2], 10, 64)
+		command = m[3]
+		argument.WriteString(m[4])
+		pending = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read general log: %w", err)
+	}
+	flush()
+	g.finish()
+	return w, nil
+}
+
+func parseGeneralLogTime(s string) (time.Time, error) {
+	if strings.Contains(s, "T") {
+		return time.Parse(time.RFC3339Nano, s)
+	}
+	return time.ParseInLocation("060102 15:04:05", strings.Join(strings.Fields(s), " "), time.Local)
+}
+
+// ReadGeneralLog reads a workload from a general query log file
+func ReadGeneralLog(path, database string) (*Workload, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open general log: %w", err)
+	}
+	defer f.Close()
+	return ParseGeneralLog(f, path, database)
+}
+
+// Save writes the workload to a JSON file
+func (w *Workload) Save(path string) error {
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadWorkload reads a workload saved by Save
+func LoadWorkload(path string) (*Workload, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var w Workload
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &w, nil
+}
+
+// ReplayOptions configures a workload replay
+type ReplayOptions struct {
+	Speed       float64               // 1 keeps the original pace, 2 is twice as fast, 0 runs queries back to back
+	Concurrency int                   // Connections to replay on; a session's queries always share one
+	ReadOnly    bool                  // Only replay SELECT, SHOW, EXPLAIN and similar statements
+	Database    string                // Replay every query in this database instead of its own
+	OnProgress  func(done, total int) // Called from the replaying goroutines
+}
+
+// DefaultReplayConcurrency is the number of replay connections by default
+const DefaultReplayConcurrency = 8
+
+// LatencySummary describes a set of query latencies
+type LatencySummary struct {
+	Count int
+	Mean  time.Duration
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// ReplayStat compares the original and replayed latency of one query
+// fingerprint
+type ReplayStat struct {
+	Fingerprint string
+	Example     string
+	Calls       int
+	Errors      int
+	Original    time.Duration // Mean original latency, 0 when the capture didn't have it
+	Replayed    time.Duration // Mean replay latency
+}
+
+// Change returns the replayed latency as a multiple of the original, or 0
+// when the original isn't known
+func (s ReplayStat) Change() float64 {
+	if s.Original <= 0 {
+		return 0
+	}
+	return float64(s.Replayed) / float64(s.Original)
+}
+
+// ReplayReport is the outcome of a workload replay
+type ReplayReport struct {
+	Source      string
+	Replayed    int
+	Skipped     int // Writes with ReadOnly, or queries of another PostgreSQL database
+	Errors      int
+	ErrorSample []string // The first few errors
+	Duration    time.Duration
+	MaxLag      time.Duration // How far the replay fell behind the schedule at worst
+	Interrupted bool
+	Latency     LatencySummary // Of every replayed query
+	Original    LatencySummary // Of the queries the capture timed, as captured
+	Compared    LatencySummary // Of the same queries, as replayed
+	Queries     []ReplayStat   // By fingerprint, the biggest slowdowns first
+}
+
+const maxReplayErrors = 10
+
+// readOnlyStatement reports whether a statement only reads
+func readOnlyStatement(query string) bool {
+	switch strings.ToUpper(firstKeyword(query)) {
+	case "SELECT", "SHOW", "EXPLAIN", "DESCRIBE", "DESC", "WITH", "VALUES", "TABLE":
+		return true
+	}
+	return false
+}
+
+// ReplayWorkload runs a captured workload against this server at the
+// original pace or faster, and compares the latencies with the captured
+// ones. Each session's queries run in order on one connection, so
+// transactions and session settings hold. Cancelling ctx stops the replay
+// and returns what ran so far.
+func (c *Connection) ReplayWorkload(ctx context.Context, w *Workload, opts ReplayOptions) (*ReplayReport, error) {
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = DefaultReplayConcurrency
+	}
+	postgres := isPostgresType(c.Config.Type)
+
+	// PostgreSQL can't switch databases on a connection
+	current := c.Config.Database
+	if postgres && current == "" {
+		if err := c.DB.QueryRowContext(ctx, "SELECT current_database()").Scan(&current); err != nil {
+			return nil, err
+		}
+	}
+
+	type job struct {
+		query    *CapturedQuery
+		database string
+		due      time.Time
+	}
+	report := &ReplayReport{Source: w.Source}
+	var plan []job
+	for i := range w.Queries {
+		q := &w.Queries[i]
+		database := q.Database
+		if opts.Database != "" {
+			database = opts.Database
+		}
+		if (opts.ReadOnly && !readOnlyStatement(q.Query)) ||
+			(postgres && database != "" && database != current) {
+			report.Skipped++
+			continue
+		}
+		plan = append(plan, job{query: q, database: database})
+	}
+
+	// One connection per worker, so a session's queries share one
+	conns := make([]*sql.Conn, workers)
+	defer func() {
+		for _, conn := range conns {
+			if conn != nil {
+				conn.Close()
+			}
+		}
+	}()
+	for i := range conns {
+		conn, err := c.DB.Conn(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open replay connections: %w", err)
+		}
+		conns[i] = conn
+	}
+
+	type outcome struct {
+		query   *CapturedQuery
+		latency time.Duration
+		lag     time.Duration
+		err     error
+	}
+	queues := make([]chan job, workers)
+	outcomes := make([][]outcome, workers)
+	var done atomic.Int64
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan job, 256)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			conn := conns[i]
+			database := ""
+			for j := range queues[i] {
+				if ctx.Err() != nil {
+					continue
+				}
+				o := outcome{query: j.query, lag: max(time.Since(j.due), 0)}
+				if !postgres && j.database != "" && j.database != database {
+					if _, err := conn.ExecContext(ctx, c.Driver.UseDatabaseStatement(j.database)); err != nil {
+						o.err = err
+						outcomes[i] = append(outcomes[i], o)
+						continue
+					}
+					database = j.database
+				}
+
+				began := time.Now()
+				o.err = runReplayQuery(ctx, conn, j.query.Query)
+				o.latency = time.Since(began)
+				if o.err != nil && ctx.Err() != nil {
+					continue
+				}
+				outcomes[i] = append(outcomes[i], o)
+				if opts.OnProgress != nil {
+					opts.OnProgress(int(done.Add(1)), len(plan))
+				}
+			}
+		}(i)
+	}
+
+	// Hand the queries out on the original schedule, scaled by the speed
+	start := time.Now()
+dispatch:
+	for _, j := range plan {
+		j.due = time.Now()
+		if opts.Speed > 0 {
+			j.due = start.Add(time.Duration(j.query.OffsetMs * float64(time.Millisecond) / opts.Speed))
+			if wait := time.Until(j.due); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					break dispatch
+				case <-timer.C:
+				}
+			}
+		}
+		select {
+		case queues[uint64(j.query.Session)%uint64(workers)] <- j:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	for _, queue := range queues {
+		close(queue)
+	}
+	wg.Wait()
+	report.Duration = time.Since(start)
+	report.Interrupted = ctx.Err() != nil
+
+	// Compare by fingerprint
+	type totals struct {
+		stat               ReplayStat
+		original, replayed time.Duration
+		timed              int
+	}
+	byFingerprint := make(map[string]*totals)
+	var all, original, compared []time.Duration
+	for _, list := range outcomes {
+		for _, o := range list {
+			report.MaxLag = max(report.MaxLag, o.lag)
+			fp := FingerprintQuery(o.query.Query)
+			t := byFingerprint[fp]
+			if t == nil {
+				t = &totals{stat: ReplayStat{Fingerprint: fp, Example: o.query.Query}}
+				byFingerprint[fp] = t
+			}
+			t.stat.Calls++
+			if o.err != nil {
+				t.stat.Errors++
+				report.Errors++
+				if len(report.ErrorSample) < maxReplayErrors {
+					report.ErrorSample = append(report.ErrorSample, fmt.Sprintf("%s: %v", truncateSQL(o.query.Query), o.err))
+				}
+				continue
+			}
+			report.Replayed++
+			all = append(all, o.latency)
+			t.replayed += o.latency
+			if o.query.DurationMs > 0 {
+				taken := time.Duration(o.query.DurationMs * float64(time.Millisecond))
+				original = append(original, taken)
+				compared = append(compared, o.latency)
+				t.original += taken
+				t.timed++
+			}
+		}
+	}
+	report.Latency = summarizeLatencies(all)
+	report.Original = summarizeLatencies(original)
+	report.Compared = summarizeLatencies(compared)
+
+	for _, t := range byFingerprint {
+		if ok := t.stat.Calls - t.stat.Errors; ok > 0 {
+			t.stat.Replayed = t.replayed / time.Duration(ok)
+		}
+		if t.timed > 0 {
+			t.stat.Original = t.original / time.Duration(t.timed)
+		}
+		report.Queries = append(report.Queries, t.stat)
+	}
+	sort.Slice(report.Queries, func(i, j int) bool {
+		a, b := report.Queries[i], report.Queries[j]
+		if (a.Original > 0) != (b.Original > 0) {
+			return a.Original > 0
+		}
+		if a.Change() != b.Change() {
+			return a.Change() > b.Change()
+		}
+		return a.Replayed*time.Duration(a.Calls) > b.Replayed*time.Duration(b.Calls)
+	})
+	return report, nil
+}
+
+// runReplayQuery runs a statement and reads all of its rows, so the
+// latency includes sending them
+func runReplayQuery(ctx context.Context, conn *sql.Conn, query string) error {
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	return rows.Close()
+}
+
+// summarizeLatencies computes the mean, percentiles and maximum
+func summarizeLatencies(latencies []time.Duration) LatencySummary {
+	s := LatencySummary{Count: len(latencies)}
+	if len(latencies) == 0 {
+		return s
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+	percentile := func(p float64) time.Duration {
+		i := int(math.Ceil(p*float64(len(latencies)))) - 1
+		return latencies[max(i, 0)]
+	}
+	s.Mean = total / time.Duration(len(latencies))
+	s.P50 = percentile(0.50)
+	s.P95 = percentile(0.95)
+	s.P99 = percentile(0.99)
+	s.Max = latencies[len(latencies)-1]
+	return s
+}
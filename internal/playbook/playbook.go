@@ -0,0 +1,838 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package playbook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"gopkg.in/yaml.v3"
+)
+
+// Notify conditions
+const (
+	WhenSuccess = "success" // Run only if every previous step succeeded (default)
+	WhenFailure = "failure" // Run only after a step has failed
+	WhenAlways  = "always"  // Run regardless of earlier failures
+)
+
+// Playbook failure handling
+const (
+	OnErrorStop     = "stop"     // Skip the remaining steps after a failure (default)
+	OnErrorContinue = "continue" // Run every step, still failing the playbook at the end
+)
+
+var varPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// Playbook is a versionable list of maintenance steps
+type Playbook struct {
+	Name        string            `yaml:"name,omitempty"`
+	Description string            `yaml:"description,omitempty"`
+	Profile     string            `yaml:"profile,omitempty"` // Connection used until the first connect step
+	Vars        map[string]string `yaml:"vars,omitempty"`
+	OnError     string            `yaml:"on_error,omitempty"` // stop or continue
+	Steps       []Step            `yaml:"steps"`
+}
+
+// Step is a single playbook action; exactly one action field must be set
+type Step struct {
+	Name            string       `yaml:"name,omitempty"`
+	ContinueOnError bool         `yaml:"continue_on_error,omitempty"`
+	Connect         *ConnectStep `yaml:"connect,omitempty"`
+	Export          *ExportStep  `yaml:"export,omitempty"`
+	Import          *ImportStep  `yaml:"import,omitempty"`
+	SQL             *SQLStep     `yaml:"sql,omitempty"`
+	Verify          *VerifyStep  `yaml:"verify,omitempty"`
+	Notify          *NotifyStep  `yaml:"notify,omitempty"`
+	Backup          *BackupStep  `yaml:"backup,omitempty"`
+	Clone           *CloneStep   `yaml:"clone,omitempty"`
+	Anonymize       *MaskStep    `yaml:"anonymize,omitempty"`
+	Grant           *GrantStep   `yaml:"grant,omitempty"`
+}
+
+// ConnectStep switches the active connection
+type ConnectStep struct {
+	Profile  string `yaml:"profile,omitempty"`
+	Database string `yaml:"database,omitempty"`
+}
+
+// ExportStep dumps a database to a file
+type ExportStep struct {
+	Database     string   `yaml:"database,omitempty"`
+	Output       string   `yaml:"output"`
+	Tables       []string `yaml:"tables,omitempty"`
+	NoData       bool     `yaml:"no_data,omitempty"`
+	NoCreate     bool     `yaml:"no_create,omitempty"`
+	AddDropTable bool     `yaml:"add_drop_table,omitempty"`
+	Native       bool     `yaml:"native,omitempty"`
+	Mask         string   `yaml:"mask,omitempty"` // Masking config file; the dump is verified for leaks
+}
+
+// ImportStep loads a SQL file into a database
+type ImportStep struct {
+	File       string `yaml:"file"`
+	Database   string `yaml:"database,omitempty"`
+	Create     bool   `yaml:"create,omitempty"`
+	NoFKChecks bool   `yaml:"no_fk_checks,omitempty"`
+	Native     bool   `yaml:"native,omitempty"`
+}
+
+// SQLStep runs a single statement or a SQL script
+type SQLStep struct {
+	Database string   `yaml:"database,omitempty"`
+	Query    string   `yaml:"query,omitempty"`
+	File     string   `yaml:"file,omitempty"`
+	Params   []string `yaml:"params,omitempty"`
+}
+
+// VerifyStep runs a query and checks its result
+type VerifyStep struct {
+	Database string   `yaml:"database,omitempty"`
+	Query    string   `yaml:"query"`
+	Params   []string `yaml:"params,omitempty"`
+	Rows     *int     `yaml:"rows,omitempty"`     // Exact row count
+	MinRows  *int     `yaml:"min_rows,omitempty"` // Minimum row count
+	Equals   *string  `yaml:"equals,omitempty"`   // Expected value of the first cell
+	Min      *float64 `yaml:"min,omitempty"`      // Minimum numeric value of the first cell
+	Max      *float64 `yaml:"max,omitempty"`      // Maximum numeric value of the first cell
+	Message  string   `yaml:"message,omitempty"`  // Shown when the check fails
+}
+
+// NotifyStep reports progress to the terminal, a webhook or a command
+type NotifyStep struct {
+	Message string `yaml:"message"`
+	When    string `yaml:"when,omitempty"`
+	Webhook string `yaml:"webhook,omitempty"`
+	Command string `yaml:"command,omitempty"`
+}
+
+// BackupStep takes a managed backup, listed by ysm backup list. Later steps
+// can refer to it as {{backup_id}}.
+type BackupStep struct {
+	Databases   []string `yaml:"databases,omitempty"`   // Default: all databases
+	Compression string   `yaml:"compression,omitempty"` // gzip, xz or zstd
+	Description string   `yaml:"description,omitempty"`
+}
+
+// CloneStep copies a database on the same server
+type CloneStep struct {
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+	NoData bool   `yaml:"no_data,omitempty"`
+	Drop   bool   `yaml:"drop,omitempty"` // Drop the target first
+}
+
+// MaskStep masks a database's columns in place with a masking config
+type MaskStep struct {
+	Database string `yaml:"database,omitempty"`
+	Mask     string `yaml:"mask"`
+}
+
+// GrantStep grants privileges, or a permission template, to a user
+type GrantStep struct {
+	User       string   `yaml:"user"`
+	Host       string   `yaml:"host,omitempty"` // MariaDB only (default: localhost)
+	Database   string   `yaml:"database,omitempty"`
+	Table      string   `yaml:"table,omitempty"`
+	Privileges []string `yaml:"privileges,omitempty"`
+	Template   string   `yaml:"template,omitempty"` // read-only, read-write or admin
+}
+
+// Load reads and validates a playbook file
+func Load(path string) (*Playbook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read playbook: %w", err)
+	}
+
+	var pb Playbook
+	if err := yaml.Unmarshal(data, &pb); err != nil {
+		return nil, fmt.Errorf("failed to parse playbook: %w", err)
+	}
+
+	if err := pb.Validate(); err != nil {
+		return nil, err
+	}
+	return &pb, nil
+}
+
+// Validate checks that every step is well-formed
+func (pb *Playbook) Validate() error {
+	if len(pb.Steps) == 0 {
+		return fmt.Errorf("playbook has no steps")
+	}
+	switch pb.OnError {
+	case "", OnErrorStop, OnErrorContinue:
+	default:
+		return fmt.Errorf("unknown on_error '%s' (use: stop, continue)", pb.OnError)
+	}
+
+	for i, step := range pb.Steps {
+		if err := step.validate(); err != nil {
+			return fmt.Errorf("step %d (%s): %w", i+1, step.Label(), err)
+		}
+	}
+	return nil
+}
+
+func (s Step) validate() error {
+	actions := 0
+	for _, set := range []bool{s.Connect != nil, s.Export != nil, s.Import != nil,
+		s.SQL != nil, s.Verify != nil, s.Notify != nil,
+		s.Backup != nil, s.Clone != nil, s.Anonymize != nil, s.Grant != nil} {
+		if set {
+			actions++
+		}
+	}
+	if actions != 1 {
+		return fmt.Errorf("exactly one of connect, export, import, sql, verify, notify, backup, clone, anonymize or grant is required")
+	}
+
+	switch {
+	case s.Export != nil && s.Export.Output == "":
+		return fmt.Errorf("export requires an output file")
+	case s.Import != nil && s.Import.File == "":
+		return fmt.Errorf("import requires a file")
+	case s.SQL != nil && (s.SQL.Query == "") == (s.SQL.File == ""):
+		return fmt.Errorf("sql requires either query or file")
+	case s.SQL != nil && s.SQL.File != "" && len(s.SQL.Params) > 0:
+		return fmt.Errorf("params are only supported with query")
+	case s.Verify != nil && s.Verify.Query == "":
+		return fmt.Errorf("verify requires a query")
+	case s.Verify != nil && s.Verify.Rows == nil && s.Verify.MinRows == nil &&
+		s.Verify.Equals == nil && s.Verify.Min == nil && s.Verify.Max == nil:
+		return fmt.Errorf("verify requires at least one of rows, min_rows, equals, min or max")
+	case s.Notify != nil && s.Notify.Message == "":
+		return fmt.Errorf("notify requires a message")
+	case s.Clone != nil && (s.Clone.Source == "" || s.Clone.Target == ""):
+		return fmt.Errorf("clone requires a source and a target")
+	case s.Anonymize != nil && s.Anonymize.Mask == "":
+		return fmt.Errorf("anonymize requires a mask file")
+	case s.Grant != nil && s.Grant.User == "":
+		return fmt.Errorf("grant requires a user")
+	case s.Grant != nil && (len(s.Grant.Privileges) == 0) == (s.Grant.Template == ""):
+		return fmt.Errorf("grant requires either privileges or a template")
+	}
+
+	if s.Backup != nil {
+		if _, err := compressionType(s.Backup.Compression); err != nil {
+			return err
+		}
+	}
+
+	if s.Notify != nil {
+		switch s.Notify.When {
+		case "", WhenSuccess, WhenFailure, WhenAlways:
+		default:
+			return fmt.Errorf("unknown notify condition '%s'", s.Notify.When)
+		}
+	}
+	return nil
+}
+
+// Kind returns the action name of the step
+func (s Step) Kind() string {
+	switch {
+	case s.Connect != nil:
+		return "connect"
+	case s.Export != nil:
+		return "export"
+	case s.Import != nil:
+		return "import"
+	case s.SQL != nil:
+		return "sql"
+	case s.Verify != nil:
+		return "verify"
+	case s.Notify != nil:
+		return "notify"
+	case s.Backup != nil:
+		return "backup"
+	case s.Clone != nil:
+		return "clone"
+	case s.Anonymize != nil:
+		return "anonymize"
+	case s.Grant != nil:
+		return "grant"
+	}
+	return "unknown"
+}
+
+// Label returns the step name, falling back to its action
+func (s Step) Label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Kind()
+}
+
+// StepResult records the outcome of one step
+type StepResult struct {
+	Step     Step
+	Skipped  bool
+	Err      error
+	Detail   string
+	Duration time.Duration
+}
+
+// Runner executes playbooks against live connections
+type Runner struct {
+	// Connect opens a connection for a profile ("" = default connection)
+	Connect func(profile string) (*db.Connection, error)
+	Out     io.Writer
+	Vars    map[string]string // Overrides playbook vars
+	DryRun  bool
+
+	conn    *db.Connection
+	vars    map[string]string
+	pbName  string
+	started time.Time
+}
+
+// Run executes every step in order. Steps after a failure are skipped,
+// except notify steps with when: failure or when: always, unless the
+// playbook sets on_error: continue; the first failure is returned either way.
+func (r *Runner) Run(pb *Playbook) ([]StepResult, error) {
+	if r.Out == nil {
+		r.Out = os.Stdout
+	}
+	r.started = time.Now()
+	r.pbName = pb.Name
+	r.vars = builtinVars(r.started)
+	for k, v := range pb.Vars {
+		r.vars[k] = v
+	}
+	for k, v := range r.Vars {
+		r.vars[k] = v
+	}
+	defer r.close()
+
+	var results []StepResult
+	var firstErr error
+	profileLoaded := false
+
+	for i, step := range pb.Steps {
+		res := StepResult{Step: step}
+		fmt.Fprintf(r.Out, "[%d/%d] %s\n", i+1, len(pb.Steps), step.Label())
+
+		when := WhenSuccess
+		if step.Notify != nil && step.Notify.When != "" {
+			when = step.Notify.When
+		}
+		// With on_error: continue only notify steps look at earlier failures
+		skipAfterFailure := step.Notify != nil || pb.OnError != OnErrorContinue
+		if (firstErr != nil && when == WhenSuccess && skipAfterFailure) || (firstErr == nil && when == WhenFailure) {
+			res.Skipped = true
+			fmt.Fprintln(r.Out, "      skipped")
+			results = append(results, res)
+			continue
+		}
+
+		if r.DryRun {
+			fmt.Fprintf(r.Out, "      %s\n", r.describe(step))
+			results = append(results, res)
+			continue
+		}
+
+		// Open the playbook's default connection lazily for the first database step
+		if !profileLoaded && step.Connect == nil && step.Notify == nil {
+			profileLoaded = true
+			if r.conn == nil {
+				if err := r.connect(pb.Profile, ""); err != nil {
+					res.Err = err
+				}
+			}
+		}
+		if step.Connect != nil {
+			profileLoaded = true
+		} else if res.Err == nil && step.Notify == nil && r.conn == nil {
+			// An earlier connection failed and the playbook carried on
+			res.Err = fmt.Errorf("not connected")
+		}
+
+		start := time.Now()
+		if res.Err == nil {
+			res.Detail, res.Err = r.runStep(step, firstErr)
+		}
+		res.Duration = time.Since(start)
+
+		if res.Err != nil {
+			fmt.Fprintf(r.Out, "      failed: %v\n", res.Err)
+			if !step.ContinueOnError && firstErr == nil {
+				firstErr = fmt.Errorf("step %d (%s) failed: %w", i+1, step.Label(), res.Err)
+			}
+		} else {
+			if res.Detail != "" {
+				fmt.Fprintf(r.Out, "      %s\n", res.Detail)
+			}
+			fmt.Fprintf(r.Out, "      ok (%s)\n", res.Duration.Round(time.Millisecond))
+		}
+		results = append(results, res)
+	}
+
+	return results, firstErr
+}
+
+func (r *Runner) close() {
+	if r.conn != nil {
+		r.conn.Close()
+		r.conn = nil
+	}
+}
+
+func (r *Runner) connect(profile, database string) error {
+	if r.Connect == nil {
+		return fmt.Errorf("no connection available")
+	}
+	r.close()
+	conn, err := r.Connect(r.expand(profile))
+	if err != nil {
+		return err
+	}
+	r.conn = conn
+	return r.useDatabase(database)
+}
+
+func (r *Runner) useDatabase(name string) error {
+	name = r.expand(name)
+	if name == "" || name == r.conn.Config.Database {
+		return nil
+	}
+	return r.conn.UseDatabase(name)
+}
+
+func (r *Runner) runStep(step Step, failure error) (string, error) {
+	switch {
+	case step.Connect != nil:
+		if err := r.connect(step.Connect.Profile, step.Connect.Database); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("connected to %s:%d", r.conn.Config.Host, r.conn.Config.Port), nil
+	case step.Export != nil:
+		return r.runExport(step.Export)
+	case step.Import != nil:
+		return r.runImport(step.Import)
+	case step.SQL != nil:
+		return r.runSQL(step.SQL)
+	case step.Verify != nil:
+		return r.runVerify(step.Verify)
+	case step.Notify != nil:
+		return "", r.runNotify(step.Notify, failure)
+	case step.Backup != nil:
+		return r.runBackup(step.Backup)
+	case step.Clone != nil:
+		return r.runClone(step.Clone)
+	case step.Anonymize != nil:
+		return r.runAnonymize(step.Anonymize)
+	case step.Grant != nil:
+		return r.runGrant(step.Grant)
+	}
+	return "", fmt.Errorf("unknown step")
+}
+
+func (r *Runner) runExport(s *ExportStep) (string, error) {
+	database := r.expand(s.Database)
+	if database == "" {
+		database = r.conn.Config.Database
+	}
+	if database == "" {
+		return "", fmt.Errorf("no database selected for export")
+	}
+
+	var masking *db.MaskingConfig
+	if s.Mask != "" {
+		mc, err := db.LoadMaskingConfig(r.expand(s.Mask))
+		if err != nil {
+			return "", err
+		}
+		masking = mc
+	}
+
+	output := r.expand(s.Output)
+	stats, err := r.conn.ExportSQLWithStats(db.ExportOptions{
+		FilePath:      output,
+		Database:      database,
+		Tables:        s.Tables,
+		NoData:        s.NoData,
+		NoCreate:      s.NoCreate,
+		AddDropTable:  s.AddDropTable,
+		UseNativeTool: s.Native,
+		Masking:       masking,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if masking != nil {
+		report, err := db.VerifyDumpLeakage(output, masking)
+		if err != nil {
+			return "", err
+		}
+		if report.Found() {
+			os.RemoveAll(output)
+			return "", fmt.Errorf("masked export leaked personal data (first: %s on line %d), dump removed",
+				report.Leaks[0].Pattern, report.Leaks[0].Line)
+		}
+	}
+	return fmt.Sprintf("exported %d tables, %d rows to %s", stats.TablesExported, stats.RowsExported, stats.OutputFile), nil
+}
+
+func (r *Runner) runBackup(s *BackupStep) (string, error) {
+	compression, _ := compressionType(s.Compression)
+	metadata, err := r.conn.CreateBackup(db.BackupOptions{
+		Databases:   r.expandAll(s.Databases),
+		Compression: compression,
+		Description: r.expand(s.Description),
+		OnProgress: func(database string, dbNum, totalDBs int, _, _ int64) {
+			r.progress(database, dbNum, totalDBs)
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	r.vars["backup_id"] = metadata.ID
+	return fmt.Sprintf("backup %s: %d database(s), %s", metadata.ID, len(metadata.Databases), db.FormatSize(metadata.TotalSize)), nil
+}
+
+func (r *Runner) runClone(s *CloneStep) (string, error) {
+	source, target := r.expand(s.Source), r.expand(s.Target)
+	err := r.conn.CloneDatabase(db.CloneOptions{
+		SourceDB:     source,
+		TargetDB:     target,
+		IncludeData:  !s.NoData,
+		DropIfExists: s.Drop,
+		OnProgress:   r.progress,
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("cloned %s to %s", source, target), nil
+}
+
+func (r *Runner) runAnonymize(s *MaskStep) (string, error) {
+	masking, err := db.LoadMaskingConfig(r.expand(s.Mask))
+	if err != nil {
+		return "", err
+	}
+	if err := r.useDatabase(s.Database); err != nil {
+		return "", err
+	}
+	if r.conn.Config.Database == "" {
+		return "", fmt.Errorf("no database selected to anonymize")
+	}
+
+	stats, err := r.conn.MaskTables(masking, r.progress)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("masked %d row(s) in %d table(s) of %s", stats.Rows, stats.Tables, r.conn.Config.Database), nil
+}
+
+func (r *Runner) runGrant(s *GrantStep) (string, error) {
+	user, host, database := r.expand(s.User), r.expand(s.Host), r.expand(s.Database)
+	if s.Template != "" {
+		if err := r.conn.ApplyPermissionTemplate(user, host, r.expand(s.Template), database); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("granted %s to %s", r.expand(s.Template), user), nil
+	}
+
+	privileges := r.expandAll(s.Privileges)
+	if err := r.conn.GrantPrivileges(user, host, privileges, database, r.expand(s.Table)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("granted %s to %s", strings.Join(privileges, ", "), user), nil
+}
+
+// progress reports the item a long step is working on
+func (r *Runner) progress(item string, n, total int) {
+	fmt.Fprintf(r.Out, "      %d/%d %s\n", n, total, item)
+}
+
+func (r *Runner) runImport(s *ImportStep) (string, error) {
+	database := r.expand(s.Database)
+	if database == "" {
+		database = r.conn.Config.Database
+	}
+
+	stats, err := r.conn.ImportSQLWithStats(db.ImportOptions{
+		FilePath:           r.expand(s.File),
+		Database:           database,
+		CreateDB:           s.Create,
+		DisableForeignKeys: s.NoFKChecks,
+		UseNativeTool:      s.Native,
+	})
+	if err != nil {
+		return "", err
+	}
+	if stats == nil {
+		return "imported " + r.expand(s.File), nil
+	}
+	return fmt.Sprintf("executed %d statements", stats.StatementsExecuted), nil
+}
+
+func (r *Runner) runSQL(s *SQLStep) (string, error) {
+	if err := r.useDatabase(s.Database); err != nil {
+		return "", err
+	}
+
+	// Scripts go through the importer so statements are split properly
+	if s.File != "" {
+		stats, err := r.conn.ImportSQLWithStats(db.ImportOptions{
+			FilePath: r.expand(s.File),
+			Database: r.conn.Config.Database,
+		})
+		if err != nil {
+			return "", err
+		}
+		if stats == nil {
+			return "", nil
+		}
+		return fmt.Sprintf("executed %d statements", stats.StatementsExecuted), nil
+	}
+
+	query := r.expand(s.Query)
+	if isRowQuery(query) {
+		result, err := r.conn.Query(query, r.params(s.Params)...)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d row(s) returned", len(result.Rows)), nil
+	}
+
+	affected, err := r.conn.Execute(query, r.params(s.Params)...)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d row(s) affected", affected), nil
+}
+
+func (r *Runner) runVerify(s *VerifyStep) (string, error) {
+	if err := r.useDatabase(s.Database); err != nil {
+		return "", err
+	}
+
+	result, err := r.conn.Query(r.expand(s.Query), r.params(s.Params)...)
+	if err != nil {
+		return "", err
+	}
+
+	if err := checkResult(s, result); err != nil {
+		if s.Message != "" {
+			return "", fmt.Errorf("%s: %w", r.expand(s.Message), err)
+		}
+		return "", err
+	}
+	return fmt.Sprintf("%d row(s) checked", len(result.Rows)), nil
+}
+
+func checkResult(s *VerifyStep, result *db.QueryResult) error {
+	rows := len(result.Rows)
+	if s.Rows != nil && rows != *s.Rows {
+		return fmt.Errorf("expected %d row(s), got %d", *s.Rows, rows)
+	}
+	if s.MinRows != nil && rows < *s.MinRows {
+		return fmt.Errorf("expected at least %d row(s), got %d", *s.MinRows, rows)
+	}
+
+	if s.Equals == nil && s.Min == nil && s.Max == nil {
+		return nil
+	}
+	if rows == 0 || len(result.Rows[0]) == 0 {
+		return fmt.Errorf("query returned no value to check")
+	}
+	value := result.Rows[0][0]
+
+	if s.Equals != nil && value != *s.Equals {
+		return fmt.Errorf("expected '%s', got '%s'", *s.Equals, value)
+	}
+	if s.Min == nil && s.Max == nil {
+		return nil
+	}
+
+	num, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return fmt.Errorf("value '%s' is not numeric", value)
+	}
+	if s.Min != nil && num < *s.Min {
+		return fmt.Errorf("value %v is below minimum %v", num, *s.Min)
+	}
+	if s.Max != nil && num > *s.Max {
+		return fmt.Errorf("value %v is above maximum %v", num, *s.Max)
+	}
+	return nil
+}
+
+func (r *Runner) runNotify(s *NotifyStep, failure error) error {
+	message := r.expand(s.Message)
+	status, reason := "success", ""
+	if failure != nil {
+		status, reason = "failure", failure.Error()
+	}
+	message = strings.ReplaceAll(message, "{{error}}", reason)
+
+	fmt.Fprintf(r.Out, "      %s\n", message)
+
+	if s.Webhook != "" {
+		payload, _ := json.Marshal(map[string]string{
+			"playbook": r.pbName,
+			"status":   status,
+			"message":  message,
+		})
+		client := &http.Client{Timeout: 30 * time.Second}
+		resp, err := client.Post(r.expand(s.Webhook), "application/json", bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("webhook failed: %w", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("webhook returned %s", resp.Status)
+		}
+	}
+
+	if s.Command != "" {
+		cmd := exec.Command("sh", "-c", r.expand(s.Command))
+		cmd.Env = append(os.Environ(),
+			"YSM_PLAYBOOK="+r.pbName,
+			"YSM_STATUS="+status,
+			"YSM_MESSAGE="+message,
+		)
+		cmd.Stdout = r.Out
+		cmd.Stderr = r.Out
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("notify command failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// describe summarizes what a step would do, for dry runs
+func (r *Runner) describe(step Step) string {
+	switch {
+	case step.Connect != nil:
+		p := r.expand(step.Connect.Profile)
+		if p == "" {
+			p = "(default)"
+		}
+		return fmt.Sprintf("connect to profile %s %s", p, r.expand(step.Connect.Database))
+	case step.Export != nil:
+		return fmt.Sprintf("export %s to %s", r.expand(step.Export.Database), r.expand(step.Export.Output))
+	case step.Import != nil:
+		return fmt.Sprintf("import %s into %s", r.expand(step.Import.File), r.expand(step.Import.Database))
+	case step.SQL != nil:
+		if step.SQL.File != "" {
+			return "run script " + r.expand(step.SQL.File)
+		}
+		return "run " + r.expand(step.SQL.Query)
+	case step.Verify != nil:
+		return "verify " + r.expand(step.Verify.Query)
+	case step.Notify != nil:
+		return "notify: " + r.expand(step.Notify.Message)
+	case step.Backup != nil:
+		databases := strings.Join(r.expandAll(step.Backup.Databases), ", ")
+		if databases == "" {
+			databases = "all databases"
+		}
+		return "back up " + databases
+	case step.Clone != nil:
+		return fmt.Sprintf("clone %s to %s", r.expand(step.Clone.Source), r.expand(step.Clone.Target))
+	case step.Anonymize != nil:
+		return fmt.Sprintf("anonymize %s with %s", r.expand(step.Anonymize.Database), r.expand(step.Anonymize.Mask))
+	case step.Grant != nil:
+		what := r.expand(step.Grant.Template)
+		if what == "" {
+			what = strings.Join(r.expandAll(step.Grant.Privileges), ", ")
+		}
+		return fmt.Sprintf("grant %s to %s", what, r.expand(step.Grant.User))
+	}
+	return ""
+}
+
+func (r *Runner) params(values []string) []interface{} {
+	return db.BindArgs(r.expandAll(values))
+}
+
+func (r *Runner) expandAll(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	expanded := make([]string, len(values))
+	for i, v := range values {
+		expanded[i] = r.expand(v)
+	}
+	return expanded
+}
+
+// expand replaces {{name}} references with playbook variables.
+// Unknown names are left untouched.
+func (r *Runner) expand(s string) string {
+	return varPattern.ReplaceAllStringFunc(s, func(m string) string {
+		name := varPattern.FindStringSubmatch(m)[1]
+		if v, ok := r.vars[name]; ok {
+			return v
+		}
+		if strings.HasPrefix(name, "env.") {
+			return os.Getenv(strings.TrimPrefix(name, "env."))
+		}
+		return m
+	})
+}
+
+// builtinVars returns the variables every playbook can reference
+func builtinVars(now time.Time) map[string]string {
+	return map[string]string{
+		"date":      now.Format("2006-01-02"),
+		"time":      now.Format("15-04-05"),
+		"timestamp": now.Format("20060102-150405"),
+	}
+}
+
+// compressionType maps a backup step's compression name to its type
+func compressionType(name string) (db.CompressionType, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return db.CompressionNone, nil
+	case "gzip", "gz":
+		return db.CompressionGzip, nil
+	case "xz":
+		return db.CompressionXZ, nil
+	case "zstd", "zst":
+		return db.CompressionZstd, nil
+	}
+	return db.CompressionNone, fmt.Errorf("unknown backup compression '%s' (use: gzip, xz, zstd)", name)
+}
+
+func isRowQuery(query string) bool {
+	upper := strings.ToUpper(strings.TrimSpace(query))
+	for _, prefix := range []string{"SELECT", "SHOW", "DESCRIBE", "DESC ", "EXPLAIN", "WITH", "VALUES"} {
+		if strings.HasPrefix(upper, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,470 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/buffer"
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+	"github.com/blubskye/yandere_sql_manager/internal/scratch"
+	"gopkg.in/yaml.v3"
+)
+
+// Plugins are external executables that talk to YSM over a small JSON
+// protocol, so they can be written in any language and installed without
+// rebuilding YSM.
+//
+// Lifecycle:
+//  1. Discovery  - every directory under ~/.config/ysm/plugins that contains a
+//     plugin.yaml manifest is loaded when a command or view needs plugins.
+//  2. Registration - the manifest declares which extension points the plugin
+//     provides: TUI views, export formats and/or a post-backup processor.
+//  3. Invocation - when an extension point fires, YSM starts the plugin's
+//     executable with the hook name as its only argument, writes a Request
+//     as JSON to stdin and reads a Response as JSON from stdout. Anything
+//     written to stderr is forwarded to the YSM debug log.
+//  4. Teardown   - the process is expected to exit after answering. Each
+//     invocation is a fresh process and is killed once the manifest timeout
+//     (default 60s) expires. A failed post-backup processor runs again
+//     as often as its manifest's retries allow.
+
+// Hook names passed to the plugin executable
+const (
+	HookView       = "view"
+	HookExport     = "export"
+	HookPostBackup = "post_backup"
+)
+
+// DefaultTimeout is used when a manifest does not specify one
+const DefaultTimeout = 60 * time.Second
+
+// RetryDelay is the wait before a post-backup processor's first retry; it
+// doubles with every further retry
+const RetryDelay = 5 * time.Second
+
+// Manifest describes a plugin and the extension points it provides
+type Manifest struct {
+	Name          string         `yaml:"name"`
+	Version       string         `yaml:"version,omitempty"`
+	Description   string         `yaml:"description,omitempty"`
+	Executable    string         `yaml:"executable"`        // Relative to the plugin directory or absolute
+	Timeout       string         `yaml:"timeout,omitempty"` // Go duration, e.g. "30s"
+	Views         []ViewSpec     `yaml:"views,omitempty"`
+	ExportFormats []ExportFormat `yaml:"export_formats,omitempty"`
+	PostBackup    bool           `yaml:"post_backup,omitempty"`
+	Retries       int            `yaml:"retries,omitempty"` // Post-backup: further attempts after a failed one
+}
+
+// ViewSpec declares a read-only TUI view rendered from plugin output
+type ViewSpec struct {
+	Name  string `yaml:"name"`
+	Title string `yaml:"title,omitempty"`
+}
+
+// ExportFormat declares an export format produced by converting a SQL dump
+type ExportFormat struct {
+	Name        string `yaml:"name"`
+	Extension   string `yaml:"extension,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// ConnectionInfo is the connection passed to plugins so they can query the server themselves
+type ConnectionInfo struct {
+	Type     string `json:"type"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	User     string `json:"user"`
+	Password string `json:"password,omitempty"`
+	Socket   string `json:"socket,omitempty"`
+	Database string `json:"database,omitempty"`
+}
+
+// Request is written as JSON to the plugin's stdin
+type Request struct {
+	Hook       string             `json:"hook"`
+	Connection *ConnectionInfo    `json:"connection,omitempty"`
+	Database   string             `json:"database,omitempty"`
+	View       string             `json:"view,omitempty"`        // HookView: which declared view to render
+	Format     string             `json:"format,omitempty"`      // HookExport: which declared format to produce
+	InputFile  string             `json:"input_file,omitempty"`  // HookExport: plain SQL dump written by YSM
+	OutputFile string             `json:"output_file,omitempty"` // HookExport: where the plugin must write its result
+	Backup     *db.BackupMetadata `json:"backup,omitempty"`      // HookPostBackup: the backup that was just created
+	BackupDir  string             `json:"backup_dir,omitempty"`  // HookPostBackup: directory holding the backup files
+	Attempt    int                `json:"attempt,omitempty"`     // HookPostBackup: 1 for the first attempt, then counting retries
+	ResumeFile string             `json:"resume_file,omitempty"` // HookPostBackup: where to keep upload progress, kept until an attempt succeeds
+}
+
+// Response is read as JSON from the plugin's stdout
+type Response struct {
+	Title   string     `json:"title,omitempty"`
+	Content string     `json:"content,omitempty"` // Free-form text (views)
+	Columns []string   `json:"columns,omitempty"` // Tabular output (views)
+	Rows    [][]string `json:"rows,omitempty"`
+	Message string     `json:"message,omitempty"` // Short status line shown to the user
+	Error   string     `json:"error,omitempty"`   // Non-empty marks the invocation as failed
+}
+
+// Plugin is a discovered plugin
+type Plugin struct {
+	Manifest Manifest
+	Dir      string
+}
+
+// Registry holds all discovered plugins
+type Registry struct {
+	Plugins []*Plugin
+}
+
+// ViewRef identifies a view provided by a plugin
+type ViewRef struct {
+	Plugin *Plugin
+	View   ViewSpec
+}
+
+// PluginsDir returns the directory plugins are discovered from
+func PluginsDir() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "plugins"), nil
+}
+
+// Discover loads every plugin manifest from the plugins directory.
+// Invalid plugins are skipped and logged rather than failing discovery.
+func Discover() (*Registry, error) {
+	dir, err := PluginsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Registry{}, nil
+		}
+		return nil, fmt.Errorf("failed to read plugins directory: %w", err)
+	}
+
+	reg := &Registry{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		p, err := Load(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			logging.Warn("Skipping plugin %s: %v", entry.Name(), err)
+			continue
+		}
+		reg.Plugins = append(reg.Plugins, p)
+	}
+
+	sort.Slice(reg.Plugins, func(i, j int) bool {
+		return reg.Plugins[i].Manifest.Name < reg.Plugins[j].Manifest.Name
+	})
+
+	return reg, nil
+}
+
+// Load reads a single plugin from its directory
+func Load(dir string) (*Plugin, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "plugin.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if m.Name == "" {
+		return nil, fmt.Errorf("manifest is missing a name")
+	}
+	if m.Executable == "" {
+		return nil, fmt.Errorf("manifest is missing an executable")
+	}
+	if m.Retries < 0 {
+		return nil, fmt.Errorf("retries can't be negative")
+	}
+	if m.Timeout != "" {
+		if _, err := time.ParseDuration(m.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid timeout %q: %w", m.Timeout, err)
+		}
+	}
+
+	return &Plugin{Manifest: m, Dir: dir}, nil
+}
+
+// Get returns a plugin by name
+func (r *Registry) Get(name string) (*Plugin, error) {
+	for _, p := range r.Plugins {
+		if p.Manifest.Name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("plugin '%s' not found", name)
+}
+
+// Views returns every view provided by the registered plugins
+func (r *Registry) Views() []ViewRef {
+	var refs []ViewRef
+	for _, p := range r.Plugins {
+		for _, v := range p.Manifest.Views {
+			refs = append(refs, ViewRef{Plugin: p, View: v})
+		}
+	}
+	return refs
+}
+
+// ExportFormat finds the plugin providing an export format
+func (r *Registry) ExportFormat(name string) (*Plugin, *ExportFormat, bool) {
+	for _, p := range r.Plugins {
+		for i := range p.Manifest.ExportFormats {
+			if strings.EqualFold(p.Manifest.ExportFormats[i].Name, name) {
+				return p, &p.Manifest.ExportFormats[i], true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// ExecutablePath returns the absolute path of the plugin executable
+func (p *Plugin) ExecutablePath() string {
+	if filepath.IsAbs(p.Manifest.Executable) {
+		return p.Manifest.Executable
+	}
+	return filepath.Join(p.Dir, p.Manifest.Executable)
+}
+
+func (p *Plugin) timeout() time.Duration {
+	if d, err := time.ParseDuration(p.Manifest.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultTimeout
+}
+
+// Invoke runs the plugin for a hook and returns its response
+func (p *Plugin) Invoke(req Request) (*Response, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout())
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.ExecutablePath(), req.Hook)
+	cmd.Dir = p.Dir
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	logging.Debug("Running plugin %s (hook: %s)", p.Manifest.Name, req.Hook)
+	runErr := cmd.Run()
+	if stderr.Len() > 0 {
+		logging.Debug("Plugin %s stderr: %s", p.Manifest.Name, strings.TrimSpace(stderr.String()))
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("plugin %s timed out after %s", p.Manifest.Name, p.timeout())
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("plugin %s failed: %w", p.Manifest.Name, runErr)
+	}
+
+	var resp Response
+	if stdout.Len() > 0 {
+		if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+			return nil, fmt.Errorf("plugin %s returned invalid response: %w", p.Manifest.Name, err)
+		}
+	}
+	if resp.Error != "" {
+		return &resp, fmt.Errorf("plugin %s: %s", p.Manifest.Name, resp.Error)
+	}
+
+	return &resp, nil
+}
+
+// NewConnectionInfo builds the connection details handed to plugins
+func NewConnectionInfo(conn *db.Connection) *ConnectionInfo {
+	if conn == nil {
+		return nil
+	}
+	return &ConnectionInfo{
+		Type:     string(conn.Config.Type),
+		Host:     conn.Config.Host,
+		Port:     conn.Config.Port,
+		User:     conn.Config.User,
+		Password: conn.Config.Password,
+		Socket:   conn.Config.Socket,
+		Database: conn.Config.Database,
+	}
+}
+
+// RenderView asks a plugin to render one of its views
+func (r *Registry) RenderView(conn *db.Connection, ref ViewRef, database string) (*Response, error) {
+	return ref.Plugin.Invoke(Request{
+		Hook:       HookView,
+		Connection: NewConnectionInfo(conn),
+		Database:   database,
+		View:       ref.View.Name,
+	})
+}
+
+// Export writes a plain SQL dump to a temporary file and hands it to the
+// plugin providing the requested format, which writes opts.FilePath.
+func (r *Registry) Export(conn *db.Connection, format string, opts db.ExportOptions) (*db.ExportStats, error) {
+	p, f, ok := r.ExportFormat(format)
+	if !ok {
+		return nil, fmt.Errorf("no plugin provides export format '%s'", format)
+	}
+
+	tmp, err := scratch.CreateTemp("plugin-export-*.sql", conn.ExportSizeEstimate(opts))
+	if err != nil {
+		return nil, err
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath)
+
+	sqlOpts := opts
+	sqlOpts.FilePath = tmpPath
+	sqlOpts.Compression = db.CompressionNone
+	sqlOpts.Format = db.DumpFormatSQL
+	sqlOpts.UseNativeTool = false
+	sqlOpts.SplitSize = 0 // The plugin's output is split instead
+
+	stats, err := conn.ExportSQLWithStats(sqlOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.Invoke(Request{
+		Hook:       HookExport,
+		Connection: NewConnectionInfo(conn),
+		Database:   opts.Database,
+		Format:     f.Name,
+		InputFile:  tmpPath,
+		OutputFile: opts.FilePath,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Message != "" {
+		logging.Info("%s: %s", p.Manifest.Name, resp.Message)
+	}
+
+	stats.OutputFile = opts.FilePath
+	if info, err := os.Stat(opts.FilePath); err == nil {
+		stats.BytesWritten = info.Size()
+	}
+	if opts.SplitSize > 0 {
+		split, err := buffer.SplitFile(opts.FilePath, opts.SplitSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split the export: %w", err)
+		}
+		stats.Split = split
+		stats.OutputFile = split.ManifestPath()
+	}
+	return stats, nil
+}
+
+// RunPostBackup runs every post-backup processor for a freshly created backup.
+// outputDir is the directory the backup was written to (empty = default backups dir).
+// Failures are collected so one broken plugin does not stop the others.
+func (r *Registry) RunPostBackup(conn *db.Connection, metadata *db.BackupMetadata, outputDir string) ([]string, []error) {
+	if outputDir == "" {
+		dir, err := db.GetBackupsDir()
+		if err != nil {
+			return nil, []error{err}
+		}
+		outputDir = dir
+	}
+
+	var messages []string
+	var errs []error
+	for _, p := range r.Plugins {
+		if !p.Manifest.PostBackup {
+			continue
+		}
+		resp, err := p.postBackup(Request{
+			Hook:       HookPostBackup,
+			Connection: NewConnectionInfo(conn),
+			Backup:     metadata,
+			BackupDir:  filepath.Join(outputDir, metadata.ID),
+		})
+		if err != nil {
+			logging.Warn("Post-backup plugin failed: %v", err)
+			errs = append(errs, err)
+			continue
+		}
+		if resp.Message != "" {
+			messages = append(messages, fmt.Sprintf("%s: %s", p.Manifest.Name, resp.Message))
+		}
+	}
+	return messages, errs
+}
+
+// postBackup invokes a post-backup processor, retrying up to the manifest's
+// retries. The processor keeps its progress, e.g. an upload ID and the parts
+// sent, in the request's resume file as it goes, so the file survives an
+// attempt that was killed or timed out. It is removed once an attempt
+// succeeds; until then every retry, and every later run for the backup,
+// can continue where the last attempt stopped.
+func (p *Plugin) postBackup(req Request) (*Response, error) {
+	req.ResumeFile = p.resumeFile(req.Backup.ID)
+	if err := os.MkdirAll(filepath.Dir(req.ResumeFile), 0700); err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", p.Manifest.Name, err)
+	}
+
+	delay := RetryDelay
+	for retry := 0; ; retry++ {
+		req.Attempt = retry + 1
+		resp, err := p.Invoke(req)
+		if err == nil {
+			os.Remove(req.ResumeFile)
+			return resp, nil
+		}
+		if retry >= p.Manifest.Retries {
+			return nil, err
+		}
+
+		logging.Warn("Post-backup plugin failed, retrying in %s: %v", delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// resumeFile is where the processor keeps its progress on a backup. It lives
+// in the plugin's directory, not the backup's, so it isn't uploaded with it.
+func (p *Plugin) resumeFile(backupID string) string {
+	return filepath.Join(p.Dir, "resume", backupID)
+}
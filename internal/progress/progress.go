@@ -0,0 +1,385 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package progress
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Unit is what a tracker counts
+type Unit int
+
+const (
+	Items Unit = iota // Steps only, e.g. databases or tables
+	Bytes
+	Rows
+	Percent // Done and total are percentage points
+)
+
+// historySize is the number of throughput samples kept for the sparkline
+const historySize = 30
+
+// historyInterval is how much time each throughput sample covers
+const historyInterval = time.Second
+
+// rateWindow is how far back rates and ETAs look, so they follow the
+// current speed rather than the average since the start
+const rateWindow = 10 * time.Second
+
+// Tracker follows a long-running operation. It is safe to update from the
+// worker goroutine while a UI reads snapshots.
+type Tracker struct {
+	mu      sync.Mutex
+	label   string
+	unit    Unit
+	total   int64 // 0 = unknown
+	done    int64
+	current string
+	step    int
+	steps   int
+	start   time.Time
+
+	// Rows and bytes moved, for trackers counting something else (-1 = not
+	// reported)
+	rows  int64
+	bytes int64
+
+	sampleAt   time.Time
+	sampleDone int64
+	history    []float64 // Units per second, oldest first
+	window     []sample  // Samples within rateWindow, oldest first
+}
+
+// sample is the state of a tracker at one point in time
+type sample struct {
+	at    time.Time
+	done  int64
+	rows  int64
+	bytes int64
+}
+
+// Snapshot is a consistent view of a tracker
+type Snapshot struct {
+	Label    string
+	Unit     Unit
+	Done     int64
+	Total    int64
+	Current  string
+	Step     int
+	Steps    int
+	Fraction float64 // 0..1, -1 when unknown
+	Rate     float64 // Units per second over the recent window
+	Rows     int64   // Rows moved, -1 when not reported
+	Bytes    int64   // Bytes moved, -1 when not reported
+	RowRate  float64 // Rows per second over the recent window
+	ByteRate float64 // Bytes per second over the recent window
+	ETA      time.Duration
+	Elapsed  time.Duration
+	History  []float64
+}
+
+// New creates a tracker. Total may be 0 when unknown.
+func New(label string, unit Unit, total int64) *Tracker {
+	now := time.Now()
+	return &Tracker{label: label, unit: unit, total: total, rows: -1, bytes: -1, start: now, sampleAt: now}
+}
+
+// SetTotal sets the total once it is known
+func (t *Tracker) SetTotal(total int64) {
+	t.mu.Lock()
+	t.total = total
+	t.mu.Unlock()
+}
+
+// Set records the absolute amount done
+func (t *Tracker) Set(done int64) {
+	t.mu.Lock()
+	t.done = done
+	t.sample(time.Now())
+	t.mu.Unlock()
+}
+
+// Add records more work done
+func (t *Tracker) Add(n int64) {
+	t.mu.Lock()
+	t.done += n
+	t.sample(time.Now())
+	t.mu.Unlock()
+}
+
+// SetRows records the rows moved so far, for a rows/s rate when the tracker
+// counts something else
+func (t *Tracker) SetRows(rows int64) {
+	t.mu.Lock()
+	t.rows = rows
+	t.mu.Unlock()
+}
+
+// SetBytes records the bytes moved so far, for a MB/s rate when the tracker
+// counts something else
+func (t *Tracker) SetBytes(bytes int64) {
+	t.mu.Lock()
+	t.bytes = bytes
+	t.mu.Unlock()
+}
+
+// counts returns the rows and bytes moved, -1 for those not reported. A
+// tracker counting rows or bytes reports them itself. Callers hold mu.
+func (t *Tracker) counts() (rows, bytes int64) {
+	rows, bytes = t.rows, t.bytes
+	switch t.unit {
+	case Rows:
+		rows = t.done
+	case Bytes:
+		bytes = t.done
+	}
+	return rows, bytes
+}
+
+// SetCurrent sets the object being worked on, e.g. table 2 of 5
+func (t *Tracker) SetCurrent(name string, step, steps int) {
+	t.mu.Lock()
+	t.current = name
+	t.step = step
+	t.steps = steps
+	t.sample(time.Now())
+	t.mu.Unlock()
+}
+
+// sample closes throughput intervals that have passed, and remembers the
+// state at each for the rate window. Callers hold mu.
+func (t *Tracker) sample(now time.Time) {
+	elapsed := now.Sub(t.sampleAt)
+	if elapsed < historyInterval {
+		return
+	}
+	t.history = append(t.history, float64(t.done-t.sampleDone)/elapsed.Seconds())
+	if len(t.history) > historySize {
+		t.history = t.history[len(t.history)-historySize:]
+	}
+	t.sampleAt = now
+	t.sampleDone = t.done
+
+	rows, bytes := t.counts()
+	t.window = append(t.window, sample{at: now, done: t.done, rows: rows, bytes: bytes})
+	// Keep one sample at or beyond the window's start so it's always covered
+	for len(t.window) > 1 && now.Sub(t.window[1].at) >= rateWindow {
+		t.window = t.window[1:]
+	}
+}
+
+// rates returns units, rows and bytes per second since the oldest sample in
+// the rate window, or since the start before there is one. A stall shows
+// as falling rates rather than the last speed seen. Callers hold mu.
+func (t *Tracker) rates(now time.Time) (rate, rowRate, byteRate float64) {
+	from := sample{at: t.start}
+	if len(t.window) > 0 && now.Sub(t.window[0].at) >= historyInterval {
+		from = t.window[0]
+	}
+	seconds := now.Sub(from.at).Seconds()
+	if seconds <= 0 {
+		return 0, 0, 0
+	}
+	rows, bytes := t.counts()
+	return float64(t.done-from.done) / seconds,
+		float64(rows-max(from.rows, 0)) / seconds,
+		float64(bytes-max(from.bytes, 0)) / seconds
+}
+
+// Snapshot returns the current state
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.sample(now)
+
+	s := Snapshot{
+		Label:    t.label,
+		Unit:     t.unit,
+		Done:     t.done,
+		Total:    t.total,
+		Current:  t.current,
+		Step:     t.step,
+		Steps:    t.steps,
+		Fraction: -1,
+		Elapsed:  now.Sub(t.start),
+		History:  append([]float64(nil), t.history...),
+	}
+
+	switch {
+	case t.total > 0:
+		s.Fraction = math.Min(float64(t.done)/float64(t.total), 1)
+	case t.steps > 0:
+		// Only the step is known; count the current one as half done
+		s.Fraction = math.Min((float64(t.step)-0.5)/float64(t.steps), 1)
+	}
+
+	s.Rows, s.Bytes = t.counts()
+	s.Rate, s.RowRate, s.ByteRate = t.rates(now)
+
+	if s.Fraction > 0 && s.Fraction < 1 {
+		if t.total > 0 && s.Rate > 0 {
+			s.ETA = time.Duration(float64(t.total-t.done) / s.Rate * float64(time.Second))
+		} else {
+			s.ETA = time.Duration(float64(s.Elapsed) * (1 - s.Fraction) / s.Fraction)
+		}
+	}
+	return s
+}
+
+// FormatRate formats the throughput, e.g. "12.3 MB/s, 1.2k rows/s", from
+// the bytes and rows reported. Empty when neither was.
+func (s Snapshot) FormatRate() string {
+	var rates []string
+	if s.Bytes >= 0 {
+		rates = append(rates, formatBytes(s.ByteRate)+"/s")
+	}
+	if s.Rows >= 0 {
+		rates = append(rates, formatCount(s.RowRate)+" rows/s")
+	}
+	return strings.Join(rates, ", ")
+}
+
+// FormatDone formats the amount done, e.g. "1.2 GB / 4.0 GB, 12.3k rows"
+func (s Snapshot) FormatDone() string {
+	var done []string
+	if s.Bytes >= 0 {
+		d := formatBytes(float64(s.Bytes))
+		if s.Unit == Bytes && s.Total > 0 {
+			d += " / " + formatBytes(float64(s.Total))
+		}
+		done = append(done, d)
+	}
+	if s.Rows >= 0 {
+		d := formatCount(float64(s.Rows))
+		if s.Unit == Rows && s.Total > 0 {
+			d += " / " + formatCount(float64(s.Total))
+		}
+		done = append(done, d+" rows")
+	}
+	return strings.Join(done, ", ")
+}
+
+// FormatCurrent formats the current object, e.g. "orders (2/5)"
+func (s Snapshot) FormatCurrent() string {
+	if s.Current == "" {
+		return ""
+	}
+	if s.Steps > 0 {
+		return fmt.Sprintf("%s (%d/%d)", s.Current, s.Step, s.Steps)
+	}
+	return s.Current
+}
+
+// Line renders a single status line for terminals, e.g.
+// "Exporting [████░░░░] 52% 12.3 MB/s ETA 1m20s ▂▃▅▇ orders (2/5)"
+func (s Snapshot) Line(barWidth int) string {
+	parts := []string{s.Label}
+	if s.Fraction >= 0 {
+		parts = append(parts, "["+Bar(s.Fraction, barWidth)+"]", fmt.Sprintf("%3.0f%%", s.Fraction*100))
+	}
+	if done := s.FormatDone(); done != "" {
+		parts = append(parts, done)
+	}
+	if rate := s.FormatRate(); rate != "" {
+		parts = append(parts, rate)
+	}
+	if s.ETA > 0 {
+		parts = append(parts, "ETA "+FormatDuration(s.ETA))
+	}
+	if len(s.History) > 1 {
+		parts = append(parts, Sparkline(s.History, 12))
+	}
+	if current := s.FormatCurrent(); current != "" {
+		parts = append(parts, current)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Bar renders a text progress bar
+func Bar(fraction float64, width int) string {
+	fraction = math.Max(0, math.Min(fraction, 1))
+	filled := int(math.Round(fraction * float64(width)))
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders the last width values as block characters scaled to their maximum
+func Sparkline(values []float64, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	peak := 0.0
+	for _, v := range values {
+		peak = math.Max(peak, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if peak > 0 {
+			i = int(math.Round(v / peak * float64(len(sparkBlocks)-1)))
+		}
+		b.WriteRune(sparkBlocks[i])
+	}
+	return b.String()
+}
+
+// FormatDuration formats an ETA like "45s", "3m20s" or "1h05m"
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+func formatBytes(b float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	i := 0
+	for b >= 1024 && i < len(units)-1 {
+		b /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", b, units[i])
+	}
+	return fmt.Sprintf("%.1f %s", b, units[i])
+}
+
+func formatCount(n float64) string {
+	switch {
+	case n >= 1e6:
+		return fmt.Sprintf("%.1fM", n/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.1fk", n/1e3)
+	default:
+		return fmt.Sprintf("%.0f", n)
+	}
+}
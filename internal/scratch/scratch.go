@@ -0,0 +1,237 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+// Package scratch manages the temporary files YSM needs for large
+// intermediate data: parallel export spill files, joined split dumps,
+// transfer and plugin dumps. They live in a directory of this process inside
+// the configured temp directory, are checked against its free space, and
+// are removed on exit or, after a crash, by the next run.
+package scratch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+)
+
+// DefaultMinFree is how much space is left free in the temp directory
+const DefaultMinFree = 512 << 20
+
+// sessionPrefix names the directory of one YSM process, followed by its pid
+const sessionPrefix = "ysm-scratch-"
+
+// checkEvery is how many bytes a scratch file takes between free space checks
+const checkEvery = 64 << 20
+
+// ErrNoSpace is returned when scratch data would leave less than the
+// configured free space in the temp directory
+var ErrNoSpace = errors.New("not enough free space for temporary files")
+
+// Settings configures where scratch files go
+type Settings struct {
+	Dir     string // Parent directory; empty = the system temp directory
+	MinFree int64  // Bytes always left free there
+}
+
+var (
+	mu       sync.Mutex
+	settings = Settings{MinFree: DefaultMinFree}
+	session  string // This process's directory, created on first use
+)
+
+// Configure sets the temp directory and free space margin, and removes what
+// earlier runs that crashed or were killed left there
+func Configure(s Settings) {
+	mu.Lock()
+	defer mu.Unlock()
+	if s.MinFree < 0 {
+		s.MinFree = 0
+	}
+	settings = s
+	session = ""
+	removeStale(parentDir(s))
+}
+
+// Dir returns the directory scratch files are created in
+func Dir() string {
+	mu.Lock()
+	defer mu.Unlock()
+	return parentDir(settings)
+}
+
+func parentDir(s Settings) string {
+	if s.Dir != "" {
+		return s.Dir
+	}
+	return os.TempDir()
+}
+
+// sessionDir returns this process's directory, creating it the first time
+func sessionDir() (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if session != "" {
+		return session, nil
+	}
+	dir := filepath.Join(parentDir(settings), sessionPrefix+strconv.Itoa(os.Getpid()))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	session = dir
+	return dir, nil
+}
+
+// removeStale deletes the directories of YSM processes that are gone
+func removeStale(parent string) {
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		pid, ok := strings.CutPrefix(e.Name(), sessionPrefix)
+		if !ok || !e.IsDir() {
+			continue
+		}
+		n, err := strconv.Atoi(pid)
+		if err != nil || n == os.Getpid() || processAlive(n) {
+			continue
+		}
+		path := filepath.Join(parent, e.Name())
+		if err := os.RemoveAll(path); err != nil {
+			logging.Warn("Failed to remove leftover temp files in %s: %v", path, err)
+		} else {
+			logging.Info("Removed temp files left by an earlier run: %s", path)
+		}
+	}
+}
+
+// Check makes sure need more bytes fit in the temp directory with the
+// configured margin to spare. Platforms without free space figures pass.
+func Check(need int64) error {
+	mu.Lock()
+	s := settings
+	mu.Unlock()
+
+	dir := parentDir(s)
+	free, err := DiskFree(dir)
+	if err != nil {
+		return nil
+	}
+	if free-need < s.MinFree {
+		return fmt.Errorf("%w in %s: %s free, %s needed with %s kept free (set temp.dir in the config or pass --temp-dir)",
+			ErrNoSpace, dir, formatSize(free), formatSize(need), formatSize(s.MinFree))
+	}
+	return nil
+}
+
+// File is a scratch file that fails writes once the temp directory runs
+// short of space, instead of filling the disk
+type File struct {
+	*os.File
+	unchecked int64 // Bytes written since the last free space check
+}
+
+// Write writes to the file, checking the free space every few megabytes
+func (f *File) Write(p []byte) (int, error) {
+	f.unchecked += int64(len(p))
+	if f.unchecked >= checkEvery {
+		f.unchecked = 0
+		if err := Check(0); err != nil {
+			return 0, err
+		}
+	}
+	return f.File.Write(p)
+}
+
+// ReadFrom copies r into the file through Write, so the checks apply
+func (f *File) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(struct{ io.Writer }{f}, r)
+}
+
+// Remove closes and deletes the file
+func (f *File) Remove() error {
+	f.File.Close()
+	return os.Remove(f.Name())
+}
+
+// CreateTemp creates a scratch file named after pattern, as os.CreateTemp
+// does, once need bytes are known to fit
+func CreateTemp(pattern string, need int64) (*File, error) {
+	if err := Check(need); err != nil {
+		return nil, err
+	}
+	dir, err := sessionDir()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	return &File{File: f}, nil
+}
+
+// MkdirTemp creates a scratch directory named after pattern, as
+// os.MkdirTemp does, once need bytes are known to fit
+func MkdirTemp(pattern string, need int64) (string, error) {
+	if err := Check(need); err != nil {
+		return "", err
+	}
+	dir, err := sessionDir()
+	if err != nil {
+		return "", err
+	}
+	path, err := os.MkdirTemp(dir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	return path, nil
+}
+
+// Cleanup removes everything this process put in the temp directory. It
+// runs on exit; files still in use by a running job go with it.
+func Cleanup() {
+	mu.Lock()
+	dir := session
+	session = ""
+	mu.Unlock()
+	if dir == "" {
+		return
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		logging.Warn("Failed to remove temp files in %s: %v", dir, err)
+	}
+}
+
+// formatSize formats bytes for error messages
+func formatSize(bytes int64) string {
+	switch {
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	}
+	return fmt.Sprintf("%d KB", bytes>>10)
+}
@@ -0,0 +1,39 @@
+//go:build !(linux || darwin || freebsd)
+
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package scratch
+
+import "errors"
+
+// DiskFree isn't supported on this platform
+func DiskFree(path string) (int64, error) {
+	return 0, errors.New("free disk space is not available on this platform")
+}
+
+// DiskSize isn't supported on this platform
+func DiskSize(path string) (int64, error) {
+	return 0, errors.New("disk size is not available on this platform")
+}
+
+// processAlive can't be checked on this platform, so leftovers of other
+// runs are kept
+func processAlive(pid int) bool {
+	return true
+}
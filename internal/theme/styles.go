@@ -0,0 +1,137 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package theme
+
+import (
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles are the colors and styles every view draws with, built from the
+// active theme
+type Styles struct {
+	Theme *Theme
+
+	Primary   lipgloss.TerminalColor
+	Secondary lipgloss.TerminalColor
+	Accent    lipgloss.TerminalColor
+	Text      lipgloss.TerminalColor
+	Selection lipgloss.TerminalColor
+	Muted     lipgloss.TerminalColor
+	Error     lipgloss.TerminalColor
+	Success   lipgloss.TerminalColor
+	Warning   lipgloss.TerminalColor
+	Highlight lipgloss.TerminalColor
+	Subtle    lipgloss.TerminalColor
+
+	Title        lipgloss.Style // Bold primary
+	Subtitle     lipgloss.Style // Italic accent
+	Header       lipgloss.Style // Bold accent
+	Focused      lipgloss.Style // The focused field
+	Blurred      lipgloss.Style // Fields without focus
+	MutedText    lipgloss.Style
+	ErrorText    lipgloss.Style
+	SuccessText  lipgloss.Style
+	WarningText  lipgloss.Style
+	Value        lipgloss.Style // Bold text
+	Selected     lipgloss.Style // The selected row or tab
+	SelectedDesc lipgloss.Style // Second line of the selected list item
+	Capture      lipgloss.Style // A row waiting for input, like a key to bind
+	Box          lipgloss.Style // Rounded border, no padding
+	ActiveBox    lipgloss.Style // Rounded border of the focused pane
+}
+
+var (
+	mu      sync.RWMutex
+	current = build(builtin(DefaultName))
+)
+
+// Current returns the styles of the active theme
+func Current() *Styles {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Use makes a theme the active one. Views built afterwards draw with it.
+func Use(t *Theme) {
+	s := build(t)
+	mu.Lock()
+	current = s
+	mu.Unlock()
+}
+
+// color turns a palette value into a lipgloss color; empty and no-color
+// themes leave the terminal's own color
+func (t *Theme) color(value string) lipgloss.TerminalColor {
+	if t.NoColor || value == "" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(value)
+}
+
+func build(t *Theme) *Styles {
+	r := t.resolved()
+	c := r.Colors
+	s := &Styles{
+		Theme:     t,
+		Primary:   r.color(c.Primary),
+		Secondary: r.color(c.Secondary),
+		Accent:    r.color(c.Accent),
+		Text:      r.color(c.Text),
+		Selection: r.color(c.Selection),
+		Muted:     r.color(c.Muted),
+		Error:     r.color(c.Error),
+		Success:   r.color(c.Success),
+		Warning:   r.color(c.Warning),
+		Highlight: r.color(c.Highlight),
+		Subtle:    r.color(c.Subtle),
+	}
+
+	s.Title = lipgloss.NewStyle().Foreground(s.Primary).Bold(true)
+	s.Subtitle = lipgloss.NewStyle().Foreground(s.Accent).Italic(true)
+	s.Header = lipgloss.NewStyle().Foreground(s.Accent).Bold(true)
+	s.Focused = lipgloss.NewStyle().Foreground(s.Primary)
+	s.Blurred = lipgloss.NewStyle().Foreground(s.Muted)
+	s.MutedText = lipgloss.NewStyle().Foreground(s.Muted)
+	s.ErrorText = lipgloss.NewStyle().Foreground(s.Error)
+	s.SuccessText = lipgloss.NewStyle().Foreground(s.Success)
+	s.WarningText = lipgloss.NewStyle().Foreground(s.Warning)
+	s.Value = lipgloss.NewStyle().Foreground(s.Text).Bold(true)
+	s.Selected = lipgloss.NewStyle().Foreground(s.Selection).Background(s.Primary).Bold(true)
+	s.SelectedDesc = lipgloss.NewStyle().Foreground(s.Selection).Background(s.Primary)
+	s.Capture = lipgloss.NewStyle().Foreground(s.Selection).Background(s.Highlight).Bold(true)
+	s.Box = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(s.Primary)
+	s.ActiveBox = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(s.Secondary)
+
+	if r.NoColor {
+		// Without colors the focus and the selection need other cues
+		s.Focused = s.Focused.Bold(true).Underline(true)
+		s.Blurred = s.Blurred.Faint(true)
+		s.MutedText = s.MutedText.Faint(true)
+		s.ErrorText = s.ErrorText.Bold(true)
+		s.WarningText = s.WarningText.Bold(true)
+		s.Selected = s.Selected.Reverse(true)
+		s.SelectedDesc = s.SelectedDesc.Reverse(true)
+		s.Capture = s.Capture.Reverse(true).Underline(true)
+		s.ActiveBox = s.ActiveBox.Border(lipgloss.DoubleBorder())
+	}
+	return s
+}
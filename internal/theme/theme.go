@@ -0,0 +1,342 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+// Package theme holds the TUI color schemes: the built-in themes, themes the
+// user writes in YAML, and the styles the views share, built from the
+// active one.
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultName is the theme used when none is configured
+const DefaultName = "yandere"
+
+// NoColorName is the built-in theme without colors, picked when NO_COLOR is
+// set and no theme is configured
+const NoColorName = "nocolor"
+
+// Palette is a theme's colors, each a #RRGGBB hex value or an ANSI color
+// number from 0 to 255
+type Palette struct {
+	Primary   string `yaml:"primary,omitempty" json:"primary,omitempty"`     // Titles, borders and the selection background
+	Secondary string `yaml:"secondary,omitempty" json:"secondary,omitempty"` // Borders of the focused pane
+	Accent    string `yaml:"accent,omitempty" json:"accent,omitempty"`       // Subtitles and headers
+	Text      string `yaml:"text,omitempty" json:"text,omitempty"`           // Values and table cells
+	Selection string `yaml:"selection,omitempty" json:"selection,omitempty"` // Text on the selection background
+	Muted     string `yaml:"muted,omitempty" json:"muted,omitempty"`         // Help lines and unfocused fields
+	Error     string `yaml:"error,omitempty" json:"error,omitempty"`
+	Success   string `yaml:"success,omitempty" json:"success,omitempty"`
+	Warning   string `yaml:"warning,omitempty" json:"warning,omitempty"`
+	Highlight string `yaml:"highlight,omitempty" json:"highlight,omitempty"` // Key capture and changed settings
+	Subtle    string `yaml:"subtle,omitempty" json:"subtle,omitempty"`       // The empty part of gauges
+}
+
+// Theme is a named color scheme
+type Theme struct {
+	Name        string  `yaml:"name" json:"name"`
+	Description string  `yaml:"description,omitempty" json:"description,omitempty"`
+	Extends     string  `yaml:"extends,omitempty" json:"extends,omitempty"`   // Built-in theme the colors left out come from (default: yandere)
+	NoColor     bool    `yaml:"no_color,omitempty" json:"no_color,omitempty"` // Bold, underline and reverse video instead of colors
+	Colors      Palette `yaml:"colors" json:"colors"`
+
+	Path string `yaml:"-" json:"path,omitempty"` // File a user theme was read from; empty for built-ins
+}
+
+// builtins are the themes that ship with YSM, in the order they are listed
+var builtins = []*Theme{
+	{
+		Name:        "yandere",
+		Description: "Hot pink on dark, the default",
+		Colors: Palette{
+			Primary:   "#FF69B4",
+			Secondary: "#FF1493",
+			Accent:    "#FFB6C1",
+			Text:      "#FFFFFF",
+			Selection: "#FFFFFF",
+			Muted:     "#888888",
+			Error:     "#FF4444",
+			Success:   "#44FF44",
+			Warning:   "#FFAA00",
+			Highlight: "#FFD700",
+			Subtle:    "#333333",
+		},
+	},
+	{
+		Name:        "midnight",
+		Description: "Calm blues and teals",
+		Colors: Palette{
+			Primary:   "#5E81AC",
+			Secondary: "#88C0D0",
+			Accent:    "#8FBCBB",
+			Text:      "#ECEFF4",
+			Selection: "#ECEFF4",
+			Muted:     "#7B8394",
+			Error:     "#BF616A",
+			Success:   "#A3BE8C",
+			Warning:   "#EBCB8B",
+			Highlight: "#D08770",
+			Subtle:    "#3B4252",
+		},
+	},
+	{
+		Name:        "colorblind",
+		Description: "Okabe-Ito palette, told apart with any color vision",
+		Colors: Palette{
+			Primary:   "#0072B2",
+			Secondary: "#56B4E9",
+			Accent:    "#56B4E9",
+			Text:      "#FFFFFF",
+			Selection: "#FFFFFF",
+			Muted:     "#999999",
+			Error:     "#D55E00",
+			Success:   "#009E73",
+			Warning:   "#E69F00",
+			Highlight: "#F0E442",
+			Subtle:    "#444444",
+		},
+	},
+	{
+		Name:        "light",
+		Description: "Dark text for light terminal backgrounds",
+		Colors: Palette{
+			Primary:   "#C2185B",
+			Secondary: "#880E4F",
+			Accent:    "#AD1457",
+			Text:      "#212121",
+			Selection: "#FFFFFF",
+			Muted:     "#6E6E6E",
+			Error:     "#C62828",
+			Success:   "#2E7D32",
+			Warning:   "#E65100",
+			Highlight: "#6A1B9A",
+			Subtle:    "#CCCCCC",
+		},
+	},
+	{
+		Name:        NoColorName,
+		Description: "No colors, only bold, underline and reverse video",
+		NoColor:     true,
+	},
+}
+
+// Builtins returns the themes that ship with YSM
+func Builtins() []*Theme {
+	return builtins
+}
+
+func builtin(name string) *Theme {
+	for _, t := range builtins {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// validNameRe limits theme names to what works as a file name
+var validNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// Validate checks the name and colors of a theme
+func (t *Theme) Validate() error {
+	if !validNameRe.MatchString(t.Name) {
+		return fmt.Errorf("invalid theme name '%s' (use letters, digits, - and _)", t.Name)
+	}
+	if t.Extends != "" && builtin(t.Extends) == nil {
+		return fmt.Errorf("theme %s extends unknown built-in theme '%s'", t.Name, t.Extends)
+	}
+	for role, value := range t.Colors.roles() {
+		if value != "" && !validColor(value) {
+			return fmt.Errorf("theme %s: invalid %s color '%s' (use #RRGGBB or 0-255)", t.Name, role, value)
+		}
+	}
+	return nil
+}
+
+func validColor(value string) bool {
+	if strings.HasPrefix(value, "#") {
+		if len(value) != 7 {
+			return false
+		}
+		_, err := strconv.ParseUint(value[1:], 16, 32)
+		return err == nil
+	}
+	n, err := strconv.Atoi(value)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// roles returns the palette's colors by YAML name
+func (p *Palette) roles() map[string]string {
+	return map[string]string{
+		"primary":   p.Primary,
+		"secondary": p.Secondary,
+		"accent":    p.Accent,
+		"text":      p.Text,
+		"selection": p.Selection,
+		"muted":     p.Muted,
+		"error":     p.Error,
+		"success":   p.Success,
+		"warning":   p.Warning,
+		"highlight": p.Highlight,
+		"subtle":    p.Subtle,
+	}
+}
+
+// resolved returns the theme with the colors it leaves out taken from the
+// theme it extends
+func (t *Theme) resolved() *Theme {
+	if t.NoColor {
+		return t
+	}
+	base := builtin(t.Extends)
+	if base == nil {
+		base = builtin(DefaultName)
+	}
+	r := *t
+	fill := func(c *string, from string) {
+		if *c == "" {
+			*c = from
+		}
+	}
+	fill(&r.Colors.Primary, base.Colors.Primary)
+	fill(&r.Colors.Secondary, base.Colors.Secondary)
+	fill(&r.Colors.Accent, base.Colors.Accent)
+	fill(&r.Colors.Text, base.Colors.Text)
+	fill(&r.Colors.Selection, base.Colors.Selection)
+	fill(&r.Colors.Muted, base.Colors.Muted)
+	fill(&r.Colors.Error, base.Colors.Error)
+	fill(&r.Colors.Success, base.Colors.Success)
+	fill(&r.Colors.Warning, base.Colors.Warning)
+	fill(&r.Colors.Highlight, base.Colors.Highlight)
+	fill(&r.Colors.Subtle, base.Colors.Subtle)
+	return &r
+}
+
+// ReadFile reads a user theme. The file name is the theme's name unless the
+// file sets one.
+func ReadFile(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme file: %w", err)
+	}
+
+	var t Theme
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse theme file %s: %w", path, err)
+	}
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	t.Path = path
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// List returns the built-in themes followed by the user themes in dir,
+// sorted by name. A user theme named like a built-in one replaces it.
+// Files that aren't valid themes are left out and reported in the error.
+func List(dir string) ([]*Theme, error) {
+	themes := append([]*Theme(nil), builtins...)
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	more, _ := filepath.Glob(filepath.Join(dir, "*.yml"))
+	paths = append(paths, more...)
+	sort.Strings(paths)
+
+	var user []*Theme
+	var errs []error
+	for _, path := range paths {
+		t, err := ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		replaced := false
+		for i, b := range themes {
+			if b.Name == t.Name {
+				themes[i] = t
+				replaced = true
+			}
+		}
+		if !replaced {
+			user = append(user, t)
+		}
+	}
+	sort.Slice(user, func(i, j int) bool { return user[i].Name < user[j].Name })
+	return append(themes, user...), errors.Join(errs...)
+}
+
+// Find returns the theme with the given name from the built-ins and the
+// user themes in dir. An empty name is the default theme, or the no-color
+// one when the NO_COLOR environment variable is set.
+func Find(dir, name string) (*Theme, error) {
+	if name == "" {
+		name = DefaultName
+		if os.Getenv("NO_COLOR") != "" {
+			name = NoColorName
+		}
+	}
+
+	themes, listErr := List(dir)
+	for _, t := range themes {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	if listErr != nil {
+		// The theme may be the one that didn't load
+		return nil, listErr
+	}
+
+	names := make([]string, len(themes))
+	for i, t := range themes {
+		names[i] = t.Name
+	}
+	return nil, fmt.Errorf("unknown theme '%s' (available: %s)", name, strings.Join(names, ", "))
+}
+
+// Template returns a user theme to start editing from, with every color of
+// the given theme written out
+func Template(name string, from *Theme) ([]byte, error) {
+	r := from.resolved()
+	t := Theme{
+		Name:        name,
+		Description: "Based on " + from.Name,
+		NoColor:     r.NoColor,
+		Colors:      r.Colors,
+	}
+	data, err := yaml.Marshal(&t)
+	if err != nil {
+		return nil, err
+	}
+	header := "# YSM theme - colors are #RRGGBB or ANSI numbers 0-255; ones left out\n" +
+		"# come from the built-in theme named by extends (default: yandere)\n"
+	return append([]byte(header), data...), nil
+}
@@ -19,10 +19,14 @@
 package tui
 
 import (
-	"fmt"
+	"os"
+	"time"
 
+	"github.com/blubskye/yandere_sql_manager/internal/alert"
+	"github.com/blubskye/yandere_sql_manager/internal/clipboard"
 	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
 	"github.com/blubskye/yandere_sql_manager/internal/tui/views"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -45,6 +49,27 @@ const (
 	ViewDashboard
 	ViewCluster
 	ViewKeybindings
+	ViewPlugins
+	ViewDesigner
+	ViewIndexes
+	ViewTableDetail
+	ViewSchemaDiff
+	ViewSync
+	ViewLocks
+	ViewBloat
+	ViewForeignLink
+	ViewProcesses
+	ViewRebuild
+	ViewTransfer
+	ViewAuditLog
+	ViewProfiles
+	ViewCloneMerge
+	ViewJobs
+	ViewTimeline
+	ViewAdvisor
+	ViewTuning
+	ViewTemplates
+	ViewDumpBrowser
 )
 
 // Model is the main application model
@@ -52,20 +77,29 @@ type Model struct {
 	width  int
 	height int
 
-	conn    *db.Connection
-	connCfg *db.ConnectionConfig
-	cfg     *config.Config
+	*session                 // The tab on screen
+	sessions      []*session // Tabs, in order
+	active        int        // Index of the tab on screen
+	nextSessionID int
 
-	currentView ViewType
-	views       map[ViewType]tea.Model
+	cfg      *config.Config
+	quitting bool
 
-	err        error
-	statusMsg  string
-	quitting   bool
+	tutorial *tutorial    // Guided overlay in demo mode (nil otherwise)
+	idle     *idleLock    // Idle auto-lock (nil when disabled)
+	help     *helpOverlay // Key help for the current view (nil when closed)
+	notifier *jobNotifier // Bell/desktop notices for long jobs (nil when disabled)
+	blurred  bool         // The terminal reported losing focus
+
+	quitWarned time.Time // When quitting was held back for running jobs
+
+	statusItems    []string      // Status bar items, in order
+	healthInterval time.Duration // How often each tab pings its server (0 when not shown)
+	compact        bool          // Stacked panels and a short status bar
 }
 
 // New creates a new TUI application
-func New(connCfg *db.ConnectionConfig) *Model {
+func New(connCfg *db.ConnectionConfig, profileName string) *Model {
 	cfg, _ := config.Load()
 	if cfg == nil {
 		cfg = &config.Config{
@@ -73,58 +107,208 @@ func New(connCfg *db.ConnectionConfig) *Model {
 		}
 	}
 
+	useTheme(cfg.Theme)
+
 	m := &Model{
-		connCfg:     connCfg,
-		cfg:         cfg,
-		currentView: ViewConnect,
-		views:       make(map[ViewType]tea.Model),
+		cfg: cfg,
 	}
 
-	// Initialize connect view
-	m.views[ViewConnect] = views.NewConnectView(cfg, connCfg)
+	items, health, err := cfg.StatusBarSettings()
+	if err != nil {
+		logging.Warn("Using default status bar: %v", err)
+	}
+	m.statusItems = items
+	if wantsHealth(items) {
+		m.healthInterval = health
+	}
+	if _, err := cfg.CompactLayout(0); err != nil {
+		logging.Warn("Using the auto layout: %v", err)
+	}
+
+	// The first tab starts on the connect view
+	m.session = m.newSession(connCfg, profileName)
+
+	if timeout, err := cfg.IdleLockAfter(); err != nil {
+		logging.Warn("Idle lock disabled: %v", err)
+	} else if timeout > 0 {
+		m.idle = newIdleLock(timeout)
+	}
+
+	if n := cfg.Notify; n != nil && (n.Bell || n.Desktop) {
+		after, err := cfg.NotifyAfter()
+		if err != nil {
+			logging.Warn("Using default notify delay: %v", err)
+		}
+		m.notifier = newJobNotifier(n, after)
+	}
 
 	return m
 }
 
 // Init initializes the application
 func (m *Model) Init() tea.Cmd {
-	return m.views[ViewConnect].Init()
+	cmds := []tea.Cmd{m.session.wrap(m.views[m.currentView].Init())}
+	if m.idle != nil {
+		cmds = append(cmds, m.idle.tick())
+	}
+	if m.queryWatch != nil {
+		cmds = append(cmds, m.session.wrap(m.queryWatch.tick()))
+	}
+	if m.health != nil {
+		cmds = append(cmds, m.session.wrap(m.health.tick()))
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update handles messages
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.idle != nil {
+		if cmd, handled := m.updateIdleLock(msg); handled {
+			return m, cmd
+		}
+	}
+
+	// Any key closes the help overlay without reaching the view
+	if m.help != nil {
+		if _, ok := msg.(tea.KeyMsg); ok {
+			m.help = nil
+			return m, nil
+		}
+	}
+
+	if m.tutorial == nil {
+		return m.update(msg)
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case tutorialNextKey:
+			// A hidden guide leaves the key to the view, e.g. to generate a password
+			if !m.tutorial.hidden {
+				m.tutorial.next()
+				return m, nil
+			}
+		case tutorialToggleKey:
+			m.tutorial.hidden = !m.tutorial.hidden
+			return m, nil
+		}
+	}
+
+	model, cmd := m.update(msg)
+	m.tutorial.observe(m.currentView)
+	return model, cmd
+}
+
+func (m *Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c":
-			m.quitting = true
-			if m.conn != nil {
-				m.conn.Close()
+			return m, m.quit()
+		case processesKey:
+			if m.conn != nil && m.currentView != ViewConnect && m.currentView != ViewProcesses {
+				return m.switchViewString("processes", "", "", "")
+			}
+		case jobsKey:
+			if m.conn != nil && m.currentView != ViewConnect && m.currentView != ViewJobs {
+				return m.switchViewString("jobs", "", "", "")
+			}
+		case newTabKey:
+			return m, m.openTab()
+		case closeTabKey:
+			if len(m.sessions) > 1 {
+				return m, m.closeSession(m.session)
 			}
-			return m, tea.Quit
+			return m, nil
+		default:
+			if i, ok := tabIndex(msg.String()); ok {
+				return m, m.switchTab(i)
+			}
+		}
+
+	case sessionMsg:
+		return m.updateSession(msg)
+
+	case queryWatchTickMsg, queryWatchResultMsg:
+		if m.queryWatch != nil {
+			return m, m.session.wrap(m.updateQueryWatch(msg))
+		}
+		return m, nil
+
+	case healthTickMsg, healthResultMsg:
+		if m.health != nil {
+			return m, m.session.wrap(m.updateHealthWatch(msg))
 		}
+		return m, nil
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.setLayout()
 		// Propagate to current view
-		if view, ok := m.views[m.currentView]; ok {
-			newView, cmd := view.Update(msg)
-			m.views[m.currentView] = newView
-			return m, cmd
-		}
+		return m, m.resizeView()
 
 	// Handle connected message from connect view
 	case views.ConnectedMsg:
+		if m.metrics != nil {
+			m.metrics.Stop()
+			m.metrics = nil
+		}
+		if m.alerts != nil {
+			m.alerts.Stop()
+			m.alerts = nil
+		}
 		m.conn = msg.Conn
+		m.profile = msg.Profile
 		m.statusMsg = "Connected!"
 		m.currentView = ViewDatabases
 		m.views[ViewDatabases] = views.NewDatabasesView(m.conn, m.width, m.height)
-		return m, m.views[ViewDatabases].Init()
+		cmds := []tea.Cmd{m.session.wrap(m.views[ViewDatabases].Init()), m.observeTimeline()}
+		if m.health != nil {
+			// Show the server's health and version without waiting a tick
+			cmds = append(cmds, m.session.wrap(m.health.check(m.conn)))
+		}
+		// The database list stays underneath, for Esc from the startup view
+		if view, database := m.startupView(); view != "databases" {
+			_, cmd := m.switchViewString(view, database, "", "")
+			cmds = append(cmds, cmd)
+		}
+		return m, tea.Batch(cmds...)
 
 	// Handle view switching from views
 	case views.SwitchViewMsg:
-		return m.switchViewString(msg.View, msg.Database, msg.Table)
+		return m.switchViewString(msg.View, msg.Database, msg.Table, msg.Path)
+
+	case views.ShowHelpMsg:
+		m.help = newHelpOverlay(msg.View)
+		return m, nil
+
+	// Terminals that support focus reporting tell us when the user looks away
+	case tea.FocusMsg:
+		m.blurred = false
+		return m, nil
+	case tea.BlurMsg:
+		m.blurred = true
+		return m, nil
+
+	case views.JobDoneMsg:
+		job := msg.JobResult()
+		view, ok := m.views[m.currentView]
+		owned := ok && jobViews[job.View] == m.currentView && views.OwnsJob(view, msg)
+		var notify tea.Cmd
+		if m.notifier != nil {
+			notify = m.notifier.notify(job, owned && m.watching(job.View))
+		}
+		hooks := m.sendWebhooks(job.Event)
+		record := m.recordJob(job)
+		if !owned {
+			// The job's view was left, so the status bar tells how it went
+			m.statusMsg = jobEndedStatus(job)
+			return m, tea.Batch(notify, hooks, record)
+		}
+		newView, cmd := views.DeliverJob(view, msg)
+		m.views[m.currentView] = newView
+		return m, tea.Batch(m.session.wrap(cmd), notify, hooks, record)
 
 	case error:
 		m.err = msg
@@ -135,18 +319,18 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if view, ok := m.views[m.currentView]; ok {
 		newView, cmd := view.Update(msg)
 		m.views[m.currentView] = newView
-		return m, cmd
+		return m, m.session.wrap(cmd)
 	}
 
 	return m, nil
 }
 
-func (m *Model) switchViewString(viewName, database, table string) (tea.Model, tea.Cmd) {
+func (m *Model) switchViewString(viewName, database, table, path string) (tea.Model, tea.Cmd) {
 	switch viewName {
 	case "connect":
 		m.currentView = ViewConnect
 		if _, ok := m.views[ViewConnect]; !ok {
-			m.views[ViewConnect] = views.NewConnectView(m.cfg, m.connCfg)
+			m.views[ViewConnect] = views.NewConnectView(m.cfg, m.connCfg, m.profile)
 		}
 	case "databases":
 		m.currentView = ViewDatabases
@@ -159,38 +343,105 @@ func (m *Model) switchViewString(viewName, database, table string) (tea.Model, t
 		m.views[ViewBrowser] = views.NewBrowserView(m.conn, database, table, m.width, m.height)
 	case "query":
 		m.currentView = ViewQuery
-		m.views[ViewQuery] = views.NewQueryView(m.conn, database, m.width, m.height)
+		m.views[ViewQuery] = views.NewQueryView(m.conn, m.cfg, m.profile, database, m.width, m.height)
 	case "import":
 		m.currentView = ViewImport
 		m.views[ViewImport] = views.NewImportView(m.conn, database, m.width, m.height)
 	case "export":
 		m.currentView = ViewExport
-		m.views[ViewExport] = views.NewExportView(m.conn, database, m.width, m.height)
+		m.views[ViewExport] = views.NewExportView(m.conn, m.cfg, database, m.activeProfile(), m.width, m.height)
 	case "settings":
 		m.currentView = ViewSettings
-		m.views[ViewSettings] = views.NewSettingsView(m.conn, m.width, m.height)
+		m.views[ViewSettings] = views.NewSettingsView(m.conn, m.cfg, m.width, m.height)
 	case "users":
 		m.currentView = ViewUsers
-		m.views[ViewUsers] = views.NewUsersView(m.conn, m.width, m.height)
+		m.views[ViewUsers] = views.NewUsersView(m.conn, m.cfg, m.profile, m.width, m.height)
 	case "backup":
 		m.currentView = ViewBackup
-		m.views[ViewBackup] = views.NewBackupView(m.conn, m.width, m.height)
+		m.views[ViewBackup] = views.NewBackupView(m.conn, m.cfg, m.profile, m.width, m.height)
 	case "setup":
 		m.currentView = ViewSetupWizard
-		m.views[ViewSetupWizard] = views.NewSetupWizardView(m.conn, m.width, m.height)
+		m.views[ViewSetupWizard] = views.NewSetupWizardView(m.conn, m.cfg, m.width, m.height)
 	case "dashboard":
 		m.currentView = ViewDashboard
-		m.views[ViewDashboard] = views.NewDashboardView(m.conn, m.width, m.height)
+		m.views[ViewDashboard] = views.NewDashboardView(m.conn, m.metricsCollector(), m.alertMonitor(), m.width, m.height)
 	case "cluster":
 		m.currentView = ViewCluster
-		m.views[ViewCluster] = views.NewClusterView(m.conn, m.width, m.height)
+		m.views[ViewCluster] = views.NewClusterView(m.conn, m.alertMonitor(), m.width, m.height)
 	case "keybindings":
 		m.currentView = ViewKeybindings
 		m.views[ViewKeybindings] = views.NewKeybindingsView(m.width, m.height)
+	case "plugins":
+		m.currentView = ViewPlugins
+		m.views[ViewPlugins] = views.NewPluginsView(m.conn, database, m.width, m.height)
+	case "designer":
+		m.currentView = ViewDesigner
+		m.views[ViewDesigner] = views.NewDesignerView(m.conn, database, table, m.width, m.height)
+	case "indexes":
+		m.currentView = ViewIndexes
+		m.views[ViewIndexes] = views.NewIndexesView(m.conn, database, table, m.width, m.height)
+	case "detail":
+		m.currentView = ViewTableDetail
+		m.views[ViewTableDetail] = views.NewTableDetailView(m.conn, database, table, m.width, m.height)
+	case "schemadiff":
+		m.currentView = ViewSchemaDiff
+		m.views[ViewSchemaDiff] = views.NewSchemaDiffView(m.conn, database, m.width, m.height)
+	case "sync":
+		m.currentView = ViewSync
+		m.views[ViewSync] = views.NewSyncView(m.conn, database, m.width, m.height)
+	case "locks":
+		m.currentView = ViewLocks
+		m.views[ViewLocks] = views.NewLocksView(m.conn, m.width, m.height)
+	case "bloat":
+		m.currentView = ViewBloat
+		m.views[ViewBloat] = views.NewBloatView(m.conn, database, m.width, m.height)
+	case "advisor":
+		m.currentView = ViewAdvisor
+		m.views[ViewAdvisor] = views.NewAdvisorView(m.conn, database, m.width, m.height)
+	case "tuning":
+		m.currentView = ViewTuning
+		m.views[ViewTuning] = views.NewTuningView(m.conn, m.width, m.height)
+	case "templates":
+		m.currentView = ViewTemplates
+		m.views[ViewTemplates] = views.NewTemplatesView(m.width, m.height)
+	case "foreign":
+		m.currentView = ViewForeignLink
+		m.views[ViewForeignLink] = views.NewForeignLinkView(m.conn, m.cfg, m.width, m.height)
+	case "rebuild":
+		m.currentView = ViewRebuild
+		m.views[ViewRebuild] = views.NewOnlineRebuildView(m.conn, database, table, m.width, m.height)
+	case "transfer":
+		m.currentView = ViewTransfer
+		m.views[ViewTransfer] = views.NewTransferView(m.conn, database, table, m.transferTargets(), m.width, m.height)
+	case "clone":
+		m.currentView = ViewCloneMerge
+		m.views[ViewCloneMerge] = views.NewCopyWizardView(m.conn, database, m.width, m.height)
+	case "profiles":
+		m.currentView = ViewProfiles
+		m.views[ViewProfiles] = views.NewProfilesView(m.cfg, m.conn != nil, m.width, m.height)
+	case "processes":
+		after := config.DefaultQueryWatchAfter
+		if m.queryWatch != nil {
+			after = m.queryWatch.after
+		}
+		m.currentView = ViewProcesses
+		m.views[ViewProcesses] = views.NewProcessesView(m.conn, after, m.width, m.height)
+	case "jobs":
+		m.currentView = ViewJobs
+		m.views[ViewJobs] = views.NewJobsView(m.width, m.height)
+	case "timeline":
+		m.currentView = ViewTimeline
+		m.views[ViewTimeline] = views.NewTimelineView(m.conn, m.serverName(), m.width, m.height)
+	case "dump":
+		m.currentView = ViewDumpBrowser
+		m.views[ViewDumpBrowser] = views.NewDumpBrowserView(path, database, m.width, m.height)
+	case "audit":
+		m.currentView = ViewAuditLog
+		m.views[ViewAuditLog] = views.NewAuditView(m.conn, m.width, m.height)
 	}
 
 	if view, ok := m.views[m.currentView]; ok {
-		return m, view.Init()
+		return m, m.session.wrap(view.Init())
 	}
 
 	return m, nil
@@ -202,6 +453,14 @@ func (m *Model) View() string {
 		return "Goodbye~ I'll be waiting for you...\n"
 	}
 
+	if m.idle != nil && m.idle.locked {
+		return m.idle.view(m)
+	}
+
+	if m.help != nil {
+		return m.help.render() + "\n" + m.renderStatusBar()
+	}
+
 	// Get current view
 	var content string
 	if view, ok := m.views[m.currentView]; ok {
@@ -210,35 +469,113 @@ func (m *Model) View() string {
 		content = "Loading..."
 	}
 
+	// Explain known server errors above the status bar
+	if hint := db.ExplainError(m.err); hint != nil {
+		content += "\n" + errorStyle.Render(hint.Title+": ") + mutedStyle.Render(hint.Explanation) +
+			"\n" + mutedStyle.Render("Fix: "+hint.Fix)
+	}
+
+	if m.tutorial != nil {
+		content += "\n" + m.tutorial.view(m.width)
+	}
+
 	// Add status bar at bottom
 	status := m.renderStatusBar()
 
 	return content + "\n" + status
 }
 
-func (m *Model) renderStatusBar() string {
-	var status string
-	if m.conn != nil {
-		dbName := m.conn.Config.Database
-		if dbName == "" {
-			dbName = "(none)"
+// metricsCollector returns the connection's trend collector, creating it on
+// first use so sampling only starts once the dashboard has been opened
+func (m *Model) metricsCollector() *db.MetricsCollector {
+	if m.metrics == nil {
+		interval, err := m.cfg.MetricsSampleInterval()
+		if err != nil {
+			logging.Warn("Using default metrics interval: %v", err)
+		}
+		m.metrics = db.NewMetricsCollector(m.conn, interval)
+		if m.cfg.HostMetrics {
+			if err := m.metrics.EnableHostMetrics(); err != nil {
+				logging.Warn("Host metrics unavailable: %v", err)
+			}
 		}
-		status = fmt.Sprintf(" %s@%s:%d | DB: %s ",
-			m.conn.Config.User, m.conn.Config.Host, m.conn.Config.Port, dbName)
 	}
+	return m.metrics
+}
 
-	if m.err != nil {
-		status += errorStyle.Render(fmt.Sprintf(" | Error: %v", m.err))
-	} else if m.statusMsg != "" {
-		status += fmt.Sprintf(" | %s", m.statusMsg)
+// alertMonitor returns the connection's alert monitor, creating it on first
+// use so polling only starts once the cluster view or dashboard is opened.
+// It returns nil when alerts aren't configured
+func (m *Model) alertMonitor() *alert.Monitor {
+	if m.alerts == nil && m.cfg.Alerts != nil {
+		server := m.profile
+		if server == "" {
+			server = m.conn.Config.Host
+		}
+		monitor, err := alert.NewMonitor(m.cfg.Alerts, server)
+		if err != nil {
+			logging.Warn("Alerts disabled: %v", err)
+			return nil
+		}
+		m.alerts = monitor
 	}
+	return m.alerts
+}
 
-	return statusBarStyle.Width(m.width).Render(status)
+// activeProfile returns the connection's profile, or an empty one when it
+// isn't from a profile
+func (m *Model) activeProfile() config.Profile {
+	if m.profile == "" {
+		return config.Profile{}
+	}
+	p, err := m.cfg.GetProfile(m.profile)
+	if err != nil {
+		return config.Profile{}
+	}
+	return *p
+}
+
+// startupView returns the view the connection's profile opens on, and its
+// database. The database is selected on the connection first, so views that
+// don't take one, such as the dashboard, still start on it.
+func (m *Model) startupView() (view, database string) {
+	p := m.activeProfile()
+	view, database, err := p.Startup()
+	if err != nil {
+		logging.Warn("Profile %s: %v", m.profile, err)
+	}
+	if database != "" {
+		if err := m.conn.UseDatabase(database); err != nil {
+			logging.Warn("Profile %s: %v", m.profile, err)
+			return "databases", ""
+		}
+	}
+	return view, database
+}
+
+// RunDemo starts the TUI on an open connection with the demo guide shown
+func RunDemo(conn *db.Connection, profileName, database string) error {
+	m := New(&conn.Config, profileName)
+	m.conn = conn
+	m.currentView = ViewDatabases
+	m.views[ViewDatabases] = views.NewDatabasesView(conn, m.width, m.height)
+	m.tutorial = newTutorial(database)
+
+	_, err := newProgram(m).Run()
+	return err
 }
 
 // Run starts the TUI application
-func Run(connCfg *db.ConnectionConfig) error {
-	p := tea.NewProgram(New(connCfg), tea.WithAltScreen())
-	_, err := p.Run()
+func Run(connCfg *db.ConnectionConfig, profileName string) error {
+	_, err := newProgram(New(connCfg, profileName)).Run()
 	return err
 }
+
+// newProgram renders m to the terminal, through the output clipboard copies
+// use as well so their escape sequences don't interleave with a frame
+func newProgram(m *Model) *tea.Program {
+	out := clipboard.NewOutput(os.Stdout)
+	views.SetTerminal(out)
+	return tea.NewProgram(m, tea.WithAltScreen(), tea.WithReportFocus(), tea.WithOutput(out))
+}
+
@@ -0,0 +1,79 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+)
+
+// helpOverlay lists the keys of one view, read from the keybindings file
+// when opened so custom bindings always show
+type helpOverlay struct {
+	view     string
+	sections []config.HelpSection
+	err      error
+}
+
+func newHelpOverlay(view string) *helpOverlay {
+	kb, err := config.LoadKeyBindings()
+	if err != nil {
+		kb = config.DefaultKeyBindings()
+	}
+	return &helpOverlay{
+		view:     view,
+		sections: kb.Help(view),
+		err:      err,
+	}
+}
+
+func (h *helpOverlay) render() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Keys: %s", h.view)))
+	b.WriteString("\n")
+
+	if h.err != nil {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Using default keys: %v", h.err)))
+		b.WriteString("\n")
+	}
+
+	keyWidth := 0
+	for _, section := range h.sections {
+		for _, binding := range section.Bindings {
+			keyWidth = max(keyWidth, len(binding.Key))
+		}
+	}
+
+	for _, section := range h.sections {
+		b.WriteString("\n")
+		b.WriteString(subtitleStyle.Render(section.Category))
+		b.WriteString("\n")
+		for _, binding := range section.Bindings {
+			b.WriteString(fmt.Sprintf("  %s  %s\n",
+				helpKeyStyle.Render(fmt.Sprintf("%-*s", keyWidth, binding.Key)),
+				binding.Description))
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("Any key: Close | Change keys in the keybindings settings"))
+	return boxStyle.Render(b.String())
+}
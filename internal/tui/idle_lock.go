@@ -0,0 +1,226 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package tui
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/tui/views"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// idleCheckInterval is how often the app checks whether it has been idle too long
+const idleCheckInterval = 10 * time.Second
+
+// idleLock locks the TUI after a period without input, since it is often
+// left open on shared workstations. Unlocking takes the connection password;
+// connections without one (socket, peer or trust authentication) unlock by
+// confirming a reconnect with the stored settings instead.
+type idleLock struct {
+	timeout      time.Duration
+	lastActivity time.Time
+	locked       bool
+	verifying    bool
+	input        textinput.Model
+	err          error
+}
+
+type idleCheckMsg struct{}
+
+type unlockResultMsg struct {
+	err error
+}
+
+func newIdleLock(timeout time.Duration) *idleLock {
+	input := textinput.New()
+	input.Placeholder = "Password"
+	input.EchoMode = textinput.EchoPassword
+	input.EchoCharacter = '•'
+	input.CharLimit = 256
+
+	return &idleLock{
+		timeout:      timeout,
+		lastActivity: time.Now(),
+		input:        input,
+	}
+}
+
+func (l *idleLock) tick() tea.Cmd {
+	return tea.Tick(min(idleCheckInterval, l.timeout), func(t time.Time) tea.Msg {
+		return idleCheckMsg{}
+	})
+}
+
+func (l *idleLock) expired() bool {
+	return time.Since(l.lastActivity) >= l.timeout
+}
+
+// updateIdleLock tracks activity and, while locked, takes all key input.
+// It reports whether the message was consumed.
+func (m *Model) updateIdleLock(msg tea.Msg) (tea.Cmd, bool) {
+	l := m.idle
+
+	switch msg := msg.(type) {
+	case idleCheckMsg:
+		if !l.locked && m.conn != nil && l.expired() {
+			m.lockScreen()
+		}
+		return l.tick(), true
+
+	case unlockResultMsg:
+		l.verifying = false
+		if msg.err != nil {
+			l.err = msg.err
+			l.input.SetValue("")
+			return nil, true
+		}
+		l.locked = false
+		l.err = nil
+		l.input.SetValue("")
+		l.input.Blur()
+		l.lastActivity = time.Now()
+		return m.session.wrap(m.views[m.currentView].Init()), true
+
+	case tea.MouseMsg:
+		if !l.locked {
+			l.lastActivity = time.Now()
+		}
+		return nil, l.locked
+
+	case tea.KeyMsg:
+		if !l.locked {
+			l.lastActivity = time.Now()
+			return nil, false
+		}
+		switch msg.String() {
+		case "ctrl+c":
+			return nil, false
+		case "enter":
+			if !l.verifying {
+				l.verifying = true
+				l.err = nil
+				if !hasPassword(m.conn) {
+					return m.reconnectUnlock(), true
+				}
+				return m.verifyUnlock(l.input.Value()), true
+			}
+			return nil, true
+		}
+		if l.verifying || !hasPassword(m.conn) {
+			return nil, true
+		}
+		var cmd tea.Cmd
+		l.input, cmd = l.input.Update(msg)
+		return cmd, true
+	}
+
+	return nil, false
+}
+
+// lockScreen locks the app and drops every open view of every tab, so no
+// query results or row data stay in memory behind the lock screen
+func (m *Model) lockScreen() {
+	m.idle.locked = true
+	m.idle.err = nil
+	m.idle.input.SetValue("")
+	m.idle.input.Focus()
+
+	for _, s := range m.sessions {
+		if s.conn == nil {
+			s.views = map[ViewType]tea.Model{
+				ViewConnect: views.NewConnectView(m.cfg, s.connCfg, s.profile),
+			}
+			s.currentView = ViewConnect
+		} else {
+			s.views = map[ViewType]tea.Model{
+				ViewDatabases: views.NewDatabasesView(s.conn, m.width, m.height),
+			}
+			s.currentView = ViewDatabases
+		}
+		s.err = nil
+		s.statusMsg = ""
+	}
+}
+
+// hasPassword reports whether conn's session connected with a password the
+// unlock can be checked against
+func hasPassword(conn *db.Connection) bool {
+	return conn.Config.Password != ""
+}
+
+// verifyUnlock checks the password against the one the session connected with
+func (m *Model) verifyUnlock(password string) tea.Cmd {
+	expected := m.conn.Config.Password
+	return func() tea.Msg {
+		if subtle.ConstantTimeCompare([]byte(password), []byte(expected)) != 1 {
+			return unlockResultMsg{err: errors.New("wrong password")}
+		}
+		return unlockResultMsg{}
+	}
+}
+
+// reconnectUnlock unlocks a session without a password by logging in to the
+// server again with the settings it connected with, so the server's socket,
+// peer or trust authentication still has to accept whoever is at the
+// terminal. Whatever was typed is never used.
+func (m *Model) reconnectUnlock() tea.Cmd {
+	cfg := m.conn.Config
+	return func() tea.Msg {
+		conn, err := db.Connect(cfg)
+		if err != nil {
+			return unlockResultMsg{err: err}
+		}
+		conn.Close()
+		return unlockResultMsg{}
+	}
+}
+
+func (l *idleLock) view(m *Model) string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("YSM is locked"))
+	b.WriteString("\n\n")
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("Locked after %s without input. I kept everything safe for you~", l.timeout)))
+	b.WriteString("\n\n")
+	if hasPassword(m.conn) {
+		b.WriteString(fmt.Sprintf("Password for %s@%s:\n", m.conn.Config.User, m.conn.Config.Host))
+		b.WriteString(l.input.View())
+	} else {
+		b.WriteString(fmt.Sprintf("%s@%s connected without a password. Unlocking reconnects to check you still have access.", m.conn.Config.User, m.conn.Config.Host))
+	}
+	b.WriteString("\n\n")
+
+	if l.verifying {
+		b.WriteString(mutedStyle.Render("Checking..."))
+	} else if l.err != nil {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", l.err)))
+	} else if hasPassword(m.conn) {
+		b.WriteString(mutedStyle.Render("Enter: Unlock | Ctrl+C: Quit"))
+	} else {
+		b.WriteString(mutedStyle.Render("Enter: Reconnect and unlock | Ctrl+C: Quit"))
+	}
+
+	return boxStyle.Render(b.String())
+}
@@ -0,0 +1,196 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/blubskye/yandere_sql_manager/internal/tui/views"
+	"github.com/blubskye/yandere_sql_manager/internal/webhook"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// jobNotifier rings the bell or shows a desktop notification when a long
+// job ends while the user is looking elsewhere
+type jobNotifier struct {
+	bell    bool
+	desktop bool
+	after   time.Duration // Shorter jobs end without a notice
+}
+
+func newJobNotifier(cfg *config.NotifyConfig, after time.Duration) *jobNotifier {
+	return &jobNotifier{bell: cfg.Bell, desktop: cfg.Desktop, after: after}
+}
+
+// notify announces a job's end unless it was quick or the user watched it
+// finish: the terminal has focus and the job's view is on screen
+func (n *jobNotifier) notify(job views.JobResult, watching bool) tea.Cmd {
+	if watching || job.Elapsed < n.after {
+		return nil
+	}
+
+	title := "YSM: " + job.Title + " finished"
+	body := "Took " + progress.FormatDuration(job.Elapsed)
+	if job.Err != nil {
+		title = "YSM: " + job.Title + " failed"
+		body = job.Err.Error()
+	}
+
+	bell, desktop := n.bell, n.desktop
+	return func() tea.Msg {
+		if bell {
+			// BEL isn't drawn, so it can go straight to the terminal
+			fmt.Fprint(os.Stdout, "\a")
+		}
+		if desktop {
+			if err := desktopNotify(title, body); err != nil {
+				logging.Warn("Desktop notification failed: %v", err)
+			}
+		}
+		return nil
+	}
+}
+
+// sendWebhooks posts a finished job to the configured webhooks in the
+// background; failures are logged
+func (m *Model) sendWebhooks(event webhook.Event) tea.Cmd {
+	if len(m.cfg.Webhooks) == 0 || event.Operation == "" {
+		return nil
+	}
+	if event.Server == "" {
+		event.Server = m.serverName()
+	}
+
+	hooks := m.cfg.Webhooks
+	return func() tea.Msg {
+		if err := webhook.Send(hooks, event); err != nil {
+			logging.Warn("Webhook delivery failed: %v", err)
+		}
+		return nil
+	}
+}
+
+// serverName names the session's server for webhooks and the timeline: the
+// profile, or the host when connected without one
+func (m *Model) serverName() string {
+	if m.profile == "" && m.conn != nil {
+		return m.conn.Config.Host
+	}
+	return m.profile
+}
+
+// recordJob adds a finished job to the server's timeline in the background;
+// failures are logged
+func (m *Model) recordJob(job views.JobResult) tea.Cmd {
+	name := cmp.Or(job.Event.Server, m.serverName())
+	if name == "" {
+		return nil
+	}
+	event := db.OperationEvent(name, job.Event.Operation, job.Title, job.Elapsed, job.Err)
+	return func() tea.Msg {
+		if err := db.RecordTimelineEvent(event); err != nil {
+			logging.Warn("Recording the job on the timeline failed: %v", err)
+		}
+		return nil
+	}
+}
+
+// observeTimeline records the server's restarts and replication changes
+// since it was last checked, in the background
+func (m *Model) observeTimeline() tea.Cmd {
+	conn, name := m.conn, m.serverName()
+	if conn == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		if _, err := conn.ObserveTimeline(name); err != nil {
+			logging.Debug("Timeline check: %v", err)
+		}
+		return nil
+	}
+}
+
+// jobsKey opens the jobs view from any view once connected
+const jobsKey = "alt+j"
+
+// jobEndedStatus is the status bar message for a job that ended after the
+// user left its view
+func jobEndedStatus(job views.JobResult) string {
+	if job.Err != nil {
+		return errorStyle.Render(fmt.Sprintf("%s failed (Alt+J: jobs)", job.Title))
+	}
+	return successStyle.Render(fmt.Sprintf("%s finished in %s (Alt+J: jobs)", job.Title, progress.FormatDuration(job.Elapsed)))
+}
+
+// jobViews maps a job's view name to the view it runs in
+var jobViews = map[string]ViewType{
+	"import":      ViewImport,
+	"export":      ViewExport,
+	"backup":      ViewBackup,
+	"rebuild":     ViewRebuild,
+	"transfer":    ViewTransfer,
+	"clone":       ViewCloneMerge,
+	"sync":        ViewSync,
+	"maintenance": ViewTableDetail,
+}
+
+// watching reports whether the user can see a job's view right now
+func (m *Model) watching(view string) bool {
+	if m.blurred || (m.idle != nil && m.idle.locked) || !m.onScreen() {
+		return false
+	}
+	current, ok := jobViews[view]
+	return ok && current == m.currentView
+}
+
+// desktopNotify shows a notification with notify-send on Linux and the BSDs
+// or osascript on macOS
+func desktopNotify(title, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		return fmt.Errorf("desktop notifications aren't supported on Windows")
+	default:
+		cmd = exec.Command("notify-send", "--app-name=ysm", title, body)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %v %s", cmd.Path, err, out)
+	}
+	return nil
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
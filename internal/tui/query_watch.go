@@ -0,0 +1,106 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// processesKey jumps to the process list from any view once connected
+const processesKey = "ctrl+r"
+
+// queryWatch checks the process list in the background and keeps the
+// queries that have run too long, for the status bar to warn about
+type queryWatch struct {
+	after    time.Duration // Queries running this long are reported
+	interval time.Duration
+	checking bool
+	long     []db.Session // Longest first
+}
+
+type queryWatchTickMsg struct{}
+
+type queryWatchResultMsg struct {
+	conn     *db.Connection
+	sessions []db.Session
+	err      error
+}
+
+func newQueryWatch(after, interval time.Duration) *queryWatch {
+	return &queryWatch{after: after, interval: interval}
+}
+
+func (w *queryWatch) tick() tea.Cmd {
+	return tea.Tick(w.interval, func(t time.Time) tea.Msg {
+		return queryWatchTickMsg{}
+	})
+}
+
+// updateQueryWatch polls on each tick while connected. Results from a
+// connection that has since been replaced are dropped.
+func (m *Model) updateQueryWatch(msg tea.Msg) tea.Cmd {
+	w := m.queryWatch
+
+	switch msg := msg.(type) {
+	case queryWatchTickMsg:
+		if m.conn == nil || w.checking {
+			return w.tick()
+		}
+		w.checking = true
+		conn := m.conn
+		return tea.Batch(w.tick(), func() tea.Msg {
+			sessions, err := conn.RunningQueries()
+			return queryWatchResultMsg{conn: conn, sessions: sessions, err: err}
+		})
+
+	case queryWatchResultMsg:
+		w.checking = false
+		if msg.conn != m.conn {
+			return nil
+		}
+		if msg.err != nil {
+			logging.Debug("Query watch: %v", msg.err)
+			w.long = nil
+			return nil
+		}
+		w.long = db.LongRunning(msg.sessions, w.after)
+	}
+	return nil
+}
+
+// warning is the status bar notice, empty while no query runs too long
+func (w *queryWatch) warning() string {
+	if len(w.long) == 0 {
+		return ""
+	}
+	after := progress.FormatDuration(w.after)
+	what := fmt.Sprintf("%d queries running over %s", len(w.long), after)
+	if len(w.long) == 1 {
+		what = "1 query running over " + after
+	}
+	longest := w.long[0]
+	return fmt.Sprintf("⚠ %s, longest %s by %s | %s: Processes",
+		what, progress.FormatDuration(longest.Duration), longest.User, processesKey)
+}
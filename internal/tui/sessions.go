@@ -0,0 +1,295 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/alert"
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+	"github.com/blubskye/yandere_sql_manager/internal/tui/views"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Tab keys. Terminals don't send ctrl+digit, so alt+1..9 switch tabs.
+const (
+	newTabKey   = "alt+t"
+	closeTabKey = "alt+w"
+)
+
+// session is one tab: a connection and the views working on it. The model
+// embeds the session on screen.
+type session struct {
+	id int
+
+	conn    *db.Connection
+	connCfg *db.ConnectionConfig
+	profile string // Profile used for the connection (empty if none)
+
+	currentView ViewType
+	views       map[ViewType]tea.Model
+
+	err       error
+	statusMsg string
+
+	metrics *db.MetricsCollector // Dashboard trends, kept across view switches
+	alerts  *alert.Monitor       // Health alerts (nil when not configured)
+
+	queryWatch *queryWatch  // Long-running query warnings (nil when disabled)
+	health     *healthWatch // Server ping for the status bar (nil when not shown)
+}
+
+// sessionMsg carries a message back to the session whose command produced
+// it, so a tab in the background still gets its results
+type sessionMsg struct {
+	id  int
+	msg tea.Msg
+}
+
+// newSession adds a tab starting on the connect view
+func (m *Model) newSession(connCfg *db.ConnectionConfig, profileName string) *session {
+	m.nextSessionID++
+	s := &session{
+		id:          m.nextSessionID,
+		connCfg:     connCfg,
+		profile:     profileName,
+		currentView: ViewConnect,
+		views:       make(map[ViewType]tea.Model),
+	}
+	s.views[ViewConnect] = views.NewConnectView(m.cfg, connCfg, profileName)
+
+	if m.cfg.QueryWatch != nil {
+		after, interval, err := m.cfg.QueryWatchTimes()
+		if err != nil {
+			logging.Warn("Using default query watch times: %v", err)
+		}
+		s.queryWatch = newQueryWatch(after, interval)
+	}
+	if m.healthInterval > 0 {
+		s.health = newHealthWatch(m.healthInterval)
+	}
+
+	m.sessions = append(m.sessions, s)
+	return s
+}
+
+// wrap tags the message a command produces with the session
+func (s *session) wrap(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	id := s.id
+	return func() tea.Msg {
+		msg := cmd()
+		if msg == nil {
+			return nil
+		}
+		return sessionMsg{id: id, msg: msg}
+	}
+}
+
+// close stops everything tied to the session's connection
+func (s *session) close() {
+	if s.metrics != nil {
+		s.metrics.Stop()
+	}
+	if s.alerts != nil {
+		s.alerts.Stop()
+	}
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
+
+// label names the tab after its profile or server
+func (s *session) label() string {
+	switch {
+	case s.profile != "":
+		return s.profile
+	case s.conn != nil:
+		return fmt.Sprintf("%s:%d", s.conn.Config.Host, s.conn.Config.Port)
+	default:
+		return "new"
+	}
+}
+
+// updateSession handles a message for the session that asked for it, which
+// may not be the one on screen
+func (m *Model) updateSession(msg sessionMsg) (tea.Model, tea.Cmd) {
+	var s *session
+	for _, candidate := range m.sessions {
+		if candidate.id == msg.id {
+			s = candidate
+		}
+	}
+	if s == nil {
+		// The tab has been closed
+		return m, nil
+	}
+
+	switch inner := msg.msg.(type) {
+	case tea.BatchMsg:
+		cmds := make([]tea.Cmd, len(inner))
+		for i, cmd := range inner {
+			cmds[i] = s.wrap(cmd)
+		}
+		return m, tea.Batch(cmds...)
+	case tea.QuitMsg:
+		// Quitting a view closes its tab while others are open
+		if len(m.sessions) == 1 {
+			return m, m.quitUnlessJobsRun()
+		}
+		return m, m.closeSession(s)
+	}
+
+	active := m.session
+	m.session = s
+	model, cmd := m.update(msg.msg)
+	m.session = active
+	return model, cmd
+}
+
+// quitWarnWindow is how long after a warning about running jobs quitting
+// again goes through
+const quitWarnWindow = 5 * time.Second
+
+// quitUnlessJobsRun quits, unless jobs are running and the user hasn't just
+// been warned that quitting stops them
+func (m *Model) quitUnlessJobsRun() tea.Cmd {
+	running := len(views.RunningJobs())
+	if running > 0 && time.Since(m.quitWarned) > quitWarnWindow {
+		m.quitWarned = time.Now()
+		m.statusMsg = errorStyle.Render(fmt.Sprintf("%d jobs still running (Alt+J: jobs); quit again to stop them", running))
+		return nil
+	}
+	return m.quit()
+}
+
+// onScreen reports whether the session being updated is the visible tab
+func (m *Model) onScreen() bool {
+	return m.session == m.sessions[m.active]
+}
+
+// quit closes every session and ends the program
+func (m *Model) quit() tea.Cmd {
+	m.quitting = true
+	for _, s := range m.sessions {
+		s.close()
+	}
+	return tea.Quit
+}
+
+// openTab adds a tab on the connect view and switches to it
+func (m *Model) openTab() tea.Cmd {
+	s := m.newSession(nil, "")
+	m.active = len(m.sessions) - 1
+	m.session = s
+
+	cmds := []tea.Cmd{s.wrap(s.views[ViewConnect].Init()), m.resizeView()}
+	if s.queryWatch != nil {
+		cmds = append(cmds, s.wrap(s.queryWatch.tick()))
+	}
+	if s.health != nil {
+		cmds = append(cmds, s.wrap(s.health.tick()))
+	}
+	return tea.Batch(cmds...)
+}
+
+// closeSession closes a tab and shows its neighbour when it was on screen
+func (m *Model) closeSession(s *session) tea.Cmd {
+	s.close()
+	for i, candidate := range m.sessions {
+		if candidate == s {
+			m.sessions = append(m.sessions[:i], m.sessions[i+1:]...)
+			if i < m.active || m.active == len(m.sessions) {
+				m.active--
+			}
+			break
+		}
+	}
+	if m.session == s {
+		m.session = m.sessions[m.active]
+		return m.resizeView()
+	}
+	return nil
+}
+
+// switchTab shows the i-th tab, counting from 0
+func (m *Model) switchTab(i int) tea.Cmd {
+	if i < 0 || i >= len(m.sessions) || i == m.active {
+		return nil
+	}
+	m.active = i
+	m.session = m.sessions[i]
+	return m.resizeView()
+}
+
+// resizeView gives the view on screen the current terminal size, which may
+// have changed while its tab was in the background
+func (m *Model) resizeView() tea.Cmd {
+	view, ok := m.views[m.currentView]
+	if !ok {
+		return nil
+	}
+	newView, cmd := view.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	m.views[m.currentView] = newView
+	return m.session.wrap(cmd)
+}
+
+// tabIndex returns the tab a key switches to
+func tabIndex(key string) (int, bool) {
+	for _, prefix := range []string{"alt+", "ctrl+"} {
+		if digit, ok := strings.CutPrefix(key, prefix); ok && len(digit) == 1 && digit >= "1" && digit <= "9" {
+			return int(digit[0] - '1'), true
+		}
+	}
+	return 0, false
+}
+
+// transferTargets lists the connected tabs a transfer can copy into
+func (m *Model) transferTargets() []views.TransferTarget {
+	var targets []views.TransferTarget
+	for i, s := range m.sessions {
+		if s.conn == nil {
+			continue
+		}
+		name := fmt.Sprintf("%d:%s", i+1, s.label())
+		if s == m.session {
+			name += " (this tab)"
+		}
+		targets = append(targets, views.TransferTarget{Name: name, Conn: s.conn})
+	}
+	return targets
+}
+
+// renderTabs lists the tabs for the status bar, marking the one on screen
+func (m *Model) renderTabs() string {
+	var b strings.Builder
+	for i, s := range m.sessions {
+		if i == m.active {
+			fmt.Fprintf(&b, "[%d:%s] ", i+1, s.label())
+		} else {
+			fmt.Fprintf(&b, " %d:%s  ", i+1, s.label())
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,265 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/blubskye/yandere_sql_manager/internal/tui/views"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// healthWatch pings the session's server in the background so the status
+// bar can show whether it still answers, and how fast
+type healthWatch struct {
+	interval time.Duration
+	checking bool
+
+	conn    *db.Connection // Connection the results below are for
+	latency time.Duration
+	err     error
+	version string // Server version, fetched once per connection
+}
+
+type healthTickMsg struct{}
+
+type healthResultMsg struct {
+	conn    *db.Connection
+	latency time.Duration
+	err     error
+	version string
+}
+
+func newHealthWatch(interval time.Duration) *healthWatch {
+	return &healthWatch{interval: interval}
+}
+
+func (w *healthWatch) tick() tea.Cmd {
+	return tea.Tick(w.interval, func(t time.Time) tea.Msg {
+		return healthTickMsg{}
+	})
+}
+
+// check pings the connection right away, without waiting for the next tick
+func (w *healthWatch) check(conn *db.Connection) tea.Cmd {
+	if conn == nil || w.checking {
+		return nil
+	}
+	w.checking = true
+	needVersion := w.conn != conn || w.version == ""
+	return func() tea.Msg {
+		start := time.Now()
+		err := conn.HealthCheck()
+		result := healthResultMsg{conn: conn, latency: time.Since(start), err: err}
+		if err == nil && needVersion {
+			if version, err := conn.GetServerVersion(); err != nil {
+				logging.Debug("Status bar version: %v", err)
+			} else {
+				result.version = version
+			}
+		}
+		return result
+	}
+}
+
+// updateHealthWatch pings on each tick while connected. Results from a
+// connection that has since been replaced are dropped.
+func (m *Model) updateHealthWatch(msg tea.Msg) tea.Cmd {
+	w := m.health
+
+	switch msg := msg.(type) {
+	case healthTickMsg:
+		return tea.Batch(w.tick(), w.check(m.conn))
+
+	case healthResultMsg:
+		w.checking = false
+		if msg.conn != m.conn {
+			return nil
+		}
+		if w.conn != msg.conn {
+			w.conn, w.version = msg.conn, ""
+		}
+		w.latency, w.err = msg.latency, msg.err
+		if msg.version != "" {
+			w.version = msg.version
+		}
+	}
+	return nil
+}
+
+// status is the health item: the ping round trip, or down
+func (w *healthWatch) status(conn *db.Connection, compact bool) string {
+	switch {
+	case w.conn != conn:
+		return "● …"
+	case w.err != nil:
+		if compact {
+			return errorStyle.Render("✖")
+		}
+		return errorStyle.Render("✖ down")
+	}
+	if w.latency < time.Second {
+		return fmt.Sprintf("● %dms", w.latency.Milliseconds())
+	}
+	return "● " + progress.FormatDuration(w.latency)
+}
+
+// versionRe finds the version number in VERSION() output of either server
+var versionRe = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// serverType names the server and, once known, its version
+func (m *Model) serverType(compact bool) string {
+	name := "MariaDB"
+	if m.conn.Config.Type == db.DatabaseTypePostgres {
+		name = "PostgreSQL"
+	}
+	if compact || m.health == nil || m.health.conn != m.conn {
+		return name
+	}
+	if version := versionRe.FindString(m.health.version); version != "" {
+		name += " " + version
+	}
+	return name
+}
+
+// jobStatus is the job item: the oldest running job and how many more run
+func jobStatus(compact bool) string {
+	jobs := views.RunningJobs()
+	if len(jobs) == 0 {
+		return ""
+	}
+	job := jobs[0]
+	done := progress.FormatDuration(job.Elapsed)
+	if job.Fraction >= 0 {
+		done = fmt.Sprintf("%.0f%%", job.Fraction*100)
+	}
+	status := "⏳ " + done
+	if !compact {
+		status = fmt.Sprintf("⏳ %s %s", job.Label, done)
+	}
+	if len(jobs) > 1 {
+		status += fmt.Sprintf(" +%d", len(jobs)-1)
+	}
+	return status
+}
+
+// renderTabsCompact shows only the tab on screen and the number of tabs
+func (m *Model) renderTabsCompact() string {
+	return fmt.Sprintf("[%d/%d:%s]", m.active+1, len(m.sessions), m.label())
+}
+
+// statusItem renders one configured status bar item, empty when it has
+// nothing to show
+func (m *Model) statusItem(item string, compact bool) string {
+	if item == config.StatusTabs {
+		switch {
+		case len(m.sessions) < 2:
+			return ""
+		case compact:
+			return m.renderTabsCompact()
+		}
+		return strings.TrimSpace(m.renderTabs())
+	}
+	if item == config.StatusJob {
+		return jobStatus(compact)
+	}
+	if m.conn == nil {
+		return ""
+	}
+
+	cfg := m.conn.Config
+	switch item {
+	case config.StatusProfile:
+		if m.profile == "" || compact {
+			return m.profile
+		}
+		return "Profile: " + m.profile
+	case config.StatusServer:
+		if compact {
+			return cfg.Host
+		}
+		return fmt.Sprintf("%s@%s:%d", cfg.User, cfg.Host, cfg.Port)
+	case config.StatusType:
+		return m.serverType(compact)
+	case config.StatusDatabase:
+		name := cfg.Database
+		if name == "" {
+			name = "(none)"
+		}
+		if compact {
+			return name
+		}
+		return "DB: " + name
+	case config.StatusHealth:
+		if m.health == nil {
+			return ""
+		}
+		return m.health.status(m.conn, compact)
+	case config.StatusConnections:
+		if compact {
+			return fmt.Sprintf("%dc", db.OpenConnections())
+		}
+		return fmt.Sprintf("Conns: %d", db.OpenConnections())
+	}
+	return ""
+}
+
+// renderStatusBar shows the configured items, then any error, status
+// message and long query warning, on one line
+func (m *Model) renderStatusBar() string {
+	var parts []string
+	for _, item := range m.statusItems {
+		if text := m.statusItem(item, m.compact); text != "" {
+			parts = append(parts, text)
+		}
+	}
+
+	if m.err != nil {
+		parts = append(parts, errorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+	} else if m.statusMsg != "" {
+		parts = append(parts, m.statusMsg)
+	}
+	if m.queryWatch != nil {
+		if warning := m.queryWatch.warning(); warning != "" {
+			parts = append(parts, errorStyle.Render(warning))
+		}
+	}
+
+	return statusBarStyle.Width(m.width).MaxHeight(1).Render(strings.Join(parts, " | "))
+}
+
+// wantsHealth reports whether a status bar item needs the server pinged
+func wantsHealth(items []string) bool {
+	return slices.Contains(items, config.StatusHealth) || slices.Contains(items, config.StatusType)
+}
+
+// setLayout picks the compact or normal layout for the terminal width
+func (m *Model) setLayout() {
+	// An invalid setting falls back to auto; New has already warned
+	m.compact, _ = m.cfg.CompactLayout(m.width)
+	views.SetCompact(m.compact)
+}
@@ -18,112 +18,81 @@
 
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+	"github.com/blubskye/yandere_sql_manager/internal/theme"
+	"github.com/blubskye/yandere_sql_manager/internal/tui/views"
+	"github.com/charmbracelet/lipgloss"
+)
 
+// Styles of the application frame, set from the active theme by applyStyles
 var (
-	// Colors - a pink/magenta theme for the yandere aesthetic
-	primaryColor   = lipgloss.Color("#FF69B4") // Hot pink
-	secondaryColor = lipgloss.Color("#FF1493") // Deep pink
-	accentColor    = lipgloss.Color("#FFB6C1") // Light pink
-	bgColor        = lipgloss.Color("#1a1a2e") // Dark blue-ish background
-	textColor      = lipgloss.Color("#FFFFFF")
-	mutedColor     = lipgloss.Color("#888888")
-	errorColor     = lipgloss.Color("#FF4444")
-	successColor   = lipgloss.Color("#44FF44")
-
-	// Base styles
-	baseStyle = lipgloss.NewStyle().
-			Foreground(textColor)
-
 	// Title style
-	titleStyle = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Bold(true).
-			Padding(0, 1)
+	titleStyle lipgloss.Style
 
 	// Subtitle style
-	subtitleStyle = lipgloss.NewStyle().
-			Foreground(accentColor).
-			Italic(true)
-
-	// Selected item style
-	selectedStyle = lipgloss.NewStyle().
-			Foreground(textColor).
-			Background(primaryColor).
-			Bold(true).
-			Padding(0, 1)
-
-	// Normal item style
-	itemStyle = lipgloss.NewStyle().
-			Foreground(textColor).
-			Padding(0, 1)
+	subtitleStyle lipgloss.Style
 
 	// Muted text style
-	mutedStyle = lipgloss.NewStyle().
-			Foreground(mutedColor)
+	mutedStyle lipgloss.Style
 
 	// Error style
-	errorStyle = lipgloss.NewStyle().
-			Foreground(errorColor).
-			Bold(true)
+	errorStyle lipgloss.Style
 
 	// Success style
-	successStyle = lipgloss.NewStyle().
-			Foreground(successColor).
-			Bold(true)
+	successStyle lipgloss.Style
 
 	// Box style for panels
-	boxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(primaryColor).
-			Padding(1, 2)
-
-	// Active box style
-	activeBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(secondaryColor).
-			Padding(1, 2)
-
-	// Help style
-	helpStyle = lipgloss.NewStyle().
-			Foreground(mutedColor).
-			Padding(1, 0)
+	boxStyle lipgloss.Style
+
+	// Help key style
+	helpKeyStyle lipgloss.Style
 
 	// Status bar style
-	statusBarStyle = lipgloss.NewStyle().
-			Foreground(textColor).
-			Background(primaryColor).
-			Padding(0, 1)
-
-	// Input style
-	inputStyle = lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder()).
-			BorderForeground(primaryColor).
-			Padding(0, 1)
-
-	// Focused input style
-	focusedInputStyle = lipgloss.NewStyle().
-				Border(lipgloss.NormalBorder()).
-				BorderForeground(secondaryColor).
-				Padding(0, 1)
-
-	// Table header style
-	tableHeaderStyle = lipgloss.NewStyle().
-				Foreground(primaryColor).
-				Bold(true).
-				Padding(0, 1)
-
-	// Table cell style
-	tableCellStyle = lipgloss.NewStyle().
-			Foreground(textColor).
-			Padding(0, 1)
+	statusBarStyle lipgloss.Style
 
 	// Logo/banner
-	bannerStyle = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Bold(true)
+	bannerStyle lipgloss.Style
 )
 
+func init() {
+	applyStyles()
+}
+
+// applyStyles rebuilds the frame styles from the active theme
+func applyStyles() {
+	s := theme.Current()
+
+	titleStyle = s.Title.Padding(0, 1)
+	subtitleStyle = s.Subtitle
+	mutedStyle = s.MutedText
+	errorStyle = s.ErrorText.Bold(true)
+	successStyle = s.SuccessText.Bold(true)
+	boxStyle = s.Box.Padding(1, 2)
+	helpKeyStyle = lipgloss.NewStyle().Foreground(s.Primary).Bold(true)
+	statusBarStyle = s.Selected.UnsetBold().Padding(0, 1)
+	bannerStyle = s.Title
+}
+
+// useTheme makes the configured theme the active one for the frame and the
+// views. An unknown or broken theme is logged and the default kept.
+func useTheme(name string) {
+	dir, err := config.ThemesDir()
+	if err != nil {
+		logging.Warn("Using the default theme: %v", err)
+		return
+	}
+	t, err := theme.Find(dir, name)
+	if err != nil {
+		logging.Warn("Using the default theme: %v", err)
+		return
+	}
+	theme.Use(t)
+	applyStyles()
+	views.ApplyTheme()
+}
+
 // Logo returns the YSM logo
 func Logo() string {
 	return bannerStyle.Render(`
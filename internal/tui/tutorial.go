@@ -0,0 +1,154 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tutorial keys, handled by the app before the current view sees them
+const (
+	tutorialNextKey   = "ctrl+g"
+	tutorialToggleKey = "ctrl+x"
+)
+
+// tutorialStep is one stage of the guided demo. A step is done once the
+// user reaches its view, or presses the next key.
+type tutorialStep struct {
+	title string
+	lines []string
+	done  ViewType // View that completes the step (-1 = manual only)
+}
+
+// tutorial walks a new user through the core flows on the demo database
+type tutorial struct {
+	steps   []tutorialStep
+	current int
+	hidden  bool
+}
+
+func newTutorial(database string) *tutorial {
+	return &tutorial{
+		steps: []tutorialStep{
+			{
+				title: "Browse",
+				lines: []string{
+					fmt.Sprintf("Select %s in the database list and press Enter.", database),
+				},
+				done: ViewTables,
+			},
+			{
+				title: "Browse",
+				lines: []string{
+					"Pick the customers table and press Enter to see its rows.",
+				},
+				done: ViewBrowser,
+			},
+			{
+				title: "Browse",
+				lines: []string{
+					"Tab moves between columns, s sorts, / filters (try >10 on id).",
+					"Enter edits a row, n inserts one. When you're done, press Esc twice",
+					"to get back to the tables, then s to open the query editor.",
+				},
+				done: ViewQuery,
+			},
+			{
+				title: "Query",
+				lines: []string{
+					"Type a query and run it, for example:",
+					"  SELECT status, COUNT(*), SUM(total) FROM orders GROUP BY status",
+					"Then press Esc until you're back at the database list.",
+				},
+				done: ViewDatabases,
+			},
+			{
+				title: "Export",
+				lines: []string{
+					fmt.Sprintf("Select %s and press e to export it to a .sql file.", database),
+				},
+				done: ViewExport,
+			},
+			{
+				title: "Backup",
+				lines: []string{
+					"Choose a file name and press Enter to export. Afterwards go back",
+					"to the database list and press b to open backups.",
+				},
+				done: ViewBackup,
+			},
+			{
+				title: "Backup & Restore",
+				lines: []string{
+					fmt.Sprintf("Press c, select %s and press Enter to create a backup.", database),
+					"Then select the backup and press r to restore it. Restoring over",
+					fmt.Sprintf("%s is safe, it only holds sample data.", database),
+				},
+				done: -1,
+			},
+			{
+				title: "Done",
+				lines: []string{
+					"That's the tour! Keep exploring the demo data as much as you like.",
+					"Remove it later with: ysm demo --drop",
+				},
+				done: -1,
+			},
+		},
+	}
+}
+
+// observe advances the tutorial when the user reaches the current step's view
+func (t *tutorial) observe(view ViewType) {
+	if t.current < len(t.steps) && t.steps[t.current].done == view {
+		t.current++
+	}
+}
+
+// next skips to the following step
+func (t *tutorial) next() {
+	if t.current < len(t.steps)-1 {
+		t.current++
+	}
+}
+
+// view renders the guide overlay
+func (t *tutorial) view(width int) string {
+	if t.hidden {
+		return mutedStyle.Render(fmt.Sprintf(" Demo guide hidden - %s to show", tutorialToggleKey))
+	}
+
+	step := t.steps[min(t.current, len(t.steps)-1)]
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Demo %d/%d: %s", min(t.current+1, len(t.steps)), len(t.steps), step.title)))
+	for _, line := range step.lines {
+		b.WriteString("\n")
+		b.WriteString(line)
+	}
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("%s: next step | %s: hide guide", tutorialNextKey, tutorialToggleKey)))
+
+	style := boxStyle.Padding(0, 1)
+	if width > 4 {
+		style = style.Width(width - 2)
+	}
+	return style.Render(b.String())
+}
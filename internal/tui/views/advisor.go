@@ -0,0 +1,264 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// AdvisorView lists what the maintenance advisor recommends for a database
+// and applies the fixes
+type AdvisorView struct {
+	conn     *db.Connection
+	database string
+	report   *db.AdvisorReport
+	cursor   int
+	loading  bool
+	confirm  *db.Advice // Waiting for y before applying its fix
+	applying string     // Subject of the fix being applied
+	message  string
+	err      error
+	width    int
+	height   int
+}
+
+type advisorLoadedMsg struct {
+	report *db.AdvisorReport
+	err    error
+}
+
+type advisorAppliedMsg struct {
+	advice db.Advice
+	err    error
+}
+
+// NewAdvisorView creates a new maintenance advisor for a database
+func NewAdvisorView(conn *db.Connection, database string, width, height int) *AdvisorView {
+	return &AdvisorView{
+		conn:     conn,
+		database: database,
+		loading:  true,
+		width:    width,
+		height:   height,
+	}
+}
+
+// Init initializes the view
+func (v *AdvisorView) Init() tea.Cmd {
+	return v.load
+}
+
+func (v *AdvisorView) load() tea.Msg {
+	if err := v.conn.UseDatabase(v.database); err != nil {
+		return advisorLoadedMsg{err: err}
+	}
+	report, err := v.conn.Advise()
+	return advisorLoadedMsg{report: report, err: err}
+}
+
+func (v *AdvisorView) apply(a db.Advice) tea.Cmd {
+	return func() tea.Msg {
+		return advisorAppliedMsg{advice: a, err: v.conn.ApplyAdvice(a)}
+	}
+}
+
+// advice returns the advice listed, if any
+func (v *AdvisorView) advice() []db.Advice {
+	if v.report == nil {
+		return nil
+	}
+	return v.report.Advice
+}
+
+// Update handles messages
+func (v *AdvisorView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+
+	case advisorLoadedMsg:
+		v.loading = false
+		v.err = msg.err
+		if msg.report != nil {
+			v.report = msg.report
+		}
+		v.cursor = min(v.cursor, max(len(v.advice())-1, 0))
+
+	case advisorAppliedMsg:
+		v.applying = ""
+		if msg.err != nil {
+			v.err = msg.err
+			return v, nil
+		}
+		v.message = fmt.Sprintf("Fixed %s (%s)", msg.advice.Subject(), msg.advice.Check)
+		v.loading = true
+		return v, v.load
+
+	case tea.KeyMsg:
+		return v.updateKeys(msg)
+	}
+	return v, nil
+}
+
+func (v *AdvisorView) updateKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if v.confirm != nil {
+		a := *v.confirm
+		v.confirm = nil
+		if msg.String() == "y" {
+			v.applying = a.Subject()
+			return v, v.apply(a)
+		}
+		return v, nil
+	}
+
+	advice := v.advice()
+	switch msg.String() {
+	case "up", "k":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down", "j":
+		if v.cursor < len(advice)-1 {
+			v.cursor++
+		}
+	case "f":
+		if v.applying == "" && v.cursor < len(advice) {
+			v.err = nil
+			v.message = ""
+			a := advice[v.cursor]
+			if a.Manual {
+				v.err = fmt.Errorf("this fix is a template: fill in its <placeholders> in the query editor (s in the tables list)")
+				return v, nil
+			}
+			v.confirm = &a
+		}
+	case "r":
+		if !v.loading && v.applying == "" {
+			v.loading = true
+			v.message = ""
+			return v, v.load
+		}
+	case "esc", "backspace":
+		return v, func() tea.Msg {
+			return SwitchViewMsg{View: "tables", Database: v.database}
+		}
+	case "q":
+		return v, tea.Quit
+	}
+	return v, nil
+}
+
+// View renders the view
+func (v *AdvisorView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Maintenance Advisor"))
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("  (%s)", v.database)))
+	b.WriteString("\n\n")
+
+	if v.loading && v.report == nil {
+		b.WriteString("Scanning the database...\n")
+		return b.String()
+	}
+
+	advice := v.advice()
+	if v.report != nil && len(advice) == 0 {
+		b.WriteString(successStyle.Render("Nothing to fix~ this database is taken good care of <3"))
+		b.WriteString("\n")
+	}
+	if len(advice) > 0 {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("%d high, %d medium, %d low",
+			v.report.Count(db.AdviceHigh), v.report.Count(db.AdviceMedium), v.report.Count(db.AdviceLow))))
+		b.WriteString("\n")
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-8s %-18s %s", "Priority", "Check", "Object")))
+		b.WriteString("\n")
+
+		// Keep the cursor on screen, leaving room for the selected advice
+		visible := max(v.height-22, 5)
+		start := 0
+		if v.cursor >= visible {
+			start = v.cursor - visible + 1
+		}
+		end := min(start+visible, len(advice))
+
+		for i := start; i < end; i++ {
+			a := advice[i]
+			line := fmt.Sprintf("%-8s %-18s %s", a.Priority, a.Check, truncateRunes(a.Subject(), max(v.width-32, 20)))
+			switch {
+			case i == v.cursor:
+				b.WriteString(selectedStyle.Render("> " + line))
+			case a.Priority == db.AdviceHigh:
+				b.WriteString(errorStyle.Render("  " + line))
+			case a.Priority == db.AdviceMedium:
+				b.WriteString(focusedStyle.Render("  " + line))
+			default:
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+
+		a := advice[min(v.cursor, len(advice)-1)]
+		wrap := lipgloss.NewStyle().Width(max(v.width-4, 40))
+		b.WriteString(wrap.Render(a.Problem))
+		b.WriteString("\n")
+		if a.Manual {
+			b.WriteString(focusedStyle.Render("Template: fill in the <placeholders> before running it"))
+			b.WriteString("\n")
+		}
+		b.WriteString(mutedStyle.Render(a.Fix))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	switch {
+	case v.applying != "":
+		b.WriteString(focusedStyle.Render(fmt.Sprintf("Fixing %s...", v.applying)))
+		b.WriteString("\n\n")
+	case v.confirm != nil:
+		b.WriteString(headerStyle.Render(fmt.Sprintf("Run the fix for %s?", v.confirm.Subject())))
+		b.WriteString("\n")
+		b.WriteString(focusedStyle.Render("  It may lock or rewrite the table while it runs"))
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render("Proceed? (y/n)"))
+		b.WriteString("\n\n")
+	case v.err != nil:
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	case v.message != "":
+		b.WriteString(successStyle.Render(v.message))
+		b.WriteString("\n\n")
+	}
+
+	if v.report != nil {
+		for _, s := range v.report.Skipped {
+			b.WriteString(mutedStyle.Render("Skipped " + s))
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString(helpStyle.Render("↑↓: Navigate | f: Apply fix | r: Rescan | Esc: Back | q: Quit"))
+
+	return b.String()
+}
@@ -0,0 +1,215 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// AuditView browses the audit log of destructive actions, newest first:
+// dropped databases and users, revoked privileges, restores and imports,
+// with who ran them and from where
+type AuditView struct {
+	conn   *db.Connection
+	width  int
+	height int
+
+	entries    []db.AuditEntry // Newest first
+	cursor     int
+	action     string // Only entries of this action, "" for all
+	allServers bool   // Show entries for every server, not only this one
+	loading    bool
+	err        error
+}
+
+type auditLoadedMsg struct {
+	entries []db.AuditEntry
+	err     error
+}
+
+// NewAuditView creates a new audit log view
+func NewAuditView(conn *db.Connection, width, height int) *AuditView {
+	return &AuditView{conn: conn, width: width, height: height, loading: true}
+}
+
+// Init loads the audit log
+func (v *AuditView) Init() tea.Cmd {
+	return v.load
+}
+
+func (v *AuditView) load() tea.Msg {
+	entries, err := db.LoadAuditLog()
+	slices.Reverse(entries)
+	return auditLoadedMsg{entries: entries, err: err}
+}
+
+// Update handles messages
+func (v *AuditView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+
+	case auditLoadedMsg:
+		v.loading = false
+		v.entries, v.err = msg.entries, msg.err
+		v.cursor = min(v.cursor, max(len(v.filtered())-1, 0))
+
+	case tea.KeyMsg:
+		entries := v.filtered()
+		switch msg.String() {
+		case "up", "k":
+			if v.cursor > 0 {
+				v.cursor--
+			}
+		case "down", "j":
+			if v.cursor < len(entries)-1 {
+				v.cursor++
+			}
+		case "pgup":
+			v.cursor = max(v.cursor-v.pageSize(), 0)
+		case "pgdown":
+			v.cursor = max(min(v.cursor+v.pageSize(), len(entries)-1), 0)
+		case "f":
+			// Cycle through the actions, then back to all of them
+			i := slices.Index(db.AuditActions, v.action)
+			if i == len(db.AuditActions)-1 {
+				v.action = ""
+			} else {
+				v.action = db.AuditActions[i+1]
+			}
+			v.cursor = 0
+		case "a":
+			v.allServers = !v.allServers
+			v.cursor = 0
+		case "r":
+			if !v.loading {
+				v.loading = true
+				return v, v.load
+			}
+		case "esc", "backspace":
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "databases"}
+			}
+		}
+	}
+	return v, nil
+}
+
+// filtered returns the entries of the selected action and server
+func (v *AuditView) filtered() []db.AuditEntry {
+	server := v.conn.ServerKey()
+	var entries []db.AuditEntry
+	for _, e := range v.entries {
+		if v.action != "" && e.Action != v.action {
+			continue
+		}
+		if !v.allServers && e.Server != server {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func (v *AuditView) pageSize() int {
+	return max(v.height-18, 5)
+}
+
+// View renders the view
+func (v *AuditView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Audit Log"))
+	b.WriteString("\n")
+	action := "all actions"
+	if v.action != "" {
+		action = v.action
+	}
+	server := v.conn.ServerKey()
+	if v.allServers {
+		server = "every server"
+	}
+	b.WriteString(subtitleStyle.Render(fmt.Sprintf("%s on %s, newest first", action, server)))
+	b.WriteString("\n\n")
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	entries := v.filtered()
+	switch {
+	case v.loading && v.entries == nil:
+		b.WriteString(mutedStyle.Render("Loading the audit log..."))
+		b.WriteString("\n\n")
+	case len(entries) == 0:
+		b.WriteString(mutedStyle.Render("Nothing recorded yet. Dropped databases and users, revoked privileges, restores and imports show up here."))
+		b.WriteString("\n\n")
+	default:
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-19s %-12s %-14s %s", "Time", "By", "Action", "Target")))
+		b.WriteString("\n")
+
+		// Keep the cursor on screen
+		visible := v.pageSize()
+		start := 0
+		if v.cursor >= visible {
+			start = v.cursor - visible + 1
+		}
+		end := min(start+visible, len(entries))
+
+		targetWidth := max(v.width-52, 20)
+		for i := start; i < end; i++ {
+			e := entries[i]
+			line := fmt.Sprintf("%-19s %-12s %-14s %s", e.Time.Format("2006-01-02 15:04:05"), truncateRunes(e.OSUser, 12), e.Action, truncateRunes(e.Target, targetWidth))
+			switch {
+			case i == v.cursor:
+				b.WriteString(selectedStyle.Render("> " + line))
+			case e.Failed():
+				b.WriteString(errorStyle.Render("  " + line))
+			default:
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+
+		e := entries[v.cursor]
+		width := max(v.width-4, 40)
+		b.WriteString(mutedStyle.Render(truncateRunes(fmt.Sprintf("%s on %s as %s to %s", e.OSUser, e.Machine, e.DBUser, e.Server), width)))
+		b.WriteString("\n")
+		if e.Detail != "" {
+			b.WriteString(mutedStyle.Render(truncateRunes(e.Detail, width)))
+			b.WriteString("\n")
+		}
+		if e.Failed() {
+			b.WriteString(errorStyle.Render(truncateRunes("Failed: "+e.Error, width)))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(helpStyle.Render("↑↓: Navigate | f: Filter by action | a: This/every server | r: Reload | Esc: Back"))
+	return b.String()
+}
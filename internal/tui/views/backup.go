@@ -19,23 +19,32 @@
 package views
 
 import (
+	"cmp"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/plugin"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/blubskye/yandere_sql_manager/internal/webhook"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
 // BackupView shows the backup management interface
 type BackupView struct {
-	conn    *db.Connection
-	list    list.Model
-	backups []db.BackupMetadata
-	width   int
-	height  int
-	err     error
+	conn        *db.Connection
+	cfg         *config.Config // Profiles offered as restore targets
+	profile     string         // Profile of the current connection
+	list        list.Model
+	backups     []db.BackupMetadata
+	keybindings *config.KeyBindings
+	width       int
+	height      int
+	err         error
 
 	// Sub-views/modes
 	mode          backupMode
@@ -79,7 +88,7 @@ type backupCreateForm struct {
 	focused          int // 0 = databases, 1 = compression
 	dbCursor         int
 	processing       bool
-	progress         string
+	progress         *progressPanel
 	err              error
 }
 
@@ -97,9 +106,23 @@ type backupRestoreForm struct {
 	selected   map[int]bool
 	dbCursor   int
 	dropExist  bool
+	analyze    bool // Refresh optimizer statistics afterwards
 	processing bool
-	progress   string
+	progress   *progressPanel
 	err        error
+
+	targets     []string // Profile names; "" is the current connection
+	targetIndex int
+	password    textinput.Model // Asked for when the target profile has none saved
+	askPassword bool
+
+	checking bool
+	report   *db.RestoreReport // Pre-restore check, shown before restoring
+	confirm  *typedConfirm     // Asks for the databases about to be dropped
+
+	restored bool              // Finished; shown when scripts ran or a safety backup was taken
+	scripts  []db.ScriptResult // Before and after scripts that ran
+	safety   string            // ID of the safety backup taken before restoring
 }
 
 // Confirm delete view
@@ -108,15 +131,8 @@ type confirmDeleteView struct {
 }
 
 // NewBackupView creates a new backup view
-func NewBackupView(conn *db.Connection, width, height int) *BackupView {
-	delegate := list.NewDefaultDelegate()
-	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#FF69B4")).
-		Bold(true)
-	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
-		Foreground(lipgloss.Color("#FFB6C1")).
-		Background(lipgloss.Color("#FF69B4"))
+func NewBackupView(conn *db.Connection, cfg *config.Config, profile string, width, height int) *BackupView {
+	delegate := newListDelegate()
 
 	l := list.New([]list.Item{}, delegate, width, height-4)
 	l.Title = "Backups"
@@ -125,11 +141,14 @@ func NewBackupView(conn *db.Connection, width, height int) *BackupView {
 	l.Styles.Title = titleStyle
 
 	return &BackupView{
-		conn:   conn,
-		list:   l,
-		width:  width,
-		height: height,
-		mode:   backupModeList,
+		conn:        conn,
+		cfg:         cfg,
+		profile:     profile,
+		list:        l,
+		keybindings: loadKeyBindings(),
+		width:       width,
+		height:      height,
+		mode:        backupModeList,
 	}
 }
 
@@ -154,13 +173,32 @@ type databasesForBackupMsg struct {
 }
 type backupCreatedMsg struct {
 	metadata *db.BackupMetadata
+	elapsed  time.Duration
+	err      error
+}
+type backupRestoredMsg struct {
+	backupID string
+	target   string // Profile restored to; "" for the current connection
+	elapsed  time.Duration
+	scripts  []db.ScriptResult
+	safety   string // Safety backup taken first, if any
+	err      error
+}
+type backupRestoreCheckedMsg struct {
+	report *db.RestoreReport
+	err    error
 }
-type backupRestoredMsg struct{}
 type backupDeletedMsg struct{}
-type backupProgressMsg struct {
-	database string
-	dbNum    int
-	totalDBs int
+
+func (m backupCreatedMsg) JobResult() JobResult {
+	return JobResult{View: "backup", Title: "Backup", Elapsed: m.elapsed, Err: m.err,
+		Event: webhook.BackupEvent(m.metadata, m.elapsed, m.err)}
+}
+
+func (m backupRestoredMsg) JobResult() JobResult {
+	event := webhook.RestoreEvent(m.backupID, m.elapsed, m.err)
+	event.Server = m.target
+	return JobResult{View: "backup", Title: "Restore", Elapsed: m.elapsed, Err: m.err, Event: event}
 }
 
 // Update handles messages
@@ -182,6 +220,11 @@ func (v *BackupView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (v *BackupView) updateList(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if !v.list.SettingFilter() {
+			if cmd := showHelp(v.keybindings, "backup", msg.String()); cmd != nil {
+				return v, cmd
+			}
+		}
 		switch msg.String() {
 		case "enter":
 			if item, ok := v.list.SelectedItem().(backupItem); ok {
@@ -255,7 +298,7 @@ func (v *BackupView) initCreateForm() tea.Cmd {
 	v.mode = backupModeCreate
 
 	return func() tea.Msg {
-		databases, err := v.conn.ListDatabases()
+		databases, err := v.conn.ListVisibleDatabases()
 		if err != nil {
 			return err
 		}
@@ -273,7 +316,7 @@ func (v *BackupView) updateCreateForm(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if form.processing {
-			return v, nil
+			return v, form.progress.runningKey(msg.String())
 		}
 
 		switch msg.String() {
@@ -335,18 +378,26 @@ func (v *BackupView) updateCreateForm(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "enter":
 			form.processing = true
-			return v, v.createBackup()
+			form.err = nil
+			return v, tea.Batch(v.createBackup(), progressTick())
 		}
 
 	case databasesForBackupMsg:
 		form.databases = msg.databases
 		return v, nil
 
-	case backupProgressMsg:
-		form.progress = fmt.Sprintf("Backing up %s (%d/%d)...", msg.database, msg.dbNum, msg.totalDBs)
+	case progressTickMsg:
+		if form.processing {
+			return v, progressTick()
+		}
 		return v, nil
 
 	case backupCreatedMsg:
+		if msg.err != nil {
+			form.err = msg.err
+			form.processing = false
+			return v, nil
+		}
 		v.mode = backupModeList
 		v.createForm = nil
 		return v, v.loadBackups
@@ -381,18 +432,39 @@ func (v *BackupView) createBackup() tea.Cmd {
 		compression = db.CompressionZstd
 	}
 
-	return func() tea.Msg {
+	form.progress = newProgressPanel("Backing up", progress.Rows, 0)
+	bar := form.progress
+	ctx, _ := bar.cancellable()
+
+	return runJob("Backup", bar, func() tea.Msg {
 		opts := db.BackupOptions{
 			Databases:   databases,
 			Compression: compression,
+			Context:     ctx,
+			OnProgress: func(database string, dbNum, totalDBs int, rows, bytes int64) {
+				bar.SetCurrent(database, dbNum, totalDBs)
+				bar.Set(rows)
+				bar.SetBytes(bytes)
+			},
 		}
 
-		metadata, err := v.conn.CreateBackup(opts)
+		conn, release := jobConnection(v.conn)
+		defer release()
+
+		// Estimated from table statistics, so the ETA is approximate
+		bar.SetTotal(conn.BackupRowEstimate(opts))
+
+		metadata, err := conn.CreateBackup(opts)
 		if err != nil {
-			return err
+			return backupCreatedMsg{elapsed: bar.Snapshot().Elapsed, err: err}
 		}
-		return backupCreatedMsg{metadata: metadata}
-	}
+
+		// Post-backup plugins log their own failures; the backup itself succeeded
+		if reg, err := plugin.Discover(); err == nil {
+			reg.RunPostBackup(conn, metadata, "")
+		}
+		return backupCreatedMsg{metadata: metadata, elapsed: bar.Snapshot().Elapsed}
+	})
 }
 
 func (v *BackupView) updateDetailsView(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -426,18 +498,124 @@ func (v *BackupView) initRestoreForm(metadata *db.BackupMetadata) {
 		v.restoreForm.selected[i] = true
 	}
 	v.restoreForm.databases = metadata.Databases
+
+	v.restoreForm.targets = []string{""}
+	if v.cfg != nil {
+		v.restoreForm.targets = append(v.restoreForm.targets, v.cfg.ListProfiles()...)
+	}
+	v.restoreForm.password = textinput.New()
+	v.restoreForm.password.Placeholder = "Password"
+	v.restoreForm.password.EchoMode = textinput.EchoPassword
+
 	v.mode = backupModeRestore
 }
 
+// restoreTarget returns the selected target profile, nil for the current connection
+func (v *BackupView) restoreTarget() *config.Profile {
+	form := v.restoreForm
+	name := form.targets[form.targetIndex]
+	if name == "" || v.cfg == nil {
+		return nil
+	}
+	p, err := v.cfg.GetProfile(name)
+	if err != nil {
+		return nil
+	}
+	return p
+}
+
+// restoreScripts returns the restore scripts of the target's profile
+func (v *BackupView) restoreScripts() db.OperationScripts {
+	if p := v.restoreTarget(); p != nil {
+		return p.Scripts.Restore
+	}
+	if v.profile == "" || v.cfg == nil {
+		return db.OperationScripts{}
+	}
+	p, err := v.cfg.GetProfile(v.profile)
+	if err != nil {
+		return db.OperationScripts{}
+	}
+	return p.Scripts.Restore
+}
+
+// restoreTargetLabel describes where the restore will go
+func (v *BackupView) restoreTargetLabel() string {
+	form := v.restoreForm
+	p := v.restoreTarget()
+	if p == nil {
+		return fmt.Sprintf("current connection (%s)", v.conn.Config.Host)
+	}
+	cc := p.ToConnectionConfig()
+	return fmt.Sprintf("profile %s (%s %s:%d)", form.targets[form.targetIndex], cc.Type, cc.Host, cc.Port)
+}
+
 func (v *BackupView) updateRestoreForm(msg tea.Msg) (tea.Model, tea.Cmd) {
 	form := v.restoreForm
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if form.processing {
+			return v, form.progress.runningKey(msg.String())
+		}
+		if form.checking {
+			return v, nil
+		}
+
+		if form.restored {
+			v.mode = backupModeList
+			v.restoreForm = nil
+			v.detailsView = nil
+			return v, v.loadBackups
+		}
+
+		if form.confirm != nil {
+			confirmed, cancelled, cmd := form.confirm.Update(msg)
+			switch {
+			case cancelled:
+				form.confirm = nil
+			case confirmed:
+				form.confirm = nil
+				return v, v.startRestore()
+			}
+			return v, cmd
+		}
+
+		if form.report != nil {
+			switch msg.String() {
+			case "esc":
+				form.report = nil
+			case "enter":
+				if !form.report.Go() {
+					break
+				}
+				if len(form.report.Dropped) > 0 {
+					form.confirm = newTypedConfirm("drop", form.report.Dropped)
+					return v, textinput.Blink
+				}
+				return v, v.startRestore()
+			}
 			return v, nil
 		}
 
+		if form.askPassword {
+			switch msg.String() {
+			case "esc":
+				form.askPassword = false
+				form.password.Blur()
+				return v, nil
+			case "enter":
+				form.askPassword = false
+				form.password.Blur()
+				form.checking = true
+				form.err = nil
+				return v, v.checkRestore()
+			}
+			var cmd tea.Cmd
+			form.password, cmd = form.password.Update(msg)
+			return v, cmd
+		}
+
 		switch msg.String() {
 		case "esc":
 			if v.detailsView != nil {
@@ -472,12 +650,53 @@ func (v *BackupView) updateRestoreForm(msg tea.Msg) (tea.Model, tea.Cmd) {
 			form.dropExist = !form.dropExist
 			return v, nil
 
+		case "a":
+			form.analyze = !form.analyze
+			return v, nil
+
+		case "t":
+			form.targetIndex = (form.targetIndex + 1) % len(form.targets)
+			form.password.SetValue("")
+			return v, nil
+
 		case "enter":
-			form.processing = true
-			return v, v.restoreBackup()
+			if p := v.restoreTarget(); p != nil && p.Password == "" {
+				form.askPassword = true
+				return v, form.password.Focus()
+			}
+			form.checking = true
+			form.err = nil
+			return v, v.checkRestore()
 		}
 
+	case progressTickMsg:
+		if form.processing {
+			return v, progressTick()
+		}
+		return v, nil
+
+	case backupRestoreCheckedMsg:
+		form.checking = false
+		if msg.err != nil {
+			form.err = msg.err
+			return v, nil
+		}
+		form.report = msg.report
+		return v, nil
+
 	case backupRestoredMsg:
+		form.scripts = msg.scripts
+		form.safety = msg.safety
+		if msg.err != nil {
+			form.err = msg.err
+			form.processing = false
+			return v, nil
+		}
+		if len(msg.scripts) > 0 || msg.safety != "" {
+			form.processing = false
+			form.restored = true
+			return v, nil
+		}
 		v.mode = backupModeList
 		v.restoreForm = nil
 		v.detailsView = nil
@@ -492,7 +711,8 @@ func (v *BackupView) updateRestoreForm(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return v, nil
 }
 
-func (v *BackupView) restoreBackup() tea.Cmd {
+// restoreOptions builds the restore options from the form
+func (v *BackupView) restoreOptions() db.RestoreOptions {
 	form := v.restoreForm
 
 	// Get selected databases
@@ -503,22 +723,140 @@ func (v *BackupView) restoreBackup() tea.Cmd {
 		}
 	}
 
-	return func() tea.Msg {
-		opts := db.RestoreOptions{
-			BackupID:           form.metadata.ID,
-			Databases:          databases,
-			DropExisting:       form.dropExist,
-			CreateIfNotExists:  true,
-			DisableForeignKeys: true,
+	return db.RestoreOptions{
+		BackupID:           form.metadata.ID,
+		Databases:          databases,
+		DropExisting:       form.dropExist,
+		CreateIfNotExists:  true,
+		DisableForeignKeys: true,
+		Analyze:            form.analyze,
+		Scripts:            v.restoreScripts(),
+	}
+}
+
+// restoreConnector returns a function connecting to the restore target and
+// a function releasing that connection. Restoring elsewhere uses a connection
+// of its own, so the session stays on the current server; restoring here gets
+// a dedicated one so the session stays responsive.
+func (v *BackupView) restoreConnector() func() (*db.Connection, func(), error) {
+	form := v.restoreForm
+	target := v.restoreTarget()
+	if target == nil {
+		conn := v.conn
+		return func() (*db.Connection, func(), error) {
+			dedicated, release := jobConnection(conn)
+			return dedicated, release, nil
 		}
+	}
 
-		if err := v.conn.RestoreBackup(opts); err != nil {
-			return err
+	targetCfg := target.ToConnectionConfig()
+	if targetCfg.Password == "" {
+		targetCfg.Password = form.password.Value()
+	}
+	return func() (*db.Connection, func(), error) {
+		conn, err := db.Connect(targetCfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to restore target: %w", err)
+		}
+		if len(target.Variables) > 0 {
+			conn.ApplyVariables(target.Variables)
 		}
-		return backupRestoredMsg{}
+		return conn, func() { conn.Close() }, nil
 	}
 }
 
+func (v *BackupView) checkRestore() tea.Cmd {
+	opts := v.restoreOptions()
+	connect := v.restoreConnector()
+
+	return func() tea.Msg {
+		conn, release, err := connect()
+		if err != nil {
+			return backupRestoreCheckedMsg{err: err}
+		}
+		defer release()
+
+		report, err := conn.CheckRestore(opts)
+		return backupRestoreCheckedMsg{report: report, err: err}
+	}
+}
+
+// startRestore leaves the pre-restore report and starts restoring
+func (v *BackupView) startRestore() tea.Cmd {
+	form := v.restoreForm
+	safety := db.SafetyBackupOptions{
+		Operation: "restore of " + form.metadata.ID,
+		Databases: form.report.Dropped,
+		Tables:    form.report.Replaced,
+		Profile:   cmp.Or(form.targets[form.targetIndex], v.profile),
+	}
+	form.report = nil
+	form.processing = true
+	form.err = nil
+	return tea.Batch(v.restoreBackup(safety), progressTick())
+}
+
+// restoreBackup restores the selected databases, first taking a safety
+// backup of what the restore replaces when the config turns them on
+func (v *BackupView) restoreBackup(safety db.SafetyBackupOptions) tea.Cmd {
+	form := v.restoreForm
+	form.progress = newProgressPanel("Restoring", progress.Percent, 0)
+	bar := form.progress
+
+	opts := v.restoreOptions()
+	ctx, _ := bar.cancellable()
+	opts.Context = ctx
+	opts.OnProgress = func(database string, dbNum, totalDBs int, percent float64, rows, bytes int64) {
+		// Overall progress in percentage points across all databases
+		bar.SetTotal(int64(totalDBs) * 100)
+		bar.Set(int64(dbNum-1)*100 + int64(percent))
+		bar.SetRows(rows)
+		bar.SetBytes(bytes)
+		bar.SetCurrent(database, dbNum, totalDBs)
+	}
+	connect := v.restoreConnector()
+	target := v.restoreForm.targets[v.restoreForm.targetIndex]
+
+	return runJob("Restore of "+opts.BackupID, bar, func() tea.Msg {
+		conn, release, err := connect()
+		if err != nil {
+			return backupRestoredMsg{backupID: opts.BackupID, target: target, err: err}
+		}
+		defer release()
+
+		var safetyID string
+		if v.cfg != nil && !safety.Empty() {
+			enabled, expire, err := v.cfg.SafetyBackupSettings()
+			if err != nil {
+				return backupRestoredMsg{backupID: opts.BackupID, target: target, err: err}
+			}
+			if enabled {
+				db.ExpireSafetyBackups(expire) // Best effort; retried on the next one
+				bar.SetCurrent("safety backup", 0, 0)
+				metadata, err := conn.CreateSafetyBackup(safety)
+				if err != nil {
+					return backupRestoredMsg{backupID: opts.BackupID, target: target, err: err}
+				}
+				if metadata != nil {
+					safetyID = metadata.ID
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			return backupRestoredMsg{backupID: opts.BackupID, target: target, safety: safetyID, err: fmt.Errorf("restore cancelled before it started")}
+		}
+
+		stats, err := conn.RestoreBackupWithStats(opts)
+		return backupRestoredMsg{backupID: opts.BackupID, target: target, elapsed: bar.Snapshot().Elapsed, scripts: stats.Scripts, safety: safetyID, err: err}
+	})
+}
+
+// ownsJob reports whether the view started the job of p
+func (v *BackupView) ownsJob(p *progressPanel) bool {
+	return (v.createForm != nil && p == v.createForm.progress) ||
+		(v.restoreForm != nil && p == v.restoreForm.progress)
+}
+
 func (v *BackupView) updateConfirmDelete(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -580,13 +918,14 @@ func (v *BackupView) viewList() string {
 	var b strings.Builder
 
 	if v.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", v.err)))
+		b.WriteString(renderError(v.err))
 		b.WriteString("\n\n")
 	}
 
 	b.WriteString(v.list.View())
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Enter: Details | c: Create | r: Restore | d: Delete | R: Refresh | Esc: Back | q: Quit"))
+	b.WriteString(helpStyle.Render(fmt.Sprintf("Enter: Details | c: Create | r: Restore | d: Delete | R: Refresh | %s: Help | Esc: Back | q: Quit",
+		v.keybindings.GetKey("backup", config.ActionHelp))))
 
 	return b.String()
 }
@@ -660,17 +999,15 @@ func (v *BackupView) viewCreateForm() string {
 	b.WriteString("\n")
 
 	if form.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", form.err)))
+		b.WriteString(renderError(form.err))
 		b.WriteString("\n\n")
 	}
 
-	if form.processing {
-		progress := form.progress
-		if progress == "" {
-			progress = "Creating backup..."
-		}
-		b.WriteString(progress)
+	if form.processing && form.progress != nil {
+		b.WriteString(form.progress.View())
 		b.WriteString("\n\n")
+		b.WriteString(form.progress.runningHelp())
+		return b.String()
 	}
 
 	b.WriteString(helpStyle.Render("Tab: Switch | ↑↓: Navigate | Space: Toggle | a: All | Enter: Create | Esc: Cancel"))
@@ -716,6 +1053,31 @@ func (v *BackupView) viewRestoreForm() string {
 	b.WriteString(titleStyle.Render(fmt.Sprintf("Restore Backup: %s", form.metadata.ID)))
 	b.WriteString("\n\n")
 
+	if form.confirm != nil {
+		b.WriteString(form.confirm.View())
+		return b.String()
+	}
+
+	if form.report != nil {
+		b.WriteString(viewRestoreReport(form.report, v.restoreTargetLabel()))
+		return b.String()
+	}
+
+	if form.restored {
+		b.WriteString(successStyle.Render("Restore completed successfully!"))
+		b.WriteString("\n\n")
+		if form.safety != "" {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("Safety backup %s was taken first. Undo with: ysm backup undo %s", form.safety, form.safety)))
+			b.WriteString("\n\n")
+		}
+		if len(form.scripts) > 0 {
+			b.WriteString(renderScriptResults(form.scripts))
+			b.WriteString("\n\n")
+		}
+		b.WriteString(helpStyle.Render("Any key: Back"))
+		return b.String()
+	}
+
 	b.WriteString("Select databases to restore:\n")
 	for i, dbName := range form.databases {
 		checkbox := "[ ]"
@@ -736,26 +1098,86 @@ func (v *BackupView) viewRestoreForm() string {
 	if form.dropExist {
 		dropCheck = "[x]"
 	}
+	analyzeCheck := "[ ]"
+	if form.analyze {
+		analyzeCheck = "[x]"
+	}
 	b.WriteString(fmt.Sprintf("Options: %s Drop existing databases (press 'd' to toggle)\n", dropCheck))
+	b.WriteString(fmt.Sprintf("         %s Analyze tables afterwards (press 'a' to toggle)\n", analyzeCheck))
+	b.WriteString(fmt.Sprintf("Target:  %s (press 't' to change)\n", headerStyle.Render(v.restoreTargetLabel())))
+	if scripts := v.restoreScripts(); !scripts.Empty() {
+		b.WriteString(fmt.Sprintf("Scripts: %d before, %d after (from the target's profile)\n", len(scripts.Before), len(scripts.After)))
+	}
 
 	b.WriteString("\n")
 
 	if form.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", form.err)))
+		b.WriteString(renderError(form.err))
 		b.WriteString("\n\n")
+		if len(form.scripts) > 0 {
+			b.WriteString(renderScriptResults(form.scripts))
+			b.WriteString("\n\n")
+		}
 	}
 
-	if form.processing {
-		progress := form.progress
-		if progress == "" {
-			progress = "Restoring backup..."
-		}
-		b.WriteString(progress)
+	if form.checking {
+		b.WriteString("Checking the backup against the target...\n\n")
+	}
+
+	if form.processing && form.progress != nil {
+		b.WriteString(form.progress.View())
 		b.WriteString("\n\n")
+		b.WriteString(form.progress.runningHelp())
+		return b.String()
 	}
 
-	b.WriteString(helpStyle.Render("↑↓: Navigate | Space: Toggle | d: Drop existing | Enter: Restore | Esc: Cancel"))
+	if form.askPassword {
+		b.WriteString(focusedStyle.Render(fmt.Sprintf("Password for profile %s:", form.targets[form.targetIndex])))
+		b.WriteString("\n")
+		b.WriteString(form.password.View())
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Enter: Check | Esc: Cancel"))
+		return b.String()
+	}
+
+	b.WriteString(helpStyle.Render("↑↓: Navigate | Space: Toggle | d: Drop existing | a: Analyze | t: Target | Enter: Check & restore | Esc: Cancel"))
+
+	return b.String()
+}
+
+// viewRestoreReport renders the pre-restore check as a go/no-go screen
+func viewRestoreReport(report *db.RestoreReport, target string) string {
+	var b strings.Builder
 
+	b.WriteString(headerStyle.Render("Pre-restore check"))
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("  %s", target)))
+	if report.ServerVersion != "" {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf(" (%s)", report.ServerVersion)))
+	}
+	b.WriteString("\n\n")
+
+	for _, check := range report.Checks {
+		style := successStyle
+		switch check.Level {
+		case db.RestoreCheckWarning:
+			style = focusedStyle
+		case db.RestoreCheckBlocker:
+			style = errorStyle
+		}
+		b.WriteString(fmt.Sprintf("  %s %-9s %s\n", style.Render(fmt.Sprintf("[%-4s]", check.Level)), check.Category, check.Message))
+	}
+	b.WriteString("\n")
+
+	warnings := report.Count(db.RestoreCheckWarning)
+	if report.Go() {
+		b.WriteString(successStyle.Render(fmt.Sprintf("GO: ready to restore (%d warning(s))", warnings)))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Enter: Restore | Esc: Back to options"))
+	} else {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("NO GO: %d blocker(s), %d warning(s)", report.Count(db.RestoreCheckBlocker), warnings)))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Esc: Back to options"))
+	}
 	return b.String()
 }
 
@@ -0,0 +1,258 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// BloatView reports the most bloated tables and indexes of a database and
+// rebuilds them
+type BloatView struct {
+	conn       *db.Connection
+	database   string
+	estimates  []db.BloatEstimate
+	cursor     int
+	sortBy     int // Index into db.BloatSortOrders
+	loading    bool
+	plan       *db.RebuildPlan // Waiting for y before rebuilding
+	rebuilding string          // Object being rebuilt
+	message    string
+	err        error
+	width      int
+	height     int
+}
+
+type bloatLoadedMsg struct {
+	estimates []db.BloatEstimate
+	err       error
+}
+
+type bloatPlannedMsg struct {
+	plan db.RebuildPlan
+}
+
+type bloatRebuiltMsg struct {
+	plan db.RebuildPlan
+	err  error
+}
+
+// NewBloatView creates a new bloat report for a database
+func NewBloatView(conn *db.Connection, database string, width, height int) *BloatView {
+	return &BloatView{
+		conn:     conn,
+		database: database,
+		loading:  true,
+		width:    width,
+		height:   height,
+	}
+}
+
+// Init initializes the view
+func (v *BloatView) Init() tea.Cmd {
+	return v.load
+}
+
+func (v *BloatView) load() tea.Msg {
+	if err := v.conn.UseDatabase(v.database); err != nil {
+		return bloatLoadedMsg{err: err}
+	}
+	estimates, err := v.conn.EstimateBloat()
+	return bloatLoadedMsg{estimates: estimates, err: err}
+}
+
+func (v *BloatView) planRebuild(b db.BloatEstimate) tea.Cmd {
+	return func() tea.Msg {
+		return bloatPlannedMsg{plan: v.conn.PlanRebuild(b)}
+	}
+}
+
+func (v *BloatView) rebuild(plan db.RebuildPlan) tea.Cmd {
+	return func() tea.Msg {
+		return bloatRebuiltMsg{plan: plan, err: v.conn.Rebuild(plan)}
+	}
+}
+
+// Update handles messages
+func (v *BloatView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+
+	case bloatLoadedMsg:
+		v.loading = false
+		v.err = msg.err
+		v.estimates = msg.estimates
+		db.SortBloat(v.estimates, db.BloatSortOrders[v.sortBy])
+		v.cursor = min(v.cursor, max(len(v.estimates)-1, 0))
+
+	case bloatPlannedMsg:
+		v.plan = &msg.plan
+
+	case bloatRebuiltMsg:
+		v.rebuilding = ""
+		if msg.err != nil {
+			v.err = msg.err
+			return v, nil
+		}
+		v.message = fmt.Sprintf("Rebuilt %s with %s", msg.plan.Target.Name(), msg.plan.Method)
+		v.loading = true
+		return v, v.load
+
+	case tea.KeyMsg:
+		return v.updateKeys(msg)
+	}
+	return v, nil
+}
+
+func (v *BloatView) updateKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if v.plan != nil {
+		plan := *v.plan
+		v.plan = nil
+		if msg.String() == "y" {
+			v.rebuilding = plan.Target.Name()
+			return v, v.rebuild(plan)
+		}
+		return v, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down", "j":
+		if v.cursor < len(v.estimates)-1 {
+			v.cursor++
+		}
+	case "s":
+		v.sortBy = (v.sortBy + 1) % len(db.BloatSortOrders)
+		db.SortBloat(v.estimates, db.BloatSortOrders[v.sortBy])
+		v.cursor = 0
+	case "o":
+		if v.rebuilding == "" && v.cursor < len(v.estimates) {
+			v.err = nil
+			v.message = ""
+			return v, v.planRebuild(v.estimates[v.cursor])
+		}
+	case "r":
+		if !v.loading && v.rebuilding == "" {
+			v.loading = true
+			v.message = ""
+			return v, v.load
+		}
+	case "esc", "backspace":
+		return v, func() tea.Msg {
+			return SwitchViewMsg{View: "tables", Database: v.database}
+		}
+	case "q":
+		return v, tea.Quit
+	}
+	return v, nil
+}
+
+// View renders the view
+func (v *BloatView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Bloat Report"))
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("  (%s, by %s)", v.database, db.BloatSortOrders[v.sortBy])))
+	b.WriteString("\n\n")
+
+	if v.loading && v.estimates == nil {
+		b.WriteString("Estimating bloat...\n")
+		return b.String()
+	}
+
+	if len(v.estimates) == 0 {
+		b.WriteString(mutedStyle.Render("No tables with statistics found"))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-44s %-24s %10s %10s %7s", "Object", "Type", "Size", "Wasted", "Bloat")))
+		b.WriteString("\n")
+
+		// Keep the cursor on screen
+		visible := max(v.height-14, 5)
+		start := 0
+		if v.cursor >= visible {
+			start = v.cursor - visible + 1
+		}
+		end := min(start+visible, len(v.estimates))
+
+		for i := start; i < end; i++ {
+			e := v.estimates[i]
+			kind := "table"
+			if e.Index != "" {
+				kind = "index on " + e.Table
+			}
+			line := fmt.Sprintf("%-44s %-24s %10s %10s %6.1f%%",
+				truncateRunes(e.Name(), 44), truncateRunes(kind, 24),
+				db.FormatSize(e.Size), db.FormatSize(e.Wasted), e.Percent())
+
+			switch {
+			case i == v.cursor:
+				b.WriteString(selectedStyle.Render("> " + line))
+			case e.Percent() >= 50:
+				b.WriteString(errorStyle.Render("  " + line))
+			case e.Percent() >= 20:
+				b.WriteString(focusedStyle.Render("  " + line))
+			default:
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+
+	switch {
+	case v.rebuilding != "":
+		b.WriteString(focusedStyle.Render(fmt.Sprintf("Rebuilding %s...", v.rebuilding)))
+		b.WriteString("\n\n")
+	case v.plan != nil:
+		b.WriteString(headerStyle.Render(fmt.Sprintf("Rebuild %s with %s?", v.plan.Target.Name(), v.plan.Method)))
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("  " + v.plan.Describe()))
+		b.WriteString("\n")
+		b.WriteString(focusedStyle.Render("  " + v.plan.Warning))
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render("Proceed? (y/n)"))
+		b.WriteString("\n\n")
+	case v.err != nil:
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	case v.message != "":
+		b.WriteString(successStyle.Render(v.message))
+		b.WriteString("\n\n")
+	}
+
+	if v.conn.Config.Type == db.DatabaseTypePostgres {
+		b.WriteString(mutedStyle.Render("Estimated from pg_stats; tables never analyzed are left out"))
+	} else {
+		b.WriteString(mutedStyle.Render("Free space inside each table's data file (data_free)"))
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑↓: Navigate | o: Rebuild | s: Sort | r: Refresh | Esc: Back | q: Quit"))
+
+	return b.String()
+}
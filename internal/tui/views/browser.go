@@ -22,10 +22,11 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
 // BrowserView shows table data
@@ -42,8 +43,48 @@ type BrowserView struct {
 	width    int
 	height   int
 	err      error
+
+	keybindings *config.KeyBindings
+	primaryKey  []string
+	status      string
+	rlsWarning  string // Set when row-level security may hide rows
+
+	// Row editing
+	mode        browserMode
+	formInputs  []textinput.Model
+	formFocus   int
+	formKey     db.RowKey
+	formOrig    []string
+	pendingDel  bool // First d of dd was pressed
+
+	// Server-side sorting and filtering
+	colCursor   int
+	sortColumn  string
+	sortDesc    bool
+	filters     map[string]string
+	filterMode  bool
+	filterInput textinput.Model
+
+	// Export of the filtered result
+	exportInput  textinput.Model
+	exportFormat string
+	exporting    bool
+
+	// Full view of one cell
+	cell *cellViewer
 }
 
+type browserMode int
+
+const (
+	browserModeNormal browserMode = iota
+	browserModeEdit
+	browserModeInsert
+	browserModeConfirmDelete
+	browserModeExport
+	browserModeCell
+)
+
 // NewBrowserView creates a new table browser view
 func NewBrowserView(conn *db.Connection, database, tableName string, width, height int) *BrowserView {
 	t := table.New(
@@ -51,18 +92,20 @@ func NewBrowserView(conn *db.Connection, database, tableName string, width, heig
 		table.WithHeight(height-8),
 	)
 
-	s := table.DefaultStyles()
-	s.Header = s.Header.
-		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("#FF69B4")).
-		BorderBottom(true).
-		Bold(true).
-		Foreground(lipgloss.Color("#FF69B4"))
-	s.Selected = s.Selected.
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#FF69B4")).
-		Bold(true)
-	t.SetStyles(s)
+	t.SetStyles(newTableStyles())
+
+	kb, _ := config.LoadKeyBindings()
+	if kb == nil {
+		kb = config.DefaultKeyBindings()
+	}
+
+	fi := textinput.New()
+	fi.Placeholder = "text, =v, >v, <v, !=v, a%b, NULL, !NULL"
+	fi.CharLimit = 256
+
+	ei := textinput.New()
+	ei.Prompt = "File: "
+	ei.CharLimit = 512
 
 	return &BrowserView{
 		conn:     conn,
@@ -73,6 +116,10 @@ func NewBrowserView(conn *db.Connection, database, tableName string, width, heig
 		pageSize: 50,
 		width:    width,
 		height:   height,
+		keybindings: kb,
+		filters:     make(map[string]string),
+		filterInput: fi,
+		exportInput: ei,
 	}
 }
 
@@ -86,36 +133,128 @@ func (v *BrowserView) loadData() tea.Msg {
 		return err
 	}
 
+	// Row editing needs the primary key; without one the browser stays read-only.
+	// It also keeps sorted pages stable when the sort column has duplicates.
+	pk, _ := v.conn.PrimaryKey(v.tableName)
+
+	opts := db.TableDataOptions{
+		Limit:    v.pageSize,
+		Offset:   v.page * v.pageSize,
+		OrderBy:  v.sortColumn,
+		Desc:     v.sortDesc,
+		TieBreak: pk,
+		Filters:  v.filters,
+	}
+
 	// Get total count
-	total, err := v.conn.CountTableRows(v.tableName)
+	total, err := v.conn.CountTableRowsWithOptions(v.tableName, opts)
 	if err != nil {
 		return err
 	}
 
 	// Get data
-	result, err := v.conn.GetTableData(v.tableName, v.pageSize, v.page*v.pageSize)
+	result, err := v.conn.GetTableDataWithOptions(v.tableName, opts)
 	if err != nil {
 		return err
 	}
 
+	// Policies silently filter rows, so say so rather than let the counts mislead
+	security, _ := v.conn.GetRowSecurity(v.tableName)
+
 	return browserData{
-		columns: result.Columns,
-		rows:    result.Rows,
-		total:   total,
+		columns:    result.Columns,
+		rows:       result.Rows,
+		total:      total,
+		primaryKey: pk,
+		rlsWarning: security.Warning(),
 	}
 }
 
 type browserData struct {
-	columns []string
-	rows    [][]string
-	total   int64
+	columns    []string
+	rows       [][]string
+	total      int64
+	primaryKey []string
+	rlsWarning string
+}
+
+type browserRowSavedMsg struct {
+	message string
+}
+
+type browserExportedMsg struct {
+	message string
+	err     error
 }
 
 // Update handles messages
 func (v *BrowserView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if v.mode == browserModeExport {
+		return v.updateExportPrompt(msg)
+	}
+	if v.mode == browserModeCell {
+		return v.updateCellViewer(msg)
+	}
+	if v.mode != browserModeNormal {
+		return v.updateRowForm(msg)
+	}
+	if v.filterMode {
+		return v.updateFilterBar(msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
+		key := msg.String()
+		pendingDel := v.pendingDel
+		v.pendingDel = false
+		v.status = ""
+
+		switch {
+		case v.keybindings.IsKey("browser", key, config.ActionEdit):
+			return v, v.openEditForm()
+		case v.keybindings.IsKey("browser", key, config.ActionCreate):
+			return v, v.openInsertForm()
+		case v.keybindings.IsKey("browser", key, config.ActionDelete):
+			if !pendingDel {
+				v.pendingDel = true
+				v.status = fmt.Sprintf("Press %s again to delete this row", key)
+				return v, nil
+			}
+			v.openDeleteConfirm()
+			return v, nil
+		case v.keybindings.IsKey("browser", key, config.ActionNextColumn):
+			v.moveColumn(1)
+			return v, nil
+		case v.keybindings.IsKey("browser", key, config.ActionPrevColumn):
+			v.moveColumn(-1)
+			return v, nil
+		case v.keybindings.IsKey("browser", key, config.ActionSort):
+			return v, v.cycleSort()
+		case v.keybindings.IsKey("browser", key, config.ActionFilter):
+			return v, v.openFilterBar()
+		case v.keybindings.IsKey("browser", key, config.ActionExportResult):
+			return v, v.openExportPrompt()
+		case v.keybindings.IsKey("browser", key, config.ActionCopyCell):
+			return v, v.copyCell()
+		case v.keybindings.IsKey("browser", key, config.ActionCopyRow):
+			return v, v.copyRow()
+		case v.keybindings.IsKey("browser", key, config.ActionViewCell):
+			v.openCellViewer()
+			return v, nil
+		case v.keybindings.IsKey("browser", key, config.ActionClearFilter):
+			if len(v.filters) > 0 {
+				v.filters = make(map[string]string)
+				v.page = 0
+				return v, v.loadData
+			}
+			return v, nil
+		case v.keybindings.IsKey("browser", key, config.ActionHelp):
+			return v, func() tea.Msg {
+				return ShowHelpMsg{View: "browser"}
+			}
+		}
+
+		switch key {
 		case "esc", "backspace":
 			return v, func() tea.Msg {
 				return SwitchViewMsg{
@@ -125,14 +264,13 @@ func (v *BrowserView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "q":
 			return v, tea.Quit
-		case "n", "right":
+		case "]", "right":
 			// Next page
-			maxPage := int(v.total) / v.pageSize
-			if v.page < maxPage {
+			if v.page < v.lastPage() {
 				v.page++
 				return v, v.loadData
 			}
-		case "p", "left":
+		case "[", "left":
 			// Previous page
 			if v.page > 0 {
 				v.page--
@@ -146,9 +284,8 @@ func (v *BrowserView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "G":
 			// Go to last page
-			maxPage := int(v.total) / v.pageSize
-			if v.page != maxPage {
-				v.page = maxPage
+			if v.page != v.lastPage() {
+				v.page = v.lastPage()
 				return v, v.loadData
 			}
 		case "r":
@@ -161,12 +298,30 @@ func (v *BrowserView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		v.table.SetHeight(msg.Height - 8)
 
 	case browserData:
+		if len(msg.columns) != len(v.columns) {
+			v.colCursor = 0
+		}
 		v.columns = msg.columns
 		v.rows = msg.rows
 		v.total = msg.total
+		v.primaryKey = msg.primaryKey
+		v.rlsWarning = msg.rlsWarning
 		v.updateTable()
 		return v, nil
 
+	case browserRowSavedMsg:
+		v.status = msg.message
+		v.err = nil
+		return v, v.loadData
+
+	case clipboardCopiedMsg:
+		if msg.err != nil {
+			v.err = msg.err
+		} else {
+			v.status = msg.status()
+		}
+		return v, nil
+
 	case error:
 		v.err = msg
 		return v, nil
@@ -195,6 +350,7 @@ func (v *BrowserView) updateTable() {
 	// Check data widths
 	for _, row := range v.rows {
 		for i, cell := range row {
+			cell = cellGridText(cell)
 			if i < len(colWidths) {
 				w := min(len(cell)+2, maxWidth)
 				if w > colWidths[i] {
@@ -204,10 +360,24 @@ func (v *BrowserView) updateTable() {
 		}
 	}
 
-	// Create columns
+	// Create columns, marking the selected, sorted and filtered ones
 	cols := make([]table.Column, len(v.columns))
 	for i, name := range v.columns {
-		cols[i] = table.Column{Title: name, Width: colWidths[i]}
+		title := name
+		if name == v.sortColumn {
+			if v.sortDesc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		if _, ok := v.filters[name]; ok {
+			title += " *"
+		}
+		if i == v.colCursor {
+			title = "›" + title
+		}
+		cols[i] = table.Column{Title: title, Width: max(colWidths[i], min(len(title)+2, maxWidth))}
 	}
 
 	// Create rows
@@ -215,6 +385,7 @@ func (v *BrowserView) updateTable() {
 	for i, row := range v.rows {
 		r := make(table.Row, len(row))
 		for j, cell := range row {
+			cell = cellGridText(cell)
 			// Truncate long values
 			if len(cell) > maxWidth-3 {
 				cell = cell[:maxWidth-6] + "..."
@@ -245,10 +416,33 @@ func (v *BrowserView) View() string {
 	b.WriteString("\n\n")
 
 	if v.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", v.err)))
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	if v.filterMode {
+		b.WriteString(v.filterInput.View())
+		b.WriteString("\n\n")
+	} else if summary := v.filterSummary(); summary != "" {
+		b.WriteString(mutedStyle.Render("Filters: " + summary))
 		b.WriteString("\n\n")
 	}
 
+	switch v.mode {
+	case browserModeEdit, browserModeInsert:
+		b.WriteString(v.renderRowForm())
+		return b.String()
+	case browserModeConfirmDelete:
+		b.WriteString(v.renderDeleteConfirm())
+		return b.String()
+	case browserModeExport:
+		b.WriteString(v.renderExportPrompt())
+		return b.String()
+	case browserModeCell:
+		b.WriteString(v.cell.view())
+		return b.String()
+	}
+
 	// Table
 	b.WriteString(v.table.View())
 	b.WriteString("\n\n")
@@ -261,10 +455,594 @@ func (v *BrowserView) View() string {
 	}
 	pageInfo := fmt.Sprintf("Showing %d-%d of %d rows (Page %d)", start, end, v.total, v.page+1)
 	b.WriteString(mutedStyle.Render(pageInfo))
+	if len(v.primaryKey) == 0 && len(v.columns) > 0 {
+		b.WriteString(mutedStyle.Render(" | read-only: no primary key"))
+	}
 	b.WriteString("\n")
+	if v.rlsWarning != "" {
+		b.WriteString(focusedStyle.Render("⚠ " + v.rlsWarning))
+		b.WriteString("\n")
+	}
+	if v.status != "" {
+		b.WriteString(successStyle.Render(v.status))
+		b.WriteString("\n")
+	}
 
 	// Help
-	b.WriteString(helpStyle.Render("←/p: Prev page | →/n: Next page | g/G: First/Last | r: Refresh | Esc: Back | q: Quit"))
+	kb := v.keybindings
+	if v.filterMode {
+		b.WriteString(helpStyle.Render("Enter: Apply filter (empty clears) | Esc: Cancel"))
+		return b.String()
+	}
+	b.WriteString(helpStyle.Render(fmt.Sprintf("←/[: Prev page | →/]: Next page | g/G: First/Last | %s/%s: Column | %s: Sort | %s: Filter | %s: Clear filters",
+		kb.GetKey("browser", config.ActionNextColumn), kb.GetKey("browser", config.ActionPrevColumn),
+		kb.GetKey("browser", config.ActionSort), kb.GetKey("browser", config.ActionFilter),
+		kb.GetKey("browser", config.ActionClearFilter))))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render(fmt.Sprintf("%s: Edit | %s: New row | %s%s: Delete | %s: View cell | %s/%s: Copy cell/row | %s: Export result | r: Refresh | %s: Help | Esc: Back | q: Quit",
+		kb.GetKey("browser", config.ActionEdit), kb.GetKey("browser", config.ActionCreate),
+		kb.GetKey("browser", config.ActionDelete), kb.GetKey("browser", config.ActionDelete),
+		kb.GetKey("browser", config.ActionViewCell),
+		kb.GetKey("browser", config.ActionCopyCell), kb.GetKey("browser", config.ActionCopyRow),
+		kb.GetKey("browser", config.ActionExportResult), kb.GetKey("browser", config.ActionHelp))))
+
+	return b.String()
+}
+
+// lastPage returns the index of the last page of the (filtered) table
+func (v *BrowserView) lastPage() int {
+	if v.total <= 0 {
+		return 0
+	}
+	return int((v.total - 1) / int64(v.pageSize))
+}
+
+func (v *BrowserView) moveColumn(delta int) {
+	if len(v.columns) == 0 {
+		return
+	}
+	v.colCursor = (v.colCursor + delta + len(v.columns)) % len(v.columns)
+	v.updateTable()
+}
+
+// cycleSort cycles the selected column through ascending, descending and unsorted
+func (v *BrowserView) cycleSort() tea.Cmd {
+	if len(v.columns) == 0 {
+		return nil
+	}
+	col := v.columns[v.colCursor]
+	switch {
+	case v.sortColumn != col:
+		v.sortColumn, v.sortDesc = col, false
+	case !v.sortDesc:
+		v.sortDesc = true
+	default:
+		v.sortColumn, v.sortDesc = "", false
+	}
+	v.page = 0
+	return v.loadData
+}
+
+func (v *BrowserView) openFilterBar() tea.Cmd {
+	if len(v.columns) == 0 {
+		return nil
+	}
+	col := v.columns[v.colCursor]
+	v.filterMode = true
+	v.filterInput.Prompt = fmt.Sprintf("Filter %s: ", col)
+	v.filterInput.SetValue(v.filters[col])
+	v.filterInput.CursorEnd()
+	v.filterInput.Focus()
+	v.table.Blur()
+	return textinput.Blink
+}
+
+func (v *BrowserView) updateFilterBar(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			v.filterMode = false
+			v.filterInput.Blur()
+			v.table.Focus()
+			return v, nil
+		case "enter":
+			col := v.columns[v.colCursor]
+			if value := strings.TrimSpace(v.filterInput.Value()); value != "" {
+				v.filters[col] = value
+			} else {
+				delete(v.filters, col)
+			}
+			v.filterMode = false
+			v.filterInput.Blur()
+			v.table.Focus()
+			v.page = 0
+			return v, v.loadData
+		}
+	}
+
+	var cmd tea.Cmd
+	v.filterInput, cmd = v.filterInput.Update(msg)
+	return v, cmd
+}
+
+// filterSummary describes the active filters, e.g. "email gmail, id >5"
+func (v *BrowserView) filterSummary() string {
+	var parts []string
+	for _, col := range v.columns {
+		if expr, ok := v.filters[col]; ok {
+			parts = append(parts, col+" "+expr)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// selectedRow returns the full (untruncated) values of the highlighted row
+func (v *BrowserView) selectedRow() ([]string, bool) {
+	cursor := v.table.Cursor()
+	if cursor < 0 || cursor >= len(v.rows) {
+		return nil, false
+	}
+	return v.rows[cursor], true
+}
+
+// openCellViewer shows the full value of the highlighted cell
+func (v *BrowserView) openCellViewer() {
+	row, ok := v.selectedRow()
+	if !ok || v.colCursor >= len(row) {
+		return
+	}
+	v.cell = newCellViewer(v.tableName, v.columns[v.colCursor], row[v.colCursor], v.width, v.height)
+	v.table.Blur()
+	v.mode = browserModeCell
+}
+
+func (v *BrowserView) updateCellViewer(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		v.width = msg.Width
+		v.height = msg.Height
+		v.table.SetHeight(msg.Height - 8)
+		v.cell.resize(msg.Width, msg.Height)
+		return v, nil
+	}
+	closed, cmd := v.cell.update(msg)
+	if closed {
+		v.cell = nil
+		v.table.Focus()
+		v.mode = browserModeNormal
+	}
+	return v, cmd
+}
+
+// copyCell puts the full value of the highlighted cell on the clipboard
+func (v *BrowserView) copyCell() tea.Cmd {
+	row, ok := v.selectedRow()
+	if !ok || v.colCursor >= len(row) {
+		return nil
+	}
+	return copyToClipboard(v.columns[v.colCursor], row[v.colCursor])
+}
+
+// copyRow puts the highlighted row on the clipboard as tab-separated
+// values, which paste into a spreadsheet as one row
+func (v *BrowserView) copyRow() tea.Cmd {
+	row, ok := v.selectedRow()
+	if !ok {
+		return nil
+	}
+	return copyToClipboard("the row", strings.Join(row, "\t"))
+}
+
+// selectedKey returns the primary key of the highlighted row
+func (v *BrowserView) selectedKey() (db.RowKey, bool) {
+	if len(v.primaryKey) == 0 {
+		v.status = "This table has no primary key, so rows can't be edited safely"
+		return db.RowKey{}, false
+	}
+	row, ok := v.selectedRow()
+	if !ok {
+		return db.RowKey{}, false
+	}
+	key, err := db.RowKeyFor(v.primaryKey, v.columns, row)
+	if err != nil {
+		v.err = err
+		return db.RowKey{}, false
+	}
+	return key, true
+}
+
+// newRowForm creates one input per column, prefilled with values
+func (v *BrowserView) newRowForm(values []string, placeholder string) tea.Cmd {
+	labelWidth := 0
+	for _, col := range v.columns {
+		labelWidth = max(labelWidth, len(col))
+	}
+
+	v.formInputs = make([]textinput.Model, len(v.columns))
+	for i, col := range v.columns {
+		ti := textinput.New()
+		label := col
+		for _, pk := range v.primaryKey {
+			if pk == col {
+				label += " (PK)"
+				break
+			}
+		}
+		ti.Prompt = fmt.Sprintf("%-*s ", labelWidth+5, label)
+		ti.Placeholder = placeholder
+		ti.PromptStyle = blurredStyle
+		if values != nil {
+			ti.SetValue(values[i])
+		}
+		v.formInputs[i] = ti
+	}
+	v.formFocus = 0
+	v.formInputs[0].Focus()
+	v.formInputs[0].PromptStyle = focusedStyle
+	v.err = nil
+	return textinput.Blink
+}
+
+func (v *BrowserView) openEditForm() tea.Cmd {
+	key, ok := v.selectedKey()
+	if !ok {
+		return nil
+	}
+	row, _ := v.selectedRow()
+
+	// Show NULLs as \N so they survive a round trip through the form
+	values := make([]string, len(v.columns))
+	for i := range v.columns {
+		if i < len(row) {
+			values[i] = row[i]
+			if row[i] == "NULL" {
+				values[i] = db.NullParam
+			}
+		}
+	}
+
+	v.mode = browserModeEdit
+	v.formKey = key
+	v.formOrig = values
+	return v.newRowForm(values, "empty string (\\N for NULL)")
+}
+
+func (v *BrowserView) openInsertForm() tea.Cmd {
+	if len(v.columns) == 0 {
+		return nil
+	}
+	v.mode = browserModeInsert
+	v.formOrig = nil
+	return v.newRowForm(nil, "default")
+}
+
+func (v *BrowserView) openDeleteConfirm() {
+	key, ok := v.selectedKey()
+	if !ok {
+		return
+	}
+	v.mode = browserModeConfirmDelete
+	v.formKey = key
+}
+
+func (v *BrowserView) closeRowForm() {
+	v.mode = browserModeNormal
+	v.formInputs = nil
+	v.formOrig = nil
+}
+
+// formChanges returns the columns and values the form would write
+func (v *BrowserView) formChanges() ([]string, []string) {
+	var columns, values []string
+	for i, input := range v.formInputs {
+		value := input.Value()
+		switch v.mode {
+		case browserModeEdit:
+			if value == v.formOrig[i] {
+				continue
+			}
+		case browserModeInsert:
+			if value == "" {
+				continue // Let the column take its default
+			}
+		}
+		columns = append(columns, v.columns[i])
+		values = append(values, value)
+	}
+	return columns, values
+}
+
+func (v *BrowserView) saveRowForm() tea.Cmd {
+	columns, values := v.formChanges()
+
+	if v.mode == browserModeEdit {
+		if len(columns) == 0 {
+			v.closeRowForm()
+			v.status = "No changes"
+			return nil
+		}
+		key := v.formKey
+		return func() tea.Msg {
+			if err := v.conn.UpdateRow(v.tableName, key, columns, values); err != nil {
+				return err
+			}
+			return browserRowSavedMsg{message: fmt.Sprintf("Row updated (%d column(s))", len(columns))}
+		}
+	}
+
+	if len(columns) == 0 {
+		v.err = fmt.Errorf("enter at least one value")
+		return nil
+	}
+	return func() tea.Msg {
+		if err := v.conn.InsertRow(v.tableName, columns, values); err != nil {
+			return err
+		}
+		return browserRowSavedMsg{message: "Row inserted"}
+	}
+}
+
+func (v *BrowserView) updateRowForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case browserRowSavedMsg:
+		v.closeRowForm()
+		v.status = msg.message
+		v.err = nil
+		return v, v.loadData
+
+	case error:
+		v.err = msg
+		if v.mode == browserModeConfirmDelete {
+			v.mode = browserModeNormal
+		}
+		return v, nil
+
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		v.table.SetHeight(msg.Height - 8)
+		return v, nil
+
+	case tea.KeyMsg:
+		if v.mode == browserModeConfirmDelete {
+			switch msg.String() {
+			case "y", "Y":
+				key := v.formKey
+				v.mode = browserModeNormal
+				return v, func() tea.Msg {
+					if err := v.conn.DeleteRow(v.tableName, key); err != nil {
+						return err
+					}
+					return browserRowSavedMsg{message: "Row deleted"}
+				}
+			case "n", "N", "esc":
+				v.mode = browserModeNormal
+			}
+			return v, nil
+		}
+
+		switch msg.String() {
+		case "esc":
+			v.closeRowForm()
+			v.err = nil
+			return v, nil
+		case "ctrl+s":
+			return v, v.saveRowForm()
+		case "tab", "down", "enter", "shift+tab", "up":
+			v.formInputs[v.formFocus].Blur()
+			v.formInputs[v.formFocus].PromptStyle = blurredStyle
+			if msg.String() == "shift+tab" || msg.String() == "up" {
+				v.formFocus = (v.formFocus - 1 + len(v.formInputs)) % len(v.formInputs)
+			} else {
+				v.formFocus = (v.formFocus + 1) % len(v.formInputs)
+			}
+			v.formInputs[v.formFocus].Focus()
+			v.formInputs[v.formFocus].PromptStyle = focusedStyle
+			return v, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	v.formInputs[v.formFocus], cmd = v.formInputs[v.formFocus].Update(msg)
+	return v, cmd
+}
+
+func (v *BrowserView) renderRowForm() string {
+	var b strings.Builder
+
+	if v.mode == browserModeEdit {
+		b.WriteString(headerStyle.Render("Edit Row"))
+	} else {
+		b.WriteString(headerStyle.Render("New Row"))
+	}
+	b.WriteString("\n\n")
+
+	// Only show the inputs around the focused one on small terminals
+	visible := max(3, v.height-16)
+	first := 0
+	if v.formFocus >= visible {
+		first = v.formFocus - visible + 1
+	}
+	last := min(len(v.formInputs), first+visible)
+	if first > 0 {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  ↑ %d more", first)))
+		b.WriteString("\n")
+	}
+	for _, input := range v.formInputs[first:last] {
+		b.WriteString(input.View())
+		b.WriteString("\n")
+	}
+	if last < len(v.formInputs) {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  ↓ %d more", len(v.formInputs)-last)))
+		b.WriteString("\n")
+	}
+
+	// Preview the statement that will run; values are bound, never interpolated
+	columns, values := v.formChanges()
+	b.WriteString("\n")
+	if len(columns) > 0 {
+		var query string
+		if v.mode == browserModeEdit {
+			query, _ = v.conn.BuildUpdateRow(v.tableName, v.formKey, columns, values)
+		} else {
+			query, _ = v.conn.BuildInsertRow(v.tableName, columns, values)
+		}
+		b.WriteString(mutedStyle.Render(query))
+	} else {
+		b.WriteString(mutedStyle.Render("No changes yet"))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(helpStyle.Render("Tab/Enter: Next field | Shift+Tab: Previous | Ctrl+S: Save | Esc: Cancel"))
+	return b.String()
+}
+
+func (v *BrowserView) renderDeleteConfirm() string {
+	var b strings.Builder
+
+	b.WriteString(errorStyle.Render("Delete this row?"))
+	b.WriteString("\n\n")
+	for i, col := range v.formKey.Columns {
+		b.WriteString(fmt.Sprintf("  %s = %s\n", col, v.formKey.Values[i]))
+	}
+	query, _ := v.conn.BuildDeleteRow(v.tableName, v.formKey)
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render(query))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("y: Delete | n/Esc: Cancel"))
+	return b.String()
+}
+
+// openExportPrompt asks where to export every row matching the current
+// filters, in the current sort order
+func (v *BrowserView) openExportPrompt() tea.Cmd {
+	if len(v.columns) == 0 {
+		return nil
+	}
+	if v.exportFormat == "" {
+		v.exportFormat = db.ResultFormatCSV
+	}
+	v.exportInput.SetValue(db.DefaultResultExportPath(v.tableName, v.exportFormat))
+	v.exportInput.CursorEnd()
+	v.exportInput.Focus()
+	v.table.Blur()
+	v.mode = browserModeExport
+	v.err = nil
+	return textinput.Blink
+}
+
+func (v *BrowserView) closeExportPrompt() {
+	v.exportInput.Blur()
+	v.table.Focus()
+	v.mode = browserModeNormal
+	v.exporting = false
+}
+
+func (v *BrowserView) updateExportPrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case browserExportedMsg:
+		v.exporting = false
+		if msg.err != nil {
+			v.err = msg.err
+			return v, nil
+		}
+		v.closeExportPrompt()
+		v.status = msg.message
+		return v, nil
+
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		v.table.SetHeight(msg.Height - 8)
+		return v, nil
 
+	case tea.KeyMsg:
+		if v.exporting {
+			return v, nil
+		}
+		switch msg.String() {
+		case "esc":
+			v.closeExportPrompt()
+			return v, nil
+		case "tab":
+			// Switch format and keep the file extension in step
+			old := v.exportFormat
+			v.exportFormat = db.ResultFormatSQL
+			if old == db.ResultFormatSQL {
+				v.exportFormat = db.ResultFormatCSV
+			}
+			path := v.exportInput.Value()
+			if strings.HasSuffix(path, "."+old) {
+				v.exportInput.SetValue(strings.TrimSuffix(path, old) + v.exportFormat)
+				v.exportInput.CursorEnd()
+			}
+			return v, nil
+		case "enter":
+			path := strings.TrimSpace(v.exportInput.Value())
+			if path == "" {
+				return v, nil
+			}
+			v.exporting = true
+			v.err = nil
+			return v, v.exportResult(path, v.exportFormat)
+		}
+	}
+
+	var cmd tea.Cmd
+	v.exportInput, cmd = v.exportInput.Update(msg)
+	return v, cmd
+}
+
+func (v *BrowserView) exportResult(path, format string) tea.Cmd {
+	opts := db.TableDataOptions{
+		OrderBy:  v.sortColumn,
+		Desc:     v.sortDesc,
+		TieBreak: v.primaryKey,
+		Filters:  make(map[string]string, len(v.filters)),
+	}
+	for col, expr := range v.filters {
+		opts.Filters[col] = expr
+	}
+
+	return func() tea.Msg {
+		count, err := v.conn.ExportTableResult(v.tableName, opts, format, path)
+		if err != nil {
+			return browserExportedMsg{err: err}
+		}
+		return browserExportedMsg{message: fmt.Sprintf("Exported %d row(s) to %s", count, path)}
+	}
+}
+
+func (v *BrowserView) renderExportPrompt() string {
+	var b strings.Builder
+
+	b.WriteString(headerStyle.Render("Export visible result"))
+	b.WriteString("\n")
+	scope := fmt.Sprintf("All %d row(s) matching the current filters", v.total)
+	if v.sortColumn != "" {
+		dir := "ascending"
+		if v.sortDesc {
+			dir = "descending"
+		}
+		scope += fmt.Sprintf(", sorted by %s (%s)", v.sortColumn, dir)
+	}
+	b.WriteString(mutedStyle.Render(scope))
+	b.WriteString("\n\n")
+
+	b.WriteString(v.exportInput.View())
+	b.WriteString("\n")
+	csvLabel, sqlLabel := "( ) CSV", "( ) SQL"
+	if v.exportFormat == db.ResultFormatSQL {
+		sqlLabel = "(x) SQL"
+	} else {
+		csvLabel = "(x) CSV"
+	}
+	b.WriteString("Format: " + csvLabel + "  " + sqlLabel)
+	b.WriteString("\n\n")
+
+	if v.exporting {
+		b.WriteString("Exporting...")
+	} else {
+		b.WriteString(helpStyle.Render("Enter: Export | Tab: Switch format | Esc: Cancel"))
+	}
 	return b.String()
 }
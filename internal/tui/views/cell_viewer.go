@@ -0,0 +1,305 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type cellKind int
+
+const (
+	cellText cellKind = iota
+	cellJSON
+	cellBinary
+)
+
+func (k cellKind) String() string {
+	switch k {
+	case cellJSON:
+		return "JSON"
+	case cellBinary:
+		return "binary"
+	}
+	return "text"
+}
+
+// extension is the file extension a saved cell of this kind gets
+func (k cellKind) extension() string {
+	switch k {
+	case cellJSON:
+		return "json"
+	case cellBinary:
+		return "bin"
+	}
+	return "txt"
+}
+
+// classifyCell tells binary values, which are not valid UTF-8 or hold
+// control characters, from JSON objects and arrays and from plain text
+func classifyCell(value string) cellKind {
+	if isBinaryCell(value) {
+		return cellBinary
+	}
+	trimmed := strings.TrimSpace(value)
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		return cellJSON
+	}
+	return cellText
+}
+
+func isBinaryCell(value string) bool {
+	if !utf8.ValidString(value) {
+		return true
+	}
+	for _, r := range value {
+		if (r < 0x20 && r != '\n' && r != '\r' && r != '\t') || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// cellGridText is how a cell shows in the data grid; binary values would
+// garble the terminal, so only their size is shown
+func cellGridText(value string) string {
+	if isBinaryCell(value) {
+		return fmt.Sprintf("<binary %s>", db.FormatSize(int64(len(value))))
+	}
+	return value
+}
+
+// cellViewer shows one cell in full: text wrapped, JSON pretty-printed and
+// binary values as a hex dump, scrolled a page at a time
+type cellViewer struct {
+	table  string
+	column string
+	value  string
+	kind   cellKind
+	hex    bool // Show the hex dump, whatever the kind
+	lines  []string
+	offset int // First line shown
+	width  int
+	height int
+
+	saving    bool
+	saveInput textinput.Model
+	status    string
+	err       error
+}
+
+type cellSavedMsg struct {
+	path string
+	size int
+	err  error
+}
+
+func newCellViewer(table, column, value string, width, height int) *cellViewer {
+	si := textinput.New()
+	si.Prompt = "File: "
+	si.CharLimit = 512
+
+	c := &cellViewer{
+		table:     table,
+		column:    column,
+		value:     value,
+		kind:      classifyCell(value),
+		width:     width,
+		height:    height,
+		saveInput: si,
+	}
+	c.hex = c.kind == cellBinary
+	c.layout()
+	return c
+}
+
+// layout renders the value into lines for the current width
+func (c *cellViewer) layout() {
+	var text string
+	switch {
+	case c.hex:
+		text = strings.TrimSuffix(hex.Dump([]byte(c.value)), "\n")
+	case c.kind == cellJSON:
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, []byte(strings.TrimSpace(c.value)), "", "  "); err == nil {
+			text = pretty.String()
+		} else {
+			text = c.value
+		}
+	default:
+		text = c.value
+	}
+	if !c.hex {
+		text = lipgloss.NewStyle().Width(max(c.width-4, 20)).Render(strings.ReplaceAll(text, "\r\n", "\n"))
+	}
+	c.lines = strings.Split(text, "\n")
+	for i, line := range c.lines {
+		c.lines[i] = strings.TrimRight(line, " ") // Wrapping pads to the width
+	}
+	c.clampOffset()
+}
+
+// pageLines is how many lines fit between the header and the help
+func (c *cellViewer) pageLines() int {
+	return max(c.height-12, 5)
+}
+
+func (c *cellViewer) clampOffset() {
+	c.offset = min(c.offset, len(c.lines)-c.pageLines())
+	c.offset = max(c.offset, 0)
+}
+
+func (c *cellViewer) resize(width, height int) {
+	c.width, c.height = width, height
+	c.layout()
+}
+
+func (c *cellViewer) openSavePrompt() tea.Cmd {
+	c.saveInput.SetValue(fmt.Sprintf("%s-%s.%s", c.table, c.column, c.kind.extension()))
+	c.saveInput.CursorEnd()
+	c.saveInput.Focus()
+	c.saving = true
+	c.err = nil
+	return textinput.Blink
+}
+
+// save writes the cell's raw content, not its rendering, to path
+func (c *cellViewer) save(path string) tea.Cmd {
+	value := c.value
+	return func() tea.Msg {
+		err := os.WriteFile(path, []byte(value), 0644)
+		if err != nil {
+			err = fmt.Errorf("failed to save the cell: %w", err)
+		}
+		return cellSavedMsg{path: path, size: len(value), err: err}
+	}
+}
+
+// update handles a message and reports whether the viewer was closed
+func (c *cellViewer) update(msg tea.Msg) (bool, tea.Cmd) {
+	switch msg := msg.(type) {
+	case cellSavedMsg:
+		if msg.err != nil {
+			c.err = msg.err
+			return false, nil
+		}
+		c.saving = false
+		c.saveInput.Blur()
+		c.status = fmt.Sprintf("Saved %s to %s", db.FormatSize(int64(msg.size)), msg.path)
+		return false, nil
+
+	case tea.KeyMsg:
+		if c.saving {
+			switch msg.String() {
+			case "esc":
+				c.saving = false
+				c.saveInput.Blur()
+				return false, nil
+			case "enter":
+				path := strings.TrimSpace(c.saveInput.Value())
+				if path == "" {
+					return false, nil
+				}
+				return false, c.save(path)
+			}
+			var cmd tea.Cmd
+			c.saveInput, cmd = c.saveInput.Update(msg)
+			return false, cmd
+		}
+
+		c.status = ""
+		switch msg.String() {
+		case "esc", "backspace", "q":
+			return true, nil
+		case "up", "k":
+			c.offset--
+		case "down", "j":
+			c.offset++
+		case "pgup", "b":
+			c.offset -= c.pageLines()
+		case "pgdown", "f", " ":
+			c.offset += c.pageLines()
+		case "g", "home":
+			c.offset = 0
+		case "G", "end":
+			c.offset = len(c.lines)
+		case "x":
+			c.hex = !c.hex
+			c.offset = 0
+			c.layout()
+		case "w":
+			return false, c.openSavePrompt()
+		}
+		c.clampOffset()
+	}
+	return false, nil
+}
+
+func (c *cellViewer) view() string {
+	var b strings.Builder
+
+	b.WriteString(headerStyle.Render(fmt.Sprintf("Cell: %s", c.column)))
+	b.WriteString("\n")
+	info := fmt.Sprintf("%s, %s", c.kind, db.FormatSize(int64(len(c.value))))
+	if c.hex && c.kind != cellBinary {
+		info += ", shown as hex"
+	}
+	if len(c.lines) > c.pageLines() {
+		last := min(c.offset+c.pageLines(), len(c.lines))
+		info += fmt.Sprintf(" | lines %d-%d of %d", c.offset+1, last, len(c.lines))
+	}
+	b.WriteString(mutedStyle.Render(info))
+	b.WriteString("\n\n")
+
+	end := min(c.offset+c.pageLines(), len(c.lines))
+	for _, line := range c.lines[c.offset:end] {
+		b.WriteString("  ")
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if c.err != nil {
+		b.WriteString(renderError(c.err))
+		b.WriteString("\n")
+	}
+	if c.status != "" {
+		b.WriteString(successStyle.Render(c.status))
+		b.WriteString("\n")
+	}
+	if c.saving {
+		b.WriteString(c.saveInput.View())
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("Enter: Save the raw content | Esc: Cancel"))
+		return b.String()
+	}
+	b.WriteString(helpStyle.Render("↑/↓: Scroll | PgUp/PgDn: Page | g/G: Top/Bottom | x: Toggle hex dump | w: Save to file | Esc: Back"))
+	return b.String()
+}
@@ -0,0 +1,59 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/blubskye/yandere_sql_manager/internal/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// terminal is the program output OSC 52 copies are written to
+var terminal io.Writer = os.Stdout
+
+// SetTerminal sets the program output, so OSC 52 copies go through the same
+// writer as the rendered frames
+func SetTerminal(w io.Writer) {
+	terminal = w
+}
+
+// clipboardCopiedMsg reports a copy to the clipboard; what names the text
+// in the status line
+type clipboardCopiedMsg struct {
+	what string
+	via  string
+	err  error
+}
+
+// status is the line a view shows after a successful copy
+func (m clipboardCopiedMsg) status() string {
+	return fmt.Sprintf("Copied %s to the clipboard (%s)", m.what, m.via)
+}
+
+// copyToClipboard puts text on the clipboard, over OSC 52 when ysm runs
+// over SSH, and reports back with a clipboardCopiedMsg
+func copyToClipboard(what, text string) tea.Cmd {
+	return func() tea.Msg {
+		via, err := clipboard.Copy(text, terminal)
+		return clipboardCopiedMsg{what: what, via: via, err: err}
+	}
+}
@@ -20,11 +20,15 @@ package views
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/blubskye/yandere_sql_manager/internal/alert"
+	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -37,11 +41,13 @@ const (
 	clusterModeNodes
 	clusterModeGalera
 	clusterModeReplication
+	clusterModeLogical
 )
 
 // ClusterView shows cluster and replication status
 type ClusterView struct {
 	conn        *db.Connection
+	alerts      *alert.Monitor // nil when alerts aren't configured
 	width       int
 	height      int
 	err         error
@@ -51,54 +57,97 @@ type ClusterView struct {
 	lastUpdate  time.Time
 	statusMu    sync.RWMutex // Protects status data for background updates
 	stopChan    chan struct{}
+	keybindings *config.KeyBindings
 
 	// Status data
 	clusterStatus *db.ClusterStatus
 	galeraStatus  *db.GaleraStatus
 	replStatus    *db.ReplicationStatus
+	logicalStatus *db.LogicalReplicationStatus
+
+	// Replication actions
+	pending    replicationAction // Waiting for y to run, "" when none
+	running    bool
+	actionDone string // Result of the last action
+	changeForm *changePrimaryForm
+
+	// Logical replication actions
+	logicalCursor int    // Selected publication or subscription
+	logicalTarget string // Publication or subscription a drop applies to
+	logicalForm   *logicalForm
 }
 
-// Styles for the cluster view
-var (
-	clusterBoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#FF69B4")).
-			Padding(0, 1)
+// replicationAction is a state-changing action in the replication tab
+type replicationAction string
 
-	clusterTitleStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("#FF69B4"))
+const (
+	replicationStart   replicationAction = "start"
+	replicationStop    replicationAction = "stop"
+	replicationSkip    replicationAction = "skip"
+	replicationChange  replicationAction = "change"
+	replicationPromote replicationAction = "promote"
+
+	logicalCreatePublication  replicationAction = "create_publication"
+	logicalDropPublication    replicationAction = "drop_publication"
+	logicalCreateSubscription replicationAction = "create_subscription"
+	logicalDropSubscription   replicationAction = "drop_subscription"
+)
 
-	clusterHealthyStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("#44FF44"))
+// Change primary form fields, in tab order
+const (
+	changeFieldHost = iota
+	changeFieldPort
+	changeFieldUser
+	changeFieldPassword
+	changeFieldGTID
+	changeFieldLogFile
+	changeFieldLogPos
+	changeFieldCount
+)
 
-	clusterUnhealthyStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("#FF4444"))
+// changePrimaryForm collects the CHANGE MASTER TO settings
+type changePrimaryForm struct {
+	inputs  []textinput.Model // Indexed by field; the GTID field has none
+	useGTID bool
+	focused int
+	err     error
+}
 
-	clusterWarningStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("#FFAA00"))
+type replicationActionMsg struct {
+	action replicationAction
+	err    error
+}
 
-	clusterNodeStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FFFFFF"))
+// Styles for the cluster view, set by ApplyTheme
+var (
+	clusterBoxStyle       lipgloss.Style
+	clusterTitleStyle     lipgloss.Style
+	clusterHealthyStyle   lipgloss.Style
+	clusterUnhealthyStyle lipgloss.Style
+	clusterWarningStyle   lipgloss.Style
+	clusterNodeStyle      lipgloss.Style
 )
 
 // NewClusterView creates a new cluster view
-func NewClusterView(conn *db.Connection, width, height int) *ClusterView {
+func NewClusterView(conn *db.Connection, alerts *alert.Monitor, width, height int) *ClusterView {
 	return &ClusterView{
-		conn:     conn,
-		width:    width,
-		height:   height,
-		loading:  true,
-		mode:     clusterModeStatus,
-		stopChan: make(chan struct{}),
+		conn:        conn,
+		alerts:      alerts,
+		width:       width,
+		height:      height,
+		loading:     true,
+		mode:        clusterModeStatus,
+		stopChan:    make(chan struct{}),
+		keybindings: loadKeyBindings(),
 	}
 }
 
 // Init initializes the view
 func (v *ClusterView) Init() tea.Cmd {
+	// Like dashboard sampling, alerting keeps running for the rest of the session
+	if v.alerts != nil {
+		v.alerts.Start(v.conn)
+	}
 	return v.loadClusterStatus
 }
 
@@ -222,6 +271,40 @@ type clusterTickMsg struct{}
 func (v *ClusterView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if v.changeForm != nil && v.pending == "" {
+			return v.updateChangeForm(msg)
+		}
+		if v.logicalForm != nil && v.pending == "" {
+			return v.updateLogicalForm(msg)
+		}
+		if v.pending != "" {
+			if msg.String() == "y" {
+				return v, v.runReplicationAction()
+			}
+			v.pending = ""
+			return v, nil
+		}
+		if v.mode == clusterModeReplication && !v.running {
+			if action := v.replicationActionForKey(msg.String()); action != "" {
+				v.actionDone = ""
+				v.err = nil
+				if action == replicationChange {
+					v.changeForm = v.newChangePrimaryForm()
+					return v, textinput.Blink
+				}
+				v.pending = action
+				return v, nil
+			}
+		}
+		if v.mode == clusterModeLogical && !v.running {
+			if cmd, handled := v.updateLogicalKey(msg.String()); handled {
+				return v, cmd
+			}
+		}
+		if cmd := showHelp(v.keybindings, "cluster", msg.String()); cmd != nil {
+			return v, cmd
+		}
+
 		switch msg.String() {
 		case "1":
 			v.mode = clusterModeStatus
@@ -244,6 +327,12 @@ func (v *ClusterView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return v, v.loadReplicationStatus
 			}
 			return v, v.loadClusterStatus
+		case "5":
+			if v.conn.Config.Type == db.DatabaseTypePostgres {
+				v.mode = clusterModeLogical
+				v.loading = true
+				return v, v.loadLogicalStatus
+			}
 		case "r":
 			v.loading = true
 			return v, v.getLoadCmd()
@@ -303,6 +392,31 @@ func (v *ClusterView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return v, nil
 
+	case logicalStatusLoadedMsg:
+		v.statusMu.Lock()
+		v.logicalStatus = msg.status
+		v.statusMu.Unlock()
+		v.clampLogicalCursor()
+		v.loading = false
+		v.lastUpdate = time.Now()
+		v.err = nil
+		if v.autoRefresh {
+			return v, v.tick()
+		}
+		return v, nil
+
+	case replicationActionMsg:
+		v.running = false
+		if msg.err != nil {
+			v.err = msg.err
+			return v, nil
+		}
+		v.changeForm = nil
+		v.logicalForm = nil
+		v.actionDone = replicationActionDone(msg.action)
+		v.loading = true
+		return v, v.getLoadCmd()
+
 	case clusterTickMsg:
 		if v.autoRefresh {
 			v.loading = true
@@ -328,6 +442,8 @@ func (v *ClusterView) getLoadCmd() tea.Cmd {
 			return v.loadReplicationStatus
 		}
 		return v.loadClusterStatus
+	case clusterModeLogical:
+		return v.loadLogicalStatus
 	default:
 		return v.loadClusterStatus
 	}
@@ -343,6 +459,8 @@ func (v *ClusterView) getLoadCmdBackground() tea.Cmd {
 			return v.loadReplicationStatusBackground()
 		}
 		return v.loadClusterStatusBackground()
+	case clusterModeLogical:
+		return v.loadLogicalStatusBackground()
 	default:
 		return v.loadClusterStatusBackground()
 	}
@@ -367,7 +485,7 @@ func (v *ClusterView) View() string {
 	}
 
 	if v.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", v.err)))
+		b.WriteString(renderError(v.err))
 		b.WriteString("\n\n")
 	}
 
@@ -385,6 +503,10 @@ func (v *ClusterView) View() string {
 		b.WriteString(v.renderGalera())
 	case clusterModeReplication:
 		b.WriteString(v.renderReplication())
+		b.WriteString(v.renderReplicationActions())
+	case clusterModeLogical:
+		b.WriteString(v.renderLogical())
+		b.WriteString(v.renderReplicationActions())
 	}
 
 	b.WriteString("\n\n")
@@ -402,34 +524,54 @@ func (v *ClusterView) View() string {
 		autoStatus = "on (5s)"
 	}
 
+	if line := renderAlertStatus(v.alerts); line != "" {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
 	b.WriteString(mutedStyle.Render(fmt.Sprintf("%s | Auto-refresh: %s", updateStatus, autoStatus)))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("1-4: Switch tabs | r: Refresh | a: Auto-refresh | Esc: Back | q: Quit"))
+	helpKey := v.keybindings.GetKey("cluster", config.ActionHelp)
+	help := "1-4: Switch tabs | r: Refresh | a: Auto-refresh | " + helpKey + ": Help | Esc: Back | q: Quit"
+	if v.conn.Config.Type == db.DatabaseTypePostgres {
+		help = "1-5: Switch tabs | r: Refresh | a: Auto-refresh | " + helpKey + ": Help | Esc: Back | q: Quit"
+	}
+	switch v.mode {
+	case clusterModeReplication:
+		if actions := v.replicationHelp(); actions != "" {
+			help = actions + " | " + help
+		}
+	case clusterModeLogical:
+		help = logicalHelp + " | " + help
+	}
+	b.WriteString(helpStyle.Render(help))
 
 	return b.String()
 }
 
 func (v *ClusterView) renderTabs() string {
-	tabs := []string{"[1] Status", "[2] Nodes"}
+	type tab struct {
+		label string
+		mode  clusterMode
+	}
+	tabs := []tab{{"[1] Status", clusterModeStatus}, {"[2] Nodes", clusterModeNodes}}
 
 	if v.conn.Config.Type == db.DatabaseTypeMariaDB {
-		tabs = append(tabs, "[3] Galera")
+		tabs = append(tabs, tab{"[3] Galera", clusterModeGalera})
 	}
 
-	tabs = append(tabs, "[4] Replication")
+	tabs = append(tabs, tab{"[4] Replication", clusterModeReplication})
+
+	if v.conn.Config.Type == db.DatabaseTypePostgres {
+		tabs = append(tabs, tab{"[5] Logical", clusterModeLogical})
+	}
 
 	// Highlight current tab
 	var rendered []string
-	for i, tab := range tabs {
-		tabMode := clusterMode(i)
-		if i == 3 {
-			tabMode = clusterModeReplication
-		}
-
-		if tabMode == v.mode {
-			rendered = append(rendered, selectedStyle.Render(tab))
+	for _, t := range tabs {
+		if t.mode == v.mode {
+			rendered = append(rendered, selectedStyle.Render(t.label))
 		} else {
-			rendered = append(rendered, mutedStyle.Render(tab))
+			rendered = append(rendered, mutedStyle.Render(t.label))
 		}
 	}
 
@@ -444,8 +586,7 @@ func (v *ClusterView) renderStatus() string {
 	var b strings.Builder
 	status := v.clusterStatus
 
-	leftWidth := (v.width - 6) / 2
-	rightWidth := leftWidth
+	leftWidth, rightWidth := panelWidths(v.width)
 
 	// Overview Box
 	var overview strings.Builder
@@ -495,7 +636,7 @@ func (v *ClusterView) renderStatus() string {
 
 	localNodeBox := clusterBoxStyle.Width(rightWidth).Render(localNode.String())
 
-	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, overviewBox, "  ", localNodeBox))
+	b.WriteString(joinPanels(overviewBox, localNodeBox))
 
 	if status.ErrorMessage != "" {
 		b.WriteString("\n\n")
@@ -586,8 +727,7 @@ func (v *ClusterView) renderGalera() string {
 	status := v.galeraStatus
 	var b strings.Builder
 
-	leftWidth := (v.width - 6) / 2
-	rightWidth := leftWidth
+	leftWidth, rightWidth := panelWidths(v.width)
 
 	// Cluster Info Box
 	var cluster strings.Builder
@@ -620,7 +760,7 @@ func (v *ClusterView) renderGalera() string {
 
 	localBox := clusterBoxStyle.Width(rightWidth).Render(local.String())
 
-	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, clusterBox, "  ", localBox))
+	b.WriteString(joinPanels(clusterBox, localBox))
 
 	if status.FlowControl {
 		b.WriteString("\n\n")
@@ -697,8 +837,7 @@ func (v *ClusterView) renderMariaDBReplication() string {
 	b.WriteString(clusterTitleStyle.Render("MariaDB Replication"))
 	b.WriteString("\n\n")
 
-	leftWidth := (v.width - 6) / 2
-	rightWidth := leftWidth
+	leftWidth, rightWidth := panelWidths(v.width)
 
 	if status.IsMaster {
 		// Master info
@@ -780,8 +919,352 @@ func (v *ClusterView) renderMariaDBReplication() string {
 	return b.String()
 }
 
+// replicationActionForKey maps a key in the replication tab to the action
+// it starts, if that action applies to this server
+func (v *ClusterView) replicationActionForKey(key string) replicationAction {
+	if v.conn.Config.Type == db.DatabaseTypePostgres {
+		if key == "p" && v.clusterStatus != nil && !v.clusterStatus.IsPrimary {
+			return replicationPromote
+		}
+		return ""
+	}
+
+	isReplica := v.replStatus != nil && v.replStatus.IsReplica
+	switch key {
+	case "s":
+		if isReplica {
+			return replicationStart
+		}
+	case "x":
+		if isReplica {
+			return replicationStop
+		}
+	case "k":
+		if isReplica {
+			return replicationSkip
+		}
+	case "m":
+		return replicationChange
+	}
+	return ""
+}
+
+// replicationHelp lists the replication actions available on this server
+func (v *ClusterView) replicationHelp() string {
+	if v.conn.Config.Type == db.DatabaseTypePostgres {
+		if v.clusterStatus != nil && !v.clusterStatus.IsPrimary {
+			return "p: Promote"
+		}
+		return ""
+	}
+	if v.replStatus != nil && v.replStatus.IsReplica {
+		return "s: Start | x: Stop | k: Skip error | m: Change primary"
+	}
+	return "m: Change primary"
+}
+
+// replicationActionPrompt describes what an action is about to do
+func (v *ClusterView) replicationActionPrompt(action replicationAction) string {
+	switch action {
+	case replicationStart:
+		return "Start the replica threads (START SLAVE)?"
+	case replicationStop:
+		return "Stop the replica threads (STOP SLAVE)? Changes from the primary will queue up until it is started again."
+	case replicationSkip:
+		return "Skip the event the replica stopped on? Its changes will be missing here, which can leave the data out of sync."
+	case replicationChange:
+		stmt, _ := v.conn.ChangePrimaryStatement(v.changePrimaryOptions(), true)
+		return "Stop the replica, run " + stmt + " and start it again?"
+	case replicationPromote:
+		return "Promote this standby to primary (pg_promote)? It stops following the current primary and can't be turned back into a standby from here."
+	case logicalCreatePublication, logicalCreateSubscription:
+		return "Run " + v.logicalStatement() + "?"
+	case logicalDropPublication:
+		return fmt.Sprintf("Drop publication %s? Its subscribers stop receiving changes and report errors until they drop their subscriptions.", v.logicalTarget)
+	case logicalDropSubscription:
+		return fmt.Sprintf("Drop subscription %s? Its slot on the publisher is dropped too, so re-subscribing copies the data again.", v.logicalTarget)
+	}
+	return ""
+}
+
+func replicationActionDone(action replicationAction) string {
+	switch action {
+	case replicationStart:
+		return "Replica started"
+	case replicationStop:
+		return "Replica stopped"
+	case replicationSkip:
+		return "Skipped one event and restarted the replica"
+	case replicationChange:
+		return "Replica now follows the new primary"
+	case replicationPromote:
+		return "Standby promoted to primary"
+	case logicalCreatePublication:
+		return "Publication created"
+	case logicalDropPublication:
+		return "Publication dropped"
+	case logicalCreateSubscription:
+		return "Subscription created"
+	case logicalDropSubscription:
+		return "Subscription dropped"
+	}
+	return ""
+}
+
+func (v *ClusterView) runReplicationAction() tea.Cmd {
+	action := v.pending
+	v.pending = ""
+	v.running = true
+	v.err = nil
+
+	conn := v.conn
+	var opts db.ChangePrimaryOptions
+	if action == replicationChange {
+		opts = v.changePrimaryOptions()
+	}
+	var pubOpts db.CreatePublicationOptions
+	var subOpts db.CreateSubscriptionOptions
+	switch action {
+	case logicalCreatePublication:
+		pubOpts = v.publicationOptions()
+	case logicalCreateSubscription:
+		subOpts = v.subscriptionOptions()
+	}
+	target := v.logicalTarget
+	return func() tea.Msg {
+		var err error
+		switch action {
+		case replicationStart:
+			err = conn.StartReplica()
+		case replicationStop:
+			err = conn.StopReplica()
+		case replicationSkip:
+			err = conn.SkipReplicationError()
+		case replicationChange:
+			err = conn.ChangePrimary(opts)
+		case replicationPromote:
+			err = conn.PromoteStandby()
+		case logicalCreatePublication:
+			err = conn.CreatePublication(pubOpts)
+		case logicalDropPublication:
+			err = conn.DropPublication(target)
+		case logicalCreateSubscription:
+			err = conn.CreateSubscription(subOpts)
+		case logicalDropSubscription:
+			err = conn.DropSubscription(target)
+		}
+		return replicationActionMsg{action: action, err: err}
+	}
+}
+
+func (v *ClusterView) newChangePrimaryForm() *changePrimaryForm {
+	form := &changePrimaryForm{
+		inputs:  make([]textinput.Model, changeFieldCount),
+		useGTID: true,
+	}
+	placeholders := map[int]string{
+		changeFieldHost:     "Primary host",
+		changeFieldPort:     "3306",
+		changeFieldUser:     "Keep the current user",
+		changeFieldPassword: "Keep the current password",
+		changeFieldLogFile:  "mariadb-bin.000001",
+		changeFieldLogPos:   "4",
+	}
+	for field, placeholder := range placeholders {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.Width = 40
+		if field == changeFieldPassword {
+			input.EchoMode = textinput.EchoPassword
+		}
+		form.inputs[field] = input
+	}
+	if v.replStatus != nil && v.replStatus.IsReplica {
+		form.inputs[changeFieldHost].SetValue(v.replStatus.MasterHost)
+		if v.replStatus.MasterPort != 0 {
+			form.inputs[changeFieldPort].SetValue(strconv.Itoa(v.replStatus.MasterPort))
+		}
+	}
+	form.inputs[changeFieldHost].Focus()
+	return form
+}
+
+func (form *changePrimaryForm) focus(field int) {
+	form.focused = field
+	for i := range form.inputs {
+		form.inputs[i].Blur()
+	}
+	if field != changeFieldGTID {
+		form.inputs[field].Focus()
+	}
+}
+
+// changePrimaryOptions reads the change primary form
+func (v *ClusterView) changePrimaryOptions() db.ChangePrimaryOptions {
+	form := v.changeForm
+	value := func(field int) string {
+		return strings.TrimSpace(form.inputs[field].Value())
+	}
+	port, _ := strconv.Atoi(value(changeFieldPort))
+	pos, _ := strconv.ParseInt(value(changeFieldLogPos), 10, 64)
+	return db.ChangePrimaryOptions{
+		Host:     value(changeFieldHost),
+		Port:     port,
+		User:     value(changeFieldUser),
+		Password: form.inputs[changeFieldPassword].Value(),
+		UseGTID:  form.useGTID,
+		LogFile:  value(changeFieldLogFile),
+		LogPos:   pos,
+	}
+}
+
+func (v *ClusterView) updateChangeForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	form := v.changeForm
+	if v.running {
+		return v, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		v.changeForm = nil
+		v.err = nil
+		return v, nil
+	case "tab", "down":
+		form.focus((form.focused + 1) % changeFieldCount)
+		return v, nil
+	case "shift+tab", "up":
+		form.focus((form.focused + changeFieldCount - 1) % changeFieldCount)
+		return v, nil
+	case "enter":
+		if _, err := v.conn.ChangePrimaryStatement(v.changePrimaryOptions(), true); err != nil {
+			form.err = err
+			return v, nil
+		}
+		if value := strings.TrimSpace(form.inputs[changeFieldPort].Value()); value != "" {
+			if _, err := strconv.Atoi(value); err != nil {
+				form.err = fmt.Errorf("invalid port: %s", value)
+				return v, nil
+			}
+		}
+		form.err = nil
+		v.pending = replicationChange
+		return v, nil
+	}
+
+	if form.focused == changeFieldGTID {
+		if msg.String() == " " {
+			form.useGTID = !form.useGTID
+		}
+		return v, nil
+	}
+
+	var cmd tea.Cmd
+	form.inputs[form.focused], cmd = form.inputs[form.focused].Update(msg)
+	return v, cmd
+}
+
+// renderReplicationActions shows the change primary or logical replication
+// form, a pending confirmation or the outcome of the last action
+func (v *ClusterView) renderReplicationActions() string {
+	var b strings.Builder
+
+	if v.logicalForm != nil {
+		b.WriteString(v.renderLogicalForm())
+	}
+
+	if form := v.changeForm; form != nil {
+		b.WriteString("\n\n")
+		b.WriteString(clusterTitleStyle.Render("Change Primary"))
+		b.WriteString("\n\n")
+
+		label := func(field int, text string) string {
+			if form.focused == field {
+				return focusedStyle.Render(text)
+			}
+			return blurredStyle.Render(text)
+		}
+		fields := []struct {
+			field int
+			text  string
+		}{
+			{changeFieldHost, "Host:     "},
+			{changeFieldPort, "Port:     "},
+			{changeFieldUser, "User:     "},
+			{changeFieldPassword, "Password: "},
+		}
+		for _, f := range fields {
+			b.WriteString(label(f.field, f.text))
+			b.WriteString(form.inputs[f.field].View())
+			b.WriteString("\n")
+		}
+		gtid := "[ ]"
+		if form.useGTID {
+			gtid = "[x]"
+		}
+		b.WriteString(label(changeFieldGTID, "Use GTID: "))
+		b.WriteString(gtid)
+		b.WriteString(mutedStyle.Render("  continue from the replica's own GTID position"))
+		b.WriteString("\n")
+		if !form.useGTID {
+			b.WriteString(label(changeFieldLogFile, "Log file: "))
+			b.WriteString(form.inputs[changeFieldLogFile].View())
+			b.WriteString("\n")
+			b.WriteString(label(changeFieldLogPos, "Log pos:  "))
+			b.WriteString(form.inputs[changeFieldLogPos].View())
+			b.WriteString("\n")
+		}
+		if form.err != nil {
+			b.WriteString("\n")
+			b.WriteString(renderError(form.err))
+		}
+		if v.pending == "" && !v.running {
+			b.WriteString("\n")
+			b.WriteString(helpStyle.Render("Tab: Next field | Space: Toggle GTID | Enter: Change primary | Esc: Cancel"))
+		}
+	}
+
+	switch {
+	case v.pending != "":
+		b.WriteString("\n\n")
+		b.WriteString(errorStyle.Render(v.replicationActionPrompt(v.pending) + " (y/n)"))
+	case v.running:
+		b.WriteString("\n\n")
+		b.WriteString(mutedStyle.Render("Working..."))
+	case v.actionDone != "":
+		b.WriteString("\n\n")
+		b.WriteString(successStyle.Render(v.actionDone))
+	}
+	return b.String()
+}
+
 // Helper functions
 
+// renderAlertStatus summarizes the alert monitor's state in one line, or
+// returns "" when alerts aren't configured
+func renderAlertStatus(alerts *alert.Monitor) string {
+	if alerts == nil {
+		return ""
+	}
+	var line string
+	active := alerts.Active()
+	switch {
+	case alerts.LastPoll().IsZero():
+		line = mutedStyle.Render("Alerts: waiting for the first check")
+	case len(active) == 0:
+		line = successStyle.Render("Alerts: all clear")
+	default:
+		var parts []string
+		for _, event := range active {
+			parts = append(parts, fmt.Sprintf("%s %s since %s", event.Check, event.Status, event.Time.Format("15:04:05")))
+		}
+		line = errorStyle.Render("Alerts: " + strings.Join(parts, ", "))
+	}
+	if err := alerts.Err(); err != nil {
+		line += " " + errorStyle.Render(fmt.Sprintf("(delivery failed: %v)", err))
+	}
+	return line
+}
+
 func formatClusterType(t db.ClusterType) string {
 	switch t {
 	case db.ClusterTypeMariaDBGalera:
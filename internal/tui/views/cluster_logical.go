@@ -0,0 +1,499 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const logicalHelp = "↑↓: Select | n: New publication | u: New subscription | d: Drop"
+
+// Publication form fields, in tab order
+const (
+	pubFieldName = iota
+	pubFieldTables
+	pubFieldOperations
+	pubFieldCount
+)
+
+// Subscription form fields, in tab order
+const (
+	subFieldName = iota
+	subFieldHost
+	subFieldPort
+	subFieldUser
+	subFieldPassword
+	subFieldDatabase
+	subFieldPublications
+	subFieldCopyData
+	subFieldCount
+)
+
+// logicalForm collects a new publication or subscription
+type logicalForm struct {
+	action   replicationAction // logicalCreatePublication or logicalCreateSubscription
+	inputs   []textinput.Model // Indexed by field; the copy data field has none
+	copyData bool
+	focused  int
+	err      error
+}
+
+type logicalStatusLoadedMsg struct {
+	status *db.LogicalReplicationStatus
+}
+
+func (v *ClusterView) loadLogicalStatus() tea.Msg {
+	status, err := v.conn.GetLogicalReplicationStatus()
+	if err != nil {
+		return err
+	}
+	return logicalStatusLoadedMsg{status: status}
+}
+
+// loadLogicalStatusBackground fetches logical replication status in a background goroutine
+func (v *ClusterView) loadLogicalStatusBackground() tea.Cmd {
+	return func() tea.Msg {
+		resultChan := make(chan logicalStatusLoadedMsg, 1)
+		errChan := make(chan error, 1)
+
+		go func() {
+			status, err := v.conn.GetLogicalReplicationStatus()
+			if err != nil {
+				errChan <- err
+				return
+			}
+			resultChan <- logicalStatusLoadedMsg{status: status}
+		}()
+
+		select {
+		case result := <-resultChan:
+			return result
+		case err := <-errChan:
+			return err
+		case <-v.stopChan:
+			return nil
+		}
+	}
+}
+
+// logicalItemCount is the number of selectable rows: publications, then subscriptions
+func (v *ClusterView) logicalItemCount() int {
+	if v.logicalStatus == nil {
+		return 0
+	}
+	return len(v.logicalStatus.Publications) + len(v.logicalStatus.Subscriptions)
+}
+
+func (v *ClusterView) clampLogicalCursor() {
+	if v.logicalCursor >= v.logicalItemCount() {
+		v.logicalCursor = max(v.logicalItemCount()-1, 0)
+	}
+}
+
+// selectedLogical returns the drop action and name for the selected row
+func (v *ClusterView) selectedLogical() (replicationAction, string) {
+	if v.logicalCursor >= v.logicalItemCount() {
+		return "", ""
+	}
+	pubs := v.logicalStatus.Publications
+	if v.logicalCursor < len(pubs) {
+		return logicalDropPublication, pubs[v.logicalCursor].Name
+	}
+	return logicalDropSubscription, v.logicalStatus.Subscriptions[v.logicalCursor-len(pubs)].Name
+}
+
+// updateLogicalKey handles the logical tab's keys, reporting whether the
+// key was one of them
+func (v *ClusterView) updateLogicalKey(key string) (tea.Cmd, bool) {
+	switch key {
+	case "up", "k":
+		if v.logicalCursor > 0 {
+			v.logicalCursor--
+		}
+		return nil, true
+	case "down", "j":
+		if v.logicalCursor < v.logicalItemCount()-1 {
+			v.logicalCursor++
+		}
+		return nil, true
+	case "n":
+		v.actionDone = ""
+		v.err = nil
+		v.logicalForm = newPublicationForm()
+		return textinput.Blink, true
+	case "u":
+		v.actionDone = ""
+		v.err = nil
+		v.logicalForm = newSubscriptionForm()
+		return textinput.Blink, true
+	case "d":
+		action, name := v.selectedLogical()
+		if action == "" {
+			return nil, true
+		}
+		v.actionDone = ""
+		v.err = nil
+		v.logicalTarget = name
+		v.pending = action
+		return nil, true
+	}
+	return nil, false
+}
+
+func newPublicationForm() *logicalForm {
+	form := &logicalForm{
+		action: logicalCreatePublication,
+		inputs: make([]textinput.Model, pubFieldCount),
+	}
+	placeholders := map[int]string{
+		pubFieldName:       "Publication name",
+		pubFieldTables:     "Empty for all tables, or public.orders, public.customers",
+		pubFieldOperations: "Empty for all, or insert, update, delete, truncate",
+	}
+	for field, placeholder := range placeholders {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.Width = 60
+		form.inputs[field] = input
+	}
+	form.inputs[pubFieldName].Focus()
+	return form
+}
+
+func newSubscriptionForm() *logicalForm {
+	form := &logicalForm{
+		action:   logicalCreateSubscription,
+		inputs:   make([]textinput.Model, subFieldCount),
+		copyData: true,
+	}
+	placeholders := map[int]string{
+		subFieldName:         "Subscription name",
+		subFieldHost:         "Publisher host",
+		subFieldPort:         "5432",
+		subFieldUser:         "Replication user",
+		subFieldPassword:     "Password",
+		subFieldDatabase:     "Database on the publisher",
+		subFieldPublications: "pub1, pub2",
+	}
+	for field, placeholder := range placeholders {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.Width = 40
+		if field == subFieldPassword {
+			input.EchoMode = textinput.EchoPassword
+		}
+		form.inputs[field] = input
+	}
+	form.inputs[subFieldName].Focus()
+	return form
+}
+
+func (form *logicalForm) fieldCount() int {
+	return len(form.inputs)
+}
+
+func (form *logicalForm) focus(field int) {
+	form.focused = field
+	for i := range form.inputs {
+		form.inputs[i].Blur()
+	}
+	if !form.isToggle(field) {
+		form.inputs[field].Focus()
+	}
+}
+
+// isToggle reports whether field is the subscription's copy data checkbox
+func (form *logicalForm) isToggle(field int) bool {
+	return form.action == logicalCreateSubscription && field == subFieldCopyData
+}
+
+func (form *logicalForm) value(field int) string {
+	return strings.TrimSpace(form.inputs[field].Value())
+}
+
+// splitList splits a comma separated form value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// publicationOptions reads the publication form
+func (v *ClusterView) publicationOptions() db.CreatePublicationOptions {
+	form := v.logicalForm
+	return db.CreatePublicationOptions{
+		Name:       form.value(pubFieldName),
+		Tables:     splitList(form.value(pubFieldTables)),
+		Operations: splitList(strings.ToLower(form.value(pubFieldOperations))),
+	}
+}
+
+// subscriptionOptions reads the subscription form
+func (v *ClusterView) subscriptionOptions() db.CreateSubscriptionOptions {
+	form := v.logicalForm
+	port, _ := strconv.Atoi(form.value(subFieldPort))
+	return db.CreateSubscriptionOptions{
+		Name:         form.value(subFieldName),
+		Host:         form.value(subFieldHost),
+		Port:         port,
+		User:         form.value(subFieldUser),
+		Password:     form.inputs[subFieldPassword].Value(),
+		Database:     form.value(subFieldDatabase),
+		Publications: splitList(form.value(subFieldPublications)),
+		CopyData:     form.copyData,
+	}
+}
+
+// logicalStatement builds the form's statement, with the password masked
+func (v *ClusterView) logicalStatement() string {
+	var stmt string
+	var err error
+	if v.logicalForm.action == logicalCreatePublication {
+		stmt, err = v.conn.CreatePublicationStatement(v.publicationOptions())
+	} else {
+		stmt, err = v.conn.CreateSubscriptionStatement(v.subscriptionOptions(), true)
+	}
+	if err != nil {
+		return ""
+	}
+	return stmt
+}
+
+func (v *ClusterView) updateLogicalForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	form := v.logicalForm
+	if v.running {
+		return v, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		v.logicalForm = nil
+		v.err = nil
+		return v, nil
+	case "tab", "down":
+		form.focus((form.focused + 1) % form.fieldCount())
+		return v, nil
+	case "shift+tab", "up":
+		form.focus((form.focused + form.fieldCount() - 1) % form.fieldCount())
+		return v, nil
+	case "enter":
+		var err error
+		if form.action == logicalCreatePublication {
+			_, err = v.conn.CreatePublicationStatement(v.publicationOptions())
+		} else {
+			_, err = v.conn.CreateSubscriptionStatement(v.subscriptionOptions(), true)
+			if value := form.value(subFieldPort); err == nil && value != "" {
+				if _, convErr := strconv.Atoi(value); convErr != nil {
+					err = fmt.Errorf("invalid port: %s", value)
+				}
+			}
+		}
+		if err != nil {
+			form.err = err
+			return v, nil
+		}
+		form.err = nil
+		v.pending = form.action
+		return v, nil
+	}
+
+	if form.isToggle(form.focused) {
+		if msg.String() == " " {
+			form.copyData = !form.copyData
+		}
+		return v, nil
+	}
+
+	var cmd tea.Cmd
+	form.inputs[form.focused], cmd = form.inputs[form.focused].Update(msg)
+	return v, cmd
+}
+
+func (v *ClusterView) renderLogical() string {
+	if v.conn.Config.Type != db.DatabaseTypePostgres {
+		return mutedStyle.Render("Logical replication is only available for PostgreSQL")
+	}
+	if v.logicalStatus == nil {
+		return helpStyle.Render("Press 'r' to refresh")
+	}
+
+	status := v.logicalStatus
+	var b strings.Builder
+	row := 0
+	cursor := func(line string) string {
+		selected := row == v.logicalCursor && v.logicalForm == nil
+		row++
+		if selected {
+			return selectedStyle.Render(line)
+		}
+		return clusterNodeStyle.Render(line)
+	}
+
+	b.WriteString(clusterTitleStyle.Render("Publications"))
+	b.WriteString("\n\n")
+	if len(status.Publications) == 0 {
+		b.WriteString(mutedStyle.Render("No publications in this database."))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(headerStyle.Render(fmt.Sprintf("%-24s %-12s %-28s %s", "NAME", "OWNER", "PUBLISHES", "TABLES")))
+		b.WriteString("\n")
+		for _, pub := range status.Publications {
+			tables := "all tables"
+			if !pub.AllTables {
+				tables = strings.Join(pub.Tables, ", ")
+				if tables == "" {
+					tables = "(none yet)"
+				}
+			}
+			b.WriteString(cursor(fmt.Sprintf("%-24s %-12s %-28s %s",
+				pub.Name, pub.Owner, strings.Join(pub.Operations, ","), truncateRunes(tables, 50))))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(clusterTitleStyle.Render("Subscriptions"))
+	b.WriteString("\n\n")
+	if len(status.Subscriptions) == 0 {
+		b.WriteString(mutedStyle.Render("No subscriptions in this database."))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(headerStyle.Render(fmt.Sprintf("%-24s %-10s %-24s %-14s %s", "NAME", "STATE", "PUBLICATIONS", "RECEIVED", "LAG")))
+		b.WriteString("\n")
+		for _, sub := range status.Subscriptions {
+			state := "stopped"
+			switch {
+			case !sub.Enabled:
+				state = "disabled"
+			case sub.WorkerRunning:
+				state = "streaming"
+			}
+			lag := "-"
+			if sub.LagSeconds != nil {
+				lag = fmt.Sprintf("%.1fs", *sub.LagSeconds)
+			}
+			b.WriteString(cursor(fmt.Sprintf("%-24s %-10s %-24s %-14s %s",
+				sub.Name, state, truncateRunes(strings.Join(sub.Publications, ","), 24), truncateLSN(sub.ReceivedLSN), lag)))
+			b.WriteString("\n")
+		}
+	}
+
+	if len(status.Slots) > 0 {
+		b.WriteString("\n")
+		b.WriteString(clusterTitleStyle.Render("Logical Slots (subscribers reading from here)"))
+		b.WriteString("\n\n")
+		b.WriteString(headerStyle.Render(fmt.Sprintf("%-28s %-12s %-10s %s", "SLOT", "DATABASE", "ACTIVE", "BEHIND")))
+		b.WriteString("\n")
+		for _, slot := range status.Slots {
+			active := clusterHealthyStyle.Render(fmt.Sprintf("%-10s", "yes"))
+			if !slot.Active {
+				active = clusterUnhealthyStyle.Render(fmt.Sprintf("%-10s", "no"))
+			}
+			behind := "-"
+			if slot.LagBytes != nil {
+				behind = db.FormatSize(*slot.LagBytes)
+			}
+			b.WriteString(fmt.Sprintf("%-28s %-12s %s %s\n", slot.Name, slot.Database, active, behind))
+		}
+	}
+
+	if problem := status.Problem(); problem != "" {
+		b.WriteString("\n")
+		b.WriteString(clusterWarningStyle.Render("Warning: " + problem))
+	}
+
+	return b.String()
+}
+
+// renderLogicalForm shows the new publication or subscription form
+func (v *ClusterView) renderLogicalForm() string {
+	form := v.logicalForm
+	var b strings.Builder
+
+	label := func(field int, text string) string {
+		if form.focused == field {
+			return focusedStyle.Render(text)
+		}
+		return blurredStyle.Render(text)
+	}
+	type formField struct {
+		field int
+		text  string
+	}
+
+	b.WriteString("\n\n")
+	var fields []formField
+	if form.action == logicalCreatePublication {
+		b.WriteString(clusterTitleStyle.Render("New Publication"))
+		fields = []formField{
+			{pubFieldName, "Name:       "},
+			{pubFieldTables, "Tables:     "},
+			{pubFieldOperations, "Operations: "},
+		}
+	} else {
+		b.WriteString(clusterTitleStyle.Render("New Subscription"))
+		fields = []formField{
+			{subFieldName, "Name:         "},
+			{subFieldHost, "Host:         "},
+			{subFieldPort, "Port:         "},
+			{subFieldUser, "User:         "},
+			{subFieldPassword, "Password:     "},
+			{subFieldDatabase, "Database:     "},
+			{subFieldPublications, "Publications: "},
+		}
+	}
+	b.WriteString("\n\n")
+
+	for _, f := range fields {
+		b.WriteString(label(f.field, f.text))
+		b.WriteString(form.inputs[f.field].View())
+		b.WriteString("\n")
+	}
+	toggleHelp := ""
+	if form.action == logicalCreateSubscription {
+		copyData := "[ ]"
+		if form.copyData {
+			copyData = "[x]"
+		}
+		b.WriteString(label(subFieldCopyData, "Copy data:    "))
+		b.WriteString(copyData)
+		b.WriteString(mutedStyle.Render("  copy existing rows before streaming changes"))
+		b.WriteString("\n")
+		toggleHelp = " | Space: Toggle copy data"
+	}
+	if form.err != nil {
+		b.WriteString("\n")
+		b.WriteString(renderError(form.err))
+	}
+	if v.pending == "" && !v.running {
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("Tab: Next field" + toggleHelp + " | Enter: Create | Esc: Cancel"))
+	}
+	return b.String()
+}
@@ -33,7 +33,8 @@ import (
 
 // ConnectedMsg is sent when a connection is established
 type ConnectedMsg struct {
-	Conn *db.Connection
+	Conn    *db.Connection
+	Profile string // Name of the profile used to connect (empty if none)
 }
 
 // Database type options
@@ -52,6 +53,7 @@ type ConnectView struct {
 	saveProfileName textinput.Model
 	cfg             *config.Config
 	connCfg         *db.ConnectionConfig
+	profileName     string // Name of the currently loaded profile
 	err             error
 	connecting      bool
 	saveSuccess     string
@@ -69,12 +71,13 @@ const (
 )
 
 // NewConnectView creates a new connect view
-func NewConnectView(cfg *config.Config, connCfg *db.ConnectionConfig) *ConnectView {
+func NewConnectView(cfg *config.Config, connCfg *db.ConnectionConfig, profileName string) *ConnectView {
 	v := &ConnectView{
-		inputs:  make([]textinput.Model, 5), // 5 text inputs (type is handled separately)
-		cfg:     cfg,
-		connCfg: connCfg,
-		focused: inputType, // Start focused on type selector
+		inputs:      make([]textinput.Model, 5), // 5 text inputs (type is handled separately)
+		cfg:         cfg,
+		connCfg:     connCfg,
+		profileName: profileName,
+		focused:     inputType, // Start focused on type selector
 	}
 
 	// Host input (index 0 in inputs slice, but inputHost-1 since type is not a text input)
@@ -119,6 +122,7 @@ func NewConnectView(cfg *config.Config, connCfg *db.ConnectionConfig) *ConnectVi
 		// Try to load default profile
 		if p, err := cfg.GetProfile(cfg.DefaultProfile); err == nil {
 			v.applyProfile(p)
+			v.profileName = cfg.DefaultProfile
 		}
 	}
 
@@ -162,6 +166,9 @@ func (v *ConnectView) applyProfile(p *config.Profile) {
 
 // Init initializes the view
 func (v *ConnectView) Init() tea.Cmd {
+	// The profile manager may have changed the saved profiles
+	v.profiles = v.cfg.ListProfiles()
+	v.selectedProf = min(v.selectedProf, max(len(v.profiles)-1, 0))
 	return textinput.Blink
 }
 
@@ -237,6 +244,7 @@ func (v *ConnectView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if v.selectedProf < len(v.profiles) {
 					if p, err := v.cfg.GetProfile(v.profiles[v.selectedProf]); err == nil {
 						v.applyProfile(p)
+						v.profileName = v.profiles[v.selectedProf]
 					}
 				}
 				v.showProfiles = false
@@ -277,6 +285,11 @@ func (v *ConnectView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return v, nil
 
+		case "ctrl+o":
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "profiles"}
+			}
+
 		case "ctrl+s":
 			// Show save profile dialog
 			v.showSaveDialog = true
@@ -398,6 +411,7 @@ func (v *ConnectView) saveProfile(name string) {
 
 	// Refresh profiles list
 	v.profiles = v.cfg.ListProfiles()
+	v.profileName = name
 	v.saveSuccess = name
 	v.err = nil
 }
@@ -413,6 +427,16 @@ func (v *ConnectView) connect() tea.Cmd {
 	userVal := v.inputs[2].Value() // User
 	passVal := v.inputs[3].Value() // Password
 	dbVal := v.inputs[4].Value()   // Database
+	profileName := v.profileName
+
+	// The profile's on connect statements, or those of the config the TUI
+	// was started with
+	var onConnect []string
+	if p, ok := v.cfg.Profiles[profileName]; ok {
+		onConnect = p.OnConnect
+	} else if v.connCfg != nil {
+		onConnect = v.connCfg.OnConnect
+	}
 
 	return func() tea.Msg {
 		host := hostVal
@@ -431,12 +455,13 @@ func (v *ConnectView) connect() tea.Cmd {
 		}
 
 		cfg := db.ConnectionConfig{
-			Type:     connType,
-			Host:     host,
-			Port:     port,
-			User:     userVal,
-			Password: passVal,
-			Database: dbVal,
+			Type:      connType,
+			Host:      host,
+			Port:      port,
+			User:      userVal,
+			Password:  passVal,
+			Database:  dbVal,
+			OnConnect: onConnect,
 		}
 
 		conn, err := db.Connect(cfg)
@@ -444,7 +469,7 @@ func (v *ConnectView) connect() tea.Cmd {
 			return err
 		}
 
-		return ConnectedMsg{Conn: conn}
+		return ConnectedMsg{Conn: conn, Profile: profileName}
 	}
 }
 
@@ -517,7 +542,7 @@ func (v *ConnectView) View() string {
 
 	// Error message
 	if v.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", v.err)))
+		b.WriteString(renderError(v.err))
 		b.WriteString("\n\n")
 	}
 
@@ -527,7 +552,7 @@ func (v *ConnectView) View() string {
 	}
 
 	// Help
-	help := []string{"Enter: Connect", "Tab: Next field", "Ctrl+S: Save Profile", "Ctrl+C: Quit"}
+	help := []string{"Enter: Connect", "Tab: Next field", "Ctrl+S: Save Profile", "Ctrl+O: Manage Profiles", "Ctrl+C: Quit"}
 	if len(v.profiles) > 0 {
 		help = append(help, "Ctrl+P: Load Profile")
 	}
@@ -0,0 +1,795 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type copyWizardStep int
+
+const (
+	copyStepKind copyWizardStep = iota
+	copyStepSources
+	copyStepOptions
+	copyStepPlanning
+	copyStepReview
+	copyStepRunning
+	copyStepDone
+)
+
+// Clone/merge options fields, in tab order
+const (
+	copyFieldTarget = iota
+	copyFieldChunkSize
+	copyFieldData // Clone: copy rows; merge: create a missing target
+	copyFieldDrop // Clone: drop an existing target; merge: conflict action
+	copyFieldCount
+)
+
+// mergeConflictActions are the conflict actions the wizard cycles through
+var mergeConflictActions = []db.MergeConflictAction{db.MergeSkip, db.MergeAppend, db.MergeRename, db.MergeReplace}
+
+// CopyWizardView walks through cloning a database or merging several into
+// one: pick the operation and sources, set the target, review the tables
+// and rows it will copy, then watch or background a copy that can be
+// cancelled, with the partial target cleaned up
+type CopyWizardView struct {
+	conn *db.Connection
+	step copyWizardStep
+
+	merge     bool
+	databases []string
+	selected  map[string]bool // Merge sources
+	cursor    int
+	source    string // Clone source
+
+	inputs     []textinput.Model // Target and chunk size
+	focused    int
+	noData     bool
+	dropTarget bool
+	create     bool
+	conflict   int // Index into mergeConflictActions
+	confirm    bool
+	typed      *typedConfirm // Asks for the target when the copy drops it or replaces its tables
+
+	plan     *db.CopyPlan
+	offset   int // First table shown in the review
+	progress *progressPanel
+	elapsed  time.Duration
+	err      error
+
+	width  int
+	height int
+}
+
+type copyDatabasesMsg struct {
+	databases []string
+	err       error
+}
+
+type copyPlannedMsg struct {
+	plan *db.CopyPlan
+	err  error
+}
+
+type copyDoneMsg struct {
+	title   string
+	elapsed time.Duration
+	err     error
+}
+
+func (m copyDoneMsg) JobResult() JobResult {
+	return JobResult{View: "clone", Title: m.title, Elapsed: m.elapsed, Err: m.err}
+}
+
+// NewCopyWizardView creates a new clone/merge wizard, starting from database
+func NewCopyWizardView(conn *db.Connection, database string, width, height int) *CopyWizardView {
+	v := &CopyWizardView{
+		conn:     conn,
+		source:   database,
+		selected: make(map[string]bool),
+		inputs:   make([]textinput.Model, 2),
+		create:   true,
+		width:    width,
+		height:   height,
+	}
+	if database != "" {
+		v.selected[database] = true
+	}
+
+	v.inputs[copyFieldTarget] = textinput.New()
+	v.inputs[copyFieldTarget].Placeholder = "Target database"
+	v.inputs[copyFieldTarget].Width = 40
+	v.inputs[copyFieldChunkSize] = textinput.New()
+	v.inputs[copyFieldChunkSize].Placeholder = strconv.Itoa(db.DefaultCopyChunkSize)
+	v.inputs[copyFieldChunkSize].Width = 40
+	return v
+}
+
+// Init initializes the view
+func (v *CopyWizardView) Init() tea.Cmd {
+	conn := v.conn
+	return func() tea.Msg {
+		databases, err := conn.ListVisibleDatabases()
+		if err != nil {
+			return copyDatabasesMsg{err: err}
+		}
+		names := make([]string, len(databases))
+		for i, d := range databases {
+			names[i] = d.Name
+		}
+		return copyDatabasesMsg{databases: names}
+	}
+}
+
+func (v *CopyWizardView) title() string {
+	if v.merge {
+		return "Merge into " + v.targetDatabase()
+	}
+	return "Clone of " + v.source
+}
+
+func (v *CopyWizardView) focus(field int) {
+	v.focused = field
+	for i := range v.inputs {
+		v.inputs[i].Blur()
+	}
+	if field < len(v.inputs) {
+		v.inputs[field].Focus()
+	}
+}
+
+func (v *CopyWizardView) targetDatabase() string {
+	return strings.TrimSpace(v.inputs[copyFieldTarget].Value())
+}
+
+// sources returns the merge sources in list order
+func (v *CopyWizardView) sources() []string {
+	var sources []string
+	for _, name := range v.databases {
+		if v.selected[name] {
+			sources = append(sources, name)
+		}
+	}
+	return sources
+}
+
+func (v *CopyWizardView) chunkSize() (int, error) {
+	s := strings.TrimSpace(v.inputs[copyFieldChunkSize].Value())
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("chunk size must be a positive number of rows")
+	}
+	return n, nil
+}
+
+func (v *CopyWizardView) cloneOptions() db.CloneOptions {
+	chunkSize, _ := v.chunkSize()
+	return db.CloneOptions{
+		SourceDB:     v.source,
+		TargetDB:     v.targetDatabase(),
+		IncludeData:  !v.noData,
+		DropIfExists: v.dropTarget,
+		ChunkSize:    chunkSize,
+	}
+}
+
+func (v *CopyWizardView) mergeOptions() db.MergeOptions {
+	chunkSize, _ := v.chunkSize()
+	action := mergeConflictActions[v.conflict]
+	return db.MergeOptions{
+		SourceDBs:    v.sources(),
+		TargetDB:     v.targetDatabase(),
+		CreateTarget: v.create,
+		ChunkSize:    chunkSize,
+		ConflictHandler: func(table, sourceDB string) db.MergeConflictAction {
+			return action
+		},
+	}
+}
+
+// planCopy checks the options and builds the plan for review
+func (v *CopyWizardView) planCopy() tea.Cmd {
+	target := v.targetDatabase()
+	if target == "" {
+		v.err = fmt.Errorf("enter a target database")
+		return nil
+	}
+	if _, err := v.chunkSize(); err != nil {
+		v.err = err
+		return nil
+	}
+	if v.merge {
+		for _, source := range v.sources() {
+			if source == target {
+				v.err = fmt.Errorf("%s can't be both a source and the target", target)
+				return nil
+			}
+		}
+	} else if v.source == target {
+		v.err = fmt.Errorf("the clone needs a different name than %s", target)
+		return nil
+	}
+
+	v.step = copyStepPlanning
+	v.err = nil
+	conn, merge := v.conn, v.merge
+	cloneOpts, mergeOpts := v.cloneOptions(), v.mergeOptions()
+	return func() tea.Msg {
+		jobConn, release := jobConnection(conn)
+		defer release()
+		if merge {
+			plan, err := jobConn.PlanMerge(mergeOpts)
+			return copyPlannedMsg{plan: plan, err: err}
+		}
+		plan, err := jobConn.PlanClone(cloneOpts)
+		return copyPlannedMsg{plan: plan, err: err}
+	}
+}
+
+// startCopy runs the clone or merge on a connection of its own
+func (v *CopyWizardView) startCopy() tea.Cmd {
+	v.step = copyStepRunning
+	v.confirm = false
+	v.err = nil
+
+	unit, total := progress.Rows, v.plan.Rows
+	if !v.plan.IncludeData {
+		unit, total = progress.Items, int64(len(v.plan.Tables))
+	}
+	v.progress = newProgressPanel(v.title(), unit, total)
+
+	ctx, _ := v.progress.cancellable()
+	conn, merge, panel, title, includeData := v.conn, v.merge, v.progress, v.title(), v.plan.IncludeData
+	cloneOpts, mergeOpts := v.cloneOptions(), v.mergeOptions()
+
+	onRows := func(p db.CopyProgress) {
+		panel.Set(p.Done)
+	}
+	cloneOpts.OnProgress = func(table string, tableNum, totalTables int) {
+		panel.SetCurrent(table, tableNum, totalTables)
+		if !includeData {
+			panel.Set(int64(tableNum))
+		}
+	}
+	cloneOpts.OnRows = onRows
+	mergeOpts.OnProgress = func(sourceDB, table string, sourceNum, totalSources int) {
+		panel.SetCurrent(sourceDB+"."+table, sourceNum, totalSources)
+	}
+	mergeOpts.OnRows = onRows
+
+	run := runJob(title, panel, func() tea.Msg {
+		jobConn, release := jobConnection(conn)
+		defer release()
+
+		start := time.Now()
+		var err error
+		if merge {
+			err = jobConn.MergeDatabasesContext(ctx, mergeOpts)
+		} else {
+			err = jobConn.CloneDatabaseContext(ctx, cloneOpts)
+		}
+		return copyDoneMsg{title: title, elapsed: time.Since(start), err: err}
+	})
+	return tea.Batch(run, progressTick())
+}
+
+// Update handles messages
+func (v *CopyWizardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		return v, nil
+
+	case copyDatabasesMsg:
+		v.err = msg.err
+		v.databases = msg.databases
+		for i, name := range v.databases {
+			if name == v.source {
+				v.cursor = i
+			}
+		}
+		return v, nil
+
+	case copyPlannedMsg:
+		if msg.err != nil {
+			v.err = msg.err
+			v.step = copyStepOptions
+			return v, nil
+		}
+		v.plan = msg.plan
+		v.offset = 0
+		v.step = copyStepReview
+		return v, nil
+
+	case progressTickMsg:
+		if v.step == copyStepRunning {
+			return v, progressTick()
+		}
+		return v, nil
+
+	case copyDoneMsg:
+		v.step = copyStepDone
+		v.elapsed = msg.elapsed
+		v.err = msg.err
+		return v, nil
+
+	case tea.KeyMsg:
+		switch v.step {
+		case copyStepKind:
+			return v.updateKind(msg)
+		case copyStepSources:
+			return v.updateSources(msg)
+		case copyStepOptions:
+			return v.updateOptions(msg)
+		case copyStepReview:
+			return v.updateReview(msg)
+		case copyStepRunning:
+			return v, v.progress.runningKey(msg.String())
+		case copyStepDone:
+			switch msg.String() {
+			case "esc", "backspace", "enter":
+				return v, v.back()
+			case "q":
+				return v, tea.Quit
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	if v.step == copyStepOptions && v.focused < len(v.inputs) {
+		v.inputs[v.focused], cmd = v.inputs[v.focused].Update(msg)
+	}
+	return v, cmd
+}
+
+// ownsJob reports whether the view started the job of p
+func (v *CopyWizardView) ownsJob(p *progressPanel) bool {
+	return p == v.progress
+}
+
+func (v *CopyWizardView) back() tea.Cmd {
+	return func() tea.Msg {
+		return SwitchViewMsg{View: "databases"}
+	}
+}
+
+func (v *CopyWizardView) updateKind(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return v, v.back()
+	case "up", "down", "k", "j", "tab":
+		v.merge = !v.merge
+	case "c":
+		v.merge = false
+		v.step = copyStepSources
+	case "m":
+		v.merge = true
+		v.step = copyStepSources
+	case "enter":
+		v.step = copyStepSources
+	}
+	return v, nil
+}
+
+func (v *CopyWizardView) updateSources(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		v.step = copyStepKind
+		v.err = nil
+	case "up", "k":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down", "j":
+		if v.cursor < len(v.databases)-1 {
+			v.cursor++
+		}
+	case " ":
+		if v.merge && v.cursor < len(v.databases) {
+			name := v.databases[v.cursor]
+			v.selected[name] = !v.selected[name]
+		}
+	case "enter":
+		if len(v.databases) == 0 {
+			return v, nil
+		}
+		if v.merge {
+			if len(v.sources()) == 0 {
+				v.err = fmt.Errorf("select at least one database to merge with space")
+				return v, nil
+			}
+		} else {
+			v.source = v.databases[v.cursor]
+			if v.targetDatabase() == "" {
+				v.inputs[copyFieldTarget].SetValue(v.source + "_copy")
+			}
+		}
+		v.err = nil
+		v.step = copyStepOptions
+		v.focus(copyFieldTarget)
+		return v, textinput.Blink
+	}
+	return v, nil
+}
+
+func (v *CopyWizardView) updateOptions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		v.step = copyStepSources
+		v.err = nil
+		return v, nil
+	case "tab", "down":
+		v.focus((v.focused + 1) % copyFieldCount)
+		return v, nil
+	case "shift+tab", "up":
+		v.focus((v.focused + copyFieldCount - 1) % copyFieldCount)
+		return v, nil
+	case "enter":
+		return v, v.planCopy()
+	}
+
+	if v.focused < len(v.inputs) {
+		var cmd tea.Cmd
+		v.inputs[v.focused], cmd = v.inputs[v.focused].Update(msg)
+		return v, cmd
+	}
+
+	key := msg.String()
+	if key != " " && key != "left" && key != "right" {
+		return v, nil
+	}
+	switch {
+	case v.focused == copyFieldData && v.merge:
+		v.create = !v.create
+	case v.focused == copyFieldData:
+		v.noData = !v.noData
+	case v.merge && key == "left":
+		v.conflict = (v.conflict + len(mergeConflictActions) - 1) % len(mergeConflictActions)
+	case v.merge:
+		v.conflict = (v.conflict + 1) % len(mergeConflictActions)
+	default:
+		v.dropTarget = !v.dropTarget
+	}
+	return v, nil
+}
+
+func (v *CopyWizardView) updateReview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if v.typed != nil {
+		confirmed, cancelled, cmd := v.typed.Update(msg)
+		switch {
+		case cancelled:
+			v.typed = nil
+		case confirmed:
+			v.typed = nil
+			return v, v.startCopy()
+		}
+		return v, cmd
+	}
+	if v.confirm {
+		if msg.String() == "y" {
+			return v, v.startCopy()
+		}
+		v.confirm = false
+		return v, nil
+	}
+
+	switch msg.String() {
+	case "esc", "e":
+		v.step = copyStepOptions
+	case "up", "k":
+		if v.offset > 0 {
+			v.offset--
+		}
+	case "down", "j":
+		if v.offset < len(v.plan.Tables)-1 {
+			v.offset++
+		}
+	case "enter":
+		if action := v.destructiveAction(); action != "" {
+			v.typed = newTypedConfirm(action, []string{v.plan.TargetDB})
+			return v, textinput.Blink
+		}
+		v.confirm = true
+	case "q":
+		return v, tea.Quit
+	}
+	return v, nil
+}
+
+// destructiveAction returns what the plan does to the target that can't be
+// undone, for the typed confirmation, or "" when it only adds to it
+func (v *CopyWizardView) destructiveAction() string {
+	if v.plan.DropTarget {
+		return "drop"
+	}
+	replaced := 0
+	for _, t := range v.plan.Tables {
+		if t.Conflict && t.Action == db.MergeReplace {
+			replaced++
+		}
+	}
+	switch replaced {
+	case 0:
+		return ""
+	case 1:
+		return "replace a table in"
+	}
+	return fmt.Sprintf("replace %d tables in", replaced)
+}
+
+// View renders the view
+func (v *CopyWizardView) View() string {
+	var b strings.Builder
+
+	switch v.step {
+	case copyStepKind:
+		b.WriteString(titleStyle.Render("Clone or Merge"))
+	case copyStepSources:
+		if v.merge {
+			b.WriteString(titleStyle.Render("Merge: Pick Sources"))
+		} else {
+			b.WriteString(titleStyle.Render("Clone: Pick the Source"))
+		}
+	default:
+		b.WriteString(titleStyle.Render(v.title()))
+	}
+	b.WriteString("\n\n")
+
+	switch v.step {
+	case copyStepKind:
+		b.WriteString(v.viewKind())
+	case copyStepSources:
+		b.WriteString(v.viewSources())
+	case copyStepOptions:
+		b.WriteString(v.viewOptions())
+	case copyStepPlanning:
+		b.WriteString(mutedStyle.Render("Listing tables..."))
+	case copyStepReview:
+		b.WriteString(v.viewReview())
+	case copyStepRunning:
+		b.WriteString(v.progress.View())
+		b.WriteString("\n\n")
+		if v.progress.cancelled.Load() {
+			b.WriteString(mutedStyle.Render("Cancelling and removing what was partly copied..."))
+		} else {
+			b.WriteString(v.progress.runningHelp())
+		}
+	case copyStepDone:
+		if v.err != nil {
+			b.WriteString(renderError(v.err))
+		} else if v.merge {
+			b.WriteString(successStyle.Render(fmt.Sprintf("Merged %d databases into %s in %s",
+				len(v.sources()), v.plan.TargetDB, progress.FormatDuration(v.elapsed))))
+		} else {
+			b.WriteString(successStyle.Render(fmt.Sprintf("Cloned %s to %s in %s",
+				v.source, v.plan.TargetDB, progress.FormatDuration(v.elapsed))))
+		}
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Enter/Esc: Back | q: Quit"))
+	}
+	return b.String()
+}
+
+func (v *CopyWizardView) viewKind() string {
+	var b strings.Builder
+
+	options := []struct {
+		merge bool
+		text  string
+	}{
+		{false, "Clone a database into a new one"},
+		{true, "Merge databases into one"},
+	}
+	for _, o := range options {
+		if o.merge == v.merge {
+			b.WriteString(focusedStyle.Render("  → " + o.text))
+		} else {
+			b.WriteString("    " + o.text)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑/↓: Choose | Enter: Next | c: Clone | m: Merge | Esc: Back"))
+	return b.String()
+}
+
+func (v *CopyWizardView) viewSources() string {
+	var b strings.Builder
+
+	if v.databases == nil && v.err == nil {
+		b.WriteString(mutedStyle.Render("Loading databases..."))
+		return b.String()
+	}
+
+	// Keep the cursor on screen
+	visible := max(v.height-12, 5)
+	start := 0
+	if v.cursor >= visible {
+		start = v.cursor - visible + 1
+	}
+	for i := start; i < len(v.databases) && i < start+visible; i++ {
+		name := v.databases[i]
+		line := name
+		if v.merge {
+			checkbox := "[ ]"
+			if v.selected[name] {
+				checkbox = "[x]"
+			}
+			line = checkbox + " " + name
+		}
+		if i == v.cursor {
+			b.WriteString(focusedStyle.Render("  → " + line))
+		} else {
+			b.WriteString("    " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	if v.merge {
+		b.WriteString(helpStyle.Render("Space: Select | Enter: Next | Esc: Back"))
+	} else {
+		b.WriteString(helpStyle.Render("Enter: Clone this one | Esc: Back"))
+	}
+	return b.String()
+}
+
+func (v *CopyWizardView) viewOptions() string {
+	var b strings.Builder
+
+	label := func(field int, text string) string {
+		if v.focused == field {
+			return focusedStyle.Render(text)
+		}
+		return blurredStyle.Render(text)
+	}
+	check := func(on bool, text string) string {
+		if on {
+			return "[x] " + text
+		}
+		return "[ ] " + text
+	}
+
+	if v.merge {
+		b.WriteString(mutedStyle.Render("Sources: " + strings.Join(v.sources(), ", ")))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(label(copyFieldTarget, "Target database:"))
+	b.WriteString("\n")
+	b.WriteString(v.inputs[copyFieldTarget].View())
+	b.WriteString("\n\n")
+	b.WriteString(label(copyFieldChunkSize, "Rows per chunk:"))
+	b.WriteString("\n")
+	b.WriteString(v.inputs[copyFieldChunkSize].View())
+	b.WriteString("\n\n")
+
+	if v.merge {
+		b.WriteString(label(copyFieldData, check(v.create, "Create the target if it doesn't exist")))
+		b.WriteString("\n")
+		b.WriteString(label(copyFieldDrop, "Tables the target already has: "+mergeConflictActions[v.conflict].String()))
+	} else {
+		b.WriteString(label(copyFieldData, check(!v.noData, "Copy rows")))
+		b.WriteString("\n")
+		b.WriteString(label(copyFieldDrop, check(v.dropTarget, "Drop the target if it exists")))
+	}
+	b.WriteString("\n\n")
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Tab: Next field | Space/←/→: Change | Enter: Review | Esc: Back"))
+	return b.String()
+}
+
+func (v *CopyWizardView) viewReview() string {
+	if v.typed != nil {
+		return v.typed.View()
+	}
+
+	var b strings.Builder
+	plan := v.plan
+
+	switch {
+	case plan.DropTarget:
+		b.WriteString(errorStyle.Render(fmt.Sprintf("%s exists and will be dropped first", plan.TargetDB)))
+	case plan.CreateTarget:
+		b.WriteString(fmt.Sprintf("%s will be created", plan.TargetDB))
+	default:
+		b.WriteString(fmt.Sprintf("Merging into the existing %s", plan.TargetDB))
+	}
+	b.WriteString("\n")
+
+	copied := plan.Copied()
+	summary := fmt.Sprintf("%d tables, structure only", len(copied))
+	if plan.IncludeData {
+		summary = fmt.Sprintf("%d tables, about %d rows", len(copied), plan.Rows)
+	}
+	if skipped := len(plan.Tables) - len(copied); skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", skipped)
+	}
+	b.WriteString(mutedStyle.Render(summary))
+	b.WriteString("\n\n")
+
+	visible := max(v.height-16, 5)
+	end := min(v.offset+visible, len(plan.Tables))
+	for _, t := range plan.Tables[v.offset:end] {
+		line := fmt.Sprintf("%s.%s", t.SourceDB, t.Table)
+		if t.Target != t.Table {
+			line += " → " + t.Target
+		}
+		if plan.IncludeData {
+			line += fmt.Sprintf("  ~%d rows", t.Rows)
+		}
+		switch {
+		case !t.Conflict:
+			b.WriteString("  " + line)
+		case t.Action == db.MergeReplace:
+			b.WriteString(errorStyle.Render("  " + line + "  (replaces the existing table)"))
+		default:
+			b.WriteString(mutedStyle.Render("  " + line + "  (exists: " + t.Action.String() + ")"))
+		}
+		b.WriteString("\n")
+	}
+	if end < len(plan.Tables) {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  ... %d more", len(plan.Tables)-end)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if plan.CreateTarget {
+		b.WriteString(mutedStyle.Render("Cancelling or a failure drops " + plan.TargetDB + " again."))
+	} else {
+		b.WriteString(mutedStyle.Render("Cancelling or a failure drops the table being created; finished tables stay."))
+	}
+	b.WriteString("\n\n")
+
+	if v.confirm {
+		verb := "Clone " + v.source + " to"
+		if v.merge {
+			verb = "Merge into"
+		}
+		b.WriteString(errorStyle.Render(fmt.Sprintf("%s %s on %s now? (y/n)", verb, plan.TargetDB, v.conn.Config.Host)))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Enter: Start | ↑/↓: Scroll | e/Esc: Edit | q: Quit"))
+	return b.String()
+}
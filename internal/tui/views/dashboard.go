@@ -24,14 +24,21 @@ import (
 	"sync"
 	"time"
 
+	"github.com/blubskye/yandere_sql_manager/internal/alert"
+	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// dashboardViewSeq tells apart the trend ticks of successive dashboards, so a
+// reopened view doesn't inherit the previous one's redraw loop
+var dashboardViewSeq int
+
 // DashboardView shows server statistics
 type DashboardView struct {
 	conn        *db.Connection
+	id          int
 	width       int
 	height      int
 	err         error
@@ -41,49 +48,80 @@ type DashboardView struct {
 	lastUpdate  time.Time
 	statsMu     sync.RWMutex // Protects stats for background updates
 	stopChan    chan struct{}
+	tab         dashboardTab
+	keybindings *config.KeyBindings
+
+	// Trends tab
+	metrics       *db.MetricsCollector
+	alerts        *alert.Monitor // nil when alerts aren't configured
+	trendsTicking bool
+
+	// Top queries tab
+	slowQueries *db.SlowQueryReport
+	slowLoading bool
+	slowErr     error
+	slowSort    db.QueryStatSort
+	slowCursor  int
+	slowMessage string
+
+	// Connections tab
+	sources        []db.ConnectionSource
+	sourcesLoading bool
+	sourcesErr     error
+	sourcesCursor  int
+	sourcesResolve bool // Reverse DNS lookups on
 }
 
-// Styles for the dashboard
-var (
-	dashboardBoxStyle = lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("#FF69B4")).
-				Padding(0, 1)
-
-	dashboardTitleStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("#FF69B4"))
-
-	dashboardValueStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("#FFFFFF"))
+// Dashboard tabs
+type dashboardTab int
 
-	dashboardBarFull = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#44FF44"))
-
-	dashboardBarEmpty = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#333333"))
-
-	dashboardBarWarning = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FFAA00"))
+const (
+	dashboardTabOverview dashboardTab = iota
+	dashboardTabTrends
+	dashboardTabQueries
+	dashboardTabSources
+	dashboardTabCount
+)
 
-	dashboardBarDanger = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FF4444"))
+// Styles for the dashboard, set by ApplyTheme
+var (
+	dashboardBoxStyle   lipgloss.Style
+	dashboardTitleStyle lipgloss.Style
+	dashboardValueStyle lipgloss.Style
+	dashboardBarFull    lipgloss.Style
+	dashboardBarEmpty   lipgloss.Style
+	dashboardBarWarning lipgloss.Style
+	dashboardBarDanger  lipgloss.Style
+	dashboardSparkStyle lipgloss.Style
 )
 
+// sparkBlocks are the sparkline levels, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
 // NewDashboardView creates a new dashboard view
-func NewDashboardView(conn *db.Connection, width, height int) *DashboardView {
+func NewDashboardView(conn *db.Connection, metrics *db.MetricsCollector, alerts *alert.Monitor, width, height int) *DashboardView {
+	dashboardViewSeq++
 	return &DashboardView{
-		conn:     conn,
-		width:    width,
-		height:   height,
-		loading:  true,
-		stopChan: make(chan struct{}),
+		conn:        conn,
+		id:          dashboardViewSeq,
+		metrics:     metrics,
+		alerts:      alerts,
+		keybindings: loadKeyBindings(),
+		width:       width,
+		height:      height,
+		loading:     true,
+		stopChan:    make(chan struct{}),
 	}
 }
 
 // Init initializes the view
 func (v *DashboardView) Init() tea.Cmd {
+	// Sampling keeps running after the dashboard closes so trends cover the
+	// whole session
+	v.metrics.Start()
+	if v.alerts != nil {
+		v.alerts.Start(v.conn)
+	}
 	return v.loadStats
 }
 
@@ -129,10 +167,84 @@ type statsLoadedMsg struct {
 
 type tickMsg struct{}
 
+type trendsTickMsg struct {
+	id int
+}
+
+type slowQueriesLoadedMsg struct {
+	report *db.SlowQueryReport
+	err    error
+}
+
+type sourcesLoadedMsg struct {
+	sources []db.ConnectionSource
+	err     error
+}
+
+type slowQueriesExportedMsg struct {
+	path string
+	err  error
+}
+
+func (v *DashboardView) loadSlowQueries() tea.Msg {
+	report, err := v.conn.SlowQueryStats()
+	return slowQueriesLoadedMsg{report: report, err: err}
+}
+
+func (v *DashboardView) loadSources() tea.Msg {
+	sources, err := v.conn.ConnectionSources()
+	if err == nil && v.sourcesResolve {
+		db.ResolveHostnames(sources, 5*time.Second)
+	}
+	return sourcesLoadedMsg{sources: sources, err: err}
+}
+
+func (v *DashboardView) exportSlowQueries() tea.Cmd {
+	report := v.slowQueries
+	path := db.DefaultResultExportPath("slow-queries", "json")
+	return func() tea.Msg {
+		return slowQueriesExportedMsg{path: path, err: report.ExportJSON(path)}
+	}
+}
+
 // Update handles messages
 func (v *DashboardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		switch msg.String() {
+		case "tab", "shift+tab":
+			if msg.String() == "tab" {
+				v.tab = (v.tab + 1) % dashboardTabCount
+			} else {
+				v.tab = (v.tab + dashboardTabCount - 1) % dashboardTabCount
+			}
+			if v.tab == dashboardTabQueries && v.slowQueries == nil && !v.slowLoading {
+				v.slowLoading = true
+				return v, v.loadSlowQueries
+			}
+			if v.tab == dashboardTabSources && v.sources == nil && !v.sourcesLoading {
+				v.sourcesLoading = true
+				return v, v.loadSources
+			}
+			if v.tab == dashboardTabTrends && !v.trendsTicking {
+				v.trendsTicking = true
+				return v, v.trendsTick()
+			}
+			return v, nil
+		}
+		if v.tab == dashboardTabQueries {
+			if cmd, handled := v.updateSlowQueries(msg); handled {
+				return v, cmd
+			}
+		}
+		if v.tab == dashboardTabSources {
+			if cmd, handled := v.updateSources(msg); handled {
+				return v, cmd
+			}
+		}
+		if cmd := showHelp(v.keybindings, "dashboard", msg.String()); cmd != nil {
+			return v, cmd
+		}
 		switch msg.String() {
 		case "r":
 			v.loading = true
@@ -143,6 +255,20 @@ func (v *DashboardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return v, v.tick()
 			}
 			return v, nil
+		case "l":
+			v.autoRefresh = false
+			close(v.stopChan)
+			v.stopChan = make(chan struct{})
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "locks"}
+			}
+		case "p":
+			v.autoRefresh = false
+			close(v.stopChan)
+			v.stopChan = make(chan struct{})
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "processes"}
+			}
 		case "esc", "backspace", "q":
 			// Stop any background operations
 			v.autoRefresh = false
@@ -168,6 +294,42 @@ func (v *DashboardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return v, nil
 
+	case slowQueriesLoadedMsg:
+		v.slowLoading = false
+		v.slowErr = msg.err
+		if msg.err == nil {
+			v.slowQueries = msg.report
+			v.slowQueries.Sort(v.slowSort)
+			v.slowCursor = min(v.slowCursor, max(len(v.slowQueries.Queries)-1, 0))
+		}
+		return v, nil
+
+	case sourcesLoadedMsg:
+		v.sourcesLoading = false
+		v.sourcesErr = msg.err
+		if msg.err == nil {
+			v.sources = msg.sources
+			v.sourcesCursor = min(v.sourcesCursor, max(len(v.sources)-1, 0))
+		}
+		return v, nil
+
+	case slowQueriesExportedMsg:
+		v.slowErr = msg.err
+		if msg.err == nil {
+			v.slowMessage = fmt.Sprintf("Exported %d queries to %s", len(v.slowQueries.Queries), msg.path)
+		}
+		return v, nil
+
+	case trendsTickMsg:
+		if msg.id != v.id {
+			return v, nil
+		}
+		if v.tab != dashboardTabTrends {
+			v.trendsTicking = false
+			return v, nil
+		}
+		return v, v.trendsTick()
+
 	case tickMsg:
 		if v.autoRefresh {
 			v.loading = true
@@ -184,18 +346,105 @@ func (v *DashboardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return v, nil
 }
 
+// updateSlowQueries handles the keys of the top queries tab, reporting
+// whether the key was used
+func (v *DashboardView) updateSlowQueries(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "r":
+		if !v.slowLoading {
+			v.slowLoading = true
+			v.slowMessage = ""
+			return v.loadSlowQueries, true
+		}
+		return nil, true
+	case "s":
+		v.slowSort = (v.slowSort + 1) % (db.SortByCalls + 1)
+		if v.slowQueries != nil {
+			v.slowQueries.Sort(v.slowSort)
+		}
+		v.slowCursor = 0
+		return nil, true
+	case "e":
+		if v.slowQueries != nil && len(v.slowQueries.Queries) > 0 {
+			v.slowMessage = ""
+			return v.exportSlowQueries(), true
+		}
+		return nil, true
+	case "up", "k":
+		if v.slowCursor > 0 {
+			v.slowCursor--
+		}
+		return nil, true
+	case "down", "j":
+		if v.slowQueries != nil && v.slowCursor < len(v.slowQueries.Queries)-1 {
+			v.slowCursor++
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+// updateSources handles the keys of the connections tab
+func (v *DashboardView) updateSources(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch msg.String() {
+	case "r", "d":
+		if msg.String() == "d" {
+			v.sourcesResolve = !v.sourcesResolve
+		}
+		if !v.sourcesLoading {
+			v.sourcesLoading = true
+			return v.loadSources, true
+		}
+		return nil, true
+	case "up", "k":
+		if v.sourcesCursor > 0 {
+			v.sourcesCursor--
+		}
+		return nil, true
+	case "down", "j":
+		if v.sourcesCursor < len(v.sources)-1 {
+			v.sourcesCursor++
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
 func (v *DashboardView) tick() tea.Cmd {
 	return tea.Tick(5*time.Second, func(t time.Time) tea.Msg {
 		return tickMsg{}
 	})
 }
 
+// trendsTick redraws the trends tab as new samples arrive
+func (v *DashboardView) trendsTick() tea.Cmd {
+	id := v.id
+	return tea.Tick(v.metrics.Interval(), func(t time.Time) tea.Msg {
+		return trendsTickMsg{id: id}
+	})
+}
+
 // View renders the view
 func (v *DashboardView) View() string {
 	var b strings.Builder
 
 	b.WriteString(titleStyle.Render("Server Dashboard"))
 	b.WriteString("\n\n")
+	b.WriteString(v.renderTabs())
+	b.WriteString("\n\n")
+
+	if v.tab == dashboardTabQueries {
+		b.WriteString(v.renderSlowQueries())
+		return b.String()
+	}
+	if v.tab == dashboardTabTrends {
+		b.WriteString(v.renderTrends())
+		return b.String()
+	}
+	if v.tab == dashboardTabSources {
+		b.WriteString(v.renderSources())
+		return b.String()
+	}
 
 	// Thread-safe stats access
 	v.statsMu.RLock()
@@ -208,7 +457,7 @@ func (v *DashboardView) View() string {
 	}
 
 	if v.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", v.err)))
+		b.WriteString(renderError(v.err))
 		b.WriteString("\n\n")
 	}
 
@@ -217,9 +466,9 @@ func (v *DashboardView) View() string {
 		return b.String()
 	}
 
-	// Layout: 2 columns of boxes
-	leftWidth := (v.width - 6) / 2
-	rightWidth := leftWidth
+	// Layout: 2 columns of boxes, stacked in the compact layout
+	leftWidth, rightWidth := panelWidths(v.width)
+	fullWidth := v.width - 4
 
 	// Server Info Box
 	serverInfo := v.renderServerInfo(leftWidth)
@@ -228,7 +477,7 @@ func (v *DashboardView) View() string {
 	connInfo := v.renderConnections(rightWidth)
 
 	// Render first row
-	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, serverInfo, "  ", connInfo))
+	b.WriteString(joinPanels(serverInfo, connInfo))
 	b.WriteString("\n\n")
 
 	// Storage Box
@@ -238,12 +487,18 @@ func (v *DashboardView) View() string {
 	perfInfo := v.renderPerformance(rightWidth)
 
 	// Render second row
-	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, storageInfo, "  ", perfInfo))
+	b.WriteString(joinPanels(storageInfo, perfInfo))
 
 	// Replication info for PostgreSQL
 	if stats.Replication != nil {
 		b.WriteString("\n\n")
-		b.WriteString(v.renderReplication(leftWidth + rightWidth + 2))
+		b.WriteString(v.renderReplication(fullWidth))
+	}
+
+	// Host saturation next to the server's own numbers
+	if v.metrics.HostMetricsEnabled() {
+		b.WriteString("\n\n")
+		b.WriteString(v.renderHost(fullWidth))
 	}
 
 	b.WriteString("\n\n")
@@ -261,9 +516,14 @@ func (v *DashboardView) View() string {
 		autoStatus = "on (5s)"
 	}
 
+	if line := renderAlertStatus(v.alerts); line != "" {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
 	b.WriteString(mutedStyle.Render(fmt.Sprintf("%s | Auto-refresh: %s", updateStatus, autoStatus)))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("r: Refresh | a: Toggle auto-refresh | Esc: Back | q: Quit"))
+	b.WriteString(helpStyle.Render("Tab: Trends | r: Refresh | a: Toggle auto-refresh | l: Locks | p: Processes | " +
+		v.keybindings.GetKey("dashboard", config.ActionHelp) + ": Help | Esc: Back | q: Quit"))
 
 	return b.String()
 }
@@ -303,6 +563,15 @@ func (v *DashboardView) renderConnections(width int) string {
 		content.WriteString(fmt.Sprintf(" %.1f%%", usage))
 	}
 
+	// What every session YSM opens runs first, from the profile
+	if onConnect := v.conn.Config.OnConnect; len(onConnect) > 0 {
+		content.WriteString("\n\nOn connect:")
+		for _, stmt := range onConnect {
+			content.WriteString("\n")
+			content.WriteString(mutedStyle.Render(truncateRunes(stmt, max(width-6, 10))))
+		}
+	}
+
 	return dashboardBoxStyle.Width(width).Render(content.String())
 }
 
@@ -397,6 +666,47 @@ func (v *DashboardView) renderReplication(width int) string {
 	return dashboardBoxStyle.Width(width).Render(content.String())
 }
 
+func (v *DashboardView) renderHost(width int) string {
+	var content strings.Builder
+
+	content.WriteString(dashboardTitleStyle.Render("Host"))
+
+	host, err := v.metrics.HostMetrics()
+	switch {
+	case err != nil:
+		content.WriteString("\n\n")
+		content.WriteString(mutedStyle.Render(fmt.Sprintf("Unavailable: %v", err)))
+		return dashboardBoxStyle.Width(width).Render(content.String())
+	case host == nil:
+		content.WriteString("\n\n")
+		content.WriteString(mutedStyle.Render("Collecting samples..."))
+		return dashboardBoxStyle.Width(width).Render(content.String())
+	}
+
+	content.WriteString(mutedStyle.Render(" - data directory " + host.DataDir))
+	content.WriteString("\n\n")
+
+	barWidth := max(width-40, 10)
+	row := func(label string, pct float64, detail string) {
+		if pct < 0 {
+			content.WriteString(fmt.Sprintf("%-8s %s\n", label, mutedStyle.Render("unavailable")))
+			return
+		}
+		content.WriteString(fmt.Sprintf("%-8s %s %5.1f%%  %s\n", label, v.renderBar(pct, barWidth), pct, detail))
+	}
+
+	ioWait := "IO wait -"
+	if host.IOWait >= 0 {
+		ioWait = fmt.Sprintf("IO wait %.1f%%", host.IOWait)
+	}
+	row("CPU", host.CPU, ioWait)
+	row("Memory", host.MemoryPercent(), fmt.Sprintf("%s / %s", db.FormatSize(host.MemoryUsed), db.FormatSize(host.MemoryTotal)))
+	row("Disk", host.DiskPercent(), fmt.Sprintf("%s / %s", db.FormatSize(host.DiskUsed), db.FormatSize(host.DiskTotal)))
+	row("Disk IO", host.DiskBusy, "busy")
+
+	return dashboardBoxStyle.Width(width).Render(strings.TrimSuffix(content.String(), "\n"))
+}
+
 func (v *DashboardView) renderBar(percent float64, width int) string {
 	if width < 5 {
 		width = 5
@@ -450,3 +760,284 @@ func (v *DashboardView) renderBarSimple(percent float64, width int) string {
 
 	return bar.String()
 }
+
+func (v *DashboardView) renderTabs() string {
+	tabs := []string{"Overview", "Trends", "Top Queries", "Connections"}
+	var rendered []string
+	for i, tab := range tabs {
+		if dashboardTab(i) == v.tab {
+			rendered = append(rendered, selectedStyle.Render(tab))
+		} else {
+			rendered = append(rendered, mutedStyle.Render(tab))
+		}
+	}
+	return strings.Join(rendered, "  ")
+}
+
+// renderSlowQueries renders the top queries tab
+func (v *DashboardView) renderSlowQueries() string {
+	var b strings.Builder
+
+	switch {
+	case v.slowLoading && v.slowQueries == nil:
+		b.WriteString("Loading slow queries...\n")
+	case v.slowQueries == nil:
+	case len(v.slowQueries.Queries) == 0:
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("No queries recorded in %s", v.slowQueries.Source)))
+		b.WriteString("\n")
+	default:
+		report := v.slowQueries
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("%d queries from %s, sorted by %s", len(report.Queries), report.Source, v.slowSort)))
+		b.WriteString("\n\n")
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %12s %12s %10s %12s  %s", "Total", "Mean", "Calls", "Rows", "Query")))
+		b.WriteString("\n")
+
+		visible := max(v.height-16, 5)
+		start := 0
+		if v.slowCursor >= visible {
+			start = v.slowCursor - visible + 1
+		}
+		end := min(start+visible, len(report.Queries))
+		for i := start; i < end; i++ {
+			q := report.Queries[i]
+			line := fmt.Sprintf("%12s %12s %10d %12d  %s",
+				q.TotalTime.Round(time.Millisecond), q.MeanTime().Round(time.Microsecond), q.Calls, q.RowsSent, q.Fingerprint)
+			line = truncateRunes(line, max(v.width-4, 40))
+			if i == v.slowCursor {
+				b.WriteString(selectedStyle.Render("> " + line))
+			} else {
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+
+		// Full text of the selected query
+		selected := report.Queries[v.slowCursor]
+		b.WriteString("\n")
+		b.WriteString(dashboardTitleStyle.Render("Example"))
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  max %s", selected.MaxTime.Round(time.Millisecond))))
+		if selected.RowsExamined > 0 {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf(", %d rows examined", selected.RowsExamined)))
+		}
+		b.WriteString("\n")
+		example := strings.Join(strings.Fields(selected.Example), " ")
+		b.WriteString(truncateRunes(example, max((v.width-4)*3, 120)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if v.slowErr != nil {
+		b.WriteString(renderError(v.slowErr))
+		b.WriteString("\n\n")
+	} else if v.slowMessage != "" {
+		b.WriteString(successStyle.Render(v.slowMessage))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Tab: Connections | ↑↓: Navigate | s: Sort | e: Export JSON | r: Refresh | l: Locks | p: Processes | " +
+		v.keybindings.GetKey("dashboard", config.ActionHelp) + ": Help | Esc: Back | q: Quit"))
+	return b.String()
+}
+
+// renderSources renders the connections tab: who is connected, grouped by
+// user, host and application
+func (v *DashboardView) renderSources() string {
+	var b strings.Builder
+
+	switch {
+	case v.sourcesLoading && v.sources == nil:
+		b.WriteString("Loading connections...\n")
+	case v.sources == nil:
+	case len(v.sources) == 0:
+		b.WriteString(mutedStyle.Render("No other connections"))
+		b.WriteString("\n")
+	default:
+		total := 0
+		for _, src := range v.sources {
+			total += src.Count
+		}
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("%d connections from %d sources", total, len(v.sources))))
+		b.WriteString("\n\n")
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-16s %-36s %-20s %6s %10s  %s", "User", "Host", "Application", "Count", "Oldest", "Databases")))
+		b.WriteString("\n")
+
+		visible := max(v.height-14, 5)
+		start := 0
+		if v.sourcesCursor >= visible {
+			start = v.sourcesCursor - visible + 1
+		}
+		end := min(start+visible, len(v.sources))
+		for i := start; i < end; i++ {
+			src := v.sources[i]
+			host := src.Host
+			if src.Hostname != "" {
+				host += " (" + src.Hostname + ")"
+			}
+			oldest := "-"
+			if src.Oldest >= 0 {
+				oldest = db.FormatUptime(src.Oldest)
+			}
+			line := fmt.Sprintf("%-16s %-36s %-20s %6d %10s  %s",
+				truncateRunes(src.User, 16), truncateRunes(host, 36), truncateRunes(src.Application, 20),
+				src.Count, oldest, strings.Join(src.Databases, ", "))
+			line = truncateRunes(line, max(v.width-4, 40))
+			if i == v.sourcesCursor {
+				b.WriteString(selectedStyle.Render("> " + line))
+			} else {
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+
+	if v.sourcesErr != nil {
+		b.WriteString(renderError(v.sourcesErr))
+		b.WriteString("\n\n")
+	} else if v.sourcesLoading && v.sources != nil {
+		b.WriteString(mutedStyle.Render("Refreshing..."))
+		b.WriteString("\n\n")
+	}
+
+	dns := "off"
+	if v.sourcesResolve {
+		dns = "on"
+	}
+	b.WriteString(mutedStyle.Render("Reverse DNS: " + dns))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("Tab: Overview | ↑↓: Navigate | d: Toggle reverse DNS | r: Refresh | l: Locks | p: Processes | " +
+		v.keybindings.GetKey("dashboard", config.ActionHelp) + ": Help | Esc: Back | q: Quit"))
+	return b.String()
+}
+
+// renderTrends renders the trends tab: one sparkline per sampled metric
+// trendSeries is one sparkline of the trends tab
+type trendSeries struct {
+	label  string
+	format string
+	floor  bool // Scale from 0 rather than from the lowest value
+	value  func(db.MetricSample) float64
+}
+
+func (v *DashboardView) renderTrends() string {
+	var b strings.Builder
+
+	samples := v.metrics.Samples()
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("Last hour, sampled every %s (%d samples)",
+		v.metrics.Interval(), len(samples))))
+	b.WriteString("\n\n")
+
+	if len(samples) == 0 {
+		b.WriteString("Collecting samples...\n\n")
+	} else {
+		width := max(v.width-6, 20)
+		series := []trendSeries{
+			{v.metrics.RateLabel(), "%.1f", true, func(s db.MetricSample) float64 { return s.QPS }},
+			{"Connections", "%.0f", true, func(s db.MetricSample) float64 { return s.Connections }},
+			{"Cache Hit Rate", "%.2f%%", false, func(s db.MetricSample) float64 { return s.CacheHitRate }},
+			{"Replication Lag", "%.0fs", true, func(s db.MetricSample) float64 { return s.ReplicationLag }},
+		}
+		if v.metrics.HostMetricsEnabled() {
+			series = append(series,
+				trendSeries{"Host CPU", "%.1f%%", true, func(s db.MetricSample) float64 { return s.HostCPU }},
+				trendSeries{"Host IO Wait", "%.1f%%", true, func(s db.MetricSample) float64 { return s.HostIOWait }},
+				trendSeries{"Host Memory", "%.1f%%", false, func(s db.MetricSample) float64 { return s.HostMemory }},
+				trendSeries{"Data Disk Busy", "%.1f%%", true, func(s db.MetricSample) float64 { return s.DiskBusy }},
+			)
+		}
+		for _, sr := range series {
+			values := make([]float64, len(samples))
+			for i, s := range samples {
+				values[i] = sr.value(s)
+			}
+			b.WriteString(v.renderTrend(sr.label, sr.format, values, sr.floor, width))
+			b.WriteString("\n\n")
+		}
+		// Time axis under the sparklines
+		if span := min(len(samples), width); span > 16 {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("%s%*s", samples[0].Time.Format("15:04:05"),
+				span-8, samples[len(samples)-1].Time.Format("15:04:05"))))
+			b.WriteString("\n\n")
+		}
+	}
+
+	if err := v.metrics.Err(); err != nil {
+		b.WriteString(renderError(err))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Tab: Top queries | l: Locks | p: Processes | " +
+		v.keybindings.GetKey("dashboard", config.ActionHelp) + ": Help | Esc: Back | q: Quit"))
+	return b.String()
+}
+
+// renderTrend renders a titled sparkline with the current, minimum and
+// maximum values; negative values mark samples where the metric was missing
+func (v *DashboardView) renderTrend(label, format string, values []float64, floor bool, width int) string {
+	var b strings.Builder
+
+	lo, hi, current := 0.0, 0.0, -1.0
+	seen := false
+	for _, val := range values {
+		if val < 0 {
+			continue
+		}
+		if !seen || val < lo {
+			lo = val
+		}
+		if !seen || val > hi {
+			hi = val
+		}
+		seen = true
+		current = val
+	}
+
+	b.WriteString(dashboardTitleStyle.Render(label))
+	if !seen {
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("Unavailable"))
+		return b.String()
+	}
+	b.WriteString(fmt.Sprintf("  now %s", dashboardValueStyle.Render(fmt.Sprintf(format, current))))
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("  min "+format+"  max "+format, lo, hi)))
+	b.WriteString("\n")
+
+	if floor {
+		lo = 0
+	}
+	b.WriteString(dashboardSparkStyle.Render(sparkline(values, lo, hi, width)))
+	return b.String()
+}
+
+// sparkline draws values scaled between lo and hi, averaging neighbouring
+// values when there are more than width of them
+func sparkline(values []float64, lo, hi float64, width int) string {
+	buckets := len(values)
+	if buckets > width {
+		buckets = width
+	}
+
+	var b strings.Builder
+	for i := 0; i < buckets; i++ {
+		start := i * len(values) / buckets
+		end := (i + 1) * len(values) / buckets
+		sum, n := 0.0, 0
+		for _, val := range values[start:end] {
+			if val >= 0 {
+				sum += val
+				n++
+			}
+		}
+		if n == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		level := len(sparkBlocks) / 2
+		if hi > lo {
+			level = int((sum/float64(n) - lo) / (hi - lo) * float64(len(sparkBlocks)-1))
+			level = min(max(level, 0), len(sparkBlocks)-1)
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
@@ -21,12 +21,13 @@ package views
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
 // SwitchViewMsg is sent to switch to a different view
@@ -34,44 +35,98 @@ type SwitchViewMsg struct {
 	View     string
 	Database string
 	Table    string
+	Path     string // File the view opens, e.g. the dump to browse
 }
 
-// DatabasesView shows the list of databases
+// ShowHelpMsg asks the app to show the key help for a view
+type ShowHelpMsg struct {
+	View string // Keybindings view name, e.g. "databases"
+}
+
+// loadKeyBindings returns the user's keybindings, or the defaults when they
+// can't be read
+func loadKeyBindings() *config.KeyBindings {
+	kb, _ := config.LoadKeyBindings()
+	if kb == nil {
+		kb = config.DefaultKeyBindings()
+	}
+	return kb
+}
+
+// showHelp returns a command showing the key help of view when key is its
+// help key, or nil
+func showHelp(kb *config.KeyBindings, view, key string) tea.Cmd {
+	if !kb.IsKey(view, key, config.ActionHelp) {
+		return nil
+	}
+	return func() tea.Msg {
+		return ShowHelpMsg{View: view}
+	}
+}
+
+// databaseFilterDelay is how long typing in the filter pauses before the
+// server is asked for matching databases
+const databaseFilterDelay = 250 * time.Millisecond
+
+// DatabasesView shows the list of databases. They are loaded a page at a
+// time, with more fetched as the cursor nears the end, and filtered on the
+// server so servers with thousands of databases stay responsive.
 type DatabasesView struct {
 	conn        *db.Connection
 	list        list.Model
 	databases   []db.Database
+	total       int // Databases matching the filter, loaded or not
+	loading     bool
+	filtering   bool
+	filterInput textinput.Model
+	filter      string // Filter the loaded databases match
+	filterSeq   int    // Bumped on each filter edit so only the last loads
 	width       int
 	height      int
 	err         error
 	keybindings *config.KeyBindings
 }
 
+// databasePageMsg carries a page of databases matching filter
+type databasePageMsg struct {
+	page   *db.DatabasePage
+	filter string
+}
+
+// databaseFilterMsg fires once the filter has been left alone for
+// databaseFilterDelay
+type databaseFilterMsg struct {
+	seq int
+}
+
 type dbItem struct {
-	name string
+	name      string
+	protected bool // Can't be dropped or truncated, see db.SystemDatabasePolicy
 }
 
-func (i dbItem) Title() string       { return i.name }
-func (i dbItem) Description() string { return "" }
+func (i dbItem) Title() string { return i.name }
+func (i dbItem) Description() string {
+	if i.protected {
+		return "protected"
+	}
+	return ""
+}
 func (i dbItem) FilterValue() string { return i.name }
 
 // NewDatabasesView creates a new databases view
 func NewDatabasesView(conn *db.Connection, width, height int) *DatabasesView {
-	delegate := list.NewDefaultDelegate()
-	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#FF69B4")).
-		Bold(true)
-	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
-		Foreground(lipgloss.Color("#FFB6C1")).
-		Background(lipgloss.Color("#FF69B4"))
+	delegate := newListDelegate()
 
 	l := list.New([]list.Item{}, delegate, width, height-4)
 	l.Title = "Databases"
 	l.SetShowStatusBar(true)
-	l.SetFilteringEnabled(true)
+	l.SetFilteringEnabled(false)
 	l.Styles.Title = titleStyle
 
+	filterInput := textinput.New()
+	filterInput.Placeholder = "part of a database name"
+	filterInput.CharLimit = 64
+
 	// Load keybindings
 	kb, _ := config.LoadKeyBindings()
 	if kb == nil {
@@ -81,6 +136,7 @@ func NewDatabasesView(conn *db.Connection, width, height int) *DatabasesView {
 	return &DatabasesView{
 		conn:        conn,
 		list:        l,
+		filterInput: filterInput,
 		width:       width,
 		height:      height,
 		keybindings: kb,
@@ -89,15 +145,66 @@ func NewDatabasesView(conn *db.Connection, width, height int) *DatabasesView {
 
 // Init initializes the view
 func (v *DatabasesView) Init() tea.Cmd {
-	return v.loadDatabases
+	return v.loadDatabases()
+}
+
+// loadDatabases reloads the first page for the current filter
+func (v *DatabasesView) loadDatabases() tea.Cmd {
+	return v.loadPage(0)
+}
+
+// loadPage loads the page of databases matching the filter at offset
+func (v *DatabasesView) loadPage(offset int) tea.Cmd {
+	v.loading = true
+	conn, filter := v.conn, v.filter
+	return func() tea.Msg {
+		page, err := conn.ListDatabasesPage(filter, db.DefaultDatabasePageSize, offset)
+		if err != nil {
+			return err
+		}
+		return databasePageMsg{page: page, filter: filter}
+	}
+}
+
+// loadMore fetches the next page once the list shows its last loaded page
+func (v *DatabasesView) loadMore() tea.Cmd {
+	if v.loading || len(v.databases) >= v.total || !v.list.Paginator.OnLastPage() {
+		return nil
+	}
+	return v.loadPage(len(v.databases))
 }
 
-func (v *DatabasesView) loadDatabases() tea.Msg {
-	databases, err := v.conn.ListDatabases()
-	if err != nil {
-		return err
+// setFilter sets the filter and waits for typing to pause before loading
+func (v *DatabasesView) setFilter(filter string) tea.Cmd {
+	if filter == v.filter {
+		return nil
 	}
-	return databases
+	v.filter = filter
+	v.filterSeq++
+	seq := v.filterSeq
+	return tea.Tick(databaseFilterDelay, func(time.Time) tea.Msg {
+		return databaseFilterMsg{seq: seq}
+	})
+}
+
+// updateFilter handles keys while typing the filter. Enter keeps the
+// filter and Esc clears it.
+func (v *DatabasesView) updateFilter(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		v.filtering = false
+		v.filterInput.Blur()
+		return nil
+	case "esc":
+		v.filtering = false
+		v.filterInput.Blur()
+		v.filterInput.SetValue("")
+		return v.setFilter("")
+	}
+
+	var cmd tea.Cmd
+	v.filterInput, cmd = v.filterInput.Update(msg)
+	return tea.Batch(cmd, v.setFilter(strings.TrimSpace(v.filterInput.Value())))
 }
 
 // Update handles messages
@@ -106,118 +213,222 @@ func (v *DatabasesView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		key := msg.String()
 
-		// Handle keybindings when not filtering
-		if !v.list.SettingFilter() {
-			// Check against configured keybindings
-			if v.keybindings.IsKey("databases", key, config.ActionSelect) || key == "enter" {
-				if item, ok := v.list.SelectedItem().(dbItem); ok {
-					return v, func() tea.Msg {
-						return SwitchViewMsg{
-							View:     "tables",
-							Database: item.name,
-						}
-					}
-				}
-			}
-			if v.keybindings.IsKey("databases", key, config.ActionQuit) {
-				return v, tea.Quit
-			}
-			if v.keybindings.IsKey("databases", key, config.ActionImport) {
-				var dbName string
-				if item, ok := v.list.SelectedItem().(dbItem); ok {
-					dbName = item.name
-				}
+		if v.filtering {
+			return v, v.updateFilter(msg)
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionFilter) {
+			v.filtering = true
+			v.filterInput.Focus()
+			return v, textinput.Blink
+		}
+		if key == "esc" && v.filter != "" {
+			v.filterInput.SetValue("")
+			return v, v.setFilter("")
+		}
+
+		// Handle keybindings
+		// Check against configured keybindings
+		if v.keybindings.IsKey("databases", key, config.ActionSelect) || key == "enter" {
+			if item, ok := v.list.SelectedItem().(dbItem); ok {
 				return v, func() tea.Msg {
 					return SwitchViewMsg{
-						View:     "import",
-						Database: dbName,
+						View:     "tables",
+						Database: item.name,
 					}
 				}
 			}
-			if v.keybindings.IsKey("databases", key, config.ActionExport) {
-				if item, ok := v.list.SelectedItem().(dbItem); ok {
-					return v, func() tea.Msg {
-						return SwitchViewMsg{
-							View:     "export",
-							Database: item.name,
-						}
-					}
-				}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionQuit) {
+			return v, tea.Quit
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionImport) {
+			var dbName string
+			if item, ok := v.list.SelectedItem().(dbItem); ok {
+				dbName = item.name
 			}
-			if v.keybindings.IsKey("databases", key, config.ActionQuery) {
-				var dbName string
-				if item, ok := v.list.SelectedItem().(dbItem); ok {
-					dbName = item.name
+			return v, func() tea.Msg {
+				return SwitchViewMsg{
+					View:     "import",
+					Database: dbName,
 				}
+			}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionExport) {
+			if item, ok := v.list.SelectedItem().(dbItem); ok {
 				return v, func() tea.Msg {
 					return SwitchViewMsg{
-						View:     "query",
-						Database: dbName,
+						View:     "export",
+						Database: item.name,
 					}
 				}
 			}
-			if v.keybindings.IsKey("databases", key, config.ActionRefresh) {
-				return v, v.loadDatabases
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionQuery) {
+			var dbName string
+			if item, ok := v.list.SelectedItem().(dbItem); ok {
+				dbName = item.name
 			}
-			if v.keybindings.IsKey("databases", key, config.ActionVariables) {
-				return v, func() tea.Msg {
-					return SwitchViewMsg{View: "settings"}
+			return v, func() tea.Msg {
+				return SwitchViewMsg{
+					View:     "query",
+					Database: dbName,
 				}
 			}
-			if v.keybindings.IsKey("databases", key, config.ActionUsers) {
-				return v, func() tea.Msg {
-					return SwitchViewMsg{View: "users"}
-				}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionRefresh) {
+			return v, v.loadDatabases()
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionVariables) {
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "settings"}
 			}
-			if v.keybindings.IsKey("databases", key, config.ActionBackup) {
-				return v, func() tea.Msg {
-					return SwitchViewMsg{View: "backup"}
-				}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionUsers) {
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "users"}
 			}
-			if v.keybindings.IsKey("databases", key, config.ActionNewDatabase) {
-				return v, func() tea.Msg {
-					return SwitchViewMsg{View: "setup"}
-				}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionBackup) {
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "backup"}
 			}
-			if v.keybindings.IsKey("databases", key, config.ActionDashboard) {
-				return v, func() tea.Msg {
-					return SwitchViewMsg{View: "dashboard"}
-				}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionNewDatabase) {
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "setup"}
 			}
-			if v.keybindings.IsKey("databases", key, config.ActionCluster) {
-				return v, func() tea.Msg {
-					return SwitchViewMsg{View: "cluster"}
-				}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionDashboard) {
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "dashboard"}
 			}
-			if v.keybindings.IsKey("databases", key, config.ActionSettings) {
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionCluster) {
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "cluster"}
+			}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionPlugins) {
+			var dbName string
+			if item, ok := v.list.SelectedItem().(dbItem); ok {
+				dbName = item.name
+			}
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "plugins", Database: dbName}
+			}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionSchemaDiff) {
+			var dbName string
+			if item, ok := v.list.SelectedItem().(dbItem); ok {
+				dbName = item.name
+			}
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "schemadiff", Database: dbName}
+			}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionSync) {
+			var dbName string
+			if item, ok := v.list.SelectedItem().(dbItem); ok {
+				dbName = item.name
+			}
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "sync", Database: dbName}
+			}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionTransfer) {
+			if item, ok := v.list.SelectedItem().(dbItem); ok {
 				return v, func() tea.Msg {
-					return SwitchViewMsg{View: "keybindings"}
+					return SwitchViewMsg{View: "transfer", Database: item.name}
 				}
 			}
 		}
+		if v.keybindings.IsKey("databases", key, config.ActionCloneMerge) {
+			var dbName string
+			if item, ok := v.list.SelectedItem().(dbItem); ok {
+				dbName = item.name
+			}
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "clone", Database: dbName}
+			}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionJobs) {
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "jobs"}
+			}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionTimeline) {
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "timeline"}
+			}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionAuditLog) {
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "audit"}
+			}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionProfiles) {
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "profiles"}
+			}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionForeignLink) {
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "foreign"}
+			}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionSettings) {
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "keybindings"}
+			}
+		}
+		if v.keybindings.IsKey("databases", key, config.ActionHelp) {
+			return v, func() tea.Msg {
+				return ShowHelpMsg{View: "databases"}
+			}
+		}
 
 	case tea.WindowSizeMsg:
 		v.width = msg.Width
 		v.height = msg.Height
 		v.list.SetSize(msg.Width, msg.Height-4)
 
-	case []db.Database:
-		v.databases = msg
-		items := make([]list.Item, len(msg))
-		for i, d := range msg {
-			items[i] = dbItem{name: d.Name}
+	case databaseFilterMsg:
+		if msg.seq != v.filterSeq {
+			return v, nil
 		}
-		v.list.SetItems(items)
-		return v, nil
+		return v, v.loadDatabases()
+
+	case databasePageMsg:
+		// Pages for a filter since replaced, or from before a reload, are dropped
+		if msg.filter != v.filter || (msg.page.Offset > 0 && msg.page.Offset != len(v.databases)) {
+			return v, nil
+		}
+		v.loading = false
+		v.err = nil
+		v.total = msg.page.Total
+		if msg.page.Offset == 0 {
+			v.databases = nil
+		}
+		v.databases = append(v.databases, msg.page.Databases...)
+		items := make([]list.Item, len(v.databases))
+		for i, d := range v.databases {
+			items[i] = dbItem{name: d.Name, protected: v.conn.IsProtectedDatabase(d.Name)}
+		}
+		cmd := v.list.SetItems(items)
+		if msg.page.Offset == 0 {
+			v.list.ResetSelected()
+		}
+		return v, tea.Batch(cmd, v.loadMore())
 
 	case error:
+		v.loading = false
 		v.err = msg
 		return v, nil
 	}
 
 	var cmd tea.Cmd
 	v.list, cmd = v.list.Update(msg)
-	return v, cmd
+	return v, tea.Batch(cmd, v.loadMore())
 }
 
 // View renders the view
@@ -225,15 +436,29 @@ func (v *DatabasesView) View() string {
 	var b strings.Builder
 
 	if v.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", v.err)))
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	if v.filtering {
+		b.WriteString("Filter: ")
+		b.WriteString(v.filterInput.View())
+		b.WriteString("\n\n")
+	} else if v.filter != "" {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("Filter: %s (Esc to clear)", v.filter)))
 		b.WriteString("\n\n")
 	}
 
 	b.WriteString(v.list.View())
 	b.WriteString("\n")
+	if len(v.databases) < v.total {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("Showing %d of %d databases, more load as you scroll", len(v.databases), v.total)))
+		b.WriteString("\n")
+	}
 
 	// Build help text with actual configured keybindings
-	help := fmt.Sprintf("Enter: Select | /: Filter | %s: New | %s: Stats | %s: Cluster | %s: Users | %s: Backup | %s: Import | %s: Export | %s: Refresh | %s: Keys | %s: Quit",
+	help := fmt.Sprintf("Enter: Select | %s: Filter | %s: New | %s: Stats | %s: Cluster | %s: Users | %s: Backup | %s: Import | %s: Export | %s: Plugins | %s: Diff | %s: Sync | %s: Transfer | %s: Clone/Merge | %s: Jobs | %s: Timeline | %s: Audit | %s: Link | %s: Profiles | %s: Refresh | %s: Keys | %s: Help | %s: Quit",
+		v.keybindings.GetKey("databases", config.ActionFilter),
 		v.keybindings.GetKey("databases", config.ActionNewDatabase),
 		v.keybindings.GetKey("databases", config.ActionDashboard),
 		v.keybindings.GetKey("databases", config.ActionCluster),
@@ -241,8 +466,19 @@ func (v *DatabasesView) View() string {
 		v.keybindings.GetKey("databases", config.ActionBackup),
 		v.keybindings.GetKey("databases", config.ActionImport),
 		v.keybindings.GetKey("databases", config.ActionExport),
+		v.keybindings.GetKey("databases", config.ActionPlugins),
+		v.keybindings.GetKey("databases", config.ActionSchemaDiff),
+		v.keybindings.GetKey("databases", config.ActionSync),
+		v.keybindings.GetKey("databases", config.ActionTransfer),
+		v.keybindings.GetKey("databases", config.ActionCloneMerge),
+		v.keybindings.GetKey("databases", config.ActionJobs),
+		v.keybindings.GetKey("databases", config.ActionTimeline),
+		v.keybindings.GetKey("databases", config.ActionAuditLog),
+		v.keybindings.GetKey("databases", config.ActionForeignLink),
+		v.keybindings.GetKey("databases", config.ActionProfiles),
 		v.keybindings.GetKey("databases", config.ActionRefresh),
 		v.keybindings.GetKey("databases", config.ActionSettings),
+		v.keybindings.GetKey("databases", config.ActionHelp),
 		v.keybindings.GetKey("databases", config.ActionQuit),
 	)
 	b.WriteString(helpStyle.Render(help))
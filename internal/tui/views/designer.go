@@ -0,0 +1,642 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type designerMode int
+
+const (
+	designerModeColumns designerMode = iota
+	designerModeColumn
+	designerModeRename
+	designerModeConfirm
+)
+
+// Column form fields
+const (
+	columnFieldName = iota
+	columnFieldType
+	columnFieldNullable
+	columnFieldPrimaryKey
+	columnFieldUnique
+	columnFieldAutoIncrement
+	columnFieldDefault
+	columnFieldCount
+)
+
+var columnFlagLabels = map[int]string{
+	columnFieldNullable:      "Nullable",
+	columnFieldPrimaryKey:    "Primary key",
+	columnFieldUnique:        "Unique",
+	columnFieldAutoIncrement: "Auto increment",
+}
+
+// DesignerView creates new tables and alters existing ones
+type DesignerView struct {
+	conn      *db.Connection
+	database  string
+	table     string      // Empty when creating a table
+	original  db.TableDef // Table as loaded from the server
+	def       db.TableDef
+	types     []string
+	cursor    int
+	mode      designerMode
+	form      *columnForm
+	nameInput textinput.Model
+	loading   bool
+	applying  bool
+	err       error
+	width     int
+	height    int
+}
+
+// columnForm edits a single column
+type columnForm struct {
+	index     int // -1 = new column
+	column    db.ColumnDef
+	name      textinput.Model
+	typ       textinput.Model
+	dflt      textinput.Model
+	typeIndex int
+	focused   int
+	err       error
+}
+
+type tableDefLoadedMsg struct {
+	def db.TableDef
+	err error
+}
+
+type tableDesignAppliedMsg struct {
+	err error
+}
+
+// NewDesignerView creates a table designer. An empty table creates a new one.
+func NewDesignerView(conn *db.Connection, database, table string, width, height int) *DesignerView {
+	nameInput := textinput.New()
+	nameInput.Placeholder = "table name"
+	nameInput.CharLimit = 64
+
+	v := &DesignerView{
+		conn:      conn,
+		database:  database,
+		table:     table,
+		types:     conn.ColumnTypes(),
+		nameInput: nameInput,
+		width:     width,
+		height:    height,
+	}
+
+	if table == "" {
+		v.def = db.TableDef{Columns: []db.ColumnDef{
+			{Name: "id", Type: v.types[0], PrimaryKey: true, AutoIncrement: true},
+		}}
+		v.startRename()
+	} else {
+		v.loading = true
+	}
+	return v
+}
+
+// Init initializes the view
+func (v *DesignerView) Init() tea.Cmd {
+	if v.table == "" {
+		return textinput.Blink
+	}
+	return v.loadTable
+}
+
+func (v *DesignerView) loadTable() tea.Msg {
+	if err := v.conn.UseDatabase(v.database); err != nil {
+		return tableDefLoadedMsg{err: err}
+	}
+	def, err := v.conn.LoadTableDef(v.table)
+	return tableDefLoadedMsg{def: def, err: err}
+}
+
+// statements builds the DDL for the current design
+func (v *DesignerView) statements() ([]string, error) {
+	if v.table == "" {
+		stmt, err := v.conn.BuildCreateTable(v.def)
+		if err != nil {
+			return nil, err
+		}
+		return []string{stmt}, nil
+	}
+	return v.conn.BuildAlterTable(v.original, v.def)
+}
+
+func (v *DesignerView) apply(statements []string) tea.Cmd {
+	return func() tea.Msg {
+		if err := v.conn.UseDatabase(v.database); err != nil {
+			return tableDesignAppliedMsg{err: err}
+		}
+		return tableDesignAppliedMsg{err: v.conn.ApplyDDL(statements)}
+	}
+}
+
+func (v *DesignerView) back() tea.Cmd {
+	return func() tea.Msg {
+		return SwitchViewMsg{View: "tables", Database: v.database}
+	}
+}
+
+// Update handles messages
+func (v *DesignerView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		return v, nil
+
+	case tableDefLoadedMsg:
+		v.loading = false
+		if msg.err != nil {
+			v.err = msg.err
+			return v, nil
+		}
+		v.original = msg.def
+		v.def = msg.def
+		v.def.Columns = append([]db.ColumnDef(nil), msg.def.Columns...)
+		return v, nil
+
+	case tableDesignAppliedMsg:
+		v.applying = false
+		v.mode = designerModeColumns
+		if msg.err != nil {
+			v.err = msg.err
+			return v, nil
+		}
+		return v, v.back()
+	}
+
+	switch v.mode {
+	case designerModeColumn:
+		return v.updateColumnForm(msg)
+	case designerModeRename:
+		return v.updateRename(msg)
+	case designerModeConfirm:
+		return v.updateConfirm(msg)
+	}
+	return v.updateColumns(msg)
+}
+
+func (v *DesignerView) updateColumns(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || v.loading || v.applying {
+		return v, nil
+	}
+
+	cols := v.def.Columns
+	switch key.String() {
+	case "esc":
+		return v, v.back()
+
+	case "q":
+		return v, tea.Quit
+
+	case "up", "k":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+
+	case "down", "j":
+		if v.cursor < len(cols)-1 {
+			v.cursor++
+		}
+
+	case "K":
+		if v.cursor > 0 {
+			cols[v.cursor-1], cols[v.cursor] = cols[v.cursor], cols[v.cursor-1]
+			v.cursor--
+		}
+
+	case "J":
+		if v.cursor < len(cols)-1 {
+			cols[v.cursor+1], cols[v.cursor] = cols[v.cursor], cols[v.cursor+1]
+			v.cursor++
+		}
+
+	case "a":
+		v.startColumnForm(-1)
+		return v, textinput.Blink
+
+	case "enter", "e":
+		if len(cols) > 0 {
+			v.startColumnForm(v.cursor)
+			return v, textinput.Blink
+		}
+
+	case "x", "delete":
+		if len(cols) > 0 {
+			v.def.Columns = append(cols[:v.cursor:v.cursor], cols[v.cursor+1:]...)
+			if v.cursor >= len(v.def.Columns) && v.cursor > 0 {
+				v.cursor--
+			}
+		}
+
+	case "r":
+		v.startRename()
+		return v, textinput.Blink
+
+	case "ctrl+s":
+		stmts, err := v.statements()
+		if err != nil {
+			v.err = err
+			return v, nil
+		}
+		if len(stmts) == 0 {
+			return v, nil
+		}
+		v.err = nil
+		v.mode = designerModeConfirm
+	}
+	return v, nil
+}
+
+func (v *DesignerView) startRename() {
+	v.nameInput.SetValue(v.def.Name)
+	v.nameInput.CursorEnd()
+	v.nameInput.Focus()
+	v.mode = designerModeRename
+}
+
+func (v *DesignerView) updateRename(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			v.nameInput.Blur()
+			if v.def.Name == "" {
+				return v, v.back()
+			}
+			v.mode = designerModeColumns
+			return v, nil
+		case "enter":
+			name := strings.TrimSpace(v.nameInput.Value())
+			if name == "" {
+				return v, nil
+			}
+			v.def.Name = name
+			v.nameInput.Blur()
+			v.mode = designerModeColumns
+			return v, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	v.nameInput, cmd = v.nameInput.Update(msg)
+	return v, cmd
+}
+
+func (v *DesignerView) updateConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || v.applying {
+		return v, nil
+	}
+
+	switch key.String() {
+	case "y", "Y":
+		stmts, err := v.statements()
+		if err != nil {
+			v.err = err
+			v.mode = designerModeColumns
+			return v, nil
+		}
+		v.applying = true
+		return v, v.apply(stmts)
+	case "n", "N", "esc":
+		v.mode = designerModeColumns
+	}
+	return v, nil
+}
+
+func (v *DesignerView) startColumnForm(index int) {
+	col := db.ColumnDef{Type: v.types[0], Nullable: true}
+	if index >= 0 {
+		col = v.def.Columns[index]
+	}
+
+	form := &columnForm{index: index, column: col}
+
+	form.name = textinput.New()
+	form.name.Placeholder = "column name"
+	form.name.CharLimit = 64
+	form.name.SetValue(col.Name)
+	form.name.Focus()
+
+	form.typ = textinput.New()
+	form.typ.Placeholder = "type"
+	form.typ.SetValue(col.Type)
+	for i, t := range v.types {
+		if strings.EqualFold(t, col.Type) {
+			form.typeIndex = i
+		}
+	}
+
+	form.dflt = textinput.New()
+	form.dflt.Placeholder = "none  (SQL expression, e.g. 'text', 0, CURRENT_TIMESTAMP)"
+	form.dflt.SetValue(col.Default)
+
+	v.form = form
+	v.mode = designerModeColumn
+}
+
+func (f *columnForm) input(field int) *textinput.Model {
+	switch field {
+	case columnFieldName:
+		return &f.name
+	case columnFieldType:
+		return &f.typ
+	case columnFieldDefault:
+		return &f.dflt
+	}
+	return nil
+}
+
+func (f *columnForm) focus(field int) {
+	if in := f.input(f.focused); in != nil {
+		in.Blur()
+	}
+	f.focused = (field + columnFieldCount) % columnFieldCount
+	if in := f.input(f.focused); in != nil {
+		in.Focus()
+	}
+}
+
+func (f *columnForm) flag(field int) *bool {
+	switch field {
+	case columnFieldNullable:
+		return &f.column.Nullable
+	case columnFieldPrimaryKey:
+		return &f.column.PrimaryKey
+	case columnFieldUnique:
+		return &f.column.Unique
+	case columnFieldAutoIncrement:
+		return &f.column.AutoIncrement
+	}
+	return nil
+}
+
+func (v *DesignerView) updateColumnForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	form := v.form
+
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			v.form = nil
+			v.mode = designerModeColumns
+			return v, nil
+
+		case "tab":
+			form.focus(form.focused + 1)
+			return v, nil
+
+		case "shift+tab":
+			form.focus(form.focused - 1)
+			return v, nil
+
+		case "up", "down":
+			if form.focused == columnFieldType {
+				if key.String() == "up" {
+					form.typeIndex = (form.typeIndex - 1 + len(v.types)) % len(v.types)
+				} else {
+					form.typeIndex = (form.typeIndex + 1) % len(v.types)
+				}
+				form.typ.SetValue(v.types[form.typeIndex])
+				form.typ.CursorEnd()
+			} else if key.String() == "up" {
+				form.focus(form.focused - 1)
+			} else {
+				form.focus(form.focused + 1)
+			}
+			return v, nil
+
+		case " ":
+			if flag := form.flag(form.focused); flag != nil {
+				*flag = !*flag
+				return v, nil
+			}
+
+		case "enter":
+			col := form.column
+			col.Name = strings.TrimSpace(form.name.Value())
+			col.Type = strings.TrimSpace(form.typ.Value())
+			col.Default = strings.TrimSpace(form.dflt.Value())
+			if col.Name == "" {
+				form.err = fmt.Errorf("column name is required")
+				return v, nil
+			}
+			if col.Type == "" {
+				form.err = fmt.Errorf("column type is required")
+				return v, nil
+			}
+			// Key columns can never be NULL
+			if col.PrimaryKey {
+				col.Nullable = false
+			}
+
+			if form.index < 0 {
+				v.def.Columns = append(v.def.Columns, col)
+				v.cursor = len(v.def.Columns) - 1
+			} else {
+				v.def.Columns[form.index] = col
+			}
+			v.form = nil
+			v.mode = designerModeColumns
+			return v, nil
+		}
+	}
+
+	if in := form.input(form.focused); in != nil {
+		var cmd tea.Cmd
+		*in, cmd = in.Update(msg)
+		return v, cmd
+	}
+	return v, nil
+}
+
+// View renders the view
+func (v *DesignerView) View() string {
+	var b strings.Builder
+
+	title := "New Table"
+	if v.table != "" {
+		title = fmt.Sprintf("Alter Table: %s", v.table)
+	}
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("  (%s)", v.database)))
+	b.WriteString("\n\n")
+
+	if v.loading {
+		b.WriteString("Loading table...\n")
+		return b.String()
+	}
+
+	switch v.mode {
+	case designerModeColumn:
+		b.WriteString(v.viewColumnForm())
+		return b.String()
+	case designerModeRename:
+		b.WriteString(focusedStyle.Render("Table name:"))
+		b.WriteString("\n")
+		b.WriteString(v.nameInput.View())
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Enter: Save | Esc: Cancel"))
+		return b.String()
+	}
+
+	name := v.def.Name
+	if v.table != "" && name != v.table {
+		name = fmt.Sprintf("%s → %s", v.table, name)
+	}
+	b.WriteString(headerStyle.Render("Table: "))
+	b.WriteString(name)
+	b.WriteString("\n\n")
+
+	b.WriteString(headerStyle.Render(fmt.Sprintf("  %-24s %-18s %-9s %-12s %s", "Column", "Type", "Null", "Keys", "Default")))
+	b.WriteString("\n")
+	for i, col := range v.def.Columns {
+		null := "NOT NULL"
+		if col.Nullable {
+			null = "NULL"
+		}
+		var keys []string
+		if col.PrimaryKey {
+			keys = append(keys, "PK")
+		}
+		if col.Unique {
+			keys = append(keys, "UQ")
+		}
+		if col.AutoIncrement {
+			keys = append(keys, "AI")
+		}
+		colName := col.Name
+		if col.Original == "" && v.table != "" {
+			colName += " (new)"
+		} else if col.Original != "" && col.Original != col.Name {
+			colName = fmt.Sprintf("%s (was %s)", col.Name, col.Original)
+		}
+
+		line := fmt.Sprintf("%-24s %-18s %-9s %-12s %s", colName, col.Type, null, strings.Join(keys, " "), col.Default)
+		if i == v.cursor {
+			b.WriteString(selectedStyle.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	if len(v.def.Columns) == 0 {
+		b.WriteString(mutedStyle.Render("  No columns - press a to add one"))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(headerStyle.Render("Preview:"))
+	b.WriteString("\n")
+	stmts, err := v.statements()
+	switch {
+	case err != nil:
+		b.WriteString(errorStyle.Render(err.Error()))
+	case len(stmts) == 0:
+		b.WriteString(mutedStyle.Render("No changes"))
+	default:
+		b.WriteString(strings.Join(stmts, ";\n") + ";")
+	}
+	b.WriteString("\n\n")
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	switch {
+	case v.applying:
+		b.WriteString("Applying changes...")
+	case v.mode == designerModeConfirm:
+		b.WriteString(errorStyle.Render("Apply these statements? (y/n)"))
+	default:
+		b.WriteString(helpStyle.Render("a: Add | Enter: Edit | x: Remove | K/J: Move | r: Rename table | Ctrl+S: Apply | Esc: Back"))
+	}
+
+	return b.String()
+}
+
+func (v *DesignerView) viewColumnForm() string {
+	var b strings.Builder
+	form := v.form
+
+	if form.index < 0 {
+		b.WriteString(headerStyle.Render("Add Column"))
+	} else {
+		b.WriteString(headerStyle.Render(fmt.Sprintf("Edit Column: %s", v.def.Columns[form.index].Name)))
+	}
+	b.WriteString("\n\n")
+
+	label := func(field int, text string) string {
+		if form.focused == field {
+			return focusedStyle.Render(text)
+		}
+		return blurredStyle.Render(text)
+	}
+
+	b.WriteString(label(columnFieldName, "Name:"))
+	b.WriteString("\n")
+	b.WriteString(form.name.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(label(columnFieldType, "Type:"))
+	if form.focused == columnFieldType {
+		b.WriteString(mutedStyle.Render("  ↑/↓ to pick a common type, or type your own"))
+	}
+	b.WriteString("\n")
+	b.WriteString(form.typ.View())
+	b.WriteString("\n\n")
+
+	for field := columnFieldNullable; field <= columnFieldAutoIncrement; field++ {
+		box := "[ ]"
+		if *form.flag(field) {
+			box = "[x]"
+		}
+		b.WriteString(label(field, fmt.Sprintf("%s %s", box, columnFlagLabels[field])))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(label(columnFieldDefault, "Default:"))
+	b.WriteString("\n")
+	b.WriteString(form.dflt.View())
+	b.WriteString("\n\n")
+
+	if form.err != nil {
+		b.WriteString(renderError(form.err))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Enter: Save | Tab: Next | Space: Toggle | Esc: Cancel"))
+
+	return b.String()
+}
@@ -0,0 +1,351 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dumpPreviewRows is how many rows of a table's first INSERT are shown
+const dumpPreviewRows = 10
+
+// DumpBrowserView shows what a SQL dump holds without importing it: its
+// tables, each one's CREATE statement and first rows, and extracting one
+// table into a file of its own
+type DumpBrowserView struct {
+	path     string
+	database string // Import target to return to
+
+	index   *db.DumpIndex
+	loading bool
+	read    *atomic.Int64 // Statements read so far while loading
+	cursor  int
+
+	detail bool // Showing the selected table
+	scroll int  // First line of the detail shown
+
+	extracting bool // Asking where to extract to
+	output     textinput.Model
+
+	message string
+	err     error
+
+	width  int
+	height int
+}
+
+type dumpIndexedMsg struct {
+	index *db.DumpIndex
+	err   error
+}
+
+type dumpExtractedMsg struct {
+	stats *db.DumpExtractStats
+	err   error
+}
+
+// NewDumpBrowserView creates a browser for the dump at path
+func NewDumpBrowserView(path, database string, width, height int) *DumpBrowserView {
+	output := textinput.New()
+	output.Placeholder = "table.sql"
+	output.Width = 60
+
+	return &DumpBrowserView{
+		path:     path,
+		database: database,
+		loading:  true,
+		read:     &atomic.Int64{},
+		output:   output,
+		width:    width,
+		height:   height,
+	}
+}
+
+// Init starts reading the dump
+func (v *DumpBrowserView) Init() tea.Cmd {
+	path, read := v.path, v.read
+	index := func() tea.Msg {
+		index, err := db.IndexDump(path, func(statements int64) {
+			read.Store(statements)
+		})
+		return dumpIndexedMsg{index: index, err: err}
+	}
+	return tea.Batch(index, progressTick())
+}
+
+func (v *DumpBrowserView) selected() *db.DumpTable {
+	if v.index == nil || v.cursor < 0 || v.cursor >= len(v.index.Tables) {
+		return nil
+	}
+	return v.index.Tables[v.cursor]
+}
+
+// Update handles messages
+func (v *DumpBrowserView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		return v, nil
+
+	case progressTickMsg:
+		if v.loading {
+			return v, progressTick()
+		}
+		return v, nil
+
+	case dumpIndexedMsg:
+		v.loading = false
+		v.index, v.err = msg.index, msg.err
+		return v, nil
+
+	case dumpExtractedMsg:
+		v.err = msg.err
+		if msg.err == nil {
+			v.message = fmt.Sprintf("Extracted %d statements (%d rows) to %s", msg.stats.Statements, msg.stats.Rows, msg.stats.Output)
+		}
+		return v, nil
+
+	case tea.KeyMsg:
+		if v.extracting {
+			return v.updateExtract(msg)
+		}
+		switch msg.String() {
+		case "esc":
+			if v.detail {
+				v.detail = false
+				return v, nil
+			}
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "import", Database: v.database}
+			}
+		case "q", "ctrl+c":
+			return v, tea.Quit
+		case "up", "k":
+			if v.detail {
+				v.scroll = max(v.scroll-1, 0)
+			} else if v.cursor > 0 {
+				v.cursor--
+			}
+		case "down", "j":
+			if v.detail {
+				v.scroll++
+			} else if v.index != nil && v.cursor < len(v.index.Tables)-1 {
+				v.cursor++
+			}
+		case "enter":
+			if !v.detail && v.selected() != nil {
+				v.detail = true
+				v.scroll = 0
+				v.message = ""
+			}
+		case "x":
+			if t := v.selected(); t != nil {
+				v.extracting = true
+				v.err = nil
+				v.message = ""
+				v.output.SetValue(filepath.Join(filepath.Dir(v.path), t.Name+".sql"))
+				v.output.CursorEnd()
+				v.output.Focus()
+				return v, textinput.Blink
+			}
+		}
+	}
+	return v, nil
+}
+
+// updateExtract handles the output path prompt
+func (v *DumpBrowserView) updateExtract(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		v.extracting = false
+		v.output.Blur()
+		return v, nil
+	case "enter":
+		output := strings.TrimSpace(v.output.Value())
+		if output == "" {
+			return v, nil
+		}
+		v.extracting = false
+		v.output.Blur()
+		path, table := v.path, v.selected()
+		v.message = fmt.Sprintf("Extracting %s...", table.QualifiedName())
+		return v, func() tea.Msg {
+			stats, err := db.ExtractDumpTable(path, table, output)
+			return dumpExtractedMsg{stats: stats, err: err}
+		}
+	}
+	var cmd tea.Cmd
+	v.output, cmd = v.output.Update(msg)
+	return v, cmd
+}
+
+// View renders the view
+func (v *DumpBrowserView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Dump: " + filepath.Base(v.path)))
+	b.WriteString("\n\n")
+
+	switch {
+	case v.loading:
+		b.WriteString(fmt.Sprintf("Reading the dump... %d statements\n", v.read.Load()))
+	case v.index == nil:
+	case v.detail:
+		b.WriteString(v.viewDetail())
+	default:
+		b.WriteString(v.viewTables())
+	}
+
+	if v.extracting {
+		b.WriteString("\n")
+		b.WriteString(focusedStyle.Render("Extract to: "))
+		b.WriteString(v.output.View())
+		b.WriteString("\n")
+	}
+	if v.err != nil {
+		b.WriteString("\n")
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n")
+	} else if v.message != "" {
+		b.WriteString("\n")
+		b.WriteString(successStyle.Render(v.message))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	switch {
+	case v.extracting:
+		b.WriteString(helpStyle.Render("Enter: Extract | Esc: Cancel"))
+	case v.detail:
+		b.WriteString(helpStyle.Render("↑↓: Scroll | x: Extract table | Esc: Back to tables"))
+	default:
+		b.WriteString(helpStyle.Render("↑↓: Select | Enter: CREATE and rows | x: Extract table | Esc: Back"))
+	}
+	return b.String()
+}
+
+// viewTables lists the tables of the dump
+func (v *DumpBrowserView) viewTables() string {
+	var b strings.Builder
+	if len(v.index.Databases) > 0 {
+		b.WriteString(mutedStyle.Render("Databases: " + strings.Join(v.index.Databases, ", ")))
+		b.WriteString("\n\n")
+	}
+	if len(v.index.Tables) == 0 {
+		b.WriteString(mutedStyle.Render("No tables found in the dump."))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	b.WriteString(headerStyle.Render(fmt.Sprintf("  %-40s %10s %12s  %s", "TABLE", "INSERTS", "ROWS", "CREATE")))
+	b.WriteString("\n")
+	visible := max(v.height-14, 5)
+	start := max(v.cursor-visible+1, 0)
+	for i := start; i < len(v.index.Tables) && i < start+visible; i++ {
+		t := v.index.Tables[i]
+		create := "-"
+		if t.CreateSQL != "" {
+			create = fmt.Sprintf("line %d", t.CreateLine)
+		}
+		line := fmt.Sprintf("  %-40s %10d %12d  %s", truncateRunes(t.QualifiedName(), 40), t.Inserts, t.Rows, create)
+		if i == v.cursor {
+			b.WriteString(selectedStyle.Render(line))
+		} else {
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("%d tables in %d statements", len(v.index.Tables), v.index.Statements)))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// viewDetail shows the selected table's CREATE statement and first rows
+func (v *DumpBrowserView) viewDetail() string {
+	t := v.selected()
+	var lines []string
+	lines = append(lines, headerStyle.Render(fmt.Sprintf("%s: %d INSERT statements, %d rows", t.QualifiedName(), t.Inserts, t.Rows)), "")
+	if t.CreateSQL != "" {
+		lines = append(lines, mutedStyle.Render(fmt.Sprintf("-- line %d", t.CreateLine)))
+		lines = append(lines, strings.Split(t.CreateSQL+";", "\n")...)
+	} else {
+		lines = append(lines, mutedStyle.Render("No CREATE TABLE in the dump"))
+	}
+
+	columns, rows := t.PreviewRows(dumpPreviewRows)
+	if len(rows) > 0 {
+		lines = append(lines, "", headerStyle.Render(fmt.Sprintf("First %d rows:", len(rows))))
+		lines = append(lines, renderDumpRows(columns, rows)...)
+	}
+
+	visible := max(v.height-12, 5)
+	v.scroll = min(v.scroll, max(len(lines)-visible, 0))
+	end := min(v.scroll+visible, len(lines))
+	return strings.Join(lines[v.scroll:end], "\n") + "\n"
+}
+
+// renderDumpRows lays preview rows out in columns
+func renderDumpRows(columns []string, rows [][]string) []string {
+	const maxWidth = 24
+	widths := make([]int, len(columns))
+	for i, c := range columns {
+		widths[i] = min(len([]rune(c)), maxWidth)
+	}
+	cells := make([][]string, len(rows))
+	for r, row := range rows {
+		cells[r] = make([]string, len(row))
+		for i, value := range row {
+			value = truncateRunes(strings.ReplaceAll(value, "\n", `\n`), maxWidth)
+			cells[r][i] = value
+			if i < len(widths) {
+				widths[i] = max(widths[i], len([]rune(value)))
+			}
+		}
+	}
+
+	pad := func(values []string) string {
+		var b strings.Builder
+		for i, value := range values {
+			b.WriteString("  ")
+			b.WriteString(value)
+			if i < len(widths) {
+				b.WriteString(strings.Repeat(" ", max(widths[i]-len([]rune(value)), 0)))
+			}
+		}
+		return b.String()
+	}
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = truncateRunes(c, maxWidth)
+	}
+	lines := []string{headerStyle.Render(pad(header))}
+	for _, row := range cells {
+		lines = append(lines, pad(row))
+	}
+	return lines
+}
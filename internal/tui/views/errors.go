@@ -0,0 +1,41 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+)
+
+// renderError renders an error with its remediation hint, if one is known
+func renderError(err error) string {
+	return errorStyle.Render(fmt.Sprintf("Error: %v", err)) + renderErrorHint(err)
+}
+
+// renderErrorHint renders the explanation and fix for a known server error,
+// or an empty string
+func renderErrorHint(err error) string {
+	hint := db.ExplainError(err)
+	if hint == nil {
+		return ""
+	}
+	return "\n" + headerStyle.Render(hint.Title+": ") + mutedStyle.Render(hint.Explanation) +
+		"\n" + mutedStyle.Render("Fix: "+hint.Fix)
+}
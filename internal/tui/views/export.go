@@ -21,11 +21,16 @@ package views
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/blubskye/yandere_sql_manager/internal/buffer"
+	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
-	"github.com/charmbracelet/bubbles/progress"
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/blubskye/yandere_sql_manager/internal/webhook"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -53,18 +58,33 @@ type ExportView struct {
 	noData     bool
 	noCreate   bool
 	addDrop    bool
+	dialect    db.OutputDialect
+	sampleRows textinput.Model // Rows per table, empty = all
+	splitSize  textinput.Model // MB per part, empty = one file
+	noOwner    bool            // PostgreSQL: restores under any role
+	noACL      bool            // PostgreSQL: no GRANT/REVOKE
 
-	progress     progress.Model
-	currentTable string
-	progressPct  float64
+	progress *progressPanel
+
+	scripts db.OperationScripts // The profile's export scripts
+
+	converters *db.ValueConverters // From the config, for exports in another dialect
 
 	err      error
 	done     bool
 	outputFile string
+	issues     []db.DialectIssue
+	filtered   []string // Tables row-level security may have filtered
+	scriptResults []db.ScriptResult
+	split         *buffer.SplitManifest // Parts the dump was split into
 }
 
-// NewExportView creates a new export view
-func NewExportView(conn *db.Connection, database string, width, height int) *ExportView {
+// exportDialects are the output dialects Space cycles through
+var exportDialects = []db.OutputDialect{db.DialectNative, db.DialectSQLServer, db.DialectOracle}
+
+// NewExportView creates a new export view, with the scripts and ownership
+// choice of the connection's profile and the value converters of the config
+func NewExportView(conn *db.Connection, cfg *config.Config, database string, profile config.Profile, width, height int) *ExportView {
 	// Default output filename
 	timestamp := time.Now().Format("20060102_150405")
 	defaultOutput := fmt.Sprintf("%s_%s.sql", database, timestamp)
@@ -75,10 +95,22 @@ func NewExportView(conn *db.Connection, database string, width, height int) *Exp
 	outputPath.Focus()
 	outputPath.Width = 50
 
-	prog := progress.New(
-		progress.WithDefaultGradient(),
-		progress.WithWidth(40),
-	)
+	sampleRows := textinput.New()
+	sampleRows.Placeholder = "all rows"
+	sampleRows.CharLimit = 9
+	sampleRows.Width = 12
+
+	splitSize := textinput.New()
+	splitSize.Placeholder = "one file"
+	splitSize.CharLimit = 7
+	splitSize.Width = 12
+
+	postgres := conn.Config.Type == db.DatabaseTypePostgres
+
+	converters, err := cfg.ValueConverters()
+	if err != nil {
+		logging.Warn("Converters: %v", err)
+	}
 
 	return &ExportView{
 		conn:       conn,
@@ -87,8 +119,13 @@ func NewExportView(conn *db.Connection, database string, width, height int) *Exp
 		height:     height,
 		phase:      exportPhaseConfig,
 		outputPath: outputPath,
+		sampleRows: sampleRows,
+		splitSize:  splitSize,
 		addDrop:    true,
-		progress:   prog,
+		noOwner:    postgres && profile.NoOwner,
+		noACL:      postgres && profile.NoACL,
+		scripts:    profile.Scripts.Export,
+		converters: converters,
 	}
 }
 
@@ -101,18 +138,16 @@ func (v *ExportView) Init() tea.Cmd {
 func (v *ExportView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if v.phase == exportPhaseExporting {
+			return v, v.progress.runningKey(msg.String())
+		}
 		switch msg.String() {
 		case "esc":
-			if v.phase == exportPhaseExporting {
-				return v, nil
-			}
 			return v, func() tea.Msg {
 				return SwitchViewMsg{View: "databases"}
 			}
 		case "q", "ctrl+c":
-			if v.phase != exportPhaseExporting {
-				return v, tea.Quit
-			}
+			return v, tea.Quit
 		case "enter":
 			if v.phase == exportPhaseConfig {
 				return v, v.startExport()
@@ -125,7 +160,15 @@ func (v *ExportView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "tab":
 			if v.phase == exportPhaseConfig {
 				// Cycle through options
-				v.focusedInput = (v.focusedInput + 1) % 4
+				v.focusedInput = (v.focusedInput + 1) % v.optionCount()
+				v.sampleRows.Blur()
+				v.splitSize.Blur()
+				switch v.focusedInput {
+				case 5:
+					v.sampleRows.Focus()
+				case 6:
+					v.splitSize.Focus()
+				}
 			}
 			return v, nil
 		case " ":
@@ -137,6 +180,17 @@ func (v *ExportView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					v.noCreate = !v.noCreate
 				case 3:
 					v.addDrop = !v.addDrop
+				case 4:
+					for i, d := range exportDialects {
+						if d == v.dialect {
+							v.dialect = exportDialects[(i+1)%len(exportDialects)]
+							break
+						}
+					}
+				case 7:
+					v.noOwner = !v.noOwner
+				case 8:
+					v.noACL = !v.noACL
 				}
 			}
 			return v, nil
@@ -146,33 +200,42 @@ func (v *ExportView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		v.width = msg.Width
 		v.height = msg.Height
 
-	case exportProgressMsg:
-		v.currentTable = msg.table
-		v.progressPct = msg.percent
+	case progressTickMsg:
+		if v.phase == exportPhaseExporting {
+			return v, progressTick()
+		}
 		return v, nil
 
 	case exportDoneMsg:
 		v.phase = exportPhaseDone
-		v.done = true
+		v.err = msg.err
+		v.done = msg.err == nil
 		v.outputFile = msg.outputFile
-		return v, nil
-
-	case error:
-		v.err = msg
-		v.phase = exportPhaseDone
+		v.issues = msg.issues
+		v.filtered = msg.filtered
+		if msg.stats != nil {
+			v.scriptResults = msg.stats.Scripts
+			v.split = msg.stats.Split
+		}
 		return v, nil
 	}
 
 	var cmd tea.Cmd
 	if v.phase == exportPhaseConfig && v.focusedInput == 0 {
 		v.outputPath, cmd = v.outputPath.Update(msg)
+	} else if v.phase == exportPhaseConfig && v.focusedInput == 5 {
+		v.sampleRows, cmd = v.sampleRows.Update(msg)
+	} else if v.phase == exportPhaseConfig && v.focusedInput == 6 {
+		v.splitSize, cmd = v.splitSize.Update(msg)
 	}
 	return v, cmd
 }
 
 func (v *ExportView) startExport() tea.Cmd {
 	v.phase = exportPhaseExporting
-	v.progressPct = 0
+	v.progress = newProgressPanel("Exporting", progress.Rows, 0)
+	bar := v.progress
+	ctx, _ := bar.cancellable()
 
 	outputPath := v.outputPath.Value()
 	if !filepath.IsAbs(outputPath) {
@@ -180,33 +243,109 @@ func (v *ExportView) startExport() tea.Cmd {
 		outputPath, _ = filepath.Abs(outputPath)
 	}
 
-	return func() tea.Msg {
+	sampleRows := 0
+	if value := strings.TrimSpace(v.sampleRows.Value()); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return func() tea.Msg {
+				return exportDoneMsg{database: v.database, err: fmt.Errorf("sample rows must be a positive number, got %q", value)}
+			}
+		}
+		sampleRows = n
+	}
+
+	var splitSize int64
+	if value := strings.TrimSpace(v.splitSize.Value()); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return func() tea.Msg {
+				return exportDoneMsg{database: v.database, err: fmt.Errorf("part size must be a positive number of MB, got %q", value)}
+			}
+		}
+		splitSize = int64(n) * 1024 * 1024
+	}
+
+	export := runJob("Export of "+v.database, bar, func() tea.Msg {
 		opts := db.ExportOptions{
 			FilePath:     outputPath,
 			Database:     v.database,
 			NoData:       v.noData,
 			NoCreate:     v.noCreate,
 			AddDropTable: v.addDrop,
-			OnProgress: func(currentTable string, tableNum, totalTables int, rowsExported int64) {
-				// Progress updates
+			Dialect:      v.dialect,
+			Converters:   v.converters,
+			SampleRows:   sampleRows,
+			Scripts:      v.scripts,
+			SplitSize:    splitSize,
+			NoOwner:      v.noOwner,
+			NoACL:        v.noACL,
+			Context:      ctx,
+			OnProgress: func(currentTable string, tableNum, totalTables int, rowsExported, bytesWritten int64) {
+				bar.SetCurrent(currentTable, tableNum, totalTables)
+				bar.Set(rowsExported)
+				bar.SetBytes(bytesWritten)
 			},
 		}
 
-		if err := v.conn.ExportSQL(opts); err != nil {
-			return err
+		conn, release := jobConnection(v.conn)
+		defer release()
+
+		// Estimated from table statistics, so the ETA is approximate
+		bar.SetTotal(conn.ExportRowEstimate(opts))
+
+		stats, err := conn.ExportSQLWithStats(opts)
+		elapsed := bar.Snapshot().Elapsed
+		if err != nil {
+			// stats are only set when an after script failed
+			return exportDoneMsg{database: v.database, elapsed: elapsed, stats: stats, err: err}
 		}
 
-		return exportDoneMsg{outputFile: outputPath}
+		return exportDoneMsg{database: v.database, elapsed: elapsed, outputFile: stats.OutputFile, stats: stats, issues: stats.DialectIssues, filtered: stats.FilteredTables}
+	})
+
+	return tea.Batch(export, progressTick())
+}
+
+// optionCount returns how many options Tab cycles through; the ownership
+// ones are PostgreSQL's
+func (v *ExportView) optionCount() int {
+	if v.conn.Config.Type == db.DatabaseTypePostgres {
+		return 9
+	}
+	return 7
+}
+
+// renderCheckbox renders a toggled option of the export form
+func renderCheckbox(label string, checked, focused bool) string {
+	checkbox := "[ ]"
+	if checked {
+		checkbox = "[x]"
 	}
+	style := blurredStyle
+	if focused {
+		style = focusedStyle
+	}
+	return style.Render(fmt.Sprintf("  %s %s", checkbox, label))
 }
 
-type exportProgressMsg struct {
-	table   string
-	percent float64
+// ownsJob reports whether the view started the job of p
+func (v *ExportView) ownsJob(p *progressPanel) bool {
+	return p == v.progress
 }
 
 type exportDoneMsg struct {
+	database   string
+	elapsed    time.Duration
 	outputFile string
+	stats      *db.ExportStats
+	issues     []db.DialectIssue
+	filtered   []string
+	err        error
+}
+
+func (m exportDoneMsg) JobResult() JobResult {
+	return JobResult{View: "export", Title: "Export of " + m.database, Elapsed: m.elapsed, Err: m.err,
+		Event: webhook.ExportEvent(m.database, m.outputFile, m.stats, m.elapsed, m.err)}
 }
 
 // View renders the view
@@ -242,37 +381,68 @@ func (v *ExportView) View() string {
 		}
 
 		for _, opt := range options {
-			checkbox := "[ ]"
-			if opt.checked {
-				checkbox = "[x]"
-			}
-			style := blurredStyle
-			if v.focusedInput == opt.idx {
-				style = focusedStyle
-			}
-			b.WriteString(style.Render(fmt.Sprintf("  %s %s", checkbox, opt.label)))
+			b.WriteString(renderCheckbox(opt.label, opt.checked, v.focusedInput == opt.idx))
+			b.WriteString("\n")
+		}
+		dialectStyle := blurredStyle
+		if v.focusedInput == 4 {
+			dialectStyle = focusedStyle
+		}
+		b.WriteString(dialectStyle.Render(fmt.Sprintf("  Dialect: %s", v.dialect)))
+		b.WriteString("\n")
+		sampleStyle := blurredStyle
+		if v.focusedInput == 5 {
+			sampleStyle = focusedStyle
+		}
+		b.WriteString(sampleStyle.Render("  Sample rows per table (by primary key): "))
+		b.WriteString(v.sampleRows.View())
+		b.WriteString("\n")
+		splitStyle := blurredStyle
+		if v.focusedInput == 6 {
+			splitStyle = focusedStyle
+		}
+		b.WriteString(splitStyle.Render("  Split into parts of at most (MB): "))
+		b.WriteString(v.splitSize.View())
+		b.WriteString("\n")
+		if v.optionCount() > 7 {
+			b.WriteString(renderCheckbox("No owners (restores under any role)", v.noOwner, v.focusedInput == 7))
+			b.WriteString("\n")
+			b.WriteString(renderCheckbox("No privileges (GRANT/REVOKE)", v.noACL, v.focusedInput == 8))
 			b.WriteString("\n")
 		}
 
 		b.WriteString("\n")
-		b.WriteString(helpStyle.Render("Tab: Next option | Space: Toggle | Enter: Export | Esc: Cancel"))
+		b.WriteString(helpStyle.Render("Tab: Next option | Space: Toggle / change dialect | Enter: Export | Esc: Cancel"))
 
 	case exportPhaseExporting:
-		b.WriteString("Exporting...\n\n")
-		if v.currentTable != "" {
-			b.WriteString(fmt.Sprintf("Current table: %s\n", v.currentTable))
-		}
-		b.WriteString(v.progress.ViewAs(v.progressPct / 100))
+		b.WriteString(v.progress.View())
 		b.WriteString("\n\n")
-		b.WriteString("Please wait...")
+		b.WriteString(v.progress.runningHelp())
 
 	case exportPhaseDone:
 		if v.err != nil {
-			b.WriteString(errorStyle.Render(fmt.Sprintf("Export failed: %v", v.err)))
+			b.WriteString(errorStyle.Render(fmt.Sprintf("Export failed: %v", v.err)) + renderErrorHint(v.err))
 		} else {
 			b.WriteString(successStyle.Render("Export completed successfully!"))
 			b.WriteString("\n\n")
 			b.WriteString(fmt.Sprintf("Output: %s", v.outputFile))
+			if v.split != nil {
+				b.WriteString(fmt.Sprintf("\nParts: %d of at most %s", len(v.split.Parts), db.FormatSize(v.split.PartSize)))
+			}
+			if len(v.filtered) > 0 {
+				b.WriteString("\n\n")
+				b.WriteString(focusedStyle.Render(fmt.Sprintf("⚠ Row-level security may have hidden rows of: %s", strings.Join(v.filtered, ", "))))
+				b.WriteString("\n")
+				b.WriteString(mutedStyle.Render("  Export as the table owner or a role with BYPASSRLS for a complete dump"))
+			}
+			if v.dialect != db.DialectNative {
+				b.WriteString("\n\n")
+				b.WriteString(v.renderIssues())
+			}
+		}
+		if len(v.scriptResults) > 0 {
+			b.WriteString("\n\n")
+			b.WriteString(renderScriptResults(v.scriptResults))
 		}
 		b.WriteString("\n\n")
 		b.WriteString(helpStyle.Render("Enter: Continue | Esc: Back"))
@@ -280,3 +450,40 @@ func (v *ExportView) View() string {
 
 	return b.String()
 }
+
+// renderScriptResults lists the before and after scripts that ran
+func renderScriptResults(results []db.ScriptResult) string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("Scripts:"))
+	for _, r := range results {
+		b.WriteString("\n")
+		line := fmt.Sprintf("  %-6s %s", r.Phase, r.Script)
+		if r.Error != "" {
+			b.WriteString(errorStyle.Render(line + ": " + r.Error))
+			continue
+		}
+		b.WriteString(line + mutedStyle.Render(fmt.Sprintf(" (%d statements)", r.Statements)))
+	}
+	return b.String()
+}
+
+// renderIssues summarizes what didn't translate to the output dialect
+func (v *ExportView) renderIssues() string {
+	if len(v.issues) == 0 {
+		return successStyle.Render(fmt.Sprintf("Everything translated cleanly to %s", v.dialect))
+	}
+
+	var b strings.Builder
+	b.WriteString(headerStyle.Render(fmt.Sprintf("%d %s incompatibilities (also listed at the end of the file):", len(v.issues), v.dialect)))
+	b.WriteString("\n")
+	const maxShown = 10
+	for i, issue := range v.issues {
+		if i == maxShown {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("  ... and %d more", len(v.issues)-maxShown)))
+			b.WriteString("\n")
+			break
+		}
+		b.WriteString("  " + issue.String() + "\n")
+	}
+	return b.String()
+}
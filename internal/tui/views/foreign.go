@@ -0,0 +1,440 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type foreignMode int
+
+const (
+	foreignModeForm foreignMode = iota
+	foreignModePlanning
+	foreignModePreview
+	foreignModeRunning
+	foreignModeDone
+)
+
+// Foreign link form fields, in tab order
+const (
+	foreignFieldProfile = iota
+	foreignFieldDatabase
+	foreignFieldSchema
+	foreignFieldTables
+	foreignFieldName
+	foreignFieldLocal
+	foreignFieldPassword
+	foreignFieldCount
+)
+
+// ForeignLinkView walks through linking another profile's tables into this
+// server with a foreign data wrapper, FEDERATED or CONNECT, previewing the
+// server, user mapping and table statements before running them
+type ForeignLinkView struct {
+	conn *db.Connection
+	cfg  *config.Config
+	mode foreignMode
+
+	profiles     []string
+	profileIndex int
+	inputs       []textinput.Model // Indexed by field; the profile field has none
+	focused      int
+	confirm      bool // Waiting for y before running the plan
+
+	plan *db.ForeignLinkPlan
+	err  error
+
+	width  int
+	height int
+}
+
+type foreignPlannedMsg struct {
+	plan *db.ForeignLinkPlan
+	err  error
+}
+
+type foreignCreatedMsg struct {
+	err error
+}
+
+// NewForeignLinkView creates a new foreign link view
+func NewForeignLinkView(conn *db.Connection, cfg *config.Config, width, height int) *ForeignLinkView {
+	v := &ForeignLinkView{
+		conn:   conn,
+		cfg:    cfg,
+		inputs: make([]textinput.Model, foreignFieldCount),
+		width:  width,
+		height: height,
+	}
+	if cfg != nil {
+		v.profiles = cfg.ListProfiles()
+		sort.Strings(v.profiles)
+	}
+
+	placeholders := map[int]string{
+		foreignFieldDatabase: "Remote database",
+		foreignFieldSchema:   "public",
+		foreignFieldTables:   "All tables (or a comma-separated list)",
+		foreignFieldName:     "Link name, e.g. reporting",
+		foreignFieldLocal:    "Same as the link name",
+		foreignFieldPassword: "Saved in the profile",
+	}
+	for field, placeholder := range placeholders {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.Width = 40
+		if field == foreignFieldPassword {
+			input.EchoMode = textinput.EchoPassword
+		}
+		v.inputs[field] = input
+	}
+	v.selectProfile(0)
+	return v
+}
+
+// Init initializes the view
+func (v *ForeignLinkView) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// profile returns the selected remote profile, nil when there are none
+func (v *ForeignLinkView) profile() *config.Profile {
+	if len(v.profiles) == 0 || v.cfg == nil {
+		return nil
+	}
+	p, err := v.cfg.GetProfile(v.profiles[v.profileIndex])
+	if err != nil {
+		return nil
+	}
+	return p
+}
+
+// selectProfile switches the remote profile, filling in its database and
+// a link name derived from the profile name
+func (v *ForeignLinkView) selectProfile(index int) {
+	if len(v.profiles) == 0 {
+		return
+	}
+	v.profileIndex = index
+	p := v.profile()
+	if p == nil {
+		return
+	}
+	v.inputs[foreignFieldDatabase].SetValue(p.Database)
+	v.inputs[foreignFieldName].SetValue(linkName(v.profiles[index]))
+	v.inputs[foreignFieldPassword].SetValue("")
+	if p.Password == "" {
+		v.inputs[foreignFieldPassword].Placeholder = "Not saved in the profile"
+	} else {
+		v.inputs[foreignFieldPassword].Placeholder = "Saved in the profile"
+	}
+}
+
+// linkName turns a profile name into a usable server/schema identifier
+func linkName(profile string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return '_'
+	}, profile)
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "link_" + name
+	}
+	return name
+}
+
+func (v *ForeignLinkView) focus(field int) {
+	v.focused = field
+	for i := range v.inputs {
+		v.inputs[i].Blur()
+	}
+	if field != foreignFieldProfile {
+		v.inputs[field].Focus()
+	}
+}
+
+// options builds the link options from the form
+func (v *ForeignLinkView) options() (db.ForeignLinkOptions, error) {
+	p := v.profile()
+	if p == nil {
+		return db.ForeignLinkOptions{}, fmt.Errorf("no profiles to link to; save the other server as a profile first")
+	}
+	remote := p.ToConnectionConfig()
+	remote.Database = strings.TrimSpace(v.inputs[foreignFieldDatabase].Value())
+	if password := v.inputs[foreignFieldPassword].Value(); password != "" {
+		remote.Password = password
+	}
+
+	var tables []string
+	for _, t := range strings.Split(v.inputs[foreignFieldTables].Value(), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tables = append(tables, t)
+		}
+	}
+
+	return db.ForeignLinkOptions{
+		Name:         strings.TrimSpace(v.inputs[foreignFieldName].Value()),
+		Remote:       remote,
+		RemoteSchema: strings.TrimSpace(v.inputs[foreignFieldSchema].Value()),
+		Tables:       tables,
+		LocalSchema:  strings.TrimSpace(v.inputs[foreignFieldLocal].Value()),
+	}, nil
+}
+
+func (v *ForeignLinkView) planLink() tea.Cmd {
+	opts, err := v.options()
+	if err != nil {
+		v.err = err
+		return nil
+	}
+	v.mode = foreignModePlanning
+	v.err = nil
+	conn := v.conn
+	return func() tea.Msg {
+		plan, err := conn.PlanForeignLink(opts)
+		return foreignPlannedMsg{plan: plan, err: err}
+	}
+}
+
+func (v *ForeignLinkView) createLink() tea.Cmd {
+	v.mode = foreignModeRunning
+	v.confirm = false
+	conn := v.conn
+	plan := v.plan
+	return func() tea.Msg {
+		return foreignCreatedMsg{err: conn.CreateForeignLink(plan)}
+	}
+}
+
+// Update handles messages
+func (v *ForeignLinkView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		return v, nil
+
+	case foreignPlannedMsg:
+		if msg.err != nil {
+			v.err = msg.err
+			v.mode = foreignModeForm
+			return v, nil
+		}
+		v.plan = msg.plan
+		v.mode = foreignModePreview
+		return v, nil
+
+	case foreignCreatedMsg:
+		v.err = msg.err
+		v.mode = foreignModeDone
+		return v, nil
+
+	case tea.KeyMsg:
+		switch v.mode {
+		case foreignModeForm:
+			return v.updateForm(msg)
+		case foreignModePreview, foreignModeDone:
+			return v.updatePreview(msg)
+		}
+	}
+
+	return v, nil
+}
+
+func (v *ForeignLinkView) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return v, func() tea.Msg {
+			return SwitchViewMsg{View: "databases"}
+		}
+	case "tab", "down":
+		v.focus((v.focused + 1) % foreignFieldCount)
+		return v, nil
+	case "shift+tab", "up":
+		v.focus((v.focused + foreignFieldCount - 1) % foreignFieldCount)
+		return v, nil
+	case "enter":
+		return v, v.planLink()
+	}
+
+	if v.focused == foreignFieldProfile {
+		if len(v.profiles) == 0 {
+			return v, nil
+		}
+		switch msg.String() {
+		case "left", "h":
+			v.selectProfile((v.profileIndex + len(v.profiles) - 1) % len(v.profiles))
+		case "right", "l", " ":
+			v.selectProfile((v.profileIndex + 1) % len(v.profiles))
+		}
+		return v, nil
+	}
+
+	var cmd tea.Cmd
+	v.inputs[v.focused], cmd = v.inputs[v.focused].Update(msg)
+	return v, cmd
+}
+
+func (v *ForeignLinkView) updatePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if v.confirm {
+		if msg.String() == "y" {
+			return v, v.createLink()
+		}
+		v.confirm = false
+		return v, nil
+	}
+
+	switch msg.String() {
+	case "esc", "backspace":
+		return v, func() tea.Msg {
+			return SwitchViewMsg{View: "databases"}
+		}
+	case "q":
+		return v, tea.Quit
+	case "e", "n":
+		v.mode = foreignModeForm
+		v.err = nil
+	case "enter", "a":
+		if v.mode == foreignModePreview {
+			v.confirm = true
+		}
+	}
+	return v, nil
+}
+
+// View renders the view
+func (v *ForeignLinkView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Link Another Server"))
+	b.WriteString("\n\n")
+
+	switch v.mode {
+	case foreignModeForm:
+		b.WriteString(v.viewForm())
+	case foreignModePlanning:
+		b.WriteString(mutedStyle.Render("Planning the link..."))
+	case foreignModeRunning:
+		b.WriteString(mutedStyle.Render("Creating the link..."))
+	case foreignModePreview, foreignModeDone:
+		b.WriteString(v.viewPlan())
+	}
+	return b.String()
+}
+
+func (v *ForeignLinkView) viewForm() string {
+	var b strings.Builder
+
+	label := func(field int, text string) string {
+		if v.focused == field {
+			return focusedStyle.Render(text)
+		}
+		return blurredStyle.Render(text)
+	}
+
+	b.WriteString(label(foreignFieldProfile, "Remote profile: "))
+	if p := v.profile(); p != nil {
+		cc := p.ToConnectionConfig()
+		b.WriteString(headerStyle.Render("< " + v.profiles[v.profileIndex] + " >"))
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  %s %s:%d", cc.Type, cc.Host, cc.Port)))
+	} else {
+		b.WriteString(errorStyle.Render("no saved profiles"))
+	}
+	b.WriteString("\n\n")
+
+	fields := []struct {
+		field int
+		text  string
+	}{
+		{foreignFieldDatabase, "Remote database:"},
+		{foreignFieldSchema, "Remote schema (PostgreSQL remotes):"},
+		{foreignFieldTables, "Tables:"},
+		{foreignFieldName, "Link name (foreign server):"},
+		{foreignFieldLocal, "Local schema/database for the tables:"},
+		{foreignFieldPassword, "Remote password:"},
+	}
+	for _, f := range fields {
+		b.WriteString(label(f.field, f.text))
+		b.WriteString("\n")
+		b.WriteString(v.inputs[f.field].View())
+		b.WriteString("\n\n")
+	}
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Tab: Next field | ←→: Profile | Enter: Preview | Esc: Back"))
+	return b.String()
+}
+
+func (v *ForeignLinkView) viewPlan() string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("Linking %s with %s\n\n",
+		headerStyle.Render(strings.TrimSpace(v.inputs[foreignFieldName].Value())),
+		headerStyle.Render(v.plan.Method)))
+
+	for _, stmt := range v.plan.Redacted {
+		if limit := max(v.width-4, 20); len(stmt) > limit {
+			stmt = stmt[:limit-3] + "..."
+		}
+		b.WriteString(stmt)
+		b.WriteString(";\n")
+	}
+	b.WriteString("\n")
+
+	for _, note := range v.plan.Notes {
+		b.WriteString(mutedStyle.Render("• " + note))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	switch {
+	case v.confirm:
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Run these %d statements on %s? (y/n)", len(v.plan.Statements), v.conn.Config.Host)))
+		b.WriteString("\n\n")
+	case v.err != nil:
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	case v.mode == foreignModeDone:
+		b.WriteString(successStyle.Render("Link created; the remote tables can now be queried here"))
+		b.WriteString("\n\n")
+	}
+
+	help := "e: Edit | Esc: Back | q: Quit"
+	if v.mode == foreignModePreview {
+		help = "Enter: Create link | " + help
+	}
+	b.WriteString(helpStyle.Render(help))
+	return b.String()
+}
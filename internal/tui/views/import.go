@@ -23,13 +23,15 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/blubskye/yandere_sql_manager/internal/buffer"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/blubskye/yandere_sql_manager/internal/webhook"
 	"github.com/charmbracelet/bubbles/filepicker"
-	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
 type importPhase int
@@ -54,24 +56,26 @@ type ImportView struct {
 
 	targetDB   textinput.Model
 	renameDB   textinput.Model
+	analyze    bool // Refresh optimizer statistics afterwards
+	verify     bool // Check the loaded rows against the dump's manifest
+	onError    db.ImportErrorPolicy // Action per error class, one row each after the checkboxes
 	focusedInput int
 
-	progress   progress.Model
-	progressPct float64
+	progress   *progressPanel
 
 	err        error
 	done       bool
+	report     *db.ImportReport // Summary of the finished import
+	message    string           // Where the report was exported
 }
 
 // NewImportView creates a new import view
 func NewImportView(conn *db.Connection, database string, width, height int) *ImportView {
 	fp := filepicker.New()
-	fp.AllowedTypes = []string{".sql", ".SQL"}
+	fp.AllowedTypes = []string{".sql", ".SQL", buffer.SplitManifestSuffix}
 	fp.CurrentDirectory, _ = os.Getwd()
 	fp.Height = height - 10
-	fp.Styles.Selected = fp.Styles.Selected.
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#FF69B4"))
+	fp.Styles.Selected = selectedRowStyle.UnsetBold()
 
 	targetDB := textinput.New()
 	targetDB.Placeholder = "Database name"
@@ -81,11 +85,6 @@ func NewImportView(conn *db.Connection, database string, width, height int) *Imp
 	renameDB := textinput.New()
 	renameDB.Placeholder = "(optional) Rename to..."
 
-	prog := progress.New(
-		progress.WithDefaultGradient(),
-		progress.WithWidth(40),
-	)
-
 	return &ImportView{
 		conn:       conn,
 		database:   database,
@@ -95,8 +94,42 @@ func NewImportView(conn *db.Connection, database string, width, height int) *Imp
 		filepicker: fp,
 		targetDB:   targetDB,
 		renameDB:   renameDB,
-		progress:   prog,
+		onError: db.ImportErrorPolicy{
+			db.ImportErrorDuplicateKey:     db.ImportErrorSkip,
+			db.ImportErrorUnknownCollation: db.ImportErrorRewrite,
+			db.ImportErrorSyntax:           db.ImportErrorAbort,
+			db.ImportErrorTableExists:      db.ImportErrorSkip,
+			db.ImportErrorOther:            db.ImportErrorAbort,
+		},
+	}
+}
+
+// importPolicyField is the first error policy row of the config form
+const importPolicyField = 4
+
+// focusedClass returns the error class of the focused policy row
+func (v *ImportView) focusedClass() (db.ImportErrorClass, bool) {
+	i := v.focusedInput - importPolicyField
+	if i < 0 || i >= len(db.ImportErrorClasses) {
+		return "", false
+	}
+	return db.ImportErrorClasses[i], true
+}
+
+// cycleErrorAction moves the focused class to its next or previous action
+func (v *ImportView) cycleErrorAction(step int) {
+	class, ok := v.focusedClass()
+	if !ok {
+		return
+	}
+	actions := class.Actions()
+	i := 0
+	for j, a := range actions {
+		if a == v.onError[class] {
+			i = j
+		}
 	}
+	v.onError[class] = actions[(i+step+len(actions))%len(actions)]
 }
 
 // Init initializes the view
@@ -108,11 +141,11 @@ func (v *ImportView) Init() tea.Cmd {
 func (v *ImportView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if v.phase == phaseImporting {
+			return v, v.progress.runningKey(msg.String())
+		}
 		switch msg.String() {
 		case "esc":
-			if v.phase == phaseImporting {
-				return v, nil // Can't cancel during import
-			}
 			if v.phase == phaseConfig {
 				v.phase = phaseSelectFile
 				return v, nil
@@ -121,21 +154,48 @@ func (v *ImportView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return SwitchViewMsg{View: "databases"}
 			}
 		case "q", "ctrl+c":
-			if v.phase != phaseImporting {
-				return v, tea.Quit
+			return v, tea.Quit
+		case "ctrl+b":
+			if v.phase == phaseConfig {
+				return v, func() tea.Msg {
+					return SwitchViewMsg{View: "dump", Database: v.targetDB.Value(), Path: v.filePath}
+				}
 			}
 		case "tab":
 			if v.phase == phaseConfig {
-				v.focusedInput = (v.focusedInput + 1) % 2
-				if v.focusedInput == 0 {
+				v.focusedInput = (v.focusedInput + 1) % (importPolicyField + len(db.ImportErrorClasses))
+				v.targetDB.Blur()
+				v.renameDB.Blur()
+				switch v.focusedInput {
+				case 0:
 					v.targetDB.Focus()
-					v.renameDB.Blur()
-				} else {
-					v.targetDB.Blur()
+				case 1:
 					v.renameDB.Focus()
 				}
 			}
 			return v, nil
+		case " ":
+			if v.phase == phaseConfig && v.focusedInput == 2 {
+				v.analyze = !v.analyze
+				return v, nil
+			}
+			if v.phase == phaseConfig && v.focusedInput == 3 {
+				v.verify = !v.verify
+				return v, nil
+			}
+			if _, ok := v.focusedClass(); v.phase == phaseConfig && ok {
+				v.cycleErrorAction(1)
+				return v, nil
+			}
+		case "left", "right":
+			if _, ok := v.focusedClass(); v.phase == phaseConfig && ok {
+				step := 1
+				if msg.String() == "left" {
+					step = -1
+				}
+				v.cycleErrorAction(step)
+				return v, nil
+			}
 		case "enter":
 			if v.phase == phaseConfig {
 				return v, v.startImport()
@@ -145,6 +205,14 @@ func (v *ImportView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return SwitchViewMsg{View: "databases"}
 				}
 			}
+		case "x", "J":
+			if v.phase == phaseDone && v.report != nil {
+				format := db.ImportReportText
+				if msg.String() == "J" {
+					format = db.ImportReportJSON
+				}
+				return v, v.exportReport(format)
+			}
 		}
 
 	case tea.WindowSizeMsg:
@@ -152,18 +220,26 @@ func (v *ImportView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		v.height = msg.Height
 		v.filepicker.Height = msg.Height - 10
 
-	case importProgressMsg:
-		v.progressPct = msg.percent
+	case progressTickMsg:
+		if v.phase == phaseImporting {
+			return v, progressTick()
+		}
 		return v, nil
 
 	case importDoneMsg:
 		v.phase = phaseDone
-		v.done = true
+		v.err = msg.err
+		v.done = msg.err == nil
+		if msg.stats != nil && msg.err == nil {
+			v.report = db.NewImportReport(v.filePath, msg.database, msg.stats)
+		}
 		return v, nil
 
-	case error:
-		v.err = msg
-		v.phase = phaseDone
+	case importReportExportedMsg:
+		v.err = msg.err
+		if msg.err == nil {
+			v.message = fmt.Sprintf("Exported the report to %s", msg.path)
+		}
 		return v, nil
 	}
 
@@ -177,7 +253,7 @@ func (v *ImportView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			v.phase = phaseConfig
 			// Try to infer database name from filename if not set
 			if v.targetDB.Value() == "" {
-				base := filepath.Base(path)
+				base := filepath.Base(buffer.SplitDumpName(path))
 				ext := filepath.Ext(base)
 				v.targetDB.SetValue(base[:len(base)-len(ext)])
 			}
@@ -185,9 +261,10 @@ func (v *ImportView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return v, cmd
 
 	case phaseConfig:
-		if v.focusedInput == 0 {
+		switch v.focusedInput {
+		case 0:
 			v.targetDB, cmd = v.targetDB.Update(msg)
-		} else {
+		case 1:
 			v.renameDB, cmd = v.renameDB.Update(msg)
 		}
 		return v, cmd
@@ -198,37 +275,84 @@ func (v *ImportView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (v *ImportView) startImport() tea.Cmd {
 	v.phase = phaseImporting
-	v.progressPct = 0
+	v.progress = newProgressPanel("Importing "+filepath.Base(v.filePath), progress.Bytes, 0)
+	bar := v.progress
+	ctx, _ := bar.cancellable()
 
 	targetDB := v.targetDB.Value()
 	renameDB := v.renameDB.Value()
+	analyze := v.analyze
+	verify := v.verify
+	policy := make(db.ImportErrorPolicy, len(v.onError))
+	for class, action := range v.onError {
+		policy[class] = action
+	}
 
-	return func() tea.Msg {
+	importSQL := runJob("Import of "+filepath.Base(v.filePath), bar, func() tea.Msg {
 		opts := db.ImportOptions{
-			FilePath: v.filePath,
-			Database: targetDB,
-			CreateDB: true,
-			RenameDB: renameDB,
-			OnProgress: func(bytesRead, totalBytes int64, statementsExecuted int64) {
-				if totalBytes > 0 {
-					// We can't easily send messages from here, progress will be approximate
-				}
+			FilePath:       v.filePath,
+			Database:       targetDB,
+			CreateDB:       true,
+			RenameDB:       renameDB,
+			Analyze:        analyze,
+			VerifyManifest: verify,
+			ErrorPolicy:    policy,
+			Context:        ctx,
+			OnProgress: func(bytesRead, totalBytes int64, statementsExecuted, rowsInserted int64) {
+				bar.SetTotal(totalBytes)
+				bar.Set(bytesRead)
+				bar.SetRows(rowsInserted)
+				bar.SetCurrent(fmt.Sprintf("%d statements", statementsExecuted), 0, 0)
+			},
+			OnAnalyze: func(table string, tableNum, totalTables int) {
+				bar.SetCurrent("analyzing "+table, tableNum, totalTables)
 			},
 		}
 
-		if err := v.conn.ImportSQL(opts); err != nil {
-			return err
+		conn, release := jobConnection(v.conn)
+		defer release()
+
+		stats, err := conn.ImportSQLWithStats(opts)
+		database := targetDB
+		if renameDB != "" {
+			database = renameDB
 		}
+		return importDoneMsg{file: filepath.Base(v.filePath), database: database, stats: stats, elapsed: bar.Snapshot().Elapsed, err: err}
+	})
 
-		return importDoneMsg{}
+	return tea.Batch(importSQL, progressTick())
+}
+
+func (v *ImportView) exportReport(format string) tea.Cmd {
+	report := v.report
+	path := db.DefaultResultExportPath("import-report", format)
+	return func() tea.Msg {
+		return importReportExportedMsg{path: path, err: report.Export(path, format)}
 	}
 }
 
-type importProgressMsg struct {
-	percent float64
+// ownsJob reports whether the view started the job of p
+func (v *ImportView) ownsJob(p *progressPanel) bool {
+	return p == v.progress
+}
+
+type importDoneMsg struct {
+	file     string
+	database string
+	stats    *db.ImportStats
+	elapsed  time.Duration
+	err      error
+}
+
+type importReportExportedMsg struct {
+	path string
+	err  error
 }
 
-type importDoneMsg struct{}
+func (m importDoneMsg) JobResult() JobResult {
+	return JobResult{View: "import", Title: "Import of " + m.file, Elapsed: m.elapsed, Err: m.err,
+		Event: webhook.ImportEvent(m.file, m.database, m.stats, m.elapsed, m.err)}
+}
 
 // View renders the view
 func (v *ImportView) View() string {
@@ -239,7 +363,7 @@ func (v *ImportView) View() string {
 
 	switch v.phase {
 	case phaseSelectFile:
-		b.WriteString("Select a .sql file to import:\n\n")
+		b.WriteString("Select a .sql file, or the .parts.json manifest of a split dump, to import:\n\n")
 		b.WriteString(v.filepicker.View())
 		b.WriteString("\n\n")
 		b.WriteString(helpStyle.Render("Enter: Select | Esc: Cancel"))
@@ -261,23 +385,167 @@ func (v *ImportView) View() string {
 			b.WriteString("\n\n")
 		}
 
-		b.WriteString(helpStyle.Render("Tab: Switch field | Enter: Start Import | Esc: Back"))
+		analyzeCheck := "[ ]"
+		if v.analyze {
+			analyzeCheck = "[x]"
+		}
+		style := blurredStyle
+		if v.focusedInput == 2 {
+			style = focusedStyle
+		}
+		b.WriteString(style.Render(analyzeCheck + " Analyze tables afterwards (refresh optimizer statistics)"))
+		b.WriteString("\n")
+
+		verifyCheck := "[ ]"
+		if v.verify {
+			verifyCheck = "[x]"
+		}
+		style = blurredStyle
+		if v.focusedInput == 3 {
+			style = focusedStyle
+		}
+		b.WriteString(style.Render(verifyCheck + " Verify row counts against the dump's manifest"))
+		b.WriteString("\n\n")
+
+		b.WriteString(headerStyle.Render("On error"))
+		b.WriteString("\n")
+		for i, class := range db.ImportErrorClasses {
+			style := blurredStyle
+			if v.focusedInput == importPolicyField+i {
+				style = focusedStyle
+			}
+			b.WriteString(style.Render(fmt.Sprintf("  %-18s < %s >", class, v.onError[class])))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+
+		b.WriteString(helpStyle.Render("Tab: Switch field | Space: Toggle | ←/→: Change action | Ctrl+B: Browse dump | Enter: Start Import | Esc: Back"))
 
 	case phaseImporting:
-		b.WriteString(fmt.Sprintf("Importing: %s\n\n", filepath.Base(v.filePath)))
-		b.WriteString(v.progress.ViewAs(v.progressPct / 100))
+		b.WriteString(v.progress.View())
 		b.WriteString("\n\n")
-		b.WriteString("Please wait...")
+		b.WriteString(v.progress.runningHelp())
 
 	case phaseDone:
+		if !v.done {
+			b.WriteString(errorStyle.Render(fmt.Sprintf("Import failed: %v", v.err)) + renderErrorHint(v.err))
+			b.WriteString("\n\n")
+			b.WriteString(helpStyle.Render("Enter: Continue | Esc: Back"))
+			break
+		}
+
+		b.WriteString(successStyle.Render("Import completed successfully!"))
+		b.WriteString("\n\n")
+		if v.report != nil {
+			b.WriteString(v.renderSummary())
+		}
 		if v.err != nil {
-			b.WriteString(errorStyle.Render(fmt.Sprintf("Import failed: %v", v.err)))
-		} else {
-			b.WriteString(successStyle.Render("Import completed successfully!"))
+			b.WriteString(renderError(v.err))
+			b.WriteString("\n\n")
+		} else if v.message != "" {
+			b.WriteString(successStyle.Render(v.message))
+			b.WriteString("\n\n")
 		}
+		b.WriteString(helpStyle.Render("x: Export report | J: Export JSON | Enter: Continue | Esc: Back"))
+	}
+
+	return b.String()
+}
+
+// renderSummary shows what the import did, by statement type and phase
+func (v *ImportView) renderSummary() string {
+	var b strings.Builder
+	stats := v.report.Stats
+
+	if stats.NativeTool {
+		b.WriteString(mutedStyle.Render("Loaded with the native client tools; statement details aren't available"))
 		b.WriteString("\n\n")
-		b.WriteString(helpStyle.Render("Enter: Continue | Esc: Back"))
+	} else {
+		b.WriteString(headerStyle.Render("Statements"))
+		b.WriteString("\n")
+		counts := []struct {
+			label string
+			n     int64
+		}{
+			{"CREATE", stats.Statements.Create},
+			{"INSERT", stats.Statements.Insert},
+			{"ALTER", stats.Statements.Alter},
+			{"Other", stats.Statements.Other},
+		}
+		for _, c := range counts {
+			b.WriteString(fmt.Sprintf("  %-8s %d\n", c.label, c.n))
+		}
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  %d read, %d executed", stats.Statements.Total(), stats.StatementsExecuted)))
+		b.WriteString("\n\n")
+
+		b.WriteString(fmt.Sprintf("Rows inserted: ~%d (estimated from VALUES)\n", stats.RowsInserted))
+		if stats.LargeObjects > 0 {
+			b.WriteString(fmt.Sprintf("Large objects: %d\n", stats.LargeObjects))
+		}
+		created := fmt.Sprintf("Tables created: %d", len(stats.TablesCreated))
+		if len(stats.TablesCreated) > 0 {
+			const maxShown = 5
+			names := stats.TablesCreated[:min(len(stats.TablesCreated), maxShown)]
+			created += " - " + strings.Join(names, ", ")
+			if len(stats.TablesCreated) > maxShown {
+				created += fmt.Sprintf(", ... +%d", len(stats.TablesCreated)-maxShown)
+			}
+		}
+		b.WriteString(truncateRunes(created, max(v.width-4, 40)))
+		b.WriteString("\n")
+	}
+	if stats.TablesAnalyzed > 0 {
+		b.WriteString(fmt.Sprintf("Tables analyzed: %d\n", stats.TablesAnalyzed))
+	}
+	if stats.Manifest != nil {
+		bad := stats.Manifest.Mismatches()
+		if len(bad) == 0 {
+			b.WriteString(successStyle.Render(fmt.Sprintf("Manifest: all %d tables hold the rows the dump wrote", len(stats.Manifest.Tables))))
+			b.WriteString("\n")
+		} else {
+			b.WriteString(errorStyle.Render(fmt.Sprintf("Manifest: %d of %d tables don't match", len(bad), len(stats.Manifest.Tables))))
+			b.WriteString("\n")
+			const maxShown = 5
+			for _, t := range bad[:min(len(bad), maxShown)] {
+				b.WriteString(fmt.Sprintf("  %s\n", t))
+			}
+		}
+	}
+	b.WriteString("\n")
+
+	if len(stats.ErrorsByClass) > 0 {
+		b.WriteString(headerStyle.Render("Skipped or rewritten"))
+		b.WriteString("\n")
+		for _, class := range db.ImportErrorClasses {
+			if n := stats.ErrorsByClass[class]; n > 0 {
+				b.WriteString(fmt.Sprintf("  %-18s %d (%s)\n", class, n, v.onError[class]))
+			}
+		}
+		b.WriteString("\n")
 	}
 
+	b.WriteString(headerStyle.Render(fmt.Sprintf("Elapsed %s", stats.Duration.Round(time.Millisecond))))
+	b.WriteString("\n")
+	for _, p := range stats.Phases {
+		b.WriteString(fmt.Sprintf("  %-8s %s\n", p.Name, p.Duration.Round(time.Millisecond)))
+	}
+	b.WriteString("\n")
+
+	if stats.WarningCount == 0 {
+		b.WriteString(mutedStyle.Render("No warnings"))
+		b.WriteString("\n\n")
+		return b.String()
+	}
+	b.WriteString(errorStyle.Render(fmt.Sprintf("%d warning(s)", stats.WarningCount)))
+	b.WriteString("\n")
+	const maxWarnings = 5
+	for _, warning := range stats.Warnings[:min(len(stats.Warnings), maxWarnings)] {
+		b.WriteString("  " + truncateRunes(warning, max(v.width-6, 40)) + "\n")
+	}
+	if stats.WarningCount > maxWarnings {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  ... and %d more", stats.WarningCount-maxWarnings)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 	return b.String()
 }
@@ -0,0 +1,480 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type indexesMode int
+
+const (
+	indexesModeList indexesMode = iota
+	indexesModeCreate
+	indexesModeConfirmDrop
+)
+
+// IndexesView lists, creates and drops the indexes of a table
+type IndexesView struct {
+	conn     *db.Connection
+	database string
+	table    string
+	indexes  []db.Index
+	cursor   int
+	mode     indexesMode
+	form     *indexForm
+	loading  bool
+	status   string
+	err      error
+	width    int
+	height   int
+}
+
+// indexForm creates an index
+type indexForm struct {
+	name     textinput.Model
+	columns  []string
+	selected []string // Picked columns, in index order
+	cursor   int
+	unique   bool
+	focused  int // 0 = name, 1 = columns, 2 = unique
+	creating bool
+	err      error
+}
+
+type indexesLoadedMsg struct {
+	indexes []db.Index
+	err     error
+}
+
+type indexColumnsLoadedMsg struct {
+	columns []string
+	err     error
+}
+
+type indexChangedMsg struct {
+	status string
+	err    error
+}
+
+// NewIndexesView creates a new indexes view
+func NewIndexesView(conn *db.Connection, database, table string, width, height int) *IndexesView {
+	return &IndexesView{
+		conn:     conn,
+		database: database,
+		table:    table,
+		loading:  true,
+		width:    width,
+		height:   height,
+	}
+}
+
+// Init initializes the view
+func (v *IndexesView) Init() tea.Cmd {
+	return v.loadIndexes
+}
+
+func (v *IndexesView) loadIndexes() tea.Msg {
+	if err := v.conn.UseDatabase(v.database); err != nil {
+		return indexesLoadedMsg{err: err}
+	}
+	indexes, err := v.conn.ListIndexes(v.table)
+	return indexesLoadedMsg{indexes: indexes, err: err}
+}
+
+func (v *IndexesView) loadColumns() tea.Msg {
+	cols, err := v.conn.DescribeTable(v.table)
+	if err != nil {
+		return indexColumnsLoadedMsg{err: err}
+	}
+	names := make([]string, len(cols))
+	for i, col := range cols {
+		names[i] = col.Field
+	}
+	return indexColumnsLoadedMsg{columns: names}
+}
+
+func (v *IndexesView) createIndex(name string, columns []string, unique bool) tea.Cmd {
+	return func() tea.Msg {
+		if name == "" {
+			name = db.DefaultIndexName(v.table, columns, unique)
+		}
+		if err := v.conn.CreateIndex(v.table, name, columns, unique); err != nil {
+			return indexChangedMsg{err: err}
+		}
+		return indexChangedMsg{status: fmt.Sprintf("Created index %s", name)}
+	}
+}
+
+func (v *IndexesView) dropIndex(name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := v.conn.DropIndex(v.table, name); err != nil {
+			return indexChangedMsg{err: err}
+		}
+		return indexChangedMsg{status: fmt.Sprintf("Dropped index %s", name)}
+	}
+}
+
+// Update handles messages
+func (v *IndexesView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		return v, nil
+
+	case indexesLoadedMsg:
+		v.loading = false
+		v.err = msg.err
+		v.indexes = msg.indexes
+		if v.cursor >= len(v.indexes) {
+			v.cursor = max(len(v.indexes)-1, 0)
+		}
+		return v, nil
+
+	case indexColumnsLoadedMsg:
+		if v.form != nil {
+			v.form.columns = msg.columns
+			v.form.err = msg.err
+		}
+		return v, nil
+
+	case indexChangedMsg:
+		if msg.err != nil {
+			if v.mode == indexesModeCreate && v.form != nil {
+				v.form.creating = false
+				v.form.err = msg.err
+				return v, nil
+			}
+			v.mode = indexesModeList
+			v.err = msg.err
+			return v, nil
+		}
+		v.mode = indexesModeList
+		v.form = nil
+		v.err = nil
+		v.status = msg.status
+		v.loading = true
+		return v, v.loadIndexes
+	}
+
+	switch v.mode {
+	case indexesModeCreate:
+		return v.updateCreateForm(msg)
+	case indexesModeConfirmDrop:
+		return v.updateConfirmDrop(msg)
+	}
+	return v.updateList(msg)
+}
+
+func (v *IndexesView) updateList(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return v, nil
+	}
+
+	switch key.String() {
+	case "esc", "backspace":
+		return v, func() tea.Msg {
+			return SwitchViewMsg{View: "tables", Database: v.database}
+		}
+
+	case "q":
+		return v, tea.Quit
+
+	case "up", "k":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+
+	case "down", "j":
+		if v.cursor < len(v.indexes)-1 {
+			v.cursor++
+		}
+
+	case "c", "n":
+		name := textinput.New()
+		name.Placeholder = "generated from the columns"
+		name.CharLimit = 63
+		name.Focus()
+		v.form = &indexForm{name: name}
+		v.mode = indexesModeCreate
+		v.status = ""
+		return v, tea.Batch(textinput.Blink, v.loadColumns)
+
+	case "d", "x":
+		if v.cursor < len(v.indexes) {
+			if v.indexes[v.cursor].Primary {
+				v.err = fmt.Errorf("the primary key can't be dropped here, change it in the table designer")
+				return v, nil
+			}
+			v.err = nil
+			v.mode = indexesModeConfirmDrop
+		}
+
+	case "r":
+		v.loading = true
+		v.status = ""
+		return v, v.loadIndexes
+	}
+	return v, nil
+}
+
+func (v *IndexesView) updateConfirmDrop(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return v, nil
+	}
+
+	switch key.String() {
+	case "y", "Y":
+		return v, v.dropIndex(v.indexes[v.cursor].Name)
+	case "n", "N", "esc":
+		v.mode = indexesModeList
+	}
+	return v, nil
+}
+
+func (v *IndexesView) updateCreateForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	form := v.form
+
+	if key, ok := msg.(tea.KeyMsg); ok && !form.creating {
+		switch key.String() {
+		case "esc":
+			v.form = nil
+			v.mode = indexesModeList
+			return v, nil
+
+		case "tab", "shift+tab":
+			if key.String() == "tab" {
+				form.focused = (form.focused + 1) % 3
+			} else {
+				form.focused = (form.focused + 2) % 3
+			}
+			if form.focused == 0 {
+				form.name.Focus()
+			} else {
+				form.name.Blur()
+			}
+			return v, nil
+
+		case "up", "k":
+			if form.focused == 1 && form.cursor > 0 {
+				form.cursor--
+				return v, nil
+			}
+
+		case "down", "j":
+			if form.focused == 1 && form.cursor < len(form.columns)-1 {
+				form.cursor++
+				return v, nil
+			}
+
+		case " ":
+			switch form.focused {
+			case 1:
+				if form.cursor < len(form.columns) {
+					form.toggle(form.columns[form.cursor])
+				}
+				return v, nil
+			case 2:
+				form.unique = !form.unique
+				return v, nil
+			}
+
+		case "enter":
+			if len(form.selected) == 0 {
+				form.err = fmt.Errorf("pick at least one column")
+				return v, nil
+			}
+			form.err = nil
+			form.creating = true
+			return v, v.createIndex(strings.TrimSpace(form.name.Value()), form.selected, form.unique)
+		}
+	}
+
+	if form.focused == 0 {
+		var cmd tea.Cmd
+		form.name, cmd = form.name.Update(msg)
+		return v, cmd
+	}
+	return v, nil
+}
+
+// toggle adds a column to the end of the index, or removes it
+func (f *indexForm) toggle(column string) {
+	for i, col := range f.selected {
+		if col == column {
+			f.selected = append(f.selected[:i], f.selected[i+1:]...)
+			return
+		}
+	}
+	f.selected = append(f.selected, column)
+}
+
+// position returns the 1-based position of a column in the index, or 0
+func (f *indexForm) position(column string) int {
+	for i, col := range f.selected {
+		if col == column {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// View renders the view
+func (v *IndexesView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Indexes on %s", v.table)))
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("  (%s)", v.database)))
+	b.WriteString("\n\n")
+
+	if v.mode == indexesModeCreate {
+		b.WriteString(v.viewCreateForm())
+		return b.String()
+	}
+
+	if v.loading {
+		b.WriteString("Loading indexes...\n")
+		return b.String()
+	}
+
+	b.WriteString(headerStyle.Render(fmt.Sprintf("  %-32s %-40s %-8s %-10s %10s", "Name", "Columns", "Unique", "Type", "Size")))
+	b.WriteString("\n")
+	for i, idx := range v.indexes {
+		unique := "no"
+		if idx.Primary {
+			unique = "primary"
+		} else if idx.Unique {
+			unique = "yes"
+		}
+		size := "-"
+		if idx.Size > 0 {
+			size = db.FormatSize(idx.Size)
+		}
+
+		line := fmt.Sprintf("%-32s %-40s %-8s %-10s %10s", idx.Name, strings.Join(idx.Columns, ", "), unique, idx.Type, size)
+		if i == v.cursor {
+			b.WriteString(selectedStyle.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	if len(v.indexes) == 0 {
+		b.WriteString(mutedStyle.Render("  No indexes - press c to create one"))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	} else if v.status != "" {
+		b.WriteString(successStyle.Render(v.status))
+		b.WriteString("\n\n")
+	}
+
+	if v.mode == indexesModeConfirmDrop {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Drop index %s? (y/n)", v.indexes[v.cursor].Name)))
+	} else {
+		b.WriteString(helpStyle.Render("c: Create | d: Drop | r: Refresh | Esc: Back | q: Quit"))
+	}
+
+	return b.String()
+}
+
+func (v *IndexesView) viewCreateForm() string {
+	var b strings.Builder
+	form := v.form
+
+	label := func(field int, text string) string {
+		if form.focused == field {
+			return focusedStyle.Render(text)
+		}
+		return blurredStyle.Render(text)
+	}
+
+	b.WriteString(headerStyle.Render("Create Index"))
+	b.WriteString("\n\n")
+
+	b.WriteString(label(0, "Name:"))
+	b.WriteString("\n")
+	b.WriteString(form.name.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(label(1, "Columns:"))
+	if form.focused == 1 {
+		b.WriteString(mutedStyle.Render("  Space picks columns in index order"))
+	}
+	b.WriteString("\n")
+	if form.columns == nil && form.err == nil {
+		b.WriteString(mutedStyle.Render("  Loading columns..."))
+		b.WriteString("\n")
+	}
+	for i, col := range form.columns {
+		box := "[ ]"
+		if pos := form.position(col); pos > 0 {
+			box = fmt.Sprintf("[%d]", pos)
+		}
+		line := fmt.Sprintf("%s %s", box, col)
+		if form.focused == 1 && i == form.cursor {
+			b.WriteString(selectedStyle.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	box := "[ ]"
+	if form.unique {
+		box = "[x]"
+	}
+	b.WriteString(label(2, box+" Unique"))
+	b.WriteString("\n\n")
+
+	if len(form.selected) > 0 {
+		name := strings.TrimSpace(form.name.Value())
+		if name == "" {
+			name = db.DefaultIndexName(v.table, form.selected, form.unique)
+		}
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("Will create %s on (%s)", name, strings.Join(form.selected, ", "))))
+		b.WriteString("\n\n")
+	}
+
+	if form.err != nil {
+		b.WriteString(renderError(form.err))
+		b.WriteString("\n\n")
+	}
+
+	if form.creating {
+		b.WriteString("Creating index...")
+	} else {
+		b.WriteString(helpStyle.Render("Enter: Create | Tab: Next | Space: Toggle | Esc: Cancel"))
+	}
+
+	return b.String()
+}
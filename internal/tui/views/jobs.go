@@ -0,0 +1,470 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// jobState is where a tracked job is in its life
+type jobState int
+
+const (
+	jobRunning jobState = iota
+	jobSucceeded
+	jobFailed
+	jobCancelled
+)
+
+// maxFinishedJobs is how many ended jobs stay listed in the jobs view
+const maxFinishedJobs = 20
+
+// jobsRefreshInterval is how often the jobs view redraws
+const jobsRefreshInterval = 500 * time.Millisecond
+
+// job is a long operation started from a view. It stays listed after it
+// ends, so its result and log can be read after leaving its view.
+type job struct {
+	id    int
+	title string
+	panel *progressPanel
+
+	mu      sync.Mutex
+	state   jobState
+	elapsed time.Duration // Fixed when the job ends
+	err     error
+}
+
+// jobs are the jobs of every tab, oldest first
+var jobs struct {
+	sync.Mutex
+	seq  int
+	list []*job
+}
+
+// runJob runs the work of a long job as a command, listed in the jobs view
+// and the status bar while it runs. The message the work ends with only
+// reaches the view that started the job, see DeliverJob.
+func runJob(title string, p *progressPanel, work func() tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		j := &job{title: title, panel: p}
+		jobs.Lock()
+		jobs.seq++
+		j.id = jobs.seq
+		jobs.list = append(jobs.list, j)
+		jobs.Unlock()
+		p.logf("Started %s", title)
+
+		msg := work()
+		done, ok := msg.(JobDoneMsg)
+		if !ok {
+			j.finish(nil)
+			return msg
+		}
+		j.finish(done.JobResult().Err)
+		return jobEndedMsg{JobDoneMsg: done, job: j}
+	}
+}
+
+// finish records how the job ended and drops the oldest ended jobs
+func (j *job) finish(err error) {
+	j.mu.Lock()
+	j.elapsed = j.panel.Snapshot().Elapsed
+	j.err = err
+	switch {
+	case err == nil:
+		j.state = jobSucceeded
+		j.panel.logf("Finished in %s", progress.FormatDuration(j.elapsed))
+	case j.panel.cancelled.Load():
+		j.state = jobCancelled
+		j.panel.logf("Cancelled: %v", err)
+	default:
+		j.state = jobFailed
+		j.panel.logf("Failed: %v", err)
+	}
+	j.mu.Unlock()
+
+	jobs.Lock()
+	defer jobs.Unlock()
+	finished := 0
+	for i := len(jobs.list) - 1; i >= 0; i-- {
+		if jobs.list[i].status() == jobRunning {
+			continue
+		}
+		if finished++; finished > maxFinishedJobs {
+			jobs.list = append(jobs.list[:i], jobs.list[i+1:]...)
+		}
+	}
+}
+
+// status returns the job's state
+func (j *job) status() jobState {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.state
+}
+
+// result returns the job's state, elapsed time and error
+func (j *job) result() (jobState, time.Duration, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.state == jobRunning {
+		return j.state, j.panel.Snapshot().Elapsed, nil
+	}
+	return j.state, j.elapsed, j.err
+}
+
+// listJobs returns the tracked jobs, newest first
+func listJobs() []*job {
+	jobs.Lock()
+	defer jobs.Unlock()
+	list := make([]*job, len(jobs.list))
+	for i, j := range jobs.list {
+		list[len(list)-1-i] = j
+	}
+	return list
+}
+
+// clearFinishedJobs forgets the jobs that ended
+func clearFinishedJobs() {
+	jobs.Lock()
+	defer jobs.Unlock()
+	running := jobs.list[:0]
+	for _, j := range jobs.list {
+		if j.status() == jobRunning {
+			running = append(running, j)
+		}
+	}
+	jobs.list = running
+}
+
+// RunningJobs returns the progress of the jobs running now, oldest first
+func RunningJobs() []progress.Snapshot {
+	jobs.Lock()
+	defer jobs.Unlock()
+	var snapshots []progress.Snapshot
+	for _, j := range jobs.list {
+		if j.status() == jobRunning {
+			snapshots = append(snapshots, j.panel.Snapshot())
+		}
+	}
+	return snapshots
+}
+
+// jobEndedMsg is the message a tracked job ended with, and the job
+type jobEndedMsg struct {
+	JobDoneMsg
+	job *job
+}
+
+// jobOwner is implemented by views whose jobs can run in the background,
+// so a job's end isn't mistaken for that of another view of the same kind
+type jobOwner interface {
+	ownsJob(p *progressPanel) bool
+}
+
+// OwnsJob reports whether view started the job msg ended. Views that can't
+// send their jobs to the background see the end of every job.
+func OwnsJob(view tea.Model, msg JobDoneMsg) bool {
+	ended, ok := msg.(jobEndedMsg)
+	if !ok {
+		return true
+	}
+	owner, ok := view.(jobOwner)
+	return !ok || owner.ownsJob(ended.job.panel)
+}
+
+// DeliverJob hands the message a job ended with to view, unless the job
+// belongs to another view
+func DeliverJob(view tea.Model, msg JobDoneMsg) (tea.Model, tea.Cmd) {
+	if !OwnsJob(view, msg) {
+		return view, nil
+	}
+	if ended, ok := msg.(jobEndedMsg); ok {
+		return view.Update(ended.JobDoneMsg)
+	}
+	return view.Update(msg)
+}
+
+// runningKey handles a key pressed in a view whose job is running: Esc
+// leaves the job running in the background and x cancels it
+func (p *progressPanel) runningKey(key string) tea.Cmd {
+	switch key {
+	case "esc":
+		return func() tea.Msg {
+			return SwitchViewMsg{View: "databases"}
+		}
+	case "x":
+		if p.cancel != nil {
+			p.cancel()
+		}
+	}
+	return nil
+}
+
+// runningHelp is the help line under the progress of a running job
+func (p *progressPanel) runningHelp() string {
+	switch {
+	case p.cancelled.Load():
+		return mutedStyle.Render("Cancelling...")
+	case p.cancel == nil:
+		return helpStyle.Render("Esc: Run in background (Alt+J: Jobs)")
+	}
+	return helpStyle.Render("Esc: Run in background (Alt+J: Jobs) | x: Cancel")
+}
+
+// jobsViewSeq tells apart the ticks of successive jobs views
+var jobsViewSeq int
+
+// JobsView lists the running and recently ended jobs of every tab, with the
+// progress and log of the selected one
+type JobsView struct {
+	width    int
+	height   int
+	id       int
+	selected int // Job ID under the cursor, 0 = the newest
+	confirm  bool
+	message  string
+}
+
+type jobsTickMsg struct {
+	id int
+}
+
+// NewJobsView creates a new jobs view
+func NewJobsView(width, height int) *JobsView {
+	jobsViewSeq++
+	return &JobsView{width: width, height: height, id: jobsViewSeq}
+}
+
+// Init initializes the view
+func (v *JobsView) Init() tea.Cmd {
+	return v.tick()
+}
+
+func (v *JobsView) tick() tea.Cmd {
+	id := v.id
+	return tea.Tick(jobsRefreshInterval, func(time.Time) tea.Msg {
+		return jobsTickMsg{id: id}
+	})
+}
+
+// Update handles messages
+func (v *JobsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+
+	case jobsTickMsg:
+		if msg.id == v.id {
+			return v, v.tick()
+		}
+
+	case tea.KeyMsg:
+		return v.updateKeys(msg)
+	}
+	return v, nil
+}
+
+func (v *JobsView) updateKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	list := listJobs()
+	cursor := v.cursor(list)
+
+	if v.confirm {
+		v.confirm = false
+		if msg.String() == "y" && cursor >= 0 {
+			if p := list[cursor].panel; p.cancel != nil {
+				p.cancel()
+				v.message = "Cancelling " + list[cursor].title
+			}
+		}
+		return v, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if cursor > 0 {
+			v.selected = list[cursor-1].id
+		}
+	case "down", "j":
+		if cursor >= 0 && cursor < len(list)-1 {
+			v.selected = list[cursor+1].id
+		}
+	case "x":
+		if cursor < 0 {
+			return v, nil
+		}
+		j := list[cursor]
+		v.message = ""
+		switch {
+		case j.status() != jobRunning:
+			v.message = j.title + " already ended"
+		case j.panel.cancel == nil:
+			v.message = j.title + " can't be cancelled"
+		case !j.panel.cancelled.Load():
+			v.confirm = true
+		}
+	case "d":
+		clearFinishedJobs()
+		v.message = "Cleared the ended jobs"
+	case "esc", "backspace":
+		return v, func() tea.Msg {
+			return SwitchViewMsg{View: "databases"}
+		}
+	}
+	return v, nil
+}
+
+// cursor returns the index of the selected job in list, -1 when it's empty
+func (v *JobsView) cursor(list []*job) int {
+	if len(list) == 0 {
+		return -1
+	}
+	for i, j := range list {
+		if j.id == v.selected {
+			return i
+		}
+	}
+	return 0
+}
+
+// View renders the view
+func (v *JobsView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Jobs"))
+	b.WriteString("\n\n")
+
+	list := listJobs()
+	cursor := v.cursor(list)
+	if len(list) == 0 {
+		b.WriteString(mutedStyle.Render("No jobs yet. Exports, imports, backups and clones are listed here while they run and after they end."))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Esc: Back"))
+		return b.String()
+	}
+
+	b.WriteString(headerStyle.Render(fmt.Sprintf("  %-10s %-32s %-22s %9s %9s", "State", "Job", "Progress", "Elapsed", "ETA")))
+	b.WriteString("\n")
+
+	// Keep the cursor on screen
+	visible := max(v.height/3, 3)
+	start := 0
+	if cursor >= visible {
+		start = cursor - visible + 1
+	}
+	end := min(start+visible, len(list))
+
+	for i := start; i < end; i++ {
+		j := list[i]
+		state, elapsed, _ := j.result()
+		s := j.panel.Snapshot()
+
+		done, eta := "", ""
+		if state == jobRunning {
+			done = s.FormatDone()
+			if s.Fraction >= 0 {
+				done = fmt.Sprintf("%3.0f%% %s", s.Fraction*100, done)
+			}
+			if s.ETA > 0 {
+				eta = progress.FormatDuration(s.ETA)
+			}
+		}
+		line := fmt.Sprintf("%-10s %-32s %-22s %9s %9s", jobStateLabel(j, state),
+			truncateRunes(j.title, 32), truncateRunes(done, 22), progress.FormatDuration(elapsed), eta)
+
+		switch {
+		case i == cursor:
+			b.WriteString(selectedStyle.Render("> " + line))
+		case state == jobFailed:
+			b.WriteString(errorStyle.Render("  " + line))
+		case state == jobSucceeded:
+			b.WriteString(successStyle.Render("  " + line))
+		default:
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// The selected job in full: its progress or result, then its log
+	j := list[cursor]
+	state, elapsed, err := j.result()
+	logLines := v.height - (end - start) - 16
+	switch state {
+	case jobRunning:
+		b.WriteString(j.panel.View())
+		logLines -= 6
+	case jobSucceeded:
+		b.WriteString(successStyle.Render(fmt.Sprintf("%s finished in %s", j.title, progress.FormatDuration(elapsed))))
+	case jobCancelled:
+		b.WriteString(warningStyle.Render(fmt.Sprintf("%s was cancelled after %s", j.title, progress.FormatDuration(elapsed))))
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render(err.Error()))
+	case jobFailed:
+		b.WriteString(renderError(err))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(subtitleStyle.Render("Log"))
+	b.WriteString("\n")
+	log := j.panel.logLines()
+	if logLines = max(logLines, 3); len(log) > logLines {
+		log = log[len(log)-logLines:]
+	}
+	for _, line := range log {
+		b.WriteString(mutedStyle.Render(truncateRunes(line, max(v.width-4, 40))))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if v.confirm {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Cancel %s? (y/n)", j.title)))
+		b.WriteString("\n\n")
+	} else if v.message != "" {
+		b.WriteString(successStyle.Render(v.message))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("↑↓: Navigate | x: Cancel job | d: Clear ended jobs | Esc: Back"))
+	return b.String()
+}
+
+// jobStateLabel names a job's state for the list
+func jobStateLabel(j *job, state jobState) string {
+	switch state {
+	case jobSucceeded:
+		return "done"
+	case jobFailed:
+		return "failed"
+	case jobCancelled:
+		return "cancelled"
+	}
+	if j.panel.cancelled.Load() {
+		return "cancelling"
+	}
+	return "running"
+}
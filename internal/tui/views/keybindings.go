@@ -25,7 +25,6 @@ import (
 	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
 // KeybindingsView allows customizing keybindings
@@ -84,12 +83,16 @@ func getActionsForView(viewName string) []config.KeyAction {
 		return actions
 	case "browser":
 		actions := allActions["Navigation"]
-		actions = append(actions, config.ActionEdit, config.ActionDelete)
+		actions = append(actions, config.ActionEdit, config.ActionCreate, config.ActionDelete)
+		actions = append(actions, allActions["Data"]...)
+		actions = append(actions, config.ActionClearFilter)
 		return actions
 	case "query":
 		return []config.KeyAction{
 			config.ActionSave,
 			config.ActionCancel,
+			config.ActionSnippets,
+			config.ActionHelp,
 		}
 	case "settings":
 		return []config.KeyAction{
@@ -243,15 +246,11 @@ func (v *KeybindingsView) View() string {
 	for i, view := range v.views {
 		name := strings.Title(view)
 		if i == v.currentView {
-			tabs = append(tabs, lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FFFFFF")).
-				Background(lipgloss.Color("#FF69B4")).
+			tabs = append(tabs, selectedRowStyle.
 				Padding(0, 1).
-				Bold(true).
 				Render(name))
 		} else {
-			tabs = append(tabs, lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#888888")).
+			tabs = append(tabs, mutedStyle.
 				Padding(0, 1).
 				Render(name))
 		}
@@ -261,7 +260,7 @@ func (v *KeybindingsView) View() string {
 
 	// Error message
 	if v.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", v.err)))
+		b.WriteString(renderError(v.err))
 		b.WriteString("\n\n")
 		v.err = nil
 	}
@@ -269,10 +268,7 @@ func (v *KeybindingsView) View() string {
 	// Status message
 	if v.statusMsg != "" {
 		if v.waitingForKey {
-			b.WriteString(lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FFD700")).
-				Bold(true).
-				Render(v.statusMsg))
+			b.WriteString(highlightStyle.Render(v.statusMsg))
 		} else {
 			b.WriteString(successStyle.Render(v.statusMsg))
 		}
@@ -328,18 +324,10 @@ func (v *KeybindingsView) View() string {
 			if i == v.cursor {
 				if v.waitingForKey {
 					// Waiting for key input
-					b.WriteString(lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#FFFFFF")).
-						Background(lipgloss.Color("#FFD700")).
-						Bold(true).
-						Render(fmt.Sprintf(" %-10s %-30s (press any key...)", keyDisplay, desc)))
+					b.WriteString(captureRowStyle.Render(fmt.Sprintf(" %-10s %-30s (press any key...)", keyDisplay, desc)))
 				} else {
 					// Selected row
-					rowStyle := lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#FFFFFF")).
-						Background(lipgloss.Color("#FF69B4")).
-						Bold(true)
-					b.WriteString(rowStyle.Render(fmt.Sprintf(" %-10s %-30s %s ", keyDisplay, desc, action)))
+					b.WriteString(selectedRowStyle.Render(fmt.Sprintf(" %-10s %-30s %s ", keyDisplay, desc, action)))
 				}
 			} else {
 				b.WriteString(fmt.Sprintf(" %-10s %-30s %s", keyDisplay, desc, mutedStyle.Render(string(action))))
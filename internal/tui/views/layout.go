@@ -0,0 +1,57 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import "github.com/charmbracelet/lipgloss"
+
+// compactLayout stacks the panels views show side by side, for terminals
+// too narrow for two columns. The app sets it from the layout setting and
+// the terminal width.
+var compactLayout bool
+
+// SetCompact switches the views between side-by-side and stacked panels
+func SetCompact(compact bool) {
+	compactLayout = compact
+}
+
+// panelWidths splits the view width into two panel columns. Stacked panels
+// each get the full width.
+func panelWidths(width int) (left, right int) {
+	if compactLayout {
+		return width - 4, width - 4
+	}
+	half := (width - 6) / 2
+	return half, half
+}
+
+// joinPanels puts panels next to each other, or under each other in the
+// compact layout
+func joinPanels(panels ...string) string {
+	if compactLayout {
+		return lipgloss.JoinVertical(lipgloss.Left, panels...)
+	}
+	spaced := make([]string, 0, 2*len(panels))
+	for i, p := range panels {
+		if i > 0 {
+			spaced = append(spaced, "  ")
+		}
+		spaced = append(spaced, p)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, spaced...)
+}
@@ -0,0 +1,341 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// locksRefreshInterval is how often the lock monitor polls while auto-refresh is on
+const locksRefreshInterval = 2 * time.Second
+
+// locksViewSeq tells apart the ticks of successive lock monitors, so a
+// reopened view doesn't inherit the previous one's refresh loop
+var locksViewSeq int
+
+// LocksView shows blocking chains as blocker→blocked trees
+type LocksView struct {
+	conn        *db.Connection
+	width       int
+	height      int
+	id          int
+	rows        []lockRow
+	cursor      int
+	loading     bool
+	autoRefresh bool
+	lastUpdate  time.Time
+	err         error
+	message     string
+	confirm     bool // Waiting for y before killing the selected session
+}
+
+// lockRow is a tree node flattened for display
+type lockRow struct {
+	node   *db.BlockingNode
+	prefix string
+}
+
+type locksLoadedMsg struct {
+	trees []*db.BlockingNode
+	err   error
+}
+
+type locksKilledMsg struct {
+	id        int64
+	queryOnly bool
+	err       error
+}
+
+type locksTickMsg struct {
+	id int
+}
+
+// NewLocksView creates a new lock monitor view
+func NewLocksView(conn *db.Connection, width, height int) *LocksView {
+	locksViewSeq++
+	return &LocksView{
+		conn:        conn,
+		width:       width,
+		height:      height,
+		id:          locksViewSeq,
+		loading:     true,
+		autoRefresh: true,
+	}
+}
+
+// Init initializes the view
+func (v *LocksView) Init() tea.Cmd {
+	return v.load
+}
+
+func (v *LocksView) load() tea.Msg {
+	waits, err := v.conn.LockWaits()
+	if err != nil {
+		return locksLoadedMsg{err: err}
+	}
+	return locksLoadedMsg{trees: db.BlockingTrees(waits)}
+}
+
+func (v *LocksView) tick() tea.Cmd {
+	id := v.id
+	return tea.Tick(locksRefreshInterval, func(t time.Time) tea.Msg {
+		return locksTickMsg{id: id}
+	})
+}
+
+func (v *LocksView) kill(id int64, queryOnly bool) tea.Cmd {
+	return func() tea.Msg {
+		return locksKilledMsg{id: id, queryOnly: queryOnly, err: v.conn.KillSession(id, queryOnly)}
+	}
+}
+
+// Update handles messages
+func (v *LocksView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+
+	case locksLoadedMsg:
+		v.loading = false
+		v.err = msg.err
+		if msg.err == nil {
+			v.setTrees(msg.trees)
+			v.lastUpdate = time.Now()
+		}
+		if v.autoRefresh {
+			return v, v.tick()
+		}
+
+	case locksTickMsg:
+		if msg.id == v.id && v.autoRefresh && !v.loading {
+			v.loading = true
+			return v, v.load
+		}
+
+	case locksKilledMsg:
+		if msg.err != nil {
+			v.err = msg.err
+			return v, nil
+		}
+		if msg.queryOnly {
+			v.message = fmt.Sprintf("Cancelled the query of session %d", msg.id)
+		} else {
+			v.message = fmt.Sprintf("Killed session %d", msg.id)
+		}
+		v.loading = true
+		return v, v.load
+
+	case tea.KeyMsg:
+		return v.updateKeys(msg)
+	}
+
+	return v, nil
+}
+
+func (v *LocksView) updateKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if v.confirm {
+		v.confirm = false
+		if msg.String() == "y" {
+			if node := v.selected(); node != nil {
+				return v, v.kill(node.ID, false)
+			}
+		}
+		return v, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down", "j":
+		if v.cursor < len(v.rows)-1 {
+			v.cursor++
+		}
+	case "x":
+		if v.selected() != nil {
+			v.err = nil
+			v.message = ""
+			v.confirm = true
+		}
+	case "c":
+		if node := v.selected(); node != nil {
+			v.err = nil
+			v.message = ""
+			return v, v.kill(node.ID, true)
+		}
+	case "r":
+		if !v.loading {
+			v.loading = true
+			return v, v.load
+		}
+	case "a":
+		v.autoRefresh = !v.autoRefresh
+		if v.autoRefresh && !v.loading {
+			return v, v.tick()
+		}
+	case "esc", "backspace", "q":
+		v.autoRefresh = false
+		return v, func() tea.Msg {
+			return SwitchViewMsg{View: "dashboard"}
+		}
+	}
+	return v, nil
+}
+
+// setTrees flattens the blocking trees, keeping the cursor on the same
+// session when it is still there
+func (v *LocksView) setTrees(trees []*db.BlockingNode) {
+	var selectedID int64 = -1
+	if node := v.selected(); node != nil {
+		selectedID = node.ID
+	}
+
+	v.rows = v.rows[:0]
+	var walk func(node *db.BlockingNode, indent string, last bool, root bool)
+	walk = func(node *db.BlockingNode, indent string, last bool, root bool) {
+		prefix, childIndent := "", ""
+		if !root {
+			if last {
+				prefix, childIndent = indent+"└─ ", indent+"   "
+			} else {
+				prefix, childIndent = indent+"├─ ", indent+"│  "
+			}
+		}
+		v.rows = append(v.rows, lockRow{node: node, prefix: prefix})
+		for i, child := range node.Waiting {
+			walk(child, childIndent, i == len(node.Waiting)-1, false)
+		}
+	}
+	for _, tree := range trees {
+		walk(tree, "", true, true)
+	}
+
+	v.cursor = min(v.cursor, max(len(v.rows)-1, 0))
+	for i, row := range v.rows {
+		if row.node.ID == selectedID {
+			v.cursor = i
+			break
+		}
+	}
+}
+
+func (v *LocksView) selected() *db.BlockingNode {
+	if v.cursor < 0 || v.cursor >= len(v.rows) {
+		return nil
+	}
+	return v.rows[v.cursor].node
+}
+
+// View renders the view
+func (v *LocksView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Lock Monitor"))
+	b.WriteString("\n\n")
+
+	if v.loading && v.lastUpdate.IsZero() {
+		b.WriteString("Loading lock waits...")
+		b.WriteString("\n")
+	} else if len(v.rows) == 0 {
+		b.WriteString(successStyle.Render("No sessions are waiting on locks"))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-40s %-12s %-16s %8s  %s", "Session", "User", "Database", "Time", "Query")))
+		b.WriteString("\n")
+
+		// Keep the cursor on screen
+		visible := max(v.height-12, 5)
+		start := 0
+		if v.cursor >= visible {
+			start = v.cursor - visible + 1
+		}
+		end := min(start+visible, len(v.rows))
+
+		for i := start; i < end; i++ {
+			row := v.rows[i]
+			label := fmt.Sprintf("%s%d", row.prefix, row.node.ID)
+			if row.node.Lock != "" {
+				label += " waits: " + row.node.Lock
+			} else if count := row.node.Count(); count > 0 {
+				label += fmt.Sprintf(" blocks %d", count)
+			}
+			query := strings.Join(strings.Fields(row.node.Query), " ")
+			line := fmt.Sprintf("%-40s %-12s %-16s %8s  %s",
+				truncateRunes(label, 40), truncateRunes(row.node.User, 12), truncateRunes(row.node.Database, 16),
+				row.node.Duration.String(), query)
+			line = truncateRunes(line, max(v.width-4, 40))
+
+			if i == v.cursor {
+				b.WriteString(selectedStyle.Render("> " + line))
+			} else if row.prefix == "" {
+				b.WriteString(errorStyle.Render("  " + line))
+			} else {
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	} else if v.confirm {
+		if node := v.selected(); node != nil {
+			b.WriteString(errorStyle.Render(fmt.Sprintf("Kill session %d (%s)? (y/n)", node.ID, node.User)))
+			b.WriteString("\n\n")
+		}
+	} else if v.message != "" {
+		b.WriteString(successStyle.Render(v.message))
+		b.WriteString("\n\n")
+	}
+
+	autoStatus := "OFF"
+	if v.autoRefresh {
+		autoStatus = "ON"
+	}
+	updateStatus := "Never updated"
+	if !v.lastUpdate.IsZero() {
+		updateStatus = "Last update: " + v.lastUpdate.Format("15:04:05")
+	}
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("%s | Auto-refresh: %s", updateStatus, autoStatus)))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑↓: Navigate | x: Kill session | c: Cancel query | r: Refresh | a: Toggle auto-refresh | Esc: Back | q: Quit"))
+
+	return b.String()
+}
+
+// truncateRunes shortens s to at most n runes
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 1 {
+		return string(r[:n])
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,476 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type rebuildMode int
+
+const (
+	rebuildModeForm rebuildMode = iota
+	rebuildModePlanning
+	rebuildModePreview
+	rebuildModeRunning
+	rebuildModeDone
+)
+
+// Online rebuild form fields, in tab order
+const (
+	rebuildFieldEngine = iota
+	rebuildFieldCharset
+	rebuildFieldCollation
+	rebuildFieldRowFormat
+	rebuildFieldChunkSize
+	rebuildFieldDropOld
+	rebuildFieldCount
+)
+
+// OnlineRebuildView walks through rebuilding a MariaDB table through a
+// trigger-fed shadow copy: pick the new settings, review the plan, then
+// watch the copy, which can be aborted until the swap
+type OnlineRebuildView struct {
+	conn     *db.Connection
+	database string
+	table    string
+	mode     rebuildMode
+
+	inputs  []textinput.Model // Indexed by field; the drop-old toggle has none
+	dropOld bool
+	focused int
+	confirm bool // Waiting for y before starting
+
+	plan     *db.OnlineRebuildPlan
+	progress *progressPanel
+	phase    string
+	phases   chan string // Phase changes of the running rebuild
+	cancel   context.CancelFunc
+	started  time.Time
+	result   *db.OnlineRebuildResult
+	err      error
+
+	width  int
+	height int
+}
+
+type rebuildPlannedMsg struct {
+	plan *db.OnlineRebuildPlan
+	err  error
+}
+
+type rebuildPhaseMsg struct {
+	phase string
+}
+
+type rebuildDoneMsg struct {
+	table   string
+	result  *db.OnlineRebuildResult
+	elapsed time.Duration
+	err     error
+}
+
+func (m rebuildDoneMsg) JobResult() JobResult {
+	return JobResult{View: "rebuild", Title: "Rebuild of " + m.table, Elapsed: m.elapsed, Err: m.err}
+}
+
+// NewOnlineRebuildView creates a new online rebuild view for a table
+func NewOnlineRebuildView(conn *db.Connection, database, table string, width, height int) *OnlineRebuildView {
+	v := &OnlineRebuildView{
+		conn:     conn,
+		database: database,
+		table:    table,
+		inputs:   make([]textinput.Model, rebuildFieldCount),
+		width:    width,
+		height:   height,
+	}
+
+	placeholders := map[int]string{
+		rebuildFieldEngine:    "Keep current (e.g. InnoDB, Aria)",
+		rebuildFieldCharset:   "Keep current (e.g. utf8mb4)",
+		rebuildFieldCollation: "Charset default (e.g. utf8mb4_unicode_ci)",
+		rebuildFieldRowFormat: "Keep current (e.g. DYNAMIC, COMPRESSED)",
+		rebuildFieldChunkSize: strconv.Itoa(db.DefaultRebuildChunkSize),
+	}
+	for field, placeholder := range placeholders {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.Width = 40
+		v.inputs[field] = input
+	}
+	v.focus(rebuildFieldEngine)
+	return v
+}
+
+// Init initializes the view
+func (v *OnlineRebuildView) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (v *OnlineRebuildView) focus(field int) {
+	v.focused = field
+	for i := range v.inputs {
+		v.inputs[i].Blur()
+	}
+	if field != rebuildFieldDropOld {
+		v.inputs[field].Focus()
+	}
+}
+
+// options builds the rebuild options from the form
+func (v *OnlineRebuildView) options() (db.OnlineRebuildOptions, error) {
+	value := func(field int) string {
+		return strings.TrimSpace(v.inputs[field].Value())
+	}
+	opts := db.OnlineRebuildOptions{
+		Database:  v.database,
+		Table:     v.table,
+		Engine:    value(rebuildFieldEngine),
+		Charset:   value(rebuildFieldCharset),
+		Collation: value(rebuildFieldCollation),
+		RowFormat: value(rebuildFieldRowFormat),
+		DropOld:   v.dropOld,
+	}
+	if s := value(rebuildFieldChunkSize); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return opts, fmt.Errorf("chunk size must be a positive number of rows")
+		}
+		opts.ChunkSize = n
+	}
+	return opts, nil
+}
+
+func (v *OnlineRebuildView) planRebuild() tea.Cmd {
+	opts, err := v.options()
+	if err != nil {
+		v.err = err
+		return nil
+	}
+	v.mode = rebuildModePlanning
+	v.err = nil
+	conn := v.conn
+	return func() tea.Msg {
+		plan, err := conn.PlanOnlineRebuild(opts)
+		return rebuildPlannedMsg{plan: plan, err: err}
+	}
+}
+
+// startRebuild runs the plan on a connection of its own. Phase changes
+// come back through a channel the view keeps listening on.
+func (v *OnlineRebuildView) startRebuild() tea.Cmd {
+	v.mode = rebuildModeRunning
+	v.confirm = false
+	v.err = nil
+	v.started = time.Now()
+	v.phase = db.RebuildPhaseCopy
+	v.progress = newProgressPanel("Copying "+v.table, progress.Rows, v.plan.EstimatedRows)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	v.cancel = cancel
+	conn, plan, panel, table := v.conn, v.plan, v.progress, v.table
+	phases := make(chan string, 4)
+	v.phases = phases
+
+	run := runJob("Rebuild of "+plan.Database+"."+table, panel, func() tea.Msg {
+		jobConn, release := jobConnection(conn)
+		defer release()
+		defer close(phases)
+
+		start := time.Now()
+		phase := db.RebuildPhaseCopy
+		result, err := jobConn.RunOnlineRebuild(ctx, plan, func(p db.OnlineRebuildProgress) {
+			panel.Set(p.Copied)
+			if p.Phase != phase {
+				phase = p.Phase
+				panel.SetCurrent(p.Phase, 0, 0)
+				phases <- p.Phase
+			}
+		})
+		return rebuildDoneMsg{table: plan.Database + "." + table, result: result, elapsed: time.Since(start), err: err}
+	})
+	return tea.Batch(run, waitRebuildPhase(phases), progressTick())
+}
+
+func waitRebuildPhase(phases <-chan string) tea.Cmd {
+	return func() tea.Msg {
+		phase, ok := <-phases
+		if !ok {
+			return nil
+		}
+		return rebuildPhaseMsg{phase: phase}
+	}
+}
+
+// Update handles messages
+func (v *OnlineRebuildView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		return v, nil
+
+	case rebuildPlannedMsg:
+		if msg.err != nil {
+			v.err = msg.err
+			v.mode = rebuildModeForm
+			return v, nil
+		}
+		v.plan = msg.plan
+		v.mode = rebuildModePreview
+		return v, nil
+
+	case rebuildPhaseMsg:
+		v.phase = msg.phase
+		return v, waitRebuildPhase(v.phases)
+
+	case progressTickMsg:
+		if v.mode == rebuildModeRunning {
+			return v, progressTick()
+		}
+		return v, nil
+
+	case rebuildDoneMsg:
+		v.mode = rebuildModeDone
+		v.cancel = nil
+		v.result = msg.result
+		v.err = msg.err
+		return v, nil
+
+	case tea.KeyMsg:
+		switch v.mode {
+		case rebuildModeForm:
+			return v.updateForm(msg)
+		case rebuildModePreview, rebuildModeDone:
+			return v.updatePreview(msg)
+		case rebuildModeRunning:
+			return v.updateRunning(msg)
+		}
+	}
+
+	return v, nil
+}
+
+func (v *OnlineRebuildView) back() tea.Cmd {
+	database := v.database
+	return func() tea.Msg {
+		return SwitchViewMsg{View: "tables", Database: database}
+	}
+}
+
+func (v *OnlineRebuildView) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return v, v.back()
+	case "tab", "down":
+		v.focus((v.focused + 1) % rebuildFieldCount)
+		return v, nil
+	case "shift+tab", "up":
+		v.focus((v.focused + rebuildFieldCount - 1) % rebuildFieldCount)
+		return v, nil
+	case "enter":
+		return v, v.planRebuild()
+	}
+
+	if v.focused == rebuildFieldDropOld {
+		if msg.String() == " " || msg.String() == "left" || msg.String() == "right" {
+			v.dropOld = !v.dropOld
+		}
+		return v, nil
+	}
+
+	var cmd tea.Cmd
+	v.inputs[v.focused], cmd = v.inputs[v.focused].Update(msg)
+	return v, cmd
+}
+
+func (v *OnlineRebuildView) updatePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if v.confirm {
+		if msg.String() == "y" {
+			return v, v.startRebuild()
+		}
+		v.confirm = false
+		return v, nil
+	}
+
+	switch msg.String() {
+	case "esc", "backspace":
+		return v, v.back()
+	case "q":
+		return v, tea.Quit
+	case "e":
+		if v.mode == rebuildModePreview {
+			v.mode = rebuildModeForm
+			v.err = nil
+		}
+	case "enter":
+		if v.mode == rebuildModePreview {
+			v.confirm = true
+		}
+	}
+	return v, nil
+}
+
+// updateRunning offers an abort until the swap, after which the rebuild
+// has to finish
+func (v *OnlineRebuildView) updateRunning(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "esc" && v.cancel != nil && v.phase == db.RebuildPhaseCopy {
+		v.cancel()
+		v.cancel = nil
+	}
+	return v, nil
+}
+
+// View renders the view
+func (v *OnlineRebuildView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Online Rebuild: %s.%s", v.database, v.table)))
+	b.WriteString("\n\n")
+
+	switch v.mode {
+	case rebuildModeForm:
+		b.WriteString(v.viewForm())
+	case rebuildModePlanning:
+		b.WriteString(mutedStyle.Render("Checking the table..."))
+	case rebuildModePreview:
+		b.WriteString(v.viewPlan())
+	case rebuildModeRunning:
+		b.WriteString(v.progress.View())
+		b.WriteString("\n\n")
+		if v.cancel == nil && v.phase == db.RebuildPhaseCopy {
+			b.WriteString(mutedStyle.Render("Aborting and removing the shadow table..."))
+		} else if v.phase == db.RebuildPhaseCopy {
+			b.WriteString(helpStyle.Render("Esc: Abort and roll back"))
+		} else {
+			b.WriteString(mutedStyle.Render("Swapping the tables; this can't be aborted any more"))
+		}
+	case rebuildModeDone:
+		b.WriteString(v.viewDone())
+	}
+	return b.String()
+}
+
+func (v *OnlineRebuildView) viewForm() string {
+	var b strings.Builder
+
+	label := func(field int, text string) string {
+		if v.focused == field {
+			return focusedStyle.Render(text)
+		}
+		return blurredStyle.Render(text)
+	}
+
+	fields := []struct {
+		field int
+		text  string
+	}{
+		{rebuildFieldEngine, "Engine:"},
+		{rebuildFieldCharset, "Character set:"},
+		{rebuildFieldCollation, "Collation:"},
+		{rebuildFieldRowFormat, "Row format:"},
+		{rebuildFieldChunkSize, "Rows per chunk:"},
+	}
+	for _, f := range fields {
+		b.WriteString(label(f.field, f.text))
+		b.WriteString("\n")
+		b.WriteString(v.inputs[f.field].View())
+		b.WriteString("\n\n")
+	}
+
+	keep := "[ ] Keep the original table for a revert"
+	if !v.dropOld {
+		keep = "[x] Keep the original table for a revert"
+	}
+	b.WriteString(label(rebuildFieldDropOld, keep))
+	b.WriteString("\n\n")
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Tab: Next field | Space: Toggle | Enter: Preview | Esc: Back"))
+	return b.String()
+}
+
+func (v *OnlineRebuildView) viewPlan() string {
+	var b strings.Builder
+	plan := v.plan
+
+	b.WriteString(fmt.Sprintf("Now:   %s\n", plan.Current))
+	b.WriteString(fmt.Sprintf("After: %s\n", headerStyle.Render(plan.Target)))
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("About %d rows, copied %d at a time by %s", plan.EstimatedRows, plan.ChunkSize, strings.Join(plan.Key, ", "))))
+	b.WriteString("\n\n")
+
+	for _, stmt := range plan.Statements() {
+		stmt = strings.Join(strings.Fields(stmt), " ")
+		b.WriteString(truncateRunes(stmt, max(v.width-4, 20)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	for _, note := range plan.Notes {
+		b.WriteString(mutedStyle.Render("• " + note))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if v.confirm {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Rebuild %s.%s on %s now? (y/n)", v.database, v.table, v.conn.Config.Host)))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Enter: Start | e: Edit | Esc: Back | q: Quit"))
+	return b.String()
+}
+
+func (v *OnlineRebuildView) viewDone() string {
+	var b strings.Builder
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	} else if r := v.result; r != nil {
+		b.WriteString(successStyle.Render(fmt.Sprintf("Rebuilt %s: %d rows in %d chunks, %s",
+			v.table, r.Copied, r.Chunks, progress.FormatDuration(r.Duration))))
+		b.WriteString("\n\n")
+		if r.Old != "" {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("The original table is kept as %s; revert with: ysm rebuild %s.%s --revert",
+				r.Old, v.database, v.table)))
+			b.WriteString("\n\n")
+		}
+		for _, w := range r.Warnings {
+			b.WriteString(errorStyle.Render("Warning: " + w))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString(helpStyle.Render("Esc: Back | q: Quit"))
+	return b.String()
+}
@@ -0,0 +1,110 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// generatePasswordKey fills a password field and its confirmation with a
+// generated password
+const generatePasswordKey = "ctrl+g"
+
+// passwordAssist generates passwords for a password field and its
+// confirmation, rates what is typed, and holds back a weak password until
+// it is submitted a second time
+type passwordAssist struct {
+	policy    db.PasswordPolicy
+	generated string // Shown in clear until the password is changed
+	weakOK    string // Weak password already warned about once
+}
+
+func newPasswordAssist(policy db.PasswordPolicy) *passwordAssist {
+	return &passwordAssist{policy: policy}
+}
+
+// generate puts a new password in both inputs
+func (a *passwordAssist) generate(password, confirm *textinput.Model) error {
+	generated, err := db.GeneratePassword(a.policy)
+	if err != nil {
+		return err
+	}
+	password.SetValue(generated)
+	confirm.SetValue(generated)
+	a.generated = generated
+	return nil
+}
+
+// checkWeak returns an error the first time a weak password is submitted;
+// submitting the same password again goes through
+func (a *passwordAssist) checkWeak(password, username string) error {
+	strength := db.CheckPasswordStrength(password, username)
+	if !strength.Weak() || a.weakOK == password {
+		return nil
+	}
+	a.weakOK = password
+	reason := strength.Label()
+	if len(strength.Warnings) > 0 {
+		reason += ": " + strength.Warnings[0]
+	}
+	return fmt.Errorf("password is %s; press Enter again to use it anyway, or Ctrl+G to generate one", reason)
+}
+
+// view renders a strength meter for the password, its warnings, and the
+// generated password while it is unchanged
+func (a *passwordAssist) view(password, username string) string {
+	if password == "" {
+		return mutedStyle.Render("Ctrl+G: generate a password")
+	}
+
+	var b strings.Builder
+	strength := db.CheckPasswordStrength(password, username)
+	b.WriteString(renderPasswordMeter(strength))
+	for _, warning := range strength.Warnings {
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("  • " + warning))
+	}
+	if a.generated != "" && a.generated == password {
+		b.WriteString("\n")
+		b.WriteString(successStyle.Render("Generated: "))
+		b.WriteString(valueStyle.Render(a.generated))
+		b.WriteString(mutedStyle.Render("  (keep it somewhere safe)"))
+	}
+	return b.String()
+}
+
+// renderPasswordMeter draws a bar filled by the strength score
+func renderPasswordMeter(strength db.PasswordStrength) string {
+	const width = 20
+	filled := (strength.Score + 1) * width / (db.PasswordVeryStrong + 1)
+
+	style := successStyle
+	switch {
+	case strength.Weak():
+		style = errorStyle
+	case strength.Score == db.PasswordFair:
+		style = warningStyle
+	}
+	bar := style.Render(strings.Repeat("█", filled)) + mutedStyle.Render(strings.Repeat("░", width-filled))
+	return fmt.Sprintf("Strength: %s %s", bar, style.Render(strength.Label()))
+}
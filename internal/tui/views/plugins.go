@@ -0,0 +1,295 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/plugin"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PluginsView lists views provided by plugins and renders their output
+type PluginsView struct {
+	conn     *db.Connection
+	database string
+	list     list.Model
+	registry *plugin.Registry
+	width    int
+	height   int
+	err      error
+
+	// Rendered plugin output
+	showOutput bool
+	loading    bool
+	current    *plugin.ViewRef
+	response   *plugin.Response
+	results    table.Model
+}
+
+type pluginViewItem struct {
+	ref plugin.ViewRef
+}
+
+func (i pluginViewItem) Title() string {
+	if i.ref.View.Title != "" {
+		return i.ref.View.Title
+	}
+	return i.ref.View.Name
+}
+func (i pluginViewItem) Description() string {
+	return fmt.Sprintf("%s · %s", i.ref.Plugin.Manifest.Name, i.ref.View.Name)
+}
+func (i pluginViewItem) FilterValue() string { return i.Title() }
+
+type pluginsLoadedMsg struct {
+	registry *plugin.Registry
+}
+
+type pluginOutputMsg struct {
+	response *plugin.Response
+}
+
+// NewPluginsView creates a new plugins view
+func NewPluginsView(conn *db.Connection, database string, width, height int) *PluginsView {
+	delegate := newListDelegate()
+
+	l := list.New([]list.Item{}, delegate, width, height-4)
+	l.Title = "Plugin Views"
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+	l.Styles.Title = titleStyle
+
+	t := table.New(
+		table.WithFocused(true),
+		table.WithHeight(height-10),
+	)
+	t.SetStyles(newTableStyles())
+
+	return &PluginsView{
+		conn:     conn,
+		database: database,
+		list:     l,
+		results:  t,
+		width:    width,
+		height:   height,
+	}
+}
+
+// Init initializes the view
+func (v *PluginsView) Init() tea.Cmd {
+	return v.loadPlugins
+}
+
+func (v *PluginsView) loadPlugins() tea.Msg {
+	reg, err := plugin.Discover()
+	if err != nil {
+		return err
+	}
+	return pluginsLoadedMsg{registry: reg}
+}
+
+func (v *PluginsView) renderView(ref plugin.ViewRef) tea.Cmd {
+	v.loading = true
+	v.current = &ref
+	return func() tea.Msg {
+		resp, err := v.registry.RenderView(v.conn, ref, v.database)
+		if err != nil {
+			return err
+		}
+		return pluginOutputMsg{response: resp}
+	}
+}
+
+// Update handles messages
+func (v *PluginsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if v.showOutput {
+			switch msg.String() {
+			case "esc", "backspace":
+				v.showOutput = false
+				v.response = nil
+				return v, nil
+			case "r":
+				return v, v.renderView(*v.current)
+			case "q":
+				return v, tea.Quit
+			}
+			var cmd tea.Cmd
+			v.results, cmd = v.results.Update(msg)
+			return v, cmd
+		}
+
+		if !v.list.SettingFilter() {
+			switch msg.String() {
+			case "enter":
+				if item, ok := v.list.SelectedItem().(pluginViewItem); ok && !v.loading {
+					v.err = nil
+					return v, v.renderView(item.ref)
+				}
+			case "r":
+				return v, v.loadPlugins
+			case "esc", "backspace":
+				return v, func() tea.Msg {
+					return SwitchViewMsg{View: "databases"}
+				}
+			case "q":
+				return v, tea.Quit
+			}
+		}
+
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		v.list.SetSize(msg.Width, msg.Height-4)
+		v.results.SetHeight(msg.Height - 10)
+
+	case pluginsLoadedMsg:
+		v.registry = msg.registry
+		refs := msg.registry.Views()
+		items := make([]list.Item, len(refs))
+		for i, ref := range refs {
+			items[i] = pluginViewItem{ref: ref}
+		}
+		v.list.SetItems(items)
+		return v, nil
+
+	case pluginOutputMsg:
+		v.loading = false
+		v.response = msg.response
+		v.showOutput = true
+		v.updateResultsTable()
+		return v, nil
+
+	case error:
+		v.loading = false
+		v.err = msg
+		return v, nil
+	}
+
+	var cmd tea.Cmd
+	v.list, cmd = v.list.Update(msg)
+	return v, cmd
+}
+
+func (v *PluginsView) updateResultsTable() {
+	if v.response == nil || len(v.response.Columns) == 0 {
+		return
+	}
+
+	maxWidth := 40
+	colWidths := make([]int, len(v.response.Columns))
+	for i, col := range v.response.Columns {
+		colWidths[i] = min(len(col)+2, maxWidth)
+	}
+	for _, row := range v.response.Rows {
+		for i, cell := range row {
+			if i < len(colWidths) {
+				colWidths[i] = max(colWidths[i], min(len(cell)+2, maxWidth))
+			}
+		}
+	}
+
+	cols := make([]table.Column, len(v.response.Columns))
+	for i, name := range v.response.Columns {
+		cols[i] = table.Column{Title: name, Width: colWidths[i]}
+	}
+
+	rows := make([]table.Row, len(v.response.Rows))
+	for i, row := range v.response.Rows {
+		r := make(table.Row, len(cols))
+		for j := range cols {
+			if j < len(row) {
+				r[j] = row[j]
+			}
+		}
+		rows[i] = r
+	}
+
+	v.results.SetRows(nil)
+	v.results.SetColumns(cols)
+	v.results.SetRows(rows)
+}
+
+// View renders the view
+func (v *PluginsView) View() string {
+	if !v.showOutput {
+		var b strings.Builder
+		if v.registry != nil && len(v.registry.Plugins) == 0 {
+			dir, _ := plugin.PluginsDir()
+			b.WriteString(titleStyle.Render("Plugin Views"))
+			b.WriteString("\n\n")
+			b.WriteString(mutedStyle.Render("No plugins installed yet~ drop them into " + dir))
+			b.WriteString("\n\n")
+			b.WriteString(helpStyle.Render("r: Rescan | Esc: Back"))
+			return b.String()
+		}
+
+		b.WriteString(v.list.View())
+		if v.loading {
+			b.WriteString("\n")
+			b.WriteString(mutedStyle.Render("Running plugin..."))
+		}
+		if v.err != nil {
+			b.WriteString("\n")
+			b.WriteString(renderError(v.err))
+		}
+		return b.String()
+	}
+
+	var b strings.Builder
+	title := v.current.View.Title
+	if v.response.Title != "" {
+		title = v.response.Title
+	}
+	if title == "" {
+		title = v.current.View.Name
+	}
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("Provided by " + v.current.Plugin.Manifest.Name))
+	b.WriteString("\n\n")
+
+	if v.response.Content != "" {
+		b.WriteString(v.response.Content)
+		b.WriteString("\n\n")
+	}
+	if len(v.response.Columns) > 0 {
+		b.WriteString(v.results.View())
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("%d row(s)", len(v.response.Rows))))
+		b.WriteString("\n\n")
+	}
+	if v.response.Message != "" {
+		b.WriteString(successStyle.Render(v.response.Message))
+		b.WriteString("\n\n")
+	}
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("↑↓: Scroll | r: Re-run | Esc: Back"))
+	return b.String()
+}
@@ -0,0 +1,305 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// processesRefreshInterval is how often the process list polls while auto-refresh is on
+const processesRefreshInterval = 2 * time.Second
+
+// processesViewSeq tells apart the ticks of successive process lists, so a
+// reopened view doesn't inherit the previous one's refresh loop
+var processesViewSeq int
+
+// ProcessesView lists the queries running on the server, longest first
+type ProcessesView struct {
+	conn        *db.Connection
+	width       int
+	height      int
+	id          int
+	slowAfter   time.Duration // Queries running this long are highlighted
+	sessions    []db.Session
+	cursor      int
+	loading     bool
+	autoRefresh bool
+	lastUpdate  time.Time
+	err         error
+	message     string
+	confirm     bool // Waiting for y before killing the selected session
+}
+
+type processesLoadedMsg struct {
+	sessions []db.Session
+	err      error
+}
+
+type processesKilledMsg struct {
+	id        int64
+	queryOnly bool
+	err       error
+}
+
+type processesTickMsg struct {
+	id int
+}
+
+// NewProcessesView creates a new process list view
+func NewProcessesView(conn *db.Connection, slowAfter time.Duration, width, height int) *ProcessesView {
+	processesViewSeq++
+	return &ProcessesView{
+		conn:        conn,
+		width:       width,
+		height:      height,
+		id:          processesViewSeq,
+		slowAfter:   slowAfter,
+		loading:     true,
+		autoRefresh: true,
+	}
+}
+
+// Init initializes the view
+func (v *ProcessesView) Init() tea.Cmd {
+	return v.load
+}
+
+func (v *ProcessesView) load() tea.Msg {
+	sessions, err := v.conn.RunningQueries()
+	return processesLoadedMsg{sessions: sessions, err: err}
+}
+
+func (v *ProcessesView) tick() tea.Cmd {
+	id := v.id
+	return tea.Tick(processesRefreshInterval, func(t time.Time) tea.Msg {
+		return processesTickMsg{id: id}
+	})
+}
+
+func (v *ProcessesView) kill(id int64, queryOnly bool) tea.Cmd {
+	return func() tea.Msg {
+		return processesKilledMsg{id: id, queryOnly: queryOnly, err: v.conn.KillSession(id, queryOnly)}
+	}
+}
+
+// Update handles messages
+func (v *ProcessesView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+
+	case processesLoadedMsg:
+		v.loading = false
+		v.err = msg.err
+		if msg.err == nil {
+			v.setSessions(msg.sessions)
+			v.lastUpdate = time.Now()
+		}
+		if v.autoRefresh {
+			return v, v.tick()
+		}
+
+	case processesTickMsg:
+		if msg.id == v.id && v.autoRefresh && !v.loading {
+			v.loading = true
+			return v, v.load
+		}
+
+	case processesKilledMsg:
+		if msg.err != nil {
+			v.err = msg.err
+			return v, nil
+		}
+		if msg.queryOnly {
+			v.message = fmt.Sprintf("Cancelled the query of session %d", msg.id)
+		} else {
+			v.message = fmt.Sprintf("Killed session %d", msg.id)
+		}
+		v.loading = true
+		return v, v.load
+
+	case tea.KeyMsg:
+		return v.updateKeys(msg)
+	}
+
+	return v, nil
+}
+
+func (v *ProcessesView) updateKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if v.confirm {
+		v.confirm = false
+		if msg.String() == "y" {
+			if s := v.selected(); s != nil {
+				return v, v.kill(s.ID, false)
+			}
+		}
+		return v, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down", "j":
+		if v.cursor < len(v.sessions)-1 {
+			v.cursor++
+		}
+	case "x":
+		if v.selected() != nil {
+			v.err = nil
+			v.message = ""
+			v.confirm = true
+		}
+	case "c":
+		if s := v.selected(); s != nil {
+			v.err = nil
+			v.message = ""
+			return v, v.kill(s.ID, true)
+		}
+	case "r":
+		if !v.loading {
+			v.loading = true
+			return v, v.load
+		}
+	case "a":
+		v.autoRefresh = !v.autoRefresh
+		if v.autoRefresh && !v.loading {
+			return v, v.tick()
+		}
+	case "esc", "backspace", "q":
+		v.autoRefresh = false
+		return v, func() tea.Msg {
+			return SwitchViewMsg{View: "dashboard"}
+		}
+	}
+	return v, nil
+}
+
+// setSessions replaces the list, keeping the cursor on the same session when
+// it is still running
+func (v *ProcessesView) setSessions(sessions []db.Session) {
+	var selectedID int64 = -1
+	if s := v.selected(); s != nil {
+		selectedID = s.ID
+	}
+
+	v.sessions = sessions
+	v.cursor = min(v.cursor, max(len(v.sessions)-1, 0))
+	for i, s := range v.sessions {
+		if s.ID == selectedID {
+			v.cursor = i
+			break
+		}
+	}
+}
+
+func (v *ProcessesView) selected() *db.Session {
+	if v.cursor < 0 || v.cursor >= len(v.sessions) {
+		return nil
+	}
+	return &v.sessions[v.cursor]
+}
+
+// View renders the view
+func (v *ProcessesView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Running Queries"))
+	b.WriteString("\n\n")
+
+	if v.loading && v.lastUpdate.IsZero() {
+		b.WriteString("Loading process list...")
+		b.WriteString("\n")
+	} else if len(v.sessions) == 0 {
+		b.WriteString(successStyle.Render("No queries are running"))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-10s %-12s %-16s %8s  %-20s %s", "Session", "User", "Database", "Time", "State", "Query")))
+		b.WriteString("\n")
+
+		// Keep the cursor on screen
+		visible := max(v.height-14, 5)
+		start := 0
+		if v.cursor >= visible {
+			start = v.cursor - visible + 1
+		}
+		end := min(start+visible, len(v.sessions))
+
+		for i := start; i < end; i++ {
+			s := v.sessions[i]
+			query := strings.Join(strings.Fields(s.Query), " ")
+			line := fmt.Sprintf("%-10d %-12s %-16s %8s  %-20s %s",
+				s.ID, truncateRunes(s.User, 12), truncateRunes(s.Database, 16),
+				s.Duration.String(), truncateRunes(s.State, 20), query)
+			line = truncateRunes(line, max(v.width-4, 40))
+
+			if i == v.cursor {
+				b.WriteString(selectedStyle.Render("> " + line))
+			} else if s.Duration >= v.slowAfter {
+				b.WriteString(errorStyle.Render("  " + line))
+			} else {
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+
+		// The selected query in full, up to a few lines
+		if s := v.selected(); s != nil {
+			query := strings.Join(strings.Fields(s.Query), " ")
+			b.WriteString("\n")
+			b.WriteString(mutedStyle.Render(truncateRunes(query, max(v.width-4, 40)*3)))
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	} else if v.confirm {
+		if s := v.selected(); s != nil {
+			b.WriteString(errorStyle.Render(fmt.Sprintf("Kill session %d (%s)? (y/n)", s.ID, s.User)))
+			b.WriteString("\n\n")
+		}
+	} else if v.message != "" {
+		b.WriteString(successStyle.Render(v.message))
+		b.WriteString("\n\n")
+	}
+
+	autoStatus := "OFF"
+	if v.autoRefresh {
+		autoStatus = "ON"
+	}
+	updateStatus := "Never updated"
+	if !v.lastUpdate.IsZero() {
+		updateStatus = "Last update: " + v.lastUpdate.Format("15:04:05")
+	}
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("%s | Auto-refresh: %s | Highlighting queries over %s", updateStatus, autoStatus, v.slowAfter)))
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑↓: Navigate | x: Kill session | c: Cancel query | r: Refresh | a: Toggle auto-refresh | Esc: Back"))
+
+	return b.String()
+}
@@ -0,0 +1,674 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Profile form fields, in tab order
+const (
+	profileFieldName = iota
+	profileFieldType
+	profileFieldHost
+	profileFieldPort
+	profileFieldUser
+	profileFieldPassword
+	profileFieldSocket
+	profileFieldDatabase
+	profileFieldFolder
+	profileFieldTags
+	profileFieldOnConnect
+	profileFieldStartupDatabase
+	profileFieldStartupView
+	profileFieldCount
+)
+
+// ProfilesView manages the saved connection profiles: create, edit, test,
+// duplicate and delete them, set the default, and group them by folder
+type ProfilesView struct {
+	cfg       *config.Config
+	connected bool // Esc returns to the database list rather than the connect form
+
+	names     []string // Listed profiles, by folder then name
+	cursor    int
+	filter    textinput.Model
+	filtering bool
+
+	editing   bool
+	original  string         // Name of the edited profile, empty for a new one
+	base      config.Profile // Settings the form doesn't show, kept on save
+	inputs    []textinput.Model
+	typeIndex int
+	viewIndex int // Into profileStartupViews
+	focused   int
+
+	confirm bool   // Waiting for y before deleting
+	testing string // Profile whose connection is being tested
+	message string
+	err     error
+
+	width  int
+	height int
+}
+
+type profileTestedMsg struct {
+	name    string
+	version string
+	elapsed time.Duration
+	err     error
+}
+
+// profileStartupViews are the choices for a profile's startup view; the
+// default opens the startup database's tables, or the database list
+var profileStartupViews = append([]string{""}, config.StartupViews...)
+
+// NewProfilesView creates a new profile manager
+func NewProfilesView(cfg *config.Config, connected bool, width, height int) *ProfilesView {
+	filter := textinput.New()
+	filter.Placeholder = "name, folder, tag or host"
+	filter.Prompt = "/"
+
+	v := &ProfilesView{
+		cfg:       cfg,
+		connected: connected,
+		filter:    filter,
+		inputs:    make([]textinput.Model, profileFieldCount),
+		width:     width,
+		height:    height,
+	}
+
+	placeholders := map[int]string{
+		profileFieldName:            "production",
+		profileFieldHost:            "localhost",
+		profileFieldPort:            "Type default",
+		profileFieldUser:            "root",
+		profileFieldPassword:        "(prompted when empty)",
+		profileFieldSocket:          "(optional) /run/mysqld/mysqld.sock",
+		profileFieldDatabase:        "(optional)",
+		profileFieldFolder:          "(optional) e.g. clients/acme",
+		profileFieldTags:            "(optional) comma separated, e.g. prod, eu",
+		profileFieldOnConnect:       "(optional) run on every session, ; separated, e.g. SET time_zone = '+00:00'",
+		profileFieldStartupDatabase: "(optional) selected on connect",
+	}
+	for field, placeholder := range placeholders {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.Width = 40
+		v.inputs[field] = input
+	}
+	v.inputs[profileFieldPassword].EchoMode = textinput.EchoPassword
+	v.inputs[profileFieldPassword].EchoCharacter = '•'
+
+	v.refresh()
+	return v
+}
+
+// Init initializes the view
+func (v *ProfilesView) Init() tea.Cmd {
+	return nil
+}
+
+// refresh lists the profiles matching the filter, by folder then name,
+// keeping the cursor on the same profile
+func (v *ProfilesView) refresh() {
+	selected := v.selected()
+	query := strings.ToLower(strings.TrimSpace(v.filter.Value()))
+
+	v.names = v.names[:0]
+	for name, p := range v.cfg.Profiles {
+		if query == "" || strings.Contains(profileSearchText(name, p), query) {
+			v.names = append(v.names, name)
+		}
+	}
+	sort.Slice(v.names, func(i, j int) bool {
+		a, b := v.cfg.Profiles[v.names[i]], v.cfg.Profiles[v.names[j]]
+		if a.Folder != b.Folder {
+			return a.Folder < b.Folder
+		}
+		return v.names[i] < v.names[j]
+	})
+
+	v.cursor = min(v.cursor, max(len(v.names)-1, 0))
+	if i := slices.Index(v.names, selected); i >= 0 {
+		v.cursor = i
+	}
+}
+
+// profileSearchText is what the filter matches a profile against
+func profileSearchText(name string, p config.Profile) string {
+	return strings.ToLower(strings.Join([]string{name, p.Folder, p.Host, strings.Join(p.Tags, " ")}, " "))
+}
+
+func (v *ProfilesView) selected() string {
+	if v.cursor < 0 || v.cursor >= len(v.names) {
+		return ""
+	}
+	return v.names[v.cursor]
+}
+
+// save writes the config, reporting failures in the view
+func (v *ProfilesView) save(message string) {
+	if err := v.cfg.Save(); err != nil {
+		v.err = fmt.Errorf("failed to save config: %w", err)
+		return
+	}
+	v.err = nil
+	v.message = message
+}
+
+// openForm edits a profile. The name is the profile's own when editing and
+// a free one when duplicating or creating.
+func (v *ProfilesView) openForm(original, name string, p config.Profile) {
+	v.editing = true
+	v.original = original
+	v.base = p
+	v.err = nil
+	v.message = ""
+
+	v.typeIndex = max(slices.Index(dbTypes, p.Type), 0)
+	v.viewIndex = max(slices.Index(profileStartupViews, p.StartupView), 0)
+	port := ""
+	if p.Port != 0 {
+		port = strconv.Itoa(p.Port)
+	}
+	values := map[int]string{
+		profileFieldName:            name,
+		profileFieldHost:            p.Host,
+		profileFieldPort:            port,
+		profileFieldUser:            p.User,
+		profileFieldPassword:        p.Password,
+		profileFieldSocket:          p.Socket,
+		profileFieldDatabase:        p.Database,
+		profileFieldFolder:          p.Folder,
+		profileFieldTags:            strings.Join(p.Tags, ", "),
+		profileFieldOnConnect:       strings.Join(p.OnConnect, "; "),
+		profileFieldStartupDatabase: p.StartupDatabase,
+	}
+	for field, value := range values {
+		v.inputs[field].SetValue(value)
+	}
+	v.focus(profileFieldName)
+}
+
+func (v *ProfilesView) focus(field int) {
+	v.focused = field
+	for i := range v.inputs {
+		v.inputs[i].Blur()
+	}
+	if field != profileFieldType && field != profileFieldStartupView {
+		v.inputs[field].Focus()
+	}
+}
+
+// freeName returns an unused name for a copy of a profile
+func (v *ProfilesView) freeName(name string) string {
+	candidate := name + "-copy"
+	for i := 2; ; i++ {
+		if _, exists := v.cfg.Profiles[candidate]; !exists {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-copy%d", name, i)
+	}
+}
+
+// submit validates the form and saves the profile
+func (v *ProfilesView) submit() {
+	value := func(field int) string {
+		return strings.TrimSpace(v.inputs[field].Value())
+	}
+
+	name := value(profileFieldName)
+	if name == "" {
+		v.err = fmt.Errorf("the profile needs a name")
+		return
+	}
+	if _, exists := v.cfg.Profiles[name]; exists && name != v.original {
+		v.err = fmt.Errorf("profile '%s' already exists", name)
+		return
+	}
+	if value(profileFieldUser) == "" {
+		v.err = fmt.Errorf("user is required")
+		return
+	}
+	port := 0
+	if s := value(profileFieldPort); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > 65535 {
+			v.err = fmt.Errorf("port must be a number from 1 to 65535")
+			return
+		}
+		port = n
+	}
+
+	var tags []string
+	for _, tag := range strings.Split(value(profileFieldTags), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	p := v.base
+	p.Type = dbTypes[v.typeIndex]
+	p.Host = value(profileFieldHost)
+	p.Port = port
+	p.User = value(profileFieldUser)
+	p.Password = v.inputs[profileFieldPassword].Value()
+	p.Socket = value(profileFieldSocket)
+	p.Database = value(profileFieldDatabase)
+	p.Folder = strings.Trim(value(profileFieldFolder), "/")
+	p.Tags = tags
+	p.OnConnect = db.SplitOnConnect(value(profileFieldOnConnect))
+	p.StartupDatabase = value(profileFieldStartupDatabase)
+	p.StartupView = profileStartupViews[v.viewIndex]
+	if _, _, err := p.Startup(); err != nil {
+		v.err = err
+		return
+	}
+
+	if v.original != "" {
+		if err := v.cfg.RenameProfile(v.original, name); err != nil {
+			v.err = err
+			return
+		}
+	}
+	v.cfg.AddProfile(name, p)
+	// The first profile becomes the default, as with ysm profile add
+	if len(v.cfg.Profiles) == 1 {
+		v.cfg.DefaultProfile = name
+	}
+
+	v.editing = false
+	v.refresh()
+	if i := slices.Index(v.names, name); i >= 0 {
+		v.cursor = i
+	}
+	v.save(fmt.Sprintf("Saved profile '%s'", name))
+}
+
+// test connects with a profile and asks the server for its version
+func (v *ProfilesView) test(name string) tea.Cmd {
+	p, ok := v.cfg.Profiles[name]
+	if !ok {
+		return nil
+	}
+	v.testing = name
+	v.err = nil
+	v.message = ""
+	cfg := p.ToConnectionConfig()
+	return func() tea.Msg {
+		start := time.Now()
+		conn, err := db.Connect(cfg)
+		if err != nil {
+			return profileTestedMsg{name: name, err: err}
+		}
+		defer conn.Close()
+		version, err := conn.GetServerVersion()
+		return profileTestedMsg{name: name, version: version, elapsed: time.Since(start), err: err}
+	}
+}
+
+// Update handles messages
+func (v *ProfilesView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		return v, nil
+
+	case profileTestedMsg:
+		v.testing = ""
+		if msg.err != nil {
+			v.err = fmt.Errorf("%s: %w", msg.name, msg.err)
+			return v, nil
+		}
+		v.message = fmt.Sprintf("Connected to %s in %s - %s", msg.name, msg.elapsed.Round(time.Millisecond), msg.version)
+		return v, nil
+
+	case tea.KeyMsg:
+		if v.editing {
+			return v.updateForm(msg)
+		}
+		if v.filtering {
+			return v.updateFilter(msg)
+		}
+		return v.updateList(msg)
+	}
+
+	return v, nil
+}
+
+func (v *ProfilesView) back() tea.Cmd {
+	view := "connect"
+	if v.connected {
+		view = "databases"
+	}
+	return func() tea.Msg {
+		return SwitchViewMsg{View: view}
+	}
+}
+
+func (v *ProfilesView) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if v.confirm {
+		v.confirm = false
+		if msg.String() == "y" {
+			name := v.selected()
+			if err := v.cfg.RemoveProfile(name); err != nil {
+				v.err = err
+				return v, nil
+			}
+			v.refresh()
+			v.save(fmt.Sprintf("Deleted profile '%s'", name))
+		}
+		return v, nil
+	}
+
+	name := v.selected()
+	switch msg.String() {
+	case "up", "k":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down", "j":
+		if v.cursor < len(v.names)-1 {
+			v.cursor++
+		}
+	case "n":
+		v.openForm("", "", config.Profile{})
+		return v, textinput.Blink
+	case "enter", "e":
+		if name != "" {
+			v.openForm(name, name, v.cfg.Profiles[name])
+			return v, textinput.Blink
+		}
+	case "c":
+		if name != "" {
+			p := v.cfg.Profiles[name]
+			p.Variables = maps.Clone(p.Variables)
+			p.Tags = slices.Clone(p.Tags)
+			v.openForm("", v.freeName(name), p)
+			return v, textinput.Blink
+		}
+	case "d":
+		if name != "" {
+			v.err = nil
+			v.message = ""
+			v.confirm = true
+		}
+	case "s":
+		if name == "" {
+			return v, nil
+		}
+		if v.cfg.DefaultProfile == name {
+			v.cfg.DefaultProfile = ""
+			v.save(fmt.Sprintf("'%s' is no longer the default", name))
+		} else {
+			v.cfg.DefaultProfile = name
+			v.save(fmt.Sprintf("'%s' is the default profile now", name))
+		}
+	case "t":
+		if name != "" && v.testing == "" {
+			return v, v.test(name)
+		}
+	case "/":
+		v.filtering = true
+		v.filter.Focus()
+		return v, textinput.Blink
+	case "esc", "backspace":
+		if v.filter.Value() != "" {
+			v.filter.SetValue("")
+			v.refresh()
+			return v, nil
+		}
+		return v, v.back()
+	case "q":
+		return v, tea.Quit
+	}
+	return v, nil
+}
+
+func (v *ProfilesView) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "esc":
+		v.filtering = false
+		v.filter.Blur()
+		if msg.String() == "esc" {
+			v.filter.SetValue("")
+			v.refresh()
+		}
+		return v, nil
+	}
+	var cmd tea.Cmd
+	v.filter, cmd = v.filter.Update(msg)
+	v.refresh()
+	return v, cmd
+}
+
+func (v *ProfilesView) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		v.editing = false
+		v.err = nil
+		return v, nil
+	case "tab", "down":
+		v.focus((v.focused + 1) % profileFieldCount)
+		return v, nil
+	case "shift+tab", "up":
+		v.focus((v.focused + profileFieldCount - 1) % profileFieldCount)
+		return v, nil
+	case "enter":
+		v.submit()
+		return v, nil
+	}
+
+	if v.focused == profileFieldType {
+		switch msg.String() {
+		case "left", "right", " ":
+			v.typeIndex = (v.typeIndex + 1) % len(dbTypes)
+		}
+		return v, nil
+	}
+	if v.focused == profileFieldStartupView {
+		switch msg.String() {
+		case "right", " ":
+			v.viewIndex = (v.viewIndex + 1) % len(profileStartupViews)
+		case "left":
+			v.viewIndex = (v.viewIndex + len(profileStartupViews) - 1) % len(profileStartupViews)
+		}
+		return v, nil
+	}
+
+	var cmd tea.Cmd
+	v.inputs[v.focused], cmd = v.inputs[v.focused].Update(msg)
+	return v, cmd
+}
+
+// View renders the view
+func (v *ProfilesView) View() string {
+	var b strings.Builder
+
+	if v.editing {
+		title := "New Profile"
+		if v.original != "" {
+			title = "Edit Profile: " + v.original
+		}
+		b.WriteString(titleStyle.Render(title))
+		b.WriteString("\n\n")
+		b.WriteString(v.viewForm())
+		return b.String()
+	}
+
+	b.WriteString(titleStyle.Render("Connection Profiles"))
+	b.WriteString("\n\n")
+
+	if v.filtering || v.filter.Value() != "" {
+		b.WriteString(v.filter.View())
+		b.WriteString("\n\n")
+	}
+
+	if len(v.names) == 0 {
+		if len(v.cfg.Profiles) == 0 {
+			b.WriteString(mutedStyle.Render("No profiles yet - press n to create one"))
+		} else {
+			b.WriteString(mutedStyle.Render("No profiles match the filter"))
+		}
+		b.WriteString("\n")
+	} else {
+		b.WriteString(v.viewList())
+	}
+	b.WriteString("\n")
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	} else if v.confirm {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Delete profile '%s'? (y/n)", v.selected())))
+		b.WriteString("\n\n")
+	} else if v.testing != "" {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("Connecting to %s...", v.testing)))
+		b.WriteString("\n\n")
+	} else if v.message != "" {
+		b.WriteString(successStyle.Render(v.message))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("↑↓: Navigate | n: New | Enter/e: Edit | c: Duplicate | d: Delete | s: Set default | t: Test | /: Filter | Esc: Back"))
+	return b.String()
+}
+
+func (v *ProfilesView) viewList() string {
+	var b strings.Builder
+
+	// Keep the cursor on screen; folder headings take a line each
+	visible := max(v.height-14, 5)
+	start := 0
+	if v.cursor >= visible {
+		start = v.cursor - visible + 1
+	}
+	end := min(start+visible, len(v.names))
+
+	folder := "\x00"
+	for i := start; i < end; i++ {
+		name := v.names[i]
+		p := v.cfg.Profiles[name]
+		if p.Folder != folder {
+			folder = p.Folder
+			heading := folder + "/"
+			if folder == "" {
+				heading = "(no folder)"
+			}
+			b.WriteString(headerStyle.Render(heading))
+			b.WriteString("\n")
+		}
+
+		mark := " "
+		if name == v.cfg.DefaultProfile {
+			mark = "*"
+		}
+		cfg := p.ToConnectionConfig()
+		target := fmt.Sprintf("%s@%s:%d", cfg.User, cmp.Or(cfg.Host, "localhost"), cfg.Port)
+		if cfg.Socket != "" {
+			target = fmt.Sprintf("%s@%s", cfg.User, cfg.Socket)
+		}
+		if p.Database != "" {
+			target += "/" + p.Database
+		}
+		line := fmt.Sprintf("%s %-20s %-9s %s", mark, truncateRunes(name, 20), cfg.Type, target)
+		if len(p.Tags) > 0 {
+			line += "  #" + strings.Join(p.Tags, " #")
+		}
+		line = truncateRunes(line, max(v.width-4, 40))
+
+		if i == v.cursor {
+			b.WriteString(selectedStyle.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("%d of %d profiles | * default", len(v.names), len(v.cfg.Profiles))))
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (v *ProfilesView) viewForm() string {
+	var b strings.Builder
+
+	label := func(field int, text string) string {
+		if v.focused == field {
+			return focusedStyle.Render(text)
+		}
+		return blurredStyle.Render(text)
+	}
+
+	fields := []struct {
+		field int
+		text  string
+	}{
+		{profileFieldName, "Name:"},
+		{profileFieldType, "Type:"},
+		{profileFieldHost, "Host:"},
+		{profileFieldPort, "Port:"},
+		{profileFieldUser, "User:"},
+		{profileFieldPassword, "Password:"},
+		{profileFieldSocket, "Socket:"},
+		{profileFieldDatabase, "Database:"},
+		{profileFieldFolder, "Folder:"},
+		{profileFieldTags, "Tags:"},
+		{profileFieldOnConnect, "On connect:"},
+		{profileFieldStartupDatabase, "Startup database:"},
+		{profileFieldStartupView, "Startup view:"},
+	}
+	for _, f := range fields {
+		b.WriteString(label(f.field, f.text))
+		b.WriteString(" ")
+		switch f.field {
+		case profileFieldType:
+			b.WriteString(label(f.field, fmt.Sprintf("[ %s ]", dbTypes[v.typeIndex])))
+		case profileFieldStartupView:
+			view := cmp.Or(profileStartupViews[v.viewIndex], "default")
+			b.WriteString(label(f.field, fmt.Sprintf("[ %s ]", view)))
+		default:
+			b.WriteString(v.inputs[f.field].View())
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Tab: Next field | ←/→: Change type or startup view | Enter: Save | Esc: Cancel"))
+	return b.String()
+}
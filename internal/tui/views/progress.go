@@ -0,0 +1,182 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/blubskye/yandere_sql_manager/internal/webhook"
+	bubbleprogress "github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// progressTickMsg redraws the progress of a running operation
+type progressTickMsg struct{}
+
+// progressTick schedules the next redraw. Views keep ticking while their
+// operation runs; the operation itself updates the tracker from its goroutine.
+func progressTick() tea.Cmd {
+	return tea.Tick(250*time.Millisecond, func(time.Time) tea.Msg {
+		return progressTickMsg{}
+	})
+}
+
+// JobResult describes a long-running job that just ended
+type JobResult struct {
+	View    string // View that ran the job
+	Title   string // What ran, e.g. "Export of shop"
+	Elapsed time.Duration
+	Err     error
+	Event   webhook.Event // Sent to the configured webhooks
+}
+
+// JobDoneMsg is implemented by the messages long-running jobs end with, so
+// the app can tell the user when they weren't watching
+type JobDoneMsg interface {
+	JobResult() JobResult
+}
+
+// jobConnection returns a connection of its own for a long job, so other
+// views stay responsive while it runs, and a function releasing it. When the
+// server refuses another connection the job shares the session's.
+func jobConnection(conn *db.Connection) (*db.Connection, func()) {
+	dedicated, err := conn.Dedicated()
+	if err != nil {
+		logging.Warn("Running the job on the session connection: %v", err)
+		return conn, func() {}
+	}
+	return dedicated, func() { dedicated.Close() }
+}
+
+// progressPanel renders a tracker as a bar with rate, ETA, a throughput
+// sparkline and the current object. It keeps a log of the objects it moved
+// through for the jobs view.
+type progressPanel struct {
+	*progress.Tracker
+	bar bubbleprogress.Model
+
+	logMu sync.Mutex
+	log   []string
+
+	cancel    context.CancelFunc // Set by cancellable, nil when the job can't be stopped
+	cancelled atomic.Bool
+}
+
+// maxJobLog is how many log lines a panel keeps
+const maxJobLog = 200
+
+func newProgressPanel(label string, unit progress.Unit, total int64) *progressPanel {
+	return &progressPanel{
+		Tracker: progress.New(label, unit, total),
+		bar: bubbleprogress.New(
+			bubbleprogress.WithDefaultGradient(),
+			bubbleprogress.WithWidth(40),
+		),
+	}
+}
+
+// SetCurrent sets the object being worked on, logging each new step like
+// table 2 of 5. Names without steps, like running counts, aren't logged.
+func (p *progressPanel) SetCurrent(name string, step, steps int) {
+	if s := p.Snapshot(); steps > 0 && (name != s.Current || step != s.Step) {
+		current := progress.Snapshot{Current: name, Step: step, Steps: steps}
+		p.logf("%s", current.FormatCurrent())
+	}
+	p.Tracker.SetCurrent(name, step, steps)
+}
+
+// cancellable returns the context of a job that can be stopped from its
+// view or the jobs view, and the function stopping it
+func (p *progressPanel) cancellable() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = func() {
+		if !p.cancelled.Swap(true) {
+			p.logf("Cancelling")
+		}
+		cancel()
+	}
+	return ctx, p.cancel
+}
+
+// logf adds a timestamped line to the panel's log
+func (p *progressPanel) logf(format string, args ...interface{}) {
+	p.logMu.Lock()
+	defer p.logMu.Unlock()
+	p.log = append(p.log, time.Now().Format("15:04:05")+" "+fmt.Sprintf(format, args...))
+	if len(p.log) > maxJobLog {
+		p.log = p.log[len(p.log)-maxJobLog:]
+	}
+}
+
+// logLines returns a copy of the panel's log, oldest first
+func (p *progressPanel) logLines() []string {
+	p.logMu.Lock()
+	defer p.logMu.Unlock()
+	return slices.Clone(p.log)
+}
+
+// View renders the panel
+func (p *progressPanel) View() string {
+	s := p.Snapshot()
+	var b strings.Builder
+
+	b.WriteString(s.Label)
+	if current := s.FormatCurrent(); current != "" {
+		b.WriteString(": ")
+		b.WriteString(headerStyle.Render(current))
+	}
+	b.WriteString("\n\n")
+
+	if s.Fraction >= 0 {
+		b.WriteString(p.bar.ViewAs(s.Fraction))
+	} else {
+		b.WriteString(mutedStyle.Render("working..."))
+	}
+	b.WriteString("\n")
+
+	stats := []string{"Elapsed " + progress.FormatDuration(s.Elapsed)}
+	if done := s.FormatDone(); done != "" {
+		stats = append(stats, done)
+	}
+	if rate := s.FormatRate(); rate != "" {
+		stats = append(stats, rate)
+	}
+	if s.ETA > 0 {
+		stats = append(stats, "ETA "+progress.FormatDuration(s.ETA))
+	}
+	b.WriteString(mutedStyle.Render(strings.Join(stats, " | ")))
+
+	if len(s.History) > 1 {
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("Throughput "))
+		b.WriteString(focusedStyle.Render(progress.Sparkline(s.History, 40)))
+		b.WriteString(mutedStyle.Render(fmt.Sprintf(" (last %ds)", min(len(s.History), 40))))
+	}
+
+	return b.String()
+}
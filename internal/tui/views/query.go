@@ -21,10 +21,14 @@ package views
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -44,10 +48,58 @@ type QueryView struct {
 	showResults bool
 	history   []string
 	historyIdx int
+
+	// Saved query snippets
+	profile       string
+	keybindings   *config.KeyBindings
+	snippetMode   snippetMode
+	snippets      []config.Snippet
+	snippetIdx    int
+	activeSnippet *config.Snippet
+	paramNames    []string
+	paramInputs   []textinput.Model
+	paramFocus    int
+	snippetName   textinput.Model
+	statusMsg     string
+
+	// Prepared statement parameters
+	bindMode   bool
+	bindSQL    string
+	bindInputs []textinput.Model
+	bindFocus  int
+	bindValues []string // Last values used, reused when re-running the same query
+
+	// Two-column results drawn as a chart instead of the table
+	chart       chartMode
+	chartData   *chartData
+	chartOffset int   // First bar shown
+	chartErr    error // Why the last result can't be charted
+
+	// Safe mode: a statement the linter warned about waits for a confirm
+	lint         db.LintPolicy
+	lintWarnings []db.LintWarning
+	lintSQL      string
+	lintArgs     []interface{}
 }
 
+// lintWarningsMsg holds back a statement safe mode warned about
+type lintWarningsMsg struct {
+	sql      string
+	args     []interface{}
+	warnings []db.LintWarning
+}
+
+type snippetMode int
+
+const (
+	snippetModeNone snippetMode = iota
+	snippetModePicker
+	snippetModeParams
+	snippetModeSave
+)
+
 // NewQueryView creates a new query view
-func NewQueryView(conn *db.Connection, database string, width, height int) *QueryView {
+func NewQueryView(conn *db.Connection, cfg *config.Config, profile, database string, width, height int) *QueryView {
 	ta := textarea.New()
 	ta.Placeholder = "Enter SQL query..."
 	ta.Focus()
@@ -62,18 +114,22 @@ func NewQueryView(conn *db.Connection, database string, width, height int) *Quer
 		table.WithHeight(height - 16),
 	)
 
-	s := table.DefaultStyles()
-	s.Header = s.Header.
-		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("#FF69B4")).
-		BorderBottom(true).
-		Bold(true).
-		Foreground(lipgloss.Color("#FF69B4"))
-	s.Selected = s.Selected.
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#FF69B4")).
-		Bold(true)
-	t.SetStyles(s)
+	t.SetStyles(newTableStyles())
+
+	// Load keybindings
+	kb, _ := config.LoadKeyBindings()
+	if kb == nil {
+		kb = config.DefaultKeyBindings()
+	}
+
+	nameInput := textinput.New()
+	nameInput.Placeholder = "snippet name"
+	nameInput.CharLimit = 64
+
+	lint, err := cfg.SafeModePolicy()
+	if err != nil {
+		logging.Warn("Safe mode: %v", err)
+	}
 
 	return &QueryView{
 		conn:     conn,
@@ -84,6 +140,10 @@ func NewQueryView(conn *db.Connection, database string, width, height int) *Quer
 		height:   height,
 		history:  make([]string, 0),
 		historyIdx: -1,
+		profile:     profile,
+		keybindings: kb,
+		snippetName: nameInput,
+		lint:        lint,
 	}
 }
 
@@ -97,9 +157,54 @@ func (v *QueryView) Init() tea.Cmd {
 
 // Update handles messages
 func (v *QueryView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if v.snippetMode != snippetModeNone {
+		return v.updateSnippets(msg)
+	}
+	if v.bindMode {
+		return v.updateBindForm(msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
+		if v.lintWarnings != nil {
+			return v.updateLintConfirm(msg)
+		}
+		key := msg.String()
+		if v.keybindings.IsKey("query", key, config.ActionSnippets) {
+			return v, v.openSnippetPicker()
+		}
+		if v.keybindings.IsKey("query", key, config.ActionHelp) {
+			return v, func() tea.Msg {
+				return ShowHelpMsg{View: "query"}
+			}
+		}
+		if v.keybindings.IsKey("query", key, config.ActionChart) {
+			v.cycleChart()
+			return v, nil
+		}
+		if v.showResults && v.chart == chartBars {
+			switch key {
+			case "up", "k":
+				v.chartOffset = max(v.chartOffset-1, 0)
+				return v, nil
+			case "down", "j":
+				v.chartOffset = min(v.chartOffset+1, max(len(v.chartData.values)-v.chartHeight(), 0))
+				return v, nil
+			}
+		}
+		if v.keybindings.IsKey("query", key, config.ActionSave) {
+			if strings.TrimSpace(v.textarea.Value()) == "" {
+				v.statusMsg = "Nothing to save~ write a query first"
+				return v, nil
+			}
+			v.snippetMode = snippetModeSave
+			v.snippetName.SetValue("")
+			v.snippetName.Focus()
+			v.textarea.Blur()
+			return v, textinput.Blink
+		}
+
+		switch key {
 		case "esc":
 			if v.showResults {
 				v.showResults = false
@@ -153,12 +258,18 @@ func (v *QueryView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		v.textarea.SetWidth(msg.Width - 4)
 		v.results.SetHeight(msg.Height - 16)
 
+	case lintWarningsMsg:
+		v.lintSQL, v.lintArgs, v.lintWarnings = msg.sql, msg.args, msg.warnings
+		v.textarea.Blur()
+		return v, nil
+
 	case queryResult:
 		v.columns = msg.columns
 		v.rows = msg.rows
 		v.affected = msg.affected
 		v.err = nil
 		v.updateResultsTable()
+		v.refreshChart()
 		if len(v.rows) > 0 {
 			v.showResults = true
 			v.textarea.Blur()
@@ -195,32 +306,182 @@ func (v *QueryView) executeQuery() tea.Cmd {
 		}
 	}
 	v.historyIdx = -1
+	v.statusMsg = ""
+
+	// Queries with placeholders are run as prepared statements
+	if n := db.CountPlaceholders(sql, v.conn.Config.Type); n > 0 {
+		return v.openBindForm(sql, n)
+	}
+
+	return v.runQuery(sql)
+}
 
+// runQuery lints sql in safe mode, holding it back for a confirm when there
+// are warnings, and otherwise executes it
+func (v *QueryView) runQuery(sql string, args ...interface{}) tea.Cmd {
+	policy := v.lint
 	return func() tea.Msg {
-		// Determine if this is a SELECT/SHOW query
-		upperSQL := strings.ToUpper(strings.TrimSpace(sql))
-		isQuery := strings.HasPrefix(upperSQL, "SELECT") ||
-			strings.HasPrefix(upperSQL, "SHOW") ||
-			strings.HasPrefix(upperSQL, "DESCRIBE") ||
-			strings.HasPrefix(upperSQL, "EXPLAIN")
-
-		if isQuery {
-			result, err := v.conn.Query(sql)
-			if err != nil {
-				return err
-			}
-			return queryResult{
-				columns: result.Columns,
-				rows:    result.Rows,
-			}
+		if warnings := v.conn.LintSQL(sql, policy, time.Now()); len(warnings) > 0 {
+			return lintWarningsMsg{sql: sql, args: args, warnings: warnings}
 		}
+		return v.execute(sql, args...)
+	}
+}
+
+// execute runs sql, binding args through a prepared statement when given
+func (v *QueryView) execute(sql string, args ...interface{}) tea.Msg {
+	// Determine if this is a SELECT/SHOW query
+	upperSQL := strings.ToUpper(strings.TrimSpace(sql))
+	isQuery := strings.HasPrefix(upperSQL, "SELECT") ||
+		strings.HasPrefix(upperSQL, "SHOW") ||
+		strings.HasPrefix(upperSQL, "DESCRIBE") ||
+		strings.HasPrefix(upperSQL, "EXPLAIN") ||
+		strings.HasPrefix(upperSQL, "WITH")
 
-		affected, err := v.conn.Execute(sql)
+	if isQuery {
+		result, err := v.conn.Query(sql, args...)
 		if err != nil {
 			return err
 		}
-		return queryResult{affected: affected}
+		return queryResult{
+			columns: result.Columns,
+			rows:    result.Rows,
+		}
+	}
+
+	affected, err := v.conn.Execute(sql, args...)
+	if err != nil {
+		return err
 	}
+	return queryResult{affected: affected}
+}
+
+// updateLintConfirm runs the held back statement on y and drops it on n
+func (v *QueryView) updateLintConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		sql, args := v.lintSQL, v.lintArgs
+		v.closeLintConfirm()
+		return v, func() tea.Msg {
+			return v.execute(sql, args...)
+		}
+	case "n", "N", "esc":
+		v.closeLintConfirm()
+		v.statusMsg = "Not run~ nothing was changed"
+	case "ctrl+c":
+		return v, tea.Quit
+	}
+	return v, nil
+}
+
+func (v *QueryView) closeLintConfirm() {
+	v.lintWarnings = nil
+	v.lintSQL = ""
+	v.lintArgs = nil
+	v.textarea.Focus()
+}
+
+func (v *QueryView) renderLintConfirm() string {
+	var b strings.Builder
+
+	b.WriteString(headerStyle.Render("Safe Mode"))
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("Are you sure? This looks like a 3 a.m. mistake~"))
+	b.WriteString("\n\n")
+	for _, w := range v.lintWarnings {
+		b.WriteString(warningStyle.Render("⚠ " + w.Message))
+		b.WriteString(mutedStyle.Render(" (" + w.Rule + ")"))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("y: Run anyway | n/Esc: Cancel"))
+
+	return paneStyle(true).
+		Padding(0, 1).
+		Width(v.width - 6).
+		Render(b.String())
+}
+
+// openBindForm shows one input per placeholder, prefilled with the last values used
+func (v *QueryView) openBindForm(sql string, n int) tea.Cmd {
+	v.bindMode = true
+	v.bindSQL = sql
+	v.bindFocus = 0
+	v.bindInputs = make([]textinput.Model, n)
+	for i := range v.bindInputs {
+		ti := textinput.New()
+		ti.Prompt = fmt.Sprintf("%-4s ", db.ParamLabel(i, v.conn.Config.Type))
+		ti.Placeholder = "value (\\N for NULL)"
+		ti.PromptStyle = blurredStyle
+		if i < len(v.bindValues) {
+			ti.SetValue(v.bindValues[i])
+		}
+		v.bindInputs[i] = ti
+	}
+	v.bindInputs[0].Focus()
+	v.bindInputs[0].PromptStyle = focusedStyle
+	v.textarea.Blur()
+	return textinput.Blink
+}
+
+func (v *QueryView) closeBindForm() {
+	v.bindMode = false
+	v.bindInputs = nil
+	v.textarea.Focus()
+}
+
+func (v *QueryView) updateBindForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			v.closeBindForm()
+			return v, nil
+		case "tab", "down", "shift+tab", "up":
+			v.bindInputs[v.bindFocus].Blur()
+			v.bindInputs[v.bindFocus].PromptStyle = blurredStyle
+			if keyMsg.String() == "tab" || keyMsg.String() == "down" {
+				v.bindFocus = (v.bindFocus + 1) % len(v.bindInputs)
+			} else {
+				v.bindFocus = (v.bindFocus - 1 + len(v.bindInputs)) % len(v.bindInputs)
+			}
+			v.bindInputs[v.bindFocus].Focus()
+			v.bindInputs[v.bindFocus].PromptStyle = focusedStyle
+			return v, nil
+		case "enter", "ctrl+enter", "f5":
+			values := make([]string, len(v.bindInputs))
+			for i, input := range v.bindInputs {
+				values[i] = input.Value()
+			}
+			v.bindValues = values
+			sql := v.bindSQL
+			v.closeBindForm()
+			return v, v.runQuery(sql, db.BindArgs(values)...)
+		}
+	}
+
+	var cmd tea.Cmd
+	v.bindInputs[v.bindFocus], cmd = v.bindInputs[v.bindFocus].Update(msg)
+	return v, cmd
+}
+
+func (v *QueryView) renderBindForm() string {
+	var b strings.Builder
+
+	b.WriteString(headerStyle.Render(fmt.Sprintf("Query Parameters (%d)", len(v.bindInputs))))
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("Values are bound through a prepared statement, never interpolated~"))
+	b.WriteString("\n\n")
+	for _, input := range v.bindInputs {
+		b.WriteString(input.View())
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("Tab: Next parameter | Enter: Execute | Esc: Cancel"))
+
+	return paneStyle(true).
+		Padding(0, 1).
+		Width(v.width - 6).
+		Render(b.String())
 }
 
 type queryResult struct {
@@ -274,6 +535,46 @@ func (v *QueryView) updateResultsTable() {
 	v.results.SetRows(rows)
 }
 
+// cycleChart switches the results between the table, a bar chart and a
+// line chart, staying on the table when they can't be charted
+func (v *QueryView) cycleChart() {
+	if len(v.rows) == 0 {
+		return
+	}
+	if v.chart == chartNone {
+		data, err := newChartData(v.columns, v.rows)
+		v.chartErr = err
+		if err != nil {
+			return
+		}
+		v.chartData = data
+		v.chartOffset = 0
+	}
+	v.chart = (v.chart + 1) % (chartLine + 1)
+}
+
+// refreshChart keeps charting new results when they fit, and goes back to
+// the table when they don't
+func (v *QueryView) refreshChart() {
+	v.chartErr = nil
+	v.chartOffset = 0
+	if v.chart == chartNone {
+		return
+	}
+	data, err := newChartData(v.columns, v.rows)
+	if err != nil {
+		v.chart = chartNone
+		v.chartData = nil
+		return
+	}
+	v.chartData = data
+}
+
+// chartHeight is the lines a chart may use, as many as the results table
+func (v *QueryView) chartHeight() int {
+	return max(v.height-17, 5)
+}
+
 // View renders the view
 func (v *QueryView) View() string {
 	var b strings.Builder
@@ -287,29 +588,53 @@ func (v *QueryView) View() string {
 	b.WriteString("\n\n")
 
 	// Query input
-	inputStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("#FF69B4")).
+	inputStyle := paneStyle(!v.showResults).
 		Padding(0, 1)
-	if !v.showResults {
-		inputStyle = inputStyle.BorderForeground(lipgloss.Color("#FF1493"))
-	}
 	b.WriteString(inputStyle.Render(v.textarea.View()))
 	b.WriteString("\n\n")
 
+	// Snippet dialogs replace the results area while open
+	if v.snippetMode != snippetModeNone {
+		b.WriteString(v.renderSnippets())
+		return b.String()
+	}
+	if v.bindMode {
+		b.WriteString(v.renderBindForm())
+		return b.String()
+	}
+	if v.lintWarnings != nil {
+		b.WriteString(v.renderLintConfirm())
+		return b.String()
+	}
+
+	if v.statusMsg != "" {
+		b.WriteString(successStyle.Render(v.statusMsg))
+		b.WriteString("\n\n")
+	}
+
 	// Error or results
 	if v.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", v.err)))
+		b.WriteString(renderError(v.err))
 		b.WriteString("\n\n")
 	} else if len(v.rows) > 0 {
-		resultStyle := lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#FF69B4"))
-		if v.showResults {
-			resultStyle = resultStyle.BorderForeground(lipgloss.Color("#FF1493"))
+		resultStyle := paneStyle(v.showResults)
+		switch v.chart {
+		case chartBars:
+			b.WriteString(subtitleStyle.Render(fmt.Sprintf("%s by %s", v.chartData.valueColumn, v.chartData.labelColumn)))
+			b.WriteString("\n")
+			b.WriteString(resultStyle.Render(v.chartData.renderBars(v.chartOffset, v.width-6, v.chartHeight())))
+		case chartLine:
+			b.WriteString(subtitleStyle.Render(fmt.Sprintf("%s over %s", v.chartData.valueColumn, v.chartData.labelColumn)))
+			b.WriteString("\n")
+			b.WriteString(resultStyle.Render(v.chartData.renderLine(v.width-6, v.chartHeight())))
+		default:
+			b.WriteString(resultStyle.Render(v.results.View()))
 		}
-		b.WriteString(resultStyle.Render(v.results.View()))
 		b.WriteString("\n")
+		if v.chartErr != nil {
+			b.WriteString(renderError(v.chartErr))
+			b.WriteString("\n")
+		}
 		b.WriteString(mutedStyle.Render(fmt.Sprintf("%d row(s) returned", len(v.rows))))
 		b.WriteString("\n")
 	} else if v.affected > 0 {
@@ -318,8 +643,270 @@ func (v *QueryView) View() string {
 	}
 
 	// Help
-	help := "Ctrl+Enter/F5: Execute | Tab: Switch focus | Ctrl+↑↓: History | Esc: Back"
+	help := fmt.Sprintf("Ctrl+Enter/F5: Execute | Tab: Switch focus | Ctrl+↑↓: History | %s: Chart | %s: Snippets | %s: Save snippet | %s: Help | Esc: Back",
+		v.keybindings.GetKey("query", config.ActionChart),
+		v.keybindings.GetKey("query", config.ActionSnippets), v.keybindings.GetKey("query", config.ActionSave),
+		v.keybindings.GetKey("query", config.ActionHelp))
 	b.WriteString(helpStyle.Render(help))
 
 	return b.String()
 }
+
+// openSnippetPicker loads the snippet library and shows the picker
+func (v *QueryView) openSnippetPicker() tea.Cmd {
+	lib, err := config.LoadSnippets()
+	if err != nil {
+		v.err = err
+		return nil
+	}
+	v.snippets = lib.ForProfile(v.profile)
+	v.snippetIdx = 0
+	v.snippetMode = snippetModePicker
+	v.textarea.Blur()
+	return nil
+}
+
+// closeSnippets returns focus to the editor
+func (v *QueryView) closeSnippets() {
+	v.snippetMode = snippetModeNone
+	v.activeSnippet = nil
+	v.paramNames = nil
+	v.paramInputs = nil
+	v.snippetName.Blur()
+	v.showResults = false
+	v.textarea.Focus()
+}
+
+func (v *QueryView) updateSnippets(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch v.snippetMode {
+	case snippetModePicker:
+		return v.updateSnippetPicker(msg)
+	case snippetModeParams:
+		return v.updateSnippetParams(msg)
+	case snippetModeSave:
+		return v.updateSnippetSave(msg)
+	}
+	return v, nil
+}
+
+func (v *QueryView) updateSnippetPicker(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return v, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "ctrl+o":
+		v.closeSnippets()
+	case "up", "k":
+		if v.snippetIdx > 0 {
+			v.snippetIdx--
+		}
+	case "down", "j":
+		if v.snippetIdx < len(v.snippets)-1 {
+			v.snippetIdx++
+		}
+	case "d", "delete":
+		if len(v.snippets) == 0 {
+			return v, nil
+		}
+		lib, err := config.LoadSnippets()
+		if err == nil {
+			err = lib.RemoveSnippet(v.profile, v.snippets[v.snippetIdx].Name)
+		}
+		if err == nil {
+			err = lib.Save()
+		}
+		if err != nil {
+			v.err = err
+			v.closeSnippets()
+			return v, nil
+		}
+		v.snippets = lib.ForProfile(v.profile)
+		if v.snippetIdx >= len(v.snippets) && v.snippetIdx > 0 {
+			v.snippetIdx--
+		}
+	case "enter":
+		if len(v.snippets) == 0 {
+			return v, nil
+		}
+		snippet := v.snippets[v.snippetIdx]
+		names := snippet.Placeholders()
+		if len(names) == 0 {
+			v.closeSnippets()
+			v.textarea.SetValue(snippet.Query)
+			v.statusMsg = fmt.Sprintf("Loaded snippet '%s'~", snippet.Name)
+			return v, nil
+		}
+
+		// Prompt for each placeholder value
+		v.activeSnippet = &snippet
+		v.paramNames = names
+		v.paramInputs = make([]textinput.Model, len(names))
+		for i, name := range names {
+			ti := textinput.New()
+			ti.Placeholder = name
+			ti.Prompt = name + ": "
+			ti.PromptStyle = blurredStyle
+			v.paramInputs[i] = ti
+		}
+		v.paramFocus = 0
+		v.paramInputs[0].Focus()
+		v.paramInputs[0].PromptStyle = focusedStyle
+		v.snippetMode = snippetModeParams
+		return v, textinput.Blink
+	}
+
+	return v, nil
+}
+
+func (v *QueryView) updateSnippetParams(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			v.snippetMode = snippetModePicker
+			v.activeSnippet = nil
+			return v, nil
+		case "tab", "down", "shift+tab", "up":
+			v.paramInputs[v.paramFocus].Blur()
+			v.paramInputs[v.paramFocus].PromptStyle = blurredStyle
+			if keyMsg.String() == "tab" || keyMsg.String() == "down" {
+				v.paramFocus = (v.paramFocus + 1) % len(v.paramInputs)
+			} else {
+				v.paramFocus = (v.paramFocus - 1 + len(v.paramInputs)) % len(v.paramInputs)
+			}
+			v.paramInputs[v.paramFocus].Focus()
+			v.paramInputs[v.paramFocus].PromptStyle = focusedStyle
+			return v, nil
+		case "enter":
+			values := make(map[string]string, len(v.paramNames))
+			for i, name := range v.paramNames {
+				values[name] = v.paramInputs[i].Value()
+			}
+			name := v.activeSnippet.Name
+			v.textarea.SetValue(v.activeSnippet.Render(values))
+			v.closeSnippets()
+			v.statusMsg = fmt.Sprintf("Loaded snippet '%s'~ press F5 to run", name)
+			return v, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	v.paramInputs[v.paramFocus], cmd = v.paramInputs[v.paramFocus].Update(msg)
+	return v, cmd
+}
+
+func (v *QueryView) updateSnippetSave(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			v.closeSnippets()
+			return v, nil
+		case "enter":
+			name := strings.TrimSpace(v.snippetName.Value())
+			lib, err := config.LoadSnippets()
+			if err == nil {
+				err = lib.AddSnippet(v.profile, config.Snippet{
+					Name:  name,
+					Query: strings.TrimSpace(v.textarea.Value()),
+				})
+			}
+			if err == nil {
+				err = lib.Save()
+			}
+			v.closeSnippets()
+			if err != nil {
+				v.err = err
+				return v, nil
+			}
+			v.err = nil
+			v.statusMsg = fmt.Sprintf("Saved snippet '%s'~ <3", name)
+			return v, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	v.snippetName, cmd = v.snippetName.Update(msg)
+	return v, cmd
+}
+
+func (v *QueryView) renderSnippets() string {
+	var b strings.Builder
+
+	boxStyle := paneStyle(true).
+		Padding(0, 1).
+		Width(v.width - 6)
+
+	switch v.snippetMode {
+	case snippetModePicker:
+		profile := v.profile
+		if profile == "" {
+			profile = config.DefaultSnippetProfile
+		}
+		b.WriteString(headerStyle.Render(fmt.Sprintf("Saved Queries (%s)", profile)))
+		b.WriteString("\n\n")
+		if len(v.snippets) == 0 {
+			b.WriteString(mutedStyle.Render("No snippets yet~ press Esc, write a query and save it with " +
+				v.keybindings.GetKey("query", config.ActionSave)))
+			b.WriteString("\n")
+		}
+
+		visible := v.height - 22
+		if visible < 3 {
+			visible = 3
+		}
+		start := 0
+		if v.snippetIdx >= visible {
+			start = v.snippetIdx - visible + 1
+		}
+		for i := start; i < len(v.snippets) && i < start+visible; i++ {
+			s := v.snippets[i]
+			line := s.Name
+			if s.Description != "" {
+				line += " - " + s.Description
+			}
+			if params := s.Placeholders(); len(params) > 0 {
+				line += mutedStyle.Render(fmt.Sprintf(" (%s)", strings.Join(params, ", ")))
+			}
+			if i == v.snippetIdx {
+				b.WriteString(selectedStyle.Render("> " + line))
+			} else {
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+
+		if len(v.snippets) > 0 {
+			preview := strings.ReplaceAll(v.snippets[v.snippetIdx].Query, "\n", " ")
+			if len(preview) > v.width-12 && v.width > 20 {
+				preview = preview[:v.width-15] + "..."
+			}
+			b.WriteString("\n")
+			b.WriteString(mutedStyle.Render(preview))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("↑↓: Navigate | Enter: Use snippet | d: Delete | Esc: Close"))
+
+	case snippetModeParams:
+		b.WriteString(headerStyle.Render(fmt.Sprintf("Parameters for '%s'", v.activeSnippet.Name)))
+		b.WriteString("\n\n")
+		for _, input := range v.paramInputs {
+			b.WriteString(input.View())
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("Tab: Next field | Enter: Insert query | Esc: Back"))
+
+	case snippetModeSave:
+		b.WriteString(headerStyle.Render("Save Query as Snippet"))
+		b.WriteString("\n\n")
+		b.WriteString(v.snippetName.View())
+		b.WriteString("\n\n")
+		b.WriteString(mutedStyle.Render("Tip: use {{name}} in the query to be prompted for values later~"))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Enter: Save | Esc: Cancel"))
+	}
+
+	return boxStyle.Render(b.String())
+}
@@ -0,0 +1,223 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// chartMode is how query results are shown
+type chartMode int
+
+const (
+	chartNone chartMode = iota // The results table
+	chartBars
+	chartLine
+)
+
+// Chart styles, set by ApplyTheme
+var (
+	chartStyle         lipgloss.Style
+	chartNegativeStyle lipgloss.Style
+)
+
+// chartBarBlocks are the eighths of a bar cell, thinnest first
+var chartBarBlocks = []rune("▏▎▍▌▋▊▉█")
+
+// chartData is a result set fit for charting: a label and a number per row
+type chartData struct {
+	labelColumn string
+	valueColumn string
+	labels      []string
+	values      []float64
+}
+
+// newChartData reads a two-column result as labels (names, dates or times)
+// and numeric values. Rows with a NULL value are left out.
+func newChartData(columns []string, rows [][]string) (*chartData, error) {
+	if len(columns) != 2 {
+		return nil, fmt.Errorf("charts need two columns, a label and a number (this result has %d)", len(columns))
+	}
+	data := &chartData{labelColumn: columns[0], valueColumn: columns[1]}
+	for _, row := range rows {
+		if len(row) < 2 || row[1] == "NULL" {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(row[1]), 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, fmt.Errorf("can't chart %s: %q isn't a number", columns[1], row[1])
+		}
+		data.labels = append(data.labels, row[0])
+		data.values = append(data.values, value)
+	}
+	if len(data.values) == 0 {
+		return nil, fmt.Errorf("nothing to chart: every %s is NULL", columns[1])
+	}
+	return data, nil
+}
+
+// bounds returns the smallest and largest value
+func (d *chartData) bounds() (lo, hi float64) {
+	lo, hi = d.values[0], d.values[0]
+	for _, v := range d.values[1:] {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	return lo, hi
+}
+
+// formatChartValue shows whole numbers without decimals
+func formatChartValue(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// renderBars draws one horizontal bar per row, from offset on, scaled to the
+// largest absolute value. Negative values are drawn in red.
+func (d *chartData) renderBars(offset, width, height int) string {
+	labelWidth, valueWidth := 0, 0
+	peak := 0.0
+	for i, v := range d.values {
+		labelWidth = max(labelWidth, lipgloss.Width(d.labels[i]))
+		valueWidth = max(valueWidth, len(formatChartValue(v)))
+		peak = math.Max(peak, math.Abs(v))
+	}
+	labelWidth = min(labelWidth, 24)
+	barWidth := max(width-labelWidth-valueWidth-4, 10)
+
+	var b strings.Builder
+	end := min(offset+height, len(d.values))
+	for i := offset; i < end; i++ {
+		v := d.values[i]
+		eighths := 0
+		if peak > 0 {
+			eighths = int(math.Round(math.Abs(v) / peak * float64(barWidth*8)))
+		}
+		bar := strings.Repeat("█", eighths/8)
+		if eighths%8 > 0 {
+			bar += string(chartBarBlocks[eighths%8-1])
+		}
+		style := chartStyle
+		if v < 0 {
+			style = chartNegativeStyle
+		}
+
+		b.WriteString(fmt.Sprintf("%-*s │", labelWidth, truncateRunes(d.labels[i], labelWidth)))
+		b.WriteString(style.Render(bar))
+		b.WriteString(strings.Repeat(" ", barWidth-lipgloss.Width(bar)+1))
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("%*s", valueWidth, formatChartValue(v))))
+		if i < end-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// brailleDots are the bits of a braille cell's dots, by column then row
+var brailleDots = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// renderLine draws the values in order as a line of braille dots, two
+// across and four down per character, with the range on the left and the
+// first and last labels underneath
+func (d *chartData) renderLine(width, height int) string {
+	lo, hi := d.bounds()
+	axisWidth := max(len(formatChartValue(lo)), len(formatChartValue(hi)))
+	cols := max(width-axisWidth-2, 10)
+	rows := max(height-1, 2)
+	dotsX, dotsY := cols*2, rows*4
+
+	cells := make([][]rune, rows)
+	for i := range cells {
+		cells[i] = make([]rune, cols)
+	}
+	plot := func(x, y int) {
+		cells[y/4][x/2] |= brailleDots[x%2][y%4]
+	}
+	// Dot coordinates of a value: x across the width, y from the top
+	point := func(i int) (int, int) {
+		x := 0
+		if len(d.values) > 1 {
+			x = i * (dotsX - 1) / (len(d.values) - 1)
+		}
+		y := dotsY / 2
+		if hi > lo {
+			y = int(math.Round((hi - d.values[i]) / (hi - lo) * float64(dotsY-1)))
+		}
+		return x, y
+	}
+
+	x0, y0 := point(0)
+	plot(x0, y0)
+	for i := 1; i < len(d.values); i++ {
+		x1, y1 := point(i)
+		// Step along the longer side so the segment has no gaps
+		steps := max(abs(x1-x0), abs(y1-y0), 1)
+		for s := 1; s <= steps; s++ {
+			plot(x0+(x1-x0)*s/steps, y0+(y1-y0)*s/steps)
+		}
+		x0, y0 = x1, y1
+	}
+
+	var b strings.Builder
+	for r, line := range cells {
+		axis := ""
+		switch r {
+		case 0:
+			axis = formatChartValue(hi)
+		case rows - 1:
+			axis = formatChartValue(lo)
+		}
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("%*s ┤", axisWidth, axis)))
+		for i, c := range line {
+			if c == 0 {
+				line[i] = ' '
+			} else {
+				line[i] = 0x2800 | c
+			}
+		}
+		b.WriteString(chartStyle.Render(string(line)))
+		b.WriteString("\n")
+	}
+
+	first, last := d.labels[0], d.labels[len(d.labels)-1]
+	gap := cols - lipgloss.Width(first) - lipgloss.Width(last)
+	b.WriteString(strings.Repeat(" ", axisWidth+2))
+	if gap > 0 {
+		b.WriteString(mutedStyle.Render(first + strings.Repeat(" ", gap) + last))
+	} else {
+		b.WriteString(mutedStyle.Render(truncateRunes(first+" → "+last, cols)))
+	}
+	return b.String()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,378 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type schemaDiffMode int
+
+const (
+	schemaDiffModePrompt schemaDiffMode = iota
+	schemaDiffModeResult
+)
+
+// SchemaDiffView compares two databases table by table and shows the
+// migration that makes the target match the source
+type SchemaDiffView struct {
+	conn     *db.Connection
+	database string
+	mode     schemaDiffMode
+
+	source  textinput.Model
+	target  textinput.Model
+	focused int
+
+	diff       *db.SchemaDiff
+	cursor     int
+	showScript bool // Whole migration instead of the selected table
+	scroll     int
+	loading    bool
+	status     string
+	err        error
+
+	width  int
+	height int
+}
+
+type schemaDiffLoadedMsg struct {
+	diff *db.SchemaDiff
+	err  error
+}
+
+type schemaDiffSavedMsg struct {
+	path string
+	err  error
+}
+
+// NewSchemaDiffView creates a new schema diff view with database as the source
+func NewSchemaDiffView(conn *db.Connection, database string, width, height int) *SchemaDiffView {
+	source := textinput.New()
+	source.Placeholder = "Source database (wanted schema)"
+	source.SetValue(database)
+	source.Width = 40
+
+	target := textinput.New()
+	target.Placeholder = "Target database (to migrate)"
+	target.Width = 40
+	target.Focus()
+
+	return &SchemaDiffView{
+		conn:     conn,
+		database: database,
+		source:   source,
+		target:   target,
+		focused:  1,
+		width:    width,
+		height:   height,
+	}
+}
+
+// Init initializes the view
+func (v *SchemaDiffView) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (v *SchemaDiffView) compare() tea.Cmd {
+	source := strings.TrimSpace(v.source.Value())
+	target := strings.TrimSpace(v.target.Value())
+	v.loading = true
+	v.err = nil
+	v.status = ""
+	return func() tea.Msg {
+		diff, err := v.conn.DiffSchemas(source, target)
+		return schemaDiffLoadedMsg{diff: diff, err: err}
+	}
+}
+
+func (v *SchemaDiffView) saveScript() tea.Cmd {
+	diff := v.diff
+	path := fmt.Sprintf("%s_migration_%s.sql", diff.Target, time.Now().Format("20060102_150405"))
+	return func() tea.Msg {
+		err := os.WriteFile(path, []byte(diff.Script()), 0644)
+		return schemaDiffSavedMsg{path: path, err: err}
+	}
+}
+
+// Update handles messages
+func (v *SchemaDiffView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		return v, nil
+
+	case schemaDiffLoadedMsg:
+		v.loading = false
+		if msg.err != nil {
+			v.err = msg.err
+			return v, nil
+		}
+		v.diff = msg.diff
+		v.mode = schemaDiffModeResult
+		v.cursor = 0
+		v.scroll = 0
+		return v, nil
+
+	case schemaDiffSavedMsg:
+		if msg.err != nil {
+			v.err = msg.err
+		} else {
+			v.err = nil
+			v.status = fmt.Sprintf("Migration written to %s", msg.path)
+		}
+		return v, nil
+
+	case tea.KeyMsg:
+		if v.loading {
+			return v, nil
+		}
+		if v.mode == schemaDiffModePrompt {
+			return v.updatePrompt(msg)
+		}
+		return v.updateResult(msg)
+	}
+
+	return v, nil
+}
+
+func (v *SchemaDiffView) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		if v.diff != nil {
+			v.mode = schemaDiffModeResult
+			return v, nil
+		}
+		return v, func() tea.Msg {
+			return SwitchViewMsg{View: "databases"}
+		}
+	case "tab", "shift+tab", "up", "down":
+		v.focused = 1 - v.focused
+		if v.focused == 0 {
+			v.source.Focus()
+			v.target.Blur()
+		} else {
+			v.target.Focus()
+			v.source.Blur()
+		}
+		return v, nil
+	case "enter":
+		if strings.TrimSpace(v.source.Value()) == "" || strings.TrimSpace(v.target.Value()) == "" {
+			v.err = fmt.Errorf("both databases are required")
+			return v, nil
+		}
+		return v, v.compare()
+	}
+
+	var cmd tea.Cmd
+	if v.focused == 0 {
+		v.source, cmd = v.source.Update(msg)
+	} else {
+		v.target, cmd = v.target.Update(msg)
+	}
+	return v, cmd
+}
+
+func (v *SchemaDiffView) updateResult(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "backspace":
+		return v, func() tea.Msg {
+			return SwitchViewMsg{View: "databases"}
+		}
+	case "q":
+		return v, tea.Quit
+	case "up", "k":
+		if v.cursor > 0 {
+			v.cursor--
+			v.scroll = 0
+		}
+	case "down", "j":
+		if v.cursor < len(v.diff.Tables)-1 {
+			v.cursor++
+			v.scroll = 0
+		}
+	case "pgdown", "ctrl+d":
+		v.scroll += 10
+	case "pgup", "ctrl+u":
+		v.scroll = max(v.scroll-10, 0)
+	case "s":
+		v.showScript = !v.showScript
+		v.scroll = 0
+	case "w":
+		if len(v.diff.Tables) > 0 {
+			return v, v.saveScript()
+		}
+	case "r":
+		return v, v.compare()
+	case "n":
+		v.mode = schemaDiffModePrompt
+		v.err = nil
+		v.status = ""
+	}
+	return v, nil
+}
+
+// View renders the view
+func (v *SchemaDiffView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Schema Diff"))
+	b.WriteString("\n\n")
+
+	if v.mode == schemaDiffModePrompt {
+		b.WriteString(v.viewPrompt())
+		return b.String()
+	}
+
+	d := v.diff
+	b.WriteString(fmt.Sprintf("Making %s match %s", headerStyle.Render(d.Target), headerStyle.Render(d.Source)))
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("  (%d different, %d identical)", len(d.Tables), len(d.Identical))))
+	b.WriteString("\n\n")
+
+	if v.loading {
+		b.WriteString("Comparing schemas...\n")
+		return b.String()
+	}
+
+	if len(d.Tables) == 0 {
+		b.WriteString(successStyle.Render("The schemas are identical"))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("n: New comparison | r: Refresh | Esc: Back | q: Quit"))
+		return b.String()
+	}
+
+	// Table list, kept to a third of the screen around the cursor
+	listHeight := max(v.height/3, 3)
+	start := 0
+	if v.cursor >= listHeight {
+		start = v.cursor - listHeight + 1
+	}
+	end := min(start+listHeight, len(d.Tables))
+	for i := start; i < end; i++ {
+		t := d.Tables[i]
+		line := fmt.Sprintf("%s %s", t.Kind.Symbol(), t.Table)
+		if t.Kind == db.SchemaModified {
+			line += mutedStyle.Render(fmt.Sprintf("  (%d changes)", len(t.Changes)))
+		}
+		if i == v.cursor {
+			b.WriteString(selectedStyle.Render("> " + fmt.Sprintf("%s %s", t.Kind.Symbol(), t.Table)))
+		} else {
+			b.WriteString("  " + schemaChangeStyle(t.Kind).Render(line))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// Detail pane: the selected table's changes and statements, or the whole script
+	var lines []string
+	if v.showScript {
+		lines = append(lines, headerStyle.Render("Migration script"))
+		lines = append(lines, strings.Split(strings.TrimRight(d.Script(), "\n"), "\n")...)
+	} else {
+		t := d.Tables[v.cursor]
+		lines = append(lines, headerStyle.Render("Changes in "+t.Table))
+		for _, c := range t.Changes {
+			lines = append(lines, "  "+schemaChangeStyle(c.Kind).Render(c.String()))
+		}
+		if statements := t.Statements(); len(statements) > 0 {
+			lines = append(lines, "", headerStyle.Render("Statements"))
+			for _, stmt := range statements {
+				lines = append(lines, strings.Split(stmt+";", "\n")...)
+			}
+		}
+	}
+
+	detailHeight := max(v.height-(end-start)-14, 5)
+	v.scroll = min(v.scroll, max(len(lines)-detailHeight, 0))
+	for _, line := range lines[v.scroll:min(v.scroll+detailHeight, len(lines))] {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	if len(lines) > detailHeight {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  lines %d-%d of %d", v.scroll+1, min(v.scroll+detailHeight, len(lines)), len(lines))))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	} else if v.status != "" {
+		b.WriteString(successStyle.Render(v.status))
+		b.WriteString("\n\n")
+	}
+
+	scriptHelp := "s: Full script"
+	if v.showScript {
+		scriptHelp = "s: Table changes"
+	}
+	b.WriteString(helpStyle.Render("↑↓: Table | PgUp/PgDn: Scroll | " + scriptHelp + " | w: Write script | n: New comparison | r: Refresh | Esc: Back"))
+
+	return b.String()
+}
+
+func (v *SchemaDiffView) viewPrompt() string {
+	var b strings.Builder
+
+	label := func(field int, text string) string {
+		if v.focused == field {
+			return focusedStyle.Render(text)
+		}
+		return blurredStyle.Render(text)
+	}
+
+	b.WriteString(label(0, "Source (the schema you want):"))
+	b.WriteString("\n")
+	b.WriteString(v.source.View())
+	b.WriteString("\n\n")
+	b.WriteString(label(1, "Target (the database to migrate):"))
+	b.WriteString("\n")
+	b.WriteString(v.target.View())
+	b.WriteString("\n\n")
+
+	if v.loading {
+		b.WriteString("Comparing schemas...\n\n")
+	} else if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Tab: Switch field | Enter: Compare | Esc: Back"))
+	return b.String()
+}
+
+// schemaChangeStyle colours additions, removals and modifications
+func schemaChangeStyle(kind db.SchemaChangeKind) lipgloss.Style {
+	switch kind {
+	case db.SchemaAdded:
+		return successStyle
+	case db.SchemaRemoved:
+		return errorStyle
+	}
+	return focusedStyle
+}
@@ -20,8 +20,11 @@ package views
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -30,40 +33,71 @@ import (
 
 // SettingsView shows and allows editing of MariaDB system variables
 type SettingsView struct {
-	conn       *db.Connection
-	width      int
-	height     int
-
-	variables  []db.Variable
-	cursor     int
-	editing    bool
-	editInput  textinput.Model
-	showGlobal bool
-	filter     string
-	filtering  bool
+	conn        *db.Connection
+	cfg         *config.Config
+	keybindings *config.KeyBindings
+	width       int
+	height      int
+
+	loaded      []db.Variable // As loaded, before client-side filters
+	variables   []db.Variable
+	cursor      int
+	editing     bool
+	editInput   textinput.Model
+	meta        *db.VariableMeta // Of the variable being edited, once loaded
+	method      int              // Index into meta.Methods
+	showGlobal  bool
+	filter      string         // LIKE pattern, or ~regexp
+	pattern     *regexp.Regexp // Compiled ~regexp filter
+	filtering   bool
 	filterInput textinput.Model
-
-	err        error
-	statusMsg  string
+	grouped     bool // Group by category
+	changedOnly bool // Only variables changed from their default
+
+	// Diff against another profile's server
+	profiles      []string
+	diffPrompt    bool
+	profileIndex  int
+	passwordInput textinput.Model
+	diffProfile   string
+	diffs         []db.VariableDiff
+	diffCursor    int
+	diffLoading   bool
+
+	err       error
+	statusMsg string
 }
 
 // NewSettingsView creates a new settings view
-func NewSettingsView(conn *db.Connection, width, height int) *SettingsView {
+func NewSettingsView(conn *db.Connection, cfg *config.Config, width, height int) *SettingsView {
 	editInput := textinput.New()
 	editInput.Placeholder = "Enter new value"
 	editInput.CharLimit = 256
 
 	filterInput := textinput.New()
-	filterInput.Placeholder = "Filter variables..."
+	filterInput.Placeholder = "LIKE pattern (innodb%), or ~regexp (~^(max|min)_)"
 	filterInput.CharLimit = 64
 
-	return &SettingsView{
-		conn:        conn,
-		width:       width,
-		height:      height,
-		editInput:   editInput,
-		filterInput: filterInput,
+	passwordInput := textinput.New()
+	passwordInput.Placeholder = "password (if the profile has none)"
+	passwordInput.EchoMode = textinput.EchoPassword
+	passwordInput.EchoCharacter = '•'
+
+	v := &SettingsView{
+		conn:          conn,
+		cfg:           cfg,
+		keybindings:   loadKeyBindings(),
+		width:         width,
+		height:        height,
+		editInput:     editInput,
+		filterInput:   filterInput,
+		passwordInput: passwordInput,
+	}
+	if cfg != nil {
+		v.profiles = cfg.ListProfiles()
+		sort.Strings(v.profiles)
 	}
+	return v
 }
 
 // Init initializes the view
@@ -75,11 +109,17 @@ func (v *SettingsView) loadVariables() tea.Msg {
 	var variables []db.Variable
 	var err error
 
-	if v.filter != "" {
+	pattern := v.filter
+	if v.pattern != nil || v.changedOnly {
+		// Regexp and changed-only filters run here, on every variable
+		pattern = "%"
+	}
+
+	if pattern != "" {
 		if v.showGlobal {
-			variables, err = v.conn.GetGlobalVariables(v.filter)
+			variables, err = v.conn.GetGlobalVariables(pattern)
 		} else {
-			variables, err = v.conn.GetVariables(v.filter)
+			variables, err = v.conn.GetVariables(pattern)
 		}
 	} else {
 		// Load common variables by default
@@ -89,6 +129,8 @@ func (v *SettingsView) loadVariables() tea.Msg {
 	if err != nil {
 		return err
 	}
+	// Without defaults there is just no highlighting
+	v.conn.AnnotateDefaults(variables)
 	return variablesLoadedMsg{variables: variables}
 }
 
@@ -99,6 +141,88 @@ type variablesLoadedMsg struct {
 type variableSetMsg struct {
 	name  string
 	value string
+	hint  string
+}
+
+type variableMetaMsg struct {
+	meta *db.VariableMeta
+	err  error
+}
+
+type variablesDiffedMsg struct {
+	profile string
+	diffs   []db.VariableDiff
+	err     error
+}
+
+// applyFilters narrows the loaded variables down with the client-side
+// filters and orders them
+func (v *SettingsView) applyFilters() {
+	v.variables = v.variables[:0]
+	for _, variable := range v.loaded {
+		if v.pattern != nil && !v.pattern.MatchString(variable.Name) {
+			continue
+		}
+		if v.changedOnly && !variable.Changed {
+			continue
+		}
+		v.variables = append(v.variables, variable)
+	}
+	if v.grouped {
+		db.SortVariablesByCategory(v.variables)
+	}
+	if v.cursor >= len(v.variables) {
+		v.cursor = 0
+	}
+}
+
+// setFilter applies a filter typed by the user: ~ starts a case-insensitive
+// regexp on the name, anything else is a LIKE pattern for the server
+func (v *SettingsView) setFilter(filter string) error {
+	var pattern *regexp.Regexp
+	if expr, ok := strings.CutPrefix(filter, "~"); ok {
+		re, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			return fmt.Errorf("invalid regexp: %w", err)
+		}
+		pattern = re
+	}
+	v.filter = filter
+	v.pattern = pattern
+	v.cursor = 0
+	return nil
+}
+
+// loadMeta reads what the variable being edited accepts
+func (v *SettingsView) loadMeta(name string) tea.Cmd {
+	return func() tea.Msg {
+		meta, err := v.conn.GetVariableMeta(name)
+		return variableMetaMsg{meta: meta, err: err}
+	}
+}
+
+// diffWith compares this server's global variables with the server of a
+// profile
+func (v *SettingsView) diffWith(name, password string) tea.Cmd {
+	return func() tea.Msg {
+		p, err := v.cfg.GetProfile(name)
+		if err != nil {
+			return variablesDiffedMsg{profile: name, err: err}
+		}
+		cc := p.ToConnectionConfig()
+		if cc.Password == "" {
+			cc.Password = password
+		}
+
+		other, err := db.Connect(cc)
+		if err != nil {
+			return variablesDiffedMsg{profile: name, err: fmt.Errorf("failed to connect to %s: %w", name, err)}
+		}
+		defer other.Close()
+
+		diffs, err := v.conn.DiffVariables(other)
+		return variablesDiffedMsg{profile: name, diffs: diffs, err: err}
+	}
 }
 
 // Update handles messages
@@ -109,7 +233,11 @@ func (v *SettingsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if v.filtering {
 			switch msg.String() {
 			case "enter":
-				v.filter = v.filterInput.Value()
+				if err := v.setFilter(v.filterInput.Value()); err != nil {
+					v.err = err
+					return v, nil
+				}
+				v.err = nil
 				v.filtering = false
 				v.filterInput.Blur()
 				return v, v.loadVariables
@@ -130,9 +258,15 @@ func (v *SettingsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch msg.String() {
 			case "enter":
 				return v, v.setVariable()
+			case "tab":
+				if v.meta != nil && len(v.meta.Methods) > 1 {
+					v.method = (v.method + 1) % len(v.meta.Methods)
+				}
+				return v, nil
 			case "esc":
 				v.editing = false
 				v.editInput.Blur()
+				v.err = nil
 				return v, nil
 			default:
 				var cmd tea.Cmd
@@ -141,7 +275,17 @@ func (v *SettingsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		if v.diffPrompt {
+			return v.updateDiffPrompt(msg)
+		}
+		if v.diffProfile != "" {
+			return v.updateDiff(msg)
+		}
+
 		// Normal mode
+		if cmd := showHelp(v.keybindings, "settings", msg.String()); cmd != nil {
+			return v, cmd
+		}
 		switch msg.String() {
 		case "esc":
 			return v, func() tea.Msg {
@@ -160,9 +304,11 @@ func (v *SettingsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "enter":
 			if len(v.variables) > 0 {
 				v.editing = true
+				v.meta = nil
+				v.method = 0
 				v.editInput.SetValue(v.variables[v.cursor].Value)
 				v.editInput.Focus()
-				return v, textinput.Blink
+				return v, tea.Batch(textinput.Blink, v.loadMeta(v.variables[v.cursor].Name))
 			}
 		case "g":
 			v.showGlobal = !v.showGlobal
@@ -177,9 +323,33 @@ func (v *SettingsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "c":
 			// Clear filter
 			v.filter = ""
+			v.pattern = nil
+			v.changedOnly = false
 			v.filterInput.SetValue("")
 			v.cursor = 0
 			return v, v.loadVariables
+		case "o":
+			v.grouped = !v.grouped
+			v.applyFilters()
+			return v, nil
+		case "d":
+			v.changedOnly = !v.changedOnly
+			v.cursor = 0
+			return v, v.loadVariables
+		case "t":
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "tuning"}
+			}
+		case "D":
+			if len(v.profiles) == 0 {
+				v.err = fmt.Errorf("no profiles to compare with; save the other server as a profile first")
+				return v, nil
+			}
+			v.err = nil
+			v.diffPrompt = true
+			v.passwordInput.SetValue("")
+			v.passwordInput.Focus()
+			return v, textinput.Blink
 		}
 
 	case tea.WindowSizeMsg:
@@ -187,19 +357,52 @@ func (v *SettingsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		v.height = msg.Height
 
 	case variablesLoadedMsg:
-		v.variables = msg.variables
-		if v.cursor >= len(v.variables) {
-			v.cursor = 0
-		}
+		v.loaded = msg.variables
+		v.applyFilters()
 		v.err = nil
 		return v, nil
 
+	case variableMetaMsg:
+		if !v.editing || v.cursor >= len(v.variables) {
+			return v, nil
+		}
+		if msg.err != nil {
+			// Edit without validation rather than not at all
+			v.err = msg.err
+			return v, nil
+		}
+		if msg.meta.Name != v.variables[v.cursor].Name {
+			return v, nil
+		}
+		v.meta = msg.meta
+		v.method = 0
+		if v.showGlobal {
+			// Start from the server-wide method when looking at global values
+			v.method = len(v.meta.Methods) - 1
+		}
+		v.method = max(v.method, 0)
+		return v, nil
+
 	case variableSetMsg:
 		v.editing = false
 		v.editInput.Blur()
 		v.statusMsg = fmt.Sprintf("Set %s = %s", msg.name, msg.value)
+		if msg.hint != "" {
+			v.statusMsg += " - " + msg.hint
+		}
 		return v, v.loadVariables
 
+	case variablesDiffedMsg:
+		v.diffLoading = false
+		if msg.err != nil {
+			v.diffProfile = ""
+			v.err = msg.err
+			return v, nil
+		}
+		v.diffs = msg.diffs
+		v.diffCursor = 0
+		return v, nil
+
 	case error:
 		v.err = msg
 		v.editing = false
@@ -210,6 +413,58 @@ func (v *SettingsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return v, nil
 }
 
+// updateDiffPrompt handles the profile picker shown before a diff
+func (v *SettingsView) updateDiffPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		v.diffPrompt = false
+		v.passwordInput.Blur()
+		return v, nil
+	case "left", "up":
+		v.profileIndex = (v.profileIndex + len(v.profiles) - 1) % len(v.profiles)
+		return v, nil
+	case "right", "down", "tab":
+		v.profileIndex = (v.profileIndex + 1) % len(v.profiles)
+		return v, nil
+	case "enter":
+		v.diffPrompt = false
+		v.passwordInput.Blur()
+		v.diffProfile = v.profiles[v.profileIndex]
+		v.diffs = nil
+		v.diffLoading = true
+		return v, v.diffWith(v.diffProfile, v.passwordInput.Value())
+	}
+
+	var cmd tea.Cmd
+	v.passwordInput, cmd = v.passwordInput.Update(msg)
+	return v, cmd
+}
+
+// updateDiff handles the keys of the diff result
+func (v *SettingsView) updateDiff(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "backspace":
+		v.diffProfile = ""
+		v.diffs = nil
+	case "up", "k":
+		if v.diffCursor > 0 {
+			v.diffCursor--
+		}
+	case "down", "j":
+		if v.diffCursor < len(v.diffs)-1 {
+			v.diffCursor++
+		}
+	case "r":
+		if !v.diffLoading {
+			v.diffLoading = true
+			return v, v.diffWith(v.diffProfile, v.passwordInput.Value())
+		}
+	case "q", "ctrl+c":
+		return v, tea.Quit
+	}
+	return v, nil
+}
+
 func (v *SettingsView) setVariable() tea.Cmd {
 	if v.cursor >= len(v.variables) {
 		return nil
@@ -218,17 +473,115 @@ func (v *SettingsView) setVariable() tea.Cmd {
 	varName := v.variables[v.cursor].Name
 	varValue := v.editInput.Value()
 
+	// Without metadata the server is left to judge the value
+	meta := v.meta
+	if meta == nil {
+		global := v.showGlobal
+		return func() tea.Msg {
+			err := v.conn.SetVariable(varName, varValue, global)
+			if err != nil {
+				return err
+			}
+			return variableSetMsg{name: varName, value: varValue}
+		}
+	}
+
+	if len(meta.Methods) == 0 {
+		v.err = fmt.Errorf("%s can't be changed at runtime: %s", varName, meta.ApplyHint(""))
+		return nil
+	}
+	if err := meta.Validate(varValue); err != nil {
+		v.err = err
+		return nil
+	}
+	v.err = nil
+
+	method := meta.Methods[v.method]
 	return func() tea.Msg {
-		err := v.conn.SetVariable(varName, varValue, v.showGlobal)
-		if err != nil {
+		if err := v.conn.ApplyVariableChange(varName, varValue, method); err != nil {
 			return err
 		}
-		return variableSetMsg{name: varName, value: varValue}
+		return variableSetMsg{name: varName, value: varValue, hint: meta.ApplyHint(method)}
 	}
 }
 
+// variableMethodLabels name the ways to apply a change in the editor
+var variableMethodLabels = map[string]string{
+	db.VariableSetSession: "SET (session)",
+	db.VariableSetGlobal:  "SET GLOBAL",
+	db.VariableSetSystem:  "ALTER SYSTEM + pg_reload_conf()",
+}
+
+// viewEditInfo describes the variable being edited: what it accepts, when
+// a change takes effect and how it will be applied
+func (v *SettingsView) viewEditInfo() string {
+	var b strings.Builder
+	m := v.meta
+	if m == nil {
+		if v.err == nil {
+			b.WriteString(mutedStyle.Render("Loading variable details..."))
+			b.WriteString("\n")
+		}
+		return b.String()
+	}
+
+	if m.Type != "" {
+		accepts := m.Type
+		switch {
+		case len(m.Values) > 0:
+			accepts += ": " + strings.Join(m.Values, ", ")
+		case m.Min != "" || m.Max != "":
+			accepts += fmt.Sprintf(" from %s to %s", m.Min, m.Max)
+		}
+		if m.Unit != "" {
+			accepts += " (" + m.Unit + ")"
+		}
+		b.WriteString(mutedStyle.Render("Accepts: " + accepts))
+		b.WriteString("\n")
+	}
+
+	change := "Change: " + m.Apply
+	if m.Apply == db.VariableApplyRestart {
+		b.WriteString(warningStyle.Render(change + " required"))
+	} else {
+		b.WriteString(mutedStyle.Render(change))
+	}
+	b.WriteString("\n")
+
+	if len(m.Methods) == 0 {
+		b.WriteString(warningStyle.Render(m.ApplyHint("")))
+		b.WriteString("\n")
+		return b.String()
+	}
+	b.WriteString("Apply: ")
+	for i, method := range m.Methods {
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		if i == v.method {
+			b.WriteString(selectedStyle.Render("[" + variableMethodLabels[method] + "]"))
+		} else {
+			b.WriteString(mutedStyle.Render(" " + variableMethodLabels[method] + " "))
+		}
+	}
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render(m.ApplyHint(m.Methods[v.method])))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// changedStyle marks variables changed from their default, set by ApplyTheme
+var changedStyle lipgloss.Style
+
 // View renders the view
 func (v *SettingsView) View() string {
+	if v.diffPrompt {
+		return v.viewDiffPrompt()
+	}
+	if v.diffProfile != "" {
+		return v.viewDiff()
+	}
+
 	var b strings.Builder
 
 	// Title
@@ -244,14 +597,21 @@ func (v *SettingsView) View() string {
 		b.WriteString("Filter: ")
 		b.WriteString(v.filterInput.View())
 		b.WriteString("\n\n")
-	} else if v.filter != "" {
-		b.WriteString(mutedStyle.Render(fmt.Sprintf("Filter: %s (press 'c' to clear)", v.filter)))
+	} else if v.filter != "" || v.changedOnly {
+		var active []string
+		if v.filter != "" {
+			active = append(active, v.filter)
+		}
+		if v.changedOnly {
+			active = append(active, "changed from default")
+		}
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("Filter: %s (press 'c' to clear)", strings.Join(active, ", "))))
 		b.WriteString("\n\n")
 	}
 
 	// Error message
 	if v.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", v.err)))
+		b.WriteString(renderError(v.err))
 		b.WriteString("\n\n")
 	}
 
@@ -277,23 +637,54 @@ func (v *SettingsView) View() string {
 			maxNameWidth = 35
 		}
 
+		// Rows are variables plus, when grouped, a header per category
+		type row struct {
+			header   string
+			variable int
+		}
+		var rows []row
+		cursorRow := 0
+		category := ""
+		for i, variable := range v.variables {
+			if v.grouped {
+				if c := db.VariableCategory(variable.Name); c != category {
+					category = c
+					rows = append(rows, row{header: c, variable: -1})
+				}
+			}
+			if i == v.cursor {
+				cursorRow = len(rows)
+			}
+			rows = append(rows, row{variable: i})
+		}
+
 		// Determine visible range
-		visibleHeight := v.height - 12
+		visibleHeight := v.height - 14
+		if v.editing {
+			visibleHeight -= 5 // Editor details below the list
+		}
 		if visibleHeight < 5 {
 			visibleHeight = 5
 		}
 
 		startIdx := 0
-		if v.cursor >= visibleHeight {
-			startIdx = v.cursor - visibleHeight + 1
+		if cursorRow >= visibleHeight {
+			startIdx = cursorRow - visibleHeight + 1
 		}
 
 		endIdx := startIdx + visibleHeight
-		if endIdx > len(v.variables) {
-			endIdx = len(v.variables)
+		if endIdx > len(rows) {
+			endIdx = len(rows)
 		}
 
-		for i := startIdx; i < endIdx; i++ {
+		for _, r := range rows[startIdx:endIdx] {
+			if r.variable < 0 {
+				b.WriteString(headerStyle.Render(r.header))
+				b.WriteString("\n")
+				continue
+			}
+
+			i := r.variable
 			variable := v.variables[i]
 			name := variable.Name
 			if len(name) > maxNameWidth {
@@ -313,28 +704,40 @@ func (v *SettingsView) View() string {
 				value = value[:maxValueWidth-3] + "..."
 			}
 
+			marker := " "
+			if variable.Changed {
+				marker = "*"
+			}
+
 			if i == v.cursor {
 				if v.editing {
 					// Show edit input
-					b.WriteString(focusedStyle.Render(paddedName))
+					b.WriteString(focusedStyle.Render(marker + paddedName))
 					b.WriteString(" = ")
 					b.WriteString(v.editInput.View())
 				} else {
 					// Highlighted row
-					rowStyle := lipgloss.NewStyle().
-						Foreground(lipgloss.Color("#FFFFFF")).
-						Background(lipgloss.Color("#FF69B4")).
-						Bold(true)
-					b.WriteString(rowStyle.Render(fmt.Sprintf(" %s = %s ", paddedName, value)))
+					b.WriteString(selectedRowStyle.Render(fmt.Sprintf("%s%s = %s ", marker, paddedName, value)))
 				}
+			} else if variable.Changed {
+				b.WriteString(changedStyle.Render(fmt.Sprintf("%s%s = %s", marker, paddedName, value)))
 			} else {
-				b.WriteString(fmt.Sprintf(" %s = %s", paddedName, value))
+				b.WriteString(fmt.Sprintf("%s%s = %s", marker, paddedName, value))
 			}
 			b.WriteString("\n")
 		}
 
+		// Default of the selected variable
+		if selected := v.variables[v.cursor]; selected.Default != "" {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("\nDefault: %s", selected.Default)))
+		}
+		if v.editing {
+			b.WriteString("\n\n")
+			b.WriteString(v.viewEditInfo())
+		}
+
 		// Scroll indicator
-		if len(v.variables) > visibleHeight {
+		if len(rows) > visibleHeight {
 			b.WriteString(mutedStyle.Render(fmt.Sprintf("\n[%d/%d]", v.cursor+1, len(v.variables))))
 		}
 	}
@@ -346,11 +749,94 @@ func (v *SettingsView) View() string {
 	if v.filtering {
 		help = "Enter: Apply filter | Esc: Cancel"
 	} else if v.editing {
-		help = "Enter: Save | Esc: Cancel"
+		help = "Enter: Save | Tab: Apply method | Esc: Cancel"
 	} else {
-		help = "↑↓: Navigate | Enter: Edit | /: Filter | c: Clear filter | g: Toggle Global/Session | r: Refresh | Esc: Back"
+		help = fmt.Sprintf("↑↓: Navigate | Enter: Edit | /: Filter | d: Changed only | o: Group | D: Diff profile | t: Tuning wizard | c: Clear filter | g: Toggle Global/Session | r: Refresh | %s: Help | Esc: Back",
+			v.keybindings.GetKey("settings", config.ActionHelp))
 	}
 	b.WriteString(helpStyle.Render(help))
 
 	return b.String()
 }
+
+func (v *SettingsView) viewDiffPrompt() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Compare Variables"))
+	b.WriteString("\n\n")
+	b.WriteString(mutedStyle.Render("Compare this server's global variables with the server of a profile"))
+	b.WriteString("\n\n")
+
+	b.WriteString(focusedStyle.Render("Profile: "))
+	b.WriteString(headerStyle.Render("< " + v.profiles[v.profileIndex] + " >"))
+	if p, err := v.cfg.GetProfile(v.profiles[v.profileIndex]); err == nil {
+		cc := p.ToConnectionConfig()
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  %s %s:%d", cc.Type, cc.Host, cc.Port)))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(blurredStyle.Render("Password:"))
+	b.WriteString("\n")
+	b.WriteString(v.passwordInput.View())
+	b.WriteString("\n\n")
+
+	b.WriteString(helpStyle.Render("←/→: Profile | Enter: Compare | Esc: Cancel"))
+	return b.String()
+}
+
+func (v *SettingsView) viewDiff() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Variables: this server vs %s", v.diffProfile)))
+	b.WriteString("\n\n")
+
+	switch {
+	case v.diffLoading && v.diffs == nil:
+		b.WriteString("Comparing variables...\n")
+	case len(v.diffs) == 0:
+		b.WriteString(successStyle.Render("No differences"))
+		b.WriteString("\n")
+	default:
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("%d variables differ", len(v.diffs))))
+		b.WriteString("\n\n")
+
+		nameWidth := 0
+		for _, d := range v.diffs {
+			nameWidth = max(nameWidth, len(d.Name))
+		}
+		nameWidth = min(nameWidth, 35)
+		valueWidth := max((v.width-nameWidth-8)/2, 15)
+
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-*s  %-*s  %s", nameWidth, "Variable", valueWidth, "This server", v.diffProfile)))
+		b.WriteString("\n")
+
+		visible := max(v.height-12, 5)
+		start := 0
+		if v.diffCursor >= visible {
+			start = v.diffCursor - visible + 1
+		}
+		end := min(start+visible, len(v.diffs))
+		for i := start; i < end; i++ {
+			d := v.diffs[i]
+			local, other := d.Local, d.Other
+			if local == "" {
+				local = "(missing)"
+			}
+			if other == "" {
+				other = "(missing)"
+			}
+			line := fmt.Sprintf("%-*s  %-*s  %s", nameWidth, truncateRunes(d.Name, nameWidth),
+				valueWidth, truncateRunes(local, valueWidth), truncateRunes(other, valueWidth))
+			if i == v.diffCursor {
+				b.WriteString(selectedStyle.Render("> " + line))
+			} else {
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+
+	b.WriteString(helpStyle.Render("↑↓: Navigate | r: Refresh | Esc: Back"))
+	return b.String()
+}
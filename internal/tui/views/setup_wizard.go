@@ -22,7 +22,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -54,6 +56,15 @@ type SetupWizardView struct {
 
 	// Processing state
 	processing bool
+
+	skipBootstrap bool // Don't run the template's bootstrap script
+
+	passwords *passwordAssist // Ctrl+G generation and the strength meter
+
+	// Connection snippets for the new database, shown once it is set up
+	app          db.AppConnection
+	snippetIndex int
+	copied       string // What the last copy did
 }
 
 type wizardStep int
@@ -72,15 +83,24 @@ const (
 var defaultHosts2 = []string{"localhost", "%", "127.0.0.1"}
 
 // NewSetupWizardView creates a new setup wizard view
-func NewSetupWizardView(conn *db.Connection, width, height int) *SetupWizardView {
+func NewSetupWizardView(conn *db.Connection, cfg *config.Config, width, height int) *SetupWizardView {
 	v := &SetupWizardView{
 		conn:      conn,
 		width:     width,
 		height:    height,
-		templates: db.DefaultTemplates(),
 		charsets:  db.CommonCharsets(),
 	}
 
+	// Custom templates join the built-in ones; a broken templates.yaml
+	// leaves just the built-in ones, with the error shown
+	v.templates, v.err = config.AppTemplates()
+
+	policy, err := cfg.PasswordPolicy()
+	if err != nil {
+		logging.Warn("Passwords: %v", err)
+	}
+	v.passwords = newPasswordAssist(policy)
+
 	// Initialize text inputs
 	v.dbName = textinput.New()
 	v.dbName.Placeholder = "myapp_db"
@@ -112,7 +132,9 @@ func (v *SetupWizardView) Init() tea.Cmd {
 	return textinput.Blink
 }
 
-type setupCompleteMsg struct{}
+type setupCompleteMsg struct {
+	app db.AppConnection
+}
 
 // Update handles messages
 func (v *SetupWizardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -147,6 +169,30 @@ func (v *SetupWizardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "right":
 			return v.handleRight()
 
+		case "e":
+			if v.step == wizardStepTemplate {
+				return v, func() tea.Msg {
+					return SwitchViewMsg{View: "templates"}
+				}
+			}
+
+		case "b":
+			if v.step == wizardStepReview && v.templates[v.templateIndex].Bootstrap != "" {
+				v.skipBootstrap = !v.skipBootstrap
+				return v, nil
+			}
+
+		case "c":
+			if v.step == wizardStepComplete {
+				return v, v.copySnippet()
+			}
+
+		case generatePasswordKey:
+			if v.step == wizardStepPassword || v.step == wizardStepConfirm {
+				v.err = v.passwords.generate(&v.password, &v.confirmPass)
+				return v, nil
+			}
+
 		case "tab":
 			if v.step == wizardStepAdvanced {
 				// Cycle through advanced options
@@ -162,6 +208,17 @@ func (v *SetupWizardView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		v.processing = false
 		v.success = true
 		v.step = wizardStepComplete
+		v.app = msg.app
+		return v, nil
+
+	case clipboardCopiedMsg:
+		if msg.err != nil {
+			v.err = msg.err
+			v.copied = ""
+		} else {
+			v.err = nil
+			v.copied = msg.status()
+		}
 		return v, nil
 
 	case error:
@@ -224,6 +281,10 @@ func (v *SetupWizardView) handleEnter() (tea.Model, tea.Cmd) {
 			v.err = fmt.Errorf("password is required")
 			return v, nil
 		}
+		if err := v.passwords.checkWeak(v.password.Value(), v.username.Value()); err != nil {
+			v.err = err
+			return v, nil
+		}
 		v.err = nil
 		v.password.Blur()
 		v.step = wizardStepConfirm
@@ -285,6 +346,9 @@ func (v *SetupWizardView) handleDown() (tea.Model, tea.Cmd) {
 
 func (v *SetupWizardView) handleLeft() (tea.Model, tea.Cmd) {
 	switch v.step {
+	case wizardStepComplete:
+		v.snippetIndex = (v.snippetIndex + len(v.snippets()) - 1) % len(v.snippets())
+		v.copied = ""
 	case wizardStepAdvanced:
 		// Cycle through options
 		v.hostIndex--
@@ -297,6 +361,9 @@ func (v *SetupWizardView) handleLeft() (tea.Model, tea.Cmd) {
 
 func (v *SetupWizardView) handleRight() (tea.Model, tea.Cmd) {
 	switch v.step {
+	case wizardStepComplete:
+		v.snippetIndex = (v.snippetIndex + 1) % len(v.snippets())
+		v.copied = ""
 	case wizardStepAdvanced:
 		// Cycle through options
 		v.hostIndex++
@@ -347,15 +414,40 @@ func (v *SetupWizardView) runSetup() tea.Cmd {
 	if v.collationIndex > 0 && v.collationIndex < len(v.collations) {
 		template.Collation = v.collations[v.collationIndex]
 	}
+	if v.skipBootstrap {
+		template.Bootstrap = ""
+	}
+
+	app := db.AppConnection{
+		Type:     v.conn.Config.Type,
+		Host:     v.conn.Config.Host,
+		Port:     v.conn.Config.Port,
+		Database: dbName,
+		User:     username,
+		Password: password,
+		Charset:  template.GetCharsetForDB(v.conn.Config.Type),
+	}
 
 	return func() tea.Msg {
 		if err := v.conn.SetupAppDatabase(&template, dbName, username, password, host); err != nil {
 			return err
 		}
-		return setupCompleteMsg{}
+		return setupCompleteMsg{app: app}
 	}
 }
 
+// snippets returns the connection snippets of the new database
+func (v *SetupWizardView) snippets() []db.AppSnippet {
+	return db.AppSnippets(v.app)
+}
+
+// copySnippet puts the shown snippet, with the real password, on the
+// clipboard
+func (v *SetupWizardView) copySnippet() tea.Cmd {
+	snippet := v.snippets()[v.snippetIndex]
+	return copyToClipboard(snippet.Name, snippet.Text)
+}
+
 // View renders the view
 func (v *SetupWizardView) View() string {
 	var b strings.Builder
@@ -407,16 +499,20 @@ func (v *SetupWizardView) View() string {
 	// Error display
 	if v.err != nil {
 		b.WriteString("\n")
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", v.err)))
+		b.WriteString(renderError(v.err))
 		b.WriteString("\n")
 	}
 
 	// Help
 	b.WriteString("\n")
 	if v.step == wizardStepComplete {
-		b.WriteString(helpStyle.Render("Enter: Return to databases | Esc: Return to databases"))
+		b.WriteString(helpStyle.Render("←→: Snippet | c: Copy to clipboard | Enter/Esc: Return to databases"))
+	} else if v.step == wizardStepReview && v.templates[v.templateIndex].Bootstrap != "" {
+		b.WriteString(helpStyle.Render("Enter: Create | b: Toggle bootstrap script | Esc: Back"))
 	} else if v.step == wizardStepTemplate {
-		b.WriteString(helpStyle.Render("↑↓: Select template | Enter: Next | Esc: Cancel"))
+		b.WriteString(helpStyle.Render("↑↓: Select template | Enter: Next | e: Edit templates | Esc: Cancel"))
+	} else if v.step == wizardStepPassword || v.step == wizardStepConfirm {
+		b.WriteString(helpStyle.Render("Enter: Next | Ctrl+G: Generate password | Esc: Back"))
 	} else {
 		b.WriteString(helpStyle.Render("Enter: Next | Esc: Back"))
 	}
@@ -463,6 +559,9 @@ func (v *SetupWizardView) viewTemplateStep() string {
 		privStr = privStr[:60] + "..."
 	}
 	b.WriteString(fmt.Sprintf("  Privileges: %s\n", privStr))
+	if t.Bootstrap != "" {
+		b.WriteString(fmt.Sprintf("  Bootstrap:  %s\n", t.Bootstrap))
+	}
 
 	return b.String()
 }
@@ -501,7 +600,7 @@ func (v *SetupWizardView) viewPasswordStep() string {
 	b.WriteString("\n")
 	b.WriteString(v.password.View())
 	b.WriteString("\n\n")
-	b.WriteString(mutedStyle.Render("Choose a secure password."))
+	b.WriteString(v.passwords.view(v.password.Value(), v.username.Value()))
 
 	return b.String()
 }
@@ -513,6 +612,8 @@ func (v *SetupWizardView) viewConfirmStep() string {
 	b.WriteString(focusedStyle.Render("Confirm Password:"))
 	b.WriteString("\n")
 	b.WriteString(v.confirmPass.View())
+	b.WriteString("\n\n")
+	b.WriteString(v.passwords.view(v.password.Value(), v.username.Value()))
 
 	return b.String()
 }
@@ -548,6 +649,13 @@ func (v *SetupWizardView) viewReviewStep() string {
 	if t.Collation != "" {
 		b.WriteString(fmt.Sprintf("  Collation: %s\n", t.Collation))
 	}
+	if t.Bootstrap != "" {
+		run := "[x]"
+		if v.skipBootstrap {
+			run = "[ ]"
+		}
+		b.WriteString(fmt.Sprintf("  Bootstrap: %s run %s\n", run, t.Bootstrap))
+	}
 
 	b.WriteString("\n")
 
@@ -575,5 +683,27 @@ func (v *SetupWizardView) viewCompleteStep() string {
 	b.WriteString("\n")
 	b.WriteString(fmt.Sprintf("Configured for: %s\n", t.Description))
 
+	// The password is masked on screen; copying uses the real one
+	masked := v.app
+	masked.Password = "********"
+	snippets := db.AppSnippets(masked)
+	b.WriteString("\n")
+	for i, s := range snippets {
+		if i == v.snippetIndex {
+			b.WriteString(focusedStyle.Render(fmt.Sprintf("[%s]", s.Name)))
+		} else {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf(" %s ", s.Name)))
+		}
+		b.WriteString(" ")
+	}
+	b.WriteString("\n\n")
+	b.WriteString(strings.TrimRight(snippets[v.snippetIndex].Text, "\n"))
+	b.WriteString("\n")
+	if v.copied != "" {
+		b.WriteString("\n")
+		b.WriteString(successStyle.Render(v.copied))
+		b.WriteString("\n")
+	}
+
 	return b.String()
 }
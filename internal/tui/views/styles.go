@@ -18,52 +18,122 @@
 
 package views
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/blubskye/yandere_sql_manager/internal/theme"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
 
-// Shared styles for all views
+// Shared styles for all views, set from the active theme by ApplyTheme
 var (
-	primaryColor   = lipgloss.Color("#FF69B4") // Hot pink
-	secondaryColor = lipgloss.Color("#FF1493") // Deep pink
-	accentColor    = lipgloss.Color("#FFB6C1") // Light pink
-	mutedColor     = lipgloss.Color("#888888")
-	errorColor     = lipgloss.Color("#FF4444")
-	successColor   = lipgloss.Color("#44FF44")
+	titleStyle       lipgloss.Style
+	subtitleStyle    lipgloss.Style
+	focusedStyle     lipgloss.Style
+	blurredStyle     lipgloss.Style
+	mutedStyle       lipgloss.Style
+	errorStyle       lipgloss.Style
+	successStyle     lipgloss.Style
+	warningStyle     lipgloss.Style
+	helpStyle        lipgloss.Style
+	bannerStyle      lipgloss.Style
+	selectedStyle    lipgloss.Style
+	headerStyle      lipgloss.Style
+	selectedRowStyle lipgloss.Style // The row under the cursor in hand-drawn lists
+	captureRowStyle  lipgloss.Style // A row waiting for a key press
+	highlightStyle   lipgloss.Style // Prompts for a key press
+	valueStyle       lipgloss.Style // Bold figures
+)
 
-	titleStyle = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Bold(true)
+func init() {
+	ApplyTheme()
+}
 
-	subtitleStyle = lipgloss.NewStyle().
-			Foreground(accentColor).
-			Italic(true)
+// ApplyTheme rebuilds the view styles from the active theme. Views built
+// before keep the styles they were built with.
+func ApplyTheme() {
+	s := theme.Current()
 
-	focusedStyle = lipgloss.NewStyle().
-			Foreground(primaryColor)
+	titleStyle = s.Title
+	subtitleStyle = s.Subtitle
+	focusedStyle = s.Focused
+	blurredStyle = s.Blurred
+	mutedStyle = s.MutedText
+	errorStyle = s.ErrorText
+	successStyle = s.SuccessText
+	warningStyle = s.WarningText
+	helpStyle = s.MutedText
+	bannerStyle = s.Title
+	selectedStyle = s.Focused.Bold(true)
+	headerStyle = s.Header
+	selectedRowStyle = s.Selected
+	captureRowStyle = s.Capture
+	highlightStyle = lipgloss.NewStyle().Foreground(s.Highlight).Bold(true)
+	valueStyle = s.Value
 
-	blurredStyle = lipgloss.NewStyle().
-			Foreground(mutedColor)
+	applyViewThemes(s)
+}
 
-	mutedStyle = lipgloss.NewStyle().
-			Foreground(mutedColor)
+// applyViewThemes sets the styles of single views
+func applyViewThemes(s *theme.Styles) {
+	dashboardBoxStyle = s.Box.Padding(0, 1)
+	dashboardTitleStyle = s.Title
+	dashboardValueStyle = s.Value
+	dashboardBarFull = lipgloss.NewStyle().Foreground(s.Success)
+	dashboardBarEmpty = lipgloss.NewStyle().Foreground(s.Subtle)
+	dashboardBarWarning = lipgloss.NewStyle().Foreground(s.Warning)
+	dashboardBarDanger = lipgloss.NewStyle().Foreground(s.Error)
+	dashboardSparkStyle = lipgloss.NewStyle().Foreground(s.Primary)
 
-	errorStyle = lipgloss.NewStyle().
-			Foreground(errorColor)
+	clusterBoxStyle = s.Box.Padding(0, 1)
+	clusterTitleStyle = s.Title
+	clusterHealthyStyle = s.SuccessText.Bold(true)
+	clusterUnhealthyStyle = s.ErrorText.Bold(true)
+	clusterWarningStyle = s.WarningText.Bold(true)
+	clusterNodeStyle = lipgloss.NewStyle().Foreground(s.Text)
 
-	successStyle = lipgloss.NewStyle().
-			Foreground(successColor)
+	chartStyle = lipgloss.NewStyle().Foreground(s.Primary)
+	chartNegativeStyle = lipgloss.NewStyle().Foreground(s.Error)
 
-	helpStyle = lipgloss.NewStyle().
-			Foreground(mutedColor)
+	changedStyle = s.WarningText
+}
 
-	bannerStyle = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Bold(true)
+// newListDelegate returns a list delegate drawing the selected item in the
+// theme's colors
+func newListDelegate() list.DefaultDelegate {
+	s := theme.Current()
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = s.Selected.
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(s.Primary).
+		Padding(0, 0, 0, 1)
+	delegate.Styles.SelectedDesc = s.SelectedDesc.
+		Border(lipgloss.NormalBorder(), false, false, false, true).
+		BorderForeground(s.Primary).
+		Padding(0, 0, 0, 1)
+	return delegate
+}
 
-	selectedStyle = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Bold(true)
+// newTableStyles returns table styles with the header and selected row in
+// the theme's colors
+func newTableStyles() table.Styles {
+	s := theme.Current()
+	styles := table.DefaultStyles()
+	styles.Header = styles.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(s.Primary).
+		BorderBottom(true).
+		Bold(true).
+		Foreground(s.Primary)
+	styles.Selected = s.Selected
+	return styles
+}
 
-	headerStyle = lipgloss.NewStyle().
-			Foreground(accentColor).
-			Bold(true)
-)
+// paneStyle returns the rounded border around a pane, brighter while it has
+// the focus
+func paneStyle(active bool) lipgloss.Style {
+	if active {
+		return theme.Current().ActiveBox
+	}
+	return theme.Current().Box
+}
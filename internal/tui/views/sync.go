@@ -0,0 +1,394 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type syncMode int
+
+const (
+	syncModeForm syncMode = iota
+	syncModeRunning
+	syncModeResult
+)
+
+// Sync form fields, in tab order
+const (
+	syncFieldSource = iota
+	syncFieldTarget
+	syncFieldMode
+	syncFieldDryRun
+	syncFieldCount
+)
+
+// SyncView makes a target database match a source, structure and/or data,
+// with a dry run to preview the changes first
+type SyncView struct {
+	conn *db.Connection
+	mode syncMode
+
+	source   textinput.Model
+	target   textinput.Model
+	syncMode db.SyncMode
+	dryRun   bool
+	focused  int
+	confirm  bool // Waiting for y before changing the target
+
+	progress *progressPanel
+	result   *db.SyncResult
+	ranDry   bool // The result is from a dry run
+	err      error
+
+	width  int
+	height int
+}
+
+type syncDoneMsg struct {
+	title   string
+	result  *db.SyncResult
+	elapsed time.Duration
+	err     error
+}
+
+func (m syncDoneMsg) JobResult() JobResult {
+	return JobResult{View: "sync", Title: m.title, Elapsed: m.elapsed, Err: m.err}
+}
+
+// NewSyncView creates a new sync view with database as the source
+func NewSyncView(conn *db.Connection, database string, width, height int) *SyncView {
+	source := textinput.New()
+	source.Placeholder = "Source database (wanted state)"
+	source.SetValue(database)
+	source.Width = 40
+
+	target := textinput.New()
+	target.Placeholder = "Target database (to change)"
+	target.Width = 40
+	target.Focus()
+
+	return &SyncView{
+		conn:     conn,
+		source:   source,
+		target:   target,
+		syncMode: db.SyncFull,
+		dryRun:   true,
+		focused:  syncFieldTarget,
+		width:    width,
+		height:   height,
+	}
+}
+
+// Init initializes the view
+func (v *SyncView) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (v *SyncView) run(dryRun bool) tea.Cmd {
+	opts := db.SyncOptions{
+		SourceDB: strings.TrimSpace(v.source.Value()),
+		TargetDB: strings.TrimSpace(v.target.Value()),
+		SyncMode: v.syncMode,
+		DryRun:   dryRun,
+	}
+
+	label := "Syncing"
+	if dryRun {
+		label = "Dry run"
+	}
+	v.progress = newProgressPanel(label, progress.Items, 0)
+	bar := v.progress
+	opts.OnProgress = func(table, action string) {
+		bar.SetCurrent(action+" "+table, 0, 0)
+	}
+
+	v.mode = syncModeRunning
+	v.ranDry = dryRun
+	v.confirm = false
+	v.err = nil
+
+	conn := v.conn
+	title := fmt.Sprintf("Sync of %s to %s", opts.SourceDB, opts.TargetDB)
+	if dryRun {
+		title = fmt.Sprintf("Dry run sync of %s to %s", opts.SourceDB, opts.TargetDB)
+	}
+	sync := runJob(title, bar, func() tea.Msg {
+		result, err := conn.SyncDatabases(opts)
+		return syncDoneMsg{title: title, result: result, elapsed: bar.Snapshot().Elapsed, err: err}
+	})
+	return tea.Batch(sync, progressTick())
+}
+
+func (v *SyncView) focus(field int) {
+	v.focused = field
+	v.source.Blur()
+	v.target.Blur()
+	switch field {
+	case syncFieldSource:
+		v.source.Focus()
+	case syncFieldTarget:
+		v.target.Focus()
+	}
+}
+
+// Update handles messages
+func (v *SyncView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		return v, nil
+
+	case progressTickMsg:
+		if v.mode == syncModeRunning {
+			return v, progressTick()
+		}
+		return v, nil
+
+	case syncDoneMsg:
+		v.result = msg.result
+		v.err = msg.err
+		v.mode = syncModeResult
+		return v, nil
+
+	case tea.KeyMsg:
+		switch v.mode {
+		case syncModeForm:
+			return v.updateForm(msg)
+		case syncModeResult:
+			return v.updateResult(msg)
+		}
+	}
+
+	return v, nil
+}
+
+func (v *SyncView) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if v.confirm {
+		if msg.String() == "y" {
+			return v, v.run(false)
+		}
+		v.confirm = false
+		return v, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		return v, func() tea.Msg {
+			return SwitchViewMsg{View: "databases"}
+		}
+	case "tab", "down":
+		v.focus((v.focused + 1) % syncFieldCount)
+		return v, nil
+	case "shift+tab", "up":
+		v.focus((v.focused + syncFieldCount - 1) % syncFieldCount)
+		return v, nil
+	case "enter":
+		if strings.TrimSpace(v.source.Value()) == "" || strings.TrimSpace(v.target.Value()) == "" {
+			v.err = fmt.Errorf("both databases are required")
+			return v, nil
+		}
+		if strings.TrimSpace(v.source.Value()) == strings.TrimSpace(v.target.Value()) {
+			v.err = fmt.Errorf("source and target must differ")
+			return v, nil
+		}
+		if !v.dryRun {
+			v.confirm = true
+			return v, nil
+		}
+		return v, v.run(true)
+	}
+
+	switch v.focused {
+	case syncFieldMode:
+		switch msg.String() {
+		case "left", "h":
+			v.syncMode = (v.syncMode + 2) % 3
+		case "right", "l", " ":
+			v.syncMode = (v.syncMode + 1) % 3
+		}
+		return v, nil
+	case syncFieldDryRun:
+		if msg.String() == " " {
+			v.dryRun = !v.dryRun
+		}
+		return v, nil
+	}
+
+	var cmd tea.Cmd
+	if v.focused == syncFieldSource {
+		v.source, cmd = v.source.Update(msg)
+	} else {
+		v.target, cmd = v.target.Update(msg)
+	}
+	return v, cmd
+}
+
+func (v *SyncView) updateResult(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if v.confirm {
+		if msg.String() == "y" {
+			return v, v.run(false)
+		}
+		v.confirm = false
+		return v, nil
+	}
+
+	switch msg.String() {
+	case "esc", "backspace":
+		return v, func() tea.Msg {
+			return SwitchViewMsg{View: "databases"}
+		}
+	case "q":
+		return v, tea.Quit
+	case "a":
+		if v.ranDry && v.err == nil {
+			v.confirm = true
+		}
+	case "r":
+		return v, v.run(v.ranDry)
+	case "n":
+		v.mode = syncModeForm
+		v.err = nil
+	}
+	return v, nil
+}
+
+// View renders the view
+func (v *SyncView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Sync Databases"))
+	b.WriteString("\n\n")
+
+	switch v.mode {
+	case syncModeForm:
+		b.WriteString(v.viewForm())
+	case syncModeRunning:
+		b.WriteString(v.progress.View())
+	case syncModeResult:
+		b.WriteString(v.viewResult())
+	}
+	return b.String()
+}
+
+func (v *SyncView) viewForm() string {
+	var b strings.Builder
+
+	label := func(field int, text string) string {
+		if v.focused == field {
+			return focusedStyle.Render(text)
+		}
+		return blurredStyle.Render(text)
+	}
+
+	b.WriteString(label(syncFieldSource, "Source (the state you want):"))
+	b.WriteString("\n")
+	b.WriteString(v.source.View())
+	b.WriteString("\n\n")
+	b.WriteString(label(syncFieldTarget, "Target (the database to change):"))
+	b.WriteString("\n")
+	b.WriteString(v.target.View())
+	b.WriteString("\n\n")
+	b.WriteString(label(syncFieldMode, "Sync: "))
+	b.WriteString(headerStyle.Render("< " + v.syncMode.String() + " >"))
+	b.WriteString("\n")
+	dryRun := "[ ]"
+	if v.dryRun {
+		dryRun = "[x]"
+	}
+	b.WriteString(label(syncFieldDryRun, "Dry run: "))
+	b.WriteString(dryRun)
+	if !v.dryRun {
+		b.WriteString(errorStyle.Render("  changes will be applied to the target"))
+	}
+	b.WriteString("\n\n")
+
+	if v.confirm {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Change %s to match %s? (y/n)", strings.TrimSpace(v.target.Value()), strings.TrimSpace(v.source.Value()))))
+		b.WriteString("\n\n")
+	} else if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Tab: Next field | ←→: Sync mode | Space: Toggle dry run | Enter: Start | Esc: Back"))
+	return b.String()
+}
+
+func (v *SyncView) viewResult() string {
+	var b strings.Builder
+
+	source := strings.TrimSpace(v.source.Value())
+	target := strings.TrimSpace(v.target.Value())
+	if v.ranDry {
+		b.WriteString(fmt.Sprintf("Dry run: what syncing %s to match %s would change", headerStyle.Render(target), headerStyle.Render(source)))
+	} else {
+		b.WriteString(fmt.Sprintf("Synced %s to match %s", headerStyle.Render(target), headerStyle.Render(source)))
+	}
+	b.WriteString(mutedStyle.Render("  (" + v.syncMode.String() + ")"))
+	b.WriteString("\n\n")
+
+	if r := v.result; r != nil {
+		tables := func(title string, names []string) {
+			b.WriteString(fmt.Sprintf("%-16s %d", title, len(names)))
+			if len(names) > 0 {
+				list := strings.Join(names, ", ")
+				if limit := max(v.width-24, 20); len(list) > limit {
+					list = list[:limit-3] + "..."
+				}
+				b.WriteString(mutedStyle.Render("  " + list))
+			}
+			b.WriteString("\n")
+		}
+		tables("Tables created", r.TablesCreated)
+		tables("Tables modified", r.TablesModified)
+		tables("Tables skipped", r.TablesSkipped)
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("%-16s %d\n", "Rows inserted", r.RowsInserted))
+		b.WriteString(fmt.Sprintf("%-16s %d\n", "Rows updated", r.RowsUpdated))
+		b.WriteString(fmt.Sprintf("%-16s %d\n", "Rows deleted", r.RowsDeleted))
+		b.WriteString("\n")
+	}
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	} else if v.confirm {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Apply these changes to %s? (y/n)", target)))
+		b.WriteString("\n\n")
+	} else if !v.ranDry {
+		b.WriteString(successStyle.Render("Sync complete"))
+		b.WriteString("\n\n")
+	}
+
+	help := "n: New sync | r: Run again | Esc: Back | q: Quit"
+	if v.ranDry && v.err == nil {
+		help = "a: Apply | " + help
+	}
+	b.WriteString(helpStyle.Render(help))
+	return b.String()
+}
@@ -0,0 +1,592 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type detailMode int
+
+const (
+	detailModeNormal detailMode = iota
+	detailModeMaintenance
+	detailModeConfirm
+	detailModeRunning
+)
+
+// TableDetailView shows a table's columns, its relationships and
+// constraints, and its statistics, and runs maintenance on it
+type TableDetailView struct {
+	conn        *db.Connection
+	database    string
+	table       string
+	columns     []db.Column
+	constraints *db.TableConstraints
+	security    *db.RowSecurity // nil when the server has no row-level security
+	health      *db.TableHealth
+	links       []db.ForeignKey // Outgoing then incoming keys, in display order
+	cursor      int
+	loading     bool
+	err         error
+	status      string // Result of the last copy
+	width       int
+	height      int
+
+	// Maintenance
+	mode      detailMode
+	actions   []db.MaintenanceAction // Those the server supports
+	action    int                    // Selected in the menu
+	progress  *progressPanel
+	result    *db.MaintenanceResult // Last run, shown until the next one
+	resultErr error
+}
+
+type tableDetailLoadedMsg struct {
+	columns     []db.Column
+	constraints *db.TableConstraints
+	security    *db.RowSecurity
+	health      *db.TableHealth
+	actions     []db.MaintenanceAction
+	err         error
+}
+
+type maintenanceDoneMsg struct {
+	title   string
+	result  *db.MaintenanceResult
+	elapsed time.Duration
+	err     error
+}
+
+func (m maintenanceDoneMsg) JobResult() JobResult {
+	return JobResult{View: "maintenance", Title: m.title, Elapsed: m.elapsed, Err: m.err}
+}
+
+// NewTableDetailView creates a new table detail view
+func NewTableDetailView(conn *db.Connection, database, table string, width, height int) *TableDetailView {
+	return &TableDetailView{
+		conn:     conn,
+		database: database,
+		table:    table,
+		loading:  true,
+		width:    width,
+		height:   height,
+	}
+}
+
+// Init initializes the view
+func (v *TableDetailView) Init() tea.Cmd {
+	return v.load
+}
+
+func (v *TableDetailView) load() tea.Msg {
+	if err := v.conn.UseDatabase(v.database); err != nil {
+		return tableDetailLoadedMsg{err: err}
+	}
+	columns, err := v.conn.DescribeTable(v.table)
+	if err != nil {
+		return tableDetailLoadedMsg{err: err}
+	}
+	constraints, err := v.conn.GetTableConstraints(v.table)
+	if err != nil {
+		return tableDetailLoadedMsg{columns: columns, err: err}
+	}
+	security, err := v.conn.GetRowSecurity(v.table)
+	if err != nil {
+		return tableDetailLoadedMsg{columns: columns, constraints: constraints, err: err}
+	}
+	health, err := v.conn.GetTableHealth(v.table)
+	return tableDetailLoadedMsg{columns: columns, constraints: constraints, security: security, health: health,
+		actions: v.conn.MaintenanceActions(v.table), err: err}
+}
+
+// runMaintenance runs the selected maintenance action on a connection of
+// its own, with the progress the server reports
+func (v *TableDetailView) runMaintenance() tea.Cmd {
+	action := v.actions[v.action]
+	v.mode = detailModeRunning
+	v.result, v.resultErr = nil, nil
+	v.progress = newProgressPanel(action.Method+" "+v.table, progress.Percent, 0)
+	panel := v.progress
+	ctx, _ := panel.cancellable()
+	conn, database := v.conn, v.database
+	title := action.Method + " of " + v.database + "." + v.table
+
+	run := runJob(title, panel, func() tea.Msg {
+		jobConn, release := jobConnection(conn)
+		defer release()
+
+		start := time.Now()
+		if err := jobConn.UseDatabase(database); err != nil {
+			return maintenanceDoneMsg{title: title, elapsed: time.Since(start), err: err}
+		}
+		result, err := jobConn.RunMaintenance(ctx, action, func(p db.MaintenanceProgress) {
+			if p.Percent >= 0 {
+				panel.SetTotal(100)
+				panel.Set(int64(p.Percent))
+			}
+			panel.SetCurrent(p.Phase, 0, 0)
+		})
+		return maintenanceDoneMsg{title: title, result: result, elapsed: time.Since(start), err: err}
+	})
+	return tea.Batch(run, progressTick())
+}
+
+// ownsJob reports whether the view started the job of p
+func (v *TableDetailView) ownsJob(p *progressPanel) bool {
+	return p == v.progress
+}
+
+// linkTarget returns the table on the other end of a relationship
+func (v *TableDetailView) linkTarget(fk db.ForeignKey) string {
+	if fk.Table == v.table {
+		return fk.RefTable
+	}
+	return fk.Table
+}
+
+// Update handles messages
+func (v *TableDetailView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		return v, nil
+
+	case tableDetailLoadedMsg:
+		v.loading = false
+		v.err = msg.err
+		v.columns = msg.columns
+		v.constraints = msg.constraints
+		v.security = msg.security
+		v.health = msg.health
+		v.actions = msg.actions
+		v.links = nil
+		if msg.constraints != nil {
+			v.links = append(v.links, msg.constraints.ForeignKeys...)
+			v.links = append(v.links, msg.constraints.ReferencedBy...)
+		}
+		if v.cursor >= len(v.links) {
+			v.cursor = 0
+		}
+		return v, nil
+
+	case progressTickMsg:
+		if v.mode == detailModeRunning {
+			return v, progressTick()
+		}
+		return v, nil
+
+	case maintenanceDoneMsg:
+		v.mode = detailModeNormal
+		v.result = msg.result
+		v.resultErr = msg.err
+		v.loading = true
+		return v, v.load
+
+	case clipboardCopiedMsg:
+		if msg.err != nil {
+			v.err = msg.err
+		} else {
+			v.status = msg.status()
+		}
+		return v, nil
+
+	case tea.KeyMsg:
+		switch v.mode {
+		case detailModeMaintenance, detailModeConfirm:
+			return v.updateMaintenance(msg)
+		case detailModeRunning:
+			return v, v.progress.runningKey(msg.String())
+		}
+
+		v.status = ""
+		switch msg.String() {
+		case "m":
+			if len(v.actions) > 0 {
+				v.mode = detailModeMaintenance
+			}
+		case "esc", "backspace":
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "tables", Database: v.database}
+			}
+		case "q":
+			return v, tea.Quit
+		case "up", "k":
+			if v.cursor > 0 {
+				v.cursor--
+			}
+		case "down", "j":
+			if v.cursor < len(v.links)-1 {
+				v.cursor++
+			}
+		case "enter":
+			// Follow the selected relationship to the other table's data
+			if v.cursor < len(v.links) {
+				target := v.linkTarget(v.links[v.cursor])
+				return v, func() tea.Msg {
+					return SwitchViewMsg{View: "browser", Database: v.database, Table: target}
+				}
+			}
+		case "g":
+			if v.cursor < len(v.links) {
+				target := v.linkTarget(v.links[v.cursor])
+				return v, func() tea.Msg {
+					return SwitchViewMsg{View: "detail", Database: v.database, Table: target}
+				}
+			}
+		case "b":
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "browser", Database: v.database, Table: v.table}
+			}
+		case "y":
+			return v, v.copyCreateTable()
+		case "r":
+			v.loading = true
+			return v, v.load
+		}
+	}
+
+	return v, nil
+}
+
+// copyCreateTable puts the table's CREATE TABLE statement on the clipboard
+func (v *TableDetailView) copyCreateTable() tea.Cmd {
+	conn, database, table := v.conn, v.database, v.table
+	return func() tea.Msg {
+		if err := conn.UseDatabase(database); err != nil {
+			return clipboardCopiedMsg{err: err}
+		}
+		create, err := conn.GetCreateTable(table)
+		if err != nil {
+			return clipboardCopiedMsg{err: err}
+		}
+		return copyToClipboard("the CREATE TABLE statement", create+";")()
+	}
+}
+
+// updateMaintenance handles the maintenance menu and its confirm
+func (v *TableDetailView) updateMaintenance(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if v.mode == detailModeConfirm {
+		switch msg.String() {
+		case "y", "Y":
+			return v, v.runMaintenance()
+		case "n", "N", "esc":
+			v.mode = detailModeMaintenance
+		}
+		return v, nil
+	}
+
+	switch msg.String() {
+	case "esc", "m":
+		v.mode = detailModeNormal
+	case "up", "k":
+		if v.action > 0 {
+			v.action--
+		}
+	case "down", "j":
+		if v.action < len(v.actions)-1 {
+			v.action++
+		}
+	case "enter":
+		v.mode = detailModeConfirm
+	}
+	return v, nil
+}
+
+// View renders the view
+func (v *TableDetailView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Table: %s.%s", v.database, v.table)))
+	b.WriteString("\n\n")
+
+	switch v.mode {
+	case detailModeRunning:
+		b.WriteString(v.progress.View())
+		b.WriteString("\n\n")
+		b.WriteString(v.progress.runningHelp())
+		return b.String()
+	case detailModeMaintenance, detailModeConfirm:
+		b.WriteString(v.renderMaintenance())
+		return b.String()
+	}
+
+	if v.loading {
+		b.WriteString("Loading table details...\n")
+		return b.String()
+	}
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	if v.result != nil || v.resultErr != nil {
+		b.WriteString(v.renderMaintenanceResult())
+		b.WriteString("\n")
+	}
+	if v.health != nil {
+		b.WriteString(v.renderHealth())
+		b.WriteString("\n")
+	}
+
+	b.WriteString(headerStyle.Render(fmt.Sprintf("  %-28s %-24s %-5s %-5s %s", "Column", "Type", "Null", "Key", "Default")))
+	b.WriteString("\n")
+	for _, col := range v.columns {
+		dflt := ""
+		if col.Default != nil {
+			dflt = *col.Default
+		}
+		b.WriteString(fmt.Sprintf("  %-28s %-24s %-5s %-5s %s\n", col.Field, col.Type, col.Null, col.Key, dflt))
+	}
+	b.WriteString("\n")
+
+	if tc := v.constraints; tc != nil {
+		i := 0
+		b.WriteString(headerStyle.Render("Foreign keys"))
+		b.WriteString("\n")
+		if len(tc.ForeignKeys) == 0 {
+			b.WriteString(mutedStyle.Render("  none"))
+			b.WriteString("\n")
+		}
+		for _, fk := range tc.ForeignKeys {
+			b.WriteString(v.renderLink(i, fmt.Sprintf("(%s) → %s(%s)",
+				strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", ")), fk))
+			i++
+		}
+		b.WriteString("\n")
+
+		b.WriteString(headerStyle.Render("Referenced by"))
+		b.WriteString("\n")
+		if len(tc.ReferencedBy) == 0 {
+			b.WriteString(mutedStyle.Render("  none"))
+			b.WriteString("\n")
+		}
+		for _, fk := range tc.ReferencedBy {
+			b.WriteString(v.renderLink(i, fmt.Sprintf("%s(%s) → (%s)",
+				fk.Table, strings.Join(fk.Columns, ", "), strings.Join(fk.RefColumns, ", ")), fk))
+			i++
+		}
+		b.WriteString("\n")
+
+		b.WriteString(headerStyle.Render("Unique constraints"))
+		b.WriteString("\n")
+		if len(tc.Uniques) == 0 {
+			b.WriteString(mutedStyle.Render("  none"))
+			b.WriteString("\n")
+		}
+		for _, u := range tc.Uniques {
+			b.WriteString(fmt.Sprintf("  %s (%s)\n", u.Name, strings.Join(u.Columns, ", ")))
+		}
+		b.WriteString("\n")
+
+		b.WriteString(headerStyle.Render("Check constraints"))
+		b.WriteString("\n")
+		if len(tc.Checks) == 0 {
+			b.WriteString(mutedStyle.Render("  none"))
+			b.WriteString("\n")
+		}
+		for _, cc := range tc.Checks {
+			b.WriteString(fmt.Sprintf("  %s: %s\n", cc.Name, cc.Clause))
+		}
+		b.WriteString("\n")
+	}
+
+	if v.security != nil {
+		b.WriteString(v.renderRowSecurity())
+		b.WriteString("\n")
+	}
+
+	if v.status != "" {
+		b.WriteString(successStyle.Render(v.status))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render("↑/↓: Select relationship | Enter: Browse related table | g: Go to its details | b: Browse this table | y: Copy CREATE TABLE | m: Maintenance | r: Refresh | Esc: Back"))
+
+	return b.String()
+}
+
+// renderHealth renders the table's size, statistics age and bloat
+func (v *TableDetailView) renderHealth() string {
+	var b strings.Builder
+	h := v.health
+
+	b.WriteString(headerStyle.Render("Statistics"))
+	b.WriteString("\n")
+	line := fmt.Sprintf("  ~%d rows  Data %s  Indexes %s", h.Rows, db.FormatSize(h.DataSize), db.FormatSize(h.IndexSize))
+	if h.DeadRows >= 0 {
+		line += fmt.Sprintf("  Dead rows %d", h.DeadRows)
+	}
+	b.WriteString(line + "\n")
+
+	when := func(t time.Time) string {
+		if t.IsZero() {
+			return mutedStyle.Render("never or unknown")
+		}
+		return t.Local().Format("2006-01-02 15:04")
+	}
+	line = "  Last analyze " + when(h.LastAnalyze)
+	if v.conn.Config.Type == db.DatabaseTypePostgres {
+		line += "  Last vacuum " + when(h.LastVacuum)
+	}
+	b.WriteString(line + "\n")
+
+	if len(h.Bloat) == 0 {
+		b.WriteString(mutedStyle.Render("  No bloat estimate"))
+		b.WriteString("\n")
+	}
+	for _, e := range h.Bloat {
+		name := "table"
+		if e.Index != "" {
+			name = e.Index
+		}
+		line := fmt.Sprintf("  %-28s %s of %s wasted (%.0f%%)", name, db.FormatSize(e.Wasted), db.FormatSize(e.Size), e.Percent())
+		if e.Percent() >= 20 {
+			b.WriteString(warningStyle.Render(line))
+		} else {
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderMaintenance renders the maintenance menu, or the confirm of the
+// selected action
+func (v *TableDetailView) renderMaintenance() string {
+	var b strings.Builder
+	selected := v.actions[v.action]
+
+	if v.mode == detailModeConfirm {
+		b.WriteString(headerStyle.Render("Run " + selected.Method + "?"))
+		b.WriteString("\n\n")
+		b.WriteString(selected.Statement)
+		b.WriteString("\n\n")
+		b.WriteString(warningStyle.Render("⚠ " + selected.Warning))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("y: Run | n/Esc: Back"))
+		return b.String()
+	}
+
+	b.WriteString(headerStyle.Render("Maintenance"))
+	b.WriteString("\n\n")
+	for i, a := range v.actions {
+		if i == v.action {
+			b.WriteString(selectedStyle.Render("> " + a.Method))
+		} else {
+			b.WriteString("  " + a.Method)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render(selected.Warning))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("↑/↓: Select | Enter: Run | Esc: Back"))
+	return b.String()
+}
+
+// renderMaintenanceResult renders how the last maintenance run went
+func (v *TableDetailView) renderMaintenanceResult() string {
+	var b strings.Builder
+	if v.resultErr != nil {
+		b.WriteString(renderError(v.resultErr))
+		b.WriteString("\n")
+	} else {
+		r := v.result
+		b.WriteString(successStyle.Render(fmt.Sprintf("✓ %s finished in %s", r.Action.Method, progress.FormatDuration(r.Elapsed))))
+		b.WriteString("\n")
+	}
+	if v.result != nil {
+		for _, m := range v.result.Messages {
+			b.WriteString(mutedStyle.Render("  " + m))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// renderLink renders one relationship line, highlighted when selected
+func (v *TableDetailView) renderLink(i int, desc string, fk db.ForeignKey) string {
+	rules := mutedStyle.Render(fmt.Sprintf("  ON UPDATE %s ON DELETE %s", fk.OnUpdate, fk.OnDelete))
+	line := fmt.Sprintf("%s  %s", fk.Name, desc)
+	if i == v.cursor {
+		return selectedStyle.Render("> "+line) + rules + "\n"
+	}
+	return "  " + line + rules + "\n"
+}
+
+// renderRowSecurity renders whether row-level security applies and the
+// table's policies
+func (v *TableDetailView) renderRowSecurity() string {
+	var b strings.Builder
+	rs := v.security
+
+	b.WriteString(headerStyle.Render("Row-level security"))
+	b.WriteString("\n")
+	switch {
+	case !rs.Enabled:
+		b.WriteString(mutedStyle.Render("  disabled"))
+	case rs.Forced:
+		b.WriteString("  enabled, forced (applies to the owner too)")
+	default:
+		b.WriteString("  enabled")
+	}
+	b.WriteString("\n")
+
+	for _, p := range rs.Policies {
+		kind := "permissive"
+		if !p.Permissive {
+			kind = "restrictive"
+		}
+		b.WriteString(fmt.Sprintf("  %s  %s %s", p.Name, p.Command, kind))
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  TO %s", strings.Join(p.Roles, ", "))))
+		b.WriteString("\n")
+		if p.Using != "" {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("    USING (%s)", p.Using)))
+			b.WriteString("\n")
+		}
+		if p.WithCheck != "" {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("    WITH CHECK (%s)", p.WithCheck)))
+			b.WriteString("\n")
+		}
+	}
+	if rs.Enabled && len(rs.Policies) == 0 {
+		b.WriteString(mutedStyle.Render("  no policies"))
+		b.WriteString("\n")
+	}
+
+	if warning := rs.Warning(); warning != "" {
+		b.WriteString(focusedStyle.Render("  ⚠ " + warning))
+		b.WriteString("\n")
+	} else if rs.Enabled && rs.Bypass {
+		b.WriteString(mutedStyle.Render("  This role bypasses row-level security and sees every row"))
+		b.WriteString("\n")
+	} else if rs.Enabled {
+		b.WriteString(mutedStyle.Render("  This role owns the table and sees every row"))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
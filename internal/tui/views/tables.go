@@ -22,21 +22,22 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
 // TablesView shows the list of tables in a database
 type TablesView struct {
-	conn     *db.Connection
-	database string
-	list     list.Model
-	tables   []db.Table
-	width    int
-	height   int
-	err      error
+	conn        *db.Connection
+	database    string
+	list        list.Model
+	tables      []db.Table
+	keybindings *config.KeyBindings
+	width       int
+	height      int
+	err         error
 }
 
 type tableItem struct {
@@ -51,14 +52,7 @@ func (i tableItem) FilterValue() string { return i.name }
 
 // NewTablesView creates a new tables view
 func NewTablesView(conn *db.Connection, database string, width, height int) *TablesView {
-	delegate := list.NewDefaultDelegate()
-	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#FF69B4")).
-		Bold(true)
-	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
-		Foreground(lipgloss.Color("#FFB6C1")).
-		Background(lipgloss.Color("#FF69B4"))
+	delegate := newListDelegate()
 
 	l := list.New([]list.Item{}, delegate, width, height-4)
 	l.Title = fmt.Sprintf("Tables in %s", database)
@@ -67,11 +61,12 @@ func NewTablesView(conn *db.Connection, database string, width, height int) *Tab
 	l.Styles.Title = titleStyle
 
 	return &TablesView{
-		conn:     conn,
-		database: database,
-		list:     l,
-		width:    width,
-		height:   height,
+		conn:        conn,
+		database:    database,
+		list:        l,
+		keybindings: loadKeyBindings(),
+		width:       width,
+		height:      height,
 	}
 }
 
@@ -96,6 +91,11 @@ func (v *TablesView) loadTables() tea.Msg {
 func (v *TablesView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if !v.list.SettingFilter() {
+			if cmd := showHelp(v.keybindings, "tables", msg.String()); cmd != nil {
+				return v, cmd
+			}
+		}
 		switch msg.String() {
 		case "enter":
 			if item, ok := v.list.SelectedItem().(tableItem); ok {
@@ -121,11 +121,23 @@ func (v *TablesView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if !v.list.SettingFilter() {
 				if item, ok := v.list.SelectedItem().(tableItem); ok {
 					return v, func() tea.Msg {
-						cols, err := v.conn.DescribeTable(item.name)
-						if err != nil {
-							return err
+						return SwitchViewMsg{
+							View:     "detail",
+							Database: v.database,
+							Table:    item.name,
+						}
+					}
+				}
+			}
+		case "t":
+			if !v.list.SettingFilter() {
+				if item, ok := v.list.SelectedItem().(tableItem); ok {
+					return v, func() tea.Msg {
+						return SwitchViewMsg{
+							View:     "transfer",
+							Database: v.database,
+							Table:    item.name,
 						}
-						return describeResult{table: item.name, columns: cols}
 					}
 				}
 			}
@@ -142,6 +154,60 @@ func (v *TablesView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if !v.list.SettingFilter() {
 				return v, v.loadTables
 			}
+		case "n":
+			if !v.list.SettingFilter() {
+				return v, func() tea.Msg {
+					return SwitchViewMsg{View: "designer", Database: v.database}
+				}
+			}
+		case "i":
+			if !v.list.SettingFilter() {
+				if item, ok := v.list.SelectedItem().(tableItem); ok {
+					return v, func() tea.Msg {
+						return SwitchViewMsg{
+							View:     "indexes",
+							Database: v.database,
+							Table:    item.name,
+						}
+					}
+				}
+			}
+		case "b":
+			if !v.list.SettingFilter() {
+				return v, func() tea.Msg {
+					return SwitchViewMsg{View: "bloat", Database: v.database}
+				}
+			}
+		case "A":
+			if !v.list.SettingFilter() {
+				return v, func() tea.Msg {
+					return SwitchViewMsg{View: "advisor", Database: v.database}
+				}
+			}
+		case "o":
+			if !v.list.SettingFilter() {
+				if item, ok := v.list.SelectedItem().(tableItem); ok {
+					return v, func() tea.Msg {
+						return SwitchViewMsg{
+							View:     "rebuild",
+							Database: v.database,
+							Table:    item.name,
+						}
+					}
+				}
+			}
+		case "a":
+			if !v.list.SettingFilter() {
+				if item, ok := v.list.SelectedItem().(tableItem); ok {
+					return v, func() tea.Msg {
+						return SwitchViewMsg{
+							View:     "designer",
+							Database: v.database,
+							Table:    item.name,
+						}
+					}
+				}
+			}
 		}
 
 	case tea.WindowSizeMsg:
@@ -158,11 +224,6 @@ func (v *TablesView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		v.list.SetItems(items)
 		return v, nil
 
-	case describeResult:
-		// Show table structure in a popup or message
-		// For now, just show in status
-		return v, nil
-
 	case error:
 		v.err = msg
 		return v, nil
@@ -173,23 +234,19 @@ func (v *TablesView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return v, cmd
 }
 
-type describeResult struct {
-	table   string
-	columns []db.Column
-}
-
 // View renders the view
 func (v *TablesView) View() string {
 	var b strings.Builder
 
 	if v.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", v.err)))
+		b.WriteString(renderError(v.err))
 		b.WriteString("\n\n")
 	}
 
 	b.WriteString(v.list.View())
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Enter: Browse | d: Describe | s: SQL | r: Refresh | Esc: Back | q: Quit"))
+	b.WriteString(helpStyle.Render(fmt.Sprintf("Enter: Browse | d: Details | s: SQL | n: New table | a: Alter | i: Indexes | b: Bloat | A: Advisor | o: Online rebuild | t: Transfer | r: Refresh | %s: Help | Esc: Back | q: Quit",
+		v.keybindings.GetKey("tables", config.ActionHelp))))
 
 	return b.String()
 }
@@ -0,0 +1,419 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/config"
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Template form fields, in tab order
+const (
+	templateFieldName = iota
+	templateFieldDescription
+	templateFieldCharset
+	templateFieldCollation
+	templateFieldPrivileges
+	templateFieldBootstrap
+	templateFieldCount
+)
+
+// TemplatesView edits the custom application templates in templates.yaml,
+// listed with the built-in ones they add to or replace
+type TemplatesView struct {
+	lib       *config.TemplateLibrary
+	templates []db.AppTemplate // Built-in and custom, as the setup wizard lists them
+	cursor    int
+
+	editing  bool
+	original string // Name of the edited custom template, empty for a new one
+	inputs   []textinput.Model
+	focused  int
+
+	confirm bool // Waiting for y before deleting
+	message string
+	err     error
+
+	width  int
+	height int
+}
+
+// NewTemplatesView creates a new template editor
+func NewTemplatesView(width, height int) *TemplatesView {
+	v := &TemplatesView{
+		inputs: make([]textinput.Model, templateFieldCount),
+		width:  width,
+		height: height,
+	}
+
+	placeholders := map[int]string{
+		templateFieldName:        "ghost",
+		templateFieldDescription: "(optional) Ghost blogging platform",
+		templateFieldCharset:     "utf8mb4",
+		templateFieldCollation:   "(optional) utf8mb4_unicode_ci",
+		templateFieldPrivileges:  "comma separated, e.g. SELECT, INSERT, UPDATE",
+		templateFieldBootstrap:   "(optional) SQL script, relative to the config dir",
+	}
+	for field, placeholder := range placeholders {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.Width = 50
+		v.inputs[field] = input
+	}
+
+	v.reload()
+	return v
+}
+
+// Init initializes the view
+func (v *TemplatesView) Init() tea.Cmd {
+	return nil
+}
+
+// reload reads templates.yaml again and lists the merged templates
+func (v *TemplatesView) reload() {
+	lib, err := config.LoadTemplateLibrary()
+	if err != nil {
+		v.err = err
+		lib = &config.TemplateLibrary{}
+	}
+	v.lib = lib
+	v.refresh()
+}
+
+// refresh lists the built-in templates merged with the library's
+func (v *TemplatesView) refresh() {
+	v.templates = db.MergeTemplates(db.DefaultTemplates(), v.lib.Templates)
+	v.cursor = min(v.cursor, max(len(v.templates)-1, 0))
+}
+
+func (v *TemplatesView) selected() *db.AppTemplate {
+	if v.cursor < 0 || v.cursor >= len(v.templates) {
+		return nil
+	}
+	return &v.templates[v.cursor]
+}
+
+// openForm edits a template. Editing a built-in one saves a custom copy
+// that replaces it.
+func (v *TemplatesView) openForm(original string, t db.AppTemplate) {
+	v.editing = true
+	v.original = original
+	v.err = nil
+	v.message = ""
+
+	values := map[int]string{
+		templateFieldName:        t.Name,
+		templateFieldDescription: t.Description,
+		templateFieldCharset:     t.Charset,
+		templateFieldCollation:   t.Collation,
+		templateFieldPrivileges:  strings.Join(t.Privileges, ", "),
+		templateFieldBootstrap:   t.Bootstrap,
+	}
+	for field, value := range values {
+		v.inputs[field].SetValue(value)
+	}
+	v.focus(templateFieldName)
+}
+
+func (v *TemplatesView) focus(field int) {
+	v.focused = field
+	for i := range v.inputs {
+		v.inputs[i].Blur()
+	}
+	v.inputs[field].Focus()
+}
+
+// submit validates the form and saves the template
+func (v *TemplatesView) submit() {
+	value := func(field int) string {
+		return strings.TrimSpace(v.inputs[field].Value())
+	}
+
+	var privileges []string
+	for _, priv := range strings.Split(value(templateFieldPrivileges), ",") {
+		if priv = strings.ToUpper(strings.Join(strings.Fields(priv), " ")); priv != "" {
+			privileges = append(privileges, priv)
+		}
+	}
+	t := db.AppTemplate{
+		Name:        value(templateFieldName),
+		Description: value(templateFieldDescription),
+		Charset:     value(templateFieldCharset),
+		Collation:   value(templateFieldCollation),
+		Privileges:  privileges,
+		Bootstrap:   value(templateFieldBootstrap),
+	}
+	if err := v.lib.Put(v.original, t); err != nil {
+		v.err = err
+		return
+	}
+	if err := v.lib.Save(); err != nil {
+		v.err = fmt.Errorf("failed to save templates: %w", err)
+		return
+	}
+
+	v.editing = false
+	v.err = nil
+	v.refresh()
+	for i := range v.templates {
+		if v.templates[i].Name == t.Name {
+			v.cursor = i
+		}
+	}
+	v.message = fmt.Sprintf("Saved template '%s'", t.Name)
+}
+
+// Update handles messages
+func (v *TemplatesView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		return v, nil
+
+	case tea.KeyMsg:
+		if v.editing {
+			return v.updateForm(msg)
+		}
+		return v.updateList(msg)
+	}
+
+	return v, nil
+}
+
+func (v *TemplatesView) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if v.confirm {
+		v.confirm = false
+		if msg.String() == "y" {
+			name := v.selected().Name
+			v.lib.Remove(name)
+			if err := v.lib.Save(); err != nil {
+				v.err = fmt.Errorf("failed to save templates: %w", err)
+				return v, nil
+			}
+			v.refresh()
+			v.message = fmt.Sprintf("Deleted template '%s'", name)
+		}
+		return v, nil
+	}
+
+	t := v.selected()
+	switch msg.String() {
+	case "up", "k":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down", "j":
+		if v.cursor < len(v.templates)-1 {
+			v.cursor++
+		}
+	case "n":
+		v.openForm("", db.AppTemplate{Charset: "utf8mb4", Privileges: []string{"ALL PRIVILEGES"}})
+		return v, textinput.Blink
+	case "enter", "e":
+		if t != nil {
+			original := ""
+			if t.Custom {
+				original = t.Name
+			}
+			v.openForm(original, *t)
+			return v, textinput.Blink
+		}
+	case "c":
+		if t != nil {
+			copied := *t
+			copied.Name += "-copy"
+			v.openForm("", copied)
+			return v, textinput.Blink
+		}
+	case "d":
+		if t != nil && t.Custom {
+			v.err = nil
+			v.message = ""
+			v.confirm = true
+		} else if t != nil {
+			v.err = fmt.Errorf("%s is built in; only custom templates can be deleted", t.Name)
+		}
+	case "r":
+		v.err = nil
+		v.message = ""
+		v.reload()
+	case "esc", "backspace":
+		return v, func() tea.Msg {
+			return SwitchViewMsg{View: "setup"}
+		}
+	case "q":
+		return v, tea.Quit
+	}
+	return v, nil
+}
+
+func (v *TemplatesView) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		v.editing = false
+		v.err = nil
+		return v, nil
+	case "tab", "down":
+		v.focus((v.focused + 1) % templateFieldCount)
+		return v, nil
+	case "shift+tab", "up":
+		v.focus((v.focused + templateFieldCount - 1) % templateFieldCount)
+		return v, nil
+	case "enter":
+		v.submit()
+		return v, nil
+	}
+
+	var cmd tea.Cmd
+	v.inputs[v.focused], cmd = v.inputs[v.focused].Update(msg)
+	return v, cmd
+}
+
+// View renders the view
+func (v *TemplatesView) View() string {
+	var b strings.Builder
+
+	if v.editing {
+		title := "New Template"
+		if v.original != "" {
+			title = "Edit Template: " + v.original
+		}
+		b.WriteString(titleStyle.Render(title))
+		b.WriteString("\n\n")
+		b.WriteString(v.viewForm())
+		return b.String()
+	}
+
+	b.WriteString(titleStyle.Render("Application Templates"))
+	b.WriteString("\n\n")
+	b.WriteString(v.viewList())
+	b.WriteString("\n")
+
+	if path, err := config.TemplatesPath(); err == nil {
+		b.WriteString(mutedStyle.Render("Custom templates are saved in " + path))
+		b.WriteString("\n\n")
+	}
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	} else if v.confirm {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Delete template '%s'? (y/n)", v.selected().Name)))
+		b.WriteString("\n\n")
+	} else if v.message != "" {
+		b.WriteString(successStyle.Render(v.message))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("↑↓: Navigate | n: New | Enter/e: Edit | c: Copy | d: Delete | r: Reload | Esc: Back"))
+	return b.String()
+}
+
+func (v *TemplatesView) viewList() string {
+	var b strings.Builder
+
+	visible := max(v.height-14, 5)
+	start := 0
+	if v.cursor >= visible {
+		start = v.cursor - visible + 1
+	}
+	end := min(start+visible, len(v.templates))
+
+	for i := start; i < end; i++ {
+		t := v.templates[i]
+		source := "built-in"
+		if t.Custom {
+			source = "custom"
+		}
+		line := fmt.Sprintf("%-20s %-8s %-10s %s", truncateRunes(t.Name, 20), source, t.Charset, t.Description)
+		if t.Bootstrap != "" {
+			line += "  +bootstrap"
+		}
+		line = truncateRunes(line, max(v.width-4, 40))
+
+		if i == v.cursor {
+			b.WriteString(selectedStyle.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("%d templates, %d custom", len(v.templates), len(v.lib.Templates))))
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (v *TemplatesView) viewForm() string {
+	var b strings.Builder
+
+	label := func(field int, text string) string {
+		if v.focused == field {
+			return focusedStyle.Render(text)
+		}
+		return blurredStyle.Render(text)
+	}
+
+	fields := []struct {
+		field int
+		text  string
+	}{
+		{templateFieldName, "Name:"},
+		{templateFieldDescription, "Description:"},
+		{templateFieldCharset, "Charset:"},
+		{templateFieldCollation, "Collation:"},
+		{templateFieldPrivileges, "Privileges:"},
+		{templateFieldBootstrap, "Bootstrap:"},
+	}
+	for _, f := range fields {
+		b.WriteString(label(f.field, f.text))
+		b.WriteString(" ")
+		b.WriteString(v.inputs[f.field].View())
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render("Privileges: " + strings.Join(db.CommonPrivileges(), ", ")))
+	b.WriteString("\n\n")
+
+	if v.original == "" && v.isBuiltin(strings.TrimSpace(v.inputs[templateFieldName].Value())) {
+		b.WriteString(warningStyle.Render("Saving replaces the built-in template of this name"))
+		b.WriteString("\n\n")
+	}
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Tab: Next field | Enter: Save | Esc: Cancel"))
+	return b.String()
+}
+
+// isBuiltin reports whether name is one of the built-in templates
+func (v *TemplatesView) isBuiltin(name string) bool {
+	_, err := db.GetTemplate(name)
+	return err == nil
+}
@@ -0,0 +1,223 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TimelineView shows a profile's notable events, newest first: restarts,
+// replication errors, backups, snapshots and the operations YSM ran
+type TimelineView struct {
+	conn    *db.Connection
+	profile string // Profile name, or host when connected without one
+	width   int
+	height  int
+
+	events   []db.TimelineEvent // Newest first
+	cursor   int
+	kind     string // Only events of this kind, "" for all
+	loading  bool
+	checkErr error // Checking the server failed; what was recorded still shows
+	err      error
+	message  string
+}
+
+type timelineLoadedMsg struct {
+	events   []db.TimelineEvent
+	observed int // New events the check recorded
+	checkErr error
+	err      error
+}
+
+// NewTimelineView creates a new timeline view
+func NewTimelineView(conn *db.Connection, profile string, width, height int) *TimelineView {
+	return &TimelineView{conn: conn, profile: profile, width: width, height: height, loading: true}
+}
+
+// Init checks the server and loads the timeline
+func (v *TimelineView) Init() tea.Cmd {
+	return v.load
+}
+
+// load records restarts and replication changes since the last check, then
+// reads the profile's events
+func (v *TimelineView) load() tea.Msg {
+	var msg timelineLoadedMsg
+	observed, err := v.conn.ObserveTimeline(v.profile)
+	msg.observed, msg.checkErr = len(observed), err
+
+	timeline, err := db.LoadTimeline()
+	if err != nil {
+		msg.err = err
+		return msg
+	}
+	msg.events = timeline.For(v.profile)
+	slices.Reverse(msg.events)
+	return msg
+}
+
+// Update handles messages
+func (v *TimelineView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+
+	case timelineLoadedMsg:
+		v.loading = false
+		v.events, v.checkErr, v.err = msg.events, msg.checkErr, msg.err
+		v.cursor = min(v.cursor, max(len(v.filtered())-1, 0))
+		if msg.observed > 0 {
+			v.message = fmt.Sprintf("Recorded %d new event(s) from the server", msg.observed)
+		}
+
+	case tea.KeyMsg:
+		events := v.filtered()
+		switch msg.String() {
+		case "up", "k":
+			if v.cursor > 0 {
+				v.cursor--
+			}
+		case "down", "j":
+			if v.cursor < len(events)-1 {
+				v.cursor++
+			}
+		case "pgup":
+			v.cursor = max(v.cursor-v.pageSize(), 0)
+		case "pgdown":
+			v.cursor = max(min(v.cursor+v.pageSize(), len(events)-1), 0)
+		case "f":
+			// Cycle through the kinds, then back to all of them
+			i := slices.Index(db.TimelineKinds, v.kind)
+			if i == len(db.TimelineKinds)-1 {
+				v.kind = ""
+			} else {
+				v.kind = db.TimelineKinds[i+1]
+			}
+			v.cursor = 0
+		case "r":
+			if !v.loading {
+				v.loading, v.message = true, ""
+				return v, v.load
+			}
+		case "esc", "backspace":
+			return v, func() tea.Msg {
+				return SwitchViewMsg{View: "databases"}
+			}
+		}
+	}
+	return v, nil
+}
+
+// filtered returns the events of the selected kind
+func (v *TimelineView) filtered() []db.TimelineEvent {
+	if v.kind == "" {
+		return v.events
+	}
+	var events []db.TimelineEvent
+	for _, e := range v.events {
+		if e.Kind == v.kind {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func (v *TimelineView) pageSize() int {
+	return max(v.height-16, 5)
+}
+
+// View renders the view
+func (v *TimelineView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Timeline: " + v.profile))
+	b.WriteString("\n")
+	kind := "all events"
+	if v.kind != "" {
+		kind = v.kind + " events"
+	}
+	b.WriteString(subtitleStyle.Render(fmt.Sprintf("%s, newest first", kind)))
+	b.WriteString("\n\n")
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+	if v.checkErr != nil {
+		b.WriteString(warningStyle.Render("Couldn't check the server, showing what was recorded: " + v.checkErr.Error()))
+		b.WriteString("\n\n")
+	}
+
+	events := v.filtered()
+	switch {
+	case v.loading && v.events == nil:
+		b.WriteString(mutedStyle.Render("Checking the server..."))
+		b.WriteString("\n\n")
+	case len(events) == 0:
+		b.WriteString(mutedStyle.Render("No events recorded yet. Restarts, replication errors, backups, snapshots and YSM operations show up here."))
+		b.WriteString("\n\n")
+	default:
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-19s %-11s %s", "Time", "Kind", "Event")))
+		b.WriteString("\n")
+
+		// Keep the cursor on screen
+		visible := v.pageSize()
+		start := 0
+		if v.cursor >= visible {
+			start = v.cursor - visible + 1
+		}
+		end := min(start+visible, len(events))
+
+		summaryWidth := max(v.width-38, 20)
+		for i := start; i < end; i++ {
+			e := events[i]
+			line := fmt.Sprintf("%-19s %-11s %s", e.Time.Format("2006-01-02 15:04:05"), e.Kind, truncateRunes(e.Summary, summaryWidth))
+			switch {
+			case i == v.cursor:
+				b.WriteString(selectedStyle.Render("> " + line))
+			case e.Failed || e.Kind == db.TimelineRestart:
+				b.WriteString(errorStyle.Render("  " + line))
+			default:
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+
+		if detail := events[v.cursor].Detail; detail != "" {
+			b.WriteString(mutedStyle.Render(truncateRunes(detail, max(v.width-4, 40))))
+			b.WriteString("\n\n")
+		}
+	}
+
+	if v.message != "" {
+		b.WriteString(successStyle.Render(v.message))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("↑↓: Navigate | f: Filter by kind | r: Check server | Esc: Back"))
+	return b.String()
+}
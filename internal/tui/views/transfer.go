@@ -0,0 +1,370 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TransferTarget is a connected session a transfer can copy into
+type TransferTarget struct {
+	Name string // Tab label, e.g. the profile name
+	Conn *db.Connection
+}
+
+type transferMode int
+
+const (
+	transferModeForm transferMode = iota
+	transferModeRunning
+	transferModeDone
+)
+
+// Transfer form fields, in tab order
+const (
+	transferFieldTarget = iota
+	transferFieldDatabase
+	transferFieldData
+	transferFieldReplace
+	transferFieldCount
+)
+
+// TransferView copies a database, or one of its tables, to the server of
+// another tab
+type TransferView struct {
+	conn     *db.Connection
+	database string
+	table    string // Empty = the whole database
+	targets  []TransferTarget
+	mode     transferMode
+
+	target   int
+	input    textinput.Model // Target database
+	noData   bool
+	replace  bool
+	focused  int
+	confirm  bool // Waiting for y before starting
+	progress *progressPanel
+	stats    *db.TransferStats
+	err      error
+
+	width  int
+	height int
+}
+
+type transferDoneMsg struct {
+	title   string
+	stats   *db.TransferStats
+	elapsed time.Duration
+	err     error
+}
+
+func (m transferDoneMsg) JobResult() JobResult {
+	return JobResult{View: "transfer", Title: m.title, Elapsed: m.elapsed, Err: m.err}
+}
+
+// NewTransferView creates a new transfer view. The targets are the
+// connected tabs, this one included.
+func NewTransferView(conn *db.Connection, database, table string, targets []TransferTarget, width, height int) *TransferView {
+	input := textinput.New()
+	input.Placeholder = database
+	input.SetValue(database)
+	input.Width = 40
+
+	v := &TransferView{
+		conn:     conn,
+		database: database,
+		table:    table,
+		targets:  targets,
+		input:    input,
+		width:    width,
+		height:   height,
+	}
+	// Another server is the likelier target
+	for i, t := range targets {
+		if t.Conn != conn {
+			v.target = i
+			break
+		}
+	}
+	return v
+}
+
+// Init initializes the view
+func (v *TransferView) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (v *TransferView) focus(field int) {
+	v.focused = field
+	if field == transferFieldDatabase {
+		v.input.Focus()
+	} else {
+		v.input.Blur()
+	}
+}
+
+func (v *TransferView) title() string {
+	if v.table != "" {
+		return "Transfer of " + v.database + "." + v.table
+	}
+	return "Transfer of " + v.database
+}
+
+func (v *TransferView) targetDatabase() string {
+	if name := strings.TrimSpace(v.input.Value()); name != "" {
+		return name
+	}
+	return v.database
+}
+
+// startTransfer runs the transfer on connections of its own, so both tabs
+// stay usable while it runs
+func (v *TransferView) startTransfer() tea.Cmd {
+	v.mode = transferModeRunning
+	v.confirm = false
+	v.err = nil
+	v.progress = newProgressPanel(v.title(), progress.Percent, 100)
+
+	source, target := v.conn, v.targets[v.target].Conn
+	opts := db.TransferOptions{
+		SourceDB: v.database,
+		TargetDB: v.targetDatabase(),
+		NoData:   v.noData,
+		Replace:  v.replace,
+	}
+	if v.table != "" {
+		opts.Tables = []string{v.table}
+	}
+	panel, title := v.progress, v.title()
+	opts.OnProgress = func(phase string, percent float64) {
+		step := 1
+		if phase == "import" {
+			step = 2
+		}
+		panel.SetCurrent(phase, step, 2)
+		panel.Set(int64(percent))
+	}
+
+	run := runJob(title, panel, func() tea.Msg {
+		sourceConn, releaseSource := jobConnection(source)
+		defer releaseSource()
+		targetConn, releaseTarget := jobConnection(target)
+		defer releaseTarget()
+
+		start := time.Now()
+		stats, err := sourceConn.TransferTo(targetConn, opts)
+		return transferDoneMsg{title: title, stats: stats, elapsed: time.Since(start), err: err}
+	})
+	return tea.Batch(run, progressTick())
+}
+
+// Update handles messages
+func (v *TransferView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		return v, nil
+
+	case progressTickMsg:
+		if v.mode == transferModeRunning {
+			return v, progressTick()
+		}
+		return v, nil
+
+	case transferDoneMsg:
+		v.mode = transferModeDone
+		v.stats = msg.stats
+		v.err = msg.err
+		return v, nil
+
+	case tea.KeyMsg:
+		switch v.mode {
+		case transferModeForm:
+			return v.updateForm(msg)
+		case transferModeDone:
+			switch msg.String() {
+			case "esc", "backspace", "enter":
+				return v, v.back()
+			case "q":
+				return v, tea.Quit
+			}
+		}
+	}
+
+	return v, nil
+}
+
+func (v *TransferView) back() tea.Cmd {
+	if v.table == "" {
+		return func() tea.Msg {
+			return SwitchViewMsg{View: "databases"}
+		}
+	}
+	database := v.database
+	return func() tea.Msg {
+		return SwitchViewMsg{View: "tables", Database: database}
+	}
+}
+
+func (v *TransferView) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if v.confirm {
+		if msg.String() == "y" {
+			return v, v.startTransfer()
+		}
+		v.confirm = false
+		return v, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		return v, v.back()
+	case "tab", "down":
+		v.focus((v.focused + 1) % transferFieldCount)
+		return v, nil
+	case "shift+tab", "up":
+		v.focus((v.focused + transferFieldCount - 1) % transferFieldCount)
+		return v, nil
+	case "enter":
+		if len(v.targets) == 0 {
+			return v, nil
+		}
+		v.err = nil
+		v.confirm = true
+		return v, nil
+	}
+
+	switch v.focused {
+	case transferFieldTarget:
+		if len(v.targets) == 0 {
+			return v, nil
+		}
+		switch msg.String() {
+		case "left", "h":
+			v.target = (v.target + len(v.targets) - 1) % len(v.targets)
+		case "right", "l", " ":
+			v.target = (v.target + 1) % len(v.targets)
+		}
+		return v, nil
+	case transferFieldData, transferFieldReplace:
+		if msg.String() == " " || msg.String() == "left" || msg.String() == "right" {
+			if v.focused == transferFieldData {
+				v.noData = !v.noData
+			} else {
+				v.replace = !v.replace
+			}
+		}
+		return v, nil
+	}
+
+	var cmd tea.Cmd
+	v.input, cmd = v.input.Update(msg)
+	return v, cmd
+}
+
+// View renders the view
+func (v *TransferView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(v.title()))
+	b.WriteString("\n\n")
+
+	switch v.mode {
+	case transferModeForm:
+		b.WriteString(v.viewForm())
+	case transferModeRunning:
+		b.WriteString(v.progress.View())
+	case transferModeDone:
+		if v.err != nil {
+			b.WriteString(renderError(v.err))
+		} else if s := v.stats; s != nil {
+			b.WriteString(successStyle.Render(fmt.Sprintf("Copied %d tables and %d rows to %s on %s in %s",
+				s.TablesCopied, s.RowsCopied, v.targetDatabase(), v.targets[v.target].Name, progress.FormatDuration(s.Duration))))
+		}
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Enter/Esc: Back | q: Quit"))
+	}
+	return b.String()
+}
+
+func (v *TransferView) viewForm() string {
+	var b strings.Builder
+
+	label := func(field int, text string) string {
+		if v.focused == field {
+			return focusedStyle.Render(text)
+		}
+		return blurredStyle.Render(text)
+	}
+
+	b.WriteString(label(transferFieldTarget, "Target tab:"))
+	b.WriteString("\n")
+	if len(v.targets) == 0 {
+		b.WriteString(mutedStyle.Render("No connected tabs; open one with alt+t"))
+	}
+	for i, t := range v.targets {
+		name := fmt.Sprintf(" %s (%s:%d) ", t.Name, t.Conn.Config.Host, t.Conn.Config.Port)
+		if i == v.target {
+			b.WriteString(selectedStyle.Render(name))
+		} else {
+			b.WriteString(mutedStyle.Render(name))
+		}
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(label(transferFieldDatabase, "Target database:"))
+	b.WriteString("\n")
+	b.WriteString(v.input.View())
+	b.WriteString("\n\n")
+
+	data := "[x] Copy rows"
+	if v.noData {
+		data = "[ ] Copy rows"
+	}
+	b.WriteString(label(transferFieldData, data))
+	b.WriteString("\n")
+	replace := "[ ] Replace tables the target already has"
+	if v.replace {
+		replace = "[x] Replace tables the target already has"
+	}
+	b.WriteString(label(transferFieldReplace, replace))
+	b.WriteString("\n\n")
+
+	if v.err != nil {
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	if v.confirm && len(v.targets) > 0 {
+		t := v.targets[v.target]
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Copy to %s on %s (%s)? (y/n)", v.targetDatabase(), t.Name, t.Conn.Config.Host)))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Tab: Next field | ←/→: Pick tab | Space: Toggle | Enter: Start | Esc: Back"))
+	return b.String()
+}
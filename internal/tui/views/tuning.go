@@ -0,0 +1,442 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TuningView walks through the machine and workload of a server and
+// proposes values for its key variables
+type TuningView struct {
+	conn   *db.Connection
+	width  int
+	height int
+
+	step     tuningStep
+	detected bool // Memory and CPUs came from this machine
+
+	// Machine
+	inputs []textinput.Model // Memory, CPUs, connections
+	focus  int               // Index into inputs, or len(inputs) for storage
+	ssd    bool
+
+	// Workload
+	workloads [][2]string
+	workload  int
+
+	// Proposal
+	plan    *db.TuningPlan
+	cursor  int
+	loading bool
+	message string
+	err     error
+}
+
+type tuningStep int
+
+const (
+	tuningStepMachine tuningStep = iota
+	tuningStepWorkload
+	tuningStepReview
+)
+
+const (
+	tuningMemory = iota
+	tuningCPUs
+	tuningConnections
+)
+
+type tuningPlanMsg struct {
+	plan *db.TuningPlan
+	err  error
+}
+
+type tuningSavedMsg struct {
+	path string
+	err  error
+}
+
+// NewTuningView creates the configuration tuning wizard
+func NewTuningView(conn *db.Connection, width, height int) *TuningView {
+	v := &TuningView{
+		conn:      conn,
+		width:     width,
+		height:    height,
+		ssd:       true,
+		workloads: db.TuningWorkloads(),
+	}
+
+	placeholders := []string{"RAM, like 16GB", "CPU cores", "expected connections (blank for the workload's usual)"}
+	v.inputs = make([]textinput.Model, len(placeholders))
+	for i, placeholder := range placeholders {
+		v.inputs[i] = textinput.New()
+		v.inputs[i].Placeholder = placeholder
+		v.inputs[i].CharLimit = 16
+	}
+	if memory, cpus, ok := conn.DetectHostResources(); ok {
+		v.detected = true
+		v.inputs[tuningMemory].SetValue(strconv.FormatInt(memory>>30, 10) + "GB")
+		v.inputs[tuningCPUs].SetValue(strconv.Itoa(cpus))
+	}
+	v.inputs[tuningMemory].Focus()
+	return v
+}
+
+// Init initializes the view
+func (v *TuningView) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// input reads the machine step into a tuning input
+func (v *TuningView) input() (db.TuningInput, error) {
+	memory, err := db.ParseSize(v.inputs[tuningMemory].Value())
+	if err != nil || memory == 0 {
+		return db.TuningInput{}, fmt.Errorf("enter the server's RAM, like 16GB")
+	}
+	cpus, err := strconv.Atoi(strings.TrimSpace(v.inputs[tuningCPUs].Value()))
+	if err != nil || cpus < 1 {
+		return db.TuningInput{}, fmt.Errorf("enter the number of CPU cores")
+	}
+	in := db.TuningInput{Memory: memory, CPUs: cpus, SSD: v.ssd}
+	if value := strings.TrimSpace(v.inputs[tuningConnections].Value()); value != "" {
+		if in.Connections, err = strconv.Atoi(value); err != nil || in.Connections < 1 {
+			return db.TuningInput{}, fmt.Errorf("expected connections must be a positive number")
+		}
+	}
+	return in, nil
+}
+
+func (v *TuningView) propose(in db.TuningInput) tea.Cmd {
+	v.loading = true
+	v.err = nil
+	return func() tea.Msg {
+		plan, err := v.conn.ProposeTuning(in)
+		return tuningPlanMsg{plan: plan, err: err}
+	}
+}
+
+// save writes the configuration snippet next to where YSM runs
+func (v *TuningView) save() tea.Cmd {
+	plan := v.plan
+	ext := "cnf"
+	if v.conn.Config.Type == db.DatabaseTypePostgres {
+		ext = "conf"
+	}
+	path := fmt.Sprintf("ysm_tuning_%s.%s", time.Now().Format("20060102_150405"), ext)
+	return func() tea.Msg {
+		err := os.WriteFile(path, []byte(plan.Snippet()), 0644)
+		return tuningSavedMsg{path: path, err: err}
+	}
+}
+
+// focusInput moves the focus in the machine step
+func (v *TuningView) focusInput(focus int) tea.Cmd {
+	v.focus = (focus + len(v.inputs) + 1) % (len(v.inputs) + 1)
+	for i := range v.inputs {
+		v.inputs[i].Blur()
+	}
+	if v.focus < len(v.inputs) {
+		v.inputs[v.focus].Focus()
+		return textinput.Blink
+	}
+	return nil
+}
+
+// Update handles messages
+func (v *TuningView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+		v.height = msg.Height
+		return v, nil
+
+	case tuningPlanMsg:
+		v.loading = false
+		if msg.err != nil {
+			v.err = msg.err
+			return v, nil
+		}
+		v.plan = msg.plan
+		v.cursor = 0
+		v.step = tuningStepReview
+		return v, nil
+
+	case tuningSavedMsg:
+		if msg.err != nil {
+			v.err = msg.err
+		} else {
+			v.err = nil
+			v.message = "Saved the configuration snippet to " + msg.path
+		}
+		return v, nil
+
+	case tea.KeyMsg:
+		if v.loading {
+			return v, nil
+		}
+		switch v.step {
+		case tuningStepMachine:
+			return v.updateMachine(msg)
+		case tuningStepWorkload:
+			return v.updateWorkload(msg)
+		default:
+			return v.updateReview(msg)
+		}
+	}
+	return v, nil
+}
+
+func (v *TuningView) updateMachine(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return v, func() tea.Msg {
+			return SwitchViewMsg{View: "settings"}
+		}
+	case "tab", "down":
+		return v, v.focusInput(v.focus + 1)
+	case "shift+tab", "up":
+		return v, v.focusInput(v.focus - 1)
+	case "enter":
+		if _, err := v.input(); err != nil {
+			v.err = err
+			return v, nil
+		}
+		v.err = nil
+		v.step = tuningStepWorkload
+		return v, nil
+	}
+
+	if v.focus == len(v.inputs) {
+		switch msg.String() {
+		case "left", "right", " ":
+			v.ssd = !v.ssd
+		}
+		return v, nil
+	}
+	var cmd tea.Cmd
+	v.inputs[v.focus], cmd = v.inputs[v.focus].Update(msg)
+	return v, cmd
+}
+
+func (v *TuningView) updateWorkload(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		v.step = tuningStepMachine
+		v.err = nil
+	case "up", "k":
+		if v.workload > 0 {
+			v.workload--
+		}
+	case "down", "j":
+		if v.workload < len(v.workloads)-1 {
+			v.workload++
+		}
+	case "enter":
+		in, err := v.input()
+		if err != nil {
+			v.err = err
+			return v, nil
+		}
+		in.Workload = v.workloads[v.workload][0]
+		return v, v.propose(in)
+	}
+	return v, nil
+}
+
+func (v *TuningView) updateReview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		v.step = tuningStepWorkload
+		v.message = ""
+		v.err = nil
+	case "up", "k":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "down", "j":
+		if v.cursor < len(v.plan.Changes)-1 {
+			v.cursor++
+		}
+	case "s":
+		return v, v.save()
+	case "q", "ctrl+c":
+		return v, tea.Quit
+	}
+	return v, nil
+}
+
+// View renders the view
+func (v *TuningView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Configuration Tuning"))
+	b.WriteString("\n\n")
+
+	switch v.step {
+	case tuningStepMachine:
+		v.viewMachine(&b)
+	case tuningStepWorkload:
+		v.viewWorkload(&b)
+	default:
+		v.viewReview(&b)
+	}
+
+	if v.err != nil {
+		b.WriteString("\n")
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n")
+	}
+	if v.message != "" && v.err == nil {
+		b.WriteString("\n")
+		b.WriteString(successStyle.Render(v.message))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	var help string
+	switch v.step {
+	case tuningStepMachine:
+		help = "Tab/↑↓: Field | ←/→: Storage | Enter: Next | Esc: Back"
+	case tuningStepWorkload:
+		help = "↑↓: Select | Enter: Propose values | Esc: Back"
+	default:
+		help = "↑↓: Navigate | s: Save config snippet | Esc: Back"
+	}
+	b.WriteString(helpStyle.Render(help))
+	return b.String()
+}
+
+func (v *TuningView) viewMachine(b *strings.Builder) {
+	b.WriteString("Step 1: The machine\n\n")
+	if v.detected {
+		b.WriteString(mutedStyle.Render("Detected from this machine, which runs the server. Change them if the server may only use part of it."))
+	} else {
+		b.WriteString(mutedStyle.Render("The server runs on another machine: enter what it has."))
+	}
+	b.WriteString("\n\n")
+
+	labels := []string{"Memory:", "CPUs:", "Connections:"}
+	for i, input := range v.inputs {
+		label := blurredStyle.Render(fmt.Sprintf("%-13s", labels[i]))
+		if i == v.focus {
+			label = focusedStyle.Render(fmt.Sprintf("%-13s", labels[i]))
+		}
+		b.WriteString(label)
+		b.WriteString(input.View())
+		b.WriteString("\n")
+	}
+
+	storage := "< spinning disks >"
+	if v.ssd {
+		storage = "< SSD >"
+	}
+	if v.focus == len(v.inputs) {
+		b.WriteString(focusedStyle.Render(fmt.Sprintf("%-13s", "Storage:")))
+		b.WriteString(selectedStyle.Render(storage))
+	} else {
+		b.WriteString(blurredStyle.Render(fmt.Sprintf("%-13s", "Storage:")))
+		b.WriteString(storage)
+	}
+	b.WriteString("\n")
+}
+
+func (v *TuningView) viewWorkload(b *strings.Builder) {
+	b.WriteString("Step 2: The workload\n\n")
+	for i, w := range v.workloads {
+		if i == v.workload {
+			b.WriteString(selectedStyle.Render("> " + w[1]))
+		} else {
+			b.WriteString("  " + w[1])
+		}
+		b.WriteString("\n")
+	}
+	if v.loading {
+		b.WriteString("\nReading the current values...\n")
+	}
+}
+
+func (v *TuningView) viewReview(b *strings.Builder) {
+	in := v.plan.Input
+	storage := "spinning disks"
+	if in.SSD {
+		storage = "SSD"
+	}
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("%s workload, %s RAM, %d CPUs, %s", in.Workload, db.FormatSize(in.Memory), in.CPUs, storage)))
+	b.WriteString("\n\n")
+
+	changed := 0
+	nameWidth := 8
+	for _, c := range v.plan.Changes {
+		nameWidth = max(nameWidth, len(c.Name))
+		if c.Changed {
+			changed++
+		}
+	}
+	b.WriteString(headerStyle.Render(fmt.Sprintf("  %-*s  %-14s  %-14s  %s", nameWidth, "Variable", "Current", "Proposed", "")))
+	b.WriteString("\n")
+
+	visible := max(v.height-16, 5)
+	start := 0
+	if v.cursor >= visible {
+		start = v.cursor - visible + 1
+	}
+	end := min(start+visible, len(v.plan.Changes))
+	for i := start; i < end; i++ {
+		c := v.plan.Changes[i]
+		note := ""
+		switch {
+		case !c.Changed:
+			note = "unchanged"
+		case c.Restart:
+			note = "restart"
+		}
+		line := fmt.Sprintf("%-*s  %-14s  %-14s  %s", nameWidth, c.Name,
+			truncateRunes(c.Current, 14), truncateRunes(c.Proposed, 14), note)
+		switch {
+		case i == v.cursor:
+			b.WriteString(selectedStyle.Render("> " + line))
+		case !c.Changed:
+			b.WriteString(mutedStyle.Render("  " + line))
+		case c.Restart:
+			b.WriteString("  " + warningStyle.Render(line))
+		default:
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	if v.cursor < len(v.plan.Changes) {
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render(v.plan.Changes[v.cursor].Reason))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("%d of %d values differ from the server's. ", changed, len(v.plan.Changes)))
+	b.WriteString(mutedStyle.Render("These are a starting point: measure before and after."))
+	b.WriteString("\n")
+}
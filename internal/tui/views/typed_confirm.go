@@ -0,0 +1,90 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// typedConfirm guards something that can't be undone by asking for each
+// name it affects to be typed back exactly, like deleting a repository on
+// GitHub, instead of a single y
+type typedConfirm struct {
+	action   string // What happens to the names, e.g. "drop"
+	names    []string
+	index    int // Name being asked for
+	input    textinput.Model
+	mismatch bool // Enter was pressed on a wrong name
+}
+
+func newTypedConfirm(action string, names []string) *typedConfirm {
+	input := textinput.New()
+	input.Focus()
+	input.PromptStyle = focusedStyle
+	input.TextStyle = focusedStyle
+	input.Width = 40
+	return &typedConfirm{action: action, names: names, input: input}
+}
+
+// Update handles a key. confirmed is set once every name was typed, and
+// cancelled when Esc was pressed
+func (t *typedConfirm) Update(msg tea.KeyMsg) (confirmed, cancelled bool, cmd tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return false, true, nil
+	case "enter":
+		if t.input.Value() != t.names[t.index] {
+			t.mismatch = true
+			return false, false, nil
+		}
+		t.mismatch = false
+		t.index++
+		t.input.SetValue("")
+		return t.index == len(t.names), false, nil
+	}
+	t.mismatch = false
+	t.input, cmd = t.input.Update(msg)
+	return false, false, cmd
+}
+
+// View renders the prompt for the current name
+func (t *typedConfirm) View() string {
+	var b strings.Builder
+	name := t.names[t.index]
+	b.WriteString(errorStyle.Render(fmt.Sprintf("This will %s %s and can't be undone.", t.action, strings.Join(t.names, ", "))))
+	b.WriteString("\n\n")
+	prompt := fmt.Sprintf("Type %s to confirm", headerStyle.Render(name))
+	if len(t.names) > 1 {
+		prompt += mutedStyle.Render(fmt.Sprintf(" (%d of %d)", t.index+1, len(t.names)))
+	}
+	b.WriteString(prompt + ":\n")
+	b.WriteString(t.input.View())
+	b.WriteString("\n")
+	if t.mismatch {
+		b.WriteString(errorStyle.Render("That doesn't match, type the name exactly"))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("Enter: Confirm | Esc: Cancel"))
+	return b.String()
+}
@@ -21,29 +21,43 @@ package views
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/blubskye/yandere_sql_manager/internal/config"
 	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/logging"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
 // UsersView shows the list of database users and allows management
 type UsersView struct {
-	conn   *db.Connection
-	list   list.Model
-	users  []db.User
-	width  int
-	height int
-	err    error
+	conn        *db.Connection
+	profile     string            // Profile recorded with temporary grants
+	passwords   db.PasswordPolicy // What Ctrl+G generates in password fields
+	list        list.Model
+	users       []db.User
+	keybindings *config.KeyBindings
+	width       int
+	height      int
+	err         error
+	status      string
 
 	// Sub-views/modes
-	mode        usersMode
-	createForm  *userCreateForm
-	grantForm   *userGrantForm
-	grantsView  *userGrantsView
-	confirmDrop *confirmDropView
+	mode           usersMode
+	createForm     *userCreateForm
+	grantForm      *userGrantForm
+	grantsView     *userGrantsView
+	confirmDrop    *confirmDropView
+	tempGrants     *tempGrantsView
+	passwordForm   *userPasswordForm
+	editForm       *userEditForm
+	roleAttrsForm  *roleAttrsForm
+	cloneForm      *userCloneForm
+	templateForm   *userTemplateForm
+	privilegeAudit *privilegeAuditView
+	userImport     *userImportView
 }
 
 type usersMode int
@@ -55,6 +69,14 @@ const (
 	usersModeGrant
 	usersModeRevoke
 	usersModeConfirmDrop
+	usersModeTempGrants
+	usersModePassword
+	usersModeEdit
+	usersModeRoleAttrs
+	usersModeClone
+	usersModeTemplate
+	usersModeAudit
+	usersModeImport
 )
 
 type userItem struct {
@@ -76,6 +98,7 @@ type userCreateForm struct {
 	focused    int
 	hostIndex  int // For MariaDB host selection
 	isMariaDB  bool
+	passwords  *passwordAssist
 	err        error
 	processing bool
 }
@@ -92,21 +115,88 @@ var defaultHosts = []string{"localhost", "%", "127.0.0.1"}
 type userGrantsView struct {
 	user   db.User
 	grants []db.Grant
+	cursor int
+	status string // Result of the last copy
 	err    error
 }
 
 // User grant form
 type userGrantForm struct {
-	user        db.User
-	databases   []string
-	dbIndex     int
-	privIndex   int
-	privileges  []string
-	selected    map[int]bool
-	isRevoke    bool
-	focused     int // 0 = database, 1 = privileges
-	err         error
-	processing  bool
+	user           db.User
+	databases      []string
+	dbIndex        int
+	tables         []string // "*" first; loaded for the selected database
+	tableIndex     int
+	tablesErr      error // Why tables can't be picked, e.g. another PostgreSQL database
+	columns        []string
+	columnIndex    int
+	columnSelected map[int]bool
+	privIndex      int
+	privileges     []string
+	selected       map[int]bool
+	isRevoke       bool
+	focused        int // One of the grantField constants
+	expiryIndex    int
+	err            error
+	processing     bool
+}
+
+const (
+	grantFieldDatabase = iota
+	grantFieldTable
+	grantFieldColumns
+	grantFieldPrivileges
+	grantFieldExpiry
+)
+
+// fields returns the fields shown for the current selection in tab order
+func (f *userGrantForm) fields() []int {
+	fields := []int{grantFieldDatabase}
+	if f.dbIndex > 0 {
+		fields = append(fields, grantFieldTable)
+	}
+	if f.tableIndex > 0 && len(f.columns) > 0 {
+		fields = append(fields, grantFieldColumns)
+	}
+	fields = append(fields, grantFieldPrivileges)
+	if !f.isRevoke {
+		fields = append(fields, grantFieldExpiry)
+	}
+	return fields
+}
+
+// move focuses the field delta places away, wrapping around
+func (f *userGrantForm) move(delta int) {
+	fields := f.fields()
+	pos := 0
+	for i, field := range fields {
+		if field == f.focused {
+			pos = i
+		}
+	}
+	f.focused = fields[(pos+delta+len(fields))%len(fields)]
+}
+
+// table returns the selected table, or "" for all tables
+func (f *userGrantForm) table() string {
+	if f.tableIndex == 0 || f.tableIndex >= len(f.tables) {
+		return ""
+	}
+	return f.tables[f.tableIndex]
+}
+
+// grantExpiryOptions are the lifetimes offered for temporary grants
+var grantExpiryOptions = []time.Duration{0, 15 * time.Minute, time.Hour, 4 * time.Hour, 8 * time.Hour, 24 * time.Hour}
+
+// tempGrantCheckInterval is how often the view revokes expired temporary grants
+const tempGrantCheckInterval = 15 * time.Second
+
+// Temporary grants view
+type tempGrantsView struct {
+	grants  []db.TemporaryGrant
+	cursor  int
+	confirm bool
+	err     error
 }
 
 // Confirm drop view
@@ -116,15 +206,8 @@ type confirmDropView struct {
 }
 
 // NewUsersView creates a new users view
-func NewUsersView(conn *db.Connection, width, height int) *UsersView {
-	delegate := list.NewDefaultDelegate()
-	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
-		Foreground(lipgloss.Color("#FFFFFF")).
-		Background(lipgloss.Color("#FF69B4")).
-		Bold(true)
-	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
-		Foreground(lipgloss.Color("#FFB6C1")).
-		Background(lipgloss.Color("#FF69B4"))
+func NewUsersView(conn *db.Connection, cfg *config.Config, profile string, width, height int) *UsersView {
+	delegate := newListDelegate()
 
 	l := list.New([]list.Item{}, delegate, width, height-4)
 	l.Title = "Database Users"
@@ -132,18 +215,27 @@ func NewUsersView(conn *db.Connection, width, height int) *UsersView {
 	l.SetFilteringEnabled(true)
 	l.Styles.Title = titleStyle
 
+	passwords, err := cfg.PasswordPolicy()
+	if err != nil {
+		logging.Warn("Passwords: %v", err)
+	}
+
 	return &UsersView{
-		conn:   conn,
-		list:   l,
-		width:  width,
-		height: height,
-		mode:   usersModeList,
+		conn:        conn,
+		profile:     profile,
+		passwords:   passwords,
+		list:        l,
+		keybindings: loadKeyBindings(),
+		width:       width,
+		height:      height,
+		mode:        usersModeList,
 	}
 }
 
 // Init initializes the view
 func (v *UsersView) Init() tea.Cmd {
-	return v.loadUsers
+	// Revoke anything that expired while YSM wasn't running
+	return tea.Batch(v.loadUsers, v.revokeExpiredGrants)
 }
 
 func (v *UsersView) loadUsers() tea.Msg {
@@ -167,9 +259,47 @@ type privilegesChangedMsg struct{}
 type databasesLoadedMsg struct {
 	databases []string
 }
+type grantTablesLoadedMsg struct {
+	database string
+	tables   []string
+	err      error
+}
+type grantColumnsLoadedMsg struct {
+	database string
+	table    string
+	columns  []string
+	err      error
+}
+type tempGrantsLoadedMsg struct {
+	grants []db.TemporaryGrant
+}
+type tempGrantRevokedMsg struct{}
+type tempGrantTickMsg struct{}
+type expiredGrantsRevokedMsg struct {
+	revoked []db.TemporaryGrant
+	err     error
+}
 
 // Update handles messages
 func (v *UsersView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Expiry checks run in every mode
+	switch msg := msg.(type) {
+	case tempGrantTickMsg:
+		return v, v.revokeExpiredGrants
+	case expiredGrantsRevokedMsg:
+		if msg.err != nil {
+			v.err = msg.err
+		}
+		if len(msg.revoked) > 0 {
+			v.status = fmt.Sprintf("Revoked %d expired temporary grant(s)", len(msg.revoked))
+		}
+		cmds := []tea.Cmd{v.tempGrantTick()}
+		if v.mode == usersModeTempGrants {
+			cmds = append(cmds, v.loadTempGrants)
+		}
+		return v, tea.Batch(cmds...)
+	}
+
 	switch v.mode {
 	case usersModeCreate:
 		return v.updateCreateForm(msg)
@@ -179,6 +309,22 @@ func (v *UsersView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return v.updateGrantForm(msg)
 	case usersModeConfirmDrop:
 		return v.updateConfirmDrop(msg)
+	case usersModeTempGrants:
+		return v.updateTempGrants(msg)
+	case usersModePassword:
+		return v.updatePasswordForm(msg)
+	case usersModeEdit:
+		return v.updateEditForm(msg)
+	case usersModeRoleAttrs:
+		return v.updateRoleAttrsForm(msg)
+	case usersModeClone:
+		return v.updateCloneForm(msg)
+	case usersModeTemplate:
+		return v.updateTemplateForm(msg)
+	case usersModeAudit:
+		return v.updatePrivilegeAudit(msg)
+	case usersModeImport:
+		return v.updateUserImport(msg)
 	}
 
 	return v.updateList(msg)
@@ -187,6 +333,11 @@ func (v *UsersView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (v *UsersView) updateList(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if !v.list.SettingFilter() {
+			if cmd := showHelp(v.keybindings, "users", msg.String()); cmd != nil {
+				return v, cmd
+			}
+		}
 		switch msg.String() {
 		case "enter":
 			if item, ok := v.list.SelectedItem().(userItem); ok {
@@ -218,6 +369,75 @@ func (v *UsersView) updateList(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return v, v.initGrantForm(item.user, true)
 				}
 			}
+		case "p":
+			if !v.list.SettingFilter() {
+				if item, ok := v.list.SelectedItem().(userItem); ok {
+					v.status = ""
+					v.initPasswordForm(item.user)
+					v.mode = usersModePassword
+					return v, textinput.Blink
+				}
+			}
+		case "e":
+			if !v.list.SettingFilter() {
+				if item, ok := v.list.SelectedItem().(userItem); ok {
+					v.status = ""
+					v.initEditForm(item.user)
+					v.mode = usersModeEdit
+					return v, textinput.Blink
+				}
+			}
+		case "a":
+			if !v.list.SettingFilter() {
+				if item, ok := v.list.SelectedItem().(userItem); ok {
+					if v.conn.Config.Type == db.DatabaseTypeMariaDB {
+						v.err = fmt.Errorf("role attributes are only supported on PostgreSQL")
+						return v, nil
+					}
+					v.status = ""
+					return v, v.initRoleAttrsForm(item.user)
+				}
+			}
+		case "C":
+			if !v.list.SettingFilter() {
+				if item, ok := v.list.SelectedItem().(userItem); ok {
+					v.status = ""
+					v.initCloneForm(item.user)
+					v.mode = usersModeClone
+					return v, textinput.Blink
+				}
+			}
+		case "T":
+			if !v.list.SettingFilter() {
+				if item, ok := v.list.SelectedItem().(userItem); ok {
+					v.status = ""
+					v.mode = usersModeTemplate
+					return v, v.initTemplateForm(item.user)
+				}
+			}
+		case "A":
+			if !v.list.SettingFilter() {
+				v.privilegeAudit = &privilegeAuditView{}
+				v.mode = usersModeAudit
+				return v, v.loadPrivilegeAudit
+			}
+		case "I":
+			if !v.list.SettingFilter() {
+				if v.conn.Config.Type != db.DatabaseTypeMariaDB {
+					v.err = fmt.Errorf("importing users is only supported on MariaDB")
+					return v, nil
+				}
+				v.status = ""
+				v.initUserImport()
+				v.mode = usersModeImport
+				return v, textinput.Blink
+			}
+		case "t":
+			if !v.list.SettingFilter() {
+				v.tempGrants = &tempGrantsView{}
+				v.mode = usersModeTempGrants
+				return v, v.loadTempGrants
+			}
 		case "R":
 			if !v.list.SettingFilter() {
 				return v, v.loadUsers
@@ -248,6 +468,10 @@ func (v *UsersView) updateList(msg tea.Msg) (tea.Model, tea.Cmd) {
 		v.list.SetItems(items)
 		return v, nil
 
+	case grantsLoadedMsg:
+		// Grants are loaded from the list, so they arrive before the mode changes
+		return v.updateGrantsView(msg)
+
 	case error:
 		v.err = msg
 		return v, nil
@@ -264,6 +488,7 @@ func (v *UsersView) initCreateForm() {
 	form := &userCreateForm{
 		inputs:    make([]textinput.Model, 3),
 		isMariaDB: isMariaDB,
+		passwords: newPasswordAssist(v.passwords),
 	}
 
 	// Username
@@ -307,6 +532,12 @@ func (v *UsersView) updateCreateForm(msg tea.Msg) (tea.Model, tea.Cmd) {
 			form.prevInput()
 			return v, nil
 
+		case generatePasswordKey:
+			if form.focused == createInputPassword || form.focused == createInputConfirm {
+				form.err = form.passwords.generate(&form.inputs[createInputPassword], &form.inputs[createInputConfirm])
+				return v, nil
+			}
+
 		case "left", "right":
 			// Host selection for MariaDB
 			if form.isMariaDB && form.focused == 3 {
@@ -342,6 +573,10 @@ func (v *UsersView) updateCreateForm(msg tea.Msg) (tea.Model, tea.Cmd) {
 				form.err = fmt.Errorf("passwords do not match")
 				return v, nil
 			}
+			if err := form.passwords.checkWeak(password, username); err != nil {
+				form.err = err
+				return v, nil
+			}
 
 			host := "localhost"
 			if form.isMariaDB {
@@ -445,16 +680,43 @@ func (v *UsersView) loadGrants(user db.User) tea.Cmd {
 func (v *UsersView) updateGrantsView(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		v.grantsView.status = ""
 		switch msg.String() {
 		case "esc", "backspace", "q":
 			v.mode = usersModeList
 			v.grantsView = nil
 			return v, nil
+		case "up", "k":
+			if v.grantsView.cursor > 0 {
+				v.grantsView.cursor--
+			}
+		case "down", "j":
+			if v.grantsView.cursor < len(v.grantsView.grants)-1 {
+				v.grantsView.cursor++
+			}
+		case "y":
+			gv := v.grantsView
+			if gv.cursor < len(gv.grants) {
+				stmt := v.conn.GrantStatement(gv.grants[gv.cursor], gv.user.Username, gv.user.Host)
+				return v, copyToClipboard("the grant statement", stmt+";")
+			}
 		case "g":
 			return v, v.initGrantForm(v.grantsView.user, false)
 		case "r":
 			return v, v.initGrantForm(v.grantsView.user, true)
 		}
+		return v, nil
+
+	case clipboardCopiedMsg:
+		if v.grantsView == nil {
+			return v, nil
+		}
+		if msg.err != nil {
+			v.grantsView.err = msg.err
+		} else {
+			v.grantsView.status = msg.status()
+		}
+		return v, nil
 
 	case grantsLoadedMsg:
 		if item, ok := v.list.SelectedItem().(userItem); ok {
@@ -478,10 +740,11 @@ func (v *UsersView) updateGrantsView(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (v *UsersView) initGrantForm(user db.User, isRevoke bool) tea.Cmd {
 	v.grantForm = &userGrantForm{
-		user:       user,
-		privileges: db.CommonPrivileges(),
-		selected:   make(map[int]bool),
-		isRevoke:   isRevoke,
+		user:           user,
+		privileges:     db.CommonPrivileges(),
+		selected:       make(map[int]bool),
+		columnSelected: make(map[int]bool),
+		isRevoke:       isRevoke,
 	}
 
 	if isRevoke {
@@ -492,7 +755,7 @@ func (v *UsersView) initGrantForm(user db.User, isRevoke bool) tea.Cmd {
 
 	// Load databases
 	return func() tea.Msg {
-		databases, err := v.conn.ListDatabases()
+		databases, err := v.conn.ListVisibleDatabases()
 		if err != nil {
 			return err
 		}
@@ -508,6 +771,14 @@ func (v *UsersView) initGrantForm(user db.User, isRevoke bool) tea.Cmd {
 func (v *UsersView) updateGrantForm(msg tea.Msg) (tea.Model, tea.Cmd) {
 	form := v.grantForm
 
+	// step moves an index by delta, wrapping around n entries
+	step := func(i, delta, n int) int {
+		if n == 0 {
+			return 0
+		}
+		return (i + delta + n) % n
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -521,41 +792,41 @@ func (v *UsersView) updateGrantForm(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return v, nil
 
 		case "tab":
-			form.focused = (form.focused + 1) % 2
+			form.move(1)
 			return v, nil
 
-		case "up", "k":
-			if form.focused == 0 {
-				form.dbIndex--
-				if form.dbIndex < 0 {
-					form.dbIndex = len(form.databases) - 1
-				}
-			} else {
-				form.privIndex--
-				if form.privIndex < 0 {
-					form.privIndex = len(form.privileges) - 1
-				}
-			}
+		case "shift+tab":
+			form.move(-1)
 			return v, nil
 
-		case "down", "j":
-			if form.focused == 0 {
-				form.dbIndex++
-				if form.dbIndex >= len(form.databases) {
-					form.dbIndex = 0
-				}
-			} else {
-				form.privIndex++
-				if form.privIndex >= len(form.privileges) {
-					form.privIndex = 0
-				}
+		case "up", "k", "down", "j":
+			delta := 1
+			if msg.String() == "up" || msg.String() == "k" {
+				delta = -1
+			}
+			switch form.focused {
+			case grantFieldDatabase:
+				form.dbIndex = step(form.dbIndex, delta, len(form.databases))
+				return v, v.selectGrantDatabase()
+			case grantFieldTable:
+				form.tableIndex = step(form.tableIndex, delta, len(form.tables))
+				return v, v.selectGrantTable()
+			case grantFieldColumns:
+				form.columnIndex = step(form.columnIndex, delta, len(form.columns))
+			case grantFieldPrivileges:
+				form.privIndex = step(form.privIndex, delta, len(form.privileges))
+			case grantFieldExpiry:
+				form.expiryIndex = step(form.expiryIndex, delta, len(grantExpiryOptions))
 			}
 			return v, nil
 
 		case " ":
-			// Toggle privilege selection
-			if form.focused == 1 {
+			// Toggle privilege or column selection
+			switch form.focused {
+			case grantFieldPrivileges:
 				form.selected[form.privIndex] = !form.selected[form.privIndex]
+			case grantFieldColumns:
+				form.columnSelected[form.columnIndex] = !form.columnSelected[form.columnIndex]
 			}
 			return v, nil
 
@@ -565,28 +836,64 @@ func (v *UsersView) updateGrantForm(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if form.dbIndex > 0 {
 				database = form.databases[form.dbIndex]
 			}
+			table := form.table()
 
 			var privs []string
-			for i, selected := range form.selected {
-				if selected {
-					privs = append(privs, form.privileges[i])
+			for i, priv := range form.privileges {
+				if form.selected[i] {
+					privs = append(privs, priv)
+				}
+			}
+
+			var columns []string
+			for i, column := range form.columns {
+				if form.columnSelected[i] {
+					columns = append(columns, column)
+				}
+			}
+			if len(columns) > 0 {
+				columnPrivs, err := v.conn.ColumnPrivileges(privs, columns)
+				if err != nil {
+					form.err = err
+					return v, nil
 				}
+				privs = columnPrivs
 			}
+
 			if len(privs) == 0 {
 				privs = []string{"ALL PRIVILEGES"}
 			}
 
+			form.err = nil
 			form.processing = true
 			if form.isRevoke {
-				return v, v.revokePrivileges(form.user, privs, database)
+				return v, v.revokePrivileges(form.user, privs, database, table)
 			}
-			return v, v.grantPrivileges(form.user, privs, database)
+			return v, v.grantPrivileges(form.user, privs, database, table, grantExpiryOptions[form.expiryIndex])
 		}
 
 	case databasesLoadedMsg:
 		form.databases = msg.databases
 		return v, nil
 
+	case grantTablesLoadedMsg:
+		if form.dbIndex == 0 || form.databases[form.dbIndex] != msg.database {
+			return v, nil // Selection moved on
+		}
+		form.tables = append([]string{"*"}, msg.tables...)
+		form.tablesErr = msg.err
+		return v, nil
+
+	case grantColumnsLoadedMsg:
+		if form.table() != msg.table || form.databases[form.dbIndex] != msg.database {
+			return v, nil
+		}
+		form.columns = msg.columns
+		if msg.err != nil {
+			form.err = msg.err
+		}
+		return v, nil
+
 	case privilegesChangedMsg:
 		v.grantForm = nil
 		if v.grantsView != nil {
@@ -605,18 +912,65 @@ func (v *UsersView) updateGrantForm(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return v, nil
 }
 
-func (v *UsersView) grantPrivileges(user db.User, privs []string, database string) tea.Cmd {
+// selectGrantDatabase resets the table and column pickers for the newly
+// selected database and loads its tables
+func (v *UsersView) selectGrantDatabase() tea.Cmd {
+	form := v.grantForm
+	form.tables = []string{"*"}
+	form.tableIndex = 0
+	form.tablesErr = nil
+	form.columns = nil
+	form.columnIndex = 0
+	form.columnSelected = make(map[int]bool)
+	if form.dbIndex == 0 {
+		return nil
+	}
+
+	database := form.databases[form.dbIndex]
 	return func() tea.Msg {
-		if err := v.conn.GrantPrivileges(user.Username, user.Host, privs, database, ""); err != nil {
+		tables, err := v.conn.ListGrantTables(database)
+		return grantTablesLoadedMsg{database: database, tables: tables, err: err}
+	}
+}
+
+// selectGrantTable resets the column picker for the newly selected table and
+// loads its columns
+func (v *UsersView) selectGrantTable() tea.Cmd {
+	form := v.grantForm
+	form.columns = nil
+	form.columnIndex = 0
+	form.columnSelected = make(map[int]bool)
+
+	table := form.table()
+	if table == "" {
+		return nil
+	}
+
+	database := form.databases[form.dbIndex]
+	return func() tea.Msg {
+		columns, err := v.conn.ListGrantColumns(database, table)
+		return grantColumnsLoadedMsg{database: database, table: table, columns: columns, err: err}
+	}
+}
+
+func (v *UsersView) grantPrivileges(user db.User, privs []string, database, table string, ttl time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		if ttl > 0 {
+			if _, err := v.conn.GrantTemporary(user.Username, user.Host, privs, database, table, ttl, "", v.profile); err != nil {
+				return err
+			}
+			return privilegesChangedMsg{}
+		}
+		if err := v.conn.GrantPrivileges(user.Username, user.Host, privs, database, table); err != nil {
 			return err
 		}
 		return privilegesChangedMsg{}
 	}
 }
 
-func (v *UsersView) revokePrivileges(user db.User, privs []string, database string) tea.Cmd {
+func (v *UsersView) revokePrivileges(user db.User, privs []string, database, table string) tea.Cmd {
 	return func() tea.Msg {
-		if err := v.conn.RevokePrivileges(user.Username, user.Host, privs, database, ""); err != nil {
+		if err := v.conn.RevokePrivileges(user.Username, user.Host, privs, database, table); err != nil {
 			return err
 		}
 		return privilegesChangedMsg{}
@@ -650,6 +1004,99 @@ func (v *UsersView) updateConfirmDrop(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return v, nil
 }
 
+func (v *UsersView) tempGrantTick() tea.Cmd {
+	return tea.Tick(tempGrantCheckInterval, func(t time.Time) tea.Msg {
+		return tempGrantTickMsg{}
+	})
+}
+
+// revokeExpiredGrants revokes expired temporary grants made on this server
+func (v *UsersView) revokeExpiredGrants() tea.Msg {
+	revoked, err := v.conn.RevokeExpiredGrants()
+	return expiredGrantsRevokedMsg{revoked: revoked, err: err}
+}
+
+// loadTempGrants loads the active temporary grants made on this server
+func (v *UsersView) loadTempGrants() tea.Msg {
+	all, err := db.ListTemporaryGrants(false)
+	if err != nil {
+		return err
+	}
+	var grants []db.TemporaryGrant
+	for _, g := range all {
+		if g.Server == v.conn.ServerKey() {
+			grants = append(grants, g)
+		}
+	}
+	return tempGrantsLoadedMsg{grants: grants}
+}
+
+func (v *UsersView) updateTempGrants(msg tea.Msg) (tea.Model, tea.Cmd) {
+	tg := v.tempGrants
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if tg.confirm {
+			switch msg.String() {
+			case "y":
+				tg.confirm = false
+				if tg.cursor < len(tg.grants) {
+					id := tg.grants[tg.cursor].ID
+					return v, func() tea.Msg {
+						if err := v.conn.RevokeTemporaryGrant(id); err != nil {
+							return err
+						}
+						return tempGrantRevokedMsg{}
+					}
+				}
+			case "n", "esc":
+				tg.confirm = false
+			}
+			return v, nil
+		}
+
+		switch msg.String() {
+		case "esc", "backspace", "q":
+			v.mode = usersModeList
+			v.tempGrants = nil
+			return v, nil
+		case "up", "k":
+			if tg.cursor > 0 {
+				tg.cursor--
+			}
+		case "down", "j":
+			if tg.cursor < len(tg.grants)-1 {
+				tg.cursor++
+			}
+		case "x":
+			if len(tg.grants) > 0 {
+				tg.confirm = true
+			}
+		case "R":
+			return v, v.loadTempGrants
+		}
+		return v, nil
+
+	case tempGrantsLoadedMsg:
+		tg.grants = msg.grants
+		if tg.cursor >= len(tg.grants) {
+			tg.cursor = max(len(tg.grants)-1, 0)
+		}
+		return v, nil
+
+	case tempGrantRevokedMsg:
+		tg.err = nil
+		v.status = "Temporary grant revoked"
+		return v, v.loadTempGrants
+
+	case error:
+		tg.err = msg
+		return v, nil
+	}
+
+	return v, nil
+}
+
 func (v *UsersView) dropUser(user db.User) tea.Cmd {
 	return func() tea.Msg {
 		if err := v.conn.DropUser(user.Username, user.Host); err != nil {
@@ -670,6 +1117,22 @@ func (v *UsersView) View() string {
 		return v.viewGrantForm()
 	case usersModeConfirmDrop:
 		return v.viewConfirmDrop()
+	case usersModeTempGrants:
+		return v.viewTempGrants()
+	case usersModePassword:
+		return v.viewPasswordForm()
+	case usersModeEdit:
+		return v.viewEditForm()
+	case usersModeRoleAttrs:
+		return v.viewRoleAttrsForm()
+	case usersModeClone:
+		return v.viewCloneForm()
+	case usersModeTemplate:
+		return v.viewTemplateForm()
+	case usersModeAudit:
+		return v.viewPrivilegeAudit()
+	case usersModeImport:
+		return v.viewUserImport()
 	}
 
 	return v.viewList()
@@ -679,13 +1142,23 @@ func (v *UsersView) viewList() string {
 	var b strings.Builder
 
 	if v.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", v.err)))
+		b.WriteString(renderError(v.err))
+		b.WriteString("\n\n")
+	}
+
+	if v.status != "" {
+		b.WriteString(successStyle.Render(v.status))
 		b.WriteString("\n\n")
 	}
 
 	b.WriteString(v.list.View())
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Enter: Show grants | c: Create | d: Drop | g: Grant | r: Revoke | R: Refresh | Esc: Back | q: Quit"))
+	roleKeys := "I: Import | "
+	if v.conn.Config.Type != db.DatabaseTypeMariaDB {
+		roleKeys = "a: Attributes | "
+	}
+	b.WriteString(helpStyle.Render("Enter: Show grants | c: Create | C: Clone | T: Template | p: Password | e: Edit | " + roleKeys + "d: Drop | g: Grant | r: Revoke | t: Temporary grants | A: Audit | R: Refresh | " +
+		v.keybindings.GetKey("users", config.ActionHelp) + ": Help | Esc: Back | q: Quit"))
 
 	return b.String()
 }
@@ -715,6 +1188,8 @@ func (v *UsersView) viewCreateForm() string {
 	}
 	b.WriteString("\n")
 	b.WriteString(form.inputs[createInputPassword].View())
+	b.WriteString("\n")
+	b.WriteString(form.passwords.view(form.inputs[createInputPassword].Value(), form.inputs[createInputUsername].Value()))
 	b.WriteString("\n\n")
 
 	// Confirm
@@ -746,7 +1221,7 @@ func (v *UsersView) viewCreateForm() string {
 	}
 
 	if form.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", form.err)))
+		b.WriteString(renderError(form.err))
 		b.WriteString("\n\n")
 	}
 
@@ -754,7 +1229,7 @@ func (v *UsersView) viewCreateForm() string {
 		b.WriteString("Creating user...\n\n")
 	}
 
-	b.WriteString(helpStyle.Render("Enter: Create | Tab: Next | Esc: Cancel"))
+	b.WriteString(helpStyle.Render("Enter: Create | Tab: Next | Ctrl+G: Generate password | Esc: Cancel"))
 
 	return b.String()
 }
@@ -772,7 +1247,7 @@ func (v *UsersView) viewGrants() string {
 	b.WriteString("\n\n")
 
 	if gv.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", gv.err)))
+		b.WriteString(renderError(gv.err))
 		b.WriteString("\n\n")
 	}
 
@@ -780,21 +1255,27 @@ func (v *UsersView) viewGrants() string {
 		b.WriteString(mutedStyle.Render("No grants found."))
 		b.WriteString("\n")
 	} else {
-		for _, g := range gv.grants {
-			if g.GrantText != "" {
-				// MariaDB raw grant
-				b.WriteString("  ")
-				b.WriteString(g.GrantText)
-				b.WriteString("\n")
+		for i, g := range gv.grants {
+			// MariaDB reports the raw grant, PostgreSQL a structured one
+			line := g.GrantText
+			if line == "" {
+				line = fmt.Sprintf("%s on %s.%s", g.Privilege, g.Database, g.Table)
+			}
+			if i == gv.cursor {
+				b.WriteString(focusedStyle.Render("→ " + line))
 			} else {
-				// PostgreSQL structured
-				b.WriteString(fmt.Sprintf("  %s on %s.%s\n", g.Privilege, g.Database, g.Table))
+				b.WriteString("  " + line)
 			}
+			b.WriteString("\n")
 		}
 	}
 
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("g: Grant | r: Revoke | Esc: Back"))
+	if gv.status != "" {
+		b.WriteString(successStyle.Render(gv.status))
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render("↑/↓: Select | y: Copy GRANT statement | g: Grant | r: Revoke | Esc: Back"))
 
 	return b.String()
 }
@@ -817,7 +1298,7 @@ func (v *UsersView) viewGrantForm() string {
 	b.WriteString("\n\n")
 
 	// Database selector
-	if form.focused == 0 {
+	if form.focused == grantFieldDatabase {
 		b.WriteString(focusedStyle.Render("Database:"))
 	} else {
 		b.WriteString(blurredStyle.Render("Database:"))
@@ -829,7 +1310,7 @@ func (v *UsersView) viewGrantForm() string {
 		if dbDisplay == "*" {
 			dbDisplay = "* (all databases)"
 		}
-		if form.focused == 0 {
+		if form.focused == grantFieldDatabase {
 			b.WriteString(focusedStyle.Render(fmt.Sprintf("  → %s", dbDisplay)))
 		} else {
 			b.WriteString(fmt.Sprintf("  %s", dbDisplay))
@@ -839,8 +1320,66 @@ func (v *UsersView) viewGrantForm() string {
 	}
 	b.WriteString("\n\n")
 
+	// Table selector, once a database is picked
+	if form.dbIndex > 0 {
+		if form.focused == grantFieldTable {
+			b.WriteString(focusedStyle.Render("Table:"))
+		} else {
+			b.WriteString(blurredStyle.Render("Table:"))
+		}
+		b.WriteString("\n")
+		tableDisplay := "* (all tables)"
+		if table := form.table(); table != "" {
+			tableDisplay = table
+		}
+		if form.focused == grantFieldTable {
+			b.WriteString(focusedStyle.Render(fmt.Sprintf("  → %s", tableDisplay)))
+		} else {
+			b.WriteString(fmt.Sprintf("  %s", tableDisplay))
+		}
+		if form.tablesErr != nil {
+			b.WriteString("\n")
+			b.WriteString(mutedStyle.Render("  " + form.tablesErr.Error()))
+		}
+		b.WriteString("\n\n")
+	}
+
+	// Column selector, once a table is picked; none selected = whole table
+	if form.tableIndex > 0 && len(form.columns) > 0 {
+		if form.focused == grantFieldColumns {
+			b.WriteString(focusedStyle.Render("Columns:"))
+		} else {
+			b.WriteString(blurredStyle.Render("Columns:"))
+		}
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("  none = whole table, else only %s", strings.Join(db.ColumnGrantPrivileges, "/"))))
+		b.WriteString("\n")
+
+		maxColumns := 5
+		first := 0
+		if form.columnIndex >= maxColumns {
+			first = form.columnIndex - maxColumns + 1
+		}
+		for i := first; i < len(form.columns) && i < first+maxColumns; i++ {
+			checkbox := "[ ]"
+			if form.columnSelected[i] {
+				checkbox = "[x]"
+			}
+			if form.focused == grantFieldColumns && i == form.columnIndex {
+				b.WriteString(focusedStyle.Render(fmt.Sprintf("  → %s %s", checkbox, form.columns[i])))
+			} else {
+				b.WriteString(fmt.Sprintf("    %s %s", checkbox, form.columns[i]))
+			}
+			b.WriteString("\n")
+		}
+		if len(form.columns) > first+maxColumns {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("    ... and %d more", len(form.columns)-first-maxColumns)))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
 	// Privileges selector
-	if form.focused == 1 {
+	if form.focused == grantFieldPrivileges {
 		b.WriteString(focusedStyle.Render("Privileges:"))
 	} else {
 		b.WriteString(blurredStyle.Render("Privileges:"))
@@ -861,7 +1400,7 @@ func (v *UsersView) viewGrantForm() string {
 			checkbox = "[x]"
 		}
 
-		if form.focused == 1 && i == form.privIndex {
+		if form.focused == grantFieldPrivileges && i == form.privIndex {
 			b.WriteString(focusedStyle.Render(fmt.Sprintf("  → %s %s", checkbox, priv)))
 		} else {
 			b.WriteString(fmt.Sprintf("    %s %s", checkbox, priv))
@@ -876,8 +1415,28 @@ func (v *UsersView) viewGrantForm() string {
 
 	b.WriteString("\n")
 
+	// Expiry selector (grant only)
+	if !form.isRevoke {
+		if form.focused == grantFieldExpiry {
+			b.WriteString(focusedStyle.Render("Expires:"))
+		} else {
+			b.WriteString(blurredStyle.Render("Expires:"))
+		}
+		b.WriteString("\n")
+		expiry := "never"
+		if ttl := grantExpiryOptions[form.expiryIndex]; ttl > 0 {
+			expiry = fmt.Sprintf("after %s (revoked automatically)", formatGrantDuration(ttl))
+		}
+		if form.focused == grantFieldExpiry {
+			b.WriteString(focusedStyle.Render(fmt.Sprintf("  → %s", expiry)))
+		} else {
+			b.WriteString(fmt.Sprintf("  %s", expiry))
+		}
+		b.WriteString("\n\n")
+	}
+
 	if form.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", form.err)))
+		b.WriteString(renderError(form.err))
 		b.WriteString("\n\n")
 	}
 
@@ -885,7 +1444,7 @@ func (v *UsersView) viewGrantForm() string {
 		b.WriteString(fmt.Sprintf("%sing privileges...\n\n", action))
 	}
 
-	b.WriteString(helpStyle.Render("Tab: Switch | ↑↓: Navigate | Space: Toggle | Enter: Execute | Esc: Cancel"))
+	b.WriteString(helpStyle.Render("Tab/Shift+Tab: Switch | ↑↓: Navigate | Space: Toggle | Enter: Execute | Esc: Cancel"))
 
 	return b.String()
 }
@@ -907,3 +1466,80 @@ func (v *UsersView) viewConfirmDrop() string {
 
 	return b.String()
 }
+
+func (v *UsersView) viewTempGrants() string {
+	var b strings.Builder
+	tg := v.tempGrants
+
+	b.WriteString(titleStyle.Render("Temporary Grants"))
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render(v.conn.ServerKey()))
+	b.WriteString("\n\n")
+
+	if tg.err != nil {
+		b.WriteString(renderError(tg.err))
+		b.WriteString("\n\n")
+	}
+
+	if len(tg.grants) == 0 {
+		b.WriteString(mutedStyle.Render("No active temporary grants."))
+		b.WriteString("\n")
+	}
+
+	for i, g := range tg.grants {
+		remaining := formatGrantDuration(g.Remaining()) + " left"
+		if g.Expired(time.Now()) {
+			remaining = "expired, revoking"
+			if g.LastError != "" {
+				remaining = "expired, revoke failed"
+			}
+		}
+
+		line := fmt.Sprintf("%s@%s  %s on %s  (%s)", g.Username, g.Host,
+			strings.Join(g.Privileges, ", "), g.Scope(), remaining)
+		if i == tg.cursor {
+			b.WriteString(focusedStyle.Render("→ " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+
+		if g.Reason != "" {
+			b.WriteString(mutedStyle.Render("    " + g.Reason))
+			b.WriteString("\n")
+		}
+		if g.LastError != "" {
+			b.WriteString(errorStyle.Render("    " + g.LastError))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	if tg.confirm && tg.cursor < len(tg.grants) {
+		g := tg.grants[tg.cursor]
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Revoke %s on %s from %s@%s now? (y/n)",
+			strings.Join(g.Privileges, ", "), g.Scope(), g.Username, g.Host)))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	b.WriteString(helpStyle.Render("↑↓: Navigate | x: Revoke now | R: Refresh | Esc: Back"))
+
+	return b.String()
+}
+
+// formatGrantDuration formats a grant lifetime to the minute, e.g. "1h30m"
+func formatGrantDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "<1m"
+	}
+	d = d.Truncate(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	}
+	return fmt.Sprintf("%dh%dm", h, m)
+}
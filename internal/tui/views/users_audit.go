@@ -0,0 +1,223 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Permissions audit report
+type privilegeAuditView struct {
+	audit        *db.PrivilegeAudit
+	findingsOnly bool // Show only entries with findings
+	cursor       int
+	message      string
+	err          error
+}
+
+type privilegeAuditLoadedMsg struct {
+	audit *db.PrivilegeAudit
+	err   error
+}
+
+type privilegeAuditExportedMsg struct {
+	path string
+	err  error
+}
+
+func (v *UsersView) loadPrivilegeAudit() tea.Msg {
+	audit, err := v.conn.AuditPrivileges()
+	return privilegeAuditLoadedMsg{audit: audit, err: err}
+}
+
+func (v *UsersView) exportPrivilegeAudit(format string) tea.Cmd {
+	audit := v.privilegeAudit.audit
+	path := db.DefaultResultExportPath("privilege-audit", format)
+	return func() tea.Msg {
+		return privilegeAuditExportedMsg{path: path, err: audit.Export(path, format)}
+	}
+}
+
+// entries returns the entries shown with the current filter
+func (a *privilegeAuditView) entries() []db.PrivilegeEntry {
+	if a.audit == nil {
+		return nil
+	}
+	if !a.findingsOnly {
+		return a.audit.Entries
+	}
+	var flagged []db.PrivilegeEntry
+	for _, e := range a.audit.Entries {
+		if len(a.audit.FindingsFor(e)) > 0 {
+			flagged = append(flagged, e)
+		}
+	}
+	return flagged
+}
+
+func (v *UsersView) updatePrivilegeAudit(msg tea.Msg) (tea.Model, tea.Cmd) {
+	view := v.privilegeAudit
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "backspace":
+			v.mode = usersModeList
+			v.privilegeAudit = nil
+			return v, nil
+		case "r":
+			view.message = ""
+			return v, v.loadPrivilegeAudit
+		case "f":
+			view.findingsOnly = !view.findingsOnly
+			view.cursor = 0
+			return v, nil
+		case "c", "J":
+			if view.audit != nil {
+				view.message = ""
+				format := db.AuditFormatCSV
+				if msg.String() == "J" {
+					format = db.AuditFormatJSON
+				}
+				return v, v.exportPrivilegeAudit(format)
+			}
+			return v, nil
+		case "up", "k":
+			if view.cursor > 0 {
+				view.cursor--
+			}
+			return v, nil
+		case "down", "j":
+			if view.cursor < len(view.entries())-1 {
+				view.cursor++
+			}
+			return v, nil
+		case "q":
+			return v, tea.Quit
+		}
+
+	case privilegeAuditLoadedMsg:
+		view.audit = msg.audit
+		view.err = msg.err
+		view.cursor = min(view.cursor, max(len(view.entries())-1, 0))
+		return v, nil
+
+	case privilegeAuditExportedMsg:
+		view.err = msg.err
+		if msg.err == nil {
+			view.message = fmt.Sprintf("Exported the audit to %s", msg.path)
+		}
+		return v, nil
+	}
+
+	return v, nil
+}
+
+func (v *UsersView) viewPrivilegeAudit() string {
+	var b strings.Builder
+	view := v.privilegeAudit
+
+	b.WriteString(titleStyle.Render("Permissions Audit"))
+	b.WriteString("\n\n")
+
+	entries := view.entries()
+	switch {
+	case view.audit == nil && view.err == nil:
+		b.WriteString("Auditing privileges...\n")
+	case view.audit == nil:
+	default:
+		audit := view.audit
+		summary := fmt.Sprintf("%d grants, %d findings", len(audit.Entries), len(audit.Findings))
+		if view.findingsOnly {
+			summary += " - showing flagged grants only"
+		}
+		b.WriteString(mutedStyle.Render(summary))
+		b.WriteString("\n\n")
+
+		if len(entries) == 0 {
+			b.WriteString(mutedStyle.Render("Nothing to show"))
+			b.WriteString("\n")
+			break
+		}
+
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-28s %-20s %-28s %s", "User", "Database", "Object", "Privileges")))
+		b.WriteString("\n")
+
+		visible := max(v.height-18, 5)
+		start := 0
+		if view.cursor >= visible {
+			start = view.cursor - visible + 1
+		}
+		end := min(start+visible, len(entries))
+		for i := start; i < end; i++ {
+			e := entries[i]
+			user := e.User
+			if e.Host != "" {
+				user += "@" + e.Host
+			}
+			privs := strings.Join(e.Privileges, ", ")
+			if e.GrantOption {
+				privs += " +GRANT"
+			}
+			line := fmt.Sprintf("%-28s %-20s %-28s %s",
+				truncateRunes(user, 28), truncateRunes(e.Database, 20), truncateRunes(e.Object, 28), privs)
+			line = truncateRunes(line, max(v.width-4, 40))
+
+			flagged := len(audit.FindingsFor(e)) > 0
+			switch {
+			case i == view.cursor:
+				b.WriteString(selectedStyle.Render("> " + line))
+			case flagged:
+				b.WriteString(errorStyle.Render("! " + line))
+			default:
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+
+		// Why the selected grant was flagged
+		findings := audit.FindingsFor(entries[view.cursor])
+		b.WriteString("\n")
+		if len(findings) == 0 {
+			b.WriteString(mutedStyle.Render("No findings for this grant"))
+			b.WriteString("\n")
+		}
+		for _, f := range findings {
+			b.WriteString(errorStyle.Render(f.Privilege))
+			b.WriteString(" " + f.Reason)
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+
+	if view.err != nil {
+		b.WriteString(renderError(view.err))
+		b.WriteString("\n\n")
+	} else if view.message != "" {
+		b.WriteString(successStyle.Render(view.message))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("↑↓: Navigate | f: Flagged only | c: Export CSV | J: Export JSON | r: Refresh | Esc: Back"))
+	return b.String()
+}
@@ -0,0 +1,444 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// User clone form: a new account with the grants of an existing one
+type userCloneForm struct {
+	source     db.User
+	inputs     []textinput.Model // Username, password, confirm
+	hosts      []string          // MariaDB only, the source's host first
+	hostIndex  int
+	isMariaDB  bool
+	focused    int // One of the cloneField constants
+	err        error
+	processing bool
+}
+
+const (
+	cloneFieldName = iota
+	cloneFieldPassword
+	cloneFieldConfirm
+	cloneFieldHost
+)
+
+// Permission template form: grant a template on a database in one step
+type userTemplateForm struct {
+	user          db.User
+	templates     []db.PermissionTemplate
+	templateIndex int
+	databases     []string // "*" (all databases) first on MariaDB
+	dbIndex       int
+	focused       int // 0 = template, 1 = database
+	err           error
+	processing    bool
+}
+
+type templateDatabasesLoadedMsg struct {
+	databases []string
+}
+
+func (v *UsersView) initCloneForm(source db.User) {
+	form := &userCloneForm{
+		source:    source,
+		inputs:    make([]textinput.Model, 3),
+		isMariaDB: v.conn.Config.Type == db.DatabaseTypeMariaDB,
+	}
+
+	form.inputs[cloneFieldName] = textinput.New()
+	form.inputs[cloneFieldName].Placeholder = "new username"
+	form.inputs[cloneFieldName].Focus()
+	form.inputs[cloneFieldName].PromptStyle = focusedStyle
+	form.inputs[cloneFieldName].TextStyle = focusedStyle
+
+	form.inputs[cloneFieldPassword] = textinput.New()
+	form.inputs[cloneFieldPassword].Placeholder = "password"
+	form.inputs[cloneFieldPassword].EchoMode = textinput.EchoPassword
+	form.inputs[cloneFieldPassword].EchoCharacter = '•'
+
+	form.inputs[cloneFieldConfirm] = textinput.New()
+	form.inputs[cloneFieldConfirm].Placeholder = "confirm password"
+	form.inputs[cloneFieldConfirm].EchoMode = textinput.EchoPassword
+	form.inputs[cloneFieldConfirm].EchoCharacter = '•'
+
+	if form.isMariaDB {
+		form.hosts = []string{source.Host}
+		for _, h := range defaultHosts {
+			if h != source.Host {
+				form.hosts = append(form.hosts, h)
+			}
+		}
+	}
+
+	v.cloneForm = form
+}
+
+// move focuses the field delta places away, wrapping around
+func (f *userCloneForm) move(delta int) {
+	count := len(f.inputs)
+	if f.isMariaDB {
+		count++
+	}
+	f.focused = (f.focused + delta + count) % count
+
+	for i := range f.inputs {
+		if i == f.focused {
+			f.inputs[i].Focus()
+			f.inputs[i].PromptStyle = focusedStyle
+			f.inputs[i].TextStyle = focusedStyle
+		} else {
+			f.inputs[i].Blur()
+			f.inputs[i].PromptStyle = blurredStyle
+			f.inputs[i].TextStyle = blurredStyle
+		}
+	}
+}
+
+func (v *UsersView) updateCloneForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	form := v.cloneForm
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			v.mode = usersModeList
+			v.cloneForm = nil
+			return v, nil
+
+		case "tab", "down":
+			form.move(1)
+			return v, nil
+
+		case "shift+tab", "up":
+			form.move(-1)
+			return v, nil
+
+		case "left", "right":
+			if form.focused == cloneFieldHost {
+				delta := 1
+				if msg.String() == "left" {
+					delta = -1
+				}
+				form.hostIndex = (form.hostIndex + delta + len(form.hosts)) % len(form.hosts)
+				return v, nil
+			}
+
+		case "enter":
+			if form.processing {
+				return v, nil
+			}
+			name := strings.TrimSpace(form.inputs[cloneFieldName].Value())
+			password := form.inputs[cloneFieldPassword].Value()
+			if name == "" {
+				form.err = fmt.Errorf("username is required")
+				return v, nil
+			}
+			if password == "" {
+				form.err = fmt.Errorf("password is required")
+				return v, nil
+			}
+			if password != form.inputs[cloneFieldConfirm].Value() {
+				form.err = fmt.Errorf("passwords do not match")
+				return v, nil
+			}
+			host := ""
+			if form.isMariaDB {
+				host = form.hosts[form.hostIndex]
+			}
+
+			form.err = nil
+			form.processing = true
+			return v, v.cloneUser(form.source, db.User{Username: name, Host: host}, password)
+		}
+
+	case userUpdatedMsg:
+		if msg.err != nil {
+			form.err = msg.err
+			form.processing = false
+			return v, nil
+		}
+		v.mode = usersModeList
+		v.cloneForm = nil
+		v.err = nil
+		v.status = msg.status
+		return v, v.loadUsers
+	}
+
+	cmds := make([]tea.Cmd, len(form.inputs))
+	for i := range form.inputs {
+		form.inputs[i], cmds[i] = form.inputs[i].Update(msg)
+	}
+	return v, tea.Batch(cmds...)
+}
+
+func (v *UsersView) cloneUser(source, user db.User, password string) tea.Cmd {
+	return func() tea.Msg {
+		if err := v.conn.CloneUser(source.Username, source.Host, user.Username, user.Host, password); err != nil {
+			return userUpdatedMsg{err: err}
+		}
+		return userUpdatedMsg{status: fmt.Sprintf("Created %s with the grants of %s",
+			userItem{user: user}.Title(), userItem{user: source}.Title())}
+	}
+}
+
+func (v *UsersView) initTemplateForm(user db.User) tea.Cmd {
+	v.templateForm = &userTemplateForm{
+		user:      user,
+		templates: v.conn.PermissionTemplates(),
+	}
+
+	return func() tea.Msg {
+		databases, err := v.conn.ListVisibleDatabases()
+		if err != nil {
+			return err
+		}
+		var names []string
+		if v.conn.Config.Type == db.DatabaseTypeMariaDB {
+			names = append(names, "*")
+		}
+		for _, d := range databases {
+			names = append(names, d.Name)
+		}
+		return templateDatabasesLoadedMsg{databases: names}
+	}
+}
+
+func (v *UsersView) updateTemplateForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	form := v.templateForm
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			v.mode = usersModeList
+			v.templateForm = nil
+			return v, nil
+
+		case "tab", "shift+tab":
+			form.focused = 1 - form.focused
+			return v, nil
+
+		case "up", "k":
+			if form.focused == 0 && form.templateIndex > 0 {
+				form.templateIndex--
+			} else if form.focused == 1 && form.dbIndex > 0 {
+				form.dbIndex--
+			}
+			return v, nil
+
+		case "down", "j":
+			if form.focused == 0 && form.templateIndex < len(form.templates)-1 {
+				form.templateIndex++
+			} else if form.focused == 1 && form.dbIndex < len(form.databases)-1 {
+				form.dbIndex++
+			}
+			return v, nil
+
+		case "enter":
+			if form.processing || len(form.databases) == 0 {
+				return v, nil
+			}
+			database := form.databases[form.dbIndex]
+			if database == "*" {
+				database = ""
+			}
+
+			form.err = nil
+			form.processing = true
+			return v, v.applyTemplate(form.user, form.templates[form.templateIndex], database)
+		}
+
+	case templateDatabasesLoadedMsg:
+		form.databases = msg.databases
+		return v, nil
+
+	case userUpdatedMsg:
+		if msg.err != nil {
+			form.err = msg.err
+			form.processing = false
+			return v, nil
+		}
+		v.mode = usersModeList
+		v.templateForm = nil
+		v.err = nil
+		v.status = msg.status
+		return v, nil
+
+	case error:
+		form.err = msg
+		form.processing = false
+		return v, nil
+	}
+
+	return v, nil
+}
+
+func (v *UsersView) applyTemplate(user db.User, template db.PermissionTemplate, database string) tea.Cmd {
+	return func() tea.Msg {
+		if err := v.conn.ApplyPermissionTemplate(user.Username, user.Host, template.Name, database); err != nil {
+			return userUpdatedMsg{err: err}
+		}
+		target := database
+		if target == "" {
+			target = "all databases"
+		}
+		return userUpdatedMsg{status: fmt.Sprintf("Granted %s on %s to %s", template.Name, target, userItem{user: user}.Title())}
+	}
+}
+
+func (v *UsersView) viewCloneForm() string {
+	var b strings.Builder
+	form := v.cloneForm
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Clone User - %s", userItem{user: form.source}.Title())))
+	b.WriteString("\n\n")
+
+	labels := []string{"New Username:", "Password:", "Confirm Password:"}
+	for i, label := range labels {
+		if form.focused == i {
+			b.WriteString(focusedStyle.Render(label))
+		} else {
+			b.WriteString(blurredStyle.Render(label))
+		}
+		b.WriteString("\n")
+		b.WriteString(form.inputs[i].View())
+		b.WriteString("\n\n")
+	}
+
+	if form.isMariaDB {
+		display := fmt.Sprintf("[ %s ]", form.hosts[form.hostIndex])
+		if form.focused == cloneFieldHost {
+			b.WriteString(focusedStyle.Render("Host:"))
+			b.WriteString("\n")
+			b.WriteString(focusedStyle.Render(display))
+			b.WriteString(mutedStyle.Render("  ←/→ to change"))
+		} else {
+			b.WriteString(blurredStyle.Render("Host:"))
+			b.WriteString("\n")
+			b.WriteString(blurredStyle.Render(display))
+		}
+		b.WriteString("\n\n")
+	} else {
+		b.WriteString(mutedStyle.Render("Role attributes, memberships and table grants in this database are copied"))
+		b.WriteString("\n\n")
+	}
+
+	if form.err != nil {
+		b.WriteString(renderError(form.err))
+		b.WriteString("\n\n")
+	}
+
+	if form.processing {
+		b.WriteString("Cloning user...\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Enter: Clone | Tab: Next | Esc: Cancel"))
+
+	return b.String()
+}
+
+func (v *UsersView) viewTemplateForm() string {
+	var b strings.Builder
+	form := v.templateForm
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Apply Permission Template - %s", userItem{user: form.user}.Title())))
+	b.WriteString("\n\n")
+
+	if form.focused == 0 {
+		b.WriteString(focusedStyle.Render("Template:"))
+	} else {
+		b.WriteString(blurredStyle.Render("Template:"))
+	}
+	b.WriteString("\n")
+	for i, t := range form.templates {
+		line := fmt.Sprintf("%-12s %s", t.Name, mutedStyle.Render(t.Description))
+		if i == form.templateIndex {
+			if form.focused == 0 {
+				b.WriteString(focusedStyle.Render("  → ") + line)
+			} else {
+				b.WriteString("  • " + line)
+			}
+		} else {
+			b.WriteString("    " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(mutedStyle.Render("    " + strings.Join(form.templates[form.templateIndex].Privileges, ", ")))
+	b.WriteString("\n\n")
+
+	if form.focused == 1 {
+		b.WriteString(focusedStyle.Render("Database:"))
+	} else {
+		b.WriteString(blurredStyle.Render("Database:"))
+	}
+	b.WriteString("\n")
+
+	if form.databases == nil && form.err == nil {
+		b.WriteString(mutedStyle.Render("  Loading..."))
+		b.WriteString("\n")
+	}
+
+	maxShow := 8
+	start := 0
+	if form.dbIndex >= maxShow {
+		start = form.dbIndex - maxShow + 1
+	}
+	for i := start; i < len(form.databases) && i < start+maxShow; i++ {
+		name := form.databases[i]
+		if name == "*" {
+			name = "* (all databases)"
+		}
+		if i == form.dbIndex {
+			if form.focused == 1 {
+				b.WriteString(focusedStyle.Render("  → " + name))
+			} else {
+				b.WriteString("  • " + name)
+			}
+		} else {
+			b.WriteString("    " + name)
+		}
+		b.WriteString("\n")
+	}
+	if len(form.databases) > start+maxShow {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("    ... and %d more", len(form.databases)-start-maxShow)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if form.err != nil {
+		b.WriteString(renderError(form.err))
+		b.WriteString("\n\n")
+	}
+
+	if form.processing {
+		b.WriteString("Granting privileges...\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Tab: Switch | ↑/↓: Select | Enter: Apply | Esc: Cancel"))
+
+	return b.String()
+}
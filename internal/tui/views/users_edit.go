@@ -0,0 +1,474 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// User password form
+type userPasswordForm struct {
+	user       db.User
+	inputs     []textinput.Model // New password, confirm
+	focused    int
+	passwords  *passwordAssist
+	err        error
+	processing bool
+}
+
+// User edit form: rename, lock and password expiry
+type userEditForm struct {
+	user        db.User
+	name        textinput.Model
+	hosts       []string // MariaDB only, the current host first
+	hostIndex   int
+	isMariaDB   bool
+	lockIndex   int
+	expiryIndex int
+	focused     int // One of the editField constants
+	err         error
+	processing  bool
+}
+
+const (
+	editFieldName = iota
+	editFieldHost
+	editFieldLock
+	editFieldExpiry
+)
+
+// userLockOptions are the account lock choices; index 0 leaves it alone
+var userLockOptions = []string{"unchanged", "lock", "unlock"}
+
+// passwordExpiryOption is a password expiry choice in the edit form
+type passwordExpiryOption struct {
+	label string
+	days  int // See db.Connection.SetPasswordExpiry
+}
+
+// passwordExpiryOptions are the expiry choices; index 0 leaves it alone
+var passwordExpiryOptions = []passwordExpiryOption{
+	{label: "unchanged"},
+	{label: "expire now", days: 0},
+	{label: "never", days: db.PasswordNeverExpires},
+	{label: "30 days", days: 30},
+	{label: "90 days", days: 90},
+	{label: "180 days", days: 180},
+}
+
+type userUpdatedMsg struct {
+	status string
+	err    error
+}
+
+func (v *UsersView) initPasswordForm(user db.User) {
+	form := &userPasswordForm{
+		user:      user,
+		inputs:    make([]textinput.Model, 2),
+		passwords: newPasswordAssist(v.passwords),
+	}
+
+	form.inputs[0] = textinput.New()
+	form.inputs[0].Placeholder = "new password"
+	form.inputs[0].EchoMode = textinput.EchoPassword
+	form.inputs[0].EchoCharacter = '•'
+	form.inputs[0].Focus()
+	form.inputs[0].PromptStyle = focusedStyle
+	form.inputs[0].TextStyle = focusedStyle
+
+	form.inputs[1] = textinput.New()
+	form.inputs[1].Placeholder = "confirm password"
+	form.inputs[1].EchoMode = textinput.EchoPassword
+	form.inputs[1].EchoCharacter = '•'
+
+	v.passwordForm = form
+}
+
+func (v *UsersView) updatePasswordForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	form := v.passwordForm
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			v.mode = usersModeList
+			v.passwordForm = nil
+			return v, nil
+
+		case "tab", "down", "shift+tab", "up":
+			form.inputs[form.focused].Blur()
+			form.inputs[form.focused].PromptStyle = blurredStyle
+			form.inputs[form.focused].TextStyle = blurredStyle
+			form.focused = 1 - form.focused
+			form.inputs[form.focused].Focus()
+			form.inputs[form.focused].PromptStyle = focusedStyle
+			form.inputs[form.focused].TextStyle = focusedStyle
+			return v, nil
+
+		case generatePasswordKey:
+			form.err = form.passwords.generate(&form.inputs[0], &form.inputs[1])
+			return v, nil
+
+		case "enter":
+			if form.processing {
+				return v, nil
+			}
+			password := form.inputs[0].Value()
+			if password == "" {
+				form.err = fmt.Errorf("password is required")
+				return v, nil
+			}
+			if password != form.inputs[1].Value() {
+				form.err = fmt.Errorf("passwords do not match")
+				return v, nil
+			}
+			if err := form.passwords.checkWeak(password, form.user.Username); err != nil {
+				form.err = err
+				return v, nil
+			}
+
+			form.err = nil
+			form.processing = true
+			return v, v.changePassword(form.user, password)
+		}
+
+	case userUpdatedMsg:
+		if msg.err != nil {
+			form.err = msg.err
+			form.processing = false
+			return v, nil
+		}
+		v.mode = usersModeList
+		v.passwordForm = nil
+		v.err = nil
+		v.status = msg.status
+		return v, nil
+	}
+
+	cmds := make([]tea.Cmd, len(form.inputs))
+	for i := range form.inputs {
+		form.inputs[i], cmds[i] = form.inputs[i].Update(msg)
+	}
+	return v, tea.Batch(cmds...)
+}
+
+func (v *UsersView) changePassword(user db.User, password string) tea.Cmd {
+	return func() tea.Msg {
+		if err := v.conn.ChangeUserPassword(user.Username, user.Host, password); err != nil {
+			return userUpdatedMsg{err: err}
+		}
+		return userUpdatedMsg{status: fmt.Sprintf("Password changed for %s", userItem{user: user}.Title())}
+	}
+}
+
+func (v *UsersView) initEditForm(user db.User) {
+	form := &userEditForm{
+		user:      user,
+		isMariaDB: v.conn.Config.Type == db.DatabaseTypeMariaDB,
+	}
+
+	form.name = textinput.New()
+	form.name.Placeholder = "username"
+	form.name.SetValue(user.Username)
+	form.name.Focus()
+	form.name.PromptStyle = focusedStyle
+	form.name.TextStyle = focusedStyle
+
+	if form.isMariaDB {
+		form.hosts = []string{user.Host}
+		for _, h := range defaultHosts {
+			if h != user.Host {
+				form.hosts = append(form.hosts, h)
+			}
+		}
+	}
+
+	v.editForm = form
+}
+
+// fields returns the form's fields in tab order
+func (f *userEditForm) fields() []int {
+	if f.isMariaDB {
+		return []int{editFieldName, editFieldHost, editFieldLock, editFieldExpiry}
+	}
+	return []int{editFieldName, editFieldLock, editFieldExpiry}
+}
+
+// move focuses the field delta places away, wrapping around
+func (f *userEditForm) move(delta int) {
+	fields := f.fields()
+	pos := 0
+	for i, field := range fields {
+		if field == f.focused {
+			pos = i
+		}
+	}
+	pos = (pos + delta + len(fields)) % len(fields)
+	f.focused = fields[pos]
+
+	if f.focused == editFieldName {
+		f.name.Focus()
+		f.name.PromptStyle = focusedStyle
+		f.name.TextStyle = focusedStyle
+	} else {
+		f.name.Blur()
+		f.name.PromptStyle = blurredStyle
+		f.name.TextStyle = blurredStyle
+	}
+}
+
+// cycle steps the focused selector by delta
+func (f *userEditForm) cycle(delta int) {
+	step := func(i, n int) int { return (i + delta + n) % n }
+	switch f.focused {
+	case editFieldHost:
+		f.hostIndex = step(f.hostIndex, len(f.hosts))
+	case editFieldLock:
+		f.lockIndex = step(f.lockIndex, len(userLockOptions))
+	case editFieldExpiry:
+		f.expiryIndex = step(f.expiryIndex, len(passwordExpiryOptions))
+	}
+}
+
+func (v *UsersView) updateEditForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	form := v.editForm
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			v.mode = usersModeList
+			v.editForm = nil
+			return v, nil
+
+		case "tab", "down":
+			form.move(1)
+			return v, nil
+
+		case "shift+tab", "up":
+			form.move(-1)
+			return v, nil
+
+		case "left", "right":
+			if form.focused != editFieldName {
+				if msg.String() == "left" {
+					form.cycle(-1)
+				} else {
+					form.cycle(1)
+				}
+				return v, nil
+			}
+
+		case "enter":
+			if form.processing {
+				return v, nil
+			}
+			newName := strings.TrimSpace(form.name.Value())
+			if newName == "" {
+				form.err = fmt.Errorf("username is required")
+				return v, nil
+			}
+			newHost := form.user.Host
+			if form.isMariaDB {
+				newHost = form.hosts[form.hostIndex]
+			}
+			rename := newName != form.user.Username || newHost != form.user.Host
+			if !rename && form.lockIndex == 0 && form.expiryIndex == 0 {
+				form.err = fmt.Errorf("nothing to change")
+				return v, nil
+			}
+
+			form.err = nil
+			form.processing = true
+			return v, v.editUser(form.user, newName, newHost, form.lockIndex, form.expiryIndex)
+		}
+
+	case userUpdatedMsg:
+		v.mode = usersModeList
+		v.editForm = nil
+		v.err = msg.err
+		v.status = msg.status
+		return v, v.loadUsers
+	}
+
+	var cmd tea.Cmd
+	form.name, cmd = form.name.Update(msg)
+	return v, cmd
+}
+
+// editUser renames the user first, so the lock and expiry changes apply to
+// the new name. Whatever succeeded before a failure is reported as done.
+func (v *UsersView) editUser(user db.User, newName, newHost string, lockIndex, expiryIndex int) tea.Cmd {
+	return func() tea.Msg {
+		var done []string
+		result := func(err error) tea.Msg {
+			msg := userUpdatedMsg{err: err}
+			if len(done) > 0 {
+				msg.status = fmt.Sprintf("%s: %s", userItem{user: user}.Title(), strings.Join(done, ", "))
+			}
+			return msg
+		}
+
+		if newName != user.Username || newHost != user.Host {
+			if err := v.conn.RenameUser(user.Username, user.Host, newName, newHost); err != nil {
+				return result(err)
+			}
+			renamed := db.User{Username: newName, Host: newHost}
+			done = append(done, "renamed to "+userItem{user: renamed}.Title())
+			user.Username, user.Host = newName, newHost
+		}
+
+		if lockIndex > 0 {
+			locked := userLockOptions[lockIndex] == "lock"
+			if err := v.conn.SetUserLocked(user.Username, user.Host, locked); err != nil {
+				return result(err)
+			}
+			done = append(done, userLockOptions[lockIndex]+"ed")
+		}
+
+		if expiryIndex > 0 {
+			opt := passwordExpiryOptions[expiryIndex]
+			if err := v.conn.SetPasswordExpiry(user.Username, user.Host, opt.days); err != nil {
+				return result(err)
+			}
+			done = append(done, "password expiry "+opt.label)
+		}
+
+		return result(nil)
+	}
+}
+
+func (v *UsersView) viewPasswordForm() string {
+	var b strings.Builder
+	form := v.passwordForm
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Change Password - %s", userItem{user: form.user}.Title())))
+	b.WriteString("\n\n")
+
+	labels := []string{"New Password:", "Confirm Password:"}
+	for i, label := range labels {
+		if form.focused == i {
+			b.WriteString(focusedStyle.Render(label))
+		} else {
+			b.WriteString(blurredStyle.Render(label))
+		}
+		b.WriteString("\n")
+		b.WriteString(form.inputs[i].View())
+		b.WriteString("\n")
+		if i == 0 {
+			b.WriteString(form.passwords.view(form.inputs[0].Value(), form.user.Username))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if form.err != nil {
+		b.WriteString(renderError(form.err))
+		b.WriteString("\n\n")
+	}
+
+	if form.processing {
+		b.WriteString("Changing password...\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Enter: Change | Tab: Next | Ctrl+G: Generate password | Esc: Cancel"))
+
+	return b.String()
+}
+
+func (v *UsersView) viewEditForm() string {
+	var b strings.Builder
+	form := v.editForm
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Edit User - %s", userItem{user: form.user}.Title())))
+	b.WriteString("\n\n")
+
+	label := func(field int, text string) {
+		if form.focused == field {
+			b.WriteString(focusedStyle.Render(text))
+		} else {
+			b.WriteString(blurredStyle.Render(text))
+		}
+		b.WriteString("\n")
+	}
+	selector := func(field int, value, hint string) {
+		display := fmt.Sprintf("[ %s ]", value)
+		if form.focused == field {
+			b.WriteString(focusedStyle.Render(display))
+			b.WriteString(mutedStyle.Render("  ←/→ to change"))
+		} else {
+			b.WriteString(blurredStyle.Render(display))
+		}
+		if hint != "" {
+			b.WriteString("\n")
+			b.WriteString(mutedStyle.Render(hint))
+		}
+		b.WriteString("\n\n")
+	}
+
+	label(editFieldName, "Username:")
+	b.WriteString(form.name.View())
+	b.WriteString("\n\n")
+
+	if form.isMariaDB {
+		label(editFieldHost, "Host:")
+		selector(editFieldHost, form.hosts[form.hostIndex], "")
+	}
+
+	lockHint := ""
+	if !form.isMariaDB && form.lockIndex > 0 {
+		lockHint = "PostgreSQL locks by removing LOGIN from the role"
+	}
+	label(editFieldLock, "Account:")
+	selector(editFieldLock, userLockOptions[form.lockIndex], lockHint)
+
+	expiryHint := ""
+	if opt := passwordExpiryOptions[form.expiryIndex]; opt.days > 0 {
+		if form.isMariaDB {
+			expiryHint = fmt.Sprintf("Expires %d days after each password change", opt.days)
+		} else {
+			expiryHint = fmt.Sprintf("Expires %d days from now (VALID UNTIL)", opt.days)
+		}
+	}
+	label(editFieldExpiry, "Password Expiry:")
+	selector(editFieldExpiry, passwordExpiryOptions[form.expiryIndex].label, expiryHint)
+
+	if !form.isMariaDB && strings.TrimSpace(form.name.Value()) != form.user.Username {
+		b.WriteString(mutedStyle.Render("Renaming clears an MD5 password; set it again with p"))
+		b.WriteString("\n\n")
+	}
+
+	if form.err != nil {
+		b.WriteString(renderError(form.err))
+		b.WriteString("\n\n")
+	}
+
+	if form.processing {
+		b.WriteString("Updating user...\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Enter: Apply | Tab: Next | ←/→: Change | Esc: Cancel"))
+
+	return b.String()
+}
@@ -0,0 +1,279 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// User import: accounts from a grants file, with a choice per conflict
+type userImportView struct {
+	input      textinput.Model // Grants file path
+	plans      []db.UserImportPlan
+	cursor     int
+	result     *db.UserImportResult
+	err        error
+	processing bool
+}
+
+type userImportPlannedMsg struct {
+	plans []db.UserImportPlan
+	err   error
+}
+
+type userImportAppliedMsg struct {
+	result *db.UserImportResult
+}
+
+func (v *UsersView) initUserImport() {
+	input := textinput.New()
+	input.Placeholder = "grants.sql"
+	input.Focus()
+	input.PromptStyle = focusedStyle
+	input.TextStyle = focusedStyle
+	v.userImport = &userImportView{input: input}
+}
+
+func (v *UsersView) planUserImport(path string) tea.Cmd {
+	return func() tea.Msg {
+		defs, err := db.ReadUserDefinitions(path)
+		if err != nil {
+			return userImportPlannedMsg{err: err}
+		}
+		plans, err := v.conn.PlanUserImport(defs)
+		return userImportPlannedMsg{plans: plans, err: err}
+	}
+}
+
+func (v *UsersView) applyUserImport(plans []db.UserImportPlan) tea.Cmd {
+	return func() tea.Msg {
+		return userImportAppliedMsg{result: v.conn.ApplyUserImport(plans)}
+	}
+}
+
+func (v *UsersView) updateUserImport(msg tea.Msg) (tea.Model, tea.Cmd) {
+	view := v.userImport
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "esc" {
+			v.mode = usersModeList
+			v.userImport = nil
+			return v, v.loadUsers
+		}
+		if view.processing || view.result != nil {
+			return v, nil
+		}
+
+		// Picking the file
+		if view.plans == nil {
+			if msg.String() == "enter" {
+				path := strings.TrimSpace(view.input.Value())
+				if path == "" {
+					view.err = fmt.Errorf("a grants file is required")
+					return v, nil
+				}
+				view.err = nil
+				view.processing = true
+				return v, v.planUserImport(path)
+			}
+			var cmd tea.Cmd
+			view.input, cmd = view.input.Update(msg)
+			return v, cmd
+		}
+
+		// Choosing actions
+		switch msg.String() {
+		case "up", "k":
+			if view.cursor > 0 {
+				view.cursor--
+			}
+		case "down", "j":
+			if view.cursor < len(view.plans)-1 {
+				view.cursor++
+			}
+		case "s", "p", "m", "c":
+			action := map[string]db.UserImportAction{
+				"s": db.UserImportSkip, "p": db.UserImportPassword, "m": db.UserImportMerge, "c": db.UserImportCreate,
+			}[msg.String()]
+			plan := &view.plans[view.cursor]
+			switch {
+			case !plan.Allows(action):
+				view.err = fmt.Errorf("%s can't be %s", plan.Account(), userImportActionLabel(action))
+			case action == db.UserImportPassword && plan.Auth == "":
+				view.err = fmt.Errorf("the grants file has no password for %s", plan.Account())
+			default:
+				view.err = nil
+				plan.Action = action
+			}
+		case "enter":
+			view.err = nil
+			view.processing = true
+			return v, v.applyUserImport(view.plans)
+		}
+		return v, nil
+
+	case userImportPlannedMsg:
+		view.processing = false
+		view.err = msg.err
+		if msg.err == nil {
+			view.plans = msg.plans
+			view.input.Blur()
+		}
+		return v, nil
+
+	case userImportAppliedMsg:
+		view.processing = false
+		view.result = msg.result
+		return v, nil
+	}
+
+	return v, nil
+}
+
+// userImportActionLabel describes an action in the past tense
+func userImportActionLabel(action db.UserImportAction) string {
+	switch action {
+	case db.UserImportCreate:
+		return "created"
+	case db.UserImportPassword:
+		return "given the file's password"
+	case db.UserImportMerge:
+		return "merged"
+	default:
+		return "skipped"
+	}
+}
+
+func (v *UsersView) viewUserImport() string {
+	var b strings.Builder
+	view := v.userImport
+
+	b.WriteString(titleStyle.Render("Import Users"))
+	b.WriteString("\n\n")
+
+	switch {
+	case view.plans == nil:
+		b.WriteString(focusedStyle.Render("Grants file:"))
+		b.WriteString("\n")
+		b.WriteString(view.input.View())
+		b.WriteString("\n\n")
+		b.WriteString(mutedStyle.Render("CREATE USER and GRANT statements, such as SHOW GRANTS or pt-show-grants output"))
+		b.WriteString("\n\n")
+
+	case view.result != nil:
+		r := view.result
+		b.WriteString(successStyle.Render(fmt.Sprintf("%d created, %d passwords changed, %d merged, %d skipped",
+			r.Created, r.Passwords, r.Merged, r.Skipped)))
+		b.WriteString("\n\n")
+		for _, note := range r.Notes {
+			b.WriteString(mutedStyle.Render(note))
+			b.WriteString("\n")
+		}
+		for _, e := range r.Errors {
+			b.WriteString(errorStyle.Render(e))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+
+	default:
+		b.WriteString(headerStyle.Render(fmt.Sprintf("  %-32s %-8s %-30s %s", "Account", "Status", "Differences", "Action")))
+		b.WriteString("\n")
+
+		visible := max(v.height-20, 5)
+		start := 0
+		if view.cursor >= visible {
+			start = view.cursor - visible + 1
+		}
+		end := min(start+visible, len(view.plans))
+		for i := start; i < end; i++ {
+			p := view.plans[i]
+			line := fmt.Sprintf("%-32s %-8s %-30s %s",
+				truncateRunes(p.Account(), 32), p.Status(), truncateRunes(userImportDifferences(p), 30), p.Action)
+			switch {
+			case i == view.cursor:
+				b.WriteString(selectedStyle.Render("> " + line))
+			case p.Conflict():
+				b.WriteString(errorStyle.Render("! " + line))
+			default:
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+		}
+
+		// What the selected account would gain or keep
+		p := view.plans[view.cursor]
+		b.WriteString("\n")
+		for _, g := range p.MissingGrants {
+			b.WriteString(successStyle.Render("+ ") + truncateRunes(g, max(v.width-6, 40)))
+			b.WriteString("\n")
+		}
+		for _, g := range p.ExtraGrants {
+			b.WriteString(mutedStyle.Render("= " + truncateRunes(g, max(v.width-6, 40)) + " (kept)"))
+			b.WriteString("\n")
+		}
+		choices := make([]string, 0, 3)
+		for _, a := range p.Actions() {
+			choices = append(choices, string(a))
+		}
+		b.WriteString(mutedStyle.Render("Choices: " + strings.Join(choices, ", ")))
+		b.WriteString("\n\n")
+	}
+
+	if view.err != nil {
+		b.WriteString(renderError(view.err))
+		b.WriteString("\n\n")
+	}
+	if view.processing {
+		b.WriteString("Working...\n\n")
+	}
+
+	switch {
+	case view.plans == nil:
+		b.WriteString(helpStyle.Render("Enter: Compare | Esc: Cancel"))
+	case view.result != nil:
+		b.WriteString(helpStyle.Render("Esc: Back"))
+	default:
+		b.WriteString(helpStyle.Render("↑↓: Navigate | c: Create | s: Skip | p: Password | m: Merge | Enter: Import | Esc: Cancel"))
+	}
+	return b.String()
+}
+
+// userImportDifferences summarizes how an existing account differs
+func userImportDifferences(p db.UserImportPlan) string {
+	var diffs []string
+	if p.PasswordDiffers {
+		diffs = append(diffs, "password")
+	}
+	if n := len(p.MissingGrants); n > 0 {
+		diffs = append(diffs, fmt.Sprintf("%d missing", n))
+	}
+	if n := len(p.ExtraGrants); n > 0 {
+		diffs = append(diffs, fmt.Sprintf("%d extra", n))
+	}
+	if len(diffs) == 0 {
+		return "-"
+	}
+	return strings.Join(diffs, ", ")
+}
@@ -0,0 +1,366 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PostgreSQL role attributes form
+type roleAttrsForm struct {
+	name       string
+	attrs      *db.RoleAttributes // nil while loading
+	connLimit  textinput.Model
+	validUntil textinput.Model
+	roles      []string // Roles the user can be made a member of
+	member     map[string]bool
+	roleCursor int
+	focused    int // One of the roleField constants
+	err        error
+	processing bool
+}
+
+const (
+	roleFieldLogin = iota
+	roleFieldSuperuser
+	roleFieldCreateDB
+	roleFieldCreateRole
+	roleFieldConnLimit
+	roleFieldValidUntil
+	roleFieldMemberOf
+	roleFieldCount
+)
+
+// roleFlags are the checkbox fields and their labels
+var roleFlags = []struct {
+	field int
+	label string
+}{
+	{roleFieldLogin, "LOGIN (can connect)"},
+	{roleFieldSuperuser, "SUPERUSER"},
+	{roleFieldCreateDB, "CREATEDB"},
+	{roleFieldCreateRole, "CREATEROLE"},
+}
+
+type roleAttrsLoadedMsg struct {
+	attrs *db.RoleAttributes
+	roles []string
+}
+
+func (v *UsersView) initRoleAttrsForm(user db.User) tea.Cmd {
+	form := &roleAttrsForm{
+		name:   user.Username,
+		member: make(map[string]bool),
+	}
+
+	form.connLimit = textinput.New()
+	form.connLimit.Placeholder = "unlimited"
+	form.connLimit.CharLimit = 6
+
+	form.validUntil = textinput.New()
+	form.validUntil.Placeholder = "never (e.g. 2026-12-31 or 2026-12-31 23:59+00)"
+
+	v.roleAttrsForm = form
+	v.mode = usersModeRoleAttrs
+
+	return func() tea.Msg {
+		attrs, err := v.conn.GetRoleAttributes(user.Username)
+		if err != nil {
+			return err
+		}
+		roles, err := v.conn.ListRoles()
+		if err != nil {
+			return err
+		}
+		return roleAttrsLoadedMsg{attrs: attrs, roles: roles}
+	}
+}
+
+// flag returns the attribute behind a checkbox field
+func (f *roleAttrsForm) flag(field int) *bool {
+	switch field {
+	case roleFieldLogin:
+		return &f.attrs.Login
+	case roleFieldSuperuser:
+		return &f.attrs.Superuser
+	case roleFieldCreateDB:
+		return &f.attrs.CreateDB
+	case roleFieldCreateRole:
+		return &f.attrs.CreateRole
+	}
+	return nil
+}
+
+// focus moves focus to a field, wrapping around
+func (f *roleAttrsForm) focus(field int) {
+	f.focused = (field + roleFieldCount) % roleFieldCount
+
+	inputs := map[int]*textinput.Model{
+		roleFieldConnLimit:  &f.connLimit,
+		roleFieldValidUntil: &f.validUntil,
+	}
+	for field, input := range inputs {
+		if field == f.focused {
+			input.Focus()
+			input.PromptStyle = focusedStyle
+			input.TextStyle = focusedStyle
+		} else {
+			input.Blur()
+			input.PromptStyle = blurredStyle
+			input.TextStyle = blurredStyle
+		}
+	}
+}
+
+func (v *UsersView) updateRoleAttrsForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	form := v.roleAttrsForm
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "esc" {
+			v.mode = usersModeList
+			v.roleAttrsForm = nil
+			return v, nil
+		}
+		if form.attrs == nil || form.processing {
+			return v, nil
+		}
+
+		switch msg.String() {
+		case "tab":
+			form.focus(form.focused + 1)
+			return v, nil
+
+		case "shift+tab":
+			form.focus(form.focused - 1)
+			return v, nil
+
+		case "down":
+			if form.focused == roleFieldMemberOf && form.roleCursor < len(form.roles)-1 {
+				form.roleCursor++
+			} else if form.focused != roleFieldMemberOf {
+				form.focus(form.focused + 1)
+			}
+			return v, nil
+
+		case "up":
+			if form.focused == roleFieldMemberOf && form.roleCursor > 0 {
+				form.roleCursor--
+			} else {
+				form.focus(form.focused - 1)
+			}
+			return v, nil
+
+		case " ":
+			if flag := form.flag(form.focused); flag != nil {
+				*flag = !*flag
+				return v, nil
+			}
+			if form.focused == roleFieldMemberOf && len(form.roles) > 0 {
+				role := form.roles[form.roleCursor]
+				form.member[role] = !form.member[role]
+				return v, nil
+			}
+
+		case "enter":
+			attrs := *form.attrs
+			attrs.ConnectionLimit = -1
+			if limit := strings.TrimSpace(form.connLimit.Value()); limit != "" {
+				n, err := strconv.Atoi(limit)
+				if err != nil || n < -1 {
+					form.err = fmt.Errorf("connection limit must be a number, or empty for unlimited")
+					return v, nil
+				}
+				attrs.ConnectionLimit = n
+			}
+			attrs.ValidUntil = strings.TrimSpace(form.validUntil.Value())
+
+			var memberOf []string
+			for _, role := range form.roles {
+				if form.member[role] {
+					memberOf = append(memberOf, role)
+				}
+			}
+
+			form.err = nil
+			form.processing = true
+			return v, v.applyRoleAttrs(attrs, memberOf)
+		}
+
+	case roleAttrsLoadedMsg:
+		form.attrs = msg.attrs
+		if msg.attrs.ConnectionLimit >= 0 {
+			form.connLimit.SetValue(strconv.Itoa(msg.attrs.ConnectionLimit))
+		}
+		if msg.attrs.ValidUntil != "infinity" {
+			form.validUntil.SetValue(msg.attrs.ValidUntil)
+		}
+		for _, role := range msg.roles {
+			if role != form.name {
+				form.roles = append(form.roles, role)
+			}
+		}
+		for _, role := range msg.attrs.MemberOf {
+			form.member[role] = true
+		}
+		form.focus(roleFieldLogin)
+		return v, nil
+
+	case userUpdatedMsg:
+		if msg.err != nil {
+			form.err = msg.err
+			form.processing = false
+			return v, nil
+		}
+		v.mode = usersModeList
+		v.roleAttrsForm = nil
+		v.err = nil
+		v.status = msg.status
+		return v, v.loadUsers
+
+	case error:
+		form.err = msg
+		form.processing = false
+		return v, nil
+	}
+
+	var cmds [2]tea.Cmd
+	form.connLimit, cmds[0] = form.connLimit.Update(msg)
+	form.validUntil, cmds[1] = form.validUntil.Update(msg)
+	return v, tea.Batch(cmds[:]...)
+}
+
+func (v *UsersView) applyRoleAttrs(attrs db.RoleAttributes, memberOf []string) tea.Cmd {
+	return func() tea.Msg {
+		if err := v.conn.AlterRoleAttributes(attrs); err != nil {
+			return userUpdatedMsg{err: err}
+		}
+		if err := v.conn.SetRoleMemberships(attrs.Name, memberOf); err != nil {
+			return userUpdatedMsg{err: err}
+		}
+		return userUpdatedMsg{status: fmt.Sprintf("Role %s updated", attrs.Name)}
+	}
+}
+
+func (v *UsersView) viewRoleAttrsForm() string {
+	var b strings.Builder
+	form := v.roleAttrsForm
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Role Attributes - %s", form.name)))
+	b.WriteString("\n\n")
+
+	if form.attrs == nil {
+		if form.err != nil {
+			b.WriteString(renderError(form.err))
+		} else {
+			b.WriteString(mutedStyle.Render("Loading..."))
+		}
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Esc: Back"))
+		return b.String()
+	}
+
+	for _, f := range roleFlags {
+		checkbox := "[ ]"
+		if *form.flag(f.field) {
+			checkbox = "[x]"
+		}
+		line := fmt.Sprintf("%s %s", checkbox, f.label)
+		if form.focused == f.field {
+			b.WriteString(focusedStyle.Render("→ " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if form.focused == roleFieldConnLimit {
+		b.WriteString(focusedStyle.Render("Connection Limit:"))
+	} else {
+		b.WriteString(blurredStyle.Render("Connection Limit:"))
+	}
+	b.WriteString("\n")
+	b.WriteString(form.connLimit.View())
+	b.WriteString("\n\n")
+
+	if form.focused == roleFieldValidUntil {
+		b.WriteString(focusedStyle.Render("Password Valid Until:"))
+	} else {
+		b.WriteString(blurredStyle.Render("Password Valid Until:"))
+	}
+	b.WriteString("\n")
+	b.WriteString(form.validUntil.View())
+	b.WriteString("\n\n")
+
+	if form.focused == roleFieldMemberOf {
+		b.WriteString(focusedStyle.Render("Member Of:"))
+	} else {
+		b.WriteString(blurredStyle.Render("Member Of:"))
+	}
+	b.WriteString("\n")
+
+	if len(form.roles) == 0 {
+		b.WriteString(mutedStyle.Render("  No other roles"))
+		b.WriteString("\n")
+	}
+
+	maxShow := 8
+	start := 0
+	if form.roleCursor >= maxShow {
+		start = form.roleCursor - maxShow + 1
+	}
+	for i := start; i < len(form.roles) && i < start+maxShow; i++ {
+		role := form.roles[i]
+		checkbox := "[ ]"
+		if form.member[role] {
+			checkbox = "[x]"
+		}
+		if form.focused == roleFieldMemberOf && i == form.roleCursor {
+			b.WriteString(focusedStyle.Render(fmt.Sprintf("  → %s %s", checkbox, role)))
+		} else {
+			b.WriteString(fmt.Sprintf("    %s %s", checkbox, role))
+		}
+		b.WriteString("\n")
+	}
+	if len(form.roles) > start+maxShow {
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("    ... and %d more", len(form.roles)-start-maxShow)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if form.err != nil {
+		b.WriteString(renderError(form.err))
+		b.WriteString("\n\n")
+	}
+
+	if form.processing {
+		b.WriteString("Updating role...\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("Tab/↑↓: Navigate | Space: Toggle | Enter: Apply | Esc: Cancel"))
+
+	return b.String()
+}
@@ -0,0 +1,246 @@
+// YSM - Yandere SQL Manager
+// Copyright (C) 2025 blubskye
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+// Source code: https://github.com/blubskye/yandere_sql_manager
+
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/blubskye/yandere_sql_manager/internal/db"
+	"github.com/blubskye/yandere_sql_manager/internal/progress"
+)
+
+// Operations that fire webhooks
+const (
+	OperationExport  = "export"
+	OperationImport  = "import"
+	OperationBackup  = "backup"
+	OperationRestore = "restore"
+)
+
+// Operations lists every operation a webhook can subscribe to
+var Operations = []string{OperationExport, OperationImport, OperationBackup, OperationRestore}
+
+// SignatureHeader carries the hex HMAC-SHA256 of the body, as sha256=<hex>,
+// when the webhook has a secret
+const SignatureHeader = "X-YSM-Signature"
+
+// Webhook receives a JSON POST when an operation finishes, or, listed under
+// alerts, when a health check changes and a health report is sent
+type Webhook struct {
+	URL     string            `yaml:"url"`
+	Secret  string            `yaml:"secret,omitempty"` // Signs each body; see SignatureHeader
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Events  []string          `yaml:"events,omitempty"` // Operations to send; default all. Not used for alerts
+}
+
+// Wants reports whether the webhook subscribes to an operation
+func (w Webhook) Wants(operation string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, operation)
+}
+
+// Validate checks a webhook's URL and events
+func (w Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook url %q", w.URL)
+	}
+	for _, e := range w.Events {
+		if !slices.Contains(Operations, e) {
+			return fmt.Errorf("webhook %s: unknown event %q (use %s)", w.URL, e, strings.Join(Operations, ", "))
+		}
+	}
+	return nil
+}
+
+// Event is a finished operation
+type Event struct {
+	Operation string
+	Server    string // Profile name, or host when connected without one
+	Target    string // Database, or backup ID for backups and restores
+	File      string // File written or read, if any
+	Duration  time.Duration
+	Rows      int64
+	Bytes     int64
+	Errors    int64 // Errors continued past; a failed operation has Err set instead
+	Err       error
+	Time      time.Time
+}
+
+// ExportEvent describes a finished export; stats may be nil when it failed
+func ExportEvent(database, file string, stats *db.ExportStats, elapsed time.Duration, err error) Event {
+	e := Event{Operation: OperationExport, Target: database, File: file, Duration: elapsed, Err: err, Time: time.Now()}
+	if stats != nil {
+		e.Rows, e.Bytes = stats.RowsExported, stats.BytesWritten
+	}
+	return e
+}
+
+// ImportEvent describes a finished import; stats may be nil when it failed
+func ImportEvent(file, database string, stats *db.ImportStats, elapsed time.Duration, err error) Event {
+	e := Event{Operation: OperationImport, Target: database, File: file, Duration: elapsed, Err: err, Time: time.Now()}
+	if stats != nil {
+		e.Rows, e.Bytes, e.Errors = stats.RowsInserted, stats.BytesRead, stats.ErrorsEncountered
+	}
+	return e
+}
+
+// BackupEvent describes a finished backup; metadata is nil when it failed
+func BackupEvent(metadata *db.BackupMetadata, elapsed time.Duration, err error) Event {
+	e := Event{Operation: OperationBackup, Duration: elapsed, Err: err, Time: time.Now()}
+	if metadata != nil {
+		e.Target, e.Bytes = metadata.ID, metadata.TotalSize
+		for _, f := range metadata.Files {
+			e.Rows += f.Rows
+		}
+	}
+	return e
+}
+
+// RestoreEvent describes a finished restore of a backup
+func RestoreEvent(backupID string, elapsed time.Duration, err error) Event {
+	return Event{Operation: OperationRestore, Target: backupID, Duration: elapsed, Err: err, Time: time.Now()}
+}
+
+// Title names what ran, e.g. "Export of shop"
+func (e Event) Title() string {
+	what := strings.ToUpper(e.Operation[:1]) + e.Operation[1:]
+	if e.Target != "" {
+		what += " of " + e.Target
+	}
+	return what
+}
+
+// Text is the one-line summary chat integrations show
+func (e Event) Text() string {
+	what := e.Title()
+	if e.Server != "" {
+		what += " on " + e.Server
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("[ysm] %s failed after %s: %v", what, progress.FormatDuration(e.Duration), e.Err)
+	}
+
+	var details []string
+	if e.Rows > 0 {
+		details = append(details, fmt.Sprintf("%d rows", e.Rows))
+	}
+	if e.Bytes > 0 {
+		details = append(details, db.FormatSize(e.Bytes))
+	}
+	if e.Errors > 0 {
+		details = append(details, fmt.Sprintf("%d errors", e.Errors))
+	}
+	text := fmt.Sprintf("[ysm] %s finished in %s", what, progress.FormatDuration(e.Duration))
+	if len(details) > 0 {
+		text += ": " + strings.Join(details, ", ")
+	}
+	return text
+}
+
+// payload is the JSON body. The text field makes it readable as-is by
+// Slack, Mattermost and Matrix hookshot incoming webhooks
+func (e Event) payload() map[string]interface{} {
+	status, errText := "success", ""
+	if e.Err != nil {
+		status, errText = "failure", e.Err.Error()
+	}
+	return map[string]interface{}{
+		"text":        e.Text(),
+		"event":       e.Operation,
+		"status":      status,
+		"server":      e.Server,
+		"target":      e.Target,
+		"file":        e.File,
+		"duration_ms": e.Duration.Milliseconds(),
+		"rows":        e.Rows,
+		"bytes":       e.Bytes,
+		"errors":      e.Errors,
+		"error":       errText,
+		"time":        e.Time.Format(time.RFC3339),
+	}
+}
+
+// Sign returns the SignatureHeader value for a body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts the event to every webhook subscribed to its operation,
+// returning the first delivery error
+func Send(hooks []Webhook, event Event) error {
+	var firstErr error
+	for _, hook := range hooks {
+		if !hook.Wants(event.Operation) {
+			continue
+		}
+		err := hook.Validate()
+		if err == nil {
+			err = PostJSON(hook, event.Operation, event.payload())
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// PostJSON posts payload to the webhook as JSON. event goes in the
+// X-YSM-Event header, e.g. the operation, "alert" or "report", and the body
+// is signed when the webhook has a secret.
+func PostJSON(hook Webhook, event string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("webhook failed: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-YSM-Event", event)
+	if hook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+	}
+	for name, value := range hook.Headers {
+		req.Header.Set(name, value)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook failed: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", hook.URL, resp.Status)
+	}
+	return nil
+}